@@ -0,0 +1,42 @@
+// Command rotate-keys encrypts the secret settings and, with ENCRYPT_CLIENT_NOTES, the notes
+// of clients again with the current data encryption key. To rotate the key, set the new key
+// in DATA_ENCRYPTION_KEY and the old one in DATA_ENCRYPTION_PREVIOUS_KEYS, restart the
+// servers, run this command, then remove the old key. It uses the database settings of the
+// server (DB_HOST, DB_PORT, ...).
+package main
+
+import (
+	"log"
+	"os"
+
+	"ps_club_backend/internal/database"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+func main() {
+	utils.InitLogger()
+
+	if err := utils.LoadEncryptionKeys(); err != nil {
+		log.Fatalf("Invalid data encryption key: %v", err)
+	}
+	if !utils.EncryptionEnabled() {
+		log.Fatal("DATA_ENCRYPTION_KEY is not set; there is no key to encrypt with")
+	}
+	repositories.SetClientNotesEncryption(os.Getenv("ENCRYPT_CLIENT_NOTES") == "true")
+
+	database.InitDB(utils.Getenv("DB_HOST", "localhost"), utils.Getenv("DB_PORT", "5432"),
+		utils.Getenv("DB_USER", "ps_club_user"), utils.Getenv("DB_PASSWORD", "ps_club_password"),
+		utils.Getenv("DB_NAME", "ps_club_crm_db"), utils.Getenv("DB_SSLMODE", "disable"), "")
+	dbConn := database.GetDB()
+
+	settings, err := repositories.NewSettingRepository(dbConn).ReencryptSecrets()
+	if err != nil {
+		log.Fatalf("Error re-encrypting secret settings (%d done): %v", settings, err)
+	}
+	notes, err := repositories.NewClientRepository(dbConn).ReencryptNotes()
+	if err != nil {
+		log.Fatalf("Error re-encrypting client notes (%d done): %v", notes, err)
+	}
+	utils.LogInfo("Data encryption key rotated", map[string]interface{}{"settings": settings, "client_notes": notes})
+}
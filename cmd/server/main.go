@@ -1,20 +1,39 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"ps_club_backend/internal/backup"
 	"ps_club_backend/internal/database"
-	// "ps_club_backend/internal/handlers" // No longer directly used for route setup here
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/grpcapi"
+	"ps_club_backend/internal/handlers" // The report runner of the scheduled saved reports
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/mail"
+	"ps_club_backend/internal/middleware"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/payments"
+	"ps_club_backend/internal/power"
+	"ps_club_backend/internal/push"
+	"ps_club_backend/internal/realtime"
+	"ps_club_backend/internal/repositories"
 	// "ps_club_backend/internal/middleware" // No longer directly used for route setup here
-	// "ps_club_backend/internal/services" // No longer directly used for route setup here
-	"ps_club_backend/internal/router" // Added for router.Setup
-	"ps_club_backend/pkg/utils"       // Import utils for logger
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"ps_club_backend/internal/router" // Added for router.New
+	"ps_club_backend/internal/services"
+	"ps_club_backend/internal/sms"
+	"ps_club_backend/internal/telegram"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils" // Import utils for logger
 )
 
 func main() {
@@ -34,34 +53,254 @@ func main() {
 	database.InitDB(dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode, dbSchemaPath)
 	utils.LogInfo("Database initialized", map[string]interface{}{"configured_from_env": true})
 
-	engine := gin.Default() // Renamed router to engine
+	dbConn := database.GetDB()
+	if err := database.RunMigrations(dbConn); err != nil {
+		log.Fatalf("Error applying database migrations: %v", err)
+	}
+	warnMissingIndexes(services.NewDiagnosticsService(repositories.NewDiagnosticsRepository(dbConn)))
+	configureEncryption()
+
+	// Club timezone and currency: application settings take precedence over the environment defaults
+	settingRepo := repositories.NewSettingRepository(dbConn)
+	loadClubTimezone(settingRepo, utils.Getenv("CLUB_TIMEZONE", "UTC"))
+	loadCurrency(settingRepo, utils.Getenv("CURRENCY", utils.DefaultCurrencyCode))
+	loadAPIV1Sunset(settingRepo)
+	loadDiscountLimits(settingRepo)
+	loadBookingPolicy(settingRepo)
+	loadPricingRules(settingRepo)
+	loadHookahSettings(settingRepo)
+	loadLostFoundSettings(settingRepo)
+	loadPayloadLogging(settingRepo)
+	loadTaxSettings(settingRepo)
+	loadReorderPointSettings(settingRepo)
+	loadAttendanceSettings(settingRepo)
+	loadClockInSettings(settingRepo)
+	loadServiceChargeSettings(settingRepo)
+	loadOrderArchiveSettings(settingRepo)
+	loadAnomalySettings(settingRepo)
+	loadPushSettings(settingRepo)
+	loadBookingReminderSettings(settingRepo)
+	loadErrorReporting(settingRepo, os.Getenv("SENTRY_DSN"))
+	logSetupRequired(repositories.NewSetupRepository(dbConn))
+	// Each instance serves one branch; daily order numbers are counted per branch
+	if err := utils.SetBranchCode(os.Getenv("BRANCH_CODE")); err != nil {
+		log.Fatalf("Invalid BRANCH_CODE: %v", err)
+	}
 
-	// Add GinLogger middleware for request logging
-	engine.Use(utils.GinLogger()) // Updated to engine
+	routerConfig := router.DefaultConfig()
 
 	// CORS configuration
-	corsAllowedOriginsEnv := os.Getenv("CORS_ALLOWED_ORIGINS")
-	var allowedOrigins []string
-	if corsAllowedOriginsEnv != "" {
-		allowedOrigins = strings.Split(corsAllowedOriginsEnv, ",")
+	if corsAllowedOriginsEnv := os.Getenv("CORS_ALLOWED_ORIGINS"); corsAllowedOriginsEnv != "" {
+		routerConfig.AllowedOrigins = strings.Split(corsAllowedOriginsEnv, ",")
+	}
+	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
+		routerConfig.JWTSecret = jwtSecret
 	} else {
-		allowedOrigins = []string{"http://localhost:3000", "http://localhost:3001"} // Default origins
+		utils.LogInfo("JWT_SECRET is not set, using the insecure development secret")
 	}
 
-	config := cors.DefaultConfig()
-	config.AllowOrigins = allowedOrigins
-	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
-	config.AllowCredentials = true
-	engine.Use(cors.New(config)) // Updated to engine
+	// Shared state: Redis is required when several instances run behind a load balancer
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		store, err := kvstore.NewRedisStore(ctx, redisURL)
+		cancel()
+		if err != nil {
+			log.Fatalf("Error connecting to Redis: %v", err)
+		}
+		routerConfig.Store = store
+		utils.LogInfo("Using Redis for shared state")
+	} else {
+		utils.LogInfo("REDIS_URL is not set, keeping shared state in memory (single instance only)")
+	}
+	authRateLimit, err := strconv.Atoi(utils.Getenv("AUTH_RATE_LIMIT", "20"))
+	if err != nil {
+		log.Fatalf("Invalid AUTH_RATE_LIMIT: %v", err)
+	}
+	routerConfig.AuthRateLimit = authRateLimit
+	// Client IPs (rate limits, the clock_in setting) come from X-Forwarded-For only through these proxies
+	if trustedProxies, ok := os.LookupEnv("TRUSTED_PROXIES"); ok {
+		routerConfig.TrustedProxies = []string{}
+		for _, proxy := range strings.Split(trustedProxies, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				routerConfig.TrustedProxies = append(routerConfig.TrustedProxies, proxy)
+			}
+		}
+	}
 
-	engine.GET("/ping", func(c *gin.Context) { // Updated to engine
-		c.JSON(http.StatusOK, gin.H{"message": "pong"})
+	// Database backups: on request of an Admin and nightly per the backup_schedule setting
+	backupRunner := newBackupRunner(backup.DBConfig{
+		Host: dbHost, Port: dbPort, User: dbUser, Password: dbPassword, Name: dbName, SSLMode: dbSSLMode,
 	})
+	routerConfig.BackupRunner = backupRunner
+	backupService := services.NewBackupService(repositories.NewBackupRepository(dbConn), settingRepo, backupRunner, routerConfig.Store, dbConn)
+	go backupService.RunSchedule(context.Background())
 
-	// Setup all application routes
-	dbConn := database.GetDB()
-	router.Setup(engine, dbConn) // Updated router to engine for the first argument
+	// Card deposits of table sessions are pre-authorized with the payment provider if PAYMENT_PROVIDER_URL is set
+	if paymentsURL := os.Getenv("PAYMENT_PROVIDER_URL"); paymentsURL != "" {
+		routerConfig.Payments = payments.NewHTTPProvider(paymentsURL, os.Getenv("PAYMENT_PROVIDER_TOKEN"))
+		utils.LogInfo("Payment provider configured", map[string]interface{}{"url": paymentsURL})
+	}
+
+	// Prepaid table sessions are warned of and stopped or moved to overtime at their time limit
+	bookingRepo := repositories.NewBookingRepository(dbConn)
+	gameTableRepo := repositories.NewGameTableRepository(dbConn)
+	tableSessionService := services.NewTableSessionService(repositories.NewTableSessionRepository(dbConn), bookingRepo, gameTableRepo,
+		repositories.NewLockerRepository(dbConn), repositories.NewSessionDepositRepository(dbConn), routerConfig.Payments,
+		events.NewPublisher(repositories.NewOutboxRepository(dbConn)), dbConn)
+	go tableSessionService.RunTimers(context.Background())
+
+	// Tables become reserved before their bookings and occupied or available with their sessions
+	gameTableService := services.NewGameTableService(gameTableRepo, bookingRepo, repositories.NewOrderRepository(dbConn),
+		tableSessionService, events.NewPublisher(repositories.NewOutboxRepository(dbConn)), dbConn)
+	go gameTableService.RunStatusSync(context.Background())
+
+	// The TV and console of a table are switched on and off with its sessions if POWER_CONTROL_URL is set
+	if powerURL := os.Getenv("POWER_CONTROL_URL"); powerURL != "" {
+		routerConfig.PowerControl = power.NewHTTPController(powerURL, os.Getenv("POWER_CONTROL_TOKEN"))
+		utils.LogInfo("Table power control configured", map[string]interface{}{"url": powerURL})
+	}
+	powerService := services.NewPowerService(bookingRepo, routerConfig.PowerControl)
+
+	// Staff are reminded to change the coals of the hookahs being served per the hookah_service setting
+	hookahService := services.NewHookahService(repositories.NewHookahRepository(dbConn), repositories.NewPricelistRepository(dbConn),
+		repositories.NewInventoryMovementRepository(dbConn), repositories.NewStockBatchRepository(dbConn),
+		events.NewPublisher(repositories.NewOutboxRepository(dbConn)), dbConn)
+	go hookahService.RunTimers(context.Background())
+
+	// Lost and found entries are purged after the retention of the lost_and_found setting
+	lostFoundService := services.NewLostFoundService(repositories.NewLostFoundRepository(dbConn), bookingRepo,
+		repositories.NewClientRepository(dbConn))
+	go lostFoundService.RunPurge(context.Background())
+
+	// Reorder points are recalculated from consumption every ReorderPointInterval per the reorder_points setting
+	reorderPointService := services.NewReorderPointService(repositories.NewReorderPointRepository(dbConn), routerConfig.Store, dbConn)
+	go reorderPointService.RunCalculation(context.Background())
+
+	// Stock left in batches past their expiry date is written off as spoilage every StockBatchWriteOffInterval
+	stockBatchService := services.NewStockBatchService(repositories.NewStockBatchRepository(dbConn), repositories.NewPricelistRepository(dbConn),
+		repositories.NewInventoryMovementRepository(dbConn), events.NewPublisher(repositories.NewOutboxRepository(dbConn)), dbConn)
+	go stockBatchService.RunWriteOff(context.Background())
+
+	// The materialized views of the sales and booking reports are refreshed every ReportViewRefreshInterval
+	reportViewService := services.NewReportViewService(repositories.NewReportViewRepository(dbConn), routerConfig.Store)
+	go reportViewService.RunRefresh(context.Background())
+
+	// The monthly partitions of the orders and inventory movements are created months ahead every PartitionMaintenanceInterval
+	partitionService := services.NewPartitionService(repositories.NewPartitionRepository(dbConn))
+	go partitionService.RunMaintenance(context.Background())
+
+	// Completed orders past the retention of the order_archive setting are archived every OrderArchiveInterval
+	orderArchiveService := services.NewOrderArchiveService(repositories.NewOrderArchiveRepository(dbConn), dbConn)
+	go orderArchiveService.RunArchival(context.Background())
+
+	// End-of-shift reports and expiring staff documents are emailed to the Admins, and scheduled saved reports to
+	// their recipients, if SMTP_HOST is set
+	var mailSender services.MailSender
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		smtpPort, err := strconv.Atoi(utils.Getenv("SMTP_PORT", strconv.Itoa(mail.DefaultPort)))
+		if err != nil {
+			log.Fatalf("Invalid SMTP_PORT: %v", err)
+		}
+		mailSender = mail.NewSMTPSender(smtpHost, smtpPort, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"),
+			utils.Getenv("SMTP_FROM", "ps-club@localhost"))
+		utils.LogInfo("Email configured", map[string]interface{}{"host": smtpHost, "port": smtpPort})
+	}
+	shiftReportService := services.NewShiftReportService(repositories.NewShiftReportRepository(dbConn),
+		repositories.NewStaffRepository(dbConn), repositories.NewAuthRepository(dbConn), mailSender)
+
+	// Staff documents about to expire are notified of every StaffDocumentCheckInterval
+	staffDocumentService := services.NewStaffDocumentService(repositories.NewStaffDocumentRepository(dbConn),
+		repositories.NewStaffRepository(dbConn), repositories.NewAuthRepository(dbConn),
+		events.NewPublisher(repositories.NewOutboxRepository(dbConn)), mailSender, dbConn)
+	go staffDocumentService.RunExpiryCheck(context.Background())
+
+	// Saved reports due to be emailed are looked for every SavedReportScheduleInterval
+	reportService := services.NewReportService(repositories.NewReportRepository(dbConn), repositories.NewDayCloseRepository(dbConn),
+		repositories.NewShiftReportRepository(dbConn), repositories.NewCalendarNoteRepository(dbConn), dbConn)
+	savedReportService := services.NewSavedReportService(repositories.NewSavedReportRepository(dbConn),
+		handlers.NewReportRunner(dbConn, reportService), mailSender, dbConn)
+	go savedReportService.RunSchedule(context.Background())
+
+	// Sales targets falling far behind after mid-month are looked for every SalesTargetCheckInterval
+	salesTargetService := services.NewSalesTargetService(repositories.NewSalesTargetRepository(dbConn),
+		repositories.NewDayCloseRepository(dbConn), repositories.NewAuthRepository(dbConn),
+		events.NewPublisher(repositories.NewOutboxRepository(dbConn)), mailSender, dbConn)
+	go salesTargetService.RunBehindCheck(context.Background())
+
+	// The previous day is checked for anomalies once, by the first run after midnight
+	anomalyService := services.NewAnomalyService(repositories.NewAnomalyRepository(dbConn), repositories.NewAuthRepository(dbConn),
+		repositories.NewCalendarNoteRepository(dbConn), events.NewPublisher(repositories.NewOutboxRepository(dbConn)), mailSender, dbConn)
+	go anomalyService.RunNightlyCheck(context.Background())
+
+	// Domain events recorded by the services are relayed from the outbox to in-process subscribers
+	eventBus := events.NewBus()
+	eventBus.Subscribe(events.AllEvents, "log", logDomainEvent)
+	// Table session, table status and cash events are pushed to the WebSocket clients of every
+	// instance; router.RealtimeTopics limits the cash events to Admins
+	sessionAlerts := realtime.NewHub(routerConfig.Store, router.SessionAlertsChannel, router.RealtimeHubConfig(routerConfig.Store))
+	go sessionAlerts.Run(context.Background())
+	for _, eventType := range []string{events.TableSessionStarted, events.TableSessionWarning, events.TableSessionOvertime, events.TableSessionStopped,
+		events.TableStatusChanged, events.OrderPaid, events.OrderRefunded} {
+		eventBus.Subscribe(eventType, "session_alerts", forwardToHub(sessionAlerts))
+	}
+	routerConfig.SessionAlerts = sessionAlerts
+	// Hookah events, above all the coal change reminders, likewise
+	hookahAlerts := realtime.NewHub(routerConfig.Store, router.HookahAlertsChannel, router.RealtimeHubConfig(routerConfig.Store))
+	go hookahAlerts.Run(context.Background())
+	for _, eventType := range []string{events.HookahCoalChangeDue, events.HookahCoalChanged, events.HookahEnded} {
+		eventBus.Subscribe(eventType, "hookah_alerts", forwardToHub(hookahAlerts))
+	}
+	routerConfig.HookahAlerts = hookahAlerts
+	for eventType := range services.PowerEvents {
+		eventBus.Subscribe(eventType, "power_control", powerService.HandleSessionEvent)
+	}
+	eventBus.Subscribe(events.StaffShiftReported, "shift_report_email", shiftReportService.HandleReportEvent)
+	eventBus.Subscribe(events.StaffDocumentExpiry, "staff_document_email", staffDocumentService.HandleExpiryEvent)
+	eventBus.Subscribe(events.SalesTargetBehind, "sales_target_email", salesTargetService.HandleBehindEvent)
+	eventBus.Subscribe(events.AnomalyDetected, "anomaly_email", anomalyService.HandleAnomalyEvent)
+	// Booking confirmations, tables ready and loyalty milestones are pushed to the client app
+	// if FCM_CREDENTIALS_FILE is set
+	var pushSender services.PushSender
+	if credentialsFile := os.Getenv("FCM_CREDENTIALS_FILE"); credentialsFile != "" {
+		credentials, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			log.Fatalf("Failed to read FCM_CREDENTIALS_FILE: %v", err)
+		}
+		fcmSender, err := push.NewFCMSender(credentials)
+		if err != nil {
+			log.Fatalf("Invalid FCM_CREDENTIALS_FILE: %v", err)
+		}
+		pushSender = fcmSender
+		utils.LogInfo("Push notifications configured", map[string]interface{}{"project_id": fcmSender.ProjectID})
+	}
+	pushService := services.NewPushNotificationService(repositories.NewPushRepository(dbConn), repositories.NewClientRepository(dbConn), pushSender)
+	for _, eventType := range services.PushEvents {
+		eventBus.Subscribe(eventType, "push_notifications", pushService.HandleEvent)
+	}
+	// Clients are reminded of their bookings by SMS if SMS_GATEWAY_URL is set, by Telegram if
+	// TELEGRAM_BOT_TOKEN is set, or by push; due reminders are looked for every BookingReminderDispatchInterval
+	var smsSender services.SMSSender
+	if gatewayURL := os.Getenv("SMS_GATEWAY_URL"); gatewayURL != "" {
+		smsSender = sms.NewHTTPGateway(gatewayURL, os.Getenv("SMS_GATEWAY_TOKEN"), os.Getenv("SMS_FROM"))
+		utils.LogInfo("SMS configured", map[string]interface{}{"gateway": gatewayURL})
+	}
+	var telegramSender services.TelegramSender
+	if botToken := os.Getenv("TELEGRAM_BOT_TOKEN"); botToken != "" {
+		telegramSender = telegram.NewBotSender(botToken)
+		utils.LogInfo("Telegram bot configured", map[string]interface{}{"api": telegram.DefaultAPIURL})
+	}
+	bookingReminderService := services.NewBookingReminderService(repositories.NewBookingReminderRepository(dbConn),
+		repositories.NewClientRepository(dbConn), pushService, smsSender, telegramSender)
+	go bookingReminderService.RunDispatch(context.Background())
+	go events.NewRelay(dbConn, repositories.NewOutboxRepository(dbConn), eventBus).Run(context.Background())
+
+	// Build the engine with all application routes
+	engine := router.New(dbConn, routerConfig)
+
+	// The gRPC API for internal integrations is optional; it shares the services and JWT secret with HTTP
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		startGRPCServer(dbConn, routerConfig.Store, grpcPort)
+	}
 
 	// Server port configuration
 	port := utils.Getenv("PORT", "8080") // Default to 8080 if not set
@@ -73,3 +312,509 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// warnMissingIndexes logs the indexes the listing and report queries expect but the
+// database lacks. They slow the queries down rather than break them, so the server starts anyway.
+func warnMissingIndexes(diagnosticsService services.DiagnosticsService) {
+	missing, err := diagnosticsService.MissingIndexes()
+	if err != nil {
+		utils.LogError(err, "Failed to check database indexes")
+		return
+	}
+	for _, index := range missing {
+		utils.LogWarn("Expected database index is missing", map[string]interface{}{"index": index.String(), "purpose": index.Purpose})
+	}
+}
+
+// startGRPCServer serves the gRPC API on the given port in the background.
+func startGRPCServer(db *sql.DB, store kvstore.Store, port string) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC on port %s: %v", port, err)
+	}
+	server := grpcapi.New(db, store)
+	utils.LogInfo("gRPC server starting", map[string]interface{}{"port": port})
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			utils.LogError(err, "gRPC server stopped")
+		}
+	}()
+}
+
+// newBackupRunner configures pg_dump backups to BACKUP_DIR and, if BACKUP_S3_BUCKET
+// is set, to S3 with the standard AWS credential variables.
+func newBackupRunner(db backup.DBConfig) *backup.Runner {
+	runner := &backup.Runner{
+		PgDumpPath: utils.Getenv("PG_DUMP_PATH", "pg_dump"),
+		DB:         db,
+		Destinations: map[string]backup.Destination{
+			models.BackupDestinationLocal: backup.LocalDestination{Dir: utils.Getenv("BACKUP_DIR", "backups")},
+		},
+	}
+	if bucket := os.Getenv("BACKUP_S3_BUCKET"); bucket != "" {
+		runner.Destinations[models.BackupDestinationS3] = backup.S3Destination{
+			Bucket:          bucket,
+			Region:          utils.Getenv("BACKUP_S3_REGION", "us-east-1"),
+			Endpoint:        os.Getenv("BACKUP_S3_ENDPOINT"),
+			Prefix:          os.Getenv("BACKUP_S3_PREFIX"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Client:          &http.Client{Timeout: time.Hour},
+		}
+	}
+	utils.LogInfo("Backups configured", map[string]interface{}{"destinations": runner.DestinationNames()})
+	return runner
+}
+
+// logDomainEvent logs every relayed domain event at debug level.
+func logDomainEvent(_ context.Context, event models.DomainEvent) error {
+	utils.LogDebug("Domain event", map[string]interface{}{
+		"event_id":     event.ID,
+		"event_type":   event.EventType,
+		"aggregate_id": event.AggregateID,
+	})
+	return nil
+}
+
+// forwardToHub pushes each relayed event to the WebSocket clients of hub on every instance,
+// with its event type as the topic.
+func forwardToHub(hub *realtime.Hub) events.Handler {
+	return func(ctx context.Context, event models.DomainEvent) error {
+		message, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return hub.Publish(ctx, event.EventType, message)
+	}
+}
+
+// loadClubTimezone configures the club timezone from the club_timezone setting,
+// falling back to the given default when the setting is missing or invalid.
+// configureEncryption sets the keys secret settings and, with ENCRYPT_CLIENT_NOTES, the notes of
+// clients are encrypted with.
+func configureEncryption() {
+	if err := utils.LoadEncryptionKeys(); err != nil {
+		log.Fatalf("Invalid data encryption key: %v", err)
+	}
+	encryptNotes := os.Getenv("ENCRYPT_CLIENT_NOTES") == "true"
+	repositories.SetClientNotesEncryption(encryptNotes)
+	if !utils.EncryptionEnabled() {
+		utils.LogInfo("DATA_ENCRYPTION_KEY is not set, storing secret settings and client notes unencrypted")
+		return
+	}
+	utils.LogInfo("Data encryption configured", map[string]interface{}{"client_notes": encryptNotes})
+}
+
+func loadClubTimezone(settingRepo repositories.SettingRepository, fallback string) {
+	tz := fallback
+	if setting, err := settingRepo.GetSettingByKey(models.SettingKeyClubTimezone); err == nil && setting.SettingValue != nil && *setting.SettingValue != "" {
+		tz = *setting.SettingValue
+	} else if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		utils.LogError(err, "Failed to load club timezone setting, using default")
+	}
+	if err := utils.SetClubTimezone(tz); err != nil {
+		utils.LogError(err, "Invalid club timezone, falling back to "+fallback)
+		if err := utils.SetClubTimezone(fallback); err != nil {
+			log.Fatalf("Invalid CLUB_TIMEZONE: %v", err)
+		}
+	}
+	utils.LogInfo("Club timezone configured", map[string]interface{}{"timezone": utils.ClubLocation().String()})
+}
+
+// loadCurrency configures the club currency from the currency setting,
+// falling back to the given default when the setting is missing or invalid.
+func loadCurrency(settingRepo repositories.SettingRepository, fallback string) {
+	value := fallback
+	if setting, err := settingRepo.GetSettingByKey(models.SettingKeyCurrency); err == nil && setting.SettingValue != nil && *setting.SettingValue != "" {
+		value = *setting.SettingValue
+	} else if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		utils.LogError(err, "Failed to load currency setting, using default")
+	}
+	currency, err := utils.ParseCurrencySetting(value)
+	if err != nil {
+		utils.LogError(err, "Invalid currency, falling back to "+fallback)
+		if currency, err = utils.ParseCurrencySetting(fallback); err != nil {
+			log.Fatalf("Invalid CURRENCY: %v", err)
+		}
+	}
+	if err := utils.SetCurrency(currency); err != nil {
+		log.Fatalf("Invalid currency: %v", err)
+	}
+	utils.LogInfo("Currency configured", map[string]interface{}{"currency": currency.Code, "decimals": currency.Decimals})
+}
+
+// loadAPIV1Sunset announces the v1 sunset date from the api_v1_sunset setting, if set.
+func loadAPIV1Sunset(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyAPIV1Sunset)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load API v1 sunset setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil || *setting.SettingValue == "" {
+		return
+	}
+	sunset, err := utils.ParseClubDate(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid API v1 sunset setting, ignoring it")
+		return
+	}
+	middleware.SetV1Sunset(&sunset)
+	utils.LogInfo("API v1 sunset announced", map[string]interface{}{"sunset": *setting.SettingValue})
+}
+
+// loadDiscountLimits applies the per-role discount limits from the discount_limits setting, if set.
+func loadDiscountLimits(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyDiscountLimits)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load discount limits setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	limits, err := models.ParseDiscountLimits(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid discount limits setting, ignoring it")
+		return
+	}
+	services.SetDiscountLimits(limits)
+	utils.LogInfo("Discount limits configured", map[string]interface{}{"roles": len(limits)})
+}
+
+// loadBookingPolicy applies the booking notice and cancellation rules from the booking_policy setting, if set.
+func loadBookingPolicy(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyBookingPolicy)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load booking policy setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	policy, err := models.ParseBookingPolicy(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid booking policy setting, ignoring it")
+		return
+	}
+	services.SetBookingPolicy(policy)
+	utils.LogInfo("Booking policy configured", map[string]interface{}{
+		"min_lead_minutes": policy.MinLeadMinutes, "free_cancellation_hours": policy.FreeCancellationHours,
+	})
+}
+
+// loadPricingRules applies the extra controller surcharges from the pricing_rules setting, if set.
+func loadPricingRules(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyPricingRules)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load pricing rules setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	rules, err := models.ParsePricingRules(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid pricing rules setting, ignoring it")
+		return
+	}
+	services.SetPricingRules(rules)
+	utils.LogInfo("Pricing rules configured", map[string]interface{}{
+		"console_types": len(rules.ExtraControllerHourly), "max_controllers": rules.MaxControllers,
+	})
+}
+
+// loadHookahSettings applies the coal change rules from the hookah_service setting, if set.
+func loadHookahSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyHookahService)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load hookah service setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseHookahSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid hookah service setting, ignoring it")
+		return
+	}
+	services.SetHookahSettings(settings)
+	utils.LogInfo("Hookah service configured", map[string]interface{}{
+		"coal_change_minutes": settings.CoalChangeMinutes, "coals_per_change": settings.CoalsPerChange,
+	})
+}
+
+// loadLostFoundSettings applies the retention from the lost_and_found setting, if set.
+func loadLostFoundSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyLostAndFound)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load lost and found setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseLostFoundSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid lost and found setting, ignoring it")
+		return
+	}
+	services.SetLostFoundSettings(settings)
+	utils.LogInfo("Lost and found configured", map[string]interface{}{"retention_days": settings.RetentionDays})
+}
+
+// loadPayloadLogging enables the payload logging of the payload_logging setting, if set.
+func loadPayloadLogging(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyPayloadLogging)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load payload logging setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParsePayloadLogging(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid payload logging setting, ignoring it")
+		return
+	}
+	if len(settings.Routes) == 0 {
+		return
+	}
+	middleware.SetPayloadLogging(settings)
+	utils.LogInfo("Payload logging enabled", map[string]interface{}{"routes": settings.Routes, "max_body_bytes": settings.MaxBodyBytes})
+}
+
+// loadTaxSettings applies the tax classes and mode from the tax setting, if set.
+func loadTaxSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyTax)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load tax setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseTaxSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid tax setting, ignoring it")
+		return
+	}
+	services.SetTaxSettings(settings)
+	utils.LogInfo("Taxes configured", map[string]interface{}{"mode": settings.Mode, "classes": len(settings.Classes)})
+}
+
+// loadReorderPointSettings applies how reorder points are calculated from the reorder_points setting, if set.
+func loadReorderPointSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyReorderPoints)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load reorder_points setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseReorderPointSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid reorder_points setting, ignoring it")
+		return
+	}
+	services.SetReorderPointSettings(settings)
+	utils.LogInfo("Reorder points configured", map[string]interface{}{"lookback_days": settings.LookbackDays, "auto_update": settings.AutoUpdate})
+}
+
+// loadAttendanceSettings applies how attendance is checked and penalized from the attendance setting, if set.
+func loadAttendanceSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyAttendance)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load attendance setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseAttendanceSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid attendance setting, ignoring it")
+		return
+	}
+	services.SetAttendanceSettings(settings)
+	utils.LogInfo("Attendance configured", map[string]interface{}{"grace_minutes": settings.GraceMinutes})
+}
+
+// loadClockInSettings applies where staff may clock in from from the clock_in setting, if set.
+func loadClockInSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyClockIn)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load clock_in setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseClockInSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid clock_in setting, ignoring it")
+		return
+	}
+	services.SetClockInSettings(settings)
+	utils.LogInfo("Clock-ins restricted", map[string]interface{}{"allowed_ips": len(settings.AllowedIPs), "radius_meters": settings.RadiusMeters})
+}
+
+// loadServiceChargeSettings applies the service charge of the service_charge setting, if set.
+func loadServiceChargeSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyServiceCharge)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load service_charge setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseServiceChargeSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid service_charge setting, ignoring it")
+		return
+	}
+	services.SetServiceChargeSettings(settings)
+	utils.LogInfo("Service charge configured", map[string]interface{}{"percent": settings.Percent.String(), "min_guests": settings.MinGuests})
+}
+
+// loadOrderArchiveSettings applies the retention of the order_archive setting, if set.
+func loadOrderArchiveSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyOrderArchive)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load order_archive setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseOrderArchiveSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid order_archive setting, ignoring it")
+		return
+	}
+	services.SetOrderArchiveSettings(settings)
+	utils.LogInfo("Order archival configured", map[string]interface{}{"retention_months": settings.RetentionMonths})
+}
+
+// loadAnomalySettings applies the anomaly_detection setting, if set.
+func loadAnomalySettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyAnomalyDetection)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load anomaly_detection setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseAnomalySettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid anomaly_detection setting, ignoring it")
+		return
+	}
+	services.SetAnomalySettings(settings)
+	utils.LogInfo("Anomaly detection configured", map[string]interface{}{"threshold_percent": settings.ThresholdPercent, "trailing_days": settings.TrailingDays})
+}
+
+// loadPushSettings applies the push_notifications setting, if set.
+func loadPushSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyPushNotifications)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load push_notifications setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParsePushSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid push_notifications setting, ignoring it")
+		return
+	}
+	services.SetPushSettings(settings)
+	utils.LogInfo("Push notifications set up", map[string]interface{}{"loyalty_milestones": settings.LoyaltyMilestones})
+}
+
+// loadBookingReminderSettings applies the booking_reminders setting, if set.
+func loadBookingReminderSettings(settingRepo repositories.SettingRepository) {
+	setting, err := settingRepo.GetSettingByKey(models.SettingKeyBookingReminders)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, "Failed to load booking_reminders setting")
+		}
+		return
+	}
+	if setting.SettingValue == nil {
+		return
+	}
+	settings, err := models.ParseBookingReminderSettings(*setting.SettingValue)
+	if err != nil {
+		utils.LogError(err, "Invalid booking_reminders setting, ignoring it")
+		return
+	}
+	services.SetBookingReminderSettings(settings)
+	utils.LogInfo("Booking reminders configured", map[string]interface{}{"channel": settings.Channel, "lead_minutes": settings.LeadMinutes})
+}
+
+// loadErrorReporting reports panics and server errors to the DSN of the sentry_dsn setting,
+// falling back to the given default when the setting is missing.
+func loadErrorReporting(settingRepo repositories.SettingRepository, fallback string) {
+	dsn := fallback
+	if setting, err := settingRepo.GetSettingByKey(models.SettingKeySentryDSN); err == nil && setting.SettingValue != nil {
+		dsn = *setting.SettingValue
+	} else if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		utils.LogError(err, "Failed to load error reporting setting")
+	}
+	if err := apperrors.Configure(dsn); err != nil {
+		utils.LogError(err, "Invalid error reporting DSN, not reporting errors")
+		return
+	}
+	if apperrors.Enabled() {
+		utils.LogInfo("Error reporting enabled")
+	}
+}
+
+// logSetupRequired points to the setup endpoint when the database has no users yet.
+func logSetupRequired(setupRepo repositories.SetupRepository) {
+	status, err := setupRepo.GetSetupStatus()
+	if err != nil {
+		utils.LogError(err, "Failed to check whether the initial setup is required")
+		return
+	}
+	if status.SetupRequired {
+		utils.LogInfo("Database is empty, create the first Admin with POST /api/v1/setup")
+	}
+}
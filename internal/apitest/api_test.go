@@ -0,0 +1,260 @@
+package apitest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/apitest"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// errorCode returns the code of an error response.
+func errorCode(t *testing.T, resp *apitest.Response) string {
+	t.Helper()
+	var body struct {
+		Error utils.APIError `json:"error"`
+	}
+	resp.Decode(t, &body)
+	return body.Error.Code
+}
+
+// expectStatus fails the test unless resp has the wanted status.
+func expectStatus(t *testing.T, resp *apitest.Response, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		t.Fatalf("status = %d, want %d: %s", resp.StatusCode, want, resp.Body)
+	}
+}
+
+// createStaffMember adds a staff member for the user and returns its ID.
+func createStaffMember(t *testing.T, srv *apitest.Server, userID int64) int64 {
+	t.Helper()
+	var id int64
+	if err := srv.DB.QueryRow(`INSERT INTO staff_members (user_id, position) VALUES ($1, 'Cashier') RETURNING id`, userID).Scan(&id); err != nil {
+		t.Fatalf("creating staff member: %v", err)
+	}
+	return id
+}
+
+// createStockedItem creates a BAR item priced 500 that tracks stock.
+func createStockedItem(t *testing.T, srv *apitest.Server, name string, stock int) int64 {
+	t.Helper()
+	repo := repositories.NewPricelistRepository(srv.DB)
+	categoryID, err := repo.CreateCategory(srv.DB, &models.PricelistCategory{Name: name + " category"})
+	if err != nil {
+		t.Fatalf("creating category: %v", err)
+	}
+	quantity := models.NewQuantityFromInt(stock)
+	id, err := repo.CreateItem(srv.DB, &models.PricelistItem{
+		CategoryID: categoryID, Name: name, Price: models.NewMoneyFromInt(500), IsAvailable: true,
+		ItemType: models.ItemTypeBar, TracksStock: true, CurrentStock: &quantity, ItemUnits: models.DefaultItemUnits(),
+	})
+	if err != nil {
+		t.Fatalf("creating item %s: %v", name, err)
+	}
+	return id
+}
+
+// createGameTable creates an available hourly game table.
+func createGameTable(t *testing.T, srv *apitest.Server, name string) int64 {
+	t.Helper()
+	rate := models.NewMoneyFromInt(2000)
+	table := &models.GameTable{
+		Name: name, Status: models.TableStatusAvailable, HourlyRate: &rate,
+		BaseControllers: models.DefaultBaseControllers, BillingMode: models.BillingModeHourly, BillingIncrementMinutes: 5,
+	}
+	if err := repositories.NewGameTableRepository(srv.DB).CreateGameTable(table); err != nil {
+		t.Fatalf("creating game table %s: %v", name, err)
+	}
+	return table.ID
+}
+
+func TestAuthFlow(t *testing.T) {
+	srv := apitest.NewServer(t)
+	srv.CreateUser(t, "cashier", "s3cret-pass", apitest.RoleStaff)
+
+	t.Run("rejects a wrong password", func(t *testing.T) {
+		resp := srv.Do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "cashier", "password": "wrong-pass"})
+		expectStatus(t, resp, http.StatusUnauthorized)
+	})
+	t.Run("rejects an unknown user", func(t *testing.T) {
+		resp := srv.Do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "nobody", "password": "s3cret-pass"})
+		expectStatus(t, resp, http.StatusUnauthorized)
+	})
+	t.Run("rejects a login without a password", func(t *testing.T) {
+		resp := srv.Do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "cashier"})
+		expectStatus(t, resp, http.StatusBadRequest)
+	})
+	t.Run("requires a token", func(t *testing.T) {
+		expectStatus(t, srv.Do(t, http.MethodGet, "/api/v1/auth/me", "", nil), http.StatusUnauthorized)
+		expectStatus(t, srv.Do(t, http.MethodGet, "/api/v1/auth/me", "not-a-token", nil), http.StatusUnauthorized)
+	})
+
+	resp := srv.Do(t, http.MethodPost, "/api/v1/auth/login", "", map[string]string{"username": "cashier", "password": "s3cret-pass"})
+	expectStatus(t, resp, http.StatusOK)
+	var auth struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	resp.Decode(t, &auth)
+	if auth.AccessToken == "" || auth.RefreshToken == "" {
+		t.Fatalf("login returned no tokens: %s", resp.Body)
+	}
+
+	resp = srv.Do(t, http.MethodGet, "/api/v1/auth/me", auth.AccessToken, nil)
+	expectStatus(t, resp, http.StatusOK)
+	var me models.User
+	resp.Decode(t, &me)
+	if me.Username != "cashier" {
+		t.Errorf("/auth/me username = %q, want %q", me.Username, "cashier")
+	}
+
+	// A refresh token is good for one exchange
+	resp = srv.Do(t, http.MethodPost, "/api/v1/auth/refresh-token", "", map[string]string{"refresh_token": auth.RefreshToken})
+	expectStatus(t, resp, http.StatusOK)
+	var refreshed struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	resp.Decode(t, &refreshed)
+	expectStatus(t, srv.Do(t, http.MethodGet, "/api/v1/auth/me", refreshed.AccessToken, nil), http.StatusOK)
+	resp = srv.Do(t, http.MethodPost, "/api/v1/auth/refresh-token", "", map[string]string{"refresh_token": auth.RefreshToken})
+	expectStatus(t, resp, http.StatusUnauthorized)
+
+	// Logging out revokes the refresh token sent with it
+	resp = srv.Do(t, http.MethodPost, "/api/v1/auth/logout", refreshed.AccessToken, map[string]string{"refresh_token": refreshed.RefreshToken})
+	expectStatus(t, resp, http.StatusOK)
+	resp = srv.Do(t, http.MethodPost, "/api/v1/auth/refresh-token", "", map[string]string{"refresh_token": refreshed.RefreshToken})
+	expectStatus(t, resp, http.StatusUnauthorized)
+}
+
+func TestCreateOrderDeductsStock(t *testing.T) {
+	srv := apitest.NewServer(t)
+	userID := srv.CreateUser(t, "cashier", "s3cret-pass", apitest.RoleStaff)
+	staffID := createStaffMember(t, srv, userID)
+	token := srv.Login(t, "cashier", "s3cret-pass")
+	itemID := createStockedItem(t, srv, "Cola", 5)
+
+	order := func(quantity int) map[string]interface{} {
+		return map[string]interface{}{
+			"staff_id": staffID, "status": "pending",
+			"order_items": []map[string]interface{}{{"pricelist_item_id": itemID, "quantity": quantity}},
+		}
+	}
+
+	resp := srv.Do(t, http.MethodPost, "/api/v1/orders", token, order(3))
+	expectStatus(t, resp, http.StatusCreated)
+	var created models.Order
+	resp.Decode(t, &created)
+	if want := models.NewMoneyFromInt(1500); created.TotalAmount.Cmp(want) != 0 {
+		t.Errorf("total_amount = %s, want %s", created.TotalAmount, want)
+	}
+	assertStock(t, srv, itemID, 2)
+
+	t.Run("rejects more than is in stock", func(t *testing.T) {
+		resp := srv.Do(t, http.MethodPost, "/api/v1/orders", token, order(3))
+		expectStatus(t, resp, http.StatusConflict)
+		if code := errorCode(t, resp); code != utils.ErrCodeConflict {
+			t.Errorf("error code = %q, want %q", code, utils.ErrCodeConflict)
+		}
+		assertStock(t, srv, itemID, 2)
+	})
+	t.Run("rejects an unknown item", func(t *testing.T) {
+		body := order(1)
+		body["order_items"] = []map[string]interface{}{{"pricelist_item_id": itemID + 100, "quantity": 1}}
+		expectStatus(t, srv.Do(t, http.MethodPost, "/api/v1/orders", token, body), http.StatusNotFound)
+	})
+	t.Run("rejects a zero quantity", func(t *testing.T) {
+		expectStatus(t, srv.Do(t, http.MethodPost, "/api/v1/orders", token, order(0)), http.StatusBadRequest)
+		assertStock(t, srv, itemID, 2)
+	})
+}
+
+// assertStock checks the stock of the item left after the orders.
+func assertStock(t *testing.T, srv *apitest.Server, itemID int64, want int) {
+	t.Helper()
+	_, stock, _, _, err := repositories.NewPricelistRepository(srv.DB).GetItemPriceAndStock(itemID)
+	if err != nil {
+		t.Fatalf("reading stock: %v", err)
+	}
+	if w := models.NewQuantityFromInt(want); stock == nil || stock.Cmp(w) != 0 {
+		t.Errorf("stock = %v, want %s", stock, w)
+	}
+}
+
+func TestCreateBookingRejectsConflicts(t *testing.T) {
+	srv := apitest.NewServer(t)
+	userID := srv.CreateUser(t, "cashier", "s3cret-pass", apitest.RoleStaff)
+	staffID := createStaffMember(t, srv, userID)
+	token := srv.Login(t, "cashier", "s3cret-pass")
+	tableID, otherTableID := createGameTable(t, srv, "PS5 #1"), createGameTable(t, srv, "PS5 #2")
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour).UTC()
+	booking := func(tableID int64, from, to time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"table_id": tableID, "staff_id": staffID,
+			"start_time": from.Format(time.RFC3339), "end_time": to.Format(time.RFC3339),
+		}
+	}
+	expectStatus(t, srv.Do(t, http.MethodPost, "/api/v1/bookings", token, booking(tableID, start, start.Add(2*time.Hour))), http.StatusCreated)
+
+	tests := []struct {
+		name     string
+		tableID  int64
+		from, to time.Time
+		want     int
+	}{
+		{name: "rejects an overlapping booking", tableID: tableID, from: start.Add(time.Hour), to: start.Add(3 * time.Hour), want: http.StatusConflict},
+		{name: "rejects a booking inside another", tableID: tableID, from: start.Add(30 * time.Minute), to: start.Add(90 * time.Minute), want: http.StatusConflict},
+		{name: "rejects an end before the start", tableID: tableID, from: start.Add(6 * time.Hour), to: start.Add(5 * time.Hour), want: http.StatusBadRequest},
+		{name: "takes the same time on another table", tableID: otherTableID, from: start, to: start.Add(2 * time.Hour), want: http.StatusCreated},
+		{name: "takes a later time", tableID: tableID, from: start.Add(4 * time.Hour), to: start.Add(5 * time.Hour), want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := srv.Do(t, http.MethodPost, "/api/v1/bookings", token, booking(tt.tableID, tt.from, tt.to))
+			expectStatus(t, resp, tt.want)
+			if tt.want == http.StatusConflict {
+				if code := errorCode(t, resp); code != utils.ErrCodeConflict {
+					t.Errorf("error code = %q, want %q", code, utils.ErrCodeConflict)
+				}
+			}
+		})
+	}
+}
+
+func TestRoleAccess(t *testing.T) {
+	srv := apitest.NewServer(t)
+	srv.CreateUser(t, "owner", "s3cret-pass", apitest.RoleAdmin)
+	srv.CreateUser(t, "cashier", "s3cret-pass", apitest.RoleStaff)
+	srv.CreateUser(t, "guest", "s3cret-pass", apitest.RoleClient)
+	tokens := map[string]string{
+		"Admin":  srv.Login(t, "owner", "s3cret-pass"),
+		"Staff":  srv.Login(t, "cashier", "s3cret-pass"),
+		"Client": srv.Login(t, "guest", "s3cret-pass"),
+	}
+
+	tests := []struct {
+		role         string
+		method, path string
+		want         int
+	}{
+		{role: "Client", method: http.MethodGet, path: "/api/v1/orders", want: http.StatusForbidden},
+		{role: "Client", method: http.MethodGet, path: "/api/v1/bookings", want: http.StatusForbidden},
+		{role: "Client", method: http.MethodGet, path: "/api/v1/staff", want: http.StatusForbidden},
+		{role: "Staff", method: http.MethodGet, path: "/api/v1/orders/export", want: http.StatusForbidden},
+		{role: "Staff", method: http.MethodPost, path: "/api/v1/staff", want: http.StatusForbidden},
+		{role: "Staff", method: http.MethodPost, path: "/api/v1/bookings/1/restore", want: http.StatusForbidden},
+		{role: "Staff", method: http.MethodGet, path: "/api/v1/orders", want: http.StatusOK},
+		{role: "Admin", method: http.MethodGet, path: "/api/v1/staff", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s %s", tt.role, tt.method, tt.path), func(t *testing.T) {
+			expectStatus(t, srv.Do(t, tt.method, tt.path, tokens[tt.role], nil), tt.want)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package backup dumps the database with pg_dump and stores the dump on local
+// disk or in an S3 bucket. services.BackupService runs it in the background and
+// records each backup in the backups table.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxErrorOutput is how much of pg_dump's stderr is kept in a failed backup's error.
+const maxErrorOutput = 2000
+
+// DBConfig holds the connection parameters passed to pg_dump.
+type DBConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+}
+
+// Destination stores finished dumps.
+type Destination interface {
+	// Store saves the file at path under name and returns where it was stored.
+	Store(ctx context.Context, name, path string) (location string, err error)
+}
+
+// Runner takes backups with pg_dump.
+type Runner struct {
+	PgDumpPath   string // pg_dump binary; "pg_dump" looks it up in PATH
+	DB           DBConfig
+	Destinations map[string]Destination // By name, e.g. "local" and "s3"
+}
+
+// DestinationNames returns the names of the configured destinations, sorted.
+func (r *Runner) DestinationNames() []string {
+	names := make([]string, 0, len(r.Destinations))
+	for name := range r.Destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run dumps the database in pg_dump's custom format and stores the dump in the
+// named destination. It returns the location and size of the stored dump.
+func (r *Runner) Run(ctx context.Context, destination string) (string, int64, error) {
+	dest, ok := r.Destinations[destination]
+	if !ok {
+		return "", 0, fmt.Errorf("backup destination %q is not configured", destination)
+	}
+
+	tmp, err := os.CreateTemp("", "ps_club-backup-*.dump")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary dump file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	pgDump := r.PgDumpPath
+	if pgDump == "" {
+		pgDump = "pg_dump"
+	}
+	cmd := exec.CommandContext(ctx, pgDump,
+		"--format=custom", "--no-owner", "--no-privileges",
+		"--host", r.DB.Host, "--port", r.DB.Port, "--username", r.DB.User,
+		"--file", tmpPath, r.DB.Name,
+	)
+	// The password and SSL mode are passed via the environment, so they do not show up in the process list
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+r.DB.Password, "PGSSLMODE="+r.DB.SSLMode)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxErrorOutput {
+			output = output[:maxErrorOutput]
+		}
+		if output != "" {
+			return "", 0, fmt.Errorf("pg_dump failed: %w: %s", err, output)
+		}
+		return "", 0, fmt.Errorf("pg_dump failed: %w", err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to stat dump file: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.dump", r.DB.Name, time.Now().UTC().Format("20060102T150405Z"))
+	location, err := dest.Store(ctx, name, tmpPath)
+	if err != nil {
+		return "", 0, err
+	}
+	return location, info.Size(), nil
+}
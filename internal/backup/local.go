@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalDestination stores dumps in a directory on the server's disk.
+type LocalDestination struct {
+	Dir string
+}
+
+// Store moves the dump into Dir, creating it if needed, and returns the absolute file path.
+func (d LocalDestination) Store(_ context.Context, name, path string) (string, error) {
+	if err := os.MkdirAll(d.Dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	target, err := filepath.Abs(filepath.Join(d.Dir, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve backup path: %w", err)
+	}
+	// Rename fails across file systems (e.g. a tmpfs /tmp), so fall back to copying
+	if err := os.Rename(path, target); err == nil {
+		return target, nil
+	}
+	if err := copyFile(path, target); err != nil {
+		os.Remove(target)
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return target, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
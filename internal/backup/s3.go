@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Destination uploads dumps to an S3 bucket, or to an S3-compatible store when
+// Endpoint is set. Requests are signed with AWS Signature Version 4.
+type S3Destination struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // e.g. "https://minio.local:9000"; empty uses AWS. Custom endpoints use path-style URLs
+	Prefix          string // Key prefix, e.g. "ps_club/"
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // For temporary credentials; optional
+	Client          *http.Client
+}
+
+// Store uploads the dump with a single PUT and returns its s3:// URI.
+func (d S3Destination) Store(ctx context.Context, name, path string) (string, error) {
+	key := d.Prefix + name
+	payloadHash, size, err := fileSHA256(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash dump file: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open dump file: %w", err)
+	}
+	defer f.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.objectURL(key), f)
+	if err != nil {
+		return "", fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	d.sign(req, payloadHash, time.Now().UTC())
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload backup to S3: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorOutput))
+		return "", fmt.Errorf("S3 upload failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return fmt.Sprintf("s3://%s/%s", d.Bucket, key), nil
+}
+
+// objectURL returns the virtual-hosted-style URL on AWS and the path-style URL on a custom endpoint.
+func (d S3Destination) objectURL(key string) string {
+	escapedKey := escapePath(key)
+	if d.Endpoint != "" {
+		return strings.TrimRight(d.Endpoint, "/") + "/" + d.Bucket + "/" + escapedKey
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", d.Bucket, d.Region, escapedKey)
+}
+
+// sign adds the AWS Signature Version 4 headers to req.
+func (d S3Destination) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if d.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", d.SessionToken)
+	}
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if d.SessionToken != "" {
+		headers["x-amz-security-token"] = d.SessionToken
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + headers[h] + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	scope := dateStamp + "/" + d.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+d.SecretAccessKey), dateStamp)
+	key = hmacSHA256(key, d.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		d.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// escapePath URI-encodes each segment of an object key, keeping the slashes.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -13,7 +13,9 @@ var DB *sql.DB
 
 // InitDB initializes the database connection
 func InitDB(host, port, user, password, dbname, sslmode, dbSchemaPath string) {
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+	// Sessions run in UTC so timestamps are stored and returned normalized; the club
+	// timezone is applied in the application when parsing inputs and bucketing reports.
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
 		host, port, user, password, dbname, sslmode)
 
 	var err error
@@ -0,0 +1,112 @@
+// Package dbtest provides a migrated PostgreSQL database for integration tests.
+//
+// By default a throwaway PostgreSQL container is started with testcontainers
+// (Docker must be available). Set TEST_DATABASE_URL to run against an existing
+// server instead; its schema is migrated but the data is not cleaned up.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/database"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// PostgresImage is the image used for test containers.
+const PostgresImage = "postgres:16-alpine"
+
+// New returns a connection to a fresh, fully migrated database. The container
+// and connection are released when the test finishes. Integration tests are
+// skipped with -short, and when neither TEST_DATABASE_URL nor Docker is available.
+func New(tb testing.TB) *sql.DB {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("skipping database integration test in short mode")
+	}
+
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		skipWithoutDocker(tb)
+		connStr = startContainer(tb)
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		tb.Fatalf("dbtest: opening database: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		tb.Fatalf("dbtest: connecting to database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		tb.Fatalf("dbtest: running migrations: %v", err)
+	}
+	return db
+}
+
+// skipWithoutDocker skips the test when there is no healthy Docker daemon to start
+// the container on. testcontainers panics when it cannot find one.
+func skipWithoutDocker(tb testing.TB) {
+	tb.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			tb.Skipf("dbtest: Docker is not available and TEST_DATABASE_URL is not set: %v", r)
+		}
+	}()
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err == nil {
+		defer provider.Close()
+		err = provider.Health(context.Background())
+	}
+	if err != nil {
+		tb.Skipf("dbtest: Docker is not available and TEST_DATABASE_URL is not set: %v", err)
+	}
+}
+
+func startContainer(tb testing.TB) string {
+	tb.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, err := postgres.Run(ctx, PostgresImage,
+		postgres.WithDatabase("ps_club_test"),
+		postgres.WithUsername("ps_club_test"),
+		postgres.WithPassword("ps_club_test"),
+		postgres.BasicWaitStrategies(),
+	)
+	if container != nil {
+		tb.Cleanup(func() {
+			if err := testcontainers.TerminateContainer(container); err != nil {
+				tb.Logf("dbtest: terminating container: %v", err)
+			}
+		})
+	}
+	if err != nil {
+		tb.Fatalf("dbtest: starting PostgreSQL container (is Docker running?): %v", err)
+	}
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable", "timezone=UTC")
+	if err != nil {
+		tb.Fatalf("dbtest: getting connection string: %v", err)
+	}
+	return connStr
+}
+
+// Truncate empties the given tables (and everything referencing them) and resets
+// their identity sequences, so tests sharing a database start from a clean state.
+func Truncate(tb testing.TB, db *sql.DB, tables ...string) {
+	tb.Helper()
+	if len(tables) == 0 {
+		return
+	}
+	if _, err := db.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE"); err != nil {
+		tb.Fatalf("dbtest: truncating %v: %v", tables, err)
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// RunMigrations applies all embedded migrations that have not been applied yet.
+// Migrations are plain SQL files named "<version>_<description>.sql" and are
+// applied in lexical order, each in its own transaction. Applied versions are
+// recorded in the schema_migrations table.
+func RunMigrations(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+
+	applied := map[string]bool{}
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("reading applied migrations: %w", err)
+	}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("listing migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+		if applied[version] {
+			continue
+		}
+		content, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("reading migration %s: %w", version, err)
+		}
+		if err := applyMigration(db, version, string(content)); err != nil {
+			return err
+		}
+		fmt.Printf("Applied migration %s\n", version)
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, version, content string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting transaction for migration %s: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(content); err != nil {
+		return fmt.Errorf("applying migration %s: %w", version, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return fmt.Errorf("recording migration %s: %w", version, err)
+	}
+	return tx.Commit()
+}
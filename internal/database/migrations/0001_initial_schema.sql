@@ -0,0 +1,190 @@
+-- Baseline schema. Every statement is idempotent so databases whose schema was
+-- applied manually before migrations existed are picked up without changes.
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO roles (id, name, description) VALUES
+    (1, 'Admin', 'Full access'),
+    (2, 'Staff', 'Club staff'),
+    (3, 'Client', 'Club client')
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('roles', 'id'), GREATEST((SELECT MAX(id) FROM roles), 1));
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id       BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGSERIAL PRIMARY KEY,
+    username      VARCHAR(100) NOT NULL,
+    password_hash TEXT NOT NULL,
+    email         VARCHAR(255),
+    full_name     VARCHAR(255),
+    role_id       BIGINT REFERENCES roles(id),
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT users_username_key UNIQUE (username),
+    CONSTRAINT users_email_key UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS staff_members (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT,
+    phone_number VARCHAR(50),
+    address      TEXT,
+    hire_date    DATE,
+    position     VARCHAR(100),
+    salary       NUMERIC(12, 2),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT staff_members_user_id_key UNIQUE (user_id),
+    CONSTRAINT staff_members_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS shifts (
+    id         BIGSERIAL PRIMARY KEY,
+    staff_id   BIGINT NOT NULL REFERENCES staff_members(id),
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time   TIMESTAMPTZ NOT NULL,
+    notes      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS clients (
+    id             BIGSERIAL PRIMARY KEY,
+    full_name      VARCHAR(255) NOT NULL,
+    phone_number   VARCHAR(50),
+    email          VARCHAR(255),
+    date_of_birth  DATE,
+    loyalty_points INTEGER DEFAULT 0,
+    notes          TEXT,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT clients_phone_number_key UNIQUE (phone_number),
+    CONSTRAINT clients_email_key UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS pricelist_categories (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS pricelist_items (
+    id                  BIGSERIAL PRIMARY KEY,
+    category_id         BIGINT NOT NULL,
+    name                VARCHAR(255) NOT NULL,
+    description         TEXT,
+    price               NUMERIC(12, 2) NOT NULL,
+    sku                 VARCHAR(100) UNIQUE,
+    is_available        BOOLEAN NOT NULL DEFAULT TRUE,
+    item_type           VARCHAR(50) NOT NULL,
+    tracks_stock        BOOLEAN NOT NULL DEFAULT FALSE,
+    current_stock       INTEGER,
+    low_stock_threshold INTEGER,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pricelist_items_category_id_fkey FOREIGN KEY (category_id) REFERENCES pricelist_categories(id)
+);
+
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id                BIGSERIAL PRIMARY KEY,
+    pricelist_item_id BIGINT NOT NULL REFERENCES pricelist_items(id),
+    staff_id          BIGINT REFERENCES staff_members(id),
+    movement_type     VARCHAR(50) NOT NULL,
+    quantity_changed  INTEGER NOT NULL,
+    reason            TEXT,
+    movement_date     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS game_tables (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    status      VARCHAR(50) NOT NULL DEFAULT 'available',
+    capacity    INTEGER,
+    hourly_rate NUMERIC(12, 2),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id               BIGSERIAL PRIMARY KEY,
+    client_id        BIGINT REFERENCES clients(id),
+    table_id         BIGINT NOT NULL REFERENCES game_tables(id),
+    staff_id         BIGINT REFERENCES staff_members(id),
+    start_time       TIMESTAMPTZ NOT NULL,
+    end_time         TIMESTAMPTZ NOT NULL,
+    number_of_guests INTEGER,
+    status           VARCHAR(50) NOT NULL DEFAULT 'confirmed',
+    notes            TEXT,
+    total_price      NUMERIC(12, 2),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id              BIGSERIAL PRIMARY KEY,
+    client_id       BIGINT REFERENCES clients(id),
+    booking_id      BIGINT REFERENCES bookings(id),
+    staff_id        BIGINT REFERENCES staff_members(id),
+    table_id        BIGINT REFERENCES game_tables(id),
+    order_time      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status          VARCHAR(50) NOT NULL,
+    total_amount    NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    discount_amount NUMERIC(12, 2),
+    final_amount    NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    payment_method  VARCHAR(50),
+    notes           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id                BIGSERIAL PRIMARY KEY,
+    order_id          BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    pricelist_item_id BIGINT NOT NULL REFERENCES pricelist_items(id),
+    quantity          INTEGER NOT NULL,
+    unit_price        NUMERIC(12, 2) NOT NULL,
+    total_price       NUMERIC(12, 2) NOT NULL,
+    notes             TEXT,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS application_settings (
+    id            BIGSERIAL PRIMARY KEY,
+    setting_key   VARCHAR(100) NOT NULL UNIQUE,
+    setting_value TEXT,
+    description   TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_table_time ON bookings (table_id, start_time, end_time);
+CREATE INDEX IF NOT EXISTS idx_orders_order_time ON orders (order_time);
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_item ON inventory_movements (pricelist_item_id);
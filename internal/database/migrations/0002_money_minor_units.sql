@@ -0,0 +1,18 @@
+-- Store monetary amounts as integer minor units (e.g. tiyn) instead of
+-- fractional values. Existing values are assumed to be in major units of a
+-- currency with 2 decimals (the default KZT) and are rounded half away from zero.
+
+ALTER TABLE pricelist_items ALTER COLUMN price TYPE BIGINT USING ROUND(price::NUMERIC * 100)::BIGINT;
+
+ALTER TABLE orders
+    ALTER COLUMN total_amount TYPE BIGINT USING ROUND(total_amount::NUMERIC * 100)::BIGINT,
+    ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount::NUMERIC * 100)::BIGINT,
+    ALTER COLUMN final_amount TYPE BIGINT USING ROUND(final_amount::NUMERIC * 100)::BIGINT;
+
+ALTER TABLE order_items
+    ALTER COLUMN unit_price TYPE BIGINT USING ROUND(unit_price::NUMERIC * 100)::BIGINT,
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price::NUMERIC * 100)::BIGINT;
+
+ALTER TABLE game_tables ALTER COLUMN hourly_rate TYPE BIGINT USING ROUND(hourly_rate::NUMERIC * 100)::BIGINT;
+ALTER TABLE bookings ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price::NUMERIC * 100)::BIGINT;
+ALTER TABLE staff_members ALTER COLUMN salary TYPE BIGINT USING ROUND(salary::NUMERIC * 100)::BIGINT;
@@ -0,0 +1,18 @@
+-- Store monetary amounts as exact NUMERIC values in major units, replacing the
+-- integer minor units from 0002. Scale 4 covers every supported currency; the
+-- application rounds amounts to the configured currency decimals.
+
+ALTER TABLE pricelist_items ALTER COLUMN price TYPE NUMERIC(18, 4) USING price::NUMERIC / 100;
+
+ALTER TABLE orders
+    ALTER COLUMN total_amount TYPE NUMERIC(18, 4) USING total_amount::NUMERIC / 100,
+    ALTER COLUMN discount_amount TYPE NUMERIC(18, 4) USING discount_amount::NUMERIC / 100,
+    ALTER COLUMN final_amount TYPE NUMERIC(18, 4) USING final_amount::NUMERIC / 100;
+
+ALTER TABLE order_items
+    ALTER COLUMN unit_price TYPE NUMERIC(18, 4) USING unit_price::NUMERIC / 100,
+    ALTER COLUMN total_price TYPE NUMERIC(18, 4) USING total_price::NUMERIC / 100;
+
+ALTER TABLE game_tables ALTER COLUMN hourly_rate TYPE NUMERIC(18, 4) USING hourly_rate::NUMERIC / 100;
+ALTER TABLE bookings ALTER COLUMN total_price TYPE NUMERIC(18, 4) USING total_price::NUMERIC / 100;
+ALTER TABLE staff_members ALTER COLUMN salary TYPE NUMERIC(18, 4) USING salary::NUMERIC / 100;
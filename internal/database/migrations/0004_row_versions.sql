@@ -0,0 +1,6 @@
+-- Row versions for optimistic locking. Every update of these rows increments
+-- version; updates carrying a stale version are rejected with 409 Conflict.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE pricelist_items ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE application_settings ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
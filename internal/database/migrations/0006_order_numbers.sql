@@ -0,0 +1,33 @@
+-- Human-friendly order numbers: a sequence per branch and business day (club-local
+-- date of the order), displayed as e.g. "2024-06-01/#37". order_number_sequences
+-- holds the last number issued; it is incremented atomically with an upsert in the
+-- transaction that creates the order.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS branch_code VARCHAR(20) NOT NULL DEFAULT 'main';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS business_date DATE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS daily_number INTEGER;
+
+-- Existing orders are numbered by their UTC date, as the club timezone is not known here.
+UPDATE orders o
+SET business_date = n.business_date, daily_number = n.daily_number
+FROM (
+    SELECT id,
+           (order_time AT TIME ZONE 'UTC')::date AS business_date,
+           ROW_NUMBER() OVER (PARTITION BY branch_code, (order_time AT TIME ZONE 'UTC')::date ORDER BY order_time, id) AS daily_number
+    FROM orders
+) n
+WHERE o.id = n.id AND o.daily_number IS NULL;
+
+ALTER TABLE orders ALTER COLUMN business_date SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN daily_number SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_daily_number ON orders (branch_code, business_date, daily_number);
+
+CREATE TABLE IF NOT EXISTS order_number_sequences (
+    branch_code VARCHAR(20) NOT NULL,
+    business_date DATE NOT NULL,
+    last_number INTEGER NOT NULL,
+    PRIMARY KEY (branch_code, business_date)
+);
+
+INSERT INTO order_number_sequences (branch_code, business_date, last_number)
+SELECT branch_code, business_date, MAX(daily_number) FROM orders GROUP BY branch_code, business_date
+ON CONFLICT (branch_code, business_date) DO NOTHING;
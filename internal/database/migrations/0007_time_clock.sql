@@ -0,0 +1,13 @@
+-- Actual working time, recorded when staff clock in and out (shifts are the schedule).
+-- A staff member has at most one open entry, i.e. one without clock_out_at.
+CREATE TABLE IF NOT EXISTS time_clock_entries (
+    id BIGSERIAL PRIMARY KEY,
+    staff_id BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    clock_in_at TIMESTAMPTZ NOT NULL,
+    clock_out_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_clock_entries_open ON time_clock_entries (staff_id) WHERE clock_out_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_time_clock_entries_staff_clock_in ON time_clock_entries (staff_id, clock_in_at);
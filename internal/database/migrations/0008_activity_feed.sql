@@ -0,0 +1,2 @@
+-- The dashboard activity feed reads recent outbox events of a few types, newest first.
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type_created_at ON outbox_events (event_type, created_at DESC, id DESC);
@@ -0,0 +1,21 @@
+-- Manager approval of sensitive actions (large discounts, refunds, order deletions).
+-- Staff requests create a pending approval; an Admin approves it, which runs the
+-- stored action, or authorizes the request up front with their approval PIN.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS approval_pin_hash TEXT;
+
+CREATE TABLE IF NOT EXISTS approvals (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    target_id BIGINT,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    reason TEXT,
+    result_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_approvals_status_created_at ON approvals (status, created_at DESC);
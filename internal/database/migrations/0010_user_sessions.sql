@@ -0,0 +1,17 @@
+-- Logged-in devices. A session is created at login and lives as long as its
+-- refresh token chain; refresh_token_id is the ID (jti) of the current refresh
+-- token, replaced on every refresh. Revoking a session ends it on that device.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_id VARCHAR(64) NOT NULL UNIQUE,
+    device_name VARCHAR(255),
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_active_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_active ON user_sessions (user_id, last_active_at DESC) WHERE revoked_at IS NULL;
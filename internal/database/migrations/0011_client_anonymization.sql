@@ -0,0 +1,3 @@
+-- Set when a client's personal data was scrubbed on request. The row stays so
+-- that bookings and orders, and thus the sales figures, remain intact.
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
@@ -0,0 +1,16 @@
+-- Database backups taken with pg_dump, on request of an Admin or by the nightly
+-- schedule (the backup_schedule setting). location is the file path or s3:// URI.
+CREATE TABLE IF NOT EXISTS backups (
+    id BIGSERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    destination VARCHAR(20) NOT NULL,
+    location TEXT,
+    size_bytes BIGINT,
+    error TEXT,
+    requested_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_backups_started_at ON backups (started_at DESC);
@@ -0,0 +1,2 @@
+-- Language of API messages for the user ("en", "ru" or "kk"); NULL follows Accept-Language.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(10);
@@ -0,0 +1,6 @@
+-- Cursor pagination reads lists newest first by (timestamp, id); these indexes serve it without sorting.
+CREATE INDEX IF NOT EXISTS idx_orders_order_time_id ON orders (order_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_bookings_start_time_id ON bookings (start_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_date_id ON inventory_movements (movement_date DESC, id DESC);
+-- Superseded by idx_orders_order_time_id, which also serves order_time ranges.
+DROP INDEX IF EXISTS idx_orders_order_time;
@@ -0,0 +1,2 @@
+-- Order lists filtered by status (e.g. the kitchen's pending orders) read them newest first.
+CREATE INDEX IF NOT EXISTS idx_orders_status_order_time ON orders (status, order_time DESC);
@@ -0,0 +1,18 @@
+-- Why bookings are cancelled, for reporting on lost bookings; one of models.CancellationReasons.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(30);
+
+-- Booking history: every change of time, table or status, with who made it and why.
+-- old_value and new_value hold the status, the table ID or the period, depending on change_type.
+CREATE TABLE IF NOT EXISTS booking_changes (
+    id BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    change_type VARCHAR(20) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    reason TEXT,
+    cancellation_reason VARCHAR(30),
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_changes_booking ON booking_changes (booking_id, changed_at, id);
@@ -0,0 +1,2 @@
+-- Late cancellation fee charged per the booking_policy setting, in major units like other amounts.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS cancellation_fee NUMERIC(18, 4);
@@ -0,0 +1,10 @@
+-- Confirmed bookings of a table may not overlap. The service checks availability before
+-- saving a booking, but two requests can pass the check at once; the constraint rejects
+-- the second. Periods are half-open, so a booking may start when the previous one ends.
+-- Adding it fails if confirmed bookings already overlap: cancel or move them first.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+    WHERE (status = 'confirmed');
@@ -0,0 +1,3 @@
+-- Cleanup time kept free after each booking of a table, in minutes. NULL uses the
+-- buffer_minutes of the booking_policy setting.
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS buffer_minutes INTEGER CHECK (buffer_minutes >= 0);
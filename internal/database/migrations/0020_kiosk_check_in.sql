@@ -0,0 +1,19 @@
+-- Check-in at the kiosk: every booking carries a secret token, shown to the client as a
+-- QR code, that the kiosk scans to check them in.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_in_token VARCHAR(64);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+UPDATE bookings SET check_in_token = replace(gen_random_uuid()::text, '-', '') WHERE check_in_token IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_check_in_token ON bookings (check_in_token);
+
+-- API keys let devices without a user login, like the check-in kiosk, call the routes of
+-- their scopes. Only the SHA-256 hash of a key is stored; the key is shown once, at creation.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
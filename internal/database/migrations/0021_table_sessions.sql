@@ -0,0 +1,27 @@
+-- Table sessions: the time a table is in play. A prepaid session has a time limit; when
+-- it runs out the session is stopped or continues as overtime, charged per minute.
+CREATE TABLE IF NOT EXISTS table_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    table_id BIGINT NOT NULL REFERENCES game_tables(id),
+    booking_id BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    client_id BIGINT REFERENCES clients(id) ON DELETE SET NULL,
+    started_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    limit_minutes INTEGER CHECK (limit_minutes > 0),
+    ends_at TIMESTAMPTZ,
+    on_expiry VARCHAR(16) NOT NULL DEFAULT 'stop',
+    overtime_rate NUMERIC(12, 2),
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    warned_minutes INTEGER,
+    stopped_at TIMESTAMPTZ,
+    stopped_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    overtime_minutes INTEGER NOT NULL DEFAULT 0,
+    overtime_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A table runs at most one session at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_table_sessions_running ON table_sessions (table_id) WHERE status <> 'stopped';
+-- The session timers scan the running sessions with a time limit
+CREATE INDEX IF NOT EXISTS idx_table_sessions_ends_at ON table_sessions (ends_at) WHERE status = 'active' AND ends_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- The smart plug or console agent that powers the TV and console of a table, as known to
+-- the power control endpoint (POWER_CONTROL_URL). Tables without one are not switched.
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS power_device_id VARCHAR(100);
@@ -0,0 +1,15 @@
+-- The console of a table (ps5, ps4, vr) and the controllers its hourly rate includes.
+-- Extra controllers are charged the hourly surcharge of the console type in the
+-- pricing_rules setting.
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS console_type VARCHAR(16);
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS base_controllers INTEGER NOT NULL DEFAULT 2 CHECK (base_controllers >= 1);
+
+-- Controllers booked or played with; NULL means the table's base controllers.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS controllers INTEGER CHECK (controllers >= 1);
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS controllers INTEGER CHECK (controllers >= 1);
+
+-- The bill of a session: the hourly rate it was started at, extra controllers included,
+-- the played time at that rate and the total with overtime, set when it is stopped.
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS hourly_rate NUMERIC(12, 2);
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS time_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS total_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
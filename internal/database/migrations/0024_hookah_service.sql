@@ -0,0 +1,18 @@
+-- Service of hookah order items: when the coals were last changed, the last reminder to
+-- change them and when the hookah was taken away. The timers remind staff to change the
+-- coals every coal_change_minutes (hookah_service setting) while the order is open.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS coal_changed_at TIMESTAMPTZ;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS coal_reminded_at TIMESTAMPTZ;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS service_ended_at TIMESTAMPTZ;
+
+-- Coal changes of hookahs, with the inventory movement deducting the coals from stock
+CREATE TABLE IF NOT EXISTS hookah_coal_changes (
+    id BIGSERIAL PRIMARY KEY,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    coals INTEGER NOT NULL CHECK (coals >= 0),
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL,
+    inventory_movement_id BIGINT REFERENCES inventory_movements(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_hookah_coal_changes_item ON hookah_coal_changes (order_item_id, changed_at);
@@ -0,0 +1,16 @@
+-- Per-minute billing of game tables (VR, pool): the played minutes are rounded up to the
+-- billing increment, charged at minute_rate and raised to minimum_charge.
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS billing_mode VARCHAR(16) NOT NULL DEFAULT 'hourly'
+    CHECK (billing_mode IN ('hourly', 'per_minute'));
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS minute_rate NUMERIC(12, 2);
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS billing_increment_minutes INTEGER NOT NULL DEFAULT 5 CHECK (billing_increment_minutes >= 1);
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS minimum_charge NUMERIC(12, 2);
+
+-- A session keeps the billing terms of its table when it started, like its hourly rate,
+-- and records the billed minutes and the top-up to the minimum charge when it is stopped.
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS billing_mode VARCHAR(16) NOT NULL DEFAULT 'hourly';
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS minute_rate NUMERIC(12, 2);
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS billing_increment_minutes INTEGER;
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS minimum_charge NUMERIC(12, 2);
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS billed_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS minimum_charge_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+-- Quick-sale presets: named bundles of pricelist items the bar sells with one tap.
+-- Each branch has its own presets.
+CREATE TABLE IF NOT EXISTS quick_sale_presets (
+    id          BIGSERIAL PRIMARY KEY,
+    branch_code VARCHAR(20) NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    sort_order  INTEGER NOT NULL DEFAULT 0,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version     INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (branch_code, name)
+);
+
+CREATE TABLE IF NOT EXISTS quick_sale_preset_items (
+    preset_id         BIGINT NOT NULL REFERENCES quick_sale_presets(id) ON DELETE CASCADE,
+    pricelist_item_id BIGINT NOT NULL REFERENCES pricelist_items(id) ON DELETE CASCADE,
+    quantity          INTEGER NOT NULL CHECK (quantity > 0),
+    position          INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (preset_id, pricelist_item_id)
+);
@@ -0,0 +1,30 @@
+-- House accounts: trusted clients run a tab across visits. Orders paid with the
+-- house_account method are charged to the account up to its credit limit; each charge is
+-- due payment_terms_days later and new charges are blocked while any charge is overdue.
+CREATE TABLE IF NOT EXISTS client_accounts (
+    client_id          BIGINT PRIMARY KEY REFERENCES clients(id) ON DELETE RESTRICT,
+    credit_limit       NUMERIC(12, 2) NOT NULL CHECK (credit_limit >= 0),
+    payment_terms_days INTEGER NOT NULL DEFAULT 30 CHECK (payment_terms_days >= 0),
+    is_active          BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version            INTEGER NOT NULL DEFAULT 1
+);
+
+-- The ledger of a house account. amount is what the entry adds to the balance the client
+-- owes: positive for charges, negative for payments and for reversals of cancelled orders.
+CREATE TABLE IF NOT EXISTS client_account_entries (
+    id             BIGSERIAL PRIMARY KEY,
+    client_id      BIGINT NOT NULL REFERENCES client_accounts(client_id) ON DELETE RESTRICT,
+    entry_type     VARCHAR(20) NOT NULL CHECK (entry_type IN ('charge', 'payment', 'reversal')),
+    amount         NUMERIC(12, 2) NOT NULL,
+    order_id       BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    payment_method VARCHAR(50),
+    notes          TEXT,
+    due_at         TIMESTAMPTZ, -- Charges only
+    created_by     BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_account_entries_client ON client_account_entries (client_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_client_account_entries_order ON client_account_entries (order_id) WHERE order_id IS NOT NULL;
@@ -0,0 +1,40 @@
+-- Lockers and shelves clients rent for a visit. Each branch numbers its own lockers.
+CREATE TABLE IF NOT EXISTS lockers (
+    id          BIGSERIAL PRIMARY KEY,
+    branch_code VARCHAR(20) NOT NULL,
+    number      VARCHAR(20) NOT NULL,
+    rental_fee  NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (rental_fee >= 0),
+    deposit     NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (deposit >= 0),
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version     INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (branch_code, number)
+);
+
+-- Rentals of lockers, from the assignment until the locker is released. The fee is billed
+-- with the table session of the visit; the deposit is returned on release unless kept,
+-- e.g. for a lost key.
+CREATE TABLE IF NOT EXISTS locker_rentals (
+    id               BIGSERIAL PRIMARY KEY,
+    locker_id        BIGINT NOT NULL REFERENCES lockers(id) ON DELETE RESTRICT,
+    client_id        BIGINT REFERENCES clients(id) ON DELETE SET NULL,
+    table_session_id BIGINT REFERENCES table_sessions(id) ON DELETE SET NULL,
+    booking_id       BIGINT REFERENCES bookings(id) ON DELETE SET NULL,
+    fee              NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    deposit          NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (deposit >= 0),
+    notes            TEXT,
+    rented_by        BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    rented_at        TIMESTAMPTZ NOT NULL,
+    released_at      TIMESTAMPTZ,
+    released_by      BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    deposit_returned BOOLEAN
+);
+
+-- A locker is rented to one visit at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locker_rentals_active ON locker_rentals (locker_id) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_locker_rentals_session ON locker_rentals (table_session_id) WHERE table_session_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_locker_rentals_booking ON locker_rentals (booking_id) WHERE booking_id IS NOT NULL;
+
+-- Locker fees billed with the session when it stopped
+ALTER TABLE table_sessions ADD COLUMN IF NOT EXISTS locker_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
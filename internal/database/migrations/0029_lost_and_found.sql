@@ -0,0 +1,24 @@
+-- Items left behind by clients, e.g. headphones at a station, until they are returned or
+-- purged after the retention of the lost_and_found setting. The photo is kept with the
+-- entry so every instance can serve it.
+CREATE TABLE IF NOT EXISTS lost_found_items (
+    id                    BIGSERIAL PRIMARY KEY,
+    branch_code           VARCHAR(20) NOT NULL,
+    description           TEXT NOT NULL,
+    table_id              BIGINT REFERENCES game_tables(id) ON DELETE SET NULL,
+    found_at              TIMESTAMPTZ NOT NULL,
+    found_by              BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    notes                 TEXT,
+    photo                 BYTEA,
+    photo_content_type    VARCHAR(50),
+    status                VARCHAR(20) NOT NULL DEFAULT 'stored' CHECK (status IN ('stored', 'returned')),
+    returned_to_client_id BIGINT REFERENCES clients(id) ON DELETE SET NULL,
+    returned_to_name      VARCHAR(255),
+    returned_at           TIMESTAMPTZ,
+    returned_by           BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version               INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_lost_found_items_branch_found ON lost_found_items (branch_code, found_at DESC);
@@ -0,0 +1,40 @@
+-- Incidents reported by staff, e.g. a broken controller, client misconduct or a cash
+-- discrepancy, with the staff member, client, table and order involved. An Admin confirms or
+-- dismisses each one; the penalty of a confirmed incident is deducted from the pay of its
+-- staff member.
+CREATE TABLE IF NOT EXISTS incidents (
+    id             BIGSERIAL PRIMARY KEY,
+    branch_code    VARCHAR(20) NOT NULL,
+    incident_type  VARCHAR(30) NOT NULL CHECK (incident_type IN ('equipment_damage', 'client_misconduct', 'cash_discrepancy', 'other')),
+    severity       VARCHAR(20) NOT NULL CHECK (severity IN ('low', 'medium', 'high', 'critical')),
+    description    TEXT NOT NULL,
+    occurred_at    TIMESTAMPTZ NOT NULL,
+    staff_id       BIGINT REFERENCES staff_members(id) ON DELETE SET NULL,
+    client_id      BIGINT REFERENCES clients(id) ON DELETE SET NULL,
+    table_id       BIGINT REFERENCES game_tables(id) ON DELETE SET NULL,
+    order_id       BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    penalty_amount NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (penalty_amount >= 0),
+    status         VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'confirmed', 'dismissed')),
+    reported_by    BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by    BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at    TIMESTAMPTZ,
+    review_notes   TEXT,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version        INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_branch_occurred ON incidents (branch_code, occurred_at DESC);
+-- Penalties counted by the payroll
+CREATE INDEX IF NOT EXISTS idx_incidents_staff_penalties ON incidents (staff_id, occurred_at) WHERE status = 'confirmed';
+
+CREATE TABLE IF NOT EXISTS incident_photos (
+    id           BIGSERIAL PRIMARY KEY,
+    incident_id  BIGINT NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    content_type VARCHAR(50) NOT NULL,
+    data         BYTEA NOT NULL,
+    uploaded_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_photos_incident ON incident_photos (incident_id);
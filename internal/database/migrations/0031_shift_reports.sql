@@ -0,0 +1,31 @@
+-- End-of-shift reports, generated when a staff member clocks out: the sales of the branch
+-- during the time clock entry, the reconciliation of the cash drawer, the orders still open
+-- at clock-out and the handover notes for the next shift. The figures are a snapshot and are
+-- not recomputed when orders change later.
+CREATE TABLE IF NOT EXISTS shift_reports (
+    id                    BIGSERIAL PRIMARY KEY,
+    branch_code           VARCHAR(20) NOT NULL,
+    time_clock_entry_id   BIGINT NOT NULL UNIQUE REFERENCES time_clock_entries(id) ON DELETE CASCADE,
+    staff_id              BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    shift_id              BIGINT REFERENCES shifts(id) ON DELETE SET NULL, -- The scheduled shift the entry overlaps, if any
+    started_at            TIMESTAMPTZ NOT NULL,
+    ended_at              TIMESTAMPTZ NOT NULL,
+    order_count           INTEGER NOT NULL DEFAULT 0,
+    sales_total           NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    sales_by_payment      JSONB NOT NULL DEFAULT '[]',
+    session_count         INTEGER NOT NULL DEFAULT 0,
+    session_total         NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    opening_cash          NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    cash_sales            NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    cash_account_payments NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    expected_cash         NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    counted_cash          NUMERIC(12, 2), -- NULL if the drawer was not counted
+    cash_difference       NUMERIC(12, 2), -- counted_cash - expected_cash
+    open_orders           JSONB NOT NULL DEFAULT '[]',
+    handover_notes        TEXT,
+    emailed_at            TIMESTAMPTZ, -- When the report was emailed to the Admins
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_reports_branch_ended ON shift_reports (branch_code, ended_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shift_reports_shift ON shift_reports (shift_id) WHERE shift_id IS NOT NULL;
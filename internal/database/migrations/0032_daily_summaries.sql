@@ -0,0 +1,26 @@
+-- The end-of-day close: a snapshot of the totals of each closed business day of a branch.
+-- Once a day is closed, its orders and those of the days before are locked against changes
+-- by anyone but an Admin.
+CREATE TABLE IF NOT EXISTS daily_summaries (
+    id                    BIGSERIAL PRIMARY KEY,
+    branch_code           VARCHAR(20) NOT NULL,
+    business_date         DATE NOT NULL,
+    order_count           INTEGER NOT NULL DEFAULT 0,
+    sales_total           NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    sales_by_payment      JSONB NOT NULL DEFAULT '[]',
+    cancelled_count       INTEGER NOT NULL DEFAULT 0,
+    refunded_count        INTEGER NOT NULL DEFAULT 0,
+    refunded_total        NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    session_count         INTEGER NOT NULL DEFAULT 0,
+    session_total         NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    cash_sales            NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    cash_account_payments NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    shift_report_count    INTEGER NOT NULL DEFAULT 0,
+    cash_difference       NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    forced_closures       JSONB NOT NULL DEFAULT '[]', -- Records closed by force, see force_reason
+    force_reason          TEXT,
+    notes                 TEXT,
+    closed_by             BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    closed_at             TIMESTAMPTZ NOT NULL,
+    UNIQUE (branch_code, business_date)
+);
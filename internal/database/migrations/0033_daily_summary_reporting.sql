@@ -0,0 +1,10 @@
+-- Period reports read their daily totals from daily_summaries. Days that were never closed are
+-- backfilled on demand; a backfilled summary does not lock its day and is replaced when the day
+-- is closed. Summaries are otherwise never updated.
+ALTER TABLE daily_summaries
+    ADD COLUMN IF NOT EXISTS revenue        NUMERIC(12, 2) NOT NULL DEFAULT 0, -- Sales plus table sessions
+    ADD COLUMN IF NOT EXISTS hours_booked   NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS category_sales JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS backfilled     BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE daily_summaries SET revenue = sales_total + session_total WHERE revenue = 0;
@@ -0,0 +1,40 @@
+-- Pre-aggregated data of the sales and booking reports, so reports over long periods do not scan
+-- the orders and bookings. Rows are bucketed per UTC hour (as timestamps in UTC) and regrouped per
+-- day, week or month of the club timezone by the reports; this is exact for timezones whose offset
+-- is a whole number of hours. The views are refreshed on a schedule and on request of an Admin;
+-- report_view_refreshes records the last refresh of each.
+CREATE MATERIALIZED VIEW IF NOT EXISTS report_sales_by_item AS
+SELECT date_trunc('hour', o.order_time AT TIME ZONE 'UTC') AS sold_hour,
+       oi.pricelist_item_id,
+       SUM(oi.quantity) AS total_quantity,
+       SUM(oi.total_price) AS total_sales,
+       SUM(COALESCE(o.discount_amount, 0)::NUMERIC / ic.item_count) AS estimated_item_discount -- Order discount split evenly over its items
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+JOIN (SELECT order_id, COUNT(*) AS item_count FROM order_items GROUP BY order_id) ic ON ic.order_id = o.id
+WHERE o.status = 'completed'
+GROUP BY 1, 2;
+
+-- Unique indexes let the views be refreshed concurrently with reads
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_sales_by_item ON report_sales_by_item (sold_hour, pricelist_item_id);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS report_table_utilization AS
+SELECT date_trunc('hour', b.start_time AT TIME ZONE 'UTC') AS start_hour,
+       b.table_id,
+       COUNT(*) AS bookings_count,
+       SUM(EXTRACT(EPOCH FROM (b.end_time - b.start_time))) / 3600.0 AS total_hours_booked
+FROM bookings b
+WHERE b.status IN ('completed', 'active')
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_table_utilization ON report_table_utilization (start_hour, table_id);
+
+CREATE TABLE IF NOT EXISTS report_view_refreshes (
+    view_name    VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMPTZ NOT NULL,
+    duration_ms  BIGINT NOT NULL
+);
+
+INSERT INTO report_view_refreshes (view_name, refreshed_at, duration_ms)
+VALUES ('report_sales_by_item', NOW(), 0), ('report_table_utilization', NOW(), 0)
+ON CONFLICT (view_name) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Built-in read-only role for reporting. Analysts may only read the reports, the dashboard and
+-- the lists opened to them; client phone numbers and email addresses are masked in responses.
+INSERT INTO roles (id, name, description) VALUES
+    (4, 'Analyst', 'Read-only access to reports, without client contact details')
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('roles', 'id'), GREATEST((SELECT MAX(id) FROM roles), 1));
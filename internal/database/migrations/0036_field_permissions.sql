@@ -0,0 +1,14 @@
+-- Permissions to see masked fields. Responses to users whose role lacks view_pii mask phone
+-- numbers and email addresses; without view_salaries, salaries and pay are hidden. Admins may
+-- see both, Staff contacts only, Analysts neither.
+INSERT INTO permissions (name, description) VALUES
+    ('view_pii', 'See phone numbers and email addresses unmasked'),
+    ('view_salaries', 'See the salaries and pay of staff members')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT ro.id, p.id
+FROM roles ro
+JOIN permissions p ON (ro.name = 'Admin' AND p.name IN ('view_pii', 'view_salaries'))
+                   OR (ro.name = 'Staff' AND p.name = 'view_pii')
+ON CONFLICT DO NOTHING;
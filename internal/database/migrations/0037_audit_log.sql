@@ -0,0 +1,18 @@
+-- Audit log: API requests that change something, every request made while an Admin impersonates
+-- a user (impersonator_id set), and actions such as starting an impersonation.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    impersonator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    action          VARCHAR(200) NOT NULL,
+    method          VARCHAR(10),
+    path            TEXT,
+    status_code     INT,
+    ip_address      VARCHAR(64),
+    details         TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user ON audit_log (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_impersonator ON audit_log (impersonator_id, created_at DESC) WHERE impersonator_id IS NOT NULL;
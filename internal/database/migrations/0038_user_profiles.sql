@@ -0,0 +1,5 @@
+-- Profile details users maintain themselves through PATCH /auth/me: a phone number and an
+-- avatar, kept with the user so every instance can serve it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number VARCHAR(30);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_content_type VARCHAR(50);
@@ -0,0 +1,20 @@
+-- Invitations of staff by an Admin. The invitee redeems the token (only its SHA-256 is kept)
+-- to choose a username and password, and gets the role of the invitation. Open registration
+-- through POST /auth/register is off unless the open_registration setting is "true".
+CREATE TABLE IF NOT EXISTS staff_invitations (
+    id               BIGSERIAL PRIMARY KEY,
+    token_hash       VARCHAR(64) NOT NULL UNIQUE,
+    email            VARCHAR(255),
+    phone_number     VARCHAR(30),
+    full_name        VARCHAR(255),
+    role_id          BIGINT NOT NULL REFERENCES roles(id),
+    invited_by       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at       TIMESTAMPTZ NOT NULL,
+    accepted_at      TIMESTAMPTZ,
+    accepted_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at       TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT staff_invitations_contact_check CHECK (email IS NOT NULL OR phone_number IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_invitations_created_at ON staff_invitations (created_at DESC, id DESC);
@@ -0,0 +1,41 @@
+-- The share of the order discount of each item, allocated when the order is created: split by
+-- line total and rounded by the largest remainder method, so the shares add up to the discount.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_amount NUMERIC(18, 4) NOT NULL DEFAULT 0;
+
+-- Existing orders are allocated the same way, to the cent (the default currency decimals)
+WITH shares AS (
+    SELECT oi.id, oi.order_id,
+           o.total_amount - o.final_amount AS discount,
+           TRUNC((o.total_amount - o.final_amount) * oi.total_price
+                 / SUM(oi.total_price) OVER (PARTITION BY oi.order_id), 2) AS share,
+           ((o.total_amount - o.final_amount) * oi.total_price
+                 / SUM(oi.total_price) OVER (PARTITION BY oi.order_id)) * 100 % 1 AS remainder
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    WHERE o.total_amount > o.final_amount AND oi.total_price > 0
+), ranked AS (
+    SELECT id, share,
+           ROW_NUMBER() OVER (PARTITION BY order_id ORDER BY remainder DESC, id) AS position,
+           ROUND((discount - SUM(share) OVER (PARTITION BY order_id)) * 100) AS leftover_cents
+    FROM shares
+)
+UPDATE order_items oi
+SET discount_amount = r.share + CASE WHEN r.position <= r.leftover_cents THEN 0.01 ELSE 0 END
+FROM ranked r
+WHERE r.id = oi.id;
+
+-- The sales report sums the stored shares instead of splitting each discount evenly
+DROP MATERIALIZED VIEW IF EXISTS report_sales_by_item;
+
+CREATE MATERIALIZED VIEW report_sales_by_item AS
+SELECT date_trunc('hour', o.order_time AT TIME ZONE 'UTC') AS sold_hour,
+       oi.pricelist_item_id,
+       SUM(oi.quantity) AS total_quantity,
+       SUM(oi.total_price) AS total_sales,
+       SUM(oi.discount_amount) AS total_discount
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE o.status = 'completed'
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_sales_by_item ON report_sales_by_item (sold_hour, pricelist_item_id);
@@ -0,0 +1,13 @@
+-- Taxes (VAT). The rates of the tax classes and whether prices include tax are set in the tax
+-- setting; a pricelist item names its class, or gets the default class. Orders keep the tax as
+-- computed when they were created, so later changes of the setting do not change past orders.
+ALTER TABLE pricelist_items ADD COLUMN IF NOT EXISTS tax_class VARCHAR(50);
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS tax_class  VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS tax_rate   NUMERIC(7, 4), -- Percent; NULL for an untaxed item
+    ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(18, 4) NOT NULL DEFAULT 0;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS tax_amount         NUMERIC(18, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,24 @@
+-- Suppliers and the prices they sell pricelist items at. The price lists are imported from the
+-- suppliers' CSV files and compared to suggest where to buy the items running low.
+CREATE TABLE IF NOT EXISTS suppliers (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL UNIQUE,
+    contact_name VARCHAR(255),
+    phone_number VARCHAR(50),
+    email        VARCHAR(255),
+    notes        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS supplier_prices (
+    supplier_id        BIGINT NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    pricelist_item_id  BIGINT NOT NULL REFERENCES pricelist_items(id) ON DELETE CASCADE,
+    price              NUMERIC(18, 4) NOT NULL CHECK (price >= 0), -- Per unit, in the club currency
+    min_order_quantity INT NOT NULL DEFAULT 1 CHECK (min_order_quantity >= 1),
+    supplier_sku       VARCHAR(100),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (supplier_id, pricelist_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_prices_item ON supplier_prices (pricelist_item_id, price);
@@ -0,0 +1,16 @@
+-- Reorder points calculated from the consumption of the stock-tracked items by a periodic job,
+-- with their basis. The reorder_points setting controls the calculation and whether the items'
+-- low stock thresholds are updated to them.
+CREATE TABLE IF NOT EXISTS item_reorder_points (
+    pricelist_item_id          BIGINT PRIMARY KEY REFERENCES pricelist_items(id) ON DELETE CASCADE,
+    consumed                   INT NOT NULL,
+    lookback_days              INT NOT NULL,
+    average_daily_consumption  NUMERIC(14, 4) NOT NULL,
+    lead_time_days             INT NOT NULL,
+    safety_days                INT NOT NULL,
+    cover_days                 INT NOT NULL,
+    suggested_threshold        INT NOT NULL,
+    suggested_reorder_quantity INT NOT NULL,
+    applied_at                 TIMESTAMPTZ,
+    calculated_at              TIMESTAMPTZ NOT NULL
+);
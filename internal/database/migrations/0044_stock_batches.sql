@@ -0,0 +1,28 @@
+-- Batches of perishable stock, e.g. syrups, received with a batch number and/or expiry date.
+-- Outgoing stock is taken from the batches first expiry first out (FEFO); the deductions of an
+-- order are kept so its cancellation or deletion puts the stock back in the same batches.
+CREATE TABLE IF NOT EXISTS stock_batches (
+    id                    BIGSERIAL PRIMARY KEY,
+    pricelist_item_id     BIGINT NOT NULL REFERENCES pricelist_items(id) ON DELETE CASCADE,
+    batch_number          VARCHAR(100),
+    expiry_date           DATE,
+    quantity_received     INT NOT NULL CHECK (quantity_received > 0),
+    quantity_remaining    INT NOT NULL CHECK (quantity_remaining >= 0),
+    received_at           TIMESTAMPTZ NOT NULL,
+    movement_id           BIGINT REFERENCES inventory_movements(id) ON DELETE SET NULL, -- The receipt
+    written_off_at        TIMESTAMPTZ, -- When it was written off as expired
+    write_off_movement_id BIGINT REFERENCES inventory_movements(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_batches_fefo ON stock_batches (pricelist_item_id, expiry_date NULLS LAST, received_at)
+    WHERE quantity_remaining > 0;
+
+CREATE TABLE IF NOT EXISTS stock_batch_deductions (
+    id          BIGSERIAL PRIMARY KEY,
+    batch_id    BIGINT NOT NULL REFERENCES stock_batches(id) ON DELETE CASCADE,
+    movement_id BIGINT NOT NULL REFERENCES inventory_movements(id) ON DELETE CASCADE,
+    order_id    BIGINT, -- Of a sale; no foreign key, as the deductions are restored when the order is deleted
+    quantity    INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_batch_deductions_order ON stock_batch_deductions (order_id) WHERE order_id IS NOT NULL;
@@ -0,0 +1,30 @@
+-- Units of measure. The stock of an item is counted in its stock unit (pcs, ml or g) and may be
+-- fractional. A sold unit of the item takes its portion_size of stock, e.g. 40 ml of a syrup,
+-- and a purchase unit, e.g. a 1 L bottle, holds purchase_unit_size of it. Existing items count
+-- pieces, one per sale and purchase, as before.
+ALTER TABLE pricelist_items
+    ADD COLUMN IF NOT EXISTS stock_unit         VARCHAR(10) NOT NULL DEFAULT 'pcs',
+    ADD COLUMN IF NOT EXISTS portion_size       NUMERIC(14, 3) NOT NULL DEFAULT 1 CHECK (portion_size > 0),
+    ADD COLUMN IF NOT EXISTS purchase_unit      VARCHAR(50), -- Its name, e.g. "bottle"
+    ADD COLUMN IF NOT EXISTS purchase_unit_size NUMERIC(14, 3) NOT NULL DEFAULT 1 CHECK (purchase_unit_size > 0),
+    ALTER COLUMN current_stock TYPE NUMERIC(14, 3),
+    ALTER COLUMN low_stock_threshold TYPE NUMERIC(14, 3);
+
+ALTER TABLE inventory_movements ALTER COLUMN quantity_changed TYPE NUMERIC(14, 3);
+
+ALTER TABLE stock_batches
+    ALTER COLUMN quantity_received TYPE NUMERIC(14, 3),
+    ALTER COLUMN quantity_remaining TYPE NUMERIC(14, 3);
+
+ALTER TABLE stock_batch_deductions ALTER COLUMN quantity TYPE NUMERIC(14, 3);
+
+ALTER TABLE item_reorder_points
+    ALTER COLUMN consumed TYPE NUMERIC(14, 3),
+    ALTER COLUMN suggested_threshold TYPE NUMERIC(14, 3),
+    ALTER COLUMN suggested_reorder_quantity TYPE NUMERIC(14, 3);
+
+-- The stock an order item took, so cancelling or deleting the order puts back the same
+-- whatever the portion of the item is by then
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS stock_quantity NUMERIC(14, 3);
+UPDATE order_items SET stock_quantity = quantity WHERE stock_quantity IS NULL;
+ALTER TABLE order_items ALTER COLUMN stock_quantity SET NOT NULL;
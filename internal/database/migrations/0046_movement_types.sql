@@ -0,0 +1,7 @@
+-- Inventory movements only record the movement types the services know (models.MovementTypes).
+-- Earlier rows were lowercased by the service, so they already qualify.
+ALTER TABLE inventory_movements DROP CONSTRAINT IF EXISTS inventory_movements_movement_type_check;
+ALTER TABLE inventory_movements ADD CONSTRAINT inventory_movements_movement_type_check
+    CHECK (movement_type IN ('purchase', 'adjustment_in', 'adjustment_out', 'stocktake_adjustment', 'transfer_in',
+                             'transfer_out', 'spoilage', 'sale', 'return_cancellation', 'return_deletion',
+                             'component_usage'));
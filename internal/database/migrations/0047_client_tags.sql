@@ -0,0 +1,10 @@
+-- Tags segment clients, e.g. vip or tournament_player. Tags are lowercase; the presets are
+-- listed by models.ClientPresetTags, and any other tag may be used as well.
+CREATE TABLE IF NOT EXISTS client_tags (
+    client_id  BIGINT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    tag        VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (client_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_tags_tag ON client_tags(tag);
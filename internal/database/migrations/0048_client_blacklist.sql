@@ -0,0 +1,10 @@
+-- Blacklisted clients cannot book until blacklisted_until, or until the blacklist is lifted
+-- if it has no end. Staff may override it with a manager's approval if the booking_policy
+-- setting allows it (blacklist_override); clients booking online never can.
+ALTER TABLE clients
+    ADD COLUMN IF NOT EXISTS blacklisted_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blacklist_reason  TEXT,
+    ADD COLUMN IF NOT EXISTS blacklisted_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS blacklisted_by    BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_clients_blacklisted_at ON clients(blacklisted_at) WHERE blacklisted_at IS NOT NULL;
@@ -0,0 +1,35 @@
+-- Documents of clients: signed consents for tournaments and for staying at night, and scans of
+-- identity documents, uploaded as photos. A document is valid until expires_at, if it has one.
+-- Only roles with the view_client_documents permission see or upload them; Analysts do not.
+CREATE TABLE IF NOT EXISTS client_documents (
+    id            BIGSERIAL PRIMARY KEY,
+    client_id     BIGINT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    document_type VARCHAR(30) NOT NULL CHECK (document_type IN ('tournament_consent', 'night_access_consent', 'id_document')),
+    content_type  VARCHAR(50) NOT NULL,
+    data          BYTEA NOT NULL,
+    expires_at    TIMESTAMPTZ,
+    uploaded_by   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_documents_client ON client_documents (client_id);
+
+-- Whether staff checked the identity of a client, e.g. their age, and against which document
+CREATE TABLE IF NOT EXISTS client_id_verifications (
+    client_id   BIGINT PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    document_id BIGINT REFERENCES client_documents(id) ON DELETE SET NULL,
+    verified_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    verified_at TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('view_client_documents', 'See and upload the consents and identity documents of clients')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT ro.id, p.id
+FROM roles ro
+JOIN permissions p ON p.name = 'view_client_documents'
+WHERE ro.name IN ('Admin', 'Staff')
+ON CONFLICT DO NOTHING;
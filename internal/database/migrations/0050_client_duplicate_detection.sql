@@ -0,0 +1,8 @@
+-- Creating a client looks for probable duplicates first: clients with the same phone number in
+-- another format (compared by its last 10 digits), the same email or a similar name (trigram
+-- similarity). These indexes keep the lookup fast.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_clients_full_name_trgm ON clients USING GIN (LOWER(full_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_clients_phone_digits ON clients (RIGHT(REGEXP_REPLACE(phone_number, '[^0-9]', '', 'g'), 10));
+CREATE INDEX IF NOT EXISTS idx_clients_email_lower ON clients (LOWER(email));
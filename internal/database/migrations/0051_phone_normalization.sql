@@ -0,0 +1,39 @@
+-- Phone numbers of clients and staff are stored in E.164 form, e.g. +77011234567, so lookups
+-- and duplicate detection compare them as they are. normalize_phone mirrors
+-- utils.NormalizePhone: 8 701 123 45 67 and 701 123 45 67 are Kazakh numbers (+7), and it
+-- returns NULL for what is not a phone number.
+CREATE OR REPLACE FUNCTION normalize_phone(phone TEXT) RETURNS TEXT
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT CASE
+        WHEN d = '' OR d !~ '^[0-9]+$' THEN NULL
+        WHEN NOT intl AND LENGTH(d) = 11 AND d LIKE '8%' THEN '+7' || SUBSTR(d, 2)
+        WHEN NOT intl AND LENGTH(d) = 11 AND d LIKE '7%' THEN '+' || d
+        WHEN NOT intl AND LENGTH(d) = 10 THEN '+7' || d
+        WHEN intl AND LENGTH(d) BETWEEN 8 AND 15 AND d NOT LIKE '0%' AND (d NOT LIKE '7%' OR LENGTH(d) = 11) THEN '+' || d
+    END
+    FROM (
+        SELECT p LIKE '+%' OR p LIKE '00%' AS intl,
+               CASE WHEN p LIKE '+%' THEN SUBSTR(p, 2) WHEN p LIKE '00%' THEN SUBSTR(p, 3) ELSE p END AS d
+        FROM (SELECT REGEXP_REPLACE(BTRIM(phone), '[[:space:]().-]', '', 'g') AS p) s
+    ) n
+$$;
+
+-- Numbers that are no phone numbers are kept as they are. Of clients whose numbers normalize
+-- to the same one, only the first keeps it, so the duplicates can be merged.
+UPDATE clients c
+SET phone_number = n.phone, updated_at = NOW()
+FROM (
+    SELECT DISTINCT ON (normalize_phone(phone_number)) id, normalize_phone(phone_number) AS phone
+    FROM clients
+    WHERE normalize_phone(phone_number) IS NOT NULL
+    ORDER BY normalize_phone(phone_number), phone_number = normalize_phone(phone_number) DESC, id
+) n
+WHERE c.id = n.id AND c.phone_number <> n.phone
+  AND NOT EXISTS (SELECT 1 FROM clients o WHERE o.phone_number = n.phone);
+
+UPDATE staff_members
+SET phone_number = normalize_phone(phone_number), updated_at = NOW()
+WHERE normalize_phone(phone_number) IS NOT NULL AND phone_number <> normalize_phone(phone_number);
+
+-- Duplicate detection compares the normalized numbers, through the unique index of phone_number
+DROP INDEX IF EXISTS idx_clients_phone_digits;
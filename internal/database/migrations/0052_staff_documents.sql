@@ -0,0 +1,18 @@
+-- Documents of staff members kept for HR: employment contracts, medical books and other
+-- certificates, uploaded as photos or scans. A document with an expiry date is valid through
+-- that day (club time). Admins are notified once of each document about to expire.
+CREATE TABLE IF NOT EXISTS staff_documents (
+    id                 BIGSERIAL PRIMARY KEY,
+    staff_id           BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    document_type      VARCHAR(30) NOT NULL CHECK (document_type IN ('employment_contract', 'medical_book', 'certificate')),
+    title              VARCHAR(255), -- E.g. the name of a certificate
+    content_type       VARCHAR(50) NOT NULL,
+    data               BYTEA NOT NULL,
+    expires_on         DATE,
+    uploaded_by        BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expiry_notified_at TIMESTAMPTZ -- When the Admins were notified that it is about to expire
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_documents_staff ON staff_documents (staff_id);
+CREATE INDEX IF NOT EXISTS idx_staff_documents_expires_on ON staff_documents (expires_on) WHERE expires_on IS NOT NULL;
@@ -0,0 +1,35 @@
+-- Time off requested by staff members for whole days, start_date to end_date inclusive (club
+-- dates). Admins approve or reject a pending request; its requester or an Admin cancels it.
+-- No shift can be scheduled on an approved day off.
+CREATE TABLE IF NOT EXISTS staff_time_off (
+    id            BIGSERIAL PRIMARY KEY,
+    staff_id      BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    start_date    DATE NOT NULL,
+    end_date      DATE NOT NULL,
+    reason        TEXT,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    requested_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_by    BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at    TIMESTAMPTZ,
+    decision_note TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_time_off_staff_dates ON staff_time_off (staff_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_staff_time_off_status ON staff_time_off (status);
+
+-- Weekly availability preferences of staff members: the hours of a weekday (1 Monday to 7
+-- Sunday) they prefer to work or cannot work. Hours not covered have no preference.
+CREATE TABLE IF NOT EXISTS staff_availability (
+    id         BIGSERIAL PRIMARY KEY,
+    staff_id   BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    weekday    SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7),
+    start_time TIME NOT NULL,
+    end_time   TIME NOT NULL,
+    preference VARCHAR(20) NOT NULL CHECK (preference IN ('preferred', 'unavailable')),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_availability_staff ON staff_availability (staff_id, weekday);
@@ -0,0 +1,19 @@
+-- Clock-ins refused by the clock_in setting because they came from outside the club's network
+-- and away from the club. An Admin may override a violation, which clocks the staff member in
+-- as of the attempt and links the resulting time clock entry.
+CREATE TABLE IF NOT EXISTS clock_in_violations (
+    id                  BIGSERIAL PRIMARY KEY,
+    staff_id            BIGINT NOT NULL REFERENCES staff_members(id) ON DELETE CASCADE,
+    reason              TEXT NOT NULL CHECK (reason IN ('ip_not_allowed', 'location_missing', 'outside_geofence')),
+    ip_address          TEXT,
+    latitude            DOUBLE PRECISION,
+    longitude           DOUBLE PRECISION,
+    distance_meters     DOUBLE PRECISION,
+    attempted_at        TIMESTAMPTZ NOT NULL,
+    overridden_by       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    overridden_at       TIMESTAMPTZ,
+    time_clock_entry_id BIGINT REFERENCES time_clock_entries(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_clock_in_violations_attempted_at ON clock_in_violations (attempted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_clock_in_violations_staff_id ON clock_in_violations (staff_id);
@@ -0,0 +1,21 @@
+-- The floor plan the POS renders as the room view: the size of the canvas and where each game
+-- table is drawn on it. Tables without a position are not on the plan.
+CREATE TABLE IF NOT EXISTS floor_plan (
+    id         BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id), -- A single plan per branch
+    width      DOUBLE PRECISION NOT NULL CHECK (width > 0),
+    height     DOUBLE PRECISION NOT NULL CHECK (height > 0),
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS floor_plan_tables (
+    table_id   BIGINT PRIMARY KEY REFERENCES game_tables(id) ON DELETE CASCADE,
+    x          DOUBLE PRECISION NOT NULL CHECK (x >= 0),
+    y          DOUBLE PRECISION NOT NULL CHECK (y >= 0),
+    width      DOUBLE PRECISION NOT NULL CHECK (width > 0),
+    height     DOUBLE PRECISION NOT NULL CHECK (height > 0),
+    rotation   DOUBLE PRECISION NOT NULL DEFAULT 0, -- Degrees clockwise
+    shape      TEXT NOT NULL CHECK (shape IN ('rectangle', 'circle')),
+    zone       TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
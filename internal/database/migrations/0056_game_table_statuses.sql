@@ -0,0 +1,9 @@
+-- The lifecycle of a game table: available, reserved and occupied follow its bookings and
+-- sessions, cleaning and maintenance are set by the staff. Statuses outside it, set freely
+-- before, become available.
+UPDATE game_tables SET status = 'available'
+WHERE status NOT IN ('available', 'occupied', 'reserved', 'cleaning', 'maintenance');
+
+ALTER TABLE game_tables DROP CONSTRAINT IF EXISTS game_tables_status_check;
+ALTER TABLE game_tables ADD CONSTRAINT game_tables_status_check
+    CHECK (status IN ('available', 'occupied', 'reserved', 'cleaning', 'maintenance'));
@@ -0,0 +1,28 @@
+-- Holding deposits taken when a table session starts, in cash or as a card pre-authorization
+-- with the payment provider. When the session stops the deposit is applied to its bill and
+-- the remainder refunded: cash is handed back, a pre-authorization is captured for the
+-- applied amount and the rest of the hold released.
+CREATE TABLE IF NOT EXISTS session_deposits (
+    id                BIGSERIAL PRIMARY KEY,
+    table_session_id  BIGINT NOT NULL UNIQUE REFERENCES table_sessions(id) ON DELETE CASCADE,
+    client_id         BIGINT REFERENCES clients(id) ON DELETE SET NULL,
+    method            VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card_preauth')),
+    amount            NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    -- held until the session stops; capturing while the provider is asked to capture a
+    -- pre-authorization, failed if it refused (retried by hand), then settled
+    status            VARCHAR(20) NOT NULL DEFAULT 'held' CHECK (status IN ('held', 'capturing', 'failed', 'settled')),
+    authorization_id  VARCHAR(100), -- Pre-authorization at the payment provider
+    applied_amount    NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (applied_amount >= 0),
+    refunded_amount   NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0),
+    failure_reason    TEXT,
+    taken_by          BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    taken_at          TIMESTAMPTZ NOT NULL,
+    settled_at        TIMESTAMPTZ, -- When the session stopped and the deposit was applied
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_deposits_taken_at ON session_deposits (taken_at);
+CREATE INDEX IF NOT EXISTS idx_session_deposits_settled_at ON session_deposits (settled_at) WHERE settled_at IS NOT NULL;
+
+-- Cash deposits taken less those refunded during the shift, part of the cash expected in the drawer
+ALTER TABLE shift_reports ADD COLUMN IF NOT EXISTS cash_deposits NUMERIC(12, 2) NOT NULL DEFAULT 0;
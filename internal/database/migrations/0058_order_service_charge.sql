@@ -0,0 +1,6 @@
+-- A service charge added to the orders of large groups and of VIP tables and rooms, see the
+-- service_charge setting. It is part of final_amount, so revenue totals include it, but is not
+-- shared out over order_items, so item-level sales reports leave it out.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS guest_count INTEGER CHECK (guest_count > 0);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS service_charge_rate NUMERIC(5, 2) CHECK (service_charge_rate >= 0 AND service_charge_rate <= 100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS service_charge_amount NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (service_charge_amount >= 0);
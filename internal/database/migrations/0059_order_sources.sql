@@ -0,0 +1,6 @@
+-- The channel an order or booking was placed through: pos, kiosk, client_app or telegram (see
+-- models.OrderSources), taken from the authenticated principal, so the revenue of the
+-- self-service channels can be told apart (GET /reports/sources). Existing rows were taken at
+-- the POS.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'pos';
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'pos';
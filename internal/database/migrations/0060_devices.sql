@@ -0,0 +1,19 @@
+-- The registry of POS terminals, kiosks and bar screens. A device registers itself with an API
+-- key of the device scope, picks up its settings (default branch, printer, allowed order types)
+-- and sends heartbeats; a device silent for two minutes is shown as offline.
+CREATE TABLE IF NOT EXISTS devices (
+    id             BIGSERIAL PRIMARY KEY,
+    device_uid     VARCHAR(100) NOT NULL UNIQUE, -- Chosen by the device, e.g. its hardware serial
+    name           VARCHAR(100) NOT NULL,
+    type           VARCHAR(20) NOT NULL CHECK (type IN ('pos', 'kiosk', 'bar_screen')),
+    settings       JSONB NOT NULL DEFAULT '{}',
+    app_version    VARCHAR(50),
+    api_key_id     BIGINT REFERENCES api_keys(id) ON DELETE SET NULL,
+    last_ip        VARCHAR(45),
+    registered_at  TIMESTAMPTZ NOT NULL,
+    last_seen_at   TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    version        INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_last_seen_at ON devices (last_seen_at);
@@ -0,0 +1,68 @@
+-- Offline sync for POS terminals. Every change of the data a POS keeps offline (the pricelist,
+-- the game tables and the clients) takes the next number of sync_versions, and deletions leave
+-- a tombstone with one, so GET /sync/changes returns what changed after the last number a
+-- terminal has seen. Orders taken offline keep the UUID the POS gave them, so pushing them
+-- again never creates them twice.
+CREATE SEQUENCE IF NOT EXISTS sync_versions;
+
+CREATE OR REPLACE FUNCTION set_sync_version() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    NEW.sync_version := nextval('sync_versions');
+    RETURN NEW;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    sync_version  BIGINT PRIMARY KEY DEFAULT nextval('sync_versions'),
+    entity        VARCHAR(30) NOT NULL, -- e.g. pricelist_item, see models.SyncEntities
+    entity_id     BIGINT NOT NULL,
+    deleted_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- TG_ARGV[0] is the entity of the table
+CREATE OR REPLACE FUNCTION record_sync_tombstone() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO sync_tombstones (entity, entity_id) VALUES (TG_ARGV[0], OLD.id);
+    RETURN OLD;
+END
+$$;
+
+ALTER TABLE pricelist_categories ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT nextval('sync_versions');
+ALTER TABLE pricelist_items ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT nextval('sync_versions');
+ALTER TABLE game_tables ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT nextval('sync_versions');
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT nextval('sync_versions');
+
+CREATE INDEX IF NOT EXISTS idx_pricelist_categories_sync_version ON pricelist_categories (sync_version);
+CREATE INDEX IF NOT EXISTS idx_pricelist_items_sync_version ON pricelist_items (sync_version);
+CREATE INDEX IF NOT EXISTS idx_game_tables_sync_version ON game_tables (sync_version);
+CREATE INDEX IF NOT EXISTS idx_clients_sync_version ON clients (sync_version);
+
+DROP TRIGGER IF EXISTS pricelist_categories_sync_version ON pricelist_categories;
+CREATE TRIGGER pricelist_categories_sync_version BEFORE UPDATE ON pricelist_categories
+    FOR EACH ROW EXECUTE FUNCTION set_sync_version();
+DROP TRIGGER IF EXISTS pricelist_items_sync_version ON pricelist_items;
+CREATE TRIGGER pricelist_items_sync_version BEFORE UPDATE ON pricelist_items
+    FOR EACH ROW EXECUTE FUNCTION set_sync_version();
+DROP TRIGGER IF EXISTS game_tables_sync_version ON game_tables;
+CREATE TRIGGER game_tables_sync_version BEFORE UPDATE ON game_tables
+    FOR EACH ROW EXECUTE FUNCTION set_sync_version();
+DROP TRIGGER IF EXISTS clients_sync_version ON clients;
+CREATE TRIGGER clients_sync_version BEFORE UPDATE ON clients
+    FOR EACH ROW EXECUTE FUNCTION set_sync_version();
+
+DROP TRIGGER IF EXISTS pricelist_categories_sync_tombstone ON pricelist_categories;
+CREATE TRIGGER pricelist_categories_sync_tombstone AFTER DELETE ON pricelist_categories
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('pricelist_category');
+DROP TRIGGER IF EXISTS pricelist_items_sync_tombstone ON pricelist_items;
+CREATE TRIGGER pricelist_items_sync_tombstone AFTER DELETE ON pricelist_items
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('pricelist_item');
+DROP TRIGGER IF EXISTS game_tables_sync_tombstone ON game_tables;
+CREATE TRIGGER game_tables_sync_tombstone AFTER DELETE ON game_tables
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('game_table');
+DROP TRIGGER IF EXISTS clients_sync_tombstone ON clients;
+CREATE TRIGGER clients_sync_tombstone AFTER DELETE ON clients
+    FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('client');
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS offline_uuid UUID UNIQUE;
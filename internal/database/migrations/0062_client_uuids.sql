@@ -0,0 +1,8 @@
+-- UUIDs the client gives to the orders, order items and bookings it creates, so that a
+-- retried request returns the row created the first time instead of creating another.
+-- Orders taken offline already carried one for POST /sync/orders.
+ALTER TABLE orders RENAME COLUMN offline_uuid TO uuid;
+ALTER TABLE orders RENAME CONSTRAINT orders_offline_uuid_key TO orders_uuid_key;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS uuid UUID UNIQUE;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS uuid UUID UNIQUE;
@@ -0,0 +1,144 @@
+-- Orders and inventory movements are partitioned by month, so the queries of a period read only
+-- the partitions of its months and an old month can be archived or dropped whole. Orders are
+-- partitioned on business_date, which keeps the daily order numbers unique, and inventory
+-- movements on movement_date, in months of UTC. create_monthly_partitions creates the missing
+-- partitions of a range of months; the server runs it daily for the months ahead
+-- (services.PartitionService).
+--
+-- A unique key of a partitioned table must include the partition key, so no foreign key can
+-- reference the orders or the inventory movements any more. Triggers take the actions of the
+-- foreign keys dropped on delete, and order_uuids keeps the UUIDs of orders unique. The
+-- business_date of an order never changes: moving an order to another partition deletes it
+-- from the first one, which would run the delete trigger.
+
+-- Partitions are named <parent>_YYYY_MM. The bounds are midnight UTC of the first of the month;
+-- for a date partition key only the date is kept.
+CREATE OR REPLACE FUNCTION create_monthly_partitions(parent TEXT, from_date DATE, to_date DATE) RETURNS SETOF TEXT
+LANGUAGE plpgsql AS $$
+DECLARE
+    month_start DATE := date_trunc('month', from_date)::DATE;
+    partition_name TEXT;
+BEGIN
+    -- Instances creating the partitions at the same time take turns
+    PERFORM pg_advisory_xact_lock(hashtext('create_monthly_partitions'));
+    WHILE month_start <= to_date LOOP
+        partition_name := parent || '_' || to_char(month_start, 'YYYY_MM');
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)', partition_name, parent,
+                           month_start::TEXT || ' 00:00:00+00', (month_start + INTERVAL '1 month')::DATE::TEXT || ' 00:00:00+00');
+            RETURN NEXT partition_name;
+        END IF;
+        month_start := (month_start + INTERVAL '1 month')::DATE;
+    END LOOP;
+END
+$$;
+
+-- Orders
+DROP MATERIALIZED VIEW IF EXISTS report_sales_by_item;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_id_fkey;
+ALTER TABLE client_account_entries DROP CONSTRAINT IF EXISTS client_account_entries_order_id_fkey;
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_order_id_fkey;
+
+ALTER TABLE orders RENAME TO orders_unpartitioned;
+ALTER SEQUENCE orders_id_seq OWNED BY NONE;
+CREATE TABLE orders (LIKE orders_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (business_date);
+SELECT create_monthly_partitions('orders', COALESCE((SELECT MIN(business_date) FROM orders_unpartitioned), CURRENT_DATE),
+                                 GREATEST((SELECT MAX(business_date) FROM orders_unpartitioned), (CURRENT_DATE + INTERVAL '3 months')::DATE));
+INSERT INTO orders SELECT * FROM orders_unpartitioned;
+DROP TABLE orders_unpartitioned;
+ALTER SEQUENCE orders_id_seq OWNED BY orders.id;
+
+ALTER TABLE orders ADD PRIMARY KEY (id, business_date);
+ALTER TABLE orders ADD FOREIGN KEY (client_id) REFERENCES clients(id);
+ALTER TABLE orders ADD FOREIGN KEY (booking_id) REFERENCES bookings(id);
+ALTER TABLE orders ADD FOREIGN KEY (staff_id) REFERENCES staff_members(id);
+ALTER TABLE orders ADD FOREIGN KEY (table_id) REFERENCES game_tables(id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_daily_number ON orders (branch_code, business_date, daily_number);
+CREATE INDEX IF NOT EXISTS idx_orders_order_time ON orders (order_time);
+CREATE INDEX IF NOT EXISTS idx_orders_order_time_id ON orders (order_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_status_order_time ON orders (status, order_time DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_uuid ON orders (uuid) WHERE uuid IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_uuids (
+    uuid     UUID PRIMARY KEY,
+    order_id BIGINT NOT NULL
+);
+INSERT INTO order_uuids (uuid, order_id) SELECT uuid, id FROM orders WHERE uuid IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION record_order_uuid() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO order_uuids (uuid, order_id) VALUES (NEW.uuid, NEW.id);
+    RETURN NULL;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION delete_order_references() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM order_items WHERE order_id = OLD.id;
+    DELETE FROM order_uuids WHERE order_id = OLD.id;
+    UPDATE client_account_entries SET order_id = NULL WHERE order_id = OLD.id;
+    UPDATE incidents SET order_id = NULL WHERE order_id = OLD.id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER orders_record_uuid AFTER INSERT ON orders
+    FOR EACH ROW WHEN (NEW.uuid IS NOT NULL) EXECUTE FUNCTION record_order_uuid();
+CREATE TRIGGER orders_delete_references AFTER DELETE ON orders
+    FOR EACH ROW EXECUTE FUNCTION delete_order_references();
+
+CREATE MATERIALIZED VIEW report_sales_by_item AS
+SELECT date_trunc('hour', o.order_time AT TIME ZONE 'UTC') AS sold_hour,
+       oi.pricelist_item_id,
+       SUM(oi.quantity) AS total_quantity,
+       SUM(oi.total_price) AS total_sales,
+       SUM(oi.discount_amount) AS total_discount
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE o.status = 'completed'
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_sales_by_item ON report_sales_by_item (sold_hour, pricelist_item_id);
+
+-- Inventory movements
+ALTER TABLE stock_batches DROP CONSTRAINT IF EXISTS stock_batches_movement_id_fkey;
+ALTER TABLE stock_batches DROP CONSTRAINT IF EXISTS stock_batches_write_off_movement_id_fkey;
+ALTER TABLE stock_batch_deductions DROP CONSTRAINT IF EXISTS stock_batch_deductions_movement_id_fkey;
+ALTER TABLE hookah_coal_changes DROP CONSTRAINT IF EXISTS hookah_coal_changes_inventory_movement_id_fkey;
+
+ALTER TABLE inventory_movements RENAME TO inventory_movements_unpartitioned;
+ALTER SEQUENCE inventory_movements_id_seq OWNED BY NONE;
+CREATE TABLE inventory_movements (LIKE inventory_movements_unpartitioned INCLUDING DEFAULTS INCLUDING CONSTRAINTS)
+    PARTITION BY RANGE (movement_date);
+SELECT create_monthly_partitions('inventory_movements',
+                                 COALESCE((SELECT MIN(movement_date AT TIME ZONE 'UTC')::DATE FROM inventory_movements_unpartitioned), CURRENT_DATE),
+                                 GREATEST((SELECT MAX(movement_date AT TIME ZONE 'UTC')::DATE FROM inventory_movements_unpartitioned),
+                                          (CURRENT_DATE + INTERVAL '3 months')::DATE));
+INSERT INTO inventory_movements SELECT * FROM inventory_movements_unpartitioned;
+DROP TABLE inventory_movements_unpartitioned;
+ALTER SEQUENCE inventory_movements_id_seq OWNED BY inventory_movements.id;
+
+ALTER TABLE inventory_movements ADD PRIMARY KEY (id, movement_date);
+ALTER TABLE inventory_movements ADD FOREIGN KEY (pricelist_item_id) REFERENCES pricelist_items(id);
+ALTER TABLE inventory_movements ADD FOREIGN KEY (staff_id) REFERENCES staff_members(id);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_item ON inventory_movements (pricelist_item_id);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_date_id ON inventory_movements (movement_date DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION delete_inventory_movement_references() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    DELETE FROM stock_batch_deductions WHERE movement_id = OLD.id;
+    UPDATE stock_batches SET movement_id = NULL WHERE movement_id = OLD.id;
+    UPDATE stock_batches SET write_off_movement_id = NULL WHERE write_off_movement_id = OLD.id;
+    UPDATE hookah_coal_changes SET inventory_movement_id = NULL WHERE inventory_movement_id = OLD.id;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER inventory_movements_delete_references AFTER DELETE ON inventory_movements
+    FOR EACH ROW EXECUTE FUNCTION delete_inventory_movement_references();
+
+ANALYZE orders;
+ANALYZE inventory_movements;
@@ -0,0 +1,58 @@
+-- Completed orders older than the retention of the order_archive setting are moved, with their
+-- items, to orders_archive and order_items_archive (services.OrderArchiveService), so the hot
+-- tables stay small. Archived orders are read through GET /archive/orders.
+--
+-- The archive tables have the columns of the live tables in the same order, after the archived_at
+-- of an order, so rows are moved as a whole: a column added to orders or order_items must be
+-- added to orders_archive or order_items_archive by the same migration.
+CREATE TABLE IF NOT EXISTS orders_archive (
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    LIKE orders INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_archive_order_time_id ON orders_archive (order_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_archive_client ON orders_archive (client_id) WHERE client_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_items_archive (
+    LIKE order_items INCLUDING CONSTRAINTS,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_archive_order_id ON order_items_archive (order_id);
+
+-- An archived order keeps its UUID, client account entries and incidents, which now refer to
+-- orders_archive; the archival sets ps_club.archiving for its transaction to skip the trigger.
+CREATE OR REPLACE FUNCTION delete_order_references() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF current_setting('ps_club.archiving', true) = 'on' THEN
+        RETURN NULL;
+    END IF;
+    DELETE FROM order_items WHERE order_id = OLD.id;
+    DELETE FROM order_uuids WHERE order_id = OLD.id;
+    UPDATE client_account_entries SET order_id = NULL WHERE order_id = OLD.id;
+    UPDATE incidents SET order_id = NULL WHERE order_id = OLD.id;
+    RETURN NULL;
+END
+$$;
+
+-- The item sales report keeps the sales of archived orders
+DROP MATERIALIZED VIEW IF EXISTS report_sales_by_item;
+
+CREATE MATERIALIZED VIEW report_sales_by_item AS
+SELECT date_trunc('hour', o.order_time AT TIME ZONE 'UTC') AS sold_hour,
+       oi.pricelist_item_id,
+       SUM(oi.quantity) AS total_quantity,
+       SUM(oi.total_price) AS total_sales,
+       SUM(oi.discount_amount) AS total_discount
+FROM (SELECT id, order_time, status FROM orders
+      UNION ALL
+      SELECT id, order_time, status FROM orders_archive) o
+JOIN (SELECT order_id, pricelist_item_id, quantity, total_price, discount_amount FROM order_items
+      UNION ALL
+      SELECT order_id, pricelist_item_id, quantity, total_price, discount_amount FROM order_items_archive) oi ON oi.order_id = o.id
+WHERE o.status = 'completed'
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_report_sales_by_item ON report_sales_by_item (sold_hour, pricelist_item_id);
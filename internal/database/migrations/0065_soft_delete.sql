@@ -0,0 +1,40 @@
+-- Soft delete of clients, pricelist items and bookings. Deleting one sets deleted_at and
+-- deleted_by instead of removing the row, so an Admin can restore it; deleted rows are left
+-- out everywhere else. The unique constraints only hold among the rows not deleted, so the
+-- phone number of a deleted client can be given to a new one (restoring the deleted client
+-- then fails until one of them changes it).
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE pricelist_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE pricelist_items ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+-- The partial unique indexes keep the names of the constraints they replace, which the
+-- services map to their errors.
+ALTER TABLE clients DROP CONSTRAINT IF EXISTS clients_phone_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS clients_phone_number_key ON clients (phone_number) WHERE deleted_at IS NULL;
+ALTER TABLE clients DROP CONSTRAINT IF EXISTS clients_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS clients_email_key ON clients (email) WHERE deleted_at IS NULL;
+ALTER TABLE pricelist_items DROP CONSTRAINT IF EXISTS pricelist_items_sku_key;
+CREATE UNIQUE INDEX IF NOT EXISTS pricelist_items_sku_key ON pricelist_items (sku) WHERE deleted_at IS NULL;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (table_id WITH =, tstzrange(start_time, end_time, '[)') WITH &&)
+    WHERE (status = 'confirmed' AND deleted_at IS NULL);
+
+CREATE INDEX IF NOT EXISTS idx_clients_deleted_at ON clients (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pricelist_items_deleted_at ON pricelist_items (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookings_deleted_at ON bookings (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A soft deleted client or pricelist item leaves a sync tombstone like a deleted one; a
+-- restored one comes back as changed, its update taking the next sync version.
+DROP TRIGGER IF EXISTS pricelist_items_sync_soft_delete ON pricelist_items;
+CREATE TRIGGER pricelist_items_sync_soft_delete AFTER UPDATE OF deleted_at ON pricelist_items
+    FOR EACH ROW WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION record_sync_tombstone('pricelist_item');
+DROP TRIGGER IF EXISTS clients_sync_soft_delete ON clients;
+CREATE TRIGGER clients_sync_soft_delete AFTER UPDATE OF deleted_at ON clients
+    FOR EACH ROW WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+    EXECUTE FUNCTION record_sync_tombstone('client');
@@ -0,0 +1,20 @@
+-- Saved reports: a named report of /reports (report_type) with its query parameters, e.g. the
+-- weekly hookah sales of last week, run by ID instead of being set up again each time. A saved
+-- report with a schedule is emailed to its recipients at next_run_at, after which the next run
+-- is set (services.SavedReportService.RunSchedule).
+CREATE TABLE IF NOT EXISTS saved_reports (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL,
+    report_type  VARCHAR(30) NOT NULL,
+    params       JSONB NOT NULL DEFAULT '{}', -- Query parameters of the report, e.g. {"range": "last_week"}
+    schedule     JSONB,                       -- models.ReportSchedule; NULL if not emailed
+    next_run_at  TIMESTAMPTZ,                 -- Set with the schedule
+    last_run_at  TIMESTAMPTZ,
+    last_error   TEXT,                        -- Of the last scheduled run, NULL if it was emailed
+    created_by   BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS saved_reports_name_key ON saved_reports (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_saved_reports_next_run_at ON saved_reports (next_run_at) WHERE next_run_at IS NOT NULL;
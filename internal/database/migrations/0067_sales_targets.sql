@@ -0,0 +1,18 @@
+-- Sales targets: the revenue a branch aims for from a pricelist category in a month. The
+-- dashboard compares them with the sales so far (GET /dashboard/targets); a target whose pace
+-- falls far behind after mid-month is notified of once (services.SalesTargetService.RunBehindCheck),
+-- again only if its amount is changed.
+CREATE TABLE IF NOT EXISTS sales_targets (
+    id                 BIGSERIAL PRIMARY KEY,
+    branch_code        VARCHAR(20) NOT NULL,
+    category_id        BIGINT NOT NULL REFERENCES pricelist_categories(id) ON DELETE CASCADE,
+    month              DATE NOT NULL CHECK (EXTRACT(DAY FROM month) = 1), -- First day of the month
+    amount             NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    behind_notified_at TIMESTAMPTZ,
+    created_by         BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT sales_targets_branch_category_month_key UNIQUE (branch_code, category_id, month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_targets_month ON sales_targets (month, branch_code);
@@ -0,0 +1,15 @@
+-- Anomaly checks: the revenue, discounts and cancelled and deleted orders of a business day
+-- compared with their averages over the trailing days, checked once per branch and day after
+-- the day ends (services.AnomalyService.RunNightlyCheck). anomalies lists the metrics that
+-- deviated beyond the threshold of the anomaly_detection setting; each check with any is
+-- notified of with an anomaly.detected event.
+CREATE TABLE IF NOT EXISTS anomaly_checks (
+    id            BIGSERIAL PRIMARY KEY,
+    branch_code   VARCHAR(20) NOT NULL,
+    business_date DATE NOT NULL,
+    metrics       JSONB NOT NULL,              -- models.RiskMetrics of the day
+    averages      JSONB,                       -- models.RiskMetrics averaged over the trailing days; NULL without enough history
+    anomalies     JSONB NOT NULL DEFAULT '[]', -- []models.Anomaly
+    checked_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT anomaly_checks_branch_date_key UNIQUE (branch_code, business_date)
+);
@@ -0,0 +1,22 @@
+-- Voids: orders cancelled and items voided from open orders, each with the reason code it was
+-- given, for the void report (GET /reports/voids). The orders are partitioned, so order_id has
+-- no foreign key; a void is kept when its order is deleted or archived. time_clock_entry_id is
+-- the shift the staff member who voided was clocked in for at the time, if any.
+CREATE TABLE IF NOT EXISTS order_voids (
+    id                  BIGSERIAL PRIMARY KEY,
+    branch_code         VARCHAR(20) NOT NULL,
+    order_id            BIGINT NOT NULL,
+    order_number        VARCHAR(30) NOT NULL,
+    order_item_id       BIGINT,                  -- NULL when the whole order was cancelled
+    pricelist_item_id   BIGINT REFERENCES pricelist_items(id) ON DELETE SET NULL,
+    quantity            INT,
+    amount              NUMERIC(18, 4) NOT NULL, -- Taken off the final amount of the order
+    reason              VARCHAR(30) NOT NULL,
+    note                TEXT,
+    order_staff_id      BIGINT REFERENCES users(id) ON DELETE SET NULL, -- Took the order
+    voided_by           BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    time_clock_entry_id BIGINT REFERENCES time_clock_entries(id) ON DELETE SET NULL,
+    voided_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_voids_voided_at ON order_voids (voided_at, branch_code);
@@ -0,0 +1,27 @@
+-- Promo codes: a percentage off an order, handed out to clients, e.g. on flyers. issued_count is
+-- how many were handed out, which the discount report (GET /reports/discounts) compares the
+-- redemptions with. A code is deactivated rather than deleted, so its redemptions are kept.
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id               BIGSERIAL PRIMARY KEY,
+    code             VARCHAR(30) NOT NULL, -- Upper case
+    description      TEXT,
+    discount_percent NUMERIC(5, 2) NOT NULL CHECK (discount_percent > 0 AND discount_percent <= 100),
+    issued_count     INT NOT NULL DEFAULT 0 CHECK (issued_count >= 0),
+    active           BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT promo_codes_code_key UNIQUE (code)
+);
+
+-- The orders a promo code was redeemed for. The orders are partitioned, so order_id has no
+-- foreign key; the report joins the orders, leaving out those deleted or archived.
+CREATE TABLE IF NOT EXISTS promo_code_redemptions (
+    order_id        BIGINT PRIMARY KEY,
+    branch_code     VARCHAR(20) NOT NULL,
+    promo_code_id   BIGINT NOT NULL REFERENCES promo_codes(id),
+    discount_amount NUMERIC(18, 4) NOT NULL,
+    redeemed_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_code_redemptions_code ON promo_code_redemptions (promo_code_id, redeemed_at);
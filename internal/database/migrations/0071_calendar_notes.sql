@@ -0,0 +1,18 @@
+-- Calendar notes: dates annotated by the admins with what may explain their demand, e.g. a city
+-- event or school holidays, shown with the reports that cover them. A note without a branch
+-- applies to every branch.
+CREATE TABLE IF NOT EXISTS calendar_notes (
+    id          BIGSERIAL PRIMARY KEY,
+    branch_code VARCHAR(20),
+    start_date  DATE NOT NULL,
+    end_date    DATE NOT NULL, -- Inclusive
+    category    VARCHAR(30) NOT NULL,
+    title       VARCHAR(200) NOT NULL,
+    note        TEXT,
+    created_by  BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT calendar_notes_dates_check CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_notes_dates ON calendar_notes (start_date, end_date);
@@ -0,0 +1,42 @@
+-- Push notifications of the client app through Firebase Cloud Messaging. The app registers the
+-- FCM token of each device a client signs in on; a token belongs to one client at a time, the
+-- last to register it.
+CREATE TABLE IF NOT EXISTS client_push_devices (
+    id           BIGSERIAL PRIMARY KEY,
+    client_id    BIGINT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    token        TEXT NOT NULL,
+    platform     VARCHAR(10) NOT NULL, -- android, ios or web
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT client_push_devices_token_key UNIQUE (token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_push_devices_client ON client_push_devices (client_id);
+
+-- The kinds of push notifications a client turned off; a client without a row receives all.
+CREATE TABLE IF NOT EXISTS client_push_preferences (
+    client_id             BIGINT PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    booking_confirmations BOOLEAN NOT NULL DEFAULT TRUE,
+    table_ready           BOOLEAN NOT NULL DEFAULT TRUE,
+    loyalty_milestones    BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Every push sent or attempted, per device. event_id is the domain event it was sent for, so a
+-- redelivered event is not pushed twice to a device it already reached.
+CREATE TABLE IF NOT EXISTS push_deliveries (
+    id         BIGSERIAL PRIMARY KEY,
+    client_id  BIGINT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    device_id  BIGINT REFERENCES client_push_devices(id) ON DELETE SET NULL,
+    event_id   BIGINT,
+    kind       VARCHAR(30) NOT NULL,
+    title      TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    status     VARCHAR(20) NOT NULL, -- sent, failed or unregistered
+    error      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_deliveries_client ON push_deliveries (client_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_push_deliveries_created ON push_deliveries (created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_deliveries_event_sent ON push_deliveries (event_id, device_id) WHERE status = 'sent';
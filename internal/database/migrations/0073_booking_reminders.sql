@@ -0,0 +1,28 @@
+-- Reminders of upcoming bookings by SMS, Telegram or push. A client without a row, or with
+-- NULL in a column, follows the booking_reminders setting.
+CREATE TABLE IF NOT EXISTS client_reminder_preferences (
+    client_id        BIGINT PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    channel          VARCHAR(20), -- sms, telegram, push or none
+    lead_minutes     INTEGER,
+    telegram_chat_id BIGINT,      -- The chat of the Telegram bot with the client
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The reminder of each booking, claimed by the dispatcher before it is sent so that several
+-- instances send it once. booking_start is the start time reminded of, so a booking moved to
+-- another time is reminded again.
+CREATE TABLE IF NOT EXISTS booking_reminders (
+    id            BIGSERIAL PRIMARY KEY,
+    booking_id    BIGINT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    client_id     BIGINT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    booking_start TIMESTAMPTZ NOT NULL,
+    channel       VARCHAR(20) NOT NULL,
+    status        VARCHAR(20) NOT NULL, -- sending, sent or failed
+    error         TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at       TIMESTAMPTZ,
+    CONSTRAINT booking_reminders_booking_start_key UNIQUE (booking_id, booking_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_reminders_client ON booking_reminders (client_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_booking_reminders_created ON booking_reminders (created_at);
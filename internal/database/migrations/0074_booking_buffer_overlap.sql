@@ -0,0 +1,37 @@
+-- Each booking records the cleanup buffer it was made with (the table's buffer_minutes, or
+-- the booking_policy default), and bookings_no_overlap covers it: a confirmed booking takes
+-- its table from its start until its end plus its buffer. Two requests that pass the
+-- availability check at once can then not book within the buffer of each other either.
+-- The range is built in UTC because adding an interval to a timestamptz is not immutable.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS buffer_minutes INTEGER NOT NULL DEFAULT 0 CHECK (buffer_minutes >= 0);
+
+-- Upcoming bookings take the buffer of their table, unless another booking already sits
+-- within it; those keep no buffer so that adding the constraint cannot fail.
+WITH policy AS (
+    SELECT substring(setting_value FROM '"buffer_minutes"\s*:\s*(\d+)')::int AS buffer_minutes
+    FROM application_settings WHERE setting_key = 'booking_policy'
+), buffered AS (
+    SELECT b.id, b.table_id, b.start_time, b.end_time,
+           make_interval(mins => COALESCE(gt.buffer_minutes, (SELECT buffer_minutes FROM policy), 0)) AS buffer
+    FROM bookings b
+    JOIN game_tables gt ON gt.id = b.table_id
+    WHERE b.status = 'confirmed' AND b.deleted_at IS NULL AND b.end_time > NOW()
+)
+UPDATE bookings SET buffer_minutes = EXTRACT(EPOCH FROM buffered.buffer)::int / 60
+FROM buffered
+WHERE bookings.id = buffered.id AND buffered.buffer > INTERVAL '0'
+  AND NOT EXISTS (
+      SELECT 1 FROM bookings other
+      WHERE other.table_id = buffered.table_id AND other.id <> buffered.id
+        AND other.status = 'confirmed' AND other.deleted_at IS NULL
+        AND other.start_time < buffered.end_time + buffered.buffer
+        AND buffered.start_time < other.end_time + buffered.buffer
+  );
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings ADD CONSTRAINT bookings_no_overlap
+    EXCLUDE USING gist (
+        table_id WITH =,
+        tsrange(start_time AT TIME ZONE 'UTC', (end_time AT TIME ZONE 'UTC') + make_interval(mins => buffer_minutes), '[)') WITH &&
+    )
+    WHERE (status = 'confirmed' AND deleted_at IS NULL);
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"ps_club_backend/internal/models"
+)
+
+// AllEvents subscribes a handler to every event type.
+const AllEvents = "*"
+
+// Handler processes a delivered event. Returning an error makes the relay retry
+// the event later, for all of its subscribers.
+type Handler func(ctx context.Context, event models.DomainEvent) error
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus dispatches events to the in-process subscribers registered for their type.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+}
+
+// NewBus creates a Bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{subscriptions: make(map[string][]subscription)}
+}
+
+// Subscribe registers handler for eventType (or AllEvents). name identifies the
+// subscriber in logs and delivery errors.
+func (b *Bus) Subscribe(eventType, name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{name: name, handler: handler})
+}
+
+// Dispatch calls every subscriber of the event and joins their errors. A panicking
+// subscriber is reported as an error instead of stopping the relay.
+func (b *Bus) Dispatch(ctx context.Context, event models.DomainEvent) error {
+	b.mu.RLock()
+	subs := make([]subscription, 0, len(b.subscriptions[event.EventType])+len(b.subscriptions[AllEvents]))
+	subs = append(subs, b.subscriptions[event.EventType]...)
+	subs = append(subs, b.subscriptions[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if err := callHandler(ctx, sub, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func callHandler(ctx context.Context, sub subscription, event models.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber %s panicked: %v", sub.name, r)
+		}
+	}()
+	if err := sub.handler(ctx, event); err != nil {
+		return fmt.Errorf("subscriber %s: %w", sub.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package events records domain events in a transactional outbox and relays
+// them to in-process subscribers (webhooks, notifications, analytics).
+//
+// Services publish an event with the transaction of the business change, so an
+// event exists if and only if the change was committed. The Relay then delivers
+// it at least once; subscribers must be idempotent, e.g. by remembering event IDs.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// Aggregate types, i.e. the kind of record an event is about.
+const (
+	AggregateOrder         = "order"
+	AggregateBooking       = "booking"
+	AggregatePricelistItem = "pricelist_item"
+	AggregateStaff         = "staff"
+	AggregateTableSession  = "table_session"
+	AggregateOrderItem     = "order_item"
+	AggregateGameTable     = "game_table"
+	AggregateSalesTarget   = "sales_target"
+	AggregateAnomalyCheck  = "anomaly_check"
+	AggregateClient        = "client"
+)
+
+// Event types. A status change publishes "<aggregate>.<new status>", so the
+// constants below cover the common ones but are not exhaustive.
+const (
+	OrderCreated         = "order.created"
+	OrderDiscounted      = "order.discounted" // Published with order.created when the order has a discount
+	OrderCompleted       = "order.completed"
+	OrderPaid            = "order.paid"
+	OrderCancelled       = "order.cancelled"
+	OrderRefunded        = "order.refunded"
+	BookingCreated       = "booking.created"
+	BookingCompleted     = "booking.completed"
+	BookingCancelled     = "booking.cancelled"
+	BookingNoShow        = "booking.no-show"
+	BookingCheckedIn     = "booking.checked_in"    // The client checked in at the kiosk
+	InventoryWrittenOff  = "inventory.written_off" // Spoilage or a manual stock decrease
+	StaffClockedIn       = "staff.clocked_in"
+	StaffClockedOut      = "staff.clocked_out"
+	StaffShiftReported   = "staff.shift_reported"  // The end-of-shift report of a clock-out was created
+	StaffDocumentExpiry  = "staff.document_expiry" // A document of a staff member expires soon or has expired
+	TableSessionStarted  = "table_session.started"
+	TableSessionWarning  = "table_session.warning"  // Published at 10 and 5 minutes before the time limit
+	TableSessionOvertime = "table_session.overtime" // The time limit passed and the session continues as overtime
+	TableSessionStopped  = "table_session.stopped"
+	HookahCoalChangeDue  = "hookah.coal_change_due" // The coals of a hookah are due to be changed
+	HookahCoalChanged    = "hookah.coal_changed"
+	HookahEnded          = "hookah.ended" // The hookah was taken away
+	TableStatusChanged   = "game_table.status_changed"
+	SalesTargetBehind    = "sales_target.behind" // The sales of a category fall far behind its monthly target
+	AnomalyDetected      = "anomaly.detected"    // The nightly check found metrics of a day far off their trailing average
+	ClientLoyaltyChanged = "client.loyalty_changed"
+)
+
+// OrderStatusEvent returns the event type published when an order enters status.
+func OrderStatusEvent(status string) string { return AggregateOrder + "." + status }
+
+// BookingStatusEvent returns the event type published when a booking enters status.
+func BookingStatusEvent(status string) string { return AggregateBooking + "." + status }
+
+// OrderPayload is the payload of order events.
+type OrderPayload struct {
+	OrderID        int64         `json:"order_id"`
+	OrderNumber    string        `json:"order_number"`
+	Status         string        `json:"status"`
+	PreviousStatus string        `json:"previous_status,omitempty"`
+	ClientID       *int64        `json:"client_id,omitempty"`
+	BookingID      *int64        `json:"booking_id,omitempty"`
+	StaffID        *int64        `json:"staff_id,omitempty"`
+	TableID        *int64        `json:"table_id,omitempty"`
+	TotalAmount    models.Money  `json:"total_amount"`
+	DiscountAmount *models.Money `json:"discount_amount,omitempty"`
+	FinalAmount    models.Money  `json:"final_amount"`
+}
+
+// NewOrderPayload builds the payload of an event about order; previousStatus is empty for order.created.
+func NewOrderPayload(order *models.Order, previousStatus string) OrderPayload {
+	return OrderPayload{
+		OrderID:        order.ID,
+		OrderNumber:    order.OrderNumber,
+		Status:         order.Status,
+		PreviousStatus: previousStatus,
+		ClientID:       order.ClientID,
+		BookingID:      order.BookingID,
+		StaffID:        order.StaffID,
+		TableID:        order.TableID,
+		TotalAmount:    order.TotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		FinalAmount:    order.FinalAmount,
+	}
+}
+
+// BookingPayload is the payload of booking events.
+type BookingPayload struct {
+	BookingID      int64     `json:"booking_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	ClientID       *int64    `json:"client_id,omitempty"`
+	TableID        int64     `json:"table_id"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	// CancellationReason is set on booking.cancelled, one of models.CancellationReasons
+	CancellationReason *string `json:"cancellation_reason,omitempty"`
+	// CancellationFee is set on booking.cancelled if a late cancellation fee was charged
+	CancellationFee *models.Money `json:"cancellation_fee,omitempty"`
+	// CheckedInAt is set once the client checked in
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+// NewBookingPayload builds the payload of an event about booking; previousStatus is empty for booking.created.
+func NewBookingPayload(booking *models.Booking, previousStatus string) BookingPayload {
+	payload := BookingPayload{
+		BookingID:      booking.ID,
+		Status:         booking.Status,
+		PreviousStatus: previousStatus,
+		ClientID:       booking.ClientID,
+		TableID:        booking.TableID,
+		StartTime:      booking.StartTime,
+		EndTime:        booking.EndTime,
+		CheckedInAt:    booking.CheckedInAt,
+	}
+	if booking.Status == string(models.BookingStatusCancelled) {
+		payload.CancellationReason = booking.CancellationReason
+		payload.CancellationFee = booking.CancellationFee
+	}
+	return payload
+}
+
+// InventoryPayload is the payload of inventory events.
+type InventoryPayload struct {
+	MovementID      int64           `json:"movement_id"`
+	PricelistItemID int64           `json:"pricelist_item_id"`
+	ItemName        string          `json:"item_name"`
+	MovementType    string          `json:"movement_type"`
+	Quantity        models.Quantity `json:"quantity"` // Removed from stock, positive
+	StockUnit       string          `json:"stock_unit,omitempty"`
+	StaffID         *int64          `json:"staff_id,omitempty"`
+	Reason          *string         `json:"reason,omitempty"`
+}
+
+// TimeClockPayload is the payload of staff clock-in and clock-out events.
+type TimeClockPayload struct {
+	EntryID    int64      `json:"entry_id"`
+	StaffID    int64      `json:"staff_id"`
+	StaffName  string     `json:"staff_name"`
+	ClockInAt  time.Time  `json:"clock_in_at"`
+	ClockOutAt *time.Time `json:"clock_out_at,omitempty"`
+}
+
+// ShiftReportPayload is the payload of staff.shift_reported.
+type ShiftReportPayload struct {
+	ReportID         int64        `json:"report_id"`
+	TimeClockEntryID int64        `json:"time_clock_entry_id"`
+	StaffID          int64        `json:"staff_id"`
+	StaffName        string       `json:"staff_name"`
+	ShiftID          *int64       `json:"shift_id,omitempty"`
+	SalesTotal       models.Money `json:"sales_total"`
+	// CashDifference is counted minus expected cash, set if the drawer was counted
+	CashDifference *models.Money `json:"cash_difference,omitempty"`
+	OpenOrderCount int           `json:"open_order_count"`
+}
+
+// StaffDocumentPayload is the payload of staff.document_expiry.
+type StaffDocumentPayload struct {
+	DocumentID   int64   `json:"document_id"`
+	StaffID      int64   `json:"staff_id"`
+	StaffName    string  `json:"staff_name"`
+	DocumentType string  `json:"document_type"`
+	Title        *string `json:"title,omitempty"`
+	ExpiresOn    string  `json:"expires_on"` // YYYY-MM-DD
+}
+
+// SalesTargetPayload is the payload of sales_target.behind.
+type SalesTargetPayload struct {
+	TargetID     int64        `json:"target_id"`
+	BranchCode   string       `json:"branch_code"`
+	CategoryID   int64        `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Month        string       `json:"month"` // YYYY-MM
+	Target       models.Money `json:"target"`
+	Actual       models.Money `json:"actual"`
+	Projected    models.Money `json:"projected"`
+}
+
+// LoyaltyPayload is the payload of client.loyalty_changed.
+type LoyaltyPayload struct {
+	ClientID       int64 `json:"client_id"`
+	Points         int   `json:"points"`
+	PreviousPoints int   `json:"previous_points"`
+}
+
+// AnomalyPayload is the payload of anomaly.detected.
+type AnomalyPayload struct {
+	CheckID      int64            `json:"check_id"`
+	BranchCode   string           `json:"branch_code"`
+	BusinessDate string           `json:"business_date"` // YYYY-MM-DD
+	Anomalies    []models.Anomaly `json:"anomalies"`
+}
+
+// TableSessionPayload is the payload of table session events.
+type TableSessionPayload struct {
+	SessionID int64      `json:"session_id"`
+	TableID   int64      `json:"table_id"`
+	BookingID *int64     `json:"booking_id,omitempty"`
+	ClientID  *int64     `json:"client_id,omitempty"`
+	Status    string     `json:"status"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	// RemainingMinutes is set on table_session.warning
+	RemainingMinutes *int `json:"remaining_minutes,omitempty"`
+	// AutoStopped is set on table_session.stopped when the session was stopped at its time limit
+	AutoStopped     bool          `json:"auto_stopped,omitempty"`
+	OvertimeMinutes int           `json:"overtime_minutes,omitempty"`
+	OvertimeAmount  *models.Money `json:"overtime_amount,omitempty"`
+	// TotalAmount is set on table_session.stopped: the bill of the session, overtime included
+	TotalAmount *models.Money `json:"total_amount,omitempty"`
+}
+
+// NewTableSessionPayload builds the payload of an event about session.
+func NewTableSessionPayload(session *models.TableSession) TableSessionPayload {
+	payload := TableSessionPayload{
+		SessionID:       session.ID,
+		TableID:         session.TableID,
+		BookingID:       session.BookingID,
+		ClientID:        session.ClientID,
+		Status:          session.Status,
+		EndsAt:          session.EndsAt,
+		AutoStopped:     session.Status == models.TableSessionStatusStopped && session.StoppedBy == nil,
+		OvertimeMinutes: session.OvertimeMinutes,
+	}
+	if session.OvertimeMinutes > 0 {
+		payload.OvertimeAmount = &session.OvertimeAmount
+	}
+	if session.Status == models.TableSessionStatusStopped {
+		payload.TotalAmount = &session.TotalAmount
+	}
+	return payload
+}
+
+// TableStatusPayload is the payload of game_table.status_changed.
+type TableStatusPayload struct {
+	TableID        int64  `json:"table_id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	// ChangedBy is the user who set the status; nil when it followed a booking or session
+	ChangedBy *int64 `json:"changed_by,omitempty"`
+}
+
+// HookahPayload is the payload of hookah events.
+type HookahPayload struct {
+	OrderItemID   int64      `json:"order_item_id"`
+	OrderID       int64      `json:"order_id"`
+	TableID       *int64     `json:"table_id,omitempty"`
+	ItemName      string     `json:"item_name"`
+	CoalChangedAt *time.Time `json:"coal_changed_at,omitempty"`
+	// DueAt is set on hookah.coal_change_due: when the coals were due to be changed
+	DueAt *time.Time `json:"due_at,omitempty"`
+	// Coals is set on hookah.coal_changed
+	Coals *int `json:"coals,omitempty"`
+}
+
+// NewHookahPayload builds the payload of an event about hookah.
+func NewHookahPayload(hookah *models.Hookah) HookahPayload {
+	return HookahPayload{
+		OrderItemID:   hookah.OrderItemID,
+		OrderID:       hookah.OrderID,
+		TableID:       hookah.TableID,
+		ItemName:      hookah.ItemName,
+		CoalChangedAt: hookah.CoalChangedAt,
+	}
+}
+
+// Publisher records domain events in the outbox.
+type Publisher interface {
+	// Publish records an event; executor must be the transaction of the change the event describes.
+	Publish(executor repositories.SQLExecutor, eventType, aggregateType string, aggregateID int64, payload interface{}) error
+}
+
+type outboxPublisher struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+// NewPublisher creates a Publisher that writes to the outbox table.
+func NewPublisher(outboxRepo repositories.OutboxRepository) Publisher {
+	return &outboxPublisher{outboxRepo: outboxRepo}
+}
+
+func (p *outboxPublisher) Publish(executor repositories.SQLExecutor, eventType, aggregateType string, aggregateID int64, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event payload: %w", eventType, err)
+	}
+	event := models.DomainEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	}
+	if _, err := p.outboxRepo.CreateEvent(executor, &event); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
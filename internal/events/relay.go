@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// Relay defaults.
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 100
+	DefaultRetention    = 7 * 24 * time.Hour // Published events are kept this long for troubleshooting
+
+	minRetryDelay   = 5 * time.Second
+	maxRetryDelay   = time.Hour
+	cleanupInterval = time.Hour
+)
+
+// Relay delivers pending outbox events to the bus. Each batch is claimed with
+// row locks inside a transaction, so several instances can run a relay at once
+// without delivering the same event concurrently. Failed events are retried
+// with exponential backoff (5s doubling up to 1h) until every subscriber succeeds.
+type Relay struct {
+	db           *sql.DB
+	outboxRepo   repositories.OutboxRepository
+	bus          *Bus
+	PollInterval time.Duration
+	BatchSize    int
+	Retention    time.Duration // 0 keeps published events forever
+}
+
+// NewRelay creates a Relay with the default settings.
+func NewRelay(db *sql.DB, outboxRepo repositories.OutboxRepository, bus *Bus) *Relay {
+	return &Relay{
+		db:           db,
+		outboxRepo:   outboxRepo,
+		bus:          bus,
+		PollInterval: DefaultPollInterval,
+		BatchSize:    DefaultBatchSize,
+		Retention:    DefaultRetention,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+	var lastCleanup time.Time
+
+	for {
+		// Drain full batches right away; wait for the next tick once caught up.
+		for {
+			delivered, err := r.RelayBatch(ctx)
+			if err != nil {
+				utils.LogError(err, "Outbox relay failed")
+				break
+			}
+			if delivered < r.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if r.Retention > 0 && time.Since(lastCleanup) >= cleanupInterval {
+			lastCleanup = time.Now()
+			if deleted, err := r.outboxRepo.DeletePublishedEventsBefore(time.Now().UTC().Add(-r.Retention)); err != nil {
+				utils.LogError(err, "Failed to delete published outbox events")
+			} else if deleted > 0 {
+				utils.LogDebug("Deleted published outbox events", map[string]interface{}{"count": deleted})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch claims one batch of due events, dispatches them and records the
+// outcome. It returns the number of events claimed.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	pending, err := r.outboxRepo.ClaimPendingEvents(tx, time.Now().UTC(), r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range pending {
+		now := time.Now().UTC()
+		if dispatchErr := r.bus.Dispatch(ctx, event); dispatchErr != nil {
+			utils.LogError(dispatchErr, fmt.Sprintf("Delivery of outbox event %d (%s) failed, will retry", event.ID, event.EventType))
+			if err := r.outboxRepo.MarkEventFailed(tx, event.ID, dispatchErr.Error(), now.Add(RetryDelay(event.Attempts+1))); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := r.outboxRepo.MarkEventPublished(tx, event.ID, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox transaction: %w", err)
+	}
+	return len(pending), nil
+}
+
+// RetryDelay returns how long to wait before the next delivery after the given number of failed attempts.
+func RetryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// complexityLimit rejects queries that would fan out into too many nested lists.
+const complexityLimit = 1000
+
+// NewHandler returns the GraphQL endpoint. It only accepts queries over GET and POST;
+// the schema has no mutations or subscriptions.
+func NewHandler(resolver *Resolver) http.Handler {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(complexityLimit))
+	srv.SetErrorPresenter(presentError)
+	return srv
+}
+
+// presentError hides database errors from clients, like the REST handlers do, and logs them instead.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if errors.Is(err, repositories.ErrDatabaseError) {
+		utils.LogError(err, "GraphQL query failed")
+		gqlErr.Message = "internal server error"
+	}
+	return gqlErr
+}
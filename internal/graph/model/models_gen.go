@@ -0,0 +1,62 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package model
+
+import (
+	"ps_club_backend/internal/models"
+)
+
+type BookingFilter struct {
+	ClientID *int64  `json:"clientId,omitempty"`
+	TableID  *int64  `json:"tableId,omitempty"`
+	StaffID  *int64  `json:"staffId,omitempty"`
+	Status   *string `json:"status,omitempty"`
+	// Club-local day, YYYY-MM-DD, inclusive.
+	DateFrom *string `json:"dateFrom,omitempty"`
+	// Club-local day, YYYY-MM-DD, inclusive.
+	DateTo *string `json:"dateTo,omitempty"`
+}
+
+type BookingPage struct {
+	Items    []*models.Booking `json:"items"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type ClientPage struct {
+	Items    []*models.Client `json:"items"`
+	PageInfo *PageInfo        `json:"pageInfo"`
+}
+
+type OrderFilter struct {
+	ClientID *int64  `json:"clientId,omitempty"`
+	StaffID  *int64  `json:"staffId,omitempty"`
+	TableID  *int64  `json:"tableId,omitempty"`
+	Status   *string `json:"status,omitempty"`
+	// Club-local day, YYYY-MM-DD.
+	Date *string `json:"date,omitempty"`
+}
+
+type OrderPage struct {
+	Items    []*models.Order `json:"items"`
+	PageInfo *PageInfo       `json:"pageInfo"`
+}
+
+type PageInfo struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+type PricelistCategoryPage struct {
+	Items    []*models.PricelistCategory `json:"items"`
+	PageInfo *PageInfo                   `json:"pageInfo"`
+}
+
+type PricelistItemPage struct {
+	Items    []*models.PricelistItem `json:"items"`
+	PageInfo *PageInfo               `json:"pageInfo"`
+}
+
+type Query struct {
+}
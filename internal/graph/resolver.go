@@ -0,0 +1,114 @@
+// Package graph serves the read-only GraphQL API for the admin dashboard. The
+// schema lives in schema.graphqls; generated.go and model/ are generated by gqlgen.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
+
+import (
+	"errors"
+
+	"ps_club_backend/internal/graph/model"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+)
+
+// Paging defaults, matching the REST list endpoints. maxPageSize bounds the
+// work a single nested query can cause.
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// Resolver resolves GraphQL queries through the service layer.
+type Resolver struct {
+	ClientService    services.ClientService
+	BookingService   services.BookingService
+	OrderService     services.OrderService
+	PricelistService services.PricelistService
+	StaffService     services.StaffService
+	ReportService    services.ReportService
+}
+
+func (r *Resolver) client(id int64) (*models.Client, error) {
+	client, err := r.ClientService.GetClientByID(id)
+	if errors.Is(err, services.ErrClientNotFound) {
+		return nil, nil
+	}
+	return client, err
+}
+
+func (r *Resolver) bookings(filter *model.BookingFilter, page, pageSize *int) (*model.BookingPage, error) {
+	p, ps := pageArgs(page, pageSize)
+	filters := models.BookingFilters{Page: p, PageSize: ps}
+	if filter != nil {
+		dateFrom, dateTo, err := utils.ParseClubDateRange(stringValue(filter.DateFrom), stringValue(filter.DateTo))
+		if err != nil {
+			return nil, errors.New("invalid date, use YYYY-MM-DD")
+		}
+		filters.ClientID = filter.ClientID
+		filters.TableID = filter.TableID
+		filters.StaffID = filter.StaffID
+		filters.Status = filter.Status
+		filters.DateFrom = dateFrom
+		filters.DateTo = dateTo
+	}
+	bookings, total, err := r.BookingService.GetBookings(filters)
+	if err != nil {
+		return nil, err
+	}
+	return &model.BookingPage{Items: pointers(bookings), PageInfo: newPageInfo(p, ps, total)}, nil
+}
+
+func (r *Resolver) orders(filter *model.OrderFilter, page, pageSize *int) (*model.OrderPage, error) {
+	p, ps := pageArgs(page, pageSize)
+	filters := models.OrderFilters{Page: p, PageSize: ps}
+	if filter != nil {
+		filters.ClientID = filter.ClientID
+		filters.StaffID = filter.StaffID
+		filters.TableID = filter.TableID
+		filters.Status = filter.Status
+		filters.Date = filter.Date
+	}
+	orders, total, err := r.OrderService.GetOrders(filters)
+	if err != nil {
+		return nil, err
+	}
+	return &model.OrderPage{Items: pointers(orders), PageInfo: newPageInfo(p, ps, total)}, nil
+}
+
+func pageArgs(page, pageSize *int) (int, int) {
+	p, ps := defaultPage, defaultPageSize
+	if page != nil && *page > 0 {
+		p = *page
+	}
+	if pageSize != nil && *pageSize > 0 {
+		ps = min(*pageSize, maxPageSize)
+	}
+	return p, ps
+}
+
+func newPageInfo(page, pageSize, total int) *model.PageInfo {
+	return &model.PageInfo{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}
+}
+
+func pointers[T any](values []T) []*T {
+	out := make([]*T, len(values))
+	for i := range values {
+		out[i] = &values[i]
+	}
+	return out
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
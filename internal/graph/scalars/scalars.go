@@ -0,0 +1,69 @@
+// Package scalars marshals the custom GraphQL scalars of the dashboard schema.
+package scalars
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// MarshalID renders database IDs as GraphQL IDs, which are strings on the wire.
+func MarshalID(id int64) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(strconv.FormatInt(id, 10)))
+	})
+}
+
+// UnmarshalID accepts an ID given as a string or an integer.
+func UnmarshalID(v any) (int64, error) {
+	switch v := v.(type) {
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ID %q", v)
+		}
+		return id, nil
+	default:
+		return graphql.UnmarshalInt64(v)
+	}
+}
+
+// MarshalMoney renders an amount as a decimal string rounded to the currency decimals,
+// so clients never see binary floating point values.
+func MarshalMoney(m models.Money) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		text, _ := m.MarshalJSON()
+		_, _ = io.WriteString(w, strconv.Quote(string(text)))
+	})
+}
+
+// UnmarshalMoney accepts an amount given as a decimal string or number.
+func UnmarshalMoney(v any) (models.Money, error) {
+	switch v := v.(type) {
+	case string:
+		return models.ParseMoney(v)
+	default:
+		return models.ParseMoney(fmt.Sprint(v))
+	}
+}
+
+// MarshalQuantity renders a stock quantity as a decimal string.
+func MarshalQuantity(q models.Quantity) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(q.String()))
+	})
+}
+
+// UnmarshalQuantity accepts a quantity given as a decimal string or number.
+func UnmarshalQuantity(v any) (models.Quantity, error) {
+	switch v := v.(type) {
+	case string:
+		return models.ParseQuantity(v)
+	default:
+		return models.ParseQuantity(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,196 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.76
+
+import (
+	"context"
+	"errors"
+	"ps_club_backend/internal/graph/model"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+)
+
+// Client is the resolver for the client field.
+func (r *bookingResolver) Client(ctx context.Context, obj *models.Booking) (*models.Client, error) {
+	if obj.ClientID == nil {
+		return nil, nil
+	}
+	return r.client(*obj.ClientID)
+}
+
+// Staff is the resolver for the staff field.
+func (r *bookingResolver) Staff(ctx context.Context, obj *models.Booking) (*models.StaffMember, error) {
+	if obj.StaffID == nil {
+		return nil, nil
+	}
+	staff, err := r.StaffService.GetStaffMemberByID(*obj.StaffID)
+	if errors.Is(err, services.ErrStaffNotFound) {
+		return nil, nil
+	}
+	return staff, err
+}
+
+// Bookings is the resolver for the bookings field.
+func (r *clientResolver) Bookings(ctx context.Context, obj *models.Client, page *int, pageSize *int) (*model.BookingPage, error) {
+	return r.bookings(&model.BookingFilter{ClientID: &obj.ID}, page, pageSize)
+}
+
+// Orders is the resolver for the orders field.
+func (r *clientResolver) Orders(ctx context.Context, obj *models.Client, page *int, pageSize *int) (*model.OrderPage, error) {
+	return r.orders(&model.OrderFilter{ClientID: &obj.ID}, page, pageSize)
+}
+
+// Client is the resolver for the client field.
+func (r *orderResolver) Client(ctx context.Context, obj *models.Order) (*models.Client, error) {
+	if obj.ClientID == nil {
+		return nil, nil
+	}
+	return r.client(*obj.ClientID)
+}
+
+// Booking is the resolver for the booking field.
+func (r *orderResolver) Booking(ctx context.Context, obj *models.Order) (*models.Booking, error) {
+	if obj.BookingID == nil {
+		return nil, nil
+	}
+	booking, err := r.BookingService.GetBookingByID(*obj.BookingID)
+	if errors.Is(err, services.ErrBookingNotFound) {
+		return nil, nil
+	}
+	return booking, err
+}
+
+// Items is the resolver for the items field.
+func (r *orderResolver) Items(ctx context.Context, obj *models.Order) ([]*models.OrderItem, error) {
+	items := obj.OrderItems
+	if items == nil {
+		// Orders are listed without their items
+		var err error
+		if items, err = r.OrderService.GetOrderItems(obj.ID); err != nil {
+			return nil, err
+		}
+	}
+	return pointers(items), nil
+}
+
+// PricelistItem is the resolver for the pricelistItem field.
+func (r *orderItemResolver) PricelistItem(ctx context.Context, obj *models.OrderItem) (*models.PricelistItem, error) {
+	item, err := r.PricelistService.GetItemByID(obj.PricelistItemID)
+	if errors.Is(err, services.ErrItemNotFound) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// Category is the resolver for the category field.
+func (r *pricelistItemResolver) Category(ctx context.Context, obj *models.PricelistItem) (*models.PricelistCategory, error) {
+	category, err := r.PricelistService.GetCategoryByID(obj.CategoryID)
+	if errors.Is(err, services.ErrCategoryNotFound) {
+		return nil, nil
+	}
+	return category, err
+}
+
+// Client is the resolver for the client field.
+func (r *queryResolver) Client(ctx context.Context, id int64) (*models.Client, error) {
+	return r.client(id)
+}
+
+// Clients is the resolver for the clients field.
+func (r *queryResolver) Clients(ctx context.Context, search *string, page *int, pageSize *int) (*model.ClientPage, error) {
+	p, ps := pageArgs(page, pageSize)
+	clients, total, err := r.ClientService.GetClients(p, ps, search, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ClientPage{Items: pointers(clients), PageInfo: newPageInfo(p, ps, total)}, nil
+}
+
+// Booking is the resolver for the booking field.
+func (r *queryResolver) Booking(ctx context.Context, id int64) (*models.Booking, error) {
+	booking, err := r.BookingService.GetBookingByID(id)
+	if errors.Is(err, services.ErrBookingNotFound) {
+		return nil, nil
+	}
+	return booking, err
+}
+
+// Bookings is the resolver for the bookings field.
+func (r *queryResolver) Bookings(ctx context.Context, filter *model.BookingFilter, page *int, pageSize *int) (*model.BookingPage, error) {
+	return r.bookings(filter, page, pageSize)
+}
+
+// Order is the resolver for the order field.
+func (r *queryResolver) Order(ctx context.Context, id int64) (*models.Order, error) {
+	order, err := r.OrderService.GetOrderByID(id)
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return nil, nil
+	}
+	return order, err
+}
+
+// Orders is the resolver for the orders field.
+func (r *queryResolver) Orders(ctx context.Context, filter *model.OrderFilter, page *int, pageSize *int) (*model.OrderPage, error) {
+	return r.orders(filter, page, pageSize)
+}
+
+// PricelistItem is the resolver for the pricelistItem field.
+func (r *queryResolver) PricelistItem(ctx context.Context, id int64) (*models.PricelistItem, error) {
+	item, err := r.PricelistService.GetItemByID(id)
+	if errors.Is(err, services.ErrItemNotFound) {
+		return nil, nil
+	}
+	return item, err
+}
+
+// PricelistItems is the resolver for the pricelistItems field.
+func (r *queryResolver) PricelistItems(ctx context.Context, categoryID *int64, itemType *string, page *int, pageSize *int) (*model.PricelistItemPage, error) {
+	p, ps := pageArgs(page, pageSize)
+	items, total, err := r.PricelistService.GetItems(categoryID, itemType, p, ps)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PricelistItemPage{Items: pointers(items), PageInfo: newPageInfo(p, ps, total)}, nil
+}
+
+// PricelistCategories is the resolver for the pricelistCategories field.
+func (r *queryResolver) PricelistCategories(ctx context.Context, page *int, pageSize *int) (*model.PricelistCategoryPage, error) {
+	p, ps := pageArgs(page, pageSize)
+	categories, total, err := r.PricelistService.GetCategories(p, ps)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PricelistCategoryPage{Items: pointers(categories), PageInfo: newPageInfo(p, ps, total)}, nil
+}
+
+// DashboardSummary is the resolver for the dashboardSummary field.
+func (r *queryResolver) DashboardSummary(ctx context.Context) (*models.DashboardSummary, error) {
+	return r.ReportService.GetDashboardSummary()
+}
+
+// Booking returns BookingResolver implementation.
+func (r *Resolver) Booking() BookingResolver { return &bookingResolver{r} }
+
+// Client returns ClientResolver implementation.
+func (r *Resolver) Client() ClientResolver { return &clientResolver{r} }
+
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
+// OrderItem returns OrderItemResolver implementation.
+func (r *Resolver) OrderItem() OrderItemResolver { return &orderItemResolver{r} }
+
+// PricelistItem returns PricelistItemResolver implementation.
+func (r *Resolver) PricelistItem() PricelistItemResolver { return &pricelistItemResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type bookingResolver struct{ *Resolver }
+type clientResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
+type orderItemResolver struct{ *Resolver }
+type pricelistItemResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
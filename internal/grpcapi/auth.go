@@ -0,0 +1,113 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// allowedRoles may call the gRPC API; it serves staff-operated clients such as the kiosk.
+var allowedRoles = []string{"Admin", "Staff"}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the token claims of the authenticated caller.
+func ClaimsFromContext(ctx context.Context) (*utils.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*utils.Claims)
+	return claims, ok
+}
+
+// authenticate validates the bearer token in the "authorization" metadata, the
+// gRPC counterpart of middleware.AuthMiddleware and RoleAuthMiddleware.
+func authenticate(ctx context.Context, store kvstore.Store) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+	parts := strings.SplitN(values[0], " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format, use Bearer <token>")
+	}
+	claims, err := utils.ValidateToken(parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if claims.SessionID != 0 {
+		_, revoked, err := store.Get(ctx, utils.RevokedSessionKey(claims.SessionID))
+		if err != nil {
+			utils.LogError(err, "grpcapi: failed to check session revocation, allowing the call")
+		} else if revoked {
+			return nil, status.Error(codes.Unauthenticated, "session has been revoked")
+		}
+	}
+	for _, role := range allowedRoles {
+		if strings.EqualFold(claims.Role, role) {
+			return context.WithValue(ctx, claimsKey{}, claims), nil
+		}
+	}
+	return nil, status.Error(codes.PermissionDenied, "required roles: "+strings.Join(allowedRoles, ", "))
+}
+
+// approvalActor describes the authenticated caller for services.ApprovalService.
+func approvalActor(ctx context.Context) services.ApprovalActor {
+	var actor services.ApprovalActor
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		actor.UserID = claims.UserID
+		actor.Role = claims.Role
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-approval-pin"); len(values) > 0 {
+		actor.PIN = values[0]
+	}
+	if values := md.Get("x-approval-approver"); len(values) > 0 {
+		// An ID that does not parse names no one, and the PIN is refused
+		actor.ApproverID, _ = strconv.ParseInt(values[0], 10, 64)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		actor.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(actor.IP); err == nil {
+			actor.IP = host
+		}
+	}
+	return actor
+}
+
+func unaryAuthInterceptor(store kvstore.Store) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticatedStream carries the authenticated context into stream handlers.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+func streamAuthInterceptor(store kvstore.Store) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), store)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
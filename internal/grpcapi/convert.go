@@ -0,0 +1,156 @@
+package grpcapi
+
+import (
+	"errors"
+	"time"
+
+	pb "ps_club_backend/internal/grpcapi/psclubv1"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toStatus maps service errors to gRPC status codes, mirroring the HTTP handlers.
+func toStatus(err error) error {
+	var approvalRequired *services.ApprovalRequiredError
+	switch {
+	case errors.As(err, &approvalRequired):
+		return status.Errorf(codes.FailedPrecondition, "%s; an Admin must approve it", approvalRequired.Error())
+	case errors.Is(err, services.ErrInvalidApprovalPIN), errors.Is(err, services.ErrDiscountLimitExceeded):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrApprovalPINLocked):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrBookingNotFound),
+		errors.Is(err, services.ErrItemNotFound), errors.Is(err, services.ErrPricelistItemNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrInsufficientStock), errors.Is(err, services.ErrVersionConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrInvalidOrderStatus), errors.Is(err, services.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
+
+// wholeUnitsPtr truncates a stock quantity to whole stock units; the proto carries integer stock.
+func wholeUnitsPtr(q *models.Quantity) *int32 {
+	if q == nil {
+		return nil
+	}
+	i := int32(q.Decimal().IntPart())
+	return &i
+}
+
+// moneyString renders an amount as a plain decimal string rounded to the currency decimals.
+func moneyString(m models.Money) string {
+	return m.Decimal().StringFixed(int32(utils.CurrentCurrency().Decimals))
+}
+
+func optionalMoneyString(m *models.Money) string {
+	if m == nil {
+		return moneyString(models.ZeroMoney)
+	}
+	return moneyString(*m)
+}
+
+func orderToProto(o *models.Order) *pb.Order {
+	out := &pb.Order{
+		Id:             o.ID,
+		OrderNumber:    o.OrderNumber,
+		ClientId:       o.ClientID,
+		BookingId:      o.BookingID,
+		StaffId:        o.StaffID,
+		TableId:        o.TableID,
+		OrderTime:      timestamp(o.OrderTime),
+		Status:         o.Status,
+		TotalAmount:    moneyString(o.TotalAmount),
+		DiscountAmount: optionalMoneyString(o.DiscountAmount),
+		FinalAmount:    moneyString(o.FinalAmount),
+		PaymentMethod:  stringValue(o.PaymentMethod),
+		Notes:          stringValue(o.Notes),
+		Version:        int32(o.Version),
+		CreatedAt:      timestamp(o.CreatedAt),
+		UpdatedAt:      timestamp(o.UpdatedAt),
+	}
+	for _, item := range o.OrderItems {
+		name := ""
+		if item.PricelistItem != nil {
+			name = item.PricelistItem.Name
+		}
+		out.Items = append(out.Items, &pb.OrderItem{
+			Id:              item.ID,
+			PricelistItemId: item.PricelistItemID,
+			ItemName:        name,
+			Quantity:        int32(item.Quantity),
+			UnitPrice:       moneyString(item.UnitPrice),
+			TotalPrice:      moneyString(item.TotalPrice),
+			Notes:           stringValue(item.Notes),
+		})
+	}
+	return out
+}
+
+func bookingToProto(b *models.Booking) *pb.Booking {
+	return &pb.Booking{
+		Id:             b.ID,
+		ClientId:       b.ClientID,
+		TableId:        b.TableID,
+		StaffId:        b.StaffID,
+		StartTime:      timestamp(b.StartTime),
+		EndTime:        timestamp(b.EndTime),
+		NumberOfGuests: int32Ptr(b.NumberOfGuests),
+		Status:         b.Status,
+		Notes:          stringValue(b.Notes),
+		TotalPrice:     optionalMoneyString(b.TotalPrice),
+		Version:        int32(b.Version),
+		CreatedAt:      timestamp(b.CreatedAt),
+		UpdatedAt:      timestamp(b.UpdatedAt),
+	}
+}
+
+func pricelistItemToProto(item *models.PricelistItem) *pb.PricelistItem {
+	out := &pb.PricelistItem{
+		Id:                item.ID,
+		CategoryId:        item.CategoryID,
+		Name:              item.Name,
+		Description:       stringValue(item.Description),
+		Price:             moneyString(item.Price),
+		Sku:               stringValue(item.SKU),
+		IsAvailable:       item.IsAvailable,
+		ItemType:          item.ItemType,
+		TracksStock:       item.TracksStock,
+		CurrentStock:      wholeUnitsPtr(item.CurrentStock),
+		LowStockThreshold: wholeUnitsPtr(item.LowStockThreshold),
+		Version:           int32(item.Version),
+	}
+	if item.Category != nil {
+		out.CategoryName = item.Category.Name
+	}
+	return out
+}
@@ -0,0 +1,1674 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: psclub/v1/psclub.proto
+
+// gRPC interface for internal integrations (e.g. the native kiosk client).
+// It exposes the same service layer as the HTTP API. Regenerate the Go code with
+// `go generate ./internal/grpcapi/...` after changing this file.
+
+package psclubv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OrderItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PricelistItemId int64                  `protobuf:"varint,2,opt,name=pricelist_item_id,json=pricelistItemId,proto3" json:"pricelist_item_id,omitempty"`
+	ItemName        string                 `protobuf:"bytes,3,opt,name=item_name,json=itemName,proto3" json:"item_name,omitempty"`
+	Quantity        int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice       string                 `protobuf:"bytes,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	TotalPrice      string                 `protobuf:"bytes,6,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	Notes           string                 `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *OrderItem) Reset() {
+	*x = OrderItem{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItem) ProtoMessage() {}
+
+func (x *OrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OrderItem) GetPricelistItemId() int64 {
+	if x != nil {
+		return x.PricelistItemId
+	}
+	return 0
+}
+
+func (x *OrderItem) GetItemName() string {
+	if x != nil {
+		return x.ItemName
+	}
+	return ""
+}
+
+func (x *OrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderItem) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+func (x *OrderItem) GetTotalPrice() string {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return ""
+}
+
+func (x *OrderItem) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type Order struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientId       *int64                 `protobuf:"varint,2,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	BookingId      *int64                 `protobuf:"varint,3,opt,name=booking_id,json=bookingId,proto3,oneof" json:"booking_id,omitempty"`
+	StaffId        *int64                 `protobuf:"varint,4,opt,name=staff_id,json=staffId,proto3,oneof" json:"staff_id,omitempty"`
+	TableId        *int64                 `protobuf:"varint,5,opt,name=table_id,json=tableId,proto3,oneof" json:"table_id,omitempty"`
+	OrderTime      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=order_time,json=orderTime,proto3" json:"order_time,omitempty"`
+	Status         string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	TotalAmount    string                 `protobuf:"bytes,8,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
+	DiscountAmount string                 `protobuf:"bytes,9,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	FinalAmount    string                 `protobuf:"bytes,10,opt,name=final_amount,json=finalAmount,proto3" json:"final_amount,omitempty"`
+	PaymentMethod  string                 `protobuf:"bytes,11,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	Notes          string                 `protobuf:"bytes,12,opt,name=notes,proto3" json:"notes,omitempty"`
+	Items          []*OrderItem           `protobuf:"bytes,13,rep,name=items,proto3" json:"items,omitempty"`
+	Version        int32                  `protobuf:"varint,14,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Display number printed on receipts, e.g. "2024-06-01/#37".
+	OrderNumber   string `protobuf:"bytes,17,opt,name=order_number,json=orderNumber,proto3" json:"order_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Order) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Order) GetClientId() int64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *Order) GetBookingId() int64 {
+	if x != nil && x.BookingId != nil {
+		return *x.BookingId
+	}
+	return 0
+}
+
+func (x *Order) GetStaffId() int64 {
+	if x != nil && x.StaffId != nil {
+		return *x.StaffId
+	}
+	return 0
+}
+
+func (x *Order) GetTableId() int64 {
+	if x != nil && x.TableId != nil {
+		return *x.TableId
+	}
+	return 0
+}
+
+func (x *Order) GetOrderTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OrderTime
+	}
+	return nil
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Order) GetTotalAmount() string {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return ""
+}
+
+func (x *Order) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+func (x *Order) GetFinalAmount() string {
+	if x != nil {
+		return x.FinalAmount
+	}
+	return ""
+}
+
+func (x *Order) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *Order) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Order) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Order) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Order) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Order) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Order) GetOrderNumber() string {
+	if x != nil {
+		return x.OrderNumber
+	}
+	return ""
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetOrderRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListOrdersRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ClientId *int64                 `protobuf:"varint,1,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	StaffId  *int64                 `protobuf:"varint,2,opt,name=staff_id,json=staffId,proto3,oneof" json:"staff_id,omitempty"`
+	TableId  *int64                 `protobuf:"varint,3,opt,name=table_id,json=tableId,proto3,oneof" json:"table_id,omitempty"`
+	Status   string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// Club-local day, YYYY-MM-DD.
+	Date          string `protobuf:"bytes,5,opt,name=date,proto3" json:"date,omitempty"`
+	Page          int32  `protobuf:"varint,6,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListOrdersRequest) GetClientId() int64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetStaffId() int64 {
+	if x != nil && x.StaffId != nil {
+		return *x.StaffId
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetTableId() int64 {
+	if x != nil && x.TableId != nil {
+		return *x.TableId
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ListOrdersRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListOrdersResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CreateOrderItem struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PricelistItemId int64                  `protobuf:"varint,1,opt,name=pricelist_item_id,json=pricelistItemId,proto3" json:"pricelist_item_id,omitempty"`
+	Quantity        int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Notes           string                 `protobuf:"bytes,3,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateOrderItem) Reset() {
+	*x = CreateOrderItem{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderItem) ProtoMessage() {}
+
+func (x *CreateOrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderItem.ProtoReflect.Descriptor instead.
+func (*CreateOrderItem) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateOrderItem) GetPricelistItemId() int64 {
+	if x != nil {
+		return x.PricelistItemId
+	}
+	return 0
+}
+
+func (x *CreateOrderItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *CreateOrderItem) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type CreateOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      *int64                 `protobuf:"varint,1,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	BookingId     *int64                 `protobuf:"varint,2,opt,name=booking_id,json=bookingId,proto3,oneof" json:"booking_id,omitempty"`
+	StaffId       int64                  `protobuf:"varint,3,opt,name=staff_id,json=staffId,proto3" json:"staff_id,omitempty"`
+	TableId       *int64                 `protobuf:"varint,4,opt,name=table_id,json=tableId,proto3,oneof" json:"table_id,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	PaymentMethod string                 `protobuf:"bytes,6,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	Notes         string                 `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+	Items         []*CreateOrderItem     `protobuf:"bytes,8,rep,name=items,proto3" json:"items,omitempty"`
+	// Optional discount, decimal string.
+	DiscountAmount string `protobuf:"bytes,9,opt,name=discount_amount,json=discountAmount,proto3" json:"discount_amount,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateOrderRequest) GetClientId() int64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetBookingId() int64 {
+	if x != nil && x.BookingId != nil {
+		return *x.BookingId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetStaffId() int64 {
+	if x != nil {
+		return x.StaffId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetTableId() int64 {
+	if x != nil && x.TableId != nil {
+		return *x.TableId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetPaymentMethod() string {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetItems() []*CreateOrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CreateOrderRequest) GetDiscountAmount() string {
+	if x != nil {
+		return x.DiscountAmount
+	}
+	return ""
+}
+
+type WatchOrderStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOrderStatusRequest) Reset() {
+	*x = WatchOrderStatusRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrderStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrderStatusRequest) ProtoMessage() {}
+
+func (x *WatchOrderStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrderStatusRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchOrderStatusRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type OrderStatusUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Version       int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusUpdate) Reset() {
+	*x = OrderStatusUpdate{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusUpdate) ProtoMessage() {}
+
+func (x *OrderStatusUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusUpdate.ProtoReflect.Descriptor instead.
+func (*OrderStatusUpdate) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OrderStatusUpdate) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderStatusUpdate) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OrderStatusUpdate) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *OrderStatusUpdate) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Booking struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientId       *int64                 `protobuf:"varint,2,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	TableId        int64                  `protobuf:"varint,3,opt,name=table_id,json=tableId,proto3" json:"table_id,omitempty"`
+	StaffId        *int64                 `protobuf:"varint,4,opt,name=staff_id,json=staffId,proto3,oneof" json:"staff_id,omitempty"`
+	StartTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	NumberOfGuests *int32                 `protobuf:"varint,7,opt,name=number_of_guests,json=numberOfGuests,proto3,oneof" json:"number_of_guests,omitempty"`
+	Status         string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	Notes          string                 `protobuf:"bytes,9,opt,name=notes,proto3" json:"notes,omitempty"`
+	TotalPrice     string                 `protobuf:"bytes,10,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	Version        int32                  `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Booking) Reset() {
+	*x = Booking{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Booking) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Booking) ProtoMessage() {}
+
+func (x *Booking) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Booking.ProtoReflect.Descriptor instead.
+func (*Booking) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Booking) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Booking) GetClientId() int64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *Booking) GetTableId() int64 {
+	if x != nil {
+		return x.TableId
+	}
+	return 0
+}
+
+func (x *Booking) GetStaffId() int64 {
+	if x != nil && x.StaffId != nil {
+		return *x.StaffId
+	}
+	return 0
+}
+
+func (x *Booking) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Booking) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Booking) GetNumberOfGuests() int32 {
+	if x != nil && x.NumberOfGuests != nil {
+		return *x.NumberOfGuests
+	}
+	return 0
+}
+
+func (x *Booking) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Booking) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Booking) GetTotalPrice() string {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return ""
+}
+
+func (x *Booking) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Booking) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Booking) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetBookingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBookingRequest) Reset() {
+	*x = GetBookingRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBookingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBookingRequest) ProtoMessage() {}
+
+func (x *GetBookingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBookingRequest.ProtoReflect.Descriptor instead.
+func (*GetBookingRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetBookingRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListBookingsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ClientId *int64                 `protobuf:"varint,1,opt,name=client_id,json=clientId,proto3,oneof" json:"client_id,omitempty"`
+	TableId  *int64                 `protobuf:"varint,2,opt,name=table_id,json=tableId,proto3,oneof" json:"table_id,omitempty"`
+	StaffId  *int64                 `protobuf:"varint,3,opt,name=staff_id,json=staffId,proto3,oneof" json:"staff_id,omitempty"`
+	Status   string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// Club-local days, YYYY-MM-DD, inclusive.
+	DateFrom      string `protobuf:"bytes,5,opt,name=date_from,json=dateFrom,proto3" json:"date_from,omitempty"`
+	DateTo        string `protobuf:"bytes,6,opt,name=date_to,json=dateTo,proto3" json:"date_to,omitempty"`
+	Page          int32  `protobuf:"varint,7,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32  `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBookingsRequest) Reset() {
+	*x = ListBookingsRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBookingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBookingsRequest) ProtoMessage() {}
+
+func (x *ListBookingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBookingsRequest.ProtoReflect.Descriptor instead.
+func (*ListBookingsRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListBookingsRequest) GetClientId() int64 {
+	if x != nil && x.ClientId != nil {
+		return *x.ClientId
+	}
+	return 0
+}
+
+func (x *ListBookingsRequest) GetTableId() int64 {
+	if x != nil && x.TableId != nil {
+		return *x.TableId
+	}
+	return 0
+}
+
+func (x *ListBookingsRequest) GetStaffId() int64 {
+	if x != nil && x.StaffId != nil {
+		return *x.StaffId
+	}
+	return 0
+}
+
+func (x *ListBookingsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListBookingsRequest) GetDateFrom() string {
+	if x != nil {
+		return x.DateFrom
+	}
+	return ""
+}
+
+func (x *ListBookingsRequest) GetDateTo() string {
+	if x != nil {
+		return x.DateTo
+	}
+	return ""
+}
+
+func (x *ListBookingsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListBookingsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListBookingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bookings      []*Booking             `protobuf:"bytes,1,rep,name=bookings,proto3" json:"bookings,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBookingsResponse) Reset() {
+	*x = ListBookingsResponse{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBookingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBookingsResponse) ProtoMessage() {}
+
+func (x *ListBookingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBookingsResponse.ProtoReflect.Descriptor instead.
+func (*ListBookingsResponse) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListBookingsResponse) GetBookings() []*Booking {
+	if x != nil {
+		return x.Bookings
+	}
+	return nil
+}
+
+func (x *ListBookingsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type PricelistItem struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CategoryId        int64                  `protobuf:"varint,2,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	CategoryName      string                 `protobuf:"bytes,3,opt,name=category_name,json=categoryName,proto3" json:"category_name,omitempty"`
+	Name              string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Description       string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Price             string                 `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	Sku               string                 `protobuf:"bytes,7,opt,name=sku,proto3" json:"sku,omitempty"`
+	IsAvailable       bool                   `protobuf:"varint,8,opt,name=is_available,json=isAvailable,proto3" json:"is_available,omitempty"`
+	ItemType          string                 `protobuf:"bytes,9,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	TracksStock       bool                   `protobuf:"varint,10,opt,name=tracks_stock,json=tracksStock,proto3" json:"tracks_stock,omitempty"`
+	CurrentStock      *int32                 `protobuf:"varint,11,opt,name=current_stock,json=currentStock,proto3,oneof" json:"current_stock,omitempty"`
+	LowStockThreshold *int32                 `protobuf:"varint,12,opt,name=low_stock_threshold,json=lowStockThreshold,proto3,oneof" json:"low_stock_threshold,omitempty"`
+	Version           int32                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PricelistItem) Reset() {
+	*x = PricelistItem{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PricelistItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PricelistItem) ProtoMessage() {}
+
+func (x *PricelistItem) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PricelistItem.ProtoReflect.Descriptor instead.
+func (*PricelistItem) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PricelistItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PricelistItem) GetCategoryId() int64 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *PricelistItem) GetCategoryName() string {
+	if x != nil {
+		return x.CategoryName
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetIsAvailable() bool {
+	if x != nil {
+		return x.IsAvailable
+	}
+	return false
+}
+
+func (x *PricelistItem) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *PricelistItem) GetTracksStock() bool {
+	if x != nil {
+		return x.TracksStock
+	}
+	return false
+}
+
+func (x *PricelistItem) GetCurrentStock() int32 {
+	if x != nil && x.CurrentStock != nil {
+		return *x.CurrentStock
+	}
+	return 0
+}
+
+func (x *PricelistItem) GetLowStockThreshold() int32 {
+	if x != nil && x.LowStockThreshold != nil {
+		return *x.LowStockThreshold
+	}
+	return 0
+}
+
+func (x *PricelistItem) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type GetPricelistItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPricelistItemRequest) Reset() {
+	*x = GetPricelistItemRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPricelistItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPricelistItemRequest) ProtoMessage() {}
+
+func (x *GetPricelistItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPricelistItemRequest.ProtoReflect.Descriptor instead.
+func (*GetPricelistItemRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetPricelistItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListPricelistItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CategoryId    *int64                 `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	ItemType      string                 `protobuf:"bytes,2,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPricelistItemsRequest) Reset() {
+	*x = ListPricelistItemsRequest{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPricelistItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPricelistItemsRequest) ProtoMessage() {}
+
+func (x *ListPricelistItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPricelistItemsRequest.ProtoReflect.Descriptor instead.
+func (*ListPricelistItemsRequest) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListPricelistItemsRequest) GetCategoryId() int64 {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return 0
+}
+
+func (x *ListPricelistItemsRequest) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *ListPricelistItemsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPricelistItemsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListPricelistItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*PricelistItem       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPricelistItemsResponse) Reset() {
+	*x = ListPricelistItemsResponse{}
+	mi := &file_psclub_v1_psclub_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPricelistItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPricelistItemsResponse) ProtoMessage() {}
+
+func (x *ListPricelistItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_psclub_v1_psclub_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPricelistItemsResponse.ProtoReflect.Descriptor instead.
+func (*ListPricelistItemsResponse) Descriptor() ([]byte, []int) {
+	return file_psclub_v1_psclub_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListPricelistItemsResponse) GetItems() []*PricelistItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListPricelistItemsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+var File_psclub_v1_psclub_proto protoreflect.FileDescriptor
+
+const file_psclub_v1_psclub_proto_rawDesc = "" +
+	"\n" +
+	"\x16psclub/v1/psclub.proto\x12\tpsclub.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd6\x01\n" +
+	"\tOrderItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12*\n" +
+	"\x11pricelist_item_id\x18\x02 \x01(\x03R\x0fpricelistItemId\x12\x1b\n" +
+	"\titem_name\x18\x03 \x01(\tR\bitemName\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x05 \x01(\tR\tunitPrice\x12\x1f\n" +
+	"\vtotal_price\x18\x06 \x01(\tR\n" +
+	"totalPrice\x12\x14\n" +
+	"\x05notes\x18\a \x01(\tR\x05notes\"\xb2\x05\n" +
+	"\x05Order\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12 \n" +
+	"\tclient_id\x18\x02 \x01(\x03H\x00R\bclientId\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"booking_id\x18\x03 \x01(\x03H\x01R\tbookingId\x88\x01\x01\x12\x1e\n" +
+	"\bstaff_id\x18\x04 \x01(\x03H\x02R\astaffId\x88\x01\x01\x12\x1e\n" +
+	"\btable_id\x18\x05 \x01(\x03H\x03R\atableId\x88\x01\x01\x129\n" +
+	"\n" +
+	"order_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\torderTime\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12!\n" +
+	"\ftotal_amount\x18\b \x01(\tR\vtotalAmount\x12'\n" +
+	"\x0fdiscount_amount\x18\t \x01(\tR\x0ediscountAmount\x12!\n" +
+	"\ffinal_amount\x18\n" +
+	" \x01(\tR\vfinalAmount\x12%\n" +
+	"\x0epayment_method\x18\v \x01(\tR\rpaymentMethod\x12\x14\n" +
+	"\x05notes\x18\f \x01(\tR\x05notes\x12*\n" +
+	"\x05items\x18\r \x03(\v2\x14.psclub.v1.OrderItemR\x05items\x12\x18\n" +
+	"\aversion\x18\x0e \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\forder_number\x18\x11 \x01(\tR\vorderNumberB\f\n" +
+	"\n" +
+	"_client_idB\r\n" +
+	"\v_booking_idB\v\n" +
+	"\t_staff_idB\v\n" +
+	"\t_table_id\"!\n" +
+	"\x0fGetOrderRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xfa\x01\n" +
+	"\x11ListOrdersRequest\x12 \n" +
+	"\tclient_id\x18\x01 \x01(\x03H\x00R\bclientId\x88\x01\x01\x12\x1e\n" +
+	"\bstaff_id\x18\x02 \x01(\x03H\x01R\astaffId\x88\x01\x01\x12\x1e\n" +
+	"\btable_id\x18\x03 \x01(\x03H\x02R\atableId\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x12\n" +
+	"\x04date\x18\x05 \x01(\tR\x04date\x12\x12\n" +
+	"\x04page\x18\x06 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSizeB\f\n" +
+	"\n" +
+	"_client_idB\v\n" +
+	"\t_staff_idB\v\n" +
+	"\t_table_id\"T\n" +
+	"\x12ListOrdersResponse\x12(\n" +
+	"\x06orders\x18\x01 \x03(\v2\x10.psclub.v1.OrderR\x06orders\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"o\n" +
+	"\x0fCreateOrderItem\x12*\n" +
+	"\x11pricelist_item_id\x18\x01 \x01(\x03R\x0fpricelistItemId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
+	"\x05notes\x18\x03 \x01(\tR\x05notes\"\xef\x02\n" +
+	"\x12CreateOrderRequest\x12 \n" +
+	"\tclient_id\x18\x01 \x01(\x03H\x00R\bclientId\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"booking_id\x18\x02 \x01(\x03H\x01R\tbookingId\x88\x01\x01\x12\x19\n" +
+	"\bstaff_id\x18\x03 \x01(\x03R\astaffId\x12\x1e\n" +
+	"\btable_id\x18\x04 \x01(\x03H\x02R\atableId\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12%\n" +
+	"\x0epayment_method\x18\x06 \x01(\tR\rpaymentMethod\x12\x14\n" +
+	"\x05notes\x18\a \x01(\tR\x05notes\x120\n" +
+	"\x05items\x18\b \x03(\v2\x1a.psclub.v1.CreateOrderItemR\x05items\x12'\n" +
+	"\x0fdiscount_amount\x18\t \x01(\tR\x0ediscountAmountB\f\n" +
+	"\n" +
+	"_client_idB\r\n" +
+	"\v_booking_idB\v\n" +
+	"\t_table_id\"4\n" +
+	"\x17WatchOrderStatusRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\x9b\x01\n" +
+	"\x11OrderStatusUpdate\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xa6\x04\n" +
+	"\aBooking\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12 \n" +
+	"\tclient_id\x18\x02 \x01(\x03H\x00R\bclientId\x88\x01\x01\x12\x19\n" +
+	"\btable_id\x18\x03 \x01(\x03R\atableId\x12\x1e\n" +
+	"\bstaff_id\x18\x04 \x01(\x03H\x01R\astaffId\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12-\n" +
+	"\x10number_of_guests\x18\a \x01(\x05H\x02R\x0enumberOfGuests\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x14\n" +
+	"\x05notes\x18\t \x01(\tR\x05notes\x12\x1f\n" +
+	"\vtotal_price\x18\n" +
+	" \x01(\tR\n" +
+	"totalPrice\x12\x18\n" +
+	"\aversion\x18\v \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\f\n" +
+	"\n" +
+	"_client_idB\v\n" +
+	"\t_staff_idB\x13\n" +
+	"\x11_number_of_guests\"#\n" +
+	"\x11GetBookingRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x9e\x02\n" +
+	"\x13ListBookingsRequest\x12 \n" +
+	"\tclient_id\x18\x01 \x01(\x03H\x00R\bclientId\x88\x01\x01\x12\x1e\n" +
+	"\btable_id\x18\x02 \x01(\x03H\x01R\atableId\x88\x01\x01\x12\x1e\n" +
+	"\bstaff_id\x18\x03 \x01(\x03H\x02R\astaffId\x88\x01\x01\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1b\n" +
+	"\tdate_from\x18\x05 \x01(\tR\bdateFrom\x12\x17\n" +
+	"\adate_to\x18\x06 \x01(\tR\x06dateTo\x12\x12\n" +
+	"\x04page\x18\a \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSizeB\f\n" +
+	"\n" +
+	"_client_idB\v\n" +
+	"\t_table_idB\v\n" +
+	"\t_staff_id\"\\\n" +
+	"\x14ListBookingsResponse\x12.\n" +
+	"\bbookings\x18\x01 \x03(\v2\x12.psclub.v1.BookingR\bbookings\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xc9\x03\n" +
+	"\rPricelistItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\vcategory_id\x18\x02 \x01(\x03R\n" +
+	"categoryId\x12#\n" +
+	"\rcategory_name\x18\x03 \x01(\tR\fcategoryName\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\tR\x05price\x12\x10\n" +
+	"\x03sku\x18\a \x01(\tR\x03sku\x12!\n" +
+	"\fis_available\x18\b \x01(\bR\visAvailable\x12\x1b\n" +
+	"\titem_type\x18\t \x01(\tR\bitemType\x12!\n" +
+	"\ftracks_stock\x18\n" +
+	" \x01(\bR\vtracksStock\x12(\n" +
+	"\rcurrent_stock\x18\v \x01(\x05H\x00R\fcurrentStock\x88\x01\x01\x123\n" +
+	"\x13low_stock_threshold\x18\f \x01(\x05H\x01R\x11lowStockThreshold\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\r \x01(\x05R\aversionB\x10\n" +
+	"\x0e_current_stockB\x16\n" +
+	"\x14_low_stock_threshold\")\n" +
+	"\x17GetPricelistItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x9f\x01\n" +
+	"\x19ListPricelistItemsRequest\x12$\n" +
+	"\vcategory_id\x18\x01 \x01(\x03H\x00R\n" +
+	"categoryId\x88\x01\x01\x12\x1b\n" +
+	"\titem_type\x18\x02 \x01(\tR\bitemType\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSizeB\x0e\n" +
+	"\f_category_id\"b\n" +
+	"\x1aListPricelistItemsResponse\x12.\n" +
+	"\x05items\x18\x01 \x03(\v2\x18.psclub.v1.PricelistItemR\x05items\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total2\xeb\x04\n" +
+	"\x06PSClub\x128\n" +
+	"\bGetOrder\x12\x1a.psclub.v1.GetOrderRequest\x1a\x10.psclub.v1.Order\x12I\n" +
+	"\n" +
+	"ListOrders\x12\x1c.psclub.v1.ListOrdersRequest\x1a\x1d.psclub.v1.ListOrdersResponse\x12>\n" +
+	"\vCreateOrder\x12\x1d.psclub.v1.CreateOrderRequest\x1a\x10.psclub.v1.Order\x12V\n" +
+	"\x10WatchOrderStatus\x12\".psclub.v1.WatchOrderStatusRequest\x1a\x1c.psclub.v1.OrderStatusUpdate0\x01\x12>\n" +
+	"\n" +
+	"GetBooking\x12\x1c.psclub.v1.GetBookingRequest\x1a\x12.psclub.v1.Booking\x12O\n" +
+	"\fListBookings\x12\x1e.psclub.v1.ListBookingsRequest\x1a\x1f.psclub.v1.ListBookingsResponse\x12P\n" +
+	"\x10GetPricelistItem\x12\".psclub.v1.GetPricelistItemRequest\x1a\x18.psclub.v1.PricelistItem\x12a\n" +
+	"\x12ListPricelistItems\x12$.psclub.v1.ListPricelistItemsRequest\x1a%.psclub.v1.ListPricelistItemsResponseB4Z2ps_club_backend/internal/grpcapi/psclubv1;psclubv1b\x06proto3"
+
+var (
+	file_psclub_v1_psclub_proto_rawDescOnce sync.Once
+	file_psclub_v1_psclub_proto_rawDescData []byte
+)
+
+func file_psclub_v1_psclub_proto_rawDescGZIP() []byte {
+	file_psclub_v1_psclub_proto_rawDescOnce.Do(func() {
+		file_psclub_v1_psclub_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_psclub_v1_psclub_proto_rawDesc), len(file_psclub_v1_psclub_proto_rawDesc)))
+	})
+	return file_psclub_v1_psclub_proto_rawDescData
+}
+
+var file_psclub_v1_psclub_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_psclub_v1_psclub_proto_goTypes = []any{
+	(*OrderItem)(nil),                  // 0: psclub.v1.OrderItem
+	(*Order)(nil),                      // 1: psclub.v1.Order
+	(*GetOrderRequest)(nil),            // 2: psclub.v1.GetOrderRequest
+	(*ListOrdersRequest)(nil),          // 3: psclub.v1.ListOrdersRequest
+	(*ListOrdersResponse)(nil),         // 4: psclub.v1.ListOrdersResponse
+	(*CreateOrderItem)(nil),            // 5: psclub.v1.CreateOrderItem
+	(*CreateOrderRequest)(nil),         // 6: psclub.v1.CreateOrderRequest
+	(*WatchOrderStatusRequest)(nil),    // 7: psclub.v1.WatchOrderStatusRequest
+	(*OrderStatusUpdate)(nil),          // 8: psclub.v1.OrderStatusUpdate
+	(*Booking)(nil),                    // 9: psclub.v1.Booking
+	(*GetBookingRequest)(nil),          // 10: psclub.v1.GetBookingRequest
+	(*ListBookingsRequest)(nil),        // 11: psclub.v1.ListBookingsRequest
+	(*ListBookingsResponse)(nil),       // 12: psclub.v1.ListBookingsResponse
+	(*PricelistItem)(nil),              // 13: psclub.v1.PricelistItem
+	(*GetPricelistItemRequest)(nil),    // 14: psclub.v1.GetPricelistItemRequest
+	(*ListPricelistItemsRequest)(nil),  // 15: psclub.v1.ListPricelistItemsRequest
+	(*ListPricelistItemsResponse)(nil), // 16: psclub.v1.ListPricelistItemsResponse
+	(*timestamppb.Timestamp)(nil),      // 17: google.protobuf.Timestamp
+}
+var file_psclub_v1_psclub_proto_depIdxs = []int32{
+	17, // 0: psclub.v1.Order.order_time:type_name -> google.protobuf.Timestamp
+	0,  // 1: psclub.v1.Order.items:type_name -> psclub.v1.OrderItem
+	17, // 2: psclub.v1.Order.created_at:type_name -> google.protobuf.Timestamp
+	17, // 3: psclub.v1.Order.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: psclub.v1.ListOrdersResponse.orders:type_name -> psclub.v1.Order
+	5,  // 5: psclub.v1.CreateOrderRequest.items:type_name -> psclub.v1.CreateOrderItem
+	17, // 6: psclub.v1.OrderStatusUpdate.updated_at:type_name -> google.protobuf.Timestamp
+	17, // 7: psclub.v1.Booking.start_time:type_name -> google.protobuf.Timestamp
+	17, // 8: psclub.v1.Booking.end_time:type_name -> google.protobuf.Timestamp
+	17, // 9: psclub.v1.Booking.created_at:type_name -> google.protobuf.Timestamp
+	17, // 10: psclub.v1.Booking.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 11: psclub.v1.ListBookingsResponse.bookings:type_name -> psclub.v1.Booking
+	13, // 12: psclub.v1.ListPricelistItemsResponse.items:type_name -> psclub.v1.PricelistItem
+	2,  // 13: psclub.v1.PSClub.GetOrder:input_type -> psclub.v1.GetOrderRequest
+	3,  // 14: psclub.v1.PSClub.ListOrders:input_type -> psclub.v1.ListOrdersRequest
+	6,  // 15: psclub.v1.PSClub.CreateOrder:input_type -> psclub.v1.CreateOrderRequest
+	7,  // 16: psclub.v1.PSClub.WatchOrderStatus:input_type -> psclub.v1.WatchOrderStatusRequest
+	10, // 17: psclub.v1.PSClub.GetBooking:input_type -> psclub.v1.GetBookingRequest
+	11, // 18: psclub.v1.PSClub.ListBookings:input_type -> psclub.v1.ListBookingsRequest
+	14, // 19: psclub.v1.PSClub.GetPricelistItem:input_type -> psclub.v1.GetPricelistItemRequest
+	15, // 20: psclub.v1.PSClub.ListPricelistItems:input_type -> psclub.v1.ListPricelistItemsRequest
+	1,  // 21: psclub.v1.PSClub.GetOrder:output_type -> psclub.v1.Order
+	4,  // 22: psclub.v1.PSClub.ListOrders:output_type -> psclub.v1.ListOrdersResponse
+	1,  // 23: psclub.v1.PSClub.CreateOrder:output_type -> psclub.v1.Order
+	8,  // 24: psclub.v1.PSClub.WatchOrderStatus:output_type -> psclub.v1.OrderStatusUpdate
+	9,  // 25: psclub.v1.PSClub.GetBooking:output_type -> psclub.v1.Booking
+	12, // 26: psclub.v1.PSClub.ListBookings:output_type -> psclub.v1.ListBookingsResponse
+	13, // 27: psclub.v1.PSClub.GetPricelistItem:output_type -> psclub.v1.PricelistItem
+	16, // 28: psclub.v1.PSClub.ListPricelistItems:output_type -> psclub.v1.ListPricelistItemsResponse
+	21, // [21:29] is the sub-list for method output_type
+	13, // [13:21] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_psclub_v1_psclub_proto_init() }
+func file_psclub_v1_psclub_proto_init() {
+	if File_psclub_v1_psclub_proto != nil {
+		return
+	}
+	file_psclub_v1_psclub_proto_msgTypes[1].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[3].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[6].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[9].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[11].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[13].OneofWrappers = []any{}
+	file_psclub_v1_psclub_proto_msgTypes[15].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_psclub_v1_psclub_proto_rawDesc), len(file_psclub_v1_psclub_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_psclub_v1_psclub_proto_goTypes,
+		DependencyIndexes: file_psclub_v1_psclub_proto_depIdxs,
+		MessageInfos:      file_psclub_v1_psclub_proto_msgTypes,
+	}.Build()
+	File_psclub_v1_psclub_proto = out.File
+	file_psclub_v1_psclub_proto_goTypes = nil
+	file_psclub_v1_psclub_proto_depIdxs = nil
+}
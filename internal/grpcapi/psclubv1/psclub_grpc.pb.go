@@ -0,0 +1,405 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: psclub/v1/psclub.proto
+
+// gRPC interface for internal integrations (e.g. the native kiosk client).
+// It exposes the same service layer as the HTTP API. Regenerate the Go code with
+// `go generate ./internal/grpcapi/...` after changing this file.
+
+package psclubv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PSClub_GetOrder_FullMethodName           = "/psclub.v1.PSClub/GetOrder"
+	PSClub_ListOrders_FullMethodName         = "/psclub.v1.PSClub/ListOrders"
+	PSClub_CreateOrder_FullMethodName        = "/psclub.v1.PSClub/CreateOrder"
+	PSClub_WatchOrderStatus_FullMethodName   = "/psclub.v1.PSClub/WatchOrderStatus"
+	PSClub_GetBooking_FullMethodName         = "/psclub.v1.PSClub/GetBooking"
+	PSClub_ListBookings_FullMethodName       = "/psclub.v1.PSClub/ListBookings"
+	PSClub_GetPricelistItem_FullMethodName   = "/psclub.v1.PSClub/GetPricelistItem"
+	PSClub_ListPricelistItems_FullMethodName = "/psclub.v1.PSClub/ListPricelistItems"
+)
+
+// PSClubClient is the client API for PSClub service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PSClub exposes core read operations and order creation. Every call requires
+// an "authorization: Bearer <access token>" metadata entry for an Admin or Staff user.
+type PSClubClient interface {
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
+	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	// WatchOrderStatus sends the current status of an order and then every change,
+	// until the order reaches a final status or the client cancels.
+	WatchOrderStatus(ctx context.Context, in *WatchOrderStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderStatusUpdate], error)
+	GetBooking(ctx context.Context, in *GetBookingRequest, opts ...grpc.CallOption) (*Booking, error)
+	ListBookings(ctx context.Context, in *ListBookingsRequest, opts ...grpc.CallOption) (*ListBookingsResponse, error)
+	GetPricelistItem(ctx context.Context, in *GetPricelistItemRequest, opts ...grpc.CallOption) (*PricelistItem, error)
+	ListPricelistItems(ctx context.Context, in *ListPricelistItemsRequest, opts ...grpc.CallOption) (*ListPricelistItemsResponse, error)
+}
+
+type pSClubClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPSClubClient(cc grpc.ClientConnInterface) PSClubClient {
+	return &pSClubClient{cc}
+}
+
+func (c *pSClubClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, PSClub_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, PSClub_ListOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, PSClub_CreateOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) WatchOrderStatus(ctx context.Context, in *WatchOrderStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderStatusUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PSClub_ServiceDesc.Streams[0], PSClub_WatchOrderStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrderStatusRequest, OrderStatusUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PSClub_WatchOrderStatusClient = grpc.ServerStreamingClient[OrderStatusUpdate]
+
+func (c *pSClubClient) GetBooking(ctx context.Context, in *GetBookingRequest, opts ...grpc.CallOption) (*Booking, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Booking)
+	err := c.cc.Invoke(ctx, PSClub_GetBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) ListBookings(ctx context.Context, in *ListBookingsRequest, opts ...grpc.CallOption) (*ListBookingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBookingsResponse)
+	err := c.cc.Invoke(ctx, PSClub_ListBookings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) GetPricelistItem(ctx context.Context, in *GetPricelistItemRequest, opts ...grpc.CallOption) (*PricelistItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PricelistItem)
+	err := c.cc.Invoke(ctx, PSClub_GetPricelistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pSClubClient) ListPricelistItems(ctx context.Context, in *ListPricelistItemsRequest, opts ...grpc.CallOption) (*ListPricelistItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPricelistItemsResponse)
+	err := c.cc.Invoke(ctx, PSClub_ListPricelistItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PSClubServer is the server API for PSClub service.
+// All implementations must embed UnimplementedPSClubServer
+// for forward compatibility.
+//
+// PSClub exposes core read operations and order creation. Every call requires
+// an "authorization: Bearer <access token>" metadata entry for an Admin or Staff user.
+type PSClubServer interface {
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
+	CreateOrder(context.Context, *CreateOrderRequest) (*Order, error)
+	// WatchOrderStatus sends the current status of an order and then every change,
+	// until the order reaches a final status or the client cancels.
+	WatchOrderStatus(*WatchOrderStatusRequest, grpc.ServerStreamingServer[OrderStatusUpdate]) error
+	GetBooking(context.Context, *GetBookingRequest) (*Booking, error)
+	ListBookings(context.Context, *ListBookingsRequest) (*ListBookingsResponse, error)
+	GetPricelistItem(context.Context, *GetPricelistItemRequest) (*PricelistItem, error)
+	ListPricelistItems(context.Context, *ListPricelistItemsRequest) (*ListPricelistItemsResponse, error)
+	mustEmbedUnimplementedPSClubServer()
+}
+
+// UnimplementedPSClubServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPSClubServer struct{}
+
+func (UnimplementedPSClubServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedPSClubServer) ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedPSClubServer) CreateOrder(context.Context, *CreateOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
+}
+func (UnimplementedPSClubServer) WatchOrderStatus(*WatchOrderStatusRequest, grpc.ServerStreamingServer[OrderStatusUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrderStatus not implemented")
+}
+func (UnimplementedPSClubServer) GetBooking(context.Context, *GetBookingRequest) (*Booking, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBooking not implemented")
+}
+func (UnimplementedPSClubServer) ListBookings(context.Context, *ListBookingsRequest) (*ListBookingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBookings not implemented")
+}
+func (UnimplementedPSClubServer) GetPricelistItem(context.Context, *GetPricelistItemRequest) (*PricelistItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPricelistItem not implemented")
+}
+func (UnimplementedPSClubServer) ListPricelistItems(context.Context, *ListPricelistItemsRequest) (*ListPricelistItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPricelistItems not implemented")
+}
+func (UnimplementedPSClubServer) mustEmbedUnimplementedPSClubServer() {}
+func (UnimplementedPSClubServer) testEmbeddedByValue()                {}
+
+// UnsafePSClubServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PSClubServer will
+// result in compilation errors.
+type UnsafePSClubServer interface {
+	mustEmbedUnimplementedPSClubServer()
+}
+
+func RegisterPSClubServer(s grpc.ServiceRegistrar, srv PSClubServer) {
+	// If the following call pancis, it indicates UnimplementedPSClubServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PSClub_ServiceDesc, srv)
+}
+
+func _PSClub_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_ListOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_CreateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).CreateOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_CreateOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).CreateOrder(ctx, req.(*CreateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_WatchOrderStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrderStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PSClubServer).WatchOrderStatus(m, &grpc.GenericServerStream[WatchOrderStatusRequest, OrderStatusUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PSClub_WatchOrderStatusServer = grpc.ServerStreamingServer[OrderStatusUpdate]
+
+func _PSClub_GetBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBookingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).GetBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_GetBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).GetBooking(ctx, req.(*GetBookingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_ListBookings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBookingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).ListBookings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_ListBookings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).ListBookings(ctx, req.(*ListBookingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_GetPricelistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPricelistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).GetPricelistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_GetPricelistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).GetPricelistItem(ctx, req.(*GetPricelistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PSClub_ListPricelistItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPricelistItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PSClubServer).ListPricelistItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PSClub_ListPricelistItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PSClubServer).ListPricelistItems(ctx, req.(*ListPricelistItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PSClub_ServiceDesc is the grpc.ServiceDesc for PSClub service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PSClub_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "psclub.v1.PSClub",
+	HandlerType: (*PSClubServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOrder",
+			Handler:    _PSClub_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _PSClub_ListOrders_Handler,
+		},
+		{
+			MethodName: "CreateOrder",
+			Handler:    _PSClub_CreateOrder_Handler,
+		},
+		{
+			MethodName: "GetBooking",
+			Handler:    _PSClub_GetBooking_Handler,
+		},
+		{
+			MethodName: "ListBookings",
+			Handler:    _PSClub_ListBookings_Handler,
+		},
+		{
+			MethodName: "GetPricelistItem",
+			Handler:    _PSClub_GetPricelistItem_Handler,
+		},
+		{
+			MethodName: "ListPricelistItems",
+			Handler:    _PSClub_ListPricelistItems_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrderStatus",
+			Handler:       _PSClub_WatchOrderStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "psclub/v1/psclub.proto",
+}
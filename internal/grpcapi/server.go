@@ -0,0 +1,272 @@
+// Package grpcapi serves the core read endpoints and order creation over gRPC for
+// internal integrations (kiosk, kitchen display). It shares the service layer
+// with the HTTP API, so both enforce the same business rules.
+package grpcapi
+
+//go:generate protoc --proto_path=../../proto --go_out=../.. --go_opt=module=ps_club_backend --go-grpc_out=../.. --go-grpc_opt=module=ps_club_backend psclub/v1/psclub.proto
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ps_club_backend/internal/events"
+	pb "ps_club_backend/internal/grpcapi/psclubv1"
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Paging defaults, matching the HTTP list endpoints.
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+// WatchPollInterval is how often WatchOrderStatus checks an order for changes.
+var WatchPollInterval = 2 * time.Second
+
+// Server implements psclubv1.PSClubServer on top of the services.
+type Server struct {
+	pb.UnimplementedPSClubServer
+	orders    services.OrderService
+	bookings  services.BookingService
+	pricelist services.PricelistService
+	approvals services.ApprovalService // Guards orders with large discounts, like POST /orders
+}
+
+// NewServer creates a Server over the given services.
+func NewServer(orders services.OrderService, bookings services.BookingService, pricelist services.PricelistService, approvals services.ApprovalService) *Server {
+	return &Server{orders: orders, bookings: bookings, pricelist: pricelist, approvals: approvals}
+}
+
+// New wires repositories and services for db, like router.Setup does for HTTP,
+// and returns a gRPC server with authentication and the PSClub service registered.
+// store must be the one the HTTP API uses, so booking locks are shared.
+func New(db *sql.DB, store kvstore.Store) *grpc.Server {
+	pricelistRepo := repositories.NewPricelistRepository(db)
+	inventoryMvRepo := repositories.NewInventoryMovementRepository(db)
+	orderRepo := repositories.NewOrderRepository(db)
+	bookingRepo := repositories.NewBookingRepository(db)
+	clientRepo := repositories.NewClientRepository(db)
+	staffRepo := repositories.NewStaffRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	publisher := events.NewPublisher(repositories.NewOutboxRepository(db))
+	orderService := services.NewOrderService(orderRepo, pricelistRepo, inventoryMvRepo, repositories.NewStockBatchRepository(db), repositories.NewClientAccountRepository(db), publisher, repositories.NewDayCloseRepository(db), repositories.NewPromoCodeRepository(db), db)
+	bookingService := services.NewBookingService(bookingRepo, clientRepo, staffRepo, repositories.NewLockerRepository(db), repositories.NewGameTableRepository(db), auditLogRepo, db, store, publisher)
+
+	srv := NewServer(
+		orderService,
+		bookingService,
+		services.NewPricelistService(pricelistRepo, auditLogRepo, db),
+		services.NewApprovalService(repositories.NewApprovalRepository(db), repositories.NewAuthRepository(db), pricelistRepo, orderService, bookingService, store, db),
+	)
+
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAuthInterceptor(store)),
+		grpc.StreamInterceptor(streamAuthInterceptor(store)),
+	)
+	pb.RegisterPSClubServer(gs, srv)
+	return gs
+}
+
+func paging(page, pageSize int32) (int, int) {
+	p, ps := int(page), int(pageSize)
+	if p < 1 {
+		p = defaultPage
+	}
+	if ps < 1 {
+		ps = defaultPageSize
+	}
+	return p, ps
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// GetOrder returns an order with its items.
+func (s *Server) GetOrder(_ context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	order, err := s.orders.GetOrderByID(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return orderToProto(order), nil
+}
+
+// ListOrders returns a page of orders matching the filters.
+func (s *Server) ListOrders(_ context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	page, pageSize := paging(req.GetPage(), req.GetPageSize())
+	orders, total, err := s.orders.GetOrders(models.OrderFilters{
+		ClientID: req.ClientId,
+		StaffID:  req.StaffId,
+		TableID:  req.TableId,
+		Status:   optionalString(req.GetStatus()),
+		Date:     optionalString(req.GetDate()),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListOrdersResponse{Total: int32(total)}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, orderToProto(&orders[i]))
+	}
+	return resp, nil
+}
+
+// CreateOrder places an order, reserving stock exactly like POST /orders. An order
+// with a large discount needs an Admin caller or an Admin PIN in the
+// "x-approval-pin" metadata, with that Admin's user ID in "x-approval-approver";
+// otherwise a pending approval is recorded.
+func (s *Server) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	if req.GetStaffId() == 0 || req.GetStatus() == "" || len(req.GetItems()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "staff_id, status and at least one item are required")
+	}
+	createReq := services.CreateOrderRequest{
+		ClientID:      req.ClientId,
+		BookingID:     req.BookingId,
+		StaffID:       req.GetStaffId(),
+		TableID:       req.TableId,
+		Status:        req.GetStatus(),
+		PaymentMethod: optionalString(req.GetPaymentMethod()),
+		Notes:         optionalString(req.GetNotes()),
+	}
+	for _, item := range req.GetItems() {
+		if item.GetQuantity() <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "item quantity must be greater than 0")
+		}
+		createReq.OrderItems = append(createReq.OrderItems, services.CreateOrderItemRequest{
+			PricelistItemID: item.GetPricelistItemId(),
+			Quantity:        int(item.GetQuantity()),
+			Notes:           item.GetNotes(),
+		})
+	}
+	if req.GetDiscountAmount() != "" {
+		discount, err := models.ParseMoney(req.GetDiscountAmount())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid discount_amount")
+		}
+		createReq.DiscountAmount = &discount
+	}
+
+	order, err := s.approvals.CreateOrder(createReq, approvalActor(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return orderToProto(order), nil
+}
+
+// isFinalOrderStatus reports whether an order can no longer change status.
+func isFinalOrderStatus(s string) bool {
+	switch s {
+	case services.StatusCompleted, services.StatusCancelled, services.StatusPaid, services.StatusRefunded:
+		return true
+	}
+	return false
+}
+
+// WatchOrderStatus polls the order and streams its status whenever it changes.
+func (s *Server) WatchOrderStatus(req *pb.WatchOrderStatusRequest, stream grpc.ServerStreamingServer[pb.OrderStatusUpdate]) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(WatchPollInterval)
+	defer ticker.Stop()
+
+	lastVersion := -1
+	for {
+		order, err := s.orders.GetOrderByID(req.GetOrderId())
+		if err != nil {
+			return toStatus(err)
+		}
+		if order.Version != lastVersion {
+			lastVersion = order.Version
+			err = stream.Send(&pb.OrderStatusUpdate{
+				OrderId:   order.ID,
+				Status:    order.Status,
+				Version:   int32(order.Version),
+				UpdatedAt: timestamp(order.UpdatedAt),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if isFinalOrderStatus(order.Status) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetBooking returns a booking.
+func (s *Server) GetBooking(_ context.Context, req *pb.GetBookingRequest) (*pb.Booking, error) {
+	booking, err := s.bookings.GetBookingByID(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return bookingToProto(booking), nil
+}
+
+// ListBookings returns a page of bookings matching the filters.
+func (s *Server) ListBookings(_ context.Context, req *pb.ListBookingsRequest) (*pb.ListBookingsResponse, error) {
+	dateFrom, dateTo, err := utils.ParseClubDateRange(req.GetDateFrom(), req.GetDateTo())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid date, use YYYY-MM-DD")
+	}
+	page, pageSize := paging(req.GetPage(), req.GetPageSize())
+	bookings, total, err := s.bookings.GetBookings(models.BookingFilters{
+		ClientID: req.ClientId,
+		TableID:  req.TableId,
+		StaffID:  req.StaffId,
+		DateFrom: dateFrom,
+		DateTo:   dateTo,
+		Status:   optionalString(req.GetStatus()),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListBookingsResponse{Total: int32(total)}
+	for i := range bookings {
+		resp.Bookings = append(resp.Bookings, bookingToProto(&bookings[i]))
+	}
+	return resp, nil
+}
+
+// GetPricelistItem returns a pricelist item.
+func (s *Server) GetPricelistItem(_ context.Context, req *pb.GetPricelistItemRequest) (*pb.PricelistItem, error) {
+	item, err := s.pricelist.GetItemByID(req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return pricelistItemToProto(item), nil
+}
+
+// ListPricelistItems returns a page of pricelist items.
+func (s *Server) ListPricelistItems(_ context.Context, req *pb.ListPricelistItemsRequest) (*pb.ListPricelistItemsResponse, error) {
+	page, pageSize := paging(req.GetPage(), req.GetPageSize())
+	items, total, err := s.pricelist.GetItems(req.CategoryId, optionalString(req.GetItemType()), page, pageSize)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &pb.ListPricelistItemsResponse{Total: int32(total)}
+	for i := range items {
+		resp.Items = append(resp.Items, pricelistItemToProto(&items[i]))
+	}
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnomalyHandler holds the anomaly service.
+type AnomalyHandler struct {
+	anomalyService services.AnomalyService
+}
+
+// NewAnomalyHandler creates a new AnomalyHandler.
+func NewAnomalyHandler(as services.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{anomalyService: as}
+}
+
+// GetAnomalyChecks lists the nightly checks of the business days, newest first, optionally
+// between ?from= and ?to= (YYYY-MM-DD) and only those that found anomalies (anomalies=true).
+func (h *AnomalyHandler) GetAnomalyChecks(c *gin.Context) {
+	onlyAnomalies := false
+	if value := c.Query("anomalies"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid anomalies value.", err.Error()))
+			return
+		}
+		onlyAnomalies = parsed
+	}
+	checks, err := h.anomalyService.GetChecks(c.Query("from"), c.Query("to"), onlyAnomalies)
+	if err != nil {
+		utils.LogError(err, "GetAnomalyChecks: Error from anomalyService.GetChecks")
+		respondWithServiceError(c, err, "Failed to fetch anomaly checks.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": checks})
+}
+
+// CheckDayForAnomalies checks a past business day, ?date=YYYY-MM-DD, that the nightly check
+// missed, e.g. while the server was down.
+func (h *AnomalyHandler) CheckDayForAnomalies(c *gin.Context) {
+	check, err := h.anomalyService.CheckDay(c.Query("date"))
+	if err != nil {
+		utils.LogError(err, "CheckDayForAnomalies: Error from anomalyService.CheckDay for "+c.Query("date"))
+		respondWithServiceError(c, err, "Failed to check the day for anomalies.")
+		return
+	}
+	c.JSON(http.StatusCreated, check)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler holds the API key service.
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(aks services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: aks}
+}
+
+// CreateAPIKey creates an API key and responds with its secret, which cannot be retrieved later.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreateAPIKey")
+	if !ok {
+		return
+	}
+	var req services.CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreateAPIKey: Error from apiKeyService.CreateAPIKey")
+		respondWithServiceError(c, err, "Failed to create API key.")
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAPIKeys lists the API keys, newest first, without their secrets.
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.GetAPIKeys()
+	if err != nil {
+		utils.LogError(err, "GetAPIKeys: Error from apiKeyService.GetAPIKeys")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch API keys.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// RevokeAPIKey revokes an API key; requests with it fail from then on.
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid API key ID format.", err.Error()))
+		return
+	}
+	if err := h.apiKeyService.RevokeAPIKey(id); err != nil {
+		utils.LogError(err, "RevokeAPIKey: Error from apiKeyService.RevokeAPIKey for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to revoke API key.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalHandler holds the approval service.
+type ApprovalHandler struct {
+	approvalService services.ApprovalService
+}
+
+// NewApprovalHandler creates a new ApprovalHandler.
+func NewApprovalHandler(as services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{approvalService: as}
+}
+
+// RejectApprovalRequest is the body of POST /approvals/:id/reject.
+type RejectApprovalRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// SetApprovalPINRequest is the body of PUT /approvals/pin.
+type SetApprovalPINRequest struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+// respondApprovalError handles the approval errors of a sensitive action and
+// reports whether err was one of them. A pending approval is answered with
+// 202 Accepted, so the client can show that the action awaits a manager.
+func respondApprovalError(c *gin.Context, err error) bool {
+	var required *services.ApprovalRequiredError
+	switch {
+	case errors.As(err, &required):
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "The action requires manager approval and is pending.",
+			"approval": required.Approval,
+		})
+	case errors.Is(err, services.ErrApprovalPINLocked):
+		c.Header("Retry-After", strconv.Itoa(int(services.ApprovalPINLockout.Seconds())))
+		respondWithServiceError(c, err, "Failed to check approval PIN.")
+	case errors.Is(err, services.ErrInvalidApprovalPIN):
+		respondWithServiceError(c, err, "Failed to check approval PIN.")
+	default:
+		return false
+	}
+	return true
+}
+
+// GetApprovals lists approvals, newest first, optionally filtered by ?status=pending.
+func (h *ApprovalHandler) GetApprovals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	var status *string
+	if statusStr := c.Query("status"); statusStr != "" {
+		status = &statusStr
+	}
+
+	approvals, total, err := h.approvalService.GetApprovals(status, page, pageSize)
+	if err != nil {
+		utils.LogError(err, "GetApprovals: Error from approvalService.GetApprovals")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch approvals.", "Internal error"))
+		return
+	}
+	respondList(c, approvals, total, page, pageSize)
+}
+
+// GetApprovalByID returns an approval, e.g. for the requester to poll its status.
+func (h *ApprovalHandler) GetApprovalByID(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+	approval, err := h.approvalService.GetApprovalByID(id)
+	if err != nil {
+		h.respondDecisionError(c, err, "GetApprovalByID")
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// ApproveApproval grants a pending approval and performs the original action.
+// The response carries the updated approval and the action's result, e.g. the order.
+func (h *ApprovalHandler) ApproveApproval(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c, "ApproveApproval")
+	if !ok {
+		return
+	}
+
+	approval, result, err := h.approvalService.Approve(id, userID)
+	if err != nil {
+		h.respondDecisionError(c, err, "ApproveApproval")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval": approval, "result": result})
+}
+
+// RejectApproval rejects a pending approval; the action is not performed.
+func (h *ApprovalHandler) RejectApproval(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c, "RejectApproval")
+	if !ok {
+		return
+	}
+	var req RejectApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	approval, err := h.approvalService.Reject(id, userID, req.Reason)
+	if err != nil {
+		h.respondDecisionError(c, err, "RejectApproval")
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// SetApprovalPIN sets the approval PIN of the current Admin.
+func (h *ApprovalHandler) SetApprovalPIN(c *gin.Context) {
+	userID, ok := currentUserID(c, "SetApprovalPIN")
+	if !ok {
+		return
+	}
+	var req SetApprovalPINRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.approvalService.SetApprovalPIN(userID, req.PIN); err != nil {
+		utils.LogError(err, "SetApprovalPIN: Error from approvalService.SetApprovalPIN")
+		respondWithServiceError(c, err, "Failed to set approval PIN.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Approval PIN set successfully"})
+}
+
+func parseApprovalID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid approval ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *ApprovalHandler) respondDecisionError(c *gin.Context, err error, handlerName string) {
+	if errors.Is(err, services.ErrApprovalActionFailed) {
+		// The approval is recorded as failed; tell the Admin why the action could not run,
+		// with the status of the action's error
+		status, code := http.StatusInternalServerError, utils.ErrCodeInternalServerError
+		if domainErr, ok := apperrors.AsDomainError(err); ok {
+			if actionStatus, ok := statusByErrorCode[domainErr.Code]; ok {
+				status, code = actionStatus, domainErr.Code
+			}
+		}
+		utils.LogError(err, handlerName+": approved action failed")
+		utils.RespondWithError(c, utils.NewAPIError(status, code, "The approved action failed.", err.Error()))
+		return
+	}
+	utils.LogError(err, handlerName+": Error from approvalService")
+	respondWithServiceError(c, err, "Failed to process approval.")
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceHandler holds the attendance service.
+type AttendanceHandler struct {
+	attendanceService services.AttendanceService
+}
+
+// NewAttendanceHandler creates a new AttendanceHandler.
+func NewAttendanceHandler(as services.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{attendanceService: as}
+}
+
+// GetAttendanceReport compares the shifts of ?month=YYYY-MM (default: this month) with the time
+// clock, optionally of a staff_id, listing the late arrivals, early departures and missed shifts
+// with the monthly totals of each staff member.
+func (h *AttendanceHandler) GetAttendanceReport(c *gin.Context) {
+	var staffID *int64
+	if staffIDStr := c.Query("staff_id"); staffIDStr != "" {
+		id, err := strconv.ParseInt(staffIDStr, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id format.", err.Error()))
+			return
+		}
+		staffID = &id
+	}
+	report, err := h.attendanceService.GetReport(c.Query("month"), staffID)
+	if err != nil {
+		utils.LogError(err, "GetAttendanceReport: Error from attendanceService.GetReport")
+		respondWithServiceError(c, err, "Failed to fetch attendance report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogHandler holds the audit log service.
+type AuditLogHandler struct {
+	auditLogService services.AuditLogService
+}
+
+// NewAuditLogHandler creates a new AuditLogHandler.
+func NewAuditLogHandler(as services.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{auditLogService: as}
+}
+
+// GetAuditLog lists the audit log, newest first, optionally only the entries of a user_id or
+// impersonator_id, those made (or not) while impersonating (impersonated=true|false), or
+// between from and to (YYYY-MM-DD, club time).
+func (h *AuditLogHandler) GetAuditLog(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 50
+	}
+	filters := models.AuditLogFilters{Page: page, PageSize: pageSize}
+	for param, target := range map[string]**int64{
+		"user_id":         &filters.UserID,
+		"impersonator_id": &filters.ImpersonatorID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+param+" value.", err.Error()))
+				return
+			}
+			*target = &id
+		}
+	}
+	if value := c.Query("impersonated"); value != "" {
+		impersonated, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid impersonated value, expected true or false.", err.Error()))
+			return
+		}
+		filters.Impersonated = &impersonated
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	entries, total, err := h.auditLogService.GetEntries(filters)
+	if err != nil {
+		utils.LogError(err, "GetAuditLog: Error from auditLogService.GetEntries")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch the audit log.", "Internal error"))
+		return
+	}
+	respondList(c, entries, total, page, pageSize)
+}
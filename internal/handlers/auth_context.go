@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/middleware"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID returns the ID of the authenticated user set by AuthMiddleware.
+// If it is missing it responds with 401 and returns false.
+func currentUserID(c *gin.Context, handlerName string) (int64, bool) {
+	userIDRaw, exists := c.Get("userID")
+	if !exists {
+		utils.LogError(errors.New("userID not found in context"), handlerName+": userID not in context")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusUnauthorized, utils.ErrCodeUnauthorized, "User not authenticated.", "Missing user ID in context"))
+		return 0, false
+	}
+	userID, ok := userIDRaw.(int64)
+	if !ok {
+		utils.LogError(errors.New("userID is not of type int64"), handlerName+": userID type assertion failed")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusUnauthorized, utils.ErrCodeUnauthorized, "User ID format incorrect.", "Invalid user ID format in context"))
+		return 0, false
+	}
+	return userID, true
+}
+
+// ApprovalPINHeader carries the approval PIN an Admin enters on a staff member's
+// device to authorize a sensitive action on the spot. ApprovalApproverHeader names
+// that Admin by user ID, so the PIN is checked against theirs only.
+const (
+	ApprovalPINHeader      = "X-Approval-PIN"
+	ApprovalApproverHeader = "X-Approval-Approver"
+)
+
+// approvalActor describes the authenticated user for services.ApprovalService.
+// If the user is missing it responds with 401, and if the approver is not a user
+// ID with 400, and returns false.
+func approvalActor(c *gin.Context, handlerName string) (services.ApprovalActor, bool) {
+	userID, ok := currentUserID(c, handlerName)
+	if !ok {
+		return services.ApprovalActor{}, false
+	}
+	var approverID int64
+	if header := c.GetHeader(ApprovalApproverHeader); header != "" {
+		var err error
+		if approverID, err = strconv.ParseInt(header, 10, 64); err != nil || approverID <= 0 {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed,
+				"Invalid "+ApprovalApproverHeader+" header.", "The header must be the user ID of the approving Admin"))
+			return services.ApprovalActor{}, false
+		}
+	}
+	return services.ApprovalActor{
+		UserID:     userID,
+		Role:       c.GetString("userRole"),
+		PIN:        c.GetHeader(ApprovalPINHeader),
+		ApproverID: approverID,
+		IP:         c.ClientIP(),
+	}, true
+}
+
+// requestSource returns the order source of the authenticated principal: the scope of the API
+// key of a self-service channel, the client app for users of the Client role, and the POS for
+// staff.
+func requestSource(c *gin.Context) string {
+	if scope := c.GetString("apiKeyScope"); models.IsValidOrderSource(scope) {
+		return scope
+	}
+	if strings.EqualFold(c.GetString("userRole"), middleware.RoleClient) {
+		return models.OrderSourceClientApp
+	}
+	return models.OrderSourcePOS
+}
+
+// sourceFromQuery reads the source query parameter, responding with 400 and returning false
+// if it is not one of models.OrderSources.
+func sourceFromQuery(c *gin.Context) (*string, bool) {
+	source := c.Query("source")
+	if source == "" {
+		return nil, true
+	}
+	if !models.IsValidOrderSource(source) {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid source value.",
+			"source must be one of: "+strings.Join(models.OrderSources, ", ")))
+		return nil, false
+	}
+	return &source, true
+}
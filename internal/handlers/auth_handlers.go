@@ -2,7 +2,12 @@ package handlers
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/middleware"
+	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils" // For APIError and error codes
 
@@ -22,24 +27,14 @@ func NewAuthHandler(as services.AuthService) *AuthHandler {
 // RegisterUser handles user registration.
 func (h *AuthHandler) RegisterUser(c *gin.Context) {
 	var req services.RegisterUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "RegisterUser: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	user, err := h.authService.RegisterUser(req)
 	if err != nil {
 		utils.LogError(err, "RegisterUser: Error from authService.RegisterUser")
-		if errors.Is(err, services.ErrUsernameExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Username already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrEmailExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Email already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrRoleNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Specified role not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to register user.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to register user.")
 		return
 	}
 	c.JSON(http.StatusCreated, user)
@@ -48,20 +43,17 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 // LoginUser handles user login.
 func (h *AuthHandler) LoginUser(c *gin.Context) {
 	var req services.LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "LoginUser: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	authResp, err := h.authService.LoginUser(req)
 	if err != nil {
 		utils.LogError(err, "LoginUser: Error from authService.LoginUser")
-		if errors.Is(err, services.ErrInvalidCredentials) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusUnauthorized, utils.ErrCodeUnauthorized, "Invalid username or password.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to login.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to login.")
 		return
 	}
 	c.JSON(http.StatusOK, authResp)
@@ -86,37 +78,220 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	user, err := h.authService.GetUserProfile(userID)
 	if err != nil {
 		utils.LogError(err, "GetCurrentUser: Error from authService.GetUserProfile for userID "+utils.Int64ToStr(userID))
-		if errors.Is(err, services.ErrUserNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "User profile not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to retrieve user profile.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to retrieve user profile.")
 		return
 	}
-	c.JSON(http.StatusOK, user)
+	// While an Admin impersonates the user, clients show the banner
+	c.JSON(http.StatusOK, currentUserResponse{User: user, Impersonation: middleware.ImpersonationFromContext(c)})
+}
+
+// currentUserResponse is the body of GET /auth/me.
+type currentUserResponse struct {
+	*models.User
+	Impersonation *models.Impersonation `json:"impersonation,omitempty"`
+}
+
+// UpdateProfile lets the current user change their own full name, email, phone number and
+// password with a JSON Merge Patch, e.g. {"phone_number": "+7 701 123 4567"} or
+// {"current_password": "...", "new_password": "..."}. Setting a new password logs out the
+// user's other devices. An Admin impersonating the user cannot change the password.
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := currentUserID(c, "UpdateProfile")
+	if !ok {
+		return
+	}
+	var req services.UpdateProfileRequest
+	if !bindUpdate(c, &req, services.ProfileClearableFields, &req.Clear) {
+		return
+	}
+	if req.NewPassword != nil && middleware.ImpersonationFromContext(c) != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusForbidden, utils.ErrCodeForbidden, "The password cannot be changed while impersonating.", "impersonation token"))
+		return
+	}
+
+	user, err := h.authService.UpdateProfile(userID, c.GetInt64("sessionID"), req)
+	if err != nil {
+		utils.LogError(err, "UpdateProfile: Error from authService.UpdateProfile")
+		respondWithServiceError(c, err, "Failed to update profile.")
+		return
+	}
+	c.JSON(http.StatusOK, currentUserResponse{User: user, Impersonation: middleware.ImpersonationFromContext(c)})
+}
+
+// UploadAvatar replaces the current user's avatar with the "photo" file of a multipart form.
+func (h *AuthHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := currentUserID(c, "UploadAvatar")
+	if !ok {
+		return
+	}
+	data, ok := readPhotoUpload(c, "UploadAvatar")
+	if !ok {
+		return
+	}
+	if err := h.authService.SetAvatar(userID, data); err != nil {
+		utils.LogError(err, "UploadAvatar: Error from authService.SetAvatar")
+		respondWithServiceError(c, err, "Failed to save avatar.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Avatar uploaded successfully"})
+}
+
+// GetAvatar serves the current user's avatar.
+func (h *AuthHandler) GetAvatar(c *gin.Context) {
+	userID, ok := currentUserID(c, "GetAvatar")
+	if !ok {
+		return
+	}
+	avatar, err := h.authService.GetAvatar(userID)
+	if err != nil {
+		utils.LogError(err, "GetAvatar: Error from authService.GetAvatar")
+		respondWithServiceError(c, err, "Failed to fetch avatar.")
+		return
+	}
+	c.Data(http.StatusOK, avatar.ContentType, avatar.Data)
+}
+
+// DeleteAvatar removes the current user's avatar.
+func (h *AuthHandler) DeleteAvatar(c *gin.Context) {
+	userID, ok := currentUserID(c, "DeleteAvatar")
+	if !ok {
+		return
+	}
+	if err := h.authService.DeleteAvatar(userID); err != nil {
+		utils.LogError(err, "DeleteAvatar: Error from authService.DeleteAvatar")
+		respondWithServiceError(c, err, "Failed to delete avatar.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Avatar deleted successfully"})
+}
+
+// Impersonate issues the Admin a short-lived access token acting as a Staff or Analyst
+// user, e.g. {"user_id": 7, "reason": "Orders screen is empty for them"}, to see what
+// they see. Every request made with it is audited with the Admin as impersonator.
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	adminID, ok := currentUserID(c, "Impersonate")
+	if !ok {
+		return
+	}
+	var req services.ImpersonateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.AdminID = adminID
+	req.SessionID = c.GetInt64("sessionID")
+	req.IPAddress = c.ClientIP()
+
+	resp, err := h.authService.Impersonate(req)
+	if err != nil {
+		utils.LogError(err, "Impersonate: Error from authService.Impersonate")
+		respondWithServiceError(c, err, "Failed to impersonate user.")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // LogoutUser handles user logout.
-// For stateless JWT, this is primarily a client-side action.
+// Access tokens are stateless and expire on their own; the client discards it. If the
+// body carries the refresh token, it is revoked so it can no longer be exchanged.
 func (h *AuthHandler) LogoutUser(c *gin.Context) {
-	// Optional: could extract userID from token to log the logout action.
-	// For stateless JWT, server doesn't do much other than acknowledge.
-	// If using a refresh token blocklist, that logic would be invoked here via the authService.
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+		return
+	}
+
+	if req.RefreshToken != "" {
+		userID, _ := c.Get("userID")
+		id, _ := userID.(int64)
+		// A token that is already invalid cannot be used anyway, so only store failures are reported
+		if err := h.authService.RevokeRefreshToken(id, req.RefreshToken); err != nil && !errors.Is(err, services.ErrInvalidRefreshToken) {
+			utils.LogError(err, "LogoutUser: Error from authService.RevokeRefreshToken")
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to revoke refresh token.", "Internal error"))
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully. Please discard your token."})
 }
 
-// RefreshToken handles refreshing an access token.
-// This is a placeholder as RefreshAccessToken is not yet in AuthService.
+// RefreshToken exchanges a refresh token for a new access token and refresh token.
+// The submitted refresh token is revoked.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	// TODO: Implement when AuthService supports RefreshAccessToken.
-	// Example structure:
-	// var req struct { RefreshToken string `json:"refresh_token" binding:"required"` }
-	// if err := c.ShouldBindJSON(&req); err != nil { ... }
-	// authResp, err := h.authService.RefreshAccessToken(req.RefreshToken)
-	// if err != nil { ... handle errors like invalid/expired refresh token ... }
-	// c.JSON(http.StatusOK, authResp)
-
-	utils.RespondWithError(c, utils.NewAPIError(http.StatusNotImplemented, utils.ErrCodeNotImplemented, "Refresh token functionality is not yet implemented.", "Not implemented"))
+	var req services.RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	req.IPAddress = c.ClientIP()
+
+	authResp, err := h.authService.RefreshAccessToken(req)
+	if err != nil {
+		utils.LogError(err, "RefreshToken: Error from authService.RefreshAccessToken")
+		respondWithServiceError(c, err, "Failed to refresh token.")
+		return
+	}
+	c.JSON(http.StatusOK, authResp)
+}
+
+// GetSessions lists the devices logged in as the current user; the requesting one has "current": true.
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, ok := currentUserID(c, "GetSessions")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID, c.GetInt64("sessionID"))
+	if err != nil {
+		utils.LogError(err, "GetSessions: Error from authService.GetSessions")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch sessions.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession logs a device out, e.g. a lost phone: its refresh token stops working
+// and its access tokens are rejected, without changing the password.
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c, "RevokeSession")
+	if !ok {
+		return
+	}
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid session ID format.", err.Error()))
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		utils.LogError(err, "RevokeSession: Error from authService.RevokeSession")
+		respondWithServiceError(c, err, "Failed to revoke session.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// UpdatePreferredLanguage sets the language of the current user's API messages,
+// e.g. {"language": "ru"}; an empty language falls back to Accept-Language.
+func (h *AuthHandler) UpdatePreferredLanguage(c *gin.Context) {
+	userID, ok := currentUserID(c, "UpdatePreferredLanguage")
+	if !ok {
+		return
+	}
+	var req struct {
+		Language string `json:"language"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.authService.UpdatePreferredLanguage(userID, req.Language)
+	if err != nil {
+		utils.LogError(err, "UpdatePreferredLanguage: Error from authService.UpdatePreferredLanguage")
+		respondWithServiceError(c, err, "Failed to update preferred language.")
+		return
+	}
+	c.JSON(http.StatusOK, user)
 }
 
 // Standalone handler functions that are not yet part of AuthHandler (if any)
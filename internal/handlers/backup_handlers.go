@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler holds the backup service.
+type BackupHandler struct {
+	backupService services.BackupService
+}
+
+// NewBackupHandler creates a new BackupHandler.
+func NewBackupHandler(bs services.BackupService) *BackupHandler {
+	return &BackupHandler{backupService: bs}
+}
+
+// TriggerBackupRequest is the body of POST /admin/backups; the destination defaults to local.
+type TriggerBackupRequest struct {
+	Destination string `json:"destination"` // "local" or "s3"
+}
+
+// TriggerBackup starts a backup in the background and responds with 202 and the running backup.
+func (h *BackupHandler) TriggerBackup(c *gin.Context) {
+	userID, ok := currentUserID(c, "TriggerBackup")
+	if !ok {
+		return
+	}
+	var req TriggerBackupRequest
+	// The body is optional
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	backup, err := h.backupService.StartBackup(services.BackupTriggerManual, req.Destination, &userID)
+	if err != nil {
+		utils.LogError(err, "TriggerBackup: Error from backupService.StartBackup")
+		respondWithServiceError(c, err, "Failed to start backup.")
+		return
+	}
+	c.JSON(http.StatusAccepted, backup)
+}
+
+// GetBackups lists recent backups, newest first (?limit=, at most 100).
+func (h *BackupHandler) GetBackups(c *gin.Context) {
+	limit := services.DefaultBackupListLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid limit, must be a positive integer.", limitStr))
+			return
+		}
+		limit = parsed
+	}
+	backups, err := h.backupService.GetBackups(limit)
+	if err != nil {
+		utils.LogError(err, "GetBackups: Error from backupService.GetBackups")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch backups.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": backups})
+}
+
+// GetBackupByID returns a backup, e.g. to poll one started with TriggerBackup.
+func (h *BackupHandler) GetBackupByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid backup ID format.", err.Error()))
+		return
+	}
+	backup, err := h.backupService.GetBackupByID(id)
+	if err != nil {
+		utils.LogError(err, "GetBackupByID: Error from backupService.GetBackupByID for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to fetch backup.")
+		return
+	}
+	c.JSON(http.StatusOK, backup)
+}
@@ -8,17 +8,18 @@ import (
 
 	"ps_club_backend/internal/database"
 	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-const BarItemType = "BAR"
+const BarItemType = models.ItemTypeBar
 
 // CreateBarItem handles creation of a new bar item (a PricelistItem with type 'BAR')
 func CreateBarItem(c *gin.Context) {
 	var item models.PricelistItem
-	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+	if !bindJSON(c, &item) {
 		return
 	}
 
@@ -28,10 +29,10 @@ func CreateBarItem(c *gin.Context) {
 	query := `INSERT INTO pricelist_items 
 	          (category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
-	          RETURNING id, created_at, updated_at`
+	          RETURNING id, created_at, updated_at, version`
 
-	item.CreatedAt = time.Now()
-	item.UpdatedAt = time.Now()
+	item.CreatedAt = time.Now().UTC()
+	item.UpdatedAt = time.Now().UTC()
 	if item.IsAvailable == false {
         // Default from DB is true, but if payload sets it, respect it.
     } else {
@@ -41,7 +42,7 @@ func CreateBarItem(c *gin.Context) {
 	err := db.QueryRow(query, 
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU, item.IsAvailable, 
 		item.ItemType, item.CurrentStock, item.LowStockThreshold, item.CreatedAt, item.UpdatedAt,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Version)
 
 	if err != nil {
 		// Check for foreign key violation for category_id
@@ -62,10 +63,10 @@ func GetBarItems(c *gin.Context) {
 	
 	queryStr := `SELECT pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	                     pi.is_available, pi.item_type, pi.current_stock, pi.low_stock_threshold, 
-	                     pi.created_at, pi.updated_at, pc.name as category_name
+	                     pi.created_at, pi.updated_at, pi.version, pc.name as category_name
 	              FROM pricelist_items pi
 	              JOIN pricelist_categories pc ON pi.category_id = pc.id
-	              WHERE pi.item_type = $1
+	              WHERE pi.item_type = $1 AND pi.deleted_at IS NULL
 	              ORDER BY pi.name`
 
 	rows, err := db.Query(queryStr, BarItemType)
@@ -82,7 +83,7 @@ func GetBarItems(c *gin.Context) {
 		if err := rows.Scan(
 			&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 			&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-			&item.CreatedAt, &item.UpdatedAt, &categoryName,
+			&item.CreatedAt, &item.UpdatedAt, &item.Version, &categoryName,
 		); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan bar item: " + err.Error()})
 			return
@@ -107,14 +108,14 @@ func GetBarItemByID(c *gin.Context) {
 	var categoryName string
 	query := `SELECT pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	                 pi.is_available, pi.item_type, pi.current_stock, pi.low_stock_threshold, 
-	                 pi.created_at, pi.updated_at, pc.name as category_name
+	                 pi.created_at, pi.updated_at, pi.version, pc.name as category_name
 	          FROM pricelist_items pi
 	          JOIN pricelist_categories pc ON pi.category_id = pc.id
-	          WHERE pi.id = $1 AND pi.item_type = $2`
+	          WHERE pi.id = $1 AND pi.item_type = $2 AND pi.deleted_at IS NULL`
 	err = db.QueryRow(query, id, BarItemType).Scan(
 		&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 		&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-		&item.CreatedAt, &item.UpdatedAt, &categoryName,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version, &categoryName,
 	)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bar item not found"})
@@ -137,8 +138,7 @@ func UpdateBarItem(c *gin.Context) {
 	}
 
 	var item models.PricelistItem
-	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+	if !bindJSON(c, &item) {
 		return
 	}
 
@@ -147,7 +147,7 @@ func UpdateBarItem(c *gin.Context) {
 	db := database.GetDB()
 	// Check if the item to update is indeed a BAR item
 	var currentItemType string
-	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1", id).Scan(&currentItemType); err == sql.ErrNoRows {
+	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1 AND deleted_at IS NULL", id).Scan(&currentItemType); err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bar item not found to update"})
 		return
 	} else if err != nil {
@@ -161,23 +161,24 @@ func UpdateBarItem(c *gin.Context) {
 
 	query := `UPDATE pricelist_items SET 
 	          category_id = $1, name = $2, description = $3, price = $4, sku = $5, 
-	          is_available = $6, item_type = $7, current_stock = $8, low_stock_threshold = $9, updated_at = $10
-	          WHERE id = $11 
-	          RETURNING id, category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at`
+	          is_available = $6, item_type = $7, current_stock = $8, low_stock_threshold = $9, updated_at = $10,
+	          version = version + 1
+	          WHERE id = $11 AND ($12 = 0 OR version = $12)
+	          RETURNING id, category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at, version`
 
-	item.UpdatedAt = time.Now()
+	item.UpdatedAt = time.Now().UTC()
 
 	err = db.QueryRow(query, 
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU, 
-		item.IsAvailable, item.ItemType, item.CurrentStock, item.LowStockThreshold, item.UpdatedAt, id,
+		item.IsAvailable, item.ItemType, item.CurrentStock, item.LowStockThreshold, item.UpdatedAt, id, item.Version,
 	).Scan(
 		&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 		&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-		&item.CreatedAt, &item.UpdatedAt,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version,
 	)
 
-	if err == sql.ErrNoRows { // Should be caught by pre-check, but good to have
-		c.JSON(http.StatusNotFound, gin.H{"error": "Bar item not found to update (race condition?)"})
+	if err == sql.ErrNoRows { // The item exists (pre-check), so the version sent by the client is stale
+		respondWithCurrentItem(c, db, id)
 		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bar item: " + err.Error()})
@@ -196,11 +197,16 @@ func DeleteBarItem(c *gin.Context) {
 		return
 	}
 
+	userID, ok := currentUserID(c, "DeleteBarItem")
+	if !ok {
+		return
+	}
+
 	db := database.GetDB()
 
 	// Check if the item to delete is indeed a BAR item
 	var currentItemType string
-	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1", id).Scan(&currentItemType); err == sql.ErrNoRows {
+	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1 AND deleted_at IS NULL", id).Scan(&currentItemType); err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Bar item not found to delete"})
 		return
 	} else if err != nil {
@@ -212,8 +218,9 @@ func DeleteBarItem(c *gin.Context) {
 		return
 	}
 
-	// Consider checking if item is in active orders or has recent inventory movements before deleting
-	result, err := db.Exec("DELETE FROM pricelist_items WHERE id = $1 AND item_type = $2", id, BarItemType)
+	// Soft deleted, so an Admin can restore it (POST /pricelist-items/:id/restore)
+	result, err := db.Exec(`UPDATE pricelist_items SET deleted_at = $3, deleted_by = $4, updated_at = $3, version = version + 1
+	                        WHERE id = $1 AND item_type = $2 AND deleted_at IS NULL`, id, BarItemType, time.Now().UTC(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bar item: " + err.Error()})
 		return
@@ -226,3 +233,13 @@ func DeleteBarItem(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Bar item deleted successfully"})
 }
 
+// respondWithCurrentItem answers an update carrying a stale version with 409 and the item as it is now.
+// Shared by the bar and hookah item handlers.
+func respondWithCurrentItem(c *gin.Context, db *sql.DB, id int64) {
+	current, err := repositories.NewPricelistRepository(db).GetItemByID(id)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Item was modified by another user, reload it and retry"})
+		return
+	}
+	utils.RespondWithVersionConflict(c, current)
+}
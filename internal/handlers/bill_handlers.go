@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillHandler holds the bill service.
+type BillHandler struct {
+	billService services.BillService
+}
+
+// NewBillHandler creates a new BillHandler.
+func NewBillHandler(bs services.BillService) *BillHandler {
+	return &BillHandler{billService: bs}
+}
+
+// GetOrderBill returns the running bill of an order with its amounts formatted for display.
+func (h *BillHandler) GetOrderBill(c *gin.Context) {
+	idStr := c.Param("id")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order ID format.", err.Error()))
+		return
+	}
+	bill, err := h.billService.GetOrderBill(orderID)
+	if err != nil {
+		utils.LogError(err, "GetOrderBill: Error from billService.GetOrderBill for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to compute bill.")
+		return
+	}
+	c.JSON(http.StatusOK, bill)
+}
+
+// GetSessionBill returns the bill of a table session so far, with the orders taken at its
+// table, without stopping the session.
+func (h *BillHandler) GetSessionBill(c *gin.Context) {
+	idStr := c.Param("id")
+	sessionID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table session ID format.", err.Error()))
+		return
+	}
+	bill, err := h.billService.GetSessionBill(sessionID)
+	if err != nil {
+		utils.LogError(err, "GetSessionBill: Error from billService.GetSessionBill for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to compute bill.")
+		return
+	}
+	c.JSON(http.StatusOK, bill)
+}
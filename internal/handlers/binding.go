@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+
+	"ps_club_backend/internal/validation"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// bindJSON decodes and validates the JSON body into req. If that fails it responds
+// with 400, listing the invalid fields for validation errors, and returns false.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+	if fields, ok := validation.FieldErrors(err); ok {
+		utils.RespondWithValidationErrors(c, fields)
+		return false
+	}
+	utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	return false
+}
+
+// MergePatchContentType is the media type of JSON Merge Patch (RFC 7386) bodies.
+const MergePatchContentType = "application/merge-patch+json"
+
+// bindUpdate binds the body of an update request into req. A PUT body is bound like
+// bindJSON. A PATCH body is a JSON Merge Patch: members set to null are appended to
+// *cleared if they are in clearable, so the service sets them to null, and the other
+// members are bound like a PUT body. Absent members stay unchanged either way.
+func bindUpdate(c *gin.Context, req interface{}, clearable []string, cleared *[]string) bool {
+	if c.Request.Method != http.MethodPatch {
+		return bindJSON(c, req)
+	}
+	if contentType := c.ContentType(); contentType != MergePatchContentType && contentType != binding.MIMEJSON {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusUnsupportedMediaType, utils.ErrCodeBadRequest,
+			"PATCH bodies must be JSON Merge Patch documents ("+MergePatchContentType+").", "Content-Type: "+contentType))
+		return false
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		details := "the patch is not a JSON object"
+		if err != nil {
+			details = err.Error()
+		}
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid merge patch: the body must be a JSON object.", details))
+		return false
+	}
+
+	fieldErrors := make(map[string]string)
+	for field, value := range patch {
+		if string(bytes.TrimSpace(value)) != "null" {
+			continue
+		}
+		delete(patch, field)
+		if !slices.Contains(clearable, field) {
+			fieldErrors[field] = "cannot be cleared"
+			continue
+		}
+		*cleared = append(*cleared, field)
+	}
+	if len(fieldErrors) > 0 {
+		utils.RespondWithValidationErrors(c, fieldErrors)
+		return false
+	}
+	sort.Strings(*cleared)
+
+	// Bind the remaining members through the JSON binding, so they are validated like a PUT body
+	remaining, err := json.Marshal(patch)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid merge patch.", err.Error()))
+		return false
+	}
+	if err := binding.JSON.BindBody(remaining, req); err != nil {
+		if fields, ok := validation.FieldErrors(err); ok {
+			utils.RespondWithValidationErrors(c, fields)
+			return false
+		}
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+		return false
+	}
+	return true
+}
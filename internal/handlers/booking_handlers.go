@@ -7,28 +7,31 @@ import (
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// BookingHandler holds the booking service.
+// BookingHandler holds the booking service, and the approval service for the
+// bookings of blacklisted clients.
 type BookingHandler struct {
-	bookingService services.BookingService
+	bookingService  services.BookingService
+	approvalService services.ApprovalService
 }
 
 // NewBookingHandler creates a new BookingHandler.
-func NewBookingHandler(bs services.BookingService) *BookingHandler {
-	return &BookingHandler{bookingService: bs}
+func NewBookingHandler(bs services.BookingService, as services.ApprovalService) *BookingHandler {
+	return &BookingHandler{bookingService: bs, approvalService: as}
 }
 
-// CreateBooking handles the creation of a new booking.
+// CreateBooking handles the creation of a new booking. A booking for a blacklisted
+// client is refused, unless override_blacklist is set and a manager approves it.
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
+	actor, ok := approvalActor(c, "CreateBooking")
+	if !ok {
+		return
+	}
 	var req services.CreateBookingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateBooking: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -40,24 +43,38 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 	// }
 	// req.StaffID = authStaffID.(int64) // This needs careful handling of type and if user is actually staff
 
-	booking, err := h.bookingService.CreateBooking(req)
+	req.Source = requestSource(c)
+
+	booking, err := h.approvalService.CreateBooking(req, actor)
 	if err != nil {
-		utils.LogError(err, "CreateBooking: Error from bookingService.CreateBooking")
-		if errors.Is(err, services.ErrTableNotAvailable) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrInvalidBookingTime) || errors.Is(err, services.ErrBookingValidation) || errors.Is(err, services.ErrShiftTimeFormat) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrClientForBookingNotFound) || errors.Is(err, services.ErrStaffForBookingNotFound) || errors.Is(err, services.ErrTableForBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create booking.", "Internal error"))
+		if respondApprovalError(c, err) {
+			return
 		}
+		utils.LogError(err, "CreateBooking: Error from approvalService.CreateBooking")
+		respondWithServiceError(c, err, "Failed to create booking.")
 		return
 	}
 	c.JSON(http.StatusCreated, booking)
 }
 
-// GetBookings handles fetching all bookings with pagination and filters.
+// QuoteBooking prices a booking of a table for a period and a number of controllers without making it.
+func (h *BookingHandler) QuoteBooking(c *gin.Context) {
+	var req services.QuoteBookingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	quote, err := h.bookingService.QuoteBooking(req)
+	if err != nil {
+		utils.LogError(err, "QuoteBooking: Error from bookingService.QuoteBooking")
+		respondWithServiceError(c, err, "Failed to quote booking.")
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// GetBookings handles fetching all bookings with pagination and filters. With a cursor query
+// parameter (empty for the first page) it uses cursor pagination and responds with {"data", "next_cursor"}.
 func (h *BookingHandler) GetBookings(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
@@ -90,17 +107,30 @@ func (h *BookingHandler) GetBookings(c *gin.Context) {
 		}
 		filters.Status = &statusStr
 	}
-	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
-		t, err := time.Parse("2006-01-02", dateFromStr)
-		if err == nil { filters.DateFrom = &t 
-		} else { utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid date_from format. Use YYYY-MM-DD.", err.Error())); return }
+	if clientTag := c.Query("client_tag"); clientTag != "" {
+		normalized := services.NormalizeClientTag(clientTag)
+		filters.ClientTag = &normalized
 	}
-	if dateToStr := c.Query("date_to"); dateToStr != "" {
-		t, err := time.Parse("2006-01-02", dateToStr)
-		if err == nil { 
-			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second) // End of day
-			filters.DateTo = &t 
-		} else { utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid date_to format. Use YYYY-MM-DD.", err.Error())); return }
+	source, ok := sourceFromQuery(c)
+	if !ok {
+		return
+	}
+	filters.Source = source
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.DateFrom, filters.DateTo = timeRange.From, timeRange.To
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		page, err := h.bookingService.GetBookingsByCursor(filters, cursor)
+		if err != nil {
+			utils.LogError(err, "GetBookings: Error from bookingService.GetBookingsByCursor")
+			respondWithServiceError(c, err, "Failed to fetch bookings.")
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
 	}
 
 	bookings, totalCount, err := h.bookingService.GetBookings(filters)
@@ -114,12 +144,7 @@ func (h *BookingHandler) GetBookings(c *gin.Context) {
 	    bookings = []models.Booking{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  bookings,
-		"total": totalCount,
-		"page":  page,
-		"page_size": pageSize,
-	})
+	respondList(c, bookings, totalCount, page, pageSize)
 }
 
 // GetBookingByID handles fetching a single booking by ID.
@@ -134,17 +159,13 @@ func (h *BookingHandler) GetBookingByID(c *gin.Context) {
 	booking, err := h.bookingService.GetBookingByID(bookingID)
 	if err != nil {
 		utils.LogError(err, "GetBookingByID: Error from bookingService.GetBookingByID for ID "+idStr)
-		if errors.Is(err, services.ErrBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Booking not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch booking.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch booking.")
 		return
 	}
 	c.JSON(http.StatusOK, booking)
 }
 
-// UpdateBooking handles updating a booking.
+// UpdateBooking handles updating a booking (PUT), or patching it with a JSON Merge Patch (PATCH).
 func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 	idStr := c.Param("id")
 	bookingID, err := strconv.ParseInt(idStr, 10, 64)
@@ -152,32 +173,30 @@ func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "UpdateBooking")
+	if !ok {
+		return
+	}
 
 	var req services.UpdateBookingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdateBooking: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindUpdate(c, &req, services.BookingClearableFields, &req.Clear) {
 		return
 	}
 
-	booking, err := h.bookingService.UpdateBooking(bookingID, req)
+	booking, err := h.bookingService.UpdateBooking(bookingID, req, userID)
 	if err != nil {
 		utils.LogError(err, "UpdateBooking: Error from bookingService.UpdateBooking for ID "+idStr)
-		if errors.Is(err, services.ErrBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Booking not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrTableNotAvailable) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrInvalidBookingTime) || errors.Is(err, services.ErrBookingValidation) || errors.Is(err, services.ErrShiftTimeFormat) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update booking.", "Internal error"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondWithCurrentBooking(c, bookingID)
+			return
 		}
+		respondWithServiceError(c, err, "Failed to update booking.")
 		return
 	}
 	c.JSON(http.StatusOK, booking)
 }
 
-// CancelBooking handles cancelling a booking.
+// CancelBooking handles cancelling a booking; the body gives the cancellation reason.
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	idStr := c.Param("id")
 	bookingID, err := strconv.ParseInt(idStr, 10, 64)
@@ -185,17 +204,23 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "CancelBooking")
+	if !ok {
+		return
+	}
+	var req services.CancelBookingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
 
-	booking, err := h.bookingService.CancelBooking(bookingID)
+	booking, err := h.bookingService.CancelBooking(bookingID, req, userID)
 	if err != nil {
 		utils.LogError(err, "CancelBooking: Error from bookingService.CancelBooking for ID "+idStr)
-		if errors.Is(err, services.ErrBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Booking not found to cancel.", err.Error()))
-		} else if errors.Is(err, services.ErrBookingStatusUpdate){
-             utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, err.Error(), err.Error()))
-        }else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to cancel booking.", "Internal error"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondWithCurrentBooking(c, bookingID)
+			return
 		}
+		respondWithServiceError(c, err, "Failed to cancel booking.")
 		return
 	}
 	c.JSON(http.StatusOK, booking)
@@ -209,23 +234,44 @@ func (h *BookingHandler) CompleteBooking(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "CompleteBooking")
+	if !ok {
+		return
+	}
 
-	booking, err := h.bookingService.CompleteBooking(bookingID)
+	booking, err := h.bookingService.CompleteBooking(bookingID, userID)
 	if err != nil {
 		utils.LogError(err, "CompleteBooking: Error from bookingService.CompleteBooking for ID "+idStr)
-		if errors.Is(err, services.ErrBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Booking not found to complete.", err.Error()))
-		} else if errors.Is(err, services.ErrBookingStatusUpdate){
-             utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, err.Error(), err.Error()))
-        } else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to complete booking.", "Internal error"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondWithCurrentBooking(c, bookingID)
+			return
 		}
+		respondWithServiceError(c, err, "Failed to complete booking.")
 		return
 	}
 	c.JSON(http.StatusOK, booking)
 }
 
-// DeleteBooking handles deleting a booking.
+// GetBookingHistory returns the changes of a booking (time, table, status), oldest first,
+// with who made each change and why.
+func (h *BookingHandler) GetBookingHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
+		return
+	}
+
+	changes, err := h.bookingService.GetBookingHistory(bookingID)
+	if err != nil {
+		utils.LogError(err, "GetBookingHistory: Error from bookingService.GetBookingHistory for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to fetch booking history.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+// DeleteBooking handles soft deleting a booking, recorded as by the authenticated user.
 func (h *BookingHandler) DeleteBooking(c *gin.Context) {
 	idStr := c.Param("id")
 	bookingID, err := strconv.ParseInt(idStr, 10, 64)
@@ -233,69 +279,87 @@ func (h *BookingHandler) DeleteBooking(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "DeleteBooking")
+	if !ok {
+		return
+	}
 
-	err = h.bookingService.DeleteBooking(bookingID)
+	err = h.bookingService.DeleteBooking(bookingID, userID)
 	if err != nil {
 		utils.LogError(err, "DeleteBooking: Error from bookingService.DeleteBooking for ID "+idStr)
-		if errors.Is(err, services.ErrBookingNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Booking not found to delete.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete booking.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete booking.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
 }
 
+// RestoreBooking restores a soft deleted booking; 409 if it is confirmed and another
+// confirmed booking of its table overlaps it since.
+func (h *BookingHandler) RestoreBooking(c *gin.Context) {
+	idStr := c.Param("id")
+	bookingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking ID format.", err.Error()))
+		return
+	}
+	userID, ok := currentUserID(c, "RestoreBooking")
+	if !ok {
+		return
+	}
+
+	booking, err := h.bookingService.RestoreBooking(bookingID, userID)
+	if err != nil {
+		utils.LogError(err, "RestoreBooking: Error from bookingService.RestoreBooking for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to restore booking.")
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
 // If table_booking_handlers.go existed, its content would be removed or this file replaces it.
 // For example, if there were old standalone functions:
 // func CreateBookingHandler(c *gin.Context) { /* ... */ }
 // func GetBookingsHandler(c *gin.Context) { /* ... */ }
-// ... they are now replaced by methods on BookingHandler.The `BookingRepository` (Part 1), `BookingService` (Part 2), and `BookingHandler` (Part 3) have been successfully implemented in the previous turns. All specified files were created/updated, and their contents align with the detailed instructions.
-
-**Summary of actions already taken for Parts 1, 2, & 3:**
-
-**Part 0: Prerequisites**
-    - `internal/models/table_booking_models.go` was updated with the `BookingFilters` struct.
-
-**Part 1: Booking Repository Layer (`internal/repositories/booking_repository.go`)**
-    - `BookingRepository` interface and `bookingRepository` struct (with `*sql.DB`) implemented.
-    - `NewBookingRepository` constructor created.
-    - Helper `scanBookingRow` (and `scanner` interface) implemented for DRY scanning of `Booking` with joined details. `GetBookings` was updated to correctly scan `total_count`.
-    - **All interface methods implemented**: `CreateBooking`, `GetBookingByID` (with joins), `GetBookings` (with filters, pagination, joins, and correct total count), `UpdateBooking`, `DeleteBooking`, and `CheckTableAvailability`.
-    - Standard error handling and `SQLExecutor` usage are in place.
-
-**Part 2: Booking Service Layer (`internal/services/booking_service.go`)**
-    - DTOs (`CreateBookingRequest`, `UpdateBookingRequest`) defined.
-    - `BookingService` interface (including `CancelBooking`, `CompleteBooking`) defined.
-    - `bookingService` struct (with `BookingRepository`, `ClientRepository`, `StaffRepository`, `*sql.DB`) and `NewBookingService` constructor implemented.
-    - Service-specific errors (e.g., `ErrBookingNotFound`, `ErrTableNotAvailable`) defined.
-    - Helper function `parseAndValidateBookingTimes` implemented.
-    - **All interface methods implemented**:
-        - `CreateBooking`: Validates input (times, foreign key existence via respective repos), checks table availability, sets default status, calls repository.
-        - `GetBookingByID`: Calls repository.
-        - `GetBookings`: Calls repository, handles default pagination.
-        - `UpdateBooking`: Fetches booking, validates, checks availability if times/table change, calls repository.
-        - `CancelBooking` & `CompleteBooking`: Implemented as wrappers around a general `updateBookingStatus` helper that calls `bookingRepo.UpdateBooking`.
-        - `DeleteBooking`: Checks existence, then calls repository.
-
-**Part 3: Booking Handler Refinement (`internal/handlers/booking_handlers.go`)**
-    - `BookingHandler` struct (with `services.BookingService`) and `NewBookingHandler` constructor implemented.
-    - Handler methods for Booking CRUD (`CreateBooking`, `GetBookings`, `GetBookingByID`, `UpdateBooking`, `DeleteBooking`) and status changes (`CancelBooking`, `CompleteBooking`) implemented as methods of `BookingHandler`.
-    - Methods bind JSON to service DTOs, parse path/query parameters (including date parsing for `GetBookings` filters), call `bookingService` methods, and map service errors to `utils.APIError` for HTTP responses.
-
-Now, I will proceed with **Part 4: Booking Dependency Injection Update**.
-
-**My plan for Part 4:**
-
-1.  **Update `internal/router/router.go`**:
-    *   Initialize `BookingRepository`.
-    *   Initialize `BookingService` with its repository dependencies (`BookingRepository`, `ClientRepository`, `StaffRepository`, and `db`).
-    *   Initialize `BookingHandler` with `BookingService`.
-    *   Pass the new `bookingHandler` to `SetupBookingRoutes`.
-
-2.  **Update `internal/router/route_groups.go`**:
-    *   Modify `SetupBookingRoutes` to accept `*handlers.BookingHandler`.
-    *   Update route definitions within `SetupBookingRoutes` to call methods on the passed `bookingHandler`, including the new routes for `CancelBooking` and `CompleteBooking`.
-
-Let's start by reading `internal/router/router.go`.
+// ... they are now replaced by methods on BookingHandler.
+
+// respondWithCurrentBooking answers a stale update with 409 and the booking as it is now.
+func (h *BookingHandler) respondWithCurrentBooking(c *gin.Context, bookingID int64) {
+	current, err := h.bookingService.GetBookingByID(bookingID)
+	if err != nil {
+		utils.LogError(err, "respondWithCurrentBooking: Failed to reload booking after version conflict")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, services.ErrVersionConflict.Error(), ""))
+		return
+	}
+	utils.RespondWithVersionConflict(c, current)
+}
+
+// CancelClientBookingsRequest selects the bookings of a client to cancel; without
+// booking_ids, all of the client's upcoming pending and confirmed bookings are cancelled.
+type CancelClientBookingsRequest struct {
+	ClientID           int64   `json:"client_id" binding:"required"`
+	BookingIDs         []int64 `json:"booking_ids"`
+	CancellationReason string  `json:"cancellation_reason" binding:"required,cancellation_reason"`
+	Reason             *string `json:"reason"`
+	AcceptLateFee      bool    `json:"accept_late_fee"` // Agrees to the late cancellation fee of each booking cancelled late
+}
+
+// CancelClientBookings cancels several bookings of a client at once and reports the outcome per booking.
+func (h *BookingHandler) CancelClientBookings(c *gin.Context) {
+	userID, ok := currentUserID(c, "CancelClientBookings")
+	if !ok {
+		return
+	}
+	var req CancelClientBookingsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	cancellation := services.CancelBookingRequest{CancellationReason: req.CancellationReason, Reason: req.Reason, AcceptLateFee: req.AcceptLateFee}
+	result, err := h.bookingService.CancelClientBookings(req.ClientID, req.BookingIDs, cancellation, userID)
+	if err != nil {
+		utils.LogError(err, "CancelClientBookings: Error from bookingService.CancelClientBookings")
+		respondWithServiceError(c, err, "Failed to cancel bookings.")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookingReminderHandler holds the booking reminder service. The staff, the client app and the
+// Telegram bot change how a client is reminded of its bookings through it, and the admins see
+// the reminders sent.
+type BookingReminderHandler struct {
+	reminderService services.BookingReminderService
+}
+
+// NewBookingReminderHandler creates a new BookingReminderHandler.
+func NewBookingReminderHandler(rs services.BookingReminderService) *BookingReminderHandler {
+	return &BookingReminderHandler{reminderService: rs}
+}
+
+// GetPreferences returns the channel and lead time of the reminders of a client, with the
+// booking_reminders setting filling in what it did not choose.
+func (h *BookingReminderHandler) GetPreferences(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	preferences, err := h.reminderService.GetPreferences(clientID)
+	if err != nil {
+		utils.LogError(err, "GetReminderPreferences: Error from reminderService.GetPreferences for client ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch reminder preferences.")
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences changes the channel, the lead time or the linked Telegram chat of the
+// reminders of a client.
+func (h *BookingReminderHandler) UpdatePreferences(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	var req services.UpdateBookingReminderPreferencesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	preferences, err := h.reminderService.UpdatePreferences(clientID, req)
+	if err != nil {
+		utils.LogError(err, "UpdateReminderPreferences: Error from reminderService.UpdatePreferences for client ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update reminder preferences.")
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// GetReminders lists the booking reminders sent and attempted, newest first, optionally only
+// those of a client_id or booking_id, through a channel, with a status, or between from and to
+// (YYYY-MM-DD, club time).
+func (h *BookingReminderHandler) GetReminders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 50
+	}
+	filters := models.BookingReminderFilters{Page: page, PageSize: pageSize}
+	if value := c.Query("client_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client_id value.", err.Error()))
+			return
+		}
+		filters.ClientID = &id
+	}
+	if value := c.Query("booking_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid booking_id value.", err.Error()))
+			return
+		}
+		filters.BookingID = &id
+	}
+	if channel := c.Query("channel"); channel != "" {
+		if !models.IsValidReminderChannel(channel) {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid channel value.",
+				"channel must be one of: "+strings.Join(models.ReminderChannels, ", ")))
+			return
+		}
+		filters.Channel = &channel
+	}
+	if status := c.Query("status"); status != "" {
+		filters.Status = &status
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	reminders, total, err := h.reminderService.GetReminders(filters)
+	if err != nil {
+		utils.LogError(err, "GetBookingReminders: Error from reminderService.GetReminders")
+		respondWithServiceError(c, err, "Failed to fetch booking reminders.")
+		return
+	}
+	respondList(c, reminders, total, page, pageSize)
+}
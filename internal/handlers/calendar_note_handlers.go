@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarNoteHandler holds the calendar note service.
+type CalendarNoteHandler struct {
+	calendarNoteService services.CalendarNoteService
+}
+
+// NewCalendarNoteHandler creates a new CalendarNoteHandler.
+func NewCalendarNoteHandler(cns services.CalendarNoteService) *CalendarNoteHandler {
+	return &CalendarNoteHandler{calendarNoteService: cns}
+}
+
+// parseCalendarNoteID parses the :id parameter, responding with an error if it is invalid.
+func parseCalendarNoteID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid calendar note ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateCalendarNote annotates a date or range of dates.
+func (h *CalendarNoteHandler) CreateCalendarNote(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreateCalendarNote")
+	if !ok {
+		return
+	}
+	var req services.CalendarNoteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	note, err := h.calendarNoteService.CreateNote(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreateCalendarNote: Error from calendarNoteService.CreateNote")
+		respondWithServiceError(c, err, "Failed to create calendar note.")
+		return
+	}
+	c.JSON(http.StatusCreated, note)
+}
+
+// GetCalendarNotes lists the notes overlapping ?from=&to= (YYYY-MM-DD, default the current month).
+func (h *CalendarNoteHandler) GetCalendarNotes(c *gin.Context) {
+	notes, err := h.calendarNoteService.GetNotes(c.Query("from"), c.Query("to"))
+	if err != nil {
+		utils.LogError(err, "GetCalendarNotes: Error from calendarNoteService.GetNotes")
+		respondWithServiceError(c, err, "Failed to fetch calendar notes.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": notes})
+}
+
+// UpdateCalendarNote replaces the dates, category, title and note of a calendar note.
+func (h *CalendarNoteHandler) UpdateCalendarNote(c *gin.Context) {
+	id, ok := parseCalendarNoteID(c)
+	if !ok {
+		return
+	}
+	var req services.CalendarNoteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	note, err := h.calendarNoteService.UpdateNote(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateCalendarNote: Error from calendarNoteService.UpdateNote for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update calendar note.")
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteCalendarNote deletes a calendar note.
+func (h *CalendarNoteHandler) DeleteCalendarNote(c *gin.Context) {
+	id, ok := parseCalendarNoteID(c)
+	if !ok {
+		return
+	}
+	if err := h.calendarNoteService.DeleteNote(id); err != nil {
+		utils.LogError(err, "DeleteCalendarNote: Error from calendarNoteService.DeleteNote for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete calendar note.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar note deleted successfully"})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChannelHandler serves the self-service channels, the kiosk, the client app and the
+// Telegram bot, which authenticate with an API key of their scope. What they create is
+// recorded with the scope as its source.
+type ChannelHandler struct {
+	orderService   services.OrderService
+	bookingService services.BookingService
+}
+
+// NewChannelHandler creates a new ChannelHandler.
+func NewChannelHandler(os services.OrderService, bs services.BookingService) *ChannelHandler {
+	return &ChannelHandler{orderService: os, bookingService: bs}
+}
+
+// ChannelOrderRequest is the body of POST /channels/:scope/orders. No staff member takes the
+// order, and it can only be discounted with a promo code.
+type ChannelOrderRequest struct {
+	ClientID      *int64                            `json:"client_id"`
+	TableID       *int64                            `json:"table_id"`
+	PaymentMethod *string                           `json:"payment_method"`
+	Notes         *string                           `json:"notes"`
+	OrderItems    []services.CreateOrderItemRequest `json:"order_items" binding:"required,dive"`
+	GuestCount    *int                              `json:"guest_count" binding:"omitempty,min=1"`
+	PromoCode     *string                           `json:"promo_code"`
+	UUID          *string                           `json:"uuid" binding:"omitempty,uuid"`
+}
+
+// ChannelBookingRequest is the body of POST /channels/:scope/bookings. No staff member takes
+// the booking, and it must meet the minimum notice of the booking policy.
+type ChannelBookingRequest struct {
+	ClientID       *int64  `json:"client_id"`
+	TableID        int64   `json:"table_id" binding:"required"`
+	StartTime      string  `json:"start_time" binding:"required"`
+	EndTime        string  `json:"end_time" binding:"required"`
+	NumberOfGuests *int    `json:"number_of_guests"`
+	Notes          *string `json:"notes"`
+	Controllers    *int    `json:"controllers" binding:"omitempty,min=1"`
+	UUID           *string `json:"uuid" binding:"omitempty,uuid"`
+}
+
+// CreateOrder places a pending order from a self-service channel.
+func (h *ChannelHandler) CreateOrder(c *gin.Context) {
+	var req ChannelOrderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	order, err := h.orderService.CreateOrder(services.CreateOrderRequest{
+		ClientID:      req.ClientID,
+		TableID:       req.TableID,
+		Status:        services.StatusPending,
+		PaymentMethod: req.PaymentMethod,
+		Notes:         req.Notes,
+		OrderItems:    req.OrderItems,
+		GuestCount:    req.GuestCount,
+		PromoCode:     req.PromoCode,
+		UUID:          req.UUID,
+		Source:        requestSource(c),
+	})
+	if err != nil {
+		utils.LogError(err, "CreateChannelOrder: Error from orderService.CreateOrder for source "+requestSource(c))
+		respondWithServiceError(c, err, "Failed to create order.")
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// CreateBooking books a table from a self-service channel. A blacklisted client is refused.
+func (h *ChannelHandler) CreateBooking(c *gin.Context) {
+	var req ChannelBookingRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	status := string(models.BookingStatusConfirmed)
+	booking, err := h.bookingService.CreateBooking(services.CreateBookingRequest{
+		ClientID:       req.ClientID,
+		TableID:        req.TableID,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		NumberOfGuests: req.NumberOfGuests,
+		Notes:          req.Notes,
+		Status:         &status,
+		Controllers:    req.Controllers,
+		UUID:           req.UUID,
+		Source:         requestSource(c),
+	}, 0)
+	if err != nil {
+		utils.LogError(err, "CreateChannelBooking: Error from bookingService.CreateBooking for source "+requestSource(c))
+		respondWithServiceError(c, err, "Failed to create booking.")
+		return
+	}
+	c.JSON(http.StatusCreated, booking)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientAccountHandler holds the house account service.
+type ClientAccountHandler struct {
+	accountService services.ClientAccountService
+}
+
+// NewClientAccountHandler creates a new ClientAccountHandler.
+func NewClientAccountHandler(as services.ClientAccountService) *ClientAccountHandler {
+	return &ClientAccountHandler{accountService: as}
+}
+
+// parseAccountClientID parses the :id parameter, the client of the account, responding with an error if it is invalid.
+func parseAccountClientID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// SaveAccount opens the house account of a client or changes its credit limit, terms or status.
+func (h *ClientAccountHandler) SaveAccount(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.ClientAccountRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	account, err := h.accountService.SaveAccount(clientID, req)
+	if err != nil {
+		utils.LogError(err, "SaveAccount: Error from accountService.SaveAccount for client "+c.Param("id"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.accountService.GetAccount(clientID)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to save house account.")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// GetAccount returns the house account of a client with its balance.
+func (h *ClientAccountHandler) GetAccount(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	account, err := h.accountService.GetAccount(clientID)
+	if err != nil {
+		utils.LogError(err, "GetAccount: Error from accountService.GetAccount for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch house account.")
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// GetAccounts lists the house accounts with their balances; overdue=true lists only the overdue ones.
+func (h *ClientAccountHandler) GetAccounts(c *gin.Context) {
+	overdueOnly := false
+	if value := c.Query("overdue"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid overdue value.", err.Error()))
+			return
+		}
+		overdueOnly = parsed
+	}
+	accounts, err := h.accountService.GetAccounts(overdueOnly)
+	if err != nil {
+		utils.LogError(err, "GetAccounts: Error from accountService.GetAccounts")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch house accounts.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// GetStatement returns the statement of a house account for the time range (see
+// bindTimeRange), by default from the start of the month to today.
+func (h *ClientAccountHandler) GetStatement(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	from, to := timeRange.From, timeRange.To
+	statement, err := h.accountService.GetStatement(clientID, from, to)
+	if err != nil {
+		utils.LogError(err, "GetStatement: Error from accountService.GetStatement for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch house account statement.")
+		return
+	}
+	c.JSON(http.StatusOK, statement)
+}
+
+// RecordPayment records a payment against the balance of a house account.
+func (h *ClientAccountHandler) RecordPayment(c *gin.Context) {
+	userID, ok := currentUserID(c, "RecordPayment")
+	if !ok {
+		return
+	}
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.AccountPaymentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	entry, err := h.accountService.RecordPayment(clientID, req, userID)
+	if err != nil {
+		utils.LogError(err, "RecordPayment: Error from accountService.RecordPayment for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to record payment.")
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
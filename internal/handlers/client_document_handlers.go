@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientDocumentHandler holds the client document service.
+type ClientDocumentHandler struct {
+	documentService services.ClientDocumentService
+}
+
+// NewClientDocumentHandler creates a new ClientDocumentHandler.
+func NewClientDocumentHandler(cds services.ClientDocumentService) *ClientDocumentHandler {
+	return &ClientDocumentHandler{documentService: cds}
+}
+
+// GetClientDocuments returns the documents and ID verification of a client.
+func (h *ClientDocumentHandler) GetClientDocuments(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	documents, err := h.documentService.GetDocuments(clientID)
+	if err != nil {
+		utils.LogError(err, "GetClientDocuments: Error from documentService.GetDocuments for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch client documents.")
+		return
+	}
+	c.JSON(http.StatusOK, documents)
+}
+
+// AddClientDocument stores the "photo" file of a multipart form as a document of a client, of
+// the form's document_type and valid until its expires_on (YYYY-MM-DD), if set.
+func (h *ClientDocumentHandler) AddClientDocument(c *gin.Context) {
+	userID, ok := currentUserID(c, "AddClientDocument")
+	if !ok {
+		return
+	}
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	data, ok := readPhotoUpload(c, "AddClientDocument")
+	if !ok {
+		return
+	}
+	var expiresOn *string
+	if value, ok := c.GetPostForm("expires_on"); ok {
+		expiresOn = &value
+	}
+	document, err := h.documentService.AddDocument(clientID, c.PostForm("document_type"), expiresOn, data, userID)
+	if err != nil {
+		utils.LogError(err, "AddClientDocument: Error from documentService.AddDocument for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to add client document.")
+		return
+	}
+	c.JSON(http.StatusCreated, document)
+}
+
+// GetClientDocumentPhoto serves the photo of a document of a client.
+func (h *ClientDocumentHandler) GetClientDocumentPhoto(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	documentID, ok := parseIncidentParam(c, "document_id", "document")
+	if !ok {
+		return
+	}
+	photo, err := h.documentService.GetDocumentPhoto(clientID, documentID)
+	if err != nil {
+		utils.LogError(err, "GetClientDocumentPhoto: Error from documentService.GetDocumentPhoto for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch client document.")
+		return
+	}
+	c.Header("Cache-Control", "no-store") // Identity documents must not linger in caches
+	c.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+// DeleteClientDocument deletes a document of a client.
+func (h *ClientDocumentHandler) DeleteClientDocument(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	documentID, ok := parseIncidentParam(c, "document_id", "document")
+	if !ok {
+		return
+	}
+	if err := h.documentService.DeleteDocument(clientID, documentID); err != nil {
+		utils.LogError(err, "DeleteClientDocument: Error from documentService.DeleteDocument for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete client document.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Client document deleted successfully"})
+}
+
+// VerifyClientID records that the identity of a client was checked.
+func (h *ClientDocumentHandler) VerifyClientID(c *gin.Context) {
+	userID, ok := currentUserID(c, "VerifyClientID")
+	if !ok {
+		return
+	}
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.VerifyClientIDRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	verification, err := h.documentService.VerifyID(clientID, req, userID)
+	if err != nil {
+		utils.LogError(err, "VerifyClientID: Error from documentService.VerifyID for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to verify client ID.")
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// RevokeClientIDVerification removes the ID verification of a client.
+func (h *ClientDocumentHandler) RevokeClientIDVerification(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	if err := h.documentService.RevokeIDVerification(clientID); err != nil {
+		utils.LogError(err, "RevokeClientIDVerification: Error from documentService.RevokeIDVerification for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to revoke client ID verification.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Client ID verification revoked successfully"})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+)
+
+// csvFile is a CSV file of a ZIP export: its name and header row.
+type csvFile struct {
+	name   string
+	header []string
+}
+
+// Files of a client data export in CSV format.
+var clientDataFiles = []csvFile{
+	{"client.csv", []string{"id", "full_name", "phone_number", "email", "date_of_birth", "loyalty_points", "notes", "created_at", "updated_at", "anonymized_at"}},
+	{"bookings.csv", []string{"id", "table", "start_time", "end_time", "number_of_guests", "status", "total_price", "notes", "created_at"}},
+	{"orders.csv", []string{"id", "order_number", "order_time", "status", "total_amount", "discount_amount", "final_amount", "payment_method", "notes"}},
+	{"order_items.csv", []string{"order_id", "item", "quantity", "unit_price", "total_price", "notes"}},
+}
+
+// csvZipWriter writes a ZIP of CSV files one after the other, record by record, so an
+// export is written out as it is read instead of being built in memory first.
+type csvZipWriter struct {
+	zw      *zip.Writer
+	files   []csvFile
+	created int // Number of files created so far
+	cw      *csv.Writer
+}
+
+func newCSVZipWriter(w io.Writer, files []csvFile) *csvZipWriter {
+	return &csvZipWriter{zw: zip.NewWriter(w), files: files}
+}
+
+// write appends a record to the file at index file. Files are written in order: the files
+// up to it are created first, and earlier files cannot be written to anymore.
+func (w *csvZipWriter) write(file int, record []string) error {
+	if err := w.advance(file + 1); err != nil {
+		return err
+	}
+	return w.cw.Write(record)
+}
+
+// close creates the files not written to yet and finishes the ZIP.
+func (w *csvZipWriter) close() error {
+	if err := w.advance(len(w.files)); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// advance creates files, with their header rows, until n files exist.
+func (w *csvZipWriter) advance(n int) error {
+	for w.created < n {
+		if err := w.flush(); err != nil {
+			return err
+		}
+		f, err := w.zw.Create(w.files[w.created].name)
+		if err != nil {
+			return err
+		}
+		w.cw = csv.NewWriter(f)
+		if err := w.cw.Write(w.files[w.created].header); err != nil {
+			return err
+		}
+		w.created++
+	}
+	return nil
+}
+
+func (w *csvZipWriter) flush() error {
+	if w.cw == nil {
+		return nil
+	}
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// clientDataZipStream returns a stream writing a client data export to w as a ZIP of the
+// clientDataFiles, and the function finishing the ZIP once the stream has ended. start is
+// called before anything is written.
+func clientDataZipStream(w io.Writer, start func()) (services.ClientDataStream, func() error) {
+	zw := newCSVZipWriter(w, clientDataFiles)
+	stream := services.ClientDataStream{
+		Client: func(client *models.Client) error {
+			start()
+			loyaltyPoints := ""
+			if client.LoyaltyPoints != nil {
+				loyaltyPoints = strconv.Itoa(*client.LoyaltyPoints)
+			}
+			return zw.write(0, []string{
+				strconv.FormatInt(client.ID, 10), client.FullName, csvString(client.PhoneNumber), csvString(client.Email),
+				csvString(client.DateOfBirth), loyaltyPoints, csvString(client.Notes), csvTime(client.CreatedAt),
+				csvTime(client.UpdatedAt), csvOptionalTime(client.AnonymizedAt),
+			})
+		},
+		Booking: func(b *models.Booking) error {
+			guests := ""
+			if b.NumberOfGuests != nil {
+				guests = strconv.Itoa(*b.NumberOfGuests)
+			}
+			tableName := ""
+			if b.GameTable != nil {
+				tableName = b.GameTable.Name
+			}
+			return zw.write(1, []string{
+				strconv.FormatInt(b.ID, 10), tableName, csvTime(b.StartTime), csvTime(b.EndTime), guests, b.Status,
+				csvOptionalMoney(b.TotalPrice), csvString(b.Notes), csvTime(b.CreatedAt),
+			})
+		},
+		Order: func(o *models.Order) error {
+			return zw.write(2, []string{
+				strconv.FormatInt(o.ID, 10), o.OrderNumber, csvTime(o.OrderTime), o.Status, csvMoney(o.TotalAmount),
+				csvOptionalMoney(o.DiscountAmount), csvMoney(o.FinalAmount), csvString(o.PaymentMethod), csvString(o.Notes),
+			})
+		},
+		OrderItem: func(item *models.OrderItem) error {
+			name := ""
+			if item.PricelistItem != nil {
+				name = item.PricelistItem.Name
+			}
+			return zw.write(3, []string{
+				strconv.FormatInt(item.OrderID, 10), name, strconv.Itoa(item.Quantity), csvMoney(item.UnitPrice),
+				csvMoney(item.TotalPrice), csvString(item.Notes),
+			})
+		},
+	}
+	return stream, zw.close
+}
+
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return csvTime(*t)
+}
+
+// csvMoney renders an amount as a plain decimal, like the JSON API does.
+func csvMoney(m models.Money) string {
+	return m.Decimal().StringFixed(int32(utils.CurrentCurrency().Decimals))
+}
+
+func csvOptionalMoney(m *models.Money) string {
+	if m == nil {
+		return ""
+	}
+	return csvMoney(*m)
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils"
 
@@ -21,33 +23,29 @@ func NewClientHandler(cs services.ClientService) *ClientHandler {
 	return &ClientHandler{clientService: cs}
 }
 
-// CreateClient handles the creation of a new client.
+// CreateClient handles the creation of a new client. Probable duplicates of existing clients
+// are listed in a 409 response unless the request sets force.
 func (h *ClientHandler) CreateClient(c *gin.Context) {
 	var req services.CreateClientRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateClient: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	client, err := h.clientService.CreateClient(req)
 	if err != nil {
-		utils.LogError(err, "CreateClient: Error from clientService.CreateClient")
-		if errors.Is(err, services.ErrPhoneNumberExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Phone number already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrEmailExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Email already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrClientValidation) || errors.Is(err, services.ErrDateFormat){
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create client.", "Internal error"))
+		var duplicates *services.ClientDuplicatesError
+		if errors.As(err, &duplicates) {
+			utils.RespondWithClientDuplicates(c, duplicates.Candidates)
+			return
 		}
+		utils.LogError(err, "CreateClient: Error from clientService.CreateClient")
+		respondWithServiceError(c, err, "Failed to create client.")
 		return
 	}
 	c.JSON(http.StatusCreated, client)
 }
 
-// GetClients handles fetching all clients with pagination and search.
+// GetClients handles fetching all clients with pagination, search and a tag filter.
 func (h *ClientHandler) GetClients(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
@@ -60,8 +58,12 @@ func (h *ClientHandler) GetClients(c *gin.Context) {
 	if searchTerm != "" {
 		pSearchTerm = &searchTerm
 	}
+	var tag *string
+	if value := c.Query("tag"); value != "" {
+		tag = &value
+	}
 
-	clients, totalCount, err := h.clientService.GetClients(page, pageSize, pSearchTerm)
+	clients, totalCount, err := h.clientService.GetClients(page, pageSize, pSearchTerm, tag)
 	if err != nil {
 		utils.LogError(err, "GetClients: Error from clientService.GetClients")
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch clients.", "Internal error"))
@@ -72,12 +74,7 @@ func (h *ClientHandler) GetClients(c *gin.Context) {
 	    clients = []models.Client{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  clients,
-		"total": totalCount,
-		"page":  page,
-		"page_size": pageSize,
-	})
+	respondList(c, clients, totalCount, page, pageSize)
 }
 
 // GetClientByID handles fetching a single client by ID.
@@ -92,17 +89,13 @@ func (h *ClientHandler) GetClientByID(c *gin.Context) {
 	client, err := h.clientService.GetClientByID(clientID)
 	if err != nil {
 		utils.LogError(err, "GetClientByID: Error from clientService.GetClientByID for ID "+idStr)
-		if errors.Is(err, services.ErrClientNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Client not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch client.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch client.")
 		return
 	}
 	c.JSON(http.StatusOK, client)
 }
 
-// UpdateClient handles updating a client.
+// UpdateClient handles updating a client (PUT), or patching it with a JSON Merge Patch (PATCH).
 func (h *ClientHandler) UpdateClient(c *gin.Context) {
 	idStr := c.Param("id")
 	clientID, err := strconv.ParseInt(idStr, 10, 64)
@@ -112,32 +105,20 @@ func (h *ClientHandler) UpdateClient(c *gin.Context) {
 	}
 
 	var req services.UpdateClientRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdateClient: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindUpdate(c, &req, services.ClientClearableFields, &req.Clear) {
 		return
 	}
 
 	client, err := h.clientService.UpdateClient(clientID, req)
 	if err != nil {
 		utils.LogError(err, "UpdateClient: Error from clientService.UpdateClient for ID "+idStr)
-		if errors.Is(err, services.ErrClientNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Client not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrPhoneNumberExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Phone number already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrEmailExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Email already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrClientValidation) || errors.Is(err, services.ErrDateFormat){
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update client.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to update client.")
 		return
 	}
 	c.JSON(http.StatusOK, client)
 }
 
-// DeleteClient handles deleting a client.
+// DeleteClient handles soft deleting a client, recorded as by the authenticated user.
 func (h *ClientHandler) DeleteClient(c *gin.Context) {
 	idStr := c.Param("id")
 	clientID, err := strconv.ParseInt(idStr, 10, 64)
@@ -145,22 +126,151 @@ func (h *ClientHandler) DeleteClient(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "DeleteClient")
+	if !ok {
+		return
+	}
 
-	err = h.clientService.DeleteClient(clientID)
+	err = h.clientService.DeleteClient(clientID, userID)
 	if err != nil {
 		utils.LogError(err, "DeleteClient: Error from clientService.DeleteClient for ID "+idStr)
-		if errors.Is(err, services.ErrClientNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Client not found to delete.", err.Error()))
-		} else if errors.Is(err, services.ErrClientInUse) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Client cannot be deleted as they are referenced in other records.", err.Error()))
-		}else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete client.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete client.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Client deleted successfully"})
 }
 
+// RestoreClient restores a soft deleted client; 409 if another client has its phone number
+// or email since.
+func (h *ClientHandler) RestoreClient(c *gin.Context) {
+	idStr := c.Param("id")
+	clientID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
+		return
+	}
+	userID, ok := currentUserID(c, "RestoreClient")
+	if !ok {
+		return
+	}
+
+	client, err := h.clientService.RestoreClient(clientID, userID)
+	if err != nil {
+		utils.LogError(err, "RestoreClient: Error from clientService.RestoreClient for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to restore client.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// ExportClientData handles a data access request: it returns everything stored about
+// the client, as JSON or, with ?format=csv, as a ZIP of CSV files.
+func (h *ClientHandler) ExportClientData(c *gin.Context) {
+	idStr := c.Param("id")
+	clientID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid format, use json or csv.", format))
+		return
+	}
+
+	filename := fmt.Sprintf("client-%d-data", clientID)
+	if format == "csv" {
+		h.streamClientDataZip(c, clientID, filename+".zip")
+		return
+	}
+
+	export, err := h.clientService.ExportClientData(clientID)
+	if err != nil {
+		utils.LogError(err, "ExportClientData: Error from clientService.ExportClientData for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to export client data.")
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// streamClientDataZip writes the client data export as a ZIP of CSV files while it is read.
+func (h *ClientHandler) streamClientDataZip(c *gin.Context, clientID int64, filename string) {
+	download := &exportStream{c: c, contentType: "application/zip", filename: filename}
+	stream, finish := clientDataZipStream(c.Writer, download.start)
+	err := h.clientService.StreamClientData(clientID, stream)
+	if err == nil {
+		err = finish()
+	}
+	if err != nil {
+		utils.LogError(err, fmt.Sprintf("ExportClientData: Failed to stream CSV bundle for ID %d", clientID))
+	}
+	if download.end(err) {
+		return
+	}
+	respondWithServiceError(c, err, "Failed to export client data.")
+}
+
+// AnonymizeClient handles an erasure request: it irreversibly scrubs the client's
+// personal data while keeping their bookings and orders for the sales figures.
+func (h *ClientHandler) AnonymizeClient(c *gin.Context) {
+	idStr := c.Param("id")
+	clientID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
+		return
+	}
+
+	client, err := h.clientService.AnonymizeClient(clientID)
+	if err != nil {
+		utils.LogError(err, "AnonymizeClient: Error from clientService.AnonymizeClient for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to anonymize client.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// BlacklistClient puts a client on the blacklist with a reason and, optionally, an end,
+// recorded as by the authenticated user.
+func (h *ClientHandler) BlacklistClient(c *gin.Context) {
+	userID, ok := currentUserID(c, "BlacklistClient")
+	if !ok {
+		return
+	}
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.BlacklistClientRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	client, err := h.clientService.BlacklistClient(clientID, req, userID)
+	if err != nil {
+		utils.LogError(err, "BlacklistClient: Error from clientService.BlacklistClient for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to blacklist client.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// LiftBlacklist takes a client off the blacklist.
+func (h *ClientHandler) LiftBlacklist(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.clientService.LiftBlacklist(clientID)
+	if err != nil {
+		utils.LogError(err, "LiftBlacklist: Error from clientService.LiftBlacklist for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to lift client blacklist.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
 // Remove or comment out old standalone functions if they existed, e.g.:
 // func CreateClient(c *gin.Context) { /* ... */ }
 // func GetClients(c *gin.Context) { /* ... */ }
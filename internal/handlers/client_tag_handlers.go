@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientTagHandler holds the client tag service.
+type ClientTagHandler struct {
+	tagService services.ClientTagService
+}
+
+// NewClientTagHandler creates a new ClientTagHandler.
+func NewClientTagHandler(cts services.ClientTagService) *ClientTagHandler {
+	return &ClientTagHandler{tagService: cts}
+}
+
+// GetClientTags lists the preset tags and the tags in use, with how many clients have each.
+func (h *ClientTagHandler) GetClientTags(c *gin.Context) {
+	tags, err := h.tagService.GetTags()
+	if err != nil {
+		utils.LogError(err, "GetClientTags: Error from tagService.GetTags")
+		respondWithServiceError(c, err, "Failed to fetch client tags.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// SetClientTags replaces the tags of a client with those in the body.
+func (h *ClientTagHandler) SetClientTags(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.ClientTagsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	client, err := h.tagService.SetClientTags(clientID, req.Tags)
+	if err != nil {
+		utils.LogError(err, "SetClientTags: Error from tagService.SetClientTags for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to set client tags.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// AddClientTags adds the tags in the body to a client.
+func (h *ClientTagHandler) AddClientTags(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	var req services.ClientTagsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	client, err := h.tagService.AddClientTags(clientID, req.Tags)
+	if err != nil {
+		utils.LogError(err, "AddClientTags: Error from tagService.AddClientTags for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to add client tags.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// RemoveClientTag removes a tag from a client.
+func (h *ClientTagHandler) RemoveClientTag(c *gin.Context) {
+	clientID, ok := parseAccountClientID(c)
+	if !ok {
+		return
+	}
+	client, err := h.tagService.RemoveClientTag(clientID, c.Param("tag"))
+	if err != nil {
+		utils.LogError(err, "RemoveClientTag: Error from tagService.RemoveClientTag for client "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to remove client tag.")
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// GetClientTagReport sums up the sales and bookings of the clients of each tag, or only of a
+// tag, for the time range (see bindTimeRange), by default from the start of the month to
+// today.
+func (h *ClientTagHandler) GetClientTagReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	from, to := timeRange.From, timeRange.To
+	var tag *string
+	if value := c.Query("tag"); value != "" {
+		tag = &value
+	}
+	items, err := h.tagService.GetTagReport(from, to, tag)
+	if err != nil {
+		utils.LogError(err, "GetClientTagReport: Error from tagService.GetTagReport")
+		respondWithServiceError(c, err, "Failed to fetch client tag report.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
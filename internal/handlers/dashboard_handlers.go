@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DashboardHandler holds the report service backing the dashboard.
+type DashboardHandler struct {
+	reportService services.ReportService
+}
+
+// NewDashboardHandler creates a new DashboardHandler.
+func NewDashboardHandler(rs services.ReportService) *DashboardHandler {
+	return &DashboardHandler{reportService: rs}
+}
+
+// GetDashboardSummary provides a summary of key metrics for the dashboard.
+func (h *DashboardHandler) GetDashboardSummary(c *gin.Context) {
+	summary, err := h.reportService.GetDashboardSummary()
+	if err != nil {
+		utils.LogError(err, "GetDashboardSummary: Error from reportService.GetDashboardSummary")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to get dashboard summary.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetActivityFeed serves GET /dashboard/activity?limit=20&cursor=...: recent significant
+// events, newest first. next_cursor is null on the last page.
+func (h *DashboardHandler) GetActivityFeed(c *gin.Context) {
+	limit := services.DefaultActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid limit, must be a positive integer.", limitStr))
+			return
+		}
+		limit = parsed
+	}
+
+	feed, err := h.reportService.GetActivityFeed(c.Query("cursor"), limit)
+	if err != nil {
+		utils.LogError(err, "GetActivityFeed: Error from reportService.GetActivityFeed")
+		respondWithServiceError(c, err, "Failed to get activity feed.")
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+// GetPeriodReport serves GET /reports/summary?from=&to=&period=daily|weekly|monthly: revenue,
+// orders, booked hours and sales per category from the daily summaries, latest first. The
+// report covers the business days the time range (see bindTimeRange) touches.
+func (h *DashboardHandler) GetPeriodReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	startDate, endDate := timeRange.ClubDates()
+	report, err := h.reportService.GetPeriodReport(startDate, endDate, c.Query("period"))
+	if err != nil {
+		utils.LogError(err, "GetPeriodReport: Error from reportService.GetPeriodReport")
+		respondWithServiceError(c, err, "Failed to get period report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTaxReport serves GET /reports/tax?from=&to=&period=daily|weekly|monthly: net sales, tax
+// and gross sales per tax class and rate, latest period first, for accounting. The report
+// covers the business days the time range (see bindTimeRange) touches.
+func (h *DashboardHandler) GetTaxReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	startDate, endDate := timeRange.ClubDates()
+	report, err := h.reportService.GetTaxReport(startDate, endDate, c.Query("period"))
+	if err != nil {
+		utils.LogError(err, "GetTaxReport: Error from reportService.GetTaxReport")
+		respondWithServiceError(c, err, "Failed to get tax report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ComparePeriods serves GET /reports/compare?metric=&granularity=day|hour&period_a=&period_b=:
+// the series of a metric over two periods aligned by day or hour, with the change between them,
+// e.g. period_a=this_week&period_b=last_week. A period is a range preset, a date, or from and
+// to joined by ".."; period_b defaults to the period of the same length before period_a.
+func (h *DashboardHandler) ComparePeriods(c *gin.Context) {
+	periodA, err := timeRangeFromPeriod(c.Query("period_a"))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid period_a: "+err.Error(), err.Error()))
+		return
+	}
+	var periodB utils.TimeRange
+	if value := c.Query("period_b"); value != "" {
+		if periodB, err = timeRangeFromPeriod(value); err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid period_b: "+err.Error(), err.Error()))
+			return
+		}
+	}
+	comparison, err := h.reportService.ComparePeriods(c.Query("metric"), c.Query("granularity"), periodA, periodB)
+	if err != nil {
+		utils.LogError(err, "ComparePeriods: Error from reportService.ComparePeriods")
+		respondWithServiceError(c, err, "Failed to compare periods.")
+		return
+	}
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetStaffingReport serves GET /reports/staffing?from=&to=&threshold=: the hours in which the
+// occupied tables per staff member on shift exceeded threshold, and the hours of the week to
+// add shifts at. The time range (see bindTimeRange) defaults to the last 30 days before today.
+func (h *DashboardHandler) GetStaffingReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	var threshold float64
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "threshold must be a positive number of tables per staff member.", value))
+			return
+		}
+		threshold = parsed
+	}
+	report, err := h.reportService.GetStaffingReport(timeRange, threshold)
+	if err != nil {
+		utils.LogError(err, "GetStaffingReport: Error from reportService.GetStaffingReport")
+		respondWithServiceError(c, err, "Failed to get staffing report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetVoidReport serves GET /reports/voids?from=&to=: the orders cancelled and items voided by
+// staff member, reason and shift. The time range (see bindTimeRange) defaults to the last 30
+// days before today.
+func (h *DashboardHandler) GetVoidReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	report, err := h.reportService.GetVoidReport(timeRange)
+	if err != nil {
+		utils.LogError(err, "GetVoidReport: Error from reportService.GetVoidReport")
+		respondWithServiceError(c, err, "Failed to get void report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDiscountReport serves GET /reports/discounts?from=&to=&period=daily|weekly|monthly: the
+// discounts of the sales by promo code, staff member and period, with the share of the orders
+// discounted and the revenue forgone. The report covers the business days the time range (see
+// bindTimeRange) touches.
+func (h *DashboardHandler) GetDiscountReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	startDate, endDate := timeRange.ClubDates()
+	report, err := h.reportService.GetDiscountReport(startDate, endDate, c.Query("period"))
+	if err != nil {
+		utils.LogError(err, "GetDiscountReport: Error from reportService.GetDiscountReport")
+		respondWithServiceError(c, err, "Failed to get discount report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSourceReport breaks the sales and bookings of a range out by the channel they came through.
+func (h *DashboardHandler) GetSourceReport(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	startDate, endDate := timeRange.ClubDates()
+	report, err := h.reportService.GetSourceReport(startDate, endDate)
+	if err != nil {
+		utils.LogError(err, "GetSourceReport: Error from reportService.GetSourceReport")
+		respondWithServiceError(c, err, "Failed to get source report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
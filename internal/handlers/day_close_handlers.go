@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DayCloseHandler holds the day close service.
+type DayCloseHandler struct {
+	dayCloseService services.DayCloseService
+}
+
+// NewDayCloseHandler creates a new DayCloseHandler.
+func NewDayCloseHandler(dcs services.DayCloseService) *DayCloseHandler {
+	return &DayCloseHandler{dayCloseService: dcs}
+}
+
+// CloseDay closes a business day and responds with its summary. Records of the day still
+// open are listed in a 409 response unless the request forces them closed with a reason.
+func (h *DayCloseHandler) CloseDay(c *gin.Context) {
+	userID, ok := currentUserID(c, "CloseDay")
+	if !ok {
+		return
+	}
+	var req services.DayCloseRequest
+	// The body is optional: closing today without force needs none
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	summary, err := h.dayCloseService.CloseDay(req, userID)
+	if err != nil {
+		var blocked *services.DayCloseBlockedError
+		if errors.As(err, &blocked) {
+			utils.RespondWithDayCloseBlocked(c, blocked.Open)
+			return
+		}
+		utils.LogError(err, "CloseDay: Error from dayCloseService.CloseDay")
+		respondWithServiceError(c, err, "Failed to close the day.")
+		return
+	}
+	c.JSON(http.StatusCreated, summary)
+}
+
+// GetDailySummaries lists the summaries of closed days, latest first, optionally only
+// those of the business days the time range touches (see bindTimeRange).
+func (h *DayCloseHandler) GetDailySummaries(c *gin.Context) {
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	from, to := timeRange.ClubDates()
+	summaries, err := h.dayCloseService.GetSummaries(from, to)
+	if err != nil {
+		utils.LogError(err, "GetDailySummaries: Error from dayCloseService.GetSummaries")
+		respondWithServiceError(c, err, "Failed to fetch daily summaries.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
+}
+
+// GetDailySummary returns the summary of a closed day (:date is YYYY-MM-DD).
+func (h *DayCloseHandler) GetDailySummary(c *gin.Context) {
+	summary, err := h.dayCloseService.GetSummary(c.Param("date"))
+	if err != nil {
+		utils.LogError(err, "GetDailySummary: Error from dayCloseService.GetSummary for "+c.Param("date"))
+		respondWithServiceError(c, err, "Failed to fetch daily summary.")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
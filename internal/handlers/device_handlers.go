@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceHandler holds the device service.
+type DeviceHandler struct {
+	deviceService services.DeviceService
+}
+
+// NewDeviceHandler creates a new DeviceHandler.
+func NewDeviceHandler(ds services.DeviceService) *DeviceHandler {
+	return &DeviceHandler{deviceService: ds}
+}
+
+// parseDeviceID parses the :id parameter, responding with an error if it is invalid.
+func parseDeviceID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid device ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// RegisterDevice registers the device calling with an API key of the device scope, or records
+// that it registered again, and responds with it and its settings: 201 if it is new.
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	var req services.RegisterDeviceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	device, created, err := h.deviceService.RegisterDevice(req, c.GetInt64("apiKeyID"), c.ClientIP())
+	if err != nil {
+		utils.LogError(err, "RegisterDevice: Error from deviceService.RegisterDevice")
+		respondWithServiceError(c, err, "Failed to register device.")
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, device)
+}
+
+// DeviceHeartbeat marks the calling device online and responds with its current settings.
+func (h *DeviceHandler) DeviceHeartbeat(c *gin.Context) {
+	var req services.DeviceHeartbeatRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	device, err := h.deviceService.Heartbeat(req, c.GetInt64("apiKeyID"), c.ClientIP())
+	if err != nil {
+		utils.LogError(err, "DeviceHeartbeat: Error from deviceService.Heartbeat")
+		respondWithServiceError(c, err, "Failed to record heartbeat.")
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// GetDevices lists the registered devices by name, filtered by ?type= and ?status=online|offline.
+func (h *DeviceHandler) GetDevices(c *gin.Context) {
+	var filters models.DeviceFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid query parameters.", err.Error()))
+		return
+	}
+	devices, err := h.deviceService.GetDevices(filters)
+	if err != nil {
+		utils.LogError(err, "GetDevices: Error from deviceService.GetDevices")
+		respondWithServiceError(c, err, "Failed to fetch devices.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": devices})
+}
+
+// GetDevice returns a device with its settings and status.
+func (h *DeviceHandler) GetDevice(c *gin.Context) {
+	id, ok := parseDeviceID(c)
+	if !ok {
+		return
+	}
+	device, err := h.deviceService.GetDevice(id)
+	if err != nil {
+		utils.LogError(err, "GetDevice: Error from deviceService.GetDevice for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch device.")
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// UpdateDevice renames a device and replaces its settings; the device picks them up with its
+// next heartbeat.
+func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
+	id, ok := parseDeviceID(c)
+	if !ok {
+		return
+	}
+	var req services.UpdateDeviceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	device, err := h.deviceService.UpdateDevice(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateDevice: Error from deviceService.UpdateDevice for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update device.")
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// DeleteDevice removes a device from the registry.
+func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
+	id, ok := parseDeviceID(c)
+	if !ok {
+		return
+	}
+	if err := h.deviceService.DeleteDevice(id); err != nil {
+		utils.LogError(err, "DeleteDevice: Error from deviceService.DeleteDevice for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete device.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Device deleted"})
+}
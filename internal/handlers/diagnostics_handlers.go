@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosticsHandler holds the diagnostics service.
+type DiagnosticsHandler struct {
+	diagnosticsService services.DiagnosticsService
+}
+
+// NewDiagnosticsHandler creates a new DiagnosticsHandler.
+func NewDiagnosticsHandler(ds services.DiagnosticsService) *DiagnosticsHandler {
+	return &DiagnosticsHandler{diagnosticsService: ds}
+}
+
+// ExplainQuery runs EXPLAIN ANALYZE on a canned listing or report query and returns its plan.
+func (h *DiagnosticsHandler) ExplainQuery(c *gin.Context) {
+	var req services.ExplainQueryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	plan, err := h.diagnosticsService.ExplainQuery(req)
+	if err != nil {
+		utils.LogError(err, "ExplainQuery: Error from diagnosticsService.ExplainQuery for "+req.Query)
+		respondWithServiceError(c, err, "Failed to explain query.")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// GetMissingIndexes lists the indexes the listing and report queries expect but the database lacks.
+func (h *DiagnosticsHandler) GetMissingIndexes(c *gin.Context) {
+	missing, err := h.diagnosticsService.MissingIndexes()
+	if err != nil {
+		utils.LogError(err, "GetMissingIndexes: Error from diagnosticsService.MissingIndexes")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to check indexes.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"expected": services.ExpectedIndexes, "missing": missing})
+}
+
+// Ready reports whether the instance can serve requests: 200 when ready, 503 when not.
+// Missing indexes don't make it unready; they are listed in warnings.
+func (h *DiagnosticsHandler) Ready(c *gin.Context) {
+	readiness := h.diagnosticsService.Readiness()
+	status := http.StatusOK
+	if readiness.Status != models.ReadinessReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, readiness)
+}
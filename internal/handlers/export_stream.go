@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportStatusTrailer is the HTTP trailer a streamed export ends with: "complete", or
+// "failed" when an error cut the export short after the download had started.
+const ExportStatusTrailer = "X-Export-Status"
+
+// exportStream is a file download written while its data is read. It has no
+// Content-Length, so it is sent with chunked transfer encoding.
+type exportStream struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+// start sends the response headers, once, before the first byte of the file.
+func (s *exportStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.c.Header("Content-Disposition", `attachment; filename="`+s.filename+`"`)
+	s.c.Header("Content-Type", s.contentType)
+	s.c.Header("Trailer", ExportStatusTrailer)
+	s.c.Status(http.StatusOK)
+}
+
+// end sets the status trailer of a started download. It returns false if the download has
+// not started, so the caller can still respond to err with an error status.
+func (s *exportStream) end(err error) bool {
+	if !s.started {
+		return false
+	}
+	if err != nil {
+		s.c.Writer.Header().Set(ExportStatusTrailer, "failed")
+		s.c.Abort()
+		return true
+	}
+	s.c.Writer.Header().Set(ExportStatusTrailer, "complete")
+	return true
+}
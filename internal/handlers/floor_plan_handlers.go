@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FloorPlanHandler holds the floor plan service.
+type FloorPlanHandler struct {
+	floorPlanService services.FloorPlanService
+}
+
+// NewFloorPlanHandler creates a new FloorPlanHandler.
+func NewFloorPlanHandler(fps services.FloorPlanService) *FloorPlanHandler {
+	return &FloorPlanHandler{floorPlanService: fps}
+}
+
+// GetFloorPlan returns the room view: the canvas and every game table with its position and
+// live status, booked_soon when a booking starts within ?soon_minutes= (default 60).
+func (h *FloorPlanHandler) GetFloorPlan(c *gin.Context) {
+	soonMinutes, err := strconv.Atoi(c.DefaultQuery("soon_minutes", strconv.Itoa(services.DefaultFloorPlanSoonMinutes)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid soon_minutes value.", err.Error()))
+		return
+	}
+	plan, err := h.floorPlanService.GetFloorPlan(soonMinutes)
+	if err != nil {
+		utils.LogError(err, "GetFloorPlan: Error from floorPlanService.GetFloorPlan")
+		respondWithServiceError(c, err, "Failed to fetch floor plan.")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// SaveFloorPlan replaces the canvas and the positions of all tables.
+func (h *FloorPlanHandler) SaveFloorPlan(c *gin.Context) {
+	userID, ok := currentUserID(c, "SaveFloorPlan")
+	if !ok {
+		return
+	}
+	var req services.FloorPlanRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	plan, err := h.floorPlanService.SaveFloorPlan(req, userID)
+	if err != nil {
+		utils.LogError(err, "SaveFloorPlan: Error from floorPlanService.SaveFloorPlan")
+		respondWithServiceError(c, err, "Failed to save floor plan.")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// SaveFloorPlanTable places a table on the floor plan or moves it.
+func (h *FloorPlanHandler) SaveFloorPlanTable(c *gin.Context) {
+	tableID, ok := parseIncidentParam(c, "table_id", "table")
+	if !ok {
+		return
+	}
+	var table models.FloorPlanTable
+	if !bindJSON(c, &table) {
+		return
+	}
+	table.TableID = tableID
+	plan, err := h.floorPlanService.SaveTable(table)
+	if err != nil {
+		utils.LogError(err, "SaveFloorPlanTable: Error from floorPlanService.SaveTable for table "+c.Param("table_id"))
+		respondWithServiceError(c, err, "Failed to place table.")
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// RemoveFloorPlanTable takes a table off the floor plan.
+func (h *FloorPlanHandler) RemoveFloorPlanTable(c *gin.Context) {
+	tableID, ok := parseIncidentParam(c, "table_id", "table")
+	if !ok {
+		return
+	}
+	if err := h.floorPlanService.RemoveTable(tableID); err != nil {
+		utils.LogError(err, "RemoveFloorPlanTable: Error from floorPlanService.RemoveTable for table "+c.Param("table_id"))
+		respondWithServiceError(c, err, "Failed to remove table from the floor plan.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Table removed from the floor plan"})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GameTableHandler holds the game table service.
+type GameTableHandler struct {
+	gameTableService services.GameTableService
+}
+
+// NewGameTableHandler creates a new GameTableHandler.
+func NewGameTableHandler(gts services.GameTableService) *GameTableHandler {
+	return &GameTableHandler{gameTableService: gts}
+}
+
+// CreateGameTable handles creation of a new game table
+func (h *GameTableHandler) CreateGameTable(c *gin.Context) {
+	var table models.GameTable
+	if !bindJSON(c, &table) {
+		return
+	}
+	created, err := h.gameTableService.CreateTable(table)
+	if err != nil {
+		utils.LogError(err, "CreateGameTable: Error from gameTableService.CreateTable")
+		respondWithServiceError(c, err, "Failed to create game table.")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetGameTables handles fetching all game tables, optionally of a status
+func (h *GameTableHandler) GetGameTables(c *gin.Context) {
+	tables, err := h.gameTableService.GetTables(c.Query("status"))
+	if err != nil {
+		utils.LogError(err, "GetGameTables: Error from gameTableService.GetTables")
+		respondWithServiceError(c, err, "Failed to fetch game tables.")
+		return
+	}
+	c.JSON(http.StatusOK, tables)
+}
+
+// GetGameTableByID handles fetching a single game table by ID
+func (h *GameTableHandler) GetGameTableByID(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "table")
+	if !ok {
+		return
+	}
+	table, err := h.gameTableService.GetTable(id)
+	if err != nil {
+		utils.LogError(err, "GetGameTableByID: Error from gameTableService.GetTable for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch game table.")
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+// GetGameTableOverview returns the station popup of the POS in one call: the table, its running
+// session billed so far, its bookings of the day still to come and its open orders.
+func (h *GameTableHandler) GetGameTableOverview(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "table")
+	if !ok {
+		return
+	}
+	overview, err := h.gameTableService.GetOverview(id)
+	if err != nil {
+		utils.LogError(err, "GetGameTableOverview: Error from gameTableService.GetOverview for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch table overview.")
+		return
+	}
+	c.JSON(http.StatusOK, overview)
+}
+
+// UpdateGameTable handles updating an existing game table; a changed status is validated
+// as by SetGameTableStatus
+func (h *GameTableHandler) UpdateGameTable(c *gin.Context) {
+	userID, ok := currentUserID(c, "UpdateGameTable")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "table")
+	if !ok {
+		return
+	}
+	var table models.GameTable
+	if !bindJSON(c, &table) {
+		return
+	}
+	updated, err := h.gameTableService.UpdateTable(id, table, userID)
+	if err != nil {
+		utils.LogError(err, "UpdateGameTable: Error from gameTableService.UpdateTable for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update game table.")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// SetGameTableStatus sets a game table available, cleaning or under maintenance.
+func (h *GameTableHandler) SetGameTableStatus(c *gin.Context) {
+	userID, ok := currentUserID(c, "SetGameTableStatus")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "table")
+	if !ok {
+		return
+	}
+	var req services.SetTableStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	table, err := h.gameTableService.SetStatus(id, req.Status, userID)
+	if err != nil {
+		utils.LogError(err, "SetGameTableStatus: Error from gameTableService.SetStatus for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to set game table status.")
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+// DeleteGameTable handles deleting a game table without active bookings
+func (h *GameTableHandler) DeleteGameTable(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "table")
+	if !ok {
+		return
+	}
+	if err := h.gameTableService.DeleteTable(id); err != nil {
+		utils.LogError(err, "DeleteGameTable: Error from gameTableService.DeleteTable for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete game table.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Game table deleted successfully"})
+}
@@ -12,13 +12,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-const HookahItemType = "HOOKAH"
+const HookahItemType = models.ItemTypeHookah
 
 // CreateHookahItem handles creation of a new hookah item (a PricelistItem with type 'HOOKAH')
 func CreateHookahItem(c *gin.Context) {
 	var item models.PricelistItem
-	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+	if !bindJSON(c, &item) {
 		return
 	}
 
@@ -28,10 +27,10 @@ func CreateHookahItem(c *gin.Context) {
 	query := `INSERT INTO pricelist_items 
 	          (category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
-	          RETURNING id, created_at, updated_at`
+	          RETURNING id, created_at, updated_at, version`
 
-	item.CreatedAt = time.Now()
-	item.UpdatedAt = time.Now()
+	item.CreatedAt = time.Now().UTC()
+	item.UpdatedAt = time.Now().UTC()
 	if item.IsAvailable == false {
         // Default from DB is true, but if payload sets it, respect it.
     } else {
@@ -41,7 +40,7 @@ func CreateHookahItem(c *gin.Context) {
 	err := db.QueryRow(query, 
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU, item.IsAvailable, 
 		item.ItemType, item.CurrentStock, item.LowStockThreshold, item.CreatedAt, item.UpdatedAt,
-	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Version)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create hookah item: " + err.Error()})
@@ -56,10 +55,10 @@ func GetHookahItems(c *gin.Context) {
 	
 	queryStr := `SELECT pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	                     pi.is_available, pi.item_type, pi.current_stock, pi.low_stock_threshold, 
-	                     pi.created_at, pi.updated_at, pc.name as category_name
+	                     pi.created_at, pi.updated_at, pi.version, pc.name as category_name
 	              FROM pricelist_items pi
 	              JOIN pricelist_categories pc ON pi.category_id = pc.id
-	              WHERE pi.item_type = $1
+	              WHERE pi.item_type = $1 AND pi.deleted_at IS NULL
 	              ORDER BY pi.name`
 
 	rows, err := db.Query(queryStr, HookahItemType)
@@ -76,7 +75,7 @@ func GetHookahItems(c *gin.Context) {
 		if err := rows.Scan(
 			&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 			&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-			&item.CreatedAt, &item.UpdatedAt, &categoryName,
+			&item.CreatedAt, &item.UpdatedAt, &item.Version, &categoryName,
 		); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan hookah item: " + err.Error()})
 			return
@@ -101,14 +100,14 @@ func GetHookahItemByID(c *gin.Context) {
 	var categoryName string
 	query := `SELECT pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	                 pi.is_available, pi.item_type, pi.current_stock, pi.low_stock_threshold, 
-	                 pi.created_at, pi.updated_at, pc.name as category_name
+	                 pi.created_at, pi.updated_at, pi.version, pc.name as category_name
 	          FROM pricelist_items pi
 	          JOIN pricelist_categories pc ON pi.category_id = pc.id
-	          WHERE pi.id = $1 AND pi.item_type = $2`
+	          WHERE pi.id = $1 AND pi.item_type = $2 AND pi.deleted_at IS NULL`
 	err = db.QueryRow(query, id, HookahItemType).Scan(
 		&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 		&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-		&item.CreatedAt, &item.UpdatedAt, &categoryName,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version, &categoryName,
 	)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Hookah item not found"})
@@ -131,8 +130,7 @@ func UpdateHookahItem(c *gin.Context) {
 	}
 
 	var item models.PricelistItem
-	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+	if !bindJSON(c, &item) {
 		return
 	}
 
@@ -141,7 +139,7 @@ func UpdateHookahItem(c *gin.Context) {
 	db := database.GetDB()
 	// Check if the item to update is indeed a HOOKAH item
 	var currentItemType string
-	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1", id).Scan(&currentItemType); err == sql.ErrNoRows {
+	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1 AND deleted_at IS NULL", id).Scan(&currentItemType); err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Hookah item not found to update"})
 		return
 	} else if err != nil {
@@ -155,23 +153,24 @@ func UpdateHookahItem(c *gin.Context) {
 
 	query := `UPDATE pricelist_items SET 
 	          category_id = $1, name = $2, description = $3, price = $4, sku = $5, 
-	          is_available = $6, item_type = $7, current_stock = $8, low_stock_threshold = $9, updated_at = $10
-	          WHERE id = $11 
-	          RETURNING id, category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at`
+	          is_available = $6, item_type = $7, current_stock = $8, low_stock_threshold = $9, updated_at = $10,
+	          version = version + 1
+	          WHERE id = $11 AND ($12 = 0 OR version = $12)
+	          RETURNING id, category_id, name, description, price, sku, is_available, item_type, current_stock, low_stock_threshold, created_at, updated_at, version`
 
-	item.UpdatedAt = time.Now()
+	item.UpdatedAt = time.Now().UTC()
 
 	err = db.QueryRow(query, 
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU, 
-		item.IsAvailable, item.ItemType, item.CurrentStock, item.LowStockThreshold, item.UpdatedAt, id,
+		item.IsAvailable, item.ItemType, item.CurrentStock, item.LowStockThreshold, item.UpdatedAt, id, item.Version,
 	).Scan(
 		&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU, 
 		&item.IsAvailable, &item.ItemType, &item.CurrentStock, &item.LowStockThreshold, 
-		&item.CreatedAt, &item.UpdatedAt,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version,
 	)
 
-	if err == sql.ErrNoRows { // Should be caught by pre-check
-		c.JSON(http.StatusNotFound, gin.H{"error": "Hookah item not found to update (race condition?)"})
+	if err == sql.ErrNoRows { // The item exists (pre-check), so the version sent by the client is stale
+		respondWithCurrentItem(c, db, id)
 		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hookah item: " + err.Error()})
@@ -190,11 +189,16 @@ func DeleteHookahItem(c *gin.Context) {
 		return
 	}
 
+	userID, ok := currentUserID(c, "DeleteHookahItem")
+	if !ok {
+		return
+	}
+
 	db := database.GetDB()
 
 	// Check if the item to delete is indeed a HOOKAH item
 	var currentItemType string
-	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1", id).Scan(&currentItemType); err == sql.ErrNoRows {
+	if err := db.QueryRow("SELECT item_type FROM pricelist_items WHERE id = $1 AND deleted_at IS NULL", id).Scan(&currentItemType); err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Hookah item not found to delete"})
 		return
 	} else if err != nil {
@@ -206,7 +210,9 @@ func DeleteHookahItem(c *gin.Context) {
 		return
 	}
 
-	result, err := db.Exec("DELETE FROM pricelist_items WHERE id = $1 AND item_type = $2", id, HookahItemType)
+	// Soft deleted, so an Admin can restore it (POST /pricelist-items/:id/restore)
+	result, err := db.Exec(`UPDATE pricelist_items SET deleted_at = $3, deleted_by = $4, updated_at = $3, version = version + 1
+	                        WHERE id = $1 AND item_type = $2 AND deleted_at IS NULL`, id, HookahItemType, time.Now().UTC(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete hookah item: " + err.Error()})
 		return
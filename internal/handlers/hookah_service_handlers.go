@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HookahServiceHandler holds the hookah service and the WebSocket feed of coal change reminders.
+type HookahServiceHandler struct {
+	hookahService services.HookahService
+	alerts        http.Handler
+}
+
+// NewHookahServiceHandler creates a new HookahServiceHandler. alerts serves the WebSocket
+// connections that receive the hookah events, see realtime.Hub.
+func NewHookahServiceHandler(hs services.HookahService, alerts http.Handler) *HookahServiceHandler {
+	return &HookahServiceHandler{hookahService: hs, alerts: alerts}
+}
+
+// parseOrderItemID parses the :id parameter, responding with an error if it is invalid.
+func parseOrderItemID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order item ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// GetActiveHookahs lists the hookahs being served with their next coal change.
+func (h *HookahServiceHandler) GetActiveHookahs(c *gin.Context) {
+	hookahs, err := h.hookahService.GetActiveHookahs()
+	if err != nil {
+		utils.LogError(err, "GetActiveHookahs: Error from hookahService.GetActiveHookahs")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch hookahs.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hookahs})
+}
+
+// RecordCoalChange records a coal change of the hookah of an order item.
+func (h *HookahServiceHandler) RecordCoalChange(c *gin.Context) {
+	userID, ok := currentUserID(c, "RecordCoalChange")
+	if !ok {
+		return
+	}
+	id, ok := parseOrderItemID(c)
+	if !ok {
+		return
+	}
+	// The body is optional: without it the coals default to the hookah_service setting
+	var req services.RecordCoalChangeRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	change, err := h.hookahService.RecordCoalChange(id, req, userID)
+	if err != nil {
+		utils.LogError(err, "RecordCoalChange: Error from hookahService.RecordCoalChange for order item "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to record coal change.")
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// GetCoalChanges lists the coal changes of the hookah of an order item.
+func (h *HookahServiceHandler) GetCoalChanges(c *gin.Context) {
+	id, ok := parseOrderItemID(c)
+	if !ok {
+		return
+	}
+	changes, err := h.hookahService.GetCoalChanges(id)
+	if err != nil {
+		utils.LogError(err, "GetCoalChanges: Error from hookahService.GetCoalChanges for order item "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch coal changes.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+// EndHookah records that the hookah of an order item was taken away.
+func (h *HookahServiceHandler) EndHookah(c *gin.Context) {
+	id, ok := parseOrderItemID(c)
+	if !ok {
+		return
+	}
+	hookah, err := h.hookahService.EndHookah(id)
+	if err != nil {
+		utils.LogError(err, "EndHookah: Error from hookahService.EndHookah for order item "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to end hookah.")
+		return
+	}
+	c.JSON(http.StatusOK, hookah)
+}
+
+// HookahAlerts upgrades to a WebSocket that receives the hookah events as they happen:
+// coal changes that are due, coal changes and hookahs taken away, as JSON domain events.
+// The hub authenticates the upgrade itself.
+func (h *HookahServiceHandler) HookahAlerts(c *gin.Context) {
+	h.alerts.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IncidentHandler holds the incident service.
+type IncidentHandler struct {
+	incidentService services.IncidentService
+}
+
+// NewIncidentHandler creates a new IncidentHandler.
+func NewIncidentHandler(is services.IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidentService: is}
+}
+
+// parseIncidentParam parses an ID parameter, responding with an error if it is invalid.
+func parseIncidentParam(c *gin.Context, param, what string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+what+" ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// ReportIncident records an incident for an Admin's review.
+func (h *IncidentHandler) ReportIncident(c *gin.Context) {
+	userID, ok := currentUserID(c, "ReportIncident")
+	if !ok {
+		return
+	}
+	var req services.IncidentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	incident, err := h.incidentService.ReportIncident(req, userID)
+	if err != nil {
+		utils.LogError(err, "ReportIncident: Error from incidentService.ReportIncident")
+		respondWithServiceError(c, err, "Failed to report incident.")
+		return
+	}
+	c.JSON(http.StatusCreated, incident)
+}
+
+// GetIncidents lists the incidents of this branch, most recent first, optionally only those with
+// a status, incident_type or severity, of a staff_id or client_id, or that occurred between from
+// and to (YYYY-MM-DD, club time).
+func (h *IncidentHandler) GetIncidents(c *gin.Context) {
+	filters := models.IncidentFilters{Status: c.Query("status"), IncidentType: c.Query("incident_type"), Severity: c.Query("severity")}
+	for param, target := range map[string]**int64{
+		"staff_id":  &filters.StaffID,
+		"client_id": &filters.ClientID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+param+" value.", err.Error()))
+				return
+			}
+			*target = &id
+		}
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	incidents, err := h.incidentService.GetIncidents(filters)
+	if err != nil {
+		utils.LogError(err, "GetIncidents: Error from incidentService.GetIncidents")
+		respondWithServiceError(c, err, "Failed to fetch incidents.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": incidents})
+}
+
+// GetIncident returns an incident with its photos.
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	incident, err := h.incidentService.GetIncident(id)
+	if err != nil {
+		utils.LogError(err, "GetIncident: Error from incidentService.GetIncident for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch incident.")
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// UpdateIncident changes the details of an incident that has not been reviewed yet.
+func (h *IncidentHandler) UpdateIncident(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	var req services.IncidentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	incident, err := h.incidentService.UpdateIncident(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateIncident: Error from incidentService.UpdateIncident for ID "+c.Param("id"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.incidentService.GetIncident(id)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to update incident.")
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// ReviewIncident confirms or dismisses an incident.
+func (h *IncidentHandler) ReviewIncident(c *gin.Context) {
+	userID, ok := currentUserID(c, "ReviewIncident")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	var req services.ReviewIncidentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	incident, err := h.incidentService.ReviewIncident(id, req, userID)
+	if err != nil {
+		utils.LogError(err, "ReviewIncident: Error from incidentService.ReviewIncident for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to review incident.")
+		return
+	}
+	c.JSON(http.StatusOK, incident)
+}
+
+// DeleteIncident deletes an incident reported by mistake, with its photos.
+func (h *IncidentHandler) DeleteIncident(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	if err := h.incidentService.DeleteIncident(id); err != nil {
+		utils.LogError(err, "DeleteIncident: Error from incidentService.DeleteIncident for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete incident.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Incident deleted successfully"})
+}
+
+// AddPhoto attaches the "photo" file of a multipart form to an incident.
+func (h *IncidentHandler) AddPhoto(c *gin.Context) {
+	userID, ok := currentUserID(c, "AddPhoto")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	data, ok := readPhotoUpload(c, "AddPhoto")
+	if !ok {
+		return
+	}
+	photo, err := h.incidentService.AddPhoto(id, data, userID)
+	if err != nil {
+		utils.LogError(err, "AddPhoto: Error from incidentService.AddPhoto for incident "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to add incident photo.")
+		return
+	}
+	c.JSON(http.StatusCreated, photo)
+}
+
+// GetPhoto serves a photo of an incident.
+func (h *IncidentHandler) GetPhoto(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	photoID, ok := parseIncidentParam(c, "photo_id", "photo")
+	if !ok {
+		return
+	}
+	photo, err := h.incidentService.GetPhoto(id, photoID)
+	if err != nil {
+		utils.LogError(err, "GetPhoto: Error from incidentService.GetPhoto for incident "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch incident photo.")
+		return
+	}
+	c.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+// DeletePhoto removes a photo from an incident that has not been reviewed yet.
+func (h *IncidentHandler) DeletePhoto(c *gin.Context) {
+	id, ok := parseIncidentParam(c, "id", "incident")
+	if !ok {
+		return
+	}
+	photoID, ok := parseIncidentParam(c, "photo_id", "photo")
+	if !ok {
+		return
+	}
+	if err := h.incidentService.DeletePhoto(id, photoID); err != nil {
+		utils.LogError(err, "DeletePhoto: Error from incidentService.DeletePhoto for incident "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete incident photo.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Incident photo deleted successfully"})
+}
+
+// GetPayroll returns the pay of every staff member for ?month=YYYY-MM (default: this month),
+// less the penalties of their confirmed incidents.
+func (h *IncidentHandler) GetPayroll(c *gin.Context) {
+	payroll, err := h.incidentService.GetPayroll(c.Query("month"))
+	if err != nil {
+		utils.LogError(err, "GetPayroll: Error from incidentService.GetPayroll")
+		respondWithServiceError(c, err, "Failed to fetch payroll.")
+		return
+	}
+	c.JSON(http.StatusOK, payroll)
+}
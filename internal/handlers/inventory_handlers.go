@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils"
 
@@ -26,22 +27,14 @@ func NewPricelistHandler(ps services.PricelistService) *PricelistHandler {
 // CreatePricelistCategory handles the creation of a new pricelist category.
 func (h *PricelistHandler) CreatePricelistCategory(c *gin.Context) {
 	var req services.CreatePricelistCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreatePricelistCategory: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	category, err := h.pricelistService.CreateCategory(req)
 	if err != nil {
 		utils.LogError(err, "CreatePricelistCategory: Error from pricelistService.CreateCategory")
-		if errors.Is(err, services.ErrCategoryNameExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Category name already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create category.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to create category.")
 		return
 	}
 	c.JSON(http.StatusCreated, category)
@@ -67,12 +60,7 @@ func (h *PricelistHandler) GetPricelistCategories(c *gin.Context) {
 	    categories = []models.PricelistCategory{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  categories,
-		"total": totalCount,
-		"page":  page,
-		"page_size": pageSize,
-	})
+	respondList(c, categories, totalCount, page, pageSize)
 }
 
 // GetPricelistCategoryByID handles fetching a single pricelist category by ID.
@@ -87,11 +75,7 @@ func (h *PricelistHandler) GetPricelistCategoryByID(c *gin.Context) {
 	category, err := h.pricelistService.GetCategoryByID(categoryID)
 	if err != nil {
 		utils.LogError(err, "GetPricelistCategoryByID: Error from pricelistService.GetCategoryByID for ID "+idStr)
-		if errors.Is(err, services.ErrCategoryNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Category not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch category.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch category.")
 		return
 	}
 	c.JSON(http.StatusOK, category)
@@ -107,24 +91,14 @@ func (h *PricelistHandler) UpdatePricelistCategory(c *gin.Context) {
 	}
 
 	var req services.UpdatePricelistCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdatePricelistCategory: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	category, err := h.pricelistService.UpdateCategory(categoryID, req)
 	if err != nil {
 		utils.LogError(err, "UpdatePricelistCategory: Error from pricelistService.UpdateCategory for ID "+idStr)
-		if errors.Is(err, services.ErrCategoryNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Category not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrCategoryNameExists) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Category name already exists.", err.Error()))
-		} else if errors.Is(err, services.ErrValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update category.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to update category.")
 		return
 	}
 	c.JSON(http.StatusOK, category)
@@ -142,13 +116,7 @@ func (h *PricelistHandler) DeletePricelistCategory(c *gin.Context) {
 	err = h.pricelistService.DeleteCategory(categoryID)
 	if err != nil {
 		utils.LogError(err, "DeletePricelistCategory: Error from pricelistService.DeleteCategory for ID "+idStr)
-		if errors.Is(err, services.ErrCategoryNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Category not found to delete.", err.Error()))
-		} else if errors.Is(err, services.ErrPricelistForeignKey) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Cannot delete category: it is currently in use.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete category.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete category.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Pricelist category deleted successfully"})
@@ -159,24 +127,14 @@ func (h *PricelistHandler) DeletePricelistCategory(c *gin.Context) {
 // CreatePricelistItem handles the creation of a new pricelist item.
 func (h *PricelistHandler) CreatePricelistItem(c *gin.Context) {
 	var req services.CreatePricelistItemRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreatePricelistItem: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	item, err := h.pricelistService.CreateItem(req)
 	if err != nil {
 		utils.LogError(err, "CreatePricelistItem: Error from pricelistService.CreateItem")
-		if errors.Is(err, services.ErrItemNameConflict) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Item name or SKU already exists or conflicts.", err.Error()))
-		} else if errors.Is(err, services.ErrCategoryNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Invalid category ID provided.", err.Error()))
-		} else if errors.Is(err, services.ErrValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create item.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to create item.")
 		return
 	}
 	c.JSON(http.StatusCreated, item)
@@ -218,12 +176,7 @@ func (h *PricelistHandler) GetPricelistItems(c *gin.Context) {
 	    items = []models.PricelistItem{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  items,
-		"total": totalCount,
-		"page": page,
-		"page_size": pageSize,
-	})
+	respondList(c, items, totalCount, page, pageSize)
 }
 
 // GetPricelistItemByID handles fetching a single pricelist item by ID.
@@ -238,17 +191,13 @@ func (h *PricelistHandler) GetPricelistItemByID(c *gin.Context) {
 	item, err := h.pricelistService.GetItemByID(itemID)
 	if err != nil {
 		utils.LogError(err, "GetPricelistItemByID: Error from pricelistService.GetItemByID for ID "+idStr)
-		if errors.Is(err, services.ErrItemNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Item not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch item.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch item.")
 		return
 	}
 	c.JSON(http.StatusOK, item)
 }
 
-// UpdatePricelistItem handles updating a pricelist item.
+// UpdatePricelistItem handles updating a pricelist item (PUT), or patching it with a JSON Merge Patch (PATCH).
 func (h *PricelistHandler) UpdatePricelistItem(c *gin.Context) {
 	idStr := c.Param("id")
 	itemID, err := strconv.ParseInt(idStr, 10, 64)
@@ -258,32 +207,33 @@ func (h *PricelistHandler) UpdatePricelistItem(c *gin.Context) {
 	}
 
 	var req services.UpdatePricelistItemRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdatePricelistItem: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindUpdate(c, &req, services.PricelistItemClearableFields, &req.Clear) {
 		return
 	}
 
+	req.CallerRole = c.GetString("userRole")
 	item, err := h.pricelistService.UpdateItem(itemID, req)
 	if err != nil {
 		utils.LogError(err, "UpdatePricelistItem: Error from pricelistService.UpdateItem for ID "+idStr)
-		if errors.Is(err, services.ErrItemNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Item not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrItemNameConflict) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Item name or SKU already exists or conflicts.", err.Error()))
-		} else if errors.Is(err, services.ErrCategoryNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Invalid category ID provided.", err.Error()))
-		} else if errors.Is(err, services.ErrValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
+		var fieldErr *services.FieldPermissionError
+		if errors.As(err, &fieldErr) {
+			utils.RespondWithForbiddenFields(c, fieldErr.Fields)
+		} else if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.pricelistService.GetItemByID(itemID)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
 		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update item.", "Internal error"))
+			respondWithServiceError(c, err, "Failed to update item.")
 		}
 		return
 	}
 	c.JSON(http.StatusOK, item)
 }
 
-// DeletePricelistItem handles deleting a pricelist item.
+// DeletePricelistItem handles soft deleting a pricelist item, recorded as by the authenticated user.
 func (h *PricelistHandler) DeletePricelistItem(c *gin.Context) {
 	idStr := c.Param("id")
 	itemID, err := strconv.ParseInt(idStr, 10, 64)
@@ -291,22 +241,42 @@ func (h *PricelistHandler) DeletePricelistItem(c *gin.Context) {
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid item ID format.", err.Error()))
 		return
 	}
+	userID, ok := currentUserID(c, "DeletePricelistItem")
+	if !ok {
+		return
+	}
 
-	err = h.pricelistService.DeleteItem(itemID)
+	err = h.pricelistService.DeleteItem(itemID, userID)
 	if err != nil {
 		utils.LogError(err, "DeletePricelistItem: Error from pricelistService.DeleteItem for ID "+idStr)
-		if errors.Is(err, services.ErrItemNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Item not found to delete.", err.Error()))
-		} else if errors.Is(err, services.ErrPricelistForeignKey) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Cannot delete item: it is currently in use (e.g., by an order).", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete item.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete item.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Pricelist item deleted successfully"})
 }
 
+// RestorePricelistItem restores a soft deleted pricelist item; 409 if another item has its SKU since.
+func (h *PricelistHandler) RestorePricelistItem(c *gin.Context) {
+	idStr := c.Param("id")
+	itemID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid item ID format.", err.Error()))
+		return
+	}
+	userID, ok := currentUserID(c, "RestorePricelistItem")
+	if !ok {
+		return
+	}
+
+	item, err := h.pricelistService.RestoreItem(itemID, userID)
+	if err != nil {
+		utils.LogError(err, "RestorePricelistItem: Error from pricelistService.RestoreItem for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to restore item.")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
 // Remove or comment out old standalone functions if they existed:
 // func CreatePricelistCategory(c *gin.Context) { ... }
 // func GetPricelistCategories(c *gin.Context) { ... }
@@ -314,3 +284,20 @@ func (h *PricelistHandler) DeletePricelistItem(c *gin.Context) {
 // func CreatePricelistItem(c *gin.Context) { ... }
 // func GetPricelistItems(c *gin.Context) { ... }
 // ... etc. ...
+
+// BulkSetItemAvailability makes several pricelist items available or unavailable at once
+// and reports the outcome per item.
+func (h *PricelistHandler) BulkSetItemAvailability(c *gin.Context) {
+	var req services.BulkSetItemAvailabilityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.pricelistService.BulkSetItemAvailability(req)
+	if err != nil {
+		utils.LogError(err, "BulkSetItemAvailability: Error from pricelistService.BulkSetItemAvailability")
+		respondWithServiceError(c, err, "Failed to update item availability.")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils"
 
@@ -24,9 +26,7 @@ func NewInventoryMovementHandler(ims services.InventoryMovementService) *Invento
 // CreateInventoryMovement handles the creation of a new inventory movement.
 func (h *InventoryMovementHandler) CreateInventoryMovement(c *gin.Context) {
 	var req services.CreateInventoryMovementRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateInventoryMovement: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -47,25 +47,14 @@ func (h *InventoryMovementHandler) CreateInventoryMovement(c *gin.Context) {
 	movement, err := h.inventoryMvService.CreateMovement(req, authStaffID)
 	if err != nil {
 		utils.LogError(err, "CreateInventoryMovement: Error from inventoryMvService.CreateMovement")
-		if errors.Is(err, services.ErrInvalidMovementType) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid movement type provided.", err.Error()))
-		} else if errors.Is(err, services.ErrMovementItemNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Pricelist item for movement not found.", err.Error()))
-		} else if errors.Is(err, services.ErrMovementItemNotTracked) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Pricelist item does not track stock.", err.Error()))
-		} else if errors.Is(err, services.ErrValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrMovementCreationFailed) || errors.Is(err, services.ErrStockUpdateFailed) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to process inventory movement due to an internal issue.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create inventory movement.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to create inventory movement.")
 		return
 	}
 	c.JSON(http.StatusCreated, movement)
 }
 
-// GetInventoryMovements handles fetching all inventory movements with filters and pagination.
+// GetInventoryMovements handles fetching all inventory movements with filters and pagination. With a
+// cursor query parameter (empty for the first page) it uses cursor pagination and responds with {"data", "next_cursor"}.
 func (h *InventoryMovementHandler) GetInventoryMovements(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
@@ -97,10 +86,31 @@ func (h *InventoryMovementHandler) GetInventoryMovements(c *gin.Context) {
 
 	var movementType *string
 	if movementTypeStr := c.Query("movement_type"); movementTypeStr != "" {
+		if !models.IsValidMovementType(movementTypeStr) {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid movement_type.",
+				"movement_type must be one of "+strings.Join(models.MovementTypes, ", ")))
+			return
+		}
 		movementType = &movementTypeStr
 	}
 
-	movements, totalCount, err := h.inventoryMvService.GetMovements(itemID, staffID, movementType, page, pageSize)
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		page, err := h.inventoryMvService.GetMovementsByCursor(itemID, staffID, movementType, timeRange.From, timeRange.To, cursor, pageSize)
+		if err != nil {
+			utils.LogError(err, "GetInventoryMovements: Error from inventoryMvService.GetMovementsByCursor")
+			respondWithServiceError(c, err, "Failed to fetch inventory movements.")
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	movements, totalCount, err := h.inventoryMvService.GetMovements(itemID, staffID, movementType, timeRange.From, timeRange.To, page, pageSize)
 	if err != nil {
 		utils.LogError(err, "GetInventoryMovements: Error from inventoryMvService.GetMovements")
 		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch inventory movements.", "Internal error"))
@@ -111,12 +121,7 @@ func (h *InventoryMovementHandler) GetInventoryMovements(c *gin.Context) {
 	    movements = []models.InventoryMovement{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  movements,
-		"total": totalCount,
-		"page": page,
-		"page_size": pageSize,
-	})
+	respondList(c, movements, totalCount, page, pageSize)
 }
 
 // Remove or comment out old standalone functions if they existed:
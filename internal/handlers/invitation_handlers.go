@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvitationHandler holds the invitation service.
+type InvitationHandler struct {
+	invitationService services.InvitationService
+}
+
+// NewInvitationHandler creates a new InvitationHandler.
+func NewInvitationHandler(is services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: is}
+}
+
+// CreateInvitation invites a staff member and responds with the token to pass on to them,
+// which cannot be retrieved later.
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreateInvitation")
+	if !ok {
+		return
+	}
+	var req services.CreateInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreateInvitation: Error from invitationService.CreateInvitation")
+		respondWithServiceError(c, err, "Failed to create invitation.")
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitations lists the invitations, newest first, without their tokens.
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.GetInvitations()
+	if err != nil {
+		utils.LogError(err, "GetInvitations: Error from invitationService.GetInvitations")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch invitations.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// RevokeInvitation revokes an invitation that has not been accepted yet.
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid invitation ID format.", err.Error()))
+		return
+	}
+	if err := h.invitationService.RevokeInvitation(id); err != nil {
+		utils.LogError(err, "RevokeInvitation: Error from invitationService.RevokeInvitation for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to revoke invitation.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// AcceptInvitation redeems an invitation token: it creates the invitee's user with the chosen
+// username and password, who then logs in as usual.
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req services.AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(req)
+	if err != nil {
+		utils.LogError(err, "AcceptInvitation: Error from invitationService.AcceptInvitation")
+		respondWithServiceError(c, err, "Failed to accept invitation.")
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KioskHandler serves the check-in kiosk, which authenticates with an API key.
+type KioskHandler struct {
+	bookingService services.BookingService
+}
+
+// NewKioskHandler creates a new KioskHandler.
+func NewKioskHandler(bs services.BookingService) *KioskHandler {
+	return &KioskHandler{bookingService: bs}
+}
+
+// CheckInRequest is the body of POST /kiosk/check-in.
+type CheckInRequest struct {
+	Token string `json:"token" binding:"required"` // Read from the booking's QR code
+}
+
+// CheckIn checks a client in with the token of their booking's QR code and responds
+// with the session slip for the kiosk to print.
+func (h *KioskHandler) CheckIn(c *gin.Context) {
+	var req CheckInRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	slip, err := h.bookingService.CheckIn(req.Token)
+	if err != nil {
+		utils.LogError(err, "CheckIn: Error from bookingService.CheckIn")
+		respondWithServiceError(c, err, "Failed to check in.")
+		return
+	}
+	c.JSON(http.StatusOK, slip)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pagination describes the page returned by a v2 list endpoint.
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// respondList writes a page of results in the envelope of the requested API version:
+//   - v1: {"data": [...], "total": n, "page": p, "page_size": s}
+//   - v2: {"data": [...], "pagination": {"page": p, "page_size": s, "total": n, "total_pages": t}}
+func respondList(c *gin.Context, data interface{}, total, page, pageSize int) {
+	if middleware.APIVersionFromContext(c) == middleware.APIVersion1 {
+		c.JSON(http.StatusOK, gin.H{
+			"data":      data,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		})
+		return
+	}
+
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"pagination": Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	})
+}
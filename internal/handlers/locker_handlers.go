@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LockerHandler holds the locker service.
+type LockerHandler struct {
+	lockerService services.LockerService
+}
+
+// NewLockerHandler creates a new LockerHandler.
+func NewLockerHandler(ls services.LockerService) *LockerHandler {
+	return &LockerHandler{lockerService: ls}
+}
+
+// parseLockerID parses the :id parameter, responding with an error if it is invalid.
+func parseLockerID(c *gin.Context, what string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+what+" ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateLocker adds a locker of this branch.
+func (h *LockerHandler) CreateLocker(c *gin.Context) {
+	var req services.LockerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	locker, err := h.lockerService.CreateLocker(req)
+	if err != nil {
+		utils.LogError(err, "CreateLocker: Error from lockerService.CreateLocker")
+		respondWithServiceError(c, err, "Failed to create locker.")
+		return
+	}
+	c.JSON(http.StatusCreated, locker)
+}
+
+// GetLockers lists the lockers of this branch with who rents them.
+func (h *LockerHandler) GetLockers(c *gin.Context) {
+	lockers, err := h.lockerService.GetLockers()
+	if err != nil {
+		utils.LogError(err, "GetLockers: Error from lockerService.GetLockers")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch lockers.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": lockers})
+}
+
+// GetLocker returns a locker with its current rental.
+func (h *LockerHandler) GetLocker(c *gin.Context) {
+	id, ok := parseLockerID(c, "locker")
+	if !ok {
+		return
+	}
+	locker, err := h.lockerService.GetLocker(id)
+	if err != nil {
+		utils.LogError(err, "GetLocker: Error from lockerService.GetLocker for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch locker.")
+		return
+	}
+	c.JSON(http.StatusOK, locker)
+}
+
+// UpdateLocker changes the number, default fee and deposit or status of a locker.
+func (h *LockerHandler) UpdateLocker(c *gin.Context) {
+	id, ok := parseLockerID(c, "locker")
+	if !ok {
+		return
+	}
+	var req services.LockerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	locker, err := h.lockerService.UpdateLocker(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateLocker: Error from lockerService.UpdateLocker for ID "+c.Param("id"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.lockerService.GetLocker(id)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to update locker.")
+		return
+	}
+	c.JSON(http.StatusOK, locker)
+}
+
+// RentLocker assigns a locker to a visit.
+func (h *LockerHandler) RentLocker(c *gin.Context) {
+	userID, ok := currentUserID(c, "RentLocker")
+	if !ok {
+		return
+	}
+	id, ok := parseLockerID(c, "locker")
+	if !ok {
+		return
+	}
+	var req services.RentLockerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	rental, err := h.lockerService.RentLocker(id, req, userID)
+	if err != nil {
+		utils.LogError(err, "RentLocker: Error from lockerService.RentLocker for locker "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to rent locker.")
+		return
+	}
+	c.JSON(http.StatusCreated, rental)
+}
+
+// GetRentals lists locker rentals, newest first, optionally only the active ones (active=true)
+// or those of a table_session_id, booking_id or client_id.
+func (h *LockerHandler) GetRentals(c *gin.Context) {
+	var filters models.LockerRentalFilters
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid active value.", err.Error()))
+			return
+		}
+		filters.ActiveOnly = active
+	}
+	for param, target := range map[string]**int64{
+		"table_session_id": &filters.TableSessionID,
+		"booking_id":       &filters.BookingID,
+		"client_id":        &filters.ClientID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+param+" value.", err.Error()))
+				return
+			}
+			*target = &id
+		}
+	}
+	rentals, err := h.lockerService.GetRentals(filters)
+	if err != nil {
+		utils.LogError(err, "GetRentals: Error from lockerService.GetRentals")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch locker rentals.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": rentals})
+}
+
+// GetRental returns a locker rental.
+func (h *LockerHandler) GetRental(c *gin.Context) {
+	id, ok := parseLockerID(c, "rental")
+	if !ok {
+		return
+	}
+	rental, err := h.lockerService.GetRental(id)
+	if err != nil {
+		utils.LogError(err, "GetRental: Error from lockerService.GetRental for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch locker rental.")
+		return
+	}
+	c.JSON(http.StatusOK, rental)
+}
+
+// ReleaseRental frees the locker of a rental.
+func (h *LockerHandler) ReleaseRental(c *gin.Context) {
+	userID, ok := currentUserID(c, "ReleaseRental")
+	if !ok {
+		return
+	}
+	id, ok := parseLockerID(c, "rental")
+	if !ok {
+		return
+	}
+	// The body is optional: without it the deposit is returned
+	var req services.ReleaseLockerRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	rental, err := h.lockerService.ReleaseRental(id, req, userID)
+	if err != nil {
+		utils.LogError(err, "ReleaseRental: Error from lockerService.ReleaseRental for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to release locker.")
+		return
+	}
+	c.JSON(http.StatusOK, rental)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LostFoundHandler holds the lost and found service.
+type LostFoundHandler struct {
+	lostFoundService services.LostFoundService
+}
+
+// NewLostFoundHandler creates a new LostFoundHandler.
+func NewLostFoundHandler(ls services.LostFoundService) *LostFoundHandler {
+	return &LostFoundHandler{lostFoundService: ls}
+}
+
+// parseLostFoundID parses the :id parameter, responding with an error if it is invalid.
+func parseLostFoundID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid lost and found item ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// LogItem logs an item found at this branch.
+func (h *LostFoundHandler) LogItem(c *gin.Context) {
+	userID, ok := currentUserID(c, "LogItem")
+	if !ok {
+		return
+	}
+	var req services.LostFoundItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	item, err := h.lostFoundService.LogItem(req, userID)
+	if err != nil {
+		utils.LogError(err, "LogItem: Error from lostFoundService.LogItem")
+		respondWithServiceError(c, err, "Failed to log lost and found item.")
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// GetItems lists the lost and found items of this branch, most recently found first, optionally
+// only those with a status (stored or returned), found at a table_id, found in the time range
+// (see bindTimeRange) or whose description contains q.
+func (h *LostFoundHandler) GetItems(c *gin.Context) {
+	filters := models.LostFoundFilters{Status: c.Query("status"), Search: strings.TrimSpace(c.Query("q"))}
+	if value := c.Query("table_id"); value != "" {
+		tableID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table_id value.", err.Error()))
+			return
+		}
+		filters.TableID = &tableID
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	items, err := h.lostFoundService.GetItems(filters)
+	if err != nil {
+		utils.LogError(err, "GetItems: Error from lostFoundService.GetItems")
+		respondWithServiceError(c, err, "Failed to fetch lost and found items.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// GetItem returns a lost and found item.
+func (h *LostFoundHandler) GetItem(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	item, err := h.lostFoundService.GetItem(id)
+	if err != nil {
+		utils.LogError(err, "GetItem: Error from lostFoundService.GetItem for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch lost and found item.")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UpdateItem changes the description, table, found time or notes of a lost and found item.
+func (h *LostFoundHandler) UpdateItem(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	var req services.LostFoundItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	item, err := h.lostFoundService.UpdateItem(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateItem: Error from lostFoundService.UpdateItem for ID "+c.Param("id"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.lostFoundService.GetItem(id)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to update lost and found item.")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// MarkReturned records that a lost and found item was handed back to a client.
+func (h *LostFoundHandler) MarkReturned(c *gin.Context) {
+	userID, ok := currentUserID(c, "MarkReturned")
+	if !ok {
+		return
+	}
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	var req services.ReturnLostFoundItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	item, err := h.lostFoundService.MarkReturned(id, req, userID)
+	if err != nil {
+		utils.LogError(err, "MarkReturned: Error from lostFoundService.MarkReturned for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to return lost and found item.")
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// UploadPhoto replaces the photo of a lost and found item with the "photo" file of a multipart form.
+func (h *LostFoundHandler) UploadPhoto(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	data, ok := readPhotoUpload(c, "UploadPhoto")
+	if !ok {
+		return
+	}
+	if err := h.lostFoundService.SetPhoto(id, data); err != nil {
+		utils.LogError(err, "UploadPhoto: Error from lostFoundService.SetPhoto for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to save photo.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Photo uploaded successfully"})
+}
+
+// GetPhoto serves the photo of a lost and found item.
+func (h *LostFoundHandler) GetPhoto(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	photo, err := h.lostFoundService.GetPhoto(id)
+	if err != nil {
+		utils.LogError(err, "GetPhoto: Error from lostFoundService.GetPhoto for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch photo.")
+		return
+	}
+	c.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+// DeletePhoto removes the photo of a lost and found item.
+func (h *LostFoundHandler) DeletePhoto(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	if err := h.lostFoundService.DeletePhoto(id); err != nil {
+		utils.LogError(err, "DeletePhoto: Error from lostFoundService.DeletePhoto for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete photo.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted successfully"})
+}
+
+// DeleteItem deletes a lost and found item logged by mistake.
+func (h *LostFoundHandler) DeleteItem(c *gin.Context) {
+	id, ok := parseLostFoundID(c)
+	if !ok {
+		return
+	}
+	if err := h.lostFoundService.DeleteItem(id); err != nil {
+		utils.LogError(err, "DeleteItem: Error from lostFoundService.DeleteItem for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete lost and found item.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Lost and found item deleted successfully"})
+}
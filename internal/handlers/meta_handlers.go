@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetEnums returns the valid values of the statuses and types the API accepts, labelled
+// in the request's language, so clients do not hardcode them.
+func GetEnums(c *gin.Context) {
+	lang := utils.RequestLanguage(c)
+	c.Header("Content-Language", lang)
+	c.JSON(http.StatusOK, services.EnumCatalog(lang))
+}
+
+// GetBookingPolicy returns the booking notice and cancellation rules, so the booking
+// widget can tell clients about them before they book or cancel.
+func GetBookingPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, services.CurrentBookingPolicy())
+}
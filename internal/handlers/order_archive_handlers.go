@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderArchiveHandler holds the order archive service.
+type OrderArchiveHandler struct {
+	orderArchiveService services.OrderArchiveService
+}
+
+// NewOrderArchiveHandler creates a new OrderArchiveHandler.
+func NewOrderArchiveHandler(oas services.OrderArchiveService) *OrderArchiveHandler {
+	return &OrderArchiveHandler{orderArchiveService: oas}
+}
+
+// GetArchivedOrders lists the archived orders, newest first, with the filters of GET /orders
+// (client_id, staff_id, table_id, status, date, date_from and date_to) and page and page_size.
+func (h *OrderArchiveHandler) GetArchivedOrders(c *gin.Context) {
+	filters, ok := orderFiltersFromQuery(c)
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 10
+	}
+	filters.Page, filters.PageSize = page, pageSize
+
+	orders, total, err := h.orderArchiveService.GetArchivedOrders(filters)
+	if err != nil {
+		respondWithServiceError(c, err, "Failed to fetch archived orders.")
+		return
+	}
+	respondList(c, orders, total, page, pageSize)
+}
+
+// GetArchivedOrder returns an archived order with its items.
+func (h *OrderArchiveHandler) GetArchivedOrder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order ID format.", err.Error()))
+		return
+	}
+	order, err := h.orderArchiveService.GetArchivedOrder(orderID)
+	if err != nil {
+		respondWithServiceError(c, err, "Failed to fetch archived order.")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header row of an orders export.
+var orderExportHeader = []string{
+	"id", "order_number", "order_time", "status", "client", "table", "staff",
+	"total_amount", "discount_amount", "final_amount", "tax_amount", "prices_include_tax", "payment_method", "notes",
+	"service_charge_amount",
+}
+
+// ExportOrders serves GET /orders/export: the orders matching the filters of GET /orders
+// as CSV, newest first. The file is streamed while the orders are read, so a year of
+// orders is never held in memory.
+func (h *OrderHandler) ExportOrders(c *gin.Context) {
+	filters, ok := orderFiltersFromQuery(c)
+	if !ok {
+		return
+	}
+
+	stream := &exportStream{c: c, contentType: "text/csv; charset=utf-8", filename: "orders.csv"}
+	cw := csv.NewWriter(c.Writer)
+	writeHeader := func() error {
+		stream.start()
+		return cw.Write(orderExportHeader)
+	}
+	err := h.orderService.ExportOrders(filters, func(o *models.Order) error {
+		if !stream.started {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+		return cw.Write(orderExportRecord(o))
+	})
+	if err == nil && !stream.started { // No orders: just the header row
+		err = writeHeader()
+	}
+	if err == nil {
+		cw.Flush()
+		err = cw.Error()
+	}
+	if err != nil {
+		utils.LogError(err, "ExportOrders: Error from orderService.ExportOrders")
+	}
+	if stream.end(err) {
+		return
+	}
+	utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to export orders.", "Internal error"))
+}
+
+func orderExportRecord(o *models.Order) []string {
+	clientName, tableName, staffName := "", "", ""
+	if o.Client != nil {
+		clientName = o.Client.FullName
+	}
+	if o.GameTable != nil {
+		tableName = o.GameTable.Name
+	}
+	if o.StaffMember != nil && o.StaffMember.User != nil {
+		staffName = csvString(o.StaffMember.User.FullName)
+	}
+	return []string{
+		strconv.FormatInt(o.ID, 10), o.OrderNumber, csvTime(o.OrderTime), o.Status, clientName, tableName, staffName,
+		csvMoney(o.TotalAmount), csvOptionalMoney(o.DiscountAmount), csvMoney(o.FinalAmount), csvMoney(o.TaxAmount),
+		strconv.FormatBool(o.PricesIncludeTax), csvString(o.PaymentMethod), csvString(o.Notes),
+		csvMoney(o.ServiceChargeAmount),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	// "time" // No longer directly used for business logic here
 
 	// "ps_club_backend/internal/database" // No longer directly used
@@ -14,22 +15,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// OrderHandler holds the order service.
+// OrderHandler holds the order service. Sensitive operations (large discounts,
+// refunds, deletions) go through the approval service.
 type OrderHandler struct {
-	orderService services.OrderService
+	orderService    services.OrderService
+	approvalService services.ApprovalService
 }
 
 // NewOrderHandler creates a new OrderHandler.
-func NewOrderHandler(os services.OrderService) *OrderHandler {
-	return &OrderHandler{orderService: os}
+func NewOrderHandler(os services.OrderService, as services.ApprovalService) *OrderHandler {
+	return &OrderHandler{orderService: os, approvalService: as}
 }
 
 // CreateOrder handles the creation of a new order with its items
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var req services.CreateOrderRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateOrder: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
@@ -42,61 +43,44 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	// }
 	// req.StaffID = userID.(int64) // Cast appropriately
 
-	createdOrder, err := h.orderService.CreateOrder(req)
+	actor, ok := approvalActor(c, "CreateOrder")
+	if !ok {
+		return
+	}
+
+	req.Source = requestSource(c)
+
+	createdOrder, err := h.approvalService.CreateOrder(req, actor)
 	if err != nil {
-		utils.LogError(err, "CreateOrder: Error from orderService.CreateOrder")
-		if errors.Is(err, services.ErrPricelistItemNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "One or more pricelist items not found or unavailable.", err.Error()))
-		} else if errors.Is(err, services.ErrInsufficientStock) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Insufficient stock for one or more items.", err.Error()))
-		} else if errors.Is(err, services.ErrInvalidOrderStatus) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order status provided.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create order.", "Internal error"))
+		if respondApprovalError(c, err) {
+			return
 		}
+		utils.LogError(err, "CreateOrder: Error from orderService.CreateOrder")
+		respondWithServiceError(c, err, "Failed to create order.")
 		return
 	}
 	c.JSON(http.StatusCreated, createdOrder)
 }
 
-// GetOrders handles fetching all orders with filters
+// GetOrders handles fetching all orders with filters. With a cursor query parameter (empty
+// for the first page) it uses cursor pagination and responds with {"data", "next_cursor"}.
+// include=items adds the order items of each listed order.
 func (h *OrderHandler) GetOrders(c *gin.Context) {
-	var filters models.OrderFilters // Changed from services.OrderFilters to models.OrderFilters
-
-	// Parse query parameters
-	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
-		clientID, err := strconv.ParseInt(clientIDStr, 10, 64)
-		if err == nil {
-			filters.ClientID = &clientID
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client_id format.", err.Error()))
-			return
-		}
-	}
-	if staffIDStr := c.Query("staff_id"); staffIDStr != "" {
-		staffID, err := strconv.ParseInt(staffIDStr, 10, 64)
-		if err == nil {
-			filters.StaffID = &staffID
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id format.", err.Error()))
-			return
-		}
+	filters, ok := orderFiltersFromQuery(c)
+	if !ok {
+		return
 	}
-	if tableIDStr := c.Query("table_id"); tableIDStr != "" {
-		tableID, err := strconv.ParseInt(tableIDStr, 10, 64)
-		if err == nil {
-			filters.TableID = &tableID
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table_id format.", err.Error()))
-			return
+	if include := c.Query("include"); include != "" {
+		for _, relation := range strings.Split(include, ",") {
+			switch strings.TrimSpace(relation) {
+			case "items":
+				filters.IncludeItems = true
+			default:
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid include value.", "include accepts: items"))
+				return
+			}
 		}
 	}
-	if status := c.Query("status"); status != "" {
-		filters.Status = &status
-	}
-	if date := c.Query("date"); date != "" {
-		filters.Date = &date
-	}
 	if pageStr := c.Query("page"); pageStr != "" {
 		page, err := strconv.Atoi(pageStr)
 		if err == nil && page > 0 {
@@ -120,18 +104,23 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 		filters.PageSize = 10 // Default page size
 	}
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		page, err := h.orderService.GetOrdersByCursor(filters, cursor)
+		if err != nil {
+			utils.LogError(err, "GetOrders: Error from orderService.GetOrdersByCursor")
+			respondWithServiceError(c, err, "Failed to fetch orders.")
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
 	// The GetOrders method in OrderService now returns (orders []models.Order, totalCount int, err error)
 	// The handler needs to adapt to this.
 	orders, totalCount, err := h.orderService.GetOrders(filters)
 	if err != nil {
 		utils.LogError(err, "GetOrders: Error from orderService.GetOrders")
-		// Check if it's a specific validation error for date format from service
-		// This specific error check might need to be more robust if the error message changes
-		if filters.Date != nil && err.Error() == "invalid date filter format: "+*filters.Date+", expected YYYY-MM-DD" {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid date format. Use YYYY-MM-DD.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch orders.", "Internal error"))
-		}
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch orders.", "Internal error"))
 		return
 	}
 
@@ -141,12 +130,55 @@ func (h *OrderHandler) GetOrders(c *gin.Context) {
 	if orders == nil { // Ensure we return an empty list instead of null if no orders found
 		orders = []models.Order{}
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"data":      orders,
-		"total":     totalCount,
-		"page":      filters.Page,
-		"page_size": filters.PageSize,
-	})
+	respondList(c, orders, totalCount, filters.Page, filters.PageSize)
+}
+
+// orderFiltersFromQuery reads the order filters of a list or export request from the query:
+// client_id, staff_id, table_id, status and the time range (see bindTimeRange). On invalid
+// values it responds with 400 and returns false.
+func orderFiltersFromQuery(c *gin.Context) (models.OrderFilters, bool) {
+	var filters models.OrderFilters
+	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
+		clientID, err := strconv.ParseInt(clientIDStr, 10, 64)
+		if err == nil {
+			filters.ClientID = &clientID
+		} else {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client_id format.", err.Error()))
+			return filters, false
+		}
+	}
+	if staffIDStr := c.Query("staff_id"); staffIDStr != "" {
+		staffID, err := strconv.ParseInt(staffIDStr, 10, 64)
+		if err == nil {
+			filters.StaffID = &staffID
+		} else {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id format.", err.Error()))
+			return filters, false
+		}
+	}
+	if tableIDStr := c.Query("table_id"); tableIDStr != "" {
+		tableID, err := strconv.ParseInt(tableIDStr, 10, 64)
+		if err == nil {
+			filters.TableID = &tableID
+		} else {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table_id format.", err.Error()))
+			return filters, false
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		filters.Status = &status
+	}
+	source, ok := sourceFromQuery(c)
+	if !ok {
+		return filters, false
+	}
+	filters.Source = source
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return filters, false
+	}
+	filters.DateFrom, filters.DateTo = timeRange.From, timeRange.To
+	return filters, true
 }
 
 // GetOrderByID handles fetching a single order by ID with its items
@@ -161,16 +193,44 @@ func (h *OrderHandler) GetOrderByID(c *gin.Context) {
 	order, err := h.orderService.GetOrderByID(orderID)
 	if err != nil {
 		utils.LogError(err, "GetOrderByID: Error from orderService.GetOrderByID for ID "+idStr)
-		if errors.Is(err, services.ErrOrderNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Order not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch order.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch order.")
 		return
 	}
 	c.JSON(http.StatusOK, order)
 }
 
+// GetOrderByNumber looks up an order by the display number printed on receipts, e.g.
+// /orders/by-number/2024-06-01/37 (or /2024-06-01/%2337). A bare number searches today's orders.
+func (h *OrderHandler) GetOrderByNumber(c *gin.Context) {
+	number := strings.TrimPrefix(c.Param("number"), "/")
+
+	order, err := h.orderService.GetOrderByNumber(number)
+	if err != nil {
+		utils.LogError(err, "GetOrderByNumber: Error from orderService.GetOrderByNumber for number "+number)
+		respondWithServiceError(c, err, "Failed to fetch order.")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// GetOrderReceipt renders a plain-text receipt for an order.
+func (h *OrderHandler) GetOrderReceipt(c *gin.Context) {
+	idStr := c.Param("id")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order ID format.", err.Error()))
+		return
+	}
+
+	receipt, err := h.orderService.RenderReceipt(orderID)
+	if err != nil {
+		utils.LogError(err, "GetOrderReceipt: Error from orderService.RenderReceipt for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to render receipt.")
+		return
+	}
+	c.String(http.StatusOK, receipt)
+}
+
 // UpdateOrderStatus handles updating the status of an order
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	idStr := c.Param("id")
@@ -181,27 +241,92 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	}
 
 	var req services.UpdateOrderStatusRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdateOrderStatus: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	updatedOrder, err := h.orderService.UpdateOrderStatus(orderID, req)
+	actor, ok := approvalActor(c, "UpdateOrderStatus")
+	if !ok {
+		return
+	}
+
+	updatedOrder, err := h.approvalService.UpdateOrderStatus(orderID, req, actor)
 	if err != nil {
+		if respondApprovalError(c, err) {
+			return
+		}
 		utils.LogError(err, "UpdateOrderStatus: Error from orderService.UpdateOrderStatus for ID "+idStr)
-		if errors.Is(err, services.ErrOrderNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Order not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrInvalidOrderStatus) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order status provided.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update order status.", "Internal error"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondWithCurrentOrder(c, orderID, err)
+			return
 		}
+		respondWithServiceError(c, err, "Failed to update order status.")
 		return
 	}
 	c.JSON(http.StatusOK, updatedOrder)
 }
 
+// BulkUpdateOrderStatus sets the status of several orders at once, e.g. completing all
+// served orders at the end of the night, and reports the outcome per order.
+func (h *OrderHandler) BulkUpdateOrderStatus(c *gin.Context) {
+	var req services.BulkUpdateOrderStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	userID, ok := currentUserID(c, "BulkUpdateOrderStatus")
+	if !ok {
+		return
+	}
+	req.CallerRole = c.GetString("userRole")
+	req.CallerID = userID
+
+	result, err := h.orderService.BulkUpdateOrderStatus(req)
+	if err != nil {
+		utils.LogError(err, "BulkUpdateOrderStatus: Error from orderService.BulkUpdateOrderStatus")
+		respondWithServiceError(c, err, "Failed to update order statuses.")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// VoidOrderItem removes an item from an open order with a void reason.
+func (h *OrderHandler) VoidOrderItem(c *gin.Context) {
+	idStr := c.Param("id")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order ID format.", err.Error()))
+		return
+	}
+	itemID, err := strconv.ParseInt(c.Param("item_id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid order item ID format.", err.Error()))
+		return
+	}
+	userID, ok := currentUserID(c, "VoidOrderItem")
+	if !ok {
+		return
+	}
+
+	var req services.VoidOrderItemRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	req.CallerRole = c.GetString("userRole")
+	req.CallerID = userID
+
+	order, err := h.orderService.VoidOrderItem(orderID, itemID, req)
+	if err != nil {
+		utils.LogError(err, "VoidOrderItem: Error from orderService.VoidOrderItem for order ID "+idStr)
+		if errors.Is(err, services.ErrVersionConflict) {
+			h.respondWithCurrentOrder(c, orderID, err)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to void order item.")
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
 // DeleteOrder handles deleting an order
 func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 	idStr := c.Param("id")
@@ -211,16 +336,31 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 		return
 	}
 
-	err = h.orderService.DeleteOrder(orderID)
+	actor, ok := approvalActor(c, "DeleteOrder")
+	if !ok {
+		return
+	}
+
+	err = h.approvalService.DeleteOrder(orderID, actor)
 	if err != nil {
-		utils.LogError(err, "DeleteOrder: Error from orderService.DeleteOrder for ID "+idStr)
-		if errors.Is(err, services.ErrOrderNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Order not found to delete.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete order.", "Internal error"))
+		if respondApprovalError(c, err) {
+			return
 		}
+		utils.LogError(err, "DeleteOrder: Error from orderService.DeleteOrder for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to delete order.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Order and its items deleted successfully"})
 	// Or c.Status(http.StatusNoContent) if no message body is preferred for DELETE success
 }
+
+// respondWithCurrentOrder answers a stale update with 409 and the order as it is now.
+func (h *OrderHandler) respondWithCurrentOrder(c *gin.Context, orderID int64, err error) {
+	current, getErr := h.orderService.GetOrderByID(orderID)
+	if getErr != nil {
+		utils.LogError(getErr, "respondWithCurrentOrder: Failed to reload order after version conflict")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+		return
+	}
+	utils.RespondWithVersionConflict(c, current)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readPhotoUpload reads the "photo" file of a multipart form, responding with an error if there is
+// none or it exceeds services.MaxPhotoBytes. The service checks what kind of image it is.
+func readPhotoUpload(c *gin.Context, handlerName string) ([]byte, bool) {
+	// Leave room for the multipart headers around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxPhotoBytes+64<<10)
+	file, err := c.FormFile("photo")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusRequestEntityTooLarge, utils.ErrCodeValidationFailed, "The photo is too large.", err.Error()))
+			return nil, false
+		}
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Upload the photo as the 'photo' file of a multipart form.", err.Error()))
+		return nil, false
+	}
+	src, err := file.Open()
+	if err != nil {
+		utils.LogError(err, handlerName+": Failed to open uploaded photo")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to read photo.", "Internal error"))
+		return nil, false
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, services.MaxPhotoBytes+1))
+	if err != nil {
+		utils.LogError(err, handlerName+": Failed to read uploaded photo")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to read photo.", "Internal error"))
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PowerHandler holds the power service.
+type PowerHandler struct {
+	powerService services.PowerService
+}
+
+// NewPowerHandler creates a new PowerHandler.
+func NewPowerHandler(ps services.PowerService) *PowerHandler {
+	return &PowerHandler{powerService: ps}
+}
+
+// SetTablePower switches the TV and console of a table on or off by hand, e.g. when
+// the automatic switch at the start or end of a session failed.
+func (h *PowerHandler) SetTablePower(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table ID format.", err.Error()))
+		return
+	}
+	var req services.SetTablePowerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.powerService.SetTablePower(c.Request.Context(), id, req.State == services.PowerStateOn); err != nil {
+		utils.LogError(err, "SetTablePower: Error from powerService.SetTablePower for table ID "+idStr)
+		respondWithServiceError(c, err, "Failed to switch table power.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"table_id": id, "state": req.State})
+}
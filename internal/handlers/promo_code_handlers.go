@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromoCodeHandler holds the promo code service.
+type PromoCodeHandler struct {
+	promoCodeService services.PromoCodeService
+}
+
+// NewPromoCodeHandler creates a new PromoCodeHandler.
+func NewPromoCodeHandler(pcs services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{promoCodeService: pcs}
+}
+
+// CreatePromoCode sets up a promo code.
+func (h *PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreatePromoCode")
+	if !ok {
+		return
+	}
+	var req services.PromoCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	promo, err := h.promoCodeService.CreatePromoCode(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreatePromoCode: Error from promoCodeService.CreatePromoCode")
+		respondWithServiceError(c, err, "Failed to create promo code.")
+		return
+	}
+	c.JSON(http.StatusCreated, promo)
+}
+
+// GetPromoCodes lists the promo codes; ?active=true only the active ones.
+func (h *PromoCodeHandler) GetPromoCodes(c *gin.Context) {
+	activeOnly := false
+	if value := c.Query("active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "active must be true or false.", value))
+			return
+		}
+		activeOnly = parsed
+	}
+	promos, err := h.promoCodeService.GetPromoCodes(activeOnly)
+	if err != nil {
+		utils.LogError(err, "GetPromoCodes: Error from promoCodeService.GetPromoCodes")
+		respondWithServiceError(c, err, "Failed to fetch promo codes.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": promos})
+}
+
+// UpdatePromoCode changes the description, discount, issued count or active flag of a promo code.
+func (h *PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid promo code ID format.", err.Error()))
+		return
+	}
+	var req services.UpdatePromoCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	promo, err := h.promoCodeService.UpdatePromoCode(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdatePromoCode: Error from promoCodeService.UpdatePromoCode for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update promo code.")
+		return
+	}
+	c.JSON(http.StatusOK, promo)
+}
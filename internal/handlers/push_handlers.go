@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PushHandler holds the push notification service. The client app registers its devices and
+// manages the preferences of its client through it, and the staff see them with the delivery log.
+type PushHandler struct {
+	pushService services.PushNotificationService
+}
+
+// NewPushHandler creates a new PushHandler.
+func NewPushHandler(ps services.PushNotificationService) *PushHandler {
+	return &PushHandler{pushService: ps}
+}
+
+// parsePushClientID parses the :id parameter, the client, responding with an error if it is invalid.
+func parsePushClientID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// RegisterDevice stores the FCM token of a device of the client app for a client.
+func (h *PushHandler) RegisterDevice(c *gin.Context) {
+	var req services.RegisterPushDeviceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	device, err := h.pushService.RegisterDevice(req)
+	if err != nil {
+		utils.LogError(err, "RegisterPushDevice: Error from pushService.RegisterDevice")
+		respondWithServiceError(c, err, "Failed to register push device.")
+		return
+	}
+	c.JSON(http.StatusCreated, device)
+}
+
+// UnregisterDevice forgets the FCM token of a device, e.g. when the client signs out of the app.
+func (h *PushHandler) UnregisterDevice(c *gin.Context) {
+	if err := h.pushService.UnregisterDevice(c.Param("token")); err != nil {
+		utils.LogError(err, "UnregisterPushDevice: Error from pushService.UnregisterDevice")
+		respondWithServiceError(c, err, "Failed to unregister push device.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Push device unregistered successfully"})
+}
+
+// GetDevices lists the devices of a client that receive its push notifications.
+func (h *PushHandler) GetDevices(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	devices, err := h.pushService.GetDevices(clientID)
+	if err != nil {
+		utils.LogError(err, "GetPushDevices: Error from pushService.GetDevices for client ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch push devices.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": devices})
+}
+
+// DeleteDevice removes a device of a client, which then receives no more push notifications.
+func (h *PushHandler) DeleteDevice(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	deviceID, err := strconv.ParseInt(c.Param("device_id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid push device ID format.", err.Error()))
+		return
+	}
+	if err := h.pushService.DeleteDevice(clientID, deviceID); err != nil {
+		utils.LogError(err, "DeletePushDevice: Error from pushService.DeleteDevice for device ID "+c.Param("device_id"))
+		respondWithServiceError(c, err, "Failed to delete push device.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Push device deleted successfully"})
+}
+
+// GetPreferences returns the kinds of push notifications a client receives.
+func (h *PushHandler) GetPreferences(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	preferences, err := h.pushService.GetPreferences(clientID)
+	if err != nil {
+		utils.LogError(err, "GetPushPreferences: Error from pushService.GetPreferences for client ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch push preferences.")
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences turns kinds of push notifications of a client on or off.
+func (h *PushHandler) UpdatePreferences(c *gin.Context) {
+	clientID, ok := parsePushClientID(c)
+	if !ok {
+		return
+	}
+	var req services.UpdatePushPreferencesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	preferences, err := h.pushService.UpdatePreferences(clientID, req)
+	if err != nil {
+		utils.LogError(err, "UpdatePushPreferences: Error from pushService.UpdatePreferences for client ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update push preferences.")
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// GetDeliveries lists the push notifications sent and attempted, newest first, optionally
+// only those of a client_id, of a kind, with a status, or between from and to (YYYY-MM-DD,
+// club time).
+func (h *PushHandler) GetDeliveries(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 50
+	}
+	filters := models.PushDeliveryFilters{Page: page, PageSize: pageSize}
+	if value := c.Query("client_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid client_id value.", err.Error()))
+			return
+		}
+		filters.ClientID = &id
+	}
+	if kind := c.Query("kind"); kind != "" {
+		valid := false
+		for _, k := range models.PushKinds {
+			valid = valid || kind == k
+		}
+		if !valid {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid kind value.",
+				"kind must be one of: "+strings.Join(models.PushKinds, ", ")))
+			return
+		}
+		filters.Kind = &kind
+	}
+	if status := c.Query("status"); status != "" {
+		filters.Status = &status
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	deliveries, total, err := h.pushService.GetDeliveries(filters)
+	if err != nil {
+		utils.LogError(err, "GetPushDeliveries: Error from pushService.GetDeliveries")
+		respondWithServiceError(c, err, "Failed to fetch push deliveries.")
+		return
+	}
+	respondList(c, deliveries, total, page, pageSize)
+}
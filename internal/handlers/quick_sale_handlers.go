@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuickSaleHandler holds the quick-sale service and the approval service that creates the orders.
+type QuickSaleHandler struct {
+	quickSaleService services.QuickSaleService
+	approvalService  services.ApprovalService
+}
+
+// NewQuickSaleHandler creates a new QuickSaleHandler.
+func NewQuickSaleHandler(qs services.QuickSaleService, as services.ApprovalService) *QuickSaleHandler {
+	return &QuickSaleHandler{quickSaleService: qs, approvalService: as}
+}
+
+// parsePresetID parses the named preset ID parameter, responding with an error if it is invalid.
+func parsePresetID(c *gin.Context, param string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid preset ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreatePreset creates a quick-sale preset of this branch.
+func (h *QuickSaleHandler) CreatePreset(c *gin.Context) {
+	var req services.QuickSalePresetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	preset, err := h.quickSaleService.CreatePreset(req)
+	if err != nil {
+		utils.LogError(err, "CreatePreset: Error from quickSaleService.CreatePreset")
+		respondWithServiceError(c, err, "Failed to create quick-sale preset.")
+		return
+	}
+	c.JSON(http.StatusCreated, preset)
+}
+
+// GetPresets lists the quick-sale presets of this branch in button order. The inactive ones
+// are only listed with include_inactive=true.
+func (h *QuickSaleHandler) GetPresets(c *gin.Context) {
+	includeInactive := false
+	if value := c.Query("include_inactive"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid include_inactive value.", err.Error()))
+			return
+		}
+		includeInactive = parsed
+	}
+	presets, err := h.quickSaleService.GetPresets(includeInactive)
+	if err != nil {
+		utils.LogError(err, "GetPresets: Error from quickSaleService.GetPresets")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch quick-sale presets.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": presets})
+}
+
+// GetPreset returns a quick-sale preset with its items and total price.
+func (h *QuickSaleHandler) GetPreset(c *gin.Context) {
+	id, ok := parsePresetID(c, "id")
+	if !ok {
+		return
+	}
+	preset, err := h.quickSaleService.GetPreset(id)
+	if err != nil {
+		utils.LogError(err, "GetPreset: Error from quickSaleService.GetPreset for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch quick-sale preset.")
+		return
+	}
+	c.JSON(http.StatusOK, preset)
+}
+
+// UpdatePreset replaces a quick-sale preset, including its items.
+func (h *QuickSaleHandler) UpdatePreset(c *gin.Context) {
+	id, ok := parsePresetID(c, "id")
+	if !ok {
+		return
+	}
+	var req services.QuickSalePresetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	preset, err := h.quickSaleService.UpdatePreset(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdatePreset: Error from quickSaleService.UpdatePreset for ID "+c.Param("id"))
+		if errors.Is(err, services.ErrVersionConflict) {
+			current, getErr := h.quickSaleService.GetPreset(id)
+			if getErr != nil {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeVersionConflict, err.Error(), ""))
+				return
+			}
+			utils.RespondWithVersionConflict(c, current)
+			return
+		}
+		respondWithServiceError(c, err, "Failed to update quick-sale preset.")
+		return
+	}
+	c.JSON(http.StatusOK, preset)
+}
+
+// DeletePreset deletes a quick-sale preset; the orders created from it are kept.
+func (h *QuickSaleHandler) DeletePreset(c *gin.Context) {
+	id, ok := parsePresetID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.quickSaleService.DeletePreset(id); err != nil {
+		utils.LogError(err, "DeletePreset: Error from quickSaleService.DeletePreset for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete quick-sale preset.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Quick-sale preset deleted successfully"})
+}
+
+// CreateQuickOrder creates an order with the items of a quick-sale preset in one call.
+func (h *QuickSaleHandler) CreateQuickOrder(c *gin.Context) {
+	presetID, ok := parsePresetID(c, "preset_id")
+	if !ok {
+		return
+	}
+	var req services.QuickOrderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	actor, ok := approvalActor(c, "CreateQuickOrder")
+	if !ok {
+		return
+	}
+
+	orderReq, err := h.quickSaleService.BuildOrderRequest(presetID, req)
+	if err != nil {
+		utils.LogError(err, "CreateQuickOrder: Error from quickSaleService.BuildOrderRequest for preset "+c.Param("preset_id"))
+		respondWithServiceError(c, err, "Failed to create order.")
+		return
+	}
+	orderReq.Source = requestSource(c)
+	createdOrder, err := h.approvalService.CreateOrder(*orderReq, actor)
+	if err != nil {
+		if respondApprovalError(c, err) {
+			return
+		}
+		utils.LogError(err, "CreateQuickOrder: Error from approvalService.CreateOrder for preset "+c.Param("preset_id"))
+		respondWithServiceError(c, err, "Failed to create order.")
+		return
+	}
+	c.JSON(http.StatusCreated, createdOrder)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferenceHandler holds the reference service.
+type ReferenceHandler struct {
+	referenceService services.ReferenceService
+}
+
+// NewReferenceHandler creates a new ReferenceHandler.
+func NewReferenceHandler(rs services.ReferenceService) *ReferenceHandler {
+	return &ReferenceHandler{referenceService: rs}
+}
+
+// GetReferences returns the handler of GET /<entity>/:id/references, the delete preflight of a
+// record of entity (one of the services.ReferenceEntity constants): the counts of the records
+// referencing it, those keeping it from being deleted apart, and what to do instead.
+func (h *ReferenceHandler) GetReferences(entity string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid ID format.", err.Error()))
+			return
+		}
+		references, err := h.referenceService.GetReferences(entity, id)
+		if err != nil {
+			respondWithServiceError(c, err, "Failed to check references.")
+			return
+		}
+		c.JSON(http.StatusOK, references)
+	}
+}
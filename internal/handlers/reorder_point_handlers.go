@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReorderPointHandler holds the reorder point service.
+type ReorderPointHandler struct {
+	reorderPointService services.ReorderPointService
+}
+
+// NewReorderPointHandler creates a new ReorderPointHandler.
+func NewReorderPointHandler(rps services.ReorderPointService) *ReorderPointHandler {
+	return &ReorderPointHandler{reorderPointService: rps}
+}
+
+// GetReorderPoints lists the reorder points last calculated for the stock-tracked items, with
+// the consumption they were calculated from, and the current reorder_points setting.
+func (h *ReorderPointHandler) GetReorderPoints(c *gin.Context) {
+	points, err := h.reorderPointService.GetReorderPoints()
+	if err != nil {
+		utils.LogError(err, "GetReorderPoints: Error from reorderPointService.GetReorderPoints")
+		respondWithServiceError(c, err, "Failed to fetch reorder points.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": points, "settings": services.CurrentReorderPointSettings()})
+}
+
+// CalculateReorderPoints recalculates the reorder points now, before the next scheduled
+// calculation, and responds with them once done.
+func (h *ReorderPointHandler) CalculateReorderPoints(c *gin.Context) {
+	points, err := h.reorderPointService.Calculate(c.Request.Context())
+	if err != nil {
+		utils.LogError(err, "CalculateReorderPoints: Error from reorderPointService.Calculate")
+		respondWithServiceError(c, err, "Failed to calculate reorder points.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": points, "settings": services.CurrentReorderPointSettings()})
+}
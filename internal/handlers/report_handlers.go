@@ -2,149 +2,109 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	"ps_club_backend/internal/database"
 	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 const DefaultReportDateLayout = "2006-01-02"
 
-// parseReportRequestParams helps parse common query parameters for reports.
-func parseReportRequestParams(c *gin.Context) models.ReportRequestParams {
+// parseReportRequestParams helps parse common query parameters for reports. If the time range
+// is invalid it responds with 400 and returns false.
+func parseReportRequestParams(c *gin.Context) (models.ReportRequestParams, bool) {
+	params, err := reportParamsFromQuery(c.Request.URL.Query())
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid time range: "+err.Error(), err.Error()))
+		return params, false
+	}
+	return params, true
+}
+
+// reportParamsFromQuery reads the common query parameters of the reports; the time range is
+// read by timeRangeFromQuery, and its error returned.
+func reportParamsFromQuery(query url.Values) (models.ReportRequestParams, error) {
 	var params models.ReportRequestParams
-	params.StartDate = c.Query("start_date")
-	params.EndDate = c.Query("end_date")
-	params.Period = c.Query("period") // daily, weekly, monthly, custom
-	params.Granularity = c.Query("granularity") // hourly, daily
+	timeRange, err := timeRangeFromQuery(query)
+	if err != nil {
+		return params, err
+	}
+	params.From, params.To = timeRange.From, timeRange.To
+	params.Period = query.Get("period") // daily, weekly, monthly, custom
+	params.Granularity = query.Get("granularity") // hourly, daily
 
-	if itemIDStr := c.Query("item_id"); itemIDStr != "" {
+	if itemIDStr := query.Get("item_id"); itemIDStr != "" {
 		if id, err := strconv.ParseInt(itemIDStr, 10, 64); err == nil {
 			params.ItemID = &id
 		}
 	}
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
+	if categoryIDStr := query.Get("category_id"); categoryIDStr != "" {
 		if id, err := strconv.ParseInt(categoryIDStr, 10, 64); err == nil {
 			params.CategoryID = &id
 		}
 	}
-	if tableIDStr := c.Query("table_id"); tableIDStr != "" {
+	if tableIDStr := query.Get("table_id"); tableIDStr != "" {
 		if id, err := strconv.ParseInt(tableIDStr, 10, 64); err == nil {
 			params.TableID = &id
 		}
 	}
-	if staffIDStr := c.Query("staff_id"); staffIDStr != "" {
+	if staffIDStr := query.Get("staff_id"); staffIDStr != "" {
 		if id, err := strconv.ParseInt(staffIDStr, 10, 64); err == nil {
 			params.StaffID = &id
 		}
 	}
-	return params
+	return params, nil
 }
 
-// GetDashboardSummary provides a summary of key metrics for the dashboard.
-func GetDashboardSummary(c *gin.Context) {
-	db := database.GetDB()
-	var summary models.DashboardSummary
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
-
-	startOfWeek := startOfDay.AddDate(0, 0, -int(startOfDay.Weekday())+1) // Assuming Monday is the start of the week
-	if startOfDay.Weekday() == time.Sunday { // Adjust if Sunday is considered start of week by system
-		startOfWeek = startOfDay.AddDate(0,0,-6)
-	}
-	endOfWeek := startOfWeek.AddDate(0,0,7).Add(-time.Nanosecond)
-
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Nanosecond)
-
-	// Active Bookings Count
-	err := db.QueryRow(`SELECT COUNT(*) FROM bookings WHERE status = 'active' AND start_time <= $1 AND end_time >= $1`, now).Scan(&summary.ActiveBookingsCount)
-	if err != nil && err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get active bookings count: " + err.Error()})
-		return
-	}
-
-	// Pending Orders Count
-	err = db.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = 'pending' OR status = 'preparing'`).Scan(&summary.PendingOrdersCount)
-	if err != nil && err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pending orders count: " + err.Error()})
-		return
-	}
-
-	// Total Sales Today
-	err = db.QueryRow(`SELECT COALESCE(SUM(final_amount), 0) FROM orders WHERE status = 'completed' AND order_time BETWEEN $1 AND $2`, startOfDay, endOfDay).Scan(&summary.TotalSalesToday)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total sales today: " + err.Error()})
-		return
-	}
-
-	// Total Sales This Week
-	err = db.QueryRow(`SELECT COALESCE(SUM(final_amount), 0) FROM orders WHERE status = 'completed' AND order_time BETWEEN $1 AND $2`, startOfWeek, endOfWeek).Scan(&summary.TotalSalesThisWeek)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total sales this week: " + err.Error()})
+// GetSalesReports generates sales reports based on query parameters. It reads the
+// report_sales_by_item view, so it shows the completed orders as of the last refresh.
+func GetSalesReports(c *gin.Context) {
+	params, ok := parseReportRequestParams(c)
+	if !ok {
 		return
 	}
-
-	// Total Sales This Month
-	err = db.QueryRow(`SELECT COALESCE(SUM(final_amount), 0) FROM orders WHERE status = 'completed' AND order_time BETWEEN $1 AND $2`, startOfMonth, endOfMonth).Scan(&summary.TotalSalesThisMonth)
+	reportItems, err := querySalesReport(database.GetDB(), params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total sales this month: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Low Stock Items Count
-	err = db.QueryRow(`SELECT COUNT(*) FROM pricelist_items WHERE current_stock IS NOT NULL AND low_stock_threshold IS NOT NULL AND current_stock <= low_stock_threshold AND is_available = TRUE`).Scan(&summary.LowStockItemsCount)
-	if err != nil && err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get low stock items count: " + err.Error()})
-		return
-	}
-
-	// Upcoming Bookings Count (e.g., next 24 hours)
-	upcomingEndTime := now.Add(24 * time.Hour)
-	err = db.QueryRow(`SELECT COUNT(*) FROM bookings WHERE status = 'confirmed' AND start_time BETWEEN $1 AND $2`, now, upcomingEndTime).Scan(&summary.UpcomingBookingsCount)
-	if err != nil && err != sql.ErrNoRows {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get upcoming bookings count: " + err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, summary)
+	c.JSON(http.StatusOK, reportItems)
 }
 
-// GetSalesReports generates sales reports based on query parameters.
-func GetSalesReports(c *gin.Context) {
-	params := parseReportRequestParams(c)
-	db := database.GetDB()
+// querySalesReport runs the sales report of GetSalesReports.
+func querySalesReport(db *sql.DB, params models.ReportRequestParams) ([]models.SalesReportItem, error) {
 
 	var queryBuilder strings.Builder
 	args := []interface{}{}
 	argIdx := 1
 
+	// The view holds UTC hours as timestamps; they are converted back to regroup them in club time
 	queryBuilder.WriteString(`
 		SELECT 
-			TO_CHAR(o.order_time, $` + strconv.Itoa(argIdx) + `) as report_date,
-			oi.pricelist_item_id,
+			TO_CHAR((s.sold_hour AT TIME ZONE 'UTC') AT TIME ZONE $` + strconv.Itoa(argIdx+1) + `, $` + strconv.Itoa(argIdx) + `) as report_date,
+			s.pricelist_item_id,
 			pi.name as item_name,
 			pi.category_id,
 			pc.name as category_name,
-			SUM(oi.quantity) as total_quantity,
-			SUM(oi.total_price) as total_sales,
-			SUM(o.discount_amount / (SELECT COUNT(*) FROM order_items WHERE order_id = o.id)) as estimated_item_discount, -- Approximate discount per item
-			SUM(oi.total_price - (o.discount_amount / (SELECT COUNT(*) FROM order_items WHERE order_id = o.id))) as net_sales
-		FROM orders o
-		JOIN order_items oi ON o.id = oi.order_id
-		JOIN pricelist_items pi ON oi.pricelist_item_id = pi.id
+			SUM(s.total_quantity) as total_quantity,
+			SUM(s.total_sales) as total_sales,
+			SUM(s.total_discount) as total_discount, -- Shares of the order discounts allocated to the item
+			SUM(s.total_sales - s.total_discount) as net_sales
+		FROM report_sales_by_item s
+		JOIN pricelist_items pi ON s.pricelist_item_id = pi.id
 		LEFT JOIN pricelist_categories pc ON pi.category_id = pc.id
-		WHERE o.status = 'completed'
+		WHERE TRUE
 	`)
 
 	dateFormat := "YYYY-MM-DD" // Default daily
-	groupByClause := "report_date, oi.pricelist_item_id, pi.name, pi.category_id, pc.name"
+	groupByClause := "report_date, s.pricelist_item_id, pi.name, pi.category_id, pc.name"
 
 	switch params.Period {
 	case "weekly":
@@ -152,30 +112,23 @@ func GetSalesReports(c *gin.Context) {
 	case "monthly":
 		dateFormat = "YYYY-MM"
 	}
-	args = append(args, dateFormat)
-	argIdx++
+	args = append(args, dateFormat, utils.ClubLocation().String())
+	argIdx += 2
 
-	if params.StartDate != "" {
-		queryBuilder.WriteString(" AND o.order_time >= $" + strconv.Itoa(argIdx))
-		args = append(args, params.StartDate)
+	startDate, endDate := params.From, params.To
+	if startDate != nil {
+		queryBuilder.WriteString(" AND s.sold_hour >= ($" + strconv.Itoa(argIdx) + "::timestamptz AT TIME ZONE 'UTC')")
+		args = append(args, *startDate)
 		argIdx++
 	}
-	if params.EndDate != "" {
-		// Adjust end date to include the whole day
-		endDateParsed, err := time.Parse(DefaultReportDateLayout, params.EndDate)
-		if err == nil {
-			endDateAdjusted := endDateParsed.AddDate(0,0,1).Format(DefaultReportDateLayout)
-			queryBuilder.WriteString(" AND o.order_time < $" + strconv.Itoa(argIdx))
-			args = append(args, endDateAdjusted)
-			argIdx++
-		} else {
-			queryBuilder.WriteString(" AND o.order_time <= $" + strconv.Itoa(argIdx))
-			args = append(args, params.EndDate)
-			argIdx++
-		}
+	if endDate != nil {
+		// endDate is exclusive; a date as end_date is the start of the day after it
+		queryBuilder.WriteString(" AND s.sold_hour < ($" + strconv.Itoa(argIdx) + "::timestamptz AT TIME ZONE 'UTC')")
+		args = append(args, *endDate)
+		argIdx++
 	}
 	if params.ItemID != nil {
-		queryBuilder.WriteString(" AND oi.pricelist_item_id = $" + strconv.Itoa(argIdx))
+		queryBuilder.WriteString(" AND s.pricelist_item_id = $" + strconv.Itoa(argIdx))
 		args = append(args, *params.ItemID)
 		argIdx++
 	}
@@ -190,15 +143,13 @@ func GetSalesReports(c *gin.Context) {
 
 	rows, err := db.Query(queryBuilder.String(), args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query sales report: " + err.Error(), "query": queryBuilder.String()})
-		return
+		return nil, fmt.Errorf("Failed to query sales report: %v", err)
 	}
 	defer rows.Close()
 
 	reportItems := []models.SalesReportItem{}
 	for rows.Next() {
 		var item models.SalesReportItem
-		var estimatedDiscount sql.NullFloat64
 		if err := rows.Scan(
 			&item.Date,
 			&item.ItemID,
@@ -207,74 +158,76 @@ func GetSalesReports(c *gin.Context) {
 			&item.CategoryName,
 			&item.TotalQuantity,
 			&item.TotalSales,
-			&estimatedDiscount,
+			&item.TotalDiscount,
 			&item.NetSales,
 		); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan sales report item: " + err.Error()})
-			return
-		}
-		if estimatedDiscount.Valid {
-			item.TotalDiscount = estimatedDiscount.Float64
+			return nil, fmt.Errorf("Failed to scan sales report item: %v", err)
 		}
 		reportItems = append(reportItems, item)
 	}
+	return reportItems, rows.Err()
+}
 
+// GetBookingReports generates booking reports. It reads the report_table_utilization view,
+// so it shows the bookings as of the last refresh.
+func GetBookingReports(c *gin.Context) {
+	params, ok := parseReportRequestParams(c)
+	if !ok {
+		return
+	}
+	reportItems, err := queryBookingReport(database.GetDB(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, reportItems)
 }
 
-// GetBookingReports generates booking reports.
-func GetBookingReports(c *gin.Context) {
-	params := parseReportRequestParams(c)
-	db := database.GetDB()
+// queryBookingReport runs the booking report of GetBookingReports.
+func queryBookingReport(db *sql.DB, params models.ReportRequestParams) ([]models.BookingReportItem, error) {
 
 	var queryBuilder strings.Builder
-	args := []interface{}{}
-	argIdx := 1
+	args := []interface{}{utils.ClubLocation().String()}
+	argIdx := 2
 
+	// The view holds UTC hours as timestamps; they are converted back to regroup them in club time
 	selectClause := `
 		SELECT 
-			TO_CHAR(b.start_time, 'YYYY-MM-DD') as report_date,
-			b.table_id,
+			TO_CHAR((u.start_hour AT TIME ZONE 'UTC') AT TIME ZONE $1, 'YYYY-MM-DD') as report_date,
+			u.table_id,
 			gt.name as table_name,
 	`
-	groupByClause := "report_date, b.table_id, gt.name"
+	groupByClause := "report_date, u.table_id, gt.name"
 
 	if params.Granularity == "hourly" {
-		selectClause += " EXTRACT(HOUR FROM b.start_time) as hour_of_day,\n"
+		selectClause += " EXTRACT(HOUR FROM (u.start_hour AT TIME ZONE 'UTC') AT TIME ZONE $1) as hour_of_day,\n"
 		groupByClause += ", hour_of_day"
 	} else {
 		selectClause += " NULL as hour_of_day,\n"
 	}
 
 	selectClause += `
-			COUNT(b.id) as bookings_count,
-			SUM(EXTRACT(EPOCH FROM (b.end_time - b.start_time))) / 3600.0 as total_hours_booked
-		FROM bookings b
-		JOIN game_tables gt ON b.table_id = gt.id
-		WHERE (b.status = 'completed' OR b.status = 'active')
+			SUM(u.bookings_count) as bookings_count,
+			SUM(u.total_hours_booked) as total_hours_booked
+		FROM report_table_utilization u
+		JOIN game_tables gt ON u.table_id = gt.id
+		WHERE TRUE
 	`
 	queryBuilder.WriteString(selectClause)
 
-	if params.StartDate != "" {
-		queryBuilder.WriteString(" AND b.start_time >= $" + strconv.Itoa(argIdx))
-		args = append(args, params.StartDate)
+	startDate, endDate := params.From, params.To
+	if startDate != nil {
+		queryBuilder.WriteString(" AND u.start_hour >= ($" + strconv.Itoa(argIdx) + "::timestamptz AT TIME ZONE 'UTC')")
+		args = append(args, *startDate)
 		argIdx++
 	}
-	if params.EndDate != "" {
-		endDateParsed, err := time.Parse(DefaultReportDateLayout, params.EndDate)
-		if err == nil {
-			endDateAdjusted := endDateParsed.AddDate(0,0,1).Format(DefaultReportDateLayout)
-			queryBuilder.WriteString(" AND b.start_time < $" + strconv.Itoa(argIdx))
-			args = append(args, endDateAdjusted)
-			argIdx++
-		} else {
-			queryBuilder.WriteString(" AND b.start_time <= $" + strconv.Itoa(argIdx))
-			args = append(args, params.EndDate)
-			argIdx++
-		}
+	if endDate != nil {
+		queryBuilder.WriteString(" AND u.start_hour < ($" + strconv.Itoa(argIdx) + "::timestamptz AT TIME ZONE 'UTC')")
+		args = append(args, *endDate)
+		argIdx++
 	}
 	if params.TableID != nil {
-		queryBuilder.WriteString(" AND b.table_id = $" + strconv.Itoa(argIdx))
+		queryBuilder.WriteString(" AND u.table_id = $" + strconv.Itoa(argIdx))
 		args = append(args, *params.TableID)
 		argIdx++
 	}
@@ -284,8 +237,7 @@ func GetBookingReports(c *gin.Context) {
 
 	rows, err := db.Query(queryBuilder.String(), args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query booking report: " + err.Error(), "query": queryBuilder.String()})
-		return
+		return nil, fmt.Errorf("Failed to query booking report: %v", err)
 	}
 	defer rows.Close()
 
@@ -301,8 +253,7 @@ func GetBookingReports(c *gin.Context) {
 			&item.BookingsCount,
 			&item.TotalHours,
 		); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan booking report item: " + err.Error()})
-			return
+			return nil, fmt.Errorf("Failed to scan booking report item: %v", err)
 		}
 		if hourOfDay.Valid {
 			hour := int(hourOfDay.Int64)
@@ -310,38 +261,43 @@ func GetBookingReports(c *gin.Context) {
 		}
 		reportItems = append(reportItems, item)
 	}
-
-	c.JSON(http.StatusOK, reportItems)
+	return reportItems, rows.Err()
 }
 
 // GetInventoryReports generates inventory reports (e.g., low stock, current stock levels).
 func GetInventoryReports(c *gin.Context) {
+	reportItems, err := queryInventoryReport(database.GetDB())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reportItems)
+}
+
+// queryInventoryReport runs the inventory report of GetInventoryReports.
+func queryInventoryReport(db *sql.DB) ([]models.InventoryReportItem, error) {
 	// For simplicity, this will list items with stock levels, highlighting low stock.
 	// More complex reports could include movement history, spoilage, etc.
-	db := database.GetDB()
 	query := `
 		SELECT 
 			pi.id, pi.name, pi.sku, pi.category_id, pc.name as category_name, 
-			pi.current_stock, pi.low_stock_threshold,
+			pi.stock_unit, pi.current_stock, pi.low_stock_threshold,
 			(SELECT MAX(im.movement_date) FROM inventory_movements im WHERE im.pricelist_item_id = pi.id) as last_movement_date
 		FROM pricelist_items pi
 		LEFT JOIN pricelist_categories pc ON pi.category_id = pc.id
-		WHERE pi.current_stock IS NOT NULL -- Only items that are tracked
+		WHERE pi.current_stock IS NOT NULL AND pi.deleted_at IS NULL -- Only items that are tracked
 		ORDER BY pi.name ASC
 	`
 
 	rows, err := db.Query(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query inventory report: " + err.Error()})
-		return
+		return nil, fmt.Errorf("Failed to query inventory report: %v", err)
 	}
 	defer rows.Close()
 
 	reportItems := []models.InventoryReportItem{}
 	for rows.Next() {
 		var item models.InventoryReportItem
-		var currentStock sql.NullInt64
-		var lowStockThreshold sql.NullInt64
 		var lastMovementDate sql.NullTime
 
 		if err := rows.Scan(
@@ -350,39 +306,25 @@ func GetInventoryReports(c *gin.Context) {
 			&item.SKU,
 			&item.CategoryID,
 			&item.CategoryName,
-			&currentStock,
-			&lowStockThreshold,
+			&item.StockUnit,
+			&item.CurrentStock,
+			&item.LowStockThreshold,
 			&lastMovementDate,
 		); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan inventory report item: " + err.Error()})
-			return
+			return nil, fmt.Errorf("Failed to scan inventory report item: %v", err)
 		}
-		if currentStock.Valid {
-			item.CurrentStock = int(currentStock.Int64)
-		}
-		if lowStockThreshold.Valid {
-			threshold := int(lowStockThreshold.Int64)
-			item.LowStockThreshold = &threshold
-			if currentStock.Valid && currentStock.Int64 <= lowStockThreshold.Int64 {
-				item.Status = "Low Stock"
-			} else if currentStock.Valid && currentStock.Int64 == 0 {
-			    item.Status = "Out of Stock"
-			} else {
-				item.Status = "In Stock"
-			}
+		if item.LowStockThreshold != nil && item.CurrentStock.Cmp(*item.LowStockThreshold) <= 0 {
+			item.Status = "Low Stock"
+		} else if !item.CurrentStock.IsPositive() {
+			item.Status = "Out of Stock"
 		} else {
-		    if currentStock.Valid && currentStock.Int64 == 0 {
-		        item.Status = "Out of Stock"
-		    } else {
-		        item.Status = "In Stock" // No threshold defined
-		    }
+			item.Status = "In Stock" // No threshold defined, or above it
 		}
 		if lastMovementDate.Valid {
 			item.LastMovementDate = &lastMovementDate.Time
 		}
 		reportItems = append(reportItems, item)
 	}
-
-	c.JSON(http.StatusOK, reportItems)
+	return reportItems, rows.Err()
 }
 
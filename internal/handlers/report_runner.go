@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+)
+
+// ReportRunner runs the reports of the /reports routes outside a request, for the saved
+// reports; it implements services.ReportRunner.
+type ReportRunner struct {
+	db            *sql.DB
+	reportService services.ReportService
+}
+
+// NewReportRunner creates a new ReportRunner.
+func NewReportRunner(db *sql.DB, reportService services.ReportService) *ReportRunner {
+	return &ReportRunner{db: db, reportService: reportService}
+}
+
+// RunReport returns what the route of the report type returns for the query parameters.
+func (r *ReportRunner) RunReport(reportType string, params url.Values) (interface{}, error) {
+	switch reportType {
+	case models.SavedReportTypeSummary, models.SavedReportTypeTax:
+		timeRange, err := timeRangeFromQuery(params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", services.ErrSavedReportValidation, err)
+		}
+		startDate, endDate := timeRange.ClubDates()
+		var report interface{}
+		if reportType == models.SavedReportTypeSummary {
+			report, err = r.reportService.GetPeriodReport(startDate, endDate, params.Get("period"))
+		} else {
+			report, err = r.reportService.GetTaxReport(startDate, endDate, params.Get("period"))
+		}
+		if errors.Is(err, services.ErrReportValidation) {
+			return nil, fmt.Errorf("%w: %v", services.ErrSavedReportValidation, err)
+		}
+		return report, err
+	case models.SavedReportTypeSales, models.SavedReportTypeBookings:
+		reportParams, err := reportParamsFromQuery(params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", services.ErrSavedReportValidation, err)
+		}
+		if reportType == models.SavedReportTypeSales {
+			return querySalesReport(r.db, reportParams)
+		}
+		return queryBookingReport(r.db, reportParams)
+	case models.SavedReportTypeInventory:
+		return queryInventoryReport(r.db)
+	}
+	return nil, fmt.Errorf("%w: unknown report type %q", services.ErrSavedReportValidation, reportType)
+}
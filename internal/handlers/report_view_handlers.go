@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportViewHandler holds the report view service.
+type ReportViewHandler struct {
+	reportViewService services.ReportViewService
+}
+
+// NewReportViewHandler creates a new ReportViewHandler.
+func NewReportViewHandler(rvs services.ReportViewService) *ReportViewHandler {
+	return &ReportViewHandler{reportViewService: rvs}
+}
+
+// GetReportViews lists the report views with their last refresh, i.e. how current the sales
+// and booking reports are.
+func (h *ReportViewHandler) GetReportViews(c *gin.Context) {
+	refreshes, err := h.reportViewService.GetRefreshes()
+	if err != nil {
+		utils.LogError(err, "GetReportViews: Error from reportViewService.GetRefreshes")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch report views.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": refreshes})
+}
+
+// RefreshReportViews refreshes the report views now, before the next scheduled refresh, and
+// responds with their refreshes once done.
+func (h *ReportViewHandler) RefreshReportViews(c *gin.Context) {
+	refreshes, err := h.reportViewService.RefreshViews(c.Request.Context())
+	if err != nil {
+		utils.LogError(err, "RefreshReportViews: Error from reportViewService.RefreshViews")
+		respondWithServiceError(c, err, "Failed to refresh report views.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": refreshes})
+}
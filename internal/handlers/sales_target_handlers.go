@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SalesTargetHandler holds the sales target service.
+type SalesTargetHandler struct {
+	salesTargetService services.SalesTargetService
+}
+
+// NewSalesTargetHandler creates a new SalesTargetHandler.
+func NewSalesTargetHandler(sts services.SalesTargetService) *SalesTargetHandler {
+	return &SalesTargetHandler{salesTargetService: sts}
+}
+
+// parseSalesTargetID parses the :id parameter, responding with an error if it is invalid.
+func parseSalesTargetID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid sales target ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateSalesTarget sets the revenue target of a category in a month.
+func (h *SalesTargetHandler) CreateSalesTarget(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreateSalesTarget")
+	if !ok {
+		return
+	}
+	var req services.SalesTargetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	target, err := h.salesTargetService.CreateTarget(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreateSalesTarget: Error from salesTargetService.CreateTarget")
+		respondWithServiceError(c, err, "Failed to create sales target.")
+		return
+	}
+	c.JSON(http.StatusCreated, target)
+}
+
+// GetSalesTargets lists the targets of a month, ?month=YYYY-MM (default the current one), of
+// a branch, ?branch_code= (default this one).
+func (h *SalesTargetHandler) GetSalesTargets(c *gin.Context) {
+	targets, err := h.salesTargetService.GetTargets(c.Query("branch_code"), c.Query("month"))
+	if err != nil {
+		utils.LogError(err, "GetSalesTargets: Error from salesTargetService.GetTargets")
+		respondWithServiceError(c, err, "Failed to fetch sales targets.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": targets})
+}
+
+// UpdateSalesTarget changes the amount of a target.
+func (h *SalesTargetHandler) UpdateSalesTarget(c *gin.Context) {
+	id, ok := parseSalesTargetID(c)
+	if !ok {
+		return
+	}
+	var req services.SalesTargetAmountRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	target, err := h.salesTargetService.UpdateTarget(id, req.Amount)
+	if err != nil {
+		utils.LogError(err, "UpdateSalesTarget: Error from salesTargetService.UpdateTarget for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update sales target.")
+		return
+	}
+	c.JSON(http.StatusOK, target)
+}
+
+// DeleteSalesTarget deletes a target.
+func (h *SalesTargetHandler) DeleteSalesTarget(c *gin.Context) {
+	id, ok := parseSalesTargetID(c)
+	if !ok {
+		return
+	}
+	if err := h.salesTargetService.DeleteTarget(id); err != nil {
+		utils.LogError(err, "DeleteSalesTarget: Error from salesTargetService.DeleteTarget for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete sales target.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sales target deleted successfully"})
+}
+
+// GetTargetProgress reports the sales of the categories with a target against it, with the
+// pace projected to the end of the month; query parameters as for GetSalesTargets.
+func (h *SalesTargetHandler) GetTargetProgress(c *gin.Context) {
+	progress, err := h.salesTargetService.GetProgress(c.Query("branch_code"), c.Query("month"))
+	if err != nil {
+		utils.LogError(err, "GetTargetProgress: Error from salesTargetService.GetProgress")
+		respondWithServiceError(c, err, "Failed to fetch sales target progress.")
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
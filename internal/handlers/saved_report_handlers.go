@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavedReportHandler holds the saved report service.
+type SavedReportHandler struct {
+	savedReportService services.SavedReportService
+}
+
+// NewSavedReportHandler creates a new SavedReportHandler.
+func NewSavedReportHandler(srs services.SavedReportService) *SavedReportHandler {
+	return &SavedReportHandler{savedReportService: srs}
+}
+
+// parseSavedReportID parses the :id parameter, responding with an error if it is invalid.
+func parseSavedReportID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid saved report ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateSavedReport saves a report with its parameters and, optionally, an email schedule.
+func (h *SavedReportHandler) CreateSavedReport(c *gin.Context) {
+	userID, ok := currentUserID(c, "CreateSavedReport")
+	if !ok {
+		return
+	}
+	var req services.SavedReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	report, err := h.savedReportService.CreateReport(req, userID)
+	if err != nil {
+		utils.LogError(err, "CreateSavedReport: Error from savedReportService.CreateReport")
+		respondWithServiceError(c, err, "Failed to save report.")
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetSavedReports lists the saved reports by name.
+func (h *SavedReportHandler) GetSavedReports(c *gin.Context) {
+	reports, err := h.savedReportService.GetReports()
+	if err != nil {
+		utils.LogError(err, "GetSavedReports: Error from savedReportService.GetReports")
+		respondWithServiceError(c, err, "Failed to fetch saved reports.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
+
+// GetSavedReport returns a saved report.
+func (h *SavedReportHandler) GetSavedReport(c *gin.Context) {
+	id, ok := parseSavedReportID(c)
+	if !ok {
+		return
+	}
+	report, err := h.savedReportService.GetReport(id)
+	if err != nil {
+		utils.LogError(err, "GetSavedReport: Error from savedReportService.GetReport for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch saved report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// UpdateSavedReport replaces a saved report; its next scheduled run is set again.
+func (h *SavedReportHandler) UpdateSavedReport(c *gin.Context) {
+	id, ok := parseSavedReportID(c)
+	if !ok {
+		return
+	}
+	var req services.SavedReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	report, err := h.savedReportService.UpdateReport(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateSavedReport: Error from savedReportService.UpdateReport for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update saved report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// DeleteSavedReport deletes a saved report, which stops its emails.
+func (h *SavedReportHandler) DeleteSavedReport(c *gin.Context) {
+	id, ok := parseSavedReportID(c)
+	if !ok {
+		return
+	}
+	if err := h.savedReportService.DeleteReport(id); err != nil {
+		utils.LogError(err, "DeleteSavedReport: Error from savedReportService.DeleteReport for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete saved report.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Saved report deleted successfully"})
+}
+
+// RunSavedReport runs a saved report. Query parameters replace its saved ones for this run,
+// e.g. ?range=yesterday; a time range given replaces the saved one as a whole.
+func (h *SavedReportHandler) RunSavedReport(c *gin.Context) {
+	id, ok := parseSavedReportID(c)
+	if !ok {
+		return
+	}
+	run, err := h.savedReportService.RunReport(id, c.Request.URL.Query())
+	if err != nil {
+		utils.LogError(err, "RunSavedReport: Error from savedReportService.RunReport for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to run saved report.")
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
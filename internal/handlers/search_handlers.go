@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler holds the search service.
+type SearchHandler struct {
+	searchService services.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(ss services.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: ss}
+}
+
+// Search serves the global search box: GET /search?q=...&limit=5 returns the
+// matching orders, bookings and clients grouped by type, best group first.
+func (h *SearchHandler) Search(c *gin.Context) {
+	limit := services.DefaultSearchLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid limit, must be a positive integer.", limitStr))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.searchService.Search(c.Query("q"), limit)
+	if err != nil {
+		utils.LogError(err, "Search: Error from searchService.Search")
+		respondWithServiceError(c, err, "Failed to search.")
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
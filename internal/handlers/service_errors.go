@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusByErrorCode is the HTTP status domain errors are answered with, by error code.
+var statusByErrorCode = map[string]int{
+	utils.ErrCodeBadRequest:            http.StatusBadRequest,
+	utils.ErrCodeValidationFailed:      http.StatusBadRequest,
+	utils.ErrCodeBookingNoticeTooShort: http.StatusBadRequest,
+	utils.ErrCodeUnauthorized:          http.StatusUnauthorized,
+	utils.ErrCodeForbidden:             http.StatusForbidden,
+	utils.ErrCodeDiscountLimitExceeded: http.StatusForbidden,
+	utils.ErrCodeFieldNotPermitted:     http.StatusForbidden,
+	utils.ErrCodeDayClosed:             http.StatusForbidden,
+	utils.ErrCodeClientBlacklisted:     http.StatusForbidden,
+	utils.ErrCodeClockInRestricted:     http.StatusForbidden,
+	utils.ErrCodePaymentFailed:         http.StatusPaymentRequired,
+	utils.ErrCodeNotFound:              http.StatusNotFound,
+	utils.ErrCodeConflict:              http.StatusConflict,
+	utils.ErrCodeVersionConflict:       http.StatusConflict,
+	utils.ErrCodeIdempotencyKeyReuse:   http.StatusConflict,
+	utils.ErrCodeLateCancellationFee:   http.StatusConflict,
+	utils.ErrCodeDayCloseBlocked:       http.StatusConflict,
+	utils.ErrCodeTooManyRequests:       http.StatusTooManyRequests,
+	utils.ErrCodeNotImplemented:        http.StatusNotImplemented,
+	utils.ErrCodeDeviceFailed:          http.StatusBadGateway,
+}
+
+// respondWithServiceError answers an error returned by a service. A domain error is
+// answered with the status of its code, its message and the whole error as details; any
+// other error with 500 and fallbackMessage, keeping its internals out of the response.
+func respondWithServiceError(c *gin.Context, err error, fallbackMessage string) {
+	if domainErr, ok := apperrors.AsDomainError(err); ok {
+		if status, ok := statusByErrorCode[domainErr.Code]; ok {
+			utils.RespondWithError(c, utils.NewAPIError(status, domainErr.Code, domainErr.Message, err.Error()))
+			return
+		}
+	}
+	utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, fallbackMessage, "Internal error"))
+}
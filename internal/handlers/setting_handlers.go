@@ -1,46 +1,37 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
+	"os"
 	"time"
 
 	"ps_club_backend/internal/database"
+	"ps_club_backend/internal/middleware"
 	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/services"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetApplicationSettings retrieves all application settings
 func GetApplicationSettings(c *gin.Context) {
-	db := database.GetDB()
-	rows, err := db.Query("SELECT id, setting_key, setting_value, description, created_at, updated_at FROM application_settings ORDER BY setting_key")
+	settings, err := repositories.NewSettingRepository(database.GetDB()).GetSettings()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch application settings: " + err.Error()})
 		return
 	}
-	defer rows.Close()
-
-	settings := []models.ApplicationSetting{}
-	for rows.Next() {
-		var s models.ApplicationSetting
-		if err := rows.Scan(&s.ID, &s.SettingKey, &s.SettingValue, &s.Description, &s.CreatedAt, &s.UpdatedAt); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan application setting: " + err.Error()})
-			return
-		}
-		settings = append(settings, s)
-	}
 	c.JSON(http.StatusOK, settings)
 }
 
 // GetApplicationSettingByKey retrieves a specific application setting by its key
 func GetApplicationSettingByKey(c *gin.Context) {
 	key := c.Param("key")
-	db := database.GetDB()
-	var s models.ApplicationSetting
-	query := "SELECT id, setting_key, setting_value, description, created_at, updated_at FROM application_settings WHERE setting_key = $1"
-	err := db.QueryRow(query, key).Scan(&s.ID, &s.SettingKey, &s.SettingValue, &s.Description, &s.CreatedAt, &s.UpdatedAt)
-	if err == sql.ErrNoRows {
+	s, err := repositories.NewSettingRepository(database.GetDB()).GetSettingByKey(key)
+	if errors.Is(err, repositories.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Application setting not found for key: " + key})
 		return
 	} else if err != nil {
@@ -53,8 +44,7 @@ func GetApplicationSettingByKey(c *gin.Context) {
 // CreateOrUpdateApplicationSetting creates a new setting or updates an existing one by key
 func CreateOrUpdateApplicationSetting(c *gin.Context) {
 	var setting models.ApplicationSetting
-	if err := c.ShouldBindJSON(&setting); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+	if !bindJSON(c, &setting) {
 		return
 	}
 
@@ -63,42 +53,375 @@ func CreateOrUpdateApplicationSetting(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
-	now := time.Now()
-
-	// Try to update first (UPSERT behavior)
-	query := `
-	    INSERT INTO application_settings (setting_key, setting_value, description, created_at, updated_at) 
-	    VALUES ($1, $2, $3, $4, $5) 
-	    ON CONFLICT (setting_key) 
-	    DO UPDATE SET setting_value = EXCLUDED.setting_value, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
-	    RETURNING id, setting_key, setting_value, description, created_at, updated_at`
-
-	err := db.QueryRow(query, setting.SettingKey, setting.SettingValue, setting.Description, now, now).
-		Scan(&setting.ID, &setting.SettingKey, &setting.SettingValue, &setting.Description, &setting.CreatedAt, &setting.UpdatedAt)
+	// The club timezone, currency and API sunset are applied immediately, so reject values that cannot be loaded
+	var currency utils.Currency
+	var sunset *time.Time
+	var discountLimits models.DiscountLimits
+	var bookingPolicy models.BookingPolicy
+	var pricingRules models.PricingRules
+	var hookahSettings models.HookahSettings
+	var lostFoundSettings models.LostFoundSettings
+	var payloadLogging models.PayloadLogging
+	var taxSettings models.TaxSettings
+	var reorderPointSettings models.ReorderPointSettings
+	var attendanceSettings models.AttendanceSettings
+	var clockInSettings models.ClockInSettings
+	var serviceChargeSettings models.ServiceChargeSettings
+	var orderArchiveSettings models.OrderArchiveSettings
+	var anomalySettings models.AnomalySettings
+	var pushSettings models.PushSettings
+	var bookingReminderSettings models.BookingReminderSettings
+	switch setting.SettingKey {
+	case models.SettingKeyClubTimezone, models.SettingKeyCurrency:
+		if setting.SettingValue == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Setting value is required for " + setting.SettingKey})
+			return
+		}
+	}
+	switch setting.SettingKey {
+	case models.SettingKeyClubTimezone:
+		if _, err := time.LoadLocation(*setting.SettingValue); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone: " + err.Error()})
+			return
+		}
+	case models.SettingKeyCurrency:
+		var err error
+		currency, err = utils.ParseCurrencySetting(*setting.SettingValue)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency: " + err.Error()})
+			return
+		}
+	case models.SettingKeyAPIV1Sunset:
+		if setting.SettingValue != nil && *setting.SettingValue != "" {
+			date, err := utils.ParseClubDate(*setting.SettingValue)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sunset date, expected YYYY-MM-DD: " + err.Error()})
+				return
+			}
+			sunset = &date
+		}
+	case models.SettingKeyDiscountLimits:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		discountLimits, err = models.ParseDiscountLimits(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyBookingPolicy:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		bookingPolicy, err = models.ParseBookingPolicy(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyPricingRules:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		pricingRules, err = models.ParsePricingRules(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyHookahService:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		hookahSettings, err = models.ParseHookahSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyLostAndFound:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		lostFoundSettings, err = models.ParseLostFoundSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyOpenRegistration:
+		// Read on every registration, so it only needs validating here
+		if setting.SettingValue != nil {
+			if _, err := models.ParseOpenRegistration(*setting.SettingValue); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	case models.SettingKeyPayloadLogging:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		payloadLogging, err = models.ParsePayloadLogging(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyTax:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		taxSettings, err = models.ParseTaxSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyReorderPoints:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		reorderPointSettings, err = models.ParseReorderPointSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyAttendance:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		attendanceSettings, err = models.ParseAttendanceSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyClockIn:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		clockInSettings, err = models.ParseClockInSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyServiceCharge:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		serviceChargeSettings, err = models.ParseServiceChargeSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyOrderArchive:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		orderArchiveSettings, err = models.ParseOrderArchiveSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyAnomalyDetection:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		anomalySettings, err = models.ParseAnomalySettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyPushNotifications:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		pushSettings, err = models.ParsePushSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeyBookingReminders:
+		value := ""
+		if setting.SettingValue != nil {
+			value = *setting.SettingValue
+		}
+		var err error
+		bookingReminderSettings, err = models.ParseBookingReminderSettings(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case models.SettingKeySentryDSN:
+		if setting.SettingValue != nil {
+			if err := apperrors.ValidateDSN(*setting.SettingValue); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	case models.SettingKeyBusinessHours:
+		if setting.SettingValue != nil {
+			if _, err := models.ParseBusinessHours(*setting.SettingValue); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	case models.SettingKeySetupCompletedAt:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Setting " + setting.SettingKey + " is set by the initial setup and cannot be changed"})
+		return
+	case models.SettingKeyBackupSchedule:
+		// Read by the backup scheduler on every check, so it only needs validating here
+		if setting.SettingValue != nil {
+			if _, err := models.ParseBackupSchedule(*setting.SettingValue); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
 
-	if err != nil {
+	// Secret values are encrypted by the repository
+	settingRepo := repositories.NewSettingRepository(database.GetDB())
+	err := settingRepo.SaveSetting(&setting)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		current, getErr := settingRepo.GetSettingByKey(setting.SettingKey)
+		if getErr != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Application setting was modified by another user, reload it and retry"})
+			return
+		}
+		utils.RespondWithVersionConflict(c, current)
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create or update application setting: " + err.Error()})
 		return
 	}
+	switch setting.SettingKey {
+	case models.SettingKeyClubTimezone:
+		if err := utils.SetClubTimezone(*setting.SettingValue); err != nil {
+			utils.LogError(err, "CreateOrUpdateApplicationSetting: failed to apply club timezone")
+		}
+	case models.SettingKeyCurrency:
+		if err := utils.SetCurrency(currency); err != nil {
+			utils.LogError(err, "CreateOrUpdateApplicationSetting: failed to apply currency")
+		}
+	case models.SettingKeyAPIV1Sunset:
+		middleware.SetV1Sunset(sunset)
+	case models.SettingKeyDiscountLimits:
+		services.SetDiscountLimits(discountLimits)
+	case models.SettingKeyBookingPolicy:
+		services.SetBookingPolicy(bookingPolicy)
+	case models.SettingKeyPricingRules:
+		services.SetPricingRules(pricingRules)
+	case models.SettingKeyHookahService:
+		services.SetHookahSettings(hookahSettings)
+	case models.SettingKeyLostAndFound:
+		services.SetLostFoundSettings(lostFoundSettings)
+	case models.SettingKeyPayloadLogging:
+		middleware.SetPayloadLogging(payloadLogging)
+	case models.SettingKeyTax:
+		services.SetTaxSettings(taxSettings)
+	case models.SettingKeyReorderPoints:
+		services.SetReorderPointSettings(reorderPointSettings)
+	case models.SettingKeyAttendance:
+		services.SetAttendanceSettings(attendanceSettings)
+	case models.SettingKeyClockIn:
+		services.SetClockInSettings(clockInSettings)
+	case models.SettingKeyServiceCharge:
+		services.SetServiceChargeSettings(serviceChargeSettings)
+	case models.SettingKeyOrderArchive:
+		services.SetOrderArchiveSettings(orderArchiveSettings)
+	case models.SettingKeyAnomalyDetection:
+		services.SetAnomalySettings(anomalySettings)
+	case models.SettingKeyPushNotifications:
+		services.SetPushSettings(pushSettings)
+	case models.SettingKeyBookingReminders:
+		services.SetBookingReminderSettings(bookingReminderSettings)
+	case models.SettingKeySentryDSN:
+		dsn := ""
+		if setting.SettingValue != nil {
+			dsn = *setting.SettingValue
+		}
+		if err := apperrors.Configure(dsn); err != nil {
+			utils.LogError(err, "CreateOrUpdateApplicationSetting: failed to configure error reporting")
+		}
+	}
 	c.JSON(http.StatusOK, setting) // Could be StatusCreated if we distinguish, but OK is fine for upsert.
 }
 
 // DeleteApplicationSettingByKey deletes an application setting by its key
 func DeleteApplicationSettingByKey(c *gin.Context) {
 	key := c.Param("key")
-	db := database.GetDB()
-
-	result, err := db.Exec("DELETE FROM application_settings WHERE setting_key = $1", key)
-	if err != nil {
+	err := repositories.NewSettingRepository(database.GetDB()).DeleteSetting(key)
+	if errors.Is(err, repositories.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application setting not found to delete for key: " + key})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete application setting: " + err.Error()})
 		return
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application setting not found to delete for key: " + key})
-		return
+	switch key {
+	case models.SettingKeyAPIV1Sunset:
+		middleware.SetV1Sunset(nil)
+	case models.SettingKeyDiscountLimits:
+		services.SetDiscountLimits(models.DiscountLimits{})
+	case models.SettingKeyBookingPolicy:
+		services.SetBookingPolicy(models.BookingPolicy{})
+	case models.SettingKeyPricingRules:
+		services.SetPricingRules(models.PricingRules{})
+	case models.SettingKeyHookahService:
+		services.SetHookahSettings(models.HookahSettings{})
+	case models.SettingKeyLostAndFound:
+		services.SetLostFoundSettings(models.DefaultLostFoundSettings())
+	case models.SettingKeyPayloadLogging:
+		middleware.SetPayloadLogging(models.PayloadLogging{})
+	case models.SettingKeyTax:
+		services.SetTaxSettings(models.TaxSettings{})
+	case models.SettingKeyReorderPoints:
+		services.SetReorderPointSettings(models.DefaultReorderPointSettings())
+	case models.SettingKeyAttendance:
+		services.SetAttendanceSettings(models.DefaultAttendanceSettings())
+	case models.SettingKeyClockIn:
+		services.SetClockInSettings(models.ClockInSettings{})
+	case models.SettingKeyServiceCharge:
+		services.SetServiceChargeSettings(models.ServiceChargeSettings{})
+	case models.SettingKeyOrderArchive:
+		services.SetOrderArchiveSettings(models.OrderArchiveSettings{})
+	case models.SettingKeyAnomalyDetection:
+		services.SetAnomalySettings(models.DefaultAnomalySettings())
+	case models.SettingKeyPushNotifications:
+		services.SetPushSettings(models.DefaultPushSettings())
+	case models.SettingKeyBookingReminders:
+		services.SetBookingReminderSettings(models.DefaultBookingReminderSettings())
+	case models.SettingKeySentryDSN:
+		if err := apperrors.Configure(os.Getenv("SENTRY_DSN")); err != nil { // Back to the environment default
+			utils.LogError(err, "DeleteApplicationSettingByKey: failed to configure error reporting")
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Application setting '" + key + "' deleted successfully"})
 }
 
+
+// GetCurrency returns the currency used for all monetary amounts, so clients can format them.
+func GetCurrency(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.CurrentCurrency())
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHandler holds the setup service.
+type SetupHandler struct {
+	setupService services.SetupService
+}
+
+// NewSetupHandler creates a new SetupHandler.
+func NewSetupHandler(ss services.SetupService) *SetupHandler {
+	return &SetupHandler{setupService: ss}
+}
+
+// GetSetupStatus tells whether the initial setup is still to be done.
+func (h *SetupHandler) GetSetupStatus(c *gin.Context) {
+	status, err := h.setupService.GetSetupStatus()
+	if err != nil {
+		utils.LogError(err, "GetSetupStatus: Error from setupService.GetSetupStatus")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch setup status.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// CompleteSetup creates the first Admin and the club settings on an empty database. It is
+// unauthenticated, so it locks itself once completed.
+func (h *SetupHandler) CompleteSetup(c *gin.Context) {
+	var req services.CompleteSetupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.setupService.CompleteSetup(req)
+	if err != nil {
+		utils.LogError(err, "CompleteSetup: Error from setupService.CompleteSetup")
+		respondWithServiceError(c, err, "Failed to complete setup.")
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShiftReportHandler holds the shift report service.
+type ShiftReportHandler struct {
+	shiftReportService services.ShiftReportService
+}
+
+// NewShiftReportHandler creates a new ShiftReportHandler.
+func NewShiftReportHandler(srs services.ShiftReportService) *ShiftReportHandler {
+	return &ShiftReportHandler{shiftReportService: srs}
+}
+
+// parseShiftReportParam parses the :id parameter, responding with an error if it is invalid.
+func parseShiftReportParam(c *gin.Context, what string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid "+what+" ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// GetShiftReport returns the end-of-shift report of a scheduled shift, from the last clock-out during it.
+func (h *ShiftReportHandler) GetShiftReport(c *gin.Context) {
+	id, ok := parseShiftReportParam(c, "shift")
+	if !ok {
+		return
+	}
+	report, err := h.shiftReportService.GetReportForShift(id)
+	if err != nil {
+		utils.LogError(err, "GetShiftReport: Error from shiftReportService.GetReportForShift for shift ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch shift report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetShiftReports lists the end-of-shift reports of this branch, most recent first, optionally
+// only those of a staff_id or that ended in the time range (see bindTimeRange).
+func (h *ShiftReportHandler) GetShiftReports(c *gin.Context) {
+	var filters models.ShiftReportFilters
+	if value := c.Query("staff_id"); value != "" {
+		staffID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id value.", err.Error()))
+			return
+		}
+		filters.StaffID = &staffID
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	reports, err := h.shiftReportService.GetReports(filters)
+	if err != nil {
+		utils.LogError(err, "GetShiftReports: Error from shiftReportService.GetReports")
+		respondWithServiceError(c, err, "Failed to fetch shift reports.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
+
+// GetShiftReportByID returns an end-of-shift report.
+func (h *ShiftReportHandler) GetShiftReportByID(c *gin.Context) {
+	id, ok := parseShiftReportParam(c, "shift report")
+	if !ok {
+		return
+	}
+	report, err := h.shiftReportService.GetReport(id)
+	if err != nil {
+		utils.LogError(err, "GetShiftReportByID: Error from shiftReportService.GetReport for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch shift report.")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaffDocumentHandler holds the staff document service.
+type StaffDocumentHandler struct {
+	documentService services.StaffDocumentService
+}
+
+// NewStaffDocumentHandler creates a new StaffDocumentHandler.
+func NewStaffDocumentHandler(sds services.StaffDocumentService) *StaffDocumentHandler {
+	return &StaffDocumentHandler{documentService: sds}
+}
+
+// GetStaffDocuments lists the documents of a staff member.
+func (h *StaffDocumentHandler) GetStaffDocuments(c *gin.Context) {
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	documents, err := h.documentService.GetDocuments(staffID)
+	if err != nil {
+		utils.LogError(err, "GetStaffDocuments: Error from documentService.GetDocuments for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch staff documents.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": documents})
+}
+
+// optionalPostForm returns a field of a multipart form, nil if it was not sent.
+func optionalPostForm(c *gin.Context, field string) *string {
+	if value, ok := c.GetPostForm(field); ok {
+		return &value
+	}
+	return nil
+}
+
+// AddStaffDocument stores the "photo" file of a multipart form as a document of a staff
+// member, of the form's document_type and title, valid through its expires_on (YYYY-MM-DD).
+func (h *StaffDocumentHandler) AddStaffDocument(c *gin.Context) {
+	userID, ok := currentUserID(c, "AddStaffDocument")
+	if !ok {
+		return
+	}
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	data, ok := readPhotoUpload(c, "AddStaffDocument")
+	if !ok {
+		return
+	}
+	document, err := h.documentService.AddDocument(staffID, c.PostForm("document_type"), optionalPostForm(c, "title"),
+		optionalPostForm(c, "expires_on"), data, userID)
+	if err != nil {
+		utils.LogError(err, "AddStaffDocument: Error from documentService.AddDocument for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to add staff document.")
+		return
+	}
+	c.JSON(http.StatusCreated, document)
+}
+
+// GetStaffDocumentPhoto serves the photo of a document of a staff member.
+func (h *StaffDocumentHandler) GetStaffDocumentPhoto(c *gin.Context) {
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	documentID, ok := parseIncidentParam(c, "document_id", "document")
+	if !ok {
+		return
+	}
+	photo, err := h.documentService.GetDocumentPhoto(staffID, documentID)
+	if err != nil {
+		utils.LogError(err, "GetStaffDocumentPhoto: Error from documentService.GetDocumentPhoto for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch staff document.")
+		return
+	}
+	c.Header("Cache-Control", "no-store") // Personal documents must not linger in caches
+	c.Data(http.StatusOK, photo.ContentType, photo.Data)
+}
+
+// DeleteStaffDocument deletes a document of a staff member.
+func (h *StaffDocumentHandler) DeleteStaffDocument(c *gin.Context) {
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	documentID, ok := parseIncidentParam(c, "document_id", "document")
+	if !ok {
+		return
+	}
+	if err := h.documentService.DeleteDocument(staffID, documentID); err != nil {
+		utils.LogError(err, "DeleteStaffDocument: Error from documentService.DeleteDocument for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete staff document.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Staff document deleted successfully"})
+}
+
+// GetExpiringStaffDocuments reports the staff documents that expire within the next days
+// (default 30), including those already expired, unless a newer document replaced them.
+func (h *StaffDocumentHandler) GetExpiringStaffDocuments(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(services.DefaultStaffDocumentDays)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid days value.", err.Error()))
+		return
+	}
+	documents, err := h.documentService.GetExpiringDocuments(days)
+	if err != nil {
+		utils.LogError(err, "GetExpiringStaffDocuments: Error from documentService.GetExpiringDocuments")
+		respondWithServiceError(c, err, "Failed to fetch expiring staff documents.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": documents})
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	// "time" // Not directly used by handlers, service handles time parsing
 
+	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/services"
 	"ps_club_backend/pkg/utils"
 
@@ -27,24 +28,14 @@ func NewStaffHandler(ss services.StaffService) *StaffHandler {
 // CreateStaffMember handles the creation of a new staff member.
 func (h *StaffHandler) CreateStaffMember(c *gin.Context) {
 	var req services.CreateStaffMemberRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateStaffMember: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	staffMember, err := h.staffService.CreateStaffMember(req)
 	if err != nil {
 		utils.LogError(err, "CreateStaffMember: Error from staffService.CreateStaffMember")
-		if errors.Is(err, services.ErrUserForStaffNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "User specified for staff member not found.", err.Error()))
-		} else if errors.Is(err, services.ErrStaffUserConflict) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "User ID is already linked to another staff member.", err.Error()))
-		} else if errors.Is(err, services.ErrHireDateFormat) || errors.Is(err, services.ErrStaffDataValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create staff member.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to create staff member.")
 		return
 	}
 	c.JSON(http.StatusCreated, staffMember)
@@ -75,12 +66,7 @@ func (h *StaffHandler) GetStaffMembers(c *gin.Context) {
 	    staffMembers = []models.StaffMember{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  staffMembers,
-		"total": totalCount,
-		"page":  page,
-		"page_size": pageSize,
-	})
+	respondList(c, staffMembers, totalCount, page, pageSize)
 }
 
 // GetStaffMemberByID handles fetching a single staff member by ID.
@@ -95,17 +81,13 @@ func (h *StaffHandler) GetStaffMemberByID(c *gin.Context) {
 	staffMember, err := h.staffService.GetStaffMemberByID(staffID)
 	if err != nil {
 		utils.LogError(err, "GetStaffMemberByID: Error from staffService.GetStaffMemberByID for ID "+idStr)
-		if errors.Is(err, services.ErrStaffNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Staff member not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch staff member.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch staff member.")
 		return
 	}
 	c.JSON(http.StatusOK, staffMember)
 }
 
-// UpdateStaffMember handles updating a staff member.
+// UpdateStaffMember handles updating a staff member (PUT), or patching it with a JSON Merge Patch (PATCH).
 func (h *StaffHandler) UpdateStaffMember(c *gin.Context) {
 	idStr := c.Param("id")
 	staffID, err := strconv.ParseInt(idStr, 10, 64)
@@ -115,21 +97,19 @@ func (h *StaffHandler) UpdateStaffMember(c *gin.Context) {
 	}
 
 	var req services.UpdateStaffMemberRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdateStaffMember: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindUpdate(c, &req, services.StaffClearableFields, &req.Clear) {
 		return
 	}
 
+	req.CallerRole = c.GetString("userRole")
 	staffMember, err := h.staffService.UpdateStaffMember(staffID, req)
 	if err != nil {
 		utils.LogError(err, "UpdateStaffMember: Error from staffService.UpdateStaffMember for ID "+idStr)
-		if errors.Is(err, services.ErrStaffNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Staff member not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrHireDateFormat) || errors.Is(err, services.ErrStaffDataValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
+		var fieldErr *services.FieldPermissionError
+		if errors.As(err, &fieldErr) {
+			utils.RespondWithForbiddenFields(c, fieldErr.Fields)
 		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update staff member.", "Internal error"))
+			respondWithServiceError(c, err, "Failed to update staff member.")
 		}
 		return
 	}
@@ -148,13 +128,7 @@ func (h *StaffHandler) DeleteStaffMember(c *gin.Context) {
 	err = h.staffService.DeleteStaffMember(staffID)
 	if err != nil {
 		utils.LogError(err, "DeleteStaffMember: Error from staffService.DeleteStaffMember for ID "+idStr)
-		if errors.Is(err, services.ErrStaffNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Staff member not found to delete.", err.Error()))
-		} else if errors.Is(err, services.ErrStaffInUse) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Staff member cannot be deleted as they are referenced in other records.", err.Error()))
-		}else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete staff member.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete staff member.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Staff member deleted successfully"})
@@ -165,24 +139,14 @@ func (h *StaffHandler) DeleteStaffMember(c *gin.Context) {
 // CreateShift handles the creation of a new shift.
 func (h *StaffHandler) CreateShift(c *gin.Context) {
 	var req services.CreateShiftRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "CreateShift: Failed to bind JSON")
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	shift, err := h.staffService.CreateShift(req)
 	if err != nil {
 		utils.LogError(err, "CreateShift: Error from staffService.CreateShift")
-		if errors.Is(err, services.ErrStaffNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Staff member for shift not found.", err.Error()))
-		} else if errors.Is(err, services.ErrShiftTimeFormat) || errors.Is(err, services.ErrShiftValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrShiftOverlap) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Shift overlaps with an existing shift.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to create shift.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to create shift.")
 		return
 	}
 	c.JSON(http.StatusCreated, shift)
@@ -207,21 +171,15 @@ func (h *StaffHandler) GetShifts(c *gin.Context) {
 		}
 	}
 	
-	startTimeFromStr := c.Query("start_time_from")
-	startTimeToStr := c.Query("start_time_to")
-	var pStartTimeFrom, pStartTimeTo *string
-	if startTimeFromStr != "" { pStartTimeFrom = &startTimeFromStr }
-	if startTimeToStr != "" { pStartTimeTo = &startTimeToStr }
-
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
 
-	shifts, totalCount, err := h.staffService.GetShifts(staffID, pStartTimeFrom, pStartTimeTo, page, pageSize)
+	shifts, totalCount, err := h.staffService.GetShifts(staffID, timeRange.From, timeRange.To, page, pageSize)
 	if err != nil {
 		utils.LogError(err, "GetShifts: Error from staffService.GetShifts")
-		if errors.Is(err, services.ErrShiftTimeFormat) || errors.Is(err, services.ErrShiftValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed for time parameters: "+err.Error(), err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch shifts.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch shifts.")
 		return
 	}
 	
@@ -229,12 +187,7 @@ func (h *StaffHandler) GetShifts(c *gin.Context) {
 	    shifts = []models.Shift{}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  shifts,
-		"total": totalCount,
-		"page":  page,
-		"page_size": pageSize,
-	})
+	respondList(c, shifts, totalCount, page, pageSize)
 }
 
 // GetShiftByID handles fetching a single shift by ID.
@@ -249,11 +202,7 @@ func (h *StaffHandler) GetShiftByID(c *gin.Context) {
 	shift, err := h.staffService.GetShiftByID(shiftID)
 	if err != nil {
 		utils.LogError(err, "GetShiftByID: Error from staffService.GetShiftByID for ID "+idStr)
-		if errors.Is(err, services.ErrShiftNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Shift not found.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch shift.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to fetch shift.")
 		return
 	}
 	c.JSON(http.StatusOK, shift)
@@ -269,24 +218,14 @@ func (h *StaffHandler) UpdateShift(c *gin.Context) {
 	}
 
 	var req services.UpdateShiftRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.LogError(err, "UpdateShift: Failed to bind JSON for ID "+idStr)
-		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid request payload: "+err.Error(), err.Error()))
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	shift, err := h.staffService.UpdateShift(shiftID, req)
 	if err != nil {
 		utils.LogError(err, "UpdateShift: Error from staffService.UpdateShift for ID "+idStr)
-		if errors.Is(err, services.ErrShiftNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Shift not found to update.", err.Error()))
-		} else if errors.Is(err, services.ErrShiftTimeFormat) || errors.Is(err, services.ErrShiftValidation) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Validation failed: "+err.Error(), err.Error()))
-		} else if errors.Is(err, services.ErrShiftOverlap) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "Updated shift overlaps with an existing shift.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to update shift.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to update shift.")
 		return
 	}
 	c.JSON(http.StatusOK, shift)
@@ -304,11 +243,7 @@ func (h *StaffHandler) DeleteShift(c *gin.Context) {
 	err = h.staffService.DeleteShift(shiftID)
 	if err != nil {
 		utils.LogError(err, "DeleteShift: Error from staffService.DeleteShift for ID "+idStr)
-		if errors.Is(err, services.ErrShiftNotFound) {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, "Shift not found to delete.", err.Error()))
-		} else {
-			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to delete shift.", "Internal error"))
-		}
+		respondWithServiceError(c, err, "Failed to delete shift.")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Shift deleted successfully"})
@@ -320,3 +255,103 @@ func (h *StaffHandler) DeleteShift(c *gin.Context) {
 // ...etc...
 // func CreateShift(c *gin.Context) { ... }
 // ...etc...
+
+// --- Time Clock Handler Methods ---
+
+// ClockIn starts a time clock entry for the staff member of the current user. The optional body
+// carries the device coordinates, checked with the request's IP address against the clock_in setting.
+func (h *StaffHandler) ClockIn(c *gin.Context) {
+	userID, ok := currentUserID(c, "ClockIn")
+	if !ok {
+		return
+	}
+	var req services.ClockInRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	entry, err := h.staffService.ClockIn(userID, req)
+	if err != nil {
+		respondTimeClockError(c, "ClockIn", err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ClockOut ends the open time clock entry of the staff member of the current user and
+// returns it with its end-of-shift report.
+func (h *StaffHandler) ClockOut(c *gin.Context) {
+	userID, ok := currentUserID(c, "ClockOut")
+	if !ok {
+		return
+	}
+	// The body is optional: without it the report has no cash count or handover notes
+	var req services.ClockOutRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	entry, err := h.staffService.ClockOut(userID, req)
+	if err != nil {
+		respondTimeClockError(c, "ClockOut", err)
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetClockInViolations lists the clock-ins refused by the clock_in setting, latest first, optionally
+// of a staff_id, overridden or not, and attempted from and to (YYYY-MM-DD).
+func (h *StaffHandler) GetClockInViolations(c *gin.Context) {
+	var filters models.ClockInViolationFilters
+	if value := c.Query("staff_id"); value != "" {
+		staffID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id format.", err.Error()))
+			return
+		}
+		filters.StaffID = &staffID
+	}
+	if value := c.Query("overridden"); value != "" {
+		overridden, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid overridden value, expected true or false.", err.Error()))
+			return
+		}
+		filters.Overridden = &overridden
+	}
+	timeRange, ok := bindTimeRange(c)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = timeRange.From, timeRange.To
+
+	violations, err := h.staffService.GetClockInViolations(filters)
+	if err != nil {
+		utils.LogError(err, "GetClockInViolations: Error from staffService.GetClockInViolations")
+		respondWithServiceError(c, err, "Failed to fetch clock-in violations.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": violations})
+}
+
+// OverrideClockInViolation clocks the staff member of a refused clock-in in as of the attempt.
+func (h *StaffHandler) OverrideClockInViolation(c *gin.Context) {
+	userID, ok := currentUserID(c, "OverrideClockInViolation")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "clock-in violation")
+	if !ok {
+		return
+	}
+	violation, err := h.staffService.OverrideClockInViolation(id, userID)
+	if err != nil {
+		respondTimeClockError(c, "OverrideClockInViolation", err)
+		return
+	}
+	c.JSON(http.StatusOK, violation)
+}
+
+func respondTimeClockError(c *gin.Context, handlerName string, err error) {
+	utils.LogError(err, handlerName+": Error from staffService")
+	respondWithServiceError(c, err, "Failed to record time clock entry.")
+}
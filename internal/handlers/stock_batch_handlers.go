@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockBatchHandler holds the stock batch service.
+type StockBatchHandler struct {
+	stockBatchService services.StockBatchService
+}
+
+// NewStockBatchHandler creates a new StockBatchHandler.
+func NewStockBatchHandler(sbs services.StockBatchService) *StockBatchHandler {
+	return &StockBatchHandler{stockBatchService: sbs}
+}
+
+// GetStockBatches lists the stock batches first expiry first, optionally only those of an
+// item_id or, with in_stock=true, those with stock left.
+func (h *StockBatchHandler) GetStockBatches(c *gin.Context) {
+	var filters models.StockBatchFilters
+	if value := c.Query("item_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid item_id value.", err.Error()))
+			return
+		}
+		filters.ItemID = &id
+	}
+	if value := c.Query("in_stock"); value != "" {
+		inStock, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid in_stock value.", err.Error()))
+			return
+		}
+		filters.InStock = inStock
+	}
+
+	batches, err := h.stockBatchService.GetBatches(filters)
+	if err != nil {
+		utils.LogError(err, "GetStockBatches: Error from stockBatchService.GetBatches")
+		respondWithServiceError(c, err, "Failed to fetch stock batches.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
+
+// GetExpiringStock reports the batches in stock that expire within the next days (default 7),
+// including those already expired but not yet written off.
+func (h *StockBatchHandler) GetExpiringStock(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(services.DefaultExpiringDays)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid days value.", err.Error()))
+		return
+	}
+	batches, err := h.stockBatchService.GetExpiringBatches(days)
+	if err != nil {
+		utils.LogError(err, "GetExpiringStock: Error from stockBatchService.GetExpiringBatches")
+		respondWithServiceError(c, err, "Failed to fetch expiring stock.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
+
+// WriteOffExpired writes off the expired batches now, before the next scheduled write-off,
+// recording the spoilage movements as by the authenticated user.
+func (h *StockBatchHandler) WriteOffExpired(c *gin.Context) {
+	userID, ok := currentUserID(c, "WriteOffExpired")
+	if !ok {
+		return
+	}
+	batches, err := h.stockBatchService.WriteOffExpired(&userID)
+	if err != nil {
+		utils.LogError(err, "WriteOffExpired: Error from stockBatchService.WriteOffExpired")
+		respondWithServiceError(c, err, "Failed to write off expired stock batches.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
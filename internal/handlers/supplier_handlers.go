@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler holds the supplier service.
+type SupplierHandler struct {
+	supplierService services.SupplierService
+}
+
+// NewSupplierHandler creates a new SupplierHandler.
+func NewSupplierHandler(ss services.SupplierService) *SupplierHandler {
+	return &SupplierHandler{supplierService: ss}
+}
+
+// parseSupplierID parses the :id parameter, responding with an error if it is invalid.
+func parseSupplierID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid supplier ID format.", err.Error()))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateSupplier adds a supplier.
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req services.SupplierRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	supplier, err := h.supplierService.CreateSupplier(req)
+	if err != nil {
+		utils.LogError(err, "CreateSupplier: Error from supplierService.CreateSupplier")
+		respondWithServiceError(c, err, "Failed to create supplier.")
+		return
+	}
+	c.JSON(http.StatusCreated, supplier)
+}
+
+// GetSuppliers lists the suppliers by name.
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	suppliers, err := h.supplierService.GetSuppliers()
+	if err != nil {
+		utils.LogError(err, "GetSuppliers: Error from supplierService.GetSuppliers")
+		respondWithServiceError(c, err, "Failed to fetch suppliers.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": suppliers})
+}
+
+// GetSupplier returns a supplier.
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	id, ok := parseSupplierID(c)
+	if !ok {
+		return
+	}
+	supplier, err := h.supplierService.GetSupplier(id)
+	if err != nil {
+		utils.LogError(err, "GetSupplier: Error from supplierService.GetSupplier for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch supplier.")
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+// UpdateSupplier replaces the details of a supplier.
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	id, ok := parseSupplierID(c)
+	if !ok {
+		return
+	}
+	var req services.SupplierRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	supplier, err := h.supplierService.UpdateSupplier(id, req)
+	if err != nil {
+		utils.LogError(err, "UpdateSupplier: Error from supplierService.UpdateSupplier for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to update supplier.")
+		return
+	}
+	c.JSON(http.StatusOK, supplier)
+}
+
+// DeleteSupplier deletes a supplier with its price list.
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	id, ok := parseSupplierID(c)
+	if !ok {
+		return
+	}
+	if err := h.supplierService.DeleteSupplier(id); err != nil {
+		utils.LogError(err, "DeleteSupplier: Error from supplierService.DeleteSupplier for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to delete supplier.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Supplier deleted successfully"})
+}
+
+// GetPrices returns the price list of a supplier by item name.
+func (h *SupplierHandler) GetPrices(c *gin.Context) {
+	id, ok := parseSupplierID(c)
+	if !ok {
+		return
+	}
+	prices, err := h.supplierService.GetPrices(id)
+	if err != nil {
+		utils.LogError(err, "GetPrices: Error from supplierService.GetPrices for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch supplier prices.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": prices})
+}
+
+// ImportPrices adds or replaces prices of a supplier from the "file" CSV of a multipart form.
+// The lines that could not be imported are reported with the reason.
+func (h *SupplierHandler) ImportPrices(c *gin.Context) {
+	id, ok := parseSupplierID(c)
+	if !ok {
+		return
+	}
+	// Leave room for the multipart headers around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxSupplierPriceFileBytes+64<<10)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusRequestEntityTooLarge, utils.ErrCodeValidationFailed, "The price list is too large.", err.Error()))
+			return
+		}
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Upload the price list as the 'file' CSV of a multipart form.", err.Error()))
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		utils.LogError(err, "ImportPrices: Failed to open uploaded price list")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to read price list.", "Internal error"))
+		return
+	}
+	defer src.Close()
+
+	result, err := h.supplierService.ImportPrices(id, src)
+	if err != nil {
+		utils.LogError(err, "ImportPrices: Error from supplierService.ImportPrices for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to import supplier prices.")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetPurchaseSuggestions proposes what to buy for the items at or below their low stock
+// threshold: enough to cover cover_days (default 14) of the consumption over the last days
+// (default 30) above the threshold, from the cheapest supplier.
+func (h *SupplierHandler) GetPurchaseSuggestions(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(services.DefaultConsumptionDays)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid days value.", err.Error()))
+		return
+	}
+	coverDays, err := strconv.Atoi(c.DefaultQuery("cover_days", strconv.Itoa(services.DefaultCoverDays)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid cover_days value.", err.Error()))
+		return
+	}
+	suggestions, err := h.supplierService.GetPurchaseSuggestions(days, coverDays)
+	if err != nil {
+		utils.LogError(err, "GetPurchaseSuggestions: Error from supplierService.GetPurchaseSuggestions")
+		respondWithServiceError(c, err, "Failed to compute purchase suggestions.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncHandler holds the sync service.
+type SyncHandler struct {
+	syncService services.SyncService
+}
+
+// NewSyncHandler creates a new SyncHandler.
+func NewSyncHandler(ss services.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: ss}
+}
+
+// GetChanges returns the changes of the pricelist, game tables and clients after ?cursor= (0 or
+// none for everything), at most ?limit= (default 500).
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	cursor, err := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid cursor value.", err.Error()))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultSyncPullLimit)))
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid limit value.", err.Error()))
+		return
+	}
+	changes, err := h.syncService.GetChanges(cursor, limit)
+	if err != nil {
+		utils.LogError(err, "GetChanges: Error from syncService.GetChanges")
+		respondWithServiceError(c, err, "Failed to fetch changes.")
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// PushOrders creates the orders a POS took offline and responds with what became of each.
+func (h *SyncHandler) PushOrders(c *gin.Context) {
+	var req services.PushOrdersRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	results, err := h.syncService.PushOrders(req, c.GetString("userRole"))
+	if err != nil {
+		utils.LogError(err, "PushOrders: Error from syncService.PushOrders")
+		respondWithServiceError(c, err, "Failed to sync orders.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
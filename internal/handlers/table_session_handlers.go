@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TableSessionHandler holds the table session service and the WebSocket feed of session alerts.
+type TableSessionHandler struct {
+	sessionService services.TableSessionService
+	alerts         http.Handler
+}
+
+// NewTableSessionHandler creates a new TableSessionHandler. alerts serves the WebSocket
+// connections that receive the session events, see realtime.Hub.
+func NewTableSessionHandler(tss services.TableSessionService, alerts http.Handler) *TableSessionHandler {
+	return &TableSessionHandler{sessionService: tss, alerts: alerts}
+}
+
+// StartSession starts a session at a table, optionally with a prepaid time limit.
+func (h *TableSessionHandler) StartSession(c *gin.Context) {
+	userID, ok := currentUserID(c, "StartSession")
+	if !ok {
+		return
+	}
+	var req services.StartTableSessionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	session, err := h.sessionService.StartSession(req, userID)
+	if err != nil {
+		utils.LogError(err, "StartSession: Error from sessionService.StartSession")
+		respondWithServiceError(c, err, "Failed to start table session.")
+		return
+	}
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetRunningSessions lists the sessions that have not been stopped.
+func (h *TableSessionHandler) GetRunningSessions(c *gin.Context) {
+	sessions, err := h.sessionService.GetRunningSessions()
+	if err != nil {
+		utils.LogError(err, "GetRunningSessions: Error from sessionService.GetRunningSessions")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to fetch table sessions.", "Internal error"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// GetSession returns a table session by ID.
+func (h *TableSessionHandler) GetSession(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table session ID format.", err.Error()))
+		return
+	}
+	session, err := h.sessionService.GetSession(id)
+	if err != nil {
+		utils.LogError(err, "GetSession: Error from sessionService.GetSession for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to fetch table session.")
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// StopSession stops a running session and responds with its overtime charge, if any.
+func (h *TableSessionHandler) StopSession(c *gin.Context) {
+	userID, ok := currentUserID(c, "StopSession")
+	if !ok {
+		return
+	}
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table session ID format.", err.Error()))
+		return
+	}
+
+	session, err := h.sessionService.StopSession(id, userID)
+	if err != nil {
+		utils.LogError(err, "StopSession: Error from sessionService.StopSession for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to stop table session.")
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// GetSessionReceipt renders a plain-text receipt for the bill of a table session.
+func (h *TableSessionHandler) GetSessionReceipt(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table session ID format.", err.Error()))
+		return
+	}
+	receipt, err := h.sessionService.RenderReceipt(id)
+	if err != nil {
+		utils.LogError(err, "GetSessionReceipt: Error from sessionService.RenderReceipt for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to render receipt.")
+		return
+	}
+	c.String(http.StatusOK, receipt)
+}
+
+// RetryDepositCapture captures the card deposit of a stopped session again after the payment
+// provider refused it.
+func (h *TableSessionHandler) RetryDepositCapture(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid table session ID format.", err.Error()))
+		return
+	}
+	deposit, err := h.sessionService.RetryDepositCapture(id)
+	if err != nil {
+		utils.LogError(err, "RetryDepositCapture: Error from sessionService.RetryDepositCapture for ID "+idStr)
+		respondWithServiceError(c, err, "Failed to capture deposit.")
+		return
+	}
+	c.JSON(http.StatusOK, deposit)
+}
+
+// SessionAlerts upgrades to a WebSocket that receives the table session events as they
+// happen: time warnings, overtime and stops, and for Admins payments and refunds, as JSON
+// domain events. The hub authenticates the upgrade itself.
+func (h *TableSessionHandler) SessionAlerts(c *gin.Context) {
+	h.alerts.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeOffHandler holds the time off service.
+type TimeOffHandler struct {
+	timeOffService services.TimeOffService
+}
+
+// NewTimeOffHandler creates a new TimeOffHandler.
+func NewTimeOffHandler(tos services.TimeOffService) *TimeOffHandler {
+	return &TimeOffHandler{timeOffService: tos}
+}
+
+// timeOffCaller returns the authenticated user with their role.
+func timeOffCaller(c *gin.Context, handlerName string) (services.TimeOffCaller, bool) {
+	userID, ok := currentUserID(c, handlerName)
+	if !ok {
+		return services.TimeOffCaller{}, false
+	}
+	return services.TimeOffCaller{UserID: userID, Role: c.GetString("userRole")}, true
+}
+
+// RequestTimeOff records a pending time off request.
+func (h *TimeOffHandler) RequestTimeOff(c *gin.Context) {
+	caller, ok := timeOffCaller(c, "RequestTimeOff")
+	if !ok {
+		return
+	}
+	var req services.CreateTimeOffRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	request, err := h.timeOffService.RequestTimeOff(req, caller)
+	if err != nil {
+		utils.LogError(err, "RequestTimeOff: Error from timeOffService.RequestTimeOff")
+		respondWithServiceError(c, err, "Failed to request time off.")
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// GetTimeOffRequests lists the time off requests, optionally of a staff_id, of a status and
+// overlapping from and to (YYYY-MM-DD).
+func (h *TimeOffHandler) GetTimeOffRequests(c *gin.Context) {
+	caller, ok := timeOffCaller(c, "GetTimeOffRequests")
+	if !ok {
+		return
+	}
+	var filters models.TimeOffFilters
+	if staffIDStr := c.Query("staff_id"); staffIDStr != "" {
+		staffID, err := strconv.ParseInt(staffIDStr, 10, 64)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid staff_id format.", err.Error()))
+			return
+		}
+		filters.StaffID = &staffID
+	}
+	if status := c.Query("status"); status != "" {
+		filters.Status = &status
+	}
+	if from := c.Query("from"); from != "" {
+		filters.From = &from
+	}
+	if to := c.Query("to"); to != "" {
+		filters.To = &to
+	}
+	requests, err := h.timeOffService.GetRequests(filters, caller)
+	if err != nil {
+		utils.LogError(err, "GetTimeOffRequests: Error from timeOffService.GetRequests")
+		respondWithServiceError(c, err, "Failed to fetch time off requests.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": requests})
+}
+
+// GetTimeOffRequest returns a time off request with the shifts scheduled during it.
+func (h *TimeOffHandler) GetTimeOffRequest(c *gin.Context) {
+	caller, ok := timeOffCaller(c, "GetTimeOffRequest")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "time off request")
+	if !ok {
+		return
+	}
+	request, err := h.timeOffService.GetRequest(id, caller)
+	if err != nil {
+		utils.LogError(err, "GetTimeOffRequest: Error from timeOffService.GetRequest for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch time off request.")
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// decideTimeOff approves or rejects a pending time off request with the optional note of the body.
+func (h *TimeOffHandler) decideTimeOff(c *gin.Context, handlerName string,
+	decide func(id int64, adminUserID int64, note *string) (*models.TimeOffRequest, error)) {
+	userID, ok := currentUserID(c, handlerName)
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "time off request")
+	if !ok {
+		return
+	}
+	var req services.DecideTimeOffRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+	request, err := decide(id, userID, req.Note)
+	if err != nil {
+		utils.LogError(err, handlerName+": Error deciding time off request ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to decide time off request.")
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveTimeOff approves a pending time off request; the response lists the shifts to reassign.
+func (h *TimeOffHandler) ApproveTimeOff(c *gin.Context) {
+	h.decideTimeOff(c, "ApproveTimeOff", h.timeOffService.Approve)
+}
+
+// RejectTimeOff rejects a pending time off request.
+func (h *TimeOffHandler) RejectTimeOff(c *gin.Context) {
+	h.decideTimeOff(c, "RejectTimeOff", h.timeOffService.Reject)
+}
+
+// CancelTimeOff withdraws a time off request.
+func (h *TimeOffHandler) CancelTimeOff(c *gin.Context) {
+	caller, ok := timeOffCaller(c, "CancelTimeOff")
+	if !ok {
+		return
+	}
+	id, ok := parseIncidentParam(c, "id", "time off request")
+	if !ok {
+		return
+	}
+	request, err := h.timeOffService.Cancel(id, caller)
+	if err != nil {
+		utils.LogError(err, "CancelTimeOff: Error from timeOffService.Cancel for ID "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to cancel time off request.")
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// GetStaffAvailability returns the weekly availability windows of a staff member.
+func (h *TimeOffHandler) GetStaffAvailability(c *gin.Context) {
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	windows, err := h.timeOffService.GetAvailability(staffID)
+	if err != nil {
+		utils.LogError(err, "GetStaffAvailability: Error from timeOffService.GetAvailability for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to fetch staff availability.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"staff_id": staffID, "windows": windows})
+}
+
+// SetStaffAvailability replaces the weekly availability windows of a staff member.
+func (h *TimeOffHandler) SetStaffAvailability(c *gin.Context) {
+	caller, ok := timeOffCaller(c, "SetStaffAvailability")
+	if !ok {
+		return
+	}
+	staffID, ok := parseIncidentParam(c, "id", "staff")
+	if !ok {
+		return
+	}
+	var req services.SetAvailabilityRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	windows, err := h.timeOffService.SetAvailability(staffID, req, caller)
+	if err != nil {
+		utils.LogError(err, "SetStaffAvailability: Error from timeOffService.SetAvailability for staff "+c.Param("id"))
+		respondWithServiceError(c, err, "Failed to set staff availability.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"staff_id": staffID, "windows": windows})
+}
+
+// GetDayAvailability returns who is off and the availability windows of each staff member
+// on a date (YYYY-MM-DD, default today), for scheduling shifts.
+func (h *TimeOffHandler) GetDayAvailability(c *gin.Context) {
+	date := c.DefaultQuery("date", utils.NowInClub().Format(utils.DateLayout))
+	days, err := h.timeOffService.GetDayAvailability(date)
+	if err != nil {
+		utils.LogError(err, "GetDayAvailability: Error from timeOffService.GetDayAvailability")
+		respondWithServiceError(c, err, "Failed to fetch staff availability.")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"date": date, "data": days})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Names the from and to query parameters had before the time range was shared, still
+// accepted so existing clients keep working. from and to take precedence.
+var (
+	timeRangeFromAliases = []string{"date_from", "start_date", "start_time_from"}
+	timeRangeToAliases   = []string{"date_to", "end_date", "start_time_to"}
+)
+
+// bindTimeRange reads the time range of a list or report query (see timeRangeFromQuery). If
+// the range is invalid it responds with 400 and returns false.
+func bindTimeRange(c *gin.Context) (utils.TimeRange, bool) {
+	timeRange, err := timeRangeFromQuery(c.Request.URL.Query())
+	if err != nil {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, "Invalid time range: "+err.Error(), err.Error()))
+		return utils.TimeRange{}, false
+	}
+	return timeRange, true
+}
+
+// timeRangeFromQuery reads from and to, or a range preset such as range=this_week instead
+// (see utils.ParseTimeRange). date=YYYY-MM-DD is the range of that day.
+func timeRangeFromQuery(query url.Values) (utils.TimeRange, error) {
+	from := queryWithAliases(query, "from", timeRangeFromAliases)
+	to := queryWithAliases(query, "to", timeRangeToAliases)
+	if date := query.Get("date"); date != "" && from == "" && to == "" {
+		from, to = date, date
+	}
+	return utils.ParseTimeRange(from, to, query.Get("range"))
+}
+
+// timeRangeFromPeriod reads a time range given in a single query parameter: a range preset,
+// a date for that day, or from and to joined by "..", e.g. 2024-06-01..2024-06-07.
+func timeRangeFromPeriod(value string) (utils.TimeRange, error) {
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		return utils.ParseTimeRange(from, to, "")
+	}
+	if utils.IsValidDate(strings.TrimSpace(value)) {
+		return utils.ParseTimeRange(value, value, "")
+	}
+	return utils.ParseTimeRange("", "", value)
+}
+
+// queryWithAliases returns the query parameter name, or the first of its aliases set.
+func queryWithAliases(query url.Values, name string, aliases []string) string {
+	if value := query.Get(name); value != "" {
+		return value
+	}
+	for _, alias := range aliases {
+		if value := query.Get(alias); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeRangeFromQuery(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		wantFrom, wantTo string
+	}{
+		{name: "no range", query: ""},
+		{name: "from and to", query: "from=2024-06-01&to=2024-06-30", wantFrom: "2024-06-01", wantTo: "2024-06-30"},
+		{name: "date_from and date_to", query: "date_from=2024-06-01&date_to=2024-06-30", wantFrom: "2024-06-01", wantTo: "2024-06-30"},
+		{name: "start_date and end_date", query: "start_date=2024-06-01&end_date=2024-06-30", wantFrom: "2024-06-01", wantTo: "2024-06-30"},
+		{name: "start_time_from and start_time_to", query: "start_time_from=2024-06-01&start_time_to=2024-06-30", wantFrom: "2024-06-01", wantTo: "2024-06-30"},
+		{name: "from wins over its aliases", query: "from=2024-06-10&date_from=2024-06-01&to=2024-06-30", wantFrom: "2024-06-10", wantTo: "2024-06-30"},
+		{name: "date is that day", query: "date=2024-06-15", wantFrom: "2024-06-15", wantTo: "2024-06-15"},
+		{name: "date gives way to from", query: "date=2024-06-15&from=2024-06-01", wantFrom: "2024-06-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			r, err := timeRangeFromQuery(query)
+			if err != nil {
+				t.Fatalf("timeRangeFromQuery(%q): %v", tt.query, err)
+			}
+			if from, to := r.ClubDates(); from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("timeRangeFromQuery(%q) = %q..%q, want %q..%q", tt.query, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestTimeRangeFromQueryPresets(t *testing.T) {
+	query, _ := url.ParseQuery("range=" + utils.RangeToday)
+	r, err := timeRangeFromQuery(query)
+	if err != nil {
+		t.Fatalf("range=today: %v", err)
+	}
+	today := utils.FormatClubTime(time.Now(), utils.DateLayout)
+	if from, to := r.ClubDates(); from != today || to != today {
+		t.Errorf("range=today = %q..%q, want %q", from, to, today)
+	}
+}
+
+func TestBindTimeRangeRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/orders", func(c *gin.Context) {
+		if _, ok := bindTimeRange(c); ok {
+			c.Status(http.StatusOK)
+		}
+	})
+
+	for _, query := range []string{
+		"from=2024-06-30&to=2024-06-01",           // Reversed
+		"date_from=2024-06-30&date_to=2024-06-01", // Reversed through the aliases
+		"from=30.06.2024",
+		"to=2024-13-01",
+		"range=next_week",
+		"range=today&from=2024-06-01",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET /orders?%s = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestTimeRangeFromPeriod(t *testing.T) {
+	tests := []struct {
+		period           string
+		wantFrom, wantTo string
+		wantErr          bool
+	}{
+		{period: "2024-06-01..2024-06-07", wantFrom: "2024-06-01", wantTo: "2024-06-07"},
+		{period: "2024-06-01", wantFrom: "2024-06-01", wantTo: "2024-06-01"},
+		{period: " 2024-06-01 ", wantFrom: "2024-06-01", wantTo: "2024-06-01"},
+		{period: "2024-06-07..2024-06-01", wantErr: true},
+		{period: "2024-06-01..", wantFrom: "2024-06-01"},
+		{period: "June", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			r, err := timeRangeFromPeriod(tt.period)
+			if tt.wantErr {
+				if !errors.Is(err, utils.ErrInvalidTimeRange) {
+					t.Errorf("timeRangeFromPeriod(%q) error = %v, want %v", tt.period, err, utils.ErrInvalidTimeRange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("timeRangeFromPeriod(%q): %v", tt.period, err)
+			}
+			if from, to := r.ClubDates(); from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("timeRangeFromPeriod(%q) = %q..%q, want %q..%q", tt.period, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package kvstore
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store for a single API instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+
+	subMu       sync.Mutex
+	subscribers map[string]map[chan string]struct{}
+}
+
+type memoryEntry struct {
+	value   string
+	expires time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store. Expired keys are removed lazily.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), subscribers: make(map[string]map[chan string]struct{})}
+}
+
+// get returns the live entry for key; the caller must hold mu.
+func (s *MemoryStore) get(key string) (memoryEntry, bool) {
+	e, ok := s.entries[key]
+	if ok && !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return e, ok
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// Get returns the value of key and whether it exists.
+func (s *MemoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.get(key)
+	return e.value, ok, nil
+}
+
+// Set stores value under key for ttl.
+func (s *MemoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{value: value, expires: expiry(ttl)}
+	return nil
+}
+
+// SetNX stores value under key for ttl unless the key exists, and reports whether it did.
+func (s *MemoryStore) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{value: value, expires: expiry(ttl)}
+	return true, nil
+}
+
+// Delete removes key.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// Incr increments the counter at key and returns the new value. A new counter expires after ttl.
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.get(key)
+	if !ok {
+		e = memoryEntry{value: "0", expires: expiry(ttl)}
+	}
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	n++
+	e.value = strconv.FormatInt(n, 10)
+	s.entries[key] = e
+	return n, nil
+}
+
+// Lock acquires the lock on key, waiting until ctx is done.
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	token := newLockToken()
+	err := acquire(ctx, func() (bool, error) {
+		return s.SetNX(ctx, key, token, ttl)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// The lock may have expired and been taken by someone else
+		if e, ok := s.get(key); ok && e.value == token {
+			delete(s.entries, key)
+		}
+	}, nil
+}
+
+// Publish sends message to the current subscribers of channel, skipping those that are full.
+func (s *MemoryStore) Publish(_ context.Context, channel, message string) error {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for sub := range s.subscribers[channel] {
+		select {
+		case sub <- message:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe delivers the messages published to channel until ctx is done.
+func (s *MemoryStore) Subscribe(ctx context.Context, channel string) <-chan string {
+	sub := make(chan string, subscriberBuffer)
+	s.subMu.Lock()
+	if s.subscribers[channel] == nil {
+		s.subscribers[channel] = make(map[chan string]struct{})
+	}
+	s.subscribers[channel][sub] = struct{}{}
+	s.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		delete(s.subscribers[channel], sub)
+		close(sub)
+	}()
+	return sub
+}
@@ -0,0 +1,144 @@
+package kvstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a Store shared by all API instances through Redis.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore connects to the Redis server at url (redis://[:password@]host:port/db)
+// and checks that it is reachable.
+func NewRedisStore(ctx context.Context, url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connecting to Redis: %w", err)
+	}
+	return &RedisStore{client: client}, nil
+}
+
+// NewRedisStoreFromClient wraps an existing client.
+func NewRedisStoreFromClient(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Close closes the connection to Redis.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Get returns the value of key and whether it exists.
+func (s *RedisStore) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key for ttl.
+func (s *RedisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// SetNX stores value under key for ttl unless the key exists, and reports whether it did.
+func (s *RedisStore) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+// Delete removes key.
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// incrScript increments a counter and sets its expiry when it is created, atomically.
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// Incr increments the counter at key and returns the new value. A new counter expires after ttl.
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, s.client, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+// unlockScript deletes a lock only if it is still held with the given token.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lock acquires the lock on key, waiting until ctx is done.
+func (s *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	token := newLockToken()
+	err := acquire(ctx, func() (bool, error) {
+		return s.client.SetNX(ctx, key, token, ttl).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		// Release with a fresh context: the caller's may already be cancelled
+		_ = unlockScript.Run(context.Background(), s.client, []string{key}, token).Err()
+	}, nil
+}
+
+// Publish sends message to the subscribers of channel on every instance.
+func (s *RedisStore) Publish(ctx context.Context, channel, message string) error {
+	return s.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe delivers the messages published to channel until ctx is done.
+func (s *RedisStore) Subscribe(ctx context.Context, channel string) <-chan string {
+	pubsub := s.client.Subscribe(ctx, channel)
+	messages := pubsub.Channel(redis.WithChannelSize(subscriberBuffer))
+	sub := make(chan string, subscriberBuffer)
+	go func() {
+		defer close(sub)
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case sub <- msg.Payload:
+				default:
+				}
+			}
+		}
+	}()
+	return sub
+}
+
+// newLockToken identifies a lock holder, so only it can release the lock.
+func newLockToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,74 @@
+// Package kvstore holds state that must be shared by every API instance: the
+// refresh-token revocation list, rate-limiter counters, idempotency keys,
+// distributed locks and the messages broadcast to every instance. A single instance can use the in-memory store; instances
+// running behind a load balancer must share a Redis store.
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLockTimeout is returned by Lock when the lock could not be acquired before the context was done.
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// Locker acquires exclusive locks across instances.
+type Locker interface {
+	// Lock acquires the lock on key, waiting until ctx is done. The lock expires after
+	// ttl if it is not released, so a crashed instance cannot hold it forever.
+	// The returned function releases the lock.
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), err error)
+}
+
+// Broadcaster delivers messages to the subscribers of a channel on every instance.
+type Broadcaster interface {
+	// Publish sends message to the current subscribers of channel. Messages are not
+	// stored: subscribers that join later do not receive them.
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe delivers the messages published to channel until ctx is done, then
+	// closes the returned channel. A subscriber that falls behind may miss messages.
+	Subscribe(ctx context.Context, channel string) <-chan string
+}
+
+// Store is a key-value store with expiring keys.
+type Store interface {
+	Locker
+	Broadcaster
+	// Get returns the value of key and whether it exists.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// SetNX stores value under key for ttl unless the key exists, and reports whether it did.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+	// Incr increments the counter at key and returns the new value. A new counter expires after ttl.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// subscriberBuffer is how many messages a subscriber may fall behind before messages are dropped.
+const subscriberBuffer = 64
+
+// lockRetryInterval is how often a waiting Lock retries.
+const lockRetryInterval = 25 * time.Millisecond
+
+// acquire retries try until it succeeds, fails, or ctx is done.
+func acquire(ctx context.Context, try func() (bool, error)) error {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := try()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ErrLockTimeout
+		case <-ticker.C:
+		}
+	}
+}
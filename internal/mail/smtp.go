@@ -0,0 +1,114 @@
+// Package mail sends plain-text email through an SMTP server. services.ShiftReportService
+// calls it to email the end-of-shift reports to the Admins.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPort is the SMTP submission port, used when SMTPSender.Port is 0.
+const DefaultPort = 587
+
+// SMTPSender sends each message through Host. With Username set it authenticates with
+// PLAIN auth, which net/smtp only allows over TLS (STARTTLS) or to localhost.
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration // Of connecting to the server
+}
+
+// NewSMTPSender creates an SMTPSender; port 0 means DefaultPort.
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	if port == 0 {
+		port = DefaultPort
+	}
+	return &SMTPSender{Host: host, Port: port, Username: username, Password: password, From: from, Timeout: 10 * time.Second}
+}
+
+// Send emails a plain-text message to the recipients.
+func (s *SMTPSender) Send(ctx context.Context, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return nil
+	}
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("starting SMTP session with %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if err := s.deliver(client, to, buildMessage(s.From, to, subject, body)); err != nil {
+		return fmt.Errorf("sending email via %s: %w", addr, err)
+	}
+	return client.Quit()
+}
+
+// deliver runs the SMTP transaction of one message on client.
+func (s *SMTPSender) deliver(client *smtp.Client, to []string, msg []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// buildMessage formats a plain-text UTF-8 message with CRLF line endings; the DATA writer
+// of net/smtp dot-stuffs it.
+func buildMessage(from string, to []string, subject, body string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader carries the API key of devices without a user login, such as the kiosk.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth admits requests carrying an active API key with scope in the X-API-Key
+// header, and sets the key's ID in the context as "apiKeyID" and the scope it was admitted
+// with as "apiKeyScope".
+func APIKeyAuth(apiKeyService services.APIKeyService, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusUnauthorized, utils.ErrCodeUnauthorized, APIKeyHeader+" header required.", ""))
+			return
+		}
+
+		apiKey, err := apiKeyService.AuthenticateAPIKey(key, scope)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidAPIKey):
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusUnauthorized, utils.ErrCodeUnauthorized, "Invalid or revoked API key.", ""))
+			case errors.Is(err, services.ErrAPIKeyScope):
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusForbidden, utils.ErrCodeForbidden, "The API key may not call this route.", "requires scope "+scope))
+			default:
+				utils.LogError(err, "APIKeyAuth: failed to authenticate API key")
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to authenticate API key.", "Internal error"))
+			}
+			return
+		}
+
+		c.Set("apiKeyID", apiKey.ID)
+		c.Set("apiKeyScope", scope)
+		c.Next()
+	}
+}
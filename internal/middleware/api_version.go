@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions mounted by the router. Both share the same services; handlers
+// that changed shape between versions check APIVersionFromContext.
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+)
+
+const apiVersionKey = "apiVersion"
+
+// APIVersion tags every request of a route group with its API version.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// APIVersionFromContext returns the API version of the request, v1 if the route is not versioned.
+func APIVersionFromContext(c *gin.Context) string {
+	if version, ok := c.Get(apiVersionKey); ok {
+		if s, ok := version.(string); ok {
+			return s
+		}
+	}
+	return APIVersion1
+}
+
+var (
+	v1Sunset   *time.Time
+	v1SunsetMu sync.RWMutex
+)
+
+// SetV1Sunset sets the date after which v1 may be removed; nil stops announcing the deprecation.
+func SetV1Sunset(sunset *time.Time) {
+	v1SunsetMu.Lock()
+	defer v1SunsetMu.Unlock()
+	v1Sunset = sunset
+}
+
+// V1Sunset returns the configured v1 sunset date, or nil.
+func V1Sunset() *time.Time {
+	v1SunsetMu.RLock()
+	defer v1SunsetMu.RUnlock()
+	return v1Sunset
+}
+
+// DeprecatedAPI announces the deprecation of a route group once a sunset date is
+// configured, using the Deprecation, Sunset (RFC 8594) and Link headers.
+// successorPath is the base path of the replacing version, e.g. "/api/v2".
+func DeprecatedAPI(successorPath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sunset := V1Sunset(); sunset != nil {
+			c.Header("Deprecation", "true")
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+			c.Header("Link", "<"+successorPath+">; rel=\"successor-version\"")
+		}
+		c.Next()
+	}
+}
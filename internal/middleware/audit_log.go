@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLog records the requests that change something, and every request made with an
+// impersonation token, in the audit log once they are handled. Entries made while
+// impersonating carry the Admin as impersonator. Failing to record an entry is logged
+// but does not fail the request. It must run after AuthMiddleware.
+func AuditLog(auditLogService services.AuditLogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		impersonation := ImpersonationFromContext(c)
+		if impersonation == nil && isReadOnlyMethod(c.Request.Method) {
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			return // No route matched
+		}
+
+		status := c.Writer.Status()
+		entry := &models.AuditLogEntry{
+			Action:     c.Request.Method + " " + route,
+			Method:     &c.Request.Method,
+			Path:       &c.Request.URL.Path,
+			StatusCode: &status,
+			IPAddress:  utils.NewNullString(c.ClientIP()),
+			CreatedAt:  utils.NowUTC(),
+		}
+		if userID, ok := c.Get("userID"); ok {
+			if id, ok := userID.(int64); ok {
+				entry.UserID = &id
+			}
+		}
+		if impersonation != nil {
+			entry.ImpersonatorID = &impersonation.ImpersonatorID
+		}
+		if err := auditLogService.RecordEntry(entry); err != nil {
+			utils.LogError(err, "AuditLog: failed to record "+entry.Action)
+		}
+	}
+}
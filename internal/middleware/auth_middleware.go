@@ -1,16 +1,62 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/realtime"
 	"ps_club_backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware creates a Gin middleware for JWT authentication.
-func AuthMiddleware() gin.HandlerFunc {
+// ErrSessionRevoked is returned by AuthenticateAccessToken for a token of a revoked session.
+var ErrSessionRevoked = errors.New("session has been revoked, please log in again")
+
+// AuthenticateAccessToken validates an access token and returns its claims. Tokens of a
+// session revoked through the shared store are rejected; if the store is unavailable,
+// valid tokens are let through.
+func AuthenticateAccessToken(ctx context.Context, store kvstore.Store, token string) (*utils.Claims, error) {
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SessionID != 0 {
+		_, revoked, err := store.Get(ctx, utils.RevokedSessionKey(claims.SessionID))
+		if err != nil {
+			utils.LogError(err, "AuthenticateAccessToken: failed to check session revocation, allowing the token")
+		} else if revoked {
+			return nil, ErrSessionRevoked
+		}
+	}
+	return claims, nil
+}
+
+// RealtimeAuthenticator authenticates the WebSocket connections of the realtime hubs, and
+// their token refreshes, like AuthMiddleware.
+func RealtimeAuthenticator(store kvstore.Store) realtime.Authenticator {
+	return func(ctx context.Context, token string) (realtime.Principal, error) {
+		claims, err := AuthenticateAccessToken(ctx, store, token)
+		if err != nil {
+			return realtime.Principal{}, err
+		}
+		principal := realtime.Principal{UserID: claims.UserID, Role: claims.Role}
+		if claims.ExpiresAt != nil {
+			principal.ExpiresAt = claims.ExpiresAt.Time
+		}
+		return principal, nil
+	}
+}
+
+// AuthMiddleware creates a Gin middleware for JWT authentication. Tokens of a
+// session revoked through the shared store are rejected; if the store is
+// unavailable, valid tokens are let through.
+func AuthMiddleware(store kvstore.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,7 +73,12 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		claims, err := utils.ValidateToken(tokenString)
+		claims, err := AuthenticateAccessToken(c.Request.Context(), store, tokenString)
+		if errors.Is(err, ErrSessionRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked, please log in again"})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token: " + err.Error()})
 			c.Abort()
@@ -38,13 +89,53 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("userRole", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		c.Set(utils.LanguageContextKey, claims.Language)
+		if claims.ImpersonatorID != 0 {
+			impersonation := &models.Impersonation{
+				ImpersonatorID:       claims.ImpersonatorID,
+				ImpersonatorUsername: claims.ImpersonatorName,
+				Banner:               fmt.Sprintf("%s is acting as %s; everything done is audited.", claims.ImpersonatorName, claims.Username),
+			}
+			if claims.ExpiresAt != nil {
+				impersonation.ExpiresAt = claims.ExpiresAt.Time
+			}
+			c.Set(impersonationKey, impersonation)
+		}
 
 		c.Next()
 	}
 }
 
+const impersonationKey = "impersonation"
+
+// ImpersonationFromContext returns the Admin acting as the authenticated user with an
+// impersonation token, or nil if the user is not impersonated.
+func ImpersonationFromContext(c *gin.Context) *models.Impersonation {
+	if impersonation, ok := c.Get(impersonationKey); ok {
+		if i, ok := impersonation.(*models.Impersonation); ok {
+			return i
+		}
+	}
+	return nil
+}
+
+// RoleAnalyst is the built-in read-only role: RoleAuthMiddleware admits it to the routes
+// listing it for GET and HEAD requests only.
+const RoleAnalyst = "Analyst"
+
+// RoleClient is the role of clients booking and ordering for themselves through the client
+// app; what they create is recorded with the client_app source.
+const RoleClient = "Client"
+
+// isReadOnlyMethod reports whether an HTTP method only reads.
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
 // RoleAuthMiddleware creates a Gin middleware for role-based authorization.
-// It checks if the user role (from JWT claims) is one of the allowed roles.
+// It checks if the user role (from JWT claims) is one of the allowed roles. The
+// read-only RoleAnalyst is only allowed GET and HEAD requests, even where listed.
 func RoleAuthMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("userRole")
@@ -74,6 +165,11 @@ func RoleAuthMiddleware(allowedRoles ...string) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if strings.EqualFold(roleStr, RoleAnalyst) && !isReadOnlyMethod(c.Request.Method) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The Analyst role has read-only access"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldMasker masks the value of a JSON field.
+type fieldMasker func(value interface{}) interface{}
+
+// maskString masks string values with mask and hides other non-null values.
+func maskString(mask func(string) string) fieldMasker {
+	return func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			return mask(s)
+		}
+		return hideValue(value)
+	}
+}
+
+// hideValue replaces a value by null; for amounts, which cannot be partly masked.
+func hideValue(interface{}) interface{} {
+	return nil
+}
+
+// maskedFields lists the JSON fields masked in responses to users whose role lacks a
+// permission, by permission. Field names of the REST API and of GraphQL are both listed.
+var maskedFields = map[string]map[string]fieldMasker{
+	models.PermissionViewPII: {
+		"phone_number": maskString(utils.MaskPhone),
+		"phoneNumber":  maskString(utils.MaskPhone),
+		"email":        maskString(utils.MaskEmail),
+	},
+	models.PermissionViewSalaries: {
+		"salary":        hideValue,
+		"net_pay":       hideValue,
+		"total_salary":  hideValue,
+		"total_net_pay": hideValue,
+	},
+}
+
+// maskingRecorder holds back a JSON response body so it can be masked before it is sent.
+// Other responses, such as files and server-sent events, are written through as they come,
+// and so is everything once the connection is hijacked for a WebSocket.
+type maskingRecorder struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	decided     bool // Whether the Content-Type was looked at, on the first write or flush
+	passThrough bool
+	hijacked    bool
+}
+
+// buffering reports whether the body is held back, deciding it by the Content-Type set
+// when the handler first writes or flushes.
+func (w *maskingRecorder) buffering() bool {
+	if !w.decided {
+		w.decided = true
+		w.passThrough = !isJSONContentType(w.Header().Get("Content-Type"))
+	}
+	return !w.passThrough && !w.hijacked
+}
+
+func (w *maskingRecorder) Write(b []byte) (int, error) {
+	if !w.buffering() {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *maskingRecorder) WriteString(s string) (int, error) {
+	if !w.buffering() {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// Flush sends what was written so far, unless the body is held back to be masked.
+func (w *maskingRecorder) Flush() {
+	if !w.buffering() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *maskingRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
+
+// isJSONContentType reports whether contentType is application/json or a +json type.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// MaskFields masks the fields of maskedFields, at any depth of JSON responses, for users
+// whose role lacks the permission to see them. Fields are masked in every JSON response, so
+// handlers need not take care of them; other responses, streams and WebSockets are left
+// alone. If the permissions of the role cannot be loaded, every listed field is masked.
+// It must run after AuthMiddleware.
+func MaskFields(permissionService services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maskers := map[string]fieldMasker{}
+		permissions, err := permissionService.RolePermissions(c.GetString("userRole"))
+		if err != nil {
+			utils.LogError(err, "MaskFields: failed to get role permissions, masking all fields")
+		}
+		for permission, fields := range maskedFields {
+			if permissions[permission] {
+				continue
+			}
+			for field, mask := range fields {
+				maskers[field] = mask
+			}
+		}
+		if len(maskers) == 0 {
+			c.Next()
+			return
+		}
+
+		recorder := &maskingRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+		if recorder.hijacked || recorder.body.Len() == 0 {
+			return
+		}
+
+		body := recorder.body.Bytes()
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber() // Keep amounts and IDs exactly as sent
+		if err := decoder.Decode(&value); err == nil {
+			if maskedBody, err := json.Marshal(maskValue(value, maskers)); err == nil {
+				body = maskedBody
+				c.Writer.Header().Del("Content-Length")
+			}
+		}
+		if _, err := c.Writer.Write(body); err != nil {
+			utils.LogError(err, "MaskFields: failed to write the response")
+		}
+	}
+}
+
+// maskValue masks the fields of a decoded JSON value in place and returns it.
+func maskValue(value interface{}, maskers map[string]fieldMasker) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if mask, ok := maskers[key]; ok && field != nil {
+				v[key] = mask(field)
+				continue
+			}
+			v[key] = maskValue(field, maskers)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = maskValue(v[i], maskers)
+		}
+	}
+	return value
+}
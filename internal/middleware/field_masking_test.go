@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// rolePermissions grants the permissions listed for each role.
+type rolePermissions map[string][]string
+
+func (p rolePermissions) RolePermissions(role string) (map[string]bool, error) {
+	granted := map[string]bool{}
+	for _, permission := range p[role] {
+		granted[permission] = true
+	}
+	return granted, nil
+}
+
+const testPhone = "+77011234567"
+
+// newMaskingRouter returns a router that masks fields for the role in the X-Test-Role header.
+// Admins may see personal data and salaries, Staff neither.
+func newMaskingRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userRole", c.GetHeader("X-Test-Role"))
+	})
+	r.Use(MaskFields(rolePermissions{"Admin": {models.PermissionViewPII, models.PermissionViewSalaries}}))
+	return r
+}
+
+func TestMaskFieldsJSON(t *testing.T) {
+	r := newMaskingRouter()
+	r.GET("/staff", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": []gin.H{{"id": 12345678901234567, "phone_number": testPhone, "salary": 250000.5}}})
+	})
+
+	tests := []struct {
+		role       string
+		wantMasked bool
+	}{
+		{role: "Staff", wantMasked: true},
+		{role: "Admin", wantMasked: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/staff", nil)
+			req.Header.Set("X-Test-Role", tt.role)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var body struct {
+				Data []map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Data) != 1 {
+				t.Fatalf("decoding %s: %v", w.Body, err)
+			}
+			staff := body.Data[0]
+			if got := string(staff["id"]); got != "12345678901234567" {
+				t.Errorf("id = %s, want it unchanged", got)
+			}
+			phoneMasked := string(staff["phone_number"]) != `"`+testPhone+`"`
+			salaryHidden := string(staff["salary"]) == "null"
+			if phoneMasked != tt.wantMasked || salaryHidden != tt.wantMasked {
+				t.Errorf("phone_number = %s, salary = %s; want masked %v", staff["phone_number"], staff["salary"], tt.wantMasked)
+			}
+		})
+	}
+}
+
+func TestMaskFieldsLeavesOtherContentTypes(t *testing.T) {
+	r := newMaskingRouter()
+	csv := "name,phone_number\nAli," + testPhone + "\n"
+	r.GET("/export", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(csv))
+	})
+	// JSON sent as a file is passed through as well
+	document := `{"phone_number": "` + testPhone + `"}`
+	r.GET("/download", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/octet-stream", []byte(document))
+	})
+
+	for path, want := range map[string]string{"/export": csv, "/download": document} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Test-Role", "Staff")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != want {
+			t.Errorf("GET %s body = %q, want %q", path, w.Body, want)
+		}
+	}
+}
+
+func TestMaskFieldsStreamsServerSentEvents(t *testing.T) {
+	r := newMaskingRouter()
+	received := make(chan struct{})
+	r.GET("/events", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("data: {\"phone_number\": \"" + testPhone + "\"}\n\n")
+		c.Writer.Flush()
+		// The handler keeps the stream open until the client got the first event
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+		}
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set("X-Test-Role", "Staff")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	line := make(chan string, 1)
+	go func() {
+		first, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- first
+	}()
+	select {
+	case got := <-line:
+		close(received)
+		if !strings.Contains(got, testPhone) {
+			t.Errorf("event = %q, want it unchanged", got)
+		}
+	case <-time.After(2 * time.Second):
+		close(received)
+		t.Fatal("the event was held back until the handler returned")
+	}
+}
+
+func TestMaskFieldsLeavesWebSockets(t *testing.T) {
+	r := newMaskingRouter()
+	upgrader := websocket.Upgrader{}
+	r.GET("/ws", func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(gin.H{"phone_number": testPhone})
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	header := http.Header{"X-Test-Role": {"Staff"}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", header)
+	if err != nil {
+		t.Fatalf("dialing the WebSocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message map[string]string
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("reading the message: %v", err)
+	}
+	if message["phone_number"] != testPhone {
+		t.Errorf("phone_number = %q, want it unchanged", message["phone_number"])
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader carries the client-chosen key of a request that may be retried.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyPending marks a key whose first request is still being processed.
+const idempotencyPending = "pending"
+
+// idempotentResponse is the stored outcome of the first request with a key.
+type idempotentResponse struct {
+	RequestHash string `json:"request_hash"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// responseRecorder captures the response body while writing it through.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to retry: the
+// first response is stored for ttl and replayed for later requests with the same key
+// from the same user. A request with a key that is still being processed gets 409;
+// reusing a key with a different body gets 422. Server errors are not stored, so the
+// client can retry them. Requests without the header are processed normally.
+// It must run after AuthMiddleware.
+func Idempotency(store kvstore.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeBadRequest, "Failed to read request body.", err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+
+		userID, _ := c.Get("userID")
+		key := fmt.Sprintf("idempotency:%v:%s:%s:%s", userID, c.Request.Method, c.FullPath(), idempotencyKey)
+		ctx := context.Background()
+
+		first, err := store.SetNX(ctx, key, idempotencyPending, ttl)
+		if err != nil {
+			utils.LogError(err, "Idempotency: failed to reserve key")
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to process idempotency key.", "Internal error"))
+			return
+		}
+		if !first {
+			replayIdempotentResponse(c, store, key, requestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Delete(ctx, key); err != nil {
+				utils.LogError(err, "Idempotency: failed to release key")
+			}
+			return
+		}
+		stored, err := json.Marshal(idempotentResponse{
+			RequestHash: requestHash,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err == nil {
+			err = store.Set(ctx, key, string(stored), ttl)
+		}
+		if err != nil {
+			utils.LogError(err, "Idempotency: failed to store response")
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, store kvstore.Store, key, requestHash string) {
+	value, found, err := store.Get(context.Background(), key)
+	if err != nil {
+		utils.LogError(err, "Idempotency: failed to load stored response")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to process idempotency key.", "Internal error"))
+		return
+	}
+	if !found || value == idempotencyPending {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, "A request with this Idempotency-Key is still being processed.", ""))
+		return
+	}
+
+	var stored idempotentResponse
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		utils.LogError(err, "Idempotency: invalid stored response")
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "Failed to process idempotency key.", "Internal error"))
+		return
+	}
+	if stored.RequestHash != requestHash {
+		utils.RespondWithError(c, utils.NewAPIError(http.StatusUnprocessableEntity, utils.ErrCodeIdempotencyKeyReuse, "This Idempotency-Key was used for a different request.", ""))
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
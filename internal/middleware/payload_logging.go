@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRedactedBodyBytes is the largest body that is read to be redacted; larger bodies are
+// never logged, since a cut off JSON document cannot be redacted reliably.
+const maxRedactedBodyBytes = 1 << 20
+
+const redacted = "[REDACTED]"
+
+var (
+	payloadLogging   models.PayloadLogging
+	payloadLoggingMu sync.RWMutex
+)
+
+// SetPayloadLogging sets the routes whose bodies are logged; the zero value logs none.
+func SetPayloadLogging(settings models.PayloadLogging) {
+	payloadLoggingMu.Lock()
+	defer payloadLoggingMu.Unlock()
+	payloadLogging = settings
+}
+
+// currentPayloadLogging returns the configured payload logging.
+func currentPayloadLogging() models.PayloadLogging {
+	payloadLoggingMu.RLock()
+	defer payloadLoggingMu.RUnlock()
+	return payloadLogging
+}
+
+// secretFieldParts mark the JSON fields whose values are never logged, by part of the
+// lower-cased field name.
+var secretFieldParts = []string{"password", "token", "secret", "api_key", "apikey", "authorization", "pin"}
+
+// piiFields are the JSON fields with personal data, masked or left out of the log.
+var piiFields = map[string]fieldMasker{
+	"email":         maskString(utils.MaskEmail),
+	"phone_number":  maskString(utils.MaskPhone),
+	"phoneNumber":   maskString(utils.MaskPhone),
+	"full_name":     redactValue,
+	"fullName":      redactValue,
+	"first_name":    redactValue,
+	"last_name":     redactValue,
+	"date_of_birth": redactValue,
+	"address":       redactValue,
+}
+
+// redactValue replaces a non-null value by a marker, so the log still shows the field was sent.
+func redactValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactPayload redacts the secrets and personal data of a decoded JSON document in place.
+// The value of a secret setting (models.IsSecretSetting), as sent to and returned by the
+// settings routes, is redacted too.
+func redactPayload(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if key, ok := v["setting_key"].(string); ok && models.IsSecretSetting(key) {
+			if settingValue, ok := v["setting_value"]; ok {
+				v["setting_value"] = redactValue(settingValue)
+			}
+		}
+		for field, fieldValue := range v {
+			if isSecretField(field) {
+				v[field] = redactValue(fieldValue)
+			} else if mask, ok := piiFields[field]; ok {
+				v[field] = mask(fieldValue)
+			} else {
+				v[field] = redactPayload(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactPayload(v[i])
+		}
+	}
+	return value
+}
+
+func isSecretField(field string) bool {
+	field = strings.ToLower(field)
+	for _, part := range secretFieldParts {
+		if strings.Contains(field, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// loggableBody returns body, of the given content type, as it is logged: redacted and cut
+// off after maxBytes. Only JSON bodies are logged; others are described by size.
+func loggableBody(body []byte, size int64, contentType string, maxBytes int) (string, bool) {
+	if size == 0 {
+		return "", false
+	}
+	if !strings.Contains(contentType, "json") {
+		if size < 0 { // Streamed without a Content-Length
+			return fmt.Sprintf("[%s body not logged]", contentType), false
+		}
+		return fmt.Sprintf("[%s body of %d bytes not logged]", contentType, size), false
+	}
+	if size > maxRedactedBodyBytes {
+		return fmt.Sprintf("[JSON body of %d bytes too large to redact]", size), false
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Sprintf("[invalid JSON body of %d bytes not logged]", size), false
+	}
+	redactedBody, err := json.Marshal(redactPayload(document))
+	if err != nil {
+		return fmt.Sprintf("[JSON body of %d bytes could not be redacted]", size), false
+	}
+	if len(redactedBody) > maxBytes {
+		return string(redactedBody[:maxBytes]), true
+	}
+	return string(redactedBody), false
+}
+
+// payloadRecorder passes the response on while keeping a copy of up to
+// maxRedactedBodyBytes of it for the log.
+type payloadRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	size int64
+}
+
+func (w *payloadRecorder) Write(b []byte) (int, error) {
+	w.record(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *payloadRecorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *payloadRecorder) record(b []byte) {
+	w.size += int64(len(b))
+	if w.body.Len()+len(b) <= maxRedactedBodyBytes {
+		w.body.Write(b)
+	}
+}
+
+// PayloadLogging logs the request and response bodies of the routes configured in the
+// payload_logging setting, to settle what a client sent and what it got back. Secrets
+// (passwords, tokens, keys) are redacted and personal data masked or redacted; bodies
+// other than JSON are only described. It must be registered on the engine, so it sees
+// the responses as sent, after field masking.
+func PayloadLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings := currentPayloadLogging()
+		route := c.FullPath()
+		if len(settings.Routes) == 0 || route == "" || !settings.Matches(c.Request.Method, route) {
+			c.Next()
+			return
+		}
+
+		requestContentType := c.ContentType()
+		var requestBody []byte
+		requestSize := c.Request.ContentLength
+		if c.Request.Body != nil && strings.Contains(requestContentType, "json") && requestSize <= maxRedactedBodyBytes {
+			body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRedactedBodyBytes+1))
+			if err != nil {
+				utils.LogError(err, "PayloadLogging: failed to read request body of "+route)
+			}
+			requestBody = body
+			requestSize = int64(len(body))
+			// The handler reads the body as if it had not been read here
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		recorder := &payloadRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		request, requestTruncated := loggableBody(requestBody, requestSize, requestContentType, settings.MaxBodyBytes)
+		response, responseTruncated := loggableBody(recorder.body.Bytes(), recorder.size, recorder.Header().Get("Content-Type"), settings.MaxBodyBytes)
+		fields := map[string]interface{}{
+			"method":                  c.Request.Method,
+			"route":                   route,
+			"path":                    c.Request.URL.Path,
+			"status_code":             c.Writer.Status(),
+			"request_body":            request,
+			"request_body_truncated":  requestTruncated,
+			"response_body":           response,
+			"response_body_truncated": responseTruncated,
+		}
+		if userID, ok := c.Get("userID"); ok {
+			fields["user_id"] = userID
+		}
+		utils.LogDebug("Request payload", fields)
+	}
+}
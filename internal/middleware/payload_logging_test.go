@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoggableBodyRedacts(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string // The body as logged, with its fields in alphabetical order
+	}{
+		{
+			name: "login",
+			body: `{"username": "cashier", "password": "hunter22"}`,
+			want: `{"password":"[REDACTED]","username":"cashier"}`,
+		},
+		{
+			name: "approval PIN",
+			body: `{"pin": "482913"}`,
+			want: `{"pin":"[REDACTED]"}`,
+		},
+		{
+			name: "refresh token in a nested object",
+			body: `{"session": {"refresh_token": "abc", "device_name": "Bar tablet"}}`,
+			want: `{"session":{"device_name":"Bar tablet","refresh_token":"[REDACTED]"}}`,
+		},
+		{
+			name: "personal data",
+			body: `{"full_name": "Ali Serikov", "email": null}`,
+			want: `{"email":null,"full_name":"[REDACTED]"}`,
+		},
+		{
+			name: "secret setting saved",
+			body: `{"setting_key": "sms_api_key", "setting_value": "sk-live-1"}`,
+			want: `{"setting_key":"sms_api_key","setting_value":"[REDACTED]"}`,
+		},
+		{
+			name: "settings listed",
+			body: `[{"setting_key": "telegram_bot_token", "setting_value": "123:abc"}, {"setting_key": "club_name", "setting_value": "Arena"}, {"setting_key": "smtp_password"}]`,
+			want: `[{"setting_key":"telegram_bot_token","setting_value":"[REDACTED]"},{"setting_key":"club_name","setting_value":"Arena"},{"setting_key":"smtp_password"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := loggableBody([]byte(tt.body), int64(len(tt.body)), "application/json", 4096)
+			if truncated {
+				t.Errorf("loggableBody truncated %q", got)
+			}
+			if got != tt.want {
+				t.Errorf("loggableBody = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggableBodyMasksPhoneNumbers(t *testing.T) {
+	body := `{"phone_number": "+77011234567"}`
+	got, _ := loggableBody([]byte(body), int64(len(body)), "application/json; charset=utf-8", 4096)
+	var logged map[string]string
+	if err := json.Unmarshal([]byte(got), &logged); err != nil {
+		t.Fatalf("logged body %q is not JSON: %v", got, err)
+	}
+	if phone := logged["phone_number"]; phone == "" || strings.Contains(phone, "1234567") {
+		t.Errorf("phone_number logged as %q, want it masked", phone)
+	}
+}
+
+func TestLoggableBodyDescribesOtherBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		size        int64
+		contentType string
+		maxBytes    int
+		want        string
+		truncated   bool
+	}{
+		{name: "empty", contentType: "application/json", maxBytes: 4096},
+		{name: "not JSON", body: "a,b", size: 3, contentType: "text/csv", maxBytes: 4096, want: "[text/csv body of 3 bytes not logged]"},
+		{name: "streamed", size: -1, contentType: "text/event-stream", maxBytes: 4096, want: "[text/event-stream body not logged]"},
+		{name: "invalid JSON", body: `{"password": `, size: 13, contentType: "application/json", maxBytes: 4096, want: "[invalid JSON body of 13 bytes not logged]"},
+		{name: "cut off", body: `{"note": "long enough"}`, size: 23, contentType: "application/json", maxBytes: 10, want: `{"note":"l`, truncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := loggableBody([]byte(tt.body), tt.size, tt.contentType, tt.maxBytes)
+			if got != tt.want || truncated != tt.truncated {
+				t.Errorf("loggableBody = %q (truncated %v), want %q (truncated %v)", got, truncated, tt.want, tt.truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows each client IP at most limit requests per window on the routes it
+// guards, counting in fixed windows in the shared store so the limit holds across
+// instances. name separates the counters of different limits. A limit of 0 or less
+// disables the middleware. If the store is unavailable, requests are let through.
+func RateLimit(store kvstore.Store, name string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		windowStart := now.Truncate(window)
+		key := fmt.Sprintf("ratelimit:%s:%s:%d", name, c.ClientIP(), windowStart.Unix())
+		count, err := store.Incr(context.Background(), key, window)
+		if err != nil {
+			utils.LogError(err, "RateLimit: failed to count request, allowing it")
+			c.Next()
+			return
+		}
+
+		if count > int64(limit) {
+			retryAfter := windowStart.Add(window).Sub(now)
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			utils.RespondWithError(c, utils.NewAPIError(http.StatusTooManyRequests, utils.ErrCodeTooManyRequests, "Too many requests, please retry later.", ""))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a 500 response, logs it with its stack and
+// reports it. Requests answered with a 5xx status are reported too, with the last error
+// attached to the context if any. It must run after RequestID.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer // Middleware holding back the response may have replaced it
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered) // The client went away; net/http handles it
+			}
+			utils.LogError(fmt.Errorf("%v", recovered), "Recovered from panic in "+c.Request.Method+" "+c.FullPath()+"\n"+string(debug.Stack()))
+			req := reportedRequest(c)
+			req.StatusCode = http.StatusInternalServerError
+			apperrors.ReportPanic(recovered, req)
+			c.Writer = writer
+			if !c.Writer.Written() {
+				utils.RespondWithError(c, utils.NewAPIError(http.StatusInternalServerError, utils.ErrCodeInternalServerError, "An unexpected error occurred.", "Internal error"))
+			}
+			c.Abort()
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() < http.StatusInternalServerError || c.FullPath() == "" {
+			return
+		}
+		err := errors.New(http.StatusText(c.Writer.Status()))
+		if last := c.Errors.Last(); last != nil {
+			err = last.Err
+		}
+		apperrors.ReportError(err, reportedRequest(c))
+	}
+}
+
+// reportedRequest describes the request of c for an error report.
+func reportedRequest(c *gin.Context) apperrors.Request {
+	req := apperrors.Request{
+		ID:         c.GetString("requestID"),
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		StatusCode: c.Writer.Status(),
+		Username:   c.GetString("username"),
+	}
+	if userID, ok := c.Get("userID"); ok {
+		if id, ok := userID.(int64); ok {
+			req.UserID = &id
+		}
+	}
+	return req
+}
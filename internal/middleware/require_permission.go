@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission admits only users whose role has the permission, e.g. to see the documents
+// of clients. Roles are granted permissions in role_permissions, so, unlike RoleAuthMiddleware,
+// who may pass can change without a new release. It must run after AuthMiddleware.
+func RequirePermission(permissionService services.PermissionService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permissions, err := permissionService.RolePermissions(c.GetString("userRole"))
+		if err != nil {
+			utils.LogError(err, "RequirePermission: failed to get role permissions")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+		if !permissions[permission] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource. Required permission: " + permission})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
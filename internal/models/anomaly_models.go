@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Metrics checked for anomalies.
+const (
+	AnomalyMetricRevenue   = "revenue"          // Sales and stopped table sessions, like the daily summaries
+	AnomalyMetricDiscounts = "discounts"        // Discount total of the completed and paid orders
+	AnomalyMetricCancelled = "cancelled_orders" // Orders placed during the day and cancelled
+	AnomalyMetricDeleted   = "deleted_orders"   // Orders deleted during the day, from the audit log
+)
+
+// AnomalySettings is the anomaly_detection setting.
+type AnomalySettings struct {
+	// ThresholdPercent is how far above (or, for the revenue, also below) its trailing average
+	// a metric of a day must be to be an anomaly.
+	ThresholdPercent int `json:"threshold_percent"`
+	// TrailingDays is how many days before the checked day are averaged; only days with
+	// revenue, i.e. the club was open, count.
+	TrailingDays int `json:"trailing_days"`
+	// MinCount is the least number of cancelled or deleted orders that can be an anomaly, so
+	// 2 cancellations against an average of 0.5 are not.
+	MinCount int `json:"min_count"`
+}
+
+// DefaultAnomalySettings returns the anomaly detection used without the anomaly_detection setting.
+func DefaultAnomalySettings() AnomalySettings {
+	return AnomalySettings{ThresholdPercent: 50, TrailingDays: 28, MinCount: 3}
+}
+
+// ParseAnomalySettings parses the value of the anomaly_detection setting, e.g.
+// {"threshold_percent": 40, "trailing_days": 28, "min_count": 3}; omitted fields keep their default.
+func ParseAnomalySettings(value string) (AnomalySettings, error) {
+	settings := DefaultAnomalySettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return AnomalySettings{}, fmt.Errorf("invalid anomaly detection settings: %w", err)
+	}
+	if settings.ThresholdPercent <= 0 {
+		return AnomalySettings{}, fmt.Errorf("threshold_percent must be positive")
+	}
+	if settings.TrailingDays < 7 || settings.TrailingDays > 365 {
+		return AnomalySettings{}, fmt.Errorf("trailing_days must be between 7 and 365")
+	}
+	if settings.MinCount < 1 {
+		return AnomalySettings{}, fmt.Errorf("min_count must be at least 1")
+	}
+	return settings, nil
+}
+
+// RiskMetrics are the metrics of a business day checked for anomalies, or their averages.
+type RiskMetrics struct {
+	BusinessDate   string  `json:"business_date,omitempty"` // YYYY-MM-DD, club time; omitted for averages
+	Revenue        Money   `json:"revenue"`
+	Discounts      Money   `json:"discounts"`
+	CancelledCount float64 `json:"cancelled_orders"`
+	DeletedCount   float64 `json:"deleted_orders"`
+}
+
+// Anomaly is a metric of a day that deviated from its trailing average beyond the threshold.
+type Anomaly struct {
+	Metric  string  `json:"metric"`
+	Value   float64 `json:"value"`
+	Average float64 `json:"average"`
+	// DeviationPercent is how far above (positive) or below (negative) the average the value
+	// is; nil when the average is 0
+	DeviationPercent *float64 `json:"deviation_percent"`
+}
+
+// AnomalyCheck is the check of a business day of a branch for anomalies.
+type AnomalyCheck struct {
+	ID           int64        `json:"id"`
+	BranchCode   string       `json:"branch_code"`
+	BusinessDate string       `json:"business_date"` // YYYY-MM-DD, club time
+	Metrics      RiskMetrics  `json:"metrics"`
+	Averages     *RiskMetrics `json:"averages"` // nil without enough trailing days to compare with
+	Anomalies    []Anomaly    `json:"anomalies"`
+	CheckedAt    time.Time    `json:"checked_at"`
+	// Notes are the calendar notes of the day, which may explain its anomalies; not stored
+	Notes []CalendarNote `json:"notes,omitempty"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// API key scopes, i.e. the groups of routes a key may call.
+const (
+	APIKeyScopeKiosk  = "kiosk"  // POST /kiosk/check-in
+	APIKeyScopeDevice = "device" // POST /devices/register and /devices/heartbeat
+	// The self-service channels place orders and book tables through /channels/<scope>; the
+	// kiosk scope opens /channels/kiosk too
+	APIKeyScopeClientApp = "client_app"
+	APIKeyScopeTelegram  = "telegram"
+)
+
+// APIKeyScopes lists the valid API key scopes.
+var APIKeyScopes = []string{APIKeyScopeKiosk, APIKeyScopeDevice, APIKeyScopeClientApp, APIKeyScopeTelegram}
+
+// ChannelAPIKeyScopes lists the scopes of the self-service channels, each named after the
+// order source it records.
+var ChannelAPIKeyScopes = []string{APIKeyScopeKiosk, APIKeyScopeClientApp, APIKeyScopeTelegram}
+
+// IsValidAPIKeyScope reports whether scope is one of APIKeyScopes.
+func IsValidAPIKeyScope(scope string) bool {
+	for _, valid := range APIKeyScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey authenticates a device without a user login, such as the check-in kiosk.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"` // e.g. "Entrance kiosk"
+	KeyHash    string     `json:"-"`    // SHA-256 of the key, hex encoded
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key may call the routes of scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
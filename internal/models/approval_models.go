@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Approval is a request to perform a sensitive action (e.g. a large discount or a
+// refund) that needs an Admin's sign-off. Payload holds the original request, which
+// is executed when the approval is granted.
+type Approval struct {
+	ID          int64           `json:"id"`
+	Action      string          `json:"action"`              // "order.discount", "order.refund", "order.delete"
+	TargetID    *int64          `json:"target_id,omitempty"` // Order the action applies to; set on approval for new orders
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"` // "pending", "approved", "rejected", "failed"
+	RequestedBy *int64          `json:"requested_by,omitempty"`
+	DecidedBy   *int64          `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time      `json:"decided_at,omitempty"`
+	Reason      *string         `json:"reason,omitempty"`       // Why it was rejected
+	ResultError *string         `json:"result_error,omitempty"` // Why the approved action failed
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ApprovalPIN is the approval PIN hash of an active Admin.
+type ApprovalPIN struct {
+	UserID  int64
+	PINHash string
+}
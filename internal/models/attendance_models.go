@@ -0,0 +1,118 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attendance anomaly types.
+const (
+	AttendanceLateArrival    = "late_arrival"    // Clocked in after the shift start and the grace period
+	AttendanceEarlyDeparture = "early_departure" // Clocked out before the shift end less the grace period
+	AttendanceMissedShift    = "missed_shift"    // Never clocked in during a shift that has ended
+)
+
+// AttendanceAnomalyTypes lists the attendance anomaly types.
+var AttendanceAnomalyTypes = []string{AttendanceLateArrival, AttendanceEarlyDeparture, AttendanceMissedShift}
+
+// AttendanceSettings is the attendance setting. A missing or zero penalty is not deducted.
+type AttendanceSettings struct {
+	GraceMinutes          int    `json:"grace_minutes"`                     // Lateness and early leave tolerated without a flag
+	LatePenalty           *Money `json:"late_penalty,omitempty"`            // Per late arrival
+	EarlyDeparturePenalty *Money `json:"early_departure_penalty,omitempty"` // Per early departure
+	MissedShiftPenalty    *Money `json:"missed_shift_penalty,omitempty"`    // Per missed shift
+}
+
+// DefaultAttendanceSettings returns the settings used without the attendance setting.
+func DefaultAttendanceSettings() AttendanceSettings {
+	return AttendanceSettings{GraceMinutes: 5}
+}
+
+// Grace returns how late or early a staff member may clock in or out without a flag.
+func (s AttendanceSettings) Grace() time.Duration {
+	return time.Duration(s.GraceMinutes) * time.Minute
+}
+
+// PenaltyFor returns the payroll penalty of an anomaly of anomalyType; zero if none is set.
+func (s AttendanceSettings) PenaltyFor(anomalyType string) Money {
+	var penalty *Money
+	switch anomalyType {
+	case AttendanceLateArrival:
+		penalty = s.LatePenalty
+	case AttendanceEarlyDeparture:
+		penalty = s.EarlyDeparturePenalty
+	case AttendanceMissedShift:
+		penalty = s.MissedShiftPenalty
+	}
+	if penalty == nil {
+		return ZeroMoney
+	}
+	return *penalty
+}
+
+// ParseAttendanceSettings parses the value of the attendance setting, e.g.
+// {"grace_minutes": 10, "late_penalty": 1000, "missed_shift_penalty": 5000}. Missing fields keep their defaults.
+func ParseAttendanceSettings(value string) (AttendanceSettings, error) {
+	settings := DefaultAttendanceSettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return AttendanceSettings{}, fmt.Errorf("invalid attendance settings: %w", err)
+	}
+	if settings.GraceMinutes < 0 || settings.GraceMinutes > 120 {
+		return AttendanceSettings{}, fmt.Errorf("grace_minutes must be between 0 and 120")
+	}
+	for _, penalty := range []*Money{settings.LatePenalty, settings.EarlyDeparturePenalty, settings.MissedShiftPenalty} {
+		if penalty != nil && penalty.IsNegative() {
+			return AttendanceSettings{}, fmt.Errorf("attendance penalties cannot be negative")
+		}
+	}
+	return settings, nil
+}
+
+// AttendanceShift is a scheduled shift checked against the time clock.
+type AttendanceShift struct {
+	Shift
+	StaffName string
+	OnTimeOff bool // Approved time off covers the day the shift starts, which excuses it
+}
+
+// AttendanceAnomaly is a shift a staff member arrived late to, left early or missed.
+type AttendanceAnomaly struct {
+	StaffID    int64      `json:"staff_id"`
+	StaffName  string     `json:"staff_name"`
+	ShiftID    int64      `json:"shift_id"`
+	ShiftStart time.Time  `json:"shift_start"`
+	ShiftEnd   time.Time  `json:"shift_end"`
+	Type       string     `json:"type"`    // One of AttendanceAnomalyTypes
+	Minutes    int        `json:"minutes"` // How late or early; the length of the shift if missed
+	ClockInAt  *time.Time `json:"clock_in_at,omitempty"`
+	ClockOutAt *time.Time `json:"clock_out_at,omitempty"`
+	Penalty    Money      `json:"penalty"` // Deducted in the payroll; 0 without a penalty set
+}
+
+// AttendanceSummary totals the attendance of a staff member over a month.
+type AttendanceSummary struct {
+	StaffID         int64  `json:"staff_id"`
+	StaffName       string `json:"staff_name"`
+	ScheduledShifts int    `json:"scheduled_shifts"` // Started by the time of the report
+	ExcusedShifts   int    `json:"excused_shifts"`   // On approved time off
+	LateArrivals    int    `json:"late_arrivals"`
+	LateMinutes     int    `json:"late_minutes"`
+	EarlyDepartures int    `json:"early_departures"`
+	EarlyMinutes    int    `json:"early_minutes"`
+	MissedShifts    int    `json:"missed_shifts"`
+	Penalties       Money  `json:"penalties"`
+}
+
+// AttendanceReport is the attendance of the staff over a month, compared with their shifts.
+type AttendanceReport struct {
+	Month          string              `json:"month"` // YYYY-MM, club time
+	GraceMinutes   int                 `json:"grace_minutes"`
+	Staff          []AttendanceSummary `json:"staff"`
+	Anomalies      []AttendanceAnomaly `json:"anomalies"` // By shift start
+	TotalPenalties Money               `json:"total_penalties"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Audit log actions other than API requests, which are logged as "<METHOD> <route>".
+const (
+	AuditActionImpersonationStart = "impersonation.start"
+	AuditActionRecordDeleted      = "record.deleted"  // A soft delete; Details is "<entity> <ID>", e.g. "clients 12"
+	AuditActionRecordRestored     = "record.restored" // The restore of a soft deleted record; Details as for AuditActionRecordDeleted
+)
+
+// AuditLogEntry records an API request that changed something, any request made while
+// impersonating, or another action of a user. ImpersonatorID is set if an Admin acted as
+// the user with an impersonation token.
+type AuditLogEntry struct {
+	ID             int64     `json:"id"`
+	UserID         *int64    `json:"user_id,omitempty"`
+	ImpersonatorID *int64    `json:"impersonator_id,omitempty"`
+	Action         string    `json:"action"` // e.g. "POST /api/v1/orders" or AuditActionImpersonationStart
+	Method         *string   `json:"method,omitempty"`
+	Path           *string   `json:"path,omitempty"` // The requested path, with IDs
+	StatusCode     *int      `json:"status_code,omitempty"`
+	IPAddress      *string   `json:"ip_address,omitempty"`
+	Details        *string   `json:"details,omitempty"` // e.g. the reason of an impersonation
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AuditLogFilters selects audit log entries; nil filters do not apply.
+type AuditLogFilters struct {
+	UserID         *int64
+	ImpersonatorID *int64
+	Impersonated   *bool      // Only entries made (or not) while impersonating
+	From           *time.Time // Created at or after
+	To             *time.Time // Created before
+	Page           int
+	PageSize       int
+}
+
+// Impersonation describes the Admin acting as the current user, so clients can show a banner.
+type Impersonation struct {
+	ImpersonatorID       int64     `json:"impersonator_id"`
+	ImpersonatorUsername string    `json:"impersonator_username"`
+	Banner               string    `json:"banner"`
+	ExpiresAt            time.Time `json:"expires_at"`
+}
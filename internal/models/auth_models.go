@@ -4,16 +4,28 @@ import "time"
 
 // User represents a user in the system
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username" db:"username"`
-	PasswordHash string    `json:"-" db:"password_hash"` // '-' means don't send in JSON response
-	Email        *string   `json:"email,omitempty" db:"email"`
-	FullName     *string   `json:"full_name,omitempty" db:"full_name"`
-	RoleID       *int64    `json:"role_id,omitempty" db:"role_id"`
-	IsActive     bool      `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
-	Role         *Role     `json:"role,omitempty"` // For joining with Role
+	ID                int64     `json:"id"`
+	Username          string    `json:"username" db:"username"`
+	PasswordHash      string    `json:"-" db:"password_hash"` // '-' means don't send in JSON response
+	Email             *string   `json:"email,omitempty" db:"email"`
+	FullName          *string   `json:"full_name,omitempty" db:"full_name"`
+	RoleID            *int64    `json:"role_id,omitempty" db:"role_id"`
+	IsActive          bool      `json:"is_active" db:"is_active"`
+	PreferredLanguage *string   `json:"preferred_language,omitempty" db:"preferred_language"` // Language of API messages; nil follows Accept-Language
+	PhoneNumber       *string   `json:"phone_number,omitempty" db:"phone_number"`
+	HasAvatar         bool      `json:"has_avatar"` // The avatar is served by GET /auth/me/avatar
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	Role              *Role     `json:"role,omitempty"` // For joining with Role
+}
+
+// UserProfile holds the details users change themselves. PasswordHash is nil unless the
+// password changes.
+type UserProfile struct {
+	FullName     *string
+	Email        *string
+	PhoneNumber  *string
+	PasswordHash *string
 }
 
 // Role represents a user role
@@ -35,6 +47,13 @@ type Permission struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Permissions checked by the API. Roles are granted them in role_permissions.
+const (
+	PermissionViewPII             = "view_pii"              // See the phone numbers and email addresses of people unmasked
+	PermissionViewSalaries        = "view_salaries"         // See the salaries and pay of staff members
+	PermissionViewClientDocuments = "view_client_documents" // See and upload the consents and ID documents of clients
+)
+
 // RolePermission is the join table for roles and permissions
 type RolePermission struct {
 	RoleID       int64     `json:"role_id" db:"role_id"`
@@ -58,3 +77,19 @@ type RegistrationPayload struct {
 }
 
 
+
+// UserSession is a device logged in as a user, from login until its refresh token
+// expires or the session is revoked.
+type UserSession struct {
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	RefreshTokenID string     `json:"-"`
+	DeviceName     *string    `json:"device_name,omitempty"`
+	IPAddress      *string    `json:"ip_address,omitempty"`
+	UserAgent      *string    `json:"user_agent,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActiveAt   time.Time  `json:"last_active_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	Current        bool       `json:"current"` // The session of the requesting device
+}
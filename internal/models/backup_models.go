@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Backup destinations.
+const (
+	BackupDestinationLocal = "local"
+	BackupDestinationS3    = "s3"
+)
+
+// Backup is a pg_dump of the database, stored on local disk or in S3.
+type Backup struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"`             // "running", "completed", "failed"
+	Trigger     string     `json:"trigger"`            // "manual" or "scheduled"
+	Destination string     `json:"destination"`        // "local" or "s3"
+	Location    *string    `json:"location,omitempty"` // File path or s3:// URI, once completed
+	SizeBytes   *int64     `json:"size_bytes,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	RequestedBy *int64     `json:"requested_by,omitempty"` // Admin who triggered it; empty for scheduled backups
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// BackupSchedule is the nightly backup configured in the backup_schedule setting.
+type BackupSchedule struct {
+	Time        string `json:"time"`        // Club-local time of day, HH:MM
+	Destination string `json:"destination"` // "local" or "s3"; empty means local
+	Hour        int    `json:"-"`
+	Minute      int    `json:"-"`
+}
+
+// ParseBackupSchedule parses the backup_schedule setting, e.g.
+// {"time": "03:00", "destination": "s3"}. An empty value disables the schedule (nil).
+func ParseBackupSchedule(value string) (*BackupSchedule, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var schedule BackupSchedule
+	if err := json.Unmarshal([]byte(value), &schedule); err != nil {
+		return nil, fmt.Errorf("invalid backup schedule: %w", err)
+	}
+	t, err := time.Parse("15:04", schedule.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup schedule time %q, use HH:MM", schedule.Time)
+	}
+	schedule.Hour, schedule.Minute = t.Hour(), t.Minute()
+	switch schedule.Destination {
+	case "":
+		schedule.Destination = BackupDestinationLocal
+	case BackupDestinationLocal, BackupDestinationS3:
+	default:
+		return nil, fmt.Errorf("invalid backup destination %q, use %s or %s", schedule.Destination, BackupDestinationLocal, BackupDestinationS3)
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Kinds of running bills.
+const (
+	BillKindOrder        = "order"
+	BillKindTableSession = "table_session"
+)
+
+// BillLine is a line of a running bill. Display is the amount formatted with the currency.
+type BillLine struct {
+	Label   string `json:"label"`
+	Detail  string `json:"detail,omitempty"` // e.g. "2 x 500 ₸" or "95 min x 2 000 ₸/h"
+	Amount  Money  `json:"amount"`
+	Display string `json:"display"`
+}
+
+// NewBillLine returns a bill line of amount with its display text.
+func NewBillLine(label, detail string, amount Money) BillLine {
+	return BillLine{Label: label, Detail: detail, Amount: amount, Display: amount.String()}
+}
+
+// Bill is the running bill of an order or a table session up to GeneratedAt; it closes nothing.
+// Amounts owed are Total less Paid; Totals repeats them as display lines in the order they are printed.
+type Bill struct {
+	Kind              string     `json:"kind"` // One of the BillKind constants
+	ID                int64      `json:"id"`
+	Title             string     `json:"title"`
+	Open              bool       `json:"open"`  // The session is running or the order is not settled yet
+	Lines             []BillLine `json:"lines"` // Time charges, then the items
+	Subtotal          Money      `json:"subtotal"`
+	Discount          Money      `json:"discount"`
+	ServiceCharge     Money      `json:"service_charge"`                // Of large groups and VIP tables, untaxed
+	ServiceChargeName string     `json:"service_charge_name,omitempty"` // Its line in Totals, e.g. "Service charge 10%"
+	Tax               Money      `json:"tax"`
+	TaxIncluded       bool       `json:"tax_included"` // Tax is part of the prices rather than added to them
+	Total             Money      `json:"total"`
+	Paid              Money      `json:"paid"` // Settled orders and house account charges so far
+	Due               Money      `json:"due"`
+	Totals            []BillLine `json:"totals"`
+	GeneratedAt       time.Time  `json:"generated_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BookingPolicy is the booking_policy setting. A zero value disables a rule.
+type BookingPolicy struct {
+	MinLeadMinutes        int    `json:"min_lead_minutes"`                // Online bookings must start at least this long after they are made
+	FreeCancellationHours int    `json:"free_cancellation_hours"`         // Cancelling later than this before the start is a late cancellation
+	LateCancellationFee   *Money `json:"late_cancellation_fee,omitempty"` // Charged for late cancellations
+	BufferMinutes         int    `json:"buffer_minutes"`                  // Cleanup time after each booking; GameTable.BufferMinutes overrides it
+	BlacklistOverride     bool   `json:"blacklist_override"`              // Staff may book blacklisted clients with a manager's approval
+}
+
+// MinLead returns the minimum notice of online bookings.
+func (p BookingPolicy) MinLead() time.Duration {
+	return time.Duration(p.MinLeadMinutes) * time.Minute
+}
+
+// Buffer returns the cleanup time kept free after bookings of a table with the given
+// override (GameTable.BufferMinutes), or the policy's if the table has none.
+func (p BookingPolicy) Buffer(tableMinutes *int) time.Duration {
+	minutes := p.BufferMinutes
+	if tableMinutes != nil {
+		minutes = *tableMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// IsLateCancellation reports whether cancelling a booking starting at start is late at now.
+func (p BookingPolicy) IsLateCancellation(start, now time.Time) bool {
+	if p.FreeCancellationHours <= 0 {
+		return false
+	}
+	return now.After(start.Add(-time.Duration(p.FreeCancellationHours) * time.Hour))
+}
+
+// ParseBookingPolicy parses the value of the booking_policy setting, e.g.
+// {"min_lead_minutes": 60, "free_cancellation_hours": 24, "late_cancellation_fee": 2000, "buffer_minutes": 10}.
+func ParseBookingPolicy(value string) (BookingPolicy, error) {
+	var policy BookingPolicy
+	if strings.TrimSpace(value) == "" {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return BookingPolicy{}, fmt.Errorf("invalid booking policy: %w", err)
+	}
+	if policy.MinLeadMinutes < 0 {
+		return BookingPolicy{}, fmt.Errorf("min_lead_minutes cannot be negative")
+	}
+	if policy.FreeCancellationHours < 0 {
+		return BookingPolicy{}, fmt.Errorf("free_cancellation_hours cannot be negative")
+	}
+	if policy.BufferMinutes < 0 {
+		return BookingPolicy{}, fmt.Errorf("buffer_minutes cannot be negative")
+	}
+	if policy.LateCancellationFee != nil && policy.LateCancellationFee.IsNegative() {
+		return BookingPolicy{}, fmt.Errorf("late_cancellation_fee cannot be negative")
+	}
+	return policy, nil
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Channels booking reminders are sent through.
+const (
+	ReminderChannelSMS      = "sms"
+	ReminderChannelTelegram = "telegram" // Needs the client's Telegram chat, linked by the bot
+	ReminderChannelPush     = "push"     // To the devices of the client app
+	ReminderChannelNone     = "none"     // No reminders
+)
+
+// ReminderChannels lists the channels of booking reminders.
+var ReminderChannels = []string{ReminderChannelSMS, ReminderChannelTelegram, ReminderChannelPush, ReminderChannelNone}
+
+// IsValidReminderChannel reports whether channel is one of ReminderChannels.
+func IsValidReminderChannel(channel string) bool {
+	for _, valid := range ReminderChannels {
+		if channel == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds of the lead time of booking reminders, in minutes.
+const (
+	MinReminderLeadMinutes = 15
+	MaxReminderLeadMinutes = 48 * 60
+)
+
+// Statuses of booking reminders.
+const (
+	BookingReminderSending = "sending" // Claimed by the dispatcher; stays so if the server stopped while sending
+	BookingReminderSent    = "sent"
+	BookingReminderFailed  = "failed"
+)
+
+// BookingReminderSettings is the booking_reminders setting, the channel and lead time of the
+// clients that chose none.
+type BookingReminderSettings struct {
+	Channel     string `json:"channel"`
+	LeadMinutes int    `json:"lead_minutes"` // How long before the booking starts the reminder is sent
+}
+
+// DefaultBookingReminderSettings returns the reminders used without the booking_reminders
+// setting: none, as every channel needs its sender configured first, 2 hours ahead for the
+// clients that choose a channel.
+func DefaultBookingReminderSettings() BookingReminderSettings {
+	return BookingReminderSettings{Channel: ReminderChannelNone, LeadMinutes: 120}
+}
+
+// ParseBookingReminderSettings parses the value of the booking_reminders setting, e.g.
+// {"channel": "sms", "lead_minutes": 120}; omitted fields keep their default.
+func ParseBookingReminderSettings(value string) (BookingReminderSettings, error) {
+	settings := DefaultBookingReminderSettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return BookingReminderSettings{}, fmt.Errorf("invalid booking reminder settings: %w", err)
+	}
+	if !IsValidReminderChannel(settings.Channel) {
+		return BookingReminderSettings{}, fmt.Errorf("channel must be one of %s", strings.Join(ReminderChannels, ", "))
+	}
+	if settings.LeadMinutes < MinReminderLeadMinutes || settings.LeadMinutes > MaxReminderLeadMinutes {
+		return BookingReminderSettings{}, fmt.Errorf("lead_minutes must be between %d and %d", MinReminderLeadMinutes, MaxReminderLeadMinutes)
+	}
+	return settings, nil
+}
+
+// BookingReminderPreferences are how a client is reminded of its bookings. Channel and
+// LeadMinutes are nil while the client follows the booking_reminders setting; the Effective
+// fields are what the dispatcher uses.
+type BookingReminderPreferences struct {
+	ClientID             int64      `json:"client_id"`
+	Channel              *string    `json:"channel"`
+	LeadMinutes          *int       `json:"lead_minutes"`
+	TelegramChatID       *int64     `json:"telegram_chat_id"` // The chat of the bot with the client
+	EffectiveChannel     string     `json:"effective_channel"`
+	EffectiveLeadMinutes int        `json:"effective_lead_minutes"`
+	UpdatedAt            *time.Time `json:"updated_at"` // nil until the preferences are saved
+}
+
+// ApplyDefaults fills in the Effective fields from the saved preferences and settings.
+func (p *BookingReminderPreferences) ApplyDefaults(settings BookingReminderSettings) {
+	p.EffectiveChannel, p.EffectiveLeadMinutes = settings.Channel, settings.LeadMinutes
+	if p.Channel != nil {
+		p.EffectiveChannel = *p.Channel
+	}
+	if p.LeadMinutes != nil {
+		p.EffectiveLeadMinutes = *p.LeadMinutes
+	}
+}
+
+// DueBookingReminder is a booking whose reminder is due, with where to send it.
+type DueBookingReminder struct {
+	BookingID      int64
+	ClientID       int64
+	TableName      string
+	StartTime      time.Time
+	Channel        string
+	PhoneNumber    *string
+	TelegramChatID *int64
+}
+
+// BookingReminder records the reminder of a booking, sent or attempted. A booking moved to
+// another start time is reminded again.
+type BookingReminder struct {
+	ID           int64      `json:"id"`
+	BookingID    int64      `json:"booking_id"`
+	ClientID     int64      `json:"client_id"`
+	BookingStart time.Time  `json:"booking_start"`
+	Channel      string     `json:"channel"`
+	Status       string     `json:"status"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`
+}
+
+// BookingReminderFilters selects booking reminders.
+type BookingReminderFilters struct {
+	ClientID  *int64
+	BookingID *int64
+	Channel   *string
+	Status    *string
+	From      *time.Time // Created at or after
+	To        *time.Time // Created before
+	Page      int
+	PageSize  int
+}
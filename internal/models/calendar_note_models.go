@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Categories of calendar notes.
+const (
+	CalendarNoteCityEvent     = "city_event"     // A concert, match or festival nearby
+	CalendarNoteSchoolHoliday = "school_holiday" // School or university holidays
+	CalendarNotePublicHoliday = "public_holiday"
+	CalendarNoteMarketing     = "marketing" // A promotion or advertising push
+	CalendarNoteOther         = "other"
+)
+
+// CalendarNoteCategories lists the valid categories of calendar notes.
+var CalendarNoteCategories = []string{
+	CalendarNoteCityEvent, CalendarNoteSchoolHoliday, CalendarNotePublicHoliday, CalendarNoteMarketing, CalendarNoteOther,
+}
+
+// CalendarNote annotates a date or range of dates with what may explain its demand; the
+// reports covering the dates include it.
+type CalendarNote struct {
+	ID         int64     `json:"id"`
+	BranchCode *string   `json:"branch_code,omitempty"` // nil for every branch
+	StartDate  string    `json:"start_date"`            // YYYY-MM-DD, club time
+	EndDate    string    `json:"end_date"`              // Inclusive; StartDate for a single day
+	Category   string    `json:"category"`
+	Title      string    `json:"title"`
+	Note       *string   `json:"note,omitempty"`
+	CreatedBy  *int64    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Covers reports whether the note covers a date (YYYY-MM-DD).
+func (n CalendarNote) Covers(date string) bool {
+	return n.StartDate <= date && date <= n.EndDate
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// PaymentMethodHouseAccount is the payment method of orders charged to the client's house account.
+const PaymentMethodHouseAccount = "house_account"
+
+// Types of house account entries.
+const (
+	AccountEntryCharge   = "charge"   // An order charged to the account
+	AccountEntryPayment  = "payment"  // A payment against the balance
+	AccountEntryReversal = "reversal" // Takes back the charge of an order that was cancelled, refunded or deleted
+)
+
+// ClientAccount is the house account of a client: the tab the client runs across visits.
+type ClientAccount struct {
+	ClientID         int64     `json:"client_id"`
+	ClientName       string    `json:"client_name"`
+	CreditLimit      Money     `json:"credit_limit"`
+	PaymentTermsDays int       `json:"payment_terms_days"` // Days after a charge that it is due
+	IsActive         bool      `json:"is_active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Version          int       `json:"version"`
+
+	// Computed from the ledger
+	Balance         Money `json:"balance"`          // What the client owes; negative if the client paid in advance
+	OverdueAmount   Money `json:"overdue_amount"`   // The part of the balance that is past due
+	AvailableCredit Money `json:"available_credit"` // What can still be charged before the credit limit
+	ChargesBlocked  bool  `json:"charges_blocked"`  // Inactive or overdue accounts take no new charges
+}
+
+// ClientAccountEntry is an entry of the ledger of a house account.
+type ClientAccountEntry struct {
+	ID            int64      `json:"id"`
+	ClientID      int64      `json:"client_id"`
+	EntryType     string     `json:"entry_type"`
+	Amount        Money      `json:"amount"` // Added to the balance: positive for charges, negative otherwise
+	OrderID       *int64     `json:"order_id,omitempty"`
+	PaymentMethod *string    `json:"payment_method,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
+	DueAt         *time.Time `json:"due_at,omitempty"`
+	CreatedBy     *int64     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ClientAccountStatement lists the entries of a house account in the half-open period
+// [From, To), between the balances before and after it.
+type ClientAccountStatement struct {
+	Account        ClientAccount        `json:"account"`
+	From           time.Time            `json:"from"`
+	To             time.Time            `json:"to"`
+	OpeningBalance Money                `json:"opening_balance"`
+	Charges        Money                `json:"charges"` // Sum of the charges in the period
+	Credits        Money                `json:"credits"` // Sum of the payments and reversals in the period, as a positive amount
+	ClosingBalance Money                `json:"closing_balance"`
+	Entries        []ClientAccountEntry `json:"entries"`
+}
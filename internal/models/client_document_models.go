@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Client document types.
+const (
+	ClientDocumentTournamentConsent  = "tournament_consent"   // Signed consent to take part in tournaments
+	ClientDocumentNightAccessConsent = "night_access_consent" // Signed consent to stay in the club at night
+	ClientDocumentID                 = "id_document"          // Scan of an identity document
+)
+
+// ClientDocumentTypes lists the client document types.
+var ClientDocumentTypes = []string{ClientDocumentTournamentConsent, ClientDocumentNightAccessConsent, ClientDocumentID}
+
+// ClientDocument describes a document of a client, served by GET /clients/:id/documents/:document_id.
+type ClientDocument struct {
+	ID           int64      `json:"id"`
+	ClientID     int64      `json:"client_id"`
+	DocumentType string     `json:"document_type"` // One of ClientDocumentTypes
+	ContentType  string     `json:"content_type"`
+	Size         int        `json:"size"`                 // In bytes
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // Nil if it does not expire
+	Expired      bool       `json:"expired"`
+	UploadedBy   *int64     `json:"uploaded_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ClientIDVerification records that staff checked the identity of a client.
+type ClientIDVerification struct {
+	ClientID   int64      `json:"client_id"`
+	DocumentID *int64     `json:"document_id,omitempty"` // The id_document checked, if it was uploaded
+	VerifiedBy *int64     `json:"verified_by,omitempty"`
+	VerifiedAt time.Time  `json:"verified_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil if it does not expire
+	Expired    bool       `json:"expired"`
+}
+
+// ClientDocuments is the documents and ID verification of a client, for the rules of
+// tournaments and night access.
+type ClientDocuments struct {
+	ClientID       int64                 `json:"client_id"`
+	IDVerified     bool                  `json:"id_verified"` // Verified and not expired
+	IDVerification *ClientIDVerification `json:"id_verification"`
+	// ValidDocumentTypes are the types of which the client has a document that has not expired
+	ValidDocumentTypes []string         `json:"valid_document_types"`
+	Documents          []ClientDocument `json:"documents"` // Newest first
+}
@@ -6,6 +6,7 @@ import "time"
 type Client struct {
 	ID            int64     `json:"id" db:"id"`
 	FullName      string    `json:"full_name" db:"full_name" binding:"required"`
+	Blacklisted   bool      `json:"blacklisted"` // Blacklisted now: new bookings are refused; see BlacklistedAt
 	PhoneNumber   *string   `json:"phone_number,omitempty" db:"phone_number"`
 	Email         *string   `json:"email,omitempty" db:"email"`
 	DateOfBirth   *string   `json:"date_of_birth,omitempty" db:"date_of_birth"` // Store as string, parse to time.Time when needed
@@ -13,5 +14,60 @@ type Client struct {
 	Notes         *string   `json:"notes,omitempty" db:"notes"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	AnonymizedAt  *time.Time `json:"anonymized_at,omitempty" db:"anonymized_at"` // Set once the personal data was scrubbed; the client can no longer be edited
+	Tags          []string   `json:"tags"`                                       // Segments the client belongs to, sorted
+
+	// Set while the client is on the blacklist, also once it expired, until it is lifted
+	BlacklistedAt    *time.Time `json:"blacklisted_at,omitempty" db:"blacklisted_at"`
+	BlacklistReason  *string    `json:"blacklist_reason,omitempty" db:"blacklist_reason"`
+	BlacklistedUntil *time.Time `json:"blacklisted_until,omitempty" db:"blacklisted_until"` // Nil keeps the client blacklisted until it is lifted
+	BlacklistedBy    *int64     `json:"blacklisted_by,omitempty" db:"blacklisted_by"`
+}
+
+// ClientDuplicateCandidate is an existing client that a new client probably duplicates.
+type ClientDuplicateCandidate struct {
+	ID             int64    `json:"id"`
+	FullName       string   `json:"full_name"`
+	PhoneNumber    *string  `json:"phone_number,omitempty"`
+	Email          *string  `json:"email,omitempty"`
+	NameSimilarity float64  `json:"name_similarity"` // Trigram similarity of the names, 0 to 1
+	MatchedOn      []string `json:"matched_on"`      // "phone", "email" and/or "name"
 }
 
+// ClientDataExport bundles the personal data the club stores about a client,
+// for data access requests. Loyalty points are part of the profile.
+type ClientDataExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Client     Client    `json:"client"`
+	Bookings   []Booking `json:"bookings"`
+	Orders     []Order   `json:"orders"` // With their order items
+}
+
+// Preset client tags. Any other tag may be used as well.
+const (
+	ClientTagVIP              = "vip"
+	ClientTagTournamentPlayer = "tournament_player"
+	ClientTagBlacklistWatch   = "blacklist_watch"
+)
+
+// ClientPresetTags lists the preset client tags.
+var ClientPresetTags = []string{ClientTagVIP, ClientTagTournamentPlayer, ClientTagBlacklistWatch}
+
+// ClientTagMaxLength is the longest a client tag may be.
+const ClientTagMaxLength = 50
+
+// ClientTagCount is a tag with the number of clients having it.
+type ClientTagCount struct {
+	Tag     string `json:"tag"`
+	Preset  bool   `json:"preset"`
+	Clients int    `json:"clients"`
+}
+
+// ClientTagReportItem sums up the visits and spending of the clients with a tag in a period.
+type ClientTagReportItem struct {
+	Tag        string `json:"tag"`
+	Clients    int    `json:"clients"`     // Clients with the tag
+	Orders     int    `json:"orders"`      // Completed and paid orders of those clients
+	TotalSales Money  `json:"total_sales"` // Final amount of those orders
+	Bookings   int    `json:"bookings"`    // Bookings of those clients starting in the period, other than cancelled and no-shows
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+// Clock-in violation reasons.
+const (
+	ClockInIPNotAllowed    = "ip_not_allowed"   // From outside the allowed networks, without a geofence to check
+	ClockInLocationMissing = "location_missing" // From outside the allowed networks, without device coordinates
+	ClockInOutsideGeofence = "outside_geofence" // From outside the allowed networks and the radius around the club
+)
+
+// ClockInViolationReasons lists the clock-in violation reasons.
+var ClockInViolationReasons = []string{ClockInIPNotAllowed, ClockInLocationMissing, ClockInOutsideGeofence}
+
+// ClockInSettings is the clock_in setting: where staff may clock in from. A clock-in is allowed
+// from an allowed IP address or network, or with device coordinates within the radius of the
+// club; without either restriction configured, from anywhere.
+type ClockInSettings struct {
+	AllowedIPs   []string `json:"allowed_ips,omitempty"` // Addresses or CIDR networks, e.g. "203.0.113.7" or "192.168.1.0/24"
+	Latitude     *float64 `json:"latitude,omitempty"`    // Of the club
+	Longitude    *float64 `json:"longitude,omitempty"`
+	RadiusMeters float64  `json:"radius_meters,omitempty"`
+
+	networks []*net.IPNet
+}
+
+// Restricted reports whether clock-ins are restricted at all.
+func (s ClockInSettings) Restricted() bool {
+	return len(s.networks) > 0 || s.hasGeofence()
+}
+
+func (s ClockInSettings) hasGeofence() bool {
+	return s.Latitude != nil && s.Longitude != nil && s.RadiusMeters > 0
+}
+
+// AllowsIP reports whether ip is in one of the allowed networks.
+func (s ClockInSettings) AllowsIP(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+	for _, network := range s.networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns why a clock-in from ip at the device coordinates (if sent) is not allowed, with
+// the distance from the club when the coordinates were checked; an empty reason if it is allowed.
+func (s ClockInSettings) Check(ip string, latitude, longitude *float64) (string, *float64) {
+	if !s.Restricted() || s.AllowsIP(ip) {
+		return "", nil
+	}
+	if !s.hasGeofence() {
+		return ClockInIPNotAllowed, nil
+	}
+	if latitude == nil || longitude == nil {
+		return ClockInLocationMissing, nil
+	}
+	distance := distanceMeters(*s.Latitude, *s.Longitude, *latitude, *longitude)
+	if distance > s.RadiusMeters {
+		return ClockInOutsideGeofence, &distance
+	}
+	return "", &distance
+}
+
+// distanceMeters returns the great-circle distance between two coordinates (haversine).
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusMeters = 6371000
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ValidCoordinates reports whether latitude and longitude are on the globe.
+func ValidCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// ParseClockInSettings parses the value of the clock_in setting, e.g. {"allowed_ips": ["192.168.1.0/24"],
+// "latitude": 43.2389, "longitude": 76.8897, "radius_meters": 150}. Empty, clock-ins are not restricted.
+func ParseClockInSettings(value string) (ClockInSettings, error) {
+	var settings ClockInSettings
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return ClockInSettings{}, fmt.Errorf("invalid clock-in settings: %w", err)
+	}
+	for _, allowed := range settings.AllowedIPs {
+		allowed = strings.TrimSpace(allowed)
+		if !strings.Contains(allowed, "/") {
+			ip := net.ParseIP(allowed)
+			if ip == nil {
+				return ClockInSettings{}, fmt.Errorf("invalid allowed IP address '%s'", allowed)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			allowed = fmt.Sprintf("%s/%d", allowed, bits)
+		}
+		_, network, err := net.ParseCIDR(allowed)
+		if err != nil {
+			return ClockInSettings{}, fmt.Errorf("invalid allowed network '%s'", allowed)
+		}
+		settings.networks = append(settings.networks, network)
+	}
+	if (settings.Latitude == nil) != (settings.Longitude == nil) {
+		return ClockInSettings{}, fmt.Errorf("latitude and longitude must be set together")
+	}
+	if settings.Latitude != nil {
+		if !ValidCoordinates(*settings.Latitude, *settings.Longitude) {
+			return ClockInSettings{}, fmt.Errorf("latitude must be between -90 and 90 and longitude between -180 and 180")
+		}
+		if settings.RadiusMeters <= 0 {
+			return ClockInSettings{}, fmt.Errorf("radius_meters must be positive with the club's coordinates")
+		}
+	}
+	return settings, nil
+}
+
+// ClockInViolation is a clock-in refused by the clock_in setting.
+type ClockInViolation struct {
+	ID               int64      `json:"id"`
+	StaffID          int64      `json:"staff_id"`
+	StaffName        string     `json:"staff_name"`
+	Reason           string     `json:"reason"` // One of ClockInViolationReasons
+	IPAddress        *string    `json:"ip_address,omitempty"`
+	Latitude         *float64   `json:"latitude,omitempty"`
+	Longitude        *float64   `json:"longitude,omitempty"`
+	DistanceMeters   *float64   `json:"distance_meters,omitempty"` // From the club, if the coordinates were checked
+	AttemptedAt      time.Time  `json:"attempted_at"`
+	OverriddenBy     *int64     `json:"overridden_by,omitempty"`
+	OverriddenAt     *time.Time `json:"overridden_at,omitempty"`
+	TimeClockEntryID *int64     `json:"time_clock_entry_id,omitempty"` // The clock-in recorded by the override
+}
+
+// ClockInViolationFilters selects clock-in violations.
+type ClockInViolationFilters struct {
+	StaffID    *int64
+	Overridden *bool
+	From       *time.Time // Attempted at or after
+	To         *time.Time // Attempted before
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Kinds of records that keep a business day from being closed.
+const (
+	DayCloseItemOrder        = "order"         // An order still open
+	DayCloseItemTableSession = "table_session" // A table session still running
+	DayCloseItemClockedIn    = "clocked_in"    // A staff member still clocked in; the ID is the staff member's
+)
+
+// DayCloseItem is a record that was still open when a business day was closed.
+type DayCloseItem struct {
+	Type        string `json:"type"` // One of the DayCloseItem constants
+	ID          int64  `json:"id"`
+	Description string `json:"description"`
+}
+
+// DailySummary is the snapshot of a business day of a branch taken when it was closed. Once a
+// day is closed, its orders and those of the days before may only be changed by an Admin.
+// Period reports backfill the summaries of days that were never closed; those are Backfilled,
+// do not lock the day and are replaced when the day is closed.
+type DailySummary struct {
+	ID           int64   `json:"id"`
+	BranchCode   string  `json:"branch_code"`
+	BusinessDate string  `json:"business_date"` // YYYY-MM-DD, club time
+	Revenue      Money   `json:"revenue"`       // SalesTotal plus SessionTotal
+	HoursBooked  float64 `json:"hours_booked"`  // Of the completed and active bookings that started during the day
+	// Sales are the completed and paid orders placed during the day
+	OrderCount     int                 `json:"order_count"`
+	SalesTotal     Money               `json:"sales_total"`
+	SalesByPayment []ShiftPaymentTotal `json:"sales_by_payment"`
+	CategorySales  []CategorySales     `json:"category_sales"`
+	CancelledCount int                 `json:"cancelled_count"`
+	RefundedCount  int                 `json:"refunded_count"`
+	RefundedTotal  Money               `json:"refunded_total"`
+	// Sessions are the table sessions stopped during the day
+	SessionCount        int   `json:"session_count"`
+	SessionTotal        Money `json:"session_total"`
+	CashSales           Money `json:"cash_sales"`
+	CashAccountPayments Money `json:"cash_account_payments"`
+	// ShiftReportCount and CashDifference sum up the end-of-shift reports of the day
+	ShiftReportCount int   `json:"shift_report_count"`
+	CashDifference   Money `json:"cash_difference"`
+	// ForcedClosures are the records closed by force to close the day, for ForceReason
+	ForcedClosures []DayCloseItem `json:"forced_closures"`
+	ForceReason    *string        `json:"force_reason,omitempty"`
+	Notes          *string        `json:"notes,omitempty"`
+	ClosedBy       *int64         `json:"closed_by,omitempty"`
+	ClosedAt       time.Time      `json:"closed_at"` // When the snapshot was taken
+	Backfilled     bool           `json:"backfilled"`
+}
+
+// CategorySales totals the items of a pricelist category sold in a period.
+type CategorySales struct {
+	CategoryID   *int64 `json:"category_id,omitempty"` // nil for items without a category
+	CategoryName string `json:"category_name"`
+	Quantity     int    `json:"quantity"`
+	Amount       Money  `json:"amount"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Types of registered devices.
+const (
+	DeviceTypePOS       = "pos"
+	DeviceTypeKiosk     = "kiosk"
+	DeviceTypeBarScreen = "bar_screen"
+)
+
+// DeviceTypes lists the valid device types.
+var DeviceTypes = []string{DeviceTypePOS, DeviceTypeKiosk, DeviceTypeBarScreen}
+
+// Order types a device may take, see DeviceSettings.AllowedOrderTypes.
+const (
+	DeviceOrderTypeTable     = "table"      // Orders served at a game table
+	DeviceOrderTypeCounter   = "counter"    // Orders at the counter, without a table
+	DeviceOrderTypeQuickSale = "quick_sale" // One-tap sales of quick sale presets
+)
+
+// DeviceOrderTypes lists the valid order types of devices.
+var DeviceOrderTypes = []string{DeviceOrderTypeTable, DeviceOrderTypeCounter, DeviceOrderTypeQuickSale}
+
+// Statuses of a device, from its last heartbeat.
+const (
+	DeviceStatusOnline  = "online"
+	DeviceStatusOffline = "offline"
+)
+
+// DeviceStatuses lists the statuses of devices.
+var DeviceStatuses = []string{DeviceStatusOnline, DeviceStatusOffline}
+
+// DeviceOnlineWindow is how long after its last heartbeat a device counts as online.
+const DeviceOnlineWindow = 2 * time.Minute
+
+// DeviceSettings is the configuration of one terminal, applied by the terminal itself.
+type DeviceSettings struct {
+	DefaultBranch     string   `json:"default_branch,omitempty"`      // Branch code; empty for the branch of the server
+	Printer           string   `json:"printer,omitempty"`             // Receipt printer, e.g. "EPSON TM-T20 (bar)"
+	AllowedOrderTypes []string `json:"allowed_order_types,omitempty"` // Of DeviceOrderTypes; empty allows all
+}
+
+// Device is a registered POS terminal, kiosk or bar screen. Devices register themselves with an
+// API key of the device scope and then send heartbeats.
+type Device struct {
+	ID           int64          `json:"id"`
+	DeviceUID    string         `json:"device_uid"` // Chosen by the device, e.g. its hardware serial; unique
+	Name         string         `json:"name"`
+	Type         string         `json:"type"` // One of DeviceTypes
+	Settings     DeviceSettings `json:"settings"`
+	AppVersion   *string        `json:"app_version,omitempty"`
+	APIKeyID     *int64         `json:"api_key_id,omitempty"` // The key it registered with; heartbeats must use it
+	LastIP       *string        `json:"last_ip,omitempty"`
+	RegisteredAt time.Time      `json:"registered_at"`
+	LastSeenAt   *time.Time     `json:"last_seen_at,omitempty"`
+	Status       string         `json:"status"` // One of DeviceStatuses, as of the request
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Version      int            `json:"version"`
+}
+
+// SetStatus sets the status of the device as of now.
+func (d *Device) SetStatus(now time.Time) {
+	d.Status = DeviceStatusOffline
+	if d.LastSeenAt != nil && now.Sub(*d.LastSeenAt) <= DeviceOnlineWindow {
+		d.Status = DeviceStatusOnline
+	}
+}
+
+// DeviceFilters defines the filters of the device list.
+type DeviceFilters struct {
+	Type   *string `form:"type"`
+	Status *string `form:"status"` // online or offline
+}
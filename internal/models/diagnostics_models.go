@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// QueryPlan is the execution plan of a canned query, as measured by EXPLAIN ANALYZE.
+type QueryPlan struct {
+	Query string          `json:"query"` // Name of the canned query, e.g. "orders_list"
+	SQL   string          `json:"sql"`
+	Args  []interface{}   `json:"args"`
+	Plan  json.RawMessage `json:"plan"` // EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) output
+}
+
+// ExpectedIndex is an index the listing and report queries rely on. Any index whose
+// leading key columns are Columns, in this order, provides it.
+type ExpectedIndex struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Purpose string   `json:"purpose"`
+}
+
+func (i ExpectedIndex) String() string {
+	return i.Table + " (" + strings.Join(i.Columns, ", ") + ")"
+}
+
+// Readiness statuses.
+const (
+	ReadinessReady       = "ready"
+	ReadinessUnavailable = "unavailable"
+)
+
+// Readiness reports whether the instance can serve requests, for GET /readyz.
+type Readiness struct {
+	Status   string            `json:"status"`             // "ready" or "unavailable"
+	Checks   map[string]string `json:"checks"`             // "ok" or the failure, by check
+	Warnings []string          `json:"warnings,omitempty"` // Problems that slow the service down without stopping it, e.g. missing indexes
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DiscountLimit is the largest order discount a role may grant without a manager
+// override. A nil field does not limit; when both are set, both apply.
+type DiscountLimit struct {
+	MaxPercent *decimal.Decimal `json:"max_percent,omitempty"` // Share of the order total, 0–100
+	MaxAmount  *Money           `json:"max_amount,omitempty"`
+}
+
+// DiscountLimits maps role names to their discount limit.
+type DiscountLimits map[string]DiscountLimit
+
+// ForRole returns the limit of role, matching the name case-insensitively.
+func (l DiscountLimits) ForRole(role string) (DiscountLimit, bool) {
+	for name, limit := range l {
+		if strings.EqualFold(name, role) {
+			return limit, true
+		}
+	}
+	return DiscountLimit{}, false
+}
+
+// Allows reports whether discount on an order totalling total is within the limit.
+func (l DiscountLimit) Allows(discount, total Money) bool {
+	if l.MaxAmount != nil && discount.Cmp(*l.MaxAmount) > 0 {
+		return false
+	}
+	if l.MaxPercent != nil {
+		maxDiscount := total.Decimal().Mul(*l.MaxPercent).Div(decimal.NewFromInt(100))
+		if discount.Decimal().GreaterThan(maxDiscount) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDiscountLimits parses the value of the discount_limits setting.
+func ParseDiscountLimits(value string) (DiscountLimits, error) {
+	limits := DiscountLimits{}
+	if strings.TrimSpace(value) == "" {
+		return limits, nil
+	}
+	if err := json.Unmarshal([]byte(value), &limits); err != nil {
+		return nil, fmt.Errorf("invalid discount limits: %w", err)
+	}
+	for role, limit := range limits {
+		if limit.MaxPercent != nil && (limit.MaxPercent.IsNegative() || limit.MaxPercent.GreaterThan(decimal.NewFromInt(100))) {
+			return nil, fmt.Errorf("max_percent of %s must be between 0 and 100", role)
+		}
+		if limit.MaxAmount != nil && limit.MaxAmount.IsNegative() {
+			return nil, fmt.Errorf("max_amount of %s cannot be negative", role)
+		}
+	}
+	return limits, nil
+}
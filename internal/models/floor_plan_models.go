@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Shapes of the tables on the floor plan.
+const (
+	FloorPlanShapeRectangle = "rectangle"
+	FloorPlanShapeCircle    = "circle" // An ellipse inside Width by Height
+)
+
+// FloorPlanShapes lists the shapes of the tables on the floor plan.
+var FloorPlanShapes = []string{FloorPlanShapeRectangle, FloorPlanShapeCircle}
+
+// Live statuses of the tables on the floor plan.
+const (
+	TableLiveFree        = "free"
+	TableLiveBusy        = "busy"        // A table session is running
+	TableLiveBookedSoon  = "booked_soon" // A pending or confirmed booking starts soon or has started
+	TableLiveMaintenance = "maintenance"
+)
+
+// TableLiveStatuses lists the live statuses of the tables on the floor plan.
+var TableLiveStatuses = []string{TableLiveFree, TableLiveBusy, TableLiveBookedSoon, TableLiveMaintenance}
+
+// FloorPlanTable is where a game table is drawn on the floor plan, in canvas units from the
+// top left corner.
+type FloorPlanTable struct {
+	TableID  int64   `json:"table_id"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation float64 `json:"rotation"` // Degrees clockwise
+	Shape    string  `json:"shape"`    // One of FloorPlanShapes
+	Zone     *string `json:"zone,omitempty"`
+}
+
+// FloorPlanTableStatus is a game table with its position on the floor plan, if placed, and
+// what is happening at it now.
+type FloorPlanTableStatus struct {
+	FloorPlanTable
+	Name             string     `json:"name"`
+	ConsoleType      *string    `json:"console_type,omitempty"`
+	Capacity         *int       `json:"capacity,omitempty"`
+	Placed           bool       `json:"-"`
+	TableStatus      string     `json:"-"`      // GameTable.Status
+	Status           string     `json:"status"` // One of TableLiveStatuses
+	SessionID        *int64     `json:"session_id,omitempty"`
+	SessionEndsAt    *time.Time `json:"session_ends_at,omitempty"` // Of a timed session
+	NextBookingID    *int64     `json:"next_booking_id,omitempty"`
+	NextBookingStart *time.Time `json:"next_booking_start,omitempty"`
+}
+
+// FloorPlan is the room view: the canvas, the placed tables with their live status and the
+// tables not placed yet.
+type FloorPlan struct {
+	Width          float64                `json:"width"` // Of the canvas; 0 before the plan is saved
+	Height         float64                `json:"height"`
+	UpdatedBy      *int64                 `json:"updated_by,omitempty"`
+	UpdatedAt      *time.Time             `json:"updated_at,omitempty"`
+	Zones          []string               `json:"zones"`
+	Tables         []FloorPlanTableStatus `json:"tables"`
+	UnplacedTables []FloorPlanTableStatus `json:"unplaced_tables"` // Without a position; x, y and the size are 0
+	GeneratedAt    time.Time              `json:"generated_at"`
+}
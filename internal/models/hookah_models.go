@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HookahSettings is the hookah_service setting.
+type HookahSettings struct {
+	// CoalChangeMinutes is how often the coals of a hookah are changed; staff are reminded when
+	// a change is due. 0 disables the reminders.
+	CoalChangeMinutes int    `json:"coal_change_minutes"`
+	CoalItemID        *int64 `json:"coal_item_id,omitempty"` // Pricelist item whose stock coal changes are deducted from
+	CoalsPerChange    int    `json:"coals_per_change"`       // Coals used by a change that does not give its own count
+}
+
+// CoalChangeInterval returns how often the coals of a hookah are changed; 0 if there are no reminders.
+func (s HookahSettings) CoalChangeInterval() time.Duration {
+	return time.Duration(s.CoalChangeMinutes) * time.Minute
+}
+
+// ParseHookahSettings parses the value of the hookah_service setting, e.g.
+// {"coal_change_minutes": 20, "coal_item_id": 42, "coals_per_change": 3}.
+func ParseHookahSettings(value string) (HookahSettings, error) {
+	var settings HookahSettings
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return HookahSettings{}, fmt.Errorf("invalid hookah service settings: %w", err)
+	}
+	if settings.CoalChangeMinutes < 0 {
+		return HookahSettings{}, fmt.Errorf("coal_change_minutes cannot be negative")
+	}
+	if settings.CoalsPerChange < 0 {
+		return HookahSettings{}, fmt.Errorf("coals_per_change cannot be negative")
+	}
+	return settings, nil
+}
+
+// Hookah is a hookah being served: a HOOKAH item of an order.
+type Hookah struct {
+	OrderItemID     int64      `json:"order_item_id"`
+	OrderID         int64      `json:"order_id"`
+	TableID         *int64     `json:"table_id,omitempty"`
+	PricelistItemID int64      `json:"pricelist_item_id"`
+	ItemName        string     `json:"item_name"`
+	Quantity        int        `json:"quantity"`
+	OrderStatus     string     `json:"order_status"`
+	StartedAt       time.Time  `json:"started_at"`                // When the item was ordered
+	CoalChangedAt   *time.Time `json:"coal_changed_at,omitempty"` // Last coal change
+	CoalRemindedAt  *time.Time `json:"-"`                         // Last reminder to change the coals
+	NextCoalChange  *time.Time `json:"next_coal_change,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"` // When the hookah was taken away
+}
+
+// LastCoals returns when the coals of the hookah were last lit: the last change, or when it was ordered.
+func (h *Hookah) LastCoals() time.Time {
+	if h.CoalChangedAt != nil {
+		return *h.CoalChangedAt
+	}
+	return h.StartedAt
+}
+
+// CoalChange is a change of the coals of a hookah.
+type CoalChange struct {
+	ID                  int64     `json:"id"`
+	OrderItemID         int64     `json:"order_item_id"`
+	Coals               int       `json:"coals"`
+	ChangedBy           *int64    `json:"changed_by,omitempty"`
+	ChangedAt           time.Time `json:"changed_at"`
+	InventoryMovementID *int64    `json:"inventory_movement_id,omitempty"` // Deduction of the coals from stock, if coals are tracked
+}
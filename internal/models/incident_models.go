@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// Incident types.
+const (
+	IncidentTypeEquipmentDamage  = "equipment_damage"
+	IncidentTypeClientMisconduct = "client_misconduct"
+	IncidentTypeCashDiscrepancy  = "cash_discrepancy"
+	IncidentTypeOther            = "other"
+)
+
+// IncidentTypes lists the valid incident types.
+var IncidentTypes = []string{IncidentTypeEquipmentDamage, IncidentTypeClientMisconduct, IncidentTypeCashDiscrepancy, IncidentTypeOther}
+
+// Incident severities, from the least to the most severe.
+const (
+	IncidentSeverityLow      = "low"
+	IncidentSeverityMedium   = "medium"
+	IncidentSeverityHigh     = "high"
+	IncidentSeverityCritical = "critical"
+)
+
+// IncidentSeverities lists the valid incident severities.
+var IncidentSeverities = []string{IncidentSeverityLow, IncidentSeverityMedium, IncidentSeverityHigh, IncidentSeverityCritical}
+
+// Review statuses of an incident.
+const (
+	IncidentStatusOpen      = "open"      // Waiting for an Admin's review
+	IncidentStatusConfirmed = "confirmed" // Its penalty counts in the payroll
+	IncidentStatusDismissed = "dismissed"
+)
+
+// IncidentStatuses lists the valid incident statuses.
+var IncidentStatuses = []string{IncidentStatusOpen, IncidentStatusConfirmed, IncidentStatusDismissed}
+
+// Incident is something that went wrong at the club, reported by staff and reviewed by an Admin.
+type Incident struct {
+	ID           int64     `json:"id"`
+	BranchCode   string    `json:"branch_code"`
+	IncidentType string    `json:"incident_type"` // One of IncidentTypes
+	Severity     string    `json:"severity"`      // One of IncidentSeverities
+	Description  string    `json:"description"`
+	OccurredAt   time.Time `json:"occurred_at"`
+	StaffID      *int64    `json:"staff_id,omitempty"`
+	ClientID     *int64    `json:"client_id,omitempty"`
+	TableID      *int64    `json:"table_id,omitempty"`
+	OrderID      *int64    `json:"order_id,omitempty"`
+	// PenaltyAmount is deducted from the pay of the staff member once the incident is confirmed
+	PenaltyAmount Money           `json:"penalty_amount"`
+	Status        string          `json:"status"` // One of IncidentStatuses
+	ReportedBy    *int64          `json:"reported_by,omitempty"`
+	ReviewedBy    *int64          `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time      `json:"reviewed_at,omitempty"`
+	ReviewNotes   *string         `json:"review_notes,omitempty"`
+	Photos        []IncidentPhoto `json:"photos"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Version       int             `json:"version"`
+}
+
+// IncidentPhoto describes a photo attached to an incident, served by GET /incidents/:id/photos/:photo_id.
+type IncidentPhoto struct {
+	ID          int64     `json:"id"`
+	IncidentID  int64     `json:"incident_id"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"` // In bytes
+	UploadedBy  *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IncidentFilters selects incidents.
+type IncidentFilters struct {
+	Status       string // Empty for any status
+	IncidentType string
+	Severity     string
+	StaffID      *int64
+	ClientID     *int64
+	From         *time.Time // Occurred at or after
+	To           *time.Time // Occurred before
+}
+
+// PayrollLine is the pay of a staff member for a month: the salary less the penalties of
+// the incidents confirmed against them that occurred in the month and of their attendance
+// anomalies (see AttendanceSettings).
+type PayrollLine struct {
+	StaffID                int64   `json:"staff_id"`
+	FullName               *string `json:"full_name,omitempty"`
+	Position               *string `json:"position,omitempty"`
+	Salary                 Money   `json:"salary"` // Monthly salary; 0 if not set
+	PenaltyCount           int     `json:"penalty_count"`
+	Penalties              Money   `json:"penalties"`
+	AttendancePenaltyCount int     `json:"attendance_penalty_count"` // Anomalies of the month, if penalized
+	AttendancePenalties    Money   `json:"attendance_penalties"`
+	NetPay                 Money   `json:"net_pay"`
+}
+
+// Payroll is the pay of every staff member for a month.
+type Payroll struct {
+	Month                    string        `json:"month"` // YYYY-MM, club time
+	Lines                    []PayrollLine `json:"lines"`
+	TotalSalary              Money         `json:"total_salary"`
+	TotalPenalties           Money         `json:"total_penalties"`
+	TotalAttendancePenalties Money         `json:"total_attendance_penalties"`
+	TotalNetPay              Money         `json:"total_net_pay"`
+}
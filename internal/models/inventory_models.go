@@ -11,31 +11,129 @@ type PricelistCategory struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Pricelist item types.
+const (
+	ItemTypeBar     = "BAR"
+	ItemTypeHookah  = "HOOKAH"
+	ItemTypeSnack   = "SNACK"
+	ItemTypeService = "SERVICE"
+)
+
+// ItemTypes lists the valid pricelist item types.
+var ItemTypes = []string{ItemTypeBar, ItemTypeHookah, ItemTypeSnack, ItemTypeService}
+
+// IsValidItemType checks if the provided string is one of ItemTypes.
+func IsValidItemType(itemType string) bool {
+	for _, valid := range ItemTypes {
+		if itemType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Stock units, the units the stock of an item is counted in.
+const (
+	UnitPieces      = "pcs"
+	UnitMilliliters = "ml"
+	UnitGrams       = "g"
+)
+
+// StockUnits lists the valid stock units.
+var StockUnits = []string{UnitPieces, UnitMilliliters, UnitGrams}
+
+// IsValidStockUnit checks if the provided string is one of StockUnits.
+func IsValidStockUnit(unit string) bool {
+	for _, valid := range StockUnits {
+		if unit == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ItemUnits are the units of measure of a pricelist item: the stock unit its stock is counted in,
+// how much of it a sold unit takes and how much a purchase unit holds.
+type ItemUnits struct {
+	StockUnit        string   `json:"stock_unit" db:"stock_unit"`                 // One of StockUnits
+	PortionSize      Quantity `json:"portion_size" db:"portion_size"`             // Stock units a sold unit takes, e.g. 40 (ml)
+	PurchaseUnit     *string  `json:"purchase_unit,omitempty" db:"purchase_unit"` // Name of the unit it is bought in, e.g. "bottle"
+	PurchaseUnitSize Quantity `json:"purchase_unit_size" db:"purchase_unit_size"` // Stock units in a purchase unit, e.g. 1000 (ml)
+}
+
+// DefaultItemUnits counts pieces, one per sale and purchase.
+func DefaultItemUnits() ItemUnits {
+	return ItemUnits{StockUnit: UnitPieces, PortionSize: NewQuantityFromInt(1), PurchaseUnitSize: NewQuantityFromInt(1)}
+}
+
+// ForPortions returns the stock count sold units of the item take.
+func (u ItemUnits) ForPortions(count int) Quantity { return u.PortionSize.MulInt(count) }
+
+// FromPurchaseUnits returns the stock quantity purchase units of the item hold.
+func (u ItemUnits) FromPurchaseUnits(quantity Quantity) Quantity { return quantity.Mul(u.PurchaseUnitSize) }
+
 // PricelistItem represents an item in the pricelist (generic for bar, hookah, snacks, services)
 type PricelistItem struct {
 	ID                int64     `json:"id" db:"id"`
 	CategoryID        int64     `json:"category_id" db:"category_id" binding:"required"`
 	Name              string    `json:"name" db:"name" binding:"required"`
 	Description       *string   `json:"description,omitempty" db:"description"`
-	Price             float64   `json:"price" db:"price" binding:"required,gt=0"`
+	Price             Money     `json:"price" db:"price" binding:"required,gt=0"`
 	SKU               *string   `json:"sku,omitempty" db:"sku"`
 	IsAvailable       bool      `json:"is_available" db:"is_available"`
 	ItemType          string    `json:"item_type" db:"item_type" binding:"required"` // e.g., BAR, HOOKAH, SNACK, SERVICE
 	TracksStock       bool      `json:"tracks_stock" db:"tracks_stock"`             // Whether this item's stock is tracked
-	CurrentStock      *int      `json:"current_stock,omitempty" db:"current_stock"` // Nullable for items that don't track stock or if stock is not yet set
-	LowStockThreshold *int      `json:"low_stock_threshold,omitempty" db:"low_stock_threshold"`
+	CurrentStock      *Quantity `json:"current_stock,omitempty" db:"current_stock"` // In the stock unit; nullable for items that don't track stock or if stock is not yet set
+	LowStockThreshold *Quantity `json:"low_stock_threshold,omitempty" db:"low_stock_threshold"`
+	ItemUnits
+	TaxClass          *string   `json:"tax_class,omitempty" db:"tax_class"` // One of the classes of the tax setting; nil for its default class
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	Version           int       `json:"version" db:"version"` // Optimistic lock, incremented on every update (including stock changes)
 	Category          *PricelistCategory `json:"category,omitempty"` // For joining with Category
 }
 
+// Movement types, the kinds of change in stock an inventory movement records.
+const (
+	MovementTypePurchase            = "purchase"
+	MovementTypeAdjustmentIn        = "adjustment_in"
+	MovementTypeAdjustmentOut       = "adjustment_out"
+	MovementTypeStocktakeAdjustment = "stocktake_adjustment" // Brings the stock to what was counted, in or out
+	MovementTypeTransferIn          = "transfer_in"          // Received from another branch or storage
+	MovementTypeTransferOut         = "transfer_out"         // Sent to another branch or storage
+	MovementTypeSpoilage            = "spoilage"
+	MovementTypeSale                = "sale"                // Recorded by order operations
+	MovementTypeReturnCancellation  = "return_cancellation" // Recorded by order operations
+	MovementTypeReturnDeletion      = "return_deletion"     // Recorded by order operations
+	MovementTypeComponentUsage      = "component_usage"     // Consumables used to serve an item, e.g. hookah coals
+)
+
+// ManualMovementTypes can be recorded by staff; the other MovementTypes are recorded by the
+// operations that change the stock.
+var (
+	ManualMovementTypes = []string{MovementTypePurchase, MovementTypeAdjustmentIn, MovementTypeAdjustmentOut,
+		MovementTypeStocktakeAdjustment, MovementTypeTransferIn, MovementTypeTransferOut, MovementTypeSpoilage}
+	MovementTypes = append(append([]string{}, ManualMovementTypes...), MovementTypeSale, MovementTypeReturnCancellation,
+		MovementTypeReturnDeletion, MovementTypeComponentUsage)
+)
+
+// IsValidMovementType checks if the provided string is one of MovementTypes.
+func IsValidMovementType(movementType string) bool {
+	for _, valid := range MovementTypes {
+		if movementType == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // InventoryMovement represents a change in stock for an item
 type InventoryMovement struct {
 	ID              int64     `json:"id" db:"id"`
 	PricelistItemID int64     `json:"pricelist_item_id" db:"pricelist_item_id" binding:"required"`
 	StaffID         *int64    `json:"staff_id,omitempty" db:"staff_id"`
-	MovementType    string    `json:"movement_type" db:"movement_type" binding:"required"` // e.g., purchase, sale, adjustment_in, adjustment_out, spoilage
-	QuantityChanged int       `json:"quantity_changed" db:"quantity_changed" binding:"required"`
+	MovementType    string    `json:"movement_type" db:"movement_type" binding:"required"` // One of MovementTypes
+	QuantityChanged Quantity  `json:"quantity_changed" db:"quantity_changed" binding:"required"` // In the stock unit of the item
 	Reason          *string   `json:"reason,omitempty" db:"reason"`
 	MovementDate    time.Time `json:"movement_date" db:"movement_date"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Invitation statuses, derived from the timestamps of an invitation.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusExpired  = "expired"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation invites a staff member, by email or phone, to create a user with a role. It
+// can be accepted once, until it expires or an Admin revokes it.
+type Invitation struct {
+	ID             int64      `json:"id"`
+	TokenHash      string     `json:"-"` // SHA-256 of the token, hex encoded
+	Email          *string    `json:"email,omitempty"`
+	PhoneNumber    *string    `json:"phone_number,omitempty"`
+	FullName       *string    `json:"full_name,omitempty"`
+	RoleID         int64      `json:"role_id"`
+	RoleName       string     `json:"role_name"`
+	InvitedBy      *int64     `json:"invited_by,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserID *int64     `json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Status         string     `json:"status"` // One of the InvitationStatus constants, as of loading
+}
+
+// SetStatus derives Status from the timestamps as of now.
+func (i *Invitation) SetStatus(now time.Time) {
+	switch {
+	case i.AcceptedAt != nil:
+		i.Status = InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		i.Status = InvitationStatusRevoked
+	case !now.Before(i.ExpiresAt):
+		i.Status = InvitationStatusExpired
+	default:
+		i.Status = InvitationStatusPending
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Locker is a locker or shelf clients rent for a visit.
+type Locker struct {
+	ID            int64         `json:"id"`
+	BranchCode    string        `json:"branch_code"`
+	Number        string        `json:"number"`
+	RentalFee     Money         `json:"rental_fee"` // Default fee of a rental
+	Deposit       Money         `json:"deposit"`    // Default deposit of a rental
+	IsActive      bool          `json:"is_active"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Version       int           `json:"version"`
+	CurrentRental *LockerRental `json:"current_rental,omitempty"` // Nil if the locker is free
+}
+
+// LockerRental is the rental of a locker for a visit, until it is released.
+type LockerRental struct {
+	ID              int64      `json:"id"`
+	LockerID        int64      `json:"locker_id"`
+	LockerNumber    string     `json:"locker_number"`
+	ClientID        *int64     `json:"client_id,omitempty"`
+	TableSessionID  *int64     `json:"table_session_id,omitempty"`
+	BookingID       *int64     `json:"booking_id,omitempty"`
+	Fee             Money      `json:"fee"`     // Billed with the table session of the visit
+	Deposit         Money      `json:"deposit"` // Taken when rented, returned on release unless kept
+	Notes           *string    `json:"notes,omitempty"`
+	RentedBy        *int64     `json:"rented_by,omitempty"`
+	RentedAt        time.Time  `json:"rented_at"`
+	ReleasedAt      *time.Time `json:"released_at,omitempty"`
+	ReleasedBy      *int64     `json:"released_by,omitempty"`
+	DepositReturned *bool      `json:"deposit_returned,omitempty"` // Set on release
+}
+
+// Active reports whether the locker has not been released.
+func (r *LockerRental) Active() bool {
+	return r.ReleasedAt == nil
+}
+
+// LockerRentalFilters selects locker rentals.
+type LockerRentalFilters struct {
+	ActiveOnly     bool
+	ClientID       *int64
+	TableSessionID *int64
+	BookingID      *int64
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statuses of a lost and found item.
+const (
+	LostFoundStatusStored   = "stored"
+	LostFoundStatusReturned = "returned"
+)
+
+// DefaultLostFoundRetentionDays is how long lost and found entries are kept without the lost_and_found setting.
+const DefaultLostFoundRetentionDays = 90
+
+// LostFoundSettings is the lost_and_found setting.
+type LostFoundSettings struct {
+	// RetentionDays is how long after it was found an entry is kept, returned or not.
+	// 0 keeps the entries forever.
+	RetentionDays int `json:"retention_days"`
+}
+
+// DefaultLostFoundSettings returns the settings used without the lost_and_found setting.
+func DefaultLostFoundSettings() LostFoundSettings {
+	return LostFoundSettings{RetentionDays: DefaultLostFoundRetentionDays}
+}
+
+// Retention returns how long the entries are kept; 0 if they are kept forever.
+func (s LostFoundSettings) Retention() time.Duration {
+	return time.Duration(s.RetentionDays) * 24 * time.Hour
+}
+
+// ParseLostFoundSettings parses the value of the lost_and_found setting, e.g. {"retention_days": 60}.
+// A missing retention_days keeps the default.
+func ParseLostFoundSettings(value string) (LostFoundSettings, error) {
+	settings := DefaultLostFoundSettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return LostFoundSettings{}, fmt.Errorf("invalid lost and found settings: %w", err)
+	}
+	if settings.RetentionDays < 0 {
+		return LostFoundSettings{}, fmt.Errorf("retention_days cannot be negative")
+	}
+	return settings, nil
+}
+
+// LostFoundItem is an item left behind by a client, logged until it is returned or purged.
+type LostFoundItem struct {
+	ID                 int64      `json:"id"`
+	BranchCode         string     `json:"branch_code"`
+	Description        string     `json:"description"`
+	TableID            *int64     `json:"table_id,omitempty"` // Where it was found
+	FoundAt            time.Time  `json:"found_at"`
+	FoundBy            *int64     `json:"found_by,omitempty"`
+	Notes              *string    `json:"notes,omitempty"`
+	HasPhoto           bool       `json:"has_photo"` // Served by GET /lost-and-found/:id/photo
+	Status             string     `json:"status"`
+	ReturnedToClientID *int64     `json:"returned_to_client_id,omitempty"`
+	ReturnedToName     *string    `json:"returned_to_name,omitempty"` // Who collected it if not a known client
+	ReturnedAt         *time.Time `json:"returned_at,omitempty"`
+	ReturnedBy         *int64     `json:"returned_by,omitempty"`
+	PurgeAt            *time.Time `json:"purge_at,omitempty"` // When it is purged; nil if entries are kept forever
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	Version            int        `json:"version"`
+}
+
+// LostFoundFilters selects lost and found items.
+type LostFoundFilters struct {
+	Status  string // Empty for any status
+	TableID *int64
+	From    *time.Time // Found at or after
+	To      *time.Time // Found before
+	Search  string     // Part of the description
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+// Money is a monetary amount in major units of the configured club currency,
+// backed by an arbitrary-precision decimal and stored in NUMERIC columns.
+//
+// Rounding rules:
+//   - Amounts entering the system (JSON input) are rounded to the currency's
+//     decimals, half away from zero.
+//   - Arithmetic (line totals, sums, discounts) is exact; no intermediate rounding.
+//   - Derived values such as per-item discount shares are rounded with Round
+//     before they are stored, and every amount is rounded when serialized.
+type Money struct {
+	d decimal.Decimal
+}
+
+// ZeroMoney is the zero amount.
+var ZeroMoney = Money{}
+
+// NewMoney creates Money from a decimal value.
+func NewMoney(d decimal.Decimal) Money {
+	return Money{d: d}
+}
+
+// NewMoneyFromInt creates Money from a whole number of major units.
+func NewMoneyFromInt(value int64) Money {
+	return Money{d: decimal.NewFromInt(value)}
+}
+
+// ParseMoney parses a decimal amount in major units ("1250.50"), rounded to the currency decimals.
+func ParseMoney(value string) (Money, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return ZeroMoney, err
+	}
+	return Money{d: d}.Round(), nil
+}
+
+// Decimal returns the underlying decimal value.
+func (m Money) Decimal() decimal.Decimal { return m.d }
+
+// Add returns m + other.
+func (m Money) Add(other Money) Money { return Money{d: m.d.Add(other.d)} }
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) Money { return Money{d: m.d.Sub(other.d)} }
+
+// MulInt returns m multiplied by a quantity.
+func (m Money) MulInt(quantity int) Money {
+	return Money{d: m.d.Mul(decimal.NewFromInt(int64(quantity)))}
+}
+
+// ForMinutes returns the amount of the hourly rate m for minutes, rounded to the currency decimals.
+func (m Money) ForMinutes(minutes int) Money {
+	return Money{d: m.d.Mul(decimal.NewFromInt(int64(minutes))).Div(decimal.NewFromInt(60))}.Round()
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money { return Money{d: m.d.Neg()} }
+
+// Round rounds m to the currency decimals, half away from zero.
+func (m Money) Round() Money {
+	return Money{d: m.d.Round(int32(utils.CurrentCurrency().Decimals))}
+}
+
+// Allocate splits m over len(weights) shares in proportion to the weights, rounded to the
+// currency decimals by the largest remainder method: every share is rounded down, and the
+// minor units left over go one each to the shares with the largest remainders, the first
+// share winning a tie. The shares add up to m rounded. Weights that are not positive get
+// nothing; if none is positive, all shares are zero.
+func (m Money) Allocate(weights []Money) []Money {
+	if m.IsNegative() {
+		shares := m.Neg().Allocate(weights)
+		for i := range shares {
+			shares[i] = shares[i].Neg()
+		}
+		return shares
+	}
+	shares := make([]Money, len(weights))
+	weightSum := decimal.Zero
+	for _, w := range weights {
+		if w.IsPositive() {
+			weightSum = weightSum.Add(w.d)
+		}
+	}
+	if weightSum.IsZero() {
+		return shares
+	}
+
+	decimals := int32(utils.CurrentCurrency().Decimals)
+	units := m.Round().d.Shift(decimals) // Whole minor units
+	remainders := make([]decimal.Decimal, len(weights))
+	leftover := units
+	for i, w := range weights {
+		if !w.IsPositive() {
+			continue
+		}
+		// Exact integer division, so remainders compare exactly
+		shares[i].d, remainders[i] = units.Mul(w.d).QuoRem(weightSum, 0)
+		leftover = leftover.Sub(shares[i].d)
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].GreaterThan(remainders[order[b]]) })
+	for _, i := range order {
+		if !leftover.IsPositive() {
+			break
+		}
+		if weights[i].IsPositive() {
+			shares[i].d = shares[i].d.Add(decimal.NewFromInt(1))
+			leftover = leftover.Sub(decimal.NewFromInt(1))
+		}
+	}
+	for i := range shares {
+		shares[i].d = shares[i].d.Shift(-decimals)
+	}
+	return shares
+}
+
+// IsZero reports whether m == 0.
+func (m Money) IsZero() bool { return m.d.IsZero() }
+
+// IsNegative reports whether m < 0.
+func (m Money) IsNegative() bool { return m.d.IsNegative() }
+
+// IsPositive reports whether m > 0.
+func (m Money) IsPositive() bool { return m.d.IsPositive() }
+
+// Cmp compares m and other, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) int { return m.d.Cmp(other.d) }
+
+// fixed renders m rounded to the currency decimals as a plain decimal string.
+func (m Money) fixed() string {
+	return m.d.StringFixed(int32(utils.CurrentCurrency().Decimals))
+}
+
+// String renders the amount for display using the currency symbol and separators.
+func (m Money) String() string {
+	return utils.FormatAmount(m.fixed())
+}
+
+// MarshalJSON renders the amount as a JSON number in major units ("1250.50").
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.fixed()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or string in major units. The raw text is
+// parsed directly so no binary floating point rounding is involved.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	parsed, err := ParseMoney(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return fmt.Errorf("invalid monetary amount: %w", err)
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing the amount as a NUMERIC string.
+func (m Money) Value() (driver.Value, error) {
+	return m.d.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC (and integer) columns.
+func (m *Money) Scan(src interface{}) error {
+	if src == nil {
+		*m = ZeroMoney
+		return nil
+	}
+	if err := m.d.Scan(src); err != nil {
+		return fmt.Errorf("scanning money: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OrderArchiveSettings is the order_archive setting.
+type OrderArchiveSettings struct {
+	// RetentionMonths is how many months after its business date a completed order is moved
+	// to the archive. 0 never archives orders.
+	RetentionMonths int `json:"retention_months"`
+}
+
+// Enabled reports whether orders are archived.
+func (s OrderArchiveSettings) Enabled() bool {
+	return s.RetentionMonths > 0
+}
+
+// ParseOrderArchiveSettings parses the value of the order_archive setting, e.g. {"retention_months": 12}.
+func ParseOrderArchiveSettings(value string) (OrderArchiveSettings, error) {
+	var settings OrderArchiveSettings
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return OrderArchiveSettings{}, fmt.Errorf("invalid order archive settings: %w", err)
+	}
+	if settings.RetentionMonths < 0 {
+		return OrderArchiveSettings{}, fmt.Errorf("retention_months cannot be negative")
+	}
+	return settings, nil
+}
+
+// ArchivedOrder is an order moved to the archive, with its items.
+type ArchivedOrder struct {
+	Order
+	ArchivedAt time.Time `json:"archived_at"`
+}
@@ -1,23 +1,41 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // Order represents a customer's order.
 type Order struct {
-	ID             int64      `json:"id" db:"id"`
-	ClientID       *int64     `json:"client_id,omitempty" db:"client_id"`
-	BookingID      *int64     `json:"booking_id,omitempty" db:"booking_id"`
-	StaffID        *int64     `json:"staff_id,omitempty" db:"staff_id"` // UserID of the staff member who took/processed the order
-	TableID        *int64     `json:"table_id,omitempty" db:"table_id"` // Optional, if order is associated with a table
-	OrderTime      time.Time  `json:"order_time" db:"order_time"`
-	Status         string     `json:"status" db:"status"` // e.g., pending, completed, cancelled, preparing, ready, served, paid
-	TotalAmount    float64    `json:"total_amount" db:"total_amount"`
-	DiscountAmount *float64   `json:"discount_amount,omitempty" db:"discount_amount"`
-	FinalAmount    float64    `json:"final_amount" db:"final_amount"`
-	PaymentMethod  *string    `json:"payment_method,omitempty" db:"payment_method"`
-	Notes          *string    `json:"notes,omitempty" db:"notes"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	ID               int64     `json:"id" db:"id"`
+	OrderNumber      string    `json:"order_number"` // Display number, e.g. "2024-06-01/#37"; see FormatOrderNumber
+	BranchCode       string    `json:"branch_code" db:"branch_code"`
+	BusinessDate     string    `json:"business_date" db:"business_date"` // Club-local date (YYYY-MM-DD) the daily number belongs to
+	DailyNumber      int       `json:"daily_number" db:"daily_number"`   // Sequence per branch and business date, starting at 1
+	ClientID         *int64    `json:"client_id,omitempty" db:"client_id"`
+	BookingID        *int64    `json:"booking_id,omitempty" db:"booking_id"`
+	StaffID          *int64    `json:"staff_id,omitempty" db:"staff_id"` // UserID of the staff member who took/processed the order
+	TableID          *int64    `json:"table_id,omitempty" db:"table_id"` // Optional, if order is associated with a table
+	OrderTime        time.Time `json:"order_time" db:"order_time"`
+	Status           string    `json:"status" db:"status"` // e.g., pending, completed, cancelled, preparing, ready, served, paid
+	TotalAmount      Money     `json:"total_amount" db:"total_amount"`
+	DiscountAmount   *Money    `json:"discount_amount,omitempty" db:"discount_amount"`
+	FinalAmount      Money     `json:"final_amount" db:"final_amount"` // Includes TaxAmount, whether prices include tax or not, and ServiceChargeAmount
+	TaxAmount        Money     `json:"tax_amount" db:"tax_amount"`
+	PricesIncludeTax bool      `json:"prices_include_tax" db:"prices_include_tax"` // Tax mode when the order was created
+	PaymentMethod    *string   `json:"payment_method,omitempty" db:"payment_method"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	Version          int       `json:"version" db:"version"` // Optimistic lock, incremented on every update
+
+	GuestCount          *int             `json:"guest_count,omitempty" db:"guest_count"`
+	ServiceChargeRate   *decimal.Decimal `json:"service_charge_rate,omitempty" db:"service_charge_rate"` // Percent; nil for an order without service charge
+	ServiceChargeAmount Money            `json:"service_charge_amount" db:"service_charge_amount"`       // Untaxed, not shared out over the items
+	UUID                *string          `json:"uuid,omitempty" db:"uuid"`                               // Given by the client that created the order, so a retry does not create it twice
+	Source              string           `json:"source" db:"source"`                                     // One of OrderSources: the channel the order was placed through
 
 	// Joined fields (populated by repository, not direct DB columns in 'orders' table)
 	Client      *Client      `json:"client,omitempty"`
@@ -26,17 +44,56 @@ type Order struct {
 	OrderItems  []OrderItem  `json:"order_items,omitempty"`
 }
 
+// Order sources, i.e. the channels orders and bookings are placed through. The source is
+// taken from the authenticated principal: the API key scope of a self-service channel, the
+// client app for users of the Client role, and the POS for staff.
+const (
+	OrderSourcePOS       = "pos"
+	OrderSourceKiosk     = "kiosk"
+	OrderSourceClientApp = "client_app"
+	OrderSourceTelegram  = "telegram"
+)
+
+// OrderSources lists the valid order sources.
+var OrderSources = []string{OrderSourcePOS, OrderSourceKiosk, OrderSourceClientApp, OrderSourceTelegram}
+
+// IsValidOrderSource reports whether source is one of OrderSources.
+func IsValidOrderSource(source string) bool {
+	for _, valid := range OrderSources {
+		if source == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSelfServiceSource reports whether source is a channel clients use without staff.
+func IsSelfServiceSource(source string) bool {
+	return source != "" && source != OrderSourcePOS
+}
+
+// FormatOrderNumber renders the display number of an order, e.g. "2024-06-01/#37".
+func FormatOrderNumber(businessDate string, dailyNumber int) string {
+	return fmt.Sprintf("%s/#%d", businessDate, dailyNumber)
+}
+
 // OrderItem represents an individual item within an order.
 type OrderItem struct {
-	ID              int64     `json:"id" db:"id"`
-	OrderID         int64     `json:"order_id" db:"order_id"`
-	PricelistItemID int64     `json:"pricelist_item_id" db:"pricelist_item_id"`
-	Quantity        int       `json:"quantity" db:"quantity"`
-	UnitPrice       float64   `json:"unit_price" db:"unit_price"` // Price at the time of order
-	TotalPrice      float64   `json:"total_price" db:"total_price"`
-	Notes           *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	ID              int64            `json:"id" db:"id"`
+	OrderID         int64            `json:"order_id" db:"order_id"`
+	PricelistItemID int64            `json:"pricelist_item_id" db:"pricelist_item_id"`
+	Quantity        int              `json:"quantity" db:"quantity"`
+	StockQuantity   Quantity         `json:"stock_quantity" db:"stock_quantity"` // Stock the item took: the quantity in portions of the item's stock unit
+	UnitPrice       Money            `json:"unit_price" db:"unit_price"`         // Price at the time of order
+	TotalPrice      Money            `json:"total_price" db:"total_price"`
+	DiscountAmount  Money            `json:"discount_amount" db:"discount_amount"` // Share of the order discount, allocated at creation
+	TaxClass        *string          `json:"tax_class,omitempty" db:"tax_class"`   // Nil for an untaxed item
+	TaxRate         *decimal.Decimal `json:"tax_rate,omitempty" db:"tax_rate"`     // Percent
+	TaxAmount       Money            `json:"tax_amount" db:"tax_amount"`           // On the line total after discount, included in it or added to it
+	Notes           *string          `json:"notes,omitempty" db:"notes"`
+	UUID            *string          `json:"uuid,omitempty" db:"uuid"` // Given by the client that created the item
+	CreatedAt       time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at" db:"updated_at"`
 
 	// Joined fields
 	PricelistItem *PricelistItem `json:"pricelist_item,omitempty"` // To get item name, SKU etc.
@@ -45,11 +102,20 @@ type OrderItem struct {
 // OrderFilters defines the available filters for querying orders.
 // This struct is used by both the service and repository layers.
 type OrderFilters struct {
-	ClientID *int64  `form:"client_id"`
-	StaffID  *int64  `form:"staff_id"`
-	TableID  *int64  `form:"table_id"`
-	Status   *string `form:"status"`
-	Date     *string `form:"date"` // Expected format YYYY-MM-DD
-	Page     int     `form:"page"`
-	PageSize int     `form:"page_size"`
+	ClientID *int64     `form:"client_id"`
+	StaffID  *int64     `form:"staff_id"`
+	TableID  *int64     `form:"table_id"`
+	Status   *string    `form:"status"`
+	Source   *string    `form:"source"` // One of OrderSources
+	Statuses []string   `form:"-"`      // Orders in any of these statuses
+	Date     *string    `form:"date"`   // Expected format YYYY-MM-DD
+	DateFrom *time.Time `form:"-"`      // Orders at or after; start of a club-local day, in UTC
+	DateTo   *time.Time `form:"-"`      // Orders before; start of the day after a club-local day, in UTC
+	Page     int        `form:"page"`
+	PageSize int        `form:"page_size"`
+	// Cursor switches to cursor pagination: orders after it, by order_time and ID, instead
+	// of Page, without counting the total
+	Cursor *Cursor `form:"-"`
+	// IncludeItems loads the items of the listed orders, with one query for the whole page
+	IncludeItems bool `form:"-"`
 }
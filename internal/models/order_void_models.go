@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Reasons an order is cancelled or an item voided for, required to do either.
+const (
+	VoidReasonCustomerRequest = "customer_request" // The client changed their mind
+	VoidReasonWrongItem       = "wrong_item"       // Rung up by mistake
+	VoidReasonQualityIssue    = "quality_issue"
+	VoidReasonSpilled         = "spilled" // Dropped or spilled before it was served
+	VoidReasonDuplicate       = "duplicate"
+	VoidReasonWalkout         = "walkout" // The client left without paying
+	VoidReasonOther           = "other"   // Needs a note
+	// VoidReasonDayClose is recorded for the open orders cancelled by force when the business
+	// day is closed; staff cannot give it.
+	VoidReasonDayClose = "day_close"
+)
+
+// VoidReasons lists the reasons staff can give.
+var VoidReasons = []string{
+	VoidReasonCustomerRequest, VoidReasonWrongItem, VoidReasonQualityIssue, VoidReasonSpilled,
+	VoidReasonDuplicate, VoidReasonWalkout, VoidReasonOther,
+}
+
+// IsValidVoidReason checks if the provided string is one of VoidReasons.
+func IsValidVoidReason(reason string) bool {
+	for _, valid := range VoidReasons {
+		if reason == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderVoid records an order cancelled, or an item voided from an open order.
+type OrderVoid struct {
+	ID              int64   `json:"id"`
+	BranchCode      string  `json:"branch_code"`
+	OrderID         int64   `json:"order_id"`
+	OrderNumber     string  `json:"order_number"`
+	OrderItemID     *int64  `json:"order_item_id,omitempty"` // nil when the whole order was cancelled
+	PricelistItemID *int64  `json:"pricelist_item_id,omitempty"`
+	Quantity        *int    `json:"quantity,omitempty"`
+	Amount          Money   `json:"amount"` // Taken off the final amount of the order
+	Reason          string  `json:"reason"`
+	Note            *string `json:"note,omitempty"`
+	OrderStaffID    *int64  `json:"order_staff_id,omitempty"` // UserID of the staff member who took the order
+	VoidedBy        *int64  `json:"voided_by,omitempty"`      // UserID; nil if the user was deleted
+	// TimeClockEntryID is the shift VoidedBy was clocked in for at the time, if any; set when
+	// the void is recorded
+	TimeClockEntryID *int64    `json:"time_clock_entry_id,omitempty"`
+	VoidedAt         time.Time `json:"voided_at"`
+
+	// Joined fields, read by the void report
+	VoidedByName *string    `json:"voided_by_name,omitempty"`
+	ClockInAt    *time.Time `json:"clock_in_at,omitempty"`
+	ClockOutAt   *time.Time `json:"clock_out_at,omitempty"`
+}
+
+// VoidReport totals the voids of a time range by the staff member who voided, by reason and by
+// the shift they were clocked in for.
+type VoidReport struct {
+	From       time.Time   `json:"from"`
+	To         time.Time   `json:"to"`
+	OrderCount int         `json:"order_count"` // Orders cancelled
+	ItemCount  int         `json:"item_count"`  // Items voided from open orders
+	Amount     Money       `json:"amount"`
+	ByStaff    []VoidTotal `json:"by_staff"`  // Highest amount first
+	ByReason   []VoidTotal `json:"by_reason"` // Highest amount first
+	ByShift    []VoidTotal `json:"by_shift"`  // Latest shift first; voids made off the clock last
+	Voids      []OrderVoid `json:"voids"`     // Latest first
+}
+
+// VoidTotal is the voids of a staff member, reason or shift in a VoidReport. Only the fields
+// of the grouping are set; those of a shift include its staff member.
+type VoidTotal struct {
+	StaffID          *int64     `json:"staff_id,omitempty"` // UserID of the staff member who voided
+	StaffName        *string    `json:"staff_name,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	TimeClockEntryID *int64     `json:"time_clock_entry_id,omitempty"`
+	ClockInAt        *time.Time `json:"clock_in_at,omitempty"`
+	ClockOutAt       *time.Time `json:"clock_out_at,omitempty"` // nil while the shift is still on
+	OrderCount       int        `json:"order_count"`
+	ItemCount        int        `json:"item_count"`
+	Amount           Money      `json:"amount"`
+}
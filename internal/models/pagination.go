@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Cursor marks the last row of a page in cursor (keyset) pagination: lists are ordered
+// newest first by a timestamp, ties broken by descending ID, and the next page starts
+// after the cursor. The zero Cursor stands for the start of the list.
+type Cursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// IsZero reports whether c is the start of the list.
+func (c Cursor) IsZero() bool {
+	return c.ID == 0 && c.Time.IsZero()
+}
+
+// CursorPage is a page of a list in cursor pagination. NextCursor is nil on the last page.
+type CursorPage[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Photo is an image attached to a record, e.g. a lost and found item or an incident.
+type Photo struct {
+	ContentType string
+	Data        []byte
+}
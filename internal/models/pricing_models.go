@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Console types of game tables.
+const (
+	ConsoleTypePS5 = "ps5"
+	ConsoleTypePS4 = "ps4"
+	ConsoleTypeVR  = "vr"
+)
+
+// ConsoleTypes lists the valid console types.
+var ConsoleTypes = []string{ConsoleTypePS5, ConsoleTypePS4, ConsoleTypeVR}
+
+// DefaultBaseControllers is the number of controllers a table's hourly rate includes unless set.
+const DefaultBaseControllers = 2
+
+// Billing modes of game tables.
+const (
+	BillingModeHourly = "hourly" // HourlyRate, prorated to the minute
+	// BillingModePerMinute charges MinuteRate per minute, rounded up to the billing increment,
+	// and at least the minimum charge; meant for VR and pool tables played by the stopwatch
+	BillingModePerMinute = "per_minute"
+)
+
+// BillingModes lists the valid billing modes.
+var BillingModes = []string{BillingModeHourly, BillingModePerMinute}
+
+// DefaultBillingIncrementMinutes is what per-minute billing rounds the played minutes up to unless set.
+const DefaultBillingIncrementMinutes = 5
+
+// IsValidBillingMode reports whether mode is one of BillingModes.
+func IsValidBillingMode(mode string) bool {
+	for _, valid := range BillingModes {
+		if mode == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ChargePerMinute bills minutes of play at minuteRate per minute, rounded up to whole
+// increments of incrementMinutes and raised to minimum if it is set. It returns the
+// billed minutes, their amount and the top-up to the minimum charge.
+func ChargePerMinute(minuteRate Money, minutes, incrementMinutes int, minimum *Money) (billed int, amount, topUp Money) {
+	if incrementMinutes <= 0 {
+		incrementMinutes = DefaultBillingIncrementMinutes
+	}
+	billed = (max(minutes, 0) + incrementMinutes - 1) / incrementMinutes * incrementMinutes
+	amount = minuteRate.MulInt(billed).Round()
+	if minimum != nil && amount.Cmp(*minimum) < 0 {
+		topUp = minimum.Sub(amount)
+	}
+	return billed, amount, topUp
+}
+
+// PricingRules is the pricing_rules setting.
+type PricingRules struct {
+	// ExtraControllerHourly is the hourly surcharge of each controller beyond a table's base
+	// controllers, by console type. Console types not listed charge nothing for extra controllers.
+	ExtraControllerHourly map[string]Money `json:"extra_controller_hourly,omitempty"`
+	MaxControllers        int              `json:"max_controllers"` // Most controllers per table; 0 does not limit
+}
+
+// ControllerSurcharge returns the hourly surcharge of an extra controller on a table with consoleType.
+func (r PricingRules) ControllerSurcharge(consoleType *string) Money {
+	if consoleType == nil {
+		return Money{}
+	}
+	return r.ExtraControllerHourly[*consoleType]
+}
+
+// ParsePricingRules parses the value of the pricing_rules setting, e.g.
+// {"extra_controller_hourly": {"ps5": 500, "ps4": 300}, "max_controllers": 4}.
+func ParsePricingRules(value string) (PricingRules, error) {
+	var rules PricingRules
+	if strings.TrimSpace(value) == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return PricingRules{}, fmt.Errorf("invalid pricing rules: %w", err)
+	}
+	for consoleType, surcharge := range rules.ExtraControllerHourly {
+		if !IsValidConsoleType(consoleType) {
+			return PricingRules{}, fmt.Errorf("unknown console type '%s' in extra_controller_hourly, must be one of %v", consoleType, ConsoleTypes)
+		}
+		if surcharge.IsNegative() {
+			return PricingRules{}, fmt.Errorf("extra_controller_hourly of %s cannot be negative", consoleType)
+		}
+	}
+	if rules.MaxControllers < 0 {
+		return PricingRules{}, fmt.Errorf("max_controllers cannot be negative")
+	}
+	return rules, nil
+}
+
+// IsValidConsoleType reports whether consoleType is one of ConsoleTypes.
+func IsValidConsoleType(consoleType string) bool {
+	for _, valid := range ConsoleTypes {
+		if consoleType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceQuote is the price of playing at a table for some minutes with some controllers.
+// Per-minute tables charge the table's minute rate plus the hourly surcharge of the extra
+// controllers / 60 for each billed minute.
+type PriceQuote struct {
+	TableID          int64   `json:"table_id"`
+	ConsoleType      *string `json:"console_type,omitempty"`
+	Minutes          int     `json:"minutes"`
+	Controllers      int     `json:"controllers"`
+	BaseControllers  int     `json:"base_controllers"`
+	ExtraControllers int     `json:"extra_controllers"`
+	TableHourlyRate  Money   `json:"table_hourly_rate"`
+	// ControllerHourlyRate is the surcharge of each extra controller per hour
+	ControllerHourlyRate Money  `json:"controller_hourly_rate"`
+	HourlyRate           Money  `json:"hourly_rate"`                 // Table rate plus the surcharge of the extra controllers
+	BillingMode          string `json:"billing_mode"`                // One of BillingModes
+	TableMinuteRate      *Money `json:"table_minute_rate,omitempty"` // Per-minute tables only
+	MinuteRate           *Money `json:"minute_rate,omitempty"`       // Per-minute tables: table rate plus extra controllers, per minute
+	// BillingIncrementMinutes is what a per-minute table rounds the minutes up to, giving
+	// BilledMinutes; hourly tables bill the minutes as they are
+	BillingIncrementMinutes int    `json:"billing_increment_minutes,omitempty"`
+	BilledMinutes           int    `json:"billed_minutes"`
+	MinimumCharge           *Money `json:"minimum_charge,omitempty"`
+	TableAmount             Money  `json:"table_amount"`
+	ControllerAmount        Money  `json:"controller_amount"`
+	// MinimumChargeAmount tops the table and controller amounts up to the minimum charge
+	MinimumChargeAmount Money `json:"minimum_charge_amount"`
+	TotalAmount         Money `json:"total_amount"`
+}
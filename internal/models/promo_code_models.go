@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PromoCode is a percentage off an order, handed out to clients and redeemed when an order is
+// created with it.
+type PromoCode struct {
+	ID              int64           `json:"id"`
+	Code            string          `json:"code"` // Upper case; given in any case
+	Description     *string         `json:"description,omitempty"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"`
+	IssuedCount     int             `json:"issued_count"` // How many were handed out
+	Active          bool            `json:"active"`       // Only active codes can be redeemed
+	CreatedBy       *int64          `json:"created_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// DiscountOn returns the discount of the code on an order totalling total, rounded to the
+// currency decimals.
+func (p PromoCode) DiscountOn(total Money) Money {
+	return NewMoney(total.Decimal().Mul(p.DiscountPercent).Div(decimal.NewFromInt(100))).Round()
+}
+
+// PromoCodeRedemption records the order a promo code was redeemed for.
+type PromoCodeRedemption struct {
+	OrderID        int64     `json:"order_id"`
+	BranchCode     string    `json:"branch_code"`
+	PromoCodeID    int64     `json:"promo_code_id"`
+	DiscountAmount Money     `json:"discount_amount"`
+	RedeemedAt     time.Time `json:"redeemed_at"`
+}
+
+// DiscountReportFilter selects the orders of the discount report.
+type DiscountReportFilter struct {
+	BranchCode string
+	Start      time.Time // Inclusive
+	End        time.Time // Exclusive
+	Period     string    // daily, weekly or monthly
+	Statuses   []string  // Statuses of the orders counted as sales
+}
+
+// DiscountReport totals the discounts given on the sales of a range of business days, to
+// evaluate whether the promotions pay off.
+type DiscountReport struct {
+	From             string           `json:"from"` // YYYY-MM-DD
+	To               string           `json:"to"`
+	Period           string           `json:"period"`
+	OrderCount       int              `json:"order_count"`
+	DiscountedOrders int              `json:"discounted_orders"`
+	AttachRate       float64          `json:"attach_rate"` // Percentage of the orders with a discount
+	GrossSales       Money            `json:"gross_sales"` // Before discounts
+	Discounts        Money            `json:"discounts"`   // The revenue forgone
+	NetSales         Money            `json:"net_sales"`   // After discounts
+	ByCode           []PromoCodeUsage `json:"by_code"`     // Most redemptions first
+	ByStaff          []DiscountTotal  `json:"by_staff"`    // Most discounts first
+	ByPeriod         []DiscountTotal  `json:"by_period"`   // Latest period first
+}
+
+// DiscountTotal is the discounts of a staff member or period in a DiscountReport. Only the
+// fields of the grouping are set.
+type DiscountTotal struct {
+	Period           string  `json:"period,omitempty"`   // YYYY-MM-DD, IYYY-IW or YYYY-MM, like the sales report
+	StaffID          *int64  `json:"staff_id,omitempty"` // UserID of the staff member who took the orders
+	StaffName        *string `json:"staff_name,omitempty"`
+	OrderCount       int     `json:"order_count"`
+	DiscountedOrders int     `json:"discounted_orders"`
+	AttachRate       float64 `json:"attach_rate"`
+	GrossSales       Money   `json:"gross_sales"`
+	Discounts        Money   `json:"discounts"`
+	NetSales         Money   `json:"net_sales"`
+}
+
+// PromoCodeUsage is the redemptions of a promo code in a DiscountReport.
+type PromoCodeUsage struct {
+	PromoCodeID      int64           `json:"promo_code_id"`
+	Code             string          `json:"code"`
+	DiscountPercent  decimal.Decimal `json:"discount_percent"`
+	Active           bool            `json:"active"`
+	IssuedCount      int             `json:"issued_count"`
+	Redemptions      int             `json:"redemptions"`       // Sales in the range the code was redeemed for
+	TotalRedemptions int             `json:"total_redemptions"` // Ever, of any branch and status
+	// RedemptionRate is TotalRedemptions as a percentage of IssuedCount; nil if none was issued
+	RedemptionRate *float64 `json:"redemption_rate,omitempty"`
+	Discounts      Money    `json:"discounts"` // Forgone on the Redemptions
+	NetSales       Money    `json:"net_sales"` // Of the orders, after the discounts
+}
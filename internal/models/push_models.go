@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of push notifications of the client app; a client can turn each off in their preferences,
+// except booking reminders, which follow the reminder preferences of the client.
+const (
+	PushKindBookingConfirmed = "booking_confirmed" // A booking of the client was confirmed
+	PushKindTableReady       = "table_ready"       // A table session of the client started
+	PushKindLoyaltyMilestone = "loyalty_milestone" // The loyalty points of the client reached a milestone
+	PushKindBookingReminder  = "booking_reminder"  // A booking of the client starts soon
+)
+
+// PushKinds lists the kinds of push notifications.
+var PushKinds = []string{PushKindBookingConfirmed, PushKindTableReady, PushKindLoyaltyMilestone, PushKindBookingReminder}
+
+// Platforms of the devices push notifications are sent to.
+const (
+	PushPlatformAndroid = "android"
+	PushPlatformIOS     = "ios"
+	PushPlatformWeb     = "web"
+)
+
+// PushPlatforms lists the platforms of push devices.
+var PushPlatforms = []string{PushPlatformAndroid, PushPlatformIOS, PushPlatformWeb}
+
+// IsValidPushPlatform reports whether platform is one of PushPlatforms.
+func IsValidPushPlatform(platform string) bool {
+	for _, valid := range PushPlatforms {
+		if platform == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses of push deliveries.
+const (
+	PushDeliverySent         = "sent"
+	PushDeliveryFailed       = "failed"
+	PushDeliveryUnregistered = "unregistered" // The token was no longer valid, so the device was removed
+)
+
+// PushDevice is a device of the client app that receives the push notifications of a client.
+type PushDevice struct {
+	ID         int64     `json:"id"`
+	ClientID   int64     `json:"client_id"`
+	Token      string    `json:"-"` // The FCM registration token; only the app needs it
+	Platform   string    `json:"platform"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"` // When the app last registered the token
+}
+
+// PushPreferences are the kinds of push notifications a client receives. A client without
+// saved preferences receives every kind.
+type PushPreferences struct {
+	ClientID             int64      `json:"client_id"`
+	BookingConfirmations bool       `json:"booking_confirmations"`
+	TableReady           bool       `json:"table_ready"`
+	LoyaltyMilestones    bool       `json:"loyalty_milestones"`
+	UpdatedAt            *time.Time `json:"updated_at"` // nil until the preferences are saved
+}
+
+// DefaultPushPreferences returns the preferences of a client that saved none.
+func DefaultPushPreferences(clientID int64) PushPreferences {
+	return PushPreferences{ClientID: clientID, BookingConfirmations: true, TableReady: true, LoyaltyMilestones: true}
+}
+
+// Allows reports whether the client receives push notifications of kind.
+func (p PushPreferences) Allows(kind string) bool {
+	switch kind {
+	case PushKindBookingConfirmed:
+		return p.BookingConfirmations
+	case PushKindTableReady:
+		return p.TableReady
+	case PushKindLoyaltyMilestone:
+		return p.LoyaltyMilestones
+	}
+	return false
+}
+
+// PushMessage is a push notification as shown on the device; Data is passed to the app.
+type PushMessage struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+// PushDelivery records a push notification sent, or attempted, to a device of a client.
+type PushDelivery struct {
+	ID        int64     `json:"id"`
+	ClientID  int64     `json:"client_id"`
+	DeviceID  *int64    `json:"device_id"` // nil once the device was removed
+	EventID   *int64    `json:"event_id,omitempty"`
+	Kind      string    `json:"kind"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	Error     *string   `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PushDeliveryFilters selects push deliveries.
+type PushDeliveryFilters struct {
+	ClientID *int64
+	Kind     *string
+	Status   *string
+	From     *time.Time // Created at or after
+	To       *time.Time // Created before
+	Page     int
+	PageSize int
+}
+
+// PushSettings is the push_notifications setting.
+type PushSettings struct {
+	// LoyaltyMilestones are the loyalty point balances a client is congratulated on reaching, ascending
+	LoyaltyMilestones []int `json:"loyalty_milestones"`
+}
+
+// DefaultPushSettings returns the push notifications used without the push_notifications setting.
+func DefaultPushSettings() PushSettings {
+	return PushSettings{LoyaltyMilestones: []int{100, 500, 1000, 5000}}
+}
+
+// ParsePushSettings parses the value of the push_notifications setting, e.g.
+// {"loyalty_milestones": [100, 500, 1000]}; omitted fields keep their default.
+func ParsePushSettings(value string) (PushSettings, error) {
+	settings := DefaultPushSettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return PushSettings{}, fmt.Errorf("invalid push notification settings: %w", err)
+	}
+	for _, milestone := range settings.LoyaltyMilestones {
+		if milestone <= 0 {
+			return PushSettings{}, fmt.Errorf("loyalty_milestones must be positive")
+		}
+	}
+	sort.Ints(settings.LoyaltyMilestones)
+	return settings, nil
+}
+
+// ReachedMilestone returns the highest loyalty milestone a balance going from previous to
+// points reached, and false if it reached none.
+func (s PushSettings) ReachedMilestone(previous, points int) (int, bool) {
+	for i := len(s.LoyaltyMilestones) - 1; i >= 0; i-- {
+		milestone := s.LoyaltyMilestones[i]
+		if previous < milestone && points >= milestone {
+			return milestone, true
+		}
+	}
+	return 0, false
+}
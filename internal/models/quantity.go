@@ -0,0 +1,130 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// QuantityDecimals is the precision stock quantities are kept in, e.g. 0.5 ml.
+const QuantityDecimals = 3
+
+// Quantity is an amount of stock in the stock unit of an item (pieces, milliliters or
+// grams), backed by an arbitrary-precision decimal and stored in NUMERIC columns.
+// Quantities entering the system (JSON input) are rounded to QuantityDecimals, and
+// arithmetic is exact.
+type Quantity struct {
+	d decimal.Decimal
+}
+
+// ZeroQuantity is the zero quantity.
+var ZeroQuantity = Quantity{}
+
+// NewQuantity creates a Quantity from a decimal value.
+func NewQuantity(d decimal.Decimal) Quantity {
+	return Quantity{d: d}
+}
+
+// NewQuantityFromInt creates a Quantity from a whole number of stock units.
+func NewQuantityFromInt(value int) Quantity {
+	return Quantity{d: decimal.NewFromInt(int64(value))}
+}
+
+// ParseQuantity parses a decimal quantity ("40", "0.5"), rounded to QuantityDecimals.
+func ParseQuantity(value string) (Quantity, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return ZeroQuantity, err
+	}
+	return Quantity{d: d.Round(QuantityDecimals)}, nil
+}
+
+// Decimal returns the underlying decimal value.
+func (q Quantity) Decimal() decimal.Decimal { return q.d }
+
+// Add returns q + other.
+func (q Quantity) Add(other Quantity) Quantity { return Quantity{d: q.d.Add(other.d)} }
+
+// Sub returns q - other.
+func (q Quantity) Sub(other Quantity) Quantity { return Quantity{d: q.d.Sub(other.d)} }
+
+// Mul returns q * other, e.g. a number of purchase units times the size of one.
+func (q Quantity) Mul(other Quantity) Quantity { return Quantity{d: q.d.Mul(other.d)} }
+
+// MulInt returns q multiplied by a count, e.g. a portion times the number sold.
+func (q Quantity) MulInt(count int) Quantity {
+	return Quantity{d: q.d.Mul(decimal.NewFromInt(int64(count)))}
+}
+
+// DivCeil returns how many whole units of size q holds, rounded up; size must be positive.
+func (q Quantity) DivCeil(size Quantity) int {
+	return int(q.d.Div(size.d).Ceil().IntPart())
+}
+
+// Ceil returns q rounded up to a whole number of stock units.
+func (q Quantity) Ceil() Quantity { return Quantity{d: q.d.Ceil()} }
+
+// Neg returns -q.
+func (q Quantity) Neg() Quantity { return Quantity{d: q.d.Neg()} }
+
+// Min returns the smaller of q and other.
+func (q Quantity) Min(other Quantity) Quantity {
+	if other.d.LessThan(q.d) {
+		return other
+	}
+	return q
+}
+
+// IsZero reports whether q == 0.
+func (q Quantity) IsZero() bool { return q.d.IsZero() }
+
+// IsNegative reports whether q < 0.
+func (q Quantity) IsNegative() bool { return q.d.IsNegative() }
+
+// IsPositive reports whether q > 0.
+func (q Quantity) IsPositive() bool { return q.d.IsPositive() }
+
+// Cmp compares q and other, returning -1, 0 or +1.
+func (q Quantity) Cmp(other Quantity) int { return q.d.Cmp(other.d) }
+
+// String renders the quantity without trailing zeros ("40", "0.5").
+func (q Quantity) String() string { return q.d.String() }
+
+// MarshalJSON renders the quantity as a JSON number ("40", "0.5").
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or string. The raw text is parsed directly so no
+// binary floating point rounding is involved.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	parsed, err := ParseQuantity(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return fmt.Errorf("invalid quantity: %w", err)
+	}
+	*q = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing the quantity as a NUMERIC string.
+func (q Quantity) Value() (driver.Value, error) {
+	return q.d.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC (and integer) columns.
+func (q *Quantity) Scan(src interface{}) error {
+	if src == nil {
+		*q = ZeroQuantity
+		return nil
+	}
+	if err := q.d.Scan(src); err != nil {
+		return fmt.Errorf("scanning quantity: %w", err)
+	}
+	return nil
+}
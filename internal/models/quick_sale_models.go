@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// QuickSalePreset is a named bundle of pricelist items sold with one tap, e.g. one of the
+// bar's most common combos. Each branch has its own presets.
+type QuickSalePreset struct {
+	ID         int64                 `json:"id"`
+	BranchCode string                `json:"branch_code"`
+	Name       string                `json:"name"`
+	SortOrder  int                   `json:"sort_order"` // Buttons are shown by ascending sort order, then name
+	IsActive   bool                  `json:"is_active"`  // Inactive presets are hidden from the buttons and cannot be sold
+	Items      []QuickSalePresetItem `json:"items"`
+	TotalPrice Money                 `json:"total_price"` // The items at their current prices
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+	Version    int                   `json:"version"`
+}
+
+// QuickSalePresetItem is an item of a quick-sale preset with its current name and price.
+type QuickSalePresetItem struct {
+	PricelistItemID int64  `json:"pricelist_item_id"`
+	ItemName        string `json:"item_name"`
+	UnitPrice       Money  `json:"unit_price"`
+	Quantity        int    `json:"quantity"`
+}
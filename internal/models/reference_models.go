@@ -0,0 +1,28 @@
+package models
+
+// What happens to the referencing records when the referenced record is deleted, per foreign key.
+const (
+	ReferenceOnDeleteRestrict = "restrict" // The record cannot be deleted while referenced
+	ReferenceOnDeleteCascade  = "cascade"  // The referencing records are deleted with it
+	ReferenceOnDeleteSetNull  = "set_null" // The referencing records lose the reference
+)
+
+// RecordReference counts the records of a table referencing a record through one column.
+type RecordReference struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Count    int64  `json:"count"`
+	OnDelete string `json:"on_delete"` // One of the ReferenceOnDelete constants
+}
+
+// RecordReferences is the delete preflight of a record: the records referencing it and
+// whether they keep it from being deleted.
+type RecordReferences struct {
+	Entity     string            `json:"entity"`
+	ID         int64             `json:"id"`
+	CanDelete  bool              `json:"can_delete"`
+	SoftDelete bool              `json:"soft_delete"`          // Deleting only hides the record, so blocking references do not keep it from being deleted
+	Blocking   []RecordReference `json:"blocking"`             // Referencing records that keep it from being deleted
+	Affected   []RecordReference `json:"affected"`             // Referencing records deleted or unlinked with it
+	Suggestion string            `json:"suggestion,omitempty"` // What to do instead when it cannot be deleted
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReorderPointSettings is the reorder_points setting, the basis of the reorder points calculated
+// from the consumption of the stock-tracked items.
+type ReorderPointSettings struct {
+	LookbackDays int  `json:"lookback_days"`  // Days of consumption the daily average is taken over
+	LeadTimeDays int  `json:"lead_time_days"` // Days a delivery takes to arrive
+	SafetyDays   int  `json:"safety_days"`    // Days of consumption kept in stock on top of the lead time
+	CoverDays    int  `json:"cover_days"`     // Days of consumption one reorder covers
+	AutoUpdate   bool `json:"auto_update"`    // Whether the items' low stock thresholds are set to the reorder points
+}
+
+// DefaultReorderPointSettings returns the settings used without the reorder_points setting.
+func DefaultReorderPointSettings() ReorderPointSettings {
+	return ReorderPointSettings{LookbackDays: 30, LeadTimeDays: 3, SafetyDays: 2, CoverDays: 14}
+}
+
+// ParseReorderPointSettings parses the value of the reorder_points setting, e.g.
+// {"lookback_days": 60, "lead_time_days": 5, "auto_update": true}. Missing fields keep their defaults.
+func ParseReorderPointSettings(value string) (ReorderPointSettings, error) {
+	settings := DefaultReorderPointSettings()
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return ReorderPointSettings{}, fmt.Errorf("invalid reorder point settings: %w", err)
+	}
+	if settings.LookbackDays < 1 || settings.LookbackDays > 365 {
+		return ReorderPointSettings{}, fmt.Errorf("lookback_days must be between 1 and 365")
+	}
+	if settings.LeadTimeDays < 0 || settings.SafetyDays < 0 || settings.CoverDays < 1 {
+		return ReorderPointSettings{}, fmt.Errorf("lead_time_days and safety_days cannot be negative, and cover_days must be at least 1")
+	}
+	return settings, nil
+}
+
+// ReorderPoint is the low stock threshold and reorder quantity calculated for a stock-tracked
+// item, with the consumption and settings they were calculated from.
+type ReorderPoint struct {
+	PricelistItemID          int64           `json:"pricelist_item_id"`
+	ItemName                 string          `json:"item_name"`
+	StockUnit                string          `json:"stock_unit"` // Of the quantities
+	CurrentStock             *Quantity       `json:"current_stock,omitempty"`
+	LowStockThreshold        *Quantity       `json:"low_stock_threshold,omitempty"` // The item's threshold now
+	Consumed                 Quantity        `json:"consumed"`                      // Over the lookback, net of returns
+	LookbackDays             int             `json:"lookback_days"`
+	AverageDailyConsumption  decimal.Decimal `json:"average_daily_consumption"`
+	LeadTimeDays             int             `json:"lead_time_days"`
+	SafetyDays               int             `json:"safety_days"`
+	CoverDays                int             `json:"cover_days"`
+	SuggestedThreshold       Quantity        `json:"suggested_threshold"` // Consumption over the lead time and safety days
+	SuggestedReorderQuantity Quantity        `json:"suggested_reorder_quantity"`
+	AppliedAt                *time.Time      `json:"applied_at,omitempty"` // When the threshold was last set to the suggestion
+	CalculatedAt             time.Time       `json:"calculated_at"`
+}
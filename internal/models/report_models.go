@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // SalesReportItem represents a single item in a sales report.
 // This could be aggregated by day, week, item, category, etc.
@@ -11,9 +16,9 @@ type SalesReportItem struct {
 	CategoryID  *int64  `json:"category_id,omitempty"`
 	CategoryName *string `json:"category_name,omitempty"`
 	TotalQuantity int     `json:"total_quantity"`
-	TotalSales    float64 `json:"total_sales"`
-	TotalDiscount float64 `json:"total_discount,omitempty"`
-	NetSales      float64 `json:"net_sales"`
+	TotalSales    Money   `json:"total_sales"`
+	TotalDiscount Money   `json:"total_discount,omitempty"`
+	NetSales      Money   `json:"net_sales"`
 }
 
 // BookingReportItem represents data for booking reports.
@@ -36,8 +41,9 @@ type InventoryReportItem struct {
 	SKU               *string `json:"sku,omitempty"`
 	CategoryID        *int64  `json:"category_id,omitempty"`
 	CategoryName      *string `json:"category_name,omitempty"`
-	CurrentStock      int     `json:"current_stock"`
-	LowStockThreshold *int    `json:"low_stock_threshold,omitempty"`
+	StockUnit         string  `json:"stock_unit"`
+	CurrentStock      Quantity `json:"current_stock"`
+	LowStockThreshold *Quantity `json:"low_stock_threshold,omitempty"`
 	LastMovementDate  *time.Time `json:"last_movement_date,omitempty"`
 	Status            string  `json:"status,omitempty"` // e.g., "Low Stock", "In Stock", "Out of Stock"
 }
@@ -46,17 +52,17 @@ type InventoryReportItem struct {
 type DashboardSummary struct {
 	ActiveBookingsCount   int     `json:"active_bookings_count"`
 	PendingOrdersCount    int     `json:"pending_orders_count"`
-	TotalSalesToday       float64 `json:"total_sales_today"`
-	TotalSalesThisWeek    float64 `json:"total_sales_this_week"`
-	TotalSalesThisMonth   float64 `json:"total_sales_this_month"`
+	TotalSalesToday       Money   `json:"total_sales_today"`
+	TotalSalesThisWeek    Money   `json:"total_sales_this_week"`
+	TotalSalesThisMonth   Money   `json:"total_sales_this_month"`
 	LowStockItemsCount    int     `json:"low_stock_items_count"`
 	UpcomingBookingsCount int     `json:"upcoming_bookings_count"` // e.g., for next 24 hours
 }
 
 // ReportRequestParams holds common parameters for requesting reports.
 type ReportRequestParams struct {
-	StartDate   string `form:"start_date"` // YYYY-MM-DD
-	EndDate     string `form:"end_date"`   // YYYY-MM-DD
+	From        *time.Time `form:"-"` // Start of the time range, inclusive, in UTC
+	To          *time.Time `form:"-"` // End of the time range, exclusive, in UTC
 	Period      string `form:"period"`     // e.g., "daily", "weekly", "monthly", "custom"
 	ItemID      *int64 `form:"item_id"`
 	CategoryID  *int64 `form:"category_id"`
@@ -65,3 +71,200 @@ type ReportRequestParams struct {
 	Granularity string `form:"granularity"` // e.g., "hourly", "daily" for booking reports
 }
 
+
+// ActivityItem is one entry of the dashboard activity feed, derived from a domain event.
+type ActivityItem struct {
+	ID            int64           `json:"id"`   // ID of the domain event
+	Type          string          `json:"type"` // Event type, e.g. "order.completed"
+	OccurredAt    time.Time       `json:"occurred_at"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Summary       string          `json:"summary"` // One line for display, e.g. "Order 2024-06-01/#37 completed: 4 500,00 ₸"
+	Data          json.RawMessage `json:"data"`    // Event payload
+}
+
+// ActivityFeed is a page of the activity feed, newest first. NextCursor is nil on the last page.
+type ActivityFeed struct {
+	Data       []ActivityItem `json:"data"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+// ActivityFilter selects the domain events shown in the activity feed.
+type ActivityFilter struct {
+	EventTypes           []string
+	DiscountEventType    string // Events of this type are only shown for large discounts
+	LargeDiscountPercent int    // Minimum discount, in percent of the order total, of a large discount
+	After                *Cursor // Of the last event of the previous page
+	Limit                int
+}
+
+// PeriodReportItem totals the daily summaries of a day, ISO week or month.
+type PeriodReportItem struct {
+	Period        string          `json:"period"` // YYYY-MM-DD, IYYY-IW or YYYY-MM, like the sales report
+	Days          int             `json:"days"`   // Days of the period with a summary
+	Revenue       Money           `json:"revenue"`
+	OrderCount    int             `json:"order_count"`
+	SalesTotal    Money           `json:"sales_total"`
+	SessionTotal  Money           `json:"session_total"`
+	HoursBooked   float64         `json:"hours_booked"`
+	CategorySales []CategorySales `json:"category_sales"`
+	Notes         []CalendarNote  `json:"notes,omitempty"` // Calendar notes of the days of the period
+}
+
+// TaxReportFilter selects the order items of the tax summary report.
+type TaxReportFilter struct {
+	BranchCode string
+	Start      time.Time // Inclusive
+	End        time.Time // Exclusive
+	Period     string    // daily, weekly or monthly
+	Statuses   []string  // Statuses of the orders counted as sales
+}
+
+// TaxReportItem totals the sales of a period at one tax class and rate, for accounting.
+type TaxReportItem struct {
+	Period      string           `json:"period"`              // YYYY-MM-DD, IYYY-IW or YYYY-MM, like the sales report
+	TaxClass    *string          `json:"tax_class,omitempty"` // Omitted for untaxed sales
+	TaxRate     *decimal.Decimal `json:"tax_rate,omitempty"`  // Percent; omitted for untaxed sales
+	NetAmount   Money            `json:"net_amount"`          // Sales after discounts, without tax
+	TaxAmount   Money            `json:"tax_amount"`
+	GrossAmount Money            `json:"gross_amount"` // NetAmount + TaxAmount
+	ItemCount   int              `json:"item_count"`   // Order items (lines) sold
+}
+
+// Materialized views backing the sales and booking reports.
+const (
+	ReportViewSalesByItem      = "report_sales_by_item"
+	ReportViewTableUtilization = "report_table_utilization"
+)
+
+// ReportViews are the materialized views refreshed for the reports, in refresh order.
+var ReportViews = []string{ReportViewSalesByItem, ReportViewTableUtilization}
+
+// ReportViewRefresh is the last refresh of a report view; reports read from it show the data as of RefreshedAt.
+type ReportViewRefresh struct {
+	ViewName    string    `json:"view_name"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+// SeriesFilter selects the records of a metric series.
+type SeriesFilter struct {
+	BranchCode string
+	Start      time.Time // Inclusive
+	End        time.Time // Exclusive
+	Hourly     bool      // Per hour instead of per day, in club time
+	Statuses   []string  // Statuses of the orders counted as sales
+}
+
+// SeriesBucket totals the records of a day or hour of a metric series.
+type SeriesBucket struct {
+	Bucket string // YYYY-MM-DD, or YYYY-MM-DDTHH:00 per hour, in club time
+	Count  int
+	Amount Money
+}
+
+// PeriodComparison aligns the series of a metric over two periods, e.g. this week (A) and
+// last week (B), for charting one against the other.
+type PeriodComparison struct {
+	Metric      string            `json:"metric"`
+	Granularity string            `json:"granularity"` // day or hour
+	PeriodA     ComparedPeriod    `json:"period_a"`
+	PeriodB     ComparedPeriod    `json:"period_b"`
+	Delta       float64           `json:"delta"`                   // Total of A minus that of B
+	DeltaPct    *float64          `json:"delta_percent,omitempty"` // Delta as a percentage of the total of B, omitted if it is 0
+	Points      []ComparisonPoint `json:"points"`
+}
+
+// ComparedPeriod is one of the periods of a PeriodComparison.
+type ComparedPeriod struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Total float64        `json:"total"`           // Of the points up to now
+	Notes []CalendarNote `json:"notes,omitempty"` // Calendar notes of the days of the period
+}
+
+// ComparisonPoint is the value of a metric in the day or hour at the same offset from the start
+// of both periods. The value of a period is omitted past its end or in the future.
+type ComparisonPoint struct {
+	Offset   int      `json:"offset"`             // Days or hours since the start of the periods
+	BucketA  *string  `json:"bucket_a,omitempty"` // YYYY-MM-DD, or YYYY-MM-DDTHH:00 per hour, in club time
+	BucketB  *string  `json:"bucket_b,omitempty"`
+	ValueA   *float64 `json:"value_a,omitempty"`
+	ValueB   *float64 `json:"value_b,omitempty"`
+	Delta    *float64 `json:"delta,omitempty"`         // ValueA minus ValueB, if both are given
+	DeltaPct *float64 `json:"delta_percent,omitempty"` // Delta as a percentage of ValueB, if it is not 0
+}
+
+// HourlyUtilization is how many tables were in use and staff members on shift in an hour, on
+// average over the hour: a table in use for half of it counts 0.5.
+type HourlyUtilization struct {
+	Hour           time.Time
+	OccupiedTables float64
+	StaffOnShift   float64
+}
+
+// StaffingReport highlights the hours in which the occupied tables per staff member on shift
+// exceeded a threshold, and the weekly hours where shifts should be added.
+type StaffingReport struct {
+	From            time.Time                `json:"from"`
+	To              time.Time                `json:"to"`
+	Threshold       float64                  `json:"threshold"` // Occupied tables per staff member
+	Hours           []StaffingHour           `json:"hours"`     // Over the threshold, in order
+	Recommendations []StaffingRecommendation `json:"recommendations"`
+}
+
+// StaffingHour is an hour over the threshold of a StaffingReport.
+type StaffingHour struct {
+	Hour           time.Time `json:"hour"`
+	OccupiedTables float64   `json:"occupied_tables"`
+	StaffOnShift   float64   `json:"staff_on_shift"`
+	// TablesPerStaff is OccupiedTables per staff member; omitted if no one was on shift
+	TablesPerStaff  *float64 `json:"tables_per_staff,omitempty"`
+	AdditionalStaff int      `json:"additional_staff"` // Staff members that would have kept the hour within the threshold
+}
+
+// StaffingRecommendation suggests adding shifts at an hour of a weekday that was over the
+// threshold at least every other week of the report's range.
+type StaffingRecommendation struct {
+	Weekday     int     `json:"weekday"` // 0 for Sunday
+	WeekdayName string  `json:"weekday_name"`
+	Hour        int     `json:"hour"`         // Of the day, in club time
+	HoursOver   int     `json:"hours_over"`   // How many of the hours at this weekday and hour were over the threshold
+	HoursTotal  int     `json:"hours_total"`  // How many hours at this weekday and hour the range covers
+	AvgOccupied float64 `json:"avg_occupied"` // Over the hours over the threshold
+	AvgStaff    float64 `json:"avg_staff"`
+	// AdditionalStaff is the staff members to add to the shifts covering this hour, for the
+	// average of the hours over the threshold
+	AdditionalStaff int `json:"additional_staff"`
+}
+
+// SourceReportFilter selects the orders and bookings of the source report.
+type SourceReportFilter struct {
+	BranchCode string
+	Start      time.Time // Inclusive
+	End        time.Time // Exclusive
+	Statuses   []string  // Statuses of the orders counted as sales
+}
+
+// SourceReport breaks the sales and bookings of a range of business days out by the channel
+// they were placed through, to see how much the self-service channels bring in.
+type SourceReport struct {
+	From           string        `json:"from"` // YYYY-MM-DD
+	To             string        `json:"to"`
+	OrderCount     int           `json:"order_count"`
+	Revenue        Money         `json:"revenue"` // Final amounts of the sales
+	BookingCount   int           `json:"booking_count"`
+	BookingRevenue Money         `json:"booking_revenue"` // Prices of the bookings not cancelled
+	BySource       []SourceTotal `json:"by_source"`       // Every source, in the order of OrderSources
+}
+
+// SourceTotal is the sales and bookings of one source in a SourceReport.
+type SourceTotal struct {
+	Source         string  `json:"source"`
+	OrderCount     int     `json:"order_count"`
+	Revenue        Money   `json:"revenue"`
+	AverageOrder   Money   `json:"average_order"`
+	RevenueShare   float64 `json:"revenue_share"` // Percentage of the revenue of all sources
+	BookingCount   int     `json:"booking_count"` // Starting in the range, not cancelled
+	BookingRevenue Money   `json:"booking_revenue"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Statuses of the progress of a sales target.
+const (
+	SalesTargetAchieved  = "achieved"   // The sales reached the target
+	SalesTargetOnTrack   = "on_track"   // At the current pace the sales reach the target by the end of the month
+	SalesTargetBehind    = "behind"     // At the current pace the sales fall short of the target
+	SalesTargetFarBehind = "far_behind" // At the current pace the sales fall short of the target by more than the notice threshold
+)
+
+// SalesTarget is the revenue a branch aims for from a pricelist category in a month.
+type SalesTarget struct {
+	ID               int64      `json:"id"`
+	BranchCode       string     `json:"branch_code"`
+	CategoryID       int64      `json:"category_id"`
+	CategoryName     string     `json:"category_name"`
+	Month            string     `json:"month"` // YYYY-MM
+	Amount           Money      `json:"amount"`
+	BehindNotifiedAt *time.Time `json:"behind_notified_at,omitempty"` // When the Admins were notified the target falls far behind
+	CreatedBy        *int64     `json:"created_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// SalesTargetProgress compares the sales targets of a branch in a month with the sales so far.
+type SalesTargetProgress struct {
+	BranchCode  string `json:"branch_code"`
+	Month       string `json:"month"` // YYYY-MM
+	DaysInMonth int    `json:"days_in_month"`
+	// DaysElapsed is how much of the month has passed, in days: DaysInMonth for a past month,
+	// 0 for a future one
+	DaysElapsed float64                  `json:"days_elapsed"`
+	Categories  []CategoryTargetProgress `json:"categories"`
+	Target      Money                    `json:"target"` // Totals of the categories
+	Actual      Money                    `json:"actual"`
+	Projected   Money                    `json:"projected"`
+}
+
+// CategoryTargetProgress is the progress of the sales of a category towards its target.
+type CategoryTargetProgress struct {
+	TargetID     int64   `json:"target_id"`
+	CategoryID   int64   `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Target       Money   `json:"target"`
+	Actual       Money   `json:"actual"`  // Net sales of the category so far
+	Percent      float64 `json:"percent"` // Actual as a percentage of Target
+	// Expected is what the sales would be so far at the pace that exactly reaches the target
+	Expected Money `json:"expected"`
+	// Projected is what the sales will be by the end of the month at the pace so far
+	Projected        Money   `json:"projected"`
+	ProjectedPercent float64 `json:"projected_percent"`
+	Status           string  `json:"status"` // achieved, on_track, behind or far_behind
+}
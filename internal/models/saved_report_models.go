@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Types of the reports that can be saved, named like their routes under /reports.
+const (
+	SavedReportTypeSummary   = "summary"
+	SavedReportTypeTax       = "tax"
+	SavedReportTypeSales     = "sales"
+	SavedReportTypeBookings  = "bookings"
+	SavedReportTypeInventory = "inventory"
+)
+
+// SavedReportTypes lists the report types that can be saved.
+var SavedReportTypes = []string{SavedReportTypeSummary, SavedReportTypeTax, SavedReportTypeSales, SavedReportTypeBookings, SavedReportTypeInventory}
+
+// Frequencies of a report schedule.
+const (
+	ReportFrequencyDaily   = "daily"
+	ReportFrequencyWeekly  = "weekly"
+	ReportFrequencyMonthly = "monthly"
+)
+
+// ReportFrequencies lists the valid frequencies of a report schedule.
+var ReportFrequencies = []string{ReportFrequencyDaily, ReportFrequencyWeekly, ReportFrequencyMonthly}
+
+// SavedReport is a named report with its filters and grouping, the query parameters of its
+// route, e.g. {"range": "last_week", "period": "weekly", "category_id": "3"}.
+type SavedReport struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	ReportType string            `json:"report_type"` // One of SavedReportTypes
+	Params     map[string]string `json:"params"`
+	Schedule   *ReportSchedule   `json:"schedule,omitempty"` // nil if it is not emailed
+	NextRunAt  *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time        `json:"last_run_at,omitempty"`
+	LastError  *string           `json:"last_error,omitempty"` // Of the last scheduled run
+	CreatedBy  *int64            `json:"created_by,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// ReportSchedule is when a saved report is emailed, and to whom.
+type ReportSchedule struct {
+	Frequency  string   `json:"frequency"`              // One of ReportFrequencies
+	Weekday    *int     `json:"weekday,omitempty"`      // Of a weekly schedule, 0 (Sunday) to 6
+	DayOfMonth *int     `json:"day_of_month,omitempty"` // Of a monthly schedule, 1 to 28
+	Time       string   `json:"time"`                   // HH:MM, club time
+	Recipients []string `json:"recipients"`             // Email addresses
+}
+
+// SavedReportRun is the result of running a saved report.
+type SavedReportRun struct {
+	Report SavedReport       `json:"report"`
+	Params map[string]string `json:"params"` // The saved parameters with those given for the run
+	Data   interface{}       `json:"data"`   // As returned by the route of the report
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Search result group types, in the order groups are returned on equal scores.
+const (
+	SearchTypeOrders   = "orders"
+	SearchTypeBookings = "bookings"
+	SearchTypeClients  = "clients"
+)
+
+// SearchResults is the response of the global search: one group per entity type
+// with matches, ordered by the score of the group's best match.
+type SearchResults struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
+
+// SearchGroup holds the matches of one entity type, best first. Items is a slice
+// of OrderSearchHit, BookingSearchHit or ClientSearchHit according to Type.
+type SearchGroup struct {
+	Type     string      `json:"type"`
+	TopScore float64     `json:"top_score"`
+	Items    interface{} `json:"items"`
+}
+
+// OrderSearchHit is an order matched by its display number or client name.
+type OrderSearchHit struct {
+	ID          int64     `json:"id"`
+	OrderNumber string    `json:"order_number"`
+	Status      string    `json:"status"`
+	ClientName  *string   `json:"client_name,omitempty"`
+	FinalAmount Money     `json:"final_amount"`
+	OrderTime   time.Time `json:"order_time"`
+	Score       float64   `json:"score"`
+}
+
+// BookingSearchHit is a booking matched by client name or phone, or table name.
+type BookingSearchHit struct {
+	ID         int64     `json:"id"`
+	Status     string    `json:"status"`
+	ClientName *string   `json:"client_name,omitempty"`
+	TableName  string    `json:"table_name"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Score      float64   `json:"score"`
+}
+
+// ClientSearchHit is a client matched by name, phone number or email.
+type ClientSearchHit struct {
+	ID              int64   `json:"id"`
+	FullName        string  `json:"full_name"`
+	Blacklisted     bool    `json:"blacklisted"` // See Client.Blacklisted
+	BlacklistReason *string `json:"blacklist_reason,omitempty"`
+	PhoneNumber     *string `json:"phone_number,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	Score           float64 `json:"score"`
+}
+
+// OrderNumberRef identifies an order by its display number.
+type OrderNumberRef struct {
+	BranchCode   string
+	BusinessDate string // YYYY-MM-DD
+	DailyNumber  int
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultServiceChargeName is printed on bills for a service_charge setting without a name.
+const DefaultServiceChargeName = "Service charge"
+
+// ServiceChargeSettings is the service_charge setting: a percentage added to the orders of large
+// groups and of VIP tables and rooms. The zero value charges nothing.
+type ServiceChargeSettings struct {
+	Name      string          `json:"name,omitempty"`       // Printed on bills, e.g. "Service charge" (the default)
+	Percent   decimal.Decimal `json:"percent"`              // Of the items after discount, before any tax added
+	MinGuests int             `json:"min_guests,omitempty"` // Orders for at least this many guests are charged; 0 for none
+	TableIDs  []int64         `json:"table_ids,omitempty"`  // VIP tables and rooms whose orders are always charged
+}
+
+// DisplayName returns the name of the service charge printed on bills.
+func (s ServiceChargeSettings) DisplayName() string {
+	if s.Name == "" {
+		return DefaultServiceChargeName
+	}
+	return s.Name
+}
+
+// Enabled reports whether a service charge is configured.
+func (s ServiceChargeSettings) Enabled() bool {
+	return s.Percent.IsPositive()
+}
+
+// AppliesTo reports whether an order at tableID for guestCount guests (either may be nil) is
+// charged without a choice made on the order.
+func (s ServiceChargeSettings) AppliesTo(tableID *int64, guestCount *int) bool {
+	if !s.Enabled() {
+		return false
+	}
+	if s.MinGuests > 0 && guestCount != nil && *guestCount >= s.MinGuests {
+		return true
+	}
+	return tableID != nil && slices.Contains(s.TableIDs, *tableID)
+}
+
+// ChargeOn returns the service charge at rate percent on amount, rounded to the currency decimals.
+func ChargeOn(amount Money, rate decimal.Decimal) Money {
+	return NewMoney(amount.Decimal().Mul(rate).Div(decimal.NewFromInt(100))).Round()
+}
+
+// ParseServiceChargeSettings parses the value of the service_charge setting.
+func ParseServiceChargeSettings(value string) (ServiceChargeSettings, error) {
+	var settings ServiceChargeSettings
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return ServiceChargeSettings{}, fmt.Errorf("invalid service charge settings: %w", err)
+	}
+	if settings.Percent.IsNegative() || settings.Percent.GreaterThan(decimal.NewFromInt(100)) {
+		return ServiceChargeSettings{}, fmt.Errorf("service charge percent must be between 0 and 100")
+	}
+	if settings.MinGuests < 0 {
+		return ServiceChargeSettings{}, fmt.Errorf("min_guests cannot be negative")
+	}
+	if len(settings.Name) > 50 {
+		return ServiceChargeSettings{}, fmt.Errorf("service charge name cannot be longer than 50 characters")
+	}
+	return settings, nil
+}
@@ -1,6 +1,89 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Well-known application setting keys.
+const (
+	// SettingKeyClubTimezone holds the IANA timezone name (e.g. "Asia/Almaty") of the club.
+	SettingKeyClubTimezone = "club_timezone"
+	// SettingKeyCurrency holds a currency code ("KZT") or a JSON currency definition
+	// with code, symbol, decimals, symbol_first, thousands_separator and decimal_separator.
+	SettingKeyCurrency = "currency"
+	// SettingKeyAPIV1Sunset holds the date (YYYY-MM-DD, club time) after which API v1 may be
+	// removed. While set, v1 responses carry Deprecation and Sunset headers; empty disables them.
+	SettingKeyAPIV1Sunset = "api_v1_sunset"
+	// SettingKeyDiscountLimits holds a JSON object with the maximum order discount per role,
+	// e.g. {"Staff": {"max_percent": 10, "max_amount": 5000}}. Roles not listed are not limited.
+	SettingKeyDiscountLimits = "discount_limits"
+	// SettingKeyBackupSchedule holds the nightly database backup as JSON, e.g.
+	// {"time": "03:00", "destination": "s3"} (club time). Empty or missing disables it.
+	SettingKeyBackupSchedule = "backup_schedule"
+	// SettingKeyBookingPolicy holds the booking rules as JSON, e.g. {"min_lead_minutes": 60,
+	// "free_cancellation_hours": 24, "late_cancellation_fee": 2000}. Missing rules do not apply.
+	SettingKeyBookingPolicy = "booking_policy"
+	// SettingKeyPricingRules holds the table pricing rules as JSON, e.g. {"extra_controller_hourly":
+	// {"ps5": 500, "ps4": 300}, "max_controllers": 4}. Missing rules do not apply.
+	SettingKeyPricingRules = "pricing_rules"
+	// SettingKeyHookahService holds the hookah coal change rules as JSON, e.g. {"coal_change_minutes": 20,
+	// "coal_item_id": 42, "coals_per_change": 3}. Without it there are no coal change reminders.
+	SettingKeyHookahService = "hookah_service"
+	// SettingKeyLostAndFound holds the lost and found rules as JSON, e.g. {"retention_days": 60}.
+	// Without it entries are purged 90 days after they were found; 0 keeps them forever.
+	SettingKeyLostAndFound = "lost_and_found"
+	// SettingKeyOpenRegistration holds "true" to let anyone register through POST /auth/register.
+	// Missing or "false", the default, staff join by invitation only.
+	SettingKeyOpenRegistration = "open_registration"
+	// SettingKeyClubName holds the name of the club, e.g. on receipts and emails.
+	SettingKeyClubName = "club_name"
+	// SettingKeyBusinessHours holds the opening hours per weekday as JSON, e.g.
+	// {"mon": {"open": "10:00", "close": "02:00"}}; a close before the open is past midnight.
+	SettingKeyBusinessHours = "business_hours"
+	// SettingKeySetupCompletedAt holds when the initial setup (POST /setup) was completed,
+	// which locks it. It is set by the setup and should not be changed.
+	SettingKeySetupCompletedAt = "setup_completed_at"
+	// SettingKeyPayloadLogging holds the routes whose request and response bodies are logged, redacted,
+	// for debugging as JSON, e.g. {"routes": ["POST /api/v1/orders", "/api/v1/bookings/*"], "max_body_bytes": 4096}.
+	// Missing or without routes, no bodies are logged.
+	SettingKeyPayloadLogging = "payload_logging"
+	// SettingKeySentryDSN holds the Sentry DSN (or of a compatible service) panics and server errors
+	// are reported to. It takes precedence over SENTRY_DSN; empty stops reporting.
+	SettingKeySentryDSN = "sentry_dsn"
+	// SettingKeyTax holds the taxes as JSON, e.g. {"name": "VAT", "mode": "inclusive", "classes":
+	// {"standard": 12, "exempt": 0}, "default_class": "standard"}. Missing, nothing is taxed.
+	SettingKeyTax = "tax"
+	// SettingKeyReorderPoints holds how reorder points are calculated from consumption as JSON, e.g.
+	// {"lookback_days": 30, "lead_time_days": 3, "safety_days": 2, "cover_days": 14, "auto_update": false}.
+	// With auto_update, the low stock thresholds of the items are set to them.
+	SettingKeyReorderPoints = "reorder_points"
+	// SettingKeyAttendance holds how attendance is checked against the shifts as JSON, e.g. {"grace_minutes": 5,
+	// "late_penalty": 1000, "early_departure_penalty": 1000, "missed_shift_penalty": 5000}. Penalties are optional.
+	SettingKeyAttendance = "attendance"
+	// SettingKeyClockIn holds where staff may clock in from as JSON, e.g. {"allowed_ips": ["192.168.1.0/24"],
+	// "latitude": 43.2389, "longitude": 76.8897, "radius_meters": 150}. Missing, clock-ins are not restricted.
+	SettingKeyClockIn = "clock_in"
+	// SettingKeyServiceCharge holds the service charge of large groups and VIP tables as JSON, e.g.
+	// {"percent": 10, "min_guests": 8, "table_ids": [12, 13]}. Missing, no order is charged.
+	SettingKeyServiceCharge = "service_charge"
+	// SettingKeyOrderArchive holds when completed orders are archived as JSON, e.g. {"retention_months": 12}.
+	// Missing or 0, orders are never archived.
+	SettingKeyOrderArchive = "order_archive"
+	// SettingKeyAnomalyDetection holds when the nightly check flags a day as JSON, e.g.
+	// {"threshold_percent": 50, "trailing_days": 28, "min_count": 3}. Missing, the defaults apply.
+	SettingKeyAnomalyDetection = "anomaly_detection"
+	// SettingKeyPushNotifications holds the push notifications of the client app as JSON, e.g.
+	// {"loyalty_milestones": [100, 500, 1000]}. Missing, the defaults apply.
+	SettingKeyPushNotifications = "push_notifications"
+	// SettingKeyBookingReminders holds how clients without preferences of their own are reminded
+	// of their bookings as JSON, e.g. {"channel": "sms", "lead_minutes": 120}. Missing, no
+	// reminders are sent to them.
+	SettingKeyBookingReminders = "booking_reminders"
+)
 
 // ApplicationSetting represents a key-value pair for application configuration
 type ApplicationSetting struct {
@@ -10,5 +93,86 @@ type ApplicationSetting struct {
 	Description   *string   `json:"description,omitempty" db:"description"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	Version       int       `json:"version" db:"version"` // Optimistic lock; send the last read version to reject stale updates (0 skips the check)
+}
+
+
+// secretSettingSuffixes end the keys of the settings holding secrets, such as API tokens and passwords.
+var secretSettingSuffixes = []string{"_password", "_token", "_secret", "_api_key"}
+
+// IsSecretSetting reports whether a setting holds a secret; secret values are stored encrypted.
+func IsSecretSetting(key string) bool {
+	for _, suffix := range secretSettingSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOpenRegistration parses the open_registration setting; empty means closed.
+func ParseOpenRegistration(value string) (bool, error) {
+	if strings.TrimSpace(value) == "" {
+		return false, nil
+	}
+	open, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("invalid open_registration %q, use true or false", value)
+	}
+	return open, nil
 }
 
+// DefaultPayloadLogBytes caps each logged body unless the payload_logging setting sets max_body_bytes.
+const DefaultPayloadLogBytes = 4096
+
+// maxPayloadLogBytes is the highest max_body_bytes of the payload_logging setting.
+const maxPayloadLogBytes = 64 * 1024
+
+// PayloadLogging is the payload_logging setting.
+type PayloadLogging struct {
+	// Routes are the routes whose bodies are logged, as registered (e.g. "/api/v1/orders/:id"),
+	// optionally preceded by a method ("POST /api/v1/orders") and ending in "*" to match a prefix.
+	Routes []string `json:"routes"`
+	// MaxBodyBytes caps each logged body; longer ones are cut off.
+	MaxBodyBytes int `json:"max_body_bytes"`
+}
+
+// Matches reports whether the bodies of requests with method to the route are logged.
+func (p PayloadLogging) Matches(method, route string) bool {
+	for _, pattern := range p.Routes {
+		if m, path, ok := strings.Cut(pattern, " "); ok {
+			if !strings.EqualFold(m, method) {
+				continue
+			}
+			pattern = strings.TrimSpace(path)
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(route, prefix) {
+				return true
+			}
+		} else if route == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePayloadLogging parses the payload_logging setting; empty logs no bodies.
+func ParsePayloadLogging(value string) (PayloadLogging, error) {
+	settings := PayloadLogging{MaxBodyBytes: DefaultPayloadLogBytes}
+	if strings.TrimSpace(value) == "" {
+		return PayloadLogging{}, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return PayloadLogging{}, fmt.Errorf("invalid payload logging settings: %w", err)
+	}
+	if settings.MaxBodyBytes <= 0 || settings.MaxBodyBytes > maxPayloadLogBytes {
+		return PayloadLogging{}, fmt.Errorf("max_body_bytes must be between 1 and %d", maxPayloadLogBytes)
+	}
+	for _, route := range settings.Routes {
+		if strings.TrimSpace(route) == "" {
+			return PayloadLogging{}, fmt.Errorf("routes cannot be empty")
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays are the keys of BusinessHours.
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// OpeningHours are the hours of one weekday, as HH:MM in club time. A Close before Open
+// means the club closes after midnight.
+type OpeningHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// BusinessHours are the opening hours by weekday (Weekdays); days not listed are closed.
+type BusinessHours map[string]OpeningHours
+
+// Validate checks the weekdays and times of the business hours.
+func (h BusinessHours) Validate() error {
+	for day, hours := range h {
+		known := false
+		for _, weekday := range Weekdays {
+			if day == weekday {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("invalid business hours day %q, use one of %v", day, Weekdays)
+		}
+		for _, t := range []string{hours.Open, hours.Close} {
+			if _, err := time.Parse("15:04", t); err != nil {
+				return fmt.Errorf("invalid business hours time %q of %s, use HH:MM", t, day)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseBusinessHours parses the business_hours setting; empty means none are set.
+func ParseBusinessHours(value string) (BusinessHours, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var hours BusinessHours
+	if err := json.Unmarshal([]byte(value), &hours); err != nil {
+		return nil, fmt.Errorf("invalid business hours: %w", err)
+	}
+	if err := hours.Validate(); err != nil {
+		return nil, err
+	}
+	return hours, nil
+}
+
+// SetupStatus tells whether the initial setup is still to be done: it is on first boot,
+// while there are no users, until POST /setup is completed.
+type SetupStatus struct {
+	SetupRequired bool       `json:"setup_required"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
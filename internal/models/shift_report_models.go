@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Payment methods the shift report treats specially. Orders record the payment method as
+// free text; only "cash" goes into the drawer.
+const (
+	PaymentMethodCash        = "cash"
+	PaymentMethodUnspecified = "unspecified" // Reported for orders without a payment method
+)
+
+// ShiftReport is the end-of-shift report generated when a staff member clocks out. Sales and
+// cash cover the whole branch from clock-in to clock-out, since the drawer is shared.
+type ShiftReport struct {
+	ID               int64     `json:"id"`
+	BranchCode       string    `json:"branch_code"`
+	TimeClockEntryID int64     `json:"time_clock_entry_id"`
+	StaffID          int64     `json:"staff_id"`
+	StaffName        string    `json:"staff_name"`
+	ShiftID          *int64    `json:"shift_id,omitempty"` // The scheduled shift the time clock entry overlaps
+	StartedAt        time.Time `json:"started_at"`
+	EndedAt          time.Time `json:"ended_at"`
+	// Sales are the completed and paid orders of the branch placed during the shift
+	OrderCount     int                 `json:"order_count"`
+	SalesTotal     Money               `json:"sales_total"`
+	SalesByPayment []ShiftPaymentTotal `json:"sales_by_payment"`
+	// Sessions are the table sessions stopped during the shift
+	SessionCount int                     `json:"session_count"`
+	SessionTotal Money                   `json:"session_total"`
+	Cash         ShiftCashReconciliation `json:"cash"`
+	// OpenOrders are the orders of the branch still open at clock-out, handed over to the next shift
+	OpenOrders    []HandoverOrder `json:"open_orders"`
+	HandoverNotes *string         `json:"handover_notes,omitempty"`
+	EmailedAt     *time.Time      `json:"emailed_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ShiftPaymentTotal is the sales of a shift with one payment method.
+type ShiftPaymentTotal struct {
+	PaymentMethod string `json:"payment_method"` // PaymentMethodUnspecified for orders without one
+	OrderCount    int    `json:"order_count"`
+	Amount        Money  `json:"amount"`
+}
+
+// ShiftCashReconciliation compares the cash the drawer should hold at clock-out with the
+// cash counted: the opening cash, plus cash sales, cash payments of house accounts and the
+// cash deposits of table sessions still held or applied.
+type ShiftCashReconciliation struct {
+	OpeningCash         Money  `json:"opening_cash"`
+	CashSales           Money  `json:"cash_sales"`
+	CashAccountPayments Money  `json:"cash_account_payments"`
+	CashDeposits        Money  `json:"cash_deposits"` // Taken during the shift less the refunds handed back
+	ExpectedCash        Money  `json:"expected_cash"`
+	CountedCash         *Money `json:"counted_cash,omitempty"` // nil if the drawer was not counted
+	Difference          *Money `json:"difference,omitempty"`   // CountedCash - ExpectedCash; negative if cash is missing
+}
+
+// HandoverOrder is an order still open at the end of a shift.
+type HandoverOrder struct {
+	OrderID     int64     `json:"order_id"`
+	OrderNumber string    `json:"order_number"`
+	TableID     *int64    `json:"table_id,omitempty"`
+	Status      string    `json:"status"`
+	FinalAmount Money     `json:"final_amount"`
+	OrderTime   time.Time `json:"order_time"`
+}
+
+// ShiftReportFilters selects shift reports.
+type ShiftReportFilters struct {
+	StaffID *int64
+	From    *time.Time // Ended at or after
+	To      *time.Time // Ended before
+}
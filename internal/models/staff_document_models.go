@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Staff document types.
+const (
+	StaffDocumentContract    = "employment_contract"
+	StaffDocumentMedicalBook = "medical_book"
+	StaffDocumentCertificate = "certificate" // E.g. a hygiene or first aid certificate; its title names it
+)
+
+// StaffDocumentTypes lists the staff document types.
+var StaffDocumentTypes = []string{StaffDocumentContract, StaffDocumentMedicalBook, StaffDocumentCertificate}
+
+// StaffDocument describes a document of a staff member, served by GET /staff/:id/documents/:document_id.
+type StaffDocument struct {
+	ID               int64      `json:"id"`
+	StaffID          int64      `json:"staff_id"`
+	StaffName        string     `json:"staff_name,omitempty"`
+	DocumentType     string     `json:"document_type"` // One of StaffDocumentTypes
+	Title            *string    `json:"title,omitempty"`
+	ContentType      string     `json:"content_type"`
+	Size             int        `json:"size"`                 // In bytes
+	ExpiresOn        *string    `json:"expires_on,omitempty"` // YYYY-MM-DD; valid through this day, nil if it does not expire
+	Expired          bool       `json:"expired"`
+	UploadedBy       *int64     `json:"uploaded_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiryNotifiedAt *time.Time `json:"expiry_notified_at,omitempty"`
+}
+
+// ExpiringStaffDocument is a document of a staff member that expires soon or has expired and
+// was not replaced by a newer one.
+type ExpiringStaffDocument struct {
+	StaffDocument
+	DaysUntilExpiry int `json:"days_until_expiry"` // 0 on its expiry date, negative once expired
+}
@@ -10,7 +10,7 @@ type StaffMember struct {
 	Address      *string   `json:"address,omitempty" db:"address"`
 	HireDate     *string   `json:"hire_date,omitempty" db:"hire_date"` // Store as string, parse to time.Time when needed
 	Position     *string   `json:"position,omitempty" db:"position"`
-	Salary       *float64  `json:"salary,omitempty" db:"salary"`
+	Salary       *Money    `json:"salary,omitempty" db:"salary"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 	User         *User     `json:"user,omitempty"` // For joining with User details (like full_name, email from users table)
@@ -28,3 +28,14 @@ type Shift struct {
 	StaffMember *StaffMember `json:"staff_member,omitempty"` // For joining with StaffMember details
 }
 
+
+// TimeClockEntry records when a staff member actually clocked in and out.
+type TimeClockEntry struct {
+	ID         int64        `json:"id" db:"id"`
+	StaffID    int64        `json:"staff_id" db:"staff_id"`
+	ClockInAt  time.Time    `json:"clock_in_at" db:"clock_in_at"`
+	ClockOutAt *time.Time   `json:"clock_out_at,omitempty" db:"clock_out_at"` // nil while clocked in
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at" db:"updated_at"`
+	Report     *ShiftReport `json:"report,omitempty"` // The end-of-shift report, set on clock-out
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// StockBatch is a batch of a stock-tracked item received with a batch number and/or expiry date.
+type StockBatch struct {
+	ID                 int64      `json:"id"`
+	PricelistItemID    int64      `json:"pricelist_item_id"`
+	ItemName           string     `json:"item_name,omitempty"`
+	StockUnit          string     `json:"stock_unit,omitempty"`
+	BatchNumber        *string    `json:"batch_number,omitempty"`
+	ExpiryDate         *string    `json:"expiry_date,omitempty"` // YYYY-MM-DD; the batch may be used through this day
+	QuantityReceived   Quantity   `json:"quantity_received"`     // In the stock unit of the item
+	QuantityRemaining  Quantity   `json:"quantity_remaining"`
+	ReceivedAt         time.Time  `json:"received_at"`
+	MovementID         *int64     `json:"movement_id,omitempty"` // The receipt
+	WrittenOffAt       *time.Time `json:"written_off_at,omitempty"`
+	WriteOffMovementID *int64     `json:"write_off_movement_id,omitempty"`
+}
+
+// StockBatchFilters selects stock batches.
+type StockBatchFilters struct {
+	ItemID            *int64
+	InStock           bool    // Only batches with stock remaining
+	ExpiresOnOrBefore *string // YYYY-MM-DD; only batches with an expiry date on or before it
+}
+
+// ExpiringStockBatch is a batch in stock that expires soon or has expired.
+type ExpiringStockBatch struct {
+	StockBatch
+	DaysUntilExpiry int  `json:"days_until_expiry"` // 0 on its expiry date, negative once expired
+	Expired         bool `json:"expired"`
+}
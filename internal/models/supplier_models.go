@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Supplier is a company the club buys stock from.
+type Supplier struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	ContactName *string   `json:"contact_name,omitempty"`
+	PhoneNumber *string   `json:"phone_number,omitempty"`
+	Email       *string   `json:"email,omitempty"`
+	Notes       *string   `json:"notes,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SupplierPrice is the price a supplier sells a pricelist item at.
+type SupplierPrice struct {
+	SupplierID       int64     `json:"supplier_id"`
+	PricelistItemID  int64     `json:"pricelist_item_id"`
+	ItemName         string    `json:"item_name,omitempty"`
+	ItemSKU          *string   `json:"item_sku,omitempty"`
+	Price            Money     `json:"price"` // Per unit
+	MinOrderQuantity int       `json:"min_order_quantity"`
+	SupplierSKU      *string   `json:"supplier_sku,omitempty"` // The supplier's own code for the item
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SupplierOffer is what one supplier offers an item at, for comparing suppliers.
+type SupplierOffer struct {
+	SupplierID       int64   `json:"supplier_id"`
+	SupplierName     string  `json:"supplier_name"`
+	Price            Money   `json:"price"`
+	MinOrderQuantity int     `json:"min_order_quantity"`
+	SupplierSKU      *string `json:"supplier_sku,omitempty"`
+}
+
+// StockConsumption is the stock of a stock-tracked item and how much of it was used in a period.
+type StockConsumption struct {
+	PricelistItemID   int64    `json:"pricelist_item_id"`
+	ItemName          string   `json:"item_name"`
+	SKU               *string  `json:"sku,omitempty"`
+	StockUnit         string   `json:"stock_unit"`
+	CurrentStock      Quantity `json:"current_stock"`
+	LowStockThreshold Quantity `json:"low_stock_threshold"`
+	Consumed          Quantity `json:"consumed"` // Sold and used as components, net of returns
+	PurchaseUnit      *string  `json:"purchase_unit,omitempty"`
+	PurchaseUnitSize  Quantity `json:"purchase_unit_size"` // Stock units in a purchase unit
+}
+
+// PurchaseSuggestion proposes how much of a low-stock item to buy, and from whom.
+type PurchaseSuggestion struct {
+	StockConsumption
+	DailyConsumption  decimal.Decimal `json:"daily_consumption"`
+	SuggestedQuantity int             `json:"suggested_quantity"`       // In purchase units, which supplier prices are per
+	Cheapest          *SupplierOffer  `json:"cheapest,omitempty"`       // nil if no supplier sells the item
+	EstimatedCost     *Money          `json:"estimated_cost,omitempty"` // Of the suggested quantity at the cheapest price
+	Offers            []SupplierOffer `json:"offers"`                   // Cheapest first
+}
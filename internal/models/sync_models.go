@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Entities a POS keeps offline, in the changes of GET /sync/changes.
+const (
+	SyncEntityPricelistCategory = "pricelist_category"
+	SyncEntityPricelistItem     = "pricelist_item"
+	SyncEntityGameTable         = "game_table"
+	SyncEntityClient            = "client"
+)
+
+// SyncEntities lists the entities a POS keeps offline.
+var SyncEntities = []string{SyncEntityPricelistCategory, SyncEntityPricelistItem, SyncEntityGameTable, SyncEntityClient}
+
+// SyncChange is a record created, updated or deleted after a sync cursor. Data is the record as
+// of the change (a SyncPricelistCategory, SyncPricelistItem, GameTable or SyncClient); it is nil
+// for a deletion.
+type SyncChange struct {
+	Version int64       `json:"version"` // Orders the changes; the cursor of the next pull is the last one applied
+	Entity  string      `json:"entity"`  // One of SyncEntities
+	ID      int64       `json:"id"`
+	Deleted bool        `json:"deleted,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// SyncChanges is a page of changes in version order.
+type SyncChanges struct {
+	Changes    []SyncChange `json:"changes"`
+	Cursor     int64        `json:"cursor"`   // Pass as ?cursor= to pull the changes after these
+	HasMore    bool         `json:"has_more"` // More changes follow; pull again right away
+	ServerTime time.Time    `json:"server_time"`
+}
+
+// SyncPricelistCategory is a pricelist category as a POS keeps it offline.
+type SyncPricelistCategory struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// SyncPricelistItem is a pricelist item as a POS keeps it offline.
+type SyncPricelistItem struct {
+	ID           int64     `json:"id"`
+	CategoryID   int64     `json:"category_id"`
+	Name         string    `json:"name"`
+	Price        Money     `json:"price"`
+	SKU          *string   `json:"sku,omitempty"`
+	IsAvailable  bool      `json:"is_available"`
+	ItemType     string    `json:"item_type"`
+	TracksStock  bool      `json:"tracks_stock"`
+	CurrentStock *Quantity `json:"current_stock,omitempty"`
+	TaxClass     *string   `json:"tax_class,omitempty"`
+}
+
+// SyncClient is a client as a POS keeps it offline: enough to find them and charge their orders.
+type SyncClient struct {
+	ID            int64   `json:"id"`
+	FullName      string  `json:"full_name"`
+	PhoneNumber   *string `json:"phone_number,omitempty"`
+	LoyaltyPoints *int    `json:"loyalty_points,omitempty"`
+	Blacklisted   bool    `json:"blacklisted"`
+}
+
+// Conflicts met when an order taken offline is synced, and how they are resolved.
+const (
+	SyncConflictPriceChanged   = "price_changed"   // The price the POS charged is kept
+	SyncConflictStockShortfall = "stock_shortfall" // The item is sold anyway and its stock goes short
+	SyncConflictClientMissing  = "client_missing"  // The order is kept without a client
+	SyncConflictTableMissing   = "table_missing"   // The order is kept without a table
+)
+
+// SyncConflict is a difference between an order taken offline and the data of the server.
+type SyncConflict struct {
+	Type            string `json:"type"` // One of the SyncConflict constants
+	PricelistItemID *int64 `json:"pricelist_item_id,omitempty"`
+	Detail          string `json:"detail"`
+}
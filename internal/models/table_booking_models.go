@@ -14,50 +14,177 @@ const (
 	// Add other statuses if they are used or anticipated
 )
 
+// BookingStatuses lists the valid booking statuses.
+var BookingStatuses = []BookingStatus{
+	BookingStatusPending,
+	BookingStatusConfirmed,
+	BookingStatusCompleted,
+	BookingStatusCancelled,
+	BookingStatusNoShow,
+}
+
 // IsValidBookingStatus checks if the provided status string is a valid BookingStatus.
 func IsValidBookingStatus(status string) bool {
-	s := BookingStatus(status) // Convert string to BookingStatus type for comparison
-	switch s {
-	case BookingStatusConfirmed,
-		 BookingStatusCancelled,
-		 BookingStatusCompleted,
-		 BookingStatusNoShow,
-		 BookingStatusPending:
-		return true
-	default:
-		return false
+	for _, valid := range BookingStatuses {
+		if BookingStatus(status) == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Reasons a booking is cancelled for, required to cancel one.
+const (
+	CancellationReasonClientRequest     = "client_request"
+	CancellationReasonClientUnreachable = "client_unreachable"
+	CancellationReasonScheduleConflict  = "schedule_conflict"
+	CancellationReasonEquipmentIssue    = "equipment_issue"
+	CancellationReasonClubClosure       = "club_closure"
+	CancellationReasonDuplicate         = "duplicate"
+	CancellationReasonOther             = "other"
+)
+
+// CancellationReasons lists the valid cancellation reasons.
+var CancellationReasons = []string{
+	CancellationReasonClientRequest, CancellationReasonClientUnreachable, CancellationReasonScheduleConflict,
+	CancellationReasonEquipmentIssue, CancellationReasonClubClosure, CancellationReasonDuplicate, CancellationReasonOther,
+}
+
+// IsValidCancellationReason checks if the provided string is one of CancellationReasons.
+func IsValidCancellationReason(reason string) bool {
+	for _, valid := range CancellationReasons {
+		if reason == valid {
+			return true
+		}
 	}
+	return false
+}
+
+// Game table statuses. Reserved and occupied follow the bookings and sessions of the table;
+// the staff set cleaning and maintenance.
+const (
+	TableStatusAvailable   = "available"
+	TableStatusOccupied    = "occupied"    // A session runs or a checked-in booking is in progress
+	TableStatusReserved    = "reserved"    // A booking starts soon
+	TableStatusCleaning    = "cleaning"    // Being cleaned after a visit
+	TableStatusMaintenance = "maintenance" // Out of service; no session can start
+)
+
+// TableStatuses lists the valid game table statuses.
+var TableStatuses = []string{TableStatusAvailable, TableStatusOccupied, TableStatusReserved, TableStatusCleaning, TableStatusMaintenance}
+
+// ManualTableStatuses are the statuses the staff may set; the others are derived.
+var ManualTableStatuses = []string{TableStatusAvailable, TableStatusCleaning, TableStatusMaintenance}
+
+// tableStatusTransitions lists the statuses a game table may change to from each status. A
+// table in use cannot go under maintenance, and one under maintenance must be made available first.
+var tableStatusTransitions = map[string][]string{
+	TableStatusAvailable:   {TableStatusOccupied, TableStatusReserved, TableStatusCleaning, TableStatusMaintenance},
+	TableStatusReserved:    {TableStatusAvailable, TableStatusOccupied, TableStatusCleaning, TableStatusMaintenance},
+	TableStatusOccupied:    {TableStatusAvailable, TableStatusReserved, TableStatusCleaning},
+	TableStatusCleaning:    {TableStatusAvailable, TableStatusOccupied, TableStatusReserved, TableStatusMaintenance},
+	TableStatusMaintenance: {TableStatusAvailable},
+}
+
+// IsValidTableStatus checks if the provided string is one of TableStatuses.
+func IsValidTableStatus(status string) bool {
+	for _, valid := range TableStatuses {
+		if status == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// CanChangeTableStatus reports whether a game table may change from one status to another.
+func CanChangeTableStatus(from, to string) bool {
+	for _, next := range tableStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TableOverview is a station as the POS shows it: the table, its running session billed up to
+// now, its bookings of the day still to come or in progress and its open orders.
+type TableOverview struct {
+	Table       GameTable             `json:"table"`
+	Session     *TableOverviewSession `json:"session"`      // Nil when no session runs
+	Bookings    []Booking             `json:"bookings"`     // Pending and confirmed, by start time
+	Orders      []Order               `json:"orders"`       // Open orders with their items, oldest first
+	OrderAmount Money                 `json:"order_amount"` // Final amount of the open orders
+	TotalAmount Money                 `json:"total_amount"` // The session so far plus the open orders
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// TableOverviewSession is the running session of a station with its time so far.
+type TableOverviewSession struct {
+	TableSession
+	ElapsedMinutes   int  `json:"elapsed_minutes"`
+	RemainingMinutes *int `json:"remaining_minutes,omitempty"` // Prepaid sessions; 0 in overtime
+}
+
+// TableStatusChange is a game table whose status differs from the one its bookings and
+// sessions call for.
+type TableStatusChange struct {
+	TableID int64
+	From    string
+	To      string
 }
 
 // GameTable represents a physical table or console in the club
 type GameTable struct {
-	ID          int64     `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" binding:"required"`
-	Description *string   `json:"description,omitempty" db:"description"`
-	Status      string    `json:"status" db:"status"` // e.g., available, occupied, reserved, maintenance
-	Capacity    *int      `json:"capacity,omitempty" db:"capacity"`
-	HourlyRate  *float64  `json:"hourly_rate,omitempty" db:"hourly_rate"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID              int64     `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name" binding:"required"`
+	Description     *string   `json:"description,omitempty" db:"description"`
+	Status          string    `json:"status" db:"status"` // One of TableStatuses
+	Capacity        *int      `json:"capacity,omitempty" db:"capacity"`
+	HourlyRate      *Money    `json:"hourly_rate,omitempty" db:"hourly_rate"`
+	BufferMinutes   *int      `json:"buffer_minutes,omitempty" db:"buffer_minutes" binding:"omitempty,min=0"`     // Overrides BookingPolicy.BufferMinutes for the table
+	PowerDeviceID   *string   `json:"power_device_id,omitempty" db:"power_device_id" binding:"omitempty,max=100"` // Smart plug or agent switched with the sessions of the table
+	ConsoleType     *string   `json:"console_type,omitempty" db:"console_type" binding:"omitempty,console_type"`  // One of ConsoleTypes
+	BaseControllers int       `json:"base_controllers" db:"base_controllers" binding:"omitempty,min=1"`           // Controllers HourlyRate includes; 0 on create or update means DefaultBaseControllers
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	// Billing; per-minute tables are played by the stopwatch, see BillingModePerMinute
+	BillingMode             string `json:"billing_mode" db:"billing_mode" binding:"omitempty,billing_mode"`                    // One of BillingModes; empty on create or update means hourly
+	MinuteRate              *Money `json:"minute_rate,omitempty" db:"minute_rate" binding:"omitempty,money"`                   // Charged per minute in per_minute mode, where it is required
+	BillingIncrementMinutes int    `json:"billing_increment_minutes" db:"billing_increment_minutes" binding:"omitempty,min=1"` // Per-minute billing rounds up to it; 0 on create or update means DefaultBillingIncrementMinutes
+	MinimumCharge           *Money `json:"minimum_charge,omitempty" db:"minimum_charge" binding:"omitempty,money"`             // Least a per-minute table charges
 }
 
 // Booking represents a reservation for a game table
 type Booking struct {
-	ID             int64      `json:"id" db:"id"`
-	ClientID       *int64     `json:"client_id,omitempty" db:"client_id"`
-	TableID        int64      `json:"table_id" db:"table_id" binding:"required"`
-	StaffID        *int64     `json:"staff_id,omitempty" db:"staff_id"`
-	StartTime      time.Time  `json:"start_time" db:"start_time" binding:"required"`
-	EndTime        time.Time  `json:"end_time" db:"end_time" binding:"required"`
-	NumberOfGuests *int       `json:"number_of_guests,omitempty" db:"number_of_guests"`
-	Status         string     `json:"status" db:"status"` // e.g., confirmed, cancelled, completed, no-show
-	Notes          *string    `json:"notes,omitempty" db:"notes"`
-	TotalPrice     *float64   `json:"total_price,omitempty" db:"total_price"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
-	Client         *Client    `json:"client,omitempty"`    // For joining with Client details
-	GameTable      *GameTable `json:"game_table,omitempty"` // For joining with GameTable details
-	StaffMember    *StaffMember `json:"staff_member,omitempty"` // For joining with StaffMember details
+	ID                 int64        `json:"id" db:"id"`
+	ClientID           *int64       `json:"client_id,omitempty" db:"client_id"`
+	TableID            int64        `json:"table_id" db:"table_id" binding:"required"`
+	StaffID            *int64       `json:"staff_id,omitempty" db:"staff_id"`
+	StartTime          time.Time    `json:"start_time" db:"start_time" binding:"required"`
+	EndTime            time.Time    `json:"end_time" db:"end_time" binding:"required"`
+	NumberOfGuests     *int         `json:"number_of_guests,omitempty" db:"number_of_guests"`
+	Status             string       `json:"status" db:"status"` // e.g., confirmed, cancelled, completed, no-show
+	Notes              *string      `json:"notes,omitempty" db:"notes"`
+	TotalPrice         *Money       `json:"total_price,omitempty" db:"total_price"`
+	Controllers        *int         `json:"controllers,omitempty" db:"controllers"` // Nil for the table's base controllers
+	CreatedAt          time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at" db:"updated_at"`
+	Version            int          `json:"version" db:"version"`                                   // Optimistic lock, incremented on every update
+	CancellationReason *string      `json:"cancellation_reason,omitempty" db:"cancellation_reason"` // One of CancellationReasons, set when cancelled
+	CancellationFee    *Money       `json:"cancellation_fee,omitempty" db:"cancellation_fee"`       // Late cancellation fee charged, per the booking policy
+	CleanupUntil       *time.Time   `json:"cleanup_until,omitempty" db:"-"`                         // End of the cleanup buffer after the booking, when there is one
+	BufferMinutes      int          `json:"-" db:"buffer_minutes"`                                  // Cleanup buffer the booking was made with; bookings_no_overlap keeps it free
+	CheckInToken       string       `json:"check_in_token,omitempty" db:"check_in_token"`           // Scanned at the kiosk to check in
+	CheckInQR          string       `json:"check_in_qr,omitempty" db:"-"`                           // PNG QR code of CheckInToken as a data URI, on single-booking responses
+	CheckedInAt        *time.Time   `json:"checked_in_at,omitempty" db:"checked_in_at"`             // When the client checked in
+	UUID               *string      `json:"uuid,omitempty" db:"uuid"`                               // Given by the client that created the booking, so a retry does not create it twice
+	Source             string       `json:"source" db:"source"`                                     // One of OrderSources: the channel the booking was made through
+	Client             *Client      `json:"client,omitempty"`                                       // For joining with Client details
+	GameTable          *GameTable   `json:"game_table,omitempty"`                                   // For joining with GameTable details
+	StaffMember        *StaffMember `json:"staff_member,omitempty"`                                 // For joining with StaffMember details
+
+	LockerRentals []LockerRental `json:"locker_rentals,omitempty" db:"-"` // Lockers rented for the visit, on single-booking responses
 }
 
 // BookingFilters defines the available filters for querying bookings.
@@ -65,10 +192,43 @@ type BookingFilters struct {
 	ClientID  *int64     `form:"client_id"`
 	TableID   *int64     `form:"table_id"`
 	StaffID   *int64     `form:"staff_id"`
-	DateFrom  *time.Time `form:"date_from"` // Expect YYYY-MM-DD, time part will be ignored or set to start/end of day
-	DateTo    *time.Time `form:"date_to"`   // Expect YYYY-MM-DD
+	DateFrom  *time.Time `form:"date_from"` // Expect YYYY-MM-DD; start of that day in the club timezone, in UTC
+	DateTo    *time.Time `form:"date_to"`   // Expect YYYY-MM-DD; start of the following day in the club timezone, in UTC
 	Status    *string    `form:"status"`
+	ClientTag *string    `form:"client_tag"` // Bookings of clients with this tag
+	Source    *string    `form:"source"`     // One of OrderSources
 	Page      int        `form:"page"`
 	PageSize  int        `form:"page_size"`
+	// Statuses, EndsAfter and StartsBefore select the bookings of a period by status, e.g. those
+	// still to come or in progress on a day
+	Statuses     []string   `form:"-"`
+	EndsAfter    *time.Time `form:"-"`
+	StartsBefore *time.Time `form:"-"`
+	// Cursor switches to cursor pagination: bookings after it, by start_time and ID, instead
+	// of Page, without counting the total
+	Cursor *Cursor `form:"-"`
 }
 
+// Types of booking changes recorded in the booking history.
+const (
+	BookingChangeCreated     = "created"
+	BookingChangeRescheduled = "rescheduled" // Start or end time moved
+	BookingChangeTableMoved  = "table_changed"
+	BookingChangeStatus      = "status_changed"
+	BookingChangeCheckedIn   = "checked_in" // The client checked in at the kiosk
+)
+
+// BookingChange is an entry of a booking's history. Old and new values are the status, the
+// table ID, or the period as "start/end" in RFC 3339, depending on the change type.
+type BookingChange struct {
+	ID                 int64     `json:"id"`
+	BookingID          int64     `json:"booking_id"`
+	ChangeType         string    `json:"change_type"`
+	OldValue           *string   `json:"old_value,omitempty"`
+	NewValue           *string   `json:"new_value,omitempty"`
+	Reason             *string   `json:"reason,omitempty"`              // Given by the user making the change
+	CancellationReason *string   `json:"cancellation_reason,omitempty"` // Of a change to cancelled
+	ChangedBy          *int64    `json:"changed_by,omitempty"`          // User ID; nil once the user is deleted
+	ChangedByName      *string   `json:"changed_by_name,omitempty"`
+	ChangedAt          time.Time `json:"changed_at"`
+}
@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+// Table session statuses.
+const (
+	TableSessionStatusActive   = "active"
+	TableSessionStatusOvertime = "overtime" // The time limit passed; the session continues at the overtime rate
+	TableSessionStatusStopped  = "stopped"
+)
+
+// What happens to a prepaid session when its time limit runs out.
+const (
+	SessionExpiryStop     = "stop"     // The session is stopped automatically
+	SessionExpiryOvertime = "overtime" // The session continues and every started minute is charged the overtime rate
+)
+
+// SessionExpiryActions lists the valid expiry actions.
+var SessionExpiryActions = []string{SessionExpiryStop, SessionExpiryOvertime}
+
+// IsValidSessionExpiryAction reports whether action is one of SessionExpiryActions.
+func IsValidSessionExpiryAction(action string) bool {
+	for _, valid := range SessionExpiryActions {
+		if action == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// TableSession is the time a game table is in play, from its start until it is stopped.
+type TableSession struct {
+	ID              int64      `json:"id"`
+	TableID         int64      `json:"table_id"`
+	BookingID       *int64     `json:"booking_id,omitempty"`
+	ClientID        *int64     `json:"client_id,omitempty"`
+	StartedBy       *int64     `json:"started_by,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	LimitMinutes    *int       `json:"limit_minutes,omitempty"` // Prepaid time; nil for an open session
+	EndsAt          *time.Time `json:"ends_at,omitempty"`       // StartedAt plus LimitMinutes
+	OnExpiry        string     `json:"on_expiry"`               // One of SessionExpiryActions
+	OvertimeRate    *Money     `json:"overtime_rate,omitempty"` // Per minute of overtime
+	Controllers     *int       `json:"controllers,omitempty"`   // Nil for the table's base controllers
+	HourlyRate      *Money     `json:"hourly_rate,omitempty"`   // Table rate plus extra controllers, when the session started
+	Status          string     `json:"status"`
+	WarnedMinutes   *int       `json:"-"`                    // Lowest remaining-time warning sent so far
+	StoppedAt       *time.Time `json:"stopped_at,omitempty"` // Set when the session is stopped
+	StoppedBy       *int64     `json:"stopped_by,omitempty"` // Nil if the session was stopped at its time limit
+	OvertimeMinutes int        `json:"overtime_minutes"`
+	OvertimeAmount  Money      `json:"overtime_amount"`
+	TimeAmount      Money      `json:"time_amount"`  // Prepaid time, or the minutes played of an open session, at HourlyRate or MinuteRate
+	TotalAmount     Money      `json:"total_amount"` // TimeAmount plus OvertimeAmount plus LockerAmount
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Billing terms of the table when the session started, see BillingModePerMinute
+	BillingMode             string `json:"billing_mode"`
+	MinuteRate              *Money `json:"minute_rate,omitempty"` // Per-minute sessions: table rate plus extra controllers, per minute
+	BillingIncrementMinutes *int   `json:"billing_increment_minutes,omitempty"`
+	MinimumCharge           *Money `json:"minimum_charge,omitempty"`
+	BilledMinutes           int    `json:"billed_minutes"`        // Minutes TimeAmount charges, rounded up to the increment on per-minute sessions
+	MinimumChargeAmount     Money  `json:"minimum_charge_amount"` // Part of TimeAmount that tops it up to the minimum charge
+
+	// Lockers rented for the visit, with the session or its booking
+	LockerRentals []LockerRental `json:"locker_rentals,omitempty"`
+	LockerAmount  Money          `json:"locker_amount"` // Fees of LockerRentals
+
+	Deposit *SessionDeposit `json:"deposit,omitempty"` // Taken when the session started
+}
+
+// Running reports whether the session has not been stopped.
+func (s *TableSession) Running() bool {
+	return s.Status != TableSessionStatusStopped
+}
+
+// Methods of session deposits.
+const (
+	DepositMethodCash        = "cash"         // Handed over at the counter; the remainder is handed back
+	DepositMethodCardPreAuth = "card_preauth" // A hold on the client's card at the payment provider
+)
+
+// DepositMethods lists the valid deposit methods.
+var DepositMethods = []string{DepositMethodCash, DepositMethodCardPreAuth}
+
+// Session deposit statuses.
+const (
+	DepositStatusHeld      = "held"      // The session is running
+	DepositStatusCapturing = "capturing" // The payment provider is asked to capture the pre-authorization
+	DepositStatusFailed    = "failed"    // The provider refused the capture; see FailureReason
+	DepositStatusSettled   = "settled"   // Applied to the bill and the remainder refunded
+)
+
+// DepositStatuses lists the session deposit statuses.
+var DepositStatuses = []string{DepositStatusHeld, DepositStatusCapturing, DepositStatusFailed, DepositStatusSettled}
+
+// SessionDeposit is a holding deposit taken when a table session starts. When the session
+// stops it pays the bill up to its amount and the rest is refunded.
+type SessionDeposit struct {
+	ID              int64      `json:"id"`
+	TableSessionID  int64      `json:"table_session_id"`
+	ClientID        *int64     `json:"client_id,omitempty"`
+	Method          string     `json:"method"` // One of DepositMethods
+	Amount          Money      `json:"amount"`
+	Status          string     `json:"status"`                     // One of DepositStatuses
+	AuthorizationID *string    `json:"authorization_id,omitempty"` // Pre-authorization at the payment provider
+	AppliedAmount   Money      `json:"applied_amount"`             // Paid towards the session bill
+	RefundedAmount  Money      `json:"refunded_amount"`            // Handed back or released
+	FailureReason   *string    `json:"failure_reason,omitempty"`
+	TakenBy         *int64     `json:"taken_by,omitempty"`
+	TakenAt         time.Time  `json:"taken_at"`
+	SettledAt       *time.Time `json:"settled_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Settle applies the deposit to a bill of total: it pays up to its amount and the rest is refunded.
+func (d *SessionDeposit) Settle(total Money) {
+	d.AppliedAmount = d.Amount
+	if total.Cmp(d.Amount) < 0 {
+		d.AppliedAmount = total
+	}
+	if d.AppliedAmount.IsNegative() {
+		d.AppliedAmount = ZeroMoney
+	}
+	d.RefundedAmount = d.Amount.Sub(d.AppliedAmount)
+}
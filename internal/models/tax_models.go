@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Tax modes of the tax setting.
+const (
+	TaxModeInclusive = "inclusive" // Prices include tax, which is part of them
+	TaxModeExclusive = "exclusive" // Tax is added on top of the prices
+)
+
+// DefaultTaxName is printed on receipts for a tax setting without a name.
+const DefaultTaxName = "VAT"
+
+// TaxSettings are the club's taxes (the tax setting). The zero value taxes nothing.
+type TaxSettings struct {
+	Name         string                     `json:"name,omitempty"`          // Printed on receipts, e.g. "VAT" (the default)
+	Mode         string                     `json:"mode"`                    // TaxModeInclusive (the default) or TaxModeExclusive
+	Classes      map[string]decimal.Decimal `json:"classes"`                 // Rate in percent per tax class, e.g. {"standard": 12, "exempt": 0}
+	DefaultClass string                     `json:"default_class,omitempty"` // Class of the items without one; none leaves them untaxed
+}
+
+// PricesIncludeTax reports whether prices include tax.
+func (t TaxSettings) PricesIncludeTax() bool {
+	return t.Mode != TaxModeExclusive
+}
+
+// DisplayName returns the name of the tax printed on receipts.
+func (t TaxSettings) DisplayName() string {
+	if t.Name == "" {
+		return DefaultTaxName
+	}
+	return t.Name
+}
+
+// ClassFor returns the tax class of an item of class itemClass ("" for none) and its rate
+// in percent. ok is false if the item is untaxed: it has no class and there is no default
+// class, or its class is not (or no longer) configured.
+func (t TaxSettings) ClassFor(itemClass string) (class string, rate decimal.Decimal, ok bool) {
+	class = itemClass
+	if class == "" {
+		class = t.DefaultClass
+	}
+	rate, ok = t.Classes[class]
+	return class, rate, ok
+}
+
+// TaxOn returns the tax at rate percent on amount, rounded to the currency decimals: the
+// part of amount that is tax if prices include tax, or else the tax to add to amount.
+func (t TaxSettings) TaxOn(amount Money, rate decimal.Decimal) Money {
+	hundred := decimal.NewFromInt(100)
+	if t.PricesIncludeTax() {
+		return NewMoney(amount.Decimal().Mul(rate).Div(hundred.Add(rate))).Round()
+	}
+	return NewMoney(amount.Decimal().Mul(rate).Div(hundred)).Round()
+}
+
+// ParseTaxSettings parses the value of the tax setting.
+func ParseTaxSettings(value string) (TaxSettings, error) {
+	var settings TaxSettings
+	if strings.TrimSpace(value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return TaxSettings{}, fmt.Errorf("invalid tax settings: %w", err)
+	}
+	if settings.Mode == "" {
+		settings.Mode = TaxModeInclusive
+	}
+	if settings.Mode != TaxModeInclusive && settings.Mode != TaxModeExclusive {
+		return TaxSettings{}, fmt.Errorf("tax mode must be %s or %s", TaxModeInclusive, TaxModeExclusive)
+	}
+	for class, rate := range settings.Classes {
+		if strings.TrimSpace(class) == "" || len(class) > 50 {
+			return TaxSettings{}, fmt.Errorf("tax class names must have 1 to 50 characters")
+		}
+		if rate.IsNegative() || rate.GreaterThan(decimal.NewFromInt(100)) {
+			return TaxSettings{}, fmt.Errorf("rate of tax class %s must be between 0 and 100", class)
+		}
+	}
+	if settings.DefaultClass != "" {
+		if _, ok := settings.Classes[settings.DefaultClass]; !ok {
+			return TaxSettings{}, fmt.Errorf("default_class %s is not one of the classes", settings.DefaultClass)
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Time off request statuses.
+const (
+	TimeOffPending   = "pending"
+	TimeOffApproved  = "approved"
+	TimeOffRejected  = "rejected"
+	TimeOffCancelled = "cancelled"
+)
+
+// TimeOffStatuses lists the time off request statuses.
+var TimeOffStatuses = []string{TimeOffPending, TimeOffApproved, TimeOffRejected, TimeOffCancelled}
+
+// TimeOffRequest is a request of a staff member for days off.
+type TimeOffRequest struct {
+	ID           int64      `json:"id"`
+	StaffID      int64      `json:"staff_id"`
+	StaffName    string     `json:"staff_name"`
+	StartDate    string     `json:"start_date"` // YYYY-MM-DD, club date
+	EndDate      string     `json:"end_date"`   // YYYY-MM-DD, inclusive
+	Reason       *string    `json:"reason,omitempty"`
+	Status       string     `json:"status"` // One of TimeOffStatuses
+	RequestedBy  *int64     `json:"requested_by,omitempty"`
+	DecidedBy    *int64     `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	DecisionNote *string    `json:"decision_note,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// ConflictingShifts are the shifts scheduled during a pending or approved request, to be
+	// reassigned; set when a single request is returned.
+	ConflictingShifts []Shift `json:"conflicting_shifts,omitempty"`
+}
+
+// TimeOffFilters selects time off requests.
+type TimeOffFilters struct {
+	StaffID *int64
+	Status  *string
+	From    *string // YYYY-MM-DD; requests ending on or after it
+	To      *string // YYYY-MM-DD; requests starting on or before it
+}
+
+// Availability preferences.
+const (
+	AvailabilityPreferred   = "preferred"
+	AvailabilityUnavailable = "unavailable"
+)
+
+// AvailabilityPreferences lists the availability preferences.
+var AvailabilityPreferences = []string{AvailabilityPreferred, AvailabilityUnavailable}
+
+// AvailabilityWindow is a weekly period a staff member prefers to work or cannot work.
+type AvailabilityWindow struct {
+	Weekday    int    `json:"weekday"`    // 1 Monday to 7 Sunday
+	StartTime  string `json:"start_time"` // HH:MM, club time
+	EndTime    string `json:"end_time"`   // HH:MM, after StartTime
+	Preference string `json:"preference"` // One of AvailabilityPreferences
+}
+
+// StaffDayAvailability is whether a staff member can be scheduled on a date.
+type StaffDayAvailability struct {
+	StaffID    int64                `json:"staff_id"`
+	StaffName  string               `json:"staff_name"`
+	OnTimeOff  bool                 `json:"on_time_off"` // Approved time off covers the date
+	PendingOff bool                 `json:"pending_off"` // A pending request covers the date
+	Windows    []AvailabilityWindow `json:"windows"`     // The preferences for the weekday of the date
+}
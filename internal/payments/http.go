@@ -0,0 +1,116 @@
+// Package payments places and settles card pre-authorizations with a payment provider
+// through its HTTP API. services.TableSessionService calls it for the card deposits of
+// table sessions: a hold when the session starts, a capture or a void when it stops.
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+)
+
+// PreAuthorization is the JSON body posted to create a pre-authorization.
+type PreAuthorization struct {
+	Amount    models.Money `json:"amount"`
+	Currency  string       `json:"currency"`
+	CardToken string       `json:"card_token"` // The card as tokenized by the terminal or the payment page
+	Reference string       `json:"reference"`  // Shown in the provider's dashboard, e.g. "table-session-42"
+}
+
+// Capture is the JSON body posted to capture a pre-authorization.
+type Capture struct {
+	Amount models.Money `json:"amount"`
+}
+
+// HTTPProvider calls the pre-authorization API at URL:
+//
+//	POST {URL}/preauthorizations            PreAuthorization, responds {"id": "..."}
+//	POST {URL}/preauthorizations/{id}/capture  Capture; the rest of the hold is released
+//	POST {URL}/preauthorizations/{id}/void     releases the whole hold
+//
+// A 2xx response means the call succeeded. Calls are not retried: a failed capture is
+// retried by hand, so a charge is never taken twice.
+type HTTPProvider struct {
+	URL    string
+	Token  string // Sent as a bearer token if set
+	Client *http.Client
+}
+
+// NewHTTPProvider creates an HTTPProvider for url.
+func NewHTTPProvider(url, token string) *HTTPProvider {
+	return &HTTPProvider{
+		URL:    strings.TrimRight(url, "/"),
+		Token:  token,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// PreAuthorize places a hold of amount on the card and returns the ID of the pre-authorization.
+func (p *HTTPProvider) PreAuthorize(ctx context.Context, amount models.Money, cardToken, reference string) (string, error) {
+	body := PreAuthorization{Amount: amount, Currency: utils.CurrentCurrency().Code, CardToken: cardToken, Reference: reference}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := p.post(ctx, "/preauthorizations", body, &created); err != nil {
+		return "", fmt.Errorf("pre-authorizing %s: %w", amount, err)
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("pre-authorizing %s: the payment provider returned no ID", amount)
+	}
+	return created.ID, nil
+}
+
+// Capture charges amount of a pre-authorization and releases the rest of the hold.
+func (p *HTTPProvider) Capture(ctx context.Context, authorizationID string, amount models.Money) error {
+	if err := p.post(ctx, "/preauthorizations/"+url.PathEscape(authorizationID)+"/capture", Capture{Amount: amount}, nil); err != nil {
+		return fmt.Errorf("capturing %s of %s: %w", amount, authorizationID, err)
+	}
+	return nil
+}
+
+// Void releases the whole hold of a pre-authorization.
+func (p *HTTPProvider) Void(ctx context.Context, authorizationID string) error {
+	if err := p.post(ctx, "/preauthorizations/"+url.PathEscape(authorizationID)+"/void", struct{}{}, nil); err != nil {
+		return fmt.Errorf("voiding %s: %w", authorizationID, err)
+	}
+	return nil
+}
+
+// post posts body as JSON to path and decodes the response into out unless it is nil.
+func (p *HTTPProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return utils.ResponseError("payment provider", resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding payment provider response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package power switches the TV and console of a game table through a smart plug or a
+// local agent that exposes an HTTP endpoint. services.PowerService calls it when table
+// sessions start and stop, and for manual overrides.
+package power
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ps_club_backend/pkg/utils"
+)
+
+// Defaults of HTTPController.
+const (
+	DefaultRetries    = 2
+	DefaultRetryDelay = time.Second
+)
+
+// Command is the JSON body posted to the power control endpoint.
+type Command struct {
+	DeviceID string `json:"device_id"`
+	State    string `json:"state"` // "on" or "off"
+}
+
+// HTTPController posts each command to URL. A 2xx response means the device was switched.
+// Network errors, 429 and 5xx responses are retried Retries times, waiting RetryDelay
+// and then twice as long before each retry; other responses fail at once.
+type HTTPController struct {
+	URL        string
+	Token      string // Sent as a bearer token if set
+	Client     *http.Client
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// NewHTTPController creates an HTTPController for url with the default retries.
+func NewHTTPController(url, token string) *HTTPController {
+	return &HTTPController{
+		URL:        url,
+		Token:      token,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		Retries:    DefaultRetries,
+		RetryDelay: DefaultRetryDelay,
+	}
+}
+
+// SetPower switches the device on or off.
+func (p *HTTPController) SetPower(ctx context.Context, deviceID string, on bool) error {
+	state := "off"
+	if on {
+		state = "on"
+	}
+	body, err := json.Marshal(Command{DeviceID: deviceID, State: state})
+	if err != nil {
+		return err
+	}
+
+	delay := p.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := p.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.Retries {
+			return fmt.Errorf("switching %s %s: %w", deviceID, state, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("switching %s %s: %w", deviceID, state, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// send posts one command and reports whether a failure may be retried.
+func (p *HTTPController) send(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, utils.ResponseError("power control endpoint", resp)
+}
@@ -0,0 +1,213 @@
+// Package push sends push notifications to the client app through Firebase Cloud Messaging.
+// services.PushNotificationService calls it for booking confirmations, tables ready and
+// loyalty milestones.
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultTokenURL issues the access tokens of service accounts.
+	DefaultTokenURL = "https://oauth2.googleapis.com/token"
+	// DefaultSendURL is the FCM HTTP v1 API; the project ID completes it.
+	DefaultSendURL = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+
+	messagingScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// tokenRefreshMargin is how long before it expires an access token is replaced.
+	tokenRefreshMargin = time.Minute
+)
+
+// serviceAccount is the part of a Google service account key file the sender uses.
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMSender sends push notifications with the FCM HTTP v1 API, authenticated as a service
+// account of the Firebase project:
+//
+//	POST https://fcm.googleapis.com/v1/projects/{ProjectID}/messages:send
+//
+// Access tokens are obtained with a JWT signed by the account's key and reused until shortly
+// before they expire. Sends are not retried.
+type FCMSender struct {
+	ProjectID   string
+	ClientEmail string
+	Key         *rsa.PrivateKey
+	TokenURL    string
+	SendURL     string // With the project ID filled in
+	Client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender creates an FCMSender from the JSON key file of a service account allowed to
+// send messages in its Firebase project.
+func NewFCMSender(credentialsJSON []byte) (*FCMSender, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM service account: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("invalid FCM service account: project_id, client_email and private_key are required")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM service account private key: %w", err)
+	}
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+	return &FCMSender{
+		ProjectID:   account.ProjectID,
+		ClientEmail: account.ClientEmail,
+		Key:         key,
+		TokenURL:    tokenURL,
+		SendURL:     fmt.Sprintf(DefaultSendURL, url.PathEscape(account.ProjectID)),
+		Client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// fcmMessage is the JSON body posted to send a message to one device.
+type fcmMessage struct {
+	Message struct {
+		Token        string            `json:"token"`
+		Notification fcmNotification   `json:"notification"`
+		Data         map[string]string `json:"data,omitempty"`
+	} `json:"message"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// fcmError is the error response of the FCM API.
+type fcmError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send pushes message to the device with token. A token FCM no longer knows returns an error
+// matching services.ErrPushTokenUnregistered.
+func (s *FCMSender) Send(ctx context.Context, token string, message models.PushMessage) error {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return fmt.Errorf("authenticating with FCM: %w", err)
+	}
+	var body fcmMessage
+	body.Message.Token = token
+	body.Message.Notification = fcmNotification{Title: message.Title, Body: message.Body}
+	body.Message.Data = message.Data
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.SendURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg := utils.ReadErrorBody(resp)
+	var fcmErr fcmError
+	if json.Unmarshal(msg, &fcmErr) == nil {
+		for _, detail := range fcmErr.Error.Details {
+			if detail.ErrorCode == "UNREGISTERED" {
+				return fmt.Errorf("%w: %s", services.ErrPushTokenUnregistered, fcmErr.Error.Message)
+			}
+		}
+	}
+	if len(msg) > 0 {
+		return fmt.Errorf("FCM responded %s: %s", resp.Status, msg)
+	}
+	return fmt.Errorf("FCM responded %s", resp.Status)
+}
+
+// token returns an access token for the FCM API, obtaining a new one when the last is about
+// to expire.
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.accessToken != "" && time.Now().Add(tokenRefreshMargin).Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.ClientEmail,
+		"scope": messagingScope,
+		"aud":   s.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.Key)
+	if err != nil {
+		return "", fmt.Errorf("signing token request: %w", err)
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", utils.ResponseError("token endpoint", resp)
+	}
+	var issued struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Seconds
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		return "", fmt.Errorf("decoding token response: %v", err)
+	}
+	if issued.AccessToken == "" {
+		return "", errors.New("the token endpoint returned no access token")
+	}
+	s.accessToken = issued.AccessToken
+	s.expiresAt = now.Add(time.Duration(issued.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
@@ -0,0 +1,83 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccessTokenQueryParam carries the access token of the upgrade request, as browsers cannot
+// set the Authorization header on a WebSocket.
+const AccessTokenQueryParam = "access_token"
+
+// CloseTokenExpired is the close code sent when the access token of a connection expires
+// before the client refreshed it; the client reconnects with a new token.
+const CloseTokenExpired = 4001
+
+// ErrForbidden is returned by an Authenticator for a valid token whose user may not connect.
+var ErrForbidden = errors.New("not allowed to connect to this feed")
+
+// Principal is the user a connection was authenticated as.
+type Principal struct {
+	UserID    int64
+	Role      string
+	ExpiresAt time.Time // Of the access token; the zero time for a token that does not expire
+}
+
+// Authenticator validates an access token and returns its user. The hub calls it during the
+// upgrade and for every token the client sends to refresh its connection.
+type Authenticator func(ctx context.Context, token string) (Principal, error)
+
+// TopicPolicy maps the topics of a hub, the event types, to the roles that receive them.
+// Topics it does not list reach every role admitted to the hub.
+type TopicPolicy map[string][]string
+
+// Allows reports whether role receives the messages of topic. Roles compare case-insensitively,
+// like the role checks of the HTTP routes.
+func (p TopicPolicy) Allows(role, topic string) bool {
+	roles, restricted := p[topic]
+	if !restricted {
+		return true
+	}
+	return hasRole(roles, role)
+}
+
+// hasRole reports whether role is one of roles.
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessToken returns the access token of the upgrade request, from the Authorization header
+// or else AccessTokenQueryParam, and false if there is none.
+func accessToken(r *http.Request) (string, bool) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+			return "", false
+		}
+		return token, true
+	}
+	token := r.URL.Query().Get(AccessTokenQueryParam)
+	return token, token != ""
+}
+
+// clientMessage is a message from the client. The only type is "refresh", which replaces the
+// access token of the connection with AccessToken, of the same user, before the old one expires.
+type clientMessage struct {
+	Type        string `json:"type"`
+	AccessToken string `json:"access_token"`
+}
+
+// controlMessage answers a clientMessage: "refreshed" with the new expiry, or "error".
+type controlMessage struct {
+	Type      string     `json:"type"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
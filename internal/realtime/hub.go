@@ -0,0 +1,311 @@
+// Package realtime pushes messages to browsers over WebSocket. A Hub fans the messages
+// published on its channel of the shared store out to the WebSocket clients connected
+// to this instance, so a message published on any instance reaches every client.
+//
+// Clients authenticate with their access token during the upgrade, and each message goes
+// only to the clients whose role may receive its topic. Before the token expires the client
+// sends {"type": "refresh", "access_token": "..."} with a new one, so the connection outlives
+// it; a connection whose token expires is closed with CloseTokenExpired.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeTimeout = 10 * time.Second
+	pongTimeout  = 60 * time.Second
+	pingInterval = pongTimeout * 9 / 10
+	// clientBuffer is how many messages a client may fall behind before it is disconnected.
+	clientBuffer = 32
+	// readLimit is the largest message a client may send, a refresh with its access token.
+	readLimit = 4096
+)
+
+// HubConfig configures who may connect to a Hub and which of its topics they receive.
+type HubConfig struct {
+	// Authenticate validates the access token of the upgrade and of each refresh; required
+	Authenticate Authenticator
+	// Roles may connect; empty admits every authenticated user
+	Roles []string
+	// Topics restricts topics to some of the roles
+	Topics TopicPolicy
+	// CheckOrigin checks the origin of the upgrade; nil accepts every origin, as clients
+	// authenticate with an access token rather than cookies
+	CheckOrigin func(r *http.Request) bool
+}
+
+// Hub holds the WebSocket clients of one channel.
+type Hub struct {
+	broadcaster  kvstore.Broadcaster
+	channel      string
+	upgrader     websocket.Upgrader
+	authenticate Authenticator
+	roles        []string
+	topics       TopicPolicy
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+}
+
+type client struct {
+	conn      *websocket.Conn
+	send      chan []byte
+	principal Principal      // Guarded by Hub.mu, as a refresh replaces it
+	expiry    chan time.Time // The expiry of the token of a refresh, for writePump
+}
+
+// envelope is a message on the channel of the shared store: the message with its topic.
+type envelope struct {
+	Topic   string          `json:"topic"`
+	Message json.RawMessage `json:"message"`
+}
+
+// NewHub creates a Hub for channel.
+func NewHub(broadcaster kvstore.Broadcaster, channel string, cfg HubConfig) *Hub {
+	checkOrigin := cfg.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = func(*http.Request) bool { return true }
+	}
+	return &Hub{
+		broadcaster:  broadcaster,
+		channel:      channel,
+		upgrader:     websocket.Upgrader{CheckOrigin: checkOrigin},
+		authenticate: cfg.Authenticate,
+		roles:        cfg.Roles,
+		topics:       cfg.Topics,
+		clients:      make(map[*client]struct{}),
+	}
+}
+
+// Publish sends message, which must be JSON, to the clients of every instance that may receive topic.
+func (h *Hub) Publish(ctx context.Context, topic string, message []byte) error {
+	data, err := json.Marshal(envelope{Topic: topic, Message: message})
+	if err != nil {
+		return err
+	}
+	return h.broadcaster.Publish(ctx, h.channel, string(data))
+}
+
+// Run forwards the messages published on the channel to the clients of this instance until ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	for data := range h.broadcaster.Subscribe(ctx, h.channel) {
+		var message envelope
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			utils.LogError(err, "Failed to decode WebSocket message on channel "+h.channel)
+			continue
+		}
+		h.broadcast(message.Topic, message.Message)
+	}
+}
+
+// broadcast queues message for every client that may receive topic; clients that fell too far
+// behind are disconnected.
+func (h *Hub) broadcast(topic string, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if !h.topics.Allows(c.principal.Role, topic) {
+			continue
+		}
+		select {
+		case c.send <- message:
+		default:
+			delete(h.clients, c)
+			close(c.send)
+		}
+	}
+}
+
+// ServeHTTP authenticates the request with its access token, upgrades it to a WebSocket
+// connection and sends it the messages of the channel its role may receive until the client
+// disconnects or its token expires. A missing or invalid token gets 401, a role the hub does
+// not admit 403.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := accessToken(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Authorization header or "+AccessTokenQueryParam+" required")
+		return
+	}
+	principal, err := h.authorize(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			writeError(w, http.StatusForbidden, err.Error())
+		} else {
+			writeError(w, http.StatusUnauthorized, "Invalid or expired token: "+err.Error())
+		}
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with the error
+		utils.LogError(err, "Failed to upgrade WebSocket connection")
+		return
+	}
+	c := &client{conn: conn, send: make(chan []byte, clientBuffer), principal: principal, expiry: make(chan time.Time, 1)}
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	go h.writePump(c, principal.ExpiresAt)
+	h.readPump(c)
+}
+
+// authorize authenticates token and checks that the hub admits its role.
+func (h *Hub) authorize(ctx context.Context, token string) (Principal, error) {
+	if h.authenticate == nil {
+		return Principal{}, errors.New("the feed has no authentication configured")
+	}
+	principal, err := h.authenticate(ctx, token)
+	if err != nil {
+		return Principal{}, err
+	}
+	if len(h.roles) > 0 && !hasRole(h.roles, principal.Role) {
+		return Principal{}, ErrForbidden
+	}
+	return principal, nil
+}
+
+// refresh replaces the principal of c with that of token, which must be of the same user, and
+// answers with a control message.
+func (h *Hub) refresh(c *client, token string) {
+	principal, err := h.authorize(context.Background(), token)
+	h.mu.Lock()
+	current := c.principal
+	h.mu.Unlock()
+	if err == nil && principal.UserID != current.UserID {
+		err = errors.New("the token is of another user")
+	}
+	if err != nil {
+		h.reply(c, controlMessage{Type: "error", Error: "refresh rejected: " + err.Error()})
+		return
+	}
+
+	h.mu.Lock()
+	c.principal = principal
+	h.mu.Unlock()
+	select { // Only the latest expiry matters
+	case <-c.expiry:
+	default:
+	}
+	c.expiry <- principal.ExpiresAt
+	reply := controlMessage{Type: "refreshed"}
+	if !principal.ExpiresAt.IsZero() {
+		reply.ExpiresAt = &principal.ExpiresAt
+	}
+	h.reply(c, reply)
+}
+
+// reply queues a control message for c, unless it was disconnected or is too far behind.
+func (h *Hub) reply(c *client, message controlMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+	}
+}
+
+// writeError responds to a request that was not upgraded, like the JSON errors of the API.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// readPump reads until the connection fails, answering pings, keeping the read deadline alive
+// with pongs and handling the refreshes of the client, then unregisters the client.
+func (h *Hub) readPump(c *client) {
+	defer func() {
+		h.mu.Lock()
+		if _, ok := h.clients[c]; ok {
+			delete(h.clients, c)
+			close(c.send)
+		}
+		h.mu.Unlock()
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(readLimit)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var message clientMessage
+		if err := json.Unmarshal(data, &message); err != nil || message.Type != "refresh" {
+			h.reply(c, controlMessage{Type: "error", Error: `unknown message, expected {"type": "refresh", "access_token": "..."}`})
+			continue
+		}
+		h.refresh(c, message.AccessToken)
+	}
+}
+
+// writePump writes the queued messages and pings to the client until its queue is closed, or
+// closes the connection when its access token, expiring at expiresAt, expires unrefreshed.
+func (h *Hub) writePump(c *client, expiresAt time.Time) {
+	ticker := time.NewTicker(pingInterval)
+	var expiry *time.Timer
+	var expired <-chan time.Time
+	setExpiry := func(at time.Time) {
+		if expiry != nil {
+			expiry.Stop()
+		}
+		expiry, expired = nil, nil
+		if !at.IsZero() {
+			expiry = time.NewTimer(time.Until(at))
+			expired = expiry.C
+		}
+	}
+	setExpiry(expiresAt)
+	defer func() {
+		ticker.Stop()
+		setExpiry(time.Time{})
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case at := <-c.expiry:
+			setExpiry(at)
+		case <-expired:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(CloseTokenExpired, "access token expired"))
+			return
+		case message, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// AnomalyRepository defines the database operations for the anomaly checks of the business
+// days. Dates are club dates (YYYY-MM-DD).
+type AnomalyRepository interface {
+	// GetDailyMetrics returns the risk metrics of each day from fromDate to toDate (inclusive)
+	// of a branch in order, zero for days without records. statuses are those of the orders
+	// counted as sales; the orders deleted are counted from the audit log entries of the
+	// deleteActions, e.g. "DELETE /api/v1/orders/:id".
+	GetDailyMetrics(branchCode string, statuses []string, deleteActions []string, fromDate, toDate string) ([]models.RiskMetrics, error)
+	// HasCheck reports whether a day of a branch was checked.
+	HasCheck(branchCode, businessDate string) (bool, error)
+	// CreateCheck inserts the check of a day; false if the day was checked already, e.g. by
+	// another instance.
+	CreateCheck(executor SQLExecutor, check *models.AnomalyCheck) (bool, error)
+	// GetChecks lists the checks of a branch from fromDate to toDate (either may be empty),
+	// newest first; with onlyAnomalies only those that found any.
+	GetChecks(branchCode, fromDate, toDate string, onlyAnomalies bool) ([]models.AnomalyCheck, error)
+}
+
+type anomalyRepository struct {
+	db *sql.DB
+}
+
+// NewAnomalyRepository creates a new instance of AnomalyRepository.
+func NewAnomalyRepository(db *sql.DB) AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+func (r *anomalyRepository) GetDailyMetrics(branchCode string, statuses []string, deleteActions []string, fromDate, toDate string) ([]models.RiskMetrics, error) {
+	from, err := utils.ParseClubDate(fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", fromDate, err)
+	}
+	lastDay, err := utils.ParseClubDate(toDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", toDate, err)
+	}
+	_, to := utils.DayBounds(lastDay)
+
+	rows, err := r.db.Query(`
+		WITH days AS (
+			SELECT d::date AS day FROM generate_series($2::date, $3::date, INTERVAL '1 day') d
+		), sales AS (
+			SELECT (order_time AT TIME ZONE $1)::date AS day,
+			       SUM(final_amount) FILTER (WHERE status = ANY($6)) AS sales,
+			       SUM(COALESCE(discount_amount, 0)) FILTER (WHERE status = ANY($6)) AS discounts,
+			       COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled
+			FROM (SELECT final_amount, discount_amount, status, branch_code, order_time, business_date FROM orders
+			      UNION ALL
+			      SELECT final_amount, discount_amount, status, branch_code, order_time, business_date FROM orders_archive) o
+			WHERE branch_code = $7 AND order_time >= $4 AND order_time < $5 AND business_date BETWEEN $9 AND $10
+			GROUP BY 1
+		), sessions AS (
+			SELECT (stopped_at AT TIME ZONE $1)::date AS day, SUM(total_amount) AS total
+			FROM table_sessions
+			WHERE status = $11 AND stopped_at >= $4 AND stopped_at < $5
+			GROUP BY 1
+		), deletions AS (
+			SELECT (created_at AT TIME ZONE $1)::date AS day, COUNT(*) AS deleted
+			FROM audit_log
+			WHERE action = ANY($8) AND status_code < 300 AND created_at >= $4 AND created_at < $5
+			GROUP BY 1
+		)
+		SELECT TO_CHAR(days.day, 'YYYY-MM-DD'), COALESCE(sales.sales, 0) + COALESCE(sessions.total, 0),
+		       COALESCE(sales.discounts, 0), COALESCE(sales.cancelled, 0), COALESCE(deletions.deleted, 0)
+		FROM days
+		LEFT JOIN sales ON sales.day = days.day
+		LEFT JOIN sessions ON sessions.day = days.day
+		LEFT JOIN deletions ON deletions.day = days.day
+		ORDER BY days.day`,
+		utils.ClubLocation().String(), fromDate, toDate, from, to, pq.Array(statuses), branchCode, pq.Array(deleteActions),
+		businessDateFrom(from), businessDateTo(to), models.TableSessionStatusStopped)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting daily risk metrics: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	days := []models.RiskMetrics{}
+	for rows.Next() {
+		var day models.RiskMetrics
+		if err := rows.Scan(&day.BusinessDate, &day.Revenue, &day.Discounts, &day.CancelledCount, &day.DeletedCount); err != nil {
+			return nil, fmt.Errorf("%w: scanning daily risk metrics: %v", ErrDatabaseError, err)
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating daily risk metrics: %v", ErrDatabaseError, err)
+	}
+	return days, nil
+}
+
+func (r *anomalyRepository) HasCheck(branchCode, businessDate string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM anomaly_checks WHERE branch_code = $1 AND business_date = $2)`,
+		branchCode, businessDate,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%w: looking up anomaly check of %s: %v", ErrDatabaseError, businessDate, err)
+	}
+	return exists, nil
+}
+
+func (r *anomalyRepository) CreateCheck(executor SQLExecutor, check *models.AnomalyCheck) (bool, error) {
+	metrics, err := json.Marshal(check.Metrics)
+	if err != nil {
+		return false, fmt.Errorf("encoding anomaly check metrics: %w", err)
+	}
+	var averages []byte
+	if check.Averages != nil {
+		if averages, err = json.Marshal(check.Averages); err != nil {
+			return false, fmt.Errorf("encoding anomaly check averages: %w", err)
+		}
+	}
+	if check.Anomalies == nil {
+		check.Anomalies = []models.Anomaly{}
+	}
+	anomalies, err := json.Marshal(check.Anomalies)
+	if err != nil {
+		return false, fmt.Errorf("encoding anomalies: %w", err)
+	}
+	err = executor.QueryRow(`INSERT INTO anomaly_checks (branch_code, business_date, metrics, averages, anomalies, checked_at)
+	                         VALUES ($1, $2, $3, $4, $5, $6)
+	                         ON CONFLICT (branch_code, business_date) DO NOTHING
+	                         RETURNING id`,
+		check.BranchCode, check.BusinessDate, metrics, averages, anomalies, check.CheckedAt,
+	).Scan(&check.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: creating anomaly check of %s: %v", ErrDatabaseError, check.BusinessDate, err)
+	}
+	return true, nil
+}
+
+func (r *anomalyRepository) GetChecks(branchCode, fromDate, toDate string, onlyAnomalies bool) ([]models.AnomalyCheck, error) {
+	rows, err := r.db.Query(`SELECT id, branch_code, TO_CHAR(business_date, 'YYYY-MM-DD'), metrics, averages, anomalies, checked_at
+	                         FROM anomaly_checks
+	                         WHERE branch_code = $1
+	                           AND ($2 = '' OR business_date >= $2::date) AND ($3 = '' OR business_date <= $3::date)
+	                           AND (NOT $4 OR anomalies <> '[]'::jsonb)
+	                         ORDER BY business_date DESC`,
+		branchCode, fromDate, toDate, onlyAnomalies)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing anomaly checks: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	checks := []models.AnomalyCheck{}
+	for rows.Next() {
+		var check models.AnomalyCheck
+		var metrics, averages, anomalies []byte
+		if err := rows.Scan(&check.ID, &check.BranchCode, &check.BusinessDate, &metrics, &averages, &anomalies, &check.CheckedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning anomaly check: %v", ErrDatabaseError, err)
+		}
+		if err := json.Unmarshal(metrics, &check.Metrics); err != nil {
+			return nil, fmt.Errorf("decoding metrics of anomaly check ID %d: %v", check.ID, err)
+		}
+		if averages != nil {
+			if err := json.Unmarshal(averages, &check.Averages); err != nil {
+				return nil, fmt.Errorf("decoding averages of anomaly check ID %d: %v", check.ID, err)
+			}
+		}
+		if err := json.Unmarshal(anomalies, &check.Anomalies); err != nil {
+			return nil, fmt.Errorf("decoding anomalies of anomaly check ID %d: %v", check.ID, err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating anomaly checks: %v", ErrDatabaseError, err)
+	}
+	return checks, nil
+}
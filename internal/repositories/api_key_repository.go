@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// APIKeyRepository defines the database operations for API keys.
+type APIKeyRepository interface {
+	CreateAPIKey(key *models.APIKey) (*models.APIKey, error)
+	GetAPIKeys() ([]models.APIKey, error)
+	// GetActiveAPIKeyByHash returns the unrevoked key with the hash; ErrNotFound if there is none.
+	GetActiveAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	// RevokeAPIKey revokes an active key; ErrNotFound if there is none.
+	RevokeAPIKey(id int64, now time.Time) error
+	TouchAPIKey(id int64, now time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository.
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, key_hash, scopes, created_by, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row scanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes pq.StringArray
+	err := row.Scan(&key.ID, &key.Name, &key.KeyHash, &scopes, &key.CreatedBy, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = []string(scopes)
+	return &key, nil
+}
+
+func (r *apiKeyRepository) CreateAPIKey(key *models.APIKey) (*models.APIKey, error) {
+	query := `INSERT INTO api_keys (name, key_hash, scopes, created_by, created_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING ` + apiKeyColumns
+	created, err := scanAPIKey(r.db.QueryRow(query, key.Name, key.KeyHash, pq.Array(key.Scopes), key.CreatedBy, time.Now().UTC()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating API key: %v", ErrDatabaseError, err)
+	}
+	return created, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeys() ([]models.APIKey, error) {
+	rows, err := r.db.Query(`SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing API keys: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning API key: %v", ErrDatabaseError, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating API keys: %v", ErrDatabaseError, err)
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetActiveAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting API key: %v", ErrDatabaseError, err)
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(id int64, now time.Time) error {
+	result, err := r.db.Exec(`UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, now)
+	if err != nil {
+		return fmt.Errorf("%w: revoking API key %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for API key %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(id int64, now time.Time) error {
+	if _, err := r.db.Exec(`UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, now); err != nil {
+		return fmt.Errorf("%w: updating last use of API key %d: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// ApprovalRepository defines the database operations for approvals of sensitive actions.
+type ApprovalRepository interface {
+	CreateApproval(executor SQLExecutor, approval *models.Approval) (*models.Approval, error)
+	GetApprovalByID(id int64) (*models.Approval, error)
+	GetApprovals(status *string, page, pageSize int) ([]models.Approval, int, error)
+	// DecideApproval moves a pending approval to status. It returns ErrNotFound if the
+	// approval does not exist or was already decided, so only one decision wins.
+	DecideApproval(executor SQLExecutor, id int64, status string, decidedBy int64, reason *string, decidedAt time.Time) error
+	// SetApprovalResult records the outcome of executing an approved action.
+	SetApprovalResult(executor SQLExecutor, id int64, status string, targetID *int64, resultError *string) error
+}
+
+type approvalRepository struct {
+	db *sql.DB
+}
+
+// NewApprovalRepository creates a new instance of ApprovalRepository.
+func NewApprovalRepository(db *sql.DB) ApprovalRepository {
+	return &approvalRepository{db: db}
+}
+
+const approvalColumns = `id, action, target_id, payload, status, requested_by, decided_by, decided_at, reason, result_error, created_at, updated_at`
+
+func scanApproval(row scanner) (*models.Approval, error) {
+	var approval models.Approval
+	var targetID, requestedBy, decidedBy sql.NullInt64
+	var decidedAt sql.NullTime
+	var reason, resultError sql.NullString
+	var payload []byte
+	err := row.Scan(
+		&approval.ID, &approval.Action, &targetID, &payload, &approval.Status, &requestedBy, &decidedBy,
+		&decidedAt, &reason, &resultError, &approval.CreatedAt, &approval.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	approval.Payload = payload
+	if targetID.Valid {
+		approval.TargetID = &targetID.Int64
+	}
+	if requestedBy.Valid {
+		approval.RequestedBy = &requestedBy.Int64
+	}
+	if decidedBy.Valid {
+		approval.DecidedBy = &decidedBy.Int64
+	}
+	if decidedAt.Valid {
+		approval.DecidedAt = &decidedAt.Time
+	}
+	if reason.Valid {
+		approval.Reason = &reason.String
+	}
+	if resultError.Valid {
+		approval.ResultError = &resultError.String
+	}
+	return &approval, nil
+}
+
+func (r *approvalRepository) CreateApproval(executor SQLExecutor, approval *models.Approval) (*models.Approval, error) {
+	query := `INSERT INTO approvals (action, target_id, payload, status, requested_by, decided_by, decided_at, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	          RETURNING ` + approvalColumns
+	created, err := scanApproval(executor.QueryRow(query,
+		approval.Action, approval.TargetID, []byte(approval.Payload), approval.Status,
+		approval.RequestedBy, approval.DecidedBy, approval.DecidedAt, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating approval: %v", ErrDatabaseError, err)
+	}
+	return created, nil
+}
+
+func (r *approvalRepository) GetApprovalByID(id int64) (*models.Approval, error) {
+	query := `SELECT ` + approvalColumns + ` FROM approvals WHERE id = $1`
+	approval, err := scanApproval(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting approval by ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return approval, nil
+}
+
+func (r *approvalRepository) GetApprovals(status *string, page, pageSize int) ([]models.Approval, int, error) {
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM approvals WHERE ($1::text IS NULL OR status = $1)`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: counting approvals: %v", ErrDatabaseError, err)
+	}
+
+	query := `SELECT ` + approvalColumns + ` FROM approvals
+	          WHERE ($1::text IS NULL OR status = $1)
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(query, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: getting approvals: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	approvals := []models.Approval{}
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: scanning approval: %v", ErrDatabaseError, err)
+		}
+		approvals = append(approvals, *approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: iterating approvals: %v", ErrDatabaseError, err)
+	}
+	return approvals, total, nil
+}
+
+func (r *approvalRepository) DecideApproval(executor SQLExecutor, id int64, status string, decidedBy int64, reason *string, decidedAt time.Time) error {
+	query := `UPDATE approvals SET status = $2, decided_by = $3, reason = $4, decided_at = $5, updated_at = $5
+	          WHERE id = $1 AND status = 'pending'`
+	result, err := executor.Exec(query, id, status, decidedBy, reason, decidedAt)
+	if err != nil {
+		return fmt.Errorf("%w: deciding approval %d: %v", ErrDatabaseError, id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for approval %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *approvalRepository) SetApprovalResult(executor SQLExecutor, id int64, status string, targetID *int64, resultError *string) error {
+	query := `UPDATE approvals SET status = $2, target_id = COALESCE($3, target_id), result_error = $4, updated_at = $5
+	          WHERE id = $1`
+	_, err := executor.Exec(query, id, status, targetID, resultError, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting result of approval %d: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+)
+
+// AttendanceRepository defines the database reads comparing the shifts with the time clock.
+type AttendanceRepository interface {
+	// GetShifts lists the shifts starting in [from, to), of staffID if set, by start time, with
+	// whether approved time off covers the club day they start on.
+	GetShifts(from, to time.Time, staffID *int64) ([]models.AttendanceShift, error)
+	// GetTimeClockEntries lists the time clock entries overlapping [from, to), of staffID if set,
+	// by clock-in time. Open entries overlap up to now.
+	GetTimeClockEntries(from, to time.Time, staffID *int64) ([]models.TimeClockEntry, error)
+}
+
+type attendanceRepository struct {
+	db *sql.DB
+}
+
+// NewAttendanceRepository creates a new instance of AttendanceRepository.
+func NewAttendanceRepository(db *sql.DB) AttendanceRepository {
+	return &attendanceRepository{db: db}
+}
+
+func (r *attendanceRepository) GetShifts(from, to time.Time, staffID *int64) ([]models.AttendanceShift, error) {
+	rows, err := r.db.Query(`SELECT sh.id, sh.staff_id, `+staffNameColumn+`, sh.start_time, sh.end_time, sh.notes,
+	                                sh.created_at, sh.updated_at,
+	                                EXISTS (SELECT 1 FROM staff_time_off t
+	                                        WHERE t.staff_id = sh.staff_id AND t.status = $4
+	                                          AND (sh.start_time AT TIME ZONE $5)::date BETWEEN t.start_date AND t.end_date)
+	                         FROM shifts sh
+	                         JOIN staff_members s ON s.id = sh.staff_id
+	                         LEFT JOIN users u ON u.id = s.user_id
+	                         WHERE sh.start_time >= $1 AND sh.start_time < $2 AND ($3::BIGINT IS NULL OR sh.staff_id = $3)
+	                         ORDER BY sh.start_time, sh.id`,
+		from, to, staffID, models.TimeOffApproved, utils.ClubLocation().String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing shifts for attendance: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	shifts := []models.AttendanceShift{}
+	for rows.Next() {
+		var shift models.AttendanceShift
+		if err := rows.Scan(&shift.ID, &shift.StaffID, &shift.StaffName, &shift.StartTime, &shift.EndTime, &shift.Notes,
+			&shift.CreatedAt, &shift.UpdatedAt, &shift.OnTimeOff); err != nil {
+			return nil, fmt.Errorf("%w: scanning shift: %v", ErrDatabaseError, err)
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating shifts: %v", ErrDatabaseError, err)
+	}
+	return shifts, nil
+}
+
+func (r *attendanceRepository) GetTimeClockEntries(from, to time.Time, staffID *int64) ([]models.TimeClockEntry, error) {
+	rows, err := r.db.Query(`SELECT id, staff_id, clock_in_at, clock_out_at, created_at, updated_at
+	                         FROM time_clock_entries
+	                         WHERE clock_in_at < $2 AND COALESCE(clock_out_at, NOW()) > $1
+	                           AND ($3::BIGINT IS NULL OR staff_id = $3)
+	                         ORDER BY clock_in_at, id`, from, to, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing time clock entries: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	entries := []models.TimeClockEntry{}
+	for rows.Next() {
+		var entry models.TimeClockEntry
+		if err := rows.Scan(&entry.ID, &entry.StaffID, &entry.ClockInAt, &entry.ClockOutAt, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning time clock entry: %v", ErrDatabaseError, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating time clock entries: %v", ErrDatabaseError, err)
+	}
+	return entries, nil
+}
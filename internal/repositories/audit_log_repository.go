@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"ps_club_backend/internal/models"
+)
+
+// AuditLogRepository defines the database operations for the audit log.
+type AuditLogRepository interface {
+	CreateEntry(executor SQLExecutor, entry *models.AuditLogEntry) error
+	// GetEntries lists the entries matching filters, newest first, with their total count.
+	GetEntries(filters models.AuditLogFilters) ([]models.AuditLogEntry, int, error)
+}
+
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository creates a new instance of AuditLogRepository.
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+const auditLogColumns = `id, user_id, impersonator_id, action, method, path, status_code, ip_address, details, created_at`
+
+func scanAuditLogEntry(row scanner) (*models.AuditLogEntry, error) {
+	var entry models.AuditLogEntry
+	err := row.Scan(&entry.ID, &entry.UserID, &entry.ImpersonatorID, &entry.Action, &entry.Method, &entry.Path,
+		&entry.StatusCode, &entry.IPAddress, &entry.Details, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *auditLogRepository) CreateEntry(executor SQLExecutor, entry *models.AuditLogEntry) error {
+	err := executor.QueryRow(`INSERT INTO audit_log (user_id, impersonator_id, action, method, path, status_code, ip_address, details, created_at)
+	                          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	                          RETURNING id`,
+		entry.UserID, entry.ImpersonatorID, entry.Action, entry.Method, entry.Path, entry.StatusCode, entry.IPAddress,
+		entry.Details, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("%w: creating audit log entry: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *auditLogRepository) GetEntries(filters models.AuditLogFilters) ([]models.AuditLogEntry, int, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	argCounter := 1
+
+	if filters.UserID != nil {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argCounter))
+		args = append(args, *filters.UserID)
+		argCounter++
+	}
+	if filters.ImpersonatorID != nil {
+		conditions = append(conditions, fmt.Sprintf("impersonator_id = $%d", argCounter))
+		args = append(args, *filters.ImpersonatorID)
+		argCounter++
+	}
+	if filters.Impersonated != nil {
+		if *filters.Impersonated {
+			conditions = append(conditions, "impersonator_id IS NOT NULL")
+		} else {
+			conditions = append(conditions, "impersonator_id IS NULL")
+		}
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argCounter))
+		args = append(args, *filters.To)
+		argCounter++
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: counting audit log entries: %v", ErrDatabaseError, err)
+	}
+
+	query := fmt.Sprintf(`SELECT `+auditLogColumns+` FROM audit_log WHERE `+where+`
+	                      ORDER BY created_at DESC, id DESC
+	                      LIMIT $%d OFFSET $%d`, argCounter, argCounter+1)
+	args = append(args, filters.PageSize, (filters.Page-1)*filters.PageSize)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: listing audit log entries: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLogEntry{}
+	for rows.Next() {
+		entry, err := scanAuditLogEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: scanning audit log entry: %v", ErrDatabaseError, err)
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: iterating audit log entries: %v", ErrDatabaseError, err)
+	}
+	return entries, total, nil
+}
@@ -15,6 +15,20 @@ type AuthRepository interface {
 	CreateUser(executor SQLExecutor, user *models.User, hashedPassword string) (int64, error)
 	FindUserByUsername(username string) (*models.User, string, error) // Returns User, HashedPassword, Error
 	FindUserByID(userID int64) (*models.User, error)
+	SetApprovalPIN(userID int64, pinHash string) error
+	SetPreferredLanguage(userID int64, language *string) error // nil clears the preference
+	// UpdateProfile replaces the full name, email and phone number of a user, and the
+	// password hash if it is set; ErrDuplicateKey if the email is taken.
+	UpdateProfile(userID int64, profile models.UserProfile) error
+	// SetAvatar replaces the avatar of a user, or removes it if avatar is nil.
+	SetAvatar(userID int64, avatar *models.Photo) error
+	// GetAvatar returns the avatar of a user; ErrNotFound if the user or their avatar does not exist.
+	GetAvatar(userID int64) (*models.Photo, error)
+	// GetAdminApprovalPIN returns the approval PIN hash of an active Admin; ErrNotFound if the user is not one or has not set a PIN.
+	GetAdminApprovalPIN(userID int64) (*models.ApprovalPIN, error)
+	GetAdminEmails() ([]string, error) // Email addresses of active Admins that have one
+	// GetRolePermissions returns the names of the permissions granted to a role (by name, any case).
+	GetRolePermissions(roleName string) ([]string, error)
 	// TODO: Add methods for refresh token management
 }
 
@@ -33,11 +47,11 @@ func NewAuthRepository(db *sql.DB) AuthRepository {
 // The user model should have Username. Other fields like Email, FullName, RoleID are optional.
 // IsActive is set to true by default. CreatedAt and UpdatedAt are set to the current time.
 func (r *authRepository) CreateUser(executor SQLExecutor, user *models.User, hashedPassword string) (int64, error) {
-	query := `INSERT INTO users (username, password_hash, email, full_name, role_id, is_active, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	query := `INSERT INTO users (username, password_hash, email, full_name, phone_number, role_id, is_active, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	          RETURNING id`
 	
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	isActive := true // Default to true for new users
 
 	// Ensure RoleID is handled correctly if it's optional and might be nil on user model
@@ -51,9 +65,10 @@ func (r *authRepository) CreateUser(executor SQLExecutor, user *models.User, has
 		query,
 		user.Username,
 		hashedPassword,
-		user.Email,    // Can be nil
-		user.FullName, // Can be nil
-		roleID,        // Use sql.NullInt64 for nullable foreign keys
+		user.Email,       // Can be nil
+		user.FullName,    // Can be nil
+		user.PhoneNumber, // Can be nil
+		roleID,           // Use sql.NullInt64 for nullable foreign keys
 		isActive,
 		currentTime,
 		currentTime,
@@ -63,9 +78,8 @@ func (r *authRepository) CreateUser(executor SQLExecutor, user *models.User, has
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code.Name() == "unique_violation" {
-				// The specific column causing the violation can be checked via pqErr.Constraint.
-				// This detail might be useful for the service layer to return a more specific error.
-				return 0, fmt.Errorf("%w: %s (constraint: %s)", ErrDuplicateKey, pqErr.Message, pqErr.Constraint)
+				// The constraint tells the taken username from the taken email, see ViolatedConstraint
+				return 0, &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: pqErr.Message}
 			}
 		}
 		return 0, fmt.Errorf("%w: creating user: %v", ErrDatabaseError, err)
@@ -81,7 +95,8 @@ func (r *authRepository) FindUserByUsername(username string) (*models.User, stri
 	// Query to fetch user details along with role name
 	// Assumes 'roles' table exists and is joinable via users.role_id = roles.id
 	query := `
-		SELECT u.id, u.username, u.password_hash, u.email, u.full_name, u.role_id, u.is_active, u.created_at, u.updated_at,
+		SELECT u.id, u.username, u.password_hash, u.email, u.full_name, u.role_id, u.is_active, u.preferred_language, u.phone_number,
+		       u.avatar IS NOT NULL, u.created_at, u.updated_at,
 		       COALESCE(ro.name, '') as role_name 
 		FROM users u
 		LEFT JOIN roles ro ON u.role_id = ro.id
@@ -92,7 +107,7 @@ func (r *authRepository) FindUserByUsername(username string) (*models.User, stri
 
 	err := r.db.QueryRow(query, username).Scan(
 		&user.ID, &user.Username, &hashedPassword, &user.Email, &user.FullName,
-		&roleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&roleID, &user.IsActive, &user.PreferredLanguage, &user.PhoneNumber, &user.HasAvatar, &user.CreatedAt, &user.UpdatedAt,
 		&roleName,
 	)
 
@@ -121,7 +136,8 @@ func (r *authRepository) FindUserByID(userID int64) (*models.User, error) {
 	user := &models.User{}
 	// Query to fetch user details along with role name
 	query := `
-		SELECT u.id, u.username, u.password_hash, u.email, u.full_name, u.role_id, u.is_active, u.created_at, u.updated_at,
+		SELECT u.id, u.username, u.password_hash, u.email, u.full_name, u.role_id, u.is_active, u.preferred_language, u.phone_number,
+		       u.avatar IS NOT NULL, u.created_at, u.updated_at,
 		       COALESCE(ro.name, '') as role_name
 		FROM users u
 		LEFT JOIN roles ro ON u.role_id = ro.id
@@ -133,7 +149,7 @@ func (r *authRepository) FindUserByID(userID int64) (*models.User, error) {
 
 	err := r.db.QueryRow(query, userID).Scan(
 		&user.ID, &user.Username, &passwordHash, &user.Email, &user.FullName,
-		&roleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
+		&roleID, &user.IsActive, &user.PreferredLanguage, &user.PhoneNumber, &user.HasAvatar, &user.CreatedAt, &user.UpdatedAt,
 		&roleName,
 	)
 
@@ -157,3 +173,164 @@ func (r *authRepository) FindUserByID(userID int64) (*models.User, error) {
 
 	return user, nil
 }
+
+// SetApprovalPIN stores the hash of the PIN an Admin uses to authorize sensitive actions.
+func (r *authRepository) SetApprovalPIN(userID int64, pinHash string) error {
+	result, err := r.db.Exec(`UPDATE users SET approval_pin_hash = $2, updated_at = $3 WHERE id = $1`, userID, pinHash, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting approval PIN of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for user %d: %v", ErrDatabaseError, userID, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetPreferredLanguage stores the language of API messages for a user.
+func (r *authRepository) SetPreferredLanguage(userID int64, language *string) error {
+	result, err := r.db.Exec(`UPDATE users SET preferred_language = $2, updated_at = $3 WHERE id = $1`, userID, language, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting preferred language of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for user %d: %v", ErrDatabaseError, userID, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *authRepository) UpdateProfile(userID int64, profile models.UserProfile) error {
+	result, err := r.db.Exec(`UPDATE users
+	                          SET full_name = $2, email = $3, phone_number = $4,
+	                              password_hash = COALESCE($5, password_hash), updated_at = $6
+	                          WHERE id = $1`,
+		userID, profile.FullName, profile.Email, profile.PhoneNumber, profile.PasswordHash, time.Now().UTC())
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: pqErr.Message}
+		}
+		return fmt.Errorf("%w: updating profile of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for user %d: %v", ErrDatabaseError, userID, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *authRepository) SetAvatar(userID int64, avatar *models.Photo) error {
+	var data []byte
+	var contentType *string
+	if avatar != nil {
+		data = avatar.Data
+		contentType = &avatar.ContentType
+	}
+	result, err := r.db.Exec(`UPDATE users SET avatar = $2, avatar_content_type = $3, updated_at = $4 WHERE id = $1`,
+		userID, data, contentType, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting avatar of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for user %d: %v", ErrDatabaseError, userID, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *authRepository) GetAvatar(userID int64) (*models.Photo, error) {
+	var avatar models.Photo
+	err := r.db.QueryRow(`SELECT avatar_content_type, avatar FROM users WHERE id = $1 AND avatar IS NOT NULL`, userID).
+		Scan(&avatar.ContentType, &avatar.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting avatar of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	return &avatar, nil
+}
+
+func (r *authRepository) GetAdminApprovalPIN(userID int64) (*models.ApprovalPIN, error) {
+	query := `
+		SELECT u.id, u.approval_pin_hash
+		FROM users u
+		JOIN roles ro ON u.role_id = ro.id
+		WHERE u.id = $1 AND ro.name = 'Admin' AND u.is_active AND u.approval_pin_hash IS NOT NULL`
+	var pin models.ApprovalPIN
+	if err := r.db.QueryRow(query, userID).Scan(&pin.UserID, &pin.PINHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting approval PIN of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	return &pin, nil
+}
+
+func (r *authRepository) GetAdminEmails() ([]string, error) {
+	query := `
+		SELECT u.email
+		FROM users u
+		JOIN roles ro ON u.role_id = ro.id
+		WHERE ro.name = 'Admin' AND u.is_active AND COALESCE(u.email, '') <> ''
+		ORDER BY u.id`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting admin emails: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	emails := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("%w: scanning admin email: %v", ErrDatabaseError, err)
+		}
+		emails = append(emails, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating admin emails: %v", ErrDatabaseError, err)
+	}
+	return emails, nil
+}
+
+func (r *authRepository) GetRolePermissions(roleName string) ([]string, error) {
+	query := `
+		SELECT p.name
+		FROM role_permissions rp
+		JOIN roles ro ON rp.role_id = ro.id
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE LOWER(ro.name) = LOWER($1)
+		ORDER BY p.name`
+	rows, err := r.db.Query(query, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting permissions of role %s: %v", ErrDatabaseError, roleName, err)
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("%w: scanning role permission: %v", ErrDatabaseError, err)
+		}
+		permissions = append(permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating role permissions: %v", ErrDatabaseError, err)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// BackupRepository defines the database operations for database backups.
+type BackupRepository interface {
+	CreateBackup(executor SQLExecutor, backup *models.Backup) (*models.Backup, error)
+	GetBackupByID(id int64) (*models.Backup, error)
+	GetBackups(limit int) ([]models.Backup, error) // Most recent first
+	// FinishBackup records the outcome of a running backup.
+	FinishBackup(executor SQLExecutor, id int64, status string, location *string, sizeBytes *int64, errMsg *string, finishedAt time.Time) error
+}
+
+type backupRepository struct {
+	db *sql.DB
+}
+
+// NewBackupRepository creates a new instance of BackupRepository.
+func NewBackupRepository(db *sql.DB) BackupRepository {
+	return &backupRepository{db: db}
+}
+
+const backupColumns = `id, status, trigger, destination, location, size_bytes, error, requested_by, started_at, finished_at`
+
+func scanBackup(row scanner) (*models.Backup, error) {
+	var backup models.Backup
+	var location, errMsg sql.NullString
+	var sizeBytes, requestedBy sql.NullInt64
+	var finishedAt sql.NullTime
+	err := row.Scan(
+		&backup.ID, &backup.Status, &backup.Trigger, &backup.Destination, &location, &sizeBytes,
+		&errMsg, &requestedBy, &backup.StartedAt, &finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if location.Valid {
+		backup.Location = &location.String
+	}
+	if sizeBytes.Valid {
+		backup.SizeBytes = &sizeBytes.Int64
+	}
+	if errMsg.Valid {
+		backup.Error = &errMsg.String
+	}
+	if requestedBy.Valid {
+		backup.RequestedBy = &requestedBy.Int64
+	}
+	if finishedAt.Valid {
+		backup.FinishedAt = &finishedAt.Time
+	}
+	return &backup, nil
+}
+
+func (r *backupRepository) CreateBackup(executor SQLExecutor, backup *models.Backup) (*models.Backup, error) {
+	query := `INSERT INTO backups (status, trigger, destination, requested_by, started_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING ` + backupColumns
+	created, err := scanBackup(executor.QueryRow(query,
+		backup.Status, backup.Trigger, backup.Destination, backup.RequestedBy, time.Now().UTC(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating backup: %v", ErrDatabaseError, err)
+	}
+	return created, nil
+}
+
+func (r *backupRepository) GetBackupByID(id int64) (*models.Backup, error) {
+	backup, err := scanBackup(r.db.QueryRow(`SELECT `+backupColumns+` FROM backups WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting backup ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return backup, nil
+}
+
+func (r *backupRepository) GetBackups(limit int) ([]models.Backup, error) {
+	rows, err := r.db.Query(`SELECT `+backupColumns+` FROM backups ORDER BY started_at DESC, id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: querying backups: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	backups := []models.Backup{}
+	for rows.Next() {
+		backup, err := scanBackup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning backup: %v", ErrDatabaseError, err)
+		}
+		backups = append(backups, *backup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating backup rows: %v", ErrDatabaseError, err)
+	}
+	return backups, nil
+}
+
+func (r *backupRepository) FinishBackup(executor SQLExecutor, id int64, status string, location *string, sizeBytes *int64, errMsg *string, finishedAt time.Time) error {
+	result, err := executor.Exec(`UPDATE backups SET status = $1, location = $2, size_bytes = $3, error = $4, finished_at = $5 WHERE id = $6`,
+		status, location, sizeBytes, errMsg, finishedAt, id)
+	if err != nil {
+		return fmt.Errorf("%w: finishing backup ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for finishing backup ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
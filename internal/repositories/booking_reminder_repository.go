@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// BookingReminderRepository defines the database operations for the reminder preferences of
+// clients and the reminders of their bookings.
+type BookingReminderRepository interface {
+	// GetPreferences returns the saved preferences of a client, without their Effective fields;
+	// ErrNotFound if it saved none.
+	GetPreferences(clientID int64) (*models.BookingReminderPreferences, error)
+	// SavePreferences creates or replaces the preferences of a client and sets their UpdatedAt.
+	SavePreferences(preferences *models.BookingReminderPreferences) error
+
+	// GetDue lists, soonest first, the pending and confirmed bookings of clients starting after
+	// now and within their lead time, that were not reminded of that start time yet. Clients
+	// without preferences of their own follow settings; those choosing no reminders are left out.
+	GetDue(settings models.BookingReminderSettings, now time.Time, limit int) ([]models.DueBookingReminder, error)
+	// Claim records reminder as being sent and sets its ID and CreatedAt. It returns false if
+	// the reminder was already claimed, e.g. by another instance.
+	Claim(reminder *models.BookingReminder) (bool, error)
+	// Finish records the outcome of sending a claimed reminder.
+	Finish(reminder *models.BookingReminder) error
+	// GetReminders lists the reminders matching filters, newest first, with their total.
+	GetReminders(filters models.BookingReminderFilters) ([]models.BookingReminder, int, error)
+}
+
+type bookingReminderRepository struct {
+	db *sql.DB
+}
+
+// NewBookingReminderRepository creates a new instance of BookingReminderRepository.
+func NewBookingReminderRepository(db *sql.DB) BookingReminderRepository {
+	return &bookingReminderRepository{db: db}
+}
+
+func (r *bookingReminderRepository) GetPreferences(clientID int64) (*models.BookingReminderPreferences, error) {
+	preferences := models.BookingReminderPreferences{ClientID: clientID}
+	var channel sql.NullString
+	var leadMinutes, telegramChatID sql.NullInt64
+	var updatedAt time.Time
+	err := r.db.QueryRow(`SELECT channel, lead_minutes, telegram_chat_id, updated_at
+	                      FROM client_reminder_preferences WHERE client_id = $1`, clientID,
+	).Scan(&channel, &leadMinutes, &telegramChatID, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting reminder preferences of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	if channel.Valid {
+		preferences.Channel = &channel.String
+	}
+	if leadMinutes.Valid {
+		minutes := int(leadMinutes.Int64)
+		preferences.LeadMinutes = &minutes
+	}
+	if telegramChatID.Valid {
+		preferences.TelegramChatID = &telegramChatID.Int64
+	}
+	preferences.UpdatedAt = &updatedAt
+	return &preferences, nil
+}
+
+func (r *bookingReminderRepository) SavePreferences(preferences *models.BookingReminderPreferences) error {
+	var updatedAt time.Time
+	err := r.db.QueryRow(`INSERT INTO client_reminder_preferences (client_id, channel, lead_minutes, telegram_chat_id, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5)
+	                      ON CONFLICT (client_id) DO UPDATE SET channel = EXCLUDED.channel, lead_minutes = EXCLUDED.lead_minutes,
+	                          telegram_chat_id = EXCLUDED.telegram_chat_id, updated_at = EXCLUDED.updated_at
+	                      RETURNING updated_at`,
+		preferences.ClientID, preferences.Channel, preferences.LeadMinutes, preferences.TelegramChatID, time.Now().UTC(),
+	).Scan(&updatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: saving reminder preferences of client ID %d: %v", ErrDatabaseError, preferences.ClientID, err)
+	}
+	preferences.UpdatedAt = &updatedAt
+	return nil
+}
+
+func (r *bookingReminderRepository) GetDue(settings models.BookingReminderSettings, now time.Time, limit int) ([]models.DueBookingReminder, error) {
+	rows, err := r.db.Query(`SELECT b.id, b.client_id, gt.name, b.start_time, eff.channel, c.phone_number, p.telegram_chat_id
+	                         FROM bookings b
+	                         JOIN clients c ON c.id = b.client_id AND c.deleted_at IS NULL AND c.anonymized_at IS NULL
+	                         JOIN game_tables gt ON gt.id = b.table_id
+	                         LEFT JOIN client_reminder_preferences p ON p.client_id = b.client_id
+	                         CROSS JOIN LATERAL (SELECT COALESCE(p.channel, $1) AS channel,
+	                                                    COALESCE(p.lead_minutes, $2) AS lead_minutes) eff
+	                         WHERE b.deleted_at IS NULL AND b.status IN ($3, $4)
+	                           AND b.start_time > $5 AND b.start_time <= $5 + eff.lead_minutes * INTERVAL '1 minute'
+	                           AND eff.channel <> $6
+	                           AND NOT EXISTS (SELECT 1 FROM booking_reminders br
+	                                           WHERE br.booking_id = b.id AND br.booking_start = b.start_time)
+	                         ORDER BY b.start_time, b.id
+	                         LIMIT $7`,
+		settings.Channel, settings.LeadMinutes, models.BookingStatusPending, models.BookingStatusConfirmed, now,
+		models.ReminderChannelNone, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing due booking reminders: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	due := []models.DueBookingReminder{}
+	for rows.Next() {
+		var reminder models.DueBookingReminder
+		var phoneNumber sql.NullString
+		var telegramChatID sql.NullInt64
+		if err := rows.Scan(&reminder.BookingID, &reminder.ClientID, &reminder.TableName, &reminder.StartTime, &reminder.Channel,
+			&phoneNumber, &telegramChatID); err != nil {
+			return nil, fmt.Errorf("%w: scanning due booking reminder: %v", ErrDatabaseError, err)
+		}
+		if phoneNumber.Valid {
+			reminder.PhoneNumber = &phoneNumber.String
+		}
+		if telegramChatID.Valid {
+			reminder.TelegramChatID = &telegramChatID.Int64
+		}
+		due = append(due, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating due booking reminders: %v", ErrDatabaseError, err)
+	}
+	return due, nil
+}
+
+func (r *bookingReminderRepository) Claim(reminder *models.BookingReminder) (bool, error) {
+	err := r.db.QueryRow(`INSERT INTO booking_reminders (booking_id, client_id, booking_start, channel, status, created_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6)
+	                      ON CONFLICT (booking_id, booking_start) DO NOTHING
+	                      RETURNING id, created_at`,
+		reminder.BookingID, reminder.ClientID, reminder.BookingStart, reminder.Channel, models.BookingReminderSending, time.Now().UTC(),
+	).Scan(&reminder.ID, &reminder.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: claiming reminder of booking ID %d: %v", ErrDatabaseError, reminder.BookingID, err)
+	}
+	reminder.Status = models.BookingReminderSending
+	return true, nil
+}
+
+func (r *bookingReminderRepository) Finish(reminder *models.BookingReminder) error {
+	_, err := r.db.Exec(`UPDATE booking_reminders SET status = $1, error = $2, sent_at = $3 WHERE id = $4`,
+		reminder.Status, reminder.Error, reminder.SentAt, reminder.ID)
+	if err != nil {
+		return fmt.Errorf("%w: recording reminder of booking ID %d: %v", ErrDatabaseError, reminder.BookingID, err)
+	}
+	return nil
+}
+
+const bookingReminderColumns = `id, booking_id, client_id, booking_start, channel, status, error, created_at, sent_at`
+
+func scanBookingReminder(row scanner) (*models.BookingReminder, error) {
+	var reminder models.BookingReminder
+	err := row.Scan(&reminder.ID, &reminder.BookingID, &reminder.ClientID, &reminder.BookingStart, &reminder.Channel,
+		&reminder.Status, &reminder.Error, &reminder.CreatedAt, &reminder.SentAt)
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (r *bookingReminderRepository) GetReminders(filters models.BookingReminderFilters) ([]models.BookingReminder, int, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	argCounter := 1
+
+	if filters.ClientID != nil {
+		conditions = append(conditions, fmt.Sprintf("client_id = $%d", argCounter))
+		args = append(args, *filters.ClientID)
+		argCounter++
+	}
+	if filters.BookingID != nil {
+		conditions = append(conditions, fmt.Sprintf("booking_id = $%d", argCounter))
+		args = append(args, *filters.BookingID)
+		argCounter++
+	}
+	if filters.Channel != nil {
+		conditions = append(conditions, fmt.Sprintf("channel = $%d", argCounter))
+		args = append(args, *filters.Channel)
+		argCounter++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCounter))
+		args = append(args, *filters.Status)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argCounter))
+		args = append(args, *filters.To)
+		argCounter++
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM booking_reminders WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: counting booking reminders: %v", ErrDatabaseError, err)
+	}
+
+	query := fmt.Sprintf(`SELECT `+bookingReminderColumns+` FROM booking_reminders WHERE `+where+`
+	                      ORDER BY created_at DESC, id DESC
+	                      LIMIT $%d OFFSET $%d`, argCounter, argCounter+1)
+	args = append(args, filters.PageSize, (filters.Page-1)*filters.PageSize)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: listing booking reminders: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	reminders := []models.BookingReminder{}
+	for rows.Next() {
+		reminder, err := scanBookingReminder(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: scanning booking reminder: %v", ErrDatabaseError, err)
+		}
+		reminders = append(reminders, *reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: iterating booking reminders: %v", ErrDatabaseError, err)
+	}
+	return reminders, total, nil
+}
@@ -8,7 +8,7 @@ import (
 	"strings"
 	"time"
 
-	// "github.com/lib/pq" // Not strictly needed if not checking specific pq errors here
+	"github.com/lib/pq" // For pq.Error
 )
 
 // BookingRepository defines the interface for booking-related database operations.
@@ -16,9 +16,30 @@ type BookingRepository interface {
 	CreateBooking(executor SQLExecutor, booking *models.Booking) (*models.Booking, error)
 	GetBookingByID(id int64) (*models.Booking, error) // Should join with client, table, staff (user)
 	GetBookings(filters models.BookingFilters) ([]models.Booking, int, error) // Bookings, total count. Joins.
+	// StreamBookings calls fn with each booking matching filters (without paging), latest start
+	// first, as rows are read. An error from fn stops the stream and is returned as is.
+	StreamBookings(filters models.BookingFilters, fn func(*models.Booking) error) error
 	UpdateBooking(executor SQLExecutor, booking *models.Booking) (*models.Booking, error)
-	DeleteBooking(executor SQLExecutor, id int64) error
-	CheckTableAvailability(tableID int64, startTime time.Time, endTime time.Time, excludeBookingID *int64) (bool, error) // True if available
+	// DeleteBooking soft deletes a booking; ErrNotFound if it does not exist or is already deleted.
+	DeleteBooking(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error
+	// RestoreBooking undoes the soft delete of a booking. ErrNotFound if it is not deleted;
+	// ErrTableNotAvailable if it is confirmed and another booking of its table overlaps it since.
+	RestoreBooking(executor SQLExecutor, id int64, now time.Time) error
+	// CheckTableAvailability reports whether the table is free for the period, keeping the table's
+	// cleanup buffer (game_tables.buffer_minutes, or defaultBufferMinutes) free after each booking.
+	CheckTableAvailability(tableID int64, startTime time.Time, endTime time.Time, defaultBufferMinutes int, excludeBookingID *int64) (bool, error)
+	// CreateBookingChanges records changes in the booking history; call it in the transaction of the change.
+	CreateBookingChanges(executor SQLExecutor, changes []models.BookingChange) error
+	// GetBookingChanges returns the history of a booking, oldest change first.
+	GetBookingChanges(bookingID int64) ([]models.BookingChange, error)
+	// GetBookingByCheckInToken returns the booking with the check-in token; ErrNotFound if there is none.
+	GetBookingByCheckInToken(token string) (*models.Booking, error)
+	// GetBookingByUUID returns the booking the client created with uuid; ErrNotFound if there is none.
+	GetBookingByUUID(uuid string) (*models.Booking, error)
+	// CheckInBooking records that the client checked in at checkedInAt. It returns
+	// ErrVersionConflict if the booking is already checked in.
+	CheckInBooking(executor SQLExecutor, booking *models.Booking, checkedInAt time.Time) (*models.Booking, error)
+	GetGameTableByID(id int64) (*models.GameTable, error)
 }
 
 type bookingRepository struct {
@@ -45,14 +66,14 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 	var clientLoyaltyPoints sql.NullInt32 
 
 	// Nullable fields for GameTable (though most are NOT NULL in DB, COALESCE for safety in JOINs)
-	var gameTableName, gameTableDesc, gameTableStatus sql.NullString
-	var gameTableCapacity sql.NullInt32
-	var gameTableHourlyRate sql.NullFloat64
+	var gameTableName, gameTableDesc, gameTableStatus, gameTableConsole sql.NullString
+	var gameTableCapacity, gameTableBuffer, gameTableControllers sql.NullInt32
+	var gameTableHourlyRate *models.Money
 	
 	// Nullable fields for StaffMember
 	var staffUserID sql.NullInt64 // This is User.ID for the staff
 	var staffPhone, staffAddr, staffHireDate, staffPos sql.NullString
-	var staffSalary sql.NullFloat64
+	var staffSalary *models.Money
 
 	// Nullable fields for User (linked to StaffMember)
 	var staffUserUsername, staffUserEmail, staffUserFullName sql.NullString
@@ -61,18 +82,21 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 
 	// totalCount for list queries
 	var totalCount int
+	var checkInToken sql.NullString // NULL only for bookings created while the migration ran
+	var controllers sql.NullInt32
 
 	// Base booking fields
 	scanDest := []interface{}{
 		&booking.ID, &booking.ClientID, &booking.TableID, &booking.StaffID,
 		&booking.StartTime, &booking.EndTime, &booking.NumberOfGuests, &booking.Status, &booking.Notes, &booking.TotalPrice,
-		&booking.CreatedAt, &booking.UpdatedAt,
+		&booking.CreatedAt, &booking.UpdatedAt, &booking.Version, &booking.CancellationReason, &booking.CancellationFee, &checkInToken, &booking.CheckedInAt,
+		&controllers, &booking.UUID, &booking.Source, &booking.BufferMinutes,
 	}
 
 	// Fields for Client join
 	scanDest = append(scanDest, &client.ID, &clientFullName, &clientPhone, &clientEmail, &clientDOB, &clientLoyaltyPoints, &clientNotes, &client.CreatedAt, &client.UpdatedAt)
 	// Fields for GameTable join
-	scanDest = append(scanDest, &gameTable.ID, &gameTableName, &gameTableDesc, &gameTableStatus, &gameTableCapacity, &gameTableHourlyRate, &gameTable.CreatedAt, &gameTable.UpdatedAt)
+	scanDest = append(scanDest, &gameTable.ID, &gameTableName, &gameTableDesc, &gameTableStatus, &gameTableCapacity, &gameTableHourlyRate, &gameTableBuffer, &gameTableConsole, &gameTableControllers, &gameTable.CreatedAt, &gameTable.UpdatedAt)
 	// Fields for StaffMember join
 	scanDest = append(scanDest, &staffMember.ID, &staffUserID, &staffPhone, &staffAddr, &staffHireDate, &staffPos, &staffSalary, &staffMember.CreatedAt, &staffMember.UpdatedAt)
 	// Fields for User join (for StaffMember)
@@ -90,6 +114,12 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 		return nil, 0, fmt.Errorf("%w: scanning booking with details: %v", ErrDatabaseError, err)
 	}
 
+	booking.CheckInToken = checkInToken.String
+	if controllers.Valid {
+		count := int(controllers.Int32)
+		booking.Controllers = &count
+	}
+
 	if booking.ClientID != nil { 
 		client.FullName = clientFullName.String
 		if clientPhone.Valid { client.PhoneNumber = &clientPhone.String }
@@ -102,6 +132,9 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 		}
 		if clientLoyaltyPoints.Valid { lp := int(clientLoyaltyPoints.Int32); client.LoyaltyPoints = &lp }
 		if clientNotes.Valid { client.Notes = &clientNotes.String }
+		if err := decryptClientNotes(client.Notes); err != nil {
+			return nil, 0, err
+		}
 		booking.Client = &client
 	} else { // Ensure client is nil if ClientID is nil
 		booking.Client = nil
@@ -112,7 +145,10 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 	if gameTableDesc.Valid { gameTable.Description = &gameTableDesc.String }
 	if gameTableStatus.Valid { gameTable.Status = gameTableStatus.String }
 	if gameTableCapacity.Valid { cap := int(gameTableCapacity.Int32); gameTable.Capacity = &cap }
-	if gameTableHourlyRate.Valid { gameTable.HourlyRate = &gameTableHourlyRate.Float64 }
+	gameTable.HourlyRate = gameTableHourlyRate
+	if gameTableBuffer.Valid { buffer := int(gameTableBuffer.Int32); gameTable.BufferMinutes = &buffer }
+	if gameTableConsole.Valid { gameTable.ConsoleType = &gameTableConsole.String }
+	gameTable.BaseControllers = int(gameTableControllers.Int32)
 	booking.GameTable = &gameTable
 	
 	if booking.StaffID != nil { 
@@ -121,7 +157,7 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 		if staffAddr.Valid { staffMember.Address = &staffAddr.String }
 		if staffHireDate.Valid { staffMember.HireDate = &staffHireDate.String }
 		if staffPos.Valid { staffMember.Position = &staffPos.String }
-		if staffSalary.Valid { staffMember.Salary = &staffSalary.Float64 }
+		staffMember.Salary = staffSalary
 		
 		if staffUserID.Valid { // Only populate user if staffUserID (which is u.id) is valid
 			user.ID = staffUserID.Int64 
@@ -145,21 +181,32 @@ func scanBookingRow(row scanner, isList bool) (*models.Booking, int, error) {
 
 func (r *bookingRepository) CreateBooking(executor SQLExecutor, booking *models.Booking) (*models.Booking, error) {
 	query := `INSERT INTO bookings 
-	            (client_id, table_id, staff_id, start_time, end_time, number_of_guests, status, notes, total_price, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	          RETURNING id, created_at, updated_at`
+	            (client_id, table_id, staff_id, start_time, end_time, number_of_guests, status, notes, total_price, created_at, updated_at, cancellation_reason, cancellation_fee, check_in_token, controllers, uuid, source, buffer_minutes)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	          RETURNING id, created_at, updated_at, version`
 	
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	booking.CreatedAt = currentTime
 	booking.UpdatedAt = currentTime
+	if booking.Source == "" {
+		booking.Source = models.OrderSourcePOS
+	}
 
 	err := executor.QueryRow(query,
 		booking.ClientID, booking.TableID, booking.StaffID, booking.StartTime, booking.EndTime,
 		booking.NumberOfGuests, booking.Status, booking.Notes, booking.TotalPrice,
-		booking.CreatedAt, booking.UpdatedAt,
-	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
+		booking.CreatedAt, booking.UpdatedAt, booking.CancellationReason, booking.CancellationFee, booking.CheckInToken, booking.Controllers,
+		booking.UUID, booking.Source, booking.BufferMinutes,
+	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt, &booking.Version)
 
 	if err != nil {
+		if isBookingOverlap(err) {
+			return nil, ErrTableNotAvailable
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "bookings_uuid_key" {
+			return nil, fmt.Errorf("%w: booking UUID %s already exists", ErrDuplicateKey, *booking.UUID)
+		}
 		return nil, fmt.Errorf("%w: creating booking: %v", ErrDatabaseError, err)
 	}
 	return booking, nil
@@ -174,16 +221,16 @@ const getBookingJoins = `
 `
 const selectBookingFields = `
 	b.id, b.client_id, b.table_id, b.staff_id, b.start_time, b.end_time, 
-	b.number_of_guests, b.status, b.notes, b.total_price, b.created_at, b.updated_at,
+	b.number_of_guests, b.status, b.notes, b.total_price, b.created_at, b.updated_at, b.version, b.cancellation_reason, b.cancellation_fee, b.check_in_token, b.checked_in_at, b.controllers, b.uuid, b.source, b.buffer_minutes,
 	COALESCE(c.id, 0), COALESCE(c.full_name, ''), COALESCE(c.phone_number, ''), COALESCE(c.email, ''), c.date_of_birth, COALESCE(c.loyalty_points, 0), COALESCE(c.notes, ''), COALESCE(c.created_at, '0001-01-01'::timestamp), COALESCE(c.updated_at, '0001-01-01'::timestamp),
-	gt.id, gt.name, gt.description, gt.status, gt.capacity, gt.hourly_rate, gt.created_at, gt.updated_at,
+	gt.id, gt.name, gt.description, gt.status, gt.capacity, gt.hourly_rate, gt.buffer_minutes, gt.console_type, gt.base_controllers, gt.created_at, gt.updated_at,
 	COALESCE(sm.id, 0), sm.user_id, COALESCE(sm.phone_number, ''), COALESCE(sm.address, ''), COALESCE(sm.hire_date, ''), COALESCE(sm.position, ''), COALESCE(sm.salary, 0), COALESCE(sm.created_at, '0001-01-01'::timestamp), COALESCE(sm.updated_at, '0001-01-01'::timestamp),
 	COALESCE(u.id, 0), COALESCE(u.username, ''), COALESCE(u.email, ''), COALESCE(u.full_name, ''), COALESCE(u.is_active, false), u.role_id, COALESCE(u.created_at, '0001-01-01'::timestamp), COALESCE(u.updated_at, '0001-01-01'::timestamp)
 `
 
 
 func (r *bookingRepository) GetBookingByID(id int64) (*models.Booking, error) {
-	query := "SELECT " + selectBookingFields + getBookingJoins + " WHERE b.id = $1"
+	query := "SELECT " + selectBookingFields + getBookingJoins + " WHERE b.id = $1 AND b.deleted_at IS NULL"
 	booking, _, err := scanBookingRow(r.db.QueryRow(query, id), false)
 	return booking, err
 }
@@ -191,11 +238,29 @@ func (r *bookingRepository) GetBookingByID(id int64) (*models.Booking, error) {
 func (r *bookingRepository) GetBookings(filters models.BookingFilters) ([]models.Booking, int, error) {
 	bookings := []models.Booking{}
 	var totalCount int // Initialize totalCount
+	err := r.queryBookings(filters, filters.Cursor == nil, func(booking *models.Booking, total int) error {
+		bookings = append(bookings, *booking)
+		totalCount = total // total_count is the same for all rows from OVER()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return bookings, totalCount, nil
+}
+
+func (r *bookingRepository) StreamBookings(filters models.BookingFilters, fn func(*models.Booking) error) error {
+	filters.Page, filters.PageSize, filters.Cursor = 0, 0, nil
+	return r.queryBookings(filters, false, func(booking *models.Booking, _ int) error { return fn(booking) })
+}
 
+// bookingsListQuery builds the booking list query for filters, with the total count of
+// matching bookings if withTotal is set.
+func bookingsListQuery(filters models.BookingFilters, withTotal bool) (string, []interface{}) {
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString("SELECT " + selectBookingFields + ", COUNT(*) OVER() as total_count " + getBookingJoins)
+	queryBuilder.WriteString("SELECT " + selectBookingFields + ", " + totalCountColumn(withTotal) + " " + getBookingJoins)
 
-	var conditions []string
+	conditions := []string{"b.deleted_at IS NULL"}
 	var args []interface{}
 	argCount := 1
 
@@ -203,73 +268,91 @@ func (r *bookingRepository) GetBookings(filters models.BookingFilters) ([]models
 	if filters.TableID != nil { conditions = append(conditions, fmt.Sprintf("b.table_id = $%d", argCount)); args = append(args, *filters.TableID); argCount++ }
 	if filters.StaffID != nil { conditions = append(conditions, fmt.Sprintf("b.staff_id = $%d", argCount)); args = append(args, *filters.StaffID); argCount++ }
 	if filters.Status != nil && *filters.Status != "" { conditions = append(conditions, fmt.Sprintf("b.status = $%d", argCount)); args = append(args, *filters.Status); argCount++ }
+	if filters.ClientTag != nil { conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM client_tags ct WHERE ct.client_id = b.client_id AND ct.tag = $%d)", argCount)); args = append(args, *filters.ClientTag); argCount++ }
+	if filters.Source != nil && *filters.Source != "" { conditions = append(conditions, fmt.Sprintf("b.source = $%d", argCount)); args = append(args, *filters.Source); argCount++ }
 	if filters.DateFrom != nil { conditions = append(conditions, fmt.Sprintf("b.start_time >= $%d", argCount)); args = append(args, *filters.DateFrom); argCount++ }
 	if filters.DateTo != nil { conditions = append(conditions, fmt.Sprintf("b.end_time <= $%d", argCount)); args = append(args, *filters.DateTo); argCount++ }
+	if len(filters.Statuses) > 0 { conditions = append(conditions, fmt.Sprintf("b.status = ANY($%d)", argCount)); args = append(args, pq.Array(filters.Statuses)); argCount++ }
+	if filters.EndsAfter != nil { conditions = append(conditions, fmt.Sprintf("b.end_time > $%d", argCount)); args = append(args, *filters.EndsAfter); argCount++ }
+	if filters.StartsBefore != nil { conditions = append(conditions, fmt.Sprintf("b.start_time < $%d", argCount)); args = append(args, *filters.StartsBefore); argCount++ }
+	if filters.Cursor != nil && !filters.Cursor.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("(b.start_time, b.id) < ($%d, $%d)", argCount, argCount+1))
+		args = append(args, filters.Cursor.Time, filters.Cursor.ID)
+		argCount += 2
+	}
 
 
-	if len(conditions) > 0 {
-		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
-	}
-	queryBuilder.WriteString(" ORDER BY b.start_time DESC")
+	queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	queryBuilder.WriteString(" ORDER BY b.start_time DESC, b.id DESC")
 
 	if filters.PageSize > 0 {
 		queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCount)); args = append(args, filters.PageSize); argCount++
-		if filters.Page > 0 {
+		if filters.Page > 0 && filters.Cursor == nil {
 			offset := (filters.Page - 1) * filters.PageSize
 			queryBuilder.WriteString(fmt.Sprintf(" OFFSET $%d", argCount)); args = append(args, offset)
 		}
 	}
+	return queryBuilder.String(), args
+}
 
-	rows, err := r.db.Query(queryBuilder.String(), args...)
+// queryBookings runs the booking list query and calls fn with each booking as it is read,
+// with the total count of matching bookings if withTotal is set.
+func (r *bookingRepository) queryBookings(filters models.BookingFilters, withTotal bool, fn func(booking *models.Booking, total int) error) error {
+	query, args := bookingsListQuery(filters, withTotal)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%w: querying bookings: %v", ErrDatabaseError, err)
+		return fmt.Errorf("%w: querying bookings: %v", ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		booking, scannedTotalCount, scanErr := scanBookingRow(rows, true)
 		if scanErr != nil {
-			return nil, 0, scanErr // Error already wrapped in scanBookingRow
+			return scanErr // Error already wrapped in scanBookingRow
+		}
+		if err := fn(booking, scannedTotalCount); err != nil {
+			return err
 		}
-		bookings = append(bookings, *booking)
-		totalCount = scannedTotalCount // total_count is the same for all rows from OVER()
 	}
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("%w: iterating booking rows: %v", ErrDatabaseError, err)
-	}
-	if len(bookings) == 0 { // If no results, totalCount from OVER() would be 0
-		totalCount = 0
+		return fmt.Errorf("%w: iterating booking rows: %v", ErrDatabaseError, err)
 	}
-	return bookings, totalCount, nil
+	return nil
 }
 
 
 func (r *bookingRepository) UpdateBooking(executor SQLExecutor, booking *models.Booking) (*models.Booking, error) {
 	query := `UPDATE bookings SET 
 	            client_id = $1, table_id = $2, staff_id = $3, start_time = $4, end_time = $5, 
-	            number_of_guests = $6, status = $7, notes = $8, total_price = $9, updated_at = $10
-	          WHERE id = $11
-	          RETURNING updated_at`
-	booking.UpdatedAt = time.Now()
+	            number_of_guests = $6, status = $7, notes = $8, total_price = $9, updated_at = $10,
+	            cancellation_reason = $13, cancellation_fee = $14, controllers = $15, buffer_minutes = $16, version = version + 1
+	          WHERE id = $11 AND version = $12
+	          RETURNING updated_at, version`
+	booking.UpdatedAt = time.Now().UTC()
 
 	err := executor.QueryRow(query,
 		booking.ClientID, booking.TableID, booking.StaffID, booking.StartTime, booking.EndTime,
 		booking.NumberOfGuests, booking.Status, booking.Notes, booking.TotalPrice,
-		booking.UpdatedAt, booking.ID,
-	).Scan(&booking.UpdatedAt)
+		booking.UpdatedAt, booking.ID, booking.Version, booking.CancellationReason, booking.CancellationFee, booking.Controllers,
+		booking.BufferMinutes,
+	).Scan(&booking.UpdatedAt, &booking.Version)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNotFound
+			return nil, versionMismatchError(executor, "bookings", booking.ID)
+		}
+		if isBookingOverlap(err) {
+			return nil, ErrTableNotAvailable
 		}
 		return nil, fmt.Errorf("%w: updating booking ID %d: %v", ErrDatabaseError, booking.ID, err)
 	}
 	return booking, nil
 }
 
-func (r *bookingRepository) DeleteBooking(executor SQLExecutor, id int64) error {
-	query := `DELETE FROM bookings WHERE id = $1`
-	result, err := executor.Exec(query, id)
+func (r *bookingRepository) DeleteBooking(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	query := `UPDATE bookings SET deleted_at = $2, deleted_by = $3, updated_at = $2, version = version + 1
+	          WHERE id = $1 AND deleted_at IS NULL`
+	result, err := executor.Exec(query, id, now, deletedBy)
 	if err != nil {
 		return fmt.Errorf("%w: deleting booking ID %d: %v", ErrDatabaseError, id, err)
 	}
@@ -280,13 +363,40 @@ func (r *bookingRepository) DeleteBooking(executor SQLExecutor, id int64) error
 	return nil
 }
 
-func (r *bookingRepository) CheckTableAvailability(tableID int64, startTime time.Time, endTime time.Time, excludeBookingID *int64) (bool, error) {
+func (r *bookingRepository) RestoreBooking(executor SQLExecutor, id int64, now time.Time) error {
+	query := `UPDATE bookings SET deleted_at = NULL, deleted_by = NULL, updated_at = $2, version = version + 1
+	          WHERE id = $1 AND deleted_at IS NOT NULL`
+	result, err := executor.Exec(query, id, now)
+	if err != nil {
+		if isBookingOverlap(err) {
+			return ErrTableNotAvailable
+		}
+		return fmt.Errorf("%w: restoring booking ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isBookingOverlap reports whether err is a violation of the bookings_no_overlap constraint,
+// which rejects confirmed bookings overlapping on the same table, each with its cleanup buffer.
+func isBookingOverlap(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "exclusion_violation" && pqErr.Constraint == "bookings_no_overlap"
+}
+
+// tableAvailabilityQuery builds the query counting the active bookings of a table that
+// overlap the given period. Each booking, the new one included, also occupies the table's
+// cleanup buffer after its end.
+func tableAvailabilityQuery(tableID int64, startTime time.Time, endTime time.Time, defaultBufferMinutes int, excludeBookingID *int64) (string, []interface{}) {
 	// Booking statuses that mean the table is occupied or unavailable for new bookings
-	activeBookingStatuses := []string{models.BookingStatusConfirmed, models.BookingStatusCheckedIn /*, models.BookingStatusPending? - depends on rules */}
+	activeBookingStatuses := []string{string(models.BookingStatusConfirmed) /*, models.BookingStatusPending? - depends on rules */}
 	
 	var statusPlaceholders []string
-	args := []interface{}{tableID, startTime, endTime}
-	argIdx := 4 // Start after tableID, startTime, endTime
+	args := []interface{}{tableID, startTime, endTime, defaultBufferMinutes}
+	argIdx := 5 // Start after tableID, startTime, endTime, defaultBufferMinutes
 
 	for _, status := range activeBookingStatuses {
 		statusPlaceholders = append(statusPlaceholders, fmt.Sprintf("$%d", argIdx))
@@ -297,16 +407,22 @@ func (r *bookingRepository) CheckTableAvailability(tableID int64, startTime time
 	statusInClause := strings.Join(statusPlaceholders, ", ")
 
 
-	query := fmt.Sprintf(`SELECT COUNT(*) FROM bookings 
-	          WHERE table_id = $1 
+	// Overlapping condition, with both periods extended by the buffer
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM bookings,
+	            (SELECT make_interval(mins => COALESCE((SELECT buffer_minutes FROM game_tables WHERE id = $1), $4::int)) AS buffer) table_buffer
+	          WHERE table_id = $1 AND deleted_at IS NULL
 	          AND status IN (%s)
-	          AND start_time < $3 AND end_time > $2`, statusInClause) // Overlapping condition
+	          AND start_time < $3::timestamptz + table_buffer.buffer AND end_time > $2::timestamptz - table_buffer.buffer`, statusInClause)
 	          
 	if excludeBookingID != nil {
 		query += fmt.Sprintf(" AND id != $%d", argIdx)
 		args = append(args, *excludeBookingID)
 	}
+	return query, args
+}
 
+func (r *bookingRepository) CheckTableAvailability(tableID int64, startTime time.Time, endTime time.Time, defaultBufferMinutes int, excludeBookingID *int64) (bool, error) {
+	query, args := tableAvailabilityQuery(tableID, startTime, endTime, defaultBufferMinutes, excludeBookingID)
 	var count int
 	err := r.db.QueryRow(query, args...).Scan(&count)
 	if err != nil {
@@ -314,3 +430,106 @@ func (r *bookingRepository) CheckTableAvailability(tableID int64, startTime time
 	}
 	return count == 0, nil 
 }
+
+func (r *bookingRepository) CreateBookingChanges(executor SQLExecutor, changes []models.BookingChange) error {
+	query := `INSERT INTO booking_changes
+	            (booking_id, change_type, old_value, new_value, reason, cancellation_reason, changed_by, changed_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	          RETURNING id`
+	for i := range changes {
+		change := &changes[i]
+		err := executor.QueryRow(query,
+			change.BookingID, change.ChangeType, change.OldValue, change.NewValue,
+			change.Reason, change.CancellationReason, change.ChangedBy, change.ChangedAt,
+		).Scan(&change.ID)
+		if err != nil {
+			return fmt.Errorf("%w: recording %s change of booking ID %d: %v", ErrDatabaseError, change.ChangeType, change.BookingID, err)
+		}
+	}
+	return nil
+}
+
+func (r *bookingRepository) GetBookingChanges(bookingID int64) ([]models.BookingChange, error) {
+	query := `SELECT bc.id, bc.booking_id, bc.change_type, bc.old_value, bc.new_value, bc.reason,
+	                 bc.cancellation_reason, bc.changed_by, COALESCE(u.full_name, u.username), bc.changed_at
+	          FROM booking_changes bc
+	          LEFT JOIN users u ON bc.changed_by = u.id
+	          WHERE bc.booking_id = $1
+	          ORDER BY bc.changed_at, bc.id`
+	rows, err := r.db.Query(query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: querying changes of booking ID %d: %v", ErrDatabaseError, bookingID, err)
+	}
+	defer rows.Close()
+
+	changes := []models.BookingChange{}
+	for rows.Next() {
+		var change models.BookingChange
+		err := rows.Scan(
+			&change.ID, &change.BookingID, &change.ChangeType, &change.OldValue, &change.NewValue, &change.Reason,
+			&change.CancellationReason, &change.ChangedBy, &change.ChangedByName, &change.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning booking change: %v", ErrDatabaseError, err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating booking change rows: %v", ErrDatabaseError, err)
+	}
+	return changes, nil
+}
+
+func (r *bookingRepository) GetBookingByCheckInToken(token string) (*models.Booking, error) {
+	query := "SELECT " + selectBookingFields + getBookingJoins + " WHERE b.check_in_token = $1 AND b.deleted_at IS NULL"
+	booking, _, err := scanBookingRow(r.db.QueryRow(query, token), false)
+	return booking, err
+}
+
+func (r *bookingRepository) GetBookingByUUID(uuid string) (*models.Booking, error) {
+	query := "SELECT " + selectBookingFields + getBookingJoins + " WHERE b.uuid = $1"
+	booking, _, err := scanBookingRow(r.db.QueryRow(query, uuid), false)
+	return booking, err
+}
+
+func (r *bookingRepository) CheckInBooking(executor SQLExecutor, booking *models.Booking, checkedInAt time.Time) (*models.Booking, error) {
+	query := `UPDATE bookings SET checked_in_at = $2, updated_at = $2, version = version + 1
+	          WHERE id = $1 AND checked_in_at IS NULL
+	          RETURNING updated_at, version`
+	err := executor.QueryRow(query, booking.ID, checkedInAt).Scan(&booking.UpdatedAt, &booking.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("%w: checking in booking ID %d: %v", ErrDatabaseError, booking.ID, err)
+	}
+	booking.CheckedInAt = &checkedInAt
+	return booking, nil
+}
+
+func (r *bookingRepository) GetGameTableByID(id int64) (*models.GameTable, error) {
+	var table models.GameTable
+	var capacity, bufferMinutes sql.NullInt32
+	err := r.db.QueryRow(`SELECT id, name, description, status, capacity, hourly_rate, buffer_minutes, power_device_id, console_type,
+	                             base_controllers, created_at, updated_at, billing_mode, minute_rate, billing_increment_minutes, minimum_charge
+	                      FROM game_tables WHERE id = $1`, id).Scan(
+		&table.ID, &table.Name, &table.Description, &table.Status, &capacity, &table.HourlyRate, &bufferMinutes, &table.PowerDeviceID,
+		&table.ConsoleType, &table.BaseControllers, &table.CreatedAt, &table.UpdatedAt, &table.BillingMode, &table.MinuteRate,
+		&table.BillingIncrementMinutes, &table.MinimumCharge,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting game table ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if capacity.Valid {
+		c := int(capacity.Int32)
+		table.Capacity = &c
+	}
+	if bufferMinutes.Valid {
+		b := int(bufferMinutes.Int32)
+		table.BufferMinutes = &b
+	}
+	return &table, nil
+}
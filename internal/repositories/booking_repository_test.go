@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/database/dbtest"
+	"ps_club_backend/internal/models"
+)
+
+// createTestTable creates a game table, with its own cleanup buffer if bufferMinutes is not nil.
+func createTestTable(t *testing.T, db *sql.DB, name string, bufferMinutes *int) int64 {
+	t.Helper()
+	rate := models.NewMoneyFromInt(2000)
+	table := &models.GameTable{
+		Name: name, Status: models.TableStatusAvailable, HourlyRate: &rate, BufferMinutes: bufferMinutes,
+		BaseControllers: models.DefaultBaseControllers, BillingMode: models.BillingModeHourly, BillingIncrementMinutes: 5,
+	}
+	if err := NewGameTableRepository(db).CreateGameTable(table); err != nil {
+		t.Fatalf("creating game table %s: %v", name, err)
+	}
+	return table.ID
+}
+
+// createTestBooking books the table from start to end, followed by a cleanup buffer of
+// bufferMinutes, without a client or staff member.
+func createTestBooking(t *testing.T, repo BookingRepository, db *sql.DB, tableID int64, start, end time.Time, bufferMinutes int, status models.BookingStatus) (*models.Booking, error) {
+	t.Helper()
+	testBookingTokens++
+	return repo.CreateBooking(db, &models.Booking{
+		TableID: tableID, StartTime: start, EndTime: end, Status: string(status), BufferMinutes: bufferMinutes,
+		CheckInToken: fmt.Sprintf("test-token-%d", testBookingTokens), // Unique across bookings
+	})
+}
+
+var testBookingTokens int
+
+func TestBookingRepositoryOverlap(t *testing.T) {
+	db := dbtest.New(t)
+	repo := NewBookingRepository(db)
+	start := time.Date(2030, 6, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		firstBuffer int // Buffer of the first booking
+		buffer      int
+		status      models.BookingStatus
+		start, end  time.Time
+		wantErr     error
+	}{
+		{name: "rejects an overlapping confirmed booking", status: models.BookingStatusConfirmed, start: start.Add(30 * time.Minute), end: start.Add(90 * time.Minute), wantErr: ErrTableNotAvailable},
+		{name: "rejects a confirmed booking inside another", status: models.BookingStatusConfirmed, start: start.Add(15 * time.Minute), end: start.Add(45 * time.Minute), wantErr: ErrTableNotAvailable},
+		{name: "takes a booking starting when the other ends", status: models.BookingStatusConfirmed, start: start.Add(time.Hour), end: start.Add(2 * time.Hour)},
+		{name: "takes a booking ending when the other starts", status: models.BookingStatusConfirmed, start: start.Add(-time.Hour), end: start},
+		{name: "takes an overlapping pending booking", status: models.BookingStatusPending, start: start, end: start.Add(time.Hour)},
+		{name: "rejects a booking inside the buffer of another", firstBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(70 * time.Minute), end: start.Add(2 * time.Hour), wantErr: ErrTableNotAvailable},
+		{name: "takes a booking after the buffer of another", firstBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(75 * time.Minute), end: start.Add(2 * time.Hour)},
+		{name: "rejects a booking whose buffer runs into another", buffer: 15, status: models.BookingStatusConfirmed, start: start.Add(-time.Hour), end: start.Add(-10 * time.Minute), wantErr: ErrTableNotAvailable},
+		{name: "takes a pending booking inside the buffer of another", firstBuffer: 15, status: models.BookingStatusPending, start: start.Add(70 * time.Minute), end: start.Add(2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Truncate(t, db, "bookings", "game_tables")
+			tableID := createTestTable(t, db, "PS5 #1", nil)
+			if _, err := createTestBooking(t, repo, db, tableID, start, start.Add(time.Hour), tt.firstBuffer, models.BookingStatusConfirmed); err != nil {
+				t.Fatalf("creating the first booking: %v", err)
+			}
+
+			_, err := createTestBooking(t, repo, db, tableID, tt.start, tt.end, tt.buffer, tt.status)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CreateBooking: %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateBooking error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("another table is free", func(t *testing.T) {
+		dbtest.Truncate(t, db, "bookings", "game_tables")
+		first, second := createTestTable(t, db, "PS5 #1", nil), createTestTable(t, db, "PS5 #2", nil)
+		if _, err := createTestBooking(t, repo, db, first, start, start.Add(time.Hour), 0, models.BookingStatusConfirmed); err != nil {
+			t.Fatalf("creating the first booking: %v", err)
+		}
+		if _, err := createTestBooking(t, repo, db, second, start, start.Add(time.Hour), 0, models.BookingStatusConfirmed); err != nil {
+			t.Fatalf("CreateBooking on another table: %v", err)
+		}
+	})
+}
+
+func TestBookingRepositoryCheckTableAvailability(t *testing.T) {
+	db := dbtest.New(t)
+	repo := NewBookingRepository(db)
+	start := time.Date(2030, 6, 1, 18, 0, 0, 0, time.UTC)
+	tableBuffer := 30
+
+	tests := []struct {
+		name          string
+		tableBuffer   *int
+		policyBuffer  int
+		status        models.BookingStatus
+		start, end    time.Time
+		excludeBooked bool
+		want          bool
+	}{
+		{name: "overlapping", status: models.BookingStatusConfirmed, start: start.Add(30 * time.Minute), end: start.Add(2 * time.Hour), want: false},
+		{name: "back to back without a buffer", status: models.BookingStatusConfirmed, start: start.Add(time.Hour), end: start.Add(2 * time.Hour), want: true},
+		{name: "inside the policy buffer", policyBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(70 * time.Minute), end: start.Add(2 * time.Hour), want: false},
+		{name: "after the policy buffer", policyBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(75 * time.Minute), end: start.Add(2 * time.Hour), want: true},
+		{name: "ending inside the buffer before it", policyBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(-time.Hour), end: start.Add(-10 * time.Minute), want: false},
+		{name: "inside the table's own buffer", tableBuffer: &tableBuffer, policyBuffer: 15, status: models.BookingStatusConfirmed, start: start.Add(80 * time.Minute), end: start.Add(2 * time.Hour), want: false},
+		{name: "over a cancelled booking", status: models.BookingStatusCancelled, start: start, end: start.Add(time.Hour), want: true},
+		{name: "the booking itself when moved", status: models.BookingStatusConfirmed, start: start.Add(30 * time.Minute), end: start.Add(90 * time.Minute), excludeBooked: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Truncate(t, db, "bookings", "game_tables")
+			tableID := createTestTable(t, db, "PS5 #1", tt.tableBuffer)
+			booked, err := createTestBooking(t, repo, db, tableID, start, start.Add(time.Hour), 0, tt.status)
+			if err != nil {
+				t.Fatalf("creating the booking: %v", err)
+			}
+			var exclude *int64
+			if tt.excludeBooked {
+				exclude = &booked.ID
+			}
+
+			available, err := repo.CheckTableAvailability(tableID, tt.start, tt.end, tt.policyBuffer, exclude)
+			if err != nil {
+				t.Fatalf("CheckTableAvailability: %v", err)
+			}
+			if available != tt.want {
+				t.Errorf("CheckTableAvailability = %v, want %v", available, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookingRepositorySoftDelete(t *testing.T) {
+	db := dbtest.New(t)
+	repo := NewBookingRepository(db)
+	start := time.Date(2030, 6, 1, 18, 0, 0, 0, time.UTC)
+	tableID := createTestTable(t, db, "PS5 #1", nil)
+
+	booking, err := createTestBooking(t, repo, db, tableID, start, start.Add(time.Hour), 0, models.BookingStatusConfirmed)
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	var adminID int64
+	if err := db.QueryRow(`INSERT INTO users (username, password_hash, role_id) VALUES ('admin', 'x', 1) RETURNING id`).Scan(&adminID); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	if err := repo.DeleteBooking(db, booking.ID, adminID, time.Now()); err != nil {
+		t.Fatalf("DeleteBooking: %v", err)
+	}
+	if _, err := repo.GetBookingByID(booking.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBookingByID of a deleted booking error = %v, want %v", err, ErrNotFound)
+	}
+
+	// The deleted booking frees its table, so restoring it fails once the slot is taken again
+	if _, err := createTestBooking(t, repo, db, tableID, start, start.Add(time.Hour), 0, models.BookingStatusConfirmed); err != nil {
+		t.Fatalf("booking the freed slot: %v", err)
+	}
+	if err := repo.RestoreBooking(db, booking.ID, time.Now()); !errors.Is(err, ErrTableNotAvailable) {
+		t.Errorf("RestoreBooking over a new booking error = %v, want %v", err, ErrTableNotAvailable)
+	}
+}
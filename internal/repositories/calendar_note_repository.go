@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// CalendarNoteRepository defines the database operations for calendar notes. Dates are club
+// dates (YYYY-MM-DD).
+type CalendarNoteRepository interface {
+	CreateNote(note *models.CalendarNote) error
+	// GetNoteByID returns a calendar note; ErrNotFound if there is none.
+	GetNoteByID(id int64) (*models.CalendarNote, error)
+	// GetNotes lists the notes of a branch, including those of every branch, that overlap
+	// fromDate to toDate (inclusive), by start date.
+	GetNotes(branchCode, fromDate, toDate string) ([]models.CalendarNote, error)
+	// UpdateNote saves the dates, category, title and note of a calendar note; ErrNotFound if
+	// there is none.
+	UpdateNote(note *models.CalendarNote) error
+	// DeleteNote deletes a calendar note; ErrNotFound if there is none.
+	DeleteNote(id int64) error
+}
+
+type calendarNoteRepository struct {
+	db *sql.DB
+}
+
+// NewCalendarNoteRepository creates a new instance of CalendarNoteRepository.
+func NewCalendarNoteRepository(db *sql.DB) CalendarNoteRepository {
+	return &calendarNoteRepository{db: db}
+}
+
+const calendarNoteColumns = `id, branch_code, TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD'), category, title, note,
+	created_by, created_at, updated_at`
+
+func scanCalendarNote(row scanner) (*models.CalendarNote, error) {
+	var note models.CalendarNote
+	err := row.Scan(&note.ID, &note.BranchCode, &note.StartDate, &note.EndDate, &note.Category, &note.Title, &note.Note,
+		&note.CreatedBy, &note.CreatedAt, &note.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *calendarNoteRepository) CreateNote(note *models.CalendarNote) error {
+	err := r.db.QueryRow(`INSERT INTO calendar_notes (branch_code, start_date, end_date, category, title, note, created_by, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	                      RETURNING id, created_at, updated_at`,
+		note.BranchCode, note.StartDate, note.EndDate, note.Category, note.Title, note.Note, note.CreatedBy, time.Now().UTC(),
+	).Scan(&note.ID, &note.CreatedAt, &note.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: creating calendar note: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *calendarNoteRepository) GetNoteByID(id int64) (*models.CalendarNote, error) {
+	note, err := scanCalendarNote(r.db.QueryRow(`SELECT `+calendarNoteColumns+` FROM calendar_notes WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting calendar note ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return note, nil
+}
+
+func (r *calendarNoteRepository) GetNotes(branchCode, fromDate, toDate string) ([]models.CalendarNote, error) {
+	rows, err := r.db.Query(`SELECT `+calendarNoteColumns+`
+	                         FROM calendar_notes
+	                         WHERE (branch_code IS NULL OR branch_code = $1) AND start_date <= $3::date AND end_date >= $2::date
+	                         ORDER BY start_date, end_date, id`, branchCode, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing calendar notes: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	notes := []models.CalendarNote{}
+	for rows.Next() {
+		note, err := scanCalendarNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning calendar note: %v", ErrDatabaseError, err)
+		}
+		notes = append(notes, *note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating calendar notes: %v", ErrDatabaseError, err)
+	}
+	return notes, nil
+}
+
+func (r *calendarNoteRepository) UpdateNote(note *models.CalendarNote) error {
+	err := r.db.QueryRow(`UPDATE calendar_notes SET start_date = $2, end_date = $3, category = $4, title = $5, note = $6, updated_at = $7
+	                      WHERE id = $1
+	                      RETURNING updated_at`,
+		note.ID, note.StartDate, note.EndDate, note.Category, note.Title, note.Note, time.Now().UTC(),
+	).Scan(&note.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: updating calendar note ID %d: %v", ErrDatabaseError, note.ID, err)
+	}
+	return nil
+}
+
+func (r *calendarNoteRepository) DeleteNote(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM calendar_notes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting calendar note ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
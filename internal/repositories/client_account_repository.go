@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ClientAccountRepository defines the database operations for house accounts and their ledger.
+// The balances of the accounts it returns are computed from the ledger as of now: the
+// overdue amount is what remains of the charges due before now once the payments and
+// reversals are applied to the oldest charges first.
+type ClientAccountRepository interface {
+	// CreateAccount returns ErrDuplicateKey if the client already has an account.
+	CreateAccount(executor SQLExecutor, account *models.ClientAccount) error
+	// GetAccount returns ErrNotFound if the client has no account. Pass a transaction with
+	// lock set to keep the account from being charged concurrently until it ends.
+	GetAccount(executor SQLExecutor, clientID int64, now time.Time, lock bool) (*models.ClientAccount, error)
+	// GetAccounts lists the accounts by client name, only the overdue ones if overdueOnly.
+	GetAccounts(now time.Time, overdueOnly bool) ([]models.ClientAccount, error)
+	// UpdateAccount updates the credit limit, terms and status of an account. Unless version
+	// is 0 the account must still have it; ErrVersionConflict if it changed in the meantime.
+	UpdateAccount(executor SQLExecutor, account *models.ClientAccount, version int) error
+
+	CreateEntry(executor SQLExecutor, entry *models.ClientAccountEntry) error
+	// GetEntries lists the entries of an account created in [from, to), oldest first.
+	GetEntries(clientID int64, from, to time.Time) ([]models.ClientAccountEntry, error)
+	// GetBalanceAt returns the balance of an account before at.
+	GetBalanceAt(clientID int64, at time.Time) (models.Money, error)
+	// GetOrderBalance returns the account an order was charged to and what remains charged
+	// for it after reversals; ErrNotFound if the order was never charged to an account.
+	GetOrderBalance(executor SQLExecutor, orderID int64) (clientID int64, amount models.Money, err error)
+}
+
+type clientAccountRepository struct {
+	db *sql.DB
+}
+
+// NewClientAccountRepository creates a new instance of ClientAccountRepository.
+func NewClientAccountRepository(db *sql.DB) ClientAccountRepository {
+	return &clientAccountRepository{db: db}
+}
+
+// clientAccountSelect selects the accounts with their balances as of $1.
+const clientAccountSelect = `
+	SELECT ca.client_id, c.full_name, ca.credit_limit, ca.payment_terms_days, ca.is_active,
+	       ca.created_at, ca.updated_at, ca.version,
+	       COALESCE(l.balance, 0),
+	       GREATEST(COALESCE(l.due_charges, 0) + COALESCE(l.credits, 0), 0)
+	FROM client_accounts ca
+	JOIN clients c ON c.id = ca.client_id
+	LEFT JOIN (
+	    SELECT client_id,
+	           SUM(amount) AS balance,
+	           SUM(amount) FILTER (WHERE entry_type = 'charge' AND due_at < $1) AS due_charges,
+	           SUM(amount) FILTER (WHERE amount < 0) AS credits
+	    FROM client_account_entries
+	    GROUP BY client_id
+	) l ON l.client_id = ca.client_id`
+
+func scanClientAccount(row scanner) (*models.ClientAccount, error) {
+	var account models.ClientAccount
+	err := row.Scan(&account.ClientID, &account.ClientName, &account.CreditLimit, &account.PaymentTermsDays,
+		&account.IsActive, &account.CreatedAt, &account.UpdatedAt, &account.Version,
+		&account.Balance, &account.OverdueAmount)
+	if err != nil {
+		return nil, err
+	}
+	account.AvailableCredit = account.CreditLimit.Sub(account.Balance)
+	if account.AvailableCredit.IsNegative() {
+		account.AvailableCredit = models.ZeroMoney
+	}
+	account.ChargesBlocked = !account.IsActive || account.OverdueAmount.IsPositive()
+	return &account, nil
+}
+
+func (r *clientAccountRepository) CreateAccount(executor SQLExecutor, account *models.ClientAccount) error {
+	now := time.Now().UTC()
+	err := executor.QueryRow(`INSERT INTO client_accounts (client_id, credit_limit, payment_terms_days, is_active, created_at, updated_at)
+	                          VALUES ($1, $2, $3, $4, $5, $5)
+	                          RETURNING created_at, updated_at, version`,
+		account.ClientID, account.CreditLimit, account.PaymentTermsDays, account.IsActive, now,
+	).Scan(&account.CreatedAt, &account.UpdatedAt, &account.Version)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: client ID %d already has a house account", ErrDuplicateKey, account.ClientID)
+		}
+		return fmt.Errorf("%w: creating house account of client ID %d: %v", ErrDatabaseError, account.ClientID, err)
+	}
+	return nil
+}
+
+func (r *clientAccountRepository) GetAccount(executor SQLExecutor, clientID int64, now time.Time, lock bool) (*models.ClientAccount, error) {
+	if lock {
+		// Lock the account row only; FOR UPDATE cannot lock the aggregated ledger
+		var locked int64
+		err := executor.QueryRow(`SELECT client_id FROM client_accounts WHERE client_id = $1 FOR UPDATE`, clientID).Scan(&locked)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("%w: locking house account of client ID %d: %v", ErrDatabaseError, clientID, err)
+		}
+	}
+	account, err := scanClientAccount(executor.QueryRow(clientAccountSelect+` WHERE ca.client_id = $2`, now, clientID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting house account of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	return account, nil
+}
+
+func (r *clientAccountRepository) GetAccounts(now time.Time, overdueOnly bool) ([]models.ClientAccount, error) {
+	rows, err := r.db.Query(clientAccountSelect+`
+	                        WHERE NOT $2 OR COALESCE(l.due_charges, 0) + COALESCE(l.credits, 0) > 0
+	                        ORDER BY c.full_name, ca.client_id`, now, overdueOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing house accounts: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	accounts := []models.ClientAccount{}
+	for rows.Next() {
+		account, err := scanClientAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning house account: %v", ErrDatabaseError, err)
+		}
+		accounts = append(accounts, *account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating house accounts: %v", ErrDatabaseError, err)
+	}
+	return accounts, nil
+}
+
+func (r *clientAccountRepository) UpdateAccount(executor SQLExecutor, account *models.ClientAccount, version int) error {
+	err := executor.QueryRow(`UPDATE client_accounts
+	                          SET credit_limit = $2, payment_terms_days = $3, is_active = $4, updated_at = $5, version = version + 1
+	                          WHERE client_id = $1 AND ($6 = 0 OR version = $6)
+	                          RETURNING updated_at, version`,
+		account.ClientID, account.CreditLimit, account.PaymentTermsDays, account.IsActive, time.Now().UTC(), version,
+	).Scan(&account.UpdatedAt, &account.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// versionMismatchError looks rows up by id; accounts are keyed by client_id
+			var exists bool
+			if err := executor.QueryRow(`SELECT EXISTS (SELECT 1 FROM client_accounts WHERE client_id = $1)`, account.ClientID).Scan(&exists); err != nil {
+				return fmt.Errorf("%w: checking house account of client ID %d after version mismatch: %v", ErrDatabaseError, account.ClientID, err)
+			}
+			if !exists {
+				return ErrNotFound
+			}
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("%w: updating house account of client ID %d: %v", ErrDatabaseError, account.ClientID, err)
+	}
+	return nil
+}
+
+const clientAccountEntryColumns = `id, client_id, entry_type, amount, order_id, payment_method, notes, due_at, created_by, created_at`
+
+func (r *clientAccountRepository) CreateEntry(executor SQLExecutor, entry *models.ClientAccountEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	err := executor.QueryRow(`INSERT INTO client_account_entries (client_id, entry_type, amount, order_id, payment_method, notes, due_at, created_by, created_at)
+	                          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	                          RETURNING id`,
+		entry.ClientID, entry.EntryType, entry.Amount, entry.OrderID, entry.PaymentMethod, entry.Notes, entry.DueAt,
+		entry.CreatedBy, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("%w: recording %s of house account of client ID %d: %v", ErrDatabaseError, entry.EntryType, entry.ClientID, err)
+	}
+	return nil
+}
+
+func (r *clientAccountRepository) GetEntries(clientID int64, from, to time.Time) ([]models.ClientAccountEntry, error) {
+	rows, err := r.db.Query(`SELECT `+clientAccountEntryColumns+` FROM client_account_entries
+	                         WHERE client_id = $1 AND created_at >= $2 AND created_at < $3
+	                         ORDER BY created_at, id`, clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing entries of house account of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	defer rows.Close()
+
+	entries := []models.ClientAccountEntry{}
+	for rows.Next() {
+		var entry models.ClientAccountEntry
+		err := rows.Scan(&entry.ID, &entry.ClientID, &entry.EntryType, &entry.Amount, &entry.OrderID,
+			&entry.PaymentMethod, &entry.Notes, &entry.DueAt, &entry.CreatedBy, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning house account entry: %v", ErrDatabaseError, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating house account entries: %v", ErrDatabaseError, err)
+	}
+	return entries, nil
+}
+
+func (r *clientAccountRepository) GetBalanceAt(clientID int64, at time.Time) (models.Money, error) {
+	var balance models.Money
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM client_account_entries
+	                      WHERE client_id = $1 AND created_at < $2`, clientID, at).Scan(&balance)
+	if err != nil {
+		return models.ZeroMoney, fmt.Errorf("%w: getting balance of house account of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	return balance, nil
+}
+
+func (r *clientAccountRepository) GetOrderBalance(executor SQLExecutor, orderID int64) (int64, models.Money, error) {
+	var clientID int64
+	var amount models.Money
+	err := executor.QueryRow(`SELECT client_id, SUM(amount) FROM client_account_entries
+	                          WHERE order_id = $1
+	                          GROUP BY client_id`, orderID).Scan(&clientID, &amount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ZeroMoney, ErrNotFound
+		}
+		return 0, models.ZeroMoney, fmt.Errorf("%w: getting house account charge of order ID %d: %v", ErrDatabaseError, orderID, err)
+	}
+	return clientID, amount, nil
+}
@@ -0,0 +1,182 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ClientDocumentRepository defines the database operations for the documents and ID verification of clients.
+type ClientDocumentRepository interface {
+	// AddDocument stores a document of a client with its photo; ErrNotFound if there is no such client.
+	AddDocument(document *models.ClientDocument, photo *models.Photo) error
+	// GetDocuments lists the documents of a client, without their data, newest first.
+	GetDocuments(clientID int64) ([]models.ClientDocument, error)
+	// GetDocumentByID returns a document of a client without its data; ErrNotFound if the client has no such document.
+	GetDocumentByID(clientID, documentID int64) (*models.ClientDocument, error)
+	// GetDocumentPhoto returns the photo of a document; ErrNotFound if the client has no such document.
+	GetDocumentPhoto(clientID, documentID int64) (*models.Photo, error)
+	DeleteDocument(clientID, documentID int64) error
+
+	// GetIDVerification returns the ID verification of a client; ErrNotFound if it was not verified.
+	GetIDVerification(clientID int64) (*models.ClientIDVerification, error)
+	// SaveIDVerification inserts or replaces the ID verification of a client.
+	SaveIDVerification(verification *models.ClientIDVerification) error
+	DeleteIDVerification(clientID int64) error
+}
+
+type clientDocumentRepository struct {
+	db *sql.DB
+}
+
+// NewClientDocumentRepository creates a new instance of ClientDocumentRepository.
+func NewClientDocumentRepository(db *sql.DB) ClientDocumentRepository {
+	return &clientDocumentRepository{db: db}
+}
+
+const clientDocumentColumns = `id, client_id, document_type, content_type, octet_length(data), expires_at, uploaded_by, created_at`
+
+func scanClientDocument(row scanner) (*models.ClientDocument, error) {
+	var document models.ClientDocument
+	err := row.Scan(&document.ID, &document.ClientID, &document.DocumentType, &document.ContentType, &document.Size,
+		&document.ExpiresAt, &document.UploadedBy, &document.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *clientDocumentRepository) AddDocument(document *models.ClientDocument, photo *models.Photo) error {
+	document.ContentType = photo.ContentType
+	document.Size = len(photo.Data)
+	err := r.db.QueryRow(`INSERT INTO client_documents (client_id, document_type, content_type, data, expires_at, uploaded_by, created_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7)
+	                      RETURNING id, created_at`,
+		document.ClientID, document.DocumentType, photo.ContentType, photo.Data, document.ExpiresAt, document.UploadedBy, time.Now().UTC(),
+	).Scan(&document.ID, &document.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "client_documents_client_id_fkey" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: adding document to client ID %d: %v", ErrDatabaseError, document.ClientID, err)
+	}
+	return nil
+}
+
+func (r *clientDocumentRepository) GetDocuments(clientID int64) ([]models.ClientDocument, error) {
+	rows, err := r.db.Query(`SELECT `+clientDocumentColumns+`
+	                         FROM client_documents
+	                         WHERE client_id = $1
+	                         ORDER BY created_at DESC, id DESC`, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing documents of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	defer rows.Close()
+
+	documents := []models.ClientDocument{}
+	for rows.Next() {
+		document, err := scanClientDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning client document: %v", ErrDatabaseError, err)
+		}
+		documents = append(documents, *document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating client documents: %v", ErrDatabaseError, err)
+	}
+	return documents, nil
+}
+
+func (r *clientDocumentRepository) GetDocumentByID(clientID, documentID int64) (*models.ClientDocument, error) {
+	document, err := scanClientDocument(r.db.QueryRow(`SELECT `+clientDocumentColumns+`
+	                                                   FROM client_documents
+	                                                   WHERE id = $1 AND client_id = $2`, documentID, clientID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting document ID %d of client ID %d: %v", ErrDatabaseError, documentID, clientID, err)
+	}
+	return document, nil
+}
+
+func (r *clientDocumentRepository) GetDocumentPhoto(clientID, documentID int64) (*models.Photo, error) {
+	var photo models.Photo
+	err := r.db.QueryRow(`SELECT content_type, data FROM client_documents WHERE id = $1 AND client_id = $2`, documentID, clientID).
+		Scan(&photo.ContentType, &photo.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting photo of document ID %d of client ID %d: %v", ErrDatabaseError, documentID, clientID, err)
+	}
+	return &photo, nil
+}
+
+func (r *clientDocumentRepository) DeleteDocument(clientID, documentID int64) error {
+	result, err := r.db.Exec(`DELETE FROM client_documents WHERE id = $1 AND client_id = $2`, documentID, clientID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting document ID %d of client ID %d: %v", ErrDatabaseError, documentID, clientID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for document ID %d of client ID %d: %v", ErrDatabaseError, documentID, clientID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *clientDocumentRepository) GetIDVerification(clientID int64) (*models.ClientIDVerification, error) {
+	var verification models.ClientIDVerification
+	err := r.db.QueryRow(`SELECT client_id, document_id, verified_by, verified_at, expires_at
+	                      FROM client_id_verifications
+	                      WHERE client_id = $1`, clientID).
+		Scan(&verification.ClientID, &verification.DocumentID, &verification.VerifiedBy, &verification.VerifiedAt, &verification.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting ID verification of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	return &verification, nil
+}
+
+func (r *clientDocumentRepository) SaveIDVerification(verification *models.ClientIDVerification) error {
+	_, err := r.db.Exec(`INSERT INTO client_id_verifications (client_id, document_id, verified_by, verified_at, expires_at)
+	                     VALUES ($1, $2, $3, $4, $5)
+	                     ON CONFLICT (client_id) DO UPDATE
+	                     SET document_id = EXCLUDED.document_id, verified_by = EXCLUDED.verified_by,
+	                         verified_at = EXCLUDED.verified_at, expires_at = EXCLUDED.expires_at`,
+		verification.ClientID, verification.DocumentID, verification.VerifiedBy, verification.VerifiedAt, verification.ExpiresAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "client_id_verifications_client_id_fkey" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: saving ID verification of client ID %d: %v", ErrDatabaseError, verification.ClientID, err)
+	}
+	return nil
+}
+
+func (r *clientDocumentRepository) DeleteIDVerification(clientID int64) error {
+	result, err := r.db.Exec(`DELETE FROM client_id_verifications WHERE client_id = $1`, clientID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting ID verification of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for ID verification of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
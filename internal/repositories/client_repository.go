@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq" // For pq.Error
@@ -16,15 +18,91 @@ type ClientRepository interface {
 	CreateClient(executor SQLExecutor, client *models.Client) (int64, error)
 	GetClientByID(id int64) (*models.Client, error)
 	GetClientByPhoneNumber(phoneNumber string) (*models.Client, error)
-	GetClients(page, pageSize int, searchTerm *string) ([]models.Client, int, error) // Clients, total count, error
+	// GetClients lists clients by name, optionally only those matching searchTerm and having tag.
+	GetClients(page, pageSize int, searchTerm, tag *string) ([]models.Client, int, error) // Clients, total count, error
+	// FindDuplicateCandidates lists up to limit clients, not anonymized, with the (normalized)
+	// phoneNumber, the email, or a name at least minSimilarity similar to fullName, best matches
+	// first. Empty phoneNumber or email match nothing.
+	FindDuplicateCandidates(fullName, phoneNumber, email string, minSimilarity float64, limit int) ([]models.ClientDuplicateCandidate, error)
 	UpdateClient(executor SQLExecutor, client *models.Client) error
-	DeleteClient(executor SQLExecutor, id int64) error
+	// DeleteClient soft deletes a client, which is then left out everywhere until restored.
+	// ErrNotFound if the client does not exist or is already deleted.
+	DeleteClient(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error
+	// RestoreClient undoes the soft delete of a client. ErrNotFound if the client is not
+	// deleted; a ConstraintError wrapping ErrDuplicateKey if another client has its phone
+	// number or email since.
+	RestoreClient(executor SQLExecutor, id int64, now time.Time) error
+	// AnonymizeClient scrubs the personal data of a client, its documents and the free-text
+	// notes of its bookings and orders, keeping the rows and amounts. ErrNotFound if the
+	// client does not exist or is already anonymized.
+	AnonymizeClient(executor SQLExecutor, id int64, placeholderName string, now time.Time) error
+	// SetBlacklist puts a client on the blacklist until until, or until lifted if nil,
+	// replacing any blacklist it is on. ErrNotFound if the client does not exist.
+	SetBlacklist(executor SQLExecutor, id int64, reason string, until *time.Time, blacklistedBy int64, now time.Time) error
+	// ClearBlacklist lifts the blacklist of a client; ErrNotFound if it is not on it.
+	ClearBlacklist(executor SQLExecutor, id int64, now time.Time) error
+	// ReencryptNotes stores the notes of clients encrypted with the current key, after a key
+	// rotation or once SetClientNotesEncryption is enabled, and returns how many it changed.
+	ReencryptNotes() (int, error)
+}
+
+var (
+	encryptClientNotes   bool
+	encryptClientNotesMu sync.RWMutex
+)
+
+// SetClientNotesEncryption sets whether the notes of clients are stored encrypted. Encrypted
+// notes are decrypted when read either way.
+func SetClientNotesEncryption(enabled bool) {
+	encryptClientNotesMu.Lock()
+	encryptClientNotes = enabled
+	encryptClientNotesMu.Unlock()
+}
+
+func clientNotesEncrypted() bool {
+	encryptClientNotesMu.RLock()
+	defer encryptClientNotesMu.RUnlock()
+	return encryptClientNotes
+}
+
+// storedClientNotes returns the notes of a client as stored: encrypted if enabled.
+func storedClientNotes(notes *string) (*string, error) {
+	if notes == nil || !clientNotesEncrypted() {
+		return notes, nil
+	}
+	encrypted, err := utils.EncryptString(*notes)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting client notes: %w", err)
+	}
+	return &encrypted, nil
+}
+
+// decryptClientNotes decrypts the scanned notes of a client in place.
+func decryptClientNotes(notes *string) error {
+	if notes == nil {
+		return nil
+	}
+	plain, err := utils.DecryptString(*notes)
+	if err != nil {
+		return fmt.Errorf("decrypting client notes: %w", err)
+	}
+	*notes = plain
+	return nil
 }
 
 type clientRepository struct {
 	db *sql.DB
 }
 
+// clientTagsColumn selects the tags of the client of the row, sorted.
+const clientTagsColumn = `COALESCE((SELECT ARRAY_AGG(ct.tag ORDER BY ct.tag) FROM client_tags ct WHERE ct.client_id = clients.id), '{}')`
+
+// clientBlacklistedColumn selects whether the client of the row is blacklisted now.
+const clientBlacklistedColumn = `(blacklisted_at IS NOT NULL AND (blacklisted_until IS NULL OR blacklisted_until > NOW()))`
+
+// clientBlacklistColumns selects clientBlacklistedColumn, then the blacklist of the client of the row.
+const clientBlacklistColumns = clientBlacklistedColumn + `, blacklisted_at, blacklist_reason, blacklisted_until, blacklisted_by`
+
 // NewClientRepository creates a new instance of ClientRepository.
 func NewClientRepository(db *sql.DB) ClientRepository {
 	return &clientRepository{db: db}
@@ -36,7 +114,7 @@ func (r *clientRepository) CreateClient(executor SQLExecutor, client *models.Cli
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	          RETURNING id`
 
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	if client.CreatedAt.IsZero() {
 		client.CreatedAt = currentTime
 	}
@@ -54,16 +132,20 @@ func (r *clientRepository) CreateClient(executor SQLExecutor, client *models.Cli
 		// If parsing fails, dobArg remains invalid (NULL for DB), or an error could be returned.
 	}
 
-	err := executor.QueryRow(query,
+	notes, err := storedClientNotes(client.Notes)
+	if err != nil {
+		return 0, err
+	}
+	err = executor.QueryRow(query,
 		client.FullName, client.PhoneNumber, client.Email, dobArg, // Use dobArg
-		client.LoyaltyPoints, client.Notes, client.CreatedAt, client.UpdatedAt,
+		client.LoyaltyPoints, notes, client.CreatedAt, client.UpdatedAt,
 	).Scan(&client.ID)
 
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code.Name() == "unique_violation" {
-				return 0, fmt.Errorf("%w: %s (constraint: %s)", ErrDuplicateKey, pqErr.Message, pqErr.Constraint)
+				return 0, &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: pqErr.Message}
 			}
 		}
 		return 0, fmt.Errorf("%w: creating client: %v", ErrDatabaseError, err)
@@ -73,14 +155,17 @@ func (r *clientRepository) CreateClient(executor SQLExecutor, client *models.Cli
 
 // GetClientByID retrieves a client by their ID.
 func (r *clientRepository) GetClientByID(id int64) (*models.Client, error) {
-	client := &models.Client{}
-	query := `SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at 
-	          FROM clients WHERE id = $1`
+	client := &models.Client{Tags: []string{}} // Kept non-nil when the client has no tags
+	query := `SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at, anonymized_at,
+	                 ` + clientTagsColumn + `, ` + clientBlacklistColumns + `
+	          FROM clients WHERE id = $1 AND deleted_at IS NULL`
 	
 	var dob sql.NullTime
 	err := r.db.QueryRow(query, id).Scan(
 		&client.ID, &client.FullName, &client.PhoneNumber, &client.Email, &dob,
-		&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt,
+		&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt, &client.AnonymizedAt,
+		pq.Array(&client.Tags), &client.Blacklisted, &client.BlacklistedAt, &client.BlacklistReason, &client.BlacklistedUntil,
+		&client.BlacklistedBy,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -88,6 +173,9 @@ func (r *clientRepository) GetClientByID(id int64) (*models.Client, error) {
 		}
 		return nil, fmt.Errorf("%w: getting client by ID %d: %v", ErrDatabaseError, id, err)
 	}
+	if err := decryptClientNotes(client.Notes); err != nil {
+		return nil, err
+	}
 	if dob.Valid {
 		dateStr := dob.Time.Format("2006-01-02")
 		client.DateOfBirth = &dateStr
@@ -99,14 +187,17 @@ func (r *clientRepository) GetClientByID(id int64) (*models.Client, error) {
 
 // GetClientByPhoneNumber retrieves a client by their phone number.
 func (r *clientRepository) GetClientByPhoneNumber(phoneNumber string) (*models.Client, error) {
-	client := &models.Client{}
-	query := `SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at 
-	          FROM clients WHERE phone_number = $1`
+	client := &models.Client{Tags: []string{}} // Kept non-nil when the client has no tags
+	query := `SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at, anonymized_at,
+	                 ` + clientTagsColumn + `, ` + clientBlacklistColumns + `
+	          FROM clients WHERE phone_number = $1 AND deleted_at IS NULL`
 	
 	var dob sql.NullTime
 	err := r.db.QueryRow(query, phoneNumber).Scan(
 		&client.ID, &client.FullName, &client.PhoneNumber, &client.Email, &dob,
-		&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt,
+		&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt, &client.AnonymizedAt,
+		pq.Array(&client.Tags), &client.Blacklisted, &client.BlacklistedAt, &client.BlacklistReason, &client.BlacklistedUntil,
+		&client.BlacklistedBy,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -114,6 +205,9 @@ func (r *clientRepository) GetClientByPhoneNumber(phoneNumber string) (*models.C
 		}
 		return nil, fmt.Errorf("%w: getting client by phone number %s: %v", ErrDatabaseError, phoneNumber, err)
 	}
+	if err := decryptClientNotes(client.Notes); err != nil {
+		return nil, err
+	}
 	if dob.Valid {
 		dateStr := dob.Time.Format("2006-01-02")
 		client.DateOfBirth = &dateStr
@@ -124,28 +218,34 @@ func (r *clientRepository) GetClientByPhoneNumber(phoneNumber string) (*models.C
 }
 
 // GetClients retrieves a list of clients with pagination and optional search.
-func (r *clientRepository) GetClients(page, pageSize int, searchTerm *string) ([]models.Client, int, error) {
+func (r *clientRepository) GetClients(page, pageSize int, searchTerm, tag *string) ([]models.Client, int, error) {
 	clients := []models.Client{}
 	totalCount := 0
 
 	var queryBuilder strings.Builder
-	queryBuilder.WriteString(`SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at, COUNT(*) OVER() as total_count 
+	queryBuilder.WriteString(`SELECT id, full_name, phone_number, email, date_of_birth, loyalty_points, notes, created_at, updated_at, anonymized_at,
+	                                 ` + clientTagsColumn + `, ` + clientBlacklistColumns + `, COUNT(*) OVER() as total_count
 	                          FROM clients`)
 
-	var conditions []string
+	conditions := []string{"deleted_at IS NULL"}
 	var args []interface{}
 	argCount := 1
 
 	if searchTerm != nil && *searchTerm != "" {
 		searchPattern := "%" + strings.ToLower(*searchTerm) + "%"
-		conditions = append(conditions, fmt.Sprintf("(LOWER(full_name) ILIKE $%d OR LOWER(phone_number) ILIKE $%d OR LOWER(email) ILIKE $%d)", argCount, argCount, argCount))
-		args = append(args, searchPattern)
+		// A whole phone number is also looked up as stored, in E.164 form
+		phoneNumber, _ := utils.NormalizePhone(*searchTerm)
+		conditions = append(conditions, fmt.Sprintf("(LOWER(full_name) ILIKE $%d OR LOWER(phone_number) ILIKE $%d OR LOWER(email) ILIKE $%d OR phone_number = NULLIF($%d, ''))", argCount, argCount, argCount, argCount+1))
+		args = append(args, searchPattern, phoneNumber)
+		argCount += 2
+	}
+	if tag != nil && *tag != "" {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM client_tags ct WHERE ct.client_id = clients.id AND ct.tag = $%d)", argCount))
+		args = append(args, *tag)
 		argCount++
 	}
 
-	if len(conditions) > 0 {
-		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
-	}
+	queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
 
 	queryBuilder.WriteString(" ORDER BY full_name ASC") 
 
@@ -167,14 +267,18 @@ func (r *clientRepository) GetClients(page, pageSize int, searchTerm *string) ([
 	defer rows.Close()
 
 	for rows.Next() {
-		var client models.Client
+		client := models.Client{Tags: []string{}}
 		var dob sql.NullTime
 		if err := rows.Scan(
 			&client.ID, &client.FullName, &client.PhoneNumber, &client.Email, &dob,
-			&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt, &totalCount,
+			&client.LoyaltyPoints, &client.Notes, &client.CreatedAt, &client.UpdatedAt, &client.AnonymizedAt, pq.Array(&client.Tags),
+			&client.Blacklisted, &client.BlacklistedAt, &client.BlacklistReason, &client.BlacklistedUntil, &client.BlacklistedBy, &totalCount,
 		); err != nil {
 			return nil, 0, fmt.Errorf("%w: scanning client: %v", ErrDatabaseError, err)
 		}
+		if err := decryptClientNotes(client.Notes); err != nil {
+			return nil, 0, err
+		}
 		if dob.Valid {
 			dateStr := dob.Time.Format("2006-01-02")
 			client.DateOfBirth = &dateStr
@@ -200,9 +304,9 @@ func (r *clientRepository) UpdateClient(executor SQLExecutor, client *models.Cli
 	query := `UPDATE clients SET 
 	            full_name = $1, phone_number = $2, email = $3, date_of_birth = $4, 
 	            loyalty_points = $5, notes = $6, updated_at = $7 
-	          WHERE id = $8`
+	          WHERE id = $8 AND deleted_at IS NULL`
 	
-	client.UpdatedAt = time.Now()
+	client.UpdatedAt = time.Now().UTC()
 	var dobArg sql.NullTime
 	if client.DateOfBirth != nil && *client.DateOfBirth != "" {
 		parsedTime, err := time.Parse("2006-01-02", *client.DateOfBirth)
@@ -213,15 +317,19 @@ func (r *clientRepository) UpdateClient(executor SQLExecutor, client *models.Cli
 		// else { return fmt.Errorf("invalid date_of_birth format: %s", *client.DateOfBirth) }
 	}
 
+	notes, err := storedClientNotes(client.Notes)
+	if err != nil {
+		return err
+	}
 	result, err := executor.Exec(query,
 		client.FullName, client.PhoneNumber, client.Email, dobArg, // Use dobArg
-		client.LoyaltyPoints, client.Notes, client.UpdatedAt, client.ID,
+		client.LoyaltyPoints, notes, client.UpdatedAt, client.ID,
 	)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code.Name() == "unique_violation" {
-				return fmt.Errorf("%w: %s (constraint: %s)", ErrDuplicateKey, pqErr.Message, pqErr.Constraint)
+				return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: pqErr.Message}
 			}
 		}
 		return fmt.Errorf("%w: updating client ID %d: %v", ErrDatabaseError, client.ID, err)
@@ -236,15 +344,11 @@ func (r *clientRepository) UpdateClient(executor SQLExecutor, client *models.Cli
 	return nil
 }
 
-// DeleteClient removes a client from the database.
-func (r *clientRepository) DeleteClient(executor SQLExecutor, id int64) error {
-	query := `DELETE FROM clients WHERE id = $1`
-	result, err := executor.Exec(query, id)
+// DeleteClient soft deletes a client, keeping its orders and bookings linked to it.
+func (r *clientRepository) DeleteClient(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	query := `UPDATE clients SET deleted_at = $2, deleted_by = $3, updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	result, err := executor.Exec(query, id, now, deletedBy)
 	if err != nil {
-		var pqErr *pq.Error
-		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
-			return fmt.Errorf("%w: client ID %d cannot be deleted as it is referenced by other records (e.g., orders) (constraint: %s)", ErrDatabaseError, id, pqErr.Constraint)
-		}
 		return fmt.Errorf("%w: deleting client ID %d: %v", ErrDatabaseError, id, err)
 	}
 	rowsAffected, err := result.RowsAffected()
@@ -256,3 +360,191 @@ func (r *clientRepository) DeleteClient(executor SQLExecutor, id int64) error {
 	}
 	return nil
 }
+
+// RestoreClient undoes the soft delete of a client; its phone number and email must still be unique.
+func (r *clientRepository) RestoreClient(executor SQLExecutor, id int64, now time.Time) error {
+	query := `UPDATE clients SET deleted_at = NULL, deleted_by = NULL, updated_at = $2 WHERE id = $1 AND deleted_at IS NOT NULL`
+	result, err := executor.Exec(query, id, now)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: pqErr.Message}
+		}
+		return fmt.Errorf("%w: restoring client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for restoring client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AnonymizeClient scrubs the client row, then the notes of its bookings and orders; run it in a transaction.
+func (r *clientRepository) AnonymizeClient(executor SQLExecutor, id int64, placeholderName string, now time.Time) error {
+	result, err := executor.Exec(`UPDATE clients SET
+	            full_name = $1, phone_number = NULL, email = NULL, date_of_birth = NULL,
+	            loyalty_points = 0, notes = NULL, blacklist_reason = NULL, anonymized_at = $2, updated_at = $2
+	          WHERE id = $3 AND anonymized_at IS NULL`, placeholderName, now, id)
+	if err != nil {
+		return fmt.Errorf("%w: anonymizing client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for anonymizing client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := executor.Exec(`UPDATE bookings SET notes = NULL WHERE client_id = $1 AND notes IS NOT NULL`, id); err != nil {
+		return fmt.Errorf("%w: clearing booking notes of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if _, err := executor.Exec(`UPDATE orders SET notes = NULL WHERE client_id = $1 AND notes IS NOT NULL`, id); err != nil {
+		return fmt.Errorf("%w: clearing order notes of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if _, err := executor.Exec(`DELETE FROM client_id_verifications WHERE client_id = $1`, id); err != nil {
+		return fmt.Errorf("%w: deleting ID verification of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if _, err := executor.Exec(`DELETE FROM client_push_devices WHERE client_id = $1`, id); err != nil {
+		return fmt.Errorf("%w: deleting push devices of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if _, err := executor.Exec(`DELETE FROM client_reminder_preferences WHERE client_id = $1`, id); err != nil {
+		return fmt.Errorf("%w: deleting reminder preferences of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if _, err := executor.Exec(`DELETE FROM client_documents WHERE client_id = $1`, id); err != nil {
+		return fmt.Errorf("%w: deleting documents of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
+
+func (r *clientRepository) SetBlacklist(executor SQLExecutor, id int64, reason string, until *time.Time, blacklistedBy int64, now time.Time) error {
+	result, err := executor.Exec(`UPDATE clients
+	                              SET blacklisted_at = $2, blacklist_reason = $3, blacklisted_until = $4, blacklisted_by = $5, updated_at = $2
+	                              WHERE id = $1 AND deleted_at IS NULL`, id, now, reason, until, blacklistedBy)
+	if err != nil {
+		return fmt.Errorf("%w: blacklisting client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *clientRepository) ClearBlacklist(executor SQLExecutor, id int64, now time.Time) error {
+	result, err := executor.Exec(`UPDATE clients
+	                              SET blacklisted_at = NULL, blacklist_reason = NULL, blacklisted_until = NULL, blacklisted_by = NULL, updated_at = $2
+	                              WHERE id = $1 AND blacklisted_at IS NOT NULL`, id, now)
+	if err != nil {
+		return fmt.Errorf("%w: lifting blacklist of client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for client ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *clientRepository) FindDuplicateCandidates(fullName, phoneNumber, email string, minSimilarity float64, limit int) ([]models.ClientDuplicateCandidate, error) {
+	// % finds the similar names through the trigram index; the threshold is checked after
+	rows, err := r.db.Query(`SELECT id, full_name, phone_number, email, name_similarity, phone_match, email_match
+	                         FROM (
+	                             SELECT id, full_name, phone_number, email,
+	                                    SIMILARITY(LOWER(full_name), LOWER($1)) AS name_similarity,
+	                                    COALESCE($2 <> '' AND phone_number = $2, FALSE) AS phone_match,
+	                                    COALESCE($3 <> '' AND LOWER(email) = LOWER($3), FALSE) AS email_match
+	                             FROM clients
+	                             WHERE anonymized_at IS NULL AND deleted_at IS NULL
+	                               AND (LOWER(full_name) % LOWER($1)
+	                                    OR phone_number = $2
+	                                    OR LOWER(email) = LOWER($3))
+	                         ) c
+	                         WHERE phone_match OR email_match OR name_similarity >= $4
+	                         ORDER BY phone_match DESC, email_match DESC, name_similarity DESC, id
+	                         LIMIT $5`, fullName, phoneNumber, email, minSimilarity, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: finding duplicate clients: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	candidates := []models.ClientDuplicateCandidate{}
+	for rows.Next() {
+		var candidate models.ClientDuplicateCandidate
+		var phoneMatch, emailMatch bool
+		if err := rows.Scan(&candidate.ID, &candidate.FullName, &candidate.PhoneNumber, &candidate.Email,
+			&candidate.NameSimilarity, &phoneMatch, &emailMatch); err != nil {
+			return nil, fmt.Errorf("%w: scanning duplicate client: %v", ErrDatabaseError, err)
+		}
+		candidate.MatchedOn = []string{}
+		if phoneMatch {
+			candidate.MatchedOn = append(candidate.MatchedOn, "phone")
+		}
+		if emailMatch {
+			candidate.MatchedOn = append(candidate.MatchedOn, "email")
+		}
+		if candidate.NameSimilarity >= minSimilarity {
+			candidate.MatchedOn = append(candidate.MatchedOn, "name")
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating duplicate clients: %v", ErrDatabaseError, err)
+	}
+	return candidates, nil
+}
+
+func (r *clientRepository) ReencryptNotes() (int, error) {
+	if !clientNotesEncrypted() {
+		return 0, nil
+	}
+	rows, err := r.db.Query(`SELECT id, notes FROM clients WHERE notes IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("%w: listing client notes: %v", ErrDatabaseError, err)
+	}
+	stored := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var notes string
+		if err := rows.Scan(&id, &notes); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%w: scanning client notes: %v", ErrDatabaseError, err)
+		}
+		if utils.NeedsReencryption(notes) {
+			stored[id] = notes
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%w: iterating client notes: %v", ErrDatabaseError, err)
+	}
+
+	changed := 0
+	for id, notes := range stored {
+		plain, err := utils.DecryptString(notes)
+		if err != nil {
+			return changed, fmt.Errorf("decrypting notes of client ID %d: %w", id, err)
+		}
+		encrypted, err := utils.EncryptString(plain)
+		if err != nil {
+			return changed, fmt.Errorf("encrypting notes of client ID %d: %w", id, err)
+		}
+		// Notes saved meanwhile are kept; updated_at is left alone as the notes did not change
+		result, err := r.db.Exec(`UPDATE clients SET notes = $1 WHERE id = $2 AND notes = $3`, encrypted, id, notes)
+		if err != nil {
+			return changed, fmt.Errorf("%w: re-encrypting notes of client ID %d: %v", ErrDatabaseError, id, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			changed++
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ClientTagRepository defines the database operations for the tags of clients.
+type ClientTagRepository interface {
+	// SetTags replaces the tags of a client with tags.
+	SetTags(executor SQLExecutor, clientID int64, tags []string) error
+	// AddTags adds tags to a client, keeping those it has.
+	AddTags(executor SQLExecutor, clientID int64, tags []string) error
+	// RemoveTag removes a tag from a client; ErrNotFound if the client does not have it.
+	RemoveTag(executor SQLExecutor, clientID int64, tag string) error
+	// GetTagCounts lists the tags in use with the number of clients having each, by tag.
+	GetTagCounts() ([]models.ClientTagCount, error)
+	// GetTagReport sums up the orders with orderStatuses and the bookings of the clients of each
+	// tag, or only of tag if set, in [from, to), by tag.
+	GetTagReport(from, to time.Time, tag *string, orderStatuses []string) ([]models.ClientTagReportItem, error)
+}
+
+type clientTagRepository struct {
+	db *sql.DB
+}
+
+// NewClientTagRepository creates a new instance of ClientTagRepository.
+func NewClientTagRepository(db *sql.DB) ClientTagRepository {
+	return &clientTagRepository{db: db}
+}
+
+func (r *clientTagRepository) SetTags(executor SQLExecutor, clientID int64, tags []string) error {
+	if _, err := executor.Exec(`DELETE FROM client_tags WHERE client_id = $1 AND tag <> ALL($2)`, clientID, pq.Array(tags)); err != nil {
+		return fmt.Errorf("%w: removing tags of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	return r.AddTags(executor, clientID, tags)
+}
+
+func (r *clientTagRepository) AddTags(executor SQLExecutor, clientID int64, tags []string) error {
+	_, err := executor.Exec(`INSERT INTO client_tags (client_id, tag)
+	                         SELECT $1, UNNEST($2::text[])
+	                         ON CONFLICT (client_id, tag) DO NOTHING`, clientID, pq.Array(tags))
+	if err != nil {
+		return fmt.Errorf("%w: adding tags to client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	return nil
+}
+
+func (r *clientTagRepository) RemoveTag(executor SQLExecutor, clientID int64, tag string) error {
+	result, err := executor.Exec(`DELETE FROM client_tags WHERE client_id = $1 AND tag = $2`, clientID, tag)
+	if err != nil {
+		return fmt.Errorf("%w: removing tag of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *clientTagRepository) GetTagCounts() ([]models.ClientTagCount, error) {
+	rows, err := r.db.Query(`SELECT tag, COUNT(*) FROM client_tags GROUP BY tag ORDER BY tag`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: counting client tags: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	counts := []models.ClientTagCount{}
+	for rows.Next() {
+		var count models.ClientTagCount
+		if err := rows.Scan(&count.Tag, &count.Clients); err != nil {
+			return nil, fmt.Errorf("%w: scanning client tag count: %v", ErrDatabaseError, err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating client tag counts: %v", ErrDatabaseError, err)
+	}
+	return counts, nil
+}
+
+func (r *clientTagRepository) GetTagReport(from, to time.Time, tag *string, orderStatuses []string) ([]models.ClientTagReportItem, error) {
+	rows, err := r.db.Query(`SELECT ct.tag, COUNT(*), COALESCE(SUM(o.orders), 0), COALESCE(SUM(o.sales), 0),
+	                                COALESCE(SUM(b.bookings), 0)
+	                         FROM client_tags ct
+	                         LEFT JOIN (SELECT client_id, COUNT(*) AS orders, SUM(final_amount) AS sales
+	                                    FROM orders
+	                                    WHERE status = ANY($3) AND order_time >= $1 AND order_time < $2
+	                                      AND business_date BETWEEN $5 AND $6
+	                                    GROUP BY client_id) o ON o.client_id = ct.client_id
+	                         LEFT JOIN (SELECT client_id, COUNT(*) AS bookings
+	                                    FROM bookings
+	                                    WHERE status NOT IN ('cancelled', 'no-show') AND start_time >= $1 AND start_time < $2 AND deleted_at IS NULL
+	                                    GROUP BY client_id) b ON b.client_id = ct.client_id
+	                         WHERE $4::text IS NULL OR ct.tag = $4
+	                         GROUP BY ct.tag
+	                         ORDER BY ct.tag`, from, to, pq.Array(orderStatuses), tag, businessDateFrom(from), businessDateTo(to))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting client tag report: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	items := []models.ClientTagReportItem{}
+	for rows.Next() {
+		var item models.ClientTagReportItem
+		if err := rows.Scan(&item.Tag, &item.Clients, &item.Orders, &item.TotalSales, &item.Bookings); err != nil {
+			return nil, fmt.Errorf("%w: scanning client tag report: %v", ErrDatabaseError, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating client tag report: %v", ErrDatabaseError, err)
+	}
+	return items, nil
+}
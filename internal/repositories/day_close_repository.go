@@ -0,0 +1,331 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// DayCloseRepository defines the database operations for the end-of-day close: the records
+// that keep a day open, the totals of the day and the daily summaries.
+type DayCloseRepository interface {
+	// GetOpenOrders returns the orders of a branch with one of statuses placed before before, oldest first.
+	GetOpenOrders(executor SQLExecutor, branchCode string, statuses []string, before time.Time) ([]models.DayCloseItem, error)
+	// GetRunningSessions returns the table sessions started before before that were not stopped.
+	GetRunningSessions(executor SQLExecutor, before time.Time) ([]models.DayCloseItem, error)
+	// GetClockedInStaff returns the staff members clocked in before before that have not clocked out.
+	GetClockedInStaff(executor SQLExecutor, before time.Time) ([]models.DayCloseItem, error)
+	// GetOrderOutcomes counts the orders of a branch placed in [from, to) that were cancelled
+	// and refunded, and totals the refunds.
+	GetOrderOutcomes(executor SQLExecutor, branchCode string, from, to time.Time) (cancelled, refunded int, refundedTotal models.Money, err error)
+	// GetShiftCashTotals counts the shift reports of a branch that ended in [from, to) and sums
+	// their cash differences.
+	GetShiftCashTotals(executor SQLExecutor, branchCode string, from, to time.Time) (int, models.Money, error)
+	// GetCategorySales totals the items sold per pricelist category in the orders of a branch
+	// with one of statuses placed in [from, to), by amount, highest first.
+	GetCategorySales(executor SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.CategorySales, error)
+	// GetHoursBooked sums the hours of the completed and active bookings that started in [from, to).
+	GetHoursBooked(executor SQLExecutor, from, to time.Time) (float64, error)
+
+	// CreateSummary saves a summary. A summary closing the day replaces a backfilled one; it
+	// returns ErrDuplicateKey if the day is already closed, or for a backfilled summary, if the
+	// day already has a summary.
+	CreateSummary(executor SQLExecutor, summary *models.DailySummary) error
+	// GetSummary returns the summary of a business day (YYYY-MM-DD); ErrNotFound if it is not closed.
+	GetSummary(branchCode, businessDate string) (*models.DailySummary, error)
+	// GetSummaries lists the summaries of a branch for business days in [from, to], latest first;
+	// empty bounds are open.
+	GetSummaries(branchCode, from, to string) ([]models.DailySummary, error)
+	// IsClosedThrough reports whether the branch closed businessDate or a later day; backfilled
+	// summaries do not count.
+	IsClosedThrough(branchCode, businessDate string) (bool, error)
+}
+
+type dayCloseRepository struct {
+	db *sql.DB
+}
+
+// NewDayCloseRepository creates a new instance of DayCloseRepository.
+func NewDayCloseRepository(db *sql.DB) DayCloseRepository {
+	return &dayCloseRepository{db: db}
+}
+
+const dailySummaryColumns = `id, branch_code, business_date, revenue, hours_booked, order_count, sales_total, sales_by_payment,
+	category_sales, cancelled_count, refunded_count, refunded_total, session_count, session_total, cash_sales,
+	cash_account_payments, shift_report_count, cash_difference, forced_closures, force_reason, notes, closed_by, closed_at,
+	backfilled`
+
+func scanDailySummary(row scanner) (*models.DailySummary, error) {
+	var summary models.DailySummary
+	var businessDate time.Time
+	var salesByPayment, categorySales, forcedClosures []byte
+	err := row.Scan(&summary.ID, &summary.BranchCode, &businessDate, &summary.Revenue, &summary.HoursBooked, &summary.OrderCount,
+		&summary.SalesTotal, &salesByPayment, &categorySales, &summary.CancelledCount, &summary.RefundedCount,
+		&summary.RefundedTotal, &summary.SessionCount, &summary.SessionTotal, &summary.CashSales, &summary.CashAccountPayments,
+		&summary.ShiftReportCount, &summary.CashDifference, &forcedClosures, &summary.ForceReason, &summary.Notes,
+		&summary.ClosedBy, &summary.ClosedAt, &summary.Backfilled)
+	if err != nil {
+		return nil, err
+	}
+	summary.BusinessDate = businessDate.Format(utils.DateLayout)
+	summary.SalesByPayment = []models.ShiftPaymentTotal{}
+	if err := json.Unmarshal(salesByPayment, &summary.SalesByPayment); err != nil {
+		return nil, fmt.Errorf("decoding sales by payment: %v", err)
+	}
+	summary.CategorySales = []models.CategorySales{}
+	if err := json.Unmarshal(categorySales, &summary.CategorySales); err != nil {
+		return nil, fmt.Errorf("decoding category sales: %v", err)
+	}
+	summary.ForcedClosures = []models.DayCloseItem{}
+	if err := json.Unmarshal(forcedClosures, &summary.ForcedClosures); err != nil {
+		return nil, fmt.Errorf("decoding forced closures: %v", err)
+	}
+	return &summary, nil
+}
+
+// queryDayCloseItems runs a query selecting the ID and description of open records of type itemType.
+func queryDayCloseItems(executor SQLExecutor, itemType, what, query string, args ...interface{}) ([]models.DayCloseItem, error) {
+	rows, err := executor.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing %s: %v", ErrDatabaseError, what, err)
+	}
+	defer rows.Close()
+
+	items := []models.DayCloseItem{}
+	for rows.Next() {
+		item := models.DayCloseItem{Type: itemType}
+		if err := rows.Scan(&item.ID, &item.Description); err != nil {
+			return nil, fmt.Errorf("%w: scanning %s: %v", ErrDatabaseError, what, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating %s: %v", ErrDatabaseError, what, err)
+	}
+	return items, nil
+}
+
+func (r *dayCloseRepository) GetOpenOrders(executor SQLExecutor, branchCode string, statuses []string, before time.Time) ([]models.DayCloseItem, error) {
+	return queryDayCloseItems(executor, models.DayCloseItemOrder, "open orders",
+		`SELECT id, 'Order ' || TO_CHAR(business_date, 'YYYY-MM-DD') || '/#' || daily_number || ' (' || status || ', ' || final_amount || ')'
+		 FROM orders
+		 WHERE branch_code = $1 AND status = ANY($2) AND order_time < $3 AND business_date <= $4
+		 ORDER BY order_time, id`, branchCode, pq.Array(statuses), before, businessDateTo(before))
+}
+
+func (r *dayCloseRepository) GetRunningSessions(executor SQLExecutor, before time.Time) ([]models.DayCloseItem, error) {
+	return queryDayCloseItems(executor, models.DayCloseItemTableSession, "running table sessions",
+		`SELECT ts.id, 'Table ' || gt.name || ' (' || ts.status || ')'
+		 FROM table_sessions ts
+		 JOIN game_tables gt ON ts.table_id = gt.id
+		 WHERE ts.status <> $1 AND ts.started_at < $2
+		 ORDER BY ts.started_at, ts.id`, models.TableSessionStatusStopped, before)
+}
+
+func (r *dayCloseRepository) GetClockedInStaff(executor SQLExecutor, before time.Time) ([]models.DayCloseItem, error) {
+	return queryDayCloseItems(executor, models.DayCloseItemClockedIn, "clocked-in staff",
+		`SELECT tce.staff_id, COALESCE(NULLIF(u.full_name, ''), u.username, 'Staff #' || tce.staff_id::text)
+		 FROM time_clock_entries tce
+		 JOIN staff_members sm ON tce.staff_id = sm.id
+		 LEFT JOIN users u ON sm.user_id = u.id
+		 WHERE tce.clock_out_at IS NULL AND tce.clock_in_at < $1
+		 ORDER BY tce.clock_in_at, tce.id`, before)
+}
+
+func (r *dayCloseRepository) GetOrderOutcomes(executor SQLExecutor, branchCode string, from, to time.Time) (int, int, models.Money, error) {
+	var cancelled, refunded int
+	var refundedTotal models.Money
+	err := executor.QueryRow(`SELECT COUNT(*) FILTER (WHERE status = 'cancelled'),
+	                                 COUNT(*) FILTER (WHERE status = 'refunded'),
+	                                 COALESCE(SUM(final_amount) FILTER (WHERE status = 'refunded'), 0)
+	                          FROM orders
+	                          WHERE branch_code = $1 AND order_time >= $2 AND order_time < $3 AND business_date BETWEEN $4 AND $5`,
+		branchCode, from, to, businessDateFrom(from), businessDateTo(to),
+	).Scan(&cancelled, &refunded, &refundedTotal)
+	if err != nil {
+		return 0, 0, models.ZeroMoney, fmt.Errorf("%w: counting cancelled and refunded orders: %v", ErrDatabaseError, err)
+	}
+	return cancelled, refunded, refundedTotal, nil
+}
+
+func (r *dayCloseRepository) GetShiftCashTotals(executor SQLExecutor, branchCode string, from, to time.Time) (int, models.Money, error) {
+	var count int
+	var difference models.Money
+	err := executor.QueryRow(`SELECT COUNT(*), COALESCE(SUM(cash_difference), 0)
+	                          FROM shift_reports
+	                          WHERE branch_code = $1 AND ended_at >= $2 AND ended_at < $3`,
+		branchCode, from, to,
+	).Scan(&count, &difference)
+	if err != nil {
+		return 0, models.ZeroMoney, fmt.Errorf("%w: totalling shift reports: %v", ErrDatabaseError, err)
+	}
+	return count, difference, nil
+}
+
+func (r *dayCloseRepository) GetCategorySales(executor SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.CategorySales, error) {
+	rows, err := executor.Query(`SELECT pi.category_id, COALESCE(pc.name, 'Uncategorized'), SUM(oi.quantity), SUM(oi.total_price)
+	                             FROM orders o
+	                             JOIN order_items oi ON oi.order_id = o.id
+	                             JOIN pricelist_items pi ON oi.pricelist_item_id = pi.id
+	                             LEFT JOIN pricelist_categories pc ON pi.category_id = pc.id
+	                             WHERE o.branch_code = $1 AND o.status = ANY($2) AND o.order_time >= $3 AND o.order_time < $4
+	                               AND o.business_date BETWEEN $5 AND $6
+	                             GROUP BY pi.category_id, pc.name
+	                             ORDER BY SUM(oi.total_price) DESC, pc.name`,
+		branchCode, pq.Array(statuses), from, to, businessDateFrom(from), businessDateTo(to))
+	if err != nil {
+		return nil, fmt.Errorf("%w: totalling sales per category: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.CategorySales{}
+	for rows.Next() {
+		var total models.CategorySales
+		if err := rows.Scan(&total.CategoryID, &total.CategoryName, &total.Quantity, &total.Amount); err != nil {
+			return nil, fmt.Errorf("%w: scanning sales per category: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating sales per category: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
+
+func (r *dayCloseRepository) GetHoursBooked(executor SQLExecutor, from, to time.Time) (float64, error) {
+	var hours float64
+	err := executor.QueryRow(`SELECT COALESCE(SUM(EXTRACT(EPOCH FROM (end_time - start_time))) / 3600.0, 0)
+	                          FROM bookings
+	                          WHERE status IN ('completed', 'active') AND start_time >= $1 AND start_time < $2 AND deleted_at IS NULL`,
+		from, to,
+	).Scan(&hours)
+	if err != nil {
+		return 0, fmt.Errorf("%w: totalling booked hours: %v", ErrDatabaseError, err)
+	}
+	return hours, nil
+}
+
+func (r *dayCloseRepository) CreateSummary(executor SQLExecutor, summary *models.DailySummary) error {
+	salesByPayment, err := json.Marshal(summary.SalesByPayment)
+	if err != nil {
+		return fmt.Errorf("encoding sales by payment: %w", err)
+	}
+	categorySales, err := json.Marshal(summary.CategorySales)
+	if err != nil {
+		return fmt.Errorf("encoding category sales: %w", err)
+	}
+	forcedClosures, err := json.Marshal(summary.ForcedClosures)
+	if err != nil {
+		return fmt.Errorf("encoding forced closures: %w", err)
+	}
+	// A backfilled summary never replaces another; closing the day replaces only a backfilled one
+	onConflict := `ON CONFLICT (branch_code, business_date) DO NOTHING`
+	if !summary.Backfilled {
+		onConflict = `ON CONFLICT (branch_code, business_date) DO UPDATE SET
+		                  revenue = EXCLUDED.revenue, hours_booked = EXCLUDED.hours_booked, order_count = EXCLUDED.order_count,
+		                  sales_total = EXCLUDED.sales_total, sales_by_payment = EXCLUDED.sales_by_payment,
+		                  category_sales = EXCLUDED.category_sales, cancelled_count = EXCLUDED.cancelled_count,
+		                  refunded_count = EXCLUDED.refunded_count, refunded_total = EXCLUDED.refunded_total,
+		                  session_count = EXCLUDED.session_count, session_total = EXCLUDED.session_total,
+		                  cash_sales = EXCLUDED.cash_sales, cash_account_payments = EXCLUDED.cash_account_payments,
+		                  shift_report_count = EXCLUDED.shift_report_count, cash_difference = EXCLUDED.cash_difference,
+		                  forced_closures = EXCLUDED.forced_closures, force_reason = EXCLUDED.force_reason,
+		                  notes = EXCLUDED.notes, closed_by = EXCLUDED.closed_by, closed_at = EXCLUDED.closed_at,
+		                  backfilled = FALSE
+		              WHERE daily_summaries.backfilled`
+	}
+	err = executor.QueryRow(`INSERT INTO daily_summaries (branch_code, business_date, revenue, hours_booked, order_count, sales_total,
+	                                                      sales_by_payment, category_sales, cancelled_count, refunded_count,
+	                                                      refunded_total, session_count, session_total, cash_sales,
+	                                                      cash_account_payments, shift_report_count, cash_difference,
+	                                                      forced_closures, force_reason, notes, closed_by, closed_at, backfilled)
+	                         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+	                                 $21, $22, $23)
+	                         `+onConflict+`
+	                         RETURNING id`,
+		summary.BranchCode, summary.BusinessDate, summary.Revenue, summary.HoursBooked, summary.OrderCount, summary.SalesTotal,
+		salesByPayment, categorySales, summary.CancelledCount, summary.RefundedCount,
+		summary.RefundedTotal, summary.SessionCount, summary.SessionTotal, summary.CashSales,
+		summary.CashAccountPayments, summary.ShiftReportCount, summary.CashDifference,
+		forcedClosures, summary.ForceReason, summary.Notes, summary.ClosedBy, summary.ClosedAt, summary.Backfilled,
+	).Scan(&summary.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // The conflicting summary was kept
+			return fmt.Errorf("%w: business day %s already has a summary", ErrDuplicateKey, summary.BusinessDate)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: business day %s already has a summary", ErrDuplicateKey, summary.BusinessDate)
+		}
+		return fmt.Errorf("%w: creating daily summary: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *dayCloseRepository) GetSummary(branchCode, businessDate string) (*models.DailySummary, error) {
+	summary, err := scanDailySummary(r.db.QueryRow(`SELECT `+dailySummaryColumns+` FROM daily_summaries
+	                                                WHERE branch_code = $1 AND business_date = $2`, branchCode, businessDate))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting daily summary of %s: %v", ErrDatabaseError, businessDate, err)
+	}
+	return summary, nil
+}
+
+func (r *dayCloseRepository) GetSummaries(branchCode, from, to string) ([]models.DailySummary, error) {
+	conditions := []string{"branch_code = $1"}
+	args := []interface{}{branchCode}
+	argCounter := 2
+
+	if from != "" {
+		conditions = append(conditions, fmt.Sprintf("business_date >= $%d", argCounter))
+		args = append(args, from)
+		argCounter++
+	}
+	if to != "" {
+		conditions = append(conditions, fmt.Sprintf("business_date <= $%d", argCounter))
+		args = append(args, to)
+	}
+
+	rows, err := r.db.Query(`SELECT `+dailySummaryColumns+` FROM daily_summaries
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY business_date DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing daily summaries: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	summaries := []models.DailySummary{}
+	for rows.Next() {
+		summary, err := scanDailySummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning daily summary: %v", ErrDatabaseError, err)
+		}
+		summaries = append(summaries, *summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating daily summaries: %v", ErrDatabaseError, err)
+	}
+	return summaries, nil
+}
+
+func (r *dayCloseRepository) IsClosedThrough(branchCode, businessDate string) (bool, error) {
+	var closed bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM daily_summaries
+	                                       WHERE branch_code = $1 AND business_date >= $2 AND NOT backfilled)`,
+		branchCode, businessDate).Scan(&closed)
+	if err != nil {
+		return false, fmt.Errorf("%w: checking the day close of %s: %v", ErrDatabaseError, businessDate, err)
+	}
+	return closed, nil
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// DeviceRepository defines the database operations for the device registry.
+type DeviceRepository interface {
+	// RegisterDevice records a device, or records that a known one registered again: its type,
+	// app version, API key, address and last seen time are updated, its name and settings kept.
+	// It reports whether the device is new.
+	RegisterDevice(device *models.Device) (bool, error)
+	// TouchDevice records a heartbeat of the device with the UID registered with the API key;
+	// ErrNotFound if there is none.
+	TouchDevice(deviceUID string, apiKeyID int64, appVersion, ip *string, now time.Time) (*models.Device, error)
+	// GetDeviceByID returns a device; ErrNotFound if there is none.
+	GetDeviceByID(id int64) (*models.Device, error)
+	// GetDevices lists the devices of a type (all if nil), seen since onlineSince if online is
+	// true or not since then if it is false, by name.
+	GetDevices(deviceType *string, online *bool, onlineSince time.Time) ([]models.Device, error)
+	// UpdateDevice updates the name and settings of a device; ErrNotFound if it does not exist.
+	// Unless version is 0 the device must still have it; ErrVersionConflict if it changed.
+	UpdateDevice(device *models.Device, version int) error
+	// DeleteDevice removes a device; ErrNotFound if there is none.
+	DeleteDevice(id int64) error
+}
+
+type deviceRepository struct {
+	db *sql.DB
+}
+
+// NewDeviceRepository creates a new instance of DeviceRepository.
+func NewDeviceRepository(db *sql.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+const deviceColumns = `id, device_uid, name, type, settings, app_version, api_key_id, last_ip, registered_at, last_seen_at,
+	updated_at, version`
+
+// scanDevice scans deviceColumns (optionally followed by extra columns) into a device.
+func scanDevice(row scanner, extra ...interface{}) (*models.Device, error) {
+	var device models.Device
+	var settings []byte
+	dest := []interface{}{
+		&device.ID, &device.DeviceUID, &device.Name, &device.Type, &settings, &device.AppVersion, &device.APIKeyID,
+		&device.LastIP, &device.RegisteredAt, &device.LastSeenAt, &device.UpdatedAt, &device.Version,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &device.Settings); err != nil {
+		return nil, fmt.Errorf("decoding settings of device ID %d: %v", device.ID, err)
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) RegisterDevice(device *models.Device) (bool, error) {
+	settings, err := json.Marshal(device.Settings)
+	if err != nil {
+		return false, fmt.Errorf("encoding device settings: %w", err)
+	}
+	// xmax is 0 for a row just inserted and set for one the upsert updated
+	var created bool
+	registered, err := scanDevice(r.db.QueryRow(`INSERT INTO devices (device_uid, name, type, settings, app_version, api_key_id, last_ip,
+	                                           registered_at, last_seen_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $8)
+	                      ON CONFLICT (device_uid) DO UPDATE SET
+	                          type = EXCLUDED.type, app_version = EXCLUDED.app_version, api_key_id = EXCLUDED.api_key_id,
+	                          last_ip = EXCLUDED.last_ip, last_seen_at = EXCLUDED.last_seen_at, updated_at = EXCLUDED.updated_at
+	                      RETURNING `+deviceColumns+`, xmax = 0`,
+		device.DeviceUID, device.Name, device.Type, settings, device.AppVersion, device.APIKeyID, device.LastIP, time.Now().UTC(),
+	), &created)
+	if err != nil {
+		return false, fmt.Errorf("%w: registering device %s: %v", ErrDatabaseError, device.DeviceUID, err)
+	}
+	*device = *registered
+	return created, nil
+}
+
+func (r *deviceRepository) TouchDevice(deviceUID string, apiKeyID int64, appVersion, ip *string, now time.Time) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRow(`UPDATE devices
+	                      SET app_version = COALESCE($3, app_version), last_ip = COALESCE($4, last_ip), last_seen_at = $5
+	                      WHERE device_uid = $1 AND api_key_id = $2
+	                      RETURNING `+deviceColumns,
+		deviceUID, apiKeyID, appVersion, ip, now,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: recording heartbeat of device %s: %v", ErrDatabaseError, deviceUID, err)
+	}
+	return device, nil
+}
+
+func (r *deviceRepository) GetDeviceByID(id int64) (*models.Device, error) {
+	device, err := scanDevice(r.db.QueryRow(`SELECT `+deviceColumns+` FROM devices WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting device ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return device, nil
+}
+
+func (r *deviceRepository) GetDevices(deviceType *string, online *bool, onlineSince time.Time) ([]models.Device, error) {
+	var conditions []string
+	var args []interface{}
+	if deviceType != nil {
+		args = append(args, *deviceType)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if online != nil {
+		args = append(args, onlineSince)
+		if *online {
+			conditions = append(conditions, fmt.Sprintf("last_seen_at >= $%d", len(args)))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(last_seen_at IS NULL OR last_seen_at < $%d)", len(args)))
+		}
+	}
+	query := `SELECT ` + deviceColumns + ` FROM devices`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	rows, err := r.db.Query(query+` ORDER BY name, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing devices: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	devices := []models.Device{}
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning device: %v", ErrDatabaseError, err)
+		}
+		devices = append(devices, *device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating devices: %v", ErrDatabaseError, err)
+	}
+	return devices, nil
+}
+
+func (r *deviceRepository) UpdateDevice(device *models.Device, version int) error {
+	settings, err := json.Marshal(device.Settings)
+	if err != nil {
+		return fmt.Errorf("encoding device settings: %w", err)
+	}
+	updated, err := scanDevice(r.db.QueryRow(`UPDATE devices
+	                      SET name = $3, settings = $4, updated_at = $5, version = version + 1
+	                      WHERE id = $1 AND ($2 = 0 OR version = $2)
+	                      RETURNING `+deviceColumns,
+		device.ID, version, device.Name, settings, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, getErr := r.GetDeviceByID(device.ID); errors.Is(getErr, ErrNotFound) {
+				return ErrNotFound
+			}
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("%w: updating device ID %d: %v", ErrDatabaseError, device.ID, err)
+	}
+	*device = *updated
+	return nil
+}
+
+func (r *deviceRepository) DeleteDevice(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM devices WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting device ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// explainTimeout bounds a diagnostic EXPLAIN ANALYZE, which runs the query for real.
+const explainTimeout = 30 * time.Second
+
+// DiagnosticsRepository defines the database operations of the diagnostics endpoints.
+// The Explain methods run EXPLAIN ANALYZE on the query the corresponding list method
+// runs for the same arguments.
+type DiagnosticsRepository interface {
+	Ping() error
+	// GetIndexColumns returns the key columns of each valid, non-partial index of table, in index order.
+	GetIndexColumns(table string) ([][]string, error)
+	ExplainOrders(filters models.OrderFilters) (*models.QueryPlan, error)
+	ExplainBookings(filters models.BookingFilters) (*models.QueryPlan, error)
+	ExplainMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) (*models.QueryPlan, error)
+	ExplainTableAvailability(tableID int64, startTime, endTime time.Time) (*models.QueryPlan, error)
+	ExplainActivityEvents(filter models.ActivityFilter) (*models.QueryPlan, error)
+}
+
+type diagnosticsRepository struct {
+	db *sql.DB
+}
+
+// NewDiagnosticsRepository creates a new instance of DiagnosticsRepository.
+func NewDiagnosticsRepository(db *sql.DB) DiagnosticsRepository {
+	return &diagnosticsRepository{db: db}
+}
+
+func (r *diagnosticsRepository) Ping() error {
+	if err := r.db.Ping(); err != nil {
+		return fmt.Errorf("%w: pinging database: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *diagnosticsRepository) GetIndexColumns(table string) ([][]string, error) {
+	// Expression columns have attnum 0 and no pg_attribute row, so they end an index's column list
+	query := `SELECT array_agg(a.attname ORDER BY k.ord)
+	          FROM pg_index i
+	          CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+	          JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+	          WHERE i.indrelid = to_regclass($1) AND i.indisvalid AND i.indpred IS NULL
+	            AND k.ord <= i.indnkeyatts
+	          GROUP BY i.indexrelid`
+	rows, err := r.db.Query(query, table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: querying indexes of %s: %v", ErrDatabaseError, table, err)
+	}
+	defer rows.Close()
+
+	indexes := [][]string{}
+	for rows.Next() {
+		var columns pq.StringArray
+		if err := rows.Scan(&columns); err != nil {
+			return nil, fmt.Errorf("%w: scanning index of %s: %v", ErrDatabaseError, table, err)
+		}
+		indexes = append(indexes, columns)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating indexes of %s: %v", ErrDatabaseError, table, err)
+	}
+	return indexes, nil
+}
+
+func (r *diagnosticsRepository) ExplainOrders(filters models.OrderFilters) (*models.QueryPlan, error) {
+	query, args := ordersListQuery(filters, filters.Cursor == nil)
+	return r.explain(query, args)
+}
+
+func (r *diagnosticsRepository) ExplainBookings(filters models.BookingFilters) (*models.QueryPlan, error) {
+	query, args := bookingsListQuery(filters, filters.Cursor == nil)
+	return r.explain(query, args)
+}
+
+func (r *diagnosticsRepository) ExplainMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) (*models.QueryPlan, error) {
+	query, args := movementsListQuery(itemID, staffID, movementType, nil, nil, nil, page, pageSize)
+	return r.explain(query, args)
+}
+
+func (r *diagnosticsRepository) ExplainTableAvailability(tableID int64, startTime, endTime time.Time) (*models.QueryPlan, error) {
+	// The buffer only shifts the compared times, so it does not change the plan
+	query, args := tableAvailabilityQuery(tableID, startTime, endTime, 0, nil)
+	return r.explain(query, args)
+}
+
+func (r *diagnosticsRepository) ExplainActivityEvents(filter models.ActivityFilter) (*models.QueryPlan, error) {
+	query, args := activityEventsQuery(filter)
+	return r.explain(query, args)
+}
+
+// explain runs EXPLAIN ANALYZE on query. ANALYZE executes the query, so it runs in a
+// read-only transaction with a timeout, which is rolled back.
+func (r *diagnosticsRepository) explain(query string, args []interface{}) (*models.QueryPlan, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("%w: starting explain transaction: %v", ErrDatabaseError, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SET TRANSACTION READ ONLY"); err != nil {
+		return nil, fmt.Errorf("%w: making explain transaction read-only: %v", ErrDatabaseError, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", explainTimeout.Milliseconds())); err != nil {
+		return nil, fmt.Errorf("%w: setting explain timeout: %v", ErrDatabaseError, err)
+	}
+	var plan []byte
+	if err := tx.QueryRow("EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+		return nil, fmt.Errorf("%w: explaining query: %v", ErrDatabaseError, err)
+	}
+	return &models.QueryPlan{SQL: strings.TrimSpace(query), Args: explainArgs(args), Plan: plan}, nil
+}
+
+// explainArgs makes query arguments readable in JSON: array arguments are wrapped by the driver.
+func explainArgs(args []interface{}) []interface{} {
+	readable := make([]interface{}, len(args))
+	for i, arg := range args {
+		if array, ok := arg.(interface{ Value() (interface{}, error) }); ok {
+			if value, err := array.Value(); err == nil {
+				arg = value
+			}
+		}
+		if b, ok := arg.([]byte); ok {
+			arg = string(b)
+		}
+		readable[i] = arg
+	}
+	return readable
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 )
 
 var (
@@ -15,8 +16,49 @@ var (
 
 	// ErrDuplicateKey is returned when an insert/update violates a unique constraint.
 	ErrDuplicateKey = errors.New("duplicate key value violates unique constraint")
+
+	// ErrVersionConflict is returned when an update carries a stale row version,
+	// i.e. the record was modified by someone else since it was read.
+	ErrVersionConflict = errors.New("record was modified concurrently")
+
+	// ErrTableNotAvailable is returned when a confirmed booking would overlap another
+	// confirmed booking of the same table (the bookings_no_overlap constraint).
+	ErrTableNotAvailable = errors.New("table is already booked for an overlapping period")
+
+	// ErrReferenced is returned when a record cannot be deleted because other records reference it.
+	ErrReferenced = errors.New("record is referenced by other records")
+
+	// ErrInvalidReference is returned when an insert/update references a record that does not exist.
+	ErrInvalidReference = errors.New("referenced record does not exist")
 )
 
+// ConstraintError is the violation of a database constraint. It names the constraint, so
+// callers can tell e.g. a taken email from a taken username without parsing messages, and
+// matches its kind (ErrDuplicateKey, ErrReferenced or ErrInvalidReference) with errors.Is.
+type ConstraintError struct {
+	Err        error
+	Constraint string
+	Detail     string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%v: %s (constraint: %s)", e.Err, e.Detail, e.Constraint)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// ViolatedConstraint returns the name of the constraint err violated, or "" if it is not
+// a ConstraintError.
+func ViolatedConstraint(err error) string {
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		return constraintErr.Constraint
+	}
+	return ""
+}
+
 // SQLExecutor defines an interface that can be satisfied by *sql.DB or *sql.Tx
 // This allows repository methods to be used within transactions or with a direct DB connection.
 type SQLExecutor interface {
@@ -30,3 +72,26 @@ type SQLExecutor interface {
 type scanner interface {
 	Scan(dest ...interface{}) error
 }
+
+// versionMismatchError explains why a versioned UPDATE matched no rows: the
+// record either no longer exists (ErrNotFound) or has a newer version (ErrVersionConflict).
+func versionMismatchError(executor SQLExecutor, table string, id int64) error {
+	var exists bool
+	err := executor.QueryRow("SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: checking %s ID %d after version mismatch: %v", ErrDatabaseError, table, id, err)
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+// totalCountColumn returns the total_count column of a list query. Without withTotal, as
+// for cursor pagination and streams, it skips the window count, which reads every matching row.
+func totalCountColumn(withTotal bool) string {
+	if !withTotal {
+		return "0 AS total_count"
+	}
+	return "COUNT(*) OVER() AS total_count"
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// FloorPlanRepository defines the database operations for the floor plan of the game tables.
+type FloorPlanRepository interface {
+	// GetCanvas returns the floor plan without its tables; ErrNotFound before it is saved.
+	GetCanvas() (*models.FloorPlan, error)
+	// GetTables lists every game table by name with its position, if placed, its running
+	// session and its next pending or confirmed booking ending after now that has no session.
+	GetTables(now time.Time) ([]models.FloorPlanTableStatus, error)
+	// ReplaceFloorPlan saves the canvas and replaces the positions of all tables; ErrNotFound
+	// if a table does not exist.
+	ReplaceFloorPlan(executor SQLExecutor, width, height float64, tables []models.FloorPlanTable, updatedBy int64, at time.Time) error
+	// SaveTable places a table or moves it; ErrNotFound if the table does not exist.
+	SaveTable(table models.FloorPlanTable, at time.Time) error
+	// DeleteTable takes a table off the plan; ErrNotFound if it was not placed.
+	DeleteTable(tableID int64) error
+}
+
+type floorPlanRepository struct {
+	db *sql.DB
+}
+
+// NewFloorPlanRepository creates a new instance of FloorPlanRepository.
+func NewFloorPlanRepository(db *sql.DB) FloorPlanRepository {
+	return &floorPlanRepository{db: db}
+}
+
+func (r *floorPlanRepository) GetCanvas() (*models.FloorPlan, error) {
+	var plan models.FloorPlan
+	var updatedAt time.Time
+	err := r.db.QueryRow(`SELECT width, height, updated_by, updated_at FROM floor_plan`).Scan(
+		&plan.Width, &plan.Height, &plan.UpdatedBy, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting floor plan: %v", ErrDatabaseError, err)
+	}
+	plan.UpdatedAt = &updatedAt
+	return &plan, nil
+}
+
+func (r *floorPlanRepository) GetTables(now time.Time) ([]models.FloorPlanTableStatus, error) {
+	rows, err := r.db.Query(`SELECT gt.id, gt.name, gt.console_type, gt.capacity, COALESCE(gt.status, ''),
+	                                fp.table_id IS NOT NULL, COALESCE(fp.x, 0), COALESCE(fp.y, 0), COALESCE(fp.width, 0),
+	                                COALESCE(fp.height, 0), COALESCE(fp.rotation, 0), COALESCE(fp.shape, ''), fp.zone,
+	                                ts.id, ts.ends_at, b.id, b.start_time
+	                         FROM game_tables gt
+	                         LEFT JOIN floor_plan_tables fp ON fp.table_id = gt.id
+	                         LEFT JOIN LATERAL (SELECT id, ends_at FROM table_sessions
+	                                            WHERE table_id = gt.id AND status <> $1
+	                                            ORDER BY started_at DESC LIMIT 1) ts ON TRUE
+	                         LEFT JOIN LATERAL (SELECT id, start_time FROM bookings bk
+	                                            WHERE bk.table_id = gt.id AND bk.status IN ($2, $3) AND bk.end_time > $4 AND bk.deleted_at IS NULL
+	                                              AND NOT EXISTS (SELECT 1 FROM table_sessions s WHERE s.booking_id = bk.id)
+	                                            ORDER BY bk.start_time LIMIT 1) b ON TRUE
+	                         ORDER BY gt.name, gt.id`,
+		models.TableSessionStatusStopped, string(models.BookingStatusPending), string(models.BookingStatusConfirmed), now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing floor plan tables: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	tables := []models.FloorPlanTableStatus{}
+	for rows.Next() {
+		var table models.FloorPlanTableStatus
+		if err := rows.Scan(&table.TableID, &table.Name, &table.ConsoleType, &table.Capacity, &table.TableStatus,
+			&table.Placed, &table.X, &table.Y, &table.Width, &table.Height, &table.Rotation, &table.Shape, &table.Zone,
+			&table.SessionID, &table.SessionEndsAt, &table.NextBookingID, &table.NextBookingStart); err != nil {
+			return nil, fmt.Errorf("%w: scanning floor plan table: %v", ErrDatabaseError, err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating floor plan tables: %v", ErrDatabaseError, err)
+	}
+	return tables, nil
+}
+
+// isTableForeignKeyViolation reports whether err is due to a game table that does not exist.
+func isTableForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "floor_plan_tables_table_id_fkey"
+}
+
+func (r *floorPlanRepository) ReplaceFloorPlan(executor SQLExecutor, width, height float64, tables []models.FloorPlanTable, updatedBy int64, at time.Time) error {
+	_, err := executor.Exec(`INSERT INTO floor_plan (id, width, height, updated_by, updated_at)
+	                         VALUES (TRUE, $1, $2, $3, $4)
+	                         ON CONFLICT (id) DO UPDATE SET width = $1, height = $2, updated_by = $3, updated_at = $4`,
+		width, height, updatedBy, at)
+	if err != nil {
+		return fmt.Errorf("%w: saving floor plan: %v", ErrDatabaseError, err)
+	}
+	if _, err := executor.Exec(`DELETE FROM floor_plan_tables`); err != nil {
+		return fmt.Errorf("%w: clearing floor plan tables: %v", ErrDatabaseError, err)
+	}
+	for _, table := range tables {
+		_, err := executor.Exec(`INSERT INTO floor_plan_tables (table_id, x, y, width, height, rotation, shape, zone, updated_at)
+		                         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			table.TableID, table.X, table.Y, table.Width, table.Height, table.Rotation, table.Shape, table.Zone, at)
+		if err != nil {
+			if isTableForeignKeyViolation(err) {
+				return fmt.Errorf("%w: game table ID %d", ErrNotFound, table.TableID)
+			}
+			return fmt.Errorf("%w: placing table ID %d: %v", ErrDatabaseError, table.TableID, err)
+		}
+	}
+	return nil
+}
+
+func (r *floorPlanRepository) SaveTable(table models.FloorPlanTable, at time.Time) error {
+	_, err := r.db.Exec(`INSERT INTO floor_plan_tables (table_id, x, y, width, height, rotation, shape, zone, updated_at)
+	                     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	                     ON CONFLICT (table_id) DO UPDATE SET x = $2, y = $3, width = $4, height = $5, rotation = $6,
+	                                                          shape = $7, zone = $8, updated_at = $9`,
+		table.TableID, table.X, table.Y, table.Width, table.Height, table.Rotation, table.Shape, table.Zone, at)
+	if err != nil {
+		if isTableForeignKeyViolation(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: placing table ID %d: %v", ErrDatabaseError, table.TableID, err)
+	}
+	return nil
+}
+
+func (r *floorPlanRepository) DeleteTable(tableID int64) error {
+	result, err := r.db.Exec(`DELETE FROM floor_plan_tables WHERE table_id = $1`, tableID)
+	if err != nil {
+		return fmt.Errorf("%w: removing table ID %d from the floor plan: %v", ErrDatabaseError, tableID, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
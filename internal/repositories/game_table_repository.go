@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// GameTableRepository defines the database operations for game tables and their statuses.
+type GameTableRepository interface {
+	// CreateGameTable inserts a game table; a ConstraintError matching ErrDuplicateKey if the name is taken.
+	CreateGameTable(table *models.GameTable) error
+	// GetGameTables lists the game tables by name, of a status if set.
+	GetGameTables(status string) ([]models.GameTable, error)
+	// GetGameTableByID returns a game table; ErrNotFound if there is none.
+	GetGameTableByID(id int64) (*models.GameTable, error)
+	// UpdateGameTable updates a game table, its status included; ErrNotFound if there is none, or
+	// ErrVersionConflict if its status is no longer fromStatus.
+	UpdateGameTable(executor SQLExecutor, table *models.GameTable, fromStatus string) error
+	// DeleteGameTable deletes a game table; a ConstraintError matching ErrReferenced if bookings or
+	// sessions keep it.
+	DeleteGameTable(id int64) error
+	// CountActiveBookings counts the bookings of a table that are neither completed nor cancelled.
+	CountActiveBookings(tableID int64) (int, error)
+	// SetGameTableStatus changes the status of a game table from one status to another;
+	// ErrVersionConflict if the table is gone or its status is no longer from.
+	SetGameTableStatus(executor SQLExecutor, tableID int64, from, to string, at time.Time) error
+	// GetTableStatusChanges returns the available, reserved and occupied tables whose status differs
+	// from the one their bookings and sessions call for at now: occupied while a session runs or a
+	// checked-in booking is in progress, reserved while a booking starts by reservedUntil, else available.
+	GetTableStatusChanges(now, reservedUntil time.Time) ([]models.TableStatusChange, error)
+}
+
+type gameTableRepository struct {
+	db *sql.DB
+}
+
+// NewGameTableRepository creates a new instance of GameTableRepository.
+func NewGameTableRepository(db *sql.DB) GameTableRepository {
+	return &gameTableRepository{db: db}
+}
+
+const gameTableColumns = `id, name, description, status, capacity, hourly_rate, buffer_minutes, power_device_id, console_type,
+	base_controllers, created_at, updated_at, billing_mode, minute_rate, billing_increment_minutes, minimum_charge`
+
+// scanGameTable scans gameTableColumns (optionally followed by extra columns) into a game table.
+func scanGameTable(row scanner, extra ...interface{}) (*models.GameTable, error) {
+	var table models.GameTable
+	dest := []interface{}{
+		&table.ID, &table.Name, &table.Description, &table.Status, &table.Capacity, &table.HourlyRate, &table.BufferMinutes,
+		&table.PowerDeviceID, &table.ConsoleType, &table.BaseControllers, &table.CreatedAt, &table.UpdatedAt, &table.BillingMode,
+		&table.MinuteRate, &table.BillingIncrementMinutes, &table.MinimumCharge,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+// gameTableWriteError converts the error of an insert or update of a game table.
+func gameTableWriteError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: "a game table with this name already exists"}
+	}
+	return fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+}
+
+func (r *gameTableRepository) CreateGameTable(table *models.GameTable) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO game_tables (name, description, status, capacity, hourly_rate, buffer_minutes, power_device_id,
+	                                               console_type, base_controllers, created_at, updated_at, billing_mode, minute_rate,
+	                                               billing_increment_minutes, minimum_charge)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10, $11, $12, $13, $14)
+	                      RETURNING id, created_at, updated_at`,
+		table.Name, table.Description, table.Status, table.Capacity, table.HourlyRate, table.BufferMinutes, table.PowerDeviceID,
+		table.ConsoleType, table.BaseControllers, now, table.BillingMode, table.MinuteRate, table.BillingIncrementMinutes,
+		table.MinimumCharge,
+	).Scan(&table.ID, &table.CreatedAt, &table.UpdatedAt)
+	if err != nil {
+		return gameTableWriteError(err, "creating game table")
+	}
+	return nil
+}
+
+func (r *gameTableRepository) GetGameTables(status string) ([]models.GameTable, error) {
+	query := `SELECT ` + gameTableColumns + ` FROM game_tables`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+	rows, err := r.db.Query(query+` ORDER BY name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing game tables: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	tables := []models.GameTable{}
+	for rows.Next() {
+		table, err := scanGameTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning game table: %v", ErrDatabaseError, err)
+		}
+		tables = append(tables, *table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating game tables: %v", ErrDatabaseError, err)
+	}
+	return tables, nil
+}
+
+func (r *gameTableRepository) GetGameTableByID(id int64) (*models.GameTable, error) {
+	table, err := scanGameTable(r.db.QueryRow(`SELECT `+gameTableColumns+` FROM game_tables WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting game table ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return table, nil
+}
+
+func (r *gameTableRepository) UpdateGameTable(executor SQLExecutor, table *models.GameTable, fromStatus string) error {
+	updated, err := scanGameTable(executor.QueryRow(`UPDATE game_tables
+	                      SET name = $3, description = $4, status = $5, capacity = $6, hourly_rate = $7, buffer_minutes = $8,
+	                          power_device_id = $9, console_type = $10, base_controllers = $11, billing_mode = $12, minute_rate = $13,
+	                          billing_increment_minutes = $14, minimum_charge = $15, updated_at = $16
+	                      WHERE id = $1 AND status = $2
+	                      RETURNING `+gameTableColumns,
+		table.ID, fromStatus, table.Name, table.Description, table.Status, table.Capacity, table.HourlyRate, table.BufferMinutes,
+		table.PowerDeviceID, table.ConsoleType, table.BaseControllers, table.BillingMode, table.MinuteRate,
+		table.BillingIncrementMinutes, table.MinimumCharge, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(executor, "game_tables", table.ID)
+		}
+		return gameTableWriteError(err, fmt.Sprintf("updating game table ID %d", table.ID))
+	}
+	*table = *updated
+	return nil
+}
+
+func (r *gameTableRepository) DeleteGameTable(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM game_tables WHERE id = $1`, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			return &ConstraintError{Err: ErrReferenced, Constraint: pqErr.Constraint, Detail: "the game table has bookings or sessions"}
+		}
+		return fmt.Errorf("%w: deleting game table ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for game table ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gameTableRepository) CountActiveBookings(tableID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM bookings WHERE table_id = $1 AND status NOT IN ($2, $3) AND deleted_at IS NULL`,
+		tableID, string(models.BookingStatusCompleted), string(models.BookingStatusCancelled)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: counting active bookings of game table ID %d: %v", ErrDatabaseError, tableID, err)
+	}
+	return count, nil
+}
+
+func (r *gameTableRepository) SetGameTableStatus(executor SQLExecutor, tableID int64, from, to string, at time.Time) error {
+	result, err := executor.Exec(`UPDATE game_tables SET status = $3, updated_at = $4 WHERE id = $1 AND status = $2`,
+		tableID, from, to, at)
+	if err != nil {
+		return fmt.Errorf("%w: setting status of game table ID %d: %v", ErrDatabaseError, tableID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for game table ID %d: %v", ErrDatabaseError, tableID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *gameTableRepository) GetTableStatusChanges(now, reservedUntil time.Time) ([]models.TableStatusChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, status, derived_status
+		FROM (
+			SELECT gt.id, gt.status,
+			       CASE
+			           WHEN EXISTS (SELECT 1 FROM table_sessions ts WHERE ts.table_id = gt.id AND ts.status <> $1)
+			             OR EXISTS (SELECT 1 FROM bookings b WHERE b.table_id = gt.id AND b.status = $2 AND b.deleted_at IS NULL
+			                          AND b.checked_in_at IS NOT NULL AND b.end_time > $4) THEN $5::text
+			           WHEN EXISTS (SELECT 1 FROM bookings b WHERE b.table_id = gt.id AND b.status IN ($2, $3) AND b.deleted_at IS NULL
+			                          AND b.checked_in_at IS NULL AND b.start_time <= $6 AND b.end_time > $4) THEN $7::text
+			           ELSE $8::text
+			       END AS derived_status
+			FROM game_tables gt
+			WHERE gt.status IN ($5::text, $7::text, $8::text)
+		) derived
+		WHERE status <> derived_status
+		ORDER BY id`,
+		models.TableSessionStatusStopped, string(models.BookingStatusConfirmed), string(models.BookingStatusPending), now,
+		models.TableStatusOccupied, reservedUntil, models.TableStatusReserved, models.TableStatusAvailable)
+	if err != nil {
+		return nil, fmt.Errorf("%w: deriving game table statuses: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	changes := []models.TableStatusChange{}
+	for rows.Next() {
+		var change models.TableStatusChange
+		if err := rows.Scan(&change.TableID, &change.From, &change.To); err != nil {
+			return nil, fmt.Errorf("%w: scanning game table status: %v", ErrDatabaseError, err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating game table statuses: %v", ErrDatabaseError, err)
+	}
+	return changes, nil
+}
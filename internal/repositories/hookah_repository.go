@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// HookahRepository defines the database operations for the service of hookahs, i.e. the
+// HOOKAH items of orders.
+type HookahRepository interface {
+	// GetActiveHookahs returns the hookahs not taken away yet of the orders in one of
+	// orderStatuses, oldest first.
+	GetActiveHookahs(orderStatuses []string) ([]models.Hookah, error)
+	// GetHookah returns the hookah of an order item; ErrNotFound if the item is not a hookah.
+	GetHookah(orderItemID int64) (*models.Hookah, error)
+	// CreateCoalChange records a coal change and makes it the last one of its hookah.
+	CreateCoalChange(executor SQLExecutor, change *models.CoalChange) error
+	// GetCoalChanges returns the coal changes of a hookah, oldest first.
+	GetCoalChanges(orderItemID int64) ([]models.CoalChange, error)
+	// MarkCoalReminded records a reminder to change the coals of a hookah, due at dueAt. It
+	// reports false if the hookah was taken away or the reminder was already sent, so each
+	// reminder is sent once even if several instances run the timers.
+	MarkCoalReminded(executor SQLExecutor, orderItemID int64, dueAt, now time.Time) (bool, error)
+	// EndHookah records that the hookah was taken away at endedAt; ErrVersionConflict if it already was.
+	EndHookah(executor SQLExecutor, orderItemID int64, endedAt time.Time) error
+}
+
+type hookahRepository struct {
+	db *sql.DB
+}
+
+// NewHookahRepository creates a new instance of HookahRepository.
+func NewHookahRepository(db *sql.DB) HookahRepository {
+	return &hookahRepository{db: db}
+}
+
+const selectHookahs = `SELECT oi.id, oi.order_id, o.table_id, oi.pricelist_item_id, pi.name, oi.quantity, o.status, oi.created_at,
+	       oi.coal_changed_at, oi.coal_reminded_at, oi.service_ended_at
+	FROM order_items oi
+	JOIN orders o ON oi.order_id = o.id
+	JOIN pricelist_items pi ON oi.pricelist_item_id = pi.id
+	WHERE UPPER(pi.item_type) = '` + models.ItemTypeHookah + `'`
+
+func scanHookah(row scanner) (*models.Hookah, error) {
+	var hookah models.Hookah
+	err := row.Scan(
+		&hookah.OrderItemID, &hookah.OrderID, &hookah.TableID, &hookah.PricelistItemID, &hookah.ItemName, &hookah.Quantity,
+		&hookah.OrderStatus, &hookah.StartedAt, &hookah.CoalChangedAt, &hookah.CoalRemindedAt, &hookah.EndedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hookah, nil
+}
+
+func (r *hookahRepository) GetActiveHookahs(orderStatuses []string) ([]models.Hookah, error) {
+	rows, err := r.db.Query(selectHookahs+` AND oi.service_ended_at IS NULL AND o.status = ANY($1) ORDER BY oi.created_at, oi.id`,
+		pq.Array(orderStatuses))
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing active hookahs: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	hookahs := []models.Hookah{}
+	for rows.Next() {
+		hookah, err := scanHookah(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning hookah: %v", ErrDatabaseError, err)
+		}
+		hookahs = append(hookahs, *hookah)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating hookahs: %v", ErrDatabaseError, err)
+	}
+	return hookahs, nil
+}
+
+func (r *hookahRepository) GetHookah(orderItemID int64) (*models.Hookah, error) {
+	hookah, err := scanHookah(r.db.QueryRow(selectHookahs+` AND oi.id = $1`, orderItemID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting hookah of order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	return hookah, nil
+}
+
+func (r *hookahRepository) CreateCoalChange(executor SQLExecutor, change *models.CoalChange) error {
+	err := executor.QueryRow(`INSERT INTO hookah_coal_changes (order_item_id, coals, changed_by, changed_at, inventory_movement_id)
+	                          VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		change.OrderItemID, change.Coals, change.ChangedBy, change.ChangedAt, change.InventoryMovementID,
+	).Scan(&change.ID)
+	if err != nil {
+		return fmt.Errorf("%w: recording coal change of order item ID %d: %v", ErrDatabaseError, change.OrderItemID, err)
+	}
+	_, err = executor.Exec(`UPDATE order_items SET coal_changed_at = $2, updated_at = $2 WHERE id = $1`, change.OrderItemID, change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("%w: updating last coal change of order item ID %d: %v", ErrDatabaseError, change.OrderItemID, err)
+	}
+	return nil
+}
+
+func (r *hookahRepository) GetCoalChanges(orderItemID int64) ([]models.CoalChange, error) {
+	rows, err := r.db.Query(`SELECT id, order_item_id, coals, changed_by, changed_at, inventory_movement_id
+	                         FROM hookah_coal_changes WHERE order_item_id = $1 ORDER BY changed_at, id`, orderItemID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing coal changes of order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	defer rows.Close()
+
+	changes := []models.CoalChange{}
+	for rows.Next() {
+		var change models.CoalChange
+		if err := rows.Scan(&change.ID, &change.OrderItemID, &change.Coals, &change.ChangedBy, &change.ChangedAt, &change.InventoryMovementID); err != nil {
+			return nil, fmt.Errorf("%w: scanning coal change: %v", ErrDatabaseError, err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating coal changes: %v", ErrDatabaseError, err)
+	}
+	return changes, nil
+}
+
+func (r *hookahRepository) MarkCoalReminded(executor SQLExecutor, orderItemID int64, dueAt, now time.Time) (bool, error) {
+	result, err := executor.Exec(`UPDATE order_items SET coal_reminded_at = $3
+	                              WHERE id = $1 AND service_ended_at IS NULL AND (coal_reminded_at IS NULL OR coal_reminded_at < $2)`,
+		orderItemID, dueAt, now)
+	if err != nil {
+		return false, fmt.Errorf("%w: recording coal reminder of order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w: checking affected rows for order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *hookahRepository) EndHookah(executor SQLExecutor, orderItemID int64, endedAt time.Time) error {
+	result, err := executor.Exec(`UPDATE order_items SET service_ended_at = $2, updated_at = $2 WHERE id = $1 AND service_ended_at IS NULL`,
+		orderItemID, endedAt)
+	if err != nil {
+		return fmt.Errorf("%w: ending service of order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for order item ID %d: %v", ErrDatabaseError, orderItemID, err)
+	}
+	if rowsAffected == 0 {
+		return versionMismatchError(executor, "order_items", orderItemID)
+	}
+	return nil
+}
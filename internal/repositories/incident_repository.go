@@ -0,0 +1,322 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// IncidentRepository defines the database operations for incidents, their photos and the
+// payroll penalties.
+type IncidentRepository interface {
+	CreateIncident(incident *models.Incident) error
+	// GetIncidentByID returns an incident with its photos; ErrNotFound if there is none.
+	GetIncidentByID(id int64) (*models.Incident, error)
+	// GetIncidents lists the incidents of a branch matching filters with their photos, most recent first.
+	GetIncidents(branchCode string, filters models.IncidentFilters) ([]models.Incident, error)
+	// UpdateIncident updates the reported details of an open incident. Unless version is 0 the
+	// incident must still have it; ErrVersionConflict if it changed or was reviewed in the meantime.
+	UpdateIncident(incident *models.Incident, version int) error
+	// ReviewIncident records the review of an open incident; ErrVersionConflict if it was already reviewed.
+	ReviewIncident(incident *models.Incident) error
+	DeleteIncident(id int64) error
+
+	AddPhoto(incidentID int64, photo *models.Photo, uploadedBy int64) (*models.IncidentPhoto, error)
+	// GetPhoto returns a photo of an incident; ErrNotFound if the incident has no such photo.
+	GetPhoto(incidentID, photoID int64) (*models.Photo, error)
+	DeletePhoto(incidentID, photoID int64) error
+
+	// GetPayrollLines returns the salary of every staff member with the penalties of the incidents
+	// confirmed against them that occurred in [from, to), by name.
+	GetPayrollLines(from, to time.Time) ([]models.PayrollLine, error)
+}
+
+type incidentRepository struct {
+	db *sql.DB
+}
+
+// NewIncidentRepository creates a new instance of IncidentRepository.
+func NewIncidentRepository(db *sql.DB) IncidentRepository {
+	return &incidentRepository{db: db}
+}
+
+const incidentColumns = `id, branch_code, incident_type, severity, description, occurred_at, staff_id, client_id, table_id, order_id,
+	penalty_amount, status, reported_by, reviewed_by, reviewed_at, review_notes, created_at, updated_at, version`
+
+func scanIncident(row scanner) (*models.Incident, error) {
+	var incident models.Incident
+	err := row.Scan(&incident.ID, &incident.BranchCode, &incident.IncidentType, &incident.Severity, &incident.Description,
+		&incident.OccurredAt, &incident.StaffID, &incident.ClientID, &incident.TableID, &incident.OrderID,
+		&incident.PenaltyAmount, &incident.Status, &incident.ReportedBy, &incident.ReviewedBy, &incident.ReviewedAt,
+		&incident.ReviewNotes, &incident.CreatedAt, &incident.UpdatedAt, &incident.Version)
+	if err != nil {
+		return nil, err
+	}
+	incident.Photos = []models.IncidentPhoto{}
+	return &incident, nil
+}
+
+func (r *incidentRepository) CreateIncident(incident *models.Incident) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO incidents (branch_code, incident_type, severity, description, occurred_at, staff_id, client_id,
+	                                             table_id, order_id, penalty_amount, status, reported_by, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+	                      RETURNING id, created_at, updated_at, version`,
+		incident.BranchCode, incident.IncidentType, incident.Severity, incident.Description, incident.OccurredAt, incident.StaffID,
+		incident.ClientID, incident.TableID, incident.OrderID, incident.PenaltyAmount, incident.Status, incident.ReportedBy, now,
+	).Scan(&incident.ID, &incident.CreatedAt, &incident.UpdatedAt, &incident.Version)
+	if err != nil {
+		return fmt.Errorf("%w: creating incident: %v", ErrDatabaseError, err)
+	}
+	incident.Photos = []models.IncidentPhoto{}
+	return nil
+}
+
+func (r *incidentRepository) GetIncidentByID(id int64) (*models.Incident, error) {
+	incident, err := scanIncident(r.db.QueryRow(`SELECT `+incidentColumns+` FROM incidents WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting incident ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if err := r.loadPhotos([]*models.Incident{incident}); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (r *incidentRepository) GetIncidents(branchCode string, filters models.IncidentFilters) ([]models.Incident, error) {
+	conditions := []string{"branch_code = $1"}
+	args := []interface{}{branchCode}
+	argCounter := 2
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCounter))
+		args = append(args, filters.Status)
+		argCounter++
+	}
+	if filters.IncidentType != "" {
+		conditions = append(conditions, fmt.Sprintf("incident_type = $%d", argCounter))
+		args = append(args, filters.IncidentType)
+		argCounter++
+	}
+	if filters.Severity != "" {
+		conditions = append(conditions, fmt.Sprintf("severity = $%d", argCounter))
+		args = append(args, filters.Severity)
+		argCounter++
+	}
+	if filters.StaffID != nil {
+		conditions = append(conditions, fmt.Sprintf("staff_id = $%d", argCounter))
+		args = append(args, *filters.StaffID)
+		argCounter++
+	}
+	if filters.ClientID != nil {
+		conditions = append(conditions, fmt.Sprintf("client_id = $%d", argCounter))
+		args = append(args, *filters.ClientID)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("occurred_at < $%d", argCounter))
+		args = append(args, *filters.To)
+	}
+
+	rows, err := r.db.Query(`SELECT `+incidentColumns+` FROM incidents
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY occurred_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing incidents: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	incidents := []models.Incident{}
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning incident: %v", ErrDatabaseError, err)
+		}
+		incidents = append(incidents, *incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating incidents: %v", ErrDatabaseError, err)
+	}
+
+	pointers := make([]*models.Incident, len(incidents))
+	for i := range incidents {
+		pointers[i] = &incidents[i]
+	}
+	if err := r.loadPhotos(pointers); err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// loadPhotos sets the photos of incidents, without their data, oldest first.
+func (r *incidentRepository) loadPhotos(incidents []*models.Incident) error {
+	if len(incidents) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.Incident, len(incidents))
+	ids := make([]int64, len(incidents))
+	for i, incident := range incidents {
+		byID[incident.ID] = incident
+		ids[i] = incident.ID
+	}
+	rows, err := r.db.Query(`SELECT id, incident_id, content_type, octet_length(data), uploaded_by, created_at
+	                         FROM incident_photos
+	                         WHERE incident_id = ANY($1)
+	                         ORDER BY incident_id, created_at, id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%w: listing incident photos: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var photo models.IncidentPhoto
+		if err := rows.Scan(&photo.ID, &photo.IncidentID, &photo.ContentType, &photo.Size, &photo.UploadedBy, &photo.CreatedAt); err != nil {
+			return fmt.Errorf("%w: scanning incident photo: %v", ErrDatabaseError, err)
+		}
+		incident := byID[photo.IncidentID]
+		incident.Photos = append(incident.Photos, photo)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: iterating incident photos: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *incidentRepository) UpdateIncident(incident *models.Incident, version int) error {
+	err := r.db.QueryRow(`UPDATE incidents
+	                      SET incident_type = $2, severity = $3, description = $4, occurred_at = $5, staff_id = $6, client_id = $7,
+	                          table_id = $8, order_id = $9, penalty_amount = $10, updated_at = $11, version = version + 1
+	                      WHERE id = $1 AND status = $12 AND ($13 = 0 OR version = $13)
+	                      RETURNING updated_at, version`,
+		incident.ID, incident.IncidentType, incident.Severity, incident.Description, incident.OccurredAt, incident.StaffID,
+		incident.ClientID, incident.TableID, incident.OrderID, incident.PenaltyAmount, time.Now().UTC(),
+		models.IncidentStatusOpen, version,
+	).Scan(&incident.UpdatedAt, &incident.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(r.db, "incidents", incident.ID)
+		}
+		return fmt.Errorf("%w: updating incident ID %d: %v", ErrDatabaseError, incident.ID, err)
+	}
+	return nil
+}
+
+func (r *incidentRepository) ReviewIncident(incident *models.Incident) error {
+	err := r.db.QueryRow(`UPDATE incidents
+	                      SET status = $2, penalty_amount = $3, reviewed_by = $4, reviewed_at = $5, review_notes = $6,
+	                          updated_at = $5, version = version + 1
+	                      WHERE id = $1 AND status = $7
+	                      RETURNING updated_at, version`,
+		incident.ID, incident.Status, incident.PenaltyAmount, incident.ReviewedBy, incident.ReviewedAt, incident.ReviewNotes,
+		models.IncidentStatusOpen,
+	).Scan(&incident.UpdatedAt, &incident.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(r.db, "incidents", incident.ID)
+		}
+		return fmt.Errorf("%w: reviewing incident ID %d: %v", ErrDatabaseError, incident.ID, err)
+	}
+	return nil
+}
+
+func (r *incidentRepository) DeleteIncident(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM incidents WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting incident ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for incident ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *incidentRepository) AddPhoto(incidentID int64, photo *models.Photo, uploadedBy int64) (*models.IncidentPhoto, error) {
+	added := &models.IncidentPhoto{IncidentID: incidentID, ContentType: photo.ContentType, Size: len(photo.Data), UploadedBy: &uploadedBy}
+	err := r.db.QueryRow(`INSERT INTO incident_photos (incident_id, content_type, data, uploaded_by, created_at)
+	                      VALUES ($1, $2, $3, $4, $5)
+	                      RETURNING id, created_at`,
+		incidentID, photo.ContentType, photo.Data, uploadedBy, time.Now().UTC(),
+	).Scan(&added.ID, &added.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: adding photo to incident ID %d: %v", ErrDatabaseError, incidentID, err)
+	}
+	return added, nil
+}
+
+func (r *incidentRepository) GetPhoto(incidentID, photoID int64) (*models.Photo, error) {
+	var photo models.Photo
+	err := r.db.QueryRow(`SELECT content_type, data FROM incident_photos WHERE id = $1 AND incident_id = $2`, photoID, incidentID).
+		Scan(&photo.ContentType, &photo.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting photo ID %d of incident ID %d: %v", ErrDatabaseError, photoID, incidentID, err)
+	}
+	return &photo, nil
+}
+
+func (r *incidentRepository) DeletePhoto(incidentID, photoID int64) error {
+	result, err := r.db.Exec(`DELETE FROM incident_photos WHERE id = $1 AND incident_id = $2`, photoID, incidentID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting photo ID %d of incident ID %d: %v", ErrDatabaseError, photoID, incidentID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for photo ID %d of incident ID %d: %v", ErrDatabaseError, photoID, incidentID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *incidentRepository) GetPayrollLines(from, to time.Time) ([]models.PayrollLine, error) {
+	rows, err := r.db.Query(`SELECT sm.id, u.full_name, sm.position, COALESCE(sm.salary, 0),
+	                                COUNT(i.id), COALESCE(SUM(i.penalty_amount), 0)
+	                         FROM staff_members sm
+	                         LEFT JOIN users u ON u.id = sm.user_id
+	                         LEFT JOIN incidents i ON i.staff_id = sm.id AND i.status = $1 AND i.penalty_amount > 0
+	                                              AND i.occurred_at >= $2 AND i.occurred_at < $3
+	                         GROUP BY sm.id, u.full_name, sm.position, sm.salary
+	                         ORDER BY u.full_name NULLS LAST, sm.id`, models.IncidentStatusConfirmed, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing payroll: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	lines := []models.PayrollLine{}
+	for rows.Next() {
+		var line models.PayrollLine
+		if err := rows.Scan(&line.StaffID, &line.FullName, &line.Position, &line.Salary, &line.PenaltyCount, &line.Penalties); err != nil {
+			return nil, fmt.Errorf("%w: scanning payroll line: %v", ErrDatabaseError, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating payroll: %v", ErrDatabaseError, err)
+	}
+	return lines, nil
+}
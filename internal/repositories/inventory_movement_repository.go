@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"ps_club_backend/internal/models"
 	"strings"
@@ -12,7 +11,9 @@ import (
 // InventoryMovementRepository defines the interface for inventory movement-related database operations.
 type InventoryMovementRepository interface {
 	CreateMovement(executor SQLExecutor, movement *models.InventoryMovement) (int64, error)
-	GetMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) ([]models.InventoryMovement, int, error)
+	// GetMovements lists the movements matching the filters, newest first; from and to, if set,
+	// bound movement_date to [from, to).
+	GetMovements(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor *models.Cursor, page, pageSize int) ([]models.InventoryMovement, int, error)
 }
 
 type inventoryMovementRepository struct {
@@ -29,7 +30,7 @@ func (r *inventoryMovementRepository) CreateMovement(executor SQLExecutor, movem
 	          (pricelist_item_id, staff_id, movement_type, quantity_changed, reason, movement_date, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	          RETURNING id`
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	if movement.MovementDate.IsZero() { // Default movement_date to current time if not provided
 		movement.MovementDate = currentTime
 	}
@@ -52,17 +53,15 @@ func (r *inventoryMovementRepository) CreateMovement(executor SQLExecutor, movem
 	return movement.ID, nil
 }
 
-func (r *inventoryMovementRepository) GetMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) ([]models.InventoryMovement, int, error) {
-	movements := []models.InventoryMovement{}
-	totalCount := 0
-
+// movementsListQuery builds the inventory movement list query; see GetMovements.
+func movementsListQuery(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor *models.Cursor, page, pageSize int) (string, []interface{}) {
 	var queryBuilder strings.Builder
 	queryBuilder.WriteString(`SELECT 
 	    im.id, im.pricelist_item_id, im.staff_id, im.movement_type, im.quantity_changed, 
 	    im.reason, im.movement_date, im.created_at, im.updated_at,
 	    pi.name as item_name, pi.sku as item_sku, pi.item_type as item_item_type, pi.tracks_stock as item_tracks_stock,
 	    u.full_name as staff_name,
-	    COUNT(*) OVER() AS total_count
+	    ` + totalCountColumn(cursor == nil) + `
 	  FROM inventory_movements im
 	  JOIN pricelist_items pi ON im.pricelist_item_id = pi.id
 	  LEFT JOIN staff_members sm ON im.staff_id = sm.id
@@ -87,17 +86,45 @@ func (r *inventoryMovementRepository) GetMovements(itemID *int64, staffID *int64
 		args = append(args, *movementType)
 		argCount++
 	}
+	if from != nil {
+		conditions = append(conditions, fmt.Sprintf("im.movement_date >= $%d", argCount))
+		args = append(args, *from)
+		argCount++
+	}
+	if to != nil {
+		conditions = append(conditions, fmt.Sprintf("im.movement_date < $%d", argCount))
+		args = append(args, *to)
+		argCount++
+	}
+	if cursor != nil && !cursor.IsZero() {
+		// The row comparison does not prune partitions, the bound on movement_date does
+		conditions = append(conditions, fmt.Sprintf("(im.movement_date, im.id) < ($%d, $%d) AND im.movement_date <= $%d", argCount, argCount+1, argCount))
+		args = append(args, cursor.Time, cursor.ID)
+		argCount += 2
+	}
 
 	if len(conditions) > 0 {
 		queryBuilder.WriteString(" WHERE ")
 		queryBuilder.WriteString(strings.Join(conditions, " AND "))
 	}
 
-	queryBuilder.WriteString(" ORDER BY im.movement_date DESC, im.created_at DESC")
-	queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1))
-	args = append(args, pageSize, (page-1)*pageSize)
+	queryBuilder.WriteString(" ORDER BY im.movement_date DESC, im.id DESC")
+	if cursor != nil {
+		queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCount))
+		args = append(args, pageSize)
+	} else {
+		queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1))
+		args = append(args, pageSize, (page-1)*pageSize)
+	}
+	return queryBuilder.String(), args
+}
+
+func (r *inventoryMovementRepository) GetMovements(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor *models.Cursor, page, pageSize int) ([]models.InventoryMovement, int, error) {
+	movements := []models.InventoryMovement{}
+	totalCount := 0
 
-	rows, err := r.db.Query(queryBuilder.String(), args...)
+	query, args := movementsListQuery(itemID, staffID, movementType, from, to, cursor, page, pageSize)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: getting inventory movements: %v", ErrDatabaseError, err)
 	}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// InvitationRepository defines the database operations for staff invitations.
+type InvitationRepository interface {
+	CreateInvitation(invitation *models.Invitation) (*models.Invitation, error)
+	// GetInvitations lists the invitations, newest first.
+	GetInvitations() ([]models.Invitation, error)
+	// GetInvitationByTokenHash returns the invitation with the token hash; ErrNotFound if there is none.
+	GetInvitationByTokenHash(tokenHash string) (*models.Invitation, error)
+	// AcceptInvitation marks a pending invitation as accepted by userID. It returns ErrNotFound
+	// if the invitation is accepted, revoked or expired, so an invitation is accepted only once.
+	AcceptInvitation(executor SQLExecutor, id, userID int64, now time.Time) error
+	// RevokeInvitation revokes a pending invitation; ErrNotFound if there is none.
+	RevokeInvitation(id int64, now time.Time) error
+}
+
+type invitationRepository struct {
+	db *sql.DB
+}
+
+// NewInvitationRepository creates a new instance of InvitationRepository.
+func NewInvitationRepository(db *sql.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+const invitationColumns = `i.id, i.token_hash, i.email, i.phone_number, i.full_name, i.role_id, ro.name, i.invited_by,
+	i.expires_at, i.accepted_at, i.accepted_user_id, i.revoked_at, i.created_at`
+
+const invitationFrom = ` FROM staff_invitations i JOIN roles ro ON ro.id = i.role_id`
+
+func scanInvitation(row scanner) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := row.Scan(&invitation.ID, &invitation.TokenHash, &invitation.Email, &invitation.PhoneNumber, &invitation.FullName,
+		&invitation.RoleID, &invitation.RoleName, &invitation.InvitedBy, &invitation.ExpiresAt, &invitation.AcceptedAt,
+		&invitation.AcceptedUserID, &invitation.RevokedAt, &invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) getInvitation(where string, arg interface{}) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(`SELECT `+invitationColumns+invitationFrom+` WHERE `+where, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting invitation: %v", ErrDatabaseError, err)
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepository) CreateInvitation(invitation *models.Invitation) (*models.Invitation, error) {
+	var id int64
+	err := r.db.QueryRow(`INSERT INTO staff_invitations (token_hash, email, phone_number, full_name, role_id, invited_by, expires_at, created_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	                      RETURNING id`,
+		invitation.TokenHash, invitation.Email, invitation.PhoneNumber, invitation.FullName, invitation.RoleID,
+		invitation.InvitedBy, invitation.ExpiresAt, time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating invitation: %v", ErrDatabaseError, err)
+	}
+	return r.getInvitation("i.id = $1", id)
+}
+
+func (r *invitationRepository) GetInvitations() ([]models.Invitation, error) {
+	rows, err := r.db.Query(`SELECT ` + invitationColumns + invitationFrom + ` ORDER BY i.created_at DESC, i.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing invitations: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning invitation: %v", ErrDatabaseError, err)
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating invitations: %v", ErrDatabaseError, err)
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.Invitation, error) {
+	return r.getInvitation("i.token_hash = $1", tokenHash)
+}
+
+func (r *invitationRepository) AcceptInvitation(executor SQLExecutor, id, userID int64, now time.Time) error {
+	result, err := executor.Exec(`UPDATE staff_invitations SET accepted_at = $3, accepted_user_id = $2
+	                              WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $3`,
+		id, userID, now)
+	if err != nil {
+		return fmt.Errorf("%w: accepting invitation %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for invitation %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *invitationRepository) RevokeInvitation(id int64, now time.Time) error {
+	result, err := r.db.Exec(`UPDATE staff_invitations SET revoked_at = $2 WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, id, now)
+	if err != nil {
+		return fmt.Errorf("%w: revoking invitation %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for invitation %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
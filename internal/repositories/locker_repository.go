@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// LockerRepository defines the database operations for lockers and their rentals.
+type LockerRepository interface {
+	// CreateLocker returns ErrDuplicateKey if the branch already has a locker with the number.
+	CreateLocker(locker *models.Locker) error
+	// GetLockerByID returns a locker with its current rental; ErrNotFound if there is none.
+	GetLockerByID(id int64) (*models.Locker, error)
+	// GetLockers returns the lockers of a branch with their current rentals, by number.
+	GetLockers(branchCode string) ([]models.Locker, error)
+	// UpdateLocker updates the number, default fee and deposit and status of a locker. Unless
+	// version is 0 the locker must still have it; ErrVersionConflict if it changed in the meantime.
+	UpdateLocker(locker *models.Locker, version int) error
+
+	// CreateRental returns ErrDuplicateKey if the locker is already rented.
+	CreateRental(executor SQLExecutor, rental *models.LockerRental) error
+	GetRentalByID(id int64) (*models.LockerRental, error)
+	// GetRentals lists the rentals matching filters, newest first.
+	GetRentals(filters models.LockerRentalFilters) ([]models.LockerRental, error)
+	// GetSessionRentals lists the rentals of a table session and, if bookingID is set, of its booking, oldest first.
+	GetSessionRentals(sessionID int64, bookingID *int64) ([]models.LockerRental, error)
+	// ReleaseRental records the release of a rental; ErrVersionConflict if it was already released.
+	ReleaseRental(executor SQLExecutor, rental *models.LockerRental) error
+}
+
+type lockerRepository struct {
+	db *sql.DB
+}
+
+// NewLockerRepository creates a new instance of LockerRepository.
+func NewLockerRepository(db *sql.DB) LockerRepository {
+	return &lockerRepository{db: db}
+}
+
+const lockerRentalColumns = `lr.id, lr.locker_id, l.number, lr.client_id, lr.table_session_id, lr.booking_id, lr.fee, lr.deposit,
+	lr.notes, lr.rented_by, lr.rented_at, lr.released_at, lr.released_by, lr.deposit_returned`
+
+func scanLockerRental(row scanner) (*models.LockerRental, error) {
+	var rental models.LockerRental
+	err := row.Scan(&rental.ID, &rental.LockerID, &rental.LockerNumber, &rental.ClientID, &rental.TableSessionID,
+		&rental.BookingID, &rental.Fee, &rental.Deposit, &rental.Notes, &rental.RentedBy, &rental.RentedAt,
+		&rental.ReleasedAt, &rental.ReleasedBy, &rental.DepositReturned)
+	if err != nil {
+		return nil, err
+	}
+	return &rental, nil
+}
+
+// lockerSelect selects lockers with the columns of their current rental, NULL if they are free.
+const lockerSelect = `SELECT l.id, l.branch_code, l.number, l.rental_fee, l.deposit, l.is_active, l.created_at, l.updated_at, l.version,
+	       lr.id, lr.client_id, lr.table_session_id, lr.booking_id, lr.fee, lr.deposit, lr.notes, lr.rented_by, lr.rented_at
+	FROM lockers l
+	LEFT JOIN locker_rentals lr ON lr.locker_id = l.id AND lr.released_at IS NULL`
+
+func scanLocker(row scanner) (*models.Locker, error) {
+	var locker models.Locker
+	var rentalID sql.NullInt64
+	var rental models.LockerRental
+	var fee, deposit *models.Money
+	var rentedAt sql.NullTime
+	err := row.Scan(&locker.ID, &locker.BranchCode, &locker.Number, &locker.RentalFee, &locker.Deposit, &locker.IsActive,
+		&locker.CreatedAt, &locker.UpdatedAt, &locker.Version,
+		&rentalID, &rental.ClientID, &rental.TableSessionID, &rental.BookingID, &fee, &deposit, &rental.Notes,
+		&rental.RentedBy, &rentedAt)
+	if err != nil {
+		return nil, err
+	}
+	if rentalID.Valid {
+		rental.ID = rentalID.Int64
+		rental.LockerID = locker.ID
+		rental.LockerNumber = locker.Number
+		rental.Fee = *fee
+		rental.Deposit = *deposit
+		rental.RentedAt = rentedAt.Time
+		locker.CurrentRental = &rental
+	}
+	return &locker, nil
+}
+
+func (r *lockerRepository) CreateLocker(locker *models.Locker) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO lockers (branch_code, number, rental_fee, deposit, is_active, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $6)
+	                      RETURNING id, created_at, updated_at, version`,
+		locker.BranchCode, locker.Number, locker.RentalFee, locker.Deposit, locker.IsActive, now,
+	).Scan(&locker.ID, &locker.CreatedAt, &locker.UpdatedAt, &locker.Version)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: locker '%s' already exists", ErrDuplicateKey, locker.Number)
+		}
+		return fmt.Errorf("%w: creating locker: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *lockerRepository) GetLockerByID(id int64) (*models.Locker, error) {
+	locker, err := scanLocker(r.db.QueryRow(lockerSelect+` WHERE l.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting locker ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return locker, nil
+}
+
+func (r *lockerRepository) GetLockers(branchCode string) ([]models.Locker, error) {
+	rows, err := r.db.Query(lockerSelect+` WHERE l.branch_code = $1 ORDER BY length(l.number), l.number, l.id`, branchCode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing lockers: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	lockers := []models.Locker{}
+	for rows.Next() {
+		locker, err := scanLocker(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning locker: %v", ErrDatabaseError, err)
+		}
+		lockers = append(lockers, *locker)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating lockers: %v", ErrDatabaseError, err)
+	}
+	return lockers, nil
+}
+
+func (r *lockerRepository) UpdateLocker(locker *models.Locker, version int) error {
+	err := r.db.QueryRow(`UPDATE lockers
+	                      SET number = $2, rental_fee = $3, deposit = $4, is_active = $5, updated_at = $6, version = version + 1
+	                      WHERE id = $1 AND ($7 = 0 OR version = $7)
+	                      RETURNING updated_at, version`,
+		locker.ID, locker.Number, locker.RentalFee, locker.Deposit, locker.IsActive, time.Now().UTC(), version,
+	).Scan(&locker.UpdatedAt, &locker.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(r.db, "lockers", locker.ID)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: locker '%s' already exists", ErrDuplicateKey, locker.Number)
+		}
+		return fmt.Errorf("%w: updating locker ID %d: %v", ErrDatabaseError, locker.ID, err)
+	}
+	return nil
+}
+
+func (r *lockerRepository) CreateRental(executor SQLExecutor, rental *models.LockerRental) error {
+	err := executor.QueryRow(`INSERT INTO locker_rentals (locker_id, client_id, table_session_id, booking_id, fee, deposit, notes, rented_by, rented_at)
+	                          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	                          RETURNING id`,
+		rental.LockerID, rental.ClientID, rental.TableSessionID, rental.BookingID, rental.Fee, rental.Deposit, rental.Notes,
+		rental.RentedBy, rental.RentedAt,
+	).Scan(&rental.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: locker ID %d is already rented", ErrDuplicateKey, rental.LockerID)
+		}
+		return fmt.Errorf("%w: renting locker ID %d: %v", ErrDatabaseError, rental.LockerID, err)
+	}
+	return nil
+}
+
+func (r *lockerRepository) GetRentalByID(id int64) (*models.LockerRental, error) {
+	rental, err := scanLockerRental(r.db.QueryRow(`SELECT `+lockerRentalColumns+`
+	                                                FROM locker_rentals lr JOIN lockers l ON l.id = lr.locker_id
+	                                                WHERE lr.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting locker rental ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return rental, nil
+}
+
+func (r *lockerRepository) GetRentals(filters models.LockerRentalFilters) ([]models.LockerRental, error) {
+	var conditions []string
+	var args []interface{}
+	argCounter := 1
+
+	if filters.ActiveOnly {
+		conditions = append(conditions, "lr.released_at IS NULL")
+	}
+	if filters.ClientID != nil {
+		conditions = append(conditions, fmt.Sprintf("lr.client_id = $%d", argCounter))
+		args = append(args, *filters.ClientID)
+		argCounter++
+	}
+	if filters.TableSessionID != nil {
+		conditions = append(conditions, fmt.Sprintf("lr.table_session_id = $%d", argCounter))
+		args = append(args, *filters.TableSessionID)
+		argCounter++
+	}
+	if filters.BookingID != nil {
+		conditions = append(conditions, fmt.Sprintf("lr.booking_id = $%d", argCounter))
+		args = append(args, *filters.BookingID)
+	}
+	query := `SELECT ` + lockerRentalColumns + ` FROM locker_rentals lr JOIN lockers l ON l.id = lr.locker_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.listRentals(query+" ORDER BY lr.rented_at DESC, lr.id DESC", args...)
+}
+
+func (r *lockerRepository) GetSessionRentals(sessionID int64, bookingID *int64) ([]models.LockerRental, error) {
+	return r.listRentals(`SELECT `+lockerRentalColumns+`
+	                      FROM locker_rentals lr JOIN lockers l ON l.id = lr.locker_id
+	                      WHERE lr.table_session_id = $1 OR ($2::BIGINT IS NOT NULL AND lr.booking_id = $2)
+	                      ORDER BY lr.rented_at, lr.id`, sessionID, bookingID)
+}
+
+func (r *lockerRepository) listRentals(query string, args ...interface{}) ([]models.LockerRental, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing locker rentals: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	rentals := []models.LockerRental{}
+	for rows.Next() {
+		rental, err := scanLockerRental(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning locker rental: %v", ErrDatabaseError, err)
+		}
+		rentals = append(rentals, *rental)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating locker rentals: %v", ErrDatabaseError, err)
+	}
+	return rentals, nil
+}
+
+func (r *lockerRepository) ReleaseRental(executor SQLExecutor, rental *models.LockerRental) error {
+	result, err := executor.Exec(`UPDATE locker_rentals SET released_at = $2, released_by = $3, deposit_returned = $4
+	                              WHERE id = $1 AND released_at IS NULL`,
+		rental.ID, rental.ReleasedAt, rental.ReleasedBy, rental.DepositReturned)
+	if err != nil {
+		return fmt.Errorf("%w: releasing locker rental ID %d: %v", ErrDatabaseError, rental.ID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for locker rental ID %d: %v", ErrDatabaseError, rental.ID, err)
+	}
+	if rowsAffected == 0 {
+		return versionMismatchError(executor, "locker_rentals", rental.ID)
+	}
+	return nil
+}
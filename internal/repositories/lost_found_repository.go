@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// LostFoundRepository defines the database operations for the lost and found log.
+type LostFoundRepository interface {
+	CreateItem(item *models.LostFoundItem) error
+	// GetItemByID returns an item without its photo; ErrNotFound if there is none.
+	GetItemByID(id int64) (*models.LostFoundItem, error)
+	// GetItems lists the items of a branch matching filters, most recently found first.
+	GetItems(branchCode string, filters models.LostFoundFilters) ([]models.LostFoundItem, error)
+	// UpdateItem updates the description, table, found time and notes of an item. Unless
+	// version is 0 the item must still have it; ErrVersionConflict if it changed in the meantime.
+	UpdateItem(item *models.LostFoundItem, version int) error
+	// MarkItemReturned records the return of an item; ErrVersionConflict if it was already returned.
+	MarkItemReturned(item *models.LostFoundItem) error
+	// SetItemPhoto replaces the photo of an item, or removes it if photo is nil.
+	SetItemPhoto(id int64, photo *models.Photo) error
+	// GetItemPhoto returns the photo of an item; ErrNotFound if the item or its photo does not exist.
+	GetItemPhoto(id int64) (*models.Photo, error)
+	DeleteItem(id int64) error
+	// DeleteItemsFoundBefore purges the items found before cutoff and returns how many were deleted.
+	DeleteItemsFoundBefore(cutoff time.Time) (int64, error)
+}
+
+type lostFoundRepository struct {
+	db *sql.DB
+}
+
+// NewLostFoundRepository creates a new instance of LostFoundRepository.
+func NewLostFoundRepository(db *sql.DB) LostFoundRepository {
+	return &lostFoundRepository{db: db}
+}
+
+const lostFoundItemColumns = `id, branch_code, description, table_id, found_at, found_by, notes, photo IS NOT NULL, status,
+	returned_to_client_id, returned_to_name, returned_at, returned_by, created_at, updated_at, version`
+
+func scanLostFoundItem(row scanner) (*models.LostFoundItem, error) {
+	var item models.LostFoundItem
+	err := row.Scan(&item.ID, &item.BranchCode, &item.Description, &item.TableID, &item.FoundAt, &item.FoundBy, &item.Notes,
+		&item.HasPhoto, &item.Status, &item.ReturnedToClientID, &item.ReturnedToName, &item.ReturnedAt, &item.ReturnedBy,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *lostFoundRepository) CreateItem(item *models.LostFoundItem) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO lost_found_items (branch_code, description, table_id, found_at, found_by, notes, status, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	                      RETURNING id, created_at, updated_at, version`,
+		item.BranchCode, item.Description, item.TableID, item.FoundAt, item.FoundBy, item.Notes, item.Status, now,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt, &item.Version)
+	if err != nil {
+		return fmt.Errorf("%w: creating lost and found item: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *lostFoundRepository) GetItemByID(id int64) (*models.LostFoundItem, error) {
+	item, err := scanLostFoundItem(r.db.QueryRow(`SELECT `+lostFoundItemColumns+` FROM lost_found_items WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return item, nil
+}
+
+func (r *lostFoundRepository) GetItems(branchCode string, filters models.LostFoundFilters) ([]models.LostFoundItem, error) {
+	conditions := []string{"branch_code = $1"}
+	args := []interface{}{branchCode}
+	argCounter := 2
+
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCounter))
+		args = append(args, filters.Status)
+		argCounter++
+	}
+	if filters.TableID != nil {
+		conditions = append(conditions, fmt.Sprintf("table_id = $%d", argCounter))
+		args = append(args, *filters.TableID)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("found_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("found_at < $%d", argCounter))
+		args = append(args, *filters.To)
+		argCounter++
+	}
+	if filters.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("description ILIKE $%d", argCounter))
+		args = append(args, "%"+filters.Search+"%")
+	}
+
+	rows, err := r.db.Query(`SELECT `+lostFoundItemColumns+` FROM lost_found_items
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY found_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing lost and found items: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	items := []models.LostFoundItem{}
+	for rows.Next() {
+		item, err := scanLostFoundItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning lost and found item: %v", ErrDatabaseError, err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating lost and found items: %v", ErrDatabaseError, err)
+	}
+	return items, nil
+}
+
+func (r *lostFoundRepository) UpdateItem(item *models.LostFoundItem, version int) error {
+	err := r.db.QueryRow(`UPDATE lost_found_items
+	                      SET description = $2, table_id = $3, found_at = $4, notes = $5, updated_at = $6, version = version + 1
+	                      WHERE id = $1 AND ($7 = 0 OR version = $7)
+	                      RETURNING updated_at, version`,
+		item.ID, item.Description, item.TableID, item.FoundAt, item.Notes, time.Now().UTC(), version,
+	).Scan(&item.UpdatedAt, &item.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(r.db, "lost_found_items", item.ID)
+		}
+		return fmt.Errorf("%w: updating lost and found item ID %d: %v", ErrDatabaseError, item.ID, err)
+	}
+	return nil
+}
+
+func (r *lostFoundRepository) MarkItemReturned(item *models.LostFoundItem) error {
+	err := r.db.QueryRow(`UPDATE lost_found_items
+	                      SET status = $2, returned_to_client_id = $3, returned_to_name = $4, returned_at = $5, returned_by = $6,
+	                          updated_at = $5, version = version + 1
+	                      WHERE id = $1 AND status <> $2
+	                      RETURNING updated_at, version`,
+		item.ID, models.LostFoundStatusReturned, item.ReturnedToClientID, item.ReturnedToName, item.ReturnedAt, item.ReturnedBy,
+	).Scan(&item.UpdatedAt, &item.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(r.db, "lost_found_items", item.ID)
+		}
+		return fmt.Errorf("%w: returning lost and found item ID %d: %v", ErrDatabaseError, item.ID, err)
+	}
+	item.Status = models.LostFoundStatusReturned
+	return nil
+}
+
+func (r *lostFoundRepository) SetItemPhoto(id int64, photo *models.Photo) error {
+	var data []byte
+	var contentType *string
+	if photo != nil {
+		data = photo.Data
+		contentType = &photo.ContentType
+	}
+	result, err := r.db.Exec(`UPDATE lost_found_items SET photo = $2, photo_content_type = $3, updated_at = $4 WHERE id = $1`,
+		id, data, contentType, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting photo of lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *lostFoundRepository) GetItemPhoto(id int64) (*models.Photo, error) {
+	var photo models.Photo
+	err := r.db.QueryRow(`SELECT photo_content_type, photo FROM lost_found_items WHERE id = $1 AND photo IS NOT NULL`, id).
+		Scan(&photo.ContentType, &photo.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting photo of lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return &photo, nil
+}
+
+func (r *lostFoundRepository) DeleteItem(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM lost_found_items WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for lost and found item ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *lostFoundRepository) DeleteItemsFoundBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM lost_found_items WHERE found_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("%w: purging lost and found items: %v", ErrDatabaseError, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%w: checking purged lost and found items: %v", ErrDatabaseError, err)
+	}
+	return deleted, nil
+}
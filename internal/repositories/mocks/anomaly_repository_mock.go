@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockAnomalyRepository is a hand-written mock of repositories.AnomalyRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockAnomalyRepository struct {
+	GetDailyMetricsFunc func(string, []string, []string, string, string) ([]models.RiskMetrics, error)
+	HasCheckFunc        func(string, string) (bool, error)
+	CreateCheckFunc     func(repositories.SQLExecutor, *models.AnomalyCheck) (bool, error)
+	GetChecksFunc       func(string, string, string, bool) ([]models.AnomalyCheck, error)
+}
+
+var _ repositories.AnomalyRepository = (*MockAnomalyRepository)(nil)
+
+func (m *MockAnomalyRepository) GetDailyMetrics(branchCode string, statuses []string, deleteActions []string, fromDate, toDate string) ([]models.RiskMetrics, error) {
+	if m.GetDailyMetricsFunc == nil {
+		panic("mocks: MockAnomalyRepository.GetDailyMetrics called but GetDailyMetricsFunc is not set")
+	}
+	return m.GetDailyMetricsFunc(branchCode, statuses, deleteActions, fromDate, toDate)
+}
+
+func (m *MockAnomalyRepository) HasCheck(branchCode, businessDate string) (bool, error) {
+	if m.HasCheckFunc == nil {
+		panic("mocks: MockAnomalyRepository.HasCheck called but HasCheckFunc is not set")
+	}
+	return m.HasCheckFunc(branchCode, businessDate)
+}
+
+func (m *MockAnomalyRepository) CreateCheck(executor repositories.SQLExecutor, check *models.AnomalyCheck) (bool, error) {
+	if m.CreateCheckFunc == nil {
+		panic("mocks: MockAnomalyRepository.CreateCheck called but CreateCheckFunc is not set")
+	}
+	return m.CreateCheckFunc(executor, check)
+}
+
+func (m *MockAnomalyRepository) GetChecks(branchCode, fromDate, toDate string, onlyAnomalies bool) ([]models.AnomalyCheck, error) {
+	if m.GetChecksFunc == nil {
+		panic("mocks: MockAnomalyRepository.GetChecks called but GetChecksFunc is not set")
+	}
+	return m.GetChecksFunc(branchCode, fromDate, toDate, onlyAnomalies)
+}
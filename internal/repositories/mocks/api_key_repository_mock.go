@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockAPIKeyRepository is a hand-written mock of repositories.APIKeyRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockAPIKeyRepository struct {
+	CreateAPIKeyFunc          func(*models.APIKey) (*models.APIKey, error)
+	GetAPIKeysFunc            func() ([]models.APIKey, error)
+	GetActiveAPIKeyByHashFunc func(string) (*models.APIKey, error)
+	RevokeAPIKeyFunc          func(int64, time.Time) error
+	TouchAPIKeyFunc           func(int64, time.Time) error
+}
+
+var _ repositories.APIKeyRepository = (*MockAPIKeyRepository)(nil)
+
+func (m *MockAPIKeyRepository) CreateAPIKey(key *models.APIKey) (*models.APIKey, error) {
+	if m.CreateAPIKeyFunc == nil {
+		panic("mocks: MockAPIKeyRepository.CreateAPIKey called but CreateAPIKeyFunc is not set")
+	}
+	return m.CreateAPIKeyFunc(key)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeys() ([]models.APIKey, error) {
+	if m.GetAPIKeysFunc == nil {
+		panic("mocks: MockAPIKeyRepository.GetAPIKeys called but GetAPIKeysFunc is not set")
+	}
+	return m.GetAPIKeysFunc()
+}
+
+func (m *MockAPIKeyRepository) GetActiveAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	if m.GetActiveAPIKeyByHashFunc == nil {
+		panic("mocks: MockAPIKeyRepository.GetActiveAPIKeyByHash called but GetActiveAPIKeyByHashFunc is not set")
+	}
+	return m.GetActiveAPIKeyByHashFunc(keyHash)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(id int64, now time.Time) error {
+	if m.RevokeAPIKeyFunc == nil {
+		panic("mocks: MockAPIKeyRepository.RevokeAPIKey called but RevokeAPIKeyFunc is not set")
+	}
+	return m.RevokeAPIKeyFunc(id, now)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(id int64, now time.Time) error {
+	if m.TouchAPIKeyFunc == nil {
+		panic("mocks: MockAPIKeyRepository.TouchAPIKey called but TouchAPIKeyFunc is not set")
+	}
+	return m.TouchAPIKeyFunc(id, now)
+}
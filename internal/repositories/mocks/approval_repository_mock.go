@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockApprovalRepository is a hand-written mock of repositories.ApprovalRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockApprovalRepository struct {
+	CreateApprovalFunc    func(repositories.SQLExecutor, *models.Approval) (*models.Approval, error)
+	GetApprovalByIDFunc   func(int64) (*models.Approval, error)
+	GetApprovalsFunc      func(*string, int, int) ([]models.Approval, int, error)
+	DecideApprovalFunc    func(repositories.SQLExecutor, int64, string, int64, *string, time.Time) error
+	SetApprovalResultFunc func(repositories.SQLExecutor, int64, string, *int64, *string) error
+}
+
+var _ repositories.ApprovalRepository = (*MockApprovalRepository)(nil)
+
+func (m *MockApprovalRepository) CreateApproval(executor repositories.SQLExecutor, approval *models.Approval) (*models.Approval, error) {
+	if m.CreateApprovalFunc == nil {
+		panic("mocks: MockApprovalRepository.CreateApproval called but CreateApprovalFunc is not set")
+	}
+	return m.CreateApprovalFunc(executor, approval)
+}
+
+func (m *MockApprovalRepository) GetApprovalByID(id int64) (*models.Approval, error) {
+	if m.GetApprovalByIDFunc == nil {
+		panic("mocks: MockApprovalRepository.GetApprovalByID called but GetApprovalByIDFunc is not set")
+	}
+	return m.GetApprovalByIDFunc(id)
+}
+
+func (m *MockApprovalRepository) GetApprovals(status *string, page, pageSize int) ([]models.Approval, int, error) {
+	if m.GetApprovalsFunc == nil {
+		panic("mocks: MockApprovalRepository.GetApprovals called but GetApprovalsFunc is not set")
+	}
+	return m.GetApprovalsFunc(status, page, pageSize)
+}
+
+func (m *MockApprovalRepository) DecideApproval(executor repositories.SQLExecutor, id int64, status string, decidedBy int64, reason *string, decidedAt time.Time) error {
+	if m.DecideApprovalFunc == nil {
+		panic("mocks: MockApprovalRepository.DecideApproval called but DecideApprovalFunc is not set")
+	}
+	return m.DecideApprovalFunc(executor, id, status, decidedBy, reason, decidedAt)
+}
+
+func (m *MockApprovalRepository) SetApprovalResult(executor repositories.SQLExecutor, id int64, status string, targetID *int64, resultError *string) error {
+	if m.SetApprovalResultFunc == nil {
+		panic("mocks: MockApprovalRepository.SetApprovalResult called but SetApprovalResultFunc is not set")
+	}
+	return m.SetApprovalResultFunc(executor, id, status, targetID, resultError)
+}
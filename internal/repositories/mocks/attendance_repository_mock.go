@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockAttendanceRepository is a hand-written mock of repositories.AttendanceRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockAttendanceRepository struct {
+	GetShiftsFunc           func(time.Time, time.Time, *int64) ([]models.AttendanceShift, error)
+	GetTimeClockEntriesFunc func(time.Time, time.Time, *int64) ([]models.TimeClockEntry, error)
+}
+
+var _ repositories.AttendanceRepository = (*MockAttendanceRepository)(nil)
+
+func (m *MockAttendanceRepository) GetShifts(from, to time.Time, staffID *int64) ([]models.AttendanceShift, error) {
+	if m.GetShiftsFunc == nil {
+		panic("mocks: MockAttendanceRepository.GetShifts called but GetShiftsFunc is not set")
+	}
+	return m.GetShiftsFunc(from, to, staffID)
+}
+
+func (m *MockAttendanceRepository) GetTimeClockEntries(from, to time.Time, staffID *int64) ([]models.TimeClockEntry, error) {
+	if m.GetTimeClockEntriesFunc == nil {
+		panic("mocks: MockAttendanceRepository.GetTimeClockEntries called but GetTimeClockEntriesFunc is not set")
+	}
+	return m.GetTimeClockEntriesFunc(from, to, staffID)
+}
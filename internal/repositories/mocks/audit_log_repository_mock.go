@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockAuditLogRepository is a hand-written mock of repositories.AuditLogRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockAuditLogRepository struct {
+	CreateEntryFunc func(repositories.SQLExecutor, *models.AuditLogEntry) error
+	GetEntriesFunc  func(models.AuditLogFilters) ([]models.AuditLogEntry, int, error)
+}
+
+var _ repositories.AuditLogRepository = (*MockAuditLogRepository)(nil)
+
+func (m *MockAuditLogRepository) CreateEntry(executor repositories.SQLExecutor, entry *models.AuditLogEntry) error {
+	if m.CreateEntryFunc == nil {
+		panic("mocks: MockAuditLogRepository.CreateEntry called but CreateEntryFunc is not set")
+	}
+	return m.CreateEntryFunc(executor, entry)
+}
+
+func (m *MockAuditLogRepository) GetEntries(filters models.AuditLogFilters) ([]models.AuditLogEntry, int, error) {
+	if m.GetEntriesFunc == nil {
+		panic("mocks: MockAuditLogRepository.GetEntries called but GetEntriesFunc is not set")
+	}
+	return m.GetEntriesFunc(filters)
+}
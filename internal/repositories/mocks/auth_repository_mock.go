@@ -0,0 +1,102 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockAuthRepository is a hand-written mock of repositories.AuthRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockAuthRepository struct {
+	CreateUserFunc           func(repositories.SQLExecutor, *models.User, string) (int64, error)
+	FindUserByUsernameFunc   func(string) (*models.User, string, error)
+	FindUserByIDFunc         func(int64) (*models.User, error)
+	SetApprovalPINFunc       func(int64, string) error
+	GetAdminApprovalPINFunc  func(int64) (*models.ApprovalPIN, error)
+	GetAdminEmailsFunc       func() ([]string, error)
+	SetPreferredLanguageFunc func(int64, *string) error
+	GetRolePermissionsFunc   func(string) ([]string, error)
+	UpdateProfileFunc        func(int64, models.UserProfile) error
+	SetAvatarFunc            func(int64, *models.Photo) error
+	GetAvatarFunc            func(int64) (*models.Photo, error)
+}
+
+var _ repositories.AuthRepository = (*MockAuthRepository)(nil)
+
+func (m *MockAuthRepository) CreateUser(executor repositories.SQLExecutor, user *models.User, hashedPassword string) (int64, error) {
+	if m.CreateUserFunc == nil {
+		panic("mocks: MockAuthRepository.CreateUser called but CreateUserFunc is not set")
+	}
+	return m.CreateUserFunc(executor, user, hashedPassword)
+}
+
+func (m *MockAuthRepository) FindUserByUsername(username string) (*models.User, string, error) {
+	if m.FindUserByUsernameFunc == nil {
+		panic("mocks: MockAuthRepository.FindUserByUsername called but FindUserByUsernameFunc is not set")
+	}
+	return m.FindUserByUsernameFunc(username)
+}
+
+func (m *MockAuthRepository) FindUserByID(userID int64) (*models.User, error) {
+	if m.FindUserByIDFunc == nil {
+		panic("mocks: MockAuthRepository.FindUserByID called but FindUserByIDFunc is not set")
+	}
+	return m.FindUserByIDFunc(userID)
+}
+
+func (m *MockAuthRepository) SetApprovalPIN(userID int64, pinHash string) error {
+	if m.SetApprovalPINFunc == nil {
+		panic("mocks: MockAuthRepository.SetApprovalPIN called but SetApprovalPINFunc is not set")
+	}
+	return m.SetApprovalPINFunc(userID, pinHash)
+}
+
+func (m *MockAuthRepository) GetAdminApprovalPIN(userID int64) (*models.ApprovalPIN, error) {
+	if m.GetAdminApprovalPINFunc == nil {
+		panic("mocks: MockAuthRepository.GetAdminApprovalPIN called but GetAdminApprovalPINFunc is not set")
+	}
+	return m.GetAdminApprovalPINFunc(userID)
+}
+
+func (m *MockAuthRepository) GetAdminEmails() ([]string, error) {
+	if m.GetAdminEmailsFunc == nil {
+		panic("mocks: MockAuthRepository.GetAdminEmails called but GetAdminEmailsFunc is not set")
+	}
+	return m.GetAdminEmailsFunc()
+}
+
+func (m *MockAuthRepository) SetPreferredLanguage(userID int64, language *string) error {
+	if m.SetPreferredLanguageFunc == nil {
+		panic("mocks: MockAuthRepository.SetPreferredLanguage called but SetPreferredLanguageFunc is not set")
+	}
+	return m.SetPreferredLanguageFunc(userID, language)
+}
+
+func (m *MockAuthRepository) GetRolePermissions(roleName string) ([]string, error) {
+	if m.GetRolePermissionsFunc == nil {
+		panic("mocks: MockAuthRepository.GetRolePermissions called but GetRolePermissionsFunc is not set")
+	}
+	return m.GetRolePermissionsFunc(roleName)
+}
+
+func (m *MockAuthRepository) UpdateProfile(userID int64, profile models.UserProfile) error {
+	if m.UpdateProfileFunc == nil {
+		panic("mocks: MockAuthRepository.UpdateProfile called but UpdateProfileFunc is not set")
+	}
+	return m.UpdateProfileFunc(userID, profile)
+}
+
+func (m *MockAuthRepository) SetAvatar(userID int64, avatar *models.Photo) error {
+	if m.SetAvatarFunc == nil {
+		panic("mocks: MockAuthRepository.SetAvatar called but SetAvatarFunc is not set")
+	}
+	return m.SetAvatarFunc(userID, avatar)
+}
+
+func (m *MockAuthRepository) GetAvatar(userID int64) (*models.Photo, error) {
+	if m.GetAvatarFunc == nil {
+		panic("mocks: MockAuthRepository.GetAvatar called but GetAvatarFunc is not set")
+	}
+	return m.GetAvatarFunc(userID)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockBackupRepository is a hand-written mock of repositories.BackupRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockBackupRepository struct {
+	CreateBackupFunc  func(repositories.SQLExecutor, *models.Backup) (*models.Backup, error)
+	GetBackupByIDFunc func(int64) (*models.Backup, error)
+	GetBackupsFunc    func(int) ([]models.Backup, error)
+	FinishBackupFunc  func(repositories.SQLExecutor, int64, string, *string, *int64, *string, time.Time) error
+}
+
+var _ repositories.BackupRepository = (*MockBackupRepository)(nil)
+
+func (m *MockBackupRepository) CreateBackup(executor repositories.SQLExecutor, backup *models.Backup) (*models.Backup, error) {
+	if m.CreateBackupFunc == nil {
+		panic("mocks: MockBackupRepository.CreateBackup called but CreateBackupFunc is not set")
+	}
+	return m.CreateBackupFunc(executor, backup)
+}
+
+func (m *MockBackupRepository) GetBackupByID(id int64) (*models.Backup, error) {
+	if m.GetBackupByIDFunc == nil {
+		panic("mocks: MockBackupRepository.GetBackupByID called but GetBackupByIDFunc is not set")
+	}
+	return m.GetBackupByIDFunc(id)
+}
+
+func (m *MockBackupRepository) GetBackups(limit int) ([]models.Backup, error) {
+	if m.GetBackupsFunc == nil {
+		panic("mocks: MockBackupRepository.GetBackups called but GetBackupsFunc is not set")
+	}
+	return m.GetBackupsFunc(limit)
+}
+
+func (m *MockBackupRepository) FinishBackup(executor repositories.SQLExecutor, id int64, status string, location *string, sizeBytes *int64, errMsg *string, finishedAt time.Time) error {
+	if m.FinishBackupFunc == nil {
+		panic("mocks: MockBackupRepository.FinishBackup called but FinishBackupFunc is not set")
+	}
+	return m.FinishBackupFunc(executor, id, status, location, sizeBytes, errMsg, finishedAt)
+}
@@ -0,0 +1,128 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockBookingRepository is a hand-written mock of repositories.BookingRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockBookingRepository struct {
+	CreateBookingFunc            func(repositories.SQLExecutor, *models.Booking) (*models.Booking, error)
+	GetBookingByIDFunc           func(int64) (*models.Booking, error)
+	GetBookingsFunc              func(models.BookingFilters) ([]models.Booking, int, error)
+	StreamBookingsFunc           func(models.BookingFilters, func(*models.Booking) error) error
+	UpdateBookingFunc            func(repositories.SQLExecutor, *models.Booking) (*models.Booking, error)
+	DeleteBookingFunc            func(repositories.SQLExecutor, int64, int64, time.Time) error
+	RestoreBookingFunc           func(repositories.SQLExecutor, int64, time.Time) error
+	CheckTableAvailabilityFunc   func(int64, time.Time, time.Time, int, *int64) (bool, error)
+	CreateBookingChangesFunc     func(repositories.SQLExecutor, []models.BookingChange) error
+	GetBookingChangesFunc        func(int64) ([]models.BookingChange, error)
+	GetBookingByCheckInTokenFunc func(string) (*models.Booking, error)
+	GetBookingByUUIDFunc         func(string) (*models.Booking, error)
+	CheckInBookingFunc           func(repositories.SQLExecutor, *models.Booking, time.Time) (*models.Booking, error)
+	GetGameTableByIDFunc         func(int64) (*models.GameTable, error)
+}
+
+var _ repositories.BookingRepository = (*MockBookingRepository)(nil)
+
+func (m *MockBookingRepository) CreateBooking(executor repositories.SQLExecutor, booking *models.Booking) (*models.Booking, error) {
+	if m.CreateBookingFunc == nil {
+		panic("mocks: MockBookingRepository.CreateBooking called but CreateBookingFunc is not set")
+	}
+	return m.CreateBookingFunc(executor, booking)
+}
+
+func (m *MockBookingRepository) GetBookingByID(id int64) (*models.Booking, error) {
+	if m.GetBookingByIDFunc == nil {
+		panic("mocks: MockBookingRepository.GetBookingByID called but GetBookingByIDFunc is not set")
+	}
+	return m.GetBookingByIDFunc(id)
+}
+
+func (m *MockBookingRepository) GetBookings(filters models.BookingFilters) ([]models.Booking, int, error) {
+	if m.GetBookingsFunc == nil {
+		panic("mocks: MockBookingRepository.GetBookings called but GetBookingsFunc is not set")
+	}
+	return m.GetBookingsFunc(filters)
+}
+
+func (m *MockBookingRepository) StreamBookings(filters models.BookingFilters, fn func(*models.Booking) error) error {
+	if m.StreamBookingsFunc == nil {
+		panic("mocks: MockBookingRepository.StreamBookings called but StreamBookingsFunc is not set")
+	}
+	return m.StreamBookingsFunc(filters, fn)
+}
+
+func (m *MockBookingRepository) UpdateBooking(executor repositories.SQLExecutor, booking *models.Booking) (*models.Booking, error) {
+	if m.UpdateBookingFunc == nil {
+		panic("mocks: MockBookingRepository.UpdateBooking called but UpdateBookingFunc is not set")
+	}
+	return m.UpdateBookingFunc(executor, booking)
+}
+
+func (m *MockBookingRepository) DeleteBooking(executor repositories.SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	if m.DeleteBookingFunc == nil {
+		panic("mocks: MockBookingRepository.DeleteBooking called but DeleteBookingFunc is not set")
+	}
+	return m.DeleteBookingFunc(executor, id, deletedBy, now)
+}
+
+func (m *MockBookingRepository) RestoreBooking(executor repositories.SQLExecutor, id int64, now time.Time) error {
+	if m.RestoreBookingFunc == nil {
+		panic("mocks: MockBookingRepository.RestoreBooking called but RestoreBookingFunc is not set")
+	}
+	return m.RestoreBookingFunc(executor, id, now)
+}
+
+func (m *MockBookingRepository) CheckTableAvailability(tableID int64, startTime time.Time, endTime time.Time, defaultBufferMinutes int, excludeBookingID *int64) (bool, error) {
+	if m.CheckTableAvailabilityFunc == nil {
+		panic("mocks: MockBookingRepository.CheckTableAvailability called but CheckTableAvailabilityFunc is not set")
+	}
+	return m.CheckTableAvailabilityFunc(tableID, startTime, endTime, defaultBufferMinutes, excludeBookingID)
+}
+
+func (m *MockBookingRepository) CreateBookingChanges(executor repositories.SQLExecutor, changes []models.BookingChange) error {
+	if m.CreateBookingChangesFunc == nil {
+		panic("mocks: MockBookingRepository.CreateBookingChanges called but CreateBookingChangesFunc is not set")
+	}
+	return m.CreateBookingChangesFunc(executor, changes)
+}
+
+func (m *MockBookingRepository) GetBookingChanges(bookingID int64) ([]models.BookingChange, error) {
+	if m.GetBookingChangesFunc == nil {
+		panic("mocks: MockBookingRepository.GetBookingChanges called but GetBookingChangesFunc is not set")
+	}
+	return m.GetBookingChangesFunc(bookingID)
+}
+
+func (m *MockBookingRepository) GetBookingByCheckInToken(token string) (*models.Booking, error) {
+	if m.GetBookingByCheckInTokenFunc == nil {
+		panic("mocks: MockBookingRepository.GetBookingByCheckInToken called but GetBookingByCheckInTokenFunc is not set")
+	}
+	return m.GetBookingByCheckInTokenFunc(token)
+}
+
+func (m *MockBookingRepository) GetBookingByUUID(uuid string) (*models.Booking, error) {
+	if m.GetBookingByUUIDFunc == nil {
+		panic("mocks: MockBookingRepository.GetBookingByUUID called but GetBookingByUUIDFunc is not set")
+	}
+	return m.GetBookingByUUIDFunc(uuid)
+}
+
+func (m *MockBookingRepository) CheckInBooking(executor repositories.SQLExecutor, booking *models.Booking, checkedInAt time.Time) (*models.Booking, error) {
+	if m.CheckInBookingFunc == nil {
+		panic("mocks: MockBookingRepository.CheckInBooking called but CheckInBookingFunc is not set")
+	}
+	return m.CheckInBookingFunc(executor, booking, checkedInAt)
+}
+
+func (m *MockBookingRepository) GetGameTableByID(id int64) (*models.GameTable, error) {
+	if m.GetGameTableByIDFunc == nil {
+		panic("mocks: MockBookingRepository.GetGameTableByID called but GetGameTableByIDFunc is not set")
+	}
+	return m.GetGameTableByIDFunc(id)
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockCalendarNoteRepository is a hand-written mock of repositories.CalendarNoteRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockCalendarNoteRepository struct {
+	CreateNoteFunc  func(*models.CalendarNote) error
+	GetNoteByIDFunc func(int64) (*models.CalendarNote, error)
+	GetNotesFunc    func(string, string, string) ([]models.CalendarNote, error)
+	UpdateNoteFunc  func(*models.CalendarNote) error
+	DeleteNoteFunc  func(int64) error
+}
+
+var _ repositories.CalendarNoteRepository = (*MockCalendarNoteRepository)(nil)
+
+func (m *MockCalendarNoteRepository) CreateNote(note *models.CalendarNote) error {
+	if m.CreateNoteFunc == nil {
+		panic("mocks: MockCalendarNoteRepository.CreateNote called but CreateNoteFunc is not set")
+	}
+	return m.CreateNoteFunc(note)
+}
+
+func (m *MockCalendarNoteRepository) GetNoteByID(id int64) (*models.CalendarNote, error) {
+	if m.GetNoteByIDFunc == nil {
+		panic("mocks: MockCalendarNoteRepository.GetNoteByID called but GetNoteByIDFunc is not set")
+	}
+	return m.GetNoteByIDFunc(id)
+}
+
+func (m *MockCalendarNoteRepository) GetNotes(branchCode, fromDate, toDate string) ([]models.CalendarNote, error) {
+	if m.GetNotesFunc == nil {
+		panic("mocks: MockCalendarNoteRepository.GetNotes called but GetNotesFunc is not set")
+	}
+	return m.GetNotesFunc(branchCode, fromDate, toDate)
+}
+
+func (m *MockCalendarNoteRepository) UpdateNote(note *models.CalendarNote) error {
+	if m.UpdateNoteFunc == nil {
+		panic("mocks: MockCalendarNoteRepository.UpdateNote called but UpdateNoteFunc is not set")
+	}
+	return m.UpdateNoteFunc(note)
+}
+
+func (m *MockCalendarNoteRepository) DeleteNote(id int64) error {
+	if m.DeleteNoteFunc == nil {
+		panic("mocks: MockCalendarNoteRepository.DeleteNote called but DeleteNoteFunc is not set")
+	}
+	return m.DeleteNoteFunc(id)
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockClientAccountRepository is a hand-written mock of repositories.ClientAccountRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockClientAccountRepository struct {
+	CreateAccountFunc   func(repositories.SQLExecutor, *models.ClientAccount) error
+	GetAccountFunc      func(repositories.SQLExecutor, int64, time.Time, bool) (*models.ClientAccount, error)
+	GetAccountsFunc     func(time.Time, bool) ([]models.ClientAccount, error)
+	UpdateAccountFunc   func(repositories.SQLExecutor, *models.ClientAccount, int) error
+	CreateEntryFunc     func(repositories.SQLExecutor, *models.ClientAccountEntry) error
+	GetEntriesFunc      func(int64, time.Time, time.Time) ([]models.ClientAccountEntry, error)
+	GetBalanceAtFunc    func(int64, time.Time) (models.Money, error)
+	GetOrderBalanceFunc func(repositories.SQLExecutor, int64) (int64, models.Money, error)
+}
+
+var _ repositories.ClientAccountRepository = (*MockClientAccountRepository)(nil)
+
+func (m *MockClientAccountRepository) CreateAccount(executor repositories.SQLExecutor, account *models.ClientAccount) error {
+	if m.CreateAccountFunc == nil {
+		panic("mocks: MockClientAccountRepository.CreateAccount called but CreateAccountFunc is not set")
+	}
+	return m.CreateAccountFunc(executor, account)
+}
+
+func (m *MockClientAccountRepository) GetAccount(executor repositories.SQLExecutor, clientID int64, now time.Time, lock bool) (*models.ClientAccount, error) {
+	if m.GetAccountFunc == nil {
+		panic("mocks: MockClientAccountRepository.GetAccount called but GetAccountFunc is not set")
+	}
+	return m.GetAccountFunc(executor, clientID, now, lock)
+}
+
+func (m *MockClientAccountRepository) GetAccounts(now time.Time, overdueOnly bool) ([]models.ClientAccount, error) {
+	if m.GetAccountsFunc == nil {
+		panic("mocks: MockClientAccountRepository.GetAccounts called but GetAccountsFunc is not set")
+	}
+	return m.GetAccountsFunc(now, overdueOnly)
+}
+
+func (m *MockClientAccountRepository) UpdateAccount(executor repositories.SQLExecutor, account *models.ClientAccount, version int) error {
+	if m.UpdateAccountFunc == nil {
+		panic("mocks: MockClientAccountRepository.UpdateAccount called but UpdateAccountFunc is not set")
+	}
+	return m.UpdateAccountFunc(executor, account, version)
+}
+
+func (m *MockClientAccountRepository) CreateEntry(executor repositories.SQLExecutor, entry *models.ClientAccountEntry) error {
+	if m.CreateEntryFunc == nil {
+		panic("mocks: MockClientAccountRepository.CreateEntry called but CreateEntryFunc is not set")
+	}
+	return m.CreateEntryFunc(executor, entry)
+}
+
+func (m *MockClientAccountRepository) GetEntries(clientID int64, from, to time.Time) ([]models.ClientAccountEntry, error) {
+	if m.GetEntriesFunc == nil {
+		panic("mocks: MockClientAccountRepository.GetEntries called but GetEntriesFunc is not set")
+	}
+	return m.GetEntriesFunc(clientID, from, to)
+}
+
+func (m *MockClientAccountRepository) GetBalanceAt(clientID int64, at time.Time) (models.Money, error) {
+	if m.GetBalanceAtFunc == nil {
+		panic("mocks: MockClientAccountRepository.GetBalanceAt called but GetBalanceAtFunc is not set")
+	}
+	return m.GetBalanceAtFunc(clientID, at)
+}
+
+func (m *MockClientAccountRepository) GetOrderBalance(executor repositories.SQLExecutor, orderID int64) (int64, models.Money, error) {
+	if m.GetOrderBalanceFunc == nil {
+		panic("mocks: MockClientAccountRepository.GetOrderBalance called but GetOrderBalanceFunc is not set")
+	}
+	return m.GetOrderBalanceFunc(executor, orderID)
+}
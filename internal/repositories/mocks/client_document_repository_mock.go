@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockClientDocumentRepository is a hand-written mock of repositories.ClientDocumentRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockClientDocumentRepository struct {
+	AddDocumentFunc          func(*models.ClientDocument, *models.Photo) error
+	GetDocumentsFunc         func(int64) ([]models.ClientDocument, error)
+	GetDocumentByIDFunc      func(int64, int64) (*models.ClientDocument, error)
+	GetDocumentPhotoFunc     func(int64, int64) (*models.Photo, error)
+	DeleteDocumentFunc       func(int64, int64) error
+	GetIDVerificationFunc    func(int64) (*models.ClientIDVerification, error)
+	SaveIDVerificationFunc   func(*models.ClientIDVerification) error
+	DeleteIDVerificationFunc func(int64) error
+}
+
+var _ repositories.ClientDocumentRepository = (*MockClientDocumentRepository)(nil)
+
+func (m *MockClientDocumentRepository) AddDocument(document *models.ClientDocument, photo *models.Photo) error {
+	if m.AddDocumentFunc == nil {
+		panic("mocks: MockClientDocumentRepository.AddDocument called but AddDocumentFunc is not set")
+	}
+	return m.AddDocumentFunc(document, photo)
+}
+
+func (m *MockClientDocumentRepository) GetDocuments(clientID int64) ([]models.ClientDocument, error) {
+	if m.GetDocumentsFunc == nil {
+		panic("mocks: MockClientDocumentRepository.GetDocuments called but GetDocumentsFunc is not set")
+	}
+	return m.GetDocumentsFunc(clientID)
+}
+
+func (m *MockClientDocumentRepository) GetDocumentByID(clientID, documentID int64) (*models.ClientDocument, error) {
+	if m.GetDocumentByIDFunc == nil {
+		panic("mocks: MockClientDocumentRepository.GetDocumentByID called but GetDocumentByIDFunc is not set")
+	}
+	return m.GetDocumentByIDFunc(clientID, documentID)
+}
+
+func (m *MockClientDocumentRepository) GetDocumentPhoto(clientID, documentID int64) (*models.Photo, error) {
+	if m.GetDocumentPhotoFunc == nil {
+		panic("mocks: MockClientDocumentRepository.GetDocumentPhoto called but GetDocumentPhotoFunc is not set")
+	}
+	return m.GetDocumentPhotoFunc(clientID, documentID)
+}
+
+func (m *MockClientDocumentRepository) DeleteDocument(clientID, documentID int64) error {
+	if m.DeleteDocumentFunc == nil {
+		panic("mocks: MockClientDocumentRepository.DeleteDocument called but DeleteDocumentFunc is not set")
+	}
+	return m.DeleteDocumentFunc(clientID, documentID)
+}
+
+func (m *MockClientDocumentRepository) GetIDVerification(clientID int64) (*models.ClientIDVerification, error) {
+	if m.GetIDVerificationFunc == nil {
+		panic("mocks: MockClientDocumentRepository.GetIDVerification called but GetIDVerificationFunc is not set")
+	}
+	return m.GetIDVerificationFunc(clientID)
+}
+
+func (m *MockClientDocumentRepository) SaveIDVerification(verification *models.ClientIDVerification) error {
+	if m.SaveIDVerificationFunc == nil {
+		panic("mocks: MockClientDocumentRepository.SaveIDVerification called but SaveIDVerificationFunc is not set")
+	}
+	return m.SaveIDVerificationFunc(verification)
+}
+
+func (m *MockClientDocumentRepository) DeleteIDVerification(clientID int64) error {
+	if m.DeleteIDVerificationFunc == nil {
+		panic("mocks: MockClientDocumentRepository.DeleteIDVerification called but DeleteIDVerificationFunc is not set")
+	}
+	return m.DeleteIDVerificationFunc(clientID)
+}
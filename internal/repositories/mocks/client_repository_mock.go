@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockClientRepository is a hand-written mock of repositories.ClientRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockClientRepository struct {
+	CreateClientFunc            func(repositories.SQLExecutor, *models.Client) (int64, error)
+	GetClientByIDFunc           func(int64) (*models.Client, error)
+	GetClientByPhoneNumberFunc  func(string) (*models.Client, error)
+	GetClientsFunc              func(int, int, *string, *string) ([]models.Client, int, error)
+	FindDuplicateCandidatesFunc func(string, string, string, float64, int) ([]models.ClientDuplicateCandidate, error)
+	UpdateClientFunc            func(repositories.SQLExecutor, *models.Client) error
+	DeleteClientFunc            func(repositories.SQLExecutor, int64, int64, time.Time) error
+	RestoreClientFunc           func(repositories.SQLExecutor, int64, time.Time) error
+	AnonymizeClientFunc         func(repositories.SQLExecutor, int64, string, time.Time) error
+	SetBlacklistFunc            func(repositories.SQLExecutor, int64, string, *time.Time, int64, time.Time) error
+	ClearBlacklistFunc          func(repositories.SQLExecutor, int64, time.Time) error
+	ReencryptNotesFunc          func() (int, error)
+}
+
+var _ repositories.ClientRepository = (*MockClientRepository)(nil)
+
+func (m *MockClientRepository) CreateClient(executor repositories.SQLExecutor, client *models.Client) (int64, error) {
+	if m.CreateClientFunc == nil {
+		panic("mocks: MockClientRepository.CreateClient called but CreateClientFunc is not set")
+	}
+	return m.CreateClientFunc(executor, client)
+}
+
+func (m *MockClientRepository) GetClientByID(id int64) (*models.Client, error) {
+	if m.GetClientByIDFunc == nil {
+		panic("mocks: MockClientRepository.GetClientByID called but GetClientByIDFunc is not set")
+	}
+	return m.GetClientByIDFunc(id)
+}
+
+func (m *MockClientRepository) GetClientByPhoneNumber(phoneNumber string) (*models.Client, error) {
+	if m.GetClientByPhoneNumberFunc == nil {
+		panic("mocks: MockClientRepository.GetClientByPhoneNumber called but GetClientByPhoneNumberFunc is not set")
+	}
+	return m.GetClientByPhoneNumberFunc(phoneNumber)
+}
+
+func (m *MockClientRepository) GetClients(page int, pageSize int, searchTerm *string, tag *string) ([]models.Client, int, error) {
+	if m.GetClientsFunc == nil {
+		panic("mocks: MockClientRepository.GetClients called but GetClientsFunc is not set")
+	}
+	return m.GetClientsFunc(page, pageSize, searchTerm, tag)
+}
+
+func (m *MockClientRepository) FindDuplicateCandidates(fullName, phoneNumber, email string, minSimilarity float64, limit int) ([]models.ClientDuplicateCandidate, error) {
+	if m.FindDuplicateCandidatesFunc == nil {
+		panic("mocks: MockClientRepository.FindDuplicateCandidates called but FindDuplicateCandidatesFunc is not set")
+	}
+	return m.FindDuplicateCandidatesFunc(fullName, phoneNumber, email, minSimilarity, limit)
+}
+
+func (m *MockClientRepository) UpdateClient(executor repositories.SQLExecutor, client *models.Client) error {
+	if m.UpdateClientFunc == nil {
+		panic("mocks: MockClientRepository.UpdateClient called but UpdateClientFunc is not set")
+	}
+	return m.UpdateClientFunc(executor, client)
+}
+
+func (m *MockClientRepository) DeleteClient(executor repositories.SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	if m.DeleteClientFunc == nil {
+		panic("mocks: MockClientRepository.DeleteClient called but DeleteClientFunc is not set")
+	}
+	return m.DeleteClientFunc(executor, id, deletedBy, now)
+}
+
+func (m *MockClientRepository) RestoreClient(executor repositories.SQLExecutor, id int64, now time.Time) error {
+	if m.RestoreClientFunc == nil {
+		panic("mocks: MockClientRepository.RestoreClient called but RestoreClientFunc is not set")
+	}
+	return m.RestoreClientFunc(executor, id, now)
+}
+
+func (m *MockClientRepository) AnonymizeClient(executor repositories.SQLExecutor, id int64, placeholderName string, now time.Time) error {
+	if m.AnonymizeClientFunc == nil {
+		panic("mocks: MockClientRepository.AnonymizeClient called but AnonymizeClientFunc is not set")
+	}
+	return m.AnonymizeClientFunc(executor, id, placeholderName, now)
+}
+
+func (m *MockClientRepository) SetBlacklist(executor repositories.SQLExecutor, id int64, reason string, until *time.Time, blacklistedBy int64, now time.Time) error {
+	if m.SetBlacklistFunc == nil {
+		panic("mocks: MockClientRepository.SetBlacklist called but SetBlacklistFunc is not set")
+	}
+	return m.SetBlacklistFunc(executor, id, reason, until, blacklistedBy, now)
+}
+
+func (m *MockClientRepository) ClearBlacklist(executor repositories.SQLExecutor, id int64, now time.Time) error {
+	if m.ClearBlacklistFunc == nil {
+		panic("mocks: MockClientRepository.ClearBlacklist called but ClearBlacklistFunc is not set")
+	}
+	return m.ClearBlacklistFunc(executor, id, now)
+}
+
+func (m *MockClientRepository) ReencryptNotes() (int, error) {
+	if m.ReencryptNotesFunc == nil {
+		panic("mocks: MockClientRepository.ReencryptNotes called but ReencryptNotesFunc is not set")
+	}
+	return m.ReencryptNotesFunc()
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockClientTagRepository is a hand-written mock of repositories.ClientTagRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockClientTagRepository struct {
+	SetTagsFunc      func(repositories.SQLExecutor, int64, []string) error
+	AddTagsFunc      func(repositories.SQLExecutor, int64, []string) error
+	RemoveTagFunc    func(repositories.SQLExecutor, int64, string) error
+	GetTagCountsFunc func() ([]models.ClientTagCount, error)
+	GetTagReportFunc func(time.Time, time.Time, *string, []string) ([]models.ClientTagReportItem, error)
+}
+
+var _ repositories.ClientTagRepository = (*MockClientTagRepository)(nil)
+
+func (m *MockClientTagRepository) SetTags(executor repositories.SQLExecutor, clientID int64, tags []string) error {
+	if m.SetTagsFunc == nil {
+		panic("mocks: MockClientTagRepository.SetTags called but SetTagsFunc is not set")
+	}
+	return m.SetTagsFunc(executor, clientID, tags)
+}
+
+func (m *MockClientTagRepository) AddTags(executor repositories.SQLExecutor, clientID int64, tags []string) error {
+	if m.AddTagsFunc == nil {
+		panic("mocks: MockClientTagRepository.AddTags called but AddTagsFunc is not set")
+	}
+	return m.AddTagsFunc(executor, clientID, tags)
+}
+
+func (m *MockClientTagRepository) RemoveTag(executor repositories.SQLExecutor, clientID int64, tag string) error {
+	if m.RemoveTagFunc == nil {
+		panic("mocks: MockClientTagRepository.RemoveTag called but RemoveTagFunc is not set")
+	}
+	return m.RemoveTagFunc(executor, clientID, tag)
+}
+
+func (m *MockClientTagRepository) GetTagCounts() ([]models.ClientTagCount, error) {
+	if m.GetTagCountsFunc == nil {
+		panic("mocks: MockClientTagRepository.GetTagCounts called but GetTagCountsFunc is not set")
+	}
+	return m.GetTagCountsFunc()
+}
+
+func (m *MockClientTagRepository) GetTagReport(from time.Time, to time.Time, tag *string, orderStatuses []string) ([]models.ClientTagReportItem, error) {
+	if m.GetTagReportFunc == nil {
+		panic("mocks: MockClientTagRepository.GetTagReport called but GetTagReportFunc is not set")
+	}
+	return m.GetTagReportFunc(from, to, tag, orderStatuses)
+}
@@ -0,0 +1,104 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockDayCloseRepository is a hand-written mock of repositories.DayCloseRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockDayCloseRepository struct {
+	GetOpenOrdersFunc      func(repositories.SQLExecutor, string, []string, time.Time) ([]models.DayCloseItem, error)
+	GetRunningSessionsFunc func(repositories.SQLExecutor, time.Time) ([]models.DayCloseItem, error)
+	GetClockedInStaffFunc  func(repositories.SQLExecutor, time.Time) ([]models.DayCloseItem, error)
+	GetOrderOutcomesFunc   func(repositories.SQLExecutor, string, time.Time, time.Time) (int, int, models.Money, error)
+	GetShiftCashTotalsFunc func(repositories.SQLExecutor, string, time.Time, time.Time) (int, models.Money, error)
+	GetCategorySalesFunc   func(repositories.SQLExecutor, string, []string, time.Time, time.Time) ([]models.CategorySales, error)
+	GetHoursBookedFunc     func(repositories.SQLExecutor, time.Time, time.Time) (float64, error)
+	CreateSummaryFunc      func(repositories.SQLExecutor, *models.DailySummary) error
+	GetSummaryFunc         func(string, string) (*models.DailySummary, error)
+	GetSummariesFunc       func(string, string, string) ([]models.DailySummary, error)
+	IsClosedThroughFunc    func(string, string) (bool, error)
+}
+
+var _ repositories.DayCloseRepository = (*MockDayCloseRepository)(nil)
+
+func (m *MockDayCloseRepository) GetOpenOrders(executor repositories.SQLExecutor, branchCode string, statuses []string, before time.Time) ([]models.DayCloseItem, error) {
+	if m.GetOpenOrdersFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetOpenOrders called but GetOpenOrdersFunc is not set")
+	}
+	return m.GetOpenOrdersFunc(executor, branchCode, statuses, before)
+}
+
+func (m *MockDayCloseRepository) GetRunningSessions(executor repositories.SQLExecutor, before time.Time) ([]models.DayCloseItem, error) {
+	if m.GetRunningSessionsFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetRunningSessions called but GetRunningSessionsFunc is not set")
+	}
+	return m.GetRunningSessionsFunc(executor, before)
+}
+
+func (m *MockDayCloseRepository) GetClockedInStaff(executor repositories.SQLExecutor, before time.Time) ([]models.DayCloseItem, error) {
+	if m.GetClockedInStaffFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetClockedInStaff called but GetClockedInStaffFunc is not set")
+	}
+	return m.GetClockedInStaffFunc(executor, before)
+}
+
+func (m *MockDayCloseRepository) GetOrderOutcomes(executor repositories.SQLExecutor, branchCode string, from, to time.Time) (int, int, models.Money, error) {
+	if m.GetOrderOutcomesFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetOrderOutcomes called but GetOrderOutcomesFunc is not set")
+	}
+	return m.GetOrderOutcomesFunc(executor, branchCode, from, to)
+}
+
+func (m *MockDayCloseRepository) GetShiftCashTotals(executor repositories.SQLExecutor, branchCode string, from, to time.Time) (int, models.Money, error) {
+	if m.GetShiftCashTotalsFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetShiftCashTotals called but GetShiftCashTotalsFunc is not set")
+	}
+	return m.GetShiftCashTotalsFunc(executor, branchCode, from, to)
+}
+
+func (m *MockDayCloseRepository) GetCategorySales(executor repositories.SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.CategorySales, error) {
+	if m.GetCategorySalesFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetCategorySales called but GetCategorySalesFunc is not set")
+	}
+	return m.GetCategorySalesFunc(executor, branchCode, statuses, from, to)
+}
+
+func (m *MockDayCloseRepository) GetHoursBooked(executor repositories.SQLExecutor, from, to time.Time) (float64, error) {
+	if m.GetHoursBookedFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetHoursBooked called but GetHoursBookedFunc is not set")
+	}
+	return m.GetHoursBookedFunc(executor, from, to)
+}
+
+func (m *MockDayCloseRepository) CreateSummary(executor repositories.SQLExecutor, summary *models.DailySummary) error {
+	if m.CreateSummaryFunc == nil {
+		panic("mocks: MockDayCloseRepository.CreateSummary called but CreateSummaryFunc is not set")
+	}
+	return m.CreateSummaryFunc(executor, summary)
+}
+
+func (m *MockDayCloseRepository) GetSummary(branchCode, businessDate string) (*models.DailySummary, error) {
+	if m.GetSummaryFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetSummary called but GetSummaryFunc is not set")
+	}
+	return m.GetSummaryFunc(branchCode, businessDate)
+}
+
+func (m *MockDayCloseRepository) GetSummaries(branchCode, from, to string) ([]models.DailySummary, error) {
+	if m.GetSummariesFunc == nil {
+		panic("mocks: MockDayCloseRepository.GetSummaries called but GetSummariesFunc is not set")
+	}
+	return m.GetSummariesFunc(branchCode, from, to)
+}
+
+func (m *MockDayCloseRepository) IsClosedThrough(branchCode, businessDate string) (bool, error) {
+	if m.IsClosedThroughFunc == nil {
+		panic("mocks: MockDayCloseRepository.IsClosedThrough called but IsClosedThroughFunc is not set")
+	}
+	return m.IsClosedThroughFunc(branchCode, businessDate)
+}
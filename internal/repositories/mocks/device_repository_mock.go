@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockDeviceRepository is a hand-written mock of repositories.DeviceRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockDeviceRepository struct {
+	RegisterDeviceFunc func(*models.Device) (bool, error)
+	TouchDeviceFunc    func(string, int64, *string, *string, time.Time) (*models.Device, error)
+	GetDeviceByIDFunc  func(int64) (*models.Device, error)
+	GetDevicesFunc     func(*string, *bool, time.Time) ([]models.Device, error)
+	UpdateDeviceFunc   func(*models.Device, int) error
+	DeleteDeviceFunc   func(int64) error
+}
+
+var _ repositories.DeviceRepository = (*MockDeviceRepository)(nil)
+
+func (m *MockDeviceRepository) RegisterDevice(device *models.Device) (bool, error) {
+	if m.RegisterDeviceFunc == nil {
+		panic("mocks: MockDeviceRepository.RegisterDevice called but RegisterDeviceFunc is not set")
+	}
+	return m.RegisterDeviceFunc(device)
+}
+
+func (m *MockDeviceRepository) TouchDevice(deviceUID string, apiKeyID int64, appVersion, ip *string, now time.Time) (*models.Device, error) {
+	if m.TouchDeviceFunc == nil {
+		panic("mocks: MockDeviceRepository.TouchDevice called but TouchDeviceFunc is not set")
+	}
+	return m.TouchDeviceFunc(deviceUID, apiKeyID, appVersion, ip, now)
+}
+
+func (m *MockDeviceRepository) GetDeviceByID(id int64) (*models.Device, error) {
+	if m.GetDeviceByIDFunc == nil {
+		panic("mocks: MockDeviceRepository.GetDeviceByID called but GetDeviceByIDFunc is not set")
+	}
+	return m.GetDeviceByIDFunc(id)
+}
+
+func (m *MockDeviceRepository) GetDevices(deviceType *string, online *bool, onlineSince time.Time) ([]models.Device, error) {
+	if m.GetDevicesFunc == nil {
+		panic("mocks: MockDeviceRepository.GetDevices called but GetDevicesFunc is not set")
+	}
+	return m.GetDevicesFunc(deviceType, online, onlineSince)
+}
+
+func (m *MockDeviceRepository) UpdateDevice(device *models.Device, version int) error {
+	if m.UpdateDeviceFunc == nil {
+		panic("mocks: MockDeviceRepository.UpdateDevice called but UpdateDeviceFunc is not set")
+	}
+	return m.UpdateDeviceFunc(device, version)
+}
+
+func (m *MockDeviceRepository) DeleteDevice(id int64) error {
+	if m.DeleteDeviceFunc == nil {
+		panic("mocks: MockDeviceRepository.DeleteDevice called but DeleteDeviceFunc is not set")
+	}
+	return m.DeleteDeviceFunc(id)
+}
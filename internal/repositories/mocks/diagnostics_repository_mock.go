@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockDiagnosticsRepository is a hand-written mock of repositories.DiagnosticsRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockDiagnosticsRepository struct {
+	PingFunc                     func() error
+	GetIndexColumnsFunc          func(string) ([][]string, error)
+	ExplainOrdersFunc            func(models.OrderFilters) (*models.QueryPlan, error)
+	ExplainBookingsFunc          func(models.BookingFilters) (*models.QueryPlan, error)
+	ExplainMovementsFunc         func(*int64, *int64, *string, int, int) (*models.QueryPlan, error)
+	ExplainTableAvailabilityFunc func(int64, time.Time, time.Time) (*models.QueryPlan, error)
+	ExplainActivityEventsFunc    func(models.ActivityFilter) (*models.QueryPlan, error)
+}
+
+var _ repositories.DiagnosticsRepository = (*MockDiagnosticsRepository)(nil)
+
+func (m *MockDiagnosticsRepository) Ping() error {
+	if m.PingFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.Ping called but PingFunc is not set")
+	}
+	return m.PingFunc()
+}
+
+func (m *MockDiagnosticsRepository) GetIndexColumns(table string) ([][]string, error) {
+	if m.GetIndexColumnsFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.GetIndexColumns called but GetIndexColumnsFunc is not set")
+	}
+	return m.GetIndexColumnsFunc(table)
+}
+
+func (m *MockDiagnosticsRepository) ExplainOrders(filters models.OrderFilters) (*models.QueryPlan, error) {
+	if m.ExplainOrdersFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.ExplainOrders called but ExplainOrdersFunc is not set")
+	}
+	return m.ExplainOrdersFunc(filters)
+}
+
+func (m *MockDiagnosticsRepository) ExplainBookings(filters models.BookingFilters) (*models.QueryPlan, error) {
+	if m.ExplainBookingsFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.ExplainBookings called but ExplainBookingsFunc is not set")
+	}
+	return m.ExplainBookingsFunc(filters)
+}
+
+func (m *MockDiagnosticsRepository) ExplainMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) (*models.QueryPlan, error) {
+	if m.ExplainMovementsFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.ExplainMovements called but ExplainMovementsFunc is not set")
+	}
+	return m.ExplainMovementsFunc(itemID, staffID, movementType, page, pageSize)
+}
+
+func (m *MockDiagnosticsRepository) ExplainTableAvailability(tableID int64, startTime, endTime time.Time) (*models.QueryPlan, error) {
+	if m.ExplainTableAvailabilityFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.ExplainTableAvailability called but ExplainTableAvailabilityFunc is not set")
+	}
+	return m.ExplainTableAvailabilityFunc(tableID, startTime, endTime)
+}
+
+func (m *MockDiagnosticsRepository) ExplainActivityEvents(filter models.ActivityFilter) (*models.QueryPlan, error) {
+	if m.ExplainActivityEventsFunc == nil {
+		panic("mocks: MockDiagnosticsRepository.ExplainActivityEvents called but ExplainActivityEventsFunc is not set")
+	}
+	return m.ExplainActivityEventsFunc(filter)
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockFloorPlanRepository is a hand-written mock of repositories.FloorPlanRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockFloorPlanRepository struct {
+	GetCanvasFunc        func() (*models.FloorPlan, error)
+	GetTablesFunc        func(time.Time) ([]models.FloorPlanTableStatus, error)
+	ReplaceFloorPlanFunc func(repositories.SQLExecutor, float64, float64, []models.FloorPlanTable, int64, time.Time) error
+	SaveTableFunc        func(models.FloorPlanTable, time.Time) error
+	DeleteTableFunc      func(int64) error
+}
+
+var _ repositories.FloorPlanRepository = (*MockFloorPlanRepository)(nil)
+
+func (m *MockFloorPlanRepository) GetCanvas() (*models.FloorPlan, error) {
+	if m.GetCanvasFunc == nil {
+		panic("mocks: MockFloorPlanRepository.GetCanvas called but GetCanvasFunc is not set")
+	}
+	return m.GetCanvasFunc()
+}
+
+func (m *MockFloorPlanRepository) GetTables(now time.Time) ([]models.FloorPlanTableStatus, error) {
+	if m.GetTablesFunc == nil {
+		panic("mocks: MockFloorPlanRepository.GetTables called but GetTablesFunc is not set")
+	}
+	return m.GetTablesFunc(now)
+}
+
+func (m *MockFloorPlanRepository) ReplaceFloorPlan(executor repositories.SQLExecutor, width, height float64, tables []models.FloorPlanTable, updatedBy int64, at time.Time) error {
+	if m.ReplaceFloorPlanFunc == nil {
+		panic("mocks: MockFloorPlanRepository.ReplaceFloorPlan called but ReplaceFloorPlanFunc is not set")
+	}
+	return m.ReplaceFloorPlanFunc(executor, width, height, tables, updatedBy, at)
+}
+
+func (m *MockFloorPlanRepository) SaveTable(table models.FloorPlanTable, at time.Time) error {
+	if m.SaveTableFunc == nil {
+		panic("mocks: MockFloorPlanRepository.SaveTable called but SaveTableFunc is not set")
+	}
+	return m.SaveTableFunc(table, at)
+}
+
+func (m *MockFloorPlanRepository) DeleteTable(tableID int64) error {
+	if m.DeleteTableFunc == nil {
+		panic("mocks: MockFloorPlanRepository.DeleteTable called but DeleteTableFunc is not set")
+	}
+	return m.DeleteTableFunc(tableID)
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockGameTableRepository is a hand-written mock of repositories.GameTableRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockGameTableRepository struct {
+	CreateGameTableFunc       func(*models.GameTable) error
+	GetGameTablesFunc         func(string) ([]models.GameTable, error)
+	GetGameTableByIDFunc      func(int64) (*models.GameTable, error)
+	UpdateGameTableFunc       func(repositories.SQLExecutor, *models.GameTable, string) error
+	DeleteGameTableFunc       func(int64) error
+	CountActiveBookingsFunc   func(int64) (int, error)
+	SetGameTableStatusFunc    func(repositories.SQLExecutor, int64, string, string, time.Time) error
+	GetTableStatusChangesFunc func(time.Time, time.Time) ([]models.TableStatusChange, error)
+}
+
+var _ repositories.GameTableRepository = (*MockGameTableRepository)(nil)
+
+func (m *MockGameTableRepository) CreateGameTable(table *models.GameTable) error {
+	if m.CreateGameTableFunc == nil {
+		panic("mocks: MockGameTableRepository.CreateGameTable called but CreateGameTableFunc is not set")
+	}
+	return m.CreateGameTableFunc(table)
+}
+
+func (m *MockGameTableRepository) GetGameTables(status string) ([]models.GameTable, error) {
+	if m.GetGameTablesFunc == nil {
+		panic("mocks: MockGameTableRepository.GetGameTables called but GetGameTablesFunc is not set")
+	}
+	return m.GetGameTablesFunc(status)
+}
+
+func (m *MockGameTableRepository) GetGameTableByID(id int64) (*models.GameTable, error) {
+	if m.GetGameTableByIDFunc == nil {
+		panic("mocks: MockGameTableRepository.GetGameTableByID called but GetGameTableByIDFunc is not set")
+	}
+	return m.GetGameTableByIDFunc(id)
+}
+
+func (m *MockGameTableRepository) UpdateGameTable(executor repositories.SQLExecutor, table *models.GameTable, fromStatus string) error {
+	if m.UpdateGameTableFunc == nil {
+		panic("mocks: MockGameTableRepository.UpdateGameTable called but UpdateGameTableFunc is not set")
+	}
+	return m.UpdateGameTableFunc(executor, table, fromStatus)
+}
+
+func (m *MockGameTableRepository) DeleteGameTable(id int64) error {
+	if m.DeleteGameTableFunc == nil {
+		panic("mocks: MockGameTableRepository.DeleteGameTable called but DeleteGameTableFunc is not set")
+	}
+	return m.DeleteGameTableFunc(id)
+}
+
+func (m *MockGameTableRepository) CountActiveBookings(tableID int64) (int, error) {
+	if m.CountActiveBookingsFunc == nil {
+		panic("mocks: MockGameTableRepository.CountActiveBookings called but CountActiveBookingsFunc is not set")
+	}
+	return m.CountActiveBookingsFunc(tableID)
+}
+
+func (m *MockGameTableRepository) SetGameTableStatus(executor repositories.SQLExecutor, tableID int64, from, to string, at time.Time) error {
+	if m.SetGameTableStatusFunc == nil {
+		panic("mocks: MockGameTableRepository.SetGameTableStatus called but SetGameTableStatusFunc is not set")
+	}
+	return m.SetGameTableStatusFunc(executor, tableID, from, to, at)
+}
+
+func (m *MockGameTableRepository) GetTableStatusChanges(now, reservedUntil time.Time) ([]models.TableStatusChange, error) {
+	if m.GetTableStatusChangesFunc == nil {
+		panic("mocks: MockGameTableRepository.GetTableStatusChanges called but GetTableStatusChangesFunc is not set")
+	}
+	return m.GetTableStatusChangesFunc(now, reservedUntil)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockHookahRepository is a hand-written mock of repositories.HookahRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockHookahRepository struct {
+	GetActiveHookahsFunc func([]string) ([]models.Hookah, error)
+	GetHookahFunc        func(int64) (*models.Hookah, error)
+	CreateCoalChangeFunc func(repositories.SQLExecutor, *models.CoalChange) error
+	GetCoalChangesFunc   func(int64) ([]models.CoalChange, error)
+	MarkCoalRemindedFunc func(repositories.SQLExecutor, int64, time.Time, time.Time) (bool, error)
+	EndHookahFunc        func(repositories.SQLExecutor, int64, time.Time) error
+}
+
+var _ repositories.HookahRepository = (*MockHookahRepository)(nil)
+
+func (m *MockHookahRepository) GetActiveHookahs(orderStatuses []string) ([]models.Hookah, error) {
+	if m.GetActiveHookahsFunc == nil {
+		panic("mocks: MockHookahRepository.GetActiveHookahs called but GetActiveHookahsFunc is not set")
+	}
+	return m.GetActiveHookahsFunc(orderStatuses)
+}
+
+func (m *MockHookahRepository) GetHookah(orderItemID int64) (*models.Hookah, error) {
+	if m.GetHookahFunc == nil {
+		panic("mocks: MockHookahRepository.GetHookah called but GetHookahFunc is not set")
+	}
+	return m.GetHookahFunc(orderItemID)
+}
+
+func (m *MockHookahRepository) CreateCoalChange(executor repositories.SQLExecutor, change *models.CoalChange) error {
+	if m.CreateCoalChangeFunc == nil {
+		panic("mocks: MockHookahRepository.CreateCoalChange called but CreateCoalChangeFunc is not set")
+	}
+	return m.CreateCoalChangeFunc(executor, change)
+}
+
+func (m *MockHookahRepository) GetCoalChanges(orderItemID int64) ([]models.CoalChange, error) {
+	if m.GetCoalChangesFunc == nil {
+		panic("mocks: MockHookahRepository.GetCoalChanges called but GetCoalChangesFunc is not set")
+	}
+	return m.GetCoalChangesFunc(orderItemID)
+}
+
+func (m *MockHookahRepository) MarkCoalReminded(executor repositories.SQLExecutor, orderItemID int64, dueAt, now time.Time) (bool, error) {
+	if m.MarkCoalRemindedFunc == nil {
+		panic("mocks: MockHookahRepository.MarkCoalReminded called but MarkCoalRemindedFunc is not set")
+	}
+	return m.MarkCoalRemindedFunc(executor, orderItemID, dueAt, now)
+}
+
+func (m *MockHookahRepository) EndHookah(executor repositories.SQLExecutor, orderItemID int64, endedAt time.Time) error {
+	if m.EndHookahFunc == nil {
+		panic("mocks: MockHookahRepository.EndHookah called but EndHookahFunc is not set")
+	}
+	return m.EndHookahFunc(executor, orderItemID, endedAt)
+}
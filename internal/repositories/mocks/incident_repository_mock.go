@@ -0,0 +1,96 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockIncidentRepository is a hand-written mock of repositories.IncidentRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockIncidentRepository struct {
+	CreateIncidentFunc  func(*models.Incident) error
+	GetIncidentByIDFunc func(int64) (*models.Incident, error)
+	GetIncidentsFunc    func(string, models.IncidentFilters) ([]models.Incident, error)
+	UpdateIncidentFunc  func(*models.Incident, int) error
+	ReviewIncidentFunc  func(*models.Incident) error
+	DeleteIncidentFunc  func(int64) error
+	AddPhotoFunc        func(int64, *models.Photo, int64) (*models.IncidentPhoto, error)
+	GetPhotoFunc        func(int64, int64) (*models.Photo, error)
+	DeletePhotoFunc     func(int64, int64) error
+	GetPayrollLinesFunc func(time.Time, time.Time) ([]models.PayrollLine, error)
+}
+
+var _ repositories.IncidentRepository = (*MockIncidentRepository)(nil)
+
+func (m *MockIncidentRepository) CreateIncident(incident *models.Incident) error {
+	if m.CreateIncidentFunc == nil {
+		panic("mocks: MockIncidentRepository.CreateIncident called but CreateIncidentFunc is not set")
+	}
+	return m.CreateIncidentFunc(incident)
+}
+
+func (m *MockIncidentRepository) GetIncidentByID(id int64) (*models.Incident, error) {
+	if m.GetIncidentByIDFunc == nil {
+		panic("mocks: MockIncidentRepository.GetIncidentByID called but GetIncidentByIDFunc is not set")
+	}
+	return m.GetIncidentByIDFunc(id)
+}
+
+func (m *MockIncidentRepository) GetIncidents(branchCode string, filters models.IncidentFilters) ([]models.Incident, error) {
+	if m.GetIncidentsFunc == nil {
+		panic("mocks: MockIncidentRepository.GetIncidents called but GetIncidentsFunc is not set")
+	}
+	return m.GetIncidentsFunc(branchCode, filters)
+}
+
+func (m *MockIncidentRepository) UpdateIncident(incident *models.Incident, version int) error {
+	if m.UpdateIncidentFunc == nil {
+		panic("mocks: MockIncidentRepository.UpdateIncident called but UpdateIncidentFunc is not set")
+	}
+	return m.UpdateIncidentFunc(incident, version)
+}
+
+func (m *MockIncidentRepository) ReviewIncident(incident *models.Incident) error {
+	if m.ReviewIncidentFunc == nil {
+		panic("mocks: MockIncidentRepository.ReviewIncident called but ReviewIncidentFunc is not set")
+	}
+	return m.ReviewIncidentFunc(incident)
+}
+
+func (m *MockIncidentRepository) DeleteIncident(id int64) error {
+	if m.DeleteIncidentFunc == nil {
+		panic("mocks: MockIncidentRepository.DeleteIncident called but DeleteIncidentFunc is not set")
+	}
+	return m.DeleteIncidentFunc(id)
+}
+
+func (m *MockIncidentRepository) AddPhoto(incidentID int64, photo *models.Photo, uploadedBy int64) (*models.IncidentPhoto, error) {
+	if m.AddPhotoFunc == nil {
+		panic("mocks: MockIncidentRepository.AddPhoto called but AddPhotoFunc is not set")
+	}
+	return m.AddPhotoFunc(incidentID, photo, uploadedBy)
+}
+
+func (m *MockIncidentRepository) GetPhoto(incidentID, photoID int64) (*models.Photo, error) {
+	if m.GetPhotoFunc == nil {
+		panic("mocks: MockIncidentRepository.GetPhoto called but GetPhotoFunc is not set")
+	}
+	return m.GetPhotoFunc(incidentID, photoID)
+}
+
+func (m *MockIncidentRepository) DeletePhoto(incidentID, photoID int64) error {
+	if m.DeletePhotoFunc == nil {
+		panic("mocks: MockIncidentRepository.DeletePhoto called but DeletePhotoFunc is not set")
+	}
+	return m.DeletePhotoFunc(incidentID, photoID)
+}
+
+func (m *MockIncidentRepository) GetPayrollLines(from, to time.Time) ([]models.PayrollLine, error) {
+	if m.GetPayrollLinesFunc == nil {
+		panic("mocks: MockIncidentRepository.GetPayrollLines called but GetPayrollLinesFunc is not set")
+	}
+	return m.GetPayrollLinesFunc(from, to)
+}
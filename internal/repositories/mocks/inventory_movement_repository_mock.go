@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockInventoryMovementRepository is a hand-written mock of repositories.InventoryMovementRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockInventoryMovementRepository struct {
+	CreateMovementFunc func(repositories.SQLExecutor, *models.InventoryMovement) (int64, error)
+	GetMovementsFunc   func(*int64, *int64, *string, *time.Time, *time.Time, *models.Cursor, int, int) ([]models.InventoryMovement, int, error)
+}
+
+var _ repositories.InventoryMovementRepository = (*MockInventoryMovementRepository)(nil)
+
+func (m *MockInventoryMovementRepository) CreateMovement(executor repositories.SQLExecutor, movement *models.InventoryMovement) (int64, error) {
+	if m.CreateMovementFunc == nil {
+		panic("mocks: MockInventoryMovementRepository.CreateMovement called but CreateMovementFunc is not set")
+	}
+	return m.CreateMovementFunc(executor, movement)
+}
+
+func (m *MockInventoryMovementRepository) GetMovements(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor *models.Cursor, page int, pageSize int) ([]models.InventoryMovement, int, error) {
+	if m.GetMovementsFunc == nil {
+		panic("mocks: MockInventoryMovementRepository.GetMovements called but GetMovementsFunc is not set")
+	}
+	return m.GetMovementsFunc(itemID, staffID, movementType, from, to, cursor, page, pageSize)
+}
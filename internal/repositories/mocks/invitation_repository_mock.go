@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockInvitationRepository is a hand-written mock of repositories.InvitationRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockInvitationRepository struct {
+	CreateInvitationFunc         func(*models.Invitation) (*models.Invitation, error)
+	GetInvitationsFunc           func() ([]models.Invitation, error)
+	GetInvitationByTokenHashFunc func(string) (*models.Invitation, error)
+	AcceptInvitationFunc         func(repositories.SQLExecutor, int64, int64, time.Time) error
+	RevokeInvitationFunc         func(int64, time.Time) error
+}
+
+var _ repositories.InvitationRepository = (*MockInvitationRepository)(nil)
+
+func (m *MockInvitationRepository) CreateInvitation(invitation *models.Invitation) (*models.Invitation, error) {
+	if m.CreateInvitationFunc == nil {
+		panic("mocks: MockInvitationRepository.CreateInvitation called but CreateInvitationFunc is not set")
+	}
+	return m.CreateInvitationFunc(invitation)
+}
+
+func (m *MockInvitationRepository) GetInvitations() ([]models.Invitation, error) {
+	if m.GetInvitationsFunc == nil {
+		panic("mocks: MockInvitationRepository.GetInvitations called but GetInvitationsFunc is not set")
+	}
+	return m.GetInvitationsFunc()
+}
+
+func (m *MockInvitationRepository) GetInvitationByTokenHash(tokenHash string) (*models.Invitation, error) {
+	if m.GetInvitationByTokenHashFunc == nil {
+		panic("mocks: MockInvitationRepository.GetInvitationByTokenHash called but GetInvitationByTokenHashFunc is not set")
+	}
+	return m.GetInvitationByTokenHashFunc(tokenHash)
+}
+
+func (m *MockInvitationRepository) AcceptInvitation(executor repositories.SQLExecutor, id, userID int64, now time.Time) error {
+	if m.AcceptInvitationFunc == nil {
+		panic("mocks: MockInvitationRepository.AcceptInvitation called but AcceptInvitationFunc is not set")
+	}
+	return m.AcceptInvitationFunc(executor, id, userID, now)
+}
+
+func (m *MockInvitationRepository) RevokeInvitation(id int64, now time.Time) error {
+	if m.RevokeInvitationFunc == nil {
+		panic("mocks: MockInvitationRepository.RevokeInvitation called but RevokeInvitationFunc is not set")
+	}
+	return m.RevokeInvitationFunc(id, now)
+}
@@ -0,0 +1,86 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockLockerRepository is a hand-written mock of repositories.LockerRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockLockerRepository struct {
+	CreateLockerFunc      func(*models.Locker) error
+	GetLockerByIDFunc     func(int64) (*models.Locker, error)
+	GetLockersFunc        func(string) ([]models.Locker, error)
+	UpdateLockerFunc      func(*models.Locker, int) error
+	CreateRentalFunc      func(repositories.SQLExecutor, *models.LockerRental) error
+	GetRentalByIDFunc     func(int64) (*models.LockerRental, error)
+	GetRentalsFunc        func(models.LockerRentalFilters) ([]models.LockerRental, error)
+	GetSessionRentalsFunc func(int64, *int64) ([]models.LockerRental, error)
+	ReleaseRentalFunc     func(repositories.SQLExecutor, *models.LockerRental) error
+}
+
+var _ repositories.LockerRepository = (*MockLockerRepository)(nil)
+
+func (m *MockLockerRepository) CreateLocker(locker *models.Locker) error {
+	if m.CreateLockerFunc == nil {
+		panic("mocks: MockLockerRepository.CreateLocker called but CreateLockerFunc is not set")
+	}
+	return m.CreateLockerFunc(locker)
+}
+
+func (m *MockLockerRepository) GetLockerByID(id int64) (*models.Locker, error) {
+	if m.GetLockerByIDFunc == nil {
+		panic("mocks: MockLockerRepository.GetLockerByID called but GetLockerByIDFunc is not set")
+	}
+	return m.GetLockerByIDFunc(id)
+}
+
+func (m *MockLockerRepository) GetLockers(branchCode string) ([]models.Locker, error) {
+	if m.GetLockersFunc == nil {
+		panic("mocks: MockLockerRepository.GetLockers called but GetLockersFunc is not set")
+	}
+	return m.GetLockersFunc(branchCode)
+}
+
+func (m *MockLockerRepository) UpdateLocker(locker *models.Locker, version int) error {
+	if m.UpdateLockerFunc == nil {
+		panic("mocks: MockLockerRepository.UpdateLocker called but UpdateLockerFunc is not set")
+	}
+	return m.UpdateLockerFunc(locker, version)
+}
+
+func (m *MockLockerRepository) CreateRental(executor repositories.SQLExecutor, rental *models.LockerRental) error {
+	if m.CreateRentalFunc == nil {
+		panic("mocks: MockLockerRepository.CreateRental called but CreateRentalFunc is not set")
+	}
+	return m.CreateRentalFunc(executor, rental)
+}
+
+func (m *MockLockerRepository) GetRentalByID(id int64) (*models.LockerRental, error) {
+	if m.GetRentalByIDFunc == nil {
+		panic("mocks: MockLockerRepository.GetRentalByID called but GetRentalByIDFunc is not set")
+	}
+	return m.GetRentalByIDFunc(id)
+}
+
+func (m *MockLockerRepository) GetRentals(filters models.LockerRentalFilters) ([]models.LockerRental, error) {
+	if m.GetRentalsFunc == nil {
+		panic("mocks: MockLockerRepository.GetRentals called but GetRentalsFunc is not set")
+	}
+	return m.GetRentalsFunc(filters)
+}
+
+func (m *MockLockerRepository) GetSessionRentals(sessionID int64, bookingID *int64) ([]models.LockerRental, error) {
+	if m.GetSessionRentalsFunc == nil {
+		panic("mocks: MockLockerRepository.GetSessionRentals called but GetSessionRentalsFunc is not set")
+	}
+	return m.GetSessionRentalsFunc(sessionID, bookingID)
+}
+
+func (m *MockLockerRepository) ReleaseRental(executor repositories.SQLExecutor, rental *models.LockerRental) error {
+	if m.ReleaseRentalFunc == nil {
+		panic("mocks: MockLockerRepository.ReleaseRental called but ReleaseRentalFunc is not set")
+	}
+	return m.ReleaseRentalFunc(executor, rental)
+}
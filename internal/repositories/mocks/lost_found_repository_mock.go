@@ -0,0 +1,88 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockLostFoundRepository is a hand-written mock of repositories.LostFoundRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockLostFoundRepository struct {
+	CreateItemFunc             func(*models.LostFoundItem) error
+	GetItemByIDFunc            func(int64) (*models.LostFoundItem, error)
+	GetItemsFunc               func(string, models.LostFoundFilters) ([]models.LostFoundItem, error)
+	UpdateItemFunc             func(*models.LostFoundItem, int) error
+	MarkItemReturnedFunc       func(*models.LostFoundItem) error
+	SetItemPhotoFunc           func(int64, *models.Photo) error
+	GetItemPhotoFunc           func(int64) (*models.Photo, error)
+	DeleteItemFunc             func(int64) error
+	DeleteItemsFoundBeforeFunc func(time.Time) (int64, error)
+}
+
+var _ repositories.LostFoundRepository = (*MockLostFoundRepository)(nil)
+
+func (m *MockLostFoundRepository) CreateItem(item *models.LostFoundItem) error {
+	if m.CreateItemFunc == nil {
+		panic("mocks: MockLostFoundRepository.CreateItem called but CreateItemFunc is not set")
+	}
+	return m.CreateItemFunc(item)
+}
+
+func (m *MockLostFoundRepository) GetItemByID(id int64) (*models.LostFoundItem, error) {
+	if m.GetItemByIDFunc == nil {
+		panic("mocks: MockLostFoundRepository.GetItemByID called but GetItemByIDFunc is not set")
+	}
+	return m.GetItemByIDFunc(id)
+}
+
+func (m *MockLostFoundRepository) GetItems(branchCode string, filters models.LostFoundFilters) ([]models.LostFoundItem, error) {
+	if m.GetItemsFunc == nil {
+		panic("mocks: MockLostFoundRepository.GetItems called but GetItemsFunc is not set")
+	}
+	return m.GetItemsFunc(branchCode, filters)
+}
+
+func (m *MockLostFoundRepository) UpdateItem(item *models.LostFoundItem, version int) error {
+	if m.UpdateItemFunc == nil {
+		panic("mocks: MockLostFoundRepository.UpdateItem called but UpdateItemFunc is not set")
+	}
+	return m.UpdateItemFunc(item, version)
+}
+
+func (m *MockLostFoundRepository) MarkItemReturned(item *models.LostFoundItem) error {
+	if m.MarkItemReturnedFunc == nil {
+		panic("mocks: MockLostFoundRepository.MarkItemReturned called but MarkItemReturnedFunc is not set")
+	}
+	return m.MarkItemReturnedFunc(item)
+}
+
+func (m *MockLostFoundRepository) SetItemPhoto(id int64, photo *models.Photo) error {
+	if m.SetItemPhotoFunc == nil {
+		panic("mocks: MockLostFoundRepository.SetItemPhoto called but SetItemPhotoFunc is not set")
+	}
+	return m.SetItemPhotoFunc(id, photo)
+}
+
+func (m *MockLostFoundRepository) GetItemPhoto(id int64) (*models.Photo, error) {
+	if m.GetItemPhotoFunc == nil {
+		panic("mocks: MockLostFoundRepository.GetItemPhoto called but GetItemPhotoFunc is not set")
+	}
+	return m.GetItemPhotoFunc(id)
+}
+
+func (m *MockLostFoundRepository) DeleteItem(id int64) error {
+	if m.DeleteItemFunc == nil {
+		panic("mocks: MockLostFoundRepository.DeleteItem called but DeleteItemFunc is not set")
+	}
+	return m.DeleteItemFunc(id)
+}
+
+func (m *MockLostFoundRepository) DeleteItemsFoundBefore(cutoff time.Time) (int64, error) {
+	if m.DeleteItemsFoundBeforeFunc == nil {
+		panic("mocks: MockLostFoundRepository.DeleteItemsFoundBefore called but DeleteItemsFoundBeforeFunc is not set")
+	}
+	return m.DeleteItemsFoundBeforeFunc(cutoff)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockOrderArchiveRepository is a hand-written mock of repositories.OrderArchiveRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockOrderArchiveRepository struct {
+	ArchiveOrdersFunc         func(repositories.SQLExecutor, string, string, int, time.Time) (int, error)
+	GetArchivedOrdersFunc     func(models.OrderFilters) ([]models.ArchivedOrder, int, error)
+	GetArchivedOrderByIDFunc  func(int64) (*models.ArchivedOrder, error)
+	GetArchivedOrderItemsFunc func(int64) ([]models.OrderItem, error)
+}
+
+var _ repositories.OrderArchiveRepository = (*MockOrderArchiveRepository)(nil)
+
+func (m *MockOrderArchiveRepository) ArchiveOrders(executor repositories.SQLExecutor, status, before string, limit int, archivedAt time.Time) (int, error) {
+	if m.ArchiveOrdersFunc == nil {
+		panic("mocks: MockOrderArchiveRepository.ArchiveOrders called but ArchiveOrdersFunc is not set")
+	}
+	return m.ArchiveOrdersFunc(executor, status, before, limit, archivedAt)
+}
+
+func (m *MockOrderArchiveRepository) GetArchivedOrders(filters models.OrderFilters) ([]models.ArchivedOrder, int, error) {
+	if m.GetArchivedOrdersFunc == nil {
+		panic("mocks: MockOrderArchiveRepository.GetArchivedOrders called but GetArchivedOrdersFunc is not set")
+	}
+	return m.GetArchivedOrdersFunc(filters)
+}
+
+func (m *MockOrderArchiveRepository) GetArchivedOrderByID(orderID int64) (*models.ArchivedOrder, error) {
+	if m.GetArchivedOrderByIDFunc == nil {
+		panic("mocks: MockOrderArchiveRepository.GetArchivedOrderByID called but GetArchivedOrderByIDFunc is not set")
+	}
+	return m.GetArchivedOrderByIDFunc(orderID)
+}
+
+func (m *MockOrderArchiveRepository) GetArchivedOrderItems(orderID int64) ([]models.OrderItem, error) {
+	if m.GetArchivedOrderItemsFunc == nil {
+		panic("mocks: MockOrderArchiveRepository.GetArchivedOrderItems called but GetArchivedOrderItemsFunc is not set")
+	}
+	return m.GetArchivedOrderItemsFunc(orderID)
+}
@@ -0,0 +1,152 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockOrderRepository is a hand-written mock of repositories.OrderRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockOrderRepository struct {
+	CreateOrderFunc               func(repositories.SQLExecutor, *models.Order) (int64, error)
+	GetOrderByIDFunc              func(int64) (*models.Order, error)
+	GetOrderByNumberFunc          func(string, string, int) (*models.Order, error)
+	GetOrderByUUIDFunc            func(string) (*models.Order, error)
+	NextDailyOrderNumberFunc      func(repositories.SQLExecutor, string, string) (int, error)
+	GetOrdersFunc                 func(models.OrderFilters) ([]models.Order, int, error)
+	StreamOrdersFunc              func(models.OrderFilters, func(*models.Order) error) error
+	UpdateOrderStatusFunc         func(repositories.SQLExecutor, int64, string, int, time.Time) error
+	DeleteOrderFunc               func(repositories.SQLExecutor, int64) (int64, error)
+	CreateOrderItemFunc           func(repositories.SQLExecutor, *models.OrderItem) (int64, error)
+	GetOrderItemsByOrderIDFunc    func(int64) ([]models.OrderItem, error)
+	GetOrderItemsByOrderIDsFunc   func([]int64) ([]models.OrderItem, error)
+	StreamOrderItemsFunc          func(models.OrderFilters, func(*models.OrderItem) error) error
+	DeleteOrderItemsByOrderIDFunc func(repositories.SQLExecutor, int64) (int64, error)
+	DeleteOrderItemFunc           func(repositories.SQLExecutor, int64, int64) error
+	UpdateOrderAmountsFunc        func(repositories.SQLExecutor, *models.Order, int, time.Time) error
+	CreateOrderVoidFunc           func(repositories.SQLExecutor, *models.OrderVoid) error
+}
+
+var _ repositories.OrderRepository = (*MockOrderRepository)(nil)
+
+func (m *MockOrderRepository) CreateOrder(executor repositories.SQLExecutor, order *models.Order) (int64, error) {
+	if m.CreateOrderFunc == nil {
+		panic("mocks: MockOrderRepository.CreateOrder called but CreateOrderFunc is not set")
+	}
+	return m.CreateOrderFunc(executor, order)
+}
+
+func (m *MockOrderRepository) GetOrderByID(orderID int64) (*models.Order, error) {
+	if m.GetOrderByIDFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrderByID called but GetOrderByIDFunc is not set")
+	}
+	return m.GetOrderByIDFunc(orderID)
+}
+
+func (m *MockOrderRepository) GetOrderByNumber(branchCode, businessDate string, dailyNumber int) (*models.Order, error) {
+	if m.GetOrderByNumberFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrderByNumber called but GetOrderByNumberFunc is not set")
+	}
+	return m.GetOrderByNumberFunc(branchCode, businessDate, dailyNumber)
+}
+
+func (m *MockOrderRepository) GetOrderByUUID(uuid string) (*models.Order, error) {
+	if m.GetOrderByUUIDFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrderByUUID called but GetOrderByUUIDFunc is not set")
+	}
+	return m.GetOrderByUUIDFunc(uuid)
+}
+
+func (m *MockOrderRepository) NextDailyOrderNumber(executor repositories.SQLExecutor, branchCode, businessDate string) (int, error) {
+	if m.NextDailyOrderNumberFunc == nil {
+		panic("mocks: MockOrderRepository.NextDailyOrderNumber called but NextDailyOrderNumberFunc is not set")
+	}
+	return m.NextDailyOrderNumberFunc(executor, branchCode, businessDate)
+}
+
+func (m *MockOrderRepository) GetOrders(filters models.OrderFilters) ([]models.Order, int, error) {
+	if m.GetOrdersFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrders called but GetOrdersFunc is not set")
+	}
+	return m.GetOrdersFunc(filters)
+}
+
+func (m *MockOrderRepository) StreamOrders(filters models.OrderFilters, fn func(*models.Order) error) error {
+	if m.StreamOrdersFunc == nil {
+		panic("mocks: MockOrderRepository.StreamOrders called but StreamOrdersFunc is not set")
+	}
+	return m.StreamOrdersFunc(filters, fn)
+}
+
+func (m *MockOrderRepository) UpdateOrderStatus(executor repositories.SQLExecutor, orderID int64, newStatus string, expectedVersion int, updatedAt time.Time) error {
+	if m.UpdateOrderStatusFunc == nil {
+		panic("mocks: MockOrderRepository.UpdateOrderStatus called but UpdateOrderStatusFunc is not set")
+	}
+	return m.UpdateOrderStatusFunc(executor, orderID, newStatus, expectedVersion, updatedAt)
+}
+
+func (m *MockOrderRepository) DeleteOrder(executor repositories.SQLExecutor, orderID int64) (int64, error) {
+	if m.DeleteOrderFunc == nil {
+		panic("mocks: MockOrderRepository.DeleteOrder called but DeleteOrderFunc is not set")
+	}
+	return m.DeleteOrderFunc(executor, orderID)
+}
+
+func (m *MockOrderRepository) CreateOrderItem(executor repositories.SQLExecutor, item *models.OrderItem) (int64, error) {
+	if m.CreateOrderItemFunc == nil {
+		panic("mocks: MockOrderRepository.CreateOrderItem called but CreateOrderItemFunc is not set")
+	}
+	return m.CreateOrderItemFunc(executor, item)
+}
+
+func (m *MockOrderRepository) GetOrderItemsByOrderID(orderID int64) ([]models.OrderItem, error) {
+	if m.GetOrderItemsByOrderIDFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrderItemsByOrderID called but GetOrderItemsByOrderIDFunc is not set")
+	}
+	return m.GetOrderItemsByOrderIDFunc(orderID)
+}
+
+func (m *MockOrderRepository) GetOrderItemsByOrderIDs(orderIDs []int64) ([]models.OrderItem, error) {
+	if m.GetOrderItemsByOrderIDsFunc == nil {
+		panic("mocks: MockOrderRepository.GetOrderItemsByOrderIDs called but GetOrderItemsByOrderIDsFunc is not set")
+	}
+	return m.GetOrderItemsByOrderIDsFunc(orderIDs)
+}
+
+func (m *MockOrderRepository) StreamOrderItems(filters models.OrderFilters, fn func(*models.OrderItem) error) error {
+	if m.StreamOrderItemsFunc == nil {
+		panic("mocks: MockOrderRepository.StreamOrderItems called but StreamOrderItemsFunc is not set")
+	}
+	return m.StreamOrderItemsFunc(filters, fn)
+}
+
+func (m *MockOrderRepository) DeleteOrderItemsByOrderID(executor repositories.SQLExecutor, orderID int64) (int64, error) {
+	if m.DeleteOrderItemsByOrderIDFunc == nil {
+		panic("mocks: MockOrderRepository.DeleteOrderItemsByOrderID called but DeleteOrderItemsByOrderIDFunc is not set")
+	}
+	return m.DeleteOrderItemsByOrderIDFunc(executor, orderID)
+}
+
+func (m *MockOrderRepository) DeleteOrderItem(executor repositories.SQLExecutor, orderID, itemID int64) error {
+	if m.DeleteOrderItemFunc == nil {
+		panic("mocks: MockOrderRepository.DeleteOrderItem called but DeleteOrderItemFunc is not set")
+	}
+	return m.DeleteOrderItemFunc(executor, orderID, itemID)
+}
+
+func (m *MockOrderRepository) UpdateOrderAmounts(executor repositories.SQLExecutor, order *models.Order, expectedVersion int, updatedAt time.Time) error {
+	if m.UpdateOrderAmountsFunc == nil {
+		panic("mocks: MockOrderRepository.UpdateOrderAmounts called but UpdateOrderAmountsFunc is not set")
+	}
+	return m.UpdateOrderAmountsFunc(executor, order, expectedVersion, updatedAt)
+}
+
+func (m *MockOrderRepository) CreateOrderVoid(executor repositories.SQLExecutor, void *models.OrderVoid) error {
+	if m.CreateOrderVoidFunc == nil {
+		panic("mocks: MockOrderRepository.CreateOrderVoid called but CreateOrderVoidFunc is not set")
+	}
+	return m.CreateOrderVoidFunc(executor, void)
+}
@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockOutboxRepository is a hand-written mock of repositories.OutboxRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockOutboxRepository struct {
+	CreateEventFunc                 func(repositories.SQLExecutor, *models.DomainEvent) (int64, error)
+	ClaimPendingEventsFunc          func(repositories.SQLExecutor, time.Time, int) ([]models.DomainEvent, error)
+	MarkEventPublishedFunc          func(repositories.SQLExecutor, int64, time.Time) error
+	MarkEventFailedFunc             func(repositories.SQLExecutor, int64, string, time.Time) error
+	DeletePublishedEventsBeforeFunc func(time.Time) (int64, error)
+}
+
+var _ repositories.OutboxRepository = (*MockOutboxRepository)(nil)
+
+func (m *MockOutboxRepository) CreateEvent(executor repositories.SQLExecutor, event *models.DomainEvent) (int64, error) {
+	if m.CreateEventFunc == nil {
+		panic("mocks: MockOutboxRepository.CreateEvent called but CreateEventFunc is not set")
+	}
+	return m.CreateEventFunc(executor, event)
+}
+
+func (m *MockOutboxRepository) ClaimPendingEvents(executor repositories.SQLExecutor, now time.Time, limit int) ([]models.DomainEvent, error) {
+	if m.ClaimPendingEventsFunc == nil {
+		panic("mocks: MockOutboxRepository.ClaimPendingEvents called but ClaimPendingEventsFunc is not set")
+	}
+	return m.ClaimPendingEventsFunc(executor, now, limit)
+}
+
+func (m *MockOutboxRepository) MarkEventPublished(executor repositories.SQLExecutor, eventID int64, publishedAt time.Time) error {
+	if m.MarkEventPublishedFunc == nil {
+		panic("mocks: MockOutboxRepository.MarkEventPublished called but MarkEventPublishedFunc is not set")
+	}
+	return m.MarkEventPublishedFunc(executor, eventID, publishedAt)
+}
+
+func (m *MockOutboxRepository) MarkEventFailed(executor repositories.SQLExecutor, eventID int64, lastError string, nextAttemptAt time.Time) error {
+	if m.MarkEventFailedFunc == nil {
+		panic("mocks: MockOutboxRepository.MarkEventFailed called but MarkEventFailedFunc is not set")
+	}
+	return m.MarkEventFailedFunc(executor, eventID, lastError, nextAttemptAt)
+}
+
+func (m *MockOutboxRepository) DeletePublishedEventsBefore(before time.Time) (int64, error) {
+	if m.DeletePublishedEventsBeforeFunc == nil {
+		panic("mocks: MockOutboxRepository.DeletePublishedEventsBefore called but DeletePublishedEventsBeforeFunc is not set")
+	}
+	return m.DeletePublishedEventsBeforeFunc(before)
+}
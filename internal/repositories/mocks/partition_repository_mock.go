@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/repositories"
+)
+
+// MockPartitionRepository is a hand-written mock of repositories.PartitionRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockPartitionRepository struct {
+	CreateMonthlyPartitionsFunc func(string, time.Time, time.Time) ([]string, error)
+}
+
+var _ repositories.PartitionRepository = (*MockPartitionRepository)(nil)
+
+func (m *MockPartitionRepository) CreateMonthlyPartitions(table string, from, through time.Time) ([]string, error) {
+	if m.CreateMonthlyPartitionsFunc == nil {
+		panic("mocks: MockPartitionRepository.CreateMonthlyPartitions called but CreateMonthlyPartitionsFunc is not set")
+	}
+	return m.CreateMonthlyPartitionsFunc(table, from, through)
+}
@@ -0,0 +1,136 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockPricelistRepository is a hand-written mock of repositories.PricelistRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockPricelistRepository struct {
+	CreateCategoryFunc       func(repositories.SQLExecutor, *models.PricelistCategory) (int64, error)
+	GetCategoryByIDFunc      func(int64) (*models.PricelistCategory, error)
+	GetCategoriesFunc        func(int, int) ([]models.PricelistCategory, int, error)
+	UpdateCategoryFunc       func(repositories.SQLExecutor, *models.PricelistCategory) error
+	DeleteCategoryFunc       func(repositories.SQLExecutor, int64) error
+	CreateItemFunc           func(repositories.SQLExecutor, *models.PricelistItem) (int64, error)
+	GetItemByIDFunc          func(int64) (*models.PricelistItem, error)
+	GetItemsFunc             func(*int64, *string, int, int) ([]models.PricelistItem, int, error)
+	UpdateItemFunc           func(repositories.SQLExecutor, *models.PricelistItem) error
+	DeleteItemFunc           func(repositories.SQLExecutor, int64, int64, time.Time) error
+	RestoreItemFunc          func(repositories.SQLExecutor, int64, time.Time) error
+	UpdateStockFunc          func(repositories.SQLExecutor, int64, models.Quantity) (models.Quantity, error)
+	GetItemPriceAndStockFunc func(int64) (price models.Money, currentStock *models.Quantity, itemName string, tracksStock bool, err error)
+	GetItemUnitsFunc         func(int64) (models.ItemUnits, error)
+	GetItemTaxClassesFunc    func([]int64) (map[int64]string, error)
+}
+
+var _ repositories.PricelistRepository = (*MockPricelistRepository)(nil)
+
+func (m *MockPricelistRepository) CreateCategory(executor repositories.SQLExecutor, category *models.PricelistCategory) (int64, error) {
+	if m.CreateCategoryFunc == nil {
+		panic("mocks: MockPricelistRepository.CreateCategory called but CreateCategoryFunc is not set")
+	}
+	return m.CreateCategoryFunc(executor, category)
+}
+
+func (m *MockPricelistRepository) GetCategoryByID(id int64) (*models.PricelistCategory, error) {
+	if m.GetCategoryByIDFunc == nil {
+		panic("mocks: MockPricelistRepository.GetCategoryByID called but GetCategoryByIDFunc is not set")
+	}
+	return m.GetCategoryByIDFunc(id)
+}
+
+func (m *MockPricelistRepository) GetCategories(page int, pageSize int) ([]models.PricelistCategory, int, error) {
+	if m.GetCategoriesFunc == nil {
+		panic("mocks: MockPricelistRepository.GetCategories called but GetCategoriesFunc is not set")
+	}
+	return m.GetCategoriesFunc(page, pageSize)
+}
+
+func (m *MockPricelistRepository) UpdateCategory(executor repositories.SQLExecutor, category *models.PricelistCategory) error {
+	if m.UpdateCategoryFunc == nil {
+		panic("mocks: MockPricelistRepository.UpdateCategory called but UpdateCategoryFunc is not set")
+	}
+	return m.UpdateCategoryFunc(executor, category)
+}
+
+func (m *MockPricelistRepository) DeleteCategory(executor repositories.SQLExecutor, id int64) error {
+	if m.DeleteCategoryFunc == nil {
+		panic("mocks: MockPricelistRepository.DeleteCategory called but DeleteCategoryFunc is not set")
+	}
+	return m.DeleteCategoryFunc(executor, id)
+}
+
+func (m *MockPricelistRepository) CreateItem(executor repositories.SQLExecutor, item *models.PricelistItem) (int64, error) {
+	if m.CreateItemFunc == nil {
+		panic("mocks: MockPricelistRepository.CreateItem called but CreateItemFunc is not set")
+	}
+	return m.CreateItemFunc(executor, item)
+}
+
+func (m *MockPricelistRepository) GetItemByID(id int64) (*models.PricelistItem, error) {
+	if m.GetItemByIDFunc == nil {
+		panic("mocks: MockPricelistRepository.GetItemByID called but GetItemByIDFunc is not set")
+	}
+	return m.GetItemByIDFunc(id)
+}
+
+func (m *MockPricelistRepository) GetItems(categoryID *int64, itemType *string, page int, pageSize int) ([]models.PricelistItem, int, error) {
+	if m.GetItemsFunc == nil {
+		panic("mocks: MockPricelistRepository.GetItems called but GetItemsFunc is not set")
+	}
+	return m.GetItemsFunc(categoryID, itemType, page, pageSize)
+}
+
+func (m *MockPricelistRepository) UpdateItem(executor repositories.SQLExecutor, item *models.PricelistItem) error {
+	if m.UpdateItemFunc == nil {
+		panic("mocks: MockPricelistRepository.UpdateItem called but UpdateItemFunc is not set")
+	}
+	return m.UpdateItemFunc(executor, item)
+}
+
+func (m *MockPricelistRepository) DeleteItem(executor repositories.SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	if m.DeleteItemFunc == nil {
+		panic("mocks: MockPricelistRepository.DeleteItem called but DeleteItemFunc is not set")
+	}
+	return m.DeleteItemFunc(executor, id, deletedBy, now)
+}
+
+func (m *MockPricelistRepository) RestoreItem(executor repositories.SQLExecutor, id int64, now time.Time) error {
+	if m.RestoreItemFunc == nil {
+		panic("mocks: MockPricelistRepository.RestoreItem called but RestoreItemFunc is not set")
+	}
+	return m.RestoreItemFunc(executor, id, now)
+}
+
+func (m *MockPricelistRepository) UpdateStock(executor repositories.SQLExecutor, itemID int64, quantityChange models.Quantity) (models.Quantity, error) {
+	if m.UpdateStockFunc == nil {
+		panic("mocks: MockPricelistRepository.UpdateStock called but UpdateStockFunc is not set")
+	}
+	return m.UpdateStockFunc(executor, itemID, quantityChange)
+}
+
+func (m *MockPricelistRepository) GetItemPriceAndStock(itemID int64) (price models.Money, currentStock *models.Quantity, itemName string, tracksStock bool, err error) {
+	if m.GetItemPriceAndStockFunc == nil {
+		panic("mocks: MockPricelistRepository.GetItemPriceAndStock called but GetItemPriceAndStockFunc is not set")
+	}
+	return m.GetItemPriceAndStockFunc(itemID)
+}
+
+func (m *MockPricelistRepository) GetItemUnits(itemID int64) (models.ItemUnits, error) {
+	if m.GetItemUnitsFunc == nil {
+		panic("mocks: MockPricelistRepository.GetItemUnits called but GetItemUnitsFunc is not set")
+	}
+	return m.GetItemUnitsFunc(itemID)
+}
+
+func (m *MockPricelistRepository) GetItemTaxClasses(itemIDs []int64) (map[int64]string, error) {
+	if m.GetItemTaxClassesFunc == nil {
+		panic("mocks: MockPricelistRepository.GetItemTaxClasses called but GetItemTaxClassesFunc is not set")
+	}
+	return m.GetItemTaxClassesFunc(itemIDs)
+}
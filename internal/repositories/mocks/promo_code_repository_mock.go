@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockPromoCodeRepository is a hand-written mock of repositories.PromoCodeRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockPromoCodeRepository struct {
+	CreatePromoCodeFunc    func(*models.PromoCode) error
+	GetPromoCodeByIDFunc   func(int64) (*models.PromoCode, error)
+	GetPromoCodeByCodeFunc func(repositories.SQLExecutor, string) (*models.PromoCode, error)
+	GetPromoCodesFunc      func(bool) ([]models.PromoCode, error)
+	UpdatePromoCodeFunc    func(*models.PromoCode) error
+	CreateRedemptionFunc   func(repositories.SQLExecutor, *models.PromoCodeRedemption) error
+}
+
+var _ repositories.PromoCodeRepository = (*MockPromoCodeRepository)(nil)
+
+func (m *MockPromoCodeRepository) CreatePromoCode(promo *models.PromoCode) error {
+	if m.CreatePromoCodeFunc == nil {
+		panic("mocks: MockPromoCodeRepository.CreatePromoCode called but CreatePromoCodeFunc is not set")
+	}
+	return m.CreatePromoCodeFunc(promo)
+}
+
+func (m *MockPromoCodeRepository) GetPromoCodeByID(id int64) (*models.PromoCode, error) {
+	if m.GetPromoCodeByIDFunc == nil {
+		panic("mocks: MockPromoCodeRepository.GetPromoCodeByID called but GetPromoCodeByIDFunc is not set")
+	}
+	return m.GetPromoCodeByIDFunc(id)
+}
+
+func (m *MockPromoCodeRepository) GetPromoCodeByCode(executor repositories.SQLExecutor, code string) (*models.PromoCode, error) {
+	if m.GetPromoCodeByCodeFunc == nil {
+		panic("mocks: MockPromoCodeRepository.GetPromoCodeByCode called but GetPromoCodeByCodeFunc is not set")
+	}
+	return m.GetPromoCodeByCodeFunc(executor, code)
+}
+
+func (m *MockPromoCodeRepository) GetPromoCodes(activeOnly bool) ([]models.PromoCode, error) {
+	if m.GetPromoCodesFunc == nil {
+		panic("mocks: MockPromoCodeRepository.GetPromoCodes called but GetPromoCodesFunc is not set")
+	}
+	return m.GetPromoCodesFunc(activeOnly)
+}
+
+func (m *MockPromoCodeRepository) UpdatePromoCode(promo *models.PromoCode) error {
+	if m.UpdatePromoCodeFunc == nil {
+		panic("mocks: MockPromoCodeRepository.UpdatePromoCode called but UpdatePromoCodeFunc is not set")
+	}
+	return m.UpdatePromoCodeFunc(promo)
+}
+
+func (m *MockPromoCodeRepository) CreateRedemption(executor repositories.SQLExecutor, redemption *models.PromoCodeRedemption) error {
+	if m.CreateRedemptionFunc == nil {
+		panic("mocks: MockPromoCodeRepository.CreateRedemption called but CreateRedemptionFunc is not set")
+	}
+	return m.CreateRedemptionFunc(executor, redemption)
+}
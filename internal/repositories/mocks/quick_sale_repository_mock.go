@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockQuickSaleRepository is a hand-written mock of repositories.QuickSaleRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockQuickSaleRepository struct {
+	CreatePresetFunc       func(repositories.SQLExecutor, *models.QuickSalePreset) error
+	GetPresetByIDFunc      func(int64) (*models.QuickSalePreset, error)
+	GetPresetsFunc         func(string, bool) ([]models.QuickSalePreset, error)
+	UpdatePresetFunc       func(repositories.SQLExecutor, *models.QuickSalePreset, int) error
+	ReplacePresetItemsFunc func(repositories.SQLExecutor, int64, []models.QuickSalePresetItem) error
+	DeletePresetFunc       func(int64) error
+}
+
+var _ repositories.QuickSaleRepository = (*MockQuickSaleRepository)(nil)
+
+func (m *MockQuickSaleRepository) CreatePreset(executor repositories.SQLExecutor, preset *models.QuickSalePreset) error {
+	if m.CreatePresetFunc == nil {
+		panic("mocks: MockQuickSaleRepository.CreatePreset called but CreatePresetFunc is not set")
+	}
+	return m.CreatePresetFunc(executor, preset)
+}
+
+func (m *MockQuickSaleRepository) GetPresetByID(id int64) (*models.QuickSalePreset, error) {
+	if m.GetPresetByIDFunc == nil {
+		panic("mocks: MockQuickSaleRepository.GetPresetByID called but GetPresetByIDFunc is not set")
+	}
+	return m.GetPresetByIDFunc(id)
+}
+
+func (m *MockQuickSaleRepository) GetPresets(branchCode string, activeOnly bool) ([]models.QuickSalePreset, error) {
+	if m.GetPresetsFunc == nil {
+		panic("mocks: MockQuickSaleRepository.GetPresets called but GetPresetsFunc is not set")
+	}
+	return m.GetPresetsFunc(branchCode, activeOnly)
+}
+
+func (m *MockQuickSaleRepository) UpdatePreset(executor repositories.SQLExecutor, preset *models.QuickSalePreset, version int) error {
+	if m.UpdatePresetFunc == nil {
+		panic("mocks: MockQuickSaleRepository.UpdatePreset called but UpdatePresetFunc is not set")
+	}
+	return m.UpdatePresetFunc(executor, preset, version)
+}
+
+func (m *MockQuickSaleRepository) ReplacePresetItems(executor repositories.SQLExecutor, presetID int64, items []models.QuickSalePresetItem) error {
+	if m.ReplacePresetItemsFunc == nil {
+		panic("mocks: MockQuickSaleRepository.ReplacePresetItems called but ReplacePresetItemsFunc is not set")
+	}
+	return m.ReplacePresetItemsFunc(executor, presetID, items)
+}
+
+func (m *MockQuickSaleRepository) DeletePreset(id int64) error {
+	if m.DeletePresetFunc == nil {
+		panic("mocks: MockQuickSaleRepository.DeletePreset called but DeletePresetFunc is not set")
+	}
+	return m.DeletePresetFunc(id)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockReferenceRepository is a hand-written mock of repositories.ReferenceRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockReferenceRepository struct {
+	RecordExistsFunc    func(string, int64) (bool, error)
+	CountReferencesFunc func(string, int64) ([]models.RecordReference, error)
+}
+
+var _ repositories.ReferenceRepository = (*MockReferenceRepository)(nil)
+
+func (m *MockReferenceRepository) RecordExists(table string, id int64) (bool, error) {
+	if m.RecordExistsFunc == nil {
+		panic("mocks: MockReferenceRepository.RecordExists called but RecordExistsFunc is not set")
+	}
+	return m.RecordExistsFunc(table, id)
+}
+
+func (m *MockReferenceRepository) CountReferences(table string, id int64) ([]models.RecordReference, error) {
+	if m.CountReferencesFunc == nil {
+		panic("mocks: MockReferenceRepository.CountReferences called but CountReferencesFunc is not set")
+	}
+	return m.CountReferencesFunc(table, id)
+}
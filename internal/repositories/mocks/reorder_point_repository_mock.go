@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockReorderPointRepository is a hand-written mock of repositories.ReorderPointRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockReorderPointRepository struct {
+	GetItemConsumptionFunc                  func(time.Time, []string) ([]models.ReorderPoint, error)
+	SaveReorderPointFunc                    func(repositories.SQLExecutor, *models.ReorderPoint) error
+	DeleteReorderPointsCalculatedBeforeFunc func(repositories.SQLExecutor, time.Time) error
+	SetLowStockThresholdFunc                func(repositories.SQLExecutor, int64, models.Quantity) error
+	GetReorderPointsFunc                    func() ([]models.ReorderPoint, error)
+}
+
+var _ repositories.ReorderPointRepository = (*MockReorderPointRepository)(nil)
+
+func (m *MockReorderPointRepository) GetItemConsumption(since time.Time, consumptionTypes []string) ([]models.ReorderPoint, error) {
+	if m.GetItemConsumptionFunc == nil {
+		panic("mocks: MockReorderPointRepository.GetItemConsumption called but GetItemConsumptionFunc is not set")
+	}
+	return m.GetItemConsumptionFunc(since, consumptionTypes)
+}
+
+func (m *MockReorderPointRepository) SaveReorderPoint(executor repositories.SQLExecutor, point *models.ReorderPoint) error {
+	if m.SaveReorderPointFunc == nil {
+		panic("mocks: MockReorderPointRepository.SaveReorderPoint called but SaveReorderPointFunc is not set")
+	}
+	return m.SaveReorderPointFunc(executor, point)
+}
+
+func (m *MockReorderPointRepository) DeleteReorderPointsCalculatedBefore(executor repositories.SQLExecutor, cutoff time.Time) error {
+	if m.DeleteReorderPointsCalculatedBeforeFunc == nil {
+		panic("mocks: MockReorderPointRepository.DeleteReorderPointsCalculatedBefore called but DeleteReorderPointsCalculatedBeforeFunc is not set")
+	}
+	return m.DeleteReorderPointsCalculatedBeforeFunc(executor, cutoff)
+}
+
+func (m *MockReorderPointRepository) SetLowStockThreshold(executor repositories.SQLExecutor, itemID int64, threshold models.Quantity) error {
+	if m.SetLowStockThresholdFunc == nil {
+		panic("mocks: MockReorderPointRepository.SetLowStockThreshold called but SetLowStockThresholdFunc is not set")
+	}
+	return m.SetLowStockThresholdFunc(executor, itemID, threshold)
+}
+
+func (m *MockReorderPointRepository) GetReorderPoints() ([]models.ReorderPoint, error) {
+	if m.GetReorderPointsFunc == nil {
+		panic("mocks: MockReorderPointRepository.GetReorderPoints called but GetReorderPointsFunc is not set")
+	}
+	return m.GetReorderPointsFunc()
+}
@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockReportRepository is a hand-written mock of repositories.ReportRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockReportRepository struct {
+	GetDashboardSummaryFunc  func(time.Time) (*models.DashboardSummary, error)
+	GetActivityEventsFunc    func(models.ActivityFilter) ([]models.DomainEvent, error)
+	GetTaxSummaryFunc        func(models.TaxReportFilter) ([]models.TaxReportItem, error)
+	GetSalesSeriesFunc       func(models.SeriesFilter) ([]models.SeriesBucket, error)
+	GetSessionSeriesFunc     func(models.SeriesFilter) ([]models.SeriesBucket, error)
+	GetHourlyUtilizationFunc func(time.Time, time.Time) ([]models.HourlyUtilization, error)
+	GetVoidsFunc             func(string, time.Time, time.Time) ([]models.OrderVoid, error)
+	GetDiscountsByPeriodFunc func(models.DiscountReportFilter) ([]models.DiscountTotal, error)
+	GetDiscountsByStaffFunc  func(models.DiscountReportFilter) ([]models.DiscountTotal, error)
+	GetPromoCodeUsageFunc    func(models.DiscountReportFilter) ([]models.PromoCodeUsage, error)
+	GetSalesBySourceFunc     func(models.SourceReportFilter) ([]models.SourceTotal, error)
+	GetBookingsBySourceFunc  func(models.SourceReportFilter) ([]models.SourceTotal, error)
+}
+
+var _ repositories.ReportRepository = (*MockReportRepository)(nil)
+
+func (m *MockReportRepository) GetDashboardSummary(now time.Time) (*models.DashboardSummary, error) {
+	if m.GetDashboardSummaryFunc == nil {
+		panic("mocks: MockReportRepository.GetDashboardSummary called but GetDashboardSummaryFunc is not set")
+	}
+	return m.GetDashboardSummaryFunc(now)
+}
+
+func (m *MockReportRepository) GetActivityEvents(filter models.ActivityFilter) ([]models.DomainEvent, error) {
+	if m.GetActivityEventsFunc == nil {
+		panic("mocks: MockReportRepository.GetActivityEvents called but GetActivityEventsFunc is not set")
+	}
+	return m.GetActivityEventsFunc(filter)
+}
+
+func (m *MockReportRepository) GetTaxSummary(filter models.TaxReportFilter) ([]models.TaxReportItem, error) {
+	if m.GetTaxSummaryFunc == nil {
+		panic("mocks: MockReportRepository.GetTaxSummary called but GetTaxSummaryFunc is not set")
+	}
+	return m.GetTaxSummaryFunc(filter)
+}
+
+func (m *MockReportRepository) GetSalesSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error) {
+	if m.GetSalesSeriesFunc == nil {
+		panic("mocks: MockReportRepository.GetSalesSeries called but GetSalesSeriesFunc is not set")
+	}
+	return m.GetSalesSeriesFunc(filter)
+}
+
+func (m *MockReportRepository) GetSessionSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error) {
+	if m.GetSessionSeriesFunc == nil {
+		panic("mocks: MockReportRepository.GetSessionSeries called but GetSessionSeriesFunc is not set")
+	}
+	return m.GetSessionSeriesFunc(filter)
+}
+
+func (m *MockReportRepository) GetHourlyUtilization(from, to time.Time) ([]models.HourlyUtilization, error) {
+	if m.GetHourlyUtilizationFunc == nil {
+		panic("mocks: MockReportRepository.GetHourlyUtilization called but GetHourlyUtilizationFunc is not set")
+	}
+	return m.GetHourlyUtilizationFunc(from, to)
+}
+
+func (m *MockReportRepository) GetVoids(branchCode string, from, to time.Time) ([]models.OrderVoid, error) {
+	if m.GetVoidsFunc == nil {
+		panic("mocks: MockReportRepository.GetVoids called but GetVoidsFunc is not set")
+	}
+	return m.GetVoidsFunc(branchCode, from, to)
+}
+
+func (m *MockReportRepository) GetDiscountsByPeriod(filter models.DiscountReportFilter) ([]models.DiscountTotal, error) {
+	if m.GetDiscountsByPeriodFunc == nil {
+		panic("mocks: MockReportRepository.GetDiscountsByPeriod called but GetDiscountsByPeriodFunc is not set")
+	}
+	return m.GetDiscountsByPeriodFunc(filter)
+}
+
+func (m *MockReportRepository) GetDiscountsByStaff(filter models.DiscountReportFilter) ([]models.DiscountTotal, error) {
+	if m.GetDiscountsByStaffFunc == nil {
+		panic("mocks: MockReportRepository.GetDiscountsByStaff called but GetDiscountsByStaffFunc is not set")
+	}
+	return m.GetDiscountsByStaffFunc(filter)
+}
+
+func (m *MockReportRepository) GetPromoCodeUsage(filter models.DiscountReportFilter) ([]models.PromoCodeUsage, error) {
+	if m.GetPromoCodeUsageFunc == nil {
+		panic("mocks: MockReportRepository.GetPromoCodeUsage called but GetPromoCodeUsageFunc is not set")
+	}
+	return m.GetPromoCodeUsageFunc(filter)
+}
+
+func (m *MockReportRepository) GetSalesBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error) {
+	if m.GetSalesBySourceFunc == nil {
+		panic("mocks: MockReportRepository.GetSalesBySource called but GetSalesBySourceFunc is not set")
+	}
+	return m.GetSalesBySourceFunc(filter)
+}
+
+func (m *MockReportRepository) GetBookingsBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error) {
+	if m.GetBookingsBySourceFunc == nil {
+		panic("mocks: MockReportRepository.GetBookingsBySource called but GetBookingsBySourceFunc is not set")
+	}
+	return m.GetBookingsBySourceFunc(filter)
+}
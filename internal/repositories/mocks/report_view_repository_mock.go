@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockReportViewRepository is a hand-written mock of repositories.ReportViewRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockReportViewRepository struct {
+	RefreshViewFunc  func(string) (*models.ReportViewRefresh, error)
+	GetRefreshesFunc func() ([]models.ReportViewRefresh, error)
+}
+
+var _ repositories.ReportViewRepository = (*MockReportViewRepository)(nil)
+
+func (m *MockReportViewRepository) RefreshView(viewName string) (*models.ReportViewRefresh, error) {
+	if m.RefreshViewFunc == nil {
+		panic("mocks: MockReportViewRepository.RefreshView called but RefreshViewFunc is not set")
+	}
+	return m.RefreshViewFunc(viewName)
+}
+
+func (m *MockReportViewRepository) GetRefreshes() ([]models.ReportViewRefresh, error) {
+	if m.GetRefreshesFunc == nil {
+		panic("mocks: MockReportViewRepository.GetRefreshes called but GetRefreshesFunc is not set")
+	}
+	return m.GetRefreshesFunc()
+}
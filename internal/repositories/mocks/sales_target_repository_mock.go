@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSalesTargetRepository is a hand-written mock of repositories.SalesTargetRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSalesTargetRepository struct {
+	CreateTargetFunc         func(*models.SalesTarget) error
+	GetTargetByIDFunc        func(int64) (*models.SalesTarget, error)
+	GetTargetsFunc           func(string, string) ([]models.SalesTarget, error)
+	UpdateTargetAmountFunc   func(int64, models.Money) (*models.SalesTarget, error)
+	DeleteTargetFunc         func(int64) error
+	GetUnnotifiedTargetsFunc func(repositories.SQLExecutor, string, string) ([]models.SalesTarget, error)
+	MarkBehindNotifiedFunc   func(repositories.SQLExecutor, int64, time.Time) error
+}
+
+var _ repositories.SalesTargetRepository = (*MockSalesTargetRepository)(nil)
+
+func (m *MockSalesTargetRepository) CreateTarget(target *models.SalesTarget) error {
+	if m.CreateTargetFunc == nil {
+		panic("mocks: MockSalesTargetRepository.CreateTarget called but CreateTargetFunc is not set")
+	}
+	return m.CreateTargetFunc(target)
+}
+
+func (m *MockSalesTargetRepository) GetTargetByID(id int64) (*models.SalesTarget, error) {
+	if m.GetTargetByIDFunc == nil {
+		panic("mocks: MockSalesTargetRepository.GetTargetByID called but GetTargetByIDFunc is not set")
+	}
+	return m.GetTargetByIDFunc(id)
+}
+
+func (m *MockSalesTargetRepository) GetTargets(branchCode, month string) ([]models.SalesTarget, error) {
+	if m.GetTargetsFunc == nil {
+		panic("mocks: MockSalesTargetRepository.GetTargets called but GetTargetsFunc is not set")
+	}
+	return m.GetTargetsFunc(branchCode, month)
+}
+
+func (m *MockSalesTargetRepository) UpdateTargetAmount(id int64, amount models.Money) (*models.SalesTarget, error) {
+	if m.UpdateTargetAmountFunc == nil {
+		panic("mocks: MockSalesTargetRepository.UpdateTargetAmount called but UpdateTargetAmountFunc is not set")
+	}
+	return m.UpdateTargetAmountFunc(id, amount)
+}
+
+func (m *MockSalesTargetRepository) DeleteTarget(id int64) error {
+	if m.DeleteTargetFunc == nil {
+		panic("mocks: MockSalesTargetRepository.DeleteTarget called but DeleteTargetFunc is not set")
+	}
+	return m.DeleteTargetFunc(id)
+}
+
+func (m *MockSalesTargetRepository) GetUnnotifiedTargets(executor repositories.SQLExecutor, branchCode, month string) ([]models.SalesTarget, error) {
+	if m.GetUnnotifiedTargetsFunc == nil {
+		panic("mocks: MockSalesTargetRepository.GetUnnotifiedTargets called but GetUnnotifiedTargetsFunc is not set")
+	}
+	return m.GetUnnotifiedTargetsFunc(executor, branchCode, month)
+}
+
+func (m *MockSalesTargetRepository) MarkBehindNotified(executor repositories.SQLExecutor, id int64, notifiedAt time.Time) error {
+	if m.MarkBehindNotifiedFunc == nil {
+		panic("mocks: MockSalesTargetRepository.MarkBehindNotified called but MarkBehindNotifiedFunc is not set")
+	}
+	return m.MarkBehindNotifiedFunc(executor, id, notifiedAt)
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSavedReportRepository is a hand-written mock of repositories.SavedReportRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSavedReportRepository struct {
+	CreateReportFunc    func(*models.SavedReport) error
+	GetReportByIDFunc   func(int64) (*models.SavedReport, error)
+	GetReportsFunc      func() ([]models.SavedReport, error)
+	UpdateReportFunc    func(*models.SavedReport) error
+	DeleteReportFunc    func(int64) error
+	GetDueReportsFunc   func(repositories.SQLExecutor, time.Time) ([]models.SavedReport, error)
+	ScheduleNextRunFunc func(repositories.SQLExecutor, int64, time.Time, time.Time) error
+	SetLastErrorFunc    func(int64, *string) error
+}
+
+var _ repositories.SavedReportRepository = (*MockSavedReportRepository)(nil)
+
+func (m *MockSavedReportRepository) CreateReport(report *models.SavedReport) error {
+	if m.CreateReportFunc == nil {
+		panic("mocks: MockSavedReportRepository.CreateReport called but CreateReportFunc is not set")
+	}
+	return m.CreateReportFunc(report)
+}
+
+func (m *MockSavedReportRepository) GetReportByID(id int64) (*models.SavedReport, error) {
+	if m.GetReportByIDFunc == nil {
+		panic("mocks: MockSavedReportRepository.GetReportByID called but GetReportByIDFunc is not set")
+	}
+	return m.GetReportByIDFunc(id)
+}
+
+func (m *MockSavedReportRepository) GetReports() ([]models.SavedReport, error) {
+	if m.GetReportsFunc == nil {
+		panic("mocks: MockSavedReportRepository.GetReports called but GetReportsFunc is not set")
+	}
+	return m.GetReportsFunc()
+}
+
+func (m *MockSavedReportRepository) UpdateReport(report *models.SavedReport) error {
+	if m.UpdateReportFunc == nil {
+		panic("mocks: MockSavedReportRepository.UpdateReport called but UpdateReportFunc is not set")
+	}
+	return m.UpdateReportFunc(report)
+}
+
+func (m *MockSavedReportRepository) DeleteReport(id int64) error {
+	if m.DeleteReportFunc == nil {
+		panic("mocks: MockSavedReportRepository.DeleteReport called but DeleteReportFunc is not set")
+	}
+	return m.DeleteReportFunc(id)
+}
+
+func (m *MockSavedReportRepository) GetDueReports(executor repositories.SQLExecutor, now time.Time) ([]models.SavedReport, error) {
+	if m.GetDueReportsFunc == nil {
+		panic("mocks: MockSavedReportRepository.GetDueReports called but GetDueReportsFunc is not set")
+	}
+	return m.GetDueReportsFunc(executor, now)
+}
+
+func (m *MockSavedReportRepository) ScheduleNextRun(executor repositories.SQLExecutor, id int64, ranAt, nextRunAt time.Time) error {
+	if m.ScheduleNextRunFunc == nil {
+		panic("mocks: MockSavedReportRepository.ScheduleNextRun called but ScheduleNextRunFunc is not set")
+	}
+	return m.ScheduleNextRunFunc(executor, id, ranAt, nextRunAt)
+}
+
+func (m *MockSavedReportRepository) SetLastError(id int64, lastError *string) error {
+	if m.SetLastErrorFunc == nil {
+		panic("mocks: MockSavedReportRepository.SetLastError called but SetLastErrorFunc is not set")
+	}
+	return m.SetLastErrorFunc(id, lastError)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSearchRepository is a hand-written mock of repositories.SearchRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSearchRepository struct {
+	SearchOrdersFunc   func(string, *models.OrderNumberRef, int) ([]models.OrderSearchHit, error)
+	SearchBookingsFunc func(string, int) ([]models.BookingSearchHit, error)
+	SearchClientsFunc  func(string, int) ([]models.ClientSearchHit, error)
+}
+
+var _ repositories.SearchRepository = (*MockSearchRepository)(nil)
+
+func (m *MockSearchRepository) SearchOrders(term string, number *models.OrderNumberRef, limit int) ([]models.OrderSearchHit, error) {
+	if m.SearchOrdersFunc == nil {
+		panic("mocks: MockSearchRepository.SearchOrders called but SearchOrdersFunc is not set")
+	}
+	return m.SearchOrdersFunc(term, number, limit)
+}
+
+func (m *MockSearchRepository) SearchBookings(term string, limit int) ([]models.BookingSearchHit, error) {
+	if m.SearchBookingsFunc == nil {
+		panic("mocks: MockSearchRepository.SearchBookings called but SearchBookingsFunc is not set")
+	}
+	return m.SearchBookingsFunc(term, limit)
+}
+
+func (m *MockSearchRepository) SearchClients(term string, limit int) ([]models.ClientSearchHit, error) {
+	if m.SearchClientsFunc == nil {
+		panic("mocks: MockSearchRepository.SearchClients called but SearchClientsFunc is not set")
+	}
+	return m.SearchClientsFunc(term, limit)
+}
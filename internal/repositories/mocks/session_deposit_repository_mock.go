@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSessionDepositRepository is a hand-written mock of repositories.SessionDepositRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSessionDepositRepository struct {
+	CreateDepositFunc       func(repositories.SQLExecutor, *models.SessionDeposit) error
+	GetDepositBySessionFunc func(int64) (*models.SessionDeposit, error)
+	UpdateDepositFunc       func(repositories.SQLExecutor, *models.SessionDeposit, string) error
+}
+
+var _ repositories.SessionDepositRepository = (*MockSessionDepositRepository)(nil)
+
+func (m *MockSessionDepositRepository) CreateDeposit(executor repositories.SQLExecutor, deposit *models.SessionDeposit) error {
+	if m.CreateDepositFunc == nil {
+		panic("mocks: MockSessionDepositRepository.CreateDeposit called but CreateDepositFunc is not set")
+	}
+	return m.CreateDepositFunc(executor, deposit)
+}
+
+func (m *MockSessionDepositRepository) GetDepositBySession(sessionID int64) (*models.SessionDeposit, error) {
+	if m.GetDepositBySessionFunc == nil {
+		panic("mocks: MockSessionDepositRepository.GetDepositBySession called but GetDepositBySessionFunc is not set")
+	}
+	return m.GetDepositBySessionFunc(sessionID)
+}
+
+func (m *MockSessionDepositRepository) UpdateDeposit(executor repositories.SQLExecutor, deposit *models.SessionDeposit, fromStatus string) error {
+	if m.UpdateDepositFunc == nil {
+		panic("mocks: MockSessionDepositRepository.UpdateDeposit called but UpdateDepositFunc is not set")
+	}
+	return m.UpdateDepositFunc(executor, deposit, fromStatus)
+}
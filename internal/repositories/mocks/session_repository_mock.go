@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSessionRepository is a hand-written mock of repositories.SessionRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSessionRepository struct {
+	CreateSessionFunc             func(repositories.SQLExecutor, *models.UserSession) (*models.UserSession, error)
+	GetSessionByIDFunc            func(int64) (*models.UserSession, error)
+	GetActiveSessionsByUserIDFunc func(int64, time.Time) ([]models.UserSession, error)
+	RotateSessionTokenFunc        func(repositories.SQLExecutor, int64, string, string, *string, time.Time, time.Time) error
+	RevokeSessionFunc             func(repositories.SQLExecutor, int64, int64, time.Time) error
+}
+
+var _ repositories.SessionRepository = (*MockSessionRepository)(nil)
+
+func (m *MockSessionRepository) CreateSession(executor repositories.SQLExecutor, session *models.UserSession) (*models.UserSession, error) {
+	if m.CreateSessionFunc == nil {
+		panic("mocks: MockSessionRepository.CreateSession called but CreateSessionFunc is not set")
+	}
+	return m.CreateSessionFunc(executor, session)
+}
+
+func (m *MockSessionRepository) GetSessionByID(id int64) (*models.UserSession, error) {
+	if m.GetSessionByIDFunc == nil {
+		panic("mocks: MockSessionRepository.GetSessionByID called but GetSessionByIDFunc is not set")
+	}
+	return m.GetSessionByIDFunc(id)
+}
+
+func (m *MockSessionRepository) GetActiveSessionsByUserID(userID int64, now time.Time) ([]models.UserSession, error) {
+	if m.GetActiveSessionsByUserIDFunc == nil {
+		panic("mocks: MockSessionRepository.GetActiveSessionsByUserID called but GetActiveSessionsByUserIDFunc is not set")
+	}
+	return m.GetActiveSessionsByUserIDFunc(userID, now)
+}
+
+func (m *MockSessionRepository) RotateSessionToken(executor repositories.SQLExecutor, id int64, oldTokenID, newTokenID string, ipAddress *string, now, expiresAt time.Time) error {
+	if m.RotateSessionTokenFunc == nil {
+		panic("mocks: MockSessionRepository.RotateSessionToken called but RotateSessionTokenFunc is not set")
+	}
+	return m.RotateSessionTokenFunc(executor, id, oldTokenID, newTokenID, ipAddress, now, expiresAt)
+}
+
+func (m *MockSessionRepository) RevokeSession(executor repositories.SQLExecutor, id, userID int64, now time.Time) error {
+	if m.RevokeSessionFunc == nil {
+		panic("mocks: MockSessionRepository.RevokeSession called but RevokeSessionFunc is not set")
+	}
+	return m.RevokeSessionFunc(executor, id, userID, now)
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSettingRepository is a hand-written mock of repositories.SettingRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSettingRepository struct {
+	GetSettingByKeyFunc  func(string) (*models.ApplicationSetting, error)
+	GetSettingsFunc      func() ([]models.ApplicationSetting, error)
+	SaveSettingFunc      func(*models.ApplicationSetting) error
+	DeleteSettingFunc    func(string) error
+	ReencryptSecretsFunc func() (int, error)
+}
+
+var _ repositories.SettingRepository = (*MockSettingRepository)(nil)
+
+func (m *MockSettingRepository) GetSettingByKey(key string) (*models.ApplicationSetting, error) {
+	if m.GetSettingByKeyFunc == nil {
+		panic("mocks: MockSettingRepository.GetSettingByKey called but GetSettingByKeyFunc is not set")
+	}
+	return m.GetSettingByKeyFunc(key)
+}
+
+func (m *MockSettingRepository) GetSettings() ([]models.ApplicationSetting, error) {
+	if m.GetSettingsFunc == nil {
+		panic("mocks: MockSettingRepository.GetSettings called but GetSettingsFunc is not set")
+	}
+	return m.GetSettingsFunc()
+}
+
+func (m *MockSettingRepository) SaveSetting(setting *models.ApplicationSetting) error {
+	if m.SaveSettingFunc == nil {
+		panic("mocks: MockSettingRepository.SaveSetting called but SaveSettingFunc is not set")
+	}
+	return m.SaveSettingFunc(setting)
+}
+
+func (m *MockSettingRepository) DeleteSetting(key string) error {
+	if m.DeleteSettingFunc == nil {
+		panic("mocks: MockSettingRepository.DeleteSetting called but DeleteSettingFunc is not set")
+	}
+	return m.DeleteSettingFunc(key)
+}
+
+func (m *MockSettingRepository) ReencryptSecrets() (int, error) {
+	if m.ReencryptSecretsFunc == nil {
+		panic("mocks: MockSettingRepository.ReencryptSecrets called but ReencryptSecretsFunc is not set")
+	}
+	return m.ReencryptSecretsFunc()
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSetupRepository is a hand-written mock of repositories.SetupRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSetupRepository struct {
+	GetSetupStatusFunc func() (*models.SetupStatus, error)
+	ClaimSetupFunc     func(repositories.SQLExecutor, time.Time) error
+	SaveSettingsFunc   func(repositories.SQLExecutor, map[string]string, time.Time) error
+}
+
+var _ repositories.SetupRepository = (*MockSetupRepository)(nil)
+
+func (m *MockSetupRepository) GetSetupStatus() (*models.SetupStatus, error) {
+	if m.GetSetupStatusFunc == nil {
+		panic("mocks: MockSetupRepository.GetSetupStatus called but GetSetupStatusFunc is not set")
+	}
+	return m.GetSetupStatusFunc()
+}
+
+func (m *MockSetupRepository) ClaimSetup(executor repositories.SQLExecutor, now time.Time) error {
+	if m.ClaimSetupFunc == nil {
+		panic("mocks: MockSetupRepository.ClaimSetup called but ClaimSetupFunc is not set")
+	}
+	return m.ClaimSetupFunc(executor, now)
+}
+
+func (m *MockSetupRepository) SaveSettings(executor repositories.SQLExecutor, settings map[string]string, now time.Time) error {
+	if m.SaveSettingsFunc == nil {
+		panic("mocks: MockSetupRepository.SaveSettings called but SaveSettingsFunc is not set")
+	}
+	return m.SaveSettingsFunc(executor, settings, now)
+}
@@ -0,0 +1,104 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockShiftReportRepository is a hand-written mock of repositories.ShiftReportRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockShiftReportRepository struct {
+	GetSalesByPaymentFunc       func(repositories.SQLExecutor, string, []string, time.Time, time.Time) ([]models.ShiftPaymentTotal, error)
+	GetStoppedSessionTotalsFunc func(repositories.SQLExecutor, time.Time, time.Time) (int, models.Money, error)
+	GetAccountPaymentsTotalFunc func(repositories.SQLExecutor, string, time.Time, time.Time) (models.Money, error)
+	GetCashDepositsTotalFunc    func(repositories.SQLExecutor, time.Time, time.Time) (models.Money, error)
+	GetOpenOrdersFunc           func(repositories.SQLExecutor, string, []string) ([]models.HandoverOrder, error)
+	FindOverlappingShiftFunc    func(repositories.SQLExecutor, int64, time.Time, time.Time) (*int64, error)
+	CreateReportFunc            func(repositories.SQLExecutor, *models.ShiftReport) error
+	GetReportByIDFunc           func(int64) (*models.ShiftReport, error)
+	GetLatestReportForShiftFunc func(int64) (*models.ShiftReport, error)
+	GetReportsFunc              func(string, models.ShiftReportFilters) ([]models.ShiftReport, error)
+	MarkReportEmailedFunc       func(int64, time.Time) error
+}
+
+var _ repositories.ShiftReportRepository = (*MockShiftReportRepository)(nil)
+
+func (m *MockShiftReportRepository) GetSalesByPayment(executor repositories.SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.ShiftPaymentTotal, error) {
+	if m.GetSalesByPaymentFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetSalesByPayment called but GetSalesByPaymentFunc is not set")
+	}
+	return m.GetSalesByPaymentFunc(executor, branchCode, statuses, from, to)
+}
+
+func (m *MockShiftReportRepository) GetStoppedSessionTotals(executor repositories.SQLExecutor, from, to time.Time) (int, models.Money, error) {
+	if m.GetStoppedSessionTotalsFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetStoppedSessionTotals called but GetStoppedSessionTotalsFunc is not set")
+	}
+	return m.GetStoppedSessionTotalsFunc(executor, from, to)
+}
+
+func (m *MockShiftReportRepository) GetAccountPaymentsTotal(executor repositories.SQLExecutor, paymentMethod string, from, to time.Time) (models.Money, error) {
+	if m.GetAccountPaymentsTotalFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetAccountPaymentsTotal called but GetAccountPaymentsTotalFunc is not set")
+	}
+	return m.GetAccountPaymentsTotalFunc(executor, paymentMethod, from, to)
+}
+
+func (m *MockShiftReportRepository) GetCashDepositsTotal(executor repositories.SQLExecutor, from, to time.Time) (models.Money, error) {
+	if m.GetCashDepositsTotalFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetCashDepositsTotal called but GetCashDepositsTotalFunc is not set")
+	}
+	return m.GetCashDepositsTotalFunc(executor, from, to)
+}
+
+func (m *MockShiftReportRepository) GetOpenOrders(executor repositories.SQLExecutor, branchCode string, statuses []string) ([]models.HandoverOrder, error) {
+	if m.GetOpenOrdersFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetOpenOrders called but GetOpenOrdersFunc is not set")
+	}
+	return m.GetOpenOrdersFunc(executor, branchCode, statuses)
+}
+
+func (m *MockShiftReportRepository) FindOverlappingShift(executor repositories.SQLExecutor, staffID int64, from, to time.Time) (*int64, error) {
+	if m.FindOverlappingShiftFunc == nil {
+		panic("mocks: MockShiftReportRepository.FindOverlappingShift called but FindOverlappingShiftFunc is not set")
+	}
+	return m.FindOverlappingShiftFunc(executor, staffID, from, to)
+}
+
+func (m *MockShiftReportRepository) CreateReport(executor repositories.SQLExecutor, report *models.ShiftReport) error {
+	if m.CreateReportFunc == nil {
+		panic("mocks: MockShiftReportRepository.CreateReport called but CreateReportFunc is not set")
+	}
+	return m.CreateReportFunc(executor, report)
+}
+
+func (m *MockShiftReportRepository) GetReportByID(id int64) (*models.ShiftReport, error) {
+	if m.GetReportByIDFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetReportByID called but GetReportByIDFunc is not set")
+	}
+	return m.GetReportByIDFunc(id)
+}
+
+func (m *MockShiftReportRepository) GetLatestReportForShift(shiftID int64) (*models.ShiftReport, error) {
+	if m.GetLatestReportForShiftFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetLatestReportForShift called but GetLatestReportForShiftFunc is not set")
+	}
+	return m.GetLatestReportForShiftFunc(shiftID)
+}
+
+func (m *MockShiftReportRepository) GetReports(branchCode string, filters models.ShiftReportFilters) ([]models.ShiftReport, error) {
+	if m.GetReportsFunc == nil {
+		panic("mocks: MockShiftReportRepository.GetReports called but GetReportsFunc is not set")
+	}
+	return m.GetReportsFunc(branchCode, filters)
+}
+
+func (m *MockShiftReportRepository) MarkReportEmailed(id int64, emailedAt time.Time) error {
+	if m.MarkReportEmailedFunc == nil {
+		panic("mocks: MockShiftReportRepository.MarkReportEmailed called but MarkReportEmailedFunc is not set")
+	}
+	return m.MarkReportEmailedFunc(id, emailedAt)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockStaffDocumentRepository is a hand-written mock of repositories.StaffDocumentRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockStaffDocumentRepository struct {
+	AddDocumentFunc          func(*models.StaffDocument, *models.Photo) error
+	GetDocumentsFunc         func(int64) ([]models.StaffDocument, error)
+	GetDocumentPhotoFunc     func(int64, int64) (*models.Photo, error)
+	DeleteDocumentFunc       func(int64, int64) error
+	GetExpiringDocumentsFunc func(string) ([]models.StaffDocument, error)
+	MarkExpiryNotifiedFunc   func(repositories.SQLExecutor, string, time.Time) ([]models.StaffDocument, error)
+}
+
+var _ repositories.StaffDocumentRepository = (*MockStaffDocumentRepository)(nil)
+
+func (m *MockStaffDocumentRepository) AddDocument(document *models.StaffDocument, photo *models.Photo) error {
+	if m.AddDocumentFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.AddDocument called but AddDocumentFunc is not set")
+	}
+	return m.AddDocumentFunc(document, photo)
+}
+
+func (m *MockStaffDocumentRepository) GetDocuments(staffID int64) ([]models.StaffDocument, error) {
+	if m.GetDocumentsFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.GetDocuments called but GetDocumentsFunc is not set")
+	}
+	return m.GetDocumentsFunc(staffID)
+}
+
+func (m *MockStaffDocumentRepository) GetDocumentPhoto(staffID, documentID int64) (*models.Photo, error) {
+	if m.GetDocumentPhotoFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.GetDocumentPhoto called but GetDocumentPhotoFunc is not set")
+	}
+	return m.GetDocumentPhotoFunc(staffID, documentID)
+}
+
+func (m *MockStaffDocumentRepository) DeleteDocument(staffID, documentID int64) error {
+	if m.DeleteDocumentFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.DeleteDocument called but DeleteDocumentFunc is not set")
+	}
+	return m.DeleteDocumentFunc(staffID, documentID)
+}
+
+func (m *MockStaffDocumentRepository) GetExpiringDocuments(expiresOnOrBefore string) ([]models.StaffDocument, error) {
+	if m.GetExpiringDocumentsFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.GetExpiringDocuments called but GetExpiringDocumentsFunc is not set")
+	}
+	return m.GetExpiringDocumentsFunc(expiresOnOrBefore)
+}
+
+func (m *MockStaffDocumentRepository) MarkExpiryNotified(executor repositories.SQLExecutor, expiresOnOrBefore string, notifiedAt time.Time) ([]models.StaffDocument, error) {
+	if m.MarkExpiryNotifiedFunc == nil {
+		panic("mocks: MockStaffDocumentRepository.MarkExpiryNotified called but MarkExpiryNotifiedFunc is not set")
+	}
+	return m.MarkExpiryNotifiedFunc(executor, expiresOnOrBefore, notifiedAt)
+}
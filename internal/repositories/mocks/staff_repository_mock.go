@@ -0,0 +1,168 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockStaffRepository is a hand-written mock of repositories.StaffRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockStaffRepository struct {
+	CreateStaffMemberFunc        func(repositories.SQLExecutor, *models.StaffMember) (*models.StaffMember, error)
+	GetStaffMemberByIDFunc       func(int64) (*models.StaffMember, error)
+	GetStaffMemberByUserIDFunc   func(int64) (*models.StaffMember, error)
+	GetStaffMembersFunc          func(int, int, *string) ([]models.StaffMember, int, error)
+	UpdateStaffMemberFunc        func(repositories.SQLExecutor, *models.StaffMember) (*models.StaffMember, error)
+	DeleteStaffMemberFunc        func(repositories.SQLExecutor, int64) error
+	CreateShiftFunc              func(repositories.SQLExecutor, *models.Shift) (*models.Shift, error)
+	GetShiftByIDFunc             func(int64) (*models.Shift, error)
+	GetShiftsFunc                func(*int64, *time.Time, *time.Time, int, int) ([]models.Shift, int, error)
+	UpdateShiftFunc              func(repositories.SQLExecutor, *models.Shift) (*models.Shift, error)
+	DeleteShiftFunc              func(repositories.SQLExecutor, int64) error
+	HasApprovedTimeOffFunc       func(int64, string, string) (bool, error)
+	CreateTimeClockEntryFunc     func(repositories.SQLExecutor, *models.TimeClockEntry) (*models.TimeClockEntry, error)
+	GetOpenTimeClockEntryFunc    func(int64) (*models.TimeClockEntry, error)
+	CloseTimeClockEntryFunc      func(repositories.SQLExecutor, int64, time.Time) (*models.TimeClockEntry, error)
+	CreateClockInViolationFunc   func(*models.ClockInViolation) error
+	GetClockInViolationByIDFunc  func(int64) (*models.ClockInViolation, error)
+	GetClockInViolationsFunc     func(models.ClockInViolationFilters) ([]models.ClockInViolation, error)
+	OverrideClockInViolationFunc func(repositories.SQLExecutor, int64, int64, time.Time, int64) error
+}
+
+var _ repositories.StaffRepository = (*MockStaffRepository)(nil)
+
+func (m *MockStaffRepository) CreateStaffMember(executor repositories.SQLExecutor, staff *models.StaffMember) (*models.StaffMember, error) {
+	if m.CreateStaffMemberFunc == nil {
+		panic("mocks: MockStaffRepository.CreateStaffMember called but CreateStaffMemberFunc is not set")
+	}
+	return m.CreateStaffMemberFunc(executor, staff)
+}
+
+func (m *MockStaffRepository) GetStaffMemberByID(id int64) (*models.StaffMember, error) {
+	if m.GetStaffMemberByIDFunc == nil {
+		panic("mocks: MockStaffRepository.GetStaffMemberByID called but GetStaffMemberByIDFunc is not set")
+	}
+	return m.GetStaffMemberByIDFunc(id)
+}
+
+func (m *MockStaffRepository) GetStaffMemberByUserID(userID int64) (*models.StaffMember, error) {
+	if m.GetStaffMemberByUserIDFunc == nil {
+		panic("mocks: MockStaffRepository.GetStaffMemberByUserID called but GetStaffMemberByUserIDFunc is not set")
+	}
+	return m.GetStaffMemberByUserIDFunc(userID)
+}
+
+func (m *MockStaffRepository) GetStaffMembers(page int, pageSize int, searchTerm *string) ([]models.StaffMember, int, error) {
+	if m.GetStaffMembersFunc == nil {
+		panic("mocks: MockStaffRepository.GetStaffMembers called but GetStaffMembersFunc is not set")
+	}
+	return m.GetStaffMembersFunc(page, pageSize, searchTerm)
+}
+
+func (m *MockStaffRepository) UpdateStaffMember(executor repositories.SQLExecutor, staff *models.StaffMember) (*models.StaffMember, error) {
+	if m.UpdateStaffMemberFunc == nil {
+		panic("mocks: MockStaffRepository.UpdateStaffMember called but UpdateStaffMemberFunc is not set")
+	}
+	return m.UpdateStaffMemberFunc(executor, staff)
+}
+
+func (m *MockStaffRepository) DeleteStaffMember(executor repositories.SQLExecutor, id int64) error {
+	if m.DeleteStaffMemberFunc == nil {
+		panic("mocks: MockStaffRepository.DeleteStaffMember called but DeleteStaffMemberFunc is not set")
+	}
+	return m.DeleteStaffMemberFunc(executor, id)
+}
+
+func (m *MockStaffRepository) CreateShift(executor repositories.SQLExecutor, shift *models.Shift) (*models.Shift, error) {
+	if m.CreateShiftFunc == nil {
+		panic("mocks: MockStaffRepository.CreateShift called but CreateShiftFunc is not set")
+	}
+	return m.CreateShiftFunc(executor, shift)
+}
+
+func (m *MockStaffRepository) GetShiftByID(id int64) (*models.Shift, error) {
+	if m.GetShiftByIDFunc == nil {
+		panic("mocks: MockStaffRepository.GetShiftByID called but GetShiftByIDFunc is not set")
+	}
+	return m.GetShiftByIDFunc(id)
+}
+
+func (m *MockStaffRepository) GetShifts(staffID *int64, startTimeFrom *time.Time, startTimeTo *time.Time, page int, pageSize int) ([]models.Shift, int, error) {
+	if m.GetShiftsFunc == nil {
+		panic("mocks: MockStaffRepository.GetShifts called but GetShiftsFunc is not set")
+	}
+	return m.GetShiftsFunc(staffID, startTimeFrom, startTimeTo, page, pageSize)
+}
+
+func (m *MockStaffRepository) UpdateShift(executor repositories.SQLExecutor, shift *models.Shift) (*models.Shift, error) {
+	if m.UpdateShiftFunc == nil {
+		panic("mocks: MockStaffRepository.UpdateShift called but UpdateShiftFunc is not set")
+	}
+	return m.UpdateShiftFunc(executor, shift)
+}
+
+func (m *MockStaffRepository) DeleteShift(executor repositories.SQLExecutor, id int64) error {
+	if m.DeleteShiftFunc == nil {
+		panic("mocks: MockStaffRepository.DeleteShift called but DeleteShiftFunc is not set")
+	}
+	return m.DeleteShiftFunc(executor, id)
+}
+
+func (m *MockStaffRepository) HasApprovedTimeOff(staffID int64, fromDate, toDate string) (bool, error) {
+	if m.HasApprovedTimeOffFunc == nil {
+		panic("mocks: MockStaffRepository.HasApprovedTimeOff called but HasApprovedTimeOffFunc is not set")
+	}
+	return m.HasApprovedTimeOffFunc(staffID, fromDate, toDate)
+}
+
+func (m *MockStaffRepository) CreateTimeClockEntry(executor repositories.SQLExecutor, entry *models.TimeClockEntry) (*models.TimeClockEntry, error) {
+	if m.CreateTimeClockEntryFunc == nil {
+		panic("mocks: MockStaffRepository.CreateTimeClockEntry called but CreateTimeClockEntryFunc is not set")
+	}
+	return m.CreateTimeClockEntryFunc(executor, entry)
+}
+
+func (m *MockStaffRepository) GetOpenTimeClockEntry(staffID int64) (*models.TimeClockEntry, error) {
+	if m.GetOpenTimeClockEntryFunc == nil {
+		panic("mocks: MockStaffRepository.GetOpenTimeClockEntry called but GetOpenTimeClockEntryFunc is not set")
+	}
+	return m.GetOpenTimeClockEntryFunc(staffID)
+}
+
+func (m *MockStaffRepository) CloseTimeClockEntry(executor repositories.SQLExecutor, entryID int64, clockOutAt time.Time) (*models.TimeClockEntry, error) {
+	if m.CloseTimeClockEntryFunc == nil {
+		panic("mocks: MockStaffRepository.CloseTimeClockEntry called but CloseTimeClockEntryFunc is not set")
+	}
+	return m.CloseTimeClockEntryFunc(executor, entryID, clockOutAt)
+}
+
+func (m *MockStaffRepository) CreateClockInViolation(violation *models.ClockInViolation) error {
+	if m.CreateClockInViolationFunc == nil {
+		panic("mocks: MockStaffRepository.CreateClockInViolation called but CreateClockInViolationFunc is not set")
+	}
+	return m.CreateClockInViolationFunc(violation)
+}
+
+func (m *MockStaffRepository) GetClockInViolationByID(id int64) (*models.ClockInViolation, error) {
+	if m.GetClockInViolationByIDFunc == nil {
+		panic("mocks: MockStaffRepository.GetClockInViolationByID called but GetClockInViolationByIDFunc is not set")
+	}
+	return m.GetClockInViolationByIDFunc(id)
+}
+
+func (m *MockStaffRepository) GetClockInViolations(filters models.ClockInViolationFilters) ([]models.ClockInViolation, error) {
+	if m.GetClockInViolationsFunc == nil {
+		panic("mocks: MockStaffRepository.GetClockInViolations called but GetClockInViolationsFunc is not set")
+	}
+	return m.GetClockInViolationsFunc(filters)
+}
+
+func (m *MockStaffRepository) OverrideClockInViolation(executor repositories.SQLExecutor, id, overriddenBy int64, overriddenAt time.Time, entryID int64) error {
+	if m.OverrideClockInViolationFunc == nil {
+		panic("mocks: MockStaffRepository.OverrideClockInViolation called but OverrideClockInViolationFunc is not set")
+	}
+	return m.OverrideClockInViolationFunc(executor, id, overriddenBy, overriddenAt, entryID)
+}
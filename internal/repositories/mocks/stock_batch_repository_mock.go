@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockStockBatchRepository is a hand-written mock of repositories.StockBatchRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockStockBatchRepository struct {
+	CreateBatchFunc            func(repositories.SQLExecutor, *models.StockBatch) error
+	GetBatchByIDFunc           func(int64) (*models.StockBatch, error)
+	GetBatchesFunc             func(models.StockBatchFilters) ([]models.StockBatch, error)
+	DeductFEFOFunc             func(repositories.SQLExecutor, int64, models.Quantity, int64, *int64) error
+	DeductBatchFunc            func(repositories.SQLExecutor, int64, models.Quantity, int64) error
+	RestoreOrderDeductionsFunc func(repositories.SQLExecutor, int64, int64) error
+	WriteOffBatchFunc          func(repositories.SQLExecutor, int64) (models.Quantity, error)
+	SetWriteOffMovementFunc    func(repositories.SQLExecutor, int64, int64) error
+}
+
+var _ repositories.StockBatchRepository = (*MockStockBatchRepository)(nil)
+
+func (m *MockStockBatchRepository) CreateBatch(executor repositories.SQLExecutor, batch *models.StockBatch) error {
+	if m.CreateBatchFunc == nil {
+		panic("mocks: MockStockBatchRepository.CreateBatch called but CreateBatchFunc is not set")
+	}
+	return m.CreateBatchFunc(executor, batch)
+}
+
+func (m *MockStockBatchRepository) GetBatchByID(id int64) (*models.StockBatch, error) {
+	if m.GetBatchByIDFunc == nil {
+		panic("mocks: MockStockBatchRepository.GetBatchByID called but GetBatchByIDFunc is not set")
+	}
+	return m.GetBatchByIDFunc(id)
+}
+
+func (m *MockStockBatchRepository) GetBatches(filters models.StockBatchFilters) ([]models.StockBatch, error) {
+	if m.GetBatchesFunc == nil {
+		panic("mocks: MockStockBatchRepository.GetBatches called but GetBatchesFunc is not set")
+	}
+	return m.GetBatchesFunc(filters)
+}
+
+func (m *MockStockBatchRepository) DeductFEFO(executor repositories.SQLExecutor, itemID int64, quantity models.Quantity, movementID int64, orderID *int64) error {
+	if m.DeductFEFOFunc == nil {
+		panic("mocks: MockStockBatchRepository.DeductFEFO called but DeductFEFOFunc is not set")
+	}
+	return m.DeductFEFOFunc(executor, itemID, quantity, movementID, orderID)
+}
+
+func (m *MockStockBatchRepository) DeductBatch(executor repositories.SQLExecutor, batchID int64, quantity models.Quantity, movementID int64) error {
+	if m.DeductBatchFunc == nil {
+		panic("mocks: MockStockBatchRepository.DeductBatch called but DeductBatchFunc is not set")
+	}
+	return m.DeductBatchFunc(executor, batchID, quantity, movementID)
+}
+
+func (m *MockStockBatchRepository) RestoreOrderDeductions(executor repositories.SQLExecutor, orderID, itemID int64) error {
+	if m.RestoreOrderDeductionsFunc == nil {
+		panic("mocks: MockStockBatchRepository.RestoreOrderDeductions called but RestoreOrderDeductionsFunc is not set")
+	}
+	return m.RestoreOrderDeductionsFunc(executor, orderID, itemID)
+}
+
+func (m *MockStockBatchRepository) WriteOffBatch(executor repositories.SQLExecutor, batchID int64) (models.Quantity, error) {
+	if m.WriteOffBatchFunc == nil {
+		panic("mocks: MockStockBatchRepository.WriteOffBatch called but WriteOffBatchFunc is not set")
+	}
+	return m.WriteOffBatchFunc(executor, batchID)
+}
+
+func (m *MockStockBatchRepository) SetWriteOffMovement(executor repositories.SQLExecutor, batchID int64, movementID int64) error {
+	if m.SetWriteOffMovementFunc == nil {
+		panic("mocks: MockStockBatchRepository.SetWriteOffMovement called but SetWriteOffMovementFunc is not set")
+	}
+	return m.SetWriteOffMovementFunc(executor, batchID, movementID)
+}
@@ -0,0 +1,104 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSupplierRepository is a hand-written mock of repositories.SupplierRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSupplierRepository struct {
+	CreateSupplierFunc         func(*models.Supplier) error
+	GetSupplierByIDFunc        func(int64) (*models.Supplier, error)
+	GetSuppliersFunc           func() ([]models.Supplier, error)
+	UpdateSupplierFunc         func(*models.Supplier) error
+	DeleteSupplierFunc         func(int64) error
+	GetSupplierPricesFunc      func(int64) ([]models.SupplierPrice, error)
+	UpsertSupplierPriceFunc    func(repositories.SQLExecutor, *models.SupplierPrice) error
+	GetItemIDsFunc             func([]int64) (map[int64]bool, error)
+	GetItemIDsBySKUFunc        func([]string) (map[string]int64, error)
+	GetOffersFunc              func([]int64) (map[int64][]models.SupplierOffer, error)
+	GetLowStockConsumptionFunc func(time.Time, []string) ([]models.StockConsumption, error)
+}
+
+var _ repositories.SupplierRepository = (*MockSupplierRepository)(nil)
+
+func (m *MockSupplierRepository) CreateSupplier(supplier *models.Supplier) error {
+	if m.CreateSupplierFunc == nil {
+		panic("mocks: MockSupplierRepository.CreateSupplier called but CreateSupplierFunc is not set")
+	}
+	return m.CreateSupplierFunc(supplier)
+}
+
+func (m *MockSupplierRepository) GetSupplierByID(id int64) (*models.Supplier, error) {
+	if m.GetSupplierByIDFunc == nil {
+		panic("mocks: MockSupplierRepository.GetSupplierByID called but GetSupplierByIDFunc is not set")
+	}
+	return m.GetSupplierByIDFunc(id)
+}
+
+func (m *MockSupplierRepository) GetSuppliers() ([]models.Supplier, error) {
+	if m.GetSuppliersFunc == nil {
+		panic("mocks: MockSupplierRepository.GetSuppliers called but GetSuppliersFunc is not set")
+	}
+	return m.GetSuppliersFunc()
+}
+
+func (m *MockSupplierRepository) UpdateSupplier(supplier *models.Supplier) error {
+	if m.UpdateSupplierFunc == nil {
+		panic("mocks: MockSupplierRepository.UpdateSupplier called but UpdateSupplierFunc is not set")
+	}
+	return m.UpdateSupplierFunc(supplier)
+}
+
+func (m *MockSupplierRepository) DeleteSupplier(id int64) error {
+	if m.DeleteSupplierFunc == nil {
+		panic("mocks: MockSupplierRepository.DeleteSupplier called but DeleteSupplierFunc is not set")
+	}
+	return m.DeleteSupplierFunc(id)
+}
+
+func (m *MockSupplierRepository) GetSupplierPrices(supplierID int64) ([]models.SupplierPrice, error) {
+	if m.GetSupplierPricesFunc == nil {
+		panic("mocks: MockSupplierRepository.GetSupplierPrices called but GetSupplierPricesFunc is not set")
+	}
+	return m.GetSupplierPricesFunc(supplierID)
+}
+
+func (m *MockSupplierRepository) UpsertSupplierPrice(executor repositories.SQLExecutor, price *models.SupplierPrice) error {
+	if m.UpsertSupplierPriceFunc == nil {
+		panic("mocks: MockSupplierRepository.UpsertSupplierPrice called but UpsertSupplierPriceFunc is not set")
+	}
+	return m.UpsertSupplierPriceFunc(executor, price)
+}
+
+func (m *MockSupplierRepository) GetItemIDs(ids []int64) (map[int64]bool, error) {
+	if m.GetItemIDsFunc == nil {
+		panic("mocks: MockSupplierRepository.GetItemIDs called but GetItemIDsFunc is not set")
+	}
+	return m.GetItemIDsFunc(ids)
+}
+
+func (m *MockSupplierRepository) GetItemIDsBySKU(skus []string) (map[string]int64, error) {
+	if m.GetItemIDsBySKUFunc == nil {
+		panic("mocks: MockSupplierRepository.GetItemIDsBySKU called but GetItemIDsBySKUFunc is not set")
+	}
+	return m.GetItemIDsBySKUFunc(skus)
+}
+
+func (m *MockSupplierRepository) GetOffers(itemIDs []int64) (map[int64][]models.SupplierOffer, error) {
+	if m.GetOffersFunc == nil {
+		panic("mocks: MockSupplierRepository.GetOffers called but GetOffersFunc is not set")
+	}
+	return m.GetOffersFunc(itemIDs)
+}
+
+func (m *MockSupplierRepository) GetLowStockConsumption(since time.Time, consumptionTypes []string) ([]models.StockConsumption, error) {
+	if m.GetLowStockConsumptionFunc == nil {
+		panic("mocks: MockSupplierRepository.GetLowStockConsumption called but GetLowStockConsumptionFunc is not set")
+	}
+	return m.GetLowStockConsumptionFunc(since, consumptionTypes)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockSyncRepository is a hand-written mock of repositories.SyncRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockSyncRepository struct {
+	GetChangesFunc   func(string, int64, int) ([]models.SyncChange, error)
+	GetDeletionsFunc func(int64, int) ([]models.SyncChange, error)
+}
+
+var _ repositories.SyncRepository = (*MockSyncRepository)(nil)
+
+func (m *MockSyncRepository) GetChanges(entity string, since int64, limit int) ([]models.SyncChange, error) {
+	if m.GetChangesFunc == nil {
+		panic("mocks: MockSyncRepository.GetChanges called but GetChangesFunc is not set")
+	}
+	return m.GetChangesFunc(entity, since, limit)
+}
+
+func (m *MockSyncRepository) GetDeletions(since int64, limit int) ([]models.SyncChange, error) {
+	if m.GetDeletionsFunc == nil {
+		panic("mocks: MockSyncRepository.GetDeletions called but GetDeletionsFunc is not set")
+	}
+	return m.GetDeletionsFunc(since, limit)
+}
@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockTableSessionRepository is a hand-written mock of repositories.TableSessionRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockTableSessionRepository struct {
+	CreateTableSessionFunc      func(repositories.SQLExecutor, *models.TableSession) (*models.TableSession, error)
+	GetTableSessionByIDFunc     func(int64) (*models.TableSession, error)
+	GetRunningTableSessionsFunc func() ([]models.TableSession, error)
+	GetTimedTableSessionsFunc   func(time.Time) ([]models.TableSession, error)
+	MarkTableSessionWarnedFunc  func(repositories.SQLExecutor, int64, int, time.Time) (bool, error)
+	SetTableSessionOvertimeFunc func(repositories.SQLExecutor, int64, time.Time) (bool, error)
+	StopTableSessionFunc        func(repositories.SQLExecutor, *models.TableSession, string) error
+}
+
+var _ repositories.TableSessionRepository = (*MockTableSessionRepository)(nil)
+
+func (m *MockTableSessionRepository) CreateTableSession(executor repositories.SQLExecutor, session *models.TableSession) (*models.TableSession, error) {
+	if m.CreateTableSessionFunc == nil {
+		panic("mocks: MockTableSessionRepository.CreateTableSession called but CreateTableSessionFunc is not set")
+	}
+	return m.CreateTableSessionFunc(executor, session)
+}
+
+func (m *MockTableSessionRepository) GetTableSessionByID(id int64) (*models.TableSession, error) {
+	if m.GetTableSessionByIDFunc == nil {
+		panic("mocks: MockTableSessionRepository.GetTableSessionByID called but GetTableSessionByIDFunc is not set")
+	}
+	return m.GetTableSessionByIDFunc(id)
+}
+
+func (m *MockTableSessionRepository) GetRunningTableSessions() ([]models.TableSession, error) {
+	if m.GetRunningTableSessionsFunc == nil {
+		panic("mocks: MockTableSessionRepository.GetRunningTableSessions called but GetRunningTableSessionsFunc is not set")
+	}
+	return m.GetRunningTableSessionsFunc()
+}
+
+func (m *MockTableSessionRepository) GetTimedTableSessions(until time.Time) ([]models.TableSession, error) {
+	if m.GetTimedTableSessionsFunc == nil {
+		panic("mocks: MockTableSessionRepository.GetTimedTableSessions called but GetTimedTableSessionsFunc is not set")
+	}
+	return m.GetTimedTableSessionsFunc(until)
+}
+
+func (m *MockTableSessionRepository) MarkTableSessionWarned(executor repositories.SQLExecutor, id int64, minutes int, now time.Time) (bool, error) {
+	if m.MarkTableSessionWarnedFunc == nil {
+		panic("mocks: MockTableSessionRepository.MarkTableSessionWarned called but MarkTableSessionWarnedFunc is not set")
+	}
+	return m.MarkTableSessionWarnedFunc(executor, id, minutes, now)
+}
+
+func (m *MockTableSessionRepository) SetTableSessionOvertime(executor repositories.SQLExecutor, id int64, now time.Time) (bool, error) {
+	if m.SetTableSessionOvertimeFunc == nil {
+		panic("mocks: MockTableSessionRepository.SetTableSessionOvertime called but SetTableSessionOvertimeFunc is not set")
+	}
+	return m.SetTableSessionOvertimeFunc(executor, id, now)
+}
+
+func (m *MockTableSessionRepository) StopTableSession(executor repositories.SQLExecutor, session *models.TableSession, fromStatus string) error {
+	if m.StopTableSessionFunc == nil {
+		panic("mocks: MockTableSessionRepository.StopTableSession called but StopTableSessionFunc is not set")
+	}
+	return m.StopTableSessionFunc(executor, session, fromStatus)
+}
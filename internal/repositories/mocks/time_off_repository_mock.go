@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// MockTimeOffRepository is a hand-written mock of repositories.TimeOffRepository. Set the Func field of each
+// method a test exercises; calling a method whose Func is nil panics so missing
+// expectations fail loudly.
+type MockTimeOffRepository struct {
+	CreateRequestFunc       func(*models.TimeOffRequest) error
+	GetRequestByIDFunc      func(int64) (*models.TimeOffRequest, error)
+	GetRequestsFunc         func(models.TimeOffFilters) ([]models.TimeOffRequest, error)
+	UpdateStatusFunc        func(int64, []string, string, *int64, *string, time.Time) error
+	GetShiftsBetweenFunc    func(int64, time.Time, time.Time) ([]models.Shift, error)
+	GetAvailabilityFunc     func(int64) ([]models.AvailabilityWindow, error)
+	ReplaceAvailabilityFunc func(repositories.SQLExecutor, int64, []models.AvailabilityWindow) error
+	GetDayAvailabilityFunc  func(string, int) ([]models.StaffDayAvailability, error)
+}
+
+var _ repositories.TimeOffRepository = (*MockTimeOffRepository)(nil)
+
+func (m *MockTimeOffRepository) CreateRequest(request *models.TimeOffRequest) error {
+	if m.CreateRequestFunc == nil {
+		panic("mocks: MockTimeOffRepository.CreateRequest called but CreateRequestFunc is not set")
+	}
+	return m.CreateRequestFunc(request)
+}
+
+func (m *MockTimeOffRepository) GetRequestByID(id int64) (*models.TimeOffRequest, error) {
+	if m.GetRequestByIDFunc == nil {
+		panic("mocks: MockTimeOffRepository.GetRequestByID called but GetRequestByIDFunc is not set")
+	}
+	return m.GetRequestByIDFunc(id)
+}
+
+func (m *MockTimeOffRepository) GetRequests(filters models.TimeOffFilters) ([]models.TimeOffRequest, error) {
+	if m.GetRequestsFunc == nil {
+		panic("mocks: MockTimeOffRepository.GetRequests called but GetRequestsFunc is not set")
+	}
+	return m.GetRequestsFunc(filters)
+}
+
+func (m *MockTimeOffRepository) UpdateStatus(id int64, fromStatuses []string, status string, decidedBy *int64, note *string, at time.Time) error {
+	if m.UpdateStatusFunc == nil {
+		panic("mocks: MockTimeOffRepository.UpdateStatus called but UpdateStatusFunc is not set")
+	}
+	return m.UpdateStatusFunc(id, fromStatuses, status, decidedBy, note, at)
+}
+
+func (m *MockTimeOffRepository) GetShiftsBetween(staffID int64, from, to time.Time) ([]models.Shift, error) {
+	if m.GetShiftsBetweenFunc == nil {
+		panic("mocks: MockTimeOffRepository.GetShiftsBetween called but GetShiftsBetweenFunc is not set")
+	}
+	return m.GetShiftsBetweenFunc(staffID, from, to)
+}
+
+func (m *MockTimeOffRepository) GetAvailability(staffID int64) ([]models.AvailabilityWindow, error) {
+	if m.GetAvailabilityFunc == nil {
+		panic("mocks: MockTimeOffRepository.GetAvailability called but GetAvailabilityFunc is not set")
+	}
+	return m.GetAvailabilityFunc(staffID)
+}
+
+func (m *MockTimeOffRepository) ReplaceAvailability(executor repositories.SQLExecutor, staffID int64, windows []models.AvailabilityWindow) error {
+	if m.ReplaceAvailabilityFunc == nil {
+		panic("mocks: MockTimeOffRepository.ReplaceAvailability called but ReplaceAvailabilityFunc is not set")
+	}
+	return m.ReplaceAvailabilityFunc(executor, staffID, windows)
+}
+
+func (m *MockTimeOffRepository) GetDayAvailability(date string, weekday int) ([]models.StaffDayAvailability, error) {
+	if m.GetDayAvailabilityFunc == nil {
+		panic("mocks: MockTimeOffRepository.GetDayAvailability called but GetDayAvailabilityFunc is not set")
+	}
+	return m.GetDayAvailabilityFunc(date, weekday)
+}
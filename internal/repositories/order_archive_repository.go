@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// OrderArchiveRepository defines the moving of old orders to orders_archive and
+// order_items_archive, and their reading.
+type OrderArchiveRepository interface {
+	// ArchiveOrders moves up to limit orders in status with a business date before the given
+	// one (YYYY-MM-DD), oldest first, with their items to the archive, and returns how many
+	// were moved. Call it in a transaction.
+	ArchiveOrders(executor SQLExecutor, status, before string, limit int, archivedAt time.Time) (int, error)
+	// GetArchivedOrders returns a page of the archived orders matching filters, newest first,
+	// with their total count. The cursor of filters is not supported.
+	GetArchivedOrders(filters models.OrderFilters) ([]models.ArchivedOrder, int, error)
+	GetArchivedOrderByID(orderID int64) (*models.ArchivedOrder, error) // ErrNotFound if the order is not archived
+	GetArchivedOrderItems(orderID int64) ([]models.OrderItem, error)
+}
+
+type orderArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewOrderArchiveRepository creates a new instance of OrderArchiveRepository.
+func NewOrderArchiveRepository(db *sql.DB) OrderArchiveRepository {
+	return &orderArchiveRepository{db: db}
+}
+
+func (r *orderArchiveRepository) ArchiveOrders(executor SQLExecutor, status, before string, limit int, archivedAt time.Time) (int, error) {
+	// Skips the trigger clearing the references to deleted orders until the transaction ends
+	if _, err := executor.Exec(`SELECT set_config('ps_club.archiving', 'on', true)`); err != nil {
+		return 0, fmt.Errorf("%w: starting archival: %v", ErrDatabaseError, err)
+	}
+	query := `
+		WITH batch AS (
+		    SELECT id FROM orders
+		    WHERE status = $1 AND business_date < $2
+		    ORDER BY business_date, id
+		    LIMIT $3
+		    FOR UPDATE
+		), moved_items AS (
+		    DELETE FROM order_items WHERE order_id IN (SELECT id FROM batch) RETURNING *
+		), archived_items AS (
+		    INSERT INTO order_items_archive SELECT * FROM moved_items
+		), moved AS (
+		    DELETE FROM orders WHERE id IN (SELECT id FROM batch) AND business_date < $2 RETURNING *
+		), archived AS (
+		    INSERT INTO orders_archive SELECT $4, moved.* FROM moved RETURNING id
+		)
+		SELECT COUNT(*) FROM archived`
+	var count int
+	if err := executor.QueryRow(query, status, before, limit, archivedAt).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%w: archiving orders before %s: %v", ErrDatabaseError, before, err)
+	}
+	return count, nil
+}
+
+func (r *orderArchiveRepository) GetArchivedOrders(filters models.OrderFilters) ([]models.ArchivedOrder, int, error) {
+	filters.Cursor = nil
+	conditions, args := orderConditions(filters)
+	argCounter := len(args) + 1
+
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString(`SELECT ` + orderColumns + `, archived_at, ` + totalCountColumn(true) + `
+		FROM orders_archive o`)
+	if len(conditions) > 0 {
+		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	queryBuilder.WriteString(" ORDER BY o.order_time DESC, o.id DESC")
+	if filters.PageSize > 0 {
+		queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCounter))
+		args = append(args, filters.PageSize)
+		argCounter++
+		if filters.Page > 0 {
+			queryBuilder.WriteString(fmt.Sprintf(" OFFSET $%d", argCounter))
+			args = append(args, (filters.Page-1)*filters.PageSize)
+		}
+	}
+
+	rows, err := r.db.Query(queryBuilder.String(), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: querying archived orders: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	orders := []models.ArchivedOrder{}
+	totalCount := 0
+	for rows.Next() {
+		var order models.ArchivedOrder
+		if err := scanOrder(rows, &order.Order, &order.ArchivedAt, &totalCount); err != nil {
+			return nil, 0, fmt.Errorf("%w: scanning archived order: %v", ErrDatabaseError, err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: iterating archived order rows: %v", ErrDatabaseError, err)
+	}
+	return orders, totalCount, nil
+}
+
+func (r *orderArchiveRepository) GetArchivedOrderByID(orderID int64) (*models.ArchivedOrder, error) {
+	order := &models.ArchivedOrder{}
+	query := `SELECT ` + orderColumns + `, archived_at
+	          FROM orders_archive
+	          WHERE id = $1`
+	err := scanOrder(r.db.QueryRow(query, orderID), &order.Order, &order.ArchivedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting archived order by ID %d: %v", ErrDatabaseError, orderID, err)
+	}
+	return order, nil
+}
+
+func (r *orderArchiveRepository) GetArchivedOrderItems(orderID int64) ([]models.OrderItem, error) {
+	items := []models.OrderItem{}
+	err := queryOrderItemsFrom(r.db, "order_items_archive", "WHERE oi.order_id = $1 ORDER BY oi.id", []interface{}{orderID}, func(item *models.OrderItem) error {
+		items = append(items, *item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
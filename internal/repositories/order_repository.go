@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
 	"strings"
 	"time"
 
@@ -16,14 +17,33 @@ type OrderRepository interface {
 	// Order methods
 	CreateOrder(executor SQLExecutor, order *models.Order) (int64, error)
 	GetOrderByID(orderID int64) (*models.Order, error) // Basic order details
+	GetOrderByNumber(branchCode, businessDate string, dailyNumber int) (*models.Order, error)
+	GetOrderByUUID(uuid string) (*models.Order, error) // ErrNotFound if no order has the UUID
+	NextDailyOrderNumber(executor SQLExecutor, branchCode, businessDate string) (int, error) // Call in the transaction that creates the order
 	GetOrders(filters models.OrderFilters) ([]models.Order, int, error) // orders, total count, error
-	UpdateOrderStatus(executor SQLExecutor, orderID int64, newStatus string, updatedAt time.Time) error
+	// StreamOrders calls fn with each order matching filters (without paging), newest first, as
+	// rows are read, so exports never hold all orders in memory. An error from fn stops the
+	// stream and is returned as is.
+	StreamOrders(filters models.OrderFilters, fn func(*models.Order) error) error
+	UpdateOrderStatus(executor SQLExecutor, orderID int64, newStatus string, expectedVersion int, updatedAt time.Time) error // ErrVersionConflict if the order's version is not expectedVersion
 	DeleteOrder(executor SQLExecutor, orderID int64) (int64, error) // Returns rows affected or error
+	// UpdateOrderAmounts stores the totals, discount, tax and service charge of order after an
+	// item was voided; ErrVersionConflict if the order's version is not expectedVersion.
+	UpdateOrderAmounts(executor SQLExecutor, order *models.Order, expectedVersion int, updatedAt time.Time) error
+	// CreateOrderVoid records a void, setting its ID and the time clock entry its staff
+	// member was clocked in for at VoidedAt.
+	CreateOrderVoid(executor SQLExecutor, void *models.OrderVoid) error
 
 	// OrderItem methods
 	CreateOrderItem(executor SQLExecutor, item *models.OrderItem) (int64, error)
 	GetOrderItemsByOrderID(orderID int64) ([]models.OrderItem, error)
+	// GetOrderItemsByOrderIDs returns the items of all the given orders in one query, ordered by order ID.
+	GetOrderItemsByOrderIDs(orderIDs []int64) ([]models.OrderItem, error)
+	// StreamOrderItems calls fn with each item of the orders matching filters (without paging),
+	// grouped by order ID, as rows are read. An error from fn stops the stream and is returned as is.
+	StreamOrderItems(filters models.OrderFilters, fn func(*models.OrderItem) error) error
 	DeleteOrderItemsByOrderID(executor SQLExecutor, orderID int64) (int64, error) // Returns rows affected or error
+	DeleteOrderItem(executor SQLExecutor, orderID, itemID int64) error            // ErrNotFound if the order has no such item
 }
 
 type orderRepository struct {
@@ -37,42 +57,86 @@ func NewOrderRepository(db *sql.DB) OrderRepository {
 
 // --- Order Methods ---
 
+// orderColumns lists the orders columns read by scanOrder, in order.
+const orderColumns = `id, client_id, booking_id, staff_id, table_id, order_time, status, 
+	                 total_amount, discount_amount, final_amount, tax_amount, prices_include_tax, payment_method, notes, 
+	                 created_at, updated_at, version, branch_code, business_date, daily_number,
+	                 guest_count, service_charge_rate, service_charge_amount, uuid, source`
+
+// scanOrder scans orderColumns (optionally followed by extra columns) into order.
+func scanOrder(row scanner, order *models.Order, extra ...interface{}) error {
+	var businessDate time.Time
+	dest := []interface{}{
+		&order.ID, &order.ClientID, &order.BookingID, &order.StaffID, &order.TableID, &order.OrderTime, &order.Status,
+		&order.TotalAmount, &order.DiscountAmount, &order.FinalAmount, &order.TaxAmount, &order.PricesIncludeTax,
+		&order.PaymentMethod, &order.Notes, &order.CreatedAt, &order.UpdatedAt, &order.Version, &order.BranchCode, &businessDate, &order.DailyNumber,
+		&order.GuestCount, &order.ServiceChargeRate, &order.ServiceChargeAmount, &order.UUID, &order.Source,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	order.BusinessDate = businessDate.Format(utils.DateLayout)
+	order.OrderNumber = models.FormatOrderNumber(order.BusinessDate, order.DailyNumber)
+	return nil
+}
+
 func (r *orderRepository) CreateOrder(executor SQLExecutor, order *models.Order) (int64, error) {
 	query := `INSERT INTO orders 
 	            (client_id, booking_id, staff_id, table_id, order_time, status, 
-	             total_amount, discount_amount, final_amount, payment_method, notes, 
-	             created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) 
-	          RETURNING id`
+	             total_amount, discount_amount, final_amount, tax_amount, prices_include_tax, payment_method, notes, 
+	             created_at, updated_at, branch_code, business_date, daily_number,
+	             guest_count, service_charge_rate, service_charge_amount, uuid, source)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23) 
+	          RETURNING id, version`
 	
-	if order.OrderTime.IsZero() { order.OrderTime = time.Now() }
-	if order.CreatedAt.IsZero() { order.CreatedAt = time.Now() }
-	if order.UpdatedAt.IsZero() { order.UpdatedAt = time.Now() }
+	if order.OrderTime.IsZero() { order.OrderTime = time.Now().UTC() }
+	if order.CreatedAt.IsZero() { order.CreatedAt = time.Now().UTC() }
+	if order.UpdatedAt.IsZero() { order.UpdatedAt = time.Now().UTC() }
+	if order.Source == "" { order.Source = models.OrderSourcePOS }
 
 	err := executor.QueryRow(query,
 		order.ClientID, order.BookingID, order.StaffID, order.TableID, order.OrderTime, order.Status,
-		order.TotalAmount, order.DiscountAmount, order.FinalAmount, order.PaymentMethod, order.Notes,
-		order.CreatedAt, order.UpdatedAt,
-	).Scan(&order.ID)
+		order.TotalAmount, order.DiscountAmount, order.FinalAmount, order.TaxAmount, order.PricesIncludeTax,
+		order.PaymentMethod, order.Notes, order.CreatedAt, order.UpdatedAt, order.BranchCode, order.BusinessDate, order.DailyNumber,
+		order.GuestCount, order.ServiceChargeRate, order.ServiceChargeAmount, order.UUID, order.Source,
+	).Scan(&order.ID, &order.Version)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			if pqErr.Constraint == "order_uuids_pkey" {
+				return 0, fmt.Errorf("%w: order UUID %s already exists", ErrDuplicateKey, *order.UUID)
+			}
+			return 0, fmt.Errorf("%w: order number %s already exists", ErrDuplicateKey, models.FormatOrderNumber(order.BusinessDate, order.DailyNumber))
+		}
 		return 0, fmt.Errorf("%w: creating order: %v", ErrDatabaseError, err)
 	}
+	order.OrderNumber = models.FormatOrderNumber(order.BusinessDate, order.DailyNumber)
 	return order.ID, nil
 }
 
+// NextDailyOrderNumber increments and returns the order sequence of the branch and
+// business date. The upsert locks the sequence row until the transaction ends, so
+// concurrent orders get distinct numbers without gaps from rolled back orders.
+func (r *orderRepository) NextDailyOrderNumber(executor SQLExecutor, branchCode, businessDate string) (int, error) {
+	query := `INSERT INTO order_number_sequences (branch_code, business_date, last_number)
+	          VALUES ($1, $2, 1)
+	          ON CONFLICT (branch_code, business_date)
+	          DO UPDATE SET last_number = order_number_sequences.last_number + 1
+	          RETURNING last_number`
+	var number int
+	if err := executor.QueryRow(query, branchCode, businessDate).Scan(&number); err != nil {
+		return 0, fmt.Errorf("%w: allocating order number: %v", ErrDatabaseError, err)
+	}
+	return number, nil
+}
+
 func (r *orderRepository) GetOrderByID(orderID int64) (*models.Order, error) {
 	order := &models.Order{}
-	query := `SELECT id, client_id, booking_id, staff_id, table_id, order_time, status, 
-	                 total_amount, discount_amount, final_amount, payment_method, notes, 
-	                 created_at, updated_at 
+	query := `SELECT ` + orderColumns + `
 	          FROM orders 
 	          WHERE id = $1`
-	err := r.db.QueryRow(query, orderID).Scan(
-		&order.ID, &order.ClientID, &order.BookingID, &order.StaffID, &order.TableID, &order.OrderTime, &order.Status,
-		&order.TotalAmount, &order.DiscountAmount, &order.FinalAmount, &order.PaymentMethod, &order.Notes,
-		&order.CreatedAt, &order.UpdatedAt,
-	)
+	err := scanOrder(r.db.QueryRow(query, orderID), order)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -82,27 +146,58 @@ func (r *orderRepository) GetOrderByID(orderID int64) (*models.Order, error) {
 	return order, nil
 }
 
+func (r *orderRepository) GetOrderByNumber(branchCode, businessDate string, dailyNumber int) (*models.Order, error) {
+	order := &models.Order{}
+	query := `SELECT ` + orderColumns + `
+	          FROM orders 
+	          WHERE branch_code = $1 AND business_date = $2 AND daily_number = $3`
+	err := scanOrder(r.db.QueryRow(query, branchCode, businessDate, dailyNumber), order)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting order by number %s: %v", ErrDatabaseError, models.FormatOrderNumber(businessDate, dailyNumber), err)
+	}
+	return order, nil
+}
+
+func (r *orderRepository) GetOrderByUUID(uuid string) (*models.Order, error) {
+	order := &models.Order{}
+	query := `SELECT ` + orderColumns + `
+	          FROM orders
+	          WHERE uuid = $1`
+	err := scanOrder(r.db.QueryRow(query, uuid), order)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting order by UUID %s: %v", ErrDatabaseError, uuid, err)
+	}
+	return order, nil
+}
+
 func (r *orderRepository) GetOrders(filters models.OrderFilters) ([]models.Order, int, error) {
 	orders := []models.Order{}
 	totalCount := 0
+	err := r.queryOrders(filters, filters.Cursor == nil, func(o *models.Order, total int) error {
+		orders = append(orders, *o)
+		totalCount = total
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, totalCount, nil
+}
 
-	var queryBuilder strings.Builder
-	queryBuilder.WriteString(`
-        SELECT
-            o.id, o.client_id, o.booking_id, o.staff_id, o.table_id, o.order_time, o.status,
-            o.total_amount, o.discount_amount, o.final_amount, o.payment_method, o.notes, 
-            o.created_at, o.updated_at,
-            c.full_name as client_name, c.phone_number as client_phone,
-            gt.name as table_name,
-            u.full_name as staff_name,
-            COUNT(*) OVER() as total_count
-        FROM orders o
-        LEFT JOIN clients c ON o.client_id = c.id
-        LEFT JOIN game_tables gt ON o.table_id = gt.id
-        LEFT JOIN staff_members sm ON o.staff_id = sm.id
-        LEFT JOIN users u ON sm.user_id = u.id
-    `)
+func (r *orderRepository) StreamOrders(filters models.OrderFilters, fn func(*models.Order) error) error {
+	filters.Page, filters.PageSize, filters.Cursor = 0, 0, nil
+	return r.queryOrders(filters, false, func(o *models.Order, _ int) error { return fn(o) })
+}
 
+// orderConditions returns the WHERE conditions selecting the orders that match filters,
+// with their arguments as $1, $2, ...
+func orderConditions(filters models.OrderFilters) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argCounter := 1
@@ -127,58 +222,115 @@ func (r *orderRepository) GetOrders(filters models.OrderFilters) ([]models.Order
 		args = append(args, *filters.Status)
 		argCounter++
 	}
+	if filters.Source != nil && *filters.Source != "" {
+		conditions = append(conditions, fmt.Sprintf("o.source = $%d", argCounter))
+		args = append(args, *filters.Source)
+		argCounter++
+	}
+	if len(filters.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("o.status = ANY($%d)", argCounter))
+		args = append(args, pq.Array(filters.Statuses))
+		argCounter++
+	}
 	if filters.Date != nil && *filters.Date != "" {
-		parsedDate, err := time.Parse("2006-01-02", *filters.Date)
+		parsedDate, err := utils.ParseClubDate(*filters.Date)
 		if err == nil {
-			startOfDay := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, parsedDate.Location())
-			endOfDay := startOfDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
-			conditions = append(conditions, fmt.Sprintf("o.order_time BETWEEN $%d AND $%d", argCounter, argCounter+1))
+			startOfDay, endOfDay := utils.DayBounds(parsedDate) // Club-local day as a UTC interval
+			conditions = append(conditions, fmt.Sprintf("o.order_time >= $%d AND o.order_time < $%d", argCounter, argCounter+1))
 			args = append(args, startOfDay, endOfDay)
 			argCounter += 2
+			conditions = append(conditions, fmt.Sprintf("o.business_date BETWEEN $%d AND $%d", argCounter, argCounter+1))
+			args = append(args, businessDateFrom(startOfDay), businessDateTo(endOfDay))
+			argCounter += 2
 		}
 	}
+	if filters.DateFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("o.order_time >= $%d AND o.business_date >= $%d", argCounter, argCounter+1))
+		args = append(args, *filters.DateFrom, businessDateFrom(*filters.DateFrom))
+		argCounter += 2
+	}
+	if filters.DateTo != nil {
+		conditions = append(conditions, fmt.Sprintf("o.order_time < $%d AND o.business_date <= $%d", argCounter, argCounter+1))
+		args = append(args, *filters.DateTo, businessDateTo(*filters.DateTo))
+		argCounter += 2
+	}
+	if filters.Cursor != nil && !filters.Cursor.IsZero() {
+		// The row comparison does not prune partitions, the bound on the business date does
+		conditions = append(conditions, fmt.Sprintf("(o.order_time, o.id) < ($%d, $%d) AND o.business_date <= $%d", argCounter, argCounter+1, argCounter+2))
+		args = append(args, filters.Cursor.Time, filters.Cursor.ID, businessDateTo(filters.Cursor.Time))
+	}
+	return conditions, args
+}
+
+// ordersListQuery builds the order list query for filters, with the total count of
+// matching orders if withTotal is set.
+func ordersListQuery(filters models.OrderFilters, withTotal bool) (string, []interface{}) {
+	var queryBuilder strings.Builder
+	queryBuilder.WriteString(`
+        SELECT
+            o.id, o.client_id, o.booking_id, o.staff_id, o.table_id, o.order_time, o.status,
+            o.total_amount, o.discount_amount, o.final_amount, o.tax_amount, o.prices_include_tax, o.payment_method, o.notes, 
+            o.created_at, o.updated_at, o.version, o.branch_code, o.business_date, o.daily_number,
+            o.guest_count, o.service_charge_rate, o.service_charge_amount, o.uuid, o.source,
+            c.full_name as client_name, c.phone_number as client_phone,
+            gt.name as table_name,
+            u.full_name as staff_name,
+            ` + totalCountColumn(withTotal) + `
+        FROM orders o
+        LEFT JOIN clients c ON o.client_id = c.id
+        LEFT JOIN game_tables gt ON o.table_id = gt.id
+        LEFT JOIN staff_members sm ON o.staff_id = sm.id
+        LEFT JOIN users u ON sm.user_id = u.id
+    `)
+
+	conditions, args := orderConditions(filters)
+	argCounter := len(args) + 1
 
 	if len(conditions) > 0 {
 		queryBuilder.WriteString(" WHERE " + strings.Join(conditions, " AND "))
 	}
-	queryBuilder.WriteString(" ORDER BY o.order_time DESC")
+	queryBuilder.WriteString(" ORDER BY o.order_time DESC, o.id DESC")
 
 	if filters.PageSize > 0 {
 		queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCounter))
 		args = append(args, filters.PageSize)
 		argCounter++
-		if filters.Page > 0 {
+		if filters.Page > 0 && filters.Cursor == nil {
 			offset := (filters.Page - 1) * filters.PageSize
 			queryBuilder.WriteString(fmt.Sprintf(" OFFSET $%d", argCounter))
 			args = append(args, offset)
 			// argCounter++ // Not needed as this is the last placeholder
 		}
 	}
+	return queryBuilder.String(), args
+}
 
-	rows, err := r.db.Query(queryBuilder.String(), args...)
+// queryOrders runs the order list query and calls fn with each order as it is read,
+// with the total count of matching orders if withTotal is set.
+func (r *orderRepository) queryOrders(filters models.OrderFilters, withTotal bool, fn func(o *models.Order, total int) error) error {
+	query, args := ordersListQuery(filters, withTotal)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%w: querying orders: %v", ErrDatabaseError, err)
+		return fmt.Errorf("%w: querying orders: %v", ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var o models.Order
 		var clientName, clientPhone, tableName, staffName sql.NullString
+		var totalCount int
 		
 		var client models.Client
 		var gameTable models.GameTable
 		var staffMember models.StaffMember
 		var user models.User
 
-		err := rows.Scan(
-			&o.ID, &o.ClientID, &o.BookingID, &o.StaffID, &o.TableID, &o.OrderTime, &o.Status,
-			&o.TotalAmount, &o.DiscountAmount, &o.FinalAmount, &o.PaymentMethod, &o.Notes,
-			&o.CreatedAt, &o.UpdatedAt,
+		err := scanOrder(rows, &o,
 			&clientName, &clientPhone, &tableName, &staffName,
 			&totalCount,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("%w: scanning order: %v", ErrDatabaseError, err)
+			return fmt.Errorf("%w: scanning order: %v", ErrDatabaseError, err)
 		}
 
 		if o.ClientID != nil {
@@ -198,17 +350,19 @@ func (r *orderRepository) GetOrders(filters models.OrderFilters) ([]models.Order
 			staffMember.User = &user
 			o.StaffMember = &staffMember
 		}
-		orders = append(orders, o)
+		if err := fn(&o, totalCount); err != nil {
+			return err
+		}
 	}
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("%w: iterating order rows: %v", ErrDatabaseError, err)
+		return fmt.Errorf("%w: iterating order rows: %v", ErrDatabaseError, err)
 	}
-	return orders, totalCount, nil
+	return nil
 }
 
-func (r *orderRepository) UpdateOrderStatus(executor SQLExecutor, orderID int64, newStatus string, updatedAt time.Time) error {
-	query := `UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`
-	result, err := executor.Exec(query, newStatus, updatedAt, orderID)
+func (r *orderRepository) UpdateOrderStatus(executor SQLExecutor, orderID int64, newStatus string, expectedVersion int, updatedAt time.Time) error {
+	query := `UPDATE orders SET status = $1, updated_at = $2, version = version + 1 WHERE id = $3 AND version = $4`
+	result, err := executor.Exec(query, newStatus, updatedAt, orderID, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("%w: updating order status for ID %d: %v", ErrDatabaseError, orderID, err)
 	}
@@ -217,7 +371,46 @@ func (r *orderRepository) UpdateOrderStatus(executor SQLExecutor, orderID int64,
 		return fmt.Errorf("%w: getting rows affected for order status update ID %d: %v", ErrDatabaseError, orderID, err)
 	}
 	if rowsAffected == 0 {
-		return ErrNotFound
+		return versionMismatchError(executor, "orders", orderID)
+	}
+	return nil
+}
+
+func (r *orderRepository) UpdateOrderAmounts(executor SQLExecutor, order *models.Order, expectedVersion int, updatedAt time.Time) error {
+	result, err := executor.Exec(`UPDATE orders
+	                              SET total_amount = $1, discount_amount = $2, final_amount = $3, tax_amount = $4,
+	                                  service_charge_amount = $5, updated_at = $6, version = version + 1
+	                              WHERE id = $7 AND version = $8`,
+		order.TotalAmount, order.DiscountAmount, order.FinalAmount, order.TaxAmount, order.ServiceChargeAmount,
+		updatedAt, order.ID, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("%w: updating amounts of order ID %d: %v", ErrDatabaseError, order.ID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for order amounts update ID %d: %v", ErrDatabaseError, order.ID, err)
+	}
+	if rowsAffected == 0 {
+		return versionMismatchError(executor, "orders", order.ID)
+	}
+	return nil
+}
+
+func (r *orderRepository) CreateOrderVoid(executor SQLExecutor, void *models.OrderVoid) error {
+	err := executor.QueryRow(`INSERT INTO order_voids
+	                          (branch_code, order_id, order_number, order_item_id, pricelist_item_id, quantity, amount, reason, note,
+	                           order_staff_id, voided_by, time_clock_entry_id, voided_at)
+	                          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+	                                  (SELECT e.id FROM time_clock_entries e JOIN staff_members sm ON e.staff_id = sm.id
+	                                   WHERE sm.user_id = $11 AND e.clock_in_at <= $12 AND (e.clock_out_at IS NULL OR e.clock_out_at > $12)
+	                                   ORDER BY e.clock_in_at DESC LIMIT 1),
+	                                  $12)
+	                          RETURNING id, time_clock_entry_id`,
+		void.BranchCode, void.OrderID, void.OrderNumber, void.OrderItemID, void.PricelistItemID, void.Quantity, void.Amount,
+		void.Reason, void.Note, void.OrderStaffID, void.VoidedBy, void.VoidedAt,
+	).Scan(&void.ID, &void.TimeClockEntryID)
+	if err != nil {
+		return fmt.Errorf("%w: recording void of order ID %d: %v", ErrDatabaseError, void.OrderID, err)
 	}
 	return nil
 }
@@ -242,16 +435,17 @@ func (r *orderRepository) DeleteOrder(executor SQLExecutor, orderID int64) (int6
 
 func (r *orderRepository) CreateOrderItem(executor SQLExecutor, item *models.OrderItem) (int64, error) {
 	query := `INSERT INTO order_items 
-	            (order_id, pricelist_item_id, quantity, unit_price, total_price, notes, 
-	             created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	            (order_id, pricelist_item_id, quantity, stock_quantity, unit_price, total_price, discount_amount, 
+	             tax_class, tax_rate, tax_amount, notes, created_at, updated_at, uuid)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	          RETURNING id`
-	if item.CreatedAt.IsZero() { item.CreatedAt = time.Now() }
-	if item.UpdatedAt.IsZero() { item.UpdatedAt = time.Now() }
+	if item.CreatedAt.IsZero() { item.CreatedAt = time.Now().UTC() }
+	if item.UpdatedAt.IsZero() { item.UpdatedAt = time.Now().UTC() }
 	
 	err := executor.QueryRow(query,
-		item.OrderID, item.PricelistItemID, item.Quantity, item.UnitPrice, item.TotalPrice, item.Notes,
-		item.CreatedAt, item.UpdatedAt,
+		item.OrderID, item.PricelistItemID, item.Quantity, item.StockQuantity, item.UnitPrice, item.TotalPrice, item.DiscountAmount,
+		item.TaxClass, item.TaxRate, item.TaxAmount, item.Notes,
+		item.CreatedAt, item.UpdatedAt, item.UUID,
 	).Scan(&item.ID)
 
 	if err != nil {
@@ -259,6 +453,9 @@ func (r *orderRepository) CreateOrderItem(executor SQLExecutor, item *models.Ord
 		if errors.As(err, &pqErr) && pqErr.Code == "23503" { 
 			return 0, fmt.Errorf("%w: creating order item (constraint: %s): %v", ErrDatabaseError, pqErr.Constraint, err)
 		}
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, fmt.Errorf("%w: order item UUID already exists", ErrDuplicateKey)
+		}
 		return 0, fmt.Errorf("%w: creating order item: %v", ErrDatabaseError, err)
 	}
 	return item.ID, nil
@@ -266,19 +463,61 @@ func (r *orderRepository) CreateOrderItem(executor SQLExecutor, item *models.Ord
 
 func (r *orderRepository) GetOrderItemsByOrderID(orderID int64) ([]models.OrderItem, error) {
 	items := []models.OrderItem{}
+	err := r.queryOrderItems("WHERE oi.order_id = $1 ORDER BY oi.id", []interface{}{orderID}, func(item *models.OrderItem) error {
+		items = append(items, *item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("order ID %d: %w", orderID, err)
+	}
+	return items, nil
+}
+
+func (r *orderRepository) GetOrderItemsByOrderIDs(orderIDs []int64) ([]models.OrderItem, error) {
+	items := []models.OrderItem{}
+	if len(orderIDs) == 0 {
+		return items, nil
+	}
+	err := r.queryOrderItems("WHERE oi.order_id = ANY($1) ORDER BY oi.order_id, oi.id", []interface{}{pq.Array(orderIDs)}, func(item *models.OrderItem) error {
+		items = append(items, *item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("order IDs %v: %w", orderIDs, err)
+	}
+	return items, nil
+}
+
+func (r *orderRepository) StreamOrderItems(filters models.OrderFilters, fn func(*models.OrderItem) error) error {
+	conditions, args := orderConditions(filters)
+	where := "WHERE oi.order_id IN (SELECT o.id FROM orders o"
+	if len(conditions) > 0 {
+		where += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	where += ") ORDER BY oi.order_id, oi.id"
+	return r.queryOrderItems(where, args, fn)
+}
+
+// queryOrderItems reads the order items selected by where (a WHERE and ORDER BY clause
+// over order_items oi) and calls fn with each item as it is read.
+func (r *orderRepository) queryOrderItems(where string, args []interface{}, fn func(*models.OrderItem) error) error {
+	return queryOrderItemsFrom(r.db, "order_items", where, args, fn)
+}
+
+// queryOrderItemsFrom is queryOrderItems over table, order_items or order_items_archive.
+func queryOrderItemsFrom(db *sql.DB, table string, where string, args []interface{}, fn func(*models.OrderItem) error) error {
 	query := `
 		SELECT 
-		    oi.id, oi.order_id, oi.pricelist_item_id, oi.quantity, oi.unit_price, 
-		    oi.total_price, oi.notes, oi.created_at, oi.updated_at,
+		    oi.id, oi.order_id, oi.pricelist_item_id, oi.quantity, oi.stock_quantity, oi.unit_price, 
+		    oi.total_price, oi.discount_amount, oi.tax_class, oi.tax_rate, oi.tax_amount, oi.notes, oi.created_at, oi.updated_at, oi.uuid,
 		    pi.name as item_name, pi.sku as item_sku, pi.tracks_stock as item_tracks_stock
-		FROM order_items oi
+		FROM ` + table + ` oi
 		JOIN pricelist_items pi ON oi.pricelist_item_id = pi.id
-		WHERE oi.order_id = $1
-		ORDER BY oi.id`
+		` + where
 
-	rows, err := r.db.Query(query, orderID)
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%w: querying order items for order ID %d: %v", ErrDatabaseError, orderID, err)
+		return fmt.Errorf("%w: querying order items: %v", ErrDatabaseError, err)
 	}
 	defer rows.Close()
 
@@ -289,12 +528,13 @@ func (r *orderRepository) GetOrderItemsByOrderID(orderID int64) ([]models.OrderI
 		var itemTracksStock sql.NullBool
 
 		err := rows.Scan(
-			&item.ID, &item.OrderID, &item.PricelistItemID, &item.Quantity, &item.UnitPrice,
-			&item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.ID, &item.OrderID, &item.PricelistItemID, &item.Quantity, &item.StockQuantity, &item.UnitPrice,
+			&item.TotalPrice, &item.DiscountAmount, &item.TaxClass, &item.TaxRate, &item.TaxAmount,
+			&item.Notes, &item.CreatedAt, &item.UpdatedAt, &item.UUID,
 			&itemName, &itemSKU, &itemTracksStock,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("%w: scanning order item for order ID %d: %v", ErrDatabaseError, orderID, err)
+			return fmt.Errorf("%w: scanning order item: %v", ErrDatabaseError, err)
 		}
 		
 		pricelistItem.ID = item.PricelistItemID 
@@ -303,12 +543,14 @@ func (r *orderRepository) GetOrderItemsByOrderID(orderID int64) ([]models.OrderI
 		if itemTracksStock.Valid { pricelistItem.TracksStock = itemTracksStock.Bool }
 		item.PricelistItem = &pricelistItem
 
-		items = append(items, item)
+		if err := fn(&item); err != nil {
+			return err
+		}
 	}
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("%w: iterating order item rows for order ID %d: %v", ErrDatabaseError, orderID, err)
+		return fmt.Errorf("%w: iterating order item rows: %v", ErrDatabaseError, err)
 	}
-	return items, nil
+	return nil
 }
 
 func (r *orderRepository) DeleteOrderItemsByOrderID(executor SQLExecutor, orderID int64) (int64, error) {
@@ -323,3 +565,18 @@ func (r *orderRepository) DeleteOrderItemsByOrderID(executor SQLExecutor, orderI
 	}
 	return rowsAffected, nil
 }
+
+func (r *orderRepository) DeleteOrderItem(executor SQLExecutor, orderID, itemID int64) error {
+	result, err := executor.Exec(`DELETE FROM order_items WHERE id = $1 AND order_id = $2`, itemID, orderID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting item ID %d of order ID %d: %v", ErrDatabaseError, itemID, orderID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for deleting item ID %d: %v", ErrDatabaseError, itemID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
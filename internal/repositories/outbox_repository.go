@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// OutboxRepository defines the database operations of the domain event outbox.
+type OutboxRepository interface {
+	// CreateEvent inserts an event; pass the transaction of the business change it describes.
+	CreateEvent(executor SQLExecutor, event *models.DomainEvent) (int64, error)
+	// ClaimPendingEvents locks up to limit unpublished events that are due at now.
+	// Rows locked by another relay are skipped, so several instances can relay concurrently.
+	ClaimPendingEvents(executor SQLExecutor, now time.Time, limit int) ([]models.DomainEvent, error)
+	MarkEventPublished(executor SQLExecutor, eventID int64, publishedAt time.Time) error
+	MarkEventFailed(executor SQLExecutor, eventID int64, lastError string, nextAttemptAt time.Time) error
+	DeletePublishedEventsBefore(before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository.
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) CreateEvent(executor SQLExecutor, event *models.DomainEvent) (int64, error) {
+	query := `INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, payload, created_at, next_attempt_at)
+	          VALUES ($1, $2, $3, $4, $5, $5)
+	          RETURNING id`
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	event.NextAttemptAt = event.CreatedAt
+
+	err := executor.QueryRow(query,
+		event.EventType, event.AggregateType, event.AggregateID, []byte(event.Payload), event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: creating outbox event: %v", ErrDatabaseError, err)
+	}
+	return event.ID, nil
+}
+
+func (r *outboxRepository) ClaimPendingEvents(executor SQLExecutor, now time.Time, limit int) ([]models.DomainEvent, error) {
+	query := `SELECT id, event_type, aggregate_type, aggregate_id, payload, created_at, published_at, attempts, last_error, next_attempt_at
+	          FROM outbox_events
+	          WHERE published_at IS NULL AND next_attempt_at <= $1
+	          ORDER BY id
+	          LIMIT $2
+	          FOR UPDATE SKIP LOCKED`
+	rows, err := executor.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: claiming outbox events: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	events := []models.DomainEvent{}
+	for rows.Next() {
+		var event models.DomainEvent
+		var payload []byte
+		var publishedAt sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(
+			&event.ID, &event.EventType, &event.AggregateType, &event.AggregateID, &payload,
+			&event.CreatedAt, &publishedAt, &event.Attempts, &lastError, &event.NextAttemptAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: scanning outbox event: %v", ErrDatabaseError, err)
+		}
+		event.Payload = payload
+		if publishedAt.Valid {
+			event.PublishedAt = &publishedAt.Time
+		}
+		if lastError.Valid {
+			event.LastError = &lastError.String
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating outbox events: %v", ErrDatabaseError, err)
+	}
+	return events, nil
+}
+
+func (r *outboxRepository) MarkEventPublished(executor SQLExecutor, eventID int64, publishedAt time.Time) error {
+	query := `UPDATE outbox_events SET published_at = $1, attempts = attempts + 1, last_error = NULL WHERE id = $2`
+	result, err := executor.Exec(query, publishedAt, eventID)
+	if err != nil {
+		return fmt.Errorf("%w: marking outbox event published: %v", ErrDatabaseError, err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkEventFailed(executor SQLExecutor, eventID int64, lastError string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox_events SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`
+	result, err := executor.Exec(query, lastError, nextAttemptAt, eventID)
+	if err != nil {
+		return fmt.Errorf("%w: marking outbox event failed: %v", ErrDatabaseError, err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *outboxRepository) DeletePublishedEventsBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM outbox_events WHERE published_at IS NOT NULL AND published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: deleting published outbox events: %v", ErrDatabaseError, err)
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/pkg/utils"
+)
+
+// Tables partitioned by month, see migration 0063_monthly_partitions.sql.
+const (
+	PartitionedOrders             = "orders"              // On business_date
+	PartitionedInventoryMovements = "inventory_movements" // On movement_date, months of UTC
+)
+
+// PartitionedTables are the tables whose monthly partitions are created ahead.
+var PartitionedTables = []string{PartitionedOrders, PartitionedInventoryMovements}
+
+// PartitionRepository defines the maintenance of the monthly partitions of PartitionedTables.
+type PartitionRepository interface {
+	// CreateMonthlyPartitions creates the partitions of table still missing for the months
+	// from the month of from through the month of through, and returns their names.
+	CreateMonthlyPartitions(table string, from, through time.Time) ([]string, error)
+}
+
+type partitionRepository struct {
+	db *sql.DB
+}
+
+// NewPartitionRepository creates a new instance of PartitionRepository.
+func NewPartitionRepository(db *sql.DB) PartitionRepository {
+	return &partitionRepository{db: db}
+}
+
+func (r *partitionRepository) CreateMonthlyPartitions(table string, from, through time.Time) ([]string, error) {
+	rows, err := r.db.Query(`SELECT create_monthly_partitions($1, $2, $3)`,
+		table, from.UTC().Format(utils.DateLayout), through.UTC().Format(utils.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating partitions of %s: %v", ErrDatabaseError, table, err)
+	}
+	defer rows.Close()
+
+	created := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("%w: scanning partition of %s: %v", ErrDatabaseError, table, err)
+		}
+		created = append(created, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: creating partitions of %s: %v", ErrDatabaseError, table, err)
+	}
+	return created, nil
+}
+
+// businessDateFrom and businessDateTo bound the business dates of the orders placed from or
+// until t. Conditions on orders.business_date, the partition key of the orders, alongside
+// those on order_time let PostgreSQL skip the partitions of other months. A business date is
+// the date of the order time in the club timezone, never more than a day from its UTC date.
+func businessDateFrom(t time.Time) string {
+	return t.UTC().AddDate(0, 0, -1).Format(utils.DateLayout)
+}
+
+func businessDateTo(t time.Time) string {
+	return t.UTC().AddDate(0, 0, 1).Format(utils.DateLayout)
+}
@@ -24,10 +24,17 @@ type PricelistRepository interface {
 	CreateItem(executor SQLExecutor, item *models.PricelistItem) (int64, error)
 	GetItemByID(id int64) (*models.PricelistItem, error) // Should join with category
 	GetItems(categoryID *int64, itemType *string, page, pageSize int) ([]models.PricelistItem, int, error) // Returns items, total count, error. Joins with category.
-	UpdateItem(executor SQLExecutor, item *models.PricelistItem) error
-	DeleteItem(executor SQLExecutor, id int64) error
-	UpdateStock(executor SQLExecutor, itemID int64, quantityChange int) (int, error) // Returns new stock level
-	GetItemPriceAndStock(itemID int64) (price float64, currentStock sql.NullInt64, itemName string, tracksStock bool, err error) // Used by OrderService
+	UpdateItem(executor SQLExecutor, item *models.PricelistItem) error // Requires item.Version to match, ErrVersionConflict otherwise
+	DeleteItem(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error // Soft deletes; ErrNotFound if already deleted
+	// RestoreItem undoes the soft delete of an item. ErrNotFound if the item is not deleted; a
+	// ConstraintError wrapping ErrDuplicateKey if another item has its SKU since.
+	RestoreItem(executor SQLExecutor, id int64, now time.Time) error
+	UpdateStock(executor SQLExecutor, itemID int64, quantityChange models.Quantity) (models.Quantity, error) // Returns new stock level
+	GetItemPriceAndStock(itemID int64) (price models.Money, currentStock *models.Quantity, itemName string, tracksStock bool, err error) // Used by OrderService
+	// GetItemUnits returns the units of measure of an item; ErrNotFound if there is none.
+	GetItemUnits(itemID int64) (models.ItemUnits, error)
+	// GetItemTaxClasses returns the tax class of those of itemIDs that have one, by item ID.
+	GetItemTaxClasses(itemIDs []int64) (map[int64]string, error)
 }
 
 type pricelistRepository struct {
@@ -45,7 +52,7 @@ func (r *pricelistRepository) CreateCategory(executor SQLExecutor, category *mod
 	query := `INSERT INTO pricelist_categories (name, description, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4)
 	          RETURNING id`
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	err := executor.QueryRow(query, category.Name, category.Description, currentTime, currentTime).Scan(&category.ID)
 	if err != nil {
 		var pqErr *pq.Error
@@ -99,7 +106,7 @@ func (r *pricelistRepository) GetCategories(page, pageSize int) ([]models.Pricel
 
 func (r *pricelistRepository) UpdateCategory(executor SQLExecutor, category *models.PricelistCategory) error {
 	query := `UPDATE pricelist_categories SET name = $1, description = $2, updated_at = $3 WHERE id = $4`
-	result, err := executor.Exec(query, category.Name, category.Description, time.Now(), category.ID)
+	result, err := executor.Exec(query, category.Name, category.Description, time.Now().UTC(), category.ID)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
@@ -123,7 +130,7 @@ func (r *pricelistRepository) DeleteCategory(executor SQLExecutor, id int64) err
 		return fmt.Errorf("%w: checking if category %d is in use: %v", ErrDatabaseError, id, err)
 	}
 	if count > 0 {
-		return fmt.Errorf("%w: category ID %d cannot be deleted as it is currently in use by %d pricelist item(s)", ErrDatabaseError, id, count)
+		return &ConstraintError{Err: ErrReferenced, Constraint: "pricelist_items_category_id_fkey", Detail: fmt.Sprintf("category ID %d is in use by %d pricelist item(s)", id, count)}
 	}
 	
 	query := `DELETE FROM pricelist_categories WHERE id = $1`
@@ -140,34 +147,29 @@ func (r *pricelistRepository) DeleteCategory(executor SQLExecutor, id int64) err
 
 // --- PricelistItem Methods ---
 
-func (r *pricelistRepository) CreateItem(executor SQLExecutor, item *models.PricelistItem) (int64, error) {
-	query := `INSERT INTO pricelist_items 
-	          (category_id, name, description, price, sku, is_available, item_type, tracks_stock, current_stock, low_stock_threshold, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	          RETURNING id`
-	currentTime := time.Now()
-
-	var currentStock sql.NullInt64
-	if item.TracksStock && item.CurrentStock != nil {
-		currentStock = sql.NullInt64{Int64: int64(*item.CurrentStock), Valid: true}
-	} else if !item.TracksStock {
-        currentStock = sql.NullInt64{Valid: false} // Ensure NULL if not tracking
-    } else { // Tracks stock but CurrentStock is nil (e.g. initial creation without stock value)
-		currentStock = sql.NullInt64{Valid: false} // Or default to 0: sql.NullInt64{Int64: 0, Valid: true}
+// stockColumns returns the stock and low stock threshold to store for item: NULL if it does not track stock.
+func stockColumns(item *models.PricelistItem) (currentStock, lowStockThreshold *models.Quantity) {
+	if !item.TracksStock {
+		return nil, nil
 	}
+	return item.CurrentStock, item.LowStockThreshold
+}
 
+func (r *pricelistRepository) CreateItem(executor SQLExecutor, item *models.PricelistItem) (int64, error) {
+	query := `INSERT INTO pricelist_items 
+	          (category_id, name, description, price, sku, is_available, item_type, tracks_stock, current_stock, low_stock_threshold, tax_class,
+	           stock_unit, portion_size, purchase_unit, purchase_unit_size, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	          RETURNING id, version`
+	currentTime := time.Now().UTC()
 
-	var lowStockThreshold sql.NullInt64
-	if item.TracksStock && item.LowStockThreshold != nil {
-		lowStockThreshold = sql.NullInt64{Int64: int64(*item.LowStockThreshold), Valid: true}
-	} else if !item.TracksStock {
-        lowStockThreshold = sql.NullInt64{Valid: false} // Ensure NULL if not tracking
-    }
+	currentStock, lowStockThreshold := stockColumns(item)
 
 	err := executor.QueryRow(query,
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU, item.IsAvailable,
-		item.ItemType, item.TracksStock, currentStock, lowStockThreshold, currentTime, currentTime,
-	).Scan(&item.ID)
+		item.ItemType, item.TracksStock, currentStock, lowStockThreshold, item.TaxClass,
+		item.StockUnit, item.PortionSize, item.PurchaseUnit, item.PurchaseUnitSize, currentTime, currentTime,
+	).Scan(&item.ID, &item.Version)
 
 	if err != nil {
 		var pqErr *pq.Error
@@ -176,7 +178,7 @@ func (r *pricelistRepository) CreateItem(executor SQLExecutor, item *models.Pric
 				return 0, fmt.Errorf("%w: creating pricelist item (constraint: %s): %v", ErrDuplicateKey, pqErr.Constraint, err)
 			}
 			if pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "pricelist_items_category_id_fkey" {
-				return 0, fmt.Errorf("%w: invalid category_id %d (constraint: %s): %v", ErrDatabaseError, item.CategoryID, pqErr.Constraint, err)
+				return 0, &ConstraintError{Err: ErrInvalidReference, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("category ID %d does not exist", item.CategoryID)}
 			}
 		}
 		return 0, fmt.Errorf("%w: creating pricelist item: %v", ErrDatabaseError, err)
@@ -191,20 +193,19 @@ func (r *pricelistRepository) GetItemByID(id int64) (*models.PricelistItem, erro
 	query := `SELECT 
 	            pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	            pi.is_available, pi.item_type, pi.tracks_stock, pi.current_stock, pi.low_stock_threshold, 
-	            pi.created_at, pi.updated_at,
+	            pi.tax_class, pi.stock_unit, pi.portion_size, pi.purchase_unit, pi.purchase_unit_size,
+	            pi.created_at, pi.updated_at, pi.version,
 	            pc.id as cat_id, pc.name as cat_name, pc.description as cat_desc, 
 	            pc.created_at as cat_created_at, pc.updated_at as cat_updated_at
 	          FROM pricelist_items pi
 	          JOIN pricelist_categories pc ON pi.category_id = pc.id
-	          WHERE pi.id = $1`
-
-	var currentStock sql.NullInt64
-	var lowStockThreshold sql.NullInt64
+	          WHERE pi.id = $1 AND pi.deleted_at IS NULL`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU,
-		&item.IsAvailable, &item.ItemType, &item.TracksStock, &currentStock, &lowStockThreshold,
-		&item.CreatedAt, &item.UpdatedAt,
+		&item.IsAvailable, &item.ItemType, &item.TracksStock, &item.CurrentStock, &item.LowStockThreshold,
+		&item.TaxClass, &item.StockUnit, &item.PortionSize, &item.PurchaseUnit, &item.PurchaseUnitSize,
+		&item.CreatedAt, &item.UpdatedAt, &item.Version,
 		&category.ID, &category.Name, &category.Description, &category.CreatedAt, &category.UpdatedAt,
 	)
 	if err != nil {
@@ -213,15 +214,6 @@ func (r *pricelistRepository) GetItemByID(id int64) (*models.PricelistItem, erro
 		}
 		return nil, fmt.Errorf("%w: getting pricelist item by ID %d: %v", ErrDatabaseError, id, err)
 	}
-
-	if currentStock.Valid {
-		val := int(currentStock.Int64)
-		item.CurrentStock = &val
-	}
-	if lowStockThreshold.Valid {
-		val := int(lowStockThreshold.Int64)
-		item.LowStockThreshold = &val
-	}
 	item.Category = category
 	return item, nil
 }
@@ -234,14 +226,15 @@ func (r *pricelistRepository) GetItems(categoryID *int64, itemType *string, page
 	queryBuilder.WriteString(`SELECT 
 	    pi.id, pi.category_id, pi.name, pi.description, pi.price, pi.sku, 
 	    pi.is_available, pi.item_type, pi.tracks_stock, pi.current_stock, pi.low_stock_threshold, 
-	    pi.created_at, pi.updated_at,
+	    pi.tax_class, pi.stock_unit, pi.portion_size, pi.purchase_unit, pi.purchase_unit_size,
+	    pi.created_at, pi.updated_at, pi.version,
 	    pc.id as cat_id, pc.name as cat_name, pc.description as cat_desc, 
 	    pc.created_at as cat_created_at, pc.updated_at as cat_updated_at,
 	    COUNT(*) OVER() AS total_count
 	  FROM pricelist_items pi
 	  JOIN pricelist_categories pc ON pi.category_id = pc.id`)
 
-	var conditions []string
+	conditions := []string{"pi.deleted_at IS NULL"}
 	var args []interface{}
 	argCount := 1
 
@@ -256,10 +249,8 @@ func (r *pricelistRepository) GetItems(categoryID *int64, itemType *string, page
 		argCount++
 	}
 
-	if len(conditions) > 0 {
-		queryBuilder.WriteString(" WHERE ")
-		queryBuilder.WriteString(strings.Join(conditions, " AND "))
-	}
+	queryBuilder.WriteString(" WHERE ")
+	queryBuilder.WriteString(strings.Join(conditions, " AND "))
 
 	queryBuilder.WriteString(" ORDER BY pi.name") // Consider making order configurable
 	queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1))
@@ -274,26 +265,17 @@ func (r *pricelistRepository) GetItems(categoryID *int64, itemType *string, page
 	for rows.Next() {
 		var item models.PricelistItem
 		var category models.PricelistCategory
-		var currentStock sql.NullInt64
-		var lowStockThreshold sql.NullInt64
 
 		if err := rows.Scan(
 			&item.ID, &item.CategoryID, &item.Name, &item.Description, &item.Price, &item.SKU,
-			&item.IsAvailable, &item.ItemType, &item.TracksStock, &currentStock, &lowStockThreshold,
-			&item.CreatedAt, &item.UpdatedAt,
+			&item.IsAvailable, &item.ItemType, &item.TracksStock, &item.CurrentStock, &item.LowStockThreshold,
+			&item.TaxClass, &item.StockUnit, &item.PortionSize, &item.PurchaseUnit, &item.PurchaseUnitSize,
+			&item.CreatedAt, &item.UpdatedAt, &item.Version,
 			&category.ID, &category.Name, &category.Description, &category.CreatedAt, &category.UpdatedAt,
 			&totalCount,
 		); err != nil {
 			return nil, 0, fmt.Errorf("%w: scanning pricelist item: %v", ErrDatabaseError, err)
 		}
-		if currentStock.Valid {
-			val := int(currentStock.Int64)
-			item.CurrentStock = &val
-		}
-		if lowStockThreshold.Valid {
-			val := int(lowStockThreshold.Int64)
-			item.LowStockThreshold = &val
-		}
 		item.Category = &category
 		items = append(items, item)
 	}
@@ -308,42 +290,45 @@ func (r *pricelistRepository) UpdateItem(executor SQLExecutor, item *models.Pric
 	query := `UPDATE pricelist_items SET 
 	            category_id = $1, name = $2, description = $3, price = $4, sku = $5, 
 	            is_available = $6, item_type = $7, tracks_stock = $8, current_stock = $9, 
-	            low_stock_threshold = $10, updated_at = $11 
-	          WHERE id = $12`
-
-	var currentStock sql.NullInt64
-	if item.TracksStock && item.CurrentStock != nil {
-		currentStock = sql.NullInt64{Int64: int64(*item.CurrentStock), Valid: true}
-	} else if !item.TracksStock {
-         currentStock = sql.NullInt64{Valid: false}
-    } else { // Tracks stock but CurrentStock is nil
-		currentStock = sql.NullInt64{Valid: false} // Or default to 0
-	}
+	            low_stock_threshold = $10, tax_class = $11, stock_unit = $12, portion_size = $13, purchase_unit = $14,
+	            purchase_unit_size = $15, updated_at = $16, version = version + 1 
+	          WHERE id = $17 AND version = $18
+	          RETURNING version`
 
-	var lowStockThreshold sql.NullInt64
-	if item.TracksStock && item.LowStockThreshold != nil {
-		lowStockThreshold = sql.NullInt64{Int64: int64(*item.LowStockThreshold), Valid: true}
-	} else if !item.TracksStock {
-        lowStockThreshold = sql.NullInt64{Valid: false}
-    }
+	currentStock, lowStockThreshold := stockColumns(item)
 
-	result, err := executor.Exec(query,
+	err := executor.QueryRow(query,
 		item.CategoryID, item.Name, item.Description, item.Price, item.SKU,
 		item.IsAvailable, item.ItemType, item.TracksStock, currentStock, lowStockThreshold,
-		time.Now(), item.ID,
-	)
+		item.TaxClass, item.StockUnit, item.PortionSize, item.PurchaseUnit, item.PurchaseUnitSize,
+		time.Now().UTC(), item.ID, item.Version,
+	).Scan(&item.Version)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(executor, "pricelist_items", item.ID)
+		}
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code.Name() == "unique_violation" {
 				return fmt.Errorf("%w: updating pricelist item (constraint: %s): %v", ErrDuplicateKey, pqErr.Constraint, err)
 			}
 			if pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "pricelist_items_category_id_fkey" {
-				return fmt.Errorf("%w: invalid category_id %d (constraint: %s): %v", ErrDatabaseError, item.CategoryID, pqErr.Constraint, err)
+				return &ConstraintError{Err: ErrInvalidReference, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("category ID %d does not exist", item.CategoryID)}
 			}
 		}
 		return fmt.Errorf("%w: updating pricelist item ID %d: %v", ErrDatabaseError, item.ID, err)
 	}
+	return nil
+}
+
+// DeleteItem soft deletes an item, keeping it on past orders and inventory movements.
+func (r *pricelistRepository) DeleteItem(executor SQLExecutor, id int64, deletedBy int64, now time.Time) error {
+	query := `UPDATE pricelist_items SET deleted_at = $2, deleted_by = $3, updated_at = $2, version = version + 1
+	          WHERE id = $1 AND deleted_at IS NULL`
+	result, err := executor.Exec(query, id, now, deletedBy)
+	if err != nil {
+		return fmt.Errorf("%w: deleting pricelist item ID %d: %v", ErrDatabaseError, id, err)
+	}
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
 		return ErrNotFound
@@ -351,15 +336,16 @@ func (r *pricelistRepository) UpdateItem(executor SQLExecutor, item *models.Pric
 	return nil
 }
 
-func (r *pricelistRepository) DeleteItem(executor SQLExecutor, id int64) error {
-	query := `DELETE FROM pricelist_items WHERE id = $1`
-	result, err := executor.Exec(query, id)
+func (r *pricelistRepository) RestoreItem(executor SQLExecutor, id int64, now time.Time) error {
+	query := `UPDATE pricelist_items SET deleted_at = NULL, deleted_by = NULL, updated_at = $2, version = version + 1
+	          WHERE id = $1 AND deleted_at IS NOT NULL`
+	result, err := executor.Exec(query, id, now)
 	if err != nil {
 		var pqErr *pq.Error
-		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
-			return fmt.Errorf("%w: item ID %d cannot be deleted as it is referenced by other records (e.g., orders, inventory movements) (constraint: %s)", ErrDatabaseError, id, pqErr.Constraint)
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("the SKU of item ID %d is used by another item", id)}
 		}
-		return fmt.Errorf("%w: deleting pricelist item ID %d: %v", ErrDatabaseError, id, err)
+		return fmt.Errorf("%w: restoring pricelist item ID %d: %v", ErrDatabaseError, id, err)
 	}
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
@@ -368,46 +354,83 @@ func (r *pricelistRepository) DeleteItem(executor SQLExecutor, id int64) error {
 	return nil
 }
 
-func (r *pricelistRepository) UpdateStock(executor SQLExecutor, itemID int64, quantityChange int) (int, error) {
-	var newStock sql.NullInt64 // Use NullInt64 to handle cases where current_stock might be NULL
+func (r *pricelistRepository) UpdateStock(executor SQLExecutor, itemID int64, quantityChange models.Quantity) (models.Quantity, error) {
+	var newStock *models.Quantity // Nil if current_stock is NULL
 	query := `UPDATE pricelist_items 
-	          SET current_stock = COALESCE(current_stock, 0) + $1, updated_at = $2 
+	          SET current_stock = COALESCE(current_stock, 0) + $1, updated_at = $2, version = version + 1 
 	          WHERE id = $3 AND tracks_stock = TRUE
 	          RETURNING current_stock`
-	err := executor.QueryRow(query, quantityChange, time.Now(), itemID).Scan(&newStock)
+	err := executor.QueryRow(query, quantityChange, time.Now().UTC(), itemID).Scan(&newStock)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			var tracksStockActual sql.NullBool
 			checkErr := r.db.QueryRow("SELECT tracks_stock FROM pricelist_items WHERE id = $1", itemID).Scan(&tracksStockActual)
 			if errors.Is(checkErr, sql.ErrNoRows) {
-				return 0, ErrNotFound // Item does not exist
+				return models.ZeroQuantity, ErrNotFound // Item does not exist
 			}
 			if checkErr == nil && tracksStockActual.Valid && !tracksStockActual.Bool {
-				return 0, fmt.Errorf("%w: stock not updated for item ID %d because it does not track stock", ErrDatabaseError, itemID)
+				return models.ZeroQuantity, fmt.Errorf("%w: stock not updated for item ID %d because it does not track stock", ErrDatabaseError, itemID)
 			}
 			// Other reasons for ErrNoRows from UPDATE (e.g., item exists but tracks_stock is false and was not caught by above)
-			return 0, fmt.Errorf("%w: failed to update stock for item ID %d (item may not track stock or not exist): %v", ErrDatabaseError, itemID, err)
+			return models.ZeroQuantity, fmt.Errorf("%w: failed to update stock for item ID %d (item may not track stock or not exist): %v", ErrDatabaseError, itemID, err)
 		}
-		return 0, fmt.Errorf("%w: updating stock for item ID %d: %v", ErrDatabaseError, itemID, err)
+		return models.ZeroQuantity, fmt.Errorf("%w: updating stock for item ID %d: %v", ErrDatabaseError, itemID, err)
 	}
-	if !newStock.Valid { // Should not happen if RETURNING current_stock and update was successful
-	    return 0, fmt.Errorf("%w: stock update for item ID %d resulted in NULL stock, which is unexpected", ErrDatabaseError, itemID)
+	if newStock == nil { // Should not happen if RETURNING current_stock and update was successful
+	    return models.ZeroQuantity, fmt.Errorf("%w: stock update for item ID %d resulted in NULL stock, which is unexpected", ErrDatabaseError, itemID)
     }
-	return int(newStock.Int64), nil
+	return *newStock, nil
 }
 
-func (r *pricelistRepository) GetItemPriceAndStock(itemID int64) (float64, sql.NullInt64, string, bool, error) {
-	var price float64
-	var currentStock sql.NullInt64
+func (r *pricelistRepository) GetItemPriceAndStock(itemID int64) (models.Money, *models.Quantity, string, bool, error) {
+	var price models.Money
+	var currentStock *models.Quantity
 	var name string
 	var tracksStock bool
-	query := `SELECT name, price, tracks_stock, current_stock FROM pricelist_items WHERE id = $1`
+	query := `SELECT name, price, tracks_stock, current_stock FROM pricelist_items WHERE id = $1 AND deleted_at IS NULL`
 	err := r.db.QueryRow(query, itemID).Scan(&name, &price, &tracksStock, &currentStock)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return 0, sql.NullInt64{}, "", false, ErrNotFound
+			return models.ZeroMoney, nil, "", false, ErrNotFound
 		}
-		return 0, sql.NullInt64{}, "", false, fmt.Errorf("%w: getting price and stock for item ID %d: %v", ErrDatabaseError, itemID, err)
+		return models.ZeroMoney, nil, "", false, fmt.Errorf("%w: getting price and stock for item ID %d: %v", ErrDatabaseError, itemID, err)
 	}
 	return price, currentStock, name, tracksStock, nil
 }
+
+func (r *pricelistRepository) GetItemUnits(itemID int64) (models.ItemUnits, error) {
+	var units models.ItemUnits
+	err := r.db.QueryRow(`SELECT stock_unit, portion_size, purchase_unit, purchase_unit_size FROM pricelist_items WHERE id = $1`, itemID).Scan(
+		&units.StockUnit, &units.PortionSize, &units.PurchaseUnit, &units.PurchaseUnitSize)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ItemUnits{}, ErrNotFound
+		}
+		return models.ItemUnits{}, fmt.Errorf("%w: getting units of item ID %d: %v", ErrDatabaseError, itemID, err)
+	}
+	return units, nil
+}
+
+func (r *pricelistRepository) GetItemTaxClasses(itemIDs []int64) (map[int64]string, error) {
+	classes := make(map[int64]string)
+	if len(itemIDs) == 0 {
+		return classes, nil
+	}
+	rows, err := r.db.Query(`SELECT id, tax_class FROM pricelist_items WHERE id = ANY($1) AND tax_class IS NOT NULL`, pq.Array(itemIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting tax classes of items: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var class string
+		if err := rows.Scan(&id, &class); err != nil {
+			return nil, fmt.Errorf("%w: scanning item tax class: %v", ErrDatabaseError, err)
+		}
+		classes[id] = class
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating item tax classes: %v", ErrDatabaseError, err)
+	}
+	return classes, nil
+}
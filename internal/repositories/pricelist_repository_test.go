@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/database/dbtest"
+	"ps_club_backend/internal/models"
+)
+
+// createTestItem creates a BAR item priced 500 in a new category, tracking stock if stock is not nil.
+func createTestItem(t *testing.T, repo PricelistRepository, db *sql.DB, name string, sku *string, stock *models.Quantity) int64 {
+	t.Helper()
+	categoryID, err := repo.CreateCategory(db, &models.PricelistCategory{Name: name + " category"})
+	if err != nil {
+		t.Fatalf("creating category: %v", err)
+	}
+	item := &models.PricelistItem{
+		CategoryID: categoryID, Name: name, Price: models.NewMoneyFromInt(500), SKU: sku, IsAvailable: true,
+		ItemType: models.ItemTypeBar, TracksStock: stock != nil, CurrentStock: stock, ItemUnits: models.DefaultItemUnits(),
+	}
+	id, err := repo.CreateItem(db, item)
+	if err != nil {
+		t.Fatalf("creating item %s: %v", name, err)
+	}
+	return id
+}
+
+func TestPricelistRepositoryUpdateStock(t *testing.T) {
+	db := dbtest.New(t)
+	repo := NewPricelistRepository(db)
+	ten := models.NewQuantityFromInt(10)
+	tracked := createTestItem(t, repo, db, "Cola", nil, &ten)
+	untracked := createTestItem(t, repo, db, "Hookah", nil, nil)
+
+	tests := []struct {
+		name    string
+		itemID  int64
+		change  int
+		want    int
+		wantErr error
+	}{
+		{name: "takes stock off", itemID: tracked, change: -3, want: 7},
+		{name: "adds stock", itemID: tracked, change: 5, want: 12},
+		{name: "refuses an item that does not track stock", itemID: untracked, change: -1, wantErr: ErrDatabaseError},
+		{name: "refuses an unknown item", itemID: untracked + 100, change: -1, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.UpdateStock(db, tt.itemID, models.NewQuantityFromInt(tt.change))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateStock error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateStock: %v", err)
+			}
+			if want := models.NewQuantityFromInt(tt.want); got.Cmp(want) != 0 {
+				t.Errorf("UpdateStock = %s, want %s", got, want)
+			}
+			_, stock, _, tracksStock, err := repo.GetItemPriceAndStock(tt.itemID)
+			if err != nil {
+				t.Fatalf("GetItemPriceAndStock: %v", err)
+			}
+			if !tracksStock || stock == nil || stock.Cmp(got) != 0 {
+				t.Errorf("stock read back = %v (tracks %v), want %s", stock, tracksStock, got)
+			}
+		})
+	}
+}
+
+func TestPricelistRepositorySKU(t *testing.T) {
+	db := dbtest.New(t)
+	repo := NewPricelistRepository(db)
+	sku := "COLA-05"
+	first := createTestItem(t, repo, db, "Cola", &sku, nil)
+
+	category, err := repo.CreateCategory(db, &models.PricelistCategory{Name: "Soft drinks"})
+	if err != nil {
+		t.Fatalf("creating category: %v", err)
+	}
+	duplicate := &models.PricelistItem{
+		CategoryID: category, Name: "Cola Zero", Price: models.NewMoneyFromInt(500), SKU: &sku,
+		ItemType: models.ItemTypeBar, ItemUnits: models.DefaultItemUnits(),
+	}
+	if _, err := repo.CreateItem(db, duplicate); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("CreateItem with a taken SKU error = %v, want %v", err, ErrDuplicateKey)
+	}
+
+	// A deleted item leaves its SKU free, and cannot be restored while another item has it
+	var adminID int64
+	if err := db.QueryRow(`INSERT INTO users (username, password_hash, role_id) VALUES ('admin', 'x', 1) RETURNING id`).Scan(&adminID); err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	if err := repo.DeleteItem(db, first, adminID, time.Now()); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	if _, err := repo.GetItemByID(first); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetItemByID of a deleted item error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := repo.CreateItem(db, duplicate); err != nil {
+		t.Fatalf("CreateItem with the SKU of a deleted item: %v", err)
+	}
+	if err := repo.RestoreItem(db, first, time.Now()); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("RestoreItem with its SKU taken error = %v, want %v", err, ErrDuplicateKey)
+	}
+}
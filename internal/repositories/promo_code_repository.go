@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// PromoCodeRepository defines the database operations for promo codes and their redemptions.
+type PromoCodeRepository interface {
+	// CreatePromoCode inserts a promo code; a ConstraintError matching ErrDuplicateKey if the
+	// code exists.
+	CreatePromoCode(promo *models.PromoCode) error
+	// GetPromoCodeByID returns a promo code; ErrNotFound if there is none.
+	GetPromoCodeByID(id int64) (*models.PromoCode, error)
+	// GetPromoCodeByCode returns the promo code with code (upper case); ErrNotFound if there
+	// is none.
+	GetPromoCodeByCode(executor SQLExecutor, code string) (*models.PromoCode, error)
+	// GetPromoCodes lists the promo codes by code; with activeOnly only the active ones.
+	GetPromoCodes(activeOnly bool) ([]models.PromoCode, error)
+	// UpdatePromoCode saves the description, discount, issued count and active flag of a
+	// promo code; ErrNotFound if there is none.
+	UpdatePromoCode(promo *models.PromoCode) error
+	// CreateRedemption records the order a promo code was redeemed for.
+	CreateRedemption(executor SQLExecutor, redemption *models.PromoCodeRedemption) error
+}
+
+type promoCodeRepository struct {
+	db *sql.DB
+}
+
+// NewPromoCodeRepository creates a new instance of PromoCodeRepository.
+func NewPromoCodeRepository(db *sql.DB) PromoCodeRepository {
+	return &promoCodeRepository{db: db}
+}
+
+const promoCodeColumns = `id, code, description, discount_percent, issued_count, active, created_by, created_at, updated_at`
+
+func scanPromoCode(row scanner) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := row.Scan(&promo.ID, &promo.Code, &promo.Description, &promo.DiscountPercent, &promo.IssuedCount, &promo.Active,
+		&promo.CreatedBy, &promo.CreatedAt, &promo.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *promoCodeRepository) CreatePromoCode(promo *models.PromoCode) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO promo_codes (code, description, discount_percent, issued_count, active, created_by, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	                      RETURNING id, created_at, updated_at`,
+		promo.Code, promo.Description, promo.DiscountPercent, promo.IssuedCount, promo.Active, promo.CreatedBy, now,
+	).Scan(&promo.ID, &promo.CreatedAt, &promo.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("promo code %s already exists", promo.Code)}
+		}
+		return fmt.Errorf("%w: creating promo code: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) GetPromoCodeByID(id int64) (*models.PromoCode, error) {
+	promo, err := scanPromoCode(r.db.QueryRow(`SELECT `+promoCodeColumns+` FROM promo_codes WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting promo code ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return promo, nil
+}
+
+func (r *promoCodeRepository) GetPromoCodeByCode(executor SQLExecutor, code string) (*models.PromoCode, error) {
+	promo, err := scanPromoCode(executor.QueryRow(`SELECT `+promoCodeColumns+` FROM promo_codes WHERE code = $1`, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting promo code %s: %v", ErrDatabaseError, code, err)
+	}
+	return promo, nil
+}
+
+func (r *promoCodeRepository) GetPromoCodes(activeOnly bool) ([]models.PromoCode, error) {
+	rows, err := r.db.Query(`SELECT `+promoCodeColumns+` FROM promo_codes WHERE active OR NOT $1 ORDER BY code`, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing promo codes: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	promos := []models.PromoCode{}
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning promo code: %v", ErrDatabaseError, err)
+		}
+		promos = append(promos, *promo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating promo codes: %v", ErrDatabaseError, err)
+	}
+	return promos, nil
+}
+
+func (r *promoCodeRepository) UpdatePromoCode(promo *models.PromoCode) error {
+	err := r.db.QueryRow(`UPDATE promo_codes SET description = $2, discount_percent = $3, issued_count = $4, active = $5, updated_at = $6
+	                      WHERE id = $1
+	                      RETURNING code, created_by, created_at, updated_at`,
+		promo.ID, promo.Description, promo.DiscountPercent, promo.IssuedCount, promo.Active, time.Now().UTC(),
+	).Scan(&promo.Code, &promo.CreatedBy, &promo.CreatedAt, &promo.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: updating promo code ID %d: %v", ErrDatabaseError, promo.ID, err)
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) CreateRedemption(executor SQLExecutor, redemption *models.PromoCodeRedemption) error {
+	_, err := executor.Exec(`INSERT INTO promo_code_redemptions (order_id, branch_code, promo_code_id, discount_amount, redeemed_at)
+	                         VALUES ($1, $2, $3, $4, $5)`,
+		redemption.OrderID, redemption.BranchCode, redemption.PromoCodeID, redemption.DiscountAmount, redemption.RedeemedAt)
+	if err != nil {
+		return fmt.Errorf("%w: recording redemption of promo code ID %d for order ID %d: %v", ErrDatabaseError,
+			redemption.PromoCodeID, redemption.OrderID, err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// PushRepository defines the database operations for the push devices, preferences and
+// deliveries of clients.
+type PushRepository interface {
+	// SaveDevice registers the token of device, moving it to device.ClientID if another client
+	// registered it, and sets its ID and timestamps.
+	SaveDevice(device *models.PushDevice) error
+	// GetDevices lists the devices of a client, most recently seen first.
+	GetDevices(clientID int64) ([]models.PushDevice, error)
+	// DeleteDevice removes a device of a client; ErrNotFound if the client has no such device.
+	DeleteDevice(clientID, deviceID int64) error
+	// DeleteDeviceByToken removes the device with token; ErrNotFound if there is none.
+	DeleteDeviceByToken(token string) error
+
+	// GetPreferences returns the saved preferences of a client; ErrNotFound if it saved none.
+	GetPreferences(clientID int64) (*models.PushPreferences, error)
+	// SavePreferences creates or replaces the preferences of a client and sets their UpdatedAt.
+	SavePreferences(preferences *models.PushPreferences) error
+
+	CreateDelivery(delivery *models.PushDelivery) error
+	// WasSent reports whether the push of event eventID was sent to device deviceID.
+	WasSent(eventID, deviceID int64) (bool, error)
+	// GetDeliveries lists the deliveries matching filters, newest first, with their total.
+	GetDeliveries(filters models.PushDeliveryFilters) ([]models.PushDelivery, int, error)
+}
+
+type pushRepository struct {
+	db *sql.DB
+}
+
+// NewPushRepository creates a new instance of PushRepository.
+func NewPushRepository(db *sql.DB) PushRepository {
+	return &pushRepository{db: db}
+}
+
+const pushDeviceColumns = `id, client_id, token, platform, created_at, last_seen_at`
+
+func scanPushDevice(row scanner) (*models.PushDevice, error) {
+	var device models.PushDevice
+	if err := row.Scan(&device.ID, &device.ClientID, &device.Token, &device.Platform, &device.CreatedAt, &device.LastSeenAt); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *pushRepository) SaveDevice(device *models.PushDevice) error {
+	err := r.db.QueryRow(`INSERT INTO client_push_devices (client_id, token, platform, created_at, last_seen_at)
+	                      VALUES ($1, $2, $3, $4, $4)
+	                      ON CONFLICT (token) DO UPDATE SET client_id = EXCLUDED.client_id, platform = EXCLUDED.platform,
+	                                                        last_seen_at = EXCLUDED.last_seen_at
+	                      RETURNING id, created_at, last_seen_at`,
+		device.ClientID, device.Token, device.Platform, time.Now().UTC(),
+	).Scan(&device.ID, &device.CreatedAt, &device.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("%w: saving push device of client ID %d: %v", ErrDatabaseError, device.ClientID, err)
+	}
+	return nil
+}
+
+func (r *pushRepository) GetDevices(clientID int64) ([]models.PushDevice, error) {
+	rows, err := r.db.Query(`SELECT `+pushDeviceColumns+` FROM client_push_devices WHERE client_id = $1 ORDER BY last_seen_at DESC, id DESC`, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing push devices of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	defer rows.Close()
+
+	devices := []models.PushDevice{}
+	for rows.Next() {
+		device, err := scanPushDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning push device: %v", ErrDatabaseError, err)
+		}
+		devices = append(devices, *device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating push devices: %v", ErrDatabaseError, err)
+	}
+	return devices, nil
+}
+
+func (r *pushRepository) DeleteDevice(clientID, deviceID int64) error {
+	result, err := r.db.Exec(`DELETE FROM client_push_devices WHERE id = $1 AND client_id = $2`, deviceID, clientID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting push device ID %d: %v", ErrDatabaseError, deviceID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pushRepository) DeleteDeviceByToken(token string) error {
+	result, err := r.db.Exec(`DELETE FROM client_push_devices WHERE token = $1`, token)
+	if err != nil {
+		return fmt.Errorf("%w: deleting push device by token: %v", ErrDatabaseError, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pushRepository) GetPreferences(clientID int64) (*models.PushPreferences, error) {
+	preferences := models.PushPreferences{ClientID: clientID}
+	var updatedAt time.Time
+	err := r.db.QueryRow(`SELECT booking_confirmations, table_ready, loyalty_milestones, updated_at
+	                      FROM client_push_preferences WHERE client_id = $1`, clientID,
+	).Scan(&preferences.BookingConfirmations, &preferences.TableReady, &preferences.LoyaltyMilestones, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting push preferences of client ID %d: %v", ErrDatabaseError, clientID, err)
+	}
+	preferences.UpdatedAt = &updatedAt
+	return &preferences, nil
+}
+
+func (r *pushRepository) SavePreferences(preferences *models.PushPreferences) error {
+	var updatedAt time.Time
+	err := r.db.QueryRow(`INSERT INTO client_push_preferences (client_id, booking_confirmations, table_ready, loyalty_milestones, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5)
+	                      ON CONFLICT (client_id) DO UPDATE SET booking_confirmations = EXCLUDED.booking_confirmations,
+	                          table_ready = EXCLUDED.table_ready, loyalty_milestones = EXCLUDED.loyalty_milestones,
+	                          updated_at = EXCLUDED.updated_at
+	                      RETURNING updated_at`,
+		preferences.ClientID, preferences.BookingConfirmations, preferences.TableReady, preferences.LoyaltyMilestones, time.Now().UTC(),
+	).Scan(&updatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: saving push preferences of client ID %d: %v", ErrDatabaseError, preferences.ClientID, err)
+	}
+	preferences.UpdatedAt = &updatedAt
+	return nil
+}
+
+const pushDeliveryColumns = `id, client_id, device_id, event_id, kind, title, body, status, error, created_at`
+
+func scanPushDelivery(row scanner) (*models.PushDelivery, error) {
+	var delivery models.PushDelivery
+	err := row.Scan(&delivery.ID, &delivery.ClientID, &delivery.DeviceID, &delivery.EventID, &delivery.Kind, &delivery.Title,
+		&delivery.Body, &delivery.Status, &delivery.Error, &delivery.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *pushRepository) CreateDelivery(delivery *models.PushDelivery) error {
+	err := r.db.QueryRow(`INSERT INTO push_deliveries (client_id, device_id, event_id, kind, title, body, status, error, created_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	                      RETURNING id, created_at`,
+		delivery.ClientID, delivery.DeviceID, delivery.EventID, delivery.Kind, delivery.Title, delivery.Body, delivery.Status,
+		delivery.Error, time.Now().UTC(),
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: recording push delivery to client ID %d: %v", ErrDatabaseError, delivery.ClientID, err)
+	}
+	return nil
+}
+
+func (r *pushRepository) WasSent(eventID, deviceID int64) (bool, error) {
+	var sent bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM push_deliveries WHERE event_id = $1 AND device_id = $2 AND status = $3)`,
+		eventID, deviceID, models.PushDeliverySent).Scan(&sent)
+	if err != nil {
+		return false, fmt.Errorf("%w: checking push delivery of event ID %d: %v", ErrDatabaseError, eventID, err)
+	}
+	return sent, nil
+}
+
+func (r *pushRepository) GetDeliveries(filters models.PushDeliveryFilters) ([]models.PushDelivery, int, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	argCounter := 1
+
+	if filters.ClientID != nil {
+		conditions = append(conditions, fmt.Sprintf("client_id = $%d", argCounter))
+		args = append(args, *filters.ClientID)
+		argCounter++
+	}
+	if filters.Kind != nil {
+		conditions = append(conditions, fmt.Sprintf("kind = $%d", argCounter))
+		args = append(args, *filters.Kind)
+		argCounter++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCounter))
+		args = append(args, *filters.Status)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argCounter))
+		args = append(args, *filters.To)
+		argCounter++
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM push_deliveries WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: counting push deliveries: %v", ErrDatabaseError, err)
+	}
+
+	query := fmt.Sprintf(`SELECT `+pushDeliveryColumns+` FROM push_deliveries WHERE `+where+`
+	                      ORDER BY created_at DESC, id DESC
+	                      LIMIT $%d OFFSET $%d`, argCounter, argCounter+1)
+	args = append(args, filters.PageSize, (filters.Page-1)*filters.PageSize)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: listing push deliveries: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.PushDelivery{}
+	for rows.Next() {
+		delivery, err := scanPushDelivery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: scanning push delivery: %v", ErrDatabaseError, err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: iterating push deliveries: %v", ErrDatabaseError, err)
+	}
+	return deliveries, total, nil
+}
@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// QuickSaleRepository defines the database operations for quick-sale presets.
+type QuickSaleRepository interface {
+	// CreatePreset returns ErrDuplicateKey if the branch already has a preset with the name.
+	CreatePreset(executor SQLExecutor, preset *models.QuickSalePreset) error
+	// GetPresetByID returns a preset with its items; ErrNotFound if there is none.
+	GetPresetByID(id int64) (*models.QuickSalePreset, error)
+	// GetPresets returns the presets of a branch with their items, by sort order and name.
+	GetPresets(branchCode string, activeOnly bool) ([]models.QuickSalePreset, error)
+	// UpdatePreset updates the name, sort order and status of a preset. Unless version is 0
+	// the preset must still have it; ErrVersionConflict if it changed in the meantime and
+	// ErrDuplicateKey if the branch has another preset with the name.
+	UpdatePreset(executor SQLExecutor, preset *models.QuickSalePreset, version int) error
+	// ReplacePresetItems replaces the items of a preset, keeping their order.
+	ReplacePresetItems(executor SQLExecutor, presetID int64, items []models.QuickSalePresetItem) error
+	DeletePreset(id int64) error
+}
+
+type quickSaleRepository struct {
+	db *sql.DB
+}
+
+// NewQuickSaleRepository creates a new instance of QuickSaleRepository.
+func NewQuickSaleRepository(db *sql.DB) QuickSaleRepository {
+	return &quickSaleRepository{db: db}
+}
+
+const quickSalePresetColumns = `id, branch_code, name, sort_order, is_active, created_at, updated_at, version`
+
+func scanQuickSalePreset(row scanner) (*models.QuickSalePreset, error) {
+	var preset models.QuickSalePreset
+	err := row.Scan(&preset.ID, &preset.BranchCode, &preset.Name, &preset.SortOrder, &preset.IsActive,
+		&preset.CreatedAt, &preset.UpdatedAt, &preset.Version)
+	if err != nil {
+		return nil, err
+	}
+	preset.Items = []models.QuickSalePresetItem{}
+	return &preset, nil
+}
+
+func (r *quickSaleRepository) CreatePreset(executor SQLExecutor, preset *models.QuickSalePreset) error {
+	now := time.Now().UTC()
+	err := executor.QueryRow(`INSERT INTO quick_sale_presets (branch_code, name, sort_order, is_active, created_at, updated_at)
+	                          VALUES ($1, $2, $3, $4, $5, $5)
+	                          RETURNING id, created_at, updated_at, version`,
+		preset.BranchCode, preset.Name, preset.SortOrder, preset.IsActive, now,
+	).Scan(&preset.ID, &preset.CreatedAt, &preset.UpdatedAt, &preset.Version)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: quick-sale preset '%s' already exists", ErrDuplicateKey, preset.Name)
+		}
+		return fmt.Errorf("%w: creating quick-sale preset: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *quickSaleRepository) GetPresetByID(id int64) (*models.QuickSalePreset, error) {
+	preset, err := scanQuickSalePreset(r.db.QueryRow(`SELECT `+quickSalePresetColumns+` FROM quick_sale_presets WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting quick-sale preset ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if err := r.loadPresetItems([]*models.QuickSalePreset{preset}); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+func (r *quickSaleRepository) GetPresets(branchCode string, activeOnly bool) ([]models.QuickSalePreset, error) {
+	rows, err := r.db.Query(`SELECT `+quickSalePresetColumns+` FROM quick_sale_presets
+	                         WHERE branch_code = $1 AND (is_active OR NOT $2)
+	                         ORDER BY sort_order, name, id`, branchCode, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing quick-sale presets: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	presets := []models.QuickSalePreset{}
+	for rows.Next() {
+		preset, err := scanQuickSalePreset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning quick-sale preset: %v", ErrDatabaseError, err)
+		}
+		presets = append(presets, *preset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating quick-sale presets: %v", ErrDatabaseError, err)
+	}
+
+	pointers := make([]*models.QuickSalePreset, len(presets))
+	for i := range presets {
+		pointers[i] = &presets[i]
+	}
+	if err := r.loadPresetItems(pointers); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// loadPresetItems sets the items of presets, with the current names and prices of the
+// pricelist items, and their total prices.
+func (r *quickSaleRepository) loadPresetItems(presets []*models.QuickSalePreset) error {
+	if len(presets) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.QuickSalePreset, len(presets))
+	ids := make([]int64, len(presets))
+	for i, preset := range presets {
+		byID[preset.ID] = preset
+		ids[i] = preset.ID
+	}
+	rows, err := r.db.Query(`SELECT qi.preset_id, qi.pricelist_item_id, pi.name, pi.price, qi.quantity
+	                         FROM quick_sale_preset_items qi
+	                         JOIN pricelist_items pi ON qi.pricelist_item_id = pi.id
+	                         WHERE qi.preset_id = ANY($1)
+	                         ORDER BY qi.preset_id, qi.position`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("%w: listing quick-sale preset items: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var presetID int64
+		var item models.QuickSalePresetItem
+		if err := rows.Scan(&presetID, &item.PricelistItemID, &item.ItemName, &item.UnitPrice, &item.Quantity); err != nil {
+			return fmt.Errorf("%w: scanning quick-sale preset item: %v", ErrDatabaseError, err)
+		}
+		preset := byID[presetID]
+		preset.Items = append(preset.Items, item)
+		preset.TotalPrice = preset.TotalPrice.Add(item.UnitPrice.MulInt(item.Quantity))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: iterating quick-sale preset items: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *quickSaleRepository) UpdatePreset(executor SQLExecutor, preset *models.QuickSalePreset, version int) error {
+	err := executor.QueryRow(`UPDATE quick_sale_presets
+	                          SET name = $2, sort_order = $3, is_active = $4, updated_at = $5, version = version + 1
+	                          WHERE id = $1 AND ($6 = 0 OR version = $6)
+	                          RETURNING updated_at, version`,
+		preset.ID, preset.Name, preset.SortOrder, preset.IsActive, time.Now().UTC(), version,
+	).Scan(&preset.UpdatedAt, &preset.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(executor, "quick_sale_presets", preset.ID)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: quick-sale preset '%s' already exists", ErrDuplicateKey, preset.Name)
+		}
+		return fmt.Errorf("%w: updating quick-sale preset ID %d: %v", ErrDatabaseError, preset.ID, err)
+	}
+	return nil
+}
+
+func (r *quickSaleRepository) ReplacePresetItems(executor SQLExecutor, presetID int64, items []models.QuickSalePresetItem) error {
+	if _, err := executor.Exec(`DELETE FROM quick_sale_preset_items WHERE preset_id = $1`, presetID); err != nil {
+		return fmt.Errorf("%w: clearing items of quick-sale preset ID %d: %v", ErrDatabaseError, presetID, err)
+	}
+	for position, item := range items {
+		_, err := executor.Exec(`INSERT INTO quick_sale_preset_items (preset_id, pricelist_item_id, quantity, position)
+		                         VALUES ($1, $2, $3, $4)`, presetID, item.PricelistItemID, item.Quantity, position)
+		if err != nil {
+			return fmt.Errorf("%w: adding item %d to quick-sale preset ID %d: %v", ErrDatabaseError, item.PricelistItemID, presetID, err)
+		}
+	}
+	return nil
+}
+
+func (r *quickSaleRepository) DeletePreset(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM quick_sale_presets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting quick-sale preset ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for quick-sale preset ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ReferenceRepository counts the records referencing a record through foreign keys, so a
+// delete can be checked before it is tried.
+type ReferenceRepository interface {
+	// RecordExists reports whether table has a record with the ID.
+	RecordExists(table string, id int64) (bool, error)
+	// CountReferences returns, per foreign key referencing table, how many records reference
+	// the record with the ID. Foreign keys without referencing records are left out.
+	CountReferences(table string, id int64) ([]models.RecordReference, error)
+}
+
+type referenceRepository struct {
+	db *sql.DB
+}
+
+// NewReferenceRepository creates a new instance of ReferenceRepository.
+func NewReferenceRepository(db *sql.DB) ReferenceRepository {
+	return &referenceRepository{db: db}
+}
+
+func (r *referenceRepository) RecordExists(table string, id int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM "+pq.QuoteIdentifier(table)+" WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%w: checking %s ID %d: %v", ErrDatabaseError, table, id, err)
+	}
+	return exists, nil
+}
+
+// foreignKey is a single column foreign key referencing a table.
+type foreignKey struct {
+	table, column string
+	onDelete      string
+}
+
+func (r *referenceRepository) CountReferences(table string, id int64) ([]models.RecordReference, error) {
+	keys, err := r.foreignKeysTo(table)
+	if err != nil {
+		return nil, err
+	}
+	references := []models.RecordReference{}
+	for _, key := range keys {
+		var count int64
+		query := "SELECT COUNT(*) FROM " + pq.QuoteIdentifier(key.table) + " WHERE " + pq.QuoteIdentifier(key.column) + " = $1"
+		if err := r.db.QueryRow(query, id).Scan(&count); err != nil {
+			return nil, fmt.Errorf("%w: counting %s referencing %s ID %d: %v", ErrDatabaseError, key.table, table, id, err)
+		}
+		if count > 0 {
+			references = append(references, models.RecordReference{Table: key.table, Column: key.column, Count: count, OnDelete: key.onDelete})
+		}
+	}
+	return references, nil
+}
+
+// foreignKeysTo lists the single column foreign keys referencing table. The copies of the
+// foreign keys of a partitioned table on its partitions are left out.
+func (r *referenceRepository) foreignKeysTo(table string) ([]foreignKey, error) {
+	rows, err := r.db.Query(`
+		SELECT cl.relname, a.attname, c.confdeltype
+		FROM pg_constraint c
+		JOIN pg_class cl ON cl.oid = c.conrelid
+		JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+		WHERE c.contype = 'f' AND c.confrelid = to_regclass($1) AND c.conparentid = 0
+		  AND array_length(c.conkey, 1) = 1
+		ORDER BY cl.relname, a.attname`, table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing foreign keys to %s: %v", ErrDatabaseError, table, err)
+	}
+	defer rows.Close()
+
+	keys := []foreignKey{}
+	for rows.Next() {
+		var key foreignKey
+		var action string
+		if err := rows.Scan(&key.table, &key.column, &action); err != nil {
+			return nil, fmt.Errorf("%w: scanning foreign key to %s: %v", ErrDatabaseError, table, err)
+		}
+		switch action {
+		case "c":
+			key.onDelete = models.ReferenceOnDeleteCascade
+		case "n", "d":
+			key.onDelete = models.ReferenceOnDeleteSetNull
+		default: // "a" (no action) and "r" (restrict)
+			key.onDelete = models.ReferenceOnDeleteRestrict
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating foreign keys to %s: %v", ErrDatabaseError, table, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ReorderPointRepository defines the database operations for the reorder points of stock-tracked items.
+type ReorderPointRepository interface {
+	// GetItemConsumption returns the stock-tracked items with how much of each was consumed, by
+	// movements of consumptionTypes, since the given time. Only the item and consumption fields are set.
+	GetItemConsumption(since time.Time, consumptionTypes []string) ([]models.ReorderPoint, error)
+	// SaveReorderPoint inserts or replaces the reorder point of an item. A nil AppliedAt keeps
+	// when it was last applied.
+	SaveReorderPoint(executor SQLExecutor, point *models.ReorderPoint) error
+	// DeleteReorderPointsCalculatedBefore deletes the reorder points of the items that no longer
+	// track stock, which were not recalculated since cutoff.
+	DeleteReorderPointsCalculatedBefore(executor SQLExecutor, cutoff time.Time) error
+	// SetLowStockThreshold sets the low stock threshold of an item.
+	SetLowStockThreshold(executor SQLExecutor, itemID int64, threshold models.Quantity) error
+	// GetReorderPoints lists the reorder points by item name.
+	GetReorderPoints() ([]models.ReorderPoint, error)
+}
+
+type reorderPointRepository struct {
+	db *sql.DB
+}
+
+// NewReorderPointRepository creates a new instance of ReorderPointRepository.
+func NewReorderPointRepository(db *sql.DB) ReorderPointRepository {
+	return &reorderPointRepository{db: db}
+}
+
+func (r *reorderPointRepository) GetItemConsumption(since time.Time, consumptionTypes []string) ([]models.ReorderPoint, error) {
+	rows, err := r.db.Query(`SELECT pi.id, pi.name, pi.stock_unit, pi.current_stock, pi.low_stock_threshold,
+	                                COALESCE(-SUM(im.quantity_changed), 0)
+	                         FROM pricelist_items pi
+	                         LEFT JOIN inventory_movements im ON im.pricelist_item_id = pi.id
+	                              AND im.movement_date >= $1 AND im.movement_type = ANY($2)
+	                         WHERE pi.tracks_stock = TRUE AND pi.deleted_at IS NULL
+	                         GROUP BY pi.id
+	                         ORDER BY pi.id`, since, pq.Array(consumptionTypes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting item consumption: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	points := []models.ReorderPoint{}
+	for rows.Next() {
+		var point models.ReorderPoint
+		if err := rows.Scan(&point.PricelistItemID, &point.ItemName, &point.StockUnit, &point.CurrentStock,
+			&point.LowStockThreshold, &point.Consumed); err != nil {
+			return nil, fmt.Errorf("%w: scanning item consumption: %v", ErrDatabaseError, err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating item consumption: %v", ErrDatabaseError, err)
+	}
+	return points, nil
+}
+
+func (r *reorderPointRepository) SaveReorderPoint(executor SQLExecutor, point *models.ReorderPoint) error {
+	_, err := executor.Exec(`INSERT INTO item_reorder_points
+	                         (pricelist_item_id, consumed, lookback_days, average_daily_consumption, lead_time_days, safety_days,
+	                          cover_days, suggested_threshold, suggested_reorder_quantity, applied_at, calculated_at)
+	                         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	                         ON CONFLICT (pricelist_item_id) DO UPDATE
+	                         SET consumed = EXCLUDED.consumed, lookback_days = EXCLUDED.lookback_days,
+	                             average_daily_consumption = EXCLUDED.average_daily_consumption,
+	                             lead_time_days = EXCLUDED.lead_time_days, safety_days = EXCLUDED.safety_days,
+	                             cover_days = EXCLUDED.cover_days, suggested_threshold = EXCLUDED.suggested_threshold,
+	                             suggested_reorder_quantity = EXCLUDED.suggested_reorder_quantity,
+	                             applied_at = COALESCE(EXCLUDED.applied_at, item_reorder_points.applied_at),
+	                             calculated_at = EXCLUDED.calculated_at`,
+		point.PricelistItemID, point.Consumed, point.LookbackDays, point.AverageDailyConsumption, point.LeadTimeDays,
+		point.SafetyDays, point.CoverDays, point.SuggestedThreshold, point.SuggestedReorderQuantity, point.AppliedAt,
+		point.CalculatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: saving reorder point of item ID %d: %v", ErrDatabaseError, point.PricelistItemID, err)
+	}
+	return nil
+}
+
+func (r *reorderPointRepository) DeleteReorderPointsCalculatedBefore(executor SQLExecutor, cutoff time.Time) error {
+	if _, err := executor.Exec(`DELETE FROM item_reorder_points WHERE calculated_at < $1`, cutoff); err != nil {
+		return fmt.Errorf("%w: deleting stale reorder points: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *reorderPointRepository) SetLowStockThreshold(executor SQLExecutor, itemID int64, threshold models.Quantity) error {
+	result, err := executor.Exec(`UPDATE pricelist_items
+	                              SET low_stock_threshold = $2, updated_at = $3, version = version + 1
+	                              WHERE id = $1`, itemID, threshold, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("%w: setting low stock threshold of item ID %d: %v", ErrDatabaseError, itemID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for item ID %d: %v", ErrDatabaseError, itemID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *reorderPointRepository) GetReorderPoints() ([]models.ReorderPoint, error) {
+	rows, err := r.db.Query(`SELECT rp.pricelist_item_id, pi.name, pi.stock_unit, pi.current_stock, pi.low_stock_threshold, rp.consumed,
+	                                rp.lookback_days, rp.average_daily_consumption, rp.lead_time_days, rp.safety_days,
+	                                rp.cover_days, rp.suggested_threshold, rp.suggested_reorder_quantity, rp.applied_at,
+	                                rp.calculated_at
+	                         FROM item_reorder_points rp
+	                         JOIN pricelist_items pi ON pi.id = rp.pricelist_item_id
+	                         ORDER BY pi.name, pi.id`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing reorder points: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	points := []models.ReorderPoint{}
+	for rows.Next() {
+		var point models.ReorderPoint
+		if err := rows.Scan(&point.PricelistItemID, &point.ItemName, &point.StockUnit, &point.CurrentStock, &point.LowStockThreshold,
+			&point.Consumed, &point.LookbackDays, &point.AverageDailyConsumption, &point.LeadTimeDays, &point.SafetyDays,
+			&point.CoverDays, &point.SuggestedThreshold, &point.SuggestedReorderQuantity, &point.AppliedAt,
+			&point.CalculatedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning reorder point: %v", ErrDatabaseError, err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating reorder points: %v", ErrDatabaseError, err)
+	}
+	return points, nil
+}
@@ -0,0 +1,478 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// ReportRepository defines the interface for aggregate queries used by dashboards and reports.
+type ReportRepository interface {
+	GetDashboardSummary(now time.Time) (*models.DashboardSummary, error)
+	// GetActivityEvents returns recorded domain events for the activity feed, newest first.
+	GetActivityEvents(filter models.ActivityFilter) ([]models.DomainEvent, error)
+	// GetTaxSummary totals the sold order items of filter per period (in club time), tax
+	// class and rate, latest period and highest rate first.
+	GetTaxSummary(filter models.TaxReportFilter) ([]models.TaxReportItem, error)
+	// GetSalesSeries counts and totals the orders of filter per day or hour, in bucket order;
+	// buckets without orders are left out.
+	GetSalesSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error)
+	// GetSessionSeries counts and totals the table sessions stopped in the range of filter per
+	// day or hour, likewise.
+	GetSessionSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error)
+	// GetHourlyUtilization returns the tables in use and the staff members on shift in each
+	// hour from from to to, which are whole hours. A table is in use during a table session,
+	// still running ones until now, or a completed or active booking without a session.
+	GetHourlyUtilization(from, to time.Time) ([]models.HourlyUtilization, error)
+	// GetVoids returns the voids of a branch from from to to, latest first, with the name of
+	// the staff member who voided and the clock-in and clock-out of their shift.
+	GetVoids(branchCode string, from, to time.Time) ([]models.OrderVoid, error)
+	// GetDiscountsByPeriod counts and totals the orders of filter and their discounts per
+	// period (in club time), latest first; periods without orders are left out.
+	GetDiscountsByPeriod(filter models.DiscountReportFilter) ([]models.DiscountTotal, error)
+	// GetDiscountsByStaff does the same per staff member who took the orders, most discounts
+	// first.
+	GetDiscountsByStaff(filter models.DiscountReportFilter) ([]models.DiscountTotal, error)
+	// GetPromoCodeUsage totals the redemptions of each promo code on the orders of filter,
+	// with its redemptions ever, most redemptions first. Inactive codes without redemptions in
+	// the range are left out.
+	GetPromoCodeUsage(filter models.DiscountReportFilter) ([]models.PromoCodeUsage, error)
+	// GetSalesBySource counts and totals the orders of filter per source; sources without
+	// orders are left out.
+	GetSalesBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error)
+	// GetBookingsBySource counts and totals the prices of the bookings starting in the range of
+	// filter, leaving out those cancelled or deleted, per source likewise.
+	GetBookingsBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error)
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+// NewReportRepository creates a new instance of ReportRepository.
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// GetDashboardSummary computes the dashboard metrics as of now. Day, week and month
+// buckets are computed in the club timezone (weeks start on Monday) and compared as
+// half-open UTC intervals, so DST days are 23 or 25 hours long as expected.
+func (r *reportRepository) GetDashboardSummary(now time.Time) (*models.DashboardSummary, error) {
+	summary := &models.DashboardSummary{}
+
+	startOfDay := utils.StartOfDay(now)
+	endOfDay := utils.AddClubDays(now, 1)
+	startOfWeek := utils.StartOfWeek(now)
+	endOfWeek := utils.AddClubDays(startOfWeek, 7)
+	startOfMonth := utils.StartOfMonth(now)
+	endOfMonth := utils.StartOfMonth(startOfMonth.AddDate(0, 1, 0))
+	upcomingEndTime := now.Add(24 * time.Hour)
+
+	const salesQuery = `SELECT COALESCE(SUM(final_amount), 0) FROM orders
+	                    WHERE status = 'completed' AND order_time >= $1 AND order_time < $2 AND business_date BETWEEN $3 AND $4`
+	queries := []struct {
+		name  string
+		query string
+		args  []interface{}
+		dest  interface{}
+	}{
+		{"active bookings count", `SELECT COUNT(*) FROM bookings WHERE deleted_at IS NULL AND status = 'active' AND start_time <= $1 AND end_time >= $1`, []interface{}{now}, &summary.ActiveBookingsCount},
+		{"pending orders count", `SELECT COUNT(*) FROM orders WHERE status = 'pending' OR status = 'preparing'`, nil, &summary.PendingOrdersCount},
+		{"total sales today", salesQuery, []interface{}{startOfDay.UTC(), endOfDay.UTC(), businessDateFrom(startOfDay), businessDateTo(endOfDay)}, &summary.TotalSalesToday},
+		{"total sales this week", salesQuery, []interface{}{startOfWeek.UTC(), endOfWeek.UTC(), businessDateFrom(startOfWeek), businessDateTo(endOfWeek)}, &summary.TotalSalesThisWeek},
+		{"total sales this month", salesQuery, []interface{}{startOfMonth.UTC(), endOfMonth.UTC(), businessDateFrom(startOfMonth), businessDateTo(endOfMonth)}, &summary.TotalSalesThisMonth},
+		{"low stock items count", `SELECT COUNT(*) FROM pricelist_items WHERE current_stock IS NOT NULL AND low_stock_threshold IS NOT NULL AND current_stock <= low_stock_threshold AND is_available = TRUE AND deleted_at IS NULL`, nil, &summary.LowStockItemsCount},
+		{"upcoming bookings count", `SELECT COUNT(*) FROM bookings WHERE deleted_at IS NULL AND status = 'confirmed' AND start_time BETWEEN $1 AND $2`, []interface{}{now, upcomingEndTime}, &summary.UpcomingBookingsCount},
+	}
+	for _, q := range queries {
+		if err := r.db.QueryRow(q.query, q.args...).Scan(q.dest); err != nil {
+			return nil, fmt.Errorf("%w: getting %s: %v", ErrDatabaseError, q.name, err)
+		}
+	}
+	return summary, nil
+}
+
+// activityEventsQuery builds the activity feed query for filter.
+func activityEventsQuery(filter models.ActivityFilter) (string, []interface{}) {
+	var afterCreatedAt *time.Time
+	var afterID *int64
+	if filter.After != nil {
+		afterCreatedAt, afterID = &filter.After.Time, &filter.After.ID
+	}
+
+	query := `SELECT id, event_type, aggregate_type, aggregate_id, payload, created_at
+	          FROM outbox_events
+	          WHERE event_type = ANY($1)
+	            AND (event_type <> $2 OR (payload->>'discount_amount')::numeric * 100 >= $3 * NULLIF((payload->>'total_amount')::numeric, 0))
+	            AND ($4::timestamptz IS NULL OR (created_at, id) < ($4::timestamptz, $5::bigint))
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT $6`
+	return query, []interface{}{pq.Array(filter.EventTypes), filter.DiscountEventType, filter.LargeDiscountPercent, afterCreatedAt, afterID, filter.Limit}
+}
+
+// GetActivityEvents reads the activity feed from the outbox table, which keeps
+// published events for the relay retention period. Discount events are filtered
+// on their payload, so only discounts of at least LargeDiscountPercent are returned.
+func (r *reportRepository) GetActivityEvents(filter models.ActivityFilter) ([]models.DomainEvent, error) {
+	query, args := activityEventsQuery(filter)
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: querying activity events: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	events := []models.DomainEvent{}
+	for rows.Next() {
+		var event models.DomainEvent
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.EventType, &event.AggregateType, &event.AggregateID, &payload, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning activity event: %v", ErrDatabaseError, err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating activity events: %v", ErrDatabaseError, err)
+	}
+	return events, nil
+}
+
+// taxPeriodFormats are the TO_CHAR formats of the periods of the tax summary, like the sales report.
+var taxPeriodFormats = map[string]string{
+	"daily":   "YYYY-MM-DD",
+	"weekly":  "IYYY-IW", // ISO year and week
+	"monthly": "YYYY-MM",
+}
+
+func (r *reportRepository) GetTaxSummary(filter models.TaxReportFilter) ([]models.TaxReportItem, error) {
+	format, ok := taxPeriodFormats[filter.Period]
+	if !ok {
+		format = taxPeriodFormats["daily"]
+	}
+	// The net amount is the line total after its discount share, without tax included in it
+	rows, err := r.db.Query(`
+		SELECT TO_CHAR(o.order_time AT TIME ZONE $1, $2) AS period, oi.tax_class, oi.tax_rate,
+		       SUM(oi.total_price - oi.discount_amount - CASE WHEN o.prices_include_tax THEN oi.tax_amount ELSE 0 END),
+		       SUM(oi.tax_amount), COUNT(*)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.branch_code = $3 AND o.status = ANY($4) AND o.order_time >= $5 AND o.order_time < $6
+		  AND o.business_date BETWEEN $7 AND $8
+		GROUP BY 1, 2, 3
+		ORDER BY 1 DESC, 3 DESC NULLS LAST, 2`,
+		utils.ClubLocation().String(), format, filter.BranchCode, pq.Array(filter.Statuses), filter.Start, filter.End,
+		businessDateFrom(filter.Start), businessDateTo(filter.End))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting tax summary: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	items := []models.TaxReportItem{}
+	for rows.Next() {
+		var item models.TaxReportItem
+		if err := rows.Scan(&item.Period, &item.TaxClass, &item.TaxRate, &item.NetAmount, &item.TaxAmount, &item.ItemCount); err != nil {
+			return nil, fmt.Errorf("%w: scanning tax summary: %v", ErrDatabaseError, err)
+		}
+		item.GrossAmount = item.NetAmount.Add(item.TaxAmount)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating tax summary: %v", ErrDatabaseError, err)
+	}
+	return items, nil
+}
+
+// seriesBucketFormat returns the TO_CHAR format of the buckets of filter, matching
+// models.SeriesBucket.
+func seriesBucketFormat(filter models.SeriesFilter) string {
+	if filter.Hourly {
+		return `YYYY-MM-DD"T"HH24:00`
+	}
+	return "YYYY-MM-DD"
+}
+
+func (r *reportRepository) GetSalesSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error) {
+	return r.querySeries("getting sales series", `
+		SELECT TO_CHAR(order_time AT TIME ZONE $1, $2), COUNT(*), COALESCE(SUM(final_amount), 0)
+		FROM (SELECT final_amount, branch_code, status, order_time, business_date FROM orders
+		      UNION ALL
+		      SELECT final_amount, branch_code, status, order_time, business_date FROM orders_archive) o
+		WHERE branch_code = $3 AND status = ANY($4) AND order_time >= $5 AND order_time < $6
+		  AND business_date BETWEEN $7 AND $8
+		GROUP BY 1
+		ORDER BY 1`,
+		utils.ClubLocation().String(), seriesBucketFormat(filter), filter.BranchCode, pq.Array(filter.Statuses), filter.Start, filter.End,
+		businessDateFrom(filter.Start), businessDateTo(filter.End))
+}
+
+func (r *reportRepository) GetSessionSeries(filter models.SeriesFilter) ([]models.SeriesBucket, error) {
+	return r.querySeries("getting table session series", `
+		SELECT TO_CHAR(stopped_at AT TIME ZONE $1, $2), COUNT(*), COALESCE(SUM(total_amount), 0)
+		FROM table_sessions
+		WHERE status = $3 AND stopped_at >= $4 AND stopped_at < $5
+		GROUP BY 1
+		ORDER BY 1`,
+		utils.ClubLocation().String(), seriesBucketFormat(filter), models.TableSessionStatusStopped, filter.Start, filter.End)
+}
+
+// querySeries runs a query selecting the bucket, count and amount of a metric series.
+func (r *reportRepository) querySeries(action, query string, args ...interface{}) ([]models.SeriesBucket, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	defer rows.Close()
+
+	buckets := []models.SeriesBucket{}
+	for rows.Next() {
+		var bucket models.SeriesBucket
+		if err := rows.Scan(&bucket.Bucket, &bucket.Count, &bucket.Amount); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	return buckets, nil
+}
+
+func (r *reportRepository) GetHourlyUtilization(from, to time.Time) ([]models.HourlyUtilization, error) {
+	rows, err := r.db.Query(`
+		WITH hours AS (
+			SELECT h FROM generate_series($1::timestamptz, $2::timestamptz - INTERVAL '1 hour', INTERVAL '1 hour') h
+		), table_use AS (
+			SELECT started_at AS start_time, COALESCE(stopped_at, NOW()) AS end_time
+			FROM table_sessions
+			WHERE started_at < $2 AND COALESCE(stopped_at, NOW()) > $1
+			UNION ALL
+			SELECT b.start_time, b.end_time
+			FROM bookings b
+			WHERE b.status IN ('completed', 'active') AND b.deleted_at IS NULL AND b.start_time < $2 AND b.end_time > $1
+			  AND NOT EXISTS (SELECT 1 FROM table_sessions ts WHERE ts.booking_id = b.id)
+		), staff_on_shift AS (
+			SELECT start_time, end_time FROM shifts WHERE start_time < $2 AND end_time > $1
+		)
+		SELECT hours.h,
+		       COALESCE((SELECT SUM(EXTRACT(EPOCH FROM LEAST(u.end_time, hours.h + INTERVAL '1 hour') - GREATEST(u.start_time, hours.h)))
+		                 FROM table_use u WHERE u.start_time < hours.h + INTERVAL '1 hour' AND u.end_time > hours.h), 0) / 3600.0,
+		       COALESCE((SELECT SUM(EXTRACT(EPOCH FROM LEAST(s.end_time, hours.h + INTERVAL '1 hour') - GREATEST(s.start_time, hours.h)))
+		                 FROM staff_on_shift s WHERE s.start_time < hours.h + INTERVAL '1 hour' AND s.end_time > hours.h), 0) / 3600.0
+		FROM hours
+		ORDER BY hours.h`,
+		from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting hourly utilization: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	hours := []models.HourlyUtilization{}
+	for rows.Next() {
+		var hour models.HourlyUtilization
+		if err := rows.Scan(&hour.Hour, &hour.OccupiedTables, &hour.StaffOnShift); err != nil {
+			return nil, fmt.Errorf("%w: scanning hourly utilization: %v", ErrDatabaseError, err)
+		}
+		hours = append(hours, hour)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating hourly utilization: %v", ErrDatabaseError, err)
+	}
+	return hours, nil
+}
+
+func (r *reportRepository) GetVoids(branchCode string, from, to time.Time) ([]models.OrderVoid, error) {
+	rows, err := r.db.Query(`SELECT v.id, v.branch_code, v.order_id, v.order_number, v.order_item_id, v.pricelist_item_id, v.quantity,
+	                                v.amount, v.reason, v.note, v.order_staff_id, v.voided_by, v.time_clock_entry_id, v.voided_at,
+	                                COALESCE(NULLIF(u.full_name, ''), u.username), e.clock_in_at, e.clock_out_at
+	                         FROM order_voids v
+	                         LEFT JOIN users u ON v.voided_by = u.id
+	                         LEFT JOIN time_clock_entries e ON v.time_clock_entry_id = e.id
+	                         WHERE v.branch_code = $1 AND v.voided_at >= $2 AND v.voided_at < $3
+	                         ORDER BY v.voided_at DESC, v.id DESC`, branchCode, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting voids: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	voids := []models.OrderVoid{}
+	for rows.Next() {
+		var v models.OrderVoid
+		if err := rows.Scan(&v.ID, &v.BranchCode, &v.OrderID, &v.OrderNumber, &v.OrderItemID, &v.PricelistItemID, &v.Quantity,
+			&v.Amount, &v.Reason, &v.Note, &v.OrderStaffID, &v.VoidedBy, &v.TimeClockEntryID, &v.VoidedAt,
+			&v.VoidedByName, &v.ClockInAt, &v.ClockOutAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning void: %v", ErrDatabaseError, err)
+		}
+		voids = append(voids, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating voids: %v", ErrDatabaseError, err)
+	}
+	return voids, nil
+}
+
+// discountTotalsQuery selects the counts and totals of the orders of the discount report, to
+// be grouped.
+const discountTotalsQuery = `COUNT(*), COUNT(*) FILTER (WHERE COALESCE(o.discount_amount, 0) > 0),
+	       SUM(o.total_amount), SUM(COALESCE(o.discount_amount, 0))
+	FROM orders o`
+
+// discountFilterWhere selects the orders of a models.DiscountReportFilter; see discountFilterArgs.
+const discountFilterWhere = `WHERE o.branch_code = $1 AND o.status = ANY($2) AND o.order_time >= $3 AND o.order_time < $4
+	  AND o.business_date BETWEEN $5 AND $6`
+
+// discountFilterArgs returns the arguments of discountFilterWhere.
+func discountFilterArgs(filter models.DiscountReportFilter) []interface{} {
+	return []interface{}{filter.BranchCode, pq.Array(filter.Statuses), filter.Start, filter.End,
+		businessDateFrom(filter.Start), businessDateTo(filter.End)}
+}
+
+func (r *reportRepository) GetDiscountsByPeriod(filter models.DiscountReportFilter) ([]models.DiscountTotal, error) {
+	format, ok := taxPeriodFormats[filter.Period]
+	if !ok {
+		format = taxPeriodFormats["daily"]
+	}
+	rows, err := r.db.Query(`SELECT TO_CHAR(o.order_time AT TIME ZONE $7, $8), `+discountTotalsQuery+`
+		`+discountFilterWhere+`
+		GROUP BY 1
+		ORDER BY 1 DESC`, append(discountFilterArgs(filter), utils.ClubLocation().String(), format)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting discounts by period: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.DiscountTotal{}
+	for rows.Next() {
+		var total models.DiscountTotal
+		if err := rows.Scan(&total.Period, &total.OrderCount, &total.DiscountedOrders, &total.GrossSales, &total.Discounts); err != nil {
+			return nil, fmt.Errorf("%w: scanning discounts by period: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating discounts by period: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
+
+func (r *reportRepository) GetDiscountsByStaff(filter models.DiscountReportFilter) ([]models.DiscountTotal, error) {
+	rows, err := r.db.Query(`SELECT o.staff_id, COALESCE(NULLIF(u.full_name, ''), u.username), `+discountTotalsQuery+`
+		LEFT JOIN users u ON o.staff_id = u.id
+		`+discountFilterWhere+`
+		GROUP BY 1, 2
+		ORDER BY 6 DESC, 3 DESC, 1`, discountFilterArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting discounts by staff: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.DiscountTotal{}
+	for rows.Next() {
+		var total models.DiscountTotal
+		if err := rows.Scan(&total.StaffID, &total.StaffName, &total.OrderCount, &total.DiscountedOrders, &total.GrossSales,
+			&total.Discounts); err != nil {
+			return nil, fmt.Errorf("%w: scanning discounts by staff: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating discounts by staff: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
+
+func (r *reportRepository) GetPromoCodeUsage(filter models.DiscountReportFilter) ([]models.PromoCodeUsage, error) {
+	rows, err := r.db.Query(`
+		WITH used AS (
+			SELECT pr.promo_code_id, COUNT(*) AS redemptions, SUM(COALESCE(o.discount_amount, 0)) AS discounts,
+			       SUM(o.total_amount - COALESCE(o.discount_amount, 0)) AS net_sales
+			FROM promo_code_redemptions pr
+			JOIN orders o ON o.id = pr.order_id
+			`+discountFilterWhere+`
+			GROUP BY 1
+		), ever AS (
+			SELECT promo_code_id, COUNT(*) AS redemptions FROM promo_code_redemptions GROUP BY 1
+		)
+		SELECT pc.id, pc.code, pc.discount_percent, pc.active, pc.issued_count, COALESCE(used.redemptions, 0),
+		       COALESCE(ever.redemptions, 0), COALESCE(used.discounts, 0), COALESCE(used.net_sales, 0)
+		FROM promo_codes pc
+		LEFT JOIN used ON used.promo_code_id = pc.id
+		LEFT JOIN ever ON ever.promo_code_id = pc.id
+		WHERE pc.active OR used.redemptions > 0
+		ORDER BY 6 DESC, 2`, discountFilterArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting promo code usage: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	usage := []models.PromoCodeUsage{}
+	for rows.Next() {
+		var code models.PromoCodeUsage
+		if err := rows.Scan(&code.PromoCodeID, &code.Code, &code.DiscountPercent, &code.Active, &code.IssuedCount, &code.Redemptions,
+			&code.TotalRedemptions, &code.Discounts, &code.NetSales); err != nil {
+			return nil, fmt.Errorf("%w: scanning promo code usage: %v", ErrDatabaseError, err)
+		}
+		usage = append(usage, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating promo code usage: %v", ErrDatabaseError, err)
+	}
+	return usage, nil
+}
+
+func (r *reportRepository) GetSalesBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error) {
+	// Archived orders are completed orders too, and count in their period like live ones
+	rows, err := r.db.Query(`SELECT o.source, COUNT(*), SUM(o.final_amount)
+		FROM (SELECT source, final_amount, branch_code, status, order_time, business_date FROM orders
+		      UNION ALL
+		      SELECT source, final_amount, branch_code, status, order_time, business_date FROM orders_archive) o
+		WHERE o.branch_code = $1 AND o.status = ANY($2) AND o.order_time >= $3 AND o.order_time < $4
+		  AND o.business_date BETWEEN $5 AND $6
+		GROUP BY 1`, filter.BranchCode, pq.Array(filter.Statuses), filter.Start, filter.End,
+		businessDateFrom(filter.Start), businessDateTo(filter.End))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting sales by source: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.SourceTotal{}
+	for rows.Next() {
+		var total models.SourceTotal
+		if err := rows.Scan(&total.Source, &total.OrderCount, &total.Revenue); err != nil {
+			return nil, fmt.Errorf("%w: scanning sales by source: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating sales by source: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
+
+func (r *reportRepository) GetBookingsBySource(filter models.SourceReportFilter) ([]models.SourceTotal, error) {
+	rows, err := r.db.Query(`SELECT b.source, COUNT(*), COALESCE(SUM(b.total_price), 0)
+		FROM bookings b
+		WHERE b.start_time >= $1 AND b.start_time < $2 AND b.status <> $3 AND b.deleted_at IS NULL
+		GROUP BY 1`, filter.Start, filter.End, string(models.BookingStatusCancelled))
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting bookings by source: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.SourceTotal{}
+	for rows.Next() {
+		var total models.SourceTotal
+		if err := rows.Scan(&total.Source, &total.BookingCount, &total.BookingRevenue); err != nil {
+			return nil, fmt.Errorf("%w: scanning bookings by source: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating bookings by source: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ReportViewRepository defines the database operations on the materialized views of the reports.
+type ReportViewRepository interface {
+	// RefreshView recomputes a view of models.ReportViews without blocking its readers and
+	// records the refresh.
+	RefreshView(viewName string) (*models.ReportViewRefresh, error)
+	// GetRefreshes returns the last refresh of each view, by view name.
+	GetRefreshes() ([]models.ReportViewRefresh, error)
+}
+
+type reportViewRepository struct {
+	db *sql.DB
+}
+
+// NewReportViewRepository creates a new instance of ReportViewRepository.
+func NewReportViewRepository(db *sql.DB) ReportViewRepository {
+	return &reportViewRepository{db: db}
+}
+
+func (r *reportViewRepository) RefreshView(viewName string) (*models.ReportViewRefresh, error) {
+	started := time.Now()
+	if _, err := r.db.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY ` + pq.QuoteIdentifier(viewName)); err != nil {
+		return nil, fmt.Errorf("%w: refreshing %s: %v", ErrDatabaseError, viewName, err)
+	}
+	refresh := &models.ReportViewRefresh{
+		ViewName:    viewName,
+		RefreshedAt: started.UTC(), // The view holds the data as of the start of the refresh
+		DurationMS:  time.Since(started).Milliseconds(),
+	}
+	_, err := r.db.Exec(`INSERT INTO report_view_refreshes (view_name, refreshed_at, duration_ms)
+	                     VALUES ($1, $2, $3)
+	                     ON CONFLICT (view_name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at, duration_ms = EXCLUDED.duration_ms`,
+		refresh.ViewName, refresh.RefreshedAt, refresh.DurationMS)
+	if err != nil {
+		return nil, fmt.Errorf("%w: recording the refresh of %s: %v", ErrDatabaseError, viewName, err)
+	}
+	return refresh, nil
+}
+
+func (r *reportViewRepository) GetRefreshes() ([]models.ReportViewRefresh, error) {
+	rows, err := r.db.Query(`SELECT view_name, refreshed_at, duration_ms FROM report_view_refreshes ORDER BY view_name`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing report view refreshes: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	refreshes := []models.ReportViewRefresh{}
+	for rows.Next() {
+		var refresh models.ReportViewRefresh
+		if err := rows.Scan(&refresh.ViewName, &refresh.RefreshedAt, &refresh.DurationMS); err != nil {
+			return nil, fmt.Errorf("%w: scanning report view refresh: %v", ErrDatabaseError, err)
+		}
+		refreshes = append(refreshes, refresh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating report view refreshes: %v", ErrDatabaseError, err)
+	}
+	return refreshes, nil
+}
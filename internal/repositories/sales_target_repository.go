@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// SalesTargetRepository defines the database operations for sales targets. Months are given
+// as YYYY-MM.
+type SalesTargetRepository interface {
+	// CreateTarget inserts a sales target; a ConstraintError matching ErrDuplicateKey if the
+	// branch has a target for the category in the month, or ErrInvalidReference if the category
+	// does not exist.
+	CreateTarget(target *models.SalesTarget) error
+	// GetTargetByID returns a sales target; ErrNotFound if there is none.
+	GetTargetByID(id int64) (*models.SalesTarget, error)
+	// GetTargets lists the sales targets of a branch in a month by category name.
+	GetTargets(branchCode, month string) ([]models.SalesTarget, error)
+	// UpdateTargetAmount changes the amount of a sales target and clears its behind notice, so
+	// the new amount is checked again; ErrNotFound if there is none.
+	UpdateTargetAmount(id int64, amount models.Money) (*models.SalesTarget, error)
+	// DeleteTarget deletes a sales target; ErrNotFound if there is none.
+	DeleteTarget(id int64) error
+	// GetUnnotifiedTargets locks and returns the targets of a branch in a month that were not
+	// notified as behind yet, skipping those locked by another instance.
+	GetUnnotifiedTargets(executor SQLExecutor, branchCode, month string) ([]models.SalesTarget, error)
+	// MarkBehindNotified records that the Admins were notified a target falls behind.
+	MarkBehindNotified(executor SQLExecutor, id int64, notifiedAt time.Time) error
+}
+
+type salesTargetRepository struct {
+	db *sql.DB
+}
+
+// NewSalesTargetRepository creates a new instance of SalesTargetRepository.
+func NewSalesTargetRepository(db *sql.DB) SalesTargetRepository {
+	return &salesTargetRepository{db: db}
+}
+
+const salesTargetColumns = `t.id, t.branch_code, t.category_id, pc.name, TO_CHAR(t.month, 'YYYY-MM'), t.amount, t.behind_notified_at,
+	t.created_by, t.created_at, t.updated_at`
+
+func scanSalesTarget(row scanner) (*models.SalesTarget, error) {
+	var target models.SalesTarget
+	err := row.Scan(&target.ID, &target.BranchCode, &target.CategoryID, &target.CategoryName, &target.Month, &target.Amount,
+		&target.BehindNotifiedAt, &target.CreatedBy, &target.CreatedAt, &target.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *salesTargetRepository) CreateTarget(target *models.SalesTarget) error {
+	var id int64
+	err := r.db.QueryRow(`INSERT INTO sales_targets (branch_code, category_id, month, amount, created_by, created_at, updated_at)
+	                      VALUES ($1, $2, TO_DATE($3, 'YYYY-MM'), $4, $5, $6, $6)
+	                      RETURNING id`,
+		target.BranchCode, target.CategoryID, target.Month, target.Amount, target.CreatedBy, time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: "the branch already has a target for this category and month"}
+			case "foreign_key_violation":
+				if pqErr.Constraint == "sales_targets_category_id_fkey" {
+					return &ConstraintError{Err: ErrInvalidReference, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("category ID %d does not exist", target.CategoryID)}
+				}
+			}
+		}
+		return fmt.Errorf("%w: creating sales target: %v", ErrDatabaseError, err)
+	}
+	created, err := r.GetTargetByID(id)
+	if err != nil {
+		return err
+	}
+	*target = *created
+	return nil
+}
+
+func (r *salesTargetRepository) GetTargetByID(id int64) (*models.SalesTarget, error) {
+	target, err := scanSalesTarget(r.db.QueryRow(`SELECT `+salesTargetColumns+`
+		FROM sales_targets t
+		JOIN pricelist_categories pc ON pc.id = t.category_id
+		WHERE t.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting sales target ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return target, nil
+}
+
+func (r *salesTargetRepository) GetTargets(branchCode, month string) ([]models.SalesTarget, error) {
+	return r.queryTargets(r.db, "listing sales targets", `SELECT `+salesTargetColumns+`
+		FROM sales_targets t
+		JOIN pricelist_categories pc ON pc.id = t.category_id
+		WHERE t.branch_code = $1 AND t.month = TO_DATE($2, 'YYYY-MM')
+		ORDER BY pc.name, t.id`, branchCode, month)
+}
+
+// queryTargets runs a query selecting salesTargetColumns.
+func (r *salesTargetRepository) queryTargets(executor SQLExecutor, action, query string, args ...interface{}) ([]models.SalesTarget, error) {
+	rows, err := executor.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	defer rows.Close()
+
+	targets := []models.SalesTarget{}
+	for rows.Next() {
+		target, err := scanSalesTarget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning sales target: %v", ErrDatabaseError, err)
+		}
+		targets = append(targets, *target)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	return targets, nil
+}
+
+func (r *salesTargetRepository) UpdateTargetAmount(id int64, amount models.Money) (*models.SalesTarget, error) {
+	result, err := r.db.Exec(`UPDATE sales_targets SET amount = $2, behind_notified_at = NULL, updated_at = $3 WHERE id = $1`,
+		id, amount, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%w: updating sales target ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return nil, ErrNotFound
+	}
+	return r.GetTargetByID(id)
+}
+
+func (r *salesTargetRepository) DeleteTarget(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM sales_targets WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting sales target ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *salesTargetRepository) GetUnnotifiedTargets(executor SQLExecutor, branchCode, month string) ([]models.SalesTarget, error) {
+	return r.queryTargets(executor, "getting unnotified sales targets", `SELECT `+salesTargetColumns+`
+		FROM sales_targets t
+		JOIN pricelist_categories pc ON pc.id = t.category_id
+		WHERE t.branch_code = $1 AND t.month = TO_DATE($2, 'YYYY-MM') AND t.behind_notified_at IS NULL
+		ORDER BY t.id
+		FOR UPDATE OF t SKIP LOCKED`, branchCode, month)
+}
+
+func (r *salesTargetRepository) MarkBehindNotified(executor SQLExecutor, id int64, notifiedAt time.Time) error {
+	_, err := executor.Exec(`UPDATE sales_targets SET behind_notified_at = $2 WHERE id = $1`, id, notifiedAt)
+	if err != nil {
+		return fmt.Errorf("%w: marking sales target ID %d notified: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
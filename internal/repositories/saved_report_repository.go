@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// SavedReportRepository defines the database operations for saved reports.
+type SavedReportRepository interface {
+	// CreateReport inserts a saved report; a ConstraintError matching ErrDuplicateKey if the
+	// name is taken.
+	CreateReport(report *models.SavedReport) error
+	// GetReportByID returns a saved report; ErrNotFound if there is none.
+	GetReportByID(id int64) (*models.SavedReport, error)
+	// GetReports lists the saved reports by name.
+	GetReports() ([]models.SavedReport, error)
+	// UpdateReport updates the name, type, parameters, schedule and next run of a saved report;
+	// ErrNotFound if there is none, or a ConstraintError matching ErrDuplicateKey if the name
+	// is taken.
+	UpdateReport(report *models.SavedReport) error
+	// DeleteReport deletes a saved report; ErrNotFound if there is none.
+	DeleteReport(id int64) error
+	// GetDueReports locks and returns the scheduled reports due at now, skipping those locked
+	// by another instance.
+	GetDueReports(executor SQLExecutor, now time.Time) ([]models.SavedReport, error)
+	// ScheduleNextRun records a run of a scheduled report at ranAt and sets its next run.
+	ScheduleNextRun(executor SQLExecutor, id int64, ranAt, nextRunAt time.Time) error
+	// SetLastError records the error of the last scheduled run of a report, nil if it succeeded.
+	SetLastError(id int64, lastError *string) error
+}
+
+type savedReportRepository struct {
+	db *sql.DB
+}
+
+// NewSavedReportRepository creates a new instance of SavedReportRepository.
+func NewSavedReportRepository(db *sql.DB) SavedReportRepository {
+	return &savedReportRepository{db: db}
+}
+
+const savedReportColumns = `id, name, report_type, params, schedule, next_run_at, last_run_at, last_error, created_by,
+	created_at, updated_at`
+
+func scanSavedReport(row scanner) (*models.SavedReport, error) {
+	var report models.SavedReport
+	var params, schedule []byte
+	err := row.Scan(&report.ID, &report.Name, &report.ReportType, &params, &schedule, &report.NextRunAt, &report.LastRunAt,
+		&report.LastError, &report.CreatedBy, &report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(params, &report.Params); err != nil {
+		return nil, fmt.Errorf("decoding params of saved report ID %d: %v", report.ID, err)
+	}
+	if schedule != nil {
+		if err := json.Unmarshal(schedule, &report.Schedule); err != nil {
+			return nil, fmt.Errorf("decoding schedule of saved report ID %d: %v", report.ID, err)
+		}
+	}
+	return &report, nil
+}
+
+// encodeSavedReport encodes the parameters and schedule of a saved report; the schedule is
+// nil if there is none.
+func encodeSavedReport(report *models.SavedReport) (params, schedule []byte, err error) {
+	if report.Params == nil {
+		report.Params = map[string]string{}
+	}
+	if params, err = json.Marshal(report.Params); err != nil {
+		return nil, nil, fmt.Errorf("encoding saved report params: %w", err)
+	}
+	if report.Schedule != nil {
+		if schedule, err = json.Marshal(report.Schedule); err != nil {
+			return nil, nil, fmt.Errorf("encoding saved report schedule: %w", err)
+		}
+	}
+	return params, schedule, nil
+}
+
+// savedReportWriteError converts the error of an insert or update of a saved report.
+func savedReportWriteError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: "a saved report with this name already exists"}
+	}
+	return fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+}
+
+func (r *savedReportRepository) CreateReport(report *models.SavedReport) error {
+	params, schedule, err := encodeSavedReport(report)
+	if err != nil {
+		return err
+	}
+	created, err := scanSavedReport(r.db.QueryRow(`INSERT INTO saved_reports (name, report_type, params, schedule, next_run_at, created_by,
+	                          created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	                      RETURNING `+savedReportColumns,
+		report.Name, report.ReportType, params, schedule, report.NextRunAt, report.CreatedBy, time.Now().UTC(),
+	))
+	if err != nil {
+		return savedReportWriteError(err, "creating saved report")
+	}
+	*report = *created
+	return nil
+}
+
+func (r *savedReportRepository) GetReportByID(id int64) (*models.SavedReport, error) {
+	report, err := scanSavedReport(r.db.QueryRow(`SELECT `+savedReportColumns+` FROM saved_reports WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting saved report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return report, nil
+}
+
+func (r *savedReportRepository) GetReports() ([]models.SavedReport, error) {
+	return r.queryReports(r.db, "listing saved reports", `SELECT `+savedReportColumns+` FROM saved_reports ORDER BY LOWER(name), id`)
+}
+
+// queryReports runs a query selecting savedReportColumns.
+func (r *savedReportRepository) queryReports(executor SQLExecutor, action, query string, args ...interface{}) ([]models.SavedReport, error) {
+	rows, err := executor.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	defer rows.Close()
+
+	reports := []models.SavedReport{}
+	for rows.Next() {
+		report, err := scanSavedReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning saved report: %v", ErrDatabaseError, err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+	}
+	return reports, nil
+}
+
+func (r *savedReportRepository) UpdateReport(report *models.SavedReport) error {
+	params, schedule, err := encodeSavedReport(report)
+	if err != nil {
+		return err
+	}
+	updated, err := scanSavedReport(r.db.QueryRow(`UPDATE saved_reports
+	                      SET name = $2, report_type = $3, params = $4, schedule = $5, next_run_at = $6, updated_at = $7
+	                      WHERE id = $1
+	                      RETURNING `+savedReportColumns,
+		report.ID, report.Name, report.ReportType, params, schedule, report.NextRunAt, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return savedReportWriteError(err, fmt.Sprintf("updating saved report ID %d", report.ID))
+	}
+	*report = *updated
+	return nil
+}
+
+func (r *savedReportRepository) DeleteReport(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM saved_reports WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting saved report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *savedReportRepository) GetDueReports(executor SQLExecutor, now time.Time) ([]models.SavedReport, error) {
+	return r.queryReports(executor, "getting due saved reports", `SELECT `+savedReportColumns+`
+		FROM saved_reports
+		WHERE next_run_at <= $1
+		ORDER BY next_run_at, id
+		FOR UPDATE SKIP LOCKED`, now)
+}
+
+func (r *savedReportRepository) ScheduleNextRun(executor SQLExecutor, id int64, ranAt, nextRunAt time.Time) error {
+	_, err := executor.Exec(`UPDATE saved_reports SET last_run_at = $2, next_run_at = $3 WHERE id = $1`, id, ranAt, nextRunAt)
+	if err != nil {
+		return fmt.Errorf("%w: scheduling saved report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
+
+func (r *savedReportRepository) SetLastError(id int64, lastError *string) error {
+	_, err := r.db.Exec(`UPDATE saved_reports SET last_error = $2 WHERE id = $1`, id, lastError)
+	if err != nil {
+		return fmt.Errorf("%w: recording run of saved report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+)
+
+// SearchRepository defines the queries behind the global search box. Every method
+// returns at most limit matches with a relevance score in (0, 1], best first.
+type SearchRepository interface {
+	// SearchOrders matches orders by display number (number may be nil), client name or phone.
+	SearchOrders(term string, number *models.OrderNumberRef, limit int) ([]models.OrderSearchHit, error)
+	SearchBookings(term string, limit int) ([]models.BookingSearchHit, error)
+	SearchClients(term string, limit int) ([]models.ClientSearchHit, error)
+}
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository creates a new instance of SearchRepository.
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Every search query takes the same leading arguments, built by searchArgs:
+// $1 the lower-cased term, $2 the term escaped for LIKE and $3 the digits of the
+// term for phone matching (” if it has fewer than 4 digits). A term that is a whole phone
+// number is matched by its E.164 digits, as phone numbers are stored, so "8 701 123 45 67"
+// finds "+77011234567".
+func searchArgs(term string) []interface{} {
+	lower := strings.ToLower(strings.TrimSpace(term))
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(lower)
+	var digits strings.Builder
+	for _, r := range lower {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	phoneDigits := digits.String()
+	if normalized, err := utils.NormalizePhone(term); err == nil {
+		phoneDigits = strings.TrimPrefix(normalized, "+")
+	}
+	if len(phoneDigits) < 4 {
+		phoneDigits = ""
+	}
+	return []interface{}{lower, escaped, phoneDigits}
+}
+
+// textScore ranks how well column matches the term: exact match 1.0, prefix 0.8,
+// prefix of a later word 0.6, anywhere 0.4, no match (or NULL) 0.
+func textScore(column string) string {
+	return fmt.Sprintf(`CASE
+	    WHEN LOWER(%[1]s) = $1::text THEN 1.0
+	    WHEN LOWER(%[1]s) LIKE $2::text || '%%' THEN 0.8
+	    WHEN LOWER(%[1]s) LIKE '%% ' || $2::text || '%%' THEN 0.6
+	    WHEN LOWER(%[1]s) LIKE '%%' || $2::text || '%%' THEN 0.4
+	    ELSE 0 END`, column)
+}
+
+// phoneScore ranks a phone number containing the digits of the term at 0.7,
+// ignoring formatting such as "+7 (701) 123-45-67".
+func phoneScore(column string) string {
+	return fmt.Sprintf(`CASE
+	    WHEN $3::text <> '' AND regexp_replace(%[1]s, '\D', '', 'g') LIKE '%%' || $3::text || '%%' THEN 0.7
+	    ELSE 0 END`, column)
+}
+
+func (r *searchRepository) SearchOrders(term string, number *models.OrderNumberRef, limit int) ([]models.OrderSearchHit, error) {
+	args := searchArgs(term)
+	var branchCode, businessDate interface{}
+	var dailyNumber interface{}
+	if number != nil {
+		branchCode, businessDate, dailyNumber = number.BranchCode, number.BusinessDate, number.DailyNumber
+	}
+	args = append(args, branchCode, businessDate, dailyNumber, limit)
+
+	query := `SELECT id, business_date, daily_number, status, client_name, final_amount, order_time, score FROM (
+	            SELECT o.id, o.business_date, o.daily_number, o.status, c.full_name AS client_name, o.final_amount, o.order_time,
+	                   GREATEST(
+	                       CASE WHEN o.branch_code = $4::text AND o.business_date = $5::date AND o.daily_number = $6::int THEN 1.0 ELSE 0 END,
+	                       ` + textScore("c.full_name") + `,
+	                       ` + phoneScore("c.phone_number") + `
+	                   ) AS score
+	            FROM orders o
+	            LEFT JOIN clients c ON o.client_id = c.id
+	            WHERE (o.branch_code = $4::text AND o.business_date = $5::date AND o.daily_number = $6::int)
+	               OR o.client_id IS NOT NULL
+	          ) matches
+	          WHERE score > 0
+	          ORDER BY score DESC, order_time DESC
+	          LIMIT $7`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: searching orders: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	hits := []models.OrderSearchHit{}
+	for rows.Next() {
+		var hit models.OrderSearchHit
+		var businessDate time.Time
+		var dailyNumber int
+		if err := rows.Scan(&hit.ID, &businessDate, &dailyNumber, &hit.Status, &hit.ClientName, &hit.FinalAmount, &hit.OrderTime, &hit.Score); err != nil {
+			return nil, fmt.Errorf("%w: scanning order search hit: %v", ErrDatabaseError, err)
+		}
+		hit.OrderNumber = models.FormatOrderNumber(businessDate.Format(utils.DateLayout), dailyNumber)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating order search hits: %v", ErrDatabaseError, err)
+	}
+	return hits, nil
+}
+
+func (r *searchRepository) SearchBookings(term string, limit int) ([]models.BookingSearchHit, error) {
+	args := append(searchArgs(term), limit)
+	query := `SELECT id, status, client_name, table_name, start_time, end_time, score FROM (
+	            SELECT b.id, b.status, c.full_name AS client_name, gt.name AS table_name, b.start_time, b.end_time,
+	                   GREATEST(` + textScore("c.full_name") + `, ` + phoneScore("c.phone_number") + `, 0.9 * ` + textScore("gt.name") + `) AS score
+	            FROM bookings b
+	            JOIN game_tables gt ON b.table_id = gt.id
+	            LEFT JOIN clients c ON b.client_id = c.id
+	            WHERE b.deleted_at IS NULL
+	          ) matches
+	          WHERE score > 0
+	          ORDER BY score DESC, start_time DESC
+	          LIMIT $4`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: searching bookings: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	hits := []models.BookingSearchHit{}
+	for rows.Next() {
+		var hit models.BookingSearchHit
+		if err := rows.Scan(&hit.ID, &hit.Status, &hit.ClientName, &hit.TableName, &hit.StartTime, &hit.EndTime, &hit.Score); err != nil {
+			return nil, fmt.Errorf("%w: scanning booking search hit: %v", ErrDatabaseError, err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating booking search hits: %v", ErrDatabaseError, err)
+	}
+	return hits, nil
+}
+
+func (r *searchRepository) SearchClients(term string, limit int) ([]models.ClientSearchHit, error) {
+	args := append(searchArgs(term), limit)
+	query := `SELECT id, full_name, blacklisted, blacklist_reason, phone_number, email, score FROM (
+	            SELECT id, full_name, phone_number, email, blacklist_reason,
+	                   ` + clientBlacklistedColumn + ` AS blacklisted,
+	                   GREATEST(` + textScore("full_name") + `, ` + phoneScore("phone_number") + `, ` + textScore("email") + `) AS score
+	            FROM clients
+	            WHERE deleted_at IS NULL
+	          ) matches
+	          WHERE score > 0
+	          ORDER BY score DESC, full_name ASC
+	          LIMIT $4`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: searching clients: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	hits := []models.ClientSearchHit{}
+	for rows.Next() {
+		var hit models.ClientSearchHit
+		if err := rows.Scan(&hit.ID, &hit.FullName, &hit.Blacklisted, &hit.BlacklistReason, &hit.PhoneNumber, &hit.Email, &hit.Score); err != nil {
+			return nil, fmt.Errorf("%w: scanning client search hit: %v", ErrDatabaseError, err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating client search hits: %v", ErrDatabaseError, err)
+	}
+	return hits, nil
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// SessionDepositRepository defines the database operations for the deposits of table sessions.
+type SessionDepositRepository interface {
+	// CreateDeposit records the deposit of a session as held.
+	CreateDeposit(executor SQLExecutor, deposit *models.SessionDeposit) error
+	// GetDepositBySession returns the deposit of a table session; ErrNotFound if it has none.
+	GetDepositBySession(sessionID int64) (*models.SessionDeposit, error)
+	// UpdateDeposit saves the status, amounts, settlement and failure reason of a deposit;
+	// ErrVersionConflict if its status is no longer fromStatus.
+	UpdateDeposit(executor SQLExecutor, deposit *models.SessionDeposit, fromStatus string) error
+}
+
+type sessionDepositRepository struct {
+	db *sql.DB
+}
+
+// NewSessionDepositRepository creates a new instance of SessionDepositRepository.
+func NewSessionDepositRepository(db *sql.DB) SessionDepositRepository {
+	return &sessionDepositRepository{db: db}
+}
+
+const sessionDepositColumns = `id, table_session_id, client_id, method, amount, status, authorization_id, applied_amount,
+	refunded_amount, failure_reason, taken_by, taken_at, settled_at, updated_at`
+
+func scanSessionDeposit(row scanner) (*models.SessionDeposit, error) {
+	var deposit models.SessionDeposit
+	err := row.Scan(&deposit.ID, &deposit.TableSessionID, &deposit.ClientID, &deposit.Method, &deposit.Amount, &deposit.Status,
+		&deposit.AuthorizationID, &deposit.AppliedAmount, &deposit.RefundedAmount, &deposit.FailureReason, &deposit.TakenBy,
+		&deposit.TakenAt, &deposit.SettledAt, &deposit.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+func (r *sessionDepositRepository) CreateDeposit(executor SQLExecutor, deposit *models.SessionDeposit) error {
+	created, err := scanSessionDeposit(executor.QueryRow(`INSERT INTO session_deposits (table_session_id, client_id, method, amount,
+	                                                             status, authorization_id, taken_by, taken_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	                      RETURNING `+sessionDepositColumns,
+		deposit.TableSessionID, deposit.ClientID, deposit.Method, deposit.Amount, models.DepositStatusHeld, deposit.AuthorizationID,
+		deposit.TakenBy, deposit.TakenAt,
+	))
+	if err != nil {
+		return fmt.Errorf("%w: creating deposit of table session ID %d: %v", ErrDatabaseError, deposit.TableSessionID, err)
+	}
+	*deposit = *created
+	return nil
+}
+
+func (r *sessionDepositRepository) GetDepositBySession(sessionID int64) (*models.SessionDeposit, error) {
+	deposit, err := scanSessionDeposit(r.db.QueryRow(`SELECT `+sessionDepositColumns+` FROM session_deposits WHERE table_session_id = $1`, sessionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting deposit of table session ID %d: %v", ErrDatabaseError, sessionID, err)
+	}
+	return deposit, nil
+}
+
+func (r *sessionDepositRepository) UpdateDeposit(executor SQLExecutor, deposit *models.SessionDeposit, fromStatus string) error {
+	updated, err := scanSessionDeposit(executor.QueryRow(`UPDATE session_deposits
+	                      SET status = $3, applied_amount = $4, refunded_amount = $5, failure_reason = $6, settled_at = $7,
+	                          updated_at = $8
+	                      WHERE id = $1 AND status = $2
+	                      RETURNING `+sessionDepositColumns,
+		deposit.ID, fromStatus, deposit.Status, deposit.AppliedAmount, deposit.RefundedAmount, deposit.FailureReason,
+		deposit.SettledAt, time.Now().UTC(),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("%w: updating deposit ID %d: %v", ErrDatabaseError, deposit.ID, err)
+	}
+	*deposit = *updated
+	return nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// SessionRepository defines the database operations for user sessions (logged-in devices).
+type SessionRepository interface {
+	CreateSession(executor SQLExecutor, session *models.UserSession) (*models.UserSession, error)
+	GetSessionByID(id int64) (*models.UserSession, error)
+	GetActiveSessionsByUserID(userID int64, now time.Time) ([]models.UserSession, error)
+	// RotateSessionToken replaces the refresh token ID of an active session. It returns
+	// ErrNotFound if the session is revoked or expired, or oldTokenID is not its current
+	// token, so a refresh token can be exchanged only once.
+	RotateSessionToken(executor SQLExecutor, id int64, oldTokenID, newTokenID string, ipAddress *string, now, expiresAt time.Time) error
+	// RevokeSession revokes an active session of userID; ErrNotFound if there is none.
+	RevokeSession(executor SQLExecutor, id, userID int64, now time.Time) error
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository.
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, refresh_token_id, device_name, ip_address, user_agent, created_at, last_active_at, expires_at, revoked_at`
+
+func scanSession(row scanner) (*models.UserSession, error) {
+	var session models.UserSession
+	var deviceName, ipAddress, userAgent sql.NullString
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.RefreshTokenID, &deviceName, &ipAddress, &userAgent,
+		&session.CreatedAt, &session.LastActiveAt, &session.ExpiresAt, &revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if deviceName.Valid {
+		session.DeviceName = &deviceName.String
+	}
+	if ipAddress.Valid {
+		session.IPAddress = &ipAddress.String
+	}
+	if userAgent.Valid {
+		session.UserAgent = &userAgent.String
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) CreateSession(executor SQLExecutor, session *models.UserSession) (*models.UserSession, error) {
+	query := `INSERT INTO user_sessions (user_id, refresh_token_id, device_name, ip_address, user_agent, created_at, last_active_at, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+	          RETURNING ` + sessionColumns
+	created, err := scanSession(executor.QueryRow(query,
+		session.UserID, session.RefreshTokenID, session.DeviceName, session.IPAddress, session.UserAgent,
+		time.Now().UTC(), session.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating session: %v", ErrDatabaseError, err)
+	}
+	return created, nil
+}
+
+func (r *sessionRepository) GetSessionByID(id int64) (*models.UserSession, error) {
+	session, err := scanSession(r.db.QueryRow(`SELECT `+sessionColumns+` FROM user_sessions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting session by ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) GetActiveSessionsByUserID(userID int64, now time.Time) ([]models.UserSession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions
+	          WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+	          ORDER BY last_active_at DESC, id DESC`
+	rows, err := r.db.Query(query, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting sessions of user %d: %v", ErrDatabaseError, userID, err)
+	}
+	defer rows.Close()
+
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning session: %v", ErrDatabaseError, err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating sessions: %v", ErrDatabaseError, err)
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) RotateSessionToken(executor SQLExecutor, id int64, oldTokenID, newTokenID string, ipAddress *string, now, expiresAt time.Time) error {
+	query := `UPDATE user_sessions
+	          SET refresh_token_id = $3, ip_address = COALESCE($4, ip_address), last_active_at = $5, expires_at = $6
+	          WHERE id = $1 AND refresh_token_id = $2 AND revoked_at IS NULL AND expires_at > $5`
+	result, err := executor.Exec(query, id, oldTokenID, newTokenID, ipAddress, now, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%w: rotating token of session %d: %v", ErrDatabaseError, id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for session %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeSession(executor SQLExecutor, id, userID int64, now time.Time) error {
+	query := `UPDATE user_sessions SET revoked_at = $3 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	result, err := executor.Exec(query, id, userID, now)
+	if err != nil {
+		return fmt.Errorf("%w: revoking session %d: %v", ErrDatabaseError, id, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for session %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+	"time"
+)
+
+// SettingRepository defines the interface for application settings database operations.
+// The values of secret settings (models.IsSecretSetting) are encrypted when saved and
+// decrypted when read, so callers only see plain values.
+type SettingRepository interface {
+	GetSettingByKey(key string) (*models.ApplicationSetting, error)
+	GetSettings() ([]models.ApplicationSetting, error)
+	// SaveSetting creates or updates a setting by key. An update with a version other than 0
+	// fails with ErrVersionConflict unless it is the current version of the setting.
+	SaveSetting(setting *models.ApplicationSetting) error
+	// DeleteSetting deletes a setting; ErrNotFound if there is none.
+	DeleteSetting(key string) error
+	// ReencryptSecrets encrypts the secret values not encrypted with the current key again,
+	// after a key rotation, and returns how many it changed.
+	ReencryptSecrets() (int, error)
+}
+
+// settingRepository implements the SettingRepository interface.
+type settingRepository struct {
+	db *sql.DB
+}
+
+// NewSettingRepository creates a new instance of SettingRepository.
+func NewSettingRepository(db *sql.DB) SettingRepository {
+	return &settingRepository{db: db}
+}
+
+const settingColumns = `id, setting_key, setting_value, description, created_at, updated_at, version`
+
+func scanSetting(row scanner) (*models.ApplicationSetting, error) {
+	s := &models.ApplicationSetting{}
+	if err := row.Scan(&s.ID, &s.SettingKey, &s.SettingValue, &s.Description, &s.CreatedAt, &s.UpdatedAt, &s.Version); err != nil {
+		return nil, err
+	}
+	if s.SettingValue != nil {
+		value, err := utils.DecryptString(*s.SettingValue)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting setting %s: %w", s.SettingKey, err)
+		}
+		s.SettingValue = &value
+	}
+	return s, nil
+}
+
+// storedSettingValue returns the value of a setting as stored: encrypted if it is secret.
+func storedSettingValue(key string, value *string) (*string, error) {
+	if value == nil || !models.IsSecretSetting(key) {
+		return value, nil
+	}
+	encrypted, err := utils.EncryptString(*value)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting setting %s: %w", key, err)
+	}
+	return &encrypted, nil
+}
+
+// GetSettingByKey retrieves a single application setting by its key.
+func (r *settingRepository) GetSettingByKey(key string) (*models.ApplicationSetting, error) {
+	s, err := scanSetting(r.db.QueryRow(`SELECT `+settingColumns+` FROM application_settings WHERE setting_key = $1`, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting application setting %s: %v", ErrDatabaseError, key, err)
+	}
+	return s, nil
+}
+
+func (r *settingRepository) GetSettings() ([]models.ApplicationSetting, error) {
+	rows, err := r.db.Query(`SELECT ` + settingColumns + ` FROM application_settings ORDER BY setting_key`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing application settings: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	settings := []models.ApplicationSetting{}
+	for rows.Next() {
+		s, err := scanSetting(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning application setting: %v", ErrDatabaseError, err)
+		}
+		settings = append(settings, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating application settings: %v", ErrDatabaseError, err)
+	}
+	return settings, nil
+}
+
+func (r *settingRepository) SaveSetting(setting *models.ApplicationSetting) error {
+	value, err := storedSettingValue(setting.SettingKey, setting.SettingValue)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	// An existing row is only updated when the setting carries no version (0) or the
+	// version the row currently has.
+	query := `
+	    INSERT INTO application_settings (setting_key, setting_value, description, created_at, updated_at)
+	    VALUES ($1, $2, $3, $4, $5)
+	    ON CONFLICT (setting_key)
+	    DO UPDATE SET setting_value = EXCLUDED.setting_value, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at,
+	                  version = application_settings.version + 1
+	    WHERE $6 = 0 OR application_settings.version = $6
+	    RETURNING ` + settingColumns
+	saved, err := scanSetting(r.db.QueryRow(query, setting.SettingKey, value, setting.Description, now, now, setting.Version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) { // The row exists with a different version
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("%w: saving application setting %s: %v", ErrDatabaseError, setting.SettingKey, err)
+	}
+	*setting = *saved
+	return nil
+}
+
+func (r *settingRepository) DeleteSetting(key string) error {
+	result, err := r.db.Exec(`DELETE FROM application_settings WHERE setting_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("%w: deleting application setting %s: %v", ErrDatabaseError, key, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: getting rows affected for deleting application setting %s: %v", ErrDatabaseError, key, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *settingRepository) ReencryptSecrets() (int, error) {
+	rows, err := r.db.Query(`SELECT setting_key, setting_value FROM application_settings WHERE setting_value IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("%w: listing application settings: %v", ErrDatabaseError, err)
+	}
+	stored := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%w: scanning application setting: %v", ErrDatabaseError, err)
+		}
+		if models.IsSecretSetting(key) && utils.NeedsReencryption(value) {
+			stored[key] = value
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%w: iterating application settings: %v", ErrDatabaseError, err)
+	}
+
+	changed := 0
+	for key, value := range stored {
+		plain, err := utils.DecryptString(value)
+		if err != nil {
+			return changed, fmt.Errorf("decrypting setting %s: %w", key, err)
+		}
+		encrypted, err := utils.EncryptString(plain)
+		if err != nil {
+			return changed, fmt.Errorf("encrypting setting %s: %w", key, err)
+		}
+		// The plain value is unchanged, so is the version; a value saved meanwhile is kept
+		result, err := r.db.Exec(`UPDATE application_settings SET setting_value = $1 WHERE setting_key = $2 AND setting_value = $3`,
+			encrypted, key, value)
+		if err != nil {
+			return changed, fmt.Errorf("%w: re-encrypting application setting %s: %v", ErrDatabaseError, key, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			changed++
+		}
+	}
+	return changed, nil
+}
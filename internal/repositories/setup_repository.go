@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// SetupRepository defines the database operations of the initial setup.
+type SetupRepository interface {
+	GetSetupStatus() (*models.SetupStatus, error)
+	// ClaimSetup marks the setup as completed at now. It returns ErrDuplicateKey if it was
+	// completed before or there already are users, so the setup runs only once even when
+	// requested concurrently. Run it in the transaction creating the first Admin.
+	ClaimSetup(executor SQLExecutor, now time.Time) error
+	// SaveSettings stores settings by key, replacing existing values.
+	SaveSettings(executor SQLExecutor, settings map[string]string, now time.Time) error
+}
+
+type setupRepository struct {
+	db *sql.DB
+}
+
+// NewSetupRepository creates a new instance of SetupRepository.
+func NewSetupRepository(db *sql.DB) SetupRepository {
+	return &setupRepository{db: db}
+}
+
+func (r *setupRepository) GetSetupStatus() (*models.SetupStatus, error) {
+	var status models.SetupStatus
+	var completedAt sql.NullString
+	var hasUsers bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users),
+	                             (SELECT setting_value FROM application_settings WHERE setting_key = $1)`,
+		models.SettingKeySetupCompletedAt,
+	).Scan(&hasUsers, &completedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting setup status: %v", ErrDatabaseError, err)
+	}
+	if completedAt.Valid {
+		if t, err := time.Parse(time.RFC3339, completedAt.String); err == nil {
+			status.CompletedAt = &t
+		}
+	}
+	status.SetupRequired = !hasUsers && !completedAt.Valid
+	return &status, nil
+}
+
+func (r *setupRepository) ClaimSetup(executor SQLExecutor, now time.Time) error {
+	// Serializes concurrent setups; the second one then sees the setting of the first
+	if _, err := executor.Exec(`LOCK TABLE application_settings IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("%w: locking application settings: %v", ErrDatabaseError, err)
+	}
+	result, err := executor.Exec(`INSERT INTO application_settings (setting_key, setting_value, description, created_at, updated_at)
+	                              SELECT $1, $2, 'Set by the initial setup', $3, $3
+	                              WHERE NOT EXISTS (SELECT 1 FROM users)
+	                              ON CONFLICT (setting_key) DO NOTHING`,
+		models.SettingKeySetupCompletedAt, now.UTC().Format(time.RFC3339), now)
+	if err != nil {
+		return fmt.Errorf("%w: claiming setup: %v", ErrDatabaseError, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows of setup claim: %v", ErrDatabaseError, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: the setup has been completed", ErrDuplicateKey)
+	}
+	return nil
+}
+
+func (r *setupRepository) SaveSettings(executor SQLExecutor, settings map[string]string, now time.Time) error {
+	for key, value := range settings {
+		stored, err := storedSettingValue(key, &value)
+		if err != nil {
+			return err
+		}
+		_, err = executor.Exec(`INSERT INTO application_settings (setting_key, setting_value, created_at, updated_at)
+		                        VALUES ($1, $2, $3, $3)
+		                        ON CONFLICT (setting_key)
+		                        DO UPDATE SET setting_value = EXCLUDED.setting_value, updated_at = EXCLUDED.updated_at,
+		                                      version = application_settings.version + 1`,
+			key, stored, now)
+		if err != nil {
+			return fmt.Errorf("%w: saving application setting %s: %v", ErrDatabaseError, key, err)
+		}
+	}
+	return nil
+}
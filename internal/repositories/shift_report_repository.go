@@ -0,0 +1,309 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/lib/pq"
+)
+
+// ShiftReportRepository defines the database operations for end-of-shift reports and the
+// figures they are built from.
+type ShiftReportRepository interface {
+	// GetSalesByPayment totals the orders of a branch with one of statuses placed in [from, to),
+	// per payment method, by amount.
+	GetSalesByPayment(executor SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.ShiftPaymentTotal, error)
+	// GetStoppedSessionTotals counts and totals the table sessions stopped in [from, to).
+	GetStoppedSessionTotals(executor SQLExecutor, from, to time.Time) (int, models.Money, error)
+	// GetAccountPaymentsTotal totals the house account payments made with paymentMethod in [from, to).
+	GetAccountPaymentsTotal(executor SQLExecutor, paymentMethod string, from, to time.Time) (models.Money, error)
+	// GetCashDepositsTotal totals the cash deposits of table sessions taken in [from, to), less the
+	// cash refunded of the deposits settled in [from, to).
+	GetCashDepositsTotal(executor SQLExecutor, from, to time.Time) (models.Money, error)
+	// GetOpenOrders returns the orders of a branch with one of statuses, oldest first.
+	GetOpenOrders(executor SQLExecutor, branchCode string, statuses []string) ([]models.HandoverOrder, error)
+	// FindOverlappingShift returns the ID of the scheduled shift of a staff member that overlaps
+	// [from, to) the most; nil if none does.
+	FindOverlappingShift(executor SQLExecutor, staffID int64, from, to time.Time) (*int64, error)
+
+	CreateReport(executor SQLExecutor, report *models.ShiftReport) error
+	// GetReportByID returns a report; ErrNotFound if there is none.
+	GetReportByID(id int64) (*models.ShiftReport, error)
+	// GetLatestReportForShift returns the most recent report of a scheduled shift; ErrNotFound if it has none.
+	GetLatestReportForShift(shiftID int64) (*models.ShiftReport, error)
+	// GetReports lists the reports of a branch matching filters, most recent first.
+	GetReports(branchCode string, filters models.ShiftReportFilters) ([]models.ShiftReport, error)
+	MarkReportEmailed(id int64, emailedAt time.Time) error
+}
+
+type shiftReportRepository struct {
+	db *sql.DB
+}
+
+// NewShiftReportRepository creates a new instance of ShiftReportRepository.
+func NewShiftReportRepository(db *sql.DB) ShiftReportRepository {
+	return &shiftReportRepository{db: db}
+}
+
+const shiftReportColumns = `sr.id, sr.branch_code, sr.time_clock_entry_id, sr.staff_id,
+	COALESCE(NULLIF(u.full_name, ''), u.username, 'Staff #' || sr.staff_id::text), sr.shift_id, sr.started_at, sr.ended_at,
+	sr.order_count, sr.sales_total, sr.sales_by_payment, sr.session_count, sr.session_total, sr.opening_cash, sr.cash_sales,
+	sr.cash_account_payments, sr.cash_deposits, sr.expected_cash, sr.counted_cash, sr.cash_difference, sr.open_orders, sr.handover_notes,
+	sr.emailed_at, sr.created_at`
+
+const shiftReportFrom = `shift_reports sr
+	JOIN staff_members sm ON sr.staff_id = sm.id
+	LEFT JOIN users u ON sm.user_id = u.id`
+
+func scanShiftReport(row scanner) (*models.ShiftReport, error) {
+	var report models.ShiftReport
+	var salesByPayment, openOrders []byte
+	err := row.Scan(&report.ID, &report.BranchCode, &report.TimeClockEntryID, &report.StaffID, &report.StaffName, &report.ShiftID,
+		&report.StartedAt, &report.EndedAt, &report.OrderCount, &report.SalesTotal, &salesByPayment, &report.SessionCount,
+		&report.SessionTotal, &report.Cash.OpeningCash, &report.Cash.CashSales, &report.Cash.CashAccountPayments,
+		&report.Cash.CashDeposits, &report.Cash.ExpectedCash, &report.Cash.CountedCash, &report.Cash.Difference, &openOrders, &report.HandoverNotes,
+		&report.EmailedAt, &report.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	report.SalesByPayment = []models.ShiftPaymentTotal{}
+	if err := json.Unmarshal(salesByPayment, &report.SalesByPayment); err != nil {
+		return nil, fmt.Errorf("decoding sales by payment: %v", err)
+	}
+	report.OpenOrders = []models.HandoverOrder{}
+	if err := json.Unmarshal(openOrders, &report.OpenOrders); err != nil {
+		return nil, fmt.Errorf("decoding open orders: %v", err)
+	}
+	return &report, nil
+}
+
+func (r *shiftReportRepository) GetSalesByPayment(executor SQLExecutor, branchCode string, statuses []string, from, to time.Time) ([]models.ShiftPaymentTotal, error) {
+	rows, err := executor.Query(`SELECT COALESCE(NULLIF(LOWER(TRIM(payment_method)), ''), $5), COUNT(*), COALESCE(SUM(final_amount), 0)
+	                             FROM orders
+	                             WHERE branch_code = $1 AND status = ANY($2) AND order_time >= $3 AND order_time < $4
+	                               AND business_date BETWEEN $6 AND $7
+	                             GROUP BY 1
+	                             ORDER BY 3 DESC, 1`,
+		branchCode, pq.Array(statuses), from, to, models.PaymentMethodUnspecified, businessDateFrom(from), businessDateTo(to))
+	if err != nil {
+		return nil, fmt.Errorf("%w: totalling shift sales: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	totals := []models.ShiftPaymentTotal{}
+	for rows.Next() {
+		var total models.ShiftPaymentTotal
+		if err := rows.Scan(&total.PaymentMethod, &total.OrderCount, &total.Amount); err != nil {
+			return nil, fmt.Errorf("%w: scanning shift sales: %v", ErrDatabaseError, err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating shift sales: %v", ErrDatabaseError, err)
+	}
+	return totals, nil
+}
+
+func (r *shiftReportRepository) GetStoppedSessionTotals(executor SQLExecutor, from, to time.Time) (int, models.Money, error) {
+	var count int
+	var total models.Money
+	err := executor.QueryRow(`SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+	                          FROM table_sessions
+	                          WHERE status = $1 AND stopped_at >= $2 AND stopped_at < $3`,
+		models.TableSessionStatusStopped, from, to,
+	).Scan(&count, &total)
+	if err != nil {
+		return 0, models.ZeroMoney, fmt.Errorf("%w: totalling shift table sessions: %v", ErrDatabaseError, err)
+	}
+	return count, total, nil
+}
+
+func (r *shiftReportRepository) GetAccountPaymentsTotal(executor SQLExecutor, paymentMethod string, from, to time.Time) (models.Money, error) {
+	// Payments are stored as negative amounts, see client_account_entries
+	var total models.Money
+	err := executor.QueryRow(`SELECT COALESCE(-SUM(amount), 0)
+	                          FROM client_account_entries
+	                          WHERE entry_type = 'payment' AND LOWER(TRIM(payment_method)) = $1 AND created_at >= $2 AND created_at < $3`,
+		paymentMethod, from, to,
+	).Scan(&total)
+	if err != nil {
+		return models.ZeroMoney, fmt.Errorf("%w: totalling house account payments: %v", ErrDatabaseError, err)
+	}
+	return total, nil
+}
+
+func (r *shiftReportRepository) GetCashDepositsTotal(executor SQLExecutor, from, to time.Time) (models.Money, error) {
+	var total models.Money
+	err := executor.QueryRow(`SELECT COALESCE(SUM(CASE WHEN taken_at >= $2 AND taken_at < $3 THEN amount ELSE 0 END), 0)
+	                                 - COALESCE(SUM(CASE WHEN settled_at >= $2 AND settled_at < $3 THEN refunded_amount ELSE 0 END), 0)
+	                          FROM session_deposits
+	                          WHERE method = $1 AND ((taken_at >= $2 AND taken_at < $3) OR (settled_at >= $2 AND settled_at < $3))`,
+		models.DepositMethodCash, from, to,
+	).Scan(&total)
+	if err != nil {
+		return models.ZeroMoney, fmt.Errorf("%w: totalling cash deposits: %v", ErrDatabaseError, err)
+	}
+	return total, nil
+}
+
+func (r *shiftReportRepository) GetOpenOrders(executor SQLExecutor, branchCode string, statuses []string) ([]models.HandoverOrder, error) {
+	rows, err := executor.Query(`SELECT id, business_date, daily_number, table_id, status, final_amount, order_time
+	                             FROM orders
+	                             WHERE branch_code = $1 AND status = ANY($2)
+	                             ORDER BY order_time, id`, branchCode, pq.Array(statuses))
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing open orders: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	orders := []models.HandoverOrder{}
+	for rows.Next() {
+		var order models.HandoverOrder
+		var businessDate time.Time
+		var dailyNumber int
+		if err := rows.Scan(&order.OrderID, &businessDate, &dailyNumber, &order.TableID, &order.Status, &order.FinalAmount, &order.OrderTime); err != nil {
+			return nil, fmt.Errorf("%w: scanning open order: %v", ErrDatabaseError, err)
+		}
+		order.OrderNumber = models.FormatOrderNumber(businessDate.Format(utils.DateLayout), dailyNumber)
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating open orders: %v", ErrDatabaseError, err)
+	}
+	return orders, nil
+}
+
+func (r *shiftReportRepository) FindOverlappingShift(executor SQLExecutor, staffID int64, from, to time.Time) (*int64, error) {
+	var shiftID int64
+	err := executor.QueryRow(`SELECT id FROM shifts
+	                          WHERE staff_id = $1 AND start_time < $3 AND end_time > $2
+	                          ORDER BY LEAST(end_time, $3) - GREATEST(start_time, $2) DESC, start_time
+	                          LIMIT 1`, staffID, from, to).Scan(&shiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: finding shift of staff member ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	return &shiftID, nil
+}
+
+func (r *shiftReportRepository) CreateReport(executor SQLExecutor, report *models.ShiftReport) error {
+	salesByPayment, err := json.Marshal(report.SalesByPayment)
+	if err != nil {
+		return fmt.Errorf("encoding sales by payment: %w", err)
+	}
+	openOrders, err := json.Marshal(report.OpenOrders)
+	if err != nil {
+		return fmt.Errorf("encoding open orders: %w", err)
+	}
+	err = executor.QueryRow(`INSERT INTO shift_reports (branch_code, time_clock_entry_id, staff_id, shift_id, started_at, ended_at,
+	                                                    order_count, sales_total, sales_by_payment, session_count, session_total,
+	                                                    opening_cash, cash_sales, cash_account_payments, expected_cash, counted_cash,
+	                                                    cash_difference, open_orders, handover_notes, created_at, cash_deposits)
+	                         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+	                         RETURNING id, created_at`,
+		report.BranchCode, report.TimeClockEntryID, report.StaffID, report.ShiftID, report.StartedAt, report.EndedAt,
+		report.OrderCount, report.SalesTotal, salesByPayment, report.SessionCount, report.SessionTotal,
+		report.Cash.OpeningCash, report.Cash.CashSales, report.Cash.CashAccountPayments, report.Cash.ExpectedCash,
+		report.Cash.CountedCash, report.Cash.Difference, openOrders, report.HandoverNotes, time.Now().UTC(),
+		report.Cash.CashDeposits,
+	).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return fmt.Errorf("%w: time clock entry ID %d already has a report", ErrDuplicateKey, report.TimeClockEntryID)
+		}
+		return fmt.Errorf("%w: creating shift report: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *shiftReportRepository) GetReportByID(id int64) (*models.ShiftReport, error) {
+	report, err := scanShiftReport(r.db.QueryRow(`SELECT `+shiftReportColumns+` FROM `+shiftReportFrom+` WHERE sr.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting shift report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return report, nil
+}
+
+func (r *shiftReportRepository) GetLatestReportForShift(shiftID int64) (*models.ShiftReport, error) {
+	report, err := scanShiftReport(r.db.QueryRow(`SELECT `+shiftReportColumns+` FROM `+shiftReportFrom+`
+	                                              WHERE sr.shift_id = $1
+	                                              ORDER BY sr.ended_at DESC, sr.id DESC
+	                                              LIMIT 1`, shiftID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting report of shift ID %d: %v", ErrDatabaseError, shiftID, err)
+	}
+	return report, nil
+}
+
+func (r *shiftReportRepository) GetReports(branchCode string, filters models.ShiftReportFilters) ([]models.ShiftReport, error) {
+	conditions := []string{"sr.branch_code = $1"}
+	args := []interface{}{branchCode}
+	argCounter := 2
+
+	if filters.StaffID != nil {
+		conditions = append(conditions, fmt.Sprintf("sr.staff_id = $%d", argCounter))
+		args = append(args, *filters.StaffID)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("sr.ended_at >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("sr.ended_at < $%d", argCounter))
+		args = append(args, *filters.To)
+	}
+
+	rows, err := r.db.Query(`SELECT `+shiftReportColumns+` FROM `+shiftReportFrom+`
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY sr.ended_at DESC, sr.id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing shift reports: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	reports := []models.ShiftReport{}
+	for rows.Next() {
+		report, err := scanShiftReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning shift report: %v", ErrDatabaseError, err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating shift reports: %v", ErrDatabaseError, err)
+	}
+	return reports, nil
+}
+
+func (r *shiftReportRepository) MarkReportEmailed(id int64, emailedAt time.Time) error {
+	result, err := r.db.Exec(`UPDATE shift_reports SET emailed_at = $2 WHERE id = $1`, id, emailedAt)
+	if err != nil {
+		return fmt.Errorf("%w: marking shift report ID %d emailed: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for shift report ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// StaffDocumentRepository defines the database operations for the documents of staff members.
+type StaffDocumentRepository interface {
+	// AddDocument stores a document of a staff member with its photo; ErrNotFound if there is no such staff member.
+	AddDocument(document *models.StaffDocument, photo *models.Photo) error
+	// GetDocuments lists the documents of a staff member, without their data, newest first.
+	GetDocuments(staffID int64) ([]models.StaffDocument, error)
+	// GetDocumentPhoto returns the photo of a document; ErrNotFound if the staff member has no such document.
+	GetDocumentPhoto(staffID, documentID int64) (*models.Photo, error)
+	DeleteDocument(staffID, documentID int64) error
+	// GetExpiringDocuments lists the documents expiring on or before a date (YYYY-MM-DD) that were
+	// not replaced by a newer document of the same type and title, first expiry first.
+	GetExpiringDocuments(expiresOnOrBefore string) ([]models.StaffDocument, error)
+	// MarkExpiryNotified marks the documents GetExpiringDocuments would list, and that were not
+	// marked yet, as notified at the given time and returns them.
+	MarkExpiryNotified(executor SQLExecutor, expiresOnOrBefore string, notifiedAt time.Time) ([]models.StaffDocument, error)
+}
+
+type staffDocumentRepository struct {
+	db *sql.DB
+}
+
+// NewStaffDocumentRepository creates a new instance of StaffDocumentRepository.
+func NewStaffDocumentRepository(db *sql.DB) StaffDocumentRepository {
+	return &staffDocumentRepository{db: db}
+}
+
+const staffDocumentColumns = `d.id, d.staff_id, COALESCE(NULLIF(u.full_name, ''), u.username, 'Staff #' || d.staff_id), d.document_type,
+	d.title, d.content_type, octet_length(d.data), TO_CHAR(d.expires_on, 'YYYY-MM-DD'), d.uploaded_by, d.created_at, d.expiry_notified_at`
+
+// staffDocumentCurrent holds for a document d not replaced by a newer one of the same type and title.
+const staffDocumentCurrent = `NOT EXISTS (SELECT 1 FROM staff_documents n
+	                                          WHERE n.staff_id = d.staff_id AND n.document_type = d.document_type
+	                                            AND COALESCE(n.title, '') = COALESCE(d.title, '')
+	                                            AND (n.created_at, n.id) > (d.created_at, d.id))`
+
+func scanStaffDocument(row scanner) (*models.StaffDocument, error) {
+	var document models.StaffDocument
+	err := row.Scan(&document.ID, &document.StaffID, &document.StaffName, &document.DocumentType, &document.Title,
+		&document.ContentType, &document.Size, &document.ExpiresOn, &document.UploadedBy, &document.CreatedAt,
+		&document.ExpiryNotifiedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// scanStaffDocuments reads the documents of rows, which it closes.
+func scanStaffDocuments(rows *sql.Rows) ([]models.StaffDocument, error) {
+	defer rows.Close()
+	documents := []models.StaffDocument{}
+	for rows.Next() {
+		document, err := scanStaffDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning staff document: %v", ErrDatabaseError, err)
+		}
+		documents = append(documents, *document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating staff documents: %v", ErrDatabaseError, err)
+	}
+	return documents, nil
+}
+
+func (r *staffDocumentRepository) AddDocument(document *models.StaffDocument, photo *models.Photo) error {
+	document.ContentType = photo.ContentType
+	document.Size = len(photo.Data)
+	err := r.db.QueryRow(`INSERT INTO staff_documents (staff_id, document_type, title, content_type, data, expires_on, uploaded_by, created_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	                      RETURNING id, created_at`,
+		document.StaffID, document.DocumentType, document.Title, photo.ContentType, photo.Data, document.ExpiresOn, document.UploadedBy,
+		time.Now().UTC(),
+	).Scan(&document.ID, &document.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "staff_documents_staff_id_fkey" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: adding document to staff ID %d: %v", ErrDatabaseError, document.StaffID, err)
+	}
+	return nil
+}
+
+func (r *staffDocumentRepository) GetDocuments(staffID int64) ([]models.StaffDocument, error) {
+	rows, err := r.db.Query(`SELECT `+staffDocumentColumns+`
+	                         FROM staff_documents d
+	                         JOIN staff_members s ON s.id = d.staff_id
+	                         LEFT JOIN users u ON u.id = s.user_id
+	                         WHERE d.staff_id = $1
+	                         ORDER BY d.created_at DESC, d.id DESC`, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing documents of staff ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	return scanStaffDocuments(rows)
+}
+
+func (r *staffDocumentRepository) GetDocumentPhoto(staffID, documentID int64) (*models.Photo, error) {
+	var photo models.Photo
+	err := r.db.QueryRow(`SELECT content_type, data FROM staff_documents WHERE id = $1 AND staff_id = $2`, documentID, staffID).
+		Scan(&photo.ContentType, &photo.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting photo of document ID %d of staff ID %d: %v", ErrDatabaseError, documentID, staffID, err)
+	}
+	return &photo, nil
+}
+
+func (r *staffDocumentRepository) DeleteDocument(staffID, documentID int64) error {
+	result, err := r.db.Exec(`DELETE FROM staff_documents WHERE id = $1 AND staff_id = $2`, documentID, staffID)
+	if err != nil {
+		return fmt.Errorf("%w: deleting document ID %d of staff ID %d: %v", ErrDatabaseError, documentID, staffID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for document ID %d of staff ID %d: %v", ErrDatabaseError, documentID, staffID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *staffDocumentRepository) GetExpiringDocuments(expiresOnOrBefore string) ([]models.StaffDocument, error) {
+	rows, err := r.db.Query(`SELECT `+staffDocumentColumns+`
+	                         FROM staff_documents d
+	                         JOIN staff_members s ON s.id = d.staff_id
+	                         LEFT JOIN users u ON u.id = s.user_id
+	                         WHERE d.expires_on <= $1 AND `+staffDocumentCurrent+`
+	                         ORDER BY d.expires_on, d.staff_id, d.id`, expiresOnOrBefore)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing expiring staff documents: %v", ErrDatabaseError, err)
+	}
+	return scanStaffDocuments(rows)
+}
+
+func (r *staffDocumentRepository) MarkExpiryNotified(executor SQLExecutor, expiresOnOrBefore string, notifiedAt time.Time) ([]models.StaffDocument, error) {
+	rows, err := executor.Query(`UPDATE staff_documents d
+	                             SET expiry_notified_at = $2
+	                             FROM staff_members s
+	                             LEFT JOIN users u ON u.id = s.user_id
+	                             WHERE s.id = d.staff_id AND d.expires_on <= $1 AND d.expiry_notified_at IS NULL
+	                               AND `+staffDocumentCurrent+`
+	                             RETURNING `+staffDocumentColumns, expiresOnOrBefore, notifiedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: marking expiring staff documents notified: %v", ErrDatabaseError, err)
+	}
+	return scanStaffDocuments(rows)
+}
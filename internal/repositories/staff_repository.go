@@ -27,6 +27,22 @@ type StaffRepository interface {
 	GetShifts(staffID *int64, startTimeFrom *time.Time, startTimeTo *time.Time, page, pageSize int) ([]models.Shift, int, error)
 	UpdateShift(executor SQLExecutor, shift *models.Shift) (*models.Shift, error)
 	DeleteShift(executor SQLExecutor, id int64) error
+	// HasApprovedTimeOff reports whether approved time off of a staff member covers any day
+	// from fromDate to toDate (YYYY-MM-DD, inclusive).
+	HasApprovedTimeOff(staffID int64, fromDate, toDate string) (bool, error)
+
+	// Time clock methods
+	CreateTimeClockEntry(executor SQLExecutor, entry *models.TimeClockEntry) (*models.TimeClockEntry, error) // ErrDuplicateKey if the staff member is already clocked in
+	GetOpenTimeClockEntry(staffID int64) (*models.TimeClockEntry, error)                                    // ErrNotFound if not clocked in
+	CloseTimeClockEntry(executor SQLExecutor, entryID int64, clockOutAt time.Time) (*models.TimeClockEntry, error)
+	// Clock-in violation methods
+	CreateClockInViolation(violation *models.ClockInViolation) error
+	GetClockInViolationByID(id int64) (*models.ClockInViolation, error) // ErrNotFound if there is none
+	// GetClockInViolations lists the clock-in violations matching filters, latest first.
+	GetClockInViolations(filters models.ClockInViolationFilters) ([]models.ClockInViolation, error)
+	// OverrideClockInViolation records the override of a violation and the time clock entry it
+	// created; ErrNotFound if the violation does not exist or was already overridden.
+	OverrideClockInViolation(executor SQLExecutor, id, overriddenBy int64, overriddenAt time.Time, entryID int64) error
 }
 
 type staffRepository struct {
@@ -45,7 +61,7 @@ func (r *staffRepository) CreateStaffMember(executor SQLExecutor, staff *models.
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	          RETURNING id, created_at, updated_at`
 	
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	staff.CreatedAt = currentTime
 	staff.UpdatedAt = currentTime
 
@@ -112,11 +128,6 @@ func scanStaffMemberRow(row scanner) (*models.StaffMember, error) {
     return &staff, nil
 }
 
-// scanner is an interface compatible with *sql.Row and *sql.Rows.
-type scanner interface {
-    Scan(dest ...interface{}) error
-}
-
 
 func (r *staffRepository) GetStaffMemberByID(id int64) (*models.StaffMember, error) {
 	query := `SELECT 
@@ -245,7 +256,7 @@ func (r *staffRepository) UpdateStaffMember(executor SQLExecutor, staff *models.
 	          WHERE id = $7
 	          RETURNING updated_at` 
 	
-	staff.UpdatedAt = time.Now()
+	staff.UpdatedAt = time.Now().UTC()
 	var hireDate sql.NullString
 	if staff.HireDate != nil {
 		hireDate = sql.NullString{String: *staff.HireDate, Valid: true}
@@ -271,7 +282,7 @@ func (r *staffRepository) DeleteStaffMember(executor SQLExecutor, id int64) erro
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23503" { 
-			return fmt.Errorf("%w: staff member ID %d cannot be deleted as they are referenced in other records (constraint: %s)", ErrDatabaseError, id, pqErr.Constraint)
+			return &ConstraintError{Err: ErrReferenced, Constraint: pqErr.Constraint, Detail: fmt.Sprintf("staff member ID %d is referenced in other records", id)}
 		}
 		return fmt.Errorf("%w: deleting staff member ID %d: %v", ErrDatabaseError, id, err)
 	}
@@ -288,7 +299,7 @@ func (r *staffRepository) CreateShift(executor SQLExecutor, shift *models.Shift)
 	query := `INSERT INTO shifts (staff_id, start_time, end_time, notes, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4, $5, $6)
 	          RETURNING id, created_at, updated_at`
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	shift.CreatedAt = currentTime
 	shift.UpdatedAt = currentTime
 
@@ -368,7 +379,7 @@ func (r *staffRepository) GetShifts(staffID *int64, startTimeFrom *time.Time, st
 		argCount++
 	}
 	if startTimeTo != nil {
-		conditions = append(conditions, fmt.Sprintf("s.end_time <= $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("s.start_time < $%d", argCount))
 		args = append(args, *startTimeTo)
 		argCount++
 	}
@@ -434,7 +445,7 @@ func (r *staffRepository) UpdateShift(executor SQLExecutor, shift *models.Shift)
 	            staff_id = $1, start_time = $2, end_time = $3, notes = $4, updated_at = $5 
 	          WHERE id = $6
 	          RETURNING updated_at`
-	shift.UpdatedAt = time.Now()
+	shift.UpdatedAt = time.Now().UTC()
 
 	err := executor.QueryRow(query,
 		shift.StaffID, shift.StartTime, shift.EndTime, shift.Notes,
@@ -466,3 +477,148 @@ func (r *staffRepository) DeleteShift(executor SQLExecutor, id int64) error {
 	}
 	return nil
 }
+
+func (r *staffRepository) HasApprovedTimeOff(staffID int64, fromDate, toDate string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM staff_time_off
+	                                     WHERE staff_id = $1 AND status = 'approved' AND start_date <= $3 AND end_date >= $2)`,
+		staffID, fromDate, toDate).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%w: checking time off of staff ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	return exists, nil
+}
+
+// --- Time Clock Methods ---
+
+func (r *staffRepository) CreateTimeClockEntry(executor SQLExecutor, entry *models.TimeClockEntry) (*models.TimeClockEntry, error) {
+	query := `INSERT INTO time_clock_entries (staff_id, clock_in_at, created_at, updated_at)
+	          VALUES ($1, $2, $3, $3)
+	          RETURNING id, created_at, updated_at`
+	err := executor.QueryRow(query, entry.StaffID, entry.ClockInAt, time.Now().UTC()).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, fmt.Errorf("%w: staff member %d is already clocked in", ErrDuplicateKey, entry.StaffID)
+		}
+		return nil, fmt.Errorf("%w: creating time clock entry: %v", ErrDatabaseError, err)
+	}
+	return entry, nil
+}
+
+func (r *staffRepository) GetOpenTimeClockEntry(staffID int64) (*models.TimeClockEntry, error) {
+	entry := &models.TimeClockEntry{}
+	query := `SELECT id, staff_id, clock_in_at, clock_out_at, created_at, updated_at
+	          FROM time_clock_entries
+	          WHERE staff_id = $1 AND clock_out_at IS NULL`
+	err := r.db.QueryRow(query, staffID).Scan(
+		&entry.ID, &entry.StaffID, &entry.ClockInAt, &entry.ClockOutAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting open time clock entry of staff %d: %v", ErrDatabaseError, staffID, err)
+	}
+	return entry, nil
+}
+
+func (r *staffRepository) CloseTimeClockEntry(executor SQLExecutor, entryID int64, clockOutAt time.Time) (*models.TimeClockEntry, error) {
+	entry := &models.TimeClockEntry{}
+	query := `UPDATE time_clock_entries SET clock_out_at = $1, updated_at = $2
+	          WHERE id = $3 AND clock_out_at IS NULL
+	          RETURNING id, staff_id, clock_in_at, clock_out_at, created_at, updated_at`
+	err := executor.QueryRow(query, clockOutAt, time.Now().UTC(), entryID).Scan(
+		&entry.ID, &entry.StaffID, &entry.ClockInAt, &entry.ClockOutAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: closing time clock entry %d: %v", ErrDatabaseError, entryID, err)
+	}
+	return entry, nil
+}
+
+// --- Clock-in Violation Methods ---
+
+const clockInViolationColumns = `v.id, v.staff_id, COALESCE(NULLIF(u.full_name, ''), u.username, 'Staff #' || sm.id), v.reason,
+	v.ip_address, v.latitude, v.longitude, v.distance_meters, v.attempted_at, v.overridden_by, v.overridden_at, v.time_clock_entry_id`
+
+const clockInViolationFrom = `FROM clock_in_violations v
+	JOIN staff_members sm ON sm.id = v.staff_id
+	LEFT JOIN users u ON u.id = sm.user_id`
+
+func scanClockInViolation(row scanner) (*models.ClockInViolation, error) {
+	var violation models.ClockInViolation
+	err := row.Scan(&violation.ID, &violation.StaffID, &violation.StaffName, &violation.Reason, &violation.IPAddress,
+		&violation.Latitude, &violation.Longitude, &violation.DistanceMeters, &violation.AttemptedAt,
+		&violation.OverriddenBy, &violation.OverriddenAt, &violation.TimeClockEntryID)
+	if err != nil {
+		return nil, err
+	}
+	return &violation, nil
+}
+
+func (r *staffRepository) CreateClockInViolation(violation *models.ClockInViolation) error {
+	err := r.db.QueryRow(`INSERT INTO clock_in_violations (staff_id, reason, ip_address, latitude, longitude, distance_meters, attempted_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7)
+	                      RETURNING id`,
+		violation.StaffID, violation.Reason, violation.IPAddress, violation.Latitude, violation.Longitude,
+		violation.DistanceMeters, violation.AttemptedAt,
+	).Scan(&violation.ID)
+	if err != nil {
+		return fmt.Errorf("%w: creating clock-in violation: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+func (r *staffRepository) GetClockInViolationByID(id int64) (*models.ClockInViolation, error) {
+	violation, err := scanClockInViolation(r.db.QueryRow(`SELECT `+clockInViolationColumns+` `+clockInViolationFrom+` WHERE v.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting clock-in violation ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return violation, nil
+}
+
+func (r *staffRepository) GetClockInViolations(filters models.ClockInViolationFilters) ([]models.ClockInViolation, error) {
+	rows, err := r.db.Query(`SELECT `+clockInViolationColumns+` `+clockInViolationFrom+`
+	                         WHERE ($1::BIGINT IS NULL OR v.staff_id = $1)
+	                           AND ($2::BOOLEAN IS NULL OR (v.overridden_at IS NOT NULL) = $2)
+	                           AND ($3::TIMESTAMPTZ IS NULL OR v.attempted_at >= $3)
+	                           AND ($4::TIMESTAMPTZ IS NULL OR v.attempted_at < $4)
+	                         ORDER BY v.attempted_at DESC, v.id DESC`,
+		filters.StaffID, filters.Overridden, filters.From, filters.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing clock-in violations: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	violations := []models.ClockInViolation{}
+	for rows.Next() {
+		violation, err := scanClockInViolation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning clock-in violation: %v", ErrDatabaseError, err)
+		}
+		violations = append(violations, *violation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating clock-in violations: %v", ErrDatabaseError, err)
+	}
+	return violations, nil
+}
+
+func (r *staffRepository) OverrideClockInViolation(executor SQLExecutor, id, overriddenBy int64, overriddenAt time.Time, entryID int64) error {
+	result, err := executor.Exec(`UPDATE clock_in_violations SET overridden_by = $1, overridden_at = $2, time_clock_entry_id = $3
+	                              WHERE id = $4 AND overridden_at IS NULL`, overriddenBy, overriddenAt, entryID, id)
+	if err != nil {
+		return fmt.Errorf("%w: overriding clock-in violation %d: %v", ErrDatabaseError, id, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+)
+
+// StockBatchRepository defines the database operations for batches of perishable stock.
+type StockBatchRepository interface {
+	CreateBatch(executor SQLExecutor, batch *models.StockBatch) error
+	// GetBatchByID returns a batch; ErrNotFound if there is none.
+	GetBatchByID(id int64) (*models.StockBatch, error)
+	// GetBatches lists the batches matching filters in the order they are used: first expiry first.
+	GetBatches(filters models.StockBatchFilters) ([]models.StockBatch, error)
+	// DeductFEFO takes up to quantity of an item from its batches, first expiry first, recording
+	// the deductions against movementID and, for a sale, orderID. Stock beyond the batches is not
+	// in any batch, so the part of quantity the batches do not hold is left alone.
+	DeductFEFO(executor SQLExecutor, itemID int64, quantity models.Quantity, movementID int64, orderID *int64) error
+	// DeductBatch takes quantity from a batch, recording the deduction against movementID;
+	// ErrVersionConflict if the batch holds less in the meantime.
+	DeductBatch(executor SQLExecutor, batchID int64, quantity models.Quantity, movementID int64) error
+	// RestoreOrderDeductions puts the stock an order took of an item back in its batches.
+	RestoreOrderDeductions(executor SQLExecutor, orderID, itemID int64) error
+	// WriteOffBatch empties a batch as written off and returns the quantity it held; 0 if it
+	// was already empty.
+	WriteOffBatch(executor SQLExecutor, batchID int64) (models.Quantity, error)
+	// SetWriteOffMovement links a written off batch to the movement recording its write-off.
+	SetWriteOffMovement(executor SQLExecutor, batchID int64, movementID int64) error
+}
+
+type stockBatchRepository struct {
+	db *sql.DB
+}
+
+// NewStockBatchRepository creates a new instance of StockBatchRepository.
+func NewStockBatchRepository(db *sql.DB) StockBatchRepository {
+	return &stockBatchRepository{db: db}
+}
+
+const stockBatchColumns = `b.id, b.pricelist_item_id, pi.name, pi.stock_unit, b.batch_number, TO_CHAR(b.expiry_date, 'YYYY-MM-DD'),
+	b.quantity_received, b.quantity_remaining, b.received_at, b.movement_id, b.written_off_at, b.write_off_movement_id`
+
+func scanStockBatch(row scanner) (*models.StockBatch, error) {
+	var batch models.StockBatch
+	err := row.Scan(&batch.ID, &batch.PricelistItemID, &batch.ItemName, &batch.StockUnit, &batch.BatchNumber, &batch.ExpiryDate,
+		&batch.QuantityReceived, &batch.QuantityRemaining, &batch.ReceivedAt, &batch.MovementID, &batch.WrittenOffAt,
+		&batch.WriteOffMovementID)
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *stockBatchRepository) CreateBatch(executor SQLExecutor, batch *models.StockBatch) error {
+	err := executor.QueryRow(`INSERT INTO stock_batches
+	                          (pricelist_item_id, batch_number, expiry_date, quantity_received, quantity_remaining, received_at, movement_id)
+	                          VALUES ($1, $2, $3, $4, $4, $5, $6)
+	                          RETURNING id`,
+		batch.PricelistItemID, batch.BatchNumber, batch.ExpiryDate, batch.QuantityReceived, batch.ReceivedAt, batch.MovementID,
+	).Scan(&batch.ID)
+	if err != nil {
+		return fmt.Errorf("%w: creating stock batch of item ID %d: %v", ErrDatabaseError, batch.PricelistItemID, err)
+	}
+	batch.QuantityRemaining = batch.QuantityReceived
+	return nil
+}
+
+func (r *stockBatchRepository) GetBatchByID(id int64) (*models.StockBatch, error) {
+	batch, err := scanStockBatch(r.db.QueryRow(`SELECT `+stockBatchColumns+`
+	                                            FROM stock_batches b
+	                                            JOIN pricelist_items pi ON pi.id = b.pricelist_item_id
+	                                            WHERE b.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting stock batch ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return batch, nil
+}
+
+func (r *stockBatchRepository) GetBatches(filters models.StockBatchFilters) ([]models.StockBatch, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	argCounter := 1
+
+	if filters.ItemID != nil {
+		conditions = append(conditions, fmt.Sprintf("b.pricelist_item_id = $%d", argCounter))
+		args = append(args, *filters.ItemID)
+		argCounter++
+	}
+	if filters.InStock {
+		conditions = append(conditions, "b.quantity_remaining > 0")
+	}
+	if filters.ExpiresOnOrBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("b.expiry_date <= $%d", argCounter))
+		args = append(args, *filters.ExpiresOnOrBefore)
+	}
+
+	rows, err := r.db.Query(`SELECT `+stockBatchColumns+`
+	                         FROM stock_batches b
+	                         JOIN pricelist_items pi ON pi.id = b.pricelist_item_id
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY b.expiry_date NULLS LAST, b.received_at, b.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing stock batches: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	batches := []models.StockBatch{}
+	for rows.Next() {
+		batch, err := scanStockBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning stock batch: %v", ErrDatabaseError, err)
+		}
+		batches = append(batches, *batch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating stock batches: %v", ErrDatabaseError, err)
+	}
+	return batches, nil
+}
+
+func (r *stockBatchRepository) DeductFEFO(executor SQLExecutor, itemID int64, quantity models.Quantity, movementID int64, orderID *int64) error {
+	rows, err := executor.Query(`SELECT id, quantity_remaining FROM stock_batches
+	                             WHERE pricelist_item_id = $1 AND quantity_remaining > 0
+	                             ORDER BY expiry_date NULLS LAST, received_at, id
+	                             FOR UPDATE`, itemID)
+	if err != nil {
+		return fmt.Errorf("%w: locking stock batches of item ID %d: %v", ErrDatabaseError, itemID, err)
+	}
+	type deduction struct {
+		batchID  int64
+		quantity models.Quantity
+	}
+	var deductions []deduction
+	for quantity.IsPositive() && rows.Next() {
+		var batchID int64
+		var remaining models.Quantity
+		if err := rows.Scan(&batchID, &remaining); err != nil {
+			rows.Close()
+			return fmt.Errorf("%w: scanning stock batch: %v", ErrDatabaseError, err)
+		}
+		taken := remaining.Min(quantity)
+		deductions = append(deductions, deduction{batchID: batchID, quantity: taken})
+		quantity = quantity.Sub(taken)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("%w: iterating stock batches: %v", ErrDatabaseError, err)
+	}
+	rows.Close()
+
+	for _, d := range deductions {
+		if err := recordBatchDeduction(executor, d.batchID, d.quantity, movementID, orderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordBatchDeduction takes quantity from a batch and records it against the movement.
+func recordBatchDeduction(executor SQLExecutor, batchID int64, quantity models.Quantity, movementID int64, orderID *int64) error {
+	if _, err := executor.Exec(`UPDATE stock_batches SET quantity_remaining = quantity_remaining - $2 WHERE id = $1`, batchID, quantity); err != nil {
+		return fmt.Errorf("%w: deducting from stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	if _, err := executor.Exec(`INSERT INTO stock_batch_deductions (batch_id, movement_id, order_id, quantity) VALUES ($1, $2, $3, $4)`,
+		batchID, movementID, orderID, quantity); err != nil {
+		return fmt.Errorf("%w: recording deduction from stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	return nil
+}
+
+func (r *stockBatchRepository) DeductBatch(executor SQLExecutor, batchID int64, quantity models.Quantity, movementID int64) error {
+	result, err := executor.Exec(`UPDATE stock_batches SET quantity_remaining = quantity_remaining - $2
+	                              WHERE id = $1 AND quantity_remaining >= $2`, batchID, quantity)
+	if err != nil {
+		return fmt.Errorf("%w: deducting from stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	if rowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	if _, err := executor.Exec(`INSERT INTO stock_batch_deductions (batch_id, movement_id, quantity) VALUES ($1, $2, $3)`,
+		batchID, movementID, quantity); err != nil {
+		return fmt.Errorf("%w: recording deduction from stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	return nil
+}
+
+func (r *stockBatchRepository) RestoreOrderDeductions(executor SQLExecutor, orderID, itemID int64) error {
+	_, err := executor.Exec(`WITH restored AS (
+	                             DELETE FROM stock_batch_deductions d
+	                             USING stock_batches b
+	                             WHERE d.batch_id = b.id AND d.order_id = $1 AND b.pricelist_item_id = $2
+	                             RETURNING d.batch_id, d.quantity
+	                         )
+	                         UPDATE stock_batches b
+	                         SET quantity_remaining = b.quantity_remaining + r.quantity
+	                         FROM (SELECT batch_id, SUM(quantity) AS quantity FROM restored GROUP BY batch_id) r
+	                         WHERE b.id = r.batch_id`, orderID, itemID)
+	if err != nil {
+		return fmt.Errorf("%w: restoring stock batches of order ID %d: %v", ErrDatabaseError, orderID, err)
+	}
+	return nil
+}
+
+func (r *stockBatchRepository) WriteOffBatch(executor SQLExecutor, batchID int64) (models.Quantity, error) {
+	var quantity models.Quantity
+	err := executor.QueryRow(`UPDATE stock_batches b
+	                          SET quantity_remaining = 0, written_off_at = $2
+	                          FROM (SELECT id, quantity_remaining FROM stock_batches WHERE id = $1 FOR UPDATE) old
+	                          WHERE b.id = old.id AND old.quantity_remaining > 0
+	                          RETURNING old.quantity_remaining`, batchID, time.Now().UTC()).Scan(&quantity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ZeroQuantity, nil
+		}
+		return models.ZeroQuantity, fmt.Errorf("%w: writing off stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	return quantity, nil
+}
+
+func (r *stockBatchRepository) SetWriteOffMovement(executor SQLExecutor, batchID int64, movementID int64) error {
+	if _, err := executor.Exec(`UPDATE stock_batches SET write_off_movement_id = $2 WHERE id = $1`, batchID, movementID); err != nil {
+		return fmt.Errorf("%w: linking write-off of stock batch ID %d: %v", ErrDatabaseError, batchID, err)
+	}
+	return nil
+}
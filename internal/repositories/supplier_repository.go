@@ -0,0 +1,294 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// SupplierRepository defines the database operations for suppliers and their price lists.
+type SupplierRepository interface {
+	// CreateSupplier inserts a supplier; a ConstraintError matching ErrDuplicateKey if the name is taken.
+	CreateSupplier(supplier *models.Supplier) error
+	// GetSupplierByID returns a supplier; ErrNotFound if there is none.
+	GetSupplierByID(id int64) (*models.Supplier, error)
+	// GetSuppliers lists all suppliers by name.
+	GetSuppliers() ([]models.Supplier, error)
+	// UpdateSupplier updates a supplier; ErrNotFound if there is none, or a ConstraintError
+	// matching ErrDuplicateKey if the name is taken.
+	UpdateSupplier(supplier *models.Supplier) error
+	// DeleteSupplier deletes a supplier with its price list.
+	DeleteSupplier(id int64) error
+	// GetSupplierPrices returns the price list of a supplier by item name.
+	GetSupplierPrices(supplierID int64) ([]models.SupplierPrice, error)
+	// UpsertSupplierPrice inserts or replaces the price of an item of a supplier.
+	UpsertSupplierPrice(executor SQLExecutor, price *models.SupplierPrice) error
+	// GetItemIDs returns which of ids are pricelist items.
+	GetItemIDs(ids []int64) (map[int64]bool, error)
+	// GetItemIDsBySKU returns the IDs of the pricelist items with the given SKUs, by SKU.
+	GetItemIDsBySKU(skus []string) (map[string]int64, error)
+	// GetOffers returns the offers of all suppliers for the given items, by item ID, cheapest first.
+	GetOffers(itemIDs []int64) (map[int64][]models.SupplierOffer, error)
+	// GetLowStockConsumption returns the available stock-tracked items at or below their low stock
+	// threshold, with how much of each was consumed since the given time.
+	GetLowStockConsumption(since time.Time, consumptionTypes []string) ([]models.StockConsumption, error)
+}
+
+type supplierRepository struct {
+	db *sql.DB
+}
+
+// NewSupplierRepository creates a new instance of SupplierRepository.
+func NewSupplierRepository(db *sql.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+const supplierColumns = `id, name, contact_name, phone_number, email, notes, created_at, updated_at`
+
+func scanSupplier(row scanner) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := row.Scan(&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.PhoneNumber, &supplier.Email,
+		&supplier.Notes, &supplier.CreatedAt, &supplier.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// supplierWriteError converts the error of an insert or update of a supplier.
+func supplierWriteError(err error, action string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+		return &ConstraintError{Err: ErrDuplicateKey, Constraint: pqErr.Constraint, Detail: "a supplier with this name already exists"}
+	}
+	return fmt.Errorf("%w: %s: %v", ErrDatabaseError, action, err)
+}
+
+func (r *supplierRepository) CreateSupplier(supplier *models.Supplier) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO suppliers (name, contact_name, phone_number, email, notes, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $6)
+	                      RETURNING id, created_at, updated_at`,
+		supplier.Name, supplier.ContactName, supplier.PhoneNumber, supplier.Email, supplier.Notes, now,
+	).Scan(&supplier.ID, &supplier.CreatedAt, &supplier.UpdatedAt)
+	if err != nil {
+		return supplierWriteError(err, "creating supplier")
+	}
+	return nil
+}
+
+func (r *supplierRepository) GetSupplierByID(id int64) (*models.Supplier, error) {
+	supplier, err := scanSupplier(r.db.QueryRow(`SELECT `+supplierColumns+` FROM suppliers WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting supplier ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return supplier, nil
+}
+
+func (r *supplierRepository) GetSuppliers() ([]models.Supplier, error) {
+	rows, err := r.db.Query(`SELECT ` + supplierColumns + ` FROM suppliers ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing suppliers: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	suppliers := []models.Supplier{}
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning supplier: %v", ErrDatabaseError, err)
+		}
+		suppliers = append(suppliers, *supplier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating suppliers: %v", ErrDatabaseError, err)
+	}
+	return suppliers, nil
+}
+
+func (r *supplierRepository) UpdateSupplier(supplier *models.Supplier) error {
+	err := r.db.QueryRow(`UPDATE suppliers
+	                      SET name = $2, contact_name = $3, phone_number = $4, email = $5, notes = $6, updated_at = $7
+	                      WHERE id = $1
+	                      RETURNING updated_at`,
+		supplier.ID, supplier.Name, supplier.ContactName, supplier.PhoneNumber, supplier.Email, supplier.Notes, time.Now().UTC(),
+	).Scan(&supplier.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return supplierWriteError(err, fmt.Sprintf("updating supplier ID %d", supplier.ID))
+	}
+	return nil
+}
+
+func (r *supplierRepository) DeleteSupplier(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM suppliers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("%w: deleting supplier ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for supplier ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *supplierRepository) GetSupplierPrices(supplierID int64) ([]models.SupplierPrice, error) {
+	rows, err := r.db.Query(`SELECT sp.supplier_id, sp.pricelist_item_id, pi.name, pi.sku, sp.price, sp.min_order_quantity,
+	                                sp.supplier_sku, sp.updated_at
+	                         FROM supplier_prices sp
+	                         JOIN pricelist_items pi ON pi.id = sp.pricelist_item_id
+	                         WHERE sp.supplier_id = $1
+	                         ORDER BY pi.name, pi.id`, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing prices of supplier ID %d: %v", ErrDatabaseError, supplierID, err)
+	}
+	defer rows.Close()
+
+	prices := []models.SupplierPrice{}
+	for rows.Next() {
+		var price models.SupplierPrice
+		if err := rows.Scan(&price.SupplierID, &price.PricelistItemID, &price.ItemName, &price.ItemSKU, &price.Price,
+			&price.MinOrderQuantity, &price.SupplierSKU, &price.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning supplier price: %v", ErrDatabaseError, err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating supplier prices: %v", ErrDatabaseError, err)
+	}
+	return prices, nil
+}
+
+func (r *supplierRepository) UpsertSupplierPrice(executor SQLExecutor, price *models.SupplierPrice) error {
+	err := executor.QueryRow(`INSERT INTO supplier_prices (supplier_id, pricelist_item_id, price, min_order_quantity, supplier_sku, updated_at)
+	                          VALUES ($1, $2, $3, $4, $5, $6)
+	                          ON CONFLICT (supplier_id, pricelist_item_id) DO UPDATE
+	                          SET price = EXCLUDED.price, min_order_quantity = EXCLUDED.min_order_quantity,
+	                              supplier_sku = EXCLUDED.supplier_sku, updated_at = EXCLUDED.updated_at
+	                          RETURNING updated_at`,
+		price.SupplierID, price.PricelistItemID, price.Price, price.MinOrderQuantity, price.SupplierSKU, time.Now().UTC(),
+	).Scan(&price.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: saving price of item ID %d for supplier ID %d: %v", ErrDatabaseError, price.PricelistItemID, price.SupplierID, err)
+	}
+	return nil
+}
+
+func (r *supplierRepository) GetItemIDs(ids []int64) (map[int64]bool, error) {
+	found := make(map[int64]bool, len(ids))
+	if len(ids) == 0 {
+		return found, nil
+	}
+	rows, err := r.db.Query(`SELECT id FROM pricelist_items WHERE id = ANY($1) AND deleted_at IS NULL`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%w: looking up pricelist items: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%w: scanning pricelist item ID: %v", ErrDatabaseError, err)
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating pricelist item IDs: %v", ErrDatabaseError, err)
+	}
+	return found, nil
+}
+
+func (r *supplierRepository) GetItemIDsBySKU(skus []string) (map[string]int64, error) {
+	found := make(map[string]int64, len(skus))
+	if len(skus) == 0 {
+		return found, nil
+	}
+	rows, err := r.db.Query(`SELECT sku, id FROM pricelist_items WHERE sku = ANY($1) AND deleted_at IS NULL`, pq.Array(skus))
+	if err != nil {
+		return nil, fmt.Errorf("%w: looking up pricelist items by SKU: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sku string
+		var id int64
+		if err := rows.Scan(&sku, &id); err != nil {
+			return nil, fmt.Errorf("%w: scanning pricelist item SKU: %v", ErrDatabaseError, err)
+		}
+		found[sku] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating pricelist item SKUs: %v", ErrDatabaseError, err)
+	}
+	return found, nil
+}
+
+func (r *supplierRepository) GetOffers(itemIDs []int64) (map[int64][]models.SupplierOffer, error) {
+	offers := make(map[int64][]models.SupplierOffer, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return offers, nil
+	}
+	rows, err := r.db.Query(`SELECT sp.pricelist_item_id, s.id, s.name, sp.price, sp.min_order_quantity, sp.supplier_sku
+	                         FROM supplier_prices sp
+	                         JOIN suppliers s ON s.id = sp.supplier_id
+	                         WHERE sp.pricelist_item_id = ANY($1)
+	                         ORDER BY sp.pricelist_item_id, sp.price, sp.min_order_quantity, s.name`, pq.Array(itemIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing supplier offers: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var itemID int64
+		var offer models.SupplierOffer
+		if err := rows.Scan(&itemID, &offer.SupplierID, &offer.SupplierName, &offer.Price, &offer.MinOrderQuantity, &offer.SupplierSKU); err != nil {
+			return nil, fmt.Errorf("%w: scanning supplier offer: %v", ErrDatabaseError, err)
+		}
+		offers[itemID] = append(offers[itemID], offer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating supplier offers: %v", ErrDatabaseError, err)
+	}
+	return offers, nil
+}
+
+func (r *supplierRepository) GetLowStockConsumption(since time.Time, consumptionTypes []string) ([]models.StockConsumption, error) {
+	rows, err := r.db.Query(`SELECT pi.id, pi.name, pi.sku, pi.stock_unit, pi.current_stock, pi.low_stock_threshold,
+	                                COALESCE(-SUM(im.quantity_changed), 0), pi.purchase_unit, pi.purchase_unit_size
+	                         FROM pricelist_items pi
+	                         LEFT JOIN inventory_movements im ON im.pricelist_item_id = pi.id
+	                              AND im.movement_date >= $1 AND im.movement_type = ANY($2)
+	                         WHERE pi.tracks_stock = TRUE AND pi.is_available = TRUE AND pi.deleted_at IS NULL
+	                           AND pi.current_stock IS NOT NULL AND pi.low_stock_threshold IS NOT NULL
+	                           AND pi.current_stock <= pi.low_stock_threshold
+	                         GROUP BY pi.id
+	                         ORDER BY pi.name, pi.id`, since, pq.Array(consumptionTypes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing low stock items: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	items := []models.StockConsumption{}
+	for rows.Next() {
+		var item models.StockConsumption
+		if err := rows.Scan(&item.PricelistItemID, &item.ItemName, &item.SKU, &item.StockUnit, &item.CurrentStock,
+			&item.LowStockThreshold, &item.Consumed, &item.PurchaseUnit, &item.PurchaseUnitSize); err != nil {
+			return nil, fmt.Errorf("%w: scanning low stock item: %v", ErrDatabaseError, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating low stock items: %v", ErrDatabaseError, err)
+	}
+	return items, nil
+}
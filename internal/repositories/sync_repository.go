@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+)
+
+// SyncRepository defines the database operations of offline sync. Every change of a synced
+// entity takes the next number of the sync_versions sequence, and a deletion leaves a tombstone
+// with one.
+type SyncRepository interface {
+	// GetChanges returns up to limit records of entity (one of models.SyncEntities) changed after
+	// version since, in version order.
+	GetChanges(entity string, since int64, limit int) ([]models.SyncChange, error)
+	// GetDeletions returns up to limit records of the synced entities deleted after version since,
+	// in version order.
+	GetDeletions(since int64, limit int) ([]models.SyncChange, error)
+}
+
+type syncRepository struct {
+	db *sql.DB
+}
+
+// NewSyncRepository creates a new instance of SyncRepository.
+func NewSyncRepository(db *sql.DB) SyncRepository {
+	return &syncRepository{db: db}
+}
+
+// syncQueries select the changes of each entity after $1, at most $2: the version, then the
+// columns scanned by scanSyncRecord.
+var syncQueries = map[string]string{
+	models.SyncEntityPricelistCategory: `SELECT sync_version, id, name FROM pricelist_categories
+	                                     WHERE sync_version > $1 ORDER BY sync_version LIMIT $2`,
+	models.SyncEntityPricelistItem: `SELECT sync_version, id, category_id, name, price, sku, is_available, item_type, tracks_stock,
+	                                        current_stock, tax_class
+	                                 FROM pricelist_items
+	                                 WHERE sync_version > $1 AND deleted_at IS NULL ORDER BY sync_version LIMIT $2`,
+	models.SyncEntityGameTable: `SELECT ` + gameTableColumns + `, sync_version FROM game_tables
+	                             WHERE sync_version > $1 ORDER BY sync_version LIMIT $2`,
+	models.SyncEntityClient: `SELECT sync_version, id, full_name, phone_number, loyalty_points, ` + clientBlacklistedColumn + `
+	                          FROM clients
+	                          WHERE sync_version > $1 AND deleted_at IS NULL ORDER BY sync_version LIMIT $2`,
+}
+
+// scanSyncRecord scans a row of the sync query of entity into a change.
+func scanSyncRecord(entity string, row scanner) (models.SyncChange, error) {
+	change := models.SyncChange{Entity: entity}
+	switch entity {
+	case models.SyncEntityPricelistCategory:
+		var category models.SyncPricelistCategory
+		if err := row.Scan(&change.Version, &category.ID, &category.Name); err != nil {
+			return change, err
+		}
+		change.ID, change.Data = category.ID, category
+	case models.SyncEntityPricelistItem:
+		var item models.SyncPricelistItem
+		err := row.Scan(&change.Version, &item.ID, &item.CategoryID, &item.Name, &item.Price, &item.SKU, &item.IsAvailable,
+			&item.ItemType, &item.TracksStock, &item.CurrentStock, &item.TaxClass)
+		if err != nil {
+			return change, err
+		}
+		change.ID, change.Data = item.ID, item
+	case models.SyncEntityGameTable:
+		table, err := scanGameTable(row, &change.Version)
+		if err != nil {
+			return change, err
+		}
+		change.ID, change.Data = table.ID, table
+	case models.SyncEntityClient:
+		var client models.SyncClient
+		err := row.Scan(&change.Version, &client.ID, &client.FullName, &client.PhoneNumber, &client.LoyaltyPoints, &client.Blacklisted)
+		if err != nil {
+			return change, err
+		}
+		change.ID, change.Data = client.ID, client
+	}
+	return change, nil
+}
+
+func (r *syncRepository) GetChanges(entity string, since int64, limit int) ([]models.SyncChange, error) {
+	query, ok := syncQueries[entity]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sync entity %q", ErrDatabaseError, entity)
+	}
+	rows, err := r.db.Query(query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting %s changes: %v", ErrDatabaseError, entity, err)
+	}
+	defer rows.Close()
+
+	changes := []models.SyncChange{}
+	for rows.Next() {
+		change, err := scanSyncRecord(entity, rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning %s change: %v", ErrDatabaseError, entity, err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating %s changes: %v", ErrDatabaseError, entity, err)
+	}
+	return changes, nil
+}
+
+func (r *syncRepository) GetDeletions(since int64, limit int) ([]models.SyncChange, error) {
+	rows, err := r.db.Query(`SELECT sync_version, entity, entity_id FROM sync_tombstones
+	                         WHERE sync_version > $1 ORDER BY sync_version LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: getting deletions: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	deletions := []models.SyncChange{}
+	for rows.Next() {
+		deletion := models.SyncChange{Deleted: true}
+		if err := rows.Scan(&deletion.Version, &deletion.Entity, &deletion.ID); err != nil {
+			return nil, fmt.Errorf("%w: scanning deletion: %v", ErrDatabaseError, err)
+		}
+		deletions = append(deletions, deletion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating deletions: %v", ErrDatabaseError, err)
+	}
+	return deletions, nil
+}
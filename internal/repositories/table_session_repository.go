@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// TableSessionRepository defines the database operations for table sessions.
+type TableSessionRepository interface {
+	// CreateTableSession returns ErrDuplicateKey if the table already runs a session.
+	CreateTableSession(executor SQLExecutor, session *models.TableSession) (*models.TableSession, error)
+	GetTableSessionByID(id int64) (*models.TableSession, error)
+	// GetRunningTableSessions returns the sessions that have not been stopped, oldest first.
+	GetRunningTableSessions() ([]models.TableSession, error)
+	// GetTimedTableSessions returns the active sessions with a time limit ending before until.
+	GetTimedTableSessions(until time.Time) ([]models.TableSession, error)
+	// MarkTableSessionWarned records that the warning for minutes remaining was sent. It reports
+	// false if the session is no longer active or a warning for as few minutes was already sent,
+	// so each warning is sent once even if several instances run the timers.
+	MarkTableSessionWarned(executor SQLExecutor, id int64, minutes int, now time.Time) (bool, error)
+	// SetTableSessionOvertime moves an active session to overtime. It reports false if the session
+	// is no longer active.
+	SetTableSessionOvertime(executor SQLExecutor, id int64, now time.Time) (bool, error)
+	// StopTableSession records the stop of session, which must still have the status it was
+	// read with; ErrVersionConflict if it changed in the meantime.
+	StopTableSession(executor SQLExecutor, session *models.TableSession, fromStatus string) error
+}
+
+type tableSessionRepository struct {
+	db *sql.DB
+}
+
+// NewTableSessionRepository creates a new instance of TableSessionRepository.
+func NewTableSessionRepository(db *sql.DB) TableSessionRepository {
+	return &tableSessionRepository{db: db}
+}
+
+const tableSessionColumns = `id, table_id, booking_id, client_id, started_by, started_at, limit_minutes, ends_at, on_expiry,
+	overtime_rate, controllers, hourly_rate, status, warned_minutes, stopped_at, stopped_by, overtime_minutes, overtime_amount,
+	time_amount, total_amount, created_at, updated_at, billing_mode, minute_rate, billing_increment_minutes, minimum_charge,
+	billed_minutes, minimum_charge_amount, locker_amount`
+
+func scanTableSession(row scanner) (*models.TableSession, error) {
+	var session models.TableSession
+	var limitMinutes, controllers, warnedMinutes, incrementMinutes sql.NullInt32
+	err := row.Scan(
+		&session.ID, &session.TableID, &session.BookingID, &session.ClientID, &session.StartedBy, &session.StartedAt,
+		&limitMinutes, &session.EndsAt, &session.OnExpiry, &session.OvertimeRate, &controllers, &session.HourlyRate, &session.Status,
+		&warnedMinutes, &session.StoppedAt, &session.StoppedBy, &session.OvertimeMinutes, &session.OvertimeAmount,
+		&session.TimeAmount, &session.TotalAmount, &session.CreatedAt, &session.UpdatedAt, &session.BillingMode, &session.MinuteRate,
+		&incrementMinutes, &session.MinimumCharge, &session.BilledMinutes, &session.MinimumChargeAmount, &session.LockerAmount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if limitMinutes.Valid {
+		minutes := int(limitMinutes.Int32)
+		session.LimitMinutes = &minutes
+	}
+	if controllers.Valid {
+		count := int(controllers.Int32)
+		session.Controllers = &count
+	}
+	if warnedMinutes.Valid {
+		minutes := int(warnedMinutes.Int32)
+		session.WarnedMinutes = &minutes
+	}
+	if incrementMinutes.Valid {
+		minutes := int(incrementMinutes.Int32)
+		session.BillingIncrementMinutes = &minutes
+	}
+	return &session, nil
+}
+
+func (r *tableSessionRepository) CreateTableSession(executor SQLExecutor, session *models.TableSession) (*models.TableSession, error) {
+	query := `INSERT INTO table_sessions (table_id, booking_id, client_id, started_by, started_at, limit_minutes, ends_at,
+	                                      on_expiry, overtime_rate, controllers, hourly_rate, status, created_at, updated_at,
+	                                      billing_mode, minute_rate, billing_increment_minutes, minimum_charge)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13, $14, $15, $16, $17)
+	          RETURNING ` + tableSessionColumns
+	created, err := scanTableSession(executor.QueryRow(query,
+		session.TableID, session.BookingID, session.ClientID, session.StartedBy, session.StartedAt, session.LimitMinutes,
+		session.EndsAt, session.OnExpiry, session.OvertimeRate, session.Controllers, session.HourlyRate, session.Status, time.Now().UTC(),
+		session.BillingMode, session.MinuteRate, session.BillingIncrementMinutes, session.MinimumCharge,
+	))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" {
+			return nil, fmt.Errorf("%w: table ID %d already runs a session", ErrDuplicateKey, session.TableID)
+		}
+		return nil, fmt.Errorf("%w: creating table session: %v", ErrDatabaseError, err)
+	}
+	return created, nil
+}
+
+func (r *tableSessionRepository) GetTableSessionByID(id int64) (*models.TableSession, error) {
+	session, err := scanTableSession(r.db.QueryRow(`SELECT `+tableSessionColumns+` FROM table_sessions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting table session ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return session, nil
+}
+
+func (r *tableSessionRepository) GetRunningTableSessions() ([]models.TableSession, error) {
+	return r.listTableSessions(`SELECT `+tableSessionColumns+` FROM table_sessions WHERE status <> $1 ORDER BY started_at, id`,
+		models.TableSessionStatusStopped)
+}
+
+func (r *tableSessionRepository) GetTimedTableSessions(until time.Time) ([]models.TableSession, error) {
+	return r.listTableSessions(`SELECT `+tableSessionColumns+` FROM table_sessions
+	                            WHERE status = $1 AND ends_at IS NOT NULL AND ends_at <= $2 ORDER BY ends_at, id`,
+		models.TableSessionStatusActive, until)
+}
+
+func (r *tableSessionRepository) listTableSessions(query string, args ...interface{}) ([]models.TableSession, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing table sessions: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	sessions := []models.TableSession{}
+	for rows.Next() {
+		session, err := scanTableSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning table session: %v", ErrDatabaseError, err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating table sessions: %v", ErrDatabaseError, err)
+	}
+	return sessions, nil
+}
+
+func (r *tableSessionRepository) MarkTableSessionWarned(executor SQLExecutor, id int64, minutes int, now time.Time) (bool, error) {
+	result, err := executor.Exec(`UPDATE table_sessions SET warned_minutes = $2, updated_at = $3
+	                              WHERE id = $1 AND status = $4 AND (warned_minutes IS NULL OR warned_minutes > $2)`,
+		id, minutes, now, models.TableSessionStatusActive)
+	if err != nil {
+		return false, fmt.Errorf("%w: recording warning of table session ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w: checking affected rows for table session ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *tableSessionRepository) SetTableSessionOvertime(executor SQLExecutor, id int64, now time.Time) (bool, error) {
+	result, err := executor.Exec(`UPDATE table_sessions SET status = $2, updated_at = $3 WHERE id = $1 AND status = $4`,
+		id, models.TableSessionStatusOvertime, now, models.TableSessionStatusActive)
+	if err != nil {
+		return false, fmt.Errorf("%w: starting overtime of table session ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w: checking affected rows for table session ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *tableSessionRepository) StopTableSession(executor SQLExecutor, session *models.TableSession, fromStatus string) error {
+	err := executor.QueryRow(`UPDATE table_sessions
+	                          SET status = $2, stopped_at = $3, stopped_by = $4, overtime_minutes = $5, overtime_amount = $6,
+	                              time_amount = $8, total_amount = $9, billed_minutes = $10, minimum_charge_amount = $11,
+	                              locker_amount = $12, updated_at = $3
+	                          WHERE id = $1 AND status = $7
+	                          RETURNING updated_at`,
+		session.ID, models.TableSessionStatusStopped, session.StoppedAt, session.StoppedBy,
+		session.OvertimeMinutes, session.OvertimeAmount, fromStatus, session.TimeAmount, session.TotalAmount,
+		session.BilledMinutes, session.MinimumChargeAmount, session.LockerAmount,
+	).Scan(&session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return versionMismatchError(executor, "table_sessions", session.ID)
+		}
+		return fmt.Errorf("%w: stopping table session ID %d: %v", ErrDatabaseError, session.ID, err)
+	}
+	session.Status = models.TableSessionStatusStopped
+	return nil
+}
@@ -0,0 +1,275 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// TimeOffRepository defines the database operations for the time off requests and weekly availability of staff members.
+type TimeOffRepository interface {
+	// CreateRequest records a time off request; ErrNotFound if there is no such staff member.
+	CreateRequest(request *models.TimeOffRequest) error
+	// GetRequestByID returns a time off request; ErrNotFound if there is none.
+	GetRequestByID(id int64) (*models.TimeOffRequest, error)
+	// GetRequests lists the time off requests matching filters by start date, latest first.
+	GetRequests(filters models.TimeOffFilters) ([]models.TimeOffRequest, error)
+	// UpdateStatus moves a request in one of fromStatuses to status, recording the decision if
+	// decidedBy is set; ErrNotFound if the request is in none of fromStatuses (or does not exist).
+	UpdateStatus(id int64, fromStatuses []string, status string, decidedBy *int64, note *string, at time.Time) error
+	// GetShiftsBetween lists the shifts of a staff member overlapping [from, to), by start time.
+	GetShiftsBetween(staffID int64, from, to time.Time) ([]models.Shift, error)
+
+	// GetAvailability lists the availability windows of a staff member by weekday and start time.
+	GetAvailability(staffID int64) ([]models.AvailabilityWindow, error)
+	// ReplaceAvailability replaces the availability windows of a staff member.
+	ReplaceAvailability(executor SQLExecutor, staffID int64, windows []models.AvailabilityWindow) error
+	// GetDayAvailability lists the staff members by name with whether approved or pending time
+	// off covers date (YYYY-MM-DD) and their availability windows of weekday.
+	GetDayAvailability(date string, weekday int) ([]models.StaffDayAvailability, error)
+}
+
+type timeOffRepository struct {
+	db *sql.DB
+}
+
+// NewTimeOffRepository creates a new instance of TimeOffRepository.
+func NewTimeOffRepository(db *sql.DB) TimeOffRepository {
+	return &timeOffRepository{db: db}
+}
+
+// staffNameColumn is the display name of the staff member s, whose user is u.
+const staffNameColumn = `COALESCE(NULLIF(u.full_name, ''), u.username, 'Staff #' || s.id)`
+
+const timeOffColumns = `t.id, t.staff_id, ` + staffNameColumn + `, TO_CHAR(t.start_date, 'YYYY-MM-DD'), TO_CHAR(t.end_date, 'YYYY-MM-DD'),
+	t.reason, t.status, t.requested_by, t.decided_by, t.decided_at, t.decision_note, t.created_at, t.updated_at`
+
+const timeOffFrom = `FROM staff_time_off t
+	JOIN staff_members s ON s.id = t.staff_id
+	LEFT JOIN users u ON u.id = s.user_id`
+
+func scanTimeOffRequest(row scanner) (*models.TimeOffRequest, error) {
+	var request models.TimeOffRequest
+	err := row.Scan(&request.ID, &request.StaffID, &request.StaffName, &request.StartDate, &request.EndDate, &request.Reason,
+		&request.Status, &request.RequestedBy, &request.DecidedBy, &request.DecidedAt, &request.DecisionNote,
+		&request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *timeOffRepository) CreateRequest(request *models.TimeOffRequest) error {
+	now := time.Now().UTC()
+	err := r.db.QueryRow(`INSERT INTO staff_time_off (staff_id, start_date, end_date, reason, status, requested_by, created_at, updated_at)
+	                      VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	                      RETURNING id, created_at, updated_at`,
+		request.StaffID, request.StartDate, request.EndDate, request.Reason, request.Status, request.RequestedBy, now,
+	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" && pqErr.Constraint == "staff_time_off_staff_id_fkey" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: creating time off request of staff ID %d: %v", ErrDatabaseError, request.StaffID, err)
+	}
+	return nil
+}
+
+func (r *timeOffRepository) GetRequestByID(id int64) (*models.TimeOffRequest, error) {
+	request, err := scanTimeOffRequest(r.db.QueryRow(`SELECT `+timeOffColumns+` `+timeOffFrom+` WHERE t.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: getting time off request ID %d: %v", ErrDatabaseError, id, err)
+	}
+	return request, nil
+}
+
+func (r *timeOffRepository) GetRequests(filters models.TimeOffFilters) ([]models.TimeOffRequest, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	argCounter := 1
+
+	if filters.StaffID != nil {
+		conditions = append(conditions, fmt.Sprintf("t.staff_id = $%d", argCounter))
+		args = append(args, *filters.StaffID)
+		argCounter++
+	}
+	if filters.Status != nil {
+		conditions = append(conditions, fmt.Sprintf("t.status = $%d", argCounter))
+		args = append(args, *filters.Status)
+		argCounter++
+	}
+	if filters.From != nil {
+		conditions = append(conditions, fmt.Sprintf("t.end_date >= $%d", argCounter))
+		args = append(args, *filters.From)
+		argCounter++
+	}
+	if filters.To != nil {
+		conditions = append(conditions, fmt.Sprintf("t.start_date <= $%d", argCounter))
+		args = append(args, *filters.To)
+	}
+
+	rows, err := r.db.Query(`SELECT `+timeOffColumns+` `+timeOffFrom+`
+	                         WHERE `+strings.Join(conditions, " AND ")+`
+	                         ORDER BY t.start_date DESC, t.id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing time off requests: %v", ErrDatabaseError, err)
+	}
+	defer rows.Close()
+
+	requests := []models.TimeOffRequest{}
+	for rows.Next() {
+		request, err := scanTimeOffRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scanning time off request: %v", ErrDatabaseError, err)
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating time off requests: %v", ErrDatabaseError, err)
+	}
+	return requests, nil
+}
+
+func (r *timeOffRepository) UpdateStatus(id int64, fromStatuses []string, status string, decidedBy *int64, note *string, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE staff_time_off
+	                          SET status = $3, updated_at = $6,
+	                              decided_by = COALESCE($4, decided_by),
+	                              decided_at = CASE WHEN $4::BIGINT IS NULL THEN decided_at ELSE $6 END,
+	                              decision_note = CASE WHEN $4::BIGINT IS NULL THEN decision_note ELSE $5 END
+	                          WHERE id = $1 AND status = ANY($2)`,
+		id, pq.Array(fromStatuses), status, decidedBy, note, at)
+	if err != nil {
+		return fmt.Errorf("%w: updating status of time off request ID %d: %v", ErrDatabaseError, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: checking affected rows for time off request ID %d: %v", ErrDatabaseError, id, err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *timeOffRepository) GetShiftsBetween(staffID int64, from, to time.Time) ([]models.Shift, error) {
+	rows, err := r.db.Query(`SELECT id, staff_id, start_time, end_time, notes, created_at, updated_at
+	                         FROM shifts
+	                         WHERE staff_id = $1 AND start_time < $3 AND end_time > $2
+	                         ORDER BY start_time, id`, staffID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing shifts of staff ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	defer rows.Close()
+
+	shifts := []models.Shift{}
+	for rows.Next() {
+		var shift models.Shift
+		if err := rows.Scan(&shift.ID, &shift.StaffID, &shift.StartTime, &shift.EndTime, &shift.Notes,
+			&shift.CreatedAt, &shift.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: scanning shift: %v", ErrDatabaseError, err)
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating shifts: %v", ErrDatabaseError, err)
+	}
+	return shifts, nil
+}
+
+func (r *timeOffRepository) GetAvailability(staffID int64) ([]models.AvailabilityWindow, error) {
+	rows, err := r.db.Query(`SELECT weekday, TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI'), preference
+	                         FROM staff_availability
+	                         WHERE staff_id = $1
+	                         ORDER BY weekday, start_time, id`, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing availability of staff ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	defer rows.Close()
+
+	windows := []models.AvailabilityWindow{}
+	for rows.Next() {
+		var window models.AvailabilityWindow
+		if err := rows.Scan(&window.Weekday, &window.StartTime, &window.EndTime, &window.Preference); err != nil {
+			return nil, fmt.Errorf("%w: scanning availability window: %v", ErrDatabaseError, err)
+		}
+		windows = append(windows, window)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating availability windows: %v", ErrDatabaseError, err)
+	}
+	return windows, nil
+}
+
+func (r *timeOffRepository) ReplaceAvailability(executor SQLExecutor, staffID int64, windows []models.AvailabilityWindow) error {
+	if _, err := executor.Exec(`DELETE FROM staff_availability WHERE staff_id = $1`, staffID); err != nil {
+		return fmt.Errorf("%w: clearing availability of staff ID %d: %v", ErrDatabaseError, staffID, err)
+	}
+	for _, window := range windows {
+		_, err := executor.Exec(`INSERT INTO staff_availability (staff_id, weekday, start_time, end_time, preference)
+		                         VALUES ($1, $2, $3, $4, $5)`,
+			staffID, window.Weekday, window.StartTime, window.EndTime, window.Preference)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+				return ErrNotFound
+			}
+			return fmt.Errorf("%w: saving availability of staff ID %d: %v", ErrDatabaseError, staffID, err)
+		}
+	}
+	return nil
+}
+
+func (r *timeOffRepository) GetDayAvailability(date string, weekday int) ([]models.StaffDayAvailability, error) {
+	rows, err := r.db.Query(`SELECT s.id, `+staffNameColumn+`,
+	                                EXISTS (SELECT 1 FROM staff_time_off t WHERE t.staff_id = s.id AND t.status = 'approved'
+	                                        AND t.start_date <= $1 AND t.end_date >= $1),
+	                                EXISTS (SELECT 1 FROM staff_time_off t WHERE t.staff_id = s.id AND t.status = 'pending'
+	                                        AND t.start_date <= $1 AND t.end_date >= $1),
+	                                a.weekday, TO_CHAR(a.start_time, 'HH24:MI'), TO_CHAR(a.end_time, 'HH24:MI'), a.preference
+	                         FROM staff_members s
+	                         LEFT JOIN users u ON u.id = s.user_id
+	                         LEFT JOIN staff_availability a ON a.staff_id = s.id AND a.weekday = $2
+	                         ORDER BY 2, s.id, a.start_time, a.id`, date, weekday)
+	if err != nil {
+		return nil, fmt.Errorf("%w: listing staff availability on %s: %v", ErrDatabaseError, date, err)
+	}
+	defer rows.Close()
+
+	days := []models.StaffDayAvailability{}
+	for rows.Next() {
+		var day models.StaffDayAvailability
+		var windowWeekday sql.NullInt64
+		var startTime, endTime, preference sql.NullString
+		if err := rows.Scan(&day.StaffID, &day.StaffName, &day.OnTimeOff, &day.PendingOff, &windowWeekday, &startTime,
+			&endTime, &preference); err != nil {
+			return nil, fmt.Errorf("%w: scanning staff availability: %v", ErrDatabaseError, err)
+		}
+		if len(days) == 0 || days[len(days)-1].StaffID != day.StaffID {
+			day.Windows = []models.AvailabilityWindow{}
+			days = append(days, day)
+		}
+		if windowWeekday.Valid {
+			last := &days[len(days)-1]
+			last.Windows = append(last.Windows, models.AvailabilityWindow{
+				Weekday:    int(windowWeekday.Int64),
+				StartTime:  startTime.String,
+				EndTime:    endTime.String,
+				Preference: preference.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: iterating staff availability: %v", ErrDatabaseError, err)
+	}
+	return days, nil
+}
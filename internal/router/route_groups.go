@@ -2,12 +2,15 @@ package router
 
 import (
 	"ps_club_backend/internal/handlers"
+	"ps_club_backend/internal/kvstore"
 	"ps_club_backend/internal/middleware"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupAuthRoutes sets up the authentication routes.
-func SetupAuthRoutes(apiGroup *gin.RouterGroup, authHandler *handlers.AuthHandler) {
+func SetupAuthRoutes(apiGroup *gin.RouterGroup, authHandler *handlers.AuthHandler, store kvstore.Store) {
 	authRoutes := apiGroup.Group("/auth")
 	{
 		authRoutes.POST("/register", authHandler.RegisterUser)
@@ -15,23 +18,36 @@ func SetupAuthRoutes(apiGroup *gin.RouterGroup, authHandler *handlers.AuthHandle
 		authRoutes.POST("/refresh-token", authHandler.RefreshToken) // Will use the placeholder from AuthHandler
 
 		authRequiredRoutes := authRoutes.Group("")
-		authRequiredRoutes.Use(middleware.AuthMiddleware()) // Apply AuthMiddleware to this sub-group
+		authRequiredRoutes.Use(middleware.AuthMiddleware(store)) // Apply AuthMiddleware to this sub-group
 		{
 			authRequiredRoutes.POST("/logout", authHandler.LogoutUser)
 			authRequiredRoutes.GET("/me", authHandler.GetCurrentUser)
+			authRequiredRoutes.GET("/sessions", authHandler.GetSessions)
+			authRequiredRoutes.DELETE("/sessions/:id", authHandler.RevokeSession)
+			authRequiredRoutes.PUT("/me/language", authHandler.UpdatePreferredLanguage)
+			authRequiredRoutes.PATCH("/me", authHandler.UpdateProfile)
+			authRequiredRoutes.PUT("/me/avatar", authHandler.UploadAvatar)
+			authRequiredRoutes.GET("/me/avatar", authHandler.GetAvatar)
+			authRequiredRoutes.DELETE("/me/avatar", authHandler.DeleteAvatar)
+			authRequiredRoutes.POST("/impersonate", middleware.RoleAuthMiddleware("Admin"), authHandler.Impersonate)
 		}
 	}
 }
 
-// SetupOrderRoutes sets up the order routes.
-func SetupOrderRoutes(authenticatedGroup *gin.RouterGroup, orderHandler *handlers.OrderHandler) {
+// SetupOrderRoutes sets up the order routes. idempotency guards order creation.
+func SetupOrderRoutes(authenticatedGroup *gin.RouterGroup, orderHandler *handlers.OrderHandler, idempotency gin.HandlerFunc) {
 	orderRoutes := authenticatedGroup.Group("/orders")
-	orderRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	orderRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
-		orderRoutes.POST("", orderHandler.CreateOrder)
+		orderRoutes.POST("", idempotency, orderHandler.CreateOrder)
 		orderRoutes.GET("", orderHandler.GetOrders)
+		orderRoutes.GET("/export", middleware.RoleAuthMiddleware("Admin"), orderHandler.ExportOrders)
+		orderRoutes.GET("/by-number/*number", orderHandler.GetOrderByNumber)
 		orderRoutes.GET("/:id", orderHandler.GetOrderByID)
+		orderRoutes.GET("/:id/receipt", orderHandler.GetOrderReceipt)
 		orderRoutes.PATCH("/:id/status", orderHandler.UpdateOrderStatus)
+		orderRoutes.POST("/bulk/status", orderHandler.BulkUpdateOrderStatus)
+		orderRoutes.POST("/:id/items/:item_id/void", orderHandler.VoidOrderItem)
 		orderRoutes.DELETE("/:id", orderHandler.DeleteOrder)
 	}
 }
@@ -39,7 +55,7 @@ func SetupOrderRoutes(authenticatedGroup *gin.RouterGroup, orderHandler *handler
 // SetupPricelistCategoryRoutes sets up the pricelist category routes.
 func SetupPricelistCategoryRoutes(authenticatedGroup *gin.RouterGroup, pricelistHandler *handlers.PricelistHandler) {
 	pricelistCategoryRoutes := authenticatedGroup.Group("/pricelist-categories")
-	pricelistCategoryRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	pricelistCategoryRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		pricelistCategoryRoutes.POST("", pricelistHandler.CreatePricelistCategory)
 		pricelistCategoryRoutes.GET("", pricelistHandler.GetPricelistCategories)
@@ -52,20 +68,23 @@ func SetupPricelistCategoryRoutes(authenticatedGroup *gin.RouterGroup, pricelist
 // SetupPricelistItemRoutes sets up the pricelist item routes.
 func SetupPricelistItemRoutes(authenticatedGroup *gin.RouterGroup, pricelistHandler *handlers.PricelistHandler) {
 	pricelistItemRoutes := authenticatedGroup.Group("/pricelist-items")
-	pricelistItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	pricelistItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		pricelistItemRoutes.POST("", pricelistHandler.CreatePricelistItem)
 		pricelistItemRoutes.GET("", pricelistHandler.GetPricelistItems)
 		pricelistItemRoutes.GET("/:id", pricelistHandler.GetPricelistItemByID)
 		pricelistItemRoutes.PUT("/:id", pricelistHandler.UpdatePricelistItem)
+		pricelistItemRoutes.PATCH("/:id", pricelistHandler.UpdatePricelistItem)
 		pricelistItemRoutes.DELETE("/:id", pricelistHandler.DeletePricelistItem)
+		pricelistItemRoutes.POST("/:id/restore", middleware.RoleAuthMiddleware("Admin"), pricelistHandler.RestorePricelistItem)
+		pricelistItemRoutes.POST("/bulk/availability", pricelistHandler.BulkSetItemAvailability)
 	}
 }
 
 // SetupBarItemRoutes sets up the bar item routes.
 func SetupBarItemRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handlers.BarItemHandler*/) {
 	barItemRoutes := authenticatedGroup.Group("/bar-items")
-	barItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	barItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		barItemRoutes.POST("", handlers.CreateBarItem)
 		barItemRoutes.GET("", handlers.GetBarItems)
@@ -78,7 +97,7 @@ func SetupBarItemRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handler
 // SetupHookahItemRoutes sets up the hookah item routes.
 func SetupHookahItemRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handlers.HookahItemHandler*/) {
 	hookahItemRoutes := authenticatedGroup.Group("/hookah-items")
-	hookahItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	hookahItemRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		hookahItemRoutes.POST("", handlers.CreateHookahItem)
 		hookahItemRoutes.GET("", handlers.GetHookahItems)
@@ -91,13 +110,66 @@ func SetupHookahItemRoutes(authenticatedGroup *gin.RouterGroup /*, handler *hand
 // SetupClientRoutes sets up the client routes.
 func SetupClientRoutes(authenticatedGroup *gin.RouterGroup, clientHandler *handlers.ClientHandler) {
 	clientRoutes := authenticatedGroup.Group("/clients")
-	clientRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	clientRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		clientRoutes.POST("", clientHandler.CreateClient)
 		clientRoutes.GET("", clientHandler.GetClients)
 		clientRoutes.GET("/:id", clientHandler.GetClientByID)
 		clientRoutes.PUT("/:id", clientHandler.UpdateClient)
+		clientRoutes.PATCH("/:id", clientHandler.UpdateClient)
 		clientRoutes.DELETE("/:id", clientHandler.DeleteClient)
+		clientRoutes.POST("/:id/restore", middleware.RoleAuthMiddleware("Admin"), clientHandler.RestoreClient)
+	}
+
+	// Personal data requests, Admin only: exports contain all of a client's data and anonymization is irreversible
+	authenticatedGroup.POST("/clients/:id/export", middleware.RoleAuthMiddleware("Admin"), clientHandler.ExportClientData)
+	authenticatedGroup.POST("/clients/:id/anonymize", middleware.RoleAuthMiddleware("Admin"), clientHandler.AnonymizeClient)
+
+	// Staff blacklist clients; only admins lift a blacklist
+	authenticatedGroup.PUT("/clients/:id/blacklist", middleware.RoleAuthMiddleware("Admin", "Staff"), clientHandler.BlacklistClient)
+	authenticatedGroup.DELETE("/clients/:id/blacklist", middleware.RoleAuthMiddleware("Admin"), clientHandler.LiftBlacklist)
+}
+
+// SetupClientAccountRoutes sets up the house accounts of clients. Only admins open accounts
+// and set their credit limits; staff take payments. idempotency guards payments.
+func SetupClientAccountRoutes(authenticatedGroup *gin.RouterGroup, accountHandler *handlers.ClientAccountHandler, idempotency gin.HandlerFunc) {
+	accountRoutes := authenticatedGroup.Group("/clients/:id/account")
+	accountRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		accountRoutes.GET("", accountHandler.GetAccount)
+		accountRoutes.PUT("", middleware.RoleAuthMiddleware("Admin"), accountHandler.SaveAccount)
+		accountRoutes.GET("/statement", accountHandler.GetStatement)
+		accountRoutes.POST("/payments", idempotency, accountHandler.RecordPayment)
+	}
+	authenticatedGroup.GET("/client-accounts", middleware.RoleAuthMiddleware("Admin", "Staff"), accountHandler.GetAccounts)
+}
+
+// SetupClientTagRoutes sets up the tags of clients and the report of sales and bookings by tag.
+func SetupClientTagRoutes(authenticatedGroup *gin.RouterGroup, tagHandler *handlers.ClientTagHandler) {
+	tagRoutes := authenticatedGroup.Group("/clients/:id/tags")
+	tagRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		tagRoutes.PUT("", tagHandler.SetClientTags)
+		tagRoutes.POST("", tagHandler.AddClientTags)
+		tagRoutes.DELETE("/:tag", tagHandler.RemoveClientTag)
+	}
+	authenticatedGroup.GET("/client-tags", middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst), tagHandler.GetClientTags)
+	authenticatedGroup.GET("/reports/client-tags", middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst), tagHandler.GetClientTagReport)
+}
+
+// SetupClientDocumentRoutes sets up the consents and ID documents of clients and their ID
+// verification, open to roles with the permission documentAccess checks. Only admins delete
+// documents.
+func SetupClientDocumentRoutes(authenticatedGroup *gin.RouterGroup, documentHandler *handlers.ClientDocumentHandler, documentAccess gin.HandlerFunc) {
+	documentRoutes := authenticatedGroup.Group("/clients/:id")
+	documentRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"), documentAccess)
+	{
+		documentRoutes.GET("/documents", documentHandler.GetClientDocuments)
+		documentRoutes.POST("/documents", documentHandler.AddClientDocument)
+		documentRoutes.GET("/documents/:document_id", documentHandler.GetClientDocumentPhoto)
+		documentRoutes.DELETE("/documents/:document_id", middleware.RoleAuthMiddleware("Admin"), documentHandler.DeleteClientDocument)
+		documentRoutes.PUT("/id-verification", documentHandler.VerifyClientID)
+		documentRoutes.DELETE("/id-verification", documentHandler.RevokeClientIDVerification)
 	}
 }
 
@@ -105,63 +177,546 @@ func SetupClientRoutes(authenticatedGroup *gin.RouterGroup, clientHandler *handl
 // Note: RoleAuthMiddleware is applied specifically for write and read operations.
 func SetupStaffRoutes(authenticatedGroup *gin.RouterGroup, staffHandler *handlers.StaffHandler) {
 	staffWriteRoutes := authenticatedGroup.Group("/staff")
-	staffWriteRoutes.Use(middleware.RoleAuthMiddleware("Admin")) // Admin only for POST, PUT, DELETE
+	staffWriteRoutes.Use(middleware.RoleAuthMiddleware("Admin")) // Admin only for POST, DELETE
 	{
 		staffWriteRoutes.POST("", staffHandler.CreateStaffMember)
-		staffWriteRoutes.PUT("/:id", staffHandler.UpdateStaffMember)
 		staffWriteRoutes.DELETE("/:id", staffHandler.DeleteStaffMember)
 	}
 
+	// Staff may update contact details; salary, position and hire date stay Admin-only (services.staffFieldRules)
+	authenticatedGroup.PUT("/staff/:id", middleware.RoleAuthMiddleware("Admin", "Staff"), staffHandler.UpdateStaffMember)
+	authenticatedGroup.PATCH("/staff/:id", middleware.RoleAuthMiddleware("Admin", "Staff"), staffHandler.UpdateStaffMember)
+
 	// GET routes with Admin or Staff roles
 	authenticatedGroup.GET("/staff", middleware.RoleAuthMiddleware("Admin", "Staff"), staffHandler.GetStaffMembers)
 	authenticatedGroup.GET("/staff/:id", middleware.RoleAuthMiddleware("Admin", "Staff"), staffHandler.GetStaffMemberByID)
 }
 
-// SetupShiftRoutes sets up the shift routes.
-func SetupShiftRoutes(authenticatedGroup *gin.RouterGroup, staffHandler *handlers.StaffHandler) {
+// SetupStaffDocumentRoutes sets up the Admin routes for the contracts, medical books and
+// certificates of staff members and the report of those expiring.
+func SetupStaffDocumentRoutes(authenticatedGroup *gin.RouterGroup, documentHandler *handlers.StaffDocumentHandler) {
+	documentRoutes := authenticatedGroup.Group("/staff/:id/documents")
+	documentRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		documentRoutes.GET("", documentHandler.GetStaffDocuments)
+		documentRoutes.POST("", documentHandler.AddStaffDocument)
+		documentRoutes.GET("/:document_id", documentHandler.GetStaffDocumentPhoto)
+		documentRoutes.DELETE("/:document_id", documentHandler.DeleteStaffDocument)
+	}
+	authenticatedGroup.GET("/reports/expiring-staff-documents", middleware.RoleAuthMiddleware("Admin"), documentHandler.GetExpiringStaffDocuments)
+}
+
+// SetupAttendanceRoutes sets up the Admin report of the attendance of the staff against their shifts.
+func SetupAttendanceRoutes(authenticatedGroup *gin.RouterGroup, attendanceHandler *handlers.AttendanceHandler) {
+	authenticatedGroup.GET("/reports/attendance", middleware.RoleAuthMiddleware("Admin"), attendanceHandler.GetAttendanceReport)
+}
+
+// SetupTimeOffRoutes sets up the time off requests and weekly availability of staff members.
+// Staff request time off and set the availability for themselves (services.TimeOffService);
+// only admins approve or reject the requests.
+func SetupTimeOffRoutes(authenticatedGroup *gin.RouterGroup, timeOffHandler *handlers.TimeOffHandler) {
+	timeOffRoutes := authenticatedGroup.Group("/time-off")
+	timeOffRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		timeOffRoutes.POST("", timeOffHandler.RequestTimeOff)
+		timeOffRoutes.GET("", timeOffHandler.GetTimeOffRequests)
+		timeOffRoutes.GET("/:id", timeOffHandler.GetTimeOffRequest)
+		timeOffRoutes.POST("/:id/approve", middleware.RoleAuthMiddleware("Admin"), timeOffHandler.ApproveTimeOff)
+		timeOffRoutes.POST("/:id/reject", middleware.RoleAuthMiddleware("Admin"), timeOffHandler.RejectTimeOff)
+		timeOffRoutes.POST("/:id/cancel", timeOffHandler.CancelTimeOff)
+	}
+	authenticatedGroup.GET("/staff/:id/availability", middleware.RoleAuthMiddleware("Admin", "Staff"), timeOffHandler.GetStaffAvailability)
+	authenticatedGroup.PUT("/staff/:id/availability", middleware.RoleAuthMiddleware("Admin", "Staff"), timeOffHandler.SetStaffAvailability)
+	authenticatedGroup.GET("/staff-availability", middleware.RoleAuthMiddleware("Admin", "Staff"), timeOffHandler.GetDayAvailability)
+}
+
+// SetupShiftRoutes sets up the shift routes, the end-of-shift reports and the Admin review of
+// the refused clock-ins.
+func SetupShiftRoutes(authenticatedGroup *gin.RouterGroup, staffHandler *handlers.StaffHandler, shiftReportHandler *handlers.ShiftReportHandler) {
 	shiftRoutes := authenticatedGroup.Group("/shifts")
 	shiftRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
 	{
 		shiftRoutes.POST("", staffHandler.CreateShift)
+		shiftRoutes.POST("/clock-in", staffHandler.ClockIn)
+		shiftRoutes.POST("/clock-out", staffHandler.ClockOut)
 		shiftRoutes.GET("", staffHandler.GetShifts)
 		shiftRoutes.GET("/:id", staffHandler.GetShiftByID)
 		shiftRoutes.PUT("/:id", staffHandler.UpdateShift)
 		shiftRoutes.DELETE("/:id", staffHandler.DeleteShift)
+		shiftRoutes.GET("/:id/report", shiftReportHandler.GetShiftReport)
+	}
+	shiftReportRoutes := authenticatedGroup.Group("/shift-reports")
+	shiftReportRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		shiftReportRoutes.GET("", shiftReportHandler.GetShiftReports)
+		shiftReportRoutes.GET("/:id", shiftReportHandler.GetShiftReportByID)
+	}
+	violationRoutes := authenticatedGroup.Group("/clock-in-violations")
+	violationRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		violationRoutes.GET("", staffHandler.GetClockInViolations)
+		violationRoutes.POST("/:id/override", staffHandler.OverrideClockInViolation)
+	}
+}
+
+// SetupApprovalRoutes sets up the routes for approving sensitive actions. Staff may
+// look up an approval they requested; deciding and setting PINs is for Admins.
+func SetupApprovalRoutes(authenticatedGroup *gin.RouterGroup, approvalHandler *handlers.ApprovalHandler) {
+	approvalRoutes := authenticatedGroup.Group("/approvals")
+	approvalRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		approvalRoutes.GET("/:id", approvalHandler.GetApprovalByID)
+	}
+	adminRoutes := authenticatedGroup.Group("/approvals")
+	adminRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		adminRoutes.GET("", approvalHandler.GetApprovals)
+		adminRoutes.PUT("/pin", approvalHandler.SetApprovalPIN)
+		adminRoutes.POST("/:id/approve", approvalHandler.ApproveApproval)
+		adminRoutes.POST("/:id/reject", approvalHandler.RejectApproval)
+	}
+}
+
+// SetupBackupRoutes sets up the Admin routes for database backups.
+func SetupBackupRoutes(authenticatedGroup *gin.RouterGroup, backupHandler *handlers.BackupHandler) {
+	backupRoutes := authenticatedGroup.Group("/admin/backups")
+	backupRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		backupRoutes.POST("", backupHandler.TriggerBackup)
+		backupRoutes.GET("", backupHandler.GetBackups)
+		backupRoutes.GET("/:id", backupHandler.GetBackupByID)
+	}
+}
+
+// SetupDayCloseRoutes sets up the Admin routes for the end-of-day close and the daily summaries.
+func SetupDayCloseRoutes(authenticatedGroup *gin.RouterGroup, dayCloseHandler *handlers.DayCloseHandler) {
+	dayCloseRoutes := authenticatedGroup.Group("/admin/day-close")
+	dayCloseRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		dayCloseRoutes.POST("", dayCloseHandler.CloseDay)
+		dayCloseRoutes.GET("", dayCloseHandler.GetDailySummaries)
+		dayCloseRoutes.GET("/:date", dayCloseHandler.GetDailySummary)
+	}
+}
+
+// SetupReportViewRoutes sets up the Admin routes for the materialized views of the reports.
+func SetupReportViewRoutes(authenticatedGroup *gin.RouterGroup, reportViewHandler *handlers.ReportViewHandler) {
+	reportViewRoutes := authenticatedGroup.Group("/admin/report-views")
+	reportViewRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		reportViewRoutes.GET("", reportViewHandler.GetReportViews)
+		reportViewRoutes.POST("/refresh", reportViewHandler.RefreshReportViews)
+	}
+}
+
+// SetupReferenceRoutes sets up GET /<entity>/:id/references, the delete preflight of the
+// records that other records reference, for the roles that may delete them.
+func SetupReferenceRoutes(authenticatedGroup *gin.RouterGroup, referenceHandler *handlers.ReferenceHandler) {
+	deleters := middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst)
+	authenticatedGroup.GET("/clients/:id/references", deleters, referenceHandler.GetReferences(services.ReferenceEntityClients))
+	authenticatedGroup.GET("/pricelist-items/:id/references", deleters, referenceHandler.GetReferences(services.ReferenceEntityPricelistItems))
+	authenticatedGroup.GET("/pricelist-categories/:id/references", deleters, referenceHandler.GetReferences(services.ReferenceEntityPricelistCategories))
+	authenticatedGroup.GET("/tables/:id/references", deleters, referenceHandler.GetReferences(services.ReferenceEntityTables))
+	authenticatedGroup.GET("/bookings/:id/references", deleters, referenceHandler.GetReferences(services.ReferenceEntityBookings))
+	authenticatedGroup.GET("/staff/:id/references", middleware.RoleAuthMiddleware("Admin"), referenceHandler.GetReferences(services.ReferenceEntityStaff))
+}
+
+// SetupOrderArchiveRoutes sets up the Admin routes for the orders moved to the archive.
+func SetupOrderArchiveRoutes(authenticatedGroup *gin.RouterGroup, orderArchiveHandler *handlers.OrderArchiveHandler) {
+	archiveRoutes := authenticatedGroup.Group("/archive/orders")
+	archiveRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		archiveRoutes.GET("", orderArchiveHandler.GetArchivedOrders)
+		archiveRoutes.GET("/:id", orderArchiveHandler.GetArchivedOrder)
+	}
+}
+
+// SetupAuditLogRoutes sets up the Admin route for the audit log.
+func SetupAuditLogRoutes(authenticatedGroup *gin.RouterGroup, auditLogHandler *handlers.AuditLogHandler) {
+	authenticatedGroup.GET("/admin/audit-log", middleware.RoleAuthMiddleware("Admin"), auditLogHandler.GetAuditLog)
+}
+
+// SetupInvitationRoutes sets up the Admin routes for inviting staff. Invitees accept them on
+// the public POST /auth/accept-invitation.
+func SetupInvitationRoutes(authenticatedGroup *gin.RouterGroup, invitationHandler *handlers.InvitationHandler) {
+	invitationRoutes := authenticatedGroup.Group("/admin/invitations")
+	invitationRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		invitationRoutes.POST("", invitationHandler.CreateInvitation)
+		invitationRoutes.GET("", invitationHandler.GetInvitations)
+		invitationRoutes.DELETE("/:id", invitationHandler.RevokeInvitation)
+	}
+}
+
+// SetupDiagnosticsRoutes sets up the Admin routes for query plan and index diagnostics.
+func SetupDiagnosticsRoutes(authenticatedGroup *gin.RouterGroup, diagnosticsHandler *handlers.DiagnosticsHandler) {
+	diagnosticsRoutes := authenticatedGroup.Group("/admin/diagnostics")
+	diagnosticsRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		diagnosticsRoutes.POST("/explain", diagnosticsHandler.ExplainQuery)
+		diagnosticsRoutes.GET("/indexes", diagnosticsHandler.GetMissingIndexes)
+	}
+}
+
+// SetupAPIKeyRoutes sets up the Admin routes for the API keys of devices such as the kiosk.
+func SetupAPIKeyRoutes(authenticatedGroup *gin.RouterGroup, apiKeyHandler *handlers.APIKeyHandler) {
+	apiKeyRoutes := authenticatedGroup.Group("/admin/api-keys")
+	apiKeyRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		apiKeyRoutes.POST("", apiKeyHandler.CreateAPIKey)
+		apiKeyRoutes.GET("", apiKeyHandler.GetAPIKeys)
+		apiKeyRoutes.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+	}
+}
+
+// SetupKioskRoutes sets up the check-in kiosk routes. The kiosk has no user login, so
+// kioskAuth admits API keys with the kiosk scope instead of access tokens.
+func SetupKioskRoutes(apiGroup *gin.RouterGroup, kioskHandler *handlers.KioskHandler, kioskAuth gin.HandlerFunc) {
+	kioskRoutes := apiGroup.Group("/kiosk", kioskAuth)
+	{
+		kioskRoutes.POST("/check-in", kioskHandler.CheckIn)
+	}
+}
+
+// SetupDeviceRoutes sets up the routes terminals call to register and send heartbeats. They
+// have no user login, so deviceAuth admits API keys with the device scope instead.
+func SetupDeviceRoutes(apiGroup *gin.RouterGroup, deviceHandler *handlers.DeviceHandler, deviceAuth gin.HandlerFunc) {
+	deviceRoutes := apiGroup.Group("/devices", deviceAuth)
+	{
+		deviceRoutes.POST("/register", deviceHandler.RegisterDevice)
+		deviceRoutes.POST("/heartbeat", deviceHandler.DeviceHeartbeat)
+	}
+}
+
+// SetupChannelRoutes sets up the routes the self-service channels place orders and book tables
+// through, under /channels/<scope> for each scope of channelAuth. They have no user login, so
+// channelAuth admits API keys with the scope of the channel instead.
+func SetupChannelRoutes(apiGroup *gin.RouterGroup, channelHandler *handlers.ChannelHandler, channelAuth map[string]gin.HandlerFunc) {
+	for scope, auth := range channelAuth {
+		channelRoutes := apiGroup.Group("/channels/"+scope, auth)
+		{
+			channelRoutes.POST("/orders", channelHandler.CreateOrder)
+			channelRoutes.POST("/bookings", channelHandler.CreateBooking)
+		}
+	}
+}
+
+// SetupClientAppRoutes sets up the routes the client app registers the push devices of its
+// clients and manages their push and reminder preferences through, under /channels/client_app
+// like its orders and bookings; clientAppAuth admits API keys of the client_app scope.
+func SetupClientAppRoutes(apiGroup *gin.RouterGroup, pushHandler *handlers.PushHandler, reminderHandler *handlers.BookingReminderHandler, clientAppAuth gin.HandlerFunc) {
+	clientAppRoutes := apiGroup.Group("/channels/"+models.APIKeyScopeClientApp, clientAppAuth)
+	{
+		clientAppRoutes.POST("/push-devices", pushHandler.RegisterDevice)
+		clientAppRoutes.DELETE("/push-devices/:token", pushHandler.UnregisterDevice)
+		clientAppRoutes.GET("/clients/:id/push-preferences", pushHandler.GetPreferences)
+		clientAppRoutes.PUT("/clients/:id/push-preferences", pushHandler.UpdatePreferences)
+		clientAppRoutes.GET("/clients/:id/reminder-preferences", reminderHandler.GetPreferences)
+		clientAppRoutes.PUT("/clients/:id/reminder-preferences", reminderHandler.UpdatePreferences)
+	}
+}
+
+// SetupTelegramRoutes sets up the routes the Telegram bot links the chats of its clients and
+// manages their reminder preferences through, under /channels/telegram; telegramAuth admits
+// API keys of the telegram scope.
+func SetupTelegramRoutes(apiGroup *gin.RouterGroup, reminderHandler *handlers.BookingReminderHandler, telegramAuth gin.HandlerFunc) {
+	telegramRoutes := apiGroup.Group("/channels/"+models.APIKeyScopeTelegram, telegramAuth)
+	{
+		telegramRoutes.GET("/clients/:id/reminder-preferences", reminderHandler.GetPreferences)
+		telegramRoutes.PUT("/clients/:id/reminder-preferences", reminderHandler.UpdatePreferences)
+	}
+}
+
+// SetupRealtimeRoutes sets up the WebSocket feeds of table session alerts and hookah coal change
+// reminders. The hubs behind them authenticate the upgrade and refreshes of the access token
+// themselves, and admit the roles of the table session and hookah routes.
+func SetupRealtimeRoutes(apiGroup *gin.RouterGroup, tableSessionHandler *handlers.TableSessionHandler, hookahServiceHandler *handlers.HookahServiceHandler) {
+	apiGroup.GET("/table-sessions/alerts", tableSessionHandler.SessionAlerts)
+	apiGroup.GET("/hookahs/alerts", hookahServiceHandler.HookahAlerts)
+}
+
+// SetupDeviceAdminRoutes sets up the Admin routes of the device registry.
+func SetupDeviceAdminRoutes(authenticatedGroup *gin.RouterGroup, deviceHandler *handlers.DeviceHandler) {
+	deviceRoutes := authenticatedGroup.Group("/admin/devices")
+	deviceRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		deviceRoutes.GET("", deviceHandler.GetDevices)
+		deviceRoutes.GET("/:id", deviceHandler.GetDevice)
+		deviceRoutes.PUT("/:id", deviceHandler.UpdateDevice)
+		deviceRoutes.DELETE("/:id", deviceHandler.DeleteDevice)
+	}
+}
+
+// SetupSyncRoutes sets up the offline sync routes of POS terminals.
+func SetupSyncRoutes(authenticatedGroup *gin.RouterGroup, syncHandler *handlers.SyncHandler) {
+	syncRoutes := authenticatedGroup.Group("/sync")
+	syncRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		syncRoutes.GET("/changes", syncHandler.GetChanges)
+		syncRoutes.POST("/orders", syncHandler.PushOrders)
+	}
+}
+
+// SetupTableSessionRoutes sets up the table session routes.
+func SetupTableSessionRoutes(authenticatedGroup *gin.RouterGroup, tableSessionHandler *handlers.TableSessionHandler) {
+	tableSessionRoutes := authenticatedGroup.Group("/table-sessions")
+	tableSessionRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		tableSessionRoutes.POST("", tableSessionHandler.StartSession)
+		tableSessionRoutes.GET("", tableSessionHandler.GetRunningSessions)
+		tableSessionRoutes.GET("/:id", tableSessionHandler.GetSession)
+		tableSessionRoutes.GET("/:id/receipt", tableSessionHandler.GetSessionReceipt)
+		tableSessionRoutes.POST("/:id/stop", tableSessionHandler.StopSession)
+		tableSessionRoutes.POST("/:id/deposit/capture", tableSessionHandler.RetryDepositCapture)
+	}
+}
+
+// SetupHookahServiceRoutes sets up the coal change tracking of the hookahs being served.
+// :id is the ID of the hookah's order item.
+func SetupHookahServiceRoutes(authenticatedGroup *gin.RouterGroup, hookahServiceHandler *handlers.HookahServiceHandler) {
+	hookahRoutes := authenticatedGroup.Group("/hookahs")
+	hookahRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		hookahRoutes.GET("", hookahServiceHandler.GetActiveHookahs)
+		hookahRoutes.POST("/:id/coal-changes", hookahServiceHandler.RecordCoalChange)
+		hookahRoutes.GET("/:id/coal-changes", hookahServiceHandler.GetCoalChanges)
+		hookahRoutes.POST("/:id/end", hookahServiceHandler.EndHookah)
+	}
+}
+
+// SetupQuickSaleRoutes sets up the quick-sale presets of this branch, which only admins
+// configure, and the creation of orders from them. idempotency guards order creation.
+func SetupQuickSaleRoutes(authenticatedGroup *gin.RouterGroup, quickSaleHandler *handlers.QuickSaleHandler, idempotency gin.HandlerFunc) {
+	presetRoutes := authenticatedGroup.Group("/quick-sale-presets")
+	presetRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		presetRoutes.GET("", quickSaleHandler.GetPresets)
+		presetRoutes.GET("/:id", quickSaleHandler.GetPreset)
+		presetRoutes.POST("", middleware.RoleAuthMiddleware("Admin"), quickSaleHandler.CreatePreset)
+		presetRoutes.PUT("/:id", middleware.RoleAuthMiddleware("Admin"), quickSaleHandler.UpdatePreset)
+		presetRoutes.DELETE("/:id", middleware.RoleAuthMiddleware("Admin"), quickSaleHandler.DeletePreset)
+	}
+	quickOrderRoutes := authenticatedGroup.Group("/orders/quick")
+	quickOrderRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		quickOrderRoutes.POST("/:preset_id", idempotency, quickSaleHandler.CreateQuickOrder)
+	}
+}
+
+// SetupLockerRoutes sets up the lockers of this branch, which only admins configure, and
+// their rentals to visits.
+func SetupLockerRoutes(authenticatedGroup *gin.RouterGroup, lockerHandler *handlers.LockerHandler) {
+	lockerRoutes := authenticatedGroup.Group("/lockers")
+	lockerRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		lockerRoutes.GET("", lockerHandler.GetLockers)
+		lockerRoutes.GET("/:id", lockerHandler.GetLocker)
+		lockerRoutes.POST("", middleware.RoleAuthMiddleware("Admin"), lockerHandler.CreateLocker)
+		lockerRoutes.PUT("/:id", middleware.RoleAuthMiddleware("Admin"), lockerHandler.UpdateLocker)
+		lockerRoutes.POST("/:id/rent", lockerHandler.RentLocker)
+	}
+	rentalRoutes := authenticatedGroup.Group("/locker-rentals")
+	rentalRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		rentalRoutes.GET("", lockerHandler.GetRentals)
+		rentalRoutes.GET("/:id", lockerHandler.GetRental)
+		rentalRoutes.POST("/:id/release", lockerHandler.ReleaseRental)
+	}
+}
+
+// SetupLostFoundRoutes sets up the lost and found log of this branch. Only admins delete
+// entries; the others are purged after the retention of the lost_and_found setting.
+func SetupLostFoundRoutes(authenticatedGroup *gin.RouterGroup, lostFoundHandler *handlers.LostFoundHandler) {
+	lostFoundRoutes := authenticatedGroup.Group("/lost-and-found")
+	lostFoundRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		lostFoundRoutes.GET("", lostFoundHandler.GetItems)
+		lostFoundRoutes.POST("", lostFoundHandler.LogItem)
+		lostFoundRoutes.GET("/:id", lostFoundHandler.GetItem)
+		lostFoundRoutes.PUT("/:id", lostFoundHandler.UpdateItem)
+		lostFoundRoutes.DELETE("/:id", middleware.RoleAuthMiddleware("Admin"), lostFoundHandler.DeleteItem)
+		lostFoundRoutes.POST("/:id/return", lostFoundHandler.MarkReturned)
+		lostFoundRoutes.GET("/:id/photo", lostFoundHandler.GetPhoto)
+		lostFoundRoutes.PUT("/:id/photo", lostFoundHandler.UploadPhoto)
+		lostFoundRoutes.DELETE("/:id/photo", lostFoundHandler.DeletePhoto)
+	}
+}
+
+// SetupIncidentRoutes sets up the incidents of this branch, which only admins review and delete,
+// and the payroll their penalties are deducted in.
+func SetupIncidentRoutes(authenticatedGroup *gin.RouterGroup, incidentHandler *handlers.IncidentHandler) {
+	incidentRoutes := authenticatedGroup.Group("/incidents")
+	incidentRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		incidentRoutes.GET("", incidentHandler.GetIncidents)
+		incidentRoutes.POST("", incidentHandler.ReportIncident)
+		incidentRoutes.GET("/:id", incidentHandler.GetIncident)
+		incidentRoutes.PUT("/:id", incidentHandler.UpdateIncident)
+		incidentRoutes.DELETE("/:id", middleware.RoleAuthMiddleware("Admin"), incidentHandler.DeleteIncident)
+		incidentRoutes.POST("/:id/review", middleware.RoleAuthMiddleware("Admin"), incidentHandler.ReviewIncident)
+		incidentRoutes.POST("/:id/photos", incidentHandler.AddPhoto)
+		incidentRoutes.GET("/:id/photos/:photo_id", incidentHandler.GetPhoto)
+		incidentRoutes.DELETE("/:id/photos/:photo_id", incidentHandler.DeletePhoto)
+	}
+	authenticatedGroup.GET("/payroll", middleware.RoleAuthMiddleware("Admin"), incidentHandler.GetPayroll)
+}
+
+// SetupSupplierRoutes sets up the suppliers, their price lists and the purchase suggestions
+// comparing them, all for admins.
+func SetupSupplierRoutes(authenticatedGroup *gin.RouterGroup, supplierHandler *handlers.SupplierHandler) {
+	supplierRoutes := authenticatedGroup.Group("/suppliers")
+	supplierRoutes.Use(middleware.RoleAuthMiddleware("Admin"))
+	{
+		supplierRoutes.GET("", supplierHandler.GetSuppliers)
+		supplierRoutes.POST("", supplierHandler.CreateSupplier)
+		supplierRoutes.GET("/:id", supplierHandler.GetSupplier)
+		supplierRoutes.PUT("/:id", supplierHandler.UpdateSupplier)
+		supplierRoutes.DELETE("/:id", supplierHandler.DeleteSupplier)
+		supplierRoutes.GET("/:id/prices", supplierHandler.GetPrices)
+		supplierRoutes.POST("/:id/prices/import", supplierHandler.ImportPrices)
+	}
+	authenticatedGroup.GET("/purchase-suggestions", middleware.RoleAuthMiddleware("Admin"), supplierHandler.GetPurchaseSuggestions)
+}
+
+// SetupReorderPointRoutes sets up the reorder points calculated from the consumption of the
+// stock-tracked items. Only admins recalculate them.
+func SetupReorderPointRoutes(authenticatedGroup *gin.RouterGroup, reorderPointHandler *handlers.ReorderPointHandler) {
+	reorderPointRoutes := authenticatedGroup.Group("/reorder-points")
+	reorderPointRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		reorderPointRoutes.GET("", reorderPointHandler.GetReorderPoints)
+		reorderPointRoutes.POST("/calculate", middleware.RoleAuthMiddleware("Admin"), reorderPointHandler.CalculateReorderPoints)
+	}
+}
+
+// SetupSalesTargetRoutes sets up the monthly sales targets per category, set by the admins,
+// and their progress on the dashboard.
+func SetupSalesTargetRoutes(authenticatedGroup *gin.RouterGroup, salesTargetHandler *handlers.SalesTargetHandler) {
+	salesTargetRoutes := authenticatedGroup.Group("/sales-targets")
+	salesTargetRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		salesTargetRoutes.GET("", salesTargetHandler.GetSalesTargets)
+		salesTargetRoutes.POST("", middleware.RoleAuthMiddleware("Admin"), salesTargetHandler.CreateSalesTarget)
+		salesTargetRoutes.PUT("/:id", middleware.RoleAuthMiddleware("Admin"), salesTargetHandler.UpdateSalesTarget)
+		salesTargetRoutes.DELETE("/:id", middleware.RoleAuthMiddleware("Admin"), salesTargetHandler.DeleteSalesTarget)
+	}
+	authenticatedGroup.GET("/dashboard/targets", middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst), salesTargetHandler.GetTargetProgress)
+}
+
+// SetupPromoCodeRoutes sets up the promo codes, set up by the admins; Staff look them up to
+// redeem them with orders.
+func SetupPromoCodeRoutes(authenticatedGroup *gin.RouterGroup, promoCodeHandler *handlers.PromoCodeHandler) {
+	promoCodeRoutes := authenticatedGroup.Group("/promo-codes")
+	promoCodeRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		promoCodeRoutes.GET("", promoCodeHandler.GetPromoCodes)
+		promoCodeRoutes.POST("", middleware.RoleAuthMiddleware("Admin"), promoCodeHandler.CreatePromoCode)
+		promoCodeRoutes.PUT("/:id", middleware.RoleAuthMiddleware("Admin"), promoCodeHandler.UpdatePromoCode)
+	}
+}
+
+// SetupCalendarNoteRoutes sets up the calendar notes, annotated by the admins and shown with the
+// reports that cover their dates.
+func SetupCalendarNoteRoutes(authenticatedGroup *gin.RouterGroup, calendarNoteHandler *handlers.CalendarNoteHandler) {
+	noteRoutes := authenticatedGroup.Group("/calendar-notes")
+	noteRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		noteRoutes.GET("", calendarNoteHandler.GetCalendarNotes)
+		noteRoutes.POST("", middleware.RoleAuthMiddleware("Admin"), calendarNoteHandler.CreateCalendarNote)
+		noteRoutes.PUT("/:id", middleware.RoleAuthMiddleware("Admin"), calendarNoteHandler.UpdateCalendarNote)
+		noteRoutes.DELETE("/:id", middleware.RoleAuthMiddleware("Admin"), calendarNoteHandler.DeleteCalendarNote)
+	}
+}
+
+// SetupAnomalyRoutes sets up the nightly checks of the revenue, discounts and voids against
+// their trailing averages. They watch the staff, so Staff cannot see them.
+func SetupAnomalyRoutes(authenticatedGroup *gin.RouterGroup, anomalyHandler *handlers.AnomalyHandler) {
+	anomalyRoutes := authenticatedGroup.Group("/reports/anomalies")
+	anomalyRoutes.Use(middleware.RoleAuthMiddleware("Admin", middleware.RoleAnalyst))
+	{
+		anomalyRoutes.GET("", anomalyHandler.GetAnomalyChecks)
+		anomalyRoutes.POST("/check", middleware.RoleAuthMiddleware("Admin"), anomalyHandler.CheckDayForAnomalies)
+	}
+}
+
+// SetupStockBatchRoutes sets up the batches of perishable stock and the report of those
+// expiring soon. Only admins write off the expired batches ahead of the scheduled write-off.
+func SetupStockBatchRoutes(authenticatedGroup *gin.RouterGroup, stockBatchHandler *handlers.StockBatchHandler) {
+	stockBatchRoutes := authenticatedGroup.Group("/stock-batches")
+	stockBatchRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		stockBatchRoutes.GET("", stockBatchHandler.GetStockBatches)
+		stockBatchRoutes.POST("/write-off-expired", middleware.RoleAuthMiddleware("Admin"), stockBatchHandler.WriteOffExpired)
+	}
+	authenticatedGroup.GET("/reports/expiring-stock", middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst), stockBatchHandler.GetExpiringStock)
+}
+
+// SetupTablePowerRoutes sets up the manual override of the power of a table's TV and console.
+func SetupTablePowerRoutes(authenticatedGroup *gin.RouterGroup, powerHandler *handlers.PowerHandler) {
+	tablePowerRoutes := authenticatedGroup.Group("/tables")
+	tablePowerRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		tablePowerRoutes.POST("/:id/power", powerHandler.SetTablePower)
+	}
+}
+
+// SetupFloorPlanRoutes sets up the floor plan the POS renders as the room view, which only admins edit.
+func SetupFloorPlanRoutes(authenticatedGroup *gin.RouterGroup, floorPlanHandler *handlers.FloorPlanHandler) {
+	floorPlanRoutes := authenticatedGroup.Group("/floor-plan")
+	floorPlanRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		floorPlanRoutes.GET("", floorPlanHandler.GetFloorPlan)
+		floorPlanRoutes.PUT("", middleware.RoleAuthMiddleware("Admin"), floorPlanHandler.SaveFloorPlan)
+		floorPlanRoutes.PUT("/tables/:table_id", middleware.RoleAuthMiddleware("Admin"), floorPlanHandler.SaveFloorPlanTable)
+		floorPlanRoutes.DELETE("/tables/:table_id", middleware.RoleAuthMiddleware("Admin"), floorPlanHandler.RemoveFloorPlanTable)
 	}
 }
 
 // SetupGameTableRoutes sets up the game table routes.
-func SetupGameTableRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handlers.GameTableHandler*/) {
+func SetupGameTableRoutes(authenticatedGroup *gin.RouterGroup, gameTableHandler *handlers.GameTableHandler) {
 	gameTableRoutes := authenticatedGroup.Group("/tables")
-	gameTableRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	gameTableRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
-		gameTableRoutes.POST("", handlers.CreateGameTable)
-		gameTableRoutes.GET("", handlers.GetGameTables)
-		gameTableRoutes.GET("/:id", handlers.GetGameTableByID)
-		gameTableRoutes.PUT("/:id", handlers.UpdateGameTable)
-		gameTableRoutes.DELETE("/:id", handlers.DeleteGameTable)
+		gameTableRoutes.POST("", gameTableHandler.CreateGameTable)
+		gameTableRoutes.GET("", gameTableHandler.GetGameTables)
+		gameTableRoutes.GET("/:id", gameTableHandler.GetGameTableByID)
+		gameTableRoutes.GET("/:id/overview", gameTableHandler.GetGameTableOverview)
+		gameTableRoutes.PUT("/:id", gameTableHandler.UpdateGameTable)
+		gameTableRoutes.PUT("/:id/status", gameTableHandler.SetGameTableStatus)
+		gameTableRoutes.DELETE("/:id", gameTableHandler.DeleteGameTable)
 	}
 }
 
-// SetupBookingRoutes sets up the booking routes.
-func SetupBookingRoutes(authenticatedGroup *gin.RouterGroup, bookingHandler *handlers.BookingHandler) {
+// SetupBillRoutes sets up the running bills of orders and table sessions, which close nothing.
+func SetupBillRoutes(authenticatedGroup *gin.RouterGroup, billHandler *handlers.BillHandler) {
+	authenticatedGroup.GET("/orders/:id/bill", middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst), billHandler.GetOrderBill)
+	authenticatedGroup.GET("/table-sessions/:id/bill", middleware.RoleAuthMiddleware("Admin", "Staff"), billHandler.GetSessionBill)
+}
+
+// SetupBookingRoutes sets up the booking routes. idempotency guards booking creation.
+func SetupBookingRoutes(authenticatedGroup *gin.RouterGroup, bookingHandler *handlers.BookingHandler, idempotency gin.HandlerFunc) {
 	bookingRoutes := authenticatedGroup.Group("/bookings")
-	bookingRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	bookingRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
-		bookingRoutes.POST("", bookingHandler.CreateBooking)
+		bookingRoutes.POST("", idempotency, bookingHandler.CreateBooking)
+		bookingRoutes.POST("/quote", bookingHandler.QuoteBooking)
 		bookingRoutes.GET("", bookingHandler.GetBookings)
 		bookingRoutes.GET("/:id", bookingHandler.GetBookingByID)
+		bookingRoutes.GET("/:id/history", bookingHandler.GetBookingHistory)
 		bookingRoutes.PUT("/:id", bookingHandler.UpdateBooking)
+		bookingRoutes.PATCH("/:id", bookingHandler.UpdateBooking)
 		bookingRoutes.DELETE("/:id", bookingHandler.DeleteBooking)
+		bookingRoutes.POST("/:id/restore", middleware.RoleAuthMiddleware("Admin"), bookingHandler.RestoreBooking)
 		bookingRoutes.PATCH("/:id/cancel", bookingHandler.CancelBooking)
 		bookingRoutes.PATCH("/:id/complete", bookingHandler.CompleteBooking)
+		bookingRoutes.POST("/bulk/cancel", bookingHandler.CancelClientBookings)
 	}
 }
 
 // SetupInventoryMovementRoutes sets up the inventory movement routes.
 func SetupInventoryMovementRoutes(authenticatedGroup *gin.RouterGroup, inventoryMvHandler *handlers.InventoryMovementHandler) {
 	inventoryMovementRoutes := authenticatedGroup.Group("/inventory-movements")
-	inventoryMovementRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	inventoryMovementRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
 		inventoryMovementRoutes.POST("", inventoryMvHandler.CreateInventoryMovement)
 		inventoryMovementRoutes.GET("", inventoryMvHandler.GetInventoryMovements)
@@ -178,24 +733,94 @@ func SetupSettingsRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handle
 		settingsRoutes.GET("/:key", handlers.GetApplicationSettingByKey)
 		settingsRoutes.DELETE("/:key", handlers.DeleteApplicationSettingByKey)
 	}
+	// Currency is needed by every client to format amounts, so it is not admin-only
+	authenticatedGroup.GET("/currency", handlers.GetCurrency)
+}
+
+// SetupMetaRoutes sets up the routes describing the API itself, open to every authenticated user.
+func SetupMetaRoutes(authenticatedGroup *gin.RouterGroup) {
+	metaRoutes := authenticatedGroup.Group("/meta")
+	{
+		metaRoutes.GET("/enums", handlers.GetEnums)
+	}
+}
+
+// SetupPublicRoutes sets up the routes open without authentication, for the public booking widget.
+func SetupPublicRoutes(apiGroup *gin.RouterGroup) {
+	publicRoutes := apiGroup.Group("/public")
+	{
+		publicRoutes.GET("/booking-policy", handlers.GetBookingPolicy)
+	}
 }
 
-// SetupReportRoutes sets up the report routes.
-func SetupReportRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handlers.ReportHandler*/) {
+// SetupSearchRoutes sets up the global search route.
+func SetupSearchRoutes(authenticatedGroup *gin.RouterGroup, searchHandler *handlers.SearchHandler) {
+	searchRoutes := authenticatedGroup.Group("/search")
+	searchRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		searchRoutes.GET("", searchHandler.Search)
+	}
+}
+
+// SetupReportRoutes sets up the report routes; the period, tax, comparison, staffing, void and
+// discount reports are served by the dashboard handler. Saved reports are changed by Admins and Staff, and run by the Analysts too.
+// source reports are served by the dashboard handler. Saved reports are changed by Admins and
+// Staff, and run by the Analysts too.
+func SetupReportRoutes(authenticatedGroup *gin.RouterGroup, dashboardHandler *handlers.DashboardHandler, savedReportHandler *handlers.SavedReportHandler) {
 	reportRoutes := authenticatedGroup.Group("/reports")
-	reportRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	reportRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
 	{
+		reportRoutes.GET("/summary", dashboardHandler.GetPeriodReport)
+		reportRoutes.GET("/tax", dashboardHandler.GetTaxReport)
+		reportRoutes.GET("/compare", dashboardHandler.ComparePeriods)
+		reportRoutes.GET("/staffing", dashboardHandler.GetStaffingReport)
+		reportRoutes.GET("/voids", middleware.RoleAuthMiddleware("Admin", middleware.RoleAnalyst), dashboardHandler.GetVoidReport)
+		reportRoutes.GET("/discounts", middleware.RoleAuthMiddleware("Admin", middleware.RoleAnalyst), dashboardHandler.GetDiscountReport)
+		reportRoutes.GET("/sources", middleware.RoleAuthMiddleware("Admin", middleware.RoleAnalyst), dashboardHandler.GetSourceReport)
 		reportRoutes.GET("/sales", handlers.GetSalesReports)
 		reportRoutes.GET("/bookings", handlers.GetBookingReports)
 		reportRoutes.GET("/inventory", handlers.GetInventoryReports)
+		reportRoutes.GET("/saved", savedReportHandler.GetSavedReports)
+		reportRoutes.POST("/saved", savedReportHandler.CreateSavedReport)
+		reportRoutes.GET("/saved/:id", savedReportHandler.GetSavedReport)
+		reportRoutes.PUT("/saved/:id", savedReportHandler.UpdateSavedReport)
+		reportRoutes.DELETE("/saved/:id", savedReportHandler.DeleteSavedReport)
+		reportRoutes.GET("/saved/:id/run", savedReportHandler.RunSavedReport)
 	}
 }
 
 // SetupDashboardRoutes sets up the dashboard routes.
-func SetupDashboardRoutes(authenticatedGroup *gin.RouterGroup /*, handler *handlers.DashboardHandler*/) {
+func SetupDashboardRoutes(authenticatedGroup *gin.RouterGroup, handler *handlers.DashboardHandler) {
 	dashboardRoutes := authenticatedGroup.Group("/dashboard")
-	dashboardRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	dashboardRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff", middleware.RoleAnalyst))
+	{
+		dashboardRoutes.GET("/summary", handler.GetDashboardSummary)
+		dashboardRoutes.GET("/activity", handler.GetActivityFeed)
+	}
+}
+
+// SetupPushRoutes sets up the push devices and preferences of clients, which the staff look up
+// and change on their behalf, and the push delivery log of the admins.
+func SetupPushRoutes(authenticatedGroup *gin.RouterGroup, pushHandler *handlers.PushHandler) {
+	pushRoutes := authenticatedGroup.Group("/clients/:id")
+	pushRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
+	{
+		pushRoutes.GET("/push-devices", pushHandler.GetDevices)
+		pushRoutes.DELETE("/push-devices/:device_id", pushHandler.DeleteDevice)
+		pushRoutes.GET("/push-preferences", pushHandler.GetPreferences)
+		pushRoutes.PUT("/push-preferences", pushHandler.UpdatePreferences)
+	}
+	authenticatedGroup.GET("/push-deliveries", middleware.RoleAuthMiddleware("Admin"), pushHandler.GetDeliveries)
+}
+
+// SetupBookingReminderRoutes sets up the reminder preferences of clients, which the staff look
+// up and change on their behalf, and the booking reminder log of the admins.
+func SetupBookingReminderRoutes(authenticatedGroup *gin.RouterGroup, reminderHandler *handlers.BookingReminderHandler) {
+	reminderRoutes := authenticatedGroup.Group("/clients/:id")
+	reminderRoutes.Use(middleware.RoleAuthMiddleware("Admin", "Staff"))
 	{
-		dashboardRoutes.GET("/summary", handlers.GetDashboardSummary)
+		reminderRoutes.GET("/reminder-preferences", reminderHandler.GetPreferences)
+		reminderRoutes.PUT("/reminder-preferences", reminderHandler.UpdatePreferences)
 	}
+	authenticatedGroup.GET("/booking-reminders", middleware.RoleAuthMiddleware("Admin"), reminderHandler.GetReminders)
 }
@@ -2,100 +2,563 @@ package router
 
 import (
 	"database/sql"
+	"net/http"
 	"time" // Added for JWT expiration
 
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/graph"
 	"ps_club_backend/internal/handlers"
+	"ps_club_backend/internal/kvstore"
 	"ps_club_backend/internal/middleware"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/realtime"
 	"ps_club_backend/internal/repositories" // Added for AuthRepository
 	"ps_club_backend/internal/services"
+	"ps_club_backend/internal/validation"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
+// Config holds the settings needed to build the HTTP engine.
+type Config struct {
+	JWTSecret      string                   // Signs and verifies access tokens
+	JWTExpiration  time.Duration            // Lifetime of access tokens issued at login
+	AllowedOrigins []string                 // CORS origins
+	Store          kvstore.Store            // State shared by all instances; in-memory if nil
+	AuthRateLimit  int                      // Requests per minute and client IP on the public auth routes; 0 disables the limit
+	BackupRunner   services.BackupRunner    // Takes database backups; nil disables POST /admin/backups
+	SessionAlerts  *realtime.Hub            // Pushes table session events to WebSocket clients; a hub that is not run if nil
+	HookahAlerts   *realtime.Hub            // Pushes hookah coal change reminders to WebSocket clients; a hub that is not run if nil
+	PowerControl   services.PowerController // Switches the TVs and consoles of tables; nil disables POST /tables/:id/power
+	Payments       services.PaymentProvider // Pre-authorizes card deposits of table sessions; nil disables them
+	// TrustedProxies are the proxies whose X-Forwarded-For sets the client IP, e.g. for the clock_in setting;
+	// nil keeps trusting any, empty trusts none.
+	TrustedProxies []string
+}
+
+// SessionAlertsChannel is the channel of the shared store the table session events are broadcast on.
+const SessionAlertsChannel = "table_session_alerts"
+
+// HookahAlertsChannel is the channel of the shared store the hookah events are broadcast on.
+const HookahAlertsChannel = "hookah_alerts"
+
+// RealtimeRoles are the roles admitted to the WebSocket feeds.
+var RealtimeRoles = []string{"Admin", "Staff"}
+
+// RealtimeTopics restricts the events of the WebSocket feeds to roles: cash events reach
+// only Admins.
+var RealtimeTopics = realtime.TopicPolicy{
+	events.OrderPaid:     {"Admin"},
+	events.OrderRefunded: {"Admin"},
+}
+
+// RealtimeHubConfig returns the configuration of the WebSocket hubs: access tokens are
+// validated like by AuthMiddleware, against the revoked sessions of store.
+func RealtimeHubConfig(store kvstore.Store) realtime.HubConfig {
+	return realtime.HubConfig{
+		Authenticate: middleware.RealtimeAuthenticator(store),
+		Roles:        RealtimeRoles,
+		Topics:       RealtimeTopics,
+	}
+}
+
+// idempotencyKeyTTL is how long the response to a request with an Idempotency-Key is replayed.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// DefaultConfig returns a configuration suitable for local development and tests.
+func DefaultConfig() Config {
+	return Config{
+		JWTSecret:      utils.DefaultJWTSecret,
+		JWTExpiration:  time.Hour * 72,
+		AllowedOrigins: []string{"http://localhost:3000", "http://localhost:3001"},
+		Store:          kvstore.NewMemoryStore(),
+	}
+}
+
+// New builds the complete HTTP engine: logging, CORS, health check and all API routes.
+// It is used by the server and by API tests (with httptest) alike.
+func New(db *sql.DB, cfg Config) *gin.Engine {
+	engine := gin.New()
+	if cfg.TrustedProxies != nil {
+		if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			utils.LogError(err, "Invalid trusted proxies, trusting none")
+			_ = engine.SetTrustedProxies(nil)
+		}
+	}
+	engine.Use(gin.Logger())
+	// Panics and 5xx responses are reported with the request ID, user and route
+	engine.Use(middleware.RequestID(), middleware.Recovery())
+
+	// Add GinLogger middleware for request logging
+	engine.Use(utils.GinLogger())
+	// Bodies of the routes listed in the payload_logging setting, redacted
+	engine.Use(middleware.PayloadLogging())
+
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.AllowedOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", middleware.APIKeyHeader}
+	corsConfig.AllowCredentials = true
+	engine.Use(cors.New(corsConfig))
+
+	engine.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "pong"})
+	})
+
+	Setup(engine, db, cfg)
+	return engine
+}
+
 // Setup initializes the routing for the application.
-func Setup(engine *gin.Engine, db *sql.DB) {
+func Setup(engine *gin.Engine, db *sql.DB, cfg Config) {
+	registerValidators()
+	// Tokens issued by AuthService must validate in AuthMiddleware, so both use the same secret
+	utils.SetJWTSecret(cfg.JWTSecret)
+	if cfg.Store == nil {
+		cfg.Store = kvstore.NewMemoryStore()
+	}
+	if cfg.SessionAlerts == nil {
+		cfg.SessionAlerts = realtime.NewHub(cfg.Store, SessionAlertsChannel, RealtimeHubConfig(cfg.Store))
+	}
+	if cfg.HookahAlerts == nil {
+		cfg.HookahAlerts = realtime.NewHub(cfg.Store, HookahAlertsChannel, RealtimeHubConfig(cfg.Store))
+	}
+
 	// Initialize Repositories
 	authRepo := repositories.NewAuthRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
 	pricelistRepo := repositories.NewPricelistRepository(db)
 	inventoryMvRepo := repositories.NewInventoryMovementRepository(db)
 	orderRepo := repositories.NewOrderRepository(db)
 	clientRepo := repositories.NewClientRepository(db)
 	staffRepo := repositories.NewStaffRepository(db)
 	bookingRepo := repositories.NewBookingRepository(db) // Added BookingRepository
+	reportRepo := repositories.NewReportRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	approvalRepo := repositories.NewApprovalRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	tableSessionRepo := repositories.NewTableSessionRepository(db)
+	hookahRepo := repositories.NewHookahRepository(db)
+	quickSaleRepo := repositories.NewQuickSaleRepository(db)
+	clientAccountRepo := repositories.NewClientAccountRepository(db)
+	clientTagRepo := repositories.NewClientTagRepository(db)
+	clientDocumentRepo := repositories.NewClientDocumentRepository(db)
+	lockerRepo := repositories.NewLockerRepository(db)
+	lostFoundRepo := repositories.NewLostFoundRepository(db)
+	incidentRepo := repositories.NewIncidentRepository(db)
+	supplierRepo := repositories.NewSupplierRepository(db)
+	stockBatchRepo := repositories.NewStockBatchRepository(db)
+	shiftReportRepo := repositories.NewShiftReportRepository(db)
+	staffDocumentRepo := repositories.NewStaffDocumentRepository(db)
+	timeOffRepo := repositories.NewTimeOffRepository(db)
+	attendanceRepo := repositories.NewAttendanceRepository(db)
+	floorPlanRepo := repositories.NewFloorPlanRepository(db)
+	gameTableRepo := repositories.NewGameTableRepository(db)
+	dayCloseRepo := repositories.NewDayCloseRepository(db)
+	reportViewRepo := repositories.NewReportViewRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	settingRepo := repositories.NewSettingRepository(db)
+	promoCodeRepo := repositories.NewPromoCodeRepository(db)
+	calendarNoteRepo := repositories.NewCalendarNoteRepository(db)
 	// TODO: Initialize other repositories here
 
 	// Initialize Services
-	// For JWT Secret: In a real app, load from config/env. Using a placeholder for now.
-	jwtSecret := "your-very-secure-jwt-secret-replace-it" // Replace with actual secret management
-	jwtExpiration := time.Hour * 72                       // Example: 72 hours
-
-	authService := services.NewAuthService(authRepo, db, jwtSecret, jwtExpiration)
-	pricelistService := services.NewPricelistService(pricelistRepo, db)
-	inventoryMvService := services.NewInventoryMovementService(inventoryMvRepo, pricelistRepo, db)
-	orderService := services.NewOrderService(orderRepo, pricelistRepo, inventoryMvRepo, db)
-	clientService := services.NewClientService(clientRepo, db)
-	staffService := services.NewStaffService(staffRepo, authRepo, db)
-	bookingService := services.NewBookingService(bookingRepo, clientRepo, staffRepo, db) // Added BookingService
+	publisher := events.NewPublisher(outboxRepo)
+	authService := services.NewAuthService(authRepo, sessionRepo, db, cfg.JWTSecret, cfg.JWTExpiration, cfg.Store, auditLogRepo, settingRepo)
+	pricelistService := services.NewPricelistService(pricelistRepo, auditLogRepo, db)
+	inventoryMvService := services.NewInventoryMovementService(inventoryMvRepo, pricelistRepo, stockBatchRepo, publisher, db)
+	orderService := services.NewOrderService(orderRepo, pricelistRepo, inventoryMvRepo, stockBatchRepo, clientAccountRepo, publisher, dayCloseRepo, promoCodeRepo, db)
+	clientService := services.NewClientService(clientRepo, bookingRepo, orderRepo, auditLogRepo, publisher, db)
+	staffService := services.NewStaffService(staffRepo, authRepo, shiftReportRepo, publisher, db)
+	bookingService := services.NewBookingService(bookingRepo, clientRepo, staffRepo, lockerRepo, gameTableRepo, auditLogRepo, db, cfg.Store, publisher) // Added BookingService
+	reportService := services.NewReportService(reportRepo, dayCloseRepo, shiftReportRepo, calendarNoteRepo, db)
+	searchService := services.NewSearchService(searchRepo)
+	approvalService := services.NewApprovalService(approvalRepo, authRepo, pricelistRepo, orderService, bookingService, cfg.Store, db)
+	backupService := services.NewBackupService(repositories.NewBackupRepository(db), settingRepo, cfg.BackupRunner, cfg.Store, db)
+	diagnosticsService := services.NewDiagnosticsService(repositories.NewDiagnosticsRepository(db))
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	deviceService := services.NewDeviceService(repositories.NewDeviceRepository(db))
+	tableSessionService := services.NewTableSessionService(tableSessionRepo, bookingRepo, gameTableRepo, lockerRepo,
+		repositories.NewSessionDepositRepository(db), cfg.Payments, publisher, db)
+	powerService := services.NewPowerService(bookingRepo, cfg.PowerControl)
+	hookahService := services.NewHookahService(hookahRepo, pricelistRepo, inventoryMvRepo, stockBatchRepo, publisher, db)
+	quickSaleService := services.NewQuickSaleService(quickSaleRepo, pricelistRepo, db)
+	clientAccountService := services.NewClientAccountService(clientAccountRepo, clientRepo, db)
+	clientTagService := services.NewClientTagService(clientTagRepo, clientRepo, db)
+	clientDocumentService := services.NewClientDocumentService(clientDocumentRepo, clientRepo)
+	lockerService := services.NewLockerService(lockerRepo, tableSessionRepo, bookingRepo, db)
+	lostFoundService := services.NewLostFoundService(lostFoundRepo, bookingRepo, clientRepo)
+	attendanceService := services.NewAttendanceService(attendanceRepo)
+	incidentService := services.NewIncidentService(incidentRepo, staffRepo, clientRepo, bookingRepo, orderRepo, attendanceService)
+	supplierService := services.NewSupplierService(supplierRepo, db)
+	reorderPointService := services.NewReorderPointService(repositories.NewReorderPointRepository(db), cfg.Store, db)    // Recalculated on schedule by cmd/server
+	stockBatchService := services.NewStockBatchService(stockBatchRepo, pricelistRepo, inventoryMvRepo, publisher, db)    // Expired batches are written off on schedule by cmd/server
+	shiftReportService := services.NewShiftReportService(shiftReportRepo, staffRepo, authRepo, nil)                      // Emailed by the subscriber in cmd/server
+	staffDocumentService := services.NewStaffDocumentService(staffDocumentRepo, staffRepo, authRepo, publisher, nil, db) // Expiry is notified on schedule by cmd/server
+	timeOffService := services.NewTimeOffService(timeOffRepo, staffRepo, db)
+	floorPlanService := services.NewFloorPlanService(floorPlanRepo, db)
+	reportViewService := services.NewReportViewService(reportViewRepo, cfg.Store)                          // Refreshed on schedule by cmd/server
+	orderArchiveService := services.NewOrderArchiveService(repositories.NewOrderArchiveRepository(db), db) // Archived on schedule by cmd/server
+	referenceService := services.NewReferenceService(repositories.NewReferenceRepository(db))
+	savedReportService := services.NewSavedReportService(repositories.NewSavedReportRepository(db), handlers.NewReportRunner(db, reportService), nil, db) // Emailed on schedule by cmd/server
+	salesTargetService := services.NewSalesTargetService(repositories.NewSalesTargetRepository(db), dayCloseRepo, authRepo, publisher, nil, db)           // Checked for falling behind on schedule by cmd/server
+	anomalyService := services.NewAnomalyService(repositories.NewAnomalyRepository(db), authRepo, calendarNoteRepo, publisher, nil, db)                   // Run nightly by cmd/server
+	promoCodeService := services.NewPromoCodeService(promoCodeRepo)
+	calendarNoteService := services.NewCalendarNoteService(calendarNoteRepo)
+	pushService := services.NewPushNotificationService(repositories.NewPushRepository(db), clientRepo, nil)                                        // Pushed by the subscriber in cmd/server
+	bookingReminderService := services.NewBookingReminderService(repositories.NewBookingReminderRepository(db), clientRepo, pushService, nil, nil) // Dispatched on schedule by cmd/server
+	permissionService := services.NewPermissionService(authRepo)
+	auditLogService := services.NewAuditLogService(auditLogRepo, db)
+	invitationService := services.NewInvitationService(repositories.NewInvitationRepository(db), authRepo, db)
+	setupService := services.NewSetupService(repositories.NewSetupRepository(db), authRepo, db)
+	gameTableService := services.NewGameTableService(gameTableRepo, bookingRepo, orderRepo, tableSessionService, publisher, db) // Statuses are synced on schedule by cmd/server
+	billService := services.NewBillService(orderService, tableSessionService, gameTableRepo)
+	syncService := services.NewSyncService(repositories.NewSyncRepository(db), orderService, orderRepo, pricelistRepo, clientRepo, gameTableRepo, dayCloseRepo)
+	dayCloseService := services.NewDayCloseService(dayCloseRepo, shiftReportRepo, orderService, tableSessionService, staffService, db)
 	// TODO: Initialize other services here as they are created
 
 	// Initialize Handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	pricelistHandler := handlers.NewPricelistHandler(pricelistService)
 	inventoryMvHandler := handlers.NewInventoryMovementHandler(inventoryMvService)
-	orderHandler := handlers.NewOrderHandler(orderService)
+	orderHandler := handlers.NewOrderHandler(orderService, approvalService)
 	clientHandler := handlers.NewClientHandler(clientService)
 	staffHandler := handlers.NewStaffHandler(staffService)
-	bookingHandler := handlers.NewBookingHandler(bookingService) // Added BookingHandler
+	bookingHandler := handlers.NewBookingHandler(bookingService, approvalService) // Added BookingHandler
+	searchHandler := handlers.NewSearchHandler(searchService)
+	dashboardHandler := handlers.NewDashboardHandler(reportService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	backupHandler := handlers.NewBackupHandler(backupService)
+	diagnosticsHandler := handlers.NewDiagnosticsHandler(diagnosticsService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	kioskHandler := handlers.NewKioskHandler(bookingService)
+	channelHandler := handlers.NewChannelHandler(orderService, bookingService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService)
+	tableSessionHandler := handlers.NewTableSessionHandler(tableSessionService, cfg.SessionAlerts)
+	powerHandler := handlers.NewPowerHandler(powerService)
+	hookahServiceHandler := handlers.NewHookahServiceHandler(hookahService, cfg.HookahAlerts)
+	quickSaleHandler := handlers.NewQuickSaleHandler(quickSaleService, approvalService)
+	clientAccountHandler := handlers.NewClientAccountHandler(clientAccountService)
+	clientTagHandler := handlers.NewClientTagHandler(clientTagService)
+	clientDocumentHandler := handlers.NewClientDocumentHandler(clientDocumentService)
+	lockerHandler := handlers.NewLockerHandler(lockerService)
+	lostFoundHandler := handlers.NewLostFoundHandler(lostFoundService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	reorderPointHandler := handlers.NewReorderPointHandler(reorderPointService)
+	stockBatchHandler := handlers.NewStockBatchHandler(stockBatchService)
+	shiftReportHandler := handlers.NewShiftReportHandler(shiftReportService)
+	staffDocumentHandler := handlers.NewStaffDocumentHandler(staffDocumentService)
+	timeOffHandler := handlers.NewTimeOffHandler(timeOffService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService)
+	floorPlanHandler := handlers.NewFloorPlanHandler(floorPlanService)
+	gameTableHandler := handlers.NewGameTableHandler(gameTableService)
+	billHandler := handlers.NewBillHandler(billService)
+	syncHandler := handlers.NewSyncHandler(syncService)
+	dayCloseHandler := handlers.NewDayCloseHandler(dayCloseService)
+	reportViewHandler := handlers.NewReportViewHandler(reportViewService)
+	orderArchiveHandler := handlers.NewOrderArchiveHandler(orderArchiveService)
+	referenceHandler := handlers.NewReferenceHandler(referenceService)
+	savedReportHandler := handlers.NewSavedReportHandler(savedReportService)
+	salesTargetHandler := handlers.NewSalesTargetHandler(salesTargetService)
+	anomalyHandler := handlers.NewAnomalyHandler(anomalyService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	calendarNoteHandler := handlers.NewCalendarNoteHandler(calendarNoteService)
+	pushHandler := handlers.NewPushHandler(pushService)
+	bookingReminderHandler := handlers.NewBookingReminderHandler(bookingReminderService)
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	setupHandler := handlers.NewSetupHandler(setupService)
 	// TODO: Initialize other handlers here as they are refactored
 
-	apiV1 := engine.Group("/api/v1")
+	h := apiHandlers{
+		auth:         authHandler,
+		pricelist:    pricelistHandler,
+		inventoryMv:  inventoryMvHandler,
+		order:        orderHandler,
+		client:       clientHandler,
+		staff:        staffHandler,
+		booking:      bookingHandler,
+		search:       searchHandler,
+		dashboard:    dashboardHandler,
+		approval:     approvalHandler,
+		backup:       backupHandler,
+		diagnostics:  diagnosticsHandler,
+		apiKey:       apiKeyHandler,
+		kiosk:        kioskHandler,
+		kioskAuth:    middleware.APIKeyAuth(apiKeyService, models.APIKeyScopeKiosk),
+		device:       deviceHandler,
+		deviceAuth:   middleware.APIKeyAuth(apiKeyService, models.APIKeyScopeDevice),
+		channel:      channelHandler,
+		channelAuth:  channelAuth(apiKeyService),
+		maskFields:   middleware.MaskFields(permissionService),
+		auditLog:     middleware.AuditLog(auditLogService),
+		tableSession: tableSessionHandler,
+		power:        powerHandler,
+		hookah:       hookahServiceHandler,
+		quickSale:    quickSaleHandler,
+		account:      clientAccountHandler,
+		clientTag:    clientTagHandler,
+		document:     clientDocumentHandler,
+		docAccess:    middleware.RequirePermission(permissionService, models.PermissionViewClientDocuments),
+		locker:       lockerHandler,
+		lostFound:    lostFoundHandler,
+		incident:     incidentHandler,
+		supplier:     supplierHandler,
+		reorderPoint: reorderPointHandler,
+		stockBatch:   stockBatchHandler,
+		shiftReport:  shiftReportHandler,
+		staffDoc:     staffDocumentHandler,
+		timeOff:      timeOffHandler,
+		attendance:   attendanceHandler,
+		floorPlan:    floorPlanHandler,
+		gameTable:    gameTableHandler,
+		bill:         billHandler,
+		sync:         syncHandler,
+		dayClose:     dayCloseHandler,
+		reportView:   reportViewHandler,
+		orderArchive: orderArchiveHandler,
+		references:   referenceHandler,
+		savedReports: savedReportHandler,
+		salesTargets: salesTargetHandler,
+		anomalies:    anomalyHandler,
+		promoCodes:   promoCodeHandler,
+		calendar:     calendarNoteHandler,
+		push:         pushHandler,
+		reminders:    bookingReminderHandler,
+		auditLogs:    auditLogHandler,
+		invitation:   invitationHandler,
+		setup:        setupHandler,
+	}
+
+	// Readiness for load balancers and orchestrators; unauthenticated like /ping
+	engine.GET("/readyz", diagnosticsHandler.Ready)
+
+	// v1 and v2 are mounted side by side on the same handlers and services. Handlers
+	// whose response shape changed in v2 (e.g. the list envelope) check the version
+	// tagged on the request. v1 announces its sunset once the api_v1_sunset setting is set.
+	apiV1 := engine.Group("/api/v1", middleware.APIVersion(middleware.APIVersion1), middleware.DeprecatedAPI("/api/v2"))
+	registerAPIRoutes(apiV1, h, cfg)
+
+	apiV2 := engine.Group("/api/v2", middleware.APIVersion(middleware.APIVersion2))
+	registerAPIRoutes(apiV2, h, cfg)
+
+	// GraphQL evolves its schema in place instead of by version, so it is mounted once
+	graphqlHandler := gin.WrapH(graph.NewHandler(&graph.Resolver{
+		ClientService:    clientService,
+		BookingService:   bookingService,
+		OrderService:     orderService,
+		PricelistService: pricelistService,
+		StaffService:     staffService,
+		ReportService:    reportService,
+	}))
+	graphqlRoutes := engine.Group("/api/graphql", middleware.AuthMiddleware(cfg.Store), middleware.RoleAuthMiddleware("Admin", "Staff"), h.maskFields, h.auditLog)
+	{
+		graphqlRoutes.GET("", graphqlHandler)
+		graphqlRoutes.POST("", graphqlHandler)
+	}
+}
+
+// apiHandlers is the set of handlers mounted for each API version.
+type apiHandlers struct {
+	auth         *handlers.AuthHandler
+	pricelist    *handlers.PricelistHandler
+	inventoryMv  *handlers.InventoryMovementHandler
+	order        *handlers.OrderHandler
+	client       *handlers.ClientHandler
+	staff        *handlers.StaffHandler
+	booking      *handlers.BookingHandler
+	search       *handlers.SearchHandler
+	dashboard    *handlers.DashboardHandler
+	approval     *handlers.ApprovalHandler
+	backup       *handlers.BackupHandler
+	diagnostics  *handlers.DiagnosticsHandler
+	apiKey       *handlers.APIKeyHandler
+	kiosk        *handlers.KioskHandler
+	kioskAuth    gin.HandlerFunc // Admits API keys with the kiosk scope
+	device       *handlers.DeviceHandler
+	deviceAuth   gin.HandlerFunc // Admits API keys with the device scope
+	maskFields   gin.HandlerFunc // Masks the fields the caller's role may not see
+	auditLog     gin.HandlerFunc // Records changes and impersonated requests in the audit log
+	tableSession *handlers.TableSessionHandler
+	power        *handlers.PowerHandler
+	hookah       *handlers.HookahServiceHandler
+	quickSale    *handlers.QuickSaleHandler
+	account      *handlers.ClientAccountHandler
+	clientTag    *handlers.ClientTagHandler
+	document     *handlers.ClientDocumentHandler
+	docAccess    gin.HandlerFunc // Admits roles with the view_client_documents permission
+	locker       *handlers.LockerHandler
+	lostFound    *handlers.LostFoundHandler
+	incident     *handlers.IncidentHandler
+	supplier     *handlers.SupplierHandler
+	reorderPoint *handlers.ReorderPointHandler
+	stockBatch   *handlers.StockBatchHandler
+	shiftReport  *handlers.ShiftReportHandler
+	staffDoc     *handlers.StaffDocumentHandler
+	timeOff      *handlers.TimeOffHandler
+	attendance   *handlers.AttendanceHandler
+	floorPlan    *handlers.FloorPlanHandler
+	gameTable    *handlers.GameTableHandler
+	bill         *handlers.BillHandler
+	sync         *handlers.SyncHandler
+	dayClose     *handlers.DayCloseHandler
+	reportView   *handlers.ReportViewHandler
+	orderArchive *handlers.OrderArchiveHandler
+	references   *handlers.ReferenceHandler
+	savedReports *handlers.SavedReportHandler
+	salesTargets *handlers.SalesTargetHandler
+	anomalies    *handlers.AnomalyHandler
+	promoCodes   *handlers.PromoCodeHandler
+	calendar     *handlers.CalendarNoteHandler
+	push         *handlers.PushHandler
+	reminders    *handlers.BookingReminderHandler
+	auditLogs    *handlers.AuditLogHandler
+	invitation   *handlers.InvitationHandler
+	setup        *handlers.SetupHandler
+
+	channel     *handlers.ChannelHandler
+	channelAuth map[string]gin.HandlerFunc // Admits API keys with the scope of each self-service channel
+}
+
+// registerAPIRoutes mounts all routes of one API version on the given group.
+func registerAPIRoutes(api *gin.RouterGroup, h apiHandlers, cfg Config) {
+	// Retried creates must not place an order or a booking twice
+	idempotency := middleware.Idempotency(cfg.Store, idempotencyKeyTTL)
 
 	// Setup public authentication routes
-	// Note: Original SetupAuthRoutes(apiV1, authHandler) might be split if some auth routes are public
+	// Note: Original SetupAuthRoutes(api, h.auth) might be split if some auth routes are public
 	// and some (like /me, /logout) are authenticated. For this example, assuming all auth routes are passed authHandler.
 	// If /register and /login are public and don't need middleware.AuthMiddleware() applied to their group:
-	// publicAuthRoutes := apiV1.Group("/auth")
-	// SetupPublicAuthRoutes(publicAuthRoutes, authHandler) // e.g. for /register, /login
+	// publicAuthRoutes := api.Group("/auth")
+	// SetupPublicAuthRoutes(publicAuthRoutes, h.auth) // e.g. for /register, /login
 
 	// Setup authenticated routes
-	authenticated := apiV1.Group("")
-	authenticated.Use(middleware.AuthMiddleware())
+	authenticated := api.Group("")
+	authenticated.Use(middleware.AuthMiddleware(cfg.Store))
+	authenticated.Use(h.maskFields) // Contacts and salaries are masked for roles without permission
+	authenticated.Use(h.auditLog)
 	{
 		// Assuming /auth/me, /auth/logout are authenticated:
-		SetupAuthenticatedAuthRoutes(authenticated.Group("/auth"), authHandler) // Grouping auth routes under /auth path
-		
-		SetupOrderRoutes(authenticated, orderHandler)
-		SetupPricelistCategoryRoutes(authenticated, pricelistHandler)
-		SetupPricelistItemRoutes(authenticated, pricelistHandler)
-		SetupInventoryMovementRoutes(authenticated, inventoryMvHandler)
-		SetupClientRoutes(authenticated, clientHandler)
-		SetupStaffRoutes(authenticated, staffHandler)
-		SetupShiftRoutes(authenticated, staffHandler)
-		SetupBookingRoutes(authenticated, bookingHandler) // Updated to pass bookingHandler
+		SetupAuthenticatedAuthRoutes(authenticated.Group("/auth"), h.auth) // Grouping auth routes under /auth path
+
+		SetupOrderRoutes(authenticated, h.order, idempotency)
+		SetupPricelistCategoryRoutes(authenticated, h.pricelist)
+		SetupPricelistItemRoutes(authenticated, h.pricelist)
+		SetupInventoryMovementRoutes(authenticated, h.inventoryMv)
+		SetupClientRoutes(authenticated, h.client)
+		SetupClientAccountRoutes(authenticated, h.account, idempotency)
+		SetupClientTagRoutes(authenticated, h.clientTag)
+		SetupClientDocumentRoutes(authenticated, h.document, h.docAccess)
+		SetupStaffRoutes(authenticated, h.staff)
+		SetupStaffDocumentRoutes(authenticated, h.staffDoc)
+		SetupShiftRoutes(authenticated, h.staff, h.shiftReport)
+		SetupTimeOffRoutes(authenticated, h.timeOff)
+		SetupAttendanceRoutes(authenticated, h.attendance)
+		SetupFloorPlanRoutes(authenticated, h.floorPlan)
+		SetupBookingRoutes(authenticated, h.booking, idempotency) // Updated to pass bookingHandler
+		SetupSearchRoutes(authenticated, h.search)
+		SetupApprovalRoutes(authenticated, h.approval)
+		SetupBackupRoutes(authenticated, h.backup)
+		SetupDayCloseRoutes(authenticated, h.dayClose)
+		SetupReportViewRoutes(authenticated, h.reportView)
+		SetupOrderArchiveRoutes(authenticated, h.orderArchive)
+		SetupReferenceRoutes(authenticated, h.references)
+		SetupAuditLogRoutes(authenticated, h.auditLogs)
+		SetupInvitationRoutes(authenticated, h.invitation)
+		SetupDiagnosticsRoutes(authenticated, h.diagnostics)
+		SetupAPIKeyRoutes(authenticated, h.apiKey)
+		SetupDeviceAdminRoutes(authenticated, h.device)
+		SetupTableSessionRoutes(authenticated, h.tableSession)
+		SetupTablePowerRoutes(authenticated, h.power)
+		SetupHookahServiceRoutes(authenticated, h.hookah)
+		SetupQuickSaleRoutes(authenticated, h.quickSale, idempotency)
+		SetupLockerRoutes(authenticated, h.locker)
+		SetupLostFoundRoutes(authenticated, h.lostFound)
+		SetupIncidentRoutes(authenticated, h.incident)
+		SetupSupplierRoutes(authenticated, h.supplier)
+		SetupReorderPointRoutes(authenticated, h.reorderPoint)
+		SetupStockBatchRoutes(authenticated, h.stockBatch)
+		SetupSalesTargetRoutes(authenticated, h.salesTargets)
+		SetupAnomalyRoutes(authenticated, h.anomalies)
+		SetupPromoCodeRoutes(authenticated, h.promoCodes)
+		SetupCalendarNoteRoutes(authenticated, h.calendar)
+		SetupPushRoutes(authenticated, h.push)
+		SetupBookingReminderRoutes(authenticated, h.reminders)
 
 		// Placeholder for other route setups, assuming they are also authenticated
-		SetupBarItemRoutes(authenticated)           // Still uses old direct handlers
-		SetupHookahItemRoutes(authenticated)        // Still uses old direct handlers
-		SetupGameTableRoutes(authenticated)         // Pass handler when available
-		SetupSettingsRoutes(authenticated)          // Pass handler when available
-		SetupReportRoutes(authenticated)            // Pass handler when available
-		SetupDashboardRoutes(authenticated)         // Pass handler when available
+		SetupBarItemRoutes(authenticated)    // Still uses old direct handlers
+		SetupHookahItemRoutes(authenticated) // Still uses old direct handlers
+		SetupGameTableRoutes(authenticated, h.gameTable)
+		SetupBillRoutes(authenticated, h.bill)
+		SetupSyncRoutes(authenticated, h.sync)
+		SetupSettingsRoutes(authenticated) // Pass handler when available
+		SetupReportRoutes(authenticated, h.dashboard, h.savedReports)
+		SetupDashboardRoutes(authenticated, h.dashboard)
+		SetupMetaRoutes(authenticated)
 	}
 
 	// If /auth/register and /auth/login are truly public (no AuthMiddleware):
 	// Re-define SetupAuthRoutes to split public and private, or have two functions.
 	// Example:
-	authPublicRoutes := apiV1.Group("/auth")
-	SetupPublicAuthRoutes(authPublicRoutes, authHandler) // For /register, /login
+	// Login attempts are limited per client IP across all instances
+	authPublicRoutes := api.Group("/auth", middleware.RateLimit(cfg.Store, "auth", cfg.AuthRateLimit, time.Minute))
+	SetupPublicAuthRoutes(authPublicRoutes, h.auth) // For /register, /login
+	authPublicRoutes.POST("/accept-invitation", h.invitation.AcceptInvitation)
+	// First boot only: creates the first Admin, then locks itself
+	setupRoutes := api.Group("/setup")
+	setupRoutes.GET("", h.setup.GetSetupStatus)
+	setupRoutes.POST("", middleware.RateLimit(cfg.Store, "auth", cfg.AuthRateLimit, time.Minute), h.setup.CompleteSetup)
+	SetupPublicRoutes(api)
+	SetupKioskRoutes(api, h.kiosk, h.kioskAuth)
+	SetupDeviceRoutes(api, h.device, h.deviceAuth)
+	SetupChannelRoutes(api, h.channel, h.channelAuth)
+	SetupClientAppRoutes(api, h.push, h.reminders, h.channelAuth[models.APIKeyScopeClientApp])
+	SetupTelegramRoutes(api, h.reminders, h.channelAuth[models.APIKeyScopeTelegram])
+	SetupRealtimeRoutes(api, h.tableSession, h.hookah)
+}
+
+// channelAuth returns the API key authentication of each scope of models.ChannelAPIKeyScopes.
+func channelAuth(apiKeyService services.APIKeyService) map[string]gin.HandlerFunc {
+	auth := make(map[string]gin.HandlerFunc, len(models.ChannelAPIKeyScopes))
+	for _, scope := range models.ChannelAPIKeyScopes {
+		auth[scope] = middleware.APIKeyAuth(apiKeyService, scope)
+	}
+	return auth
 }
 
 // Helper for clarity if splitting auth routes (example, actual split logic is in SetupAuthRoutes)
 func SetupPublicAuthRoutes(group *gin.RouterGroup, authHandler *handlers.AuthHandler) {
-    group.POST("/register", authHandler.RegisterUser)
-    group.POST("/login", authHandler.LoginUser)
-    group.POST("/refresh-token", authHandler.RefreshToken)
+	group.POST("/register", authHandler.RegisterUser)
+	group.POST("/login", authHandler.LoginUser)
+	group.POST("/refresh-token", authHandler.RefreshToken)
 }
 
 func SetupAuthenticatedAuthRoutes(group *gin.RouterGroup, authHandler *handlers.AuthHandler) {
-    group.POST("/logout", authHandler.LogoutUser)
-    group.GET("/me", authHandler.GetCurrentUser)
+	group.POST("/logout", authHandler.LogoutUser)
+	group.GET("/me", authHandler.GetCurrentUser)
+	group.GET("/sessions", authHandler.GetSessions)
+	group.DELETE("/sessions/:id", authHandler.RevokeSession)
+	group.PUT("/me/language", authHandler.UpdatePreferredLanguage)
+	group.PATCH("/me", authHandler.UpdateProfile)
+	group.PUT("/me/avatar", authHandler.UploadAvatar)
+	group.GET("/me/avatar", authHandler.GetAvatar)
+	group.DELETE("/me/avatar", authHandler.DeleteAvatar)
+	group.POST("/impersonate", middleware.RoleAuthMiddleware("Admin"), authHandler.Impersonate)
+}
+
+// registerValidators adds the custom validation rules (see package validation) to the
+// binding validator, so DTO tags like `binding:"omitempty,phone"` work.
+func registerValidators() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// The rules are fixed at compile time, so failing to register one is a programming error
+		if err := validation.Register(v); err != nil {
+			panic(err)
+		}
+	}
 }
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrAnomalyValidation   = apperrors.New(utils.ErrCodeValidationFailed, "anomaly check validation error")
+	ErrAnomalyCheckExists  = apperrors.New(utils.ErrCodeConflict, "the day was already checked for anomalies")
+	ErrAnomalyDayNotClosed = apperrors.New(utils.ErrCodeValidationFailed, "only past days can be checked for anomalies")
+)
+
+var (
+	anomalySettings   = models.DefaultAnomalySettings()
+	anomalySettingsMu sync.RWMutex
+)
+
+// SetAnomalySettings sets when the nightly check flags a day (the anomaly_detection setting).
+func SetAnomalySettings(settings models.AnomalySettings) {
+	anomalySettingsMu.Lock()
+	defer anomalySettingsMu.Unlock()
+	anomalySettings = settings
+}
+
+// CurrentAnomalySettings returns the configured anomaly detection.
+func CurrentAnomalySettings() models.AnomalySettings {
+	anomalySettingsMu.RLock()
+	defer anomalySettingsMu.RUnlock()
+	return anomalySettings
+}
+
+// AnomalyCheckInterval is how often RunNightlyCheck looks for the previous day to check; the
+// day is checked once, by the first run after midnight club time.
+var AnomalyCheckInterval = time.Hour
+
+// MinAnomalyTrailingDays is the least number of trailing days with revenue a day is compared
+// with; a newer club records its checks without anomalies.
+const MinAnomalyTrailingDays = 7
+
+// orderDeleteActions are the audit log actions of deleting an order, one per API version.
+var orderDeleteActions = []string{"DELETE /api/v1/orders/:id", "DELETE /api/v2/orders/:id"}
+
+// --- AnomalyService Interface ---
+type AnomalyService interface {
+	// CheckDay compares the revenue, discount total and cancelled and deleted orders of a past
+	// business day (YYYY-MM-DD) of this branch with their averages over the trailing days,
+	// records the check and publishes anomaly.detected when any deviates beyond the threshold
+	// of the anomaly_detection setting. A day is checked once.
+	CheckDay(date string) (*models.AnomalyCheck, error)
+	// RunNightlyCheck checks the previous day at start and every AnomalyCheckInterval until ctx
+	// is done, unless it was checked already. Every instance may run it.
+	RunNightlyCheck(ctx context.Context)
+	// HandleAnomalyEvent emails the anomalies of an anomaly.detected event to the active Admins
+	// with an email address. A failure is returned so the relay retries the event.
+	HandleAnomalyEvent(ctx context.Context, event models.DomainEvent) error
+	// GetChecks lists the checks of this branch between two dates (either may be empty),
+	// newest first, with the calendar notes of their days; with onlyAnomalies only those that
+	// found any.
+	GetChecks(fromDate, toDate string, onlyAnomalies bool) ([]models.AnomalyCheck, error)
+}
+
+type anomalyService struct {
+	anomalyRepo repositories.AnomalyRepository
+	authRepo    repositories.AuthRepository
+	noteRepo    repositories.CalendarNoteRepository // The calendar notes that may explain the anomalies
+	publisher   events.Publisher                    // Records the notices in their transaction
+	sender      MailSender                          // nil if email is not configured
+	db          *sql.DB
+}
+
+// NewAnomalyService creates a new AnomalyService. sender may be nil, which disables emailing
+// the anomalies.
+func NewAnomalyService(anomalyRepo repositories.AnomalyRepository, authRepo repositories.AuthRepository,
+	noteRepo repositories.CalendarNoteRepository, publisher events.Publisher, sender MailSender, db *sql.DB) AnomalyService {
+	return &anomalyService{
+		anomalyRepo: anomalyRepo,
+		authRepo:    authRepo,
+		noteRepo:    noteRepo,
+		publisher:   publisher,
+		sender:      sender,
+		db:          db,
+	}
+}
+
+func (s *anomalyService) CheckDay(date string) (*models.AnomalyCheck, error) {
+	day, err := utils.ParseClubDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrAnomalyValidation)
+	}
+	if !day.Before(utils.StartOfDay(utils.NowInClub())) {
+		return nil, ErrAnomalyDayNotClosed
+	}
+	settings := CurrentAnomalySettings()
+	branchCode := utils.BranchCode()
+
+	days, err := s.anomalyRepo.GetDailyMetrics(branchCode, ShiftSalesOrderStatuses, orderDeleteActions,
+		day.AddDate(0, 0, -settings.TrailingDays).Format(utils.DateLayout), date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily metrics: %w", err)
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no metrics returned for %s", date)
+	}
+	check := &models.AnomalyCheck{
+		BranchCode:   branchCode,
+		BusinessDate: date,
+		Metrics:      days[len(days)-1],
+		CheckedAt:    utils.NowUTC(),
+	}
+	check.Metrics.BusinessDate = ""
+	if averages := trailingAverages(days[:len(days)-1]); averages != nil {
+		check.Averages = averages
+		check.Anomalies = findAnomalies(check.Metrics, *averages, settings)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created, err := s.anomalyRepo.CreateCheck(tx, check)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAnomalyCheckExists
+	}
+	if len(check.Anomalies) > 0 {
+		payload := events.AnomalyPayload{
+			CheckID:      check.ID,
+			BranchCode:   branchCode,
+			BusinessDate: date,
+			Anomalies:    check.Anomalies,
+		}
+		if err := s.publisher.Publish(tx, events.AnomalyDetected, events.AggregateAnomalyCheck, check.ID, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit anomaly check: %w", err)
+	}
+	return check, nil
+}
+
+// trailingAverages averages the metrics of the days with revenue, i.e. the club was open; nil
+// with fewer than MinAnomalyTrailingDays of them.
+func trailingAverages(days []models.RiskMetrics) *models.RiskMetrics {
+	revenue, discounts := decimal.Zero, decimal.Zero
+	var cancelled, deleted float64
+	open := 0
+	for _, day := range days {
+		if !day.Revenue.IsPositive() {
+			continue
+		}
+		open++
+		revenue = revenue.Add(day.Revenue.Decimal())
+		discounts = discounts.Add(day.Discounts.Decimal())
+		cancelled += day.CancelledCount
+		deleted += day.DeletedCount
+	}
+	if open < MinAnomalyTrailingDays {
+		return nil
+	}
+	n := decimal.NewFromInt(int64(open))
+	return &models.RiskMetrics{
+		Revenue:        models.NewMoney(revenue.Div(n)).Round(),
+		Discounts:      models.NewMoney(discounts.Div(n)).Round(),
+		CancelledCount: roundTo(cancelled/float64(open), 2),
+		DeletedCount:   roundTo(deleted/float64(open), 2),
+	}
+}
+
+// findAnomalies flags the metrics of a day beyond the threshold from their averages: the
+// revenue in either direction, the others only above. A count is flagged from MinCount, and
+// then also against an average of 0.
+func findAnomalies(day, averages models.RiskMetrics, settings models.AnomalySettings) []models.Anomaly {
+	threshold := float64(settings.ThresholdPercent)
+	var anomalies []models.Anomaly
+	flag := func(metric string, value, average decimal.Decimal, bothWays bool) {
+		deviation := deltaPercent(value, average)
+		if deviation == nil {
+			return
+		}
+		if *deviation <= threshold && (!bothWays || *deviation >= -threshold) {
+			return
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			Metric:           metric,
+			Value:            value.InexactFloat64(),
+			Average:          average.InexactFloat64(),
+			DeviationPercent: deviation,
+		})
+	}
+	flagCount := func(metric string, value, average float64) {
+		if value < float64(settings.MinCount) {
+			return
+		}
+		if average == 0 {
+			anomalies = append(anomalies, models.Anomaly{Metric: metric, Value: value})
+			return
+		}
+		flag(metric, decimal.NewFromFloat(value), decimal.NewFromFloat(average), false)
+	}
+
+	flag(models.AnomalyMetricRevenue, day.Revenue.Decimal(), averages.Revenue.Decimal(), true)
+	flag(models.AnomalyMetricDiscounts, day.Discounts.Decimal(), averages.Discounts.Decimal(), false)
+	flagCount(models.AnomalyMetricCancelled, day.CancelledCount, averages.CancelledCount)
+	flagCount(models.AnomalyMetricDeleted, day.DeletedCount, averages.DeletedCount)
+	return anomalies
+}
+
+func (s *anomalyService) RunNightlyCheck(ctx context.Context) {
+	ticker := time.NewTicker(AnomalyCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.checkPreviousDay()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkPreviousDay checks the day before today unless it was checked already.
+func (s *anomalyService) checkPreviousDay() {
+	date := utils.NowInClub().AddDate(0, 0, -1).Format(utils.DateLayout)
+	checked, err := s.anomalyRepo.HasCheck(utils.BranchCode(), date)
+	if err != nil {
+		utils.LogError(err, "Failed to look up the anomaly check of "+date)
+		return
+	}
+	if checked {
+		return
+	}
+	check, err := s.CheckDay(date)
+	if errors.Is(err, ErrAnomalyCheckExists) {
+		return // Checked by another instance meanwhile
+	}
+	if err != nil {
+		utils.LogError(err, "Failed to check "+date+" for anomalies")
+		return
+	}
+	utils.LogInfo("Checked the day for anomalies", map[string]interface{}{"business_date": date, "anomalies": len(check.Anomalies)})
+}
+
+func (s *anomalyService) HandleAnomalyEvent(ctx context.Context, event models.DomainEvent) error {
+	if s.sender == nil {
+		return nil
+	}
+	var payload events.AnomalyPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+	}
+	recipients, err := s.authRepo.GetAdminEmails()
+	if err != nil {
+		return fmt.Errorf("failed to get admin emails: %w", err)
+	}
+	if len(recipients) == 0 {
+		utils.LogWarn("No Admin has an email address; anomalies not emailed", map[string]interface{}{"check_id": payload.CheckID})
+		return nil
+	}
+
+	var lines strings.Builder
+	for _, anomaly := range payload.Anomalies {
+		if anomaly.DeviationPercent == nil {
+			fmt.Fprintf(&lines, "- %s: %g, usually none\n", anomaly.Metric, anomaly.Value)
+			continue
+		}
+		fmt.Fprintf(&lines, "- %s: %g against an average of %g (%+.1f%%)\n",
+			anomaly.Metric, anomaly.Value, anomaly.Average, *anomaly.DeviationPercent)
+	}
+	notes, err := s.noteRepo.GetNotes(payload.BranchCode, payload.BusinessDate, payload.BusinessDate)
+	if err != nil {
+		return fmt.Errorf("failed to get calendar notes: %w", err)
+	}
+	if len(notes) > 0 {
+		lines.WriteString("The calendar notes of the day:\n")
+		for _, note := range notes {
+			fmt.Fprintf(&lines, "- %s (%s)\n", note.Title, note.Category)
+		}
+	}
+	subject := fmt.Sprintf("Unusual figures on %s, branch %s", payload.BusinessDate, payload.BranchCode)
+	body := fmt.Sprintf("The nightly check of %s found figures far off their trailing average:\n%s"+
+		"See the checks under /reports/anomalies.\n", payload.BusinessDate, lines.String())
+	if err := s.sender.Send(ctx, recipients, subject, body); err != nil {
+		return fmt.Errorf("failed to email anomaly check ID %d: %w", payload.CheckID, err)
+	}
+	return nil
+}
+
+func (s *anomalyService) GetChecks(fromDate, toDate string, onlyAnomalies bool) ([]models.AnomalyCheck, error) {
+	for _, date := range []string{fromDate, toDate} {
+		if date != "" && !utils.IsValidDate(date) {
+			return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrAnomalyValidation)
+		}
+	}
+	checks, err := s.anomalyRepo.GetChecks(utils.BranchCode(), fromDate, toDate, onlyAnomalies)
+	if err != nil || len(checks) == 0 {
+		return checks, err
+	}
+	// Newest first, so the last check is the earliest
+	notes, err := s.noteRepo.GetNotes(utils.BranchCode(), checks[len(checks)-1].BusinessDate, checks[0].BusinessDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar notes: %w", err)
+	}
+	for i := range checks {
+		checks[i].Notes = notesCovering(notes, checks[i].BusinessDate)
+	}
+	return checks, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrAPIKeyNotFound   = apperrors.New(utils.ErrCodeNotFound, "API key not found")
+	ErrInvalidAPIKey    = apperrors.New(utils.ErrCodeUnauthorized, "invalid or revoked API key")
+	ErrAPIKeyScope      = apperrors.New(utils.ErrCodeForbidden, "API key does not have the required scope")
+	ErrAPIKeyValidation = apperrors.New(utils.ErrCodeValidationFailed, "API key validation error")
+)
+
+// apiKeyPrefix starts every API key, so leaked keys are easy to recognise.
+const apiKeyPrefix = "psk_"
+
+// CreateAPIKeyRequest is the body of POST /admin/api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"` // Of models.APIKeyScopes
+}
+
+// CreatedAPIKey is a new API key with its secret, which is only ever returned once.
+type CreatedAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// --- APIKeyService Interface ---
+type APIKeyService interface {
+	CreateAPIKey(req CreateAPIKeyRequest, createdBy int64) (*CreatedAPIKey, error)
+	GetAPIKeys() ([]models.APIKey, error)
+	RevokeAPIKey(id int64) error
+	// AuthenticateAPIKey returns the active key matching key. It returns ErrInvalidAPIKey
+	// for unknown or revoked keys and ErrAPIKeyScope if the key lacks scope.
+	AuthenticateAPIKey(key, scope string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new APIKeyService.
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository) APIKeyService {
+	return &apiKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+func (s *apiKeyService) CreateAPIKey(req CreateAPIKeyRequest, createdBy int64) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrAPIKeyValidation)
+	}
+	for _, scope := range req.Scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: invalid scope '%s', must be one of %v", ErrAPIKeyValidation, scope, models.APIKeyScopes)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+	created, err := s.apiKeyRepo.CreateAPIKey(&models.APIKey{
+		Name:      name,
+		KeyHash:   hashAPIKey(key),
+		Scopes:    req.Scopes,
+		CreatedBy: &createdBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return &CreatedAPIKey{APIKey: *created, Key: key}, nil
+}
+
+func (s *apiKeyService) GetAPIKeys() ([]models.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(id int64) error {
+	if err := s.apiKeyRepo.RevokeAPIKey(id, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+func (s *apiKeyService) AuthenticateAPIKey(key, scope string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	apiKey, err := s.apiKeyRepo.GetActiveAPIKeyByHash(hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to authenticate API key: %w", err)
+	}
+	if !apiKey.HasScope(scope) {
+		return nil, ErrAPIKeyScope
+	}
+	// Last use is informational, so failing to record it does not fail the request
+	now := utils.NowUTC()
+	if err := s.apiKeyRepo.TouchAPIKey(apiKey.ID, now); err != nil {
+		utils.LogError(err, "AuthenticateAPIKey: failed to record last use of API key")
+	} else {
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, nil
+}
+
+// hashAPIKey returns the SHA-256 of key, hex encoded. Keys are random, so a fast hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
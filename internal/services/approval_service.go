@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrApprovalRequired   = apperrors.New(utils.ErrCodeForbidden, "action requires manager approval")
+	ErrApprovalNotFound   = apperrors.New(utils.ErrCodeNotFound, "approval not found")
+	ErrApprovalNotPending = apperrors.New(utils.ErrCodeConflict, "approval has already been decided")
+	ErrInvalidApprovalPIN = apperrors.New(utils.ErrCodeForbidden, "invalid approval PIN")
+	ErrApprovalPINLocked  = apperrors.New(utils.ErrCodeTooManyRequests, "too many invalid approval PINs, try again later")
+	// Wraps the error of the approved action, which gives the status of the response
+	ErrApprovalActionFailed = errors.New("approved action failed")
+)
+
+// Sensitive actions that need an Admin's approval.
+const (
+	ApprovalActionOrderDiscount            = "order.discount" // Creating an order with a large discount
+	ApprovalActionOrderRefund              = "order.refund"
+	ApprovalActionOrderDelete              = "order.delete"
+	ApprovalActionBookingBlacklistOverride = "booking.blacklist_override" // Booking a blacklisted client, see BookingPolicy.BlacklistOverride
+)
+
+// Approval statuses.
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+	ApprovalStatusFailed   = "failed" // Approved, but executing the action failed
+)
+
+// LargeDiscountPercent is the share of the order total from which a discount is
+// considered large: it needs approval and is shown in the activity feed.
+const LargeDiscountPercent = 20
+
+const (
+	approverRole      = "Admin"
+	minApprovalPINLen = 6
+	maxApprovalPINLen = 8
+)
+
+// MaxApprovalPINAttempts is how many invalid approval PINs a user, or a client IP, may
+// enter before PINs from them are refused for ApprovalPINLockout.
+const (
+	MaxApprovalPINAttempts = 5
+	ApprovalPINLockout     = 15 * time.Minute
+)
+
+// ApprovalActor identifies who performs a sensitive action. Admins are approved
+// implicitly; anyone else is approved on the spot by the PIN of the Admin they name,
+// or gets a pending approval that an Admin decides later.
+type ApprovalActor struct {
+	UserID     int64
+	Role       string
+	PIN        string // Approval PIN an Admin entered on the requester's device, if any
+	ApproverID int64  // User ID of the Admin whose PIN it is
+	IP         string // Client IP of the request, counted with the user for the PIN attempt limit
+}
+
+// ApprovalRequiredError is returned when a sensitive action was not performed but
+// recorded as a pending approval. It matches ErrApprovalRequired with errors.Is.
+type ApprovalRequiredError struct {
+	Approval *models.Approval
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("%s (approval %d)", ErrApprovalRequired, e.Approval.ID)
+}
+
+func (e *ApprovalRequiredError) Is(target error) bool { return target == ErrApprovalRequired }
+
+// --- ApprovalService Interface ---
+type ApprovalService interface {
+	// CreateOrder, UpdateOrderStatus and DeleteOrder perform the order operation if it
+	// is not sensitive or actor is approved, otherwise they return *ApprovalRequiredError.
+	CreateOrder(req CreateOrderRequest, actor ApprovalActor) (*models.Order, error)
+	UpdateOrderStatus(orderID int64, req UpdateOrderStatusRequest, actor ApprovalActor) (*models.Order, error)
+	DeleteOrder(orderID int64, actor ApprovalActor) error
+	// CreateBooking creates the booking. A booking for a blacklisted client with
+	// OverrideBlacklist set is made if actor is approved, otherwise it returns
+	// *ApprovalRequiredError; without OverrideBlacklist it fails with ErrClientBlacklisted.
+	CreateBooking(req CreateBookingRequest, actor ApprovalActor) (*models.Booking, error)
+
+	GetApprovals(status *string, page, pageSize int) ([]models.Approval, int, error)
+	GetApprovalByID(id int64) (*models.Approval, error)
+	// Approve grants a pending approval and performs the original action. It returns
+	// the action's result, e.g. the created or refunded order (nil for deletions).
+	Approve(id, adminUserID int64) (*models.Approval, interface{}, error)
+	Reject(id, adminUserID int64, reason *string) (*models.Approval, error)
+	SetApprovalPIN(adminUserID int64, pin string) error
+}
+
+// --- approvalService Implementation ---
+type approvalService struct {
+	approvalRepo   repositories.ApprovalRepository
+	authRepo       repositories.AuthRepository
+	pricelistRepo  repositories.PricelistRepository
+	orderService   OrderService
+	bookingService BookingService
+	store          kvstore.Store // Invalid PIN attempt counters, shared by all instances
+	db             *sql.DB
+}
+
+// NewApprovalService creates a new instance of ApprovalService.
+func NewApprovalService(
+	ar repositories.ApprovalRepository,
+	authRepo repositories.AuthRepository,
+	pr repositories.PricelistRepository,
+	orderService OrderService,
+	bookingService BookingService,
+	store kvstore.Store,
+	db *sql.DB,
+) ApprovalService {
+	return &approvalService{
+		approvalRepo:   ar,
+		authRepo:       authRepo,
+		pricelistRepo:  pr,
+		orderService:   orderService,
+		bookingService: bookingService,
+		store:          store,
+		db:             db,
+	}
+}
+
+func (s *approvalService) CreateOrder(req CreateOrderRequest, actor ApprovalActor) (*models.Order, error) {
+	req.CallerRole = actor.Role
+	total, priced := s.orderTotal(req)
+	if !priced || req.DiscountAmount == nil || !req.DiscountAmount.IsPositive() {
+		return s.orderService.CreateOrder(req)
+	}
+	// A discount over the caller's limit fails with ErrDiscountLimitExceeded, so the
+	// client can ask for a manager override, unless the request already carries one.
+	overLimit := checkDiscountLimit(actor.Role, *req.DiscountAmount, total) != nil
+	if !isLargeDiscount(*req.DiscountAmount, total) && !(overLimit && actor.PIN != "") {
+		return s.orderService.CreateOrder(req)
+	}
+	approval, err := s.authorize(ApprovalActionOrderDiscount, nil, req, actor)
+	if err != nil {
+		return nil, err
+	}
+	req.DiscountApproved = true
+	order, err := s.orderService.CreateOrder(req)
+	var orderID *int64
+	if order != nil {
+		orderID = &order.ID
+	}
+	s.recordResult(approval, orderID, err)
+	return order, err
+}
+
+func (s *approvalService) UpdateOrderStatus(orderID int64, req UpdateOrderStatusRequest, actor ApprovalActor) (*models.Order, error) {
+	if req.Status != StatusRefunded {
+		req.CallerRole = actor.Role
+		req.CallerID = actor.UserID
+		return s.orderService.UpdateOrderStatus(orderID, req)
+	}
+	// The approver decides on the order as it is then, so the version is not kept
+	approval, err := s.authorize(ApprovalActionOrderRefund, &orderID, UpdateOrderStatusRequest{Status: req.Status}, actor)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderService.UpdateOrderStatus(orderID, req)
+	s.recordResult(approval, nil, err)
+	return order, err
+}
+
+func (s *approvalService) DeleteOrder(orderID int64, actor ApprovalActor) error {
+	approval, err := s.authorize(ApprovalActionOrderDelete, &orderID, struct{}{}, actor)
+	if err != nil {
+		return err
+	}
+	err = s.orderService.DeleteOrder(orderID)
+	s.recordResult(approval, nil, err)
+	return err
+}
+
+func (s *approvalService) CreateBooking(req CreateBookingRequest, actor ApprovalActor) (*models.Booking, error) {
+	req.CallerRole = actor.Role
+	req.BlacklistOverrideApproved = false
+	booking, err := s.bookingService.CreateBooking(req, actor.UserID)
+	if !errors.Is(err, ErrClientBlacklisted) || !req.OverrideBlacklist {
+		return booking, err
+	}
+	if !blacklistOverridable(actor.Role) {
+		return nil, fmt.Errorf("%w: %w", ErrBlacklistOverrideNotAllowed, err)
+	}
+	approval, err := s.authorize(ApprovalActionBookingBlacklistOverride, req.ClientID, req, actor)
+	if err != nil {
+		return nil, err
+	}
+	req.BlacklistOverrideApproved = true
+	booking, err = s.bookingService.CreateBooking(req, actor.UserID)
+	s.recordResult(approval, nil, err)
+	return booking, err
+}
+
+// orderTotal prices the items of req. It reports false if an item cannot be
+// priced; CreateOrder then rejects the request.
+func (s *approvalService) orderTotal(req CreateOrderRequest) (models.Money, bool) {
+	var total models.Money
+	for _, item := range req.OrderItems {
+		price, _, _, _, err := s.pricelistRepo.GetItemPriceAndStock(item.PricelistItemID)
+		if err != nil {
+			return models.ZeroMoney, false
+		}
+		total = total.Add(price.MulInt(item.Quantity))
+	}
+	return total, true
+}
+
+// isLargeDiscount reports whether discount is at least LargeDiscountPercent of total.
+func isLargeDiscount(discount, total models.Money) bool {
+	threshold := total.Decimal().Mul(decimal.NewFromInt(LargeDiscountPercent)).Div(decimal.NewFromInt(100))
+	return discount.Decimal().GreaterThanOrEqual(threshold)
+}
+
+// authorize records the approval of a sensitive action. It returns the approved
+// record if actor may perform the action now, or *ApprovalRequiredError after
+// recording a pending approval.
+func (s *approvalService) authorize(action string, targetID *int64, payload interface{}, actor ApprovalActor) (*models.Approval, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode approval payload: %w", err)
+	}
+	approval := &models.Approval{
+		Action:      action,
+		TargetID:    targetID,
+		Payload:     payloadJSON,
+		Status:      ApprovalStatusPending,
+		RequestedBy: &actor.UserID,
+	}
+
+	var approverID int64
+	switch {
+	case strings.EqualFold(actor.Role, approverRole):
+		approverID = actor.UserID
+	case actor.PIN != "":
+		approverID, err = s.checkApprovalPIN(actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if approverID != 0 {
+		now := time.Now().UTC()
+		approval.Status = ApprovalStatusApproved
+		approval.DecidedBy = &approverID
+		approval.DecidedAt = &now
+	}
+
+	created, err := s.approvalRepo.CreateApproval(s.db, approval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	if created.Status == ApprovalStatusPending {
+		return nil, &ApprovalRequiredError{Approval: created}
+	}
+	return created, nil
+}
+
+// checkApprovalPIN returns the ID of the Admin the actor named if the PIN is theirs.
+// Each invalid PIN counts against both the actor and their IP; once either reaches
+// MaxApprovalPINAttempts, PINs are refused with ErrApprovalPINLocked until the
+// lockout expires, so PINs cannot be guessed one request at a time.
+func (s *approvalService) checkApprovalPIN(actor ApprovalActor) (int64, error) {
+	if actor.ApproverID == 0 {
+		return 0, fmt.Errorf("%w: the Admin the PIN belongs to is not given", ErrInvalidApprovalPIN)
+	}
+	ctx := context.Background()
+	keys := approvalPINAttemptKeys(actor)
+	for _, key := range keys {
+		value, found, err := s.store.Get(ctx, key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read approval PIN attempts: %w", err)
+		}
+		if attempts, _ := strconv.Atoi(value); found && attempts >= MaxApprovalPINAttempts {
+			return 0, ErrApprovalPINLocked
+		}
+	}
+
+	pin, err := s.authRepo.GetAdminApprovalPIN(actor.ApproverID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get approval PIN: %w", err)
+	}
+	if pin != nil && bcrypt.CompareHashAndPassword([]byte(pin.PINHash), []byte(actor.PIN)) == nil {
+		if err := s.store.Delete(ctx, keys[0]); err != nil {
+			utils.LogError(err, "checkApprovalPIN: failed to reset approval PIN attempts")
+		}
+		return pin.UserID, nil
+	}
+
+	// An unknown approver counts as a wrong PIN, so it tells nothing about which Admins have one
+	for _, key := range keys {
+		attempts, err := s.store.Incr(ctx, key, ApprovalPINLockout)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count approval PIN attempt: %w", err)
+		}
+		if attempts >= MaxApprovalPINAttempts {
+			// The lockout runs from the attempt that reached the limit
+			if err := s.store.Set(ctx, key, strconv.FormatInt(attempts, 10), ApprovalPINLockout); err != nil {
+				return 0, fmt.Errorf("failed to lock approval PIN attempts: %w", err)
+			}
+		}
+	}
+	return 0, ErrInvalidApprovalPIN
+}
+
+// approvalPINAttemptKeys returns the store keys counting the invalid PINs of the actor,
+// the actor's own first.
+func approvalPINAttemptKeys(actor ApprovalActor) []string {
+	keys := []string{fmt.Sprintf("approvalpin:user:%d", actor.UserID)}
+	if actor.IP != "" {
+		keys = append(keys, "approvalpin:ip:"+actor.IP)
+	}
+	return keys
+}
+
+// recordResult stores the outcome of an approved action. The action already
+// happened (or failed), so a failure to record it is only logged.
+func (s *approvalService) recordResult(approval *models.Approval, targetID *int64, actionErr error) {
+	status := ApprovalStatusApproved
+	var resultError *string
+	if actionErr != nil {
+		status = ApprovalStatusFailed
+		msg := actionErr.Error()
+		resultError = &msg
+	}
+	if err := s.approvalRepo.SetApprovalResult(s.db, approval.ID, status, targetID, resultError); err != nil {
+		utils.LogError(err, fmt.Sprintf("Failed to record result of approval %d", approval.ID))
+	}
+}
+
+func (s *approvalService) GetApprovals(status *string, page, pageSize int) ([]models.Approval, int, error) {
+	approvals, total, err := s.approvalRepo.GetApprovals(status, page, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get approvals: %w", err)
+	}
+	return approvals, total, nil
+}
+
+func (s *approvalService) GetApprovalByID(id int64) (*models.Approval, error) {
+	approval, err := s.approvalRepo.GetApprovalByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval: %w", err)
+	}
+	return approval, nil
+}
+
+func (s *approvalService) Approve(id, adminUserID int64) (*models.Approval, interface{}, error) {
+	approval, err := s.decide(id, ApprovalStatusApproved, adminUserID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, targetID, actionErr := s.execute(approval)
+	s.recordResult(approval, targetID, actionErr)
+	if actionErr != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrApprovalActionFailed, actionErr)
+	}
+	approval, err = s.GetApprovalByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return approval, result, nil
+}
+
+func (s *approvalService) Reject(id, adminUserID int64, reason *string) (*models.Approval, error) {
+	if _, err := s.decide(id, ApprovalStatusRejected, adminUserID, reason); err != nil {
+		return nil, err
+	}
+	return s.GetApprovalByID(id)
+}
+
+// decide moves a pending approval to status; only the first decision succeeds.
+func (s *approvalService) decide(id int64, status string, adminUserID int64, reason *string) (*models.Approval, error) {
+	approval, err := s.GetApprovalByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != ApprovalStatusPending {
+		return nil, ErrApprovalNotPending
+	}
+	err = s.approvalRepo.DecideApproval(s.db, id, status, adminUserID, reason, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrApprovalNotPending
+		}
+		return nil, fmt.Errorf("failed to decide approval: %w", err)
+	}
+	return approval, nil
+}
+
+// execute performs the action of an approved approval and returns its result and,
+// for new orders, the ID of the created order.
+func (s *approvalService) execute(approval *models.Approval) (interface{}, *int64, error) {
+	switch approval.Action {
+	case ApprovalActionOrderDiscount:
+		var req CreateOrderRequest
+		if err := json.Unmarshal(approval.Payload, &req); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode approval payload: %w", err)
+		}
+		req.DiscountApproved = true
+		order, err := s.orderService.CreateOrder(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		return order, &order.ID, nil
+	case ApprovalActionOrderRefund:
+		var req UpdateOrderStatusRequest
+		if err := json.Unmarshal(approval.Payload, &req); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode approval payload: %w", err)
+		}
+		order, err := s.orderService.UpdateOrderStatus(*approval.TargetID, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		return order, nil, nil
+	case ApprovalActionOrderDelete:
+		return nil, nil, s.orderService.DeleteOrder(*approval.TargetID)
+	case ApprovalActionBookingBlacklistOverride:
+		var req CreateBookingRequest
+		if err := json.Unmarshal(approval.Payload, &req); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode approval payload: %w", err)
+		}
+		req.BlacklistOverrideApproved = true
+		booking, err := s.bookingService.CreateBooking(req, *approval.RequestedBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		return booking, nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown approval action %q", approval.Action)
+}
+
+func (s *approvalService) SetApprovalPIN(adminUserID int64, pin string) error {
+	if len(pin) < minApprovalPINLen || len(pin) > maxApprovalPINLen || strings.Trim(pin, "0123456789") != "" {
+		return fmt.Errorf("%w: PIN must be %d to %d digits", ErrValidation, minApprovalPINLen, maxApprovalPINLen)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash approval PIN: %w", err)
+	}
+	if err := s.authRepo.SetApprovalPIN(adminUserID, string(hash)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to set approval PIN: %w", err)
+	}
+	return nil
+}
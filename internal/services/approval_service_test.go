@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/repositories/mocks"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestApprovalService returns an approval service where Admins 1 and 2 have the PINs
+// 111111 and 222222, recording the Admins whose PIN was looked up.
+func newTestApprovalService(t *testing.T) (ApprovalService, *[]int64) {
+	t.Helper()
+	hashes := map[int64]string{}
+	for id, pin := range map[int64]string{1: "111111", 2: "222222"} {
+		hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("hashing PIN: %v", err)
+		}
+		hashes[id] = string(hash)
+	}
+	var looked []int64
+	authRepo := &mocks.MockAuthRepository{
+		GetAdminApprovalPINFunc: func(userID int64) (*models.ApprovalPIN, error) {
+			looked = append(looked, userID)
+			hash, ok := hashes[userID]
+			if !ok {
+				return nil, repositories.ErrNotFound
+			}
+			return &models.ApprovalPIN{UserID: userID, PINHash: hash}, nil
+		},
+	}
+	approvalRepo := &mocks.MockApprovalRepository{
+		CreateApprovalFunc: func(_ repositories.SQLExecutor, approval *models.Approval) (*models.Approval, error) {
+			created := *approval
+			created.ID = 1
+			return &created, nil
+		},
+	}
+	svc := NewApprovalService(approvalRepo, authRepo, &mocks.MockPricelistRepository{}, nil, nil, kvstore.NewMemoryStore(), nil)
+	return svc, &looked
+}
+
+// staffActor is staff member 7 entering pin for approver from ip.
+func staffActor(pin string, approver int64, ip string) ApprovalActor {
+	return ApprovalActor{UserID: 7, Role: "Staff", PIN: pin, ApproverID: approver, IP: ip}
+}
+
+func TestApprovalServiceAuthorizeWithPIN(t *testing.T) {
+	tests := []struct {
+		name         string
+		actor        ApprovalActor
+		wantApprover int64
+		wantErr      error
+		wantLooked   []int64
+	}{
+		{name: "approves with the PIN of the named Admin", actor: staffActor("222222", 2, "10.0.0.1"), wantApprover: 2, wantLooked: []int64{2}},
+		{name: "refuses the PIN of another Admin", actor: staffActor("111111", 2, "10.0.0.1"), wantErr: ErrInvalidApprovalPIN, wantLooked: []int64{2}},
+		{name: "refuses a PIN without an approver", actor: staffActor("111111", 0, "10.0.0.1"), wantErr: ErrInvalidApprovalPIN},
+		{name: "refuses an approver without a PIN", actor: staffActor("111111", 3, "10.0.0.1"), wantErr: ErrInvalidApprovalPIN, wantLooked: []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, looked := newTestApprovalService(t)
+			approval, err := svc.(*approvalService).authorize(ApprovalActionOrderDelete, nil, struct{}{}, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("authorize error = %v, want %v", err, tt.wantErr)
+			}
+			if len(*looked) != len(tt.wantLooked) || (len(tt.wantLooked) > 0 && (*looked)[0] != tt.wantLooked[0]) {
+				t.Errorf("looked up the PINs of %v, want %v", *looked, tt.wantLooked)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if approval.Status != ApprovalStatusApproved || approval.DecidedBy == nil || *approval.DecidedBy != tt.wantApprover {
+				t.Errorf("approval = %s decided by %v, want approved by %d", approval.Status, approval.DecidedBy, tt.wantApprover)
+			}
+		})
+	}
+}
+
+func TestApprovalServicePINLockout(t *testing.T) {
+	svc, _ := newTestApprovalService(t)
+	authorize := func(actor ApprovalActor) error {
+		_, err := svc.(*approvalService).authorize(ApprovalActionOrderDelete, nil, struct{}{}, actor)
+		return err
+	}
+
+	for i := 0; i < MaxApprovalPINAttempts; i++ {
+		if err := authorize(staffActor("000000", 1, "10.0.0.1")); !errors.Is(err, ErrInvalidApprovalPIN) {
+			t.Fatalf("attempt %d error = %v, want %v", i+1, err, ErrInvalidApprovalPIN)
+		}
+	}
+	// Locked out, even with the right PIN
+	if err := authorize(staffActor("111111", 1, "10.0.0.1")); !errors.Is(err, ErrApprovalPINLocked) {
+		t.Errorf("user over the limit error = %v, want %v", err, ErrApprovalPINLocked)
+	}
+	if err := authorize(staffActor("111111", 1, "10.0.0.2")); !errors.Is(err, ErrApprovalPINLocked) {
+		t.Errorf("user over the limit from another IP error = %v, want %v", err, ErrApprovalPINLocked)
+	}
+	other := staffActor("111111", 1, "10.0.0.1")
+	other.UserID = 8
+	if err := authorize(other); !errors.Is(err, ErrApprovalPINLocked) {
+		t.Errorf("IP over the limit error = %v, want %v", err, ErrApprovalPINLocked)
+	}
+	other.IP = "10.0.0.2"
+	if err := authorize(other); err != nil {
+		t.Errorf("another user from another IP: %v", err)
+	}
+}
+
+func TestApprovalServicePINResetsAfterSuccess(t *testing.T) {
+	svc, _ := newTestApprovalService(t)
+	authorize := func(pin, ip string) error {
+		_, err := svc.(*approvalService).authorize(ApprovalActionOrderDelete, nil, struct{}{}, staffActor(pin, 1, ip))
+		return err
+	}
+
+	// Each round comes from a new IP, so only the user's own count could lock them out
+	ips := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	for _, ip := range ips {
+		for i := 0; i < MaxApprovalPINAttempts-1; i++ {
+			if err := authorize("000000", ip); !errors.Is(err, ErrInvalidApprovalPIN) {
+				t.Fatalf("wrong PIN error = %v, want %v", err, ErrInvalidApprovalPIN)
+			}
+		}
+		if err := authorize("111111", ip); err != nil {
+			t.Fatalf("right PIN after %d wrong ones: %v", MaxApprovalPINAttempts-1, err)
+		}
+	}
+}
+
+func TestApprovalServiceSetApprovalPIN(t *testing.T) {
+	tests := []struct {
+		pin     string
+		wantErr error
+	}{
+		{pin: "1234", wantErr: ErrValidation},
+		{pin: "12345", wantErr: ErrValidation},
+		{pin: "123456"},
+		{pin: "12345678"},
+		{pin: "123456789", wantErr: ErrValidation},
+		{pin: "12345a", wantErr: ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pin, func(t *testing.T) {
+			var stored string
+			authRepo := &mocks.MockAuthRepository{
+				SetApprovalPINFunc: func(_ int64, hash string) error {
+					stored = hash
+					return nil
+				},
+			}
+			svc := NewApprovalService(&mocks.MockApprovalRepository{}, authRepo, &mocks.MockPricelistRepository{}, nil, nil, kvstore.NewMemoryStore(), nil)
+			err := svc.SetApprovalPIN(1, tt.pin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetApprovalPIN error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && bcrypt.CompareHashAndPassword([]byte(stored), []byte(tt.pin)) != nil {
+				t.Errorf("stored hash does not match the PIN")
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrAttendanceValidation = apperrors.New(utils.ErrCodeValidationFailed, "attendance validation error")
+
+var (
+	attendanceSettings   = models.DefaultAttendanceSettings()
+	attendanceSettingsMu sync.RWMutex
+)
+
+// SetAttendanceSettings sets how attendance is checked and penalized (the attendance setting).
+func SetAttendanceSettings(settings models.AttendanceSettings) {
+	attendanceSettingsMu.Lock()
+	defer attendanceSettingsMu.Unlock()
+	attendanceSettings = settings
+}
+
+// CurrentAttendanceSettings returns how attendance is checked and penalized.
+func CurrentAttendanceSettings() models.AttendanceSettings {
+	attendanceSettingsMu.RLock()
+	defer attendanceSettingsMu.RUnlock()
+	return attendanceSettings
+}
+
+// --- AttendanceService Interface ---
+type AttendanceService interface {
+	// GetReport compares the shifts starting in a month (YYYY-MM, club time; empty for the
+	// current month) with the time clock, of staffID if set, and returns the late arrivals,
+	// early departures and missed shifts with the monthly totals of each staff member.
+	GetReport(month string, staffID *int64) (*models.AttendanceReport, error)
+}
+
+type attendanceService struct {
+	attendanceRepo repositories.AttendanceRepository
+}
+
+// NewAttendanceService creates a new AttendanceService.
+func NewAttendanceService(attendanceRepo repositories.AttendanceRepository) AttendanceService {
+	return &attendanceService{attendanceRepo: attendanceRepo}
+}
+
+func (s *attendanceService) GetReport(month string, staffID *int64) (*models.AttendanceReport, error) {
+	if strings.TrimSpace(month) == "" {
+		month = utils.FormatClubTime(utils.NowUTC(), utils.MonthLayout)
+	}
+	from, to, err := utils.ParseClubMonth(month)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid month '%s', expected YYYY-MM", ErrAttendanceValidation, month)
+	}
+	shifts, err := s.attendanceRepo.GetShifts(from, to, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shifts for attendance: %w", err)
+	}
+	// The entries of the last shifts may run past the end of the month
+	entriesTo := to
+	for _, shift := range shifts {
+		if shift.EndTime.After(entriesTo) {
+			entriesTo = shift.EndTime
+		}
+	}
+	entries, err := s.attendanceRepo.GetTimeClockEntries(from, entriesTo, staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time clock entries for attendance: %w", err)
+	}
+
+	settings := CurrentAttendanceSettings()
+	report := &models.AttendanceReport{Month: strings.TrimSpace(month), GraceMinutes: settings.GraceMinutes}
+	report.Staff, report.Anomalies = checkAttendance(shifts, entries, settings, utils.NowUTC())
+	for _, summary := range report.Staff {
+		report.TotalPenalties = report.TotalPenalties.Add(summary.Penalties)
+	}
+	return report, nil
+}
+
+// checkAttendance compares the shifts started by now with the time clock entries of their staff
+// members. A shift is attended by the entries overlapping it: the first clock-in is checked
+// against its start and, once every entry is closed, the last clock-out against its end. A shift
+// that ended without any entry was missed, unless approved time off excused it.
+func checkAttendance(shifts []models.AttendanceShift, entries []models.TimeClockEntry,
+	settings models.AttendanceSettings, now time.Time) ([]models.AttendanceSummary, []models.AttendanceAnomaly) {
+	entriesByStaff := make(map[int64][]models.TimeClockEntry)
+	for _, entry := range entries {
+		entriesByStaff[entry.StaffID] = append(entriesByStaff[entry.StaffID], entry)
+	}
+	summaries := make(map[int64]*models.AttendanceSummary)
+	anomalies := []models.AttendanceAnomaly{}
+	grace := settings.Grace()
+
+	for _, shift := range shifts {
+		if shift.StartTime.After(now) {
+			continue
+		}
+		summary, ok := summaries[shift.StaffID]
+		if !ok {
+			summary = &models.AttendanceSummary{StaffID: shift.StaffID, StaffName: shift.StaffName}
+			summaries[shift.StaffID] = summary
+		}
+		summary.ScheduledShifts++
+		if shift.OnTimeOff {
+			summary.ExcusedShifts++
+			continue
+		}
+
+		var clockIn, clockOut *time.Time
+		open := false
+		for _, entry := range entriesByStaff[shift.StaffID] {
+			if !entry.ClockInAt.Before(shift.EndTime) || (entry.ClockOutAt != nil && !entry.ClockOutAt.After(shift.StartTime)) {
+				continue
+			}
+			if clockIn == nil || entry.ClockInAt.Before(*clockIn) {
+				clockIn = &entry.ClockInAt
+			}
+			if entry.ClockOutAt == nil {
+				open = true
+			} else if clockOut == nil || entry.ClockOutAt.After(*clockOut) {
+				clockOut = entry.ClockOutAt
+			}
+		}
+		if open {
+			clockOut = nil
+		}
+
+		anomaly := models.AttendanceAnomaly{StaffID: shift.StaffID, StaffName: shift.StaffName, ShiftID: shift.ID,
+			ShiftStart: shift.StartTime, ShiftEnd: shift.EndTime, ClockInAt: clockIn, ClockOutAt: clockOut}
+		var found []models.AttendanceAnomaly
+		switch {
+		case clockIn == nil:
+			if shift.EndTime.After(now) {
+				continue // The staff member may still clock in
+			}
+			anomaly.Type = models.AttendanceMissedShift
+			anomaly.Minutes = int(shift.EndTime.Sub(shift.StartTime).Minutes())
+			summary.MissedShifts++
+			found = append(found, anomaly)
+		default:
+			if late := clockIn.Sub(shift.StartTime); late > grace {
+				anomaly.Type = models.AttendanceLateArrival
+				anomaly.Minutes = int(late.Minutes())
+				summary.LateArrivals++
+				summary.LateMinutes += anomaly.Minutes
+				found = append(found, anomaly)
+			}
+			if clockOut != nil {
+				if early := shift.EndTime.Sub(*clockOut); early > grace {
+					anomaly.Type = models.AttendanceEarlyDeparture
+					anomaly.Minutes = int(early.Minutes())
+					summary.EarlyDepartures++
+					summary.EarlyMinutes += anomaly.Minutes
+					found = append(found, anomaly)
+				}
+			}
+		}
+		for _, a := range found {
+			a.Penalty = settings.PenaltyFor(a.Type)
+			summary.Penalties = summary.Penalties.Add(a.Penalty)
+			anomalies = append(anomalies, a)
+		}
+	}
+
+	staff := make([]models.AttendanceSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		staff = append(staff, *summary)
+	}
+	sort.Slice(staff, func(i, j int) bool {
+		if staff[i].StaffName != staff[j].StaffName {
+			return staff[i].StaffName < staff[j].StaffName
+		}
+		return staff[i].StaffID < staff[j].StaffID
+	})
+	return staff, anomalies
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+)
+
+// --- AuditLogService Interface ---
+type AuditLogService interface {
+	// RecordEntry adds an entry to the audit log, e.g. a request seen by middleware.AuditLog.
+	RecordEntry(entry *models.AuditLogEntry) error
+	GetEntries(filters models.AuditLogFilters) ([]models.AuditLogEntry, int, error)
+}
+
+type auditLogService struct {
+	auditLogRepo repositories.AuditLogRepository
+	db           *sql.DB
+}
+
+// NewAuditLogService creates a new AuditLogService.
+func NewAuditLogService(auditLogRepo repositories.AuditLogRepository, db *sql.DB) AuditLogService {
+	return &auditLogService{auditLogRepo: auditLogRepo, db: db}
+}
+
+func (s *auditLogService) RecordEntry(entry *models.AuditLogEntry) error {
+	if err := s.auditLogRepo.CreateEntry(s.db, entry); err != nil {
+		return fmt.Errorf("failed to record audit log entry: %w", err)
+	}
+	return nil
+}
+
+func (s *auditLogService) GetEntries(filters models.AuditLogFilters) ([]models.AuditLogEntry, int, error) {
+	entries, total, err := s.auditLogRepo.GetEntries(filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit log entries: %w", err)
+	}
+	return entries, total, nil
+}
@@ -1,15 +1,19 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"ps_club_backend/internal/kvstore"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
-	// "ps_club_backend/pkg/utils" // Not using separate JWT utils for now
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -17,20 +21,39 @@ import (
 
 // --- Custom Service Errors ---
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrInvalidCredentials = errors.New("invalid username or password")
-	ErrUsernameExists     = errors.New("username already exists")
-	ErrEmailExists        = errors.New("email already exists")
-	ErrRoleNotFound       = errors.New("specified role not found")
-	ErrTokenGeneration    = errors.New("failed to generate token")
+	ErrUserNotFound        = apperrors.New(utils.ErrCodeNotFound, "user not found")
+	ErrInvalidCredentials  = apperrors.New(utils.ErrCodeUnauthorized, "invalid username or password")
+	ErrUsernameExists      = apperrors.New(utils.ErrCodeConflict, "username already exists")
+	ErrEmailExists         = apperrors.New(utils.ErrCodeConflict, "email already exists")
+	ErrRoleNotFound        = apperrors.New(utils.ErrCodeBadRequest, "specified role not found")
+	ErrTokenGeneration     = errors.New("failed to generate token")
+	ErrInvalidRefreshToken = apperrors.New(utils.ErrCodeUnauthorized, "invalid, expired or revoked refresh token")
+	ErrSessionNotFound     = apperrors.New(utils.ErrCodeNotFound, "session not found")
+	ErrUnsupportedLanguage = apperrors.New(utils.ErrCodeValidationFailed, "unsupported language")
+	ErrCannotImpersonate   = apperrors.New(utils.ErrCodeForbidden, "only active Staff and Analyst users other than yourself can be impersonated")
+	ErrProfileValidation   = apperrors.New(utils.ErrCodeValidationFailed, "profile validation error")
+	ErrWrongPassword       = apperrors.New(utils.ErrCodeForbidden, "current password is incorrect")
+	ErrAvatarNotFound      = apperrors.New(utils.ErrCodeNotFound, "the user has no avatar")
+	ErrRegistrationClosed  = apperrors.New(utils.ErrCodeForbidden, "open registration is disabled, staff join by invitation")
 )
 
+// ImpersonationTokenTTL is the lifetime of impersonation tokens. They cannot be refreshed.
+var ImpersonationTokenTTL = 15 * time.Minute
+
+// impersonatableRoles are the roles an Admin may act as.
+var impersonatableRoles = []string{"Staff", "Analyst"}
+
 // --- Data Transfer Objects (DTOs) ---
 
 // LoginRequest DTO
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username   string `json:"username" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name"` // Shown in the session list, e.g. "Bar tablet"
+
+	// Set by the handler from the request
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // RegisterUserRequest DTO
@@ -42,6 +65,45 @@ type RegisterUserRequest struct {
 	RoleName string `json:"role_name"` // e.g., "Client", "Staff". Default if empty.
 }
 
+// RefreshTokenRequest DTO, used to refresh and to revoke (log out) a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+
+	IPAddress string `json:"-"` // Set by the handler from the request
+}
+
+// UpdateProfileRequest DTO, for users to change their own profile. Nil fields stay unchanged.
+// Changing the password requires the current one.
+type UpdateProfileRequest struct {
+	FullName        *string  `json:"full_name" binding:"omitempty,max=255"`
+	Email           *string  `json:"email" binding:"omitempty,email,max=255"`
+	PhoneNumber     *string  `json:"phone_number" binding:"omitempty,phone"`
+	NewPassword     *string  `json:"new_password" binding:"omitempty,min=8,max=72"`
+	CurrentPassword string   `json:"current_password"`
+	Clear           []string `json:"-"` // Fields to set to null, from a merge patch; see ProfileClearableFields
+}
+
+// ProfileClearableFields are the fields of UpdateProfileRequest a merge patch may set to null.
+var ProfileClearableFields = []string{"full_name", "email", "phone_number"}
+
+// ImpersonateRequest DTO, for an Admin to act as another user while troubleshooting their view
+type ImpersonateRequest struct {
+	UserID int64  `json:"user_id" binding:"required"`
+	Reason string `json:"reason" binding:"required,max=1000"` // Recorded in the audit log
+
+	// Set by the handler from the request
+	AdminID   int64  `json:"-"`
+	SessionID int64  `json:"-"` // The Admin's session; revoking it ends the impersonation
+	IPAddress string `json:"-"`
+}
+
+// ImpersonationResponse DTO
+type ImpersonationResponse struct {
+	User        *models.User `json:"user"`
+	AccessToken string       `json:"access_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
 // AuthResponse DTO
 type AuthResponse struct {
 	User         *models.User `json:"user"`
@@ -54,28 +116,67 @@ type AuthService interface {
 	RegisterUser(req RegisterUserRequest) (*models.User, error)
 	LoginUser(req LoginRequest) (*AuthResponse, error)
 	GetUserProfile(userID int64) (*models.User, error)
+	RefreshAccessToken(req RefreshTokenRequest) (*AuthResponse, error)
+	RevokeRefreshToken(userID int64, refreshToken string) error
+	// GetSessions lists the active sessions of a user; currentSessionID is marked as current.
+	GetSessions(userID, currentSessionID int64) ([]models.UserSession, error)
+	// RevokeSession ends a session of the user: its refresh token stops working and
+	// its access tokens are rejected.
+	RevokeSession(userID, sessionID int64) error
+	// UpdatePreferredLanguage sets the language of the user's API messages; an empty
+	// language clears it. Tokens carry the language from the next login or refresh.
+	UpdatePreferredLanguage(userID int64, language string) (*models.User, error)
+	// Impersonate issues an Admin a short-lived access token acting as another user, without
+	// a refresh token, and records it in the audit log. The token carries the Admin in its
+	// impersonation claim, so every request made with it is audited as theirs.
+	Impersonate(req ImpersonateRequest) (*ImpersonationResponse, error)
+	// UpdateProfile changes the user's own profile. A new password logs out every other
+	// session of the user; currentSessionID stays logged in.
+	UpdateProfile(userID, currentSessionID int64, req UpdateProfileRequest) (*models.User, error)
+	// SetAvatar replaces the user's avatar with a JPEG, PNG or WebP image.
+	SetAvatar(userID int64, data []byte) error
+	GetAvatar(userID int64) (*models.Photo, error)
+	DeleteAvatar(userID int64) error
 }
 
 // --- authService Implementation ---
 type authService struct {
 	authRepo      repositories.AuthRepository
+	sessionRepo   repositories.SessionRepository
 	db            *sql.DB // Used as SQLExecutor for single repo calls, or for managing transactions
 	jwtSecret     string
 	jwtExpiration time.Duration
+	store         kvstore.Store // Revoked refresh tokens, shared by all instances
+	auditLogRepo  repositories.AuditLogRepository
+	settingRepo   repositories.SettingRepository // open_registration
 }
 
 // NewAuthService creates a new instance of AuthService.
-func NewAuthService(authRepo repositories.AuthRepository, db *sql.DB, jwtSecret string, jwtExp time.Duration) AuthService {
+func NewAuthService(authRepo repositories.AuthRepository, sessionRepo repositories.SessionRepository, db *sql.DB, jwtSecret string, jwtExp time.Duration, store kvstore.Store, auditLogRepo repositories.AuditLogRepository, settingRepo repositories.SettingRepository) AuthService {
 	return &authService{
 		authRepo:      authRepo,
+		sessionRepo:   sessionRepo,
 		db:            db,
 		jwtSecret:     jwtSecret,
 		jwtExpiration: jwtExp,
+		store:         store,
+		auditLogRepo:  auditLogRepo,
+		settingRepo:   settingRepo,
 	}
 }
 
-// generateJWT creates a new JWT token for a given user.
-func (s *authService) generateJWT(user *models.User) (string, error) {
+// revokedRefreshTokenKey is the store key marking a refresh token as revoked.
+func revokedRefreshTokenKey(tokenID string) string {
+	return "auth:revoked_refresh:" + tokenID
+}
+
+// generateJWT creates a new JWT token for a given user and session.
+func (s *authService) generateJWT(user *models.User, sessionID int64) (string, error) {
+	return s.signJWT(accessTokenClaims(user, sessionID, time.Now().Add(s.jwtExpiration)))
+}
+
+// accessTokenClaims returns the claims of an access token of user in a session.
+func accessTokenClaims(user *models.User, sessionID int64, expiresAt time.Time) jwt.MapClaims {
 	roleName := "default" // Default role claim
 	if user.Role != nil && user.Role.Name != "" {
 		roleName = user.Role.Name
@@ -93,9 +194,18 @@ func (s *authService) generateJWT(user *models.User) (string, error) {
 		"user_id":  user.ID,
 		"username": user.Username,
 		"role":     roleName,
-		"exp":      time.Now().Add(s.jwtExpiration).Unix(),
+		"sid":      sessionID,
+		"exp":      expiresAt.Unix(),
 		"iat":      time.Now().Unix(),
 	}
+	if user.PreferredLanguage != nil {
+		claims["lang"] = *user.PreferredLanguage
+	}
+	return claims
+}
+
+// signJWT signs access token claims with the JWT secret.
+func (s *authService) signJWT(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString([]byte(s.jwtSecret))
 	if err != nil {
@@ -104,8 +214,49 @@ func (s *authService) generateJWT(user *models.User) (string, error) {
 	return signedToken, nil
 }
 
+// userDuplicateError returns the domain error of a taken username or email, by the
+// violated unique constraint.
+func userDuplicateError(err error) error {
+	if repositories.ViolatedConstraint(err) == "users_email_key" {
+		return ErrEmailExists
+	}
+	return ErrUsernameExists
+}
+
+// roleIDs maps the lower-cased names of the built-in roles to their IDs.
+// This mapping should ideally come from a configuration or database lookup
+var roleIDs = map[string]int64{
+	"admin":   1, // Assuming 1 is Admin ID
+	"staff":   2, // Assuming 2 is Staff ID
+	"client":  3, // Assuming 3 is Client ID
+	"analyst": 4, // Read-only reporting role, see migration 0035
+}
+
+// registrationOpen reports whether the open_registration setting lets anyone register.
+func (s *authService) registrationOpen() (bool, error) {
+	setting, err := s.settingRepo.GetSettingByKey(models.SettingKeyOpenRegistration)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if setting.SettingValue == nil {
+		return false, nil
+	}
+	return models.ParseOpenRegistration(*setting.SettingValue)
+}
+
 // RegisterUser handles the business logic for user registration.
 func (s *authService) RegisterUser(req RegisterUserRequest) (*models.User, error) {
+	open, err := s.registrationOpen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open registration: %w", err)
+	}
+	if !open {
+		return nil, ErrRegistrationClosed
+	}
+
 	hashedPasswordBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
@@ -117,14 +268,8 @@ func (s *authService) RegisterUser(req RegisterUserRequest) (*models.User, error
 	// In a real app, this would involve a RoleRepository or a more robust lookup.
 	if req.RoleName != "" {
 		var tempRoleID int64
-		// This mapping should ideally come from a configuration or database lookup
-		roleMap := map[string]int64{
-			"admin":  1, // Assuming 1 is Admin ID
-			"staff":  2, // Assuming 2 is Staff ID
-			"client": 3, // Assuming 3 is Client ID
-		}
 		normalizedRoleName := strings.ToLower(req.RoleName)
-		if id, ok := roleMap[normalizedRoleName]; ok {
+		if id, ok := roleIDs[normalizedRoleName]; ok {
 			tempRoleID = id
 		} else {
 			return nil, fmt.Errorf("%w: '%s'", ErrRoleNotFound, req.RoleName)
@@ -146,15 +291,7 @@ func (s *authService) RegisterUser(req RegisterUserRequest) (*models.User, error
 	createdUserID, err := s.authRepo.CreateUser(s.db, &user, hashedPassword)
 	if err != nil {
 		if errors.Is(err, repositories.ErrDuplicateKey) {
-			// The repository error now includes constraint name, e.g.,
-			// "duplicate key value violates unique constraint \"users_username_key\" (constraint: users_username_key)"
-			if strings.Contains(err.Error(), "users_username_key") {
-				return nil, ErrUsernameExists
-			} else if strings.Contains(err.Error(), "users_email_key") { // Assuming 'users_email_key' is the constraint name for email
-				return nil, ErrEmailExists
-			}
-			// Fallback if constraint name isn't as expected or not parsed
-			return nil, fmt.Errorf("%w: %s", ErrUsernameExists, "username or email already taken")
+			return nil, userDuplicateError(err)
 		}
 		return nil, fmt.Errorf("failed to register user: %w", err)
 	}
@@ -192,7 +329,18 @@ func (s *authService) LoginUser(req LoginRequest) (*AuthResponse, error) {
 		return nil, ErrInvalidCredentials
 	}
 
-	accessToken, err := s.generateJWT(user)
+	session := &models.UserSession{
+		UserID:     user.ID,
+		DeviceName: optionalString(req.DeviceName),
+		IPAddress:  optionalString(req.IPAddress),
+		UserAgent:  optionalString(req.UserAgent),
+	}
+	refreshToken, session, err := s.startSession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.generateJWT(user, session.ID)
 	if err != nil {
 		// Log the internal error for diagnosis
 		// log.Printf("ERROR: Failed to generate JWT for user %s: %v", user.Username, err)
@@ -201,12 +349,151 @@ func (s *authService) LoginUser(req LoginRequest) (*AuthResponse, error) {
 
 	user.PasswordHash = "" // Clear password hash before returning user details
 	return &AuthResponse{
-		User:        user,
-		AccessToken: accessToken,
-		// RefreshToken: "...", // Implement refresh token generation if needed
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
+// startSession records a new session and issues its first refresh token.
+func (s *authService) startSession(session *models.UserSession) (string, *models.UserSession, error) {
+	tokenID, err := utils.NewRefreshTokenID()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+	}
+	session.RefreshTokenID = tokenID
+	session.ExpiresAt = time.Now().UTC().Add(utils.RefreshTokenTTL)
+	created, err := s.sessionRepo.CreateSession(s.db, session)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	refreshToken, err := utils.GenerateRefreshToken(session.UserID, created.ID, tokenID)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+	}
+	return refreshToken, created, nil
+}
+
+// optionalString returns nil for an empty string.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token and a new
+// refresh token. The old refresh token is revoked, so each can be used only once,
+// even when two instances receive it concurrently.
+func (s *authService) RefreshAccessToken(req RefreshTokenRequest) (*AuthResponse, error) {
+	claims, err := utils.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	firstUse, err := s.store.SetNX(context.Background(), revokedRefreshTokenKey(claims.ID), "1", time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if !firstUse {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.authRepo.FindUserByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to load user for refresh: %w", err)
+	}
+	if !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var newRefreshToken string
+	sessionID := claims.SessionID
+	if sessionID == 0 {
+		// Tokens issued before sessions were tracked start a session on their first refresh
+		var session *models.UserSession
+		newRefreshToken, session, err = s.startSession(&models.UserSession{UserID: user.ID, IPAddress: optionalString(req.IPAddress)})
+		if err != nil {
+			return nil, err
+		}
+		sessionID = session.ID
+	} else {
+		newTokenID, err := utils.NewRefreshTokenID()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		}
+		now := time.Now().UTC()
+		err = s.sessionRepo.RotateSessionToken(s.db, sessionID, claims.ID, newTokenID, optionalString(req.IPAddress), now, now.Add(utils.RefreshTokenTTL))
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, ErrInvalidRefreshToken // The session was revoked
+			}
+			return nil, fmt.Errorf("failed to update session: %w", err)
+		}
+		newRefreshToken, err = utils.GenerateRefreshToken(user.ID, sessionID, newTokenID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		}
+	}
+
+	accessToken, err := s.generateJWT(user, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	user.PasswordHash = ""
+	return &AuthResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
+}
+
+// RevokeRefreshToken revokes a refresh token of the given user, e.g. on logout.
+func (s *authService) RevokeRefreshToken(userID int64, refreshToken string) error {
+	claims, err := utils.ValidateRefreshToken(refreshToken)
+	if err != nil || claims.UserID != userID {
+		return ErrInvalidRefreshToken
+	}
+	if err := s.store.Set(context.Background(), revokedRefreshTokenKey(claims.ID), "1", time.Until(claims.ExpiresAt.Time)); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if claims.SessionID != 0 {
+		if err := s.RevokeSession(userID, claims.SessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *authService) GetSessions(userID, currentSessionID int64) ([]models.UserSession, error) {
+	sessions, err := s.sessionRepo.GetActiveSessionsByUserID(userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *authService) RevokeSession(userID, sessionID int64) error {
+	err := s.sessionRepo.RevokeSession(s.db, sessionID, userID, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	// Access tokens are checked against the store until the last one issued for the session expires
+	if err := s.store.Set(context.Background(), utils.RevokedSessionKey(sessionID), "1", s.jwtExpiration); err != nil {
+		return fmt.Errorf("failed to revoke access tokens of session: %w", err)
+	}
+	return nil
+}
+
 // GetUserProfile retrieves a user's profile by their ID.
 func (s *authService) GetUserProfile(userID int64) (*models.User, error) {
 	user, err := s.authRepo.FindUserByID(userID)
@@ -219,3 +506,183 @@ func (s *authService) GetUserProfile(userID int64) (*models.User, error) {
 	user.PasswordHash = "" // Ensure password hash is not exposed
 	return user, nil
 }
+
+// UpdatePreferredLanguage sets or clears the user's preferred language of API messages.
+func (s *authService) UpdatePreferredLanguage(userID int64, language string) (*models.User, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	var preferred *string
+	if language != "" {
+		if !utils.IsSupportedLanguage(language) {
+			return nil, fmt.Errorf("%w %q, expected one of: %s", ErrUnsupportedLanguage, language, strings.Join(utils.SupportedLanguages, ", "))
+		}
+		preferred = &language
+	}
+	if err := s.authRepo.SetPreferredLanguage(userID, preferred); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update preferred language: %w", err)
+	}
+	return s.GetUserProfile(userID)
+}
+
+func (s *authService) Impersonate(req ImpersonateRequest) (*ImpersonationResponse, error) {
+	admin, err := s.authRepo.FindUserByID(req.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the impersonating user: %w", err)
+	}
+	user, err := s.authRepo.FindUserByID(req.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user to impersonate: %w", err)
+	}
+	if user.ID == admin.ID || !user.IsActive || user.Role == nil || !slices.ContainsFunc(impersonatableRoles, func(role string) bool {
+		return strings.EqualFold(role, user.Role.Name)
+	}) {
+		return nil, ErrCannotImpersonate
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ImpersonationTokenTTL)
+	claims := accessTokenClaims(user, req.SessionID, expiresAt)
+	claims["imp"] = admin.ID
+	claims["imp_name"] = admin.Username
+	accessToken, err := s.signJWT(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// No token is handed out unless it is on record
+	reason := strings.TrimSpace(req.Reason)
+	entry := &models.AuditLogEntry{
+		UserID:         &user.ID,
+		ImpersonatorID: &admin.ID,
+		Action:         models.AuditActionImpersonationStart,
+		IPAddress:      utils.NewNullString(req.IPAddress),
+		Details:        &reason,
+		CreatedAt:      now,
+	}
+	if err := s.auditLogRepo.CreateEntry(s.db, entry); err != nil {
+		return nil, fmt.Errorf("failed to record the impersonation: %w", err)
+	}
+	user.PasswordHash = ""
+	return &ImpersonationResponse{User: user, AccessToken: accessToken, ExpiresAt: expiresAt}, nil
+}
+
+func (s *authService) UpdateProfile(userID, currentSessionID int64, req UpdateProfileRequest) (*models.User, error) {
+	if err := checkClearable(req.Clear, ProfileClearableFields, ErrProfileValidation); err != nil {
+		return nil, err
+	}
+	user, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve user profile: %w", err)
+	}
+
+	profile := models.UserProfile{FullName: user.FullName, Email: user.Email, PhoneNumber: user.PhoneNumber}
+	if req.FullName != nil {
+		fullName := strings.TrimSpace(*req.FullName)
+		if fullName == "" {
+			return nil, fmt.Errorf("%w: full_name cannot be empty", ErrProfileValidation)
+		}
+		profile.FullName = &fullName
+	}
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		profile.Email = &email
+	}
+	if req.PhoneNumber != nil {
+		phone := strings.TrimSpace(*req.PhoneNumber)
+		profile.PhoneNumber = &phone
+	}
+	if clears(req.Clear, "full_name") {
+		profile.FullName = nil
+	}
+	if clears(req.Clear, "email") {
+		profile.Email = nil
+	}
+	if clears(req.Clear, "phone_number") {
+		profile.PhoneNumber = nil
+	}
+	if req.NewPassword != nil {
+		_, storedHash, err := s.authRepo.FindUserByUsername(user.Username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify current password: %w", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(req.CurrentPassword)) != nil {
+			return nil, ErrWrongPassword
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(*req.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		passwordHash := string(hash)
+		profile.PasswordHash = &passwordHash
+	}
+
+	if err := s.authRepo.UpdateProfile(userID, profile); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrUserNotFound
+		case errors.Is(err, repositories.ErrDuplicateKey):
+			return nil, userDuplicateError(err)
+		}
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if profile.PasswordHash != nil {
+		// Devices that knew the old password must log in again
+		sessions, err := s.sessionRepo.GetActiveSessionsByUserID(userID, time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sessions to revoke: %w", err)
+		}
+		for _, session := range sessions {
+			if session.ID == currentSessionID {
+				continue
+			}
+			if err := s.RevokeSession(userID, session.ID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+				return nil, err
+			}
+		}
+	}
+	return s.GetUserProfile(userID)
+}
+
+func (s *authService) SetAvatar(userID int64, data []byte) error {
+	contentType, err := checkPhoto(data, ErrProfileValidation)
+	if err != nil {
+		return err
+	}
+	if err := s.authRepo.SetAvatar(userID, &models.Photo{ContentType: contentType, Data: data}); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to save avatar: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) GetAvatar(userID int64) (*models.Photo, error) {
+	avatar, err := s.authRepo.GetAvatar(userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrAvatarNotFound
+		}
+		return nil, fmt.Errorf("failed to get avatar: %w", err)
+	}
+	return avatar, nil
+}
+
+func (s *authService) DeleteAvatar(userID int64) error {
+	if err := s.authRepo.SetAvatar(userID, nil); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	return nil
+}
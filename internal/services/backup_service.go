@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrBackupNotFound               = apperrors.New(utils.ErrCodeNotFound, "backup not found")
+	ErrBackupInProgress             = apperrors.New(utils.ErrCodeConflict, "a backup is already running")
+	ErrBackupDestinationUnavailable = apperrors.New(utils.ErrCodeBadRequest, "backup destination is not configured")
+)
+
+// Backup statuses and triggers.
+const (
+	BackupStatusRunning   = "running"
+	BackupStatusCompleted = "completed"
+	BackupStatusFailed    = "failed"
+
+	BackupTriggerManual    = "manual"
+	BackupTriggerScheduled = "scheduled"
+)
+
+// Page sizes of GET /admin/backups.
+const (
+	DefaultBackupListLimit = 20
+	MaxBackupListLimit     = 100
+)
+
+const (
+	backupTimeout        = 2 * time.Hour
+	backupLockKey        = "backup:running"
+	backupScheduleWindow = time.Hour // A nightly backup missed by more than this (e.g. server down) is skipped until the next night
+)
+
+// BackupScheduleCheckInterval is how often RunSchedule checks whether the nightly backup is due.
+var BackupScheduleCheckInterval = time.Minute
+
+// BackupRunner dumps the database and stores the dump; backup.Runner implements it.
+type BackupRunner interface {
+	Run(ctx context.Context, destination string) (location string, sizeBytes int64, err error)
+	DestinationNames() []string
+}
+
+// --- BackupService Interface ---
+type BackupService interface {
+	// StartBackup records a running backup and takes it in the background; poll
+	// GetBackupByID for the outcome. Only one backup runs at a time across instances.
+	StartBackup(trigger, destination string, requestedBy *int64) (*models.Backup, error)
+	GetBackups(limit int) ([]models.Backup, error)
+	GetBackupByID(id int64) (*models.Backup, error)
+	// RunSchedule takes the nightly backup configured in the backup_schedule setting
+	// until ctx is done. Every instance may run it; only one takes each night's backup.
+	RunSchedule(ctx context.Context)
+}
+
+// --- backupService Implementation ---
+type backupService struct {
+	backupRepo  repositories.BackupRepository
+	settingRepo repositories.SettingRepository
+	runner      BackupRunner // nil if backups are not configured
+	store       kvstore.Store
+	db          *sql.DB
+}
+
+// NewBackupService creates a new instance of BackupService.
+func NewBackupService(br repositories.BackupRepository, settingRepo repositories.SettingRepository, runner BackupRunner, store kvstore.Store, db *sql.DB) BackupService {
+	return &backupService{
+		backupRepo:  br,
+		settingRepo: settingRepo,
+		runner:      runner,
+		store:       store,
+		db:          db,
+	}
+}
+
+func (s *backupService) destinationAvailable(destination string) bool {
+	if s.runner == nil {
+		return false
+	}
+	for _, name := range s.runner.DestinationNames() {
+		if name == destination {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *backupService) StartBackup(trigger, destination string, requestedBy *int64) (*models.Backup, error) {
+	if destination == "" {
+		destination = models.BackupDestinationLocal
+	}
+	if !s.destinationAvailable(destination) {
+		return nil, fmt.Errorf("%w: %s", ErrBackupDestinationUnavailable, destination)
+	}
+
+	// Like the rate limiter, fail open if the store is unavailable: a second concurrent dump is only slower
+	locked, err := s.store.SetNX(context.Background(), backupLockKey, "1", backupTimeout)
+	if err != nil {
+		utils.LogError(err, "Failed to acquire backup lock, starting the backup anyway")
+	} else if !locked {
+		return nil, ErrBackupInProgress
+	}
+
+	backup, err := s.backupRepo.CreateBackup(s.db, &models.Backup{
+		Status:      BackupStatusRunning,
+		Trigger:     trigger,
+		Destination: destination,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
+		s.releaseLock()
+		return nil, fmt.Errorf("failed to record backup: %w", err)
+	}
+
+	go s.run(backup)
+	return backup, nil
+}
+
+// run takes the backup and records its outcome.
+func (s *backupService) run(backup *models.Backup) {
+	defer s.releaseLock()
+	ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+	defer cancel()
+
+	status := BackupStatusCompleted
+	var location, errMsg *string
+	var sizeBytes *int64
+	loc, size, err := s.runner.Run(ctx, backup.Destination)
+	if err != nil {
+		utils.LogError(err, fmt.Sprintf("Backup %d failed", backup.ID))
+		status = BackupStatusFailed
+		msg := err.Error()
+		errMsg = &msg
+	} else {
+		location, sizeBytes = &loc, &size
+		utils.LogInfo("Backup completed", map[string]interface{}{"backup_id": backup.ID, "location": loc, "size_bytes": size})
+	}
+	if err := s.backupRepo.FinishBackup(s.db, backup.ID, status, location, sizeBytes, errMsg, time.Now().UTC()); err != nil {
+		utils.LogError(err, fmt.Sprintf("Failed to record the outcome of backup %d", backup.ID))
+	}
+}
+
+func (s *backupService) releaseLock() {
+	if err := s.store.Delete(context.Background(), backupLockKey); err != nil {
+		utils.LogError(err, "Failed to release backup lock, it expires on its own")
+	}
+}
+
+func (s *backupService) GetBackups(limit int) ([]models.Backup, error) {
+	if limit <= 0 {
+		limit = DefaultBackupListLimit
+	}
+	if limit > MaxBackupListLimit {
+		limit = MaxBackupListLimit
+	}
+	backups, err := s.backupRepo.GetBackups(limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backups: %w", err)
+	}
+	return backups, nil
+}
+
+func (s *backupService) GetBackupByID(id int64) (*models.Backup, error) {
+	backup, err := s.backupRepo.GetBackupByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrBackupNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	return backup, nil
+}
+
+func (s *backupService) RunSchedule(ctx context.Context) {
+	ticker := time.NewTicker(BackupScheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.checkSchedule(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSchedule starts tonight's backup if it is due at now and no instance has started it yet.
+// The schedule is read from the settings on every check, so changes apply without a restart.
+func (s *backupService) checkSchedule(ctx context.Context, now time.Time) {
+	schedule, err := s.loadSchedule()
+	if err != nil {
+		utils.LogError(err, "Invalid backup schedule setting, skipping the nightly backup")
+		return
+	}
+	if schedule == nil {
+		return
+	}
+
+	local := now.In(utils.ClubLocation())
+	due := time.Date(local.Year(), local.Month(), local.Day(), schedule.Hour, schedule.Minute, 0, 0, local.Location())
+	if local.Before(due) || !local.Before(due.Add(backupScheduleWindow)) {
+		return
+	}
+	claimed, err := s.store.SetNX(ctx, "backup:scheduled:"+due.Format("2006-01-02"), "1", 48*time.Hour)
+	if err != nil {
+		utils.LogError(err, "Failed to claim the nightly backup, retrying on the next check")
+		return
+	}
+	if !claimed {
+		return
+	}
+	if _, err := s.StartBackup(BackupTriggerScheduled, schedule.Destination, nil); err != nil {
+		utils.LogError(err, "Failed to start the nightly backup")
+	}
+}
+
+func (s *backupService) loadSchedule() (*models.BackupSchedule, error) {
+	setting, err := s.settingRepo.GetSettingByKey(models.SettingKeyBackupSchedule)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if setting.SettingValue == nil {
+		return nil, nil
+	}
+	return models.ParseBackupSchedule(*setting.SettingValue)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// --- BillService Interface ---
+type BillService interface {
+	// GetOrderBill returns the bill of an order as it stands: its items, discount, tax and
+	// what has been paid of it.
+	GetOrderBill(orderID int64) (*models.Bill, error)
+	// GetSessionBill returns the bill of a table session so far without stopping it: the time
+	// played, overtime and lockers, plus the orders taken at its table while it ran.
+	GetSessionBill(sessionID int64) (*models.Bill, error)
+}
+
+type billService struct {
+	orderService   OrderService
+	sessionService TableSessionService
+	tableRepo      repositories.GameTableRepository // Names the table of a session bill
+}
+
+// NewBillService creates a new BillService.
+func NewBillService(orderService OrderService, sessionService TableSessionService, tableRepo repositories.GameTableRepository) BillService {
+	return &billService{orderService: orderService, sessionService: sessionService, tableRepo: tableRepo}
+}
+
+// sessionBillOrderStatuses are the statuses of the orders a session bill includes: open or settled.
+var sessionBillOrderStatuses = slices.Concat(OpenOrderStatuses, ShiftSalesOrderStatuses)
+
+func (s *billService) GetOrderBill(orderID int64) (*models.Bill, error) {
+	order, err := s.orderService.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	bill := &models.Bill{
+		Kind:        models.BillKindOrder,
+		ID:          order.ID,
+		Title:       "Order " + order.OrderNumber,
+		Open:        slices.Contains(OpenOrderStatuses, order.Status),
+		Lines:       []models.BillLine{},
+		TaxIncluded: order.PricesIncludeTax,
+		GeneratedAt: utils.NowUTC(),
+	}
+	addOrderToBill(bill, order)
+	totalBill(bill)
+	return bill, nil
+}
+
+func (s *billService) GetSessionBill(sessionID int64) (*models.Bill, error) {
+	session, err := s.sessionService.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	tableName := fmt.Sprintf("Table #%d", session.TableID)
+	if table, err := s.tableRepo.GetGameTableByID(session.TableID); err == nil {
+		tableName = table.Name
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get game table: %w", err)
+	}
+	bill := &models.Bill{
+		Kind:        models.BillKindTableSession,
+		ID:          session.ID,
+		Title:       fmt.Sprintf("%s, session %d", tableName, session.ID),
+		Open:        session.Running(),
+		Lines:       sessionBillLines(session),
+		Subtotal:    session.TotalAmount,
+		Total:       session.TotalAmount,
+		TaxIncluded: true,
+		GeneratedAt: utils.NowUTC(),
+	}
+
+	filters := models.OrderFilters{TableID: &session.TableID, Statuses: sessionBillOrderStatuses, DateFrom: &session.StartedAt, IncludeItems: true}
+	if session.StoppedAt != nil {
+		filters.DateTo = session.StoppedAt
+	}
+	orders, _, err := s.orderService.GetOrders(filters)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(orders) // Oldest first
+	for i := range orders {
+		// Tax is shown as added if any order adds it to its prices
+		bill.TaxIncluded = bill.TaxIncluded && orders[i].PricesIncludeTax
+		addOrderToBill(bill, &orders[i])
+	}
+	// A deposit pays the session once it stops; while it runs the deposit is only held
+	if session.Deposit != nil && session.Deposit.Status != models.DepositStatusHeld {
+		bill.Paid = bill.Paid.Add(session.Deposit.AppliedAmount)
+	}
+	totalBill(bill)
+	return bill, nil
+}
+
+// sessionBillLines returns the lines of the time, overtime and locker charges of session, as
+// billSession set them.
+func sessionBillLines(session *models.TableSession) []models.BillLine {
+	lines := []models.BillLine{}
+	if session.BillingMode == models.BillingModePerMinute && session.MinuteRate != nil {
+		lines = append(lines, models.NewBillLine("Time", fmt.Sprintf("%d min x %s/min", session.BilledMinutes, session.MinuteRate),
+			session.TimeAmount.Sub(session.MinimumChargeAmount)))
+		if session.MinimumChargeAmount.IsPositive() {
+			lines = append(lines, models.NewBillLine("Minimum charge", session.MinimumCharge.String(), session.MinimumChargeAmount))
+		}
+	} else if session.HourlyRate != nil {
+		lines = append(lines, models.NewBillLine("Time", fmt.Sprintf("%d min x %s/h", session.BilledMinutes, session.HourlyRate), session.TimeAmount))
+	}
+	if session.OvertimeMinutes > 0 && session.OvertimeRate != nil {
+		lines = append(lines, models.NewBillLine("Overtime", fmt.Sprintf("%d min x %s/min", session.OvertimeMinutes, session.OvertimeRate),
+			session.OvertimeAmount))
+	}
+	for _, rental := range session.LockerRentals {
+		if rental.Fee.IsPositive() {
+			lines = append(lines, models.NewBillLine("Locker "+rental.LockerNumber, "", rental.Fee))
+		}
+	}
+	return lines
+}
+
+// addOrderToBill adds the items, discount, service charge and tax of order to bill, and its amount to the paid
+// amount once it is settled or charged to a house account.
+func addOrderToBill(bill *models.Bill, order *models.Order) {
+	for _, item := range order.OrderItems {
+		name := fmt.Sprintf("Item #%d", item.PricelistItemID)
+		if item.PricelistItem != nil && item.PricelistItem.Name != "" {
+			name = item.PricelistItem.Name
+		}
+		bill.Lines = append(bill.Lines, models.NewBillLine(name, fmt.Sprintf("%d x %s", item.Quantity, item.UnitPrice), item.TotalPrice))
+	}
+	bill.Subtotal = bill.Subtotal.Add(order.TotalAmount)
+	if order.DiscountAmount != nil {
+		bill.Discount = bill.Discount.Add(*order.DiscountAmount)
+	}
+	if order.ServiceChargeAmount.IsPositive() {
+		bill.ServiceCharge = bill.ServiceCharge.Add(order.ServiceChargeAmount)
+		bill.ServiceChargeName = serviceChargeLabel(order)
+	}
+	bill.Tax = bill.Tax.Add(order.TaxAmount)
+	bill.Total = bill.Total.Add(order.FinalAmount)
+	houseAccount := order.PaymentMethod != nil && *order.PaymentMethod == models.PaymentMethodHouseAccount
+	if houseAccount || slices.Contains(ShiftSalesOrderStatuses, order.Status) {
+		bill.Paid = bill.Paid.Add(order.FinalAmount)
+	}
+}
+
+// totalBill sets what is due of bill and renders its totals for display.
+func totalBill(bill *models.Bill) {
+	bill.Due = bill.Total.Sub(bill.Paid)
+	if bill.Due.IsNegative() {
+		bill.Due = models.ZeroMoney
+	}
+
+	taxName := CurrentTaxSettings().DisplayName()
+	bill.Totals = []models.BillLine{models.NewBillLine("Subtotal", "", bill.Subtotal)}
+	if !bill.Discount.IsZero() {
+		bill.Totals = append(bill.Totals, models.NewBillLine("Discount", "", bill.Discount.Neg()))
+	}
+	if !bill.ServiceCharge.IsZero() {
+		bill.Totals = append(bill.Totals, models.NewBillLine(bill.ServiceChargeName, "", bill.ServiceCharge))
+	}
+	if !bill.TaxIncluded && !bill.Tax.IsZero() {
+		bill.Totals = append(bill.Totals, models.NewBillLine(taxName, "", bill.Tax))
+	}
+	bill.Totals = append(bill.Totals, models.NewBillLine("Total", "", bill.Total))
+	if bill.TaxIncluded && !bill.Tax.IsZero() {
+		bill.Totals = append(bill.Totals, models.NewBillLine("incl. "+taxName, "", bill.Tax))
+	}
+	if !bill.Paid.IsZero() {
+		bill.Totals = append(bill.Totals, models.NewBillLine("Paid", "", bill.Paid.Neg()))
+	}
+	bill.Totals = append(bill.Totals, models.NewBillLine("Due", "", bill.Due))
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrCheckInTokenInvalid = apperrors.New(utils.ErrCodeNotFound, "no booking found for the check-in code")
+	ErrAlreadyCheckedIn    = apperrors.New(utils.ErrCodeConflict, "booking is already checked in")
+	ErrCheckInNotAllowed   = apperrors.New(utils.ErrCodeConflict, "booking cannot be checked in now")
+)
+
+// CheckInOpensBefore is how long before its start a booking can be checked in.
+const CheckInOpensBefore = 30 * time.Minute
+
+// SessionSlip is returned by the kiosk on check-in; Text is ready to print.
+type SessionSlip struct {
+	BookingID      int64     `json:"booking_id"`
+	ClientName     string    `json:"client_name,omitempty"`
+	TableName      string    `json:"table_name"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	NumberOfGuests *int      `json:"number_of_guests,omitempty"`
+	CheckedInAt    time.Time `json:"checked_in_at"`
+	Text           string    `json:"text"`
+}
+
+// newCheckInToken returns a random check-in token for a new booking.
+func newCheckInToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate check-in token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setCheckInQR sets the QR code of the booking's check-in token. The QR code is a
+// convenience, so failing to draw it leaves the booking without one instead of failing.
+func setCheckInQR(booking *models.Booking) {
+	if booking.CheckInToken == "" {
+		return
+	}
+	qr, err := utils.QRCodeDataURI(booking.CheckInToken)
+	if err != nil {
+		utils.LogError(err, "setCheckInQR: failed to encode QR code for booking "+strconv.FormatInt(booking.ID, 10))
+		return
+	}
+	booking.CheckInQR = qr
+}
+
+// CheckIn checks in the client of the booking with the token, as scanned at the kiosk: it
+// records the check-in and marks the table occupied, starting the session. Confirmed
+// bookings can be checked in from CheckInOpensBefore their start until their end.
+func (s *bookingService) CheckIn(token string) (*SessionSlip, error) {
+	booking, err := s.bookingRepo.GetBookingByCheckInToken(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrCheckInTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to find booking for check-in: %w", err)
+	}
+	if booking.CheckedInAt != nil {
+		return nil, ErrAlreadyCheckedIn
+	}
+	if booking.Status != string(models.BookingStatusConfirmed) {
+		return nil, fmt.Errorf("%w: booking is %s", ErrCheckInNotAllowed, booking.Status)
+	}
+	now := utils.NowUTC()
+	if opensAt := booking.StartTime.Add(-CheckInOpensBefore); now.Before(opensAt) {
+		return nil, fmt.Errorf("%w: check-in opens at %s", ErrCheckInNotAllowed, utils.FormatClubTime(opensAt, "15:04"))
+	}
+	if !now.Before(booking.EndTime) {
+		return nil, fmt.Errorf("%w: the booking has ended", ErrCheckInNotAllowed)
+	}
+
+	table, err := s.tableRepo.GetGameTableByID(booking.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game table for check-in: %w", err)
+	}
+	if table.Status == models.TableStatusMaintenance {
+		return nil, fmt.Errorf("%w: the table is under maintenance", ErrCheckInNotAllowed)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := s.bookingRepo.CheckInBooking(tx, booking, now); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrAlreadyCheckedIn
+		}
+		return nil, fmt.Errorf("failed to check in booking: %w", err)
+	}
+	if err := changeTableStatus(tx, s.tableRepo, s.publisher, table, models.TableStatusOccupied, nil); err != nil {
+		return nil, err
+	}
+	checkedIn := now.Format(time.RFC3339)
+	change := models.BookingChange{
+		BookingID:  booking.ID,
+		ChangeType: models.BookingChangeCheckedIn,
+		NewValue:   &checkedIn,
+		ChangedAt:  now,
+	}
+	if err := s.bookingRepo.CreateBookingChanges(tx, []models.BookingChange{change}); err != nil {
+		return nil, fmt.Errorf("failed to record booking history: %w", err)
+	}
+	if err := s.publisher.Publish(tx, events.BookingCheckedIn, events.AggregateBooking, booking.ID, events.NewBookingPayload(booking, "")); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit check-in transaction: %w", err)
+	}
+	return newSessionSlip(booking), nil
+}
+
+// newSessionSlip builds the slip of a checked-in booking, with times in the club timezone.
+func newSessionSlip(booking *models.Booking) *SessionSlip {
+	slip := &SessionSlip{
+		BookingID:      booking.ID,
+		StartTime:      booking.StartTime,
+		EndTime:        booking.EndTime,
+		NumberOfGuests: booking.NumberOfGuests,
+		CheckedInAt:    *booking.CheckedInAt,
+	}
+	if booking.Client != nil {
+		slip.ClientName = booking.Client.FullName
+	}
+	if booking.GameTable != nil {
+		slip.TableName = booking.GameTable.Name
+	}
+
+	lines := []string{
+		"SESSION SLIP",
+		"Booking #" + strconv.FormatInt(booking.ID, 10),
+	}
+	if slip.ClientName != "" {
+		lines = append(lines, "Client: "+slip.ClientName)
+	}
+	lines = append(lines,
+		"Table: "+slip.TableName,
+		"Time: "+utils.FormatClubTime(slip.StartTime, "2006-01-02 15:04")+" - "+utils.FormatClubTime(slip.EndTime, "15:04"),
+	)
+	if slip.NumberOfGuests != nil {
+		lines = append(lines, "Guests: "+strconv.Itoa(*slip.NumberOfGuests))
+	}
+	lines = append(lines, "Checked in: "+utils.FormatClubTime(slip.CheckedInAt, "15:04"))
+	slip.Text = strings.Join(lines, "\n")
+	return slip
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	// ErrBookingNoticeTooShort is returned when an online booking starts sooner than the minimum notice allows.
+	ErrBookingNoticeTooShort = apperrors.New(utils.ErrCodeBookingNoticeTooShort, "booking starts too soon")
+	// ErrLateCancellationFee is returned when a late cancellation incurs a fee the caller has not accepted.
+	ErrLateCancellationFee = apperrors.New(utils.ErrCodeLateCancellationFee, "late cancellation fee applies")
+	// ErrClientBlacklisted is returned when a booking is made for a blacklisted client without an approved override.
+	ErrClientBlacklisted = apperrors.New(utils.ErrCodeClientBlacklisted, "client is blacklisted")
+	// ErrBlacklistOverrideNotAllowed is returned when the override of a blacklist is asked for but
+	// the booking policy does not allow it, or the caller books online.
+	ErrBlacklistOverrideNotAllowed = apperrors.New(utils.ErrCodeForbidden, "overriding the blacklist is not allowed")
+)
+
+// onlineBookingRole is the role of users booking for themselves, e.g. through the booking
+// widget. The minimum notice only applies to them: staff also book walk-ins.
+const onlineBookingRole = "Client"
+
+var (
+	bookingPolicy   = models.BookingPolicy{}
+	bookingPolicyMu sync.RWMutex
+)
+
+// SetBookingPolicy sets the booking policy (the booking_policy setting).
+func SetBookingPolicy(policy models.BookingPolicy) {
+	bookingPolicyMu.Lock()
+	defer bookingPolicyMu.Unlock()
+	bookingPolicy = policy
+}
+
+// CurrentBookingPolicy returns the configured booking policy.
+func CurrentBookingPolicy() models.BookingPolicy {
+	bookingPolicyMu.RLock()
+	defer bookingPolicyMu.RUnlock()
+	return bookingPolicy
+}
+
+// checkBookingNotice returns ErrBookingNoticeTooShort if a booking made at now by a user of
+// role starts before the minimum notice.
+func checkBookingNotice(role string, start, now time.Time) error {
+	policy := CurrentBookingPolicy()
+	if role != onlineBookingRole || policy.MinLeadMinutes <= 0 {
+		return nil
+	}
+	if start.Before(now.Add(policy.MinLead())) {
+		return fmt.Errorf("%w: online bookings must be made at least %d minutes in advance", ErrBookingNoticeTooShort, policy.MinLeadMinutes)
+	}
+	return nil
+}
+
+// checkClientBlacklist returns ErrClientBlacklisted if client is blacklisted and its blacklist
+// was not overridden with a manager's approval.
+func checkClientBlacklist(client *models.Client, overrideApproved bool) error {
+	if !client.Blacklisted || overrideApproved {
+		return nil
+	}
+	reason := ""
+	if client.BlacklistReason != nil {
+		reason = *client.BlacklistReason
+	}
+	return fmt.Errorf("%w: client ID %d: %s", ErrClientBlacklisted, client.ID, reason)
+}
+
+// blacklistOverridable reports whether a user of role may ask to override a blacklist.
+func blacklistOverridable(role string) bool {
+	return role != onlineBookingRole && CurrentBookingPolicy().BlacklistOverride
+}
+
+// chargesLateFee reports whether a cancellation for reason is the client's doing, and so
+// may incur the late cancellation fee. Cancellations the club causes are always free.
+func chargesLateFee(reason string) bool {
+	switch reason {
+	case models.CancellationReasonClientRequest, models.CancellationReasonClientUnreachable, models.CancellationReasonOther:
+		return true
+	}
+	return false
+}
+
+// applyCancellationPolicy sets the fee of a booking cancelled at now. A late cancellation
+// with a fee fails with ErrLateCancellationFee unless acceptLateFee is set.
+func applyCancellationPolicy(booking *models.Booking, acceptLateFee bool, now time.Time) error {
+	booking.CancellationFee = nil
+	policy := CurrentBookingPolicy()
+	if policy.LateCancellationFee == nil || !policy.LateCancellationFee.IsPositive() {
+		return nil
+	}
+	if !policy.IsLateCancellation(booking.StartTime, now) || booking.CancellationReason == nil || !chargesLateFee(*booking.CancellationReason) {
+		return nil
+	}
+	if !acceptLateFee {
+		return fmt.Errorf("%w: cancelling less than %d hours before the start costs %s, resend with accept_late_fee to cancel",
+			ErrLateCancellationFee, policy.FreeCancellationHours, policy.LateCancellationFee.String())
+	}
+	fee := *policy.LateCancellationFee
+	booking.CancellationFee = &fee
+	return nil
+}
+
+// bookingBufferMinutes returns the cleanup buffer a booking of the table is made with, which
+// the bookings_no_overlap constraint keeps free after it.
+func bookingBufferMinutes(table *models.GameTable) int {
+	return int(CurrentBookingPolicy().Buffer(table.BufferMinutes) / time.Minute)
+}
+
+// setCleanupUntil sets when the cleanup buffer after a booking ends, so calendars can show
+// the table as taken until then. Bookings without a buffer are left without it.
+func setCleanupUntil(booking *models.Booking) {
+	var tableMinutes *int
+	if booking.GameTable != nil {
+		tableMinutes = booking.GameTable.BufferMinutes
+	}
+	buffer := CurrentBookingPolicy().Buffer(tableMinutes)
+	if buffer <= 0 {
+		return
+	}
+	cleanupUntil := booking.EndTime.Add(buffer)
+	booking.CleanupUntil = &cleanupUntil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrBookingReminderValidation = apperrors.New(utils.ErrCodeValidationFailed, "booking reminder validation error")
+
+// BookingReminderDispatchInterval is how often RunDispatch looks for bookings to remind clients of.
+var BookingReminderDispatchInterval = time.Minute
+
+// bookingReminderBatch is how many reminders one dispatch sends at most; the rest wait for the next.
+const bookingReminderBatch = 100
+
+// ReminderChannelDefault in UpdateBookingReminderPreferencesRequest.Channel makes the client
+// follow the booking_reminders setting again.
+const ReminderChannelDefault = "default"
+
+var (
+	bookingReminderSettings   = models.DefaultBookingReminderSettings()
+	bookingReminderSettingsMu sync.RWMutex
+)
+
+// SetBookingReminderSettings sets the channel and lead time of the clients that chose none
+// (the booking_reminders setting).
+func SetBookingReminderSettings(settings models.BookingReminderSettings) {
+	bookingReminderSettingsMu.Lock()
+	defer bookingReminderSettingsMu.Unlock()
+	bookingReminderSettings = settings
+}
+
+// CurrentBookingReminderSettings returns the configured booking reminders.
+func CurrentBookingReminderSettings() models.BookingReminderSettings {
+	bookingReminderSettingsMu.RLock()
+	defer bookingReminderSettingsMu.RUnlock()
+	return bookingReminderSettings
+}
+
+// SMSSender sends text messages; sms.HTTPGateway implements it.
+type SMSSender interface {
+	SendSMS(ctx context.Context, phoneNumber, text string) error
+}
+
+// TelegramSender sends messages to Telegram chats with the club's bot; telegram.BotSender implements it.
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// UpdateBookingReminderPreferencesRequest is the body of PUT .../reminder-preferences; fields
+// left out are kept. Channel "default" and LeadMinutes 0 follow the booking_reminders setting again.
+type UpdateBookingReminderPreferencesRequest struct {
+	Channel        *string `json:"channel"`          // One of models.ReminderChannels or "default"
+	LeadMinutes    *int    `json:"lead_minutes"`     // Between models.MinReminderLeadMinutes and models.MaxReminderLeadMinutes, or 0
+	TelegramChatID *int64  `json:"telegram_chat_id"` // 0 unlinks the chat
+}
+
+// --- BookingReminderService Interface ---
+type BookingReminderService interface {
+	// GetPreferences returns how a client is reminded of its bookings, the booking_reminders
+	// setting if it chose nothing.
+	GetPreferences(clientID int64) (*models.BookingReminderPreferences, error)
+	// UpdatePreferences changes how a client is reminded. Choosing Telegram needs the client's
+	// chat linked, and SMS a phone number on the client.
+	UpdatePreferences(clientID int64, req UpdateBookingReminderPreferencesRequest) (*models.BookingReminderPreferences, error)
+
+	// GetReminders lists the reminders sent and attempted, newest first, with their total.
+	GetReminders(filters models.BookingReminderFilters) ([]models.BookingReminder, int, error)
+
+	// DispatchDue sends the reminders of the bookings starting within the lead time of their
+	// client, through the client's channel, and returns them. Each start time of a booking is
+	// reminded of once, across instances; failed sends are recorded, not retried.
+	DispatchDue(ctx context.Context) ([]models.BookingReminder, error)
+	// RunDispatch calls DispatchDue every BookingReminderDispatchInterval until ctx is done.
+	RunDispatch(ctx context.Context)
+}
+
+type bookingReminderService struct {
+	reminderRepo   repositories.BookingReminderRepository
+	clientRepo     repositories.ClientRepository
+	pushService    PushNotificationService
+	smsSender      SMSSender
+	telegramSender TelegramSender
+}
+
+// NewBookingReminderService creates a new BookingReminderService. smsSender and telegramSender
+// may be nil when the channel is not configured; reminders through it are then recorded as failed.
+func NewBookingReminderService(reminderRepo repositories.BookingReminderRepository, clientRepo repositories.ClientRepository,
+	pushService PushNotificationService, smsSender SMSSender, telegramSender TelegramSender) BookingReminderService {
+	return &bookingReminderService{reminderRepo: reminderRepo, clientRepo: clientRepo, pushService: pushService,
+		smsSender: smsSender, telegramSender: telegramSender}
+}
+
+// client returns a client, ErrClientNotFound for an unknown one and ErrClientAnonymized for an
+// anonymized one.
+func (s *bookingReminderService) client(clientID int64) (*models.Client, error) {
+	client, err := s.clientRepo.GetClientByID(clientID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+	return client, nil
+}
+
+// preferences returns the saved preferences of a client, or none, with their Effective fields.
+func (s *bookingReminderService) preferences(clientID int64) (*models.BookingReminderPreferences, error) {
+	preferences, err := s.reminderRepo.GetPreferences(clientID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		preferences, err = &models.BookingReminderPreferences{ClientID: clientID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder preferences: %w", err)
+	}
+	preferences.ApplyDefaults(CurrentBookingReminderSettings())
+	return preferences, nil
+}
+
+func (s *bookingReminderService) GetPreferences(clientID int64) (*models.BookingReminderPreferences, error) {
+	if _, err := s.client(clientID); err != nil {
+		return nil, err
+	}
+	return s.preferences(clientID)
+}
+
+func (s *bookingReminderService) UpdatePreferences(clientID int64, req UpdateBookingReminderPreferencesRequest) (*models.BookingReminderPreferences, error) {
+	client, err := s.client(clientID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := s.preferences(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Channel != nil {
+		channel := strings.TrimSpace(*req.Channel)
+		switch {
+		case channel == ReminderChannelDefault:
+			preferences.Channel = nil
+		case models.IsValidReminderChannel(channel):
+			preferences.Channel = &channel
+		default:
+			return nil, fmt.Errorf("%w: channel must be one of %s or %s", ErrBookingReminderValidation,
+				strings.Join(models.ReminderChannels, ", "), ReminderChannelDefault)
+		}
+	}
+	if req.LeadMinutes != nil {
+		minutes := *req.LeadMinutes
+		switch {
+		case minutes == 0:
+			preferences.LeadMinutes = nil
+		case minutes >= models.MinReminderLeadMinutes && minutes <= models.MaxReminderLeadMinutes:
+			preferences.LeadMinutes = &minutes
+		default:
+			return nil, fmt.Errorf("%w: lead_minutes must be between %d and %d, or 0 for the default", ErrBookingReminderValidation,
+				models.MinReminderLeadMinutes, models.MaxReminderLeadMinutes)
+		}
+	}
+	if req.TelegramChatID != nil {
+		preferences.TelegramChatID = req.TelegramChatID
+		if *req.TelegramChatID == 0 {
+			preferences.TelegramChatID = nil
+		}
+	}
+	if preferences.Channel != nil {
+		switch *preferences.Channel {
+		case models.ReminderChannelTelegram:
+			if preferences.TelegramChatID == nil {
+				return nil, fmt.Errorf("%w: the Telegram chat of the client must be linked for Telegram reminders", ErrBookingReminderValidation)
+			}
+		case models.ReminderChannelSMS:
+			if client.PhoneNumber == nil || strings.TrimSpace(*client.PhoneNumber) == "" {
+				return nil, fmt.Errorf("%w: the client has no phone number for SMS reminders", ErrBookingReminderValidation)
+			}
+		}
+	}
+	if err := s.reminderRepo.SavePreferences(preferences); err != nil {
+		return nil, fmt.Errorf("failed to save reminder preferences: %w", err)
+	}
+	preferences.ApplyDefaults(CurrentBookingReminderSettings())
+	return preferences, nil
+}
+
+func (s *bookingReminderService) GetReminders(filters models.BookingReminderFilters) ([]models.BookingReminder, int, error) {
+	reminders, total, err := s.reminderRepo.GetReminders(filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get booking reminders: %w", err)
+	}
+	return reminders, total, nil
+}
+
+func (s *bookingReminderService) DispatchDue(ctx context.Context) ([]models.BookingReminder, error) {
+	due, err := s.reminderRepo.GetDue(CurrentBookingReminderSettings(), time.Now().UTC(), bookingReminderBatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due booking reminders: %w", err)
+	}
+	reminders := []models.BookingReminder{}
+	for _, booking := range due {
+		reminder := models.BookingReminder{BookingID: booking.BookingID, ClientID: booking.ClientID,
+			BookingStart: booking.StartTime, Channel: booking.Channel}
+		claimed, err := s.reminderRepo.Claim(&reminder)
+		if err != nil {
+			return reminders, err
+		}
+		if !claimed {
+			continue
+		}
+		reminder.Status = models.BookingReminderSent
+		if sendErr := s.send(ctx, booking); sendErr != nil {
+			errMsg := sendErr.Error()
+			reminder.Status, reminder.Error = models.BookingReminderFailed, &errMsg
+			utils.LogError(sendErr, fmt.Sprintf("Failed to send %s reminder of booking ID %d", booking.Channel, booking.BookingID))
+		} else {
+			sentAt := time.Now().UTC()
+			reminder.SentAt = &sentAt
+		}
+		if err := s.reminderRepo.Finish(&reminder); err != nil {
+			utils.LogError(err, "Failed to record booking reminder")
+		}
+		reminders = append(reminders, reminder)
+	}
+	return reminders, nil
+}
+
+// send reminds the client of booking through its channel.
+func (s *bookingReminderService) send(ctx context.Context, booking models.DueBookingReminder) error {
+	when := utils.FormatClubTime(booking.StartTime, "Mon 2 Jan, 15:04")
+	text := fmt.Sprintf("Reminder: your booking of %s starts %s. See you soon!", booking.TableName, when)
+	switch booking.Channel {
+	case models.ReminderChannelSMS:
+		if s.smsSender == nil {
+			return errors.New("SMS is not configured")
+		}
+		if booking.PhoneNumber == nil || strings.TrimSpace(*booking.PhoneNumber) == "" {
+			return errors.New("the client has no phone number")
+		}
+		return s.smsSender.SendSMS(ctx, *booking.PhoneNumber, text)
+	case models.ReminderChannelTelegram:
+		if s.telegramSender == nil {
+			return errors.New("the Telegram bot is not configured")
+		}
+		if booking.TelegramChatID == nil {
+			return errors.New("the client has not linked a Telegram chat")
+		}
+		return s.telegramSender.SendMessage(ctx, *booking.TelegramChatID, text)
+	case models.ReminderChannelPush:
+		reached, err := s.pushService.PushToClient(ctx, booking.ClientID, models.PushKindBookingReminder, models.PushMessage{
+			Title: "Booking reminder",
+			Body:  text,
+			Data:  map[string]string{"kind": models.PushKindBookingReminder, "booking_id": strconv.FormatInt(booking.BookingID, 10)},
+		})
+		if err != nil {
+			return err
+		}
+		if reached == 0 {
+			return errors.New("no device of the client could be reached")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown reminder channel %q", booking.Channel)
+}
+
+func (s *bookingReminderService) RunDispatch(ctx context.Context) {
+	ticker := time.NewTicker(BookingReminderDispatchInterval)
+	defer ticker.Stop()
+	for {
+		sent, err := s.DispatchDue(ctx)
+		if err != nil {
+			utils.LogError(err, "Failed to dispatch booking reminders")
+		} else if len(sent) > 0 {
+			utils.LogInfo("Dispatched booking reminders", map[string]interface{}{"reminders": len(sent)})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
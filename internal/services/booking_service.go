@@ -1,25 +1,34 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/kvstore"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // --- Custom Service Errors for Booking ---
 var (
-	ErrBookingNotFound          = errors.New("booking not found")
-	ErrTableNotAvailable        = errors.New("table is not available for the requested time")
-	ErrInvalidBookingTime       = errors.New("invalid booking time (e.g., end before start, duration limits, or in the past)")
-	ErrClientForBookingNotFound = errors.New("client specified for booking not found")
-	ErrStaffForBookingNotFound  = errors.New("staff member specified for booking not found")
-	ErrTableForBookingNotFound  = errors.New("table specified for booking not found") 
-	ErrBookingStatusUpdate      = errors.New("invalid status transition or error updating booking status")
-	ErrBookingValidation        = errors.New("booking data validation error")
+	ErrBookingNotFound          = apperrors.New(utils.ErrCodeNotFound, "booking not found")
+	ErrTableNotAvailable        = apperrors.New(utils.ErrCodeConflict, "table is not available for the requested time")
+	ErrTableBusy                = apperrors.New(utils.ErrCodeConflict, "table is being booked by another request, please retry")
+	ErrInvalidBookingTime       = apperrors.New(utils.ErrCodeValidationFailed, "invalid booking time (e.g., end before start, duration limits, or in the past)")
+	ErrClientForBookingNotFound = apperrors.New(utils.ErrCodeBadRequest, "client specified for booking not found")
+	ErrStaffForBookingNotFound  = apperrors.New(utils.ErrCodeBadRequest, "staff member specified for booking not found")
+	ErrTableForBookingNotFound  = apperrors.New(utils.ErrCodeBadRequest, "table specified for booking not found") 
+	ErrBookingStatusUpdate      = apperrors.New(utils.ErrCodeConflict, "invalid status transition or error updating booking status")
+	ErrBookingValidation        = apperrors.New(utils.ErrCodeValidationFailed, "booking data validation error")
+	ErrBookingNotDeleted        = apperrors.New(utils.ErrCodeNotFound, "booking is not deleted")
 )
 
 
@@ -32,27 +41,94 @@ type CreateBookingRequest struct {
 	EndTime        string  `json:"end_time" binding:"required"`
 	NumberOfGuests *int    `json:"number_of_guests"`
 	Notes          *string `json:"notes"`
-	Status         *string `json:"status"` 
+	Status         *string `json:"status" binding:"omitempty,booking_status"`
+	Controllers    *int    `json:"controllers" binding:"omitempty,min=1"` // Defaults to the table's base controllers
+	CallerRole     string  `json:"-"`                                     // Role of the authenticated user; bookings by clients must meet the minimum notice
+	// UUID is given by the client to the booking so that a retried request returns the
+	// booking created the first time instead of creating it again
+	UUID *string `json:"uuid" binding:"omitempty,uuid"`
+
+	// OverrideBlacklist asks a manager to approve booking a blacklisted client (ApprovalService.CreateBooking)
+	OverrideBlacklist         bool `json:"override_blacklist"`
+	BlacklistOverrideApproved bool `json:"-"` // Set once a manager approved it
+
+	// Source is the channel the booking is made through, one of models.OrderSources, from the
+	// authenticated principal; empty for the POS. Bookings of self-service channels have no
+	// StaffID and must meet the minimum notice like those of clients.
+	Source string `json:"-"`
 }
 
 type UpdateBookingRequest struct {
-	TableID        *int64  `json:"table_id"`
-	StartTime      *string `json:"start_time"`
-	EndTime        *string `json:"end_time"`
-	NumberOfGuests *int    `json:"number_of_guests"`
-	Notes          *string `json:"notes"`
-	Status         *string `json:"status"`
+	TableID            *int64   `json:"table_id"`
+	StartTime          *string  `json:"start_time"`
+	EndTime            *string  `json:"end_time"`
+	NumberOfGuests     *int     `json:"number_of_guests"`
+	Notes              *string  `json:"notes"`
+	Status             *string  `json:"status" binding:"omitempty,booking_status"`
+	Controllers        *int     `json:"controllers" binding:"omitempty,min=1"`
+	Version            *int     `json:"version"`                                                     // Version the client last read; a stale value is rejected with ErrVersionConflict
+	Clear              []string `json:"-"`                                                           // Fields to set to null, from a merge patch; see BookingClearableFields
+	Reason             *string  `json:"reason"`                                                      // Why the booking is changed, recorded in its history
+	CancellationReason *string  `json:"cancellation_reason" binding:"omitempty,cancellation_reason"` // Required to change the status to cancelled
+	AcceptLateFee      bool     `json:"accept_late_fee"`                                             // Agrees to the late cancellation fee, see CancelBookingRequest
+}
+
+// CancelBookingRequest is the body of PATCH /bookings/:id/cancel.
+type CancelBookingRequest struct {
+	CancellationReason string  `json:"cancellation_reason" binding:"required,cancellation_reason"` // One of models.CancellationReasons
+	Reason             *string `json:"reason"`                                                     // Details, recorded in the booking history
+	// AcceptLateFee agrees to the late cancellation fee of the booking policy. Without it, a
+	// cancellation that incurs the fee fails with ErrLateCancellationFee.
+	AcceptLateFee bool `json:"accept_late_fee"`
+}
+
+// QuoteBookingRequest is the body of POST /bookings/quote.
+type QuoteBookingRequest struct {
+	TableID     int64  `json:"table_id" binding:"required"`
+	StartTime   string `json:"start_time" binding:"required"`
+	EndTime     string `json:"end_time" binding:"required"`
+	Controllers *int   `json:"controllers" binding:"omitempty,min=1"` // Defaults to the table's base controllers
 }
 
+// BookingClearableFields are the fields of UpdateBookingRequest a merge patch may set to null.
+// Clearing controllers books the table's base controllers.
+var BookingClearableFields = []string{"number_of_guests", "notes", "controllers"}
+
 // --- BookingService Interface ---
 type BookingService interface {
-	CreateBooking(req CreateBookingRequest) (*models.Booking, error)
+	// The methods changing a booking record the change in its history as made by the user changedBy.
+	CreateBooking(req CreateBookingRequest, changedBy int64) (*models.Booking, error)
 	GetBookingByID(bookingID int64) (*models.Booking, error)
 	GetBookings(filters models.BookingFilters) ([]models.Booking, int, error)
-	UpdateBooking(bookingID int64, req UpdateBookingRequest) (*models.Booking, error)
-	CancelBooking(bookingID int64) (*models.Booking, error) 
-	CompleteBooking(bookingID int64) (*models.Booking, error) 
-	DeleteBooking(bookingID int64) error
+	// GetBookingsByCursor returns a page of bookings, latest start first, of filters.PageSize
+	// bookings. Pass the NextCursor of a page as cursor to get the following page ("" for the first).
+	GetBookingsByCursor(filters models.BookingFilters, cursor string) (*models.CursorPage[models.Booking], error)
+	UpdateBooking(bookingID int64, req UpdateBookingRequest, changedBy int64) (*models.Booking, error)
+	CancelBooking(bookingID int64, req CancelBookingRequest, changedBy int64) (*models.Booking, error)
+	CompleteBooking(bookingID int64, changedBy int64) (*models.Booking, error)
+	// CancelClientBookings cancels the client's pending and confirmed bookings that have
+	// not started yet, or only those in bookingIDs if given, in one transaction and
+	// reports the outcome per booking.
+	CancelClientBookings(clientID int64, bookingIDs []int64, req CancelBookingRequest, changedBy int64) (*BulkResult, error)
+	// DeleteBooking soft deletes a booking: it is left out everywhere and frees its table until
+	// an Admin restores it. The delete is recorded in the audit log.
+	DeleteBooking(bookingID int64, deletedBy int64) error
+	// RestoreBooking restores a soft deleted booking, recording it in the audit log.
+	// ErrBookingNotDeleted if it is not deleted; ErrTableNotAvailable if it is confirmed and
+	// another confirmed booking of its table overlaps it since.
+	RestoreBooking(bookingID int64, restoredBy int64) (*models.Booking, error)
+	// GetBookingHistory returns the changes of a booking, oldest first.
+	GetBookingHistory(bookingID int64) ([]models.BookingChange, error)
+	// CheckIn checks in the booking with the check-in token and returns its session slip.
+	CheckIn(token string) (*SessionSlip, error)
+	// QuoteBooking prices a booking without making it, with the surcharge of extra controllers.
+	QuoteBooking(req QuoteBookingRequest) (*models.PriceQuote, error)
+}
+
+// bookingChange says who changes a booking and why, for the booking history.
+type bookingChange struct {
+	changedBy int64
+	reason    *string
 }
 
 // --- bookingService Implementation ---
@@ -60,25 +136,59 @@ type bookingService struct {
 	bookingRepo repositories.BookingRepository
 	clientRepo  repositories.ClientRepository 
 	staffRepo   repositories.StaffRepository  
-	// tableRepo repositories.GameTableRepository // TODO: Add when GameTableRepository exists
-	db *sql.DB
+	tableRepo   repositories.GameTableRepository // Marks the table occupied on check-in
+	auditLogRepo repositories.AuditLogRepository // Records soft deletes and restores
+	db        *sql.DB
+	locker    kvstore.Locker   // Serializes availability check and write per table across instances
+	publisher events.Publisher // Records booking events in the transaction of the change
+
+	lockerRepo repositories.LockerRepository // Lockers rented for the visit, on single-booking responses
 }
 
+const (
+	bookingLockTTL  = 10 * time.Second // Bounds how long a crashed instance can block a table
+	bookingLockWait = 5 * time.Second
+)
+
 // NewBookingService creates a new instance of BookingService.
 func NewBookingService(
 	br repositories.BookingRepository,
 	cr repositories.ClientRepository,
 	sr repositories.StaffRepository,
-	// tr repositories.GameTableRepository, // TODO
+	lr repositories.LockerRepository,
+	tr repositories.GameTableRepository,
+	ar repositories.AuditLogRepository,
 	db *sql.DB,
+	locker kvstore.Locker,
+	publisher events.Publisher,
 ) BookingService {
 	return &bookingService{
 		bookingRepo: br,
 		clientRepo:  cr,
 		staffRepo:   sr,
-		// tableRepo: tr, // TODO
-		db: db,
+		tableRepo:   tr,
+		auditLogRepo: ar,
+		db:        db,
+		locker:    locker,
+		publisher: publisher,
+
+		lockerRepo: lr,
+	}
+}
+
+// lockTable takes the booking lock of a table, so that two requests (possibly on
+// different instances) cannot both see a slot as free and book it.
+func (s *bookingService) lockTable(tableID int64) (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bookingLockWait)
+	defer cancel()
+	unlock, err := s.locker.Lock(ctx, fmt.Sprintf("lock:booking:table:%d", tableID), bookingLockTTL)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrLockTimeout) {
+			return nil, ErrTableBusy
+		}
+		return nil, fmt.Errorf("failed to lock table for booking: %w", err)
 	}
+	return unlock, nil
 }
 
 // parseAndValidateBookingTimes parses string dates to time.Time and performs validation.
@@ -108,7 +218,7 @@ func (s *bookingService) parseAndValidateBookingTimes(startTimeStr, endTimeStr s
 	if !forUpdate && startTime.Before(time.Now().Add(-5*time.Minute)) {
 		return time.Time{}, time.Time{}, fmt.Errorf("%w: booking start time cannot be in the past for new bookings", ErrInvalidBookingTime)
 	}
-	if forUpdate && existingStartTime != nil && startTime != *existingStartTime && startTime.Before(time.Now().Add(-5*time.Minute)) {
+	if forUpdate && existingStartTime != nil && !startTime.Equal(*existingStartTime) && startTime.Before(time.Now().Add(-5*time.Minute)) {
 		return time.Time{}, time.Time{}, fmt.Errorf("%w: booking start time cannot be moved to the past", ErrInvalidBookingTime)
 	}
 
@@ -117,34 +227,66 @@ func (s *bookingService) parseAndValidateBookingTimes(startTimeStr, endTimeStr s
 }
 
 
-func (s *bookingService) CreateBooking(req CreateBookingRequest) (*models.Booking, error) {
+func (s *bookingService) CreateBooking(req CreateBookingRequest, changedBy int64) (*models.Booking, error) {
+	if existing, err := s.bookingWithUUID(req.UUID); existing != nil || err != nil {
+		return existing, err
+	}
+
 	startTime, endTime, err := s.parseAndValidateBookingTimes(req.StartTime, req.EndTime, false, nil)
 	if err != nil {
 		return nil, err
 	}
+	callerRole := req.CallerRole
+	if models.IsSelfServiceSource(req.Source) {
+		callerRole = onlineBookingRole
+	}
+	if err := checkBookingNotice(callerRole, startTime, utils.NowUTC()); err != nil {
+		return nil, err
+	}
 
 	if req.ClientID != nil {
-		_, err = s.clientRepo.GetClientByID(*req.ClientID)
+		client, err := s.clientRepo.GetClientByID(*req.ClientID)
 		if err != nil {
 			if errors.Is(err, repositories.ErrNotFound) {
 				return nil, fmt.Errorf("%w: ID %d", ErrClientForBookingNotFound, *req.ClientID)
 			}
 			return nil, fmt.Errorf("failed to validate client for booking: %w", err)
 		}
+		if err := checkClientBlacklist(client, req.BlacklistOverrideApproved); err != nil {
+			return nil, err
+		}
 	}
 
-	_, err = s.staffRepo.GetStaffMemberByID(req.StaffID)
-	if err != nil {
-		if errors.Is(err, repositories.ErrNotFound) {
-			return nil, fmt.Errorf("%w: ID %d", ErrStaffForBookingNotFound, req.StaffID)
+	var staffID *int64
+	if req.StaffID != 0 || !models.IsSelfServiceSource(req.Source) {
+		_, err = s.staffRepo.GetStaffMemberByID(req.StaffID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: ID %d", ErrStaffForBookingNotFound, req.StaffID)
+			}
+			return nil, fmt.Errorf("failed to validate staff for booking: %w", err)
 		}
-		return nil, fmt.Errorf("failed to validate staff for booking: %w", err)
+		staffID = &req.StaffID
 	}
 	
-	// TODO: Validate TableID using a GameTableRepository if it exists.
-	// For now, CheckTableAvailability implicitly requires table to exist for the query to not fail in a specific way.
+	table, err := s.getBookingTable(req.TableID)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := quoteTable(table, req.Controllers, bookingMinutes(startTime, endTime), ErrBookingValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockTable(req.TableID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	available, err := s.bookingRepo.CheckTableAvailability(req.TableID, startTime, endTime, nil)
+	// The bookings_no_overlap constraint rejects bookings overlapping, cleanup buffers included,
+	// that pass this check concurrently; checking first fails early with a clear error in the common case.
+	available, err := s.bookingRepo.CheckTableAvailability(req.TableID, startTime, endTime, CurrentBookingPolicy().BufferMinutes, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check table availability: %w", err)
 	}
@@ -152,7 +294,7 @@ func (s *bookingService) CreateBooking(req CreateBookingRequest) (*models.Bookin
 		return nil, ErrTableNotAvailable
 	}
 
-	status := models.BookingStatusConfirmed 
+	status := string(models.BookingStatusConfirmed) 
 	if req.Status != nil && strings.TrimSpace(*req.Status) != "" {
 		if !models.IsValidBookingStatus(*req.Status) {
 			return nil, fmt.Errorf("%w: invalid status '%s'", ErrBookingValidation, *req.Status)
@@ -163,21 +305,109 @@ func (s *bookingService) CreateBooking(req CreateBookingRequest) (*models.Bookin
 	booking := &models.Booking{
 		ClientID:       req.ClientID,
 		TableID:        req.TableID,
-		StaffID:        &req.StaffID,
+		StaffID:        staffID,
 		StartTime:      startTime,
 		EndTime:        endTime,
 		NumberOfGuests: req.NumberOfGuests,
 		Status:         status,
 		Notes:          req.Notes,
-		// TotalPrice will be calculated by repository or trigger if not set
+		TotalPrice:     &quote.TotalAmount,
+		Controllers:    req.Controllers,
+		UUID:           req.UUID,
+		Source:         req.Source,
+		BufferMinutes:  bookingBufferMinutes(table),
+	}
+	booking.CheckInToken, err = newCheckInToken()
+	if err != nil {
+		return nil, err
 	}
 
-	createdBooking, err := s.bookingRepo.CreateBooking(s.db, booking)
+	tx, err := s.db.Begin()
 	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	createdBooking, err := s.bookingRepo.CreateBooking(tx, booking)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTableNotAvailable) {
+			return nil, ErrTableNotAvailable
+		}
+		// Retried at the same time: the other request created it
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			tx.Rollback()
+			if existing, getErr := s.bookingWithUUID(req.UUID); existing != nil || getErr != nil {
+				return existing, getErr
+			}
+		}
 		return nil, fmt.Errorf("failed to create booking in repository: %w", err)
 	}
+	created := models.BookingChange{
+		BookingID:  createdBooking.ID,
+		ChangeType: models.BookingChangeCreated,
+		NewValue:   &createdBooking.Status,
+		ChangedAt:  createdBooking.CreatedAt,
+	}
+	if changedBy != 0 { // Bookings of self-service channels are made by no user
+		created.ChangedBy = &changedBy
+	}
+	if err := s.bookingRepo.CreateBookingChanges(tx, []models.BookingChange{created}); err != nil {
+		return nil, fmt.Errorf("failed to record booking history: %w", err)
+	}
+	if err := s.publisher.Publish(tx, events.BookingCreated, events.AggregateBooking, createdBooking.ID, events.NewBookingPayload(createdBooking, "")); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit booking transaction: %w", err)
+	}
 	
-	return s.bookingRepo.GetBookingByID(createdBooking.ID) // Fetch with all joins
+	return s.GetBookingByID(createdBooking.ID) // Fetch with all joins, and the check-in QR code
+}
+
+// bookingWithUUID returns the booking created before with uuid, nil if uuid is nil or no
+// booking has it.
+func (s *bookingService) bookingWithUUID(uuid *string) (*models.Booking, error) {
+	if uuid == nil {
+		return nil, nil
+	}
+	existing, err := s.bookingRepo.GetBookingByUUID(*uuid)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up booking by UUID: %w", err)
+	}
+	return s.GetBookingByID(existing.ID)
+}
+
+// getBookingTable returns the game table of a booking; ErrTableForBookingNotFound if it does not exist.
+func (s *bookingService) getBookingTable(tableID int64) (*models.GameTable, error) {
+	table, err := s.bookingRepo.GetGameTableByID(tableID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: ID %d", ErrTableForBookingNotFound, tableID)
+		}
+		return nil, fmt.Errorf("failed to validate table for booking: %w", err)
+	}
+	return table, nil
+}
+
+// bookingMinutes returns the length of a booking in minutes, counting a started minute as a whole one.
+func bookingMinutes(startTime, endTime time.Time) int {
+	return int(math.Ceil(endTime.Sub(startTime).Minutes()))
+}
+
+func (s *bookingService) QuoteBooking(req QuoteBookingRequest) (*models.PriceQuote, error) {
+	// Validated as an update, so that a quote may be asked for a booking that already started
+	startTime, endTime, err := s.parseAndValidateBookingTimes(req.StartTime, req.EndTime, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.getBookingTable(req.TableID)
+	if err != nil {
+		return nil, err
+	}
+	return quoteTable(table, req.Controllers, bookingMinutes(startTime, endTime), ErrBookingValidation)
 }
 
 func (s *bookingService) GetBookingByID(bookingID int64) (*models.Booking, error) {
@@ -188,6 +418,13 @@ func (s *bookingService) GetBookingByID(bookingID int64) (*models.Booking, error
 		}
 		return nil, fmt.Errorf("failed to get booking by ID: %w", err)
 	}
+	setCleanupUntil(booking)
+	setCheckInQR(booking)
+	rentals, err := s.lockerRepo.GetRentals(models.LockerRentalFilters{BookingID: &bookingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locker rentals of booking: %w", err)
+	}
+	booking.LockerRentals = rentals
 	return booking, nil
 }
 
@@ -199,10 +436,37 @@ func (s *bookingService) GetBookings(filters models.BookingFilters) ([]models.Bo
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get bookings: %w", err)
 	}
+	for i := range bookings {
+		setCleanupUntil(&bookings[i])
+	}
 	return bookings, totalCount, nil
 }
 
-func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest) (*models.Booking, error) {
+func (s *bookingService) GetBookingsByCursor(filters models.BookingFilters, cursor string) (*models.CursorPage[models.Booking], error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := filters.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultCursorPageSize
+	}
+	filters.Cursor = after
+	filters.PageSize = pageSize + 1 // One extra row tells whether there is a next page
+
+	bookings, _, err := s.bookingRepo.GetBookings(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %w", err)
+	}
+	for i := range bookings {
+		setCleanupUntil(&bookings[i])
+	}
+	return cursorPage(bookings, pageSize, func(b models.Booking) models.Cursor {
+		return models.Cursor{Time: b.StartTime, ID: b.ID}
+	}), nil
+}
+
+func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest, changedBy int64) (*models.Booking, error) {
 	booking, err := s.bookingRepo.GetBookingByID(bookingID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -210,11 +474,20 @@ func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest
 		}
 		return nil, fmt.Errorf("failed to find booking for update: %w", err)
 	}
+	if req.Version != nil {
+		if *req.Version != booking.Version {
+			return nil, ErrVersionConflict
+		}
+	}
+	if err := checkClearable(req.Clear, BookingClearableFields, ErrBookingValidation); err != nil {
+		return nil, err
+	}
 
 	// Prevent updates to bookings that are already completed or cancelled
-	if booking.Status == models.BookingStatusCompleted || booking.Status == models.BookingStatusCancelled {
+	if booking.Status == string(models.BookingStatusCompleted) || booking.Status == string(models.BookingStatusCancelled) {
 		return nil, fmt.Errorf("%w: cannot update a booking that is already '%s'", ErrBookingValidation, booking.Status)
 	}
+	before := *booking
 
 
 	if req.TableID != nil { booking.TableID = *req.TableID }
@@ -235,8 +508,14 @@ func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest
 	}
 
 
-	if timeChanged || (req.TableID != nil && *req.TableID != booking.TableID) {
-		available, availabilityErr := s.bookingRepo.CheckTableAvailability(booking.TableID, newStartTime, newEndTime, &bookingID)
+	if timeChanged || booking.TableID != before.TableID {
+		unlock, lockErr := s.lockTable(booking.TableID)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		defer unlock()
+
+		available, availabilityErr := s.bookingRepo.CheckTableAvailability(booking.TableID, newStartTime, newEndTime, CurrentBookingPolicy().BufferMinutes, &bookingID)
 		if availabilityErr != nil {
 			return nil, fmt.Errorf("failed to check table availability for update: %w", availabilityErr)
 		}
@@ -249,6 +528,10 @@ func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest
 	
 	if req.NumberOfGuests != nil { booking.NumberOfGuests = req.NumberOfGuests }
 	if req.Notes != nil { booking.Notes = req.Notes }
+	if clears(req.Clear, "number_of_guests") { booking.NumberOfGuests = nil }
+	if clears(req.Clear, "notes") { booking.Notes = nil }
+	if req.Controllers != nil { booking.Controllers = req.Controllers }
+	if clears(req.Clear, "controllers") { booking.Controllers = nil }
 	if req.Status != nil { 
 		if !models.IsValidBookingStatus(*req.Status) {
 			return nil, fmt.Errorf("%w: invalid status '%s'", ErrBookingValidation, *req.Status)
@@ -257,19 +540,43 @@ func (s *bookingService) UpdateBooking(bookingID int64, req UpdateBookingRequest
 		// e.g., if current status is "confirmed", can it be changed to "pending"?
 		booking.Status = *req.Status
 	}
-	// TODO: Recalculate TotalPrice if times or table changed
+	if booking.Status == string(models.BookingStatusCancelled) {
+		if err := prepareCancellation(booking, req.CancellationReason, req.AcceptLateFee); err != nil {
+			return nil, err
+		}
+	}
+	if timeChanged || booking.TableID != before.TableID || req.Controllers != nil || clears(req.Clear, "controllers") {
+		table, tableErr := s.getBookingTable(booking.TableID)
+		if tableErr != nil {
+			return nil, tableErr
+		}
+		quote, quoteErr := quoteTable(table, booking.Controllers, bookingMinutes(booking.StartTime, booking.EndTime), ErrBookingValidation)
+		if quoteErr != nil {
+			return nil, quoteErr
+		}
+		booking.TotalPrice = &quote.TotalAmount
+		// A moved booking takes the buffer it was checked with; otherwise it keeps the one it was made with
+		if timeChanged || booking.TableID != before.TableID {
+			booking.BufferMinutes = bookingBufferMinutes(table)
+		}
+	}
 
-	updatedBooking, err := s.bookingRepo.UpdateBooking(s.db, booking)
+	updatedBooking, err := s.saveBooking(booking, &before, bookingChange{changedBy: changedBy, reason: req.Reason})
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return nil, ErrBookingNotFound 
 		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
 		return nil, fmt.Errorf("failed to update booking in repository: %w", err)
 	}
 	return s.bookingRepo.GetBookingByID(updatedBooking.ID)
 }
 
-func (s *bookingService) updateBookingStatus(bookingID int64, newStatus string) (*models.Booking, error) {
+// updateBookingStatus changes the status of a booking; cancellation is required to
+// cancel it and ignored otherwise.
+func (s *bookingService) updateBookingStatus(bookingID int64, newStatus string, cancellation *CancelBookingRequest, change bookingChange) (*models.Booking, error) {
     booking, err := s.bookingRepo.GetBookingByID(bookingID)
     if err != nil {
         if errors.Is(err, repositories.ErrNotFound) {
@@ -278,34 +585,216 @@ func (s *bookingService) updateBookingStatus(bookingID int64, newStatus string)
         return nil, fmt.Errorf("failed to find booking to update status: %w", err)
     }
 
-    // Basic status transition validation (can be more complex)
-    if booking.Status == models.BookingStatusCompleted && newStatus != models.BookingStatusCompleted {
-        return nil, fmt.Errorf("%w: cannot change status of a completed booking", ErrBookingStatusUpdate)
-    }
-    if booking.Status == models.BookingStatusCancelled && newStatus != models.BookingStatusCancelled {
-         return nil, fmt.Errorf("%w: cannot change status of a cancelled booking", ErrBookingStatusUpdate)
+    if err := checkBookingStatusChange(booking, newStatus); err != nil {
+        return nil, err
     }
 
+    before := *booking
     booking.Status = newStatus
+    // Cancelling a cancelled booking again keeps its reason and fee
+    if newStatus == string(models.BookingStatusCancelled) && before.Status != newStatus {
+        if err := prepareCancellation(booking, &cancellation.CancellationReason, cancellation.AcceptLateFee); err != nil {
+            return nil, err
+        }
+    }
     // The UpdateBooking method updates more than just status.
     // A more specific repository method `UpdateBookingStatus` would be better.
     // For now, using the general UpdateBooking.
-    updatedBooking, err := s.bookingRepo.UpdateBooking(s.db, booking) 
+    updatedBooking, err := s.saveBooking(booking, &before, change)
     if err != nil {
+        if errors.Is(err, repositories.ErrVersionConflict) {
+            return nil, ErrVersionConflict // Changed between our read and write
+        }
         return nil, fmt.Errorf("%w: %v", ErrBookingStatusUpdate, err)
     }
     return s.bookingRepo.GetBookingByID(updatedBooking.ID)
 }
 
-func (s *bookingService) CancelBooking(bookingID int64) (*models.Booking, error) {
-	return s.updateBookingStatus(bookingID, models.BookingStatusCancelled)
+// checkBookingStatusChange rejects status changes of completed and cancelled bookings.
+func checkBookingStatusChange(booking *models.Booking, newStatus string) error {
+    // Basic status transition validation (can be more complex)
+    if booking.Status == string(models.BookingStatusCompleted) && newStatus != string(models.BookingStatusCompleted) {
+        return fmt.Errorf("%w: cannot change status of a completed booking", ErrBookingStatusUpdate)
+    }
+    if booking.Status == string(models.BookingStatusCancelled) && newStatus != string(models.BookingStatusCancelled) {
+         return fmt.Errorf("%w: cannot change status of a cancelled booking", ErrBookingStatusUpdate)
+    }
+    return nil
+}
+
+// prepareCancellation sets the reason a booking is cancelled for and the late cancellation
+// fee the booking policy charges for it, if any; see applyCancellationPolicy.
+func prepareCancellation(booking *models.Booking, reason *string, acceptLateFee bool) error {
+	if reason == nil {
+		return fmt.Errorf("%w: cancellation_reason is required to cancel a booking, one of %v", ErrBookingValidation, models.CancellationReasons)
+	}
+	if !models.IsValidCancellationReason(*reason) {
+		return fmt.Errorf("%w: invalid cancellation reason '%s'", ErrBookingValidation, *reason)
+	}
+	booking.CancellationReason = reason
+	return applyCancellationPolicy(booking, acceptLateFee, utils.NowUTC())
+}
+
+// saveBooking updates the booking, records how it changed from before in its history
+// and, if its status changed, publishes the status event, all in one transaction.
+func (s *bookingService) saveBooking(booking *models.Booking, before *models.Booking, change bookingChange) (*models.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updatedBooking, err := s.saveBookingTx(tx, booking, before, change)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit booking transaction: %w", err)
+	}
+	return updatedBooking, nil
+}
+
+// saveBookingTx is saveBooking within the caller's transaction.
+func (s *bookingService) saveBookingTx(tx *sql.Tx, booking *models.Booking, before *models.Booking, change bookingChange) (*models.Booking, error) {
+	updatedBooking, err := s.bookingRepo.UpdateBooking(tx, booking)
+	if err != nil {
+		if errors.Is(err, repositories.ErrTableNotAvailable) {
+			return nil, ErrTableNotAvailable
+		}
+		return nil, err
+	}
+	if changes := bookingChanges(before, updatedBooking, change); len(changes) > 0 {
+		if err := s.bookingRepo.CreateBookingChanges(tx, changes); err != nil {
+			return nil, fmt.Errorf("failed to record booking history: %w", err)
+		}
+	}
+	if booking.Status != before.Status {
+		payload := events.NewBookingPayload(booking, before.Status)
+		if err := s.publisher.Publish(tx, events.BookingStatusEvent(booking.Status), events.AggregateBooking, booking.ID, payload); err != nil {
+			return nil, err
+		}
+	}
+	return updatedBooking, nil
+}
+
+// bookingChanges lists the changes of time, table and status from before to after, for the booking history.
+func bookingChanges(before, after *models.Booking, change bookingChange) []models.BookingChange {
+	var changes []models.BookingChange
+	entry := func(changeType, oldValue, newValue string) models.BookingChange {
+		return models.BookingChange{
+			BookingID:  after.ID,
+			ChangeType: changeType,
+			OldValue:   &oldValue,
+			NewValue:   &newValue,
+			Reason:     change.reason,
+			ChangedBy:  &change.changedBy,
+			ChangedAt:  after.UpdatedAt,
+		}
+	}
+	if !before.StartTime.Equal(after.StartTime) || !before.EndTime.Equal(after.EndTime) {
+		changes = append(changes, entry(models.BookingChangeRescheduled, bookingPeriod(before), bookingPeriod(after)))
+	}
+	if before.TableID != after.TableID {
+		changes = append(changes, entry(models.BookingChangeTableMoved, strconv.FormatInt(before.TableID, 10), strconv.FormatInt(after.TableID, 10)))
+	}
+	if before.Status != after.Status {
+		statusChange := entry(models.BookingChangeStatus, before.Status, after.Status)
+		if after.Status == string(models.BookingStatusCancelled) {
+			statusChange.CancellationReason = after.CancellationReason
+		}
+		changes = append(changes, statusChange)
+	}
+	return changes
 }
 
-func (s *bookingService) CompleteBooking(bookingID int64) (*models.Booking, error) {
-	return s.updateBookingStatus(bookingID, models.BookingStatusCompleted)
+// bookingPeriod formats the period of a booking as an RFC 3339 interval, "start/end".
+func bookingPeriod(booking *models.Booking) string {
+	return booking.StartTime.UTC().Format(time.RFC3339) + "/" + booking.EndTime.UTC().Format(time.RFC3339)
 }
 
-func (s *bookingService) DeleteBooking(bookingID int64) error {
+func (s *bookingService) CancelBooking(bookingID int64, req CancelBookingRequest, changedBy int64) (*models.Booking, error) {
+	return s.updateBookingStatus(bookingID, string(models.BookingStatusCancelled), &req, bookingChange{changedBy: changedBy, reason: req.Reason})
+}
+
+func (s *bookingService) CompleteBooking(bookingID int64, changedBy int64) (*models.Booking, error) {
+	return s.updateBookingStatus(bookingID, string(models.BookingStatusCompleted), nil, bookingChange{changedBy: changedBy})
+}
+
+func (s *bookingService) CancelClientBookings(clientID int64, bookingIDs []int64, req CancelBookingRequest, changedBy int64) (*BulkResult, error) {
+	if !models.IsValidCancellationReason(req.CancellationReason) {
+		return nil, fmt.Errorf("%w: invalid cancellation reason '%s'", ErrBookingValidation, req.CancellationReason)
+	}
+	if _, err := s.clientRepo.GetClientByID(clientID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to find client: %w", err)
+	}
+	if len(bookingIDs) == 0 {
+		var err error
+		if bookingIDs, err = s.upcomingBookingIDs(clientID); err != nil {
+			return nil, err
+		}
+	}
+
+	cancelled := string(models.BookingStatusCancelled)
+	return runBulk(s.db, bookingIDs, func(tx *sql.Tx, bookingID int64) error {
+		booking, err := s.bookingRepo.GetBookingByID(bookingID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrBookingNotFound
+			}
+			return fmt.Errorf("failed to find booking to cancel: %w", err)
+		}
+		if booking.ClientID == nil || *booking.ClientID != clientID {
+			return fmt.Errorf("%w: booking belongs to another client", ErrBookingValidation)
+		}
+		if booking.Status == cancelled {
+			return fmt.Errorf("%w: booking is already cancelled", ErrBookingStatusUpdate)
+		}
+		if err := checkBookingStatusChange(booking, cancelled); err != nil {
+			return err
+		}
+		before := *booking
+		booking.Status = cancelled
+		if err := prepareCancellation(booking, &req.CancellationReason, req.AcceptLateFee); err != nil {
+			return err
+		}
+		if _, err := s.saveBookingTx(tx, booking, &before, bookingChange{changedBy: changedBy, reason: req.Reason}); err != nil {
+			if errors.Is(err, repositories.ErrVersionConflict) {
+				return ErrVersionConflict
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// upcomingBookingIDs returns the IDs of the client's pending and confirmed bookings that have not started yet.
+func (s *bookingService) upcomingBookingIDs(clientID int64) ([]int64, error) {
+	now := time.Now()
+	var ids []int64
+	seen := 0
+	for page := 1; ; page++ {
+		bookings, total, err := s.bookingRepo.GetBookings(models.BookingFilters{ClientID: &clientID, Page: page, PageSize: bulkPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bookings of client %d: %w", clientID, err)
+		}
+		for _, booking := range bookings {
+			open := booking.Status == string(models.BookingStatusPending) || booking.Status == string(models.BookingStatusConfirmed)
+			if open && booking.StartTime.After(now) {
+				ids = append(ids, booking.ID)
+			}
+		}
+		seen += len(bookings)
+		if len(bookings) == 0 || seen >= total {
+			break
+		}
+	}
+	return ids, nil
+}
+
+func (s *bookingService) DeleteBooking(bookingID int64, deletedBy int64) error {
 	_, err := s.bookingRepo.GetBookingByID(bookingID) 
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -313,7 +802,9 @@ func (s *bookingService) DeleteBooking(bookingID int64) error {
 		}
 		return fmt.Errorf("failed to find booking for deletion: %w", err)
 	}
-	err = s.bookingRepo.DeleteBooking(s.db, bookingID)
+	err = softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordDeleted, ReferenceEntityBookings, bookingID, deletedBy, func(tx *sql.Tx, now time.Time) error {
+		return s.bookingRepo.DeleteBooking(tx, bookingID, deletedBy, now)
+	})
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) { 
 			return ErrBookingNotFound
@@ -322,3 +813,33 @@ func (s *bookingService) DeleteBooking(bookingID int64) error {
 	}
 	return nil
 }
+
+func (s *bookingService) RestoreBooking(bookingID int64, restoredBy int64) (*models.Booking, error) {
+	err := softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordRestored, ReferenceEntityBookings, bookingID, restoredBy, func(tx *sql.Tx, now time.Time) error {
+		return s.bookingRepo.RestoreBooking(tx, bookingID, now)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrBookingNotDeleted
+		}
+		if errors.Is(err, repositories.ErrTableNotAvailable) {
+			return nil, ErrTableNotAvailable
+		}
+		return nil, fmt.Errorf("failed to restore booking: %w", err)
+	}
+	return s.GetBookingByID(bookingID)
+}
+
+func (s *bookingService) GetBookingHistory(bookingID int64) ([]models.BookingChange, error) {
+	if _, err := s.bookingRepo.GetBookingByID(bookingID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, fmt.Errorf("failed to find booking: %w", err)
+	}
+	changes, err := s.bookingRepo.GetBookingChanges(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking history: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/repositories/mocks"
+)
+
+// bookingTestRecorder records what the booking service wrote through the mocked repositories.
+type bookingTestRecorder struct {
+	created         *models.Booking
+	updated         *models.Booking
+	availabilityFor []time.Time // Start and end of each availability check
+	bufferMinutes   int
+}
+
+// newTestBookingService returns a booking service over the existing booking (nil for none)
+// and a table with an hourly rate of 2000. available is what the availability check answers.
+func newTestBookingService(t *testing.T, existing *models.Booking, available bool) (BookingService, *bookingTestRecorder) {
+	t.Helper()
+	rec := &bookingTestRecorder{}
+	rate := models.NewMoneyFromInt(2000)
+	blacklistReason := "unpaid tab"
+
+	bookingRepo := &mocks.MockBookingRepository{
+		GetGameTableByIDFunc: func(id int64) (*models.GameTable, error) {
+			if id != 1 {
+				return nil, repositories.ErrNotFound
+			}
+			return &models.GameTable{ID: 1, Name: "PS5 #1", HourlyRate: &rate}, nil
+		},
+		CheckTableAvailabilityFunc: func(_ int64, start, end time.Time, bufferMinutes int, _ *int64) (bool, error) {
+			rec.availabilityFor = append(rec.availabilityFor, start, end)
+			rec.bufferMinutes = bufferMinutes
+			return available, nil
+		},
+		CreateBookingFunc: func(_ repositories.SQLExecutor, booking *models.Booking) (*models.Booking, error) {
+			created := *booking
+			created.ID = 10
+			rec.created = &created
+			return &created, nil
+		},
+		UpdateBookingFunc: func(_ repositories.SQLExecutor, booking *models.Booking) (*models.Booking, error) {
+			updated := *booking
+			rec.updated = &updated
+			return &updated, nil
+		},
+		CreateBookingChangesFunc: func(repositories.SQLExecutor, []models.BookingChange) error { return nil },
+		GetBookingByIDFunc: func(id int64) (*models.Booking, error) {
+			switch {
+			case rec.updated != nil && rec.updated.ID == id:
+				booking := *rec.updated
+				return &booking, nil
+			case rec.created != nil && rec.created.ID == id:
+				booking := *rec.created
+				return &booking, nil
+			case existing != nil && existing.ID == id:
+				booking := *existing
+				return &booking, nil
+			}
+			return nil, repositories.ErrNotFound
+		},
+	}
+	clientRepo := &mocks.MockClientRepository{
+		GetClientByIDFunc: func(id int64) (*models.Client, error) {
+			switch id {
+			case 1:
+				return &models.Client{ID: 1}, nil
+			case 2:
+				return &models.Client{ID: 2, Blacklisted: true, BlacklistReason: &blacklistReason}, nil
+			}
+			return nil, repositories.ErrNotFound
+		},
+	}
+	staffRepo := &mocks.MockStaffRepository{
+		GetStaffMemberByIDFunc: func(id int64) (*models.StaffMember, error) {
+			if id != 7 {
+				return nil, repositories.ErrNotFound
+			}
+			return &models.StaffMember{ID: 7}, nil
+		},
+	}
+	lockerRepo := &mocks.MockLockerRepository{
+		GetRentalsFunc: func(models.LockerRentalFilters) ([]models.LockerRental, error) { return nil, nil },
+	}
+	outboxRepo := &mocks.MockOutboxRepository{
+		CreateEventFunc: func(repositories.SQLExecutor, *models.DomainEvent) (int64, error) { return 1, nil },
+	}
+
+	service := NewBookingService(bookingRepo, clientRepo, staffRepo, lockerRepo, &mocks.MockGameTableRepository{},
+		&mocks.MockAuditLogRepository{}, newTxDB(t), kvstore.NewMemoryStore(), events.NewPublisher(outboxRepo))
+	return service, rec
+}
+
+// useBookingPolicy sets the booking policy for the test and restores the default after it.
+func useBookingPolicy(t *testing.T, policy models.BookingPolicy) {
+	t.Helper()
+	SetBookingPolicy(policy)
+	t.Cleanup(func() { SetBookingPolicy(models.BookingPolicy{}) })
+}
+
+func TestBookingServiceCreateBooking(t *testing.T) {
+	useBookingPolicy(t, models.BookingPolicy{MinLeadMinutes: 120, BufferMinutes: 15})
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	at := func(d time.Duration) string { return start.Add(d).Format(time.RFC3339) }
+	clientID, unknownClientID, blacklistedClientID := int64(1), int64(99), int64(2)
+
+	tests := []struct {
+		name      string
+		req       CreateBookingRequest
+		available bool
+		wantErr   error
+		wantPrice int64
+	}{
+		{
+			name:      "books a free table",
+			req:       CreateBookingRequest{ClientID: &clientID, TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(90 * time.Minute)},
+			available: true,
+			wantPrice: 3000,
+		},
+		{
+			name:    "rejects a booking overlapping another",
+			req:     CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(time.Hour)},
+			wantErr: ErrTableNotAvailable,
+		},
+		{
+			name:      "rejects an end before the start",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: at(time.Hour), EndTime: at(0)},
+			available: true,
+			wantErr:   ErrInvalidBookingTime,
+		},
+		{
+			name:      "rejects a booking shorter than 15 minutes",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(10 * time.Minute)},
+			available: true,
+			wantErr:   ErrInvalidBookingTime,
+		},
+		{
+			name:      "rejects a booking longer than 12 hours",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(13 * time.Hour)},
+			available: true,
+			wantErr:   ErrInvalidBookingTime,
+		},
+		{
+			name:      "rejects a start in the past",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: at(-72 * time.Hour), EndTime: at(-71 * time.Hour)},
+			available: true,
+			wantErr:   ErrInvalidBookingTime,
+		},
+		{
+			name:      "rejects a time that is not a date-time",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 7, StartTime: "tomorrow", EndTime: at(time.Hour)},
+			available: true,
+			wantErr:   ErrInvalidBookingTime,
+		},
+		{
+			name: "rejects an online booking inside the minimum notice",
+			req: CreateBookingRequest{
+				TableID: 1, Source: models.OrderSourceClientApp,
+				StartTime: time.Now().UTC().Add(time.Hour).Format(time.RFC3339), EndTime: time.Now().UTC().Add(2 * time.Hour).Format(time.RFC3339),
+			},
+			available: true,
+			wantErr:   ErrBookingNoticeTooShort,
+		},
+		{
+			name:      "rejects an unknown client",
+			req:       CreateBookingRequest{ClientID: &unknownClientID, TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(time.Hour)},
+			available: true,
+			wantErr:   ErrClientForBookingNotFound,
+		},
+		{
+			name:      "rejects a blacklisted client",
+			req:       CreateBookingRequest{ClientID: &blacklistedClientID, TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(time.Hour)},
+			available: true,
+			wantErr:   ErrClientBlacklisted,
+		},
+		{
+			name: "books a blacklisted client a manager approved",
+			req: CreateBookingRequest{
+				ClientID: &blacklistedClientID, TableID: 1, StaffID: 7, StartTime: at(0), EndTime: at(time.Hour),
+				BlacklistOverrideApproved: true,
+			},
+			available: true,
+			wantPrice: 2000,
+		},
+		{
+			name:      "rejects an unknown staff member",
+			req:       CreateBookingRequest{TableID: 1, StaffID: 8, StartTime: at(0), EndTime: at(time.Hour)},
+			available: true,
+			wantErr:   ErrStaffForBookingNotFound,
+		},
+		{
+			name:      "rejects an unknown table",
+			req:       CreateBookingRequest{TableID: 2, StaffID: 7, StartTime: at(0), EndTime: at(time.Hour)},
+			available: true,
+			wantErr:   ErrTableForBookingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, rec := newTestBookingService(t, nil, tt.available)
+
+			booking, err := service.CreateBooking(tt.req, 1)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateBooking error = %v, want %v", err, tt.wantErr)
+				}
+				if rec.created != nil {
+					t.Errorf("CreateBooking wrote booking %+v despite failing", rec.created)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateBooking: %v", err)
+			}
+			if booking.Status != string(models.BookingStatusConfirmed) {
+				t.Errorf("Status = %s, want %s", booking.Status, models.BookingStatusConfirmed)
+			}
+			if want := models.NewMoneyFromInt(tt.wantPrice); booking.TotalPrice == nil || booking.TotalPrice.Cmp(want) != 0 {
+				t.Errorf("TotalPrice = %v, want %s", booking.TotalPrice, want)
+			}
+			if booking.CheckInToken == "" {
+				t.Error("the booking has no check-in token")
+			}
+			if rec.bufferMinutes != 15 {
+				t.Errorf("availability checked with a %d minute buffer, want the policy's 15", rec.bufferMinutes)
+			}
+			if rec.created.BufferMinutes != 15 {
+				t.Errorf("booking made with a %d minute buffer, want the policy's 15", rec.created.BufferMinutes)
+			}
+		})
+	}
+}
+
+func TestBookingServiceUpdateBooking(t *testing.T) {
+	useBookingPolicy(t, models.BookingPolicy{BufferMinutes: 15})
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	booking := func(status string) *models.Booking {
+		return &models.Booking{ID: 5, TableID: 1, Status: status, StartTime: start, EndTime: start.Add(time.Hour), Version: 3, BufferMinutes: 5}
+	}
+	newEnd := start.Add(2 * time.Hour).Format(time.RFC3339)
+	staleVersion := 2
+	cancelled := string(models.BookingStatusCancelled)
+	reason := models.CancellationReasonClientRequest
+
+	tests := []struct {
+		name       string
+		existing   *models.Booking
+		req        UpdateBookingRequest
+		available  bool
+		wantErr    error
+		wantBuffer int
+	}{
+		{
+			name:       "reschedules onto free time with the current buffer",
+			existing:   booking(string(models.BookingStatusConfirmed)),
+			req:        UpdateBookingRequest{EndTime: &newEnd},
+			available:  true,
+			wantBuffer: 15,
+		},
+		{
+			name:     "rejects rescheduling onto another booking",
+			existing: booking(string(models.BookingStatusConfirmed)),
+			req:      UpdateBookingRequest{EndTime: &newEnd},
+			wantErr:  ErrTableNotAvailable,
+		},
+		{
+			name:      "rejects a stale version",
+			existing:  booking(string(models.BookingStatusConfirmed)),
+			req:       UpdateBookingRequest{EndTime: &newEnd, Version: &staleVersion},
+			available: true,
+			wantErr:   ErrVersionConflict,
+		},
+		{
+			name:      "rejects changing a completed booking",
+			existing:  booking(string(models.BookingStatusCompleted)),
+			req:       UpdateBookingRequest{EndTime: &newEnd},
+			available: true,
+			wantErr:   ErrBookingValidation,
+		},
+		{
+			name:      "rejects cancelling without a reason",
+			existing:  booking(string(models.BookingStatusConfirmed)),
+			req:       UpdateBookingRequest{Status: &cancelled},
+			available: true,
+			wantErr:   ErrBookingValidation,
+		},
+		{
+			name:       "cancels with a reason, keeping the buffer",
+			existing:   booking(string(models.BookingStatusConfirmed)),
+			req:        UpdateBookingRequest{Status: &cancelled, CancellationReason: &reason},
+			available:  true,
+			wantBuffer: 5,
+		},
+		{
+			name:    "rejects an unknown booking",
+			req:     UpdateBookingRequest{EndTime: &newEnd},
+			wantErr: ErrBookingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, rec := newTestBookingService(t, tt.existing, tt.available)
+
+			_, err := service.UpdateBooking(5, tt.req, 1)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateBooking error = %v, want %v", err, tt.wantErr)
+				}
+				if rec.updated != nil {
+					t.Errorf("UpdateBooking wrote booking %+v despite failing", rec.updated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateBooking: %v", err)
+			}
+			if rec.updated == nil {
+				t.Fatal("UpdateBooking did not write the booking")
+			}
+			if rec.updated.BufferMinutes != tt.wantBuffer {
+				t.Errorf("BufferMinutes = %d, want %d", rec.updated.BufferMinutes, tt.wantBuffer)
+			}
+		})
+	}
+}
+
+func TestBookingServiceCancelBookingLateFee(t *testing.T) {
+	fee := models.NewMoneyFromInt(1000)
+	useBookingPolicy(t, models.BookingPolicy{FreeCancellationHours: 24, LateCancellationFee: &fee})
+	start := time.Now().UTC().Add(3 * time.Hour).Truncate(time.Minute)
+	existing := &models.Booking{ID: 5, TableID: 1, Status: string(models.BookingStatusConfirmed), StartTime: start, EndTime: start.Add(time.Hour)}
+
+	tests := []struct {
+		name    string
+		req     CancelBookingRequest
+		wantErr error
+		wantFee *models.Money
+	}{
+		{
+			name:    "asks to accept the fee of a late cancellation",
+			req:     CancelBookingRequest{CancellationReason: models.CancellationReasonClientRequest},
+			wantErr: ErrLateCancellationFee,
+		},
+		{
+			name:    "charges the fee once accepted",
+			req:     CancelBookingRequest{CancellationReason: models.CancellationReasonClientRequest, AcceptLateFee: true},
+			wantFee: &fee,
+		},
+		{
+			name: "charges no fee when the club cancels",
+			req:  CancelBookingRequest{CancellationReason: models.CancellationReasonEquipmentIssue},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, rec := newTestBookingService(t, existing, true)
+
+			_, err := service.CancelBooking(existing.ID, tt.req, 1)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CancelBooking error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CancelBooking: %v", err)
+			}
+			if rec.updated.Status != string(models.BookingStatusCancelled) {
+				t.Errorf("Status = %s, want cancelled", rec.updated.Status)
+			}
+			got := rec.updated.CancellationFee
+			if (got == nil) != (tt.wantFee == nil) || (got != nil && got.Cmp(*tt.wantFee) != 0) {
+				t.Errorf("CancellationFee = %v, want %v", got, tt.wantFee)
+			}
+		})
+	}
+}
+
+func TestBookingServiceCompletedBookingKeepsItsStatus(t *testing.T) {
+	start := time.Now().UTC().Add(-2 * time.Hour)
+	existing := &models.Booking{ID: 5, TableID: 1, Status: string(models.BookingStatusCompleted), StartTime: start, EndTime: start.Add(time.Hour)}
+	service, rec := newTestBookingService(t, existing, true)
+
+	_, err := service.CancelBooking(existing.ID, CancelBookingRequest{CancellationReason: models.CancellationReasonOther}, 1)
+	if !errors.Is(err, ErrBookingStatusUpdate) {
+		t.Fatalf("CancelBooking error = %v, want %v", err, ErrBookingStatusUpdate)
+	}
+	if rec.updated != nil {
+		t.Errorf("CancelBooking wrote booking %+v despite failing", rec.updated)
+	}
+}
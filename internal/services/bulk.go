@@ -0,0 +1,99 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+// MaxBulkSize is the most entities one bulk request may change.
+const MaxBulkSize = 500
+
+// bulkPageSize is the page size used to collect the entities a bulk request selects by filter.
+const bulkPageSize = 100
+
+// ErrBulkTooLarge is returned when a bulk request selects more than MaxBulkSize entities.
+var ErrBulkTooLarge = apperrors.New(utils.ErrCodeValidationFailed, fmt.Sprintf("a bulk request may change at most %d entities", MaxBulkSize))
+
+// Outcomes of an entity in a bulk request.
+const (
+	BulkStatusSucceeded = "succeeded"
+	BulkStatusFailed    = "failed"
+)
+
+// BulkItemResult reports the outcome of one entity of a bulk request.
+type BulkItemResult struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult reports the outcome of a bulk request, entity by entity.
+type BulkResult struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// runBulk applies apply to each entity in ids within one transaction. Each entity
+// runs in a savepoint: if apply fails, its changes to that entity are rolled back and
+// the failure is reported, while the other entities are still committed. A database
+// error aborts the whole request, so nothing is committed.
+func runBulk(db *sql.DB, ids []int64, apply func(tx *sql.Tx, id int64) error) (*BulkResult, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) > MaxBulkSize {
+		return nil, ErrBulkTooLarge
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &BulkResult{Results: make([]BulkItemResult, 0, len(ids))}
+	for _, id := range ids {
+		if _, err := tx.Exec("SAVEPOINT bulk_entity"); err != nil {
+			return nil, fmt.Errorf("%w: creating savepoint: %v", repositories.ErrDatabaseError, err)
+		}
+		applyErr := apply(tx, id)
+		if applyErr == nil {
+			if _, err := tx.Exec("RELEASE SAVEPOINT bulk_entity"); err != nil {
+				return nil, fmt.Errorf("%w: releasing savepoint: %v", repositories.ErrDatabaseError, err)
+			}
+			result.Succeeded++
+			result.Results = append(result.Results, BulkItemResult{ID: id, Status: BulkStatusSucceeded})
+			continue
+		}
+		if errors.Is(applyErr, repositories.ErrDatabaseError) {
+			return nil, fmt.Errorf("failed to process entity %d: %w", id, applyErr)
+		}
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT bulk_entity"); err != nil {
+			return nil, fmt.Errorf("%w: rolling back to savepoint: %v", repositories.ErrDatabaseError, err)
+		}
+		result.Failed++
+		result.Results = append(result.Results, BulkItemResult{ID: id, Status: BulkStatusFailed, Error: applyErr.Error()})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk transaction: %w", err)
+	}
+	return result, nil
+}
+
+// uniqueIDs returns ids without duplicates, keeping the first occurrence of each.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
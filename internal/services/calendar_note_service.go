@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrCalendarNoteNotFound   = apperrors.New(utils.ErrCodeNotFound, "calendar note not found")
+	ErrCalendarNoteValidation = apperrors.New(utils.ErrCodeValidationFailed, "calendar note validation error")
+)
+
+// MaxCalendarNoteDays bounds the dates a note covers, and those listed at once.
+const MaxCalendarNoteDays = 366
+
+// CalendarNoteRequest is the body of POST /calendar-notes and PUT /calendar-notes/:id, e.g.
+// {"start_date": "2025-03-21", "end_date": "2025-03-23", "category": "public_holiday", "title": "Nauryz"}.
+type CalendarNoteRequest struct {
+	// BranchCode limits the note to a branch; left out, it applies to every branch. It cannot
+	// be changed by PUT.
+	BranchCode *string `json:"branch_code"`
+	StartDate  string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate    string  `json:"end_date"`                      // Inclusive; defaults to StartDate
+	Category   string  `json:"category" binding:"required,note_category"`
+	Title      string  `json:"title" binding:"required,max=200"`
+	Note       *string `json:"note"`
+}
+
+// --- CalendarNoteService Interface ---
+type CalendarNoteService interface {
+	CreateNote(req CalendarNoteRequest, createdBy int64) (*models.CalendarNote, error)
+	// GetNotes lists the notes of this branch, including those of every branch, that overlap
+	// fromDate to toDate (YYYY-MM-DD, inclusive); empty dates default to the current month.
+	GetNotes(fromDate, toDate string) ([]models.CalendarNote, error)
+	UpdateNote(id int64, req CalendarNoteRequest) (*models.CalendarNote, error)
+	DeleteNote(id int64) error
+}
+
+type calendarNoteService struct {
+	noteRepo repositories.CalendarNoteRepository
+}
+
+// NewCalendarNoteService creates a new CalendarNoteService.
+func NewCalendarNoteService(noteRepo repositories.CalendarNoteRepository) CalendarNoteService {
+	return &calendarNoteService{noteRepo: noteRepo}
+}
+
+// calendarNoteError converts the errors of the calendar note repository.
+func calendarNoteError(err error, action string) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCalendarNoteNotFound
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// checkNoteDates validates the dates of a calendar note or of a listing, defaulting the end
+// to the start.
+func checkNoteDates(startDate, endDate string) (string, string, error) {
+	if endDate == "" {
+		endDate = startDate
+	}
+	start, err := utils.ParseClubDate(startDate)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrCalendarNoteValidation)
+	}
+	end, err := utils.ParseClubDate(endDate)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrCalendarNoteValidation)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("%w: the end date is before the start date", ErrCalendarNoteValidation)
+	}
+	if end.AddDate(0, 0, -MaxCalendarNoteDays).After(start) {
+		return "", "", fmt.Errorf("%w: dates span at most %d days", ErrCalendarNoteValidation, MaxCalendarNoteDays)
+	}
+	return startDate, endDate, nil
+}
+
+// applyNoteRequest validates req and sets it on note, except the branch.
+func applyNoteRequest(note *models.CalendarNote, req CalendarNoteRequest) error {
+	startDate, endDate, err := checkNoteDates(req.StartDate, req.EndDate)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(models.CalendarNoteCategories, req.Category) {
+		return fmt.Errorf("%w: category must be one of %v", ErrCalendarNoteValidation, models.CalendarNoteCategories)
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("%w: title is required", ErrCalendarNoteValidation)
+	}
+	note.StartDate, note.EndDate = startDate, endDate
+	note.Category = req.Category
+	note.Title = title
+	note.Note = req.Note
+	return nil
+}
+
+func (s *calendarNoteService) CreateNote(req CalendarNoteRequest, createdBy int64) (*models.CalendarNote, error) {
+	if req.BranchCode != nil && !utils.IsValidBranchCode(*req.BranchCode) {
+		return nil, fmt.Errorf("%w: invalid branch_code %q", ErrCalendarNoteValidation, *req.BranchCode)
+	}
+	note := &models.CalendarNote{BranchCode: req.BranchCode, CreatedBy: &createdBy}
+	if err := applyNoteRequest(note, req); err != nil {
+		return nil, err
+	}
+	if err := s.noteRepo.CreateNote(note); err != nil {
+		return nil, calendarNoteError(err, "create calendar note")
+	}
+	return note, nil
+}
+
+func (s *calendarNoteService) GetNotes(fromDate, toDate string) ([]models.CalendarNote, error) {
+	if fromDate == "" && toDate == "" {
+		now := utils.NowInClub()
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		fromDate, toDate = first.Format(utils.DateLayout), first.AddDate(0, 1, -1).Format(utils.DateLayout)
+	} else if fromDate == "" {
+		fromDate = toDate
+	}
+	fromDate, toDate, err := checkNoteDates(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.noteRepo.GetNotes(utils.BranchCode(), fromDate, toDate)
+	if err != nil {
+		return nil, calendarNoteError(err, "get calendar notes")
+	}
+	return notes, nil
+}
+
+func (s *calendarNoteService) UpdateNote(id int64, req CalendarNoteRequest) (*models.CalendarNote, error) {
+	note, err := s.noteRepo.GetNoteByID(id)
+	if err != nil {
+		return nil, calendarNoteError(err, "get calendar note")
+	}
+	if err := applyNoteRequest(note, req); err != nil {
+		return nil, err
+	}
+	if err := s.noteRepo.UpdateNote(note); err != nil {
+		return nil, calendarNoteError(err, "update calendar note")
+	}
+	return note, nil
+}
+
+func (s *calendarNoteService) DeleteNote(id int64) error {
+	if err := s.noteRepo.DeleteNote(id); err != nil {
+		return calendarNoteError(err, "delete calendar note")
+	}
+	return nil
+}
+
+// notesCovering returns the notes among notes that cover date (YYYY-MM-DD).
+func notesCovering(notes []models.CalendarNote, date string) []models.CalendarNote {
+	var covering []models.CalendarNote
+	for _, note := range notes {
+		if note.Covers(date) {
+			covering = append(covering, note)
+		}
+	}
+	return covering
+}
+
+// appendNotes appends the notes not in list yet to it.
+func appendNotes(list []models.CalendarNote, notes []models.CalendarNote) []models.CalendarNote {
+	for _, note := range notes {
+		if !slices.ContainsFunc(list, func(listed models.CalendarNote) bool { return listed.ID == note.ID }) {
+			list = append(list, note)
+		}
+	}
+	return list
+}
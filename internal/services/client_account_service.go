@@ -0,0 +1,292 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrClientAccountNotFound   = apperrors.New(utils.ErrCodeNotFound, "the client has no house account")
+	ErrClientAccountInactive   = apperrors.New(utils.ErrCodeConflict, "the house account is inactive")
+	ErrClientAccountOverdue    = apperrors.New(utils.ErrCodeConflict, "the house account has overdue charges, new charges are blocked until they are paid")
+	ErrCreditLimitExceeded     = apperrors.New(utils.ErrCodeConflict, "the charge exceeds the credit limit of the house account")
+	ErrClientAccountValidation = apperrors.New(utils.ErrCodeValidationFailed, "house account validation error")
+)
+
+// DefaultPaymentTermsDays is how long a house account charge is due after, unless the account sets it.
+const DefaultPaymentTermsDays = 30
+
+// ClientAccountRequest is the body of PUT /clients/:id/account, which opens the house account
+// of a client or changes it.
+type ClientAccountRequest struct {
+	CreditLimit      *models.Money `json:"credit_limit" binding:"required,money"`
+	PaymentTermsDays *int          `json:"payment_terms_days" binding:"omitempty,min=0"` // Defaults to DefaultPaymentTermsDays
+	IsActive         *bool         `json:"is_active"`                                    // Defaults to true
+	Version          *int          `json:"version"`                                      // Checked on update if set
+}
+
+// AccountPaymentRequest is the body of POST /clients/:id/account/payments.
+type AccountPaymentRequest struct {
+	Amount        models.Money `json:"amount" binding:"required,gt=0"`
+	PaymentMethod string       `json:"payment_method" binding:"required,max=50"` // How the client paid, e.g. cash; not house_account
+	Notes         *string      `json:"notes"`
+}
+
+// --- ClientAccountService Interface ---
+type ClientAccountService interface {
+	// SaveAccount opens the house account of a client, or changes the existing one.
+	SaveAccount(clientID int64, req ClientAccountRequest) (*models.ClientAccount, error)
+	GetAccount(clientID int64) (*models.ClientAccount, error)
+	GetAccounts(overdueOnly bool) ([]models.ClientAccount, error)
+	// GetStatement lists the entries of the account in [from, to); from defaults to the start
+	// of the month and to to the end of today, in the club timezone.
+	GetStatement(clientID int64, from, to *time.Time) (*models.ClientAccountStatement, error)
+	// RecordPayment records a payment against the balance. Paying more than the balance
+	// leaves a credit that later charges use up.
+	RecordPayment(clientID int64, req AccountPaymentRequest, receivedBy int64) (*models.ClientAccountEntry, error)
+}
+
+type clientAccountService struct {
+	accountRepo repositories.ClientAccountRepository
+	clientRepo  repositories.ClientRepository
+	db          *sql.DB
+}
+
+// NewClientAccountService creates a new ClientAccountService.
+func NewClientAccountService(accountRepo repositories.ClientAccountRepository, clientRepo repositories.ClientRepository, db *sql.DB) ClientAccountService {
+	return &clientAccountService{accountRepo: accountRepo, clientRepo: clientRepo, db: db}
+}
+
+func (s *clientAccountService) SaveAccount(clientID int64, req ClientAccountRequest) (*models.ClientAccount, error) {
+	if req.CreditLimit == nil || req.CreditLimit.IsNegative() {
+		return nil, fmt.Errorf("%w: credit_limit cannot be negative", ErrClientAccountValidation)
+	}
+	client, err := s.clientRepo.GetClientByID(clientID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := utils.NowUTC()
+	account, err := s.accountRepo.GetAccount(tx, clientID, now, true)
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		if client.AnonymizedAt != nil {
+			return nil, ErrClientAnonymized
+		}
+		account = &models.ClientAccount{ClientID: clientID, PaymentTermsDays: DefaultPaymentTermsDays, IsActive: true}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get house account: %w", err)
+	}
+
+	account.CreditLimit = *req.CreditLimit
+	if req.PaymentTermsDays != nil {
+		if *req.PaymentTermsDays < 0 {
+			return nil, fmt.Errorf("%w: payment_terms_days cannot be negative", ErrClientAccountValidation)
+		}
+		account.PaymentTermsDays = *req.PaymentTermsDays
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+	if account.Version == 0 {
+		err = s.accountRepo.CreateAccount(tx, account)
+	} else {
+		version := 0
+		if req.Version != nil {
+			version = *req.Version
+		}
+		err = s.accountRepo.UpdateAccount(tx, account, version)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrClientAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to save house account: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetAccount(clientID)
+}
+
+func (s *clientAccountService) GetAccount(clientID int64) (*models.ClientAccount, error) {
+	account, err := s.accountRepo.GetAccount(s.db, clientID, utils.NowUTC(), false)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get house account: %w", err)
+	}
+	return account, nil
+}
+
+func (s *clientAccountService) GetAccounts(overdueOnly bool) ([]models.ClientAccount, error) {
+	accounts, err := s.accountRepo.GetAccounts(utils.NowUTC(), overdueOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get house accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+func (s *clientAccountService) GetStatement(clientID int64, from, to *time.Time) (*models.ClientAccountStatement, error) {
+	account, err := s.GetAccount(clientID)
+	if err != nil {
+		return nil, err
+	}
+	now := utils.NowUTC()
+	statement := &models.ClientAccountStatement{Account: *account, From: utils.StartOfMonth(now).UTC()}
+	_, statement.To = utils.DayBounds(now)
+	if from != nil {
+		statement.From = *from
+	}
+	if to != nil {
+		statement.To = *to
+	}
+	if !statement.From.Before(statement.To) {
+		return nil, fmt.Errorf("%w: the statement period must end after it starts", ErrClientAccountValidation)
+	}
+
+	statement.OpeningBalance, err = s.accountRepo.GetBalanceAt(clientID, statement.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+	statement.Entries, err = s.accountRepo.GetEntries(clientID, statement.From, statement.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get house account entries: %w", err)
+	}
+	statement.ClosingBalance = statement.OpeningBalance
+	for _, entry := range statement.Entries {
+		if entry.EntryType == models.AccountEntryCharge {
+			statement.Charges = statement.Charges.Add(entry.Amount)
+		} else {
+			statement.Credits = statement.Credits.Sub(entry.Amount)
+		}
+		statement.ClosingBalance = statement.ClosingBalance.Add(entry.Amount)
+	}
+	return statement, nil
+}
+
+func (s *clientAccountService) RecordPayment(clientID int64, req AccountPaymentRequest, receivedBy int64) (*models.ClientAccountEntry, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrClientAccountValidation)
+	}
+	if req.PaymentMethod == models.PaymentMethodHouseAccount {
+		return nil, fmt.Errorf("%w: a house account cannot be paid from a house account", ErrClientAccountValidation)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := s.accountRepo.GetAccount(tx, clientID, utils.NowUTC(), true); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get house account: %w", err)
+	}
+	entry := &models.ClientAccountEntry{
+		ClientID:      clientID,
+		EntryType:     models.AccountEntryPayment,
+		Amount:        req.Amount.Neg(),
+		PaymentMethod: &req.PaymentMethod,
+		Notes:         req.Notes,
+		CreatedBy:     &receivedBy,
+	}
+	if err := s.accountRepo.CreateEntry(tx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return entry, nil
+}
+
+// chargeClientAccount charges order, paid with the house_account method, to the house account
+// of its client within tx. The account is locked until tx ends, so concurrent charges cannot
+// together exceed the credit limit.
+func chargeClientAccount(tx *sql.Tx, accountRepo repositories.ClientAccountRepository, order *models.Order) error {
+	if order.ClientID == nil {
+		return fmt.Errorf("%w: orders charged to a house account need a client_id", ErrClientAccountValidation)
+	}
+	if !order.FinalAmount.IsPositive() {
+		return nil
+	}
+	now := utils.NowUTC()
+	account, err := accountRepo.GetAccount(tx, *order.ClientID, now, true)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrClientAccountNotFound
+		}
+		return fmt.Errorf("failed to get house account: %w", err)
+	}
+	if !account.IsActive {
+		return ErrClientAccountInactive
+	}
+	if account.OverdueAmount.IsPositive() {
+		return fmt.Errorf("%w (overdue: %s)", ErrClientAccountOverdue, account.OverdueAmount)
+	}
+	if account.Balance.Add(order.FinalAmount).Cmp(account.CreditLimit) > 0 {
+		return fmt.Errorf("%w (available: %s, order: %s)", ErrCreditLimitExceeded, account.AvailableCredit, order.FinalAmount)
+	}
+
+	dueAt := now.AddDate(0, 0, account.PaymentTermsDays)
+	entry := &models.ClientAccountEntry{
+		ClientID:  *order.ClientID,
+		EntryType: models.AccountEntryCharge,
+		Amount:    order.FinalAmount,
+		OrderID:   &order.ID,
+		DueAt:     &dueAt,
+		CreatedAt: now,
+	}
+	if err := accountRepo.CreateEntry(tx, entry); err != nil {
+		return fmt.Errorf("failed to charge house account: %w", err)
+	}
+	return nil
+}
+
+// reverseClientAccountCharge takes back within tx what remains charged to a house account for
+// an order that was cancelled, refunded or deleted. Orders never charged are left alone.
+func reverseClientAccountCharge(tx *sql.Tx, accountRepo repositories.ClientAccountRepository, orderID int64, reason string) error {
+	clientID, charged, err := accountRepo.GetOrderBalance(tx, orderID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get house account charge: %w", err)
+	}
+	if !charged.IsPositive() {
+		return nil
+	}
+	entry := &models.ClientAccountEntry{
+		ClientID:  clientID,
+		EntryType: models.AccountEntryReversal,
+		Amount:    charged.Neg(),
+		OrderID:   &orderID,
+		Notes:     &reason,
+	}
+	if err := accountRepo.CreateEntry(tx, entry); err != nil {
+		return fmt.Errorf("failed to reverse house account charge: %w", err)
+	}
+	return nil
+}
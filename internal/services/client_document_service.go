@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrClientDocumentValidation = apperrors.New(utils.ErrCodeValidationFailed, "client document validation error")
+	ErrClientDocumentNotFound   = apperrors.New(utils.ErrCodeNotFound, "client document not found")
+	ErrClientIDNotVerified      = apperrors.New(utils.ErrCodeNotFound, "the identity of the client has not been verified")
+)
+
+// VerifyClientIDRequest is the body of PUT /clients/:id/id-verification.
+type VerifyClientIDRequest struct {
+	DocumentID *int64  `json:"document_id"` // An id_document of the client the identity was checked against
+	ExpiresOn  *string `json:"expires_on"`  // YYYY-MM-DD, club time; defaults to the expiry of the document
+}
+
+// parseDocumentExpiry parses an expiry date (YYYY-MM-DD, club time) into the moment the
+// document expires: the end of that day. Empty means it does not expire.
+func parseDocumentExpiry(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	day, err := utils.ParseClubDate(strings.TrimSpace(*value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expiry date, use YYYY-MM-DD", ErrClientDocumentValidation)
+	}
+	_, end := utils.DayBounds(day)
+	return &end, nil
+}
+
+// expiredAt reports whether something that expires at expiresAt, if set, has expired at now.
+func expiredAt(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt != nil && !expiresAt.After(now)
+}
+
+// --- ClientDocumentService Interface ---
+type ClientDocumentService interface {
+	// GetDocuments returns the documents and ID verification of a client, with the document
+	// types it holds a valid document of.
+	GetDocuments(clientID int64) (*models.ClientDocuments, error)
+	// AddDocument stores a JPEG, PNG or WebP photo or scan of a document of a client, valid
+	// until the end of expiresOn (YYYY-MM-DD, club time) if set.
+	AddDocument(clientID int64, documentType string, expiresOn *string, data []byte, uploadedBy int64) (*models.ClientDocument, error)
+	GetDocumentPhoto(clientID, documentID int64) (*models.Photo, error)
+	DeleteDocument(clientID, documentID int64) error
+	// VerifyID records that the identity of a client was checked, replacing any earlier verification.
+	VerifyID(clientID int64, req VerifyClientIDRequest, verifiedBy int64) (*models.ClientIDVerification, error)
+	// RevokeIDVerification removes the ID verification of a client; ErrClientIDNotVerified if it has none.
+	RevokeIDVerification(clientID int64) error
+}
+
+type clientDocumentService struct {
+	documentRepo repositories.ClientDocumentRepository
+	clientRepo   repositories.ClientRepository
+}
+
+// NewClientDocumentService creates a new ClientDocumentService.
+func NewClientDocumentService(documentRepo repositories.ClientDocumentRepository, clientRepo repositories.ClientRepository) ClientDocumentService {
+	return &clientDocumentService{documentRepo: documentRepo, clientRepo: clientRepo}
+}
+
+// getClient returns the client whose documents are used; ErrClientNotFound if there is none.
+func (s *clientDocumentService) getClient(clientID int64) (*models.Client, error) {
+	client, err := s.clientRepo.GetClientByID(clientID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return client, nil
+}
+
+func (s *clientDocumentService) GetDocuments(clientID int64) (*models.ClientDocuments, error) {
+	if _, err := s.getClient(clientID); err != nil {
+		return nil, err
+	}
+	documents, err := s.documentRepo.GetDocuments(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client documents: %w", err)
+	}
+	verification, err := s.documentRepo.GetIDVerification(clientID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get client ID verification: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result := &models.ClientDocuments{ClientID: clientID, ValidDocumentTypes: []string{}, Documents: documents}
+	for i := range result.Documents {
+		document := &result.Documents[i]
+		document.Expired = expiredAt(document.ExpiresAt, now)
+		if !document.Expired && !slices.Contains(result.ValidDocumentTypes, document.DocumentType) {
+			result.ValidDocumentTypes = append(result.ValidDocumentTypes, document.DocumentType)
+		}
+	}
+	slices.Sort(result.ValidDocumentTypes)
+	if verification != nil {
+		verification.Expired = expiredAt(verification.ExpiresAt, now)
+		result.IDVerification = verification
+		result.IDVerified = !verification.Expired
+	}
+	return result, nil
+}
+
+func (s *clientDocumentService) AddDocument(clientID int64, documentType string, expiresOn *string, data []byte, uploadedBy int64) (*models.ClientDocument, error) {
+	if !slices.Contains(models.ClientDocumentTypes, documentType) {
+		return nil, fmt.Errorf("%w: document_type must be one of %v", ErrClientDocumentValidation, models.ClientDocumentTypes)
+	}
+	expiresAt, err := parseDocumentExpiry(expiresOn)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if expiredAt(expiresAt, now) {
+		return nil, fmt.Errorf("%w: the document has already expired", ErrClientDocumentValidation)
+	}
+	contentType, err := checkPhoto(data, ErrClientDocumentValidation)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.getClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+
+	document := &models.ClientDocument{ClientID: clientID, DocumentType: documentType, ExpiresAt: expiresAt, UploadedBy: &uploadedBy}
+	if err := s.documentRepo.AddDocument(document, &models.Photo{ContentType: contentType, Data: data}); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to add client document: %w", err)
+	}
+	return document, nil
+}
+
+func (s *clientDocumentService) GetDocumentPhoto(clientID, documentID int64) (*models.Photo, error) {
+	photo, err := s.documentRepo.GetDocumentPhoto(clientID, documentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get client document: %w", err)
+	}
+	return photo, nil
+}
+
+func (s *clientDocumentService) DeleteDocument(clientID, documentID int64) error {
+	if err := s.documentRepo.DeleteDocument(clientID, documentID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrClientDocumentNotFound
+		}
+		return fmt.Errorf("failed to delete client document: %w", err)
+	}
+	return nil
+}
+
+func (s *clientDocumentService) VerifyID(clientID int64, req VerifyClientIDRequest, verifiedBy int64) (*models.ClientIDVerification, error) {
+	expiresAt, err := parseDocumentExpiry(req.ExpiresOn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.getClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+
+	now := time.Now().UTC()
+	if req.DocumentID != nil {
+		document, err := s.documentRepo.GetDocumentByID(clientID, *req.DocumentID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, ErrClientDocumentNotFound
+			}
+			return nil, fmt.Errorf("failed to get client document: %w", err)
+		}
+		if document.DocumentType != models.ClientDocumentID {
+			return nil, fmt.Errorf("%w: document %d is not an %s", ErrClientDocumentValidation, document.ID, models.ClientDocumentID)
+		}
+		if expiredAt(document.ExpiresAt, now) {
+			return nil, fmt.Errorf("%w: document %d has expired", ErrClientDocumentValidation, document.ID)
+		}
+		if expiresAt == nil {
+			expiresAt = document.ExpiresAt
+		}
+	}
+	if expiredAt(expiresAt, now) {
+		return nil, fmt.Errorf("%w: the verification would already have expired", ErrClientDocumentValidation)
+	}
+
+	verification := &models.ClientIDVerification{
+		ClientID:   clientID,
+		DocumentID: req.DocumentID,
+		VerifiedBy: &verifiedBy,
+		VerifiedAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.documentRepo.SaveIDVerification(verification); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to save client ID verification: %w", err)
+	}
+	return verification, nil
+}
+
+func (s *clientDocumentService) RevokeIDVerification(clientID int64) error {
+	if err := s.documentRepo.DeleteIDVerification(clientID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrClientIDNotVerified
+		}
+		return fmt.Errorf("failed to revoke client ID verification: %w", err)
+	}
+	return nil
+}
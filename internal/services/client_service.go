@@ -4,67 +4,143 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"ps_club_backend/internal/events"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
-	"regexp"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 	"strings"
 	"time"
 )
 
 // --- Custom Service Errors for Client ---
 var (
-	ErrClientNotFound     = errors.New("client not found")
-	ErrPhoneNumberExists  = errors.New("phone number already exists")
-	ErrEmailExists        = errors.New("email already exists")
-	ErrClientValidation   = errors.New("client data validation error")
-	ErrDateFormat         = errors.New("invalid date format, please use YYYY-MM-DD")
-	ErrClientInUse        = errors.New("client cannot be deleted as they are referenced in other records")
+	ErrClientNotFound       = apperrors.New(utils.ErrCodeNotFound, "client not found")
+	ErrPhoneNumberExists    = apperrors.New(utils.ErrCodeConflict, "phone number already exists")
+	ErrClientValidation     = apperrors.New(utils.ErrCodeValidationFailed, "client data validation error")
+	ErrDateFormat           = apperrors.New(utils.ErrCodeValidationFailed, "invalid date format, please use YYYY-MM-DD")
+	ErrClientNotDeleted     = apperrors.New(utils.ErrCodeNotFound, "client is not deleted")
+	ErrClientAnonymized     = apperrors.New(utils.ErrCodeConflict, "client has been anonymized")
+	ErrClientNotBlacklisted = apperrors.New(utils.ErrCodeNotFound, "client is not blacklisted")
 )
 
+const (
+	// clientDuplicateNameSimilarity is the trigram similarity from which the name of an existing
+	// client makes it a probable duplicate of a new one, e.g. "Aliya Nurlanova" and "Alia Nurlanova".
+	clientDuplicateNameSimilarity = 0.5
+	// clientDuplicateCandidateLimit is the most probable duplicates listed.
+	clientDuplicateCandidateLimit = 5
+)
+
+var ErrClientPossibleDuplicate = errors.New("the client probably exists already")
+
+// ClientDuplicatesError is returned when a new client probably duplicates existing clients,
+// listed best match first. It matches ErrClientPossibleDuplicate with errors.Is.
+type ClientDuplicatesError struct {
+	Candidates []models.ClientDuplicateCandidate
+}
+
+func (e *ClientDuplicatesError) Error() string {
+	return fmt.Sprintf("%s (%d candidates)", ErrClientPossibleDuplicate, len(e.Candidates))
+}
+
+func (e *ClientDuplicatesError) Is(target error) bool { return target == ErrClientPossibleDuplicate }
+
 // --- Client DTOs ---
 type CreateClientRequest struct {
 	FullName      string  `json:"full_name" binding:"required"`
-	PhoneNumber   *string `json:"phone_number"` 
-	Email         *string `json:"email"`        
-	DateOfBirth   *string `json:"date_of_birth"` // Format YYYY-MM-DD
+	PhoneNumber   *string `json:"phone_number" binding:"omitempty,phone"`
+	Email         *string `json:"email" binding:"omitempty,email"`
+	DateOfBirth   *string `json:"date_of_birth" binding:"omitempty,date"` // Format YYYY-MM-DD
 	LoyaltyPoints *int    `json:"loyalty_points"`
 	Notes         *string `json:"notes"`
+	Force         bool    `json:"force"` // Create the client even if it probably exists already
 }
 
 type UpdateClientRequest struct {
 	FullName      *string `json:"full_name"`
-	PhoneNumber   *string `json:"phone_number"`
-	Email         *string `json:"email"`
-	DateOfBirth   *string `json:"date_of_birth"` // Format YYYY-MM-DD
+	PhoneNumber   *string `json:"phone_number" binding:"omitempty,phone"`
+	Email         *string `json:"email" binding:"omitempty,email"`
+	DateOfBirth   *string `json:"date_of_birth" binding:"omitempty,date"` // Format YYYY-MM-DD
 	LoyaltyPoints *int    `json:"loyalty_points"`
 	Notes         *string `json:"notes"`
+	Clear         []string `json:"-"` // Fields to set to null, from a merge patch; see ClientClearableFields
+}
+
+// BlacklistClientRequest is the body of PUT /clients/:id/blacklist.
+type BlacklistClientRequest struct {
+	Reason string  `json:"reason" binding:"required"`
+	Until  *string `json:"until"` // RFC3339 or club date-time; omitted, the client stays blacklisted until it is lifted
+}
+
+// ClientClearableFields are the fields of UpdateClientRequest a merge patch may set to null.
+var ClientClearableFields = []string{"phone_number", "email", "date_of_birth", "notes"}
+
+// ClientDataStream receives a client data export piece by piece, as it is read: the profile,
+// then every booking, then every order, then every order item, each group complete before
+// the next starts. Orders come without their items.
+type ClientDataStream struct {
+	Client    func(*models.Client) error
+	Booking   func(*models.Booking) error
+	Order     func(*models.Order) error
+	OrderItem func(*models.OrderItem) error
 }
 
 // --- ClientService Interface ---
 type ClientService interface {
+	// CreateClient creates a client. Unless req.Force is set, it returns *ClientDuplicatesError
+	// if clients with the same phone number, the same email or a similar name exist. Phone
+	// numbers are stored in E.164 form, e.g. "8 701 123 45 67" as "+77011234567".
 	CreateClient(req CreateClientRequest) (*models.Client, error)
 	GetClientByID(clientID int64) (*models.Client, error)
-	GetClients(page, pageSize int, searchTerm *string) ([]models.Client, int, error)
+	// GetClients lists clients matching searchTerm and, if set, having tag.
+	GetClients(page, pageSize int, searchTerm, tag *string) ([]models.Client, int, error)
+	// UpdateClient updates a client; a change of its loyalty points publishes client.loyalty_changed.
 	UpdateClient(clientID int64, req UpdateClientRequest) (*models.Client, error)
-	DeleteClient(clientID int64) error
+	// DeleteClient soft deletes a client: it is left out everywhere, keeping its orders and
+	// bookings, until an Admin restores it. The delete is recorded in the audit log.
+	DeleteClient(clientID int64, deletedBy int64) error
+	// RestoreClient restores a soft deleted client, recording it in the audit log.
+	// ErrClientNotDeleted if the client is not deleted; ErrPhoneNumberExists or ErrEmailExists
+	// if another client has its phone number or email since.
+	RestoreClient(clientID int64, restoredBy int64) (*models.Client, error)
+
+	// Personal data requests
+	ExportClientData(clientID int64) (*models.ClientDataExport, error)
+	// StreamClientData passes the data of ExportClientData to stream without collecting it,
+	// for large histories. It returns ErrClientNotFound before calling stream for an unknown client.
+	StreamClientData(clientID int64, stream ClientDataStream) error
+	AnonymizeClient(clientID int64) (*models.Client, error) // Irreversible; keeps bookings and orders
+
+	// BlacklistClient puts a client on the blacklist, replacing the blacklist it is on, so
+	// new bookings for it are refused; see CreateBookingRequest.OverrideBlacklist.
+	BlacklistClient(clientID int64, req BlacklistClientRequest, blacklistedBy int64) (*models.Client, error)
+	// LiftBlacklist takes a client off the blacklist; ErrClientNotBlacklisted if it is not on it.
+	LiftBlacklist(clientID int64) (*models.Client, error)
 }
 
 // --- clientService Implementation ---
 type clientService struct {
-	clientRepo repositories.ClientRepository
-	db         *sql.DB 
+	clientRepo   repositories.ClientRepository
+	bookingRepo  repositories.BookingRepository // For data exports
+	orderRepo    repositories.OrderRepository   // For data exports
+	auditLogRepo repositories.AuditLogRepository
+	publisher    events.Publisher
+	db           *sql.DB 
 }
 
 // NewClientService creates a new instance of ClientService.
-func NewClientService(repo repositories.ClientRepository, db *sql.DB) ClientService {
+func NewClientService(repo repositories.ClientRepository, bookingRepo repositories.BookingRepository, orderRepo repositories.OrderRepository, auditLogRepo repositories.AuditLogRepository, publisher events.Publisher, db *sql.DB) ClientService {
 	return &clientService{
-		clientRepo: repo,
-		db:         db,
+		clientRepo:   repo,
+		bookingRepo:  bookingRepo,
+		orderRepo:    orderRepo,
+		auditLogRepo: auditLogRepo,
+		publisher:    publisher,
+		db:           db,
 	}
 }
 
-var emailRegex = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
-
 func (s *clientService) validateClientData(fullName string, phoneNumber, email *string, isUpdate bool, clientID int64) error {
 	if strings.TrimSpace(fullName) == "" && !isUpdate { // FullName is required for create
 		return fmt.Errorf("%w: full name cannot be empty", ErrClientValidation)
@@ -80,12 +156,12 @@ func (s *clientService) validateClientData(fullName string, phoneNumber, email *
 			return fmt.Errorf("%w: phone number cannot be empty if provided", ErrClientValidation)
 		}
         if pn != "" {
-            // Basic validation, can be more complex (e.g. E.164)
-            // if !regexp.MustCompile(`^\+?[0-9\s\-()]{7,20}$`).MatchString(pn) {
-            //     return fmt.Errorf("%w: phone number format is invalid", ErrClientValidation)
-            // }
+            normalized, err := normalizePhone(&pn, ErrClientValidation)
+            if err != nil {
+                return err
+            }
             // Check for uniqueness if phone number is being set or changed
-            existingClient, err := s.clientRepo.GetClientByPhoneNumber(pn)
+            existingClient, err := s.clientRepo.GetClientByPhoneNumber(*normalized)
             if err != nil && !errors.Is(err, repositories.ErrNotFound) {
                 return fmt.Errorf("failed to check phone number uniqueness: %w", err)
             }
@@ -97,7 +173,7 @@ func (s *clientService) validateClientData(fullName string, phoneNumber, email *
 
 	if email != nil && *email != "" {
 		em := strings.ToLower(strings.TrimSpace(*email))
-		if !emailRegex.MatchString(em) {
+		if !utils.IsValidEmail(em) {
 			return fmt.Errorf("%w: email format is invalid", ErrClientValidation)
 		}
 		// TODO: Add uniqueness check for email if required by business logic, similar to phone number
@@ -120,6 +196,11 @@ func (s *clientService) parseDateOfBirth(dobStr *string) (*time.Time, error) {
 }
 
 func (s *clientService) CreateClient(req CreateClientRequest) (*models.Client, error) {
+	phoneNumber, err := normalizePhone(req.PhoneNumber, ErrClientValidation)
+	if err != nil {
+		return nil, err
+	}
+	req.PhoneNumber = phoneNumber // Stored and looked up in E.164 form
 	if err := s.validateClientData(req.FullName, req.PhoneNumber, req.Email, false, 0); err != nil {
 		return nil, err
 	}
@@ -128,6 +209,11 @@ func (s *clientService) CreateClient(req CreateClientRequest) (*models.Client, e
 	if err != nil {
 		return nil, err
 	}
+	if !req.Force {
+		if err := s.checkDuplicates(req); err != nil {
+			return nil, err
+		}
+	}
 
 	loyaltyPoints := 0
 	if req.LoyaltyPoints != nil {
@@ -155,11 +241,8 @@ func (s *clientService) CreateClient(req CreateClientRequest) (*models.Client, e
 	id, err := s.clientRepo.CreateClient(s.db, client)
 	if err != nil {
 		if errors.Is(err, repositories.ErrDuplicateKey) {
-			if req.PhoneNumber != nil && strings.Contains(err.Error(), "clients_phone_number_key") {
-				return nil, ErrPhoneNumberExists
-			}
-			if req.Email != nil && strings.Contains(err.Error(), "clients_email_key") {
-				return nil, ErrEmailExists
+			if duplicate := clientDuplicateError(err); duplicate != nil {
+				return nil, duplicate
 			}
 			return nil, fmt.Errorf("failed to create client due to duplicate data: %w", err)
 		}
@@ -168,6 +251,26 @@ func (s *clientService) CreateClient(req CreateClientRequest) (*models.Client, e
 	return s.clientRepo.GetClientByID(id)
 }
 
+// checkDuplicates returns *ClientDuplicatesError if the client to create probably exists already.
+func (s *clientService) checkDuplicates(req CreateClientRequest) error {
+	var phoneNumber, email string
+	if req.PhoneNumber != nil {
+		phoneNumber = *req.PhoneNumber
+	}
+	if req.Email != nil {
+		email = strings.TrimSpace(*req.Email)
+	}
+	candidates, err := s.clientRepo.FindDuplicateCandidates(strings.TrimSpace(req.FullName), phoneNumber, email,
+		clientDuplicateNameSimilarity, clientDuplicateCandidateLimit)
+	if err != nil {
+		return fmt.Errorf("failed to check for duplicate clients: %w", err)
+	}
+	if len(candidates) > 0 {
+		return &ClientDuplicatesError{Candidates: candidates}
+	}
+	return nil
+}
+
 func (s *clientService) GetClientByID(clientID int64) (*models.Client, error) {
 	client, err := s.clientRepo.GetClientByID(clientID)
 	if err != nil {
@@ -179,11 +282,15 @@ func (s *clientService) GetClientByID(clientID int64) (*models.Client, error) {
 	return client, nil
 }
 
-func (s *clientService) GetClients(page, pageSize int, searchTerm *string) ([]models.Client, int, error) {
+func (s *clientService) GetClients(page, pageSize int, searchTerm, tag *string) ([]models.Client, int, error) {
 	if page <= 0 { page = 1 }
 	if pageSize <= 0 { pageSize = 10 }
+	if tag != nil {
+		normalized := NormalizeClientTag(*tag)
+		tag = &normalized
+	}
 
-	clients, totalCount, err := s.clientRepo.GetClients(page, pageSize, searchTerm)
+	clients, totalCount, err := s.clientRepo.GetClients(page, pageSize, searchTerm, tag)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get clients: %w", err)
 	}
@@ -198,17 +305,25 @@ func (s *clientService) UpdateClient(clientID int64, req UpdateClientRequest) (*
 		}
 		return nil, fmt.Errorf("failed to find client for update: %w", err)
 	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+	if err := checkClearable(req.Clear, ClientClearableFields, ErrClientValidation); err != nil {
+		return nil, err
+	}
 
 	// Prepare fields for validation
 	fullNameToValidate := client.FullName
 	if req.FullName != nil {
 		fullNameToValidate = *req.FullName
 	}
-	// Use new phone/email for validation if provided, otherwise existing
-    phoneNumberToValidate := client.PhoneNumber
-    if req.PhoneNumber != nil {
-        phoneNumberToValidate = req.PhoneNumber
-    }
+	// The phone number is only checked if it changes, so clients keep numbers stored before
+	// numbers were normalized. Use new email for validation if provided, otherwise existing.
+	phoneNumberToValidate, err := normalizePhone(req.PhoneNumber, ErrClientValidation)
+	if err != nil {
+		return nil, err
+	}
+	req.PhoneNumber = phoneNumberToValidate // Stored in E.164 form
     emailToValidate := client.Email
     if req.Email != nil {
         emailToValidate = req.Email
@@ -231,6 +346,10 @@ func (s *clientService) UpdateClient(clientID int64, req UpdateClientRequest) (*
 		}
 		client.DateOfBirth = dobStrToUpdate // Updated
 	}
+	previousPoints := 0
+	if client.LoyaltyPoints != nil {
+		previousPoints = *client.LoyaltyPoints
+	}
 	if req.LoyaltyPoints != nil {
 		if *req.LoyaltyPoints < 0 {
 			return nil, fmt.Errorf("%w: loyalty points cannot be negative", ErrClientValidation)
@@ -238,15 +357,22 @@ func (s *clientService) UpdateClient(clientID int64, req UpdateClientRequest) (*
 		client.LoyaltyPoints = req.LoyaltyPoints
 	}
 	if req.Notes != nil { client.Notes = req.Notes }
+	if clears(req.Clear, "phone_number") { client.PhoneNumber = nil }
+	if clears(req.Clear, "email") { client.Email = nil }
+	if clears(req.Clear, "date_of_birth") { client.DateOfBirth = nil }
+	if clears(req.Clear, "notes") { client.Notes = nil }
 
-	err = s.clientRepo.UpdateClient(s.db, client)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = s.clientRepo.UpdateClient(tx, client)
 	if err != nil {
 		if errors.Is(err, repositories.ErrDuplicateKey) {
-			if req.PhoneNumber != nil && strings.Contains(err.Error(), "clients_phone_number_key") {
-				return nil, ErrPhoneNumberExists
-			}
-			if req.Email != nil && strings.Contains(err.Error(), "clients_email_key") {
-				return nil, ErrEmailExists
+			if duplicate := clientDuplicateError(err); duplicate != nil {
+				return nil, duplicate
 			}
 			return nil, fmt.Errorf("failed to update client due to duplicate data: %w", err)
 		}
@@ -255,10 +381,31 @@ func (s *clientService) UpdateClient(clientID int64, req UpdateClientRequest) (*
 		}
 		return nil, fmt.Errorf("failed to update client in repository: %w", err)
 	}
+	if req.LoyaltyPoints != nil && *req.LoyaltyPoints != previousPoints {
+		payload := events.LoyaltyPayload{ClientID: clientID, Points: *req.LoyaltyPoints, PreviousPoints: previousPoints}
+		if err := s.publisher.Publish(tx, events.ClientLoyaltyChanged, events.AggregateClient, clientID, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit client update: %w", err)
+	}
 	return s.clientRepo.GetClientByID(clientID)
 }
 
-func (s *clientService) DeleteClient(clientID int64) error {
+// clientDuplicateError returns the domain error of a duplicate client phone number or
+// email, by the violated unique constraint; nil for other constraints.
+func clientDuplicateError(err error) error {
+	switch repositories.ViolatedConstraint(err) {
+	case "clients_phone_number_key":
+		return ErrPhoneNumberExists
+	case "clients_email_key":
+		return ErrEmailExists
+	}
+	return nil
+}
+
+func (s *clientService) DeleteClient(clientID int64, deletedBy int64) error {
 	_, err := s.clientRepo.GetClientByID(clientID) 
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -267,15 +414,181 @@ func (s *clientService) DeleteClient(clientID int64) error {
 		return fmt.Errorf("failed to find client for deletion: %w", err)
 	}
 
-	err = s.clientRepo.DeleteClient(s.db, clientID)
+	err = softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordDeleted, ReferenceEntityClients, clientID, deletedBy, func(tx *sql.Tx, now time.Time) error {
+		return s.clientRepo.DeleteClient(tx, clientID, deletedBy, now)
+	})
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return ErrClientNotFound
 		}
-		if strings.Contains(err.Error(), "violates foreign key constraint") {
-             return ErrClientInUse
-        }
 		return fmt.Errorf("failed to delete client: %w", err)
 	}
 	return nil
 }
+
+func (s *clientService) RestoreClient(clientID int64, restoredBy int64) (*models.Client, error) {
+	err := softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordRestored, ReferenceEntityClients, clientID, restoredBy, func(tx *sql.Tx, now time.Time) error {
+		return s.clientRepo.RestoreClient(tx, clientID, now)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotDeleted
+		}
+		if duplicate := clientDuplicateError(err); duplicate != nil {
+			return nil, fmt.Errorf("%w: another client has it since this one was deleted", duplicate)
+		}
+		return nil, fmt.Errorf("failed to restore client: %w", err)
+	}
+	return s.clientRepo.GetClientByID(clientID)
+}
+
+// --- Personal data requests ---
+
+// ExportClientData collects the client's profile, bookings and orders (with items).
+// Joined staff details are left out, they are not the client's data.
+func (s *clientService) ExportClientData(clientID int64) (*models.ClientDataExport, error) {
+	export := &models.ClientDataExport{
+		ExportedAt: time.Now().UTC(),
+		Bookings:   []models.Booking{},
+		Orders:     []models.Order{},
+	}
+	orderIndex := make(map[int64]int) // Position of each order in export.Orders, to attach its items
+	err := s.StreamClientData(clientID, ClientDataStream{
+		Client: func(client *models.Client) error {
+			export.Client = *client
+			return nil
+		},
+		Booking: func(booking *models.Booking) error {
+			export.Bookings = append(export.Bookings, *booking)
+			return nil
+		},
+		Order: func(order *models.Order) error {
+			order.OrderItems = []models.OrderItem{}
+			orderIndex[order.ID] = len(export.Orders)
+			export.Orders = append(export.Orders, *order)
+			return nil
+		},
+		OrderItem: func(item *models.OrderItem) error {
+			if i, ok := orderIndex[item.OrderID]; ok {
+				export.Orders[i].OrderItems = append(export.Orders[i].OrderItems, *item)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func (s *clientService) StreamClientData(clientID int64, stream ClientDataStream) error {
+	client, err := s.GetClientByID(clientID)
+	if err != nil {
+		return err
+	}
+	if err := stream.Client(client); err != nil {
+		return err
+	}
+
+	err = s.bookingRepo.StreamBookings(models.BookingFilters{ClientID: &clientID}, func(booking *models.Booking) error {
+		booking.Client = nil
+		booking.StaffMember = nil
+		return stream.Booking(booking)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export bookings of client %d: %w", clientID, err)
+	}
+
+	orderFilters := models.OrderFilters{ClientID: &clientID}
+	err = s.orderRepo.StreamOrders(orderFilters, func(order *models.Order) error {
+		order.Client = nil
+		order.StaffMember = nil
+		return stream.Order(order)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export orders of client %d: %w", clientID, err)
+	}
+	if err := s.orderRepo.StreamOrderItems(orderFilters, stream.OrderItem); err != nil {
+		return fmt.Errorf("failed to export order items of client %d: %w", clientID, err)
+	}
+	return nil
+}
+
+// AnonymizeClient replaces the client's name with a placeholder and clears the
+// contact details, date of birth, loyalty points and the notes of the client and
+// its bookings and orders, and deletes its documents. Bookings and orders keep their amounts, so sales
+// reports do not change.
+func (s *clientService) AnonymizeClient(clientID int64) (*models.Client, error) {
+	client, err := s.GetClientByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	placeholderName := fmt.Sprintf("Anonymized client #%d", clientID)
+	if err := s.clientRepo.AnonymizeClient(tx, clientID, placeholderName, time.Now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientAnonymized // Anonymized concurrently
+		}
+		return nil, fmt.Errorf("failed to anonymize client: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit client anonymization: %w", err)
+	}
+	return s.GetClientByID(clientID)
+}
+
+func (s *clientService) BlacklistClient(clientID int64, req BlacklistClientRequest, blacklistedBy int64) (*models.Client, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason cannot be empty", ErrClientValidation)
+	}
+	now := time.Now().UTC()
+	var until *time.Time
+	if req.Until != nil && strings.TrimSpace(*req.Until) != "" {
+		parsed, err := utils.ParseClubDateTime(*req.Until)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid until, use RFC3339 or YYYY-MM-DD HH:MM", ErrClientValidation)
+		}
+		if !parsed.After(now) {
+			return nil, fmt.Errorf("%w: until must be in the future", ErrClientValidation)
+		}
+		until = &parsed
+	}
+	client, err := s.GetClientByID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.AnonymizedAt != nil {
+		return nil, ErrClientAnonymized
+	}
+
+	if err := s.clientRepo.SetBlacklist(s.db, clientID, reason, until, blacklistedBy, now); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to blacklist client: %w", err)
+	}
+	return s.GetClientByID(clientID)
+}
+
+func (s *clientService) LiftBlacklist(clientID int64) (*models.Client, error) {
+	if _, err := s.GetClientByID(clientID); err != nil {
+		return nil, err
+	}
+	if err := s.clientRepo.ClearBlacklist(s.db, clientID, time.Now().UTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientNotBlacklisted
+		}
+		return nil, fmt.Errorf("failed to lift client blacklist: %w", err)
+	}
+	return s.GetClientByID(clientID)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrClientTagValidation = apperrors.New(utils.ErrCodeValidationFailed, "client tag validation error")
+	ErrClientTagNotFound   = apperrors.New(utils.ErrCodeNotFound, "the client does not have the tag")
+)
+
+// ClientTagsRequest is the body of PUT and POST /clients/:id/tags.
+type ClientTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+// NormalizeClientTag returns tag the way it is stored: lower case, with runs of spaces
+// replaced by underscores, so "Tournament player" is tournament_player.
+func NormalizeClientTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "_")
+}
+
+// normalizeClientTags normalizes and validates tags, dropping duplicates.
+func normalizeClientTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeClientTag(tag)
+		if tag == "" {
+			return nil, fmt.Errorf("%w: tags cannot be empty", ErrClientTagValidation)
+		}
+		if utf8.RuneCountInString(tag) > models.ClientTagMaxLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrClientTagValidation, tag, models.ClientTagMaxLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// --- ClientTagService Interface ---
+type ClientTagService interface {
+	// GetTags lists the preset tags and the tags in use, with the number of clients having each, by tag.
+	GetTags() ([]models.ClientTagCount, error)
+	// SetClientTags replaces the tags of a client and returns the client.
+	SetClientTags(clientID int64, tags []string) (*models.Client, error)
+	// AddClientTags adds tags to a client, keeping those it has, and returns the client.
+	AddClientTags(clientID int64, tags []string) (*models.Client, error)
+	// RemoveClientTag removes a tag from a client and returns the client.
+	RemoveClientTag(clientID int64, tag string) (*models.Client, error)
+	// GetTagReport sums up the sales and bookings of the clients of each tag, or only of tag if
+	// set, in [from, to); from defaults to the start of the month and to to the end of today,
+	// in the club timezone.
+	GetTagReport(from, to *time.Time, tag *string) ([]models.ClientTagReportItem, error)
+}
+
+type clientTagService struct {
+	tagRepo    repositories.ClientTagRepository
+	clientRepo repositories.ClientRepository
+	db         *sql.DB
+}
+
+// NewClientTagService creates a new ClientTagService.
+func NewClientTagService(tagRepo repositories.ClientTagRepository, clientRepo repositories.ClientRepository, db *sql.DB) ClientTagService {
+	return &clientTagService{tagRepo: tagRepo, clientRepo: clientRepo, db: db}
+}
+
+func (s *clientTagService) GetTags() ([]models.ClientTagCount, error) {
+	counts, err := s.tagRepo.GetTagCounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client tags: %w", err)
+	}
+	inUse := make(map[string]int, len(counts))
+	for i := range counts {
+		inUse[counts[i].Tag] = i
+	}
+	for _, preset := range models.ClientPresetTags {
+		if i, ok := inUse[preset]; ok {
+			counts[i].Preset = true
+			continue
+		}
+		counts = append(counts, models.ClientTagCount{Tag: preset, Preset: true})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Tag < counts[j].Tag })
+	return counts, nil
+}
+
+// taggableClient checks that the client whose tags are to change exists and is not anonymized.
+func (s *clientTagService) taggableClient(clientID int64) error {
+	client, err := s.clientRepo.GetClientByID(clientID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrClientNotFound
+		}
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.AnonymizedAt != nil {
+		return ErrClientAnonymized
+	}
+	return nil
+}
+
+func (s *clientTagService) SetClientTags(clientID int64, tags []string) (*models.Client, error) {
+	tags, err := normalizeClientTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.taggableClient(clientID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.tagRepo.SetTags(tx, clientID, tags); err != nil {
+		return nil, fmt.Errorf("failed to set client tags: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit client tags: %w", err)
+	}
+	return s.clientRepo.GetClientByID(clientID)
+}
+
+func (s *clientTagService) AddClientTags(clientID int64, tags []string) (*models.Client, error) {
+	tags, err := normalizeClientTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", ErrClientTagValidation)
+	}
+	if err := s.taggableClient(clientID); err != nil {
+		return nil, err
+	}
+	if err := s.tagRepo.AddTags(s.db, clientID, tags); err != nil {
+		return nil, fmt.Errorf("failed to add client tags: %w", err)
+	}
+	return s.clientRepo.GetClientByID(clientID)
+}
+
+func (s *clientTagService) RemoveClientTag(clientID int64, tag string) (*models.Client, error) {
+	if err := s.taggableClient(clientID); err != nil {
+		return nil, err
+	}
+	if err := s.tagRepo.RemoveTag(s.db, clientID, NormalizeClientTag(tag)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClientTagNotFound
+		}
+		return nil, fmt.Errorf("failed to remove client tag: %w", err)
+	}
+	return s.clientRepo.GetClientByID(clientID)
+}
+
+func (s *clientTagService) GetTagReport(from, to *time.Time, tag *string) ([]models.ClientTagReportItem, error) {
+	now := utils.NowUTC()
+	start := utils.StartOfMonth(now).UTC()
+	_, end := utils.DayBounds(now)
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("%w: the report period must end after it starts", ErrClientTagValidation)
+	}
+	if tag != nil {
+		normalized := NormalizeClientTag(*tag)
+		tag = &normalized
+	}
+
+	items, err := s.tagRepo.GetTagReport(start, end, tag, ShiftSalesOrderStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client tag report: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"sync"
+
+	"ps_club_backend/internal/models"
+)
+
+var (
+	clockInSettings   models.ClockInSettings
+	clockInSettingsMu sync.RWMutex
+)
+
+// SetClockInSettings sets where staff may clock in from (the clock_in setting).
+func SetClockInSettings(settings models.ClockInSettings) {
+	clockInSettingsMu.Lock()
+	defer clockInSettingsMu.Unlock()
+	clockInSettings = settings
+}
+
+// CurrentClockInSettings returns where staff may clock in from.
+func CurrentClockInSettings() models.ClockInSettings {
+	clockInSettingsMu.RLock()
+	defer clockInSettingsMu.RUnlock()
+	return clockInSettings
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrInvalidCursor = apperrors.New(utils.ErrCodeValidationFailed, "invalid cursor")
+
+// DefaultCursorPageSize is the page size of cursor pagination when none is given.
+const DefaultCursorPageSize = 10
+
+// encodeCursor encodes a cursor as an opaque URL-safe string.
+func encodeCursor(c models.Cursor) string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor decodes a cursor returned as NextCursor; "" decodes to the zero Cursor,
+// the start of the list.
+func decodeCursor(cursor string) (*models.Cursor, error) {
+	if cursor == "" {
+		return &models.Cursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanosStr, idStr, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &models.Cursor{Time: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// cursorPage turns rows fetched with one row more than pageSize into a page: the extra
+// row only tells that there is a next page, which starts after the last row returned.
+func cursorPage[T any](rows []T, pageSize int, cursorOf func(T) models.Cursor) *models.CursorPage[T] {
+	page := &models.CursorPage[T]{Data: rows}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	if len(rows) > pageSize {
+		page.Data = rows[:pageSize]
+		next := encodeCursor(cursorOf(rows[pageSize-1]))
+		page.NextCursor = &next
+	}
+	return page
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrDayCloseBlocked      = apperrors.New(utils.ErrCodeDayCloseBlocked, "orders, table sessions or shifts of the day are still open")
+	ErrDayClosed            = apperrors.New(utils.ErrCodeDayClosed, "the business day is closed; only an Admin may change its records")
+	ErrDayAlreadyClosed     = apperrors.New(utils.ErrCodeConflict, "the business day is already closed")
+	ErrDayCloseValidation   = apperrors.New(utils.ErrCodeValidationFailed, "day close validation error")
+	ErrDailySummaryNotFound = apperrors.New(utils.ErrCodeNotFound, "daily summary not found")
+)
+
+// DayCloseBlockedError is returned when a day cannot be closed because records of it are
+// still open. It matches ErrDayCloseBlocked with errors.Is.
+type DayCloseBlockedError struct {
+	Open []models.DayCloseItem
+}
+
+func (e *DayCloseBlockedError) Error() string {
+	return fmt.Sprintf("%s (%d open)", ErrDayCloseBlocked, len(e.Open))
+}
+
+func (e *DayCloseBlockedError) Is(target error) bool { return target == ErrDayCloseBlocked }
+
+// DayCloseRequest closes a business day. With Force, the records still open are closed
+// first: orders are cancelled, table sessions stopped and staff members clocked out.
+type DayCloseRequest struct {
+	BusinessDate *string `json:"business_date" binding:"omitempty,date"` // YYYY-MM-DD, club time; defaults to today
+	Force        bool    `json:"force"`
+	Reason       *string `json:"reason" binding:"omitempty,max=1000"` // Required with force
+	Notes        *string `json:"notes" binding:"omitempty,max=4000"`
+}
+
+// --- DayCloseService Interface ---
+type DayCloseService interface {
+	// CloseDay closes a business day of this branch on behalf of closedBy and returns its
+	// summary. It returns *DayCloseBlockedError if records of the day are still open and
+	// req.Force is not set, and ErrDayAlreadyClosed if the day was closed before.
+	CloseDay(req DayCloseRequest, closedBy int64) (*models.DailySummary, error)
+	// GetSummary returns the summary of a closed business day (YYYY-MM-DD) of this branch.
+	GetSummary(businessDate string) (*models.DailySummary, error)
+	// GetSummaries lists the summaries of this branch for business days in [from, to], latest
+	// first; empty bounds are open.
+	GetSummaries(from, to string) ([]models.DailySummary, error)
+}
+
+type dayCloseService struct {
+	dayCloseRepo        repositories.DayCloseRepository
+	shiftReportRepo     repositories.ShiftReportRepository
+	orderService        OrderService
+	tableSessionService TableSessionService
+	staffService        StaffService
+	db                  *sql.DB
+}
+
+// NewDayCloseService creates a new DayCloseService. The forced closures go through the
+// services that own the records, so they return stock, bill sessions and report shifts
+// as if done by hand.
+func NewDayCloseService(dayCloseRepo repositories.DayCloseRepository, shiftReportRepo repositories.ShiftReportRepository,
+	orderService OrderService, tableSessionService TableSessionService, staffService StaffService, db *sql.DB) DayCloseService {
+	return &dayCloseService{
+		dayCloseRepo:        dayCloseRepo,
+		shiftReportRepo:     shiftReportRepo,
+		orderService:        orderService,
+		tableSessionService: tableSessionService,
+		staffService:        staffService,
+		db:                  db,
+	}
+}
+
+func (s *dayCloseService) CloseDay(req DayCloseRequest, closedBy int64) (*models.DailySummary, error) {
+	now := utils.NowUTC()
+	businessDate := utils.FormatClubTime(now, utils.DateLayout)
+	if req.BusinessDate != nil {
+		businessDate = strings.TrimSpace(*req.BusinessDate)
+	}
+	start, err := utils.ParseClubDate(businessDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: business_date must be YYYY-MM-DD", ErrDayCloseValidation)
+	}
+	if start.After(now) {
+		return nil, fmt.Errorf("%w: business_date is in the future", ErrDayCloseValidation)
+	}
+	_, end := utils.DayBounds(start)
+	reason := trimmedOrNil(req.Reason)
+	if req.Force && reason == nil {
+		return nil, fmt.Errorf("%w: a reason is required to close the day by force", ErrDayCloseValidation)
+	}
+
+	if existing, err := s.dayCloseRepo.GetSummary(utils.BranchCode(), businessDate); err == nil {
+		if !existing.Backfilled {
+			return nil, ErrDayAlreadyClosed
+		}
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get daily summary: %w", err)
+	}
+
+	var forced []models.DayCloseItem
+	if req.Force {
+		open, err := s.openItems(s.db, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range open {
+			closed, err := s.forceClose(item, closedBy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to close %s %d: %w", item.Type, item.ID, err)
+			}
+			if closed {
+				forced = append(forced, item)
+			}
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Records opened in the meantime still keep the day open
+	open, err := s.openItems(tx, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		return nil, &DayCloseBlockedError{Open: open}
+	}
+
+	summary, err := buildDailySummary(tx, s.dayCloseRepo, s.shiftReportRepo, businessDate, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(forced) > 0 {
+		summary.ForcedClosures = forced
+		summary.ForceReason = reason
+	}
+	summary.Notes = trimmedOrNil(req.Notes)
+	summary.ClosedBy = &closedBy
+
+	if err := s.dayCloseRepo.CreateSummary(tx, summary); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrDayAlreadyClosed
+		}
+		return nil, fmt.Errorf("failed to save daily summary: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit day close: %w", err)
+	}
+	return summary, nil
+}
+
+// openItems returns the orders, table sessions and clock-ins started before end that are still open.
+func (s *dayCloseService) openItems(executor repositories.SQLExecutor, end time.Time) ([]models.DayCloseItem, error) {
+	orders, err := s.dayCloseRepo.GetOpenOrders(executor, utils.BranchCode(), OpenOrderStatuses, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open orders: %w", err)
+	}
+	sessions, err := s.dayCloseRepo.GetRunningSessions(executor, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running table sessions: %w", err)
+	}
+	staff, err := s.dayCloseRepo.GetClockedInStaff(executor, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clocked-in staff: %w", err)
+	}
+	open := make([]models.DayCloseItem, 0, len(orders)+len(sessions)+len(staff))
+	open = append(open, orders...)
+	open = append(open, sessions...)
+	return append(open, staff...), nil
+}
+
+// forceClose closes an open record of the day. It reports false if the record was closed
+// in the meantime by someone else.
+func (s *dayCloseService) forceClose(item models.DayCloseItem, closedBy int64) (bool, error) {
+	var err error
+	switch item.Type {
+	case models.DayCloseItemOrder:
+		reason := models.VoidReasonDayClose
+		_, err = s.orderService.UpdateOrderStatus(item.ID, UpdateOrderStatusRequest{Status: StatusCancelled, VoidReason: &reason, CallerID: closedBy})
+		if errors.Is(err, ErrOrderNotFound) {
+			return false, nil
+		}
+	case models.DayCloseItemTableSession:
+		_, err = s.tableSessionService.StopSession(item.ID, closedBy)
+		if errors.Is(err, ErrTableSessionStopped) || errors.Is(err, ErrTableSessionNotFound) {
+			return false, nil
+		}
+	case models.DayCloseItemClockedIn:
+		_, err = s.staffService.ClockOutStaff(item.ID, ClockOutRequest{})
+		if errors.Is(err, ErrNotClockedIn) || errors.Is(err, ErrStaffNotFound) {
+			return false, nil
+		}
+	default:
+		return false, fmt.Errorf("unknown record type %q", item.Type)
+	}
+	return err == nil, err
+}
+
+// buildDailySummary totals the business day [start, end) of this branch, for closing the
+// day or backfilling its summary.
+func buildDailySummary(executor repositories.SQLExecutor, dayCloseRepo repositories.DayCloseRepository,
+	shiftReportRepo repositories.ShiftReportRepository, businessDate string, start, end time.Time) (*models.DailySummary, error) {
+	summary := &models.DailySummary{BranchCode: utils.BranchCode(), BusinessDate: businessDate}
+
+	sales, err := shiftReportRepo.GetSalesByPayment(executor, summary.BranchCode, ShiftSalesOrderStatuses, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total sales: %w", err)
+	}
+	summary.SalesByPayment = sales
+	for _, total := range sales {
+		summary.OrderCount += total.OrderCount
+		summary.SalesTotal = summary.SalesTotal.Add(total.Amount)
+		if total.PaymentMethod == models.PaymentMethodCash {
+			summary.CashSales = total.Amount
+		}
+	}
+	summary.CategorySales, err = dayCloseRepo.GetCategorySales(executor, summary.BranchCode, ShiftSalesOrderStatuses, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total sales per category: %w", err)
+	}
+
+	summary.CancelledCount, summary.RefundedCount, summary.RefundedTotal, err = dayCloseRepo.GetOrderOutcomes(executor, summary.BranchCode, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cancelled and refunded orders: %w", err)
+	}
+	summary.SessionCount, summary.SessionTotal, err = shiftReportRepo.GetStoppedSessionTotals(executor, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total table sessions: %w", err)
+	}
+	summary.Revenue = summary.SalesTotal.Add(summary.SessionTotal)
+	summary.HoursBooked, err = dayCloseRepo.GetHoursBooked(executor, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total booked hours: %w", err)
+	}
+	summary.CashAccountPayments, err = shiftReportRepo.GetAccountPaymentsTotal(executor, models.PaymentMethodCash, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total house account payments: %w", err)
+	}
+	summary.ShiftReportCount, summary.CashDifference, err = dayCloseRepo.GetShiftCashTotals(executor, summary.BranchCode, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total shift reports: %w", err)
+	}
+	summary.ForcedClosures = []models.DayCloseItem{}
+	summary.ClosedAt = utils.NowUTC()
+	return summary, nil
+}
+
+func (s *dayCloseService) GetSummary(businessDate string) (*models.DailySummary, error) {
+	if !utils.IsValidDate(businessDate) {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrDayCloseValidation)
+	}
+	summary, err := s.dayCloseRepo.GetSummary(utils.BranchCode(), businessDate)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrDailySummaryNotFound
+		}
+		return nil, fmt.Errorf("failed to get daily summary: %w", err)
+	}
+	return summary, nil
+}
+
+func (s *dayCloseService) GetSummaries(from, to string) ([]models.DailySummary, error) {
+	for _, date := range []string{from, to} {
+		if date != "" && !utils.IsValidDate(date) {
+			return nil, fmt.Errorf("%w: dates must be YYYY-MM-DD", ErrDayCloseValidation)
+		}
+	}
+	summaries, err := s.dayCloseRepo.GetSummaries(utils.BranchCode(), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// trimmedOrNil returns value without surrounding whitespace, or nil if it is nil or blank.
+func trimmedOrNil(value *string) *string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
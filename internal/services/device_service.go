@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrDeviceValidation    = apperrors.New(utils.ErrCodeValidationFailed, "device validation error")
+	ErrDeviceNotFound      = apperrors.New(utils.ErrCodeNotFound, "device not found")
+	ErrDeviceNotRegistered = apperrors.New(utils.ErrCodeNotFound, "the device is not registered with this API key, register it first")
+)
+
+// Bounds of device fields.
+const (
+	maxDeviceUIDLength     = 100
+	maxDeviceNameLength    = 100
+	maxDeviceAppVersion    = 50
+	maxDevicePrinterLength = 100
+)
+
+// RegisterDeviceRequest is the body of POST /devices/register.
+type RegisterDeviceRequest struct {
+	DeviceUID  string `json:"device_uid" binding:"required"`
+	Name       string `json:"name" binding:"required"` // Kept if the device registers again
+	Type       string `json:"type" binding:"required"` // One of models.DeviceTypes
+	AppVersion string `json:"app_version"`
+}
+
+// DeviceHeartbeatRequest is the body of POST /devices/heartbeat.
+type DeviceHeartbeatRequest struct {
+	DeviceUID  string `json:"device_uid" binding:"required"`
+	AppVersion string `json:"app_version"`
+}
+
+// UpdateDeviceRequest is the body of PUT /admin/devices/:id.
+type UpdateDeviceRequest struct {
+	Name     string                `json:"name" binding:"required"`
+	Settings models.DeviceSettings `json:"settings"`
+	Version  *int                  `json:"version"` // Checked if set
+}
+
+// --- DeviceService Interface ---
+type DeviceService interface {
+	// RegisterDevice records the device calling with the API key apiKeyID from ip, or records
+	// that it registered again, and reports whether it is new. Its settings are in the response.
+	RegisterDevice(req RegisterDeviceRequest, apiKeyID int64, ip string) (*models.Device, bool, error)
+	// Heartbeat marks a device registered with apiKeyID as online and returns it with its
+	// current settings.
+	Heartbeat(req DeviceHeartbeatRequest, apiKeyID int64, ip string) (*models.Device, error)
+	GetDevices(filters models.DeviceFilters) ([]models.Device, error)
+	GetDevice(id int64) (*models.Device, error)
+	// UpdateDevice renames a device and replaces its settings.
+	UpdateDevice(id int64, req UpdateDeviceRequest) (*models.Device, error)
+	DeleteDevice(id int64) error
+}
+
+type deviceService struct {
+	deviceRepo repositories.DeviceRepository
+}
+
+// NewDeviceService creates a new DeviceService.
+func NewDeviceService(deviceRepo repositories.DeviceRepository) DeviceService {
+	return &deviceService{deviceRepo: deviceRepo}
+}
+
+func (s *deviceService) RegisterDevice(req RegisterDeviceRequest, apiKeyID int64, ip string) (*models.Device, bool, error) {
+	uid := strings.TrimSpace(req.DeviceUID)
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case uid == "" || len(uid) > maxDeviceUIDLength:
+		return nil, false, fmt.Errorf("%w: device_uid is required, at most %d characters", ErrDeviceValidation, maxDeviceUIDLength)
+	case name == "" || len(name) > maxDeviceNameLength:
+		return nil, false, fmt.Errorf("%w: name is required, at most %d characters", ErrDeviceValidation, maxDeviceNameLength)
+	case !slices.Contains(models.DeviceTypes, req.Type):
+		return nil, false, fmt.Errorf("%w: invalid device type '%s', must be one of %v", ErrDeviceValidation, req.Type, models.DeviceTypes)
+	}
+	appVersion, err := deviceAppVersion(req.AppVersion)
+	if err != nil {
+		return nil, false, err
+	}
+
+	device := &models.Device{
+		DeviceUID:  uid,
+		Name:       name,
+		Type:       req.Type,
+		AppVersion: appVersion,
+		APIKeyID:   &apiKeyID,
+		LastIP:     optionalString(ip),
+	}
+	created, err := s.deviceRepo.RegisterDevice(device)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to register device: %w", err)
+	}
+	device.SetStatus(utils.NowUTC())
+	return device, created, nil
+}
+
+func (s *deviceService) Heartbeat(req DeviceHeartbeatRequest, apiKeyID int64, ip string) (*models.Device, error) {
+	appVersion, err := deviceAppVersion(req.AppVersion)
+	if err != nil {
+		return nil, err
+	}
+	now := utils.NowUTC()
+	device, err := s.deviceRepo.TouchDevice(strings.TrimSpace(req.DeviceUID), apiKeyID, appVersion, optionalString(ip), now)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrDeviceNotRegistered
+		}
+		return nil, fmt.Errorf("failed to record device heartbeat: %w", err)
+	}
+	device.SetStatus(now)
+	return device, nil
+}
+
+func (s *deviceService) GetDevices(filters models.DeviceFilters) ([]models.Device, error) {
+	if filters.Type != nil && !slices.Contains(models.DeviceTypes, *filters.Type) {
+		return nil, fmt.Errorf("%w: invalid device type '%s', must be one of %v", ErrDeviceValidation, *filters.Type, models.DeviceTypes)
+	}
+	var online *bool
+	if filters.Status != nil {
+		if !slices.Contains(models.DeviceStatuses, *filters.Status) {
+			return nil, fmt.Errorf("%w: invalid status '%s', must be one of %v", ErrDeviceValidation, *filters.Status, models.DeviceStatuses)
+		}
+		isOnline := *filters.Status == models.DeviceStatusOnline
+		online = &isOnline
+	}
+	now := utils.NowUTC()
+	devices, err := s.deviceRepo.GetDevices(filters.Type, online, now.Add(-models.DeviceOnlineWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	for i := range devices {
+		devices[i].SetStatus(now)
+	}
+	return devices, nil
+}
+
+func (s *deviceService) GetDevice(id int64) (*models.Device, error) {
+	device, err := s.deviceRepo.GetDeviceByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	device.SetStatus(utils.NowUTC())
+	return device, nil
+}
+
+func (s *deviceService) UpdateDevice(id int64, req UpdateDeviceRequest) (*models.Device, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxDeviceNameLength {
+		return nil, fmt.Errorf("%w: name is required, at most %d characters", ErrDeviceValidation, maxDeviceNameLength)
+	}
+	settings, err := validateDeviceSettings(req.Settings)
+	if err != nil {
+		return nil, err
+	}
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	device := &models.Device{ID: id, Name: name, Settings: settings}
+	if err := s.deviceRepo.UpdateDevice(device, version); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrDeviceNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update device: %w", err)
+	}
+	device.SetStatus(utils.NowUTC())
+	return device, nil
+}
+
+func (s *deviceService) DeleteDevice(id int64) error {
+	if err := s.deviceRepo.DeleteDevice(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	return nil
+}
+
+// validateDeviceSettings checks the settings of a device and returns them trimmed, with each
+// allowed order type once.
+func validateDeviceSettings(settings models.DeviceSettings) (models.DeviceSettings, error) {
+	settings.DefaultBranch = strings.TrimSpace(settings.DefaultBranch)
+	if settings.DefaultBranch != "" && !utils.IsValidBranchCode(settings.DefaultBranch) {
+		return settings, fmt.Errorf("%w: invalid default_branch '%s': use up to 20 letters, digits, '-' or '_'", ErrDeviceValidation, settings.DefaultBranch)
+	}
+	settings.Printer = strings.TrimSpace(settings.Printer)
+	if len(settings.Printer) > maxDevicePrinterLength {
+		return settings, fmt.Errorf("%w: printer cannot be longer than %d characters", ErrDeviceValidation, maxDevicePrinterLength)
+	}
+	orderTypes := make([]string, 0, len(settings.AllowedOrderTypes))
+	for _, orderType := range settings.AllowedOrderTypes {
+		if !slices.Contains(models.DeviceOrderTypes, orderType) {
+			return settings, fmt.Errorf("%w: invalid order type '%s', must be one of %v", ErrDeviceValidation, orderType, models.DeviceOrderTypes)
+		}
+		if !slices.Contains(orderTypes, orderType) {
+			orderTypes = append(orderTypes, orderType)
+		}
+	}
+	settings.AllowedOrderTypes = orderTypes
+	return settings, nil
+}
+
+// deviceAppVersion returns the trimmed app version a device reported, nil if it sent none.
+func deviceAppVersion(version string) (*string, error) {
+	version = strings.TrimSpace(version)
+	if len(version) > maxDeviceAppVersion {
+		return nil, fmt.Errorf("%w: app_version cannot be longer than %d characters", ErrDeviceValidation, maxDeviceAppVersion)
+	}
+	return optionalString(version), nil
+}
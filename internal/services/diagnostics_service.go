@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrUnknownDiagnosticQuery = apperrors.New(utils.ErrCodeValidationFailed, "unknown diagnostic query")
+
+// Canned queries that POST /admin/diagnostics/explain can explain.
+const (
+	DiagnosticQueryOrders            = "orders_list"
+	DiagnosticQueryBookings          = "bookings_list"
+	DiagnosticQueryMovements         = "inventory_movements_list"
+	DiagnosticQueryTableAvailability = "table_availability"
+	DiagnosticQueryActivityFeed      = "activity_feed"
+)
+
+// DiagnosticQueries lists the canned queries, for error messages.
+var DiagnosticQueries = []string{
+	DiagnosticQueryOrders, DiagnosticQueryBookings, DiagnosticQueryMovements,
+	DiagnosticQueryTableAvailability, DiagnosticQueryActivityFeed,
+}
+
+// ExpectedIndexes are the indexes the listing and report queries need to stay fast as
+// the tables grow. The migrations create them; an index dropped or never built (e.g. a
+// failed CREATE INDEX CONCURRENTLY) is reported at startup and by GET /readyz.
+var ExpectedIndexes = []models.ExpectedIndex{
+	{Table: "bookings", Columns: []string{"table_id", "start_time"}, Purpose: "table availability checks and booking lists by table"},
+	{Table: "bookings", Columns: []string{"start_time"}, Purpose: "booking lists by date"},
+	{Table: "orders", Columns: []string{"order_time"}, Purpose: "order lists and sales reports by date"},
+	{Table: "orders", Columns: []string{"status", "order_time"}, Purpose: "order lists by status"},
+	{Table: "inventory_movements", Columns: []string{"movement_date"}, Purpose: "inventory movement lists"},
+	{Table: "outbox_events", Columns: []string{"event_type", "created_at"}, Purpose: "the activity feed"},
+}
+
+// ExplainQueryRequest is the body of POST /admin/diagnostics/explain. Params holds the
+// parameters of the query, named like the query parameters of its endpoint; dates are
+// YYYY-MM-DD and times RFC 3339.
+type ExplainQueryRequest struct {
+	Query  string          `json:"query" binding:"required"`
+	Params json.RawMessage `json:"params"`
+}
+
+type ordersExplainParams struct {
+	ClientID *int64  `json:"client_id"`
+	StaffID  *int64  `json:"staff_id"`
+	TableID  *int64  `json:"table_id"`
+	Status   *string `json:"status"`
+	Date     *string `json:"date"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Cursor   *string `json:"cursor"`
+}
+
+type bookingsExplainParams struct {
+	ClientID *int64  `json:"client_id"`
+	TableID  *int64  `json:"table_id"`
+	StaffID  *int64  `json:"staff_id"`
+	Status   *string `json:"status"`
+	DateFrom *string `json:"date_from"`
+	DateTo   *string `json:"date_to"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Cursor   *string `json:"cursor"`
+}
+
+type movementsExplainParams struct {
+	ItemID       *int64  `json:"item_id"`
+	StaffID      *int64  `json:"staff_id"`
+	MovementType *string `json:"movement_type"`
+	Page         int     `json:"page"`
+	PageSize     int     `json:"page_size"`
+}
+
+type tableAvailabilityExplainParams struct {
+	TableID   int64     `json:"table_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type activityFeedExplainParams struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+// --- DiagnosticsService Interface ---
+type DiagnosticsService interface {
+	// ExplainQuery runs EXPLAIN ANALYZE on a canned query with the given parameters.
+	// The query is executed, read-only, so it takes as long as the real one.
+	ExplainQuery(req ExplainQueryRequest) (*models.QueryPlan, error)
+	// MissingIndexes returns the ExpectedIndexes that no index of the database provides.
+	MissingIndexes() ([]models.ExpectedIndex, error)
+	// Readiness checks the database; missing indexes are reported as warnings.
+	Readiness() *models.Readiness
+}
+
+// --- diagnosticsService Implementation ---
+type diagnosticsService struct {
+	diagnosticsRepo repositories.DiagnosticsRepository
+}
+
+// NewDiagnosticsService creates a new instance of DiagnosticsService.
+func NewDiagnosticsService(dr repositories.DiagnosticsRepository) DiagnosticsService {
+	return &diagnosticsService{diagnosticsRepo: dr}
+}
+
+func (s *diagnosticsService) ExplainQuery(req ExplainQueryRequest) (*models.QueryPlan, error) {
+	var plan *models.QueryPlan
+	var err error
+	switch req.Query {
+	case DiagnosticQueryOrders:
+		plan, err = s.explainOrders(req.Params)
+	case DiagnosticQueryBookings:
+		plan, err = s.explainBookings(req.Params)
+	case DiagnosticQueryMovements:
+		plan, err = s.explainMovements(req.Params)
+	case DiagnosticQueryTableAvailability:
+		plan, err = s.explainTableAvailability(req.Params)
+	case DiagnosticQueryActivityFeed:
+		plan, err = s.explainActivityFeed(req.Params)
+	default:
+		return nil, fmt.Errorf("%w '%s', expected one of %v", ErrUnknownDiagnosticQuery, req.Query, DiagnosticQueries)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Query = req.Query
+	return plan, nil
+}
+
+func (s *diagnosticsService) explainOrders(raw json.RawMessage) (*models.QueryPlan, error) {
+	var params ordersExplainParams
+	if err := decodeExplainParams(raw, &params); err != nil {
+		return nil, err
+	}
+	filters := models.OrderFilters{
+		ClientID: params.ClientID, StaffID: params.StaffID, TableID: params.TableID,
+		Status: params.Status, Date: params.Date,
+	}
+	if params.Date != nil && !utils.IsValidDate(*params.Date) {
+		return nil, fmt.Errorf("%w: date must be in YYYY-MM-DD format", ErrValidation)
+	}
+	if params.Cursor != nil {
+		cursor, err := decodeCursor(*params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filters.Cursor = cursor
+	} else {
+		filters.Page = params.Page
+	}
+	filters.Page, filters.PageSize = explainPage(filters.Page, params.PageSize)
+	if filters.Cursor != nil {
+		filters.PageSize++ // Like GetOrdersByCursor, which fetches one extra row
+	}
+
+	plan, err := s.diagnosticsRepo.ExplainOrders(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain orders query: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *diagnosticsService) explainBookings(raw json.RawMessage) (*models.QueryPlan, error) {
+	var params bookingsExplainParams
+	if err := decodeExplainParams(raw, &params); err != nil {
+		return nil, err
+	}
+	filters := models.BookingFilters{
+		ClientID: params.ClientID, TableID: params.TableID, StaffID: params.StaffID, Status: params.Status,
+	}
+	if params.DateFrom != nil {
+		from, err := utils.ParseClubDate(*params.DateFrom)
+		if err != nil {
+			return nil, fmt.Errorf("%w: date_from must be in YYYY-MM-DD format", ErrValidation)
+		}
+		filters.DateFrom = &from
+	}
+	if params.DateTo != nil {
+		day, err := utils.ParseClubDate(*params.DateTo)
+		if err != nil {
+			return nil, fmt.Errorf("%w: date_to must be in YYYY-MM-DD format", ErrValidation)
+		}
+		_, to := utils.DayBounds(day)
+		filters.DateTo = &to
+	}
+	if params.Cursor != nil {
+		cursor, err := decodeCursor(*params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filters.Cursor = cursor
+	} else {
+		filters.Page = params.Page
+	}
+	filters.Page, filters.PageSize = explainPage(filters.Page, params.PageSize)
+	if filters.Cursor != nil {
+		filters.PageSize++
+	}
+
+	plan, err := s.diagnosticsRepo.ExplainBookings(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain bookings query: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *diagnosticsService) explainMovements(raw json.RawMessage) (*models.QueryPlan, error) {
+	var params movementsExplainParams
+	if err := decodeExplainParams(raw, &params); err != nil {
+		return nil, err
+	}
+	page, pageSize := explainPage(params.Page, params.PageSize)
+	plan, err := s.diagnosticsRepo.ExplainMovements(params.ItemID, params.StaffID, params.MovementType, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain inventory movements query: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *diagnosticsService) explainTableAvailability(raw json.RawMessage) (*models.QueryPlan, error) {
+	var params tableAvailabilityExplainParams
+	if err := decodeExplainParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.TableID <= 0 {
+		return nil, fmt.Errorf("%w: table_id is required", ErrValidation)
+	}
+	if params.StartTime.IsZero() || !params.EndTime.After(params.StartTime) {
+		return nil, fmt.Errorf("%w: start_time and end_time are required and end_time must be after start_time", ErrValidation)
+	}
+	plan, err := s.diagnosticsRepo.ExplainTableAvailability(params.TableID, params.StartTime.UTC(), params.EndTime.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain table availability query: %w", err)
+	}
+	return plan, nil
+}
+
+func (s *diagnosticsService) explainActivityFeed(raw json.RawMessage) (*models.QueryPlan, error) {
+	var params activityFeedExplainParams
+	if err := decodeExplainParams(raw, &params); err != nil {
+		return nil, err
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultActivityLimit
+	}
+	if limit > MaxActivityLimit {
+		limit = MaxActivityLimit
+	}
+	// The filter GetActivityFeed uses
+	filter := models.ActivityFilter{
+		EventTypes:           activityEventTypes,
+		DiscountEventType:    events.OrderDiscounted,
+		LargeDiscountPercent: LargeDiscountPercent,
+		Limit:                limit + 1,
+	}
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	plan, err := s.diagnosticsRepo.ExplainActivityEvents(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain activity feed query: %w", err)
+	}
+	return plan, nil
+}
+
+// decodeExplainParams decodes the parameters of a canned query into params, rejecting
+// unknown ones so a misspelt parameter doesn't silently explain a different query.
+func decodeExplainParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return fmt.Errorf("%w: invalid params: %v", ErrValidation, err)
+	}
+	return nil
+}
+
+// explainPage applies the page defaults of the list endpoints.
+func explainPage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultCursorPageSize
+	}
+	return page, pageSize
+}
+
+func (s *diagnosticsService) MissingIndexes() ([]models.ExpectedIndex, error) {
+	indexesByTable := make(map[string][][]string)
+	missing := []models.ExpectedIndex{}
+	for _, expected := range ExpectedIndexes {
+		indexes, ok := indexesByTable[expected.Table]
+		if !ok {
+			var err error
+			indexes, err = s.diagnosticsRepo.GetIndexColumns(expected.Table)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get indexes of %s: %w", expected.Table, err)
+			}
+			indexesByTable[expected.Table] = indexes
+		}
+		if !slices.ContainsFunc(indexes, func(columns []string) bool { return providesIndex(columns, expected.Columns) }) {
+			missing = append(missing, expected)
+		}
+	}
+	return missing, nil
+}
+
+// providesIndex reports whether an index on columns serves queries that need an index on
+// expected: it does if expected are its leading columns.
+func providesIndex(columns, expected []string) bool {
+	return len(columns) >= len(expected) && slices.Equal(columns[:len(expected)], expected)
+}
+
+func (s *diagnosticsService) Readiness() *models.Readiness {
+	readiness := &models.Readiness{Status: models.ReadinessReady, Checks: map[string]string{}}
+	if err := s.diagnosticsRepo.Ping(); err != nil {
+		utils.LogError(err, "Readiness: database ping failed")
+		readiness.Status = models.ReadinessUnavailable
+		readiness.Checks["database"] = "unreachable"
+		return readiness
+	}
+	readiness.Checks["database"] = "ok"
+
+	missing, err := s.MissingIndexes()
+	if err != nil {
+		utils.LogError(err, "Readiness: failed to check indexes")
+		readiness.Checks["indexes"] = "unknown"
+		return readiness
+	}
+	if len(missing) == 0 {
+		readiness.Checks["indexes"] = "ok"
+		return readiness
+	}
+	readiness.Checks["indexes"] = fmt.Sprintf("%d missing", len(missing))
+	for _, index := range missing {
+		readiness.Warnings = append(readiness.Warnings, "missing index on "+index.String()+", used by "+index.Purpose)
+	}
+	return readiness
+}
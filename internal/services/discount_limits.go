@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"ps_club_backend/internal/models"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+// ErrDiscountLimitExceeded is returned when an order discount is larger than the
+// caller's role may grant; a manager override (approval PIN) lifts the limit.
+var ErrDiscountLimitExceeded = apperrors.New(utils.ErrCodeDiscountLimitExceeded, "discount exceeds the limit of your role")
+
+var (
+	discountLimits   = models.DiscountLimits{}
+	discountLimitsMu sync.RWMutex
+)
+
+// SetDiscountLimits sets the per-role discount limits (the discount_limits setting).
+func SetDiscountLimits(limits models.DiscountLimits) {
+	discountLimitsMu.Lock()
+	defer discountLimitsMu.Unlock()
+	discountLimits = limits
+}
+
+// CurrentDiscountLimits returns the configured per-role discount limits.
+func CurrentDiscountLimits() models.DiscountLimits {
+	discountLimitsMu.RLock()
+	defer discountLimitsMu.RUnlock()
+	return discountLimits
+}
+
+// checkDiscountLimit returns ErrDiscountLimitExceeded if role may not grant discount
+// on an order totalling total. Roles without a configured limit are not limited.
+func checkDiscountLimit(role string, discount, total models.Money) error {
+	limit, ok := CurrentDiscountLimits().ForRole(role)
+	if !ok || limit.Allows(discount, total) {
+		return nil
+	}
+	return fmt.Errorf("%w (role %s)", ErrDiscountLimitExceeded, role)
+}
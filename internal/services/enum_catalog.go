@@ -0,0 +1,423 @@
+package services
+
+import (
+	"ps_club_backend/internal/models"
+	"ps_club_backend/pkg/utils"
+)
+
+// Enum names in the catalog returned by GET /meta/enums.
+const (
+	EnumOrderStatuses       = "order_statuses"
+	EnumBookingStatuses     = "booking_statuses"
+	EnumItemTypes           = "item_types"
+	EnumMovementTypes       = "movement_types"
+	EnumManualMovementTypes = "manual_movement_types"
+	EnumCancellationReasons = "cancellation_reasons"
+	EnumVoidReasons         = "void_reasons"
+	EnumNoteCategories      = "calendar_note_categories"
+	EnumConsoleTypes        = "console_types"
+	EnumBillingModes        = "billing_modes"
+	EnumIncidentTypes       = "incident_types"
+	EnumIncidentSeverities  = "incident_severities"
+	EnumIncidentStatuses    = "incident_statuses"
+	EnumClientPresetTags    = "client_preset_tags"
+	EnumClientDocumentTypes = "client_document_types"
+	EnumStaffDocumentTypes  = "staff_document_types"
+	EnumTimeOffStatuses     = "time_off_statuses"
+	EnumAvailabilityPrefs   = "availability_preferences"
+	EnumAttendanceAnomalies = "attendance_anomaly_types"
+	EnumClockInViolations   = "clock_in_violation_reasons"
+	EnumFloorPlanShapes     = "floor_plan_shapes"
+	EnumTableLiveStatuses   = "table_live_statuses"
+	EnumTableStatuses       = "table_statuses"
+	EnumDepositMethods      = "deposit_methods"
+	EnumDepositStatuses     = "deposit_statuses"
+	EnumDeviceTypes         = "device_types"
+	EnumDeviceOrderTypes    = "device_order_types"
+	EnumDeviceStatuses      = "device_statuses"
+)
+
+// EnumValue is a valid value of an enum with its label in the requested language.
+type EnumValue struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// enumValues returns the values of each enum, taken from the lists the services validate against.
+func enumValues() map[string][]string {
+	bookingStatuses := make([]string, len(models.BookingStatuses))
+	for i, status := range models.BookingStatuses {
+		bookingStatuses[i] = string(status)
+	}
+	return map[string][]string{
+		EnumOrderStatuses:       OrderStatuses,
+		EnumBookingStatuses:     bookingStatuses,
+		EnumItemTypes:           models.ItemTypes,
+		EnumMovementTypes:       models.MovementTypes,
+		EnumManualMovementTypes: models.ManualMovementTypes,
+		EnumCancellationReasons: models.CancellationReasons,
+		EnumVoidReasons:         models.VoidReasons,
+		EnumNoteCategories:      models.CalendarNoteCategories,
+		EnumConsoleTypes:        models.ConsoleTypes,
+		EnumBillingModes:        models.BillingModes,
+		EnumIncidentTypes:       models.IncidentTypes,
+		EnumIncidentSeverities:  models.IncidentSeverities,
+		EnumIncidentStatuses:    models.IncidentStatuses,
+		EnumClientPresetTags:    models.ClientPresetTags,
+		EnumClientDocumentTypes: models.ClientDocumentTypes,
+		EnumStaffDocumentTypes:  models.StaffDocumentTypes,
+		EnumTimeOffStatuses:     models.TimeOffStatuses,
+		EnumAvailabilityPrefs:   models.AvailabilityPreferences,
+		EnumAttendanceAnomalies: models.AttendanceAnomalyTypes,
+		EnumClockInViolations:   models.ClockInViolationReasons,
+		EnumFloorPlanShapes:     models.FloorPlanShapes,
+		EnumTableLiveStatuses:   models.TableLiveStatuses,
+		EnumTableStatuses:       models.TableStatuses,
+		EnumDepositMethods:      models.DepositMethods,
+		EnumDepositStatuses:     models.DepositStatuses,
+		EnumDeviceTypes:         models.DeviceTypes,
+		EnumDeviceOrderTypes:    models.DeviceOrderTypes,
+		EnumDeviceStatuses:      models.DeviceStatuses,
+	}
+}
+
+// enumLabels holds the labels of enum values by language, enum and value. Order and booking
+// statuses share values, so labels are looked up per enum; manual movement types use the
+// labels of movement types.
+var enumLabels = map[string]map[string]map[string]string{
+	utils.LanguageEnglish: {
+		EnumOrderStatuses: {
+			StatusPending: "Pending", StatusPreparing: "Preparing", StatusReady: "Ready", StatusServed: "Served",
+			StatusCompleted: "Completed", StatusPaid: "Paid", StatusCancelled: "Cancelled", StatusRefunded: "Refunded",
+		},
+		EnumBookingStatuses: {
+			string(models.BookingStatusPending): "Pending", string(models.BookingStatusConfirmed): "Confirmed",
+			string(models.BookingStatusCompleted): "Completed", string(models.BookingStatusCancelled): "Cancelled",
+			string(models.BookingStatusNoShow): "No-show",
+		},
+		EnumItemTypes: {
+			models.ItemTypeBar: "Bar", models.ItemTypeHookah: "Hookah", models.ItemTypeSnack: "Snack", models.ItemTypeService: "Service",
+		},
+		EnumMovementTypes: {
+			models.MovementTypePurchase: "Purchase", models.MovementTypeAdjustmentIn: "Adjustment (in)", models.MovementTypeAdjustmentOut: "Adjustment (out)",
+			models.MovementTypeSpoilage: "Spoilage", models.MovementTypeSale: "Sale", models.MovementTypeReturnCancellation: "Return (order cancelled)",
+			models.MovementTypeReturnDeletion: "Return (order deleted)", models.MovementTypeComponentUsage: "Used in service",
+			models.MovementTypeStocktakeAdjustment: "Stocktake adjustment", models.MovementTypeTransferIn: "Transfer (in)",
+			models.MovementTypeTransferOut: "Transfer (out)",
+		},
+		EnumCancellationReasons: {
+			models.CancellationReasonClientRequest: "Client request", models.CancellationReasonClientUnreachable: "Client unreachable",
+			models.CancellationReasonScheduleConflict: "Schedule conflict", models.CancellationReasonEquipmentIssue: "Equipment issue",
+			models.CancellationReasonClubClosure: "Club closure", models.CancellationReasonDuplicate: "Duplicate booking",
+			models.CancellationReasonOther: "Other",
+		},
+		EnumVoidReasons: {
+			models.VoidReasonCustomerRequest: "Customer request", models.VoidReasonWrongItem: "Wrong item",
+			models.VoidReasonQualityIssue: "Quality issue", models.VoidReasonSpilled: "Spilled", models.VoidReasonDuplicate: "Duplicate",
+			models.VoidReasonWalkout: "Walkout", models.VoidReasonOther: "Other", models.VoidReasonDayClose: "Day close",
+		},
+		EnumNoteCategories: {
+			models.CalendarNoteCityEvent: "City event", models.CalendarNoteSchoolHoliday: "School holidays",
+			models.CalendarNotePublicHoliday: "Public holiday", models.CalendarNoteMarketing: "Marketing", models.CalendarNoteOther: "Other",
+		},
+		// Product names, used in every language
+		EnumConsoleTypes: {
+			models.ConsoleTypePS5: "PlayStation 5", models.ConsoleTypePS4: "PlayStation 4", models.ConsoleTypeVR: "VR",
+		},
+		EnumBillingModes: {models.BillingModeHourly: "Hourly", models.BillingModePerMinute: "Per minute"},
+		EnumIncidentTypes: {
+			models.IncidentTypeEquipmentDamage: "Equipment damage", models.IncidentTypeClientMisconduct: "Client misconduct",
+			models.IncidentTypeCashDiscrepancy: "Cash discrepancy", models.IncidentTypeOther: "Other",
+		},
+		EnumIncidentSeverities: {
+			models.IncidentSeverityLow: "Low", models.IncidentSeverityMedium: "Medium", models.IncidentSeverityHigh: "High",
+			models.IncidentSeverityCritical: "Critical",
+		},
+		EnumIncidentStatuses: {
+			models.IncidentStatusOpen: "Open", models.IncidentStatusConfirmed: "Confirmed", models.IncidentStatusDismissed: "Dismissed",
+		},
+		EnumClientPresetTags: {
+			models.ClientTagVIP: "VIP", models.ClientTagTournamentPlayer: "Tournament player", models.ClientTagBlacklistWatch: "Blacklist watch",
+		},
+		EnumClientDocumentTypes: {
+			models.ClientDocumentTournamentConsent: "Tournament consent", models.ClientDocumentNightAccessConsent: "Night access consent",
+			models.ClientDocumentID: "ID document",
+		},
+		EnumStaffDocumentTypes: {
+			models.StaffDocumentContract: "Employment contract", models.StaffDocumentMedicalBook: "Medical book",
+			models.StaffDocumentCertificate: "Certificate",
+		},
+		EnumTimeOffStatuses: {
+			models.TimeOffPending: "Pending", models.TimeOffApproved: "Approved", models.TimeOffRejected: "Rejected",
+			models.TimeOffCancelled: "Cancelled",
+		},
+		EnumAvailabilityPrefs: {
+			models.AvailabilityPreferred: "Preferred", models.AvailabilityUnavailable: "Unavailable",
+		},
+		EnumAttendanceAnomalies: {
+			models.AttendanceLateArrival: "Late arrival", models.AttendanceEarlyDeparture: "Early departure",
+			models.AttendanceMissedShift: "Missed shift",
+		},
+		EnumClockInViolations: {
+			models.ClockInIPNotAllowed: "Outside the club network", models.ClockInLocationMissing: "Location missing",
+			models.ClockInOutsideGeofence: "Away from the club",
+		},
+		EnumFloorPlanShapes: {
+			models.FloorPlanShapeRectangle: "Rectangle", models.FloorPlanShapeCircle: "Circle",
+		},
+		EnumTableLiveStatuses: {
+			models.TableLiveFree: "Free", models.TableLiveBusy: "Busy", models.TableLiveBookedSoon: "Booked soon",
+			models.TableLiveMaintenance: "Maintenance",
+		},
+		EnumTableStatuses: {
+			models.TableStatusAvailable: "Available", models.TableStatusOccupied: "Occupied", models.TableStatusReserved: "Reserved",
+			models.TableStatusCleaning: "Cleaning", models.TableStatusMaintenance: "Maintenance",
+		},
+		EnumDepositMethods: {
+			models.DepositMethodCash: "Cash", models.DepositMethodCardPreAuth: "Card pre-authorization",
+		},
+		EnumDepositStatuses: {
+			models.DepositStatusHeld: "Held", models.DepositStatusCapturing: "Capturing", models.DepositStatusFailed: "Capture failed",
+			models.DepositStatusSettled: "Settled",
+		},
+		EnumDeviceTypes: {
+			models.DeviceTypePOS: "POS terminal", models.DeviceTypeKiosk: "Kiosk", models.DeviceTypeBarScreen: "Bar screen",
+		},
+		EnumDeviceOrderTypes: {
+			models.DeviceOrderTypeTable: "Table orders", models.DeviceOrderTypeCounter: "Counter orders",
+			models.DeviceOrderTypeQuickSale: "Quick sales",
+		},
+		EnumDeviceStatuses: {
+			models.DeviceStatusOnline: "Online", models.DeviceStatusOffline: "Offline",
+		},
+	},
+	utils.LanguageRussian: {
+		EnumOrderStatuses: {
+			StatusPending: "Ожидает", StatusPreparing: "Готовится", StatusReady: "Готов", StatusServed: "Подан",
+			StatusCompleted: "Завершён", StatusPaid: "Оплачен", StatusCancelled: "Отменён", StatusRefunded: "Возврат",
+		},
+		EnumBookingStatuses: {
+			string(models.BookingStatusPending): "Ожидает", string(models.BookingStatusConfirmed): "Подтверждено",
+			string(models.BookingStatusCompleted): "Завершено", string(models.BookingStatusCancelled): "Отменено",
+			string(models.BookingStatusNoShow): "Неявка",
+		},
+		EnumItemTypes: {
+			models.ItemTypeBar: "Бар", models.ItemTypeHookah: "Кальян", models.ItemTypeSnack: "Закуски", models.ItemTypeService: "Услуга",
+		},
+		EnumMovementTypes: {
+			models.MovementTypePurchase: "Закупка", models.MovementTypeAdjustmentIn: "Корректировка (приход)", models.MovementTypeAdjustmentOut: "Корректировка (расход)",
+			models.MovementTypeSpoilage: "Списание", models.MovementTypeSale: "Продажа", models.MovementTypeReturnCancellation: "Возврат (заказ отменён)",
+			models.MovementTypeReturnDeletion: "Возврат (заказ удалён)", models.MovementTypeComponentUsage: "Расход на обслуживание",
+			models.MovementTypeStocktakeAdjustment: "Корректировка по инвентаризации", models.MovementTypeTransferIn: "Перемещение (приход)",
+			models.MovementTypeTransferOut: "Перемещение (расход)",
+		},
+		EnumCancellationReasons: {
+			models.CancellationReasonClientRequest: "По просьбе клиента", models.CancellationReasonClientUnreachable: "Клиент недоступен",
+			models.CancellationReasonScheduleConflict: "Накладка в расписании", models.CancellationReasonEquipmentIssue: "Неисправность оборудования",
+			models.CancellationReasonClubClosure: "Клуб закрыт", models.CancellationReasonDuplicate: "Повторное бронирование",
+			models.CancellationReasonOther: "Другое",
+		},
+		EnumVoidReasons: {
+			models.VoidReasonCustomerRequest: "По просьбе клиента", models.VoidReasonWrongItem: "Ошибочная позиция",
+			models.VoidReasonQualityIssue: "Проблема с качеством", models.VoidReasonSpilled: "Пролито", models.VoidReasonDuplicate: "Дубликат",
+			models.VoidReasonWalkout: "Ушёл без оплаты", models.VoidReasonOther: "Другое", models.VoidReasonDayClose: "Закрытие дня",
+		},
+		EnumNoteCategories: {
+			models.CalendarNoteCityEvent: "Городское мероприятие", models.CalendarNoteSchoolHoliday: "Школьные каникулы",
+			models.CalendarNotePublicHoliday: "Праздничный день", models.CalendarNoteMarketing: "Маркетинг", models.CalendarNoteOther: "Другое",
+		},
+		EnumBillingModes: {models.BillingModeHourly: "Почасовая", models.BillingModePerMinute: "Поминутная"},
+		EnumIncidentTypes: {
+			models.IncidentTypeEquipmentDamage: "Поломка оборудования", models.IncidentTypeClientMisconduct: "Нарушение клиентом",
+			models.IncidentTypeCashDiscrepancy: "Расхождение в кассе", models.IncidentTypeOther: "Другое",
+		},
+		EnumIncidentSeverities: {
+			models.IncidentSeverityLow: "Низкая", models.IncidentSeverityMedium: "Средняя", models.IncidentSeverityHigh: "Высокая",
+			models.IncidentSeverityCritical: "Критическая",
+		},
+		EnumIncidentStatuses: {
+			models.IncidentStatusOpen: "На рассмотрении", models.IncidentStatusConfirmed: "Подтверждён", models.IncidentStatusDismissed: "Отклонён",
+		},
+		EnumClientPresetTags: {
+			models.ClientTagVIP: "VIP", models.ClientTagTournamentPlayer: "Участник турниров", models.ClientTagBlacklistWatch: "Под наблюдением",
+		},
+		EnumClientDocumentTypes: {
+			models.ClientDocumentTournamentConsent: "Согласие на участие в турнирах", models.ClientDocumentNightAccessConsent: "Согласие на ночное посещение",
+			models.ClientDocumentID: "Удостоверение личности",
+		},
+		EnumStaffDocumentTypes: {
+			models.StaffDocumentContract: "Трудовой договор", models.StaffDocumentMedicalBook: "Медицинская книжка",
+			models.StaffDocumentCertificate: "Сертификат",
+		},
+		EnumTimeOffStatuses: {
+			models.TimeOffPending: "На рассмотрении", models.TimeOffApproved: "Одобрено", models.TimeOffRejected: "Отклонено",
+			models.TimeOffCancelled: "Отменено",
+		},
+		EnumAvailabilityPrefs: {
+			models.AvailabilityPreferred: "Предпочтительно", models.AvailabilityUnavailable: "Недоступен",
+		},
+		EnumAttendanceAnomalies: {
+			models.AttendanceLateArrival: "Опоздание", models.AttendanceEarlyDeparture: "Ранний уход",
+			models.AttendanceMissedShift: "Пропуск смены",
+		},
+		EnumClockInViolations: {
+			models.ClockInIPNotAllowed: "Вне сети клуба", models.ClockInLocationMissing: "Нет геопозиции",
+			models.ClockInOutsideGeofence: "Вдали от клуба",
+		},
+		EnumFloorPlanShapes: {
+			models.FloorPlanShapeRectangle: "Прямоугольник", models.FloorPlanShapeCircle: "Круг",
+		},
+		EnumTableLiveStatuses: {
+			models.TableLiveFree: "Свободен", models.TableLiveBusy: "Занят", models.TableLiveBookedSoon: "Скоро бронь",
+			models.TableLiveMaintenance: "Обслуживание",
+		},
+		EnumTableStatuses: {
+			models.TableStatusAvailable: "Свободен", models.TableStatusOccupied: "Занят", models.TableStatusReserved: "Забронирован",
+			models.TableStatusCleaning: "Уборка", models.TableStatusMaintenance: "Обслуживание",
+		},
+		EnumDepositMethods: {
+			models.DepositMethodCash: "Наличные", models.DepositMethodCardPreAuth: "Предавторизация карты",
+		},
+		EnumDepositStatuses: {
+			models.DepositStatusHeld: "Удерживается", models.DepositStatusCapturing: "Списывается", models.DepositStatusFailed: "Ошибка списания",
+			models.DepositStatusSettled: "Рассчитан",
+		},
+		EnumDeviceTypes: {
+			models.DeviceTypePOS: "POS-терминал", models.DeviceTypeKiosk: "Киоск", models.DeviceTypeBarScreen: "Экран бара",
+		},
+		EnumDeviceOrderTypes: {
+			models.DeviceOrderTypeTable: "Заказы к столу", models.DeviceOrderTypeCounter: "Заказы у стойки",
+			models.DeviceOrderTypeQuickSale: "Быстрые продажи",
+		},
+		EnumDeviceStatuses: {
+			models.DeviceStatusOnline: "В сети", models.DeviceStatusOffline: "Не в сети",
+		},
+	},
+	utils.LanguageKazakh: {
+		EnumOrderStatuses: {
+			StatusPending: "Күтуде", StatusPreparing: "Дайындалуда", StatusReady: "Дайын", StatusServed: "Берілді",
+			StatusCompleted: "Аяқталды", StatusPaid: "Төленді", StatusCancelled: "Бас тартылды", StatusRefunded: "Қайтарылды",
+		},
+		EnumBookingStatuses: {
+			string(models.BookingStatusPending): "Күтуде", string(models.BookingStatusConfirmed): "Расталды",
+			string(models.BookingStatusCompleted): "Аяқталды", string(models.BookingStatusCancelled): "Бас тартылды",
+			string(models.BookingStatusNoShow): "Келмеді",
+		},
+		EnumItemTypes: {
+			models.ItemTypeBar: "Бар", models.ItemTypeHookah: "Кальян", models.ItemTypeSnack: "Тағамдар", models.ItemTypeService: "Қызмет",
+		},
+		EnumMovementTypes: {
+			models.MovementTypePurchase: "Сатып алу", models.MovementTypeAdjustmentIn: "Түзету (кіріс)", models.MovementTypeAdjustmentOut: "Түзету (шығыс)",
+			models.MovementTypeSpoilage: "Есептен шығару", models.MovementTypeSale: "Сату", models.MovementTypeReturnCancellation: "Қайтару (тапсырыс бас тартылды)",
+			models.MovementTypeReturnDeletion: "Қайтару (тапсырыс жойылды)", models.MovementTypeComponentUsage: "Қызмет көрсетуге жұмсалды",
+			models.MovementTypeStocktakeAdjustment: "Түгендеу бойынша түзету", models.MovementTypeTransferIn: "Орын ауыстыру (кіріс)",
+			models.MovementTypeTransferOut: "Орын ауыстыру (шығыс)",
+		},
+		EnumCancellationReasons: {
+			models.CancellationReasonClientRequest: "Клиенттің өтініші", models.CancellationReasonClientUnreachable: "Клиентпен байланыс жоқ",
+			models.CancellationReasonScheduleConflict: "Кестедегі қайшылық", models.CancellationReasonEquipmentIssue: "Жабдық ақауы",
+			models.CancellationReasonClubClosure: "Клуб жабық", models.CancellationReasonDuplicate: "Қайталанған брондау",
+			models.CancellationReasonOther: "Басқа",
+		},
+		EnumVoidReasons: {
+			models.VoidReasonCustomerRequest: "Клиенттің өтініші", models.VoidReasonWrongItem: "Қате позиция",
+			models.VoidReasonQualityIssue: "Сапа мәселесі", models.VoidReasonSpilled: "Төгілді", models.VoidReasonDuplicate: "Қайталама",
+			models.VoidReasonWalkout: "Төлемей кетті", models.VoidReasonOther: "Басқа", models.VoidReasonDayClose: "Күнді жабу",
+		},
+		EnumNoteCategories: {
+			models.CalendarNoteCityEvent: "Қалалық іс-шара", models.CalendarNoteSchoolHoliday: "Мектеп демалысы",
+			models.CalendarNotePublicHoliday: "Мереке күні", models.CalendarNoteMarketing: "Маркетинг", models.CalendarNoteOther: "Басқа",
+		},
+		EnumBillingModes: {models.BillingModeHourly: "Сағаттық", models.BillingModePerMinute: "Минуттық"},
+		EnumIncidentTypes: {
+			models.IncidentTypeEquipmentDamage: "Жабдықтың бүлінуі", models.IncidentTypeClientMisconduct: "Клиенттің тәртіп бұзуы",
+			models.IncidentTypeCashDiscrepancy: "Кассадағы айырмашылық", models.IncidentTypeOther: "Басқа",
+		},
+		EnumIncidentSeverities: {
+			models.IncidentSeverityLow: "Төмен", models.IncidentSeverityMedium: "Орташа", models.IncidentSeverityHigh: "Жоғары",
+			models.IncidentSeverityCritical: "Сыни",
+		},
+		EnumIncidentStatuses: {
+			models.IncidentStatusOpen: "Қаралуда", models.IncidentStatusConfirmed: "Расталды", models.IncidentStatusDismissed: "Қабылданбады",
+		},
+		EnumClientPresetTags: {
+			models.ClientTagVIP: "VIP", models.ClientTagTournamentPlayer: "Турнир қатысушысы", models.ClientTagBlacklistWatch: "Бақылауда",
+		},
+		EnumClientDocumentTypes: {
+			models.ClientDocumentTournamentConsent: "Турнирге қатысуға келісім", models.ClientDocumentNightAccessConsent: "Түнгі келуге келісім",
+			models.ClientDocumentID: "Жеке куәлік",
+		},
+		EnumStaffDocumentTypes: {
+			models.StaffDocumentContract: "Еңбек шарты", models.StaffDocumentMedicalBook: "Медициналық кітапша",
+			models.StaffDocumentCertificate: "Сертификат",
+		},
+		EnumTimeOffStatuses: {
+			models.TimeOffPending: "Қаралуда", models.TimeOffApproved: "Мақұлданды", models.TimeOffRejected: "Қабылданбады",
+			models.TimeOffCancelled: "Бас тартылды",
+		},
+		EnumAvailabilityPrefs: {
+			models.AvailabilityPreferred: "Қолайлы", models.AvailabilityUnavailable: "Қолжетімсіз",
+		},
+		EnumAttendanceAnomalies: {
+			models.AttendanceLateArrival: "Кешігу", models.AttendanceEarlyDeparture: "Ерте кету",
+			models.AttendanceMissedShift: "Ауысымды өткізу",
+		},
+		EnumClockInViolations: {
+			models.ClockInIPNotAllowed: "Клуб желісінен тыс", models.ClockInLocationMissing: "Геолокация жоқ",
+			models.ClockInOutsideGeofence: "Клубтан алыс",
+		},
+		EnumFloorPlanShapes: {
+			models.FloorPlanShapeRectangle: "Тіктөртбұрыш", models.FloorPlanShapeCircle: "Дөңгелек",
+		},
+		EnumTableLiveStatuses: {
+			models.TableLiveFree: "Бос", models.TableLiveBusy: "Бос емес", models.TableLiveBookedSoon: "Жақында брондалған",
+			models.TableLiveMaintenance: "Қызмет көрсету",
+		},
+		EnumTableStatuses: {
+			models.TableStatusAvailable: "Бос", models.TableStatusOccupied: "Бос емес", models.TableStatusReserved: "Брондалған",
+			models.TableStatusCleaning: "Тазалау", models.TableStatusMaintenance: "Қызмет көрсету",
+		},
+		EnumDepositMethods: {
+			models.DepositMethodCash: "Қолма-қол", models.DepositMethodCardPreAuth: "Картаны алдын ала авторизациялау",
+		},
+		EnumDepositStatuses: {
+			models.DepositStatusHeld: "Ұсталуда", models.DepositStatusCapturing: "Есептен шығарылуда", models.DepositStatusFailed: "Есептен шығару қатесі",
+			models.DepositStatusSettled: "Есептелді",
+		},
+		EnumDeviceTypes: {
+			models.DeviceTypePOS: "POS-терминал", models.DeviceTypeKiosk: "Киоск", models.DeviceTypeBarScreen: "Бар экраны",
+		},
+		EnumDeviceOrderTypes: {
+			models.DeviceOrderTypeTable: "Үстелге тапсырыстар", models.DeviceOrderTypeCounter: "Сөредегі тапсырыстар",
+			models.DeviceOrderTypeQuickSale: "Жылдам сатулар",
+		},
+		EnumDeviceStatuses: {
+			models.DeviceStatusOnline: "Желіде", models.DeviceStatusOffline: "Желіде емес",
+		},
+	},
+}
+
+// EnumCatalog returns every enum with its valid values labelled in lang. Values without a
+// label in lang fall back to the English label, then to the value itself.
+func EnumCatalog(lang string) map[string][]EnumValue {
+	catalog := make(map[string][]EnumValue)
+	for name, values := range enumValues() {
+		labelsOf := name
+		if name == EnumManualMovementTypes {
+			labelsOf = EnumMovementTypes
+		}
+		entries := make([]EnumValue, len(values))
+		for i, value := range values {
+			label, ok := enumLabels[lang][labelsOf][value]
+			if !ok {
+				label, ok = enumLabels[utils.LanguageEnglish][labelsOf][value]
+			}
+			if !ok {
+				label = value
+			}
+			entries[i] = EnumValue{Value: value, Label: label}
+		}
+		catalog[name] = entries
+	}
+	return catalog
+}
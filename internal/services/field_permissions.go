@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ps_club_backend/internal/models"
+)
+
+// ErrFieldNotPermitted is returned when an update changes fields the caller's role may not change.
+var ErrFieldNotPermitted = errors.New("your role may not change these fields")
+
+// FieldPermissionError lists the fields of an update the caller's role may not change.
+// It matches ErrFieldNotPermitted with errors.Is.
+type FieldPermissionError struct {
+	Role   string
+	Fields []string // JSON names of the offending fields
+}
+
+func (e *FieldPermissionError) Error() string {
+	return fmt.Sprintf("%s (role %s): %s", ErrFieldNotPermitted.Error(), e.Role, strings.Join(e.Fields, ", "))
+}
+
+// Is reports whether target is ErrFieldNotPermitted.
+func (e *FieldPermissionError) Is(target error) bool { return target == ErrFieldNotPermitted }
+
+// fieldRules maps the JSON name of a field to the roles that may change it.
+// Fields that are not listed may be changed by every role allowed on the route.
+type fieldRules map[string][]string
+
+// staffFieldRules guards the HR fields of a staff member.
+var staffFieldRules = fieldRules{
+	"salary":    {"Admin"},
+	"position":  {"Admin"},
+	"hire_date": {"Admin"},
+}
+
+// pricelistItemFieldRules lets Staff maintain items (e.g. availability and stock) but not prices
+// or how they are taxed.
+var pricelistItemFieldRules = fieldRules{
+	"price":     {"Admin"},
+	"tax_class": {"Admin"},
+}
+
+// check returns a *FieldPermissionError listing the changed fields role may not change.
+// An empty role is an internal caller and is not restricted.
+func (r fieldRules) check(role string, changed []string) error {
+	if role == "" {
+		return nil
+	}
+	var denied []string
+	for _, field := range changed {
+		roles, ok := r[field]
+		if !ok {
+			continue
+		}
+		allowed := false
+		for _, allowedRole := range roles {
+			if strings.EqualFold(allowedRole, role) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		return &FieldPermissionError{Role: role, Fields: denied}
+	}
+	return nil
+}
+
+// moneyChanged reports whether an update to next changes current. Sending the
+// current value again (e.g. a client that PUTs the whole record) is not a change.
+func moneyChanged(current *models.Money, next *models.Money) bool {
+	if next == nil {
+		return false
+	}
+	return current == nil || current.Cmp(*next) != 0
+}
+
+func stringChanged(current *string, next *string) bool {
+	if next == nil {
+		return false
+	}
+	return current == nil || *current != *next
+}
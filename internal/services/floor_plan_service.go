@@ -0,0 +1,218 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrFloorPlanValidation    = apperrors.New(utils.ErrCodeValidationFailed, "floor plan validation error")
+	ErrFloorPlanTableNotFound = apperrors.New(utils.ErrCodeNotFound, "game table not found")
+	ErrFloorPlanNotPlaced     = apperrors.New(utils.ErrCodeNotFound, "the table is not on the floor plan")
+	ErrFloorPlanNotSaved      = apperrors.New(utils.ErrCodeConflict, "save the floor plan with its canvas size first")
+)
+
+// Bounds of the floor plan.
+const (
+	DefaultFloorPlanSoonMinutes = 60 // A booking starting this soon marks its table booked_soon
+	maxFloorPlanSoonMinutes     = 24 * 60
+	maxFloorPlanCanvas          = 100000 // Canvas units per side
+	maxFloorPlanZoneLength      = 50
+)
+
+// FloorPlanRequest is the body of PUT /floor-plan.
+type FloorPlanRequest struct {
+	Width  float64                 `json:"width" binding:"required"`
+	Height float64                 `json:"height" binding:"required"`
+	Tables []models.FloorPlanTable `json:"tables"`
+}
+
+// --- FloorPlanService Interface ---
+type FloorPlanService interface {
+	// GetFloorPlan returns the canvas and every game table with its position and live status.
+	// A table is booked_soon when a booking starts within soonMinutes.
+	GetFloorPlan(soonMinutes int) (*models.FloorPlan, error)
+	// SaveFloorPlan replaces the canvas and the positions of all tables; tables left out are
+	// taken off the plan.
+	SaveFloorPlan(req FloorPlanRequest, adminUserID int64) (*models.FloorPlan, error)
+	// SaveTable places a table on the saved plan or moves it.
+	SaveTable(table models.FloorPlanTable) (*models.FloorPlan, error)
+	// RemoveTable takes a table off the plan.
+	RemoveTable(tableID int64) error
+}
+
+type floorPlanService struct {
+	floorPlanRepo repositories.FloorPlanRepository
+	db            *sql.DB
+}
+
+// NewFloorPlanService creates a new FloorPlanService.
+func NewFloorPlanService(floorPlanRepo repositories.FloorPlanRepository, db *sql.DB) FloorPlanService {
+	return &floorPlanService{floorPlanRepo: floorPlanRepo, db: db}
+}
+
+func (s *floorPlanService) GetFloorPlan(soonMinutes int) (*models.FloorPlan, error) {
+	if soonMinutes < 0 || soonMinutes > maxFloorPlanSoonMinutes {
+		return nil, fmt.Errorf("%w: soon_minutes must be between 0 and %d", ErrFloorPlanValidation, maxFloorPlanSoonMinutes)
+	}
+	plan, err := s.floorPlanRepo.GetCanvas()
+	if errors.Is(err, repositories.ErrNotFound) {
+		plan = &models.FloorPlan{}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get floor plan: %w", err)
+	}
+	now := utils.NowUTC()
+	tables, err := s.floorPlanRepo.GetTables(now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get floor plan tables: %w", err)
+	}
+
+	soon := now.Add(time.Duration(soonMinutes) * time.Minute)
+	plan.GeneratedAt = now
+	plan.Zones = []string{}
+	plan.Tables = []models.FloorPlanTableStatus{}
+	plan.UnplacedTables = []models.FloorPlanTableStatus{}
+	for _, table := range tables {
+		table.Status = liveTableStatus(table, soon)
+		if !table.Placed {
+			plan.UnplacedTables = append(plan.UnplacedTables, table)
+			continue
+		}
+		if table.Zone != nil && !slices.Contains(plan.Zones, *table.Zone) {
+			plan.Zones = append(plan.Zones, *table.Zone)
+		}
+		plan.Tables = append(plan.Tables, table)
+	}
+	sort.Strings(plan.Zones)
+	return plan, nil
+}
+
+// liveTableStatus returns what is happening at a table: a running session makes it busy, a
+// booking starting before soon (or already started) booked_soon.
+func liveTableStatus(table models.FloorPlanTableStatus, soon time.Time) string {
+	switch {
+	case table.SessionID != nil:
+		return models.TableLiveBusy
+	case table.TableStatus == models.TableStatusMaintenance:
+		return models.TableLiveMaintenance
+	case table.NextBookingStart != nil && !table.NextBookingStart.After(soon):
+		return models.TableLiveBookedSoon
+	default:
+		return models.TableLiveFree
+	}
+}
+
+// validateFloorPlanTable checks the position of a table on a canvas of width by height and
+// normalizes its shape and zone.
+func validateFloorPlanTable(table *models.FloorPlanTable, width, height float64) error {
+	if table.TableID <= 0 {
+		return fmt.Errorf("%w: table_id is required", ErrFloorPlanValidation)
+	}
+	for _, value := range []float64{table.X, table.Y, table.Width, table.Height, table.Rotation} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("%w: the position of table %d must be finite", ErrFloorPlanValidation, table.TableID)
+		}
+	}
+	if table.Width <= 0 || table.Height <= 0 {
+		return fmt.Errorf("%w: the width and height of table %d must be positive", ErrFloorPlanValidation, table.TableID)
+	}
+	if table.X < 0 || table.Y < 0 || table.X+table.Width > width || table.Y+table.Height > height {
+		return fmt.Errorf("%w: table %d must lie within the %gx%g canvas", ErrFloorPlanValidation, table.TableID, width, height)
+	}
+	table.Rotation = math.Mod(table.Rotation, 360)
+	if table.Rotation < 0 {
+		table.Rotation += 360
+	}
+	table.Shape = strings.TrimSpace(table.Shape)
+	if table.Shape == "" {
+		table.Shape = models.FloorPlanShapeRectangle
+	}
+	if !slices.Contains(models.FloorPlanShapes, table.Shape) {
+		return fmt.Errorf("%w: invalid shape '%s', must be one of %v", ErrFloorPlanValidation, table.Shape, models.FloorPlanShapes)
+	}
+	if table.Zone != nil {
+		zone := strings.TrimSpace(*table.Zone)
+		if len([]rune(zone)) > maxFloorPlanZoneLength {
+			return fmt.Errorf("%w: zone cannot be longer than %d characters", ErrFloorPlanValidation, maxFloorPlanZoneLength)
+		}
+		table.Zone = &zone
+		if zone == "" {
+			table.Zone = nil
+		}
+	}
+	return nil
+}
+
+func (s *floorPlanService) SaveFloorPlan(req FloorPlanRequest, adminUserID int64) (*models.FloorPlan, error) {
+	if req.Width <= 0 || req.Height <= 0 || req.Width > maxFloorPlanCanvas || req.Height > maxFloorPlanCanvas {
+		return nil, fmt.Errorf("%w: the canvas width and height must be between 0 and %d", ErrFloorPlanValidation, maxFloorPlanCanvas)
+	}
+	seen := make(map[int64]bool, len(req.Tables))
+	for i := range req.Tables {
+		if err := validateFloorPlanTable(&req.Tables[i], req.Width, req.Height); err != nil {
+			return nil, err
+		}
+		if seen[req.Tables[i].TableID] {
+			return nil, fmt.Errorf("%w: table %d is placed twice", ErrFloorPlanValidation, req.Tables[i].TableID)
+		}
+		seen[req.Tables[i].TableID] = true
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.floorPlanRepo.ReplaceFloorPlan(tx, req.Width, req.Height, req.Tables, adminUserID, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrFloorPlanTableNotFound, err)
+		}
+		return nil, fmt.Errorf("failed to save floor plan: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit floor plan: %w", err)
+	}
+	return s.GetFloorPlan(DefaultFloorPlanSoonMinutes)
+}
+
+func (s *floorPlanService) SaveTable(table models.FloorPlanTable) (*models.FloorPlan, error) {
+	plan, err := s.floorPlanRepo.GetCanvas()
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrFloorPlanNotSaved
+		}
+		return nil, fmt.Errorf("failed to get floor plan: %w", err)
+	}
+	if err := validateFloorPlanTable(&table, plan.Width, plan.Height); err != nil {
+		return nil, err
+	}
+	if err := s.floorPlanRepo.SaveTable(table, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrFloorPlanTableNotFound
+		}
+		return nil, fmt.Errorf("failed to place table: %w", err)
+	}
+	return s.GetFloorPlan(DefaultFloorPlanSoonMinutes)
+}
+
+func (s *floorPlanService) RemoveTable(tableID int64) error {
+	if err := s.floorPlanRepo.DeleteTable(tableID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrFloorPlanNotPlaced
+		}
+		return fmt.Errorf("failed to remove table from the floor plan: %w", err)
+	}
+	return nil
+}
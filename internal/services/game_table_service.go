@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrGameTableNotFound     = apperrors.New(utils.ErrCodeNotFound, "game table not found")
+	ErrGameTableNameTaken    = apperrors.New(utils.ErrCodeConflict, "a game table with this name already exists")
+	ErrGameTableValidation   = apperrors.New(utils.ErrCodeValidationFailed, "game table validation error")
+	ErrGameTableInUse        = apperrors.New(utils.ErrCodeConflict, "the game table has active bookings, resolve them first")
+	ErrTableStatusTransition = apperrors.New(utils.ErrCodeConflict, "the game table cannot change to this status")
+)
+
+// TableStatusSyncInterval is how often RunStatusSync derives the statuses of the tables from
+// their bookings and sessions. A table is reserved from CheckInOpensBefore the start of a booking.
+var TableStatusSyncInterval = time.Minute
+
+// SetTableStatusRequest is the body of PUT /tables/:id/status.
+type SetTableStatusRequest struct {
+	Status string `json:"status" binding:"required"` // One of models.ManualTableStatuses
+}
+
+// --- GameTableService Interface ---
+type GameTableService interface {
+	// CreateTable creates a game table, available unless created under cleaning or maintenance.
+	CreateTable(table models.GameTable) (*models.GameTable, error)
+	// GetTables lists the game tables, of a status if set.
+	GetTables(status string) ([]models.GameTable, error)
+	GetTable(id int64) (*models.GameTable, error)
+	// UpdateTable updates a game table. A status given is set as by SetStatus; none keeps the current one.
+	UpdateTable(id int64, table models.GameTable, changedBy int64) (*models.GameTable, error)
+	// DeleteTable deletes a game table without active bookings.
+	DeleteTable(id int64) error
+	// SetStatus sets the status of a game table by hand: available, cleaning or maintenance.
+	// Occupied and reserved follow the sessions and bookings of the table, and a table in use
+	// keeps its status until its session stops.
+	SetStatus(id int64, status string, changedBy int64) (*models.GameTable, error)
+	// GetOverview returns what the POS shows of a station: the table, its running session billed up
+	// to now, its pending and confirmed bookings of the club day not over yet and its open orders.
+	GetOverview(id int64) (*models.TableOverview, error)
+	// RunStatusSync derives the available, reserved and occupied statuses of the tables from
+	// their bookings and sessions until ctx is done. Every instance may run it.
+	RunStatusSync(ctx context.Context)
+}
+
+type gameTableService struct {
+	tableRepo   repositories.GameTableRepository
+	bookingRepo repositories.BookingRepository
+	orderRepo   repositories.OrderRepository
+	sessions    TableSessionService
+	publisher   events.Publisher
+	db          *sql.DB
+}
+
+// NewGameTableService creates a new GameTableService.
+func NewGameTableService(tableRepo repositories.GameTableRepository, bookingRepo repositories.BookingRepository,
+	orderRepo repositories.OrderRepository, sessions TableSessionService, publisher events.Publisher, db *sql.DB) GameTableService {
+	return &gameTableService{
+		tableRepo:   tableRepo,
+		bookingRepo: bookingRepo,
+		orderRepo:   orderRepo,
+		sessions:    sessions,
+		publisher:   publisher,
+		db:          db,
+	}
+}
+
+// gameTableError converts the errors of the game table repository.
+func gameTableError(err error, action string) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrGameTableNotFound
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		return ErrGameTableNameTaken
+	case errors.Is(err, repositories.ErrVersionConflict):
+		return ErrVersionConflict
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// applyGameTableDefaults fills in the controllers and billing of a game table being created
+// or updated that were left out, and rejects a per-minute table without a minute rate.
+func applyGameTableDefaults(table *models.GameTable) error {
+	if table.BaseControllers == 0 {
+		table.BaseControllers = models.DefaultBaseControllers
+	}
+	if table.BillingMode == "" {
+		table.BillingMode = models.BillingModeHourly
+	}
+	if table.BillingIncrementMinutes == 0 {
+		table.BillingIncrementMinutes = models.DefaultBillingIncrementMinutes
+	}
+	if table.BillingMode == models.BillingModePerMinute && table.MinuteRate == nil {
+		return fmt.Errorf("%w: minute_rate is required for per-minute billing", ErrGameTableValidation)
+	}
+	return nil
+}
+
+// validateManualTableStatus checks that the staff may set a table with status current to status.
+func validateManualTableStatus(current, status string) error {
+	if !slices.Contains(models.ManualTableStatuses, status) {
+		if models.IsValidTableStatus(status) {
+			return fmt.Errorf("%w: %s follows the bookings and sessions of the table and cannot be set", ErrGameTableValidation, status)
+		}
+		return fmt.Errorf("%w: invalid status '%s', must be one of %v", ErrGameTableValidation, status, models.ManualTableStatuses)
+	}
+	if current == models.TableStatusOccupied && status != current {
+		return fmt.Errorf("%w: the table is occupied, stop its session first", ErrTableStatusTransition)
+	}
+	if status != current && !models.CanChangeTableStatus(current, status) {
+		return fmt.Errorf("%w: a %s table cannot become %s", ErrTableStatusTransition, current, status)
+	}
+	return nil
+}
+
+// changeTableStatus moves table to status within executor, if its lifecycle allows it, and
+// publishes game_table.status_changed. changedBy is nil for a change following a booking or
+// session. It returns ErrVersionConflict if the status of the table changed since it was read.
+func changeTableStatus(executor repositories.SQLExecutor, tableRepo repositories.GameTableRepository, publisher events.Publisher,
+	table *models.GameTable, status string, changedBy *int64) error {
+	if table.Status == status {
+		return nil
+	}
+	if !models.CanChangeTableStatus(table.Status, status) {
+		return fmt.Errorf("%w: table '%s' cannot change from %s to %s", ErrTableStatusTransition, table.Name, table.Status, status)
+	}
+	if err := tableRepo.SetGameTableStatus(executor, table.ID, table.Status, status, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("failed to set game table status: %w", err)
+	}
+	payload := events.TableStatusPayload{TableID: table.ID, Status: status, PreviousStatus: table.Status, ChangedBy: changedBy}
+	if err := publisher.Publish(executor, events.TableStatusChanged, events.AggregateGameTable, table.ID, payload); err != nil {
+		return err
+	}
+	table.Status = status
+	return nil
+}
+
+func (s *gameTableService) CreateTable(table models.GameTable) (*models.GameTable, error) {
+	if table.Status == "" {
+		table.Status = models.TableStatusAvailable
+	}
+	if err := validateManualTableStatus(models.TableStatusAvailable, table.Status); err != nil {
+		return nil, err
+	}
+	if err := applyGameTableDefaults(&table); err != nil {
+		return nil, err
+	}
+	if err := s.tableRepo.CreateGameTable(&table); err != nil {
+		return nil, gameTableError(err, "create game table")
+	}
+	return &table, nil
+}
+
+func (s *gameTableService) GetTables(status string) ([]models.GameTable, error) {
+	if status != "" && !models.IsValidTableStatus(status) {
+		return nil, fmt.Errorf("%w: invalid status '%s', must be one of %v", ErrGameTableValidation, status, models.TableStatuses)
+	}
+	tables, err := s.tableRepo.GetGameTables(status)
+	if err != nil {
+		return nil, gameTableError(err, "get game tables")
+	}
+	return tables, nil
+}
+
+func (s *gameTableService) GetTable(id int64) (*models.GameTable, error) {
+	table, err := s.tableRepo.GetGameTableByID(id)
+	if err != nil {
+		return nil, gameTableError(err, "get game table")
+	}
+	return table, nil
+}
+
+func (s *gameTableService) UpdateTable(id int64, table models.GameTable, changedBy int64) (*models.GameTable, error) {
+	current, err := s.tableRepo.GetGameTableByID(id)
+	if err != nil {
+		return nil, gameTableError(err, "get game table")
+	}
+	if table.Status == "" {
+		table.Status = current.Status
+	}
+	if table.Status != current.Status {
+		if err := validateManualTableStatus(current.Status, table.Status); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyGameTableDefaults(&table); err != nil {
+		return nil, err
+	}
+	table.ID = id
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.tableRepo.UpdateGameTable(tx, &table, current.Status); err != nil {
+		return nil, gameTableError(err, "update game table")
+	}
+	if table.Status != current.Status {
+		payload := events.TableStatusPayload{TableID: id, Status: table.Status, PreviousStatus: current.Status, ChangedBy: &changedBy}
+		if err := s.publisher.Publish(tx, events.TableStatusChanged, events.AggregateGameTable, id, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit game table update: %w", err)
+	}
+	return &table, nil
+}
+
+func (s *gameTableService) DeleteTable(id int64) error {
+	count, err := s.tableRepo.CountActiveBookings(id)
+	if err != nil {
+		return gameTableError(err, "check active bookings")
+	}
+	if count > 0 {
+		return ErrGameTableInUse
+	}
+	if err := s.tableRepo.DeleteGameTable(id); err != nil {
+		if errors.Is(err, repositories.ErrReferenced) {
+			return fmt.Errorf("%w: %v", ErrGameTableInUse, err)
+		}
+		return gameTableError(err, "delete game table")
+	}
+	return nil
+}
+
+func (s *gameTableService) SetStatus(id int64, status string, changedBy int64) (*models.GameTable, error) {
+	table, err := s.tableRepo.GetGameTableByID(id)
+	if err != nil {
+		return nil, gameTableError(err, "get game table")
+	}
+	if err := validateManualTableStatus(table.Status, status); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := changeTableStatus(tx, s.tableRepo, s.publisher, table, status, &changedBy); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit game table status: %w", err)
+	}
+	return table, nil
+}
+
+func (s *gameTableService) GetOverview(id int64) (*models.TableOverview, error) {
+	table, err := s.tableRepo.GetGameTableByID(id)
+	if err != nil {
+		return nil, gameTableError(err, "get game table")
+	}
+	now := utils.NowUTC()
+	overview := &models.TableOverview{Table: *table, GeneratedAt: now}
+
+	sessions, err := s.sessions.GetRunningSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.TableID != id {
+			continue
+		}
+		overview.Session = &models.TableOverviewSession{TableSession: session}
+		if now.After(session.StartedAt) {
+			overview.Session.ElapsedMinutes = int(now.Sub(session.StartedAt).Minutes())
+		}
+		if session.EndsAt != nil {
+			remaining := 0
+			if session.EndsAt.After(now) {
+				remaining = int(math.Ceil(session.EndsAt.Sub(now).Minutes()))
+			}
+			overview.Session.RemainingMinutes = &remaining
+		}
+		overview.TotalAmount = session.TotalAmount
+		break
+	}
+
+	_, endOfDay := utils.DayBounds(utils.NowInClub())
+	overview.Bookings, _, err = s.bookingRepo.GetBookings(models.BookingFilters{
+		TableID:      &id,
+		Statuses:     []string{string(models.BookingStatusPending), string(models.BookingStatusConfirmed)},
+		EndsAfter:    &now,
+		StartsBefore: &endOfDay,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings of game table: %w", err)
+	}
+	slices.Reverse(overview.Bookings)
+
+	overview.Orders, _, err = s.orderRepo.GetOrders(models.OrderFilters{TableID: &id, Statuses: OpenOrderStatuses, IncludeItems: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders of game table: %w", err)
+	}
+	slices.Reverse(overview.Orders)
+	for _, order := range overview.Orders {
+		overview.OrderAmount = overview.OrderAmount.Add(order.FinalAmount)
+	}
+	overview.TotalAmount = overview.TotalAmount.Add(overview.OrderAmount)
+	return overview, nil
+}
+
+func (s *gameTableService) RunStatusSync(ctx context.Context) {
+	ticker := time.NewTicker(TableStatusSyncInterval)
+	defer ticker.Stop()
+	for {
+		s.syncStatuses(utils.NowUTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncStatuses moves the tables whose bookings and sessions call for another status at now.
+// A change another instance applied first is skipped.
+func (s *gameTableService) syncStatuses(now time.Time) {
+	changes, err := s.tableRepo.GetTableStatusChanges(now, now.Add(CheckInOpensBefore))
+	if err != nil {
+		utils.LogError(err, "syncStatuses: failed to derive game table statuses")
+		return
+	}
+	for _, change := range changes {
+		table := &models.GameTable{ID: change.TableID, Status: change.From}
+		if err := s.applyStatusChange(table, change.To); err != nil && !errors.Is(err, ErrVersionConflict) {
+			utils.LogError(err, "syncStatuses: failed to set status of game table "+strconv.FormatInt(change.TableID, 10))
+		}
+	}
+}
+
+// applyStatusChange moves table to status in a transaction of its own.
+func (s *gameTableService) applyStatusChange(table *models.GameTable, status string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := changeTableStatus(tx, s.tableRepo, s.publisher, table, status, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
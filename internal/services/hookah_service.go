@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrHookahNotFound   = apperrors.New(utils.ErrCodeNotFound, "hookah not found")
+	ErrHookahEnded      = apperrors.New(utils.ErrCodeConflict, "the hookah was already taken away")
+	ErrHookahValidation = apperrors.New(utils.ErrCodeValidationFailed, "hookah validation error")
+)
+
+var (
+	hookahSettings   = models.HookahSettings{}
+	hookahSettingsMu sync.RWMutex
+)
+
+// SetHookahSettings sets the coal change rules (the hookah_service setting).
+func SetHookahSettings(settings models.HookahSettings) {
+	hookahSettingsMu.Lock()
+	defer hookahSettingsMu.Unlock()
+	hookahSettings = settings
+}
+
+// CurrentHookahSettings returns the configured coal change rules.
+func CurrentHookahSettings() models.HookahSettings {
+	hookahSettingsMu.RLock()
+	defer hookahSettingsMu.RUnlock()
+	return hookahSettings
+}
+
+// ActiveHookahOrderStatuses are the statuses of the orders whose hookahs are still being served.
+var ActiveHookahOrderStatuses = []string{StatusPending, StatusPreparing, StatusReady, StatusServed}
+
+// HookahTimerInterval is how often RunTimers checks for coal changes that are due.
+var HookahTimerInterval = 30 * time.Second
+
+// RecordCoalChangeRequest is the body of POST /hookahs/:id/coal-changes.
+type RecordCoalChangeRequest struct {
+	Coals *int `json:"coals" binding:"omitempty,min=0"` // Defaults to coals_per_change of the hookah_service setting
+}
+
+// --- HookahService Interface ---
+type HookahService interface {
+	// GetActiveHookahs returns the hookahs being served, with their next coal change if reminders are on.
+	GetActiveHookahs() ([]models.Hookah, error)
+	// RecordCoalChange records a coal change of the hookah of an order item and deducts the
+	// coals from the stock of the coal item of the hookah_service setting, if it tracks stock.
+	RecordCoalChange(orderItemID int64, req RecordCoalChangeRequest, changedBy int64) (*models.CoalChange, error)
+	GetCoalChanges(orderItemID int64) ([]models.CoalChange, error)
+	// EndHookah records that the hookah was taken away, which stops its coal change reminders.
+	EndHookah(orderItemID int64) (*models.Hookah, error)
+	// RunTimers publishes hookah.coal_change_due every coal_change_minutes after the last coal
+	// change of each active hookah until ctx is done. Every instance may run it; each reminder
+	// is published once.
+	RunTimers(ctx context.Context)
+}
+
+type hookahService struct {
+	hookahRepo      repositories.HookahRepository
+	pricelistRepo   repositories.PricelistRepository
+	inventoryMvRepo repositories.InventoryMovementRepository
+	batchRepo       repositories.StockBatchRepository
+	publisher       events.Publisher
+	db              *sql.DB
+}
+
+// NewHookahService creates a new HookahService.
+func NewHookahService(hookahRepo repositories.HookahRepository, pricelistRepo repositories.PricelistRepository,
+	inventoryMvRepo repositories.InventoryMovementRepository, batchRepo repositories.StockBatchRepository,
+	publisher events.Publisher, db *sql.DB) HookahService {
+	return &hookahService{
+		hookahRepo:      hookahRepo,
+		pricelistRepo:   pricelistRepo,
+		inventoryMvRepo: inventoryMvRepo,
+		batchRepo:       batchRepo,
+		publisher:       publisher,
+		db:              db,
+	}
+}
+
+// nextCoalChange returns when the coals of hookah are next due to be changed: interval after
+// the last change or, once reminded, after the last reminder.
+func nextCoalChange(hookah *models.Hookah, interval time.Duration) time.Time {
+	last := hookah.LastCoals()
+	if hookah.CoalRemindedAt != nil && hookah.CoalRemindedAt.After(last) {
+		last = *hookah.CoalRemindedAt
+	}
+	return last.Add(interval)
+}
+
+func (s *hookahService) GetActiveHookahs() ([]models.Hookah, error) {
+	hookahs, err := s.hookahRepo.GetActiveHookahs(ActiveHookahOrderStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active hookahs: %w", err)
+	}
+	if interval := CurrentHookahSettings().CoalChangeInterval(); interval > 0 {
+		for i := range hookahs {
+			next := nextCoalChange(&hookahs[i], interval)
+			hookahs[i].NextCoalChange = &next
+		}
+	}
+	return hookahs, nil
+}
+
+// getHookah returns the hookah of an order item, ErrHookahNotFound if there is none.
+func (s *hookahService) getHookah(orderItemID int64) (*models.Hookah, error) {
+	hookah, err := s.hookahRepo.GetHookah(orderItemID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrHookahNotFound
+		}
+		return nil, fmt.Errorf("failed to get hookah: %w", err)
+	}
+	return hookah, nil
+}
+
+func (s *hookahService) RecordCoalChange(orderItemID int64, req RecordCoalChangeRequest, changedBy int64) (*models.CoalChange, error) {
+	settings := CurrentHookahSettings()
+	coals := settings.CoalsPerChange
+	if req.Coals != nil {
+		coals = *req.Coals
+	}
+	if coals < 0 {
+		return nil, fmt.Errorf("%w: coals cannot be negative", ErrHookahValidation)
+	}
+	hookah, err := s.getHookah(orderItemID)
+	if err != nil {
+		return nil, err
+	}
+	if hookah.EndedAt != nil {
+		return nil, ErrHookahEnded
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := utils.NowUTC()
+	change := &models.CoalChange{OrderItemID: orderItemID, Coals: coals, ChangedBy: &changedBy, ChangedAt: now}
+	if settings.CoalItemID != nil && coals > 0 {
+		movementID, err := s.deductCoals(tx, *settings.CoalItemID, coals, hookah, now)
+		if err != nil {
+			return nil, err
+		}
+		change.InventoryMovementID = movementID
+	}
+	if err := s.hookahRepo.CreateCoalChange(tx, change); err != nil {
+		return nil, fmt.Errorf("failed to record coal change: %w", err)
+	}
+	hookah.CoalChangedAt = &now
+	payload := events.NewHookahPayload(hookah)
+	payload.Coals = &coals
+	if err := s.publisher.Publish(tx, events.HookahCoalChanged, events.AggregateOrderItem, orderItemID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return change, nil
+}
+
+// deductCoals takes coals off the stock of the coal item, each a portion of it, and records the
+// component usage. It returns nil without a movement if the coal item does not track stock. The
+// stock may go negative: the coals are already in use, so the change is recorded anyway.
+func (s *hookahService) deductCoals(tx *sql.Tx, coalItemID int64, coals int, hookah *models.Hookah, now time.Time) (*int64, error) {
+	_, _, itemName, tracksStock, err := s.pricelistRepo.GetItemPriceAndStock(coalItemID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: coal item ID %d of the hookah_service setting not found", ErrHookahValidation, coalItemID)
+		}
+		return nil, fmt.Errorf("failed to get coal item: %w", err)
+	}
+	if !tracksStock {
+		return nil, nil
+	}
+	units, err := s.pricelistRepo.GetItemUnits(coalItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units of coal item: %w", err)
+	}
+	quantity := units.ForPortions(coals)
+	if _, err := s.pricelistRepo.UpdateStock(tx, coalItemID, quantity.Neg()); err != nil {
+		return nil, fmt.Errorf("failed to update stock for coal item %s (ID: %d): %w", itemName, coalItemID, err)
+	}
+	movement := models.InventoryMovement{
+		PricelistItemID: coalItemID,
+		MovementType:    models.MovementTypeComponentUsage,
+		QuantityChanged: quantity.Neg(),
+		Reason:          utils.NewNullString(fmt.Sprintf("Hookah coal change, order %d", hookah.OrderID)),
+		MovementDate:    now,
+	}
+	movementID, err := s.inventoryMvRepo.CreateMovement(tx, &movement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record inventory movement for coal item %s (ID: %d): %w", itemName, coalItemID, err)
+	}
+	if err := s.batchRepo.DeductFEFO(tx, coalItemID, quantity, movementID, nil); err != nil {
+		return nil, fmt.Errorf("failed to take coal item %s (ID: %d) from its stock batches: %w", itemName, coalItemID, err)
+	}
+	return &movementID, nil
+}
+
+func (s *hookahService) GetCoalChanges(orderItemID int64) ([]models.CoalChange, error) {
+	if _, err := s.getHookah(orderItemID); err != nil {
+		return nil, err
+	}
+	changes, err := s.hookahRepo.GetCoalChanges(orderItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coal changes: %w", err)
+	}
+	return changes, nil
+}
+
+func (s *hookahService) EndHookah(orderItemID int64) (*models.Hookah, error) {
+	hookah, err := s.getHookah(orderItemID)
+	if err != nil {
+		return nil, err
+	}
+	if hookah.EndedAt != nil {
+		return nil, ErrHookahEnded
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := utils.NowUTC()
+	if err := s.hookahRepo.EndHookah(tx, orderItemID, now); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrHookahEnded
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrHookahNotFound
+		}
+		return nil, fmt.Errorf("failed to end hookah: %w", err)
+	}
+	hookah.EndedAt = &now
+	if err := s.publisher.Publish(tx, events.HookahEnded, events.AggregateOrderItem, orderItemID, events.NewHookahPayload(hookah)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return hookah, nil
+}
+
+func (s *hookahService) RunTimers(ctx context.Context) {
+	ticker := time.NewTicker(HookahTimerInterval)
+	defer ticker.Stop()
+	for {
+		s.checkTimers(utils.NowUTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkTimers reminds of the coal changes that are due.
+func (s *hookahService) checkTimers(now time.Time) {
+	interval := CurrentHookahSettings().CoalChangeInterval()
+	if interval <= 0 {
+		return
+	}
+	hookahs, err := s.hookahRepo.GetActiveHookahs(ActiveHookahOrderStatuses)
+	if err != nil {
+		utils.LogError(err, "Failed to check hookah timers")
+		return
+	}
+	for i := range hookahs {
+		hookah := &hookahs[i]
+		dueAt := nextCoalChange(hookah, interval)
+		if now.Before(dueAt) {
+			continue
+		}
+		if err := s.remind(hookah, dueAt, now); err != nil {
+			utils.LogError(err, "Failed to remind of the coal change of order item "+strconv.FormatInt(hookah.OrderItemID, 10))
+		}
+	}
+}
+
+// remind publishes hookah.coal_change_due for hookah, unless another instance already did.
+func (s *hookahService) remind(hookah *models.Hookah, dueAt, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	marked, err := s.hookahRepo.MarkCoalReminded(tx, hookah.OrderItemID, dueAt, now)
+	if err != nil || !marked {
+		return err
+	}
+	payload := events.NewHookahPayload(hookah)
+	payload.DueAt = &dueAt
+	if err := s.publisher.Publish(tx, events.HookahCoalChangeDue, events.AggregateOrderItem, hookah.OrderItemID, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
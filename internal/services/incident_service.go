@@ -0,0 +1,376 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrIncidentNotFound      = apperrors.New(utils.ErrCodeNotFound, "incident not found")
+	ErrIncidentPhotoNotFound = apperrors.New(utils.ErrCodeNotFound, "incident photo not found")
+	ErrIncidentReviewed      = apperrors.New(utils.ErrCodeConflict, "the incident was already reviewed")
+	ErrIncidentValidation    = apperrors.New(utils.ErrCodeValidationFailed, "incident validation error")
+)
+
+// IncidentRequest is the body of POST and PUT /incidents.
+type IncidentRequest struct {
+	IncidentType string     `json:"incident_type" binding:"required"` // One of models.IncidentTypes
+	Severity     string     `json:"severity" binding:"required"`      // One of models.IncidentSeverities
+	Description  string     `json:"description" binding:"required"`
+	OccurredAt   *time.Time `json:"occurred_at"` // Defaults to now on create
+	StaffID      *int64     `json:"staff_id"`
+	ClientID     *int64     `json:"client_id"`
+	TableID      *int64     `json:"table_id"`
+	OrderID      *int64     `json:"order_id"`
+	// PenaltyAmount is proposed for the staff member; it counts in the payroll once an Admin confirms the incident
+	PenaltyAmount *models.Money `json:"penalty_amount" binding:"omitempty,money"`
+	Version       *int          `json:"version"` // Checked on update if set
+}
+
+// ReviewIncidentRequest is the body of POST /incidents/:id/review.
+type ReviewIncidentRequest struct {
+	Status        string        `json:"status" binding:"required,oneof=confirmed dismissed"`
+	PenaltyAmount *models.Money `json:"penalty_amount" binding:"omitempty,money"` // Replaces the proposed penalty if set
+	Notes         *string       `json:"notes"`
+}
+
+// --- IncidentService Interface ---
+type IncidentService interface {
+	// ReportIncident records an incident at this branch, open for an Admin's review.
+	ReportIncident(req IncidentRequest, reportedBy int64) (*models.Incident, error)
+	// GetIncidents returns the incidents of this branch matching filters, most recent first.
+	GetIncidents(filters models.IncidentFilters) ([]models.Incident, error)
+	GetIncident(id int64) (*models.Incident, error)
+	// UpdateIncident changes the details of an incident that has not been reviewed yet.
+	UpdateIncident(id int64, req IncidentRequest) (*models.Incident, error)
+	// ReviewIncident confirms or dismisses an open incident. The penalty of a confirmed incident
+	// is deducted from the pay of its staff member.
+	ReviewIncident(id int64, req ReviewIncidentRequest, reviewedBy int64) (*models.Incident, error)
+	DeleteIncident(id int64) error
+	// AddPhoto attaches a JPEG, PNG or WebP photo to an incident.
+	AddPhoto(id int64, data []byte, uploadedBy int64) (*models.IncidentPhoto, error)
+	GetPhoto(id, photoID int64) (*models.Photo, error)
+	// DeletePhoto removes a photo from an incident that has not been reviewed yet.
+	DeletePhoto(id, photoID int64) error
+	// GetPayroll returns the pay of every staff member for a month (YYYY-MM, club time; empty
+	// for the current month): the salary less the penalties of the incidents confirmed against
+	// them that occurred in the month and of their attendance anomalies in the month.
+	GetPayroll(month string) (*models.Payroll, error)
+}
+
+type incidentService struct {
+	incidentRepo repositories.IncidentRepository
+	staffRepo    repositories.StaffRepository
+	clientRepo   repositories.ClientRepository
+	bookingRepo  repositories.BookingRepository
+	orderRepo    repositories.OrderRepository
+	attendance   AttendanceService // Adds the attendance penalties to the payroll
+}
+
+// NewIncidentService creates a new IncidentService.
+func NewIncidentService(incidentRepo repositories.IncidentRepository, staffRepo repositories.StaffRepository,
+	clientRepo repositories.ClientRepository, bookingRepo repositories.BookingRepository, orderRepo repositories.OrderRepository,
+	attendance AttendanceService) IncidentService {
+	return &incidentService{
+		incidentRepo: incidentRepo,
+		staffRepo:    staffRepo,
+		clientRepo:   clientRepo,
+		bookingRepo:  bookingRepo,
+		orderRepo:    orderRepo,
+		attendance:   attendance,
+	}
+}
+
+// applyIncidentRequest sets the fields of req on incident, checking that the linked staff
+// member, client, table and order exist.
+func (s *incidentService) applyIncidentRequest(incident *models.Incident, req IncidentRequest) error {
+	if !slices.Contains(models.IncidentTypes, req.IncidentType) {
+		return fmt.Errorf("%w: invalid incident_type '%s', must be one of %v", ErrIncidentValidation, req.IncidentType, models.IncidentTypes)
+	}
+	if !slices.Contains(models.IncidentSeverities, req.Severity) {
+		return fmt.Errorf("%w: invalid severity '%s', must be one of %v", ErrIncidentValidation, req.Severity, models.IncidentSeverities)
+	}
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		return fmt.Errorf("%w: description is required", ErrIncidentValidation)
+	}
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(utils.NowUTC()) {
+			return fmt.Errorf("%w: occurred_at cannot be in the future", ErrIncidentValidation)
+		}
+		incident.OccurredAt = req.OccurredAt.UTC()
+	}
+	penalty := models.ZeroMoney
+	if req.PenaltyAmount != nil {
+		if req.PenaltyAmount.IsNegative() {
+			return fmt.Errorf("%w: penalty_amount cannot be negative", ErrIncidentValidation)
+		}
+		penalty = *req.PenaltyAmount
+	}
+	if penalty.IsPositive() && req.StaffID == nil {
+		return fmt.Errorf("%w: a penalty needs the staff_id of the staff member it is deducted from", ErrIncidentValidation)
+	}
+
+	if req.StaffID != nil {
+		if _, err := s.staffRepo.GetStaffMemberByID(*req.StaffID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: staff member ID %d not found", ErrIncidentValidation, *req.StaffID)
+			}
+			return fmt.Errorf("failed to get staff member: %w", err)
+		}
+	}
+	if req.ClientID != nil {
+		if _, err := s.clientRepo.GetClientByID(*req.ClientID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: client ID %d not found", ErrIncidentValidation, *req.ClientID)
+			}
+			return fmt.Errorf("failed to get client: %w", err)
+		}
+	}
+	if req.TableID != nil {
+		if _, err := s.bookingRepo.GetGameTableByID(*req.TableID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: game table ID %d not found", ErrIncidentValidation, *req.TableID)
+			}
+			return fmt.Errorf("failed to get game table: %w", err)
+		}
+	}
+	if req.OrderID != nil {
+		if _, err := s.orderRepo.GetOrderByID(*req.OrderID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: order ID %d not found", ErrIncidentValidation, *req.OrderID)
+			}
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+	}
+
+	incident.IncidentType = req.IncidentType
+	incident.Severity = req.Severity
+	incident.Description = description
+	incident.StaffID = req.StaffID
+	incident.ClientID = req.ClientID
+	incident.TableID = req.TableID
+	incident.OrderID = req.OrderID
+	incident.PenaltyAmount = penalty
+	return nil
+}
+
+func (s *incidentService) ReportIncident(req IncidentRequest, reportedBy int64) (*models.Incident, error) {
+	incident := &models.Incident{
+		BranchCode: utils.BranchCode(),
+		OccurredAt: utils.NowUTC(),
+		Status:     models.IncidentStatusOpen,
+		ReportedBy: &reportedBy,
+	}
+	if err := s.applyIncidentRequest(incident, req); err != nil {
+		return nil, err
+	}
+	if err := s.incidentRepo.CreateIncident(incident); err != nil {
+		return nil, fmt.Errorf("failed to report incident: %w", err)
+	}
+	return incident, nil
+}
+
+func (s *incidentService) GetIncidents(filters models.IncidentFilters) ([]models.Incident, error) {
+	if filters.Status != "" && !slices.Contains(models.IncidentStatuses, filters.Status) {
+		return nil, fmt.Errorf("%w: invalid status '%s', must be one of %v", ErrIncidentValidation, filters.Status, models.IncidentStatuses)
+	}
+	if filters.IncidentType != "" && !slices.Contains(models.IncidentTypes, filters.IncidentType) {
+		return nil, fmt.Errorf("%w: invalid incident_type '%s', must be one of %v", ErrIncidentValidation, filters.IncidentType, models.IncidentTypes)
+	}
+	if filters.Severity != "" && !slices.Contains(models.IncidentSeverities, filters.Severity) {
+		return nil, fmt.Errorf("%w: invalid severity '%s', must be one of %v", ErrIncidentValidation, filters.Severity, models.IncidentSeverities)
+	}
+	incidents, err := s.incidentRepo.GetIncidents(utils.BranchCode(), filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// GetIncident returns an incident of this branch; the incidents of other branches are not found.
+func (s *incidentService) GetIncident(id int64) (*models.Incident, error) {
+	incident, err := s.incidentRepo.GetIncidentByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to get incident: %w", err)
+	}
+	if incident.BranchCode != utils.BranchCode() {
+		return nil, ErrIncidentNotFound
+	}
+	return incident, nil
+}
+
+func (s *incidentService) UpdateIncident(id int64, req IncidentRequest) (*models.Incident, error) {
+	incident, err := s.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.Status != models.IncidentStatusOpen {
+		return nil, ErrIncidentReviewed
+	}
+	if err := s.applyIncidentRequest(incident, req); err != nil {
+		return nil, err
+	}
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	if err := s.incidentRepo.UpdateIncident(incident, version); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrIncidentNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update incident: %w", err)
+	}
+	return incident, nil
+}
+
+func (s *incidentService) ReviewIncident(id int64, req ReviewIncidentRequest, reviewedBy int64) (*models.Incident, error) {
+	if req.Status != models.IncidentStatusConfirmed && req.Status != models.IncidentStatusDismissed {
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrIncidentValidation, models.IncidentStatusConfirmed, models.IncidentStatusDismissed)
+	}
+	incident, err := s.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.Status != models.IncidentStatusOpen {
+		return nil, ErrIncidentReviewed
+	}
+	if req.PenaltyAmount != nil {
+		if req.PenaltyAmount.IsNegative() {
+			return nil, fmt.Errorf("%w: penalty_amount cannot be negative", ErrIncidentValidation)
+		}
+		incident.PenaltyAmount = *req.PenaltyAmount
+	}
+	if incident.PenaltyAmount.IsPositive() && incident.StaffID == nil {
+		return nil, fmt.Errorf("%w: a penalty needs the staff member it is deducted from; set the incident's staff_id first", ErrIncidentValidation)
+	}
+
+	now := utils.NowUTC()
+	incident.Status = req.Status
+	incident.ReviewedBy = &reviewedBy
+	incident.ReviewedAt = &now
+	incident.ReviewNotes = req.Notes
+	if err := s.incidentRepo.ReviewIncident(incident); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrIncidentNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrIncidentReviewed
+		}
+		return nil, fmt.Errorf("failed to review incident: %w", err)
+	}
+	return incident, nil
+}
+
+func (s *incidentService) DeleteIncident(id int64) error {
+	if _, err := s.GetIncident(id); err != nil {
+		return err
+	}
+	if err := s.incidentRepo.DeleteIncident(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrIncidentNotFound
+		}
+		return fmt.Errorf("failed to delete incident: %w", err)
+	}
+	return nil
+}
+
+func (s *incidentService) AddPhoto(id int64, data []byte, uploadedBy int64) (*models.IncidentPhoto, error) {
+	contentType, err := checkPhoto(data, ErrIncidentValidation)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetIncident(id); err != nil {
+		return nil, err
+	}
+	photo, err := s.incidentRepo.AddPhoto(id, &models.Photo{ContentType: contentType, Data: data}, uploadedBy)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to add incident photo: %w", err)
+	}
+	return photo, nil
+}
+
+func (s *incidentService) GetPhoto(id, photoID int64) (*models.Photo, error) {
+	if _, err := s.GetIncident(id); err != nil {
+		return nil, err
+	}
+	photo, err := s.incidentRepo.GetPhoto(id, photoID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrIncidentPhotoNotFound
+		}
+		return nil, fmt.Errorf("failed to get incident photo: %w", err)
+	}
+	return photo, nil
+}
+
+func (s *incidentService) DeletePhoto(id, photoID int64) error {
+	incident, err := s.GetIncident(id)
+	if err != nil {
+		return err
+	}
+	// The photos of a reviewed incident are the evidence the review was based on
+	if incident.Status != models.IncidentStatusOpen {
+		return ErrIncidentReviewed
+	}
+	if err := s.incidentRepo.DeletePhoto(id, photoID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrIncidentPhotoNotFound
+		}
+		return fmt.Errorf("failed to delete incident photo: %w", err)
+	}
+	return nil
+}
+
+func (s *incidentService) GetPayroll(month string) (*models.Payroll, error) {
+	if strings.TrimSpace(month) == "" {
+		month = utils.FormatClubTime(utils.NowUTC(), utils.MonthLayout)
+	}
+	from, to, err := utils.ParseClubMonth(month)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid month '%s', expected YYYY-MM", ErrIncidentValidation, month)
+	}
+	lines, err := s.incidentRepo.GetPayrollLines(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payroll: %w", err)
+	}
+	attendance, err := s.attendance.GetReport(month, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance penalties: %w", err)
+	}
+	attendancePenalties := make(map[int64]models.AttendanceSummary, len(attendance.Staff))
+	for _, summary := range attendance.Staff {
+		attendancePenalties[summary.StaffID] = summary
+	}
+	payroll := &models.Payroll{Month: strings.TrimSpace(month), Lines: lines}
+	for i := range lines {
+		line := &lines[i]
+		if summary, ok := attendancePenalties[line.StaffID]; ok && summary.Penalties.IsPositive() {
+			line.AttendancePenaltyCount = summary.LateArrivals + summary.EarlyDepartures + summary.MissedShifts
+			line.AttendancePenalties = summary.Penalties
+		}
+		line.NetPay = line.Salary.Sub(line.Penalties).Sub(line.AttendancePenalties)
+		payroll.TotalSalary = payroll.TotalSalary.Add(line.Salary)
+		payroll.TotalPenalties = payroll.TotalPenalties.Add(line.Penalties)
+		payroll.TotalAttendancePenalties = payroll.TotalAttendancePenalties.Add(line.AttendancePenalties)
+		payroll.TotalNetPay = payroll.TotalNetPay.Add(line.NetPay)
+	}
+	return payroll, nil
+}
@@ -4,51 +4,60 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"ps_club_backend/internal/events"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 	"strings"
 	"time"
 )
 
 // --- Custom Service Errors for Inventory Movement ---
 var (
-	ErrInvalidMovementType    = errors.New("invalid inventory movement type")
-	ErrMovementItemNotFound   = errors.New("item for inventory movement not found")
-	ErrMovementItemNotTracked = errors.New("item for inventory movement does not track stock")
-	ErrMovementCreationFailed  = errors.New("failed to create inventory movement")
+	ErrInvalidMovementType    = apperrors.New(utils.ErrCodeValidationFailed, "invalid inventory movement type")
+	ErrMovementItemNotFound   = apperrors.New(utils.ErrCodeNotFound, "item for inventory movement not found")
+	ErrMovementItemNotTracked = apperrors.New(utils.ErrCodeBadRequest, "item for inventory movement does not track stock")
+	// Internal failures, answered with 500
+	ErrMovementCreationFailed = errors.New("failed to create inventory movement")
 	ErrStockUpdateFailed      = errors.New("failed to update stock after movement")
 )
 
-// MovementType constants - ensure these are comprehensive and match usage elsewhere
-const (
-	MovementTypePurchase           string = "purchase"
-	MovementTypeSale               string = "sale" // Handled by OrderService
-	MovementTypeAdjustmentIn       string = "adjustment_in"
-	MovementTypeAdjustmentOut      string = "adjustment_out"
-	MovementTypeSpoilage           string = "spoilage"
-	MovementTypeReturnCancellation string = "return_cancellation" // Handled by OrderService
-	MovementTypeReturnDeletion     string = "return_deletion"     // Handled by OrderService
-)
 
 // --- Inventory Movement DTOs ---
 type CreateInventoryMovementRequest struct {
-	PricelistItemID int64   `json:"pricelist_item_id" binding:"required"`
-	StaffID         *int64  `json:"staff_id"` // If nil, authenticated user's ID is used. Admin can override.
-	MovementType    string  `json:"movement_type" binding:"required"`
-	QuantityChanged int     `json:"quantity_changed" binding:"required"` // Always positive; service determines sign for stock update
-	Reason          *string `json:"reason"`
+	PricelistItemID int64           `json:"pricelist_item_id" binding:"required"`
+	StaffID         *int64          `json:"staff_id"` // If nil, authenticated user's ID is used. Admin can override.
+	MovementType    string          `json:"movement_type" binding:"required,movement_type"`
+	QuantityChanged models.Quantity `json:"quantity_changed" binding:"required"` // Positive, signed by the movement type; for stocktake_adjustment the counted minus the recorded stock
+	// quantity_changed is in purchase units of the item, e.g. bottles, rather than its stock unit
+	PurchaseUnits bool    `json:"purchase_units"`
+	Reason        *string `json:"reason"`
+	// Stock received (a purchase, adjustment_in, transfer_in or stocktake_adjustment up) with a batch
+	// number or expiry date is received as a batch
+	BatchNumber *string `json:"batch_number" binding:"omitempty,max=100"`
+	ExpiryDate  *string `json:"expiry_date" binding:"omitempty,date"` // Format YYYY-MM-DD
+	// Stock taken out manually is taken from this batch instead of first expiry first
+	BatchID *int64 `json:"batch_id"`
 }
 
 // --- InventoryMovementService Interface ---
 type InventoryMovementService interface {
 	CreateMovement(req CreateInventoryMovementRequest, authenticatedStaffID int64) (*models.InventoryMovement, error)
-	GetMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) ([]models.InventoryMovement, int, error)
+	// GetMovements lists the movements matching the filters, newest first; from and to, if set,
+	// bound the movement date to [from, to).
+	GetMovements(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, page, pageSize int) ([]models.InventoryMovement, int, error)
+	// GetMovementsByCursor returns a page of movements, newest first. Pass the NextCursor
+	// of a page as cursor to get the following page ("" for the first).
+	GetMovementsByCursor(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor string, pageSize int) (*models.CursorPage[models.InventoryMovement], error)
 }
 
 // --- inventoryMovementService Implementation ---
 type inventoryMovementService struct {
 	inventoryMvRepo repositories.InventoryMovementRepository
 	pricelistRepo   repositories.PricelistRepository
+	batchRepo       repositories.StockBatchRepository
+	publisher       events.Publisher // Records write-offs in the transaction of the movement
 	db              *sql.DB
 }
 
@@ -56,11 +65,15 @@ type inventoryMovementService struct {
 func NewInventoryMovementService(
 	imr repositories.InventoryMovementRepository,
 	pr repositories.PricelistRepository,
+	br repositories.StockBatchRepository,
+	publisher events.Publisher,
 	db *sql.DB,
 ) InventoryMovementService {
 	return &inventoryMovementService{
 		inventoryMvRepo: imr,
 		pricelistRepo:   pr,
+		batchRepo:       br,
+		publisher:       publisher,
 		db:              db,
 	}
 }
@@ -72,17 +85,26 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 	normalizedMovementType := strings.ToLower(req.MovementType)
 
 	switch normalizedMovementType {
-	case MovementTypePurchase, MovementTypeAdjustmentIn:
-		if req.QuantityChanged <= 0 {
+	case models.MovementTypePurchase, models.MovementTypeAdjustmentIn, models.MovementTypeTransferIn:
+		if !req.QuantityChanged.IsPositive() {
 			return nil, fmt.Errorf("%w: quantity for '%s' movement must be positive", ErrValidation, req.MovementType)
 		}
 		stockChangeMultiplier = 1 // Positive change
-	case MovementTypeAdjustmentOut, MovementTypeSpoilage:
-		if req.QuantityChanged <= 0 {
+	case models.MovementTypeAdjustmentOut, models.MovementTypeSpoilage, models.MovementTypeTransferOut:
+		if !req.QuantityChanged.IsPositive() {
 			return nil, fmt.Errorf("%w: quantity for '%s' movement must be positive (it will be deducted from stock)", ErrValidation, req.MovementType)
 		}
 		stockChangeMultiplier = -1 // Negative change
-	case MovementTypeSale, MovementTypeReturnCancellation, MovementTypeReturnDeletion:
+	case models.MovementTypeStocktakeAdjustment:
+		if req.QuantityChanged.IsZero() {
+			return nil, fmt.Errorf("%w: quantity for '%s' movement must not be zero", ErrValidation, req.MovementType)
+		}
+		stockChangeMultiplier = 1
+		if req.QuantityChanged.IsNegative() { // Less was counted than recorded
+			stockChangeMultiplier = -1
+			req.QuantityChanged = req.QuantityChanged.Neg()
+		}
+	case models.MovementTypeSale, models.MovementTypeReturnCancellation, models.MovementTypeReturnDeletion, models.MovementTypeComponentUsage:
 		// These types are typically system-generated by OrderService and reflect stock changes already.
 		// Manual creation for these types via this endpoint might be disallowed or require special handling.
 		// For now, disallowing to prevent accidental stock duplication or complex logic here.
@@ -91,7 +113,13 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 		return nil, fmt.Errorf("%w: unknown type '%s'", ErrInvalidMovementType, req.MovementType)
 	}
 
-	actualStockChange := req.QuantityChanged * stockChangeMultiplier
+	receivesBatch := req.BatchNumber != nil || req.ExpiryDate != nil
+	if receivesBatch && stockChangeMultiplier < 0 {
+		return nil, fmt.Errorf("%w: batch_number and expiry_date are only recorded on stock received", ErrValidation)
+	}
+	if req.BatchID != nil && stockChangeMultiplier > 0 {
+		return nil, fmt.Errorf("%w: batch_id is only used for stock taken out", ErrValidation)
+	}
 
 	// Determine StaffID to use for the movement record
 	staffIDForRecord := authenticatedStaffID
@@ -100,7 +128,7 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 	}
 
 	// Verify pricelist item
-	_, _, _, tracksStock, err := s.pricelistRepo.GetItemPriceAndStock(req.PricelistItemID)
+	_, _, itemName, tracksStock, err := s.pricelistRepo.GetItemPriceAndStock(req.PricelistItemID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return nil, fmt.Errorf("%w: pricelist item with ID %d not found", ErrMovementItemNotFound, req.PricelistItemID)
@@ -110,6 +138,33 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 	if !tracksStock {
 		return nil, fmt.Errorf("%w: item ID %d", ErrMovementItemNotTracked, req.PricelistItemID)
 	}
+	units, err := s.pricelistRepo.GetItemUnits(req.PricelistItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units of pricelist item: %w", err)
+	}
+	quantity := req.QuantityChanged // In the stock unit
+	if req.PurchaseUnits {
+		quantity = units.FromPurchaseUnits(req.QuantityChanged)
+	}
+	actualStockChange := quantity
+	if stockChangeMultiplier < 0 {
+		actualStockChange = quantity.Neg()
+	}
+	if req.BatchID != nil {
+		batch, err := s.batchRepo.GetBatchByID(*req.BatchID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: stock batch ID %d not found", ErrValidation, *req.BatchID)
+			}
+			return nil, fmt.Errorf("failed to get stock batch: %w", err)
+		}
+		if batch.PricelistItemID != req.PricelistItemID {
+			return nil, fmt.Errorf("%w: stock batch ID %d is not of item ID %d", ErrValidation, *req.BatchID, req.PricelistItemID)
+		}
+		if batch.QuantityRemaining.Cmp(quantity) < 0 {
+			return nil, fmt.Errorf("%w: stock batch ID %d holds only %s %s", ErrValidation, *req.BatchID, batch.QuantityRemaining, units.StockUnit)
+		}
+	}
 
 	// Start transaction
 	tx, err := s.db.Begin()
@@ -124,7 +179,7 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 		MovementType:    normalizedMovementType,
 		QuantityChanged: actualStockChange, // This is the actual change to stock (+ve or -ve)
 		Reason:          req.Reason,
-		MovementDate:    time.Now(), // Service sets the movement date
+		MovementDate:    time.Now().UTC(), // Service sets the movement date
 	}
 
 	movementID, err := s.inventoryMvRepo.CreateMovement(tx, movement)
@@ -141,6 +196,49 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 		return nil, fmt.Errorf("%w: for item ID %d: %v", ErrStockUpdateFailed, req.PricelistItemID, err)
 	}
 
+	switch {
+	case receivesBatch:
+		batch := &models.StockBatch{
+			PricelistItemID:  req.PricelistItemID,
+			BatchNumber:      req.BatchNumber,
+			ExpiryDate:       req.ExpiryDate,
+			QuantityReceived: quantity,
+			ReceivedAt:       movement.MovementDate,
+			MovementID:       &movement.ID,
+		}
+		if err := s.batchRepo.CreateBatch(tx, batch); err != nil {
+			return nil, fmt.Errorf("failed to record stock batch: %w", err)
+		}
+	case req.BatchID != nil:
+		if err := s.batchRepo.DeductBatch(tx, *req.BatchID, quantity, movement.ID); err != nil {
+			if errors.Is(err, repositories.ErrVersionConflict) {
+				return nil, fmt.Errorf("%w: stock batch ID %d holds less than %s %s", ErrValidation, *req.BatchID, quantity, units.StockUnit)
+			}
+			return nil, fmt.Errorf("failed to deduct from stock batch: %w", err)
+		}
+	case stockChangeMultiplier < 0:
+		if err := s.batchRepo.DeductFEFO(tx, req.PricelistItemID, quantity, movement.ID, nil); err != nil {
+			return nil, fmt.Errorf("failed to deduct from stock batches: %w", err)
+		}
+	}
+
+	// Stock sent elsewhere is not lost
+	if stockChangeMultiplier < 0 && normalizedMovementType != models.MovementTypeTransferOut {
+		payload := events.InventoryPayload{
+			MovementID:      movement.ID,
+			PricelistItemID: movement.PricelistItemID,
+			ItemName:        itemName,
+			MovementType:    movement.MovementType,
+			Quantity:        quantity,
+			StockUnit:       units.StockUnit,
+			StaffID:         movement.StaffID,
+			Reason:          movement.Reason,
+		}
+		if err := s.publisher.Publish(tx, events.InventoryWrittenOff, events.AggregatePricelistItem, movement.PricelistItemID, payload); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction for inventory movement: %w", err)
 	}
@@ -151,10 +249,10 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 	createdMovement, fetchErr := s.fetchMovementDetails(movement.ID)
 	if fetchErr != nil {
 		// Log this error, but return the original movement data as a fallback
-		fmt.Printf("Warning: failed to fetch full movement details after creation (ID: %d): %v\n", movement.ID, fetchErr)
+		apperrors.Warn(fetchErr, fmt.Sprintf("failed to fetch full movement details after creation (ID: %d)", movement.ID))
 		// Ensure basic details are set
-		movement.CreatedAt = time.Now() // Approximate, DB has actual
-		movement.UpdatedAt = time.Now()
+		movement.CreatedAt = time.Now().UTC() // Approximate, DB has actual
+		movement.UpdatedAt = time.Now().UTC()
 		return movement, nil 
 	}
 
@@ -164,7 +262,7 @@ func (s *inventoryMovementService) CreateMovement(req CreateInventoryMovementReq
 // helper to fetch movement details - ideally GetMovementByID in repo
 func (s *inventoryMovementService) fetchMovementDetails(movementID int64) (*models.InventoryMovement, error) {
     // This is a simplified fetch. Ideally, repo.GetMovementByID(movementID)
-    movements, _, err := s.inventoryMvRepo.GetMovements(nil, nil, nil, 1, 1) // This is not ideal for fetching one specific ID
+    movements, _, err := s.inventoryMvRepo.GetMovements(nil, nil, nil, nil, nil, nil, 1, 1) // This is not ideal for fetching one specific ID
     if err != nil {
         return nil, err
     }
@@ -180,13 +278,32 @@ func (s *inventoryMovementService) fetchMovementDetails(movementID int64) (*mode
 }
 
 
-func (s *inventoryMovementService) GetMovements(itemID *int64, staffID *int64, movementType *string, page, pageSize int) ([]models.InventoryMovement, int, error) {
+func (s *inventoryMovementService) GetMovements(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, page, pageSize int) ([]models.InventoryMovement, int, error) {
 	if page <= 0 { page = 1 }
 	if pageSize <= 0 { pageSize = 10 } 
 
-	movements, totalCount, err := s.inventoryMvRepo.GetMovements(itemID, staffID, movementType, page, pageSize)
+	movements, totalCount, err := s.inventoryMvRepo.GetMovements(itemID, staffID, movementType, from, to, nil, page, pageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get inventory movements: %w", err)
 	}
 	return movements, totalCount, nil
 }
+
+func (s *inventoryMovementService) GetMovementsByCursor(itemID *int64, staffID *int64, movementType *string, from, to *time.Time, cursor string, pageSize int) (*models.CursorPage[models.InventoryMovement], error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultCursorPageSize
+	}
+
+	// One extra row tells whether there is a next page
+	movements, _, err := s.inventoryMvRepo.GetMovements(itemID, staffID, movementType, from, to, after, 1, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inventory movements: %w", err)
+	}
+	return cursorPage(movements, pageSize, func(m models.InventoryMovement) models.Cursor {
+		return models.Cursor{Time: m.MovementDate, ID: m.ID}
+	}), nil
+}
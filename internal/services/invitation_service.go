@@ -0,0 +1,220 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvitationNotFound   = apperrors.New(utils.ErrCodeNotFound, "invitation not found")
+	ErrInvalidInvitation    = apperrors.New(utils.ErrCodeBadRequest, "invalid, expired, revoked or already accepted invitation")
+	ErrInvitationValidation = apperrors.New(utils.ErrCodeValidationFailed, "invitation validation error")
+)
+
+// invitationTokenPrefix starts every invitation token, so leaked tokens are easy to recognise.
+const invitationTokenPrefix = "psi_"
+
+// DefaultInvitationTTL is how long an invitation can be accepted unless the Admin picks otherwise.
+var DefaultInvitationTTL = 72 * time.Hour
+
+// invitableRoles are the roles staff can be invited to.
+var invitableRoles = []string{"Admin", "Staff", "Analyst"}
+
+// CreateInvitationRequest is the body of POST /admin/invitations. The invitee is reached by
+// email or phone, so at least one is required.
+type CreateInvitationRequest struct {
+	Email          *string `json:"email" binding:"omitempty,email,max=255"`
+	PhoneNumber    *string `json:"phone_number" binding:"omitempty,phone"`
+	FullName       *string `json:"full_name" binding:"omitempty,max=255"`
+	RoleName       string  `json:"role_name" binding:"required"`                       // Of invitableRoles
+	ExpiresInHours int     `json:"expires_in_hours" binding:"omitempty,min=1,max=720"` // DefaultInvitationTTL if 0
+}
+
+// CreatedInvitation is a new invitation with its token, which is only ever returned once.
+type CreatedInvitation struct {
+	models.Invitation
+	Token string `json:"token"`
+}
+
+// AcceptInvitationRequest is the body of POST /auth/accept-invitation. The full name
+// defaults to the one of the invitation.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,max=100"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	FullName string `json:"full_name" binding:"max=255"`
+}
+
+// --- InvitationService Interface ---
+type InvitationService interface {
+	CreateInvitation(req CreateInvitationRequest, invitedBy int64) (*CreatedInvitation, error)
+	GetInvitations() ([]models.Invitation, error)
+	// RevokeInvitation revokes a pending invitation, so it can no longer be accepted.
+	RevokeInvitation(id int64) error
+	// AcceptInvitation creates the invitee's user with the role of the invitation. Each
+	// invitation can be accepted once, before it expires.
+	AcceptInvitation(req AcceptInvitationRequest) (*models.User, error)
+}
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	authRepo       repositories.AuthRepository
+	db             *sql.DB
+}
+
+// NewInvitationService creates a new InvitationService.
+func NewInvitationService(invitationRepo repositories.InvitationRepository, authRepo repositories.AuthRepository, db *sql.DB) InvitationService {
+	return &invitationService{invitationRepo: invitationRepo, authRepo: authRepo, db: db}
+}
+
+func (s *invitationService) CreateInvitation(req CreateInvitationRequest, invitedBy int64) (*CreatedInvitation, error) {
+	invitation := models.Invitation{InvitedBy: &invitedBy}
+	if req.Email != nil && strings.TrimSpace(*req.Email) != "" {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		invitation.Email = &email
+	}
+	if req.PhoneNumber != nil && strings.TrimSpace(*req.PhoneNumber) != "" {
+		phone := strings.TrimSpace(*req.PhoneNumber)
+		invitation.PhoneNumber = &phone
+	}
+	if invitation.Email == nil && invitation.PhoneNumber == nil {
+		return nil, fmt.Errorf("%w: email or phone_number is required", ErrInvitationValidation)
+	}
+	if req.FullName != nil && strings.TrimSpace(*req.FullName) != "" {
+		fullName := strings.TrimSpace(*req.FullName)
+		invitation.FullName = &fullName
+	}
+	roleFound := false
+	for _, role := range invitableRoles {
+		if strings.EqualFold(role, req.RoleName) {
+			invitation.RoleID = roleIDs[strings.ToLower(role)]
+			roleFound = true
+			break
+		}
+	}
+	if !roleFound {
+		return nil, fmt.Errorf("%w: role_name must be one of %v", ErrInvitationValidation, invitableRoles)
+	}
+	ttl := DefaultInvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	invitation.ExpiresAt = utils.NowUTC().Add(ttl)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	token := invitationTokenPrefix + hex.EncodeToString(secret)
+	invitation.TokenHash = hashAPIKey(token)
+
+	created, err := s.invitationRepo.CreateInvitation(&invitation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+	created.SetStatus(utils.NowUTC())
+	return &CreatedInvitation{Invitation: *created, Token: token}, nil
+}
+
+func (s *invitationService) GetInvitations() ([]models.Invitation, error) {
+	invitations, err := s.invitationRepo.GetInvitations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	now := utils.NowUTC()
+	for i := range invitations {
+		invitations[i].SetStatus(now)
+	}
+	return invitations, nil
+}
+
+func (s *invitationService) RevokeInvitation(id int64) error {
+	if err := s.invitationRepo.RevokeInvitation(id, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrInvitationNotFound
+		}
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	return nil
+}
+
+func (s *invitationService) AcceptInvitation(req AcceptInvitationRequest) (*models.User, error) {
+	if !strings.HasPrefix(req.Token, invitationTokenPrefix) {
+		return nil, ErrInvalidInvitation
+	}
+	invitation, err := s.invitationRepo.GetInvitationByTokenHash(hashAPIKey(req.Token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	now := utils.NowUTC()
+	invitation.SetStatus(now)
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, ErrInvalidInvitation
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvitationValidation)
+	}
+	fullName := invitation.FullName
+	if name := strings.TrimSpace(req.FullName); name != "" {
+		fullName = &name
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user := models.User{
+		Username:    username,
+		Email:       invitation.Email,
+		FullName:    fullName,
+		PhoneNumber: invitation.PhoneNumber,
+		RoleID:      &invitation.RoleID,
+	}
+	userID, err := s.authRepo.CreateUser(tx, &user, string(hash))
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, userDuplicateError(err)
+		}
+		return nil, fmt.Errorf("failed to create invited user: %w", err)
+	}
+	// Fails if the invitation was accepted or revoked meanwhile
+	if err := s.invitationRepo.AcceptInvitation(tx, invitation.ID, userID, now); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	created, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invited user created but failed to retrieve it: %w", err)
+	}
+	created.PasswordHash = ""
+	return created, nil
+}
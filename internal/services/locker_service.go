@@ -0,0 +1,280 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrLockerNotFound       = apperrors.New(utils.ErrCodeNotFound, "locker not found")
+	ErrLockerExists         = apperrors.New(utils.ErrCodeConflict, "a locker with this number already exists")
+	ErrLockerRented         = apperrors.New(utils.ErrCodeConflict, "the locker is already rented")
+	ErrLockerInactive       = apperrors.New(utils.ErrCodeConflict, "the locker is out of service")
+	ErrLockerRentalNotFound = apperrors.New(utils.ErrCodeNotFound, "locker rental not found")
+	ErrLockerReleased       = apperrors.New(utils.ErrCodeConflict, "the locker was already released")
+	ErrLockerValidation     = apperrors.New(utils.ErrCodeValidationFailed, "locker validation error")
+)
+
+// LockerRequest is the body of POST and PUT /lockers.
+type LockerRequest struct {
+	Number    string        `json:"number" binding:"required,max=20"`
+	RentalFee *models.Money `json:"rental_fee" binding:"omitempty,money"` // Defaults to 0
+	Deposit   *models.Money `json:"deposit" binding:"omitempty,money"`    // Defaults to 0
+	IsActive  *bool         `json:"is_active"`                            // Defaults to true
+	Version   *int          `json:"version"`                              // Checked on update if set
+}
+
+// RentLockerRequest is the body of POST /lockers/:id/rent. The rental belongs to the visit of
+// a table session, a booking or a client; the client and booking default to the session's.
+type RentLockerRequest struct {
+	TableSessionID *int64        `json:"table_session_id"`
+	BookingID      *int64        `json:"booking_id"`
+	ClientID       *int64        `json:"client_id"`
+	Fee            *models.Money `json:"fee" binding:"omitempty,money"`     // Defaults to the locker's rental fee
+	Deposit        *models.Money `json:"deposit" binding:"omitempty,money"` // Defaults to the locker's deposit
+	Notes          *string       `json:"notes"`
+}
+
+// ReleaseLockerRequest is the body of POST /locker-rentals/:id/release.
+type ReleaseLockerRequest struct {
+	DepositReturned *bool `json:"deposit_returned"` // Defaults to true; false keeps the deposit, e.g. for a lost key
+}
+
+// --- LockerService Interface ---
+type LockerService interface {
+	CreateLocker(req LockerRequest) (*models.Locker, error)
+	// GetLockers returns the lockers of this branch with their current rentals.
+	GetLockers() ([]models.Locker, error)
+	GetLocker(id int64) (*models.Locker, error)
+	UpdateLocker(id int64, req LockerRequest) (*models.Locker, error)
+	// RentLocker assigns a free locker to a visit. A fee is billed with the table session of the visit.
+	RentLocker(lockerID int64, req RentLockerRequest, rentedBy int64) (*models.LockerRental, error)
+	GetRentals(filters models.LockerRentalFilters) ([]models.LockerRental, error)
+	GetRental(id int64) (*models.LockerRental, error)
+	// ReleaseRental frees the locker of a rental, recording whether the deposit was returned.
+	ReleaseRental(id int64, req ReleaseLockerRequest, releasedBy int64) (*models.LockerRental, error)
+}
+
+type lockerService struct {
+	lockerRepo  repositories.LockerRepository
+	sessionRepo repositories.TableSessionRepository
+	bookingRepo repositories.BookingRepository
+	db          *sql.DB
+}
+
+// NewLockerService creates a new LockerService.
+func NewLockerService(lockerRepo repositories.LockerRepository, sessionRepo repositories.TableSessionRepository,
+	bookingRepo repositories.BookingRepository, db *sql.DB) LockerService {
+	return &lockerService{lockerRepo: lockerRepo, sessionRepo: sessionRepo, bookingRepo: bookingRepo, db: db}
+}
+
+// applyLockerRequest sets the fields of req on locker.
+func applyLockerRequest(locker *models.Locker, req LockerRequest) error {
+	if req.RentalFee != nil {
+		if req.RentalFee.IsNegative() {
+			return fmt.Errorf("%w: rental_fee cannot be negative", ErrLockerValidation)
+		}
+		locker.RentalFee = *req.RentalFee
+	}
+	if req.Deposit != nil {
+		if req.Deposit.IsNegative() {
+			return fmt.Errorf("%w: deposit cannot be negative", ErrLockerValidation)
+		}
+		locker.Deposit = *req.Deposit
+	}
+	locker.Number = req.Number
+	if req.IsActive != nil {
+		locker.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func (s *lockerService) CreateLocker(req LockerRequest) (*models.Locker, error) {
+	locker := &models.Locker{BranchCode: utils.BranchCode(), IsActive: true}
+	if err := applyLockerRequest(locker, req); err != nil {
+		return nil, err
+	}
+	if err := s.lockerRepo.CreateLocker(locker); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrLockerExists
+		}
+		return nil, fmt.Errorf("failed to create locker: %w", err)
+	}
+	return locker, nil
+}
+
+func (s *lockerService) GetLockers() ([]models.Locker, error) {
+	lockers, err := s.lockerRepo.GetLockers(utils.BranchCode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lockers: %w", err)
+	}
+	return lockers, nil
+}
+
+// GetLocker returns a locker of this branch; the lockers of other branches are not found.
+func (s *lockerService) GetLocker(id int64) (*models.Locker, error) {
+	locker, err := s.lockerRepo.GetLockerByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrLockerNotFound
+		}
+		return nil, fmt.Errorf("failed to get locker: %w", err)
+	}
+	if locker.BranchCode != utils.BranchCode() {
+		return nil, ErrLockerNotFound
+	}
+	return locker, nil
+}
+
+func (s *lockerService) UpdateLocker(id int64, req LockerRequest) (*models.Locker, error) {
+	locker, err := s.GetLocker(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyLockerRequest(locker, req); err != nil {
+		return nil, err
+	}
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	if err := s.lockerRepo.UpdateLocker(locker, version); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrDuplicateKey):
+			return nil, ErrLockerExists
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrLockerNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update locker: %w", err)
+	}
+	return locker, nil
+}
+
+func (s *lockerService) RentLocker(lockerID int64, req RentLockerRequest, rentedBy int64) (*models.LockerRental, error) {
+	if req.TableSessionID == nil && req.BookingID == nil && req.ClientID == nil {
+		return nil, fmt.Errorf("%w: rent the locker to a table_session_id, booking_id or client_id", ErrLockerValidation)
+	}
+	locker, err := s.GetLocker(lockerID)
+	if err != nil {
+		return nil, err
+	}
+	if !locker.IsActive {
+		return nil, ErrLockerInactive
+	}
+	if locker.CurrentRental != nil {
+		return nil, ErrLockerRented
+	}
+
+	rental := &models.LockerRental{
+		LockerID:       locker.ID,
+		LockerNumber:   locker.Number,
+		ClientID:       req.ClientID,
+		TableSessionID: req.TableSessionID,
+		BookingID:      req.BookingID,
+		Fee:            locker.RentalFee,
+		Deposit:        locker.Deposit,
+		Notes:          req.Notes,
+		RentedBy:       &rentedBy,
+		RentedAt:       utils.NowUTC(),
+	}
+	if req.Fee != nil {
+		rental.Fee = *req.Fee
+	}
+	if req.Deposit != nil {
+		rental.Deposit = *req.Deposit
+	}
+	if rental.Fee.IsNegative() || rental.Deposit.IsNegative() {
+		return nil, fmt.Errorf("%w: fee and deposit cannot be negative", ErrLockerValidation)
+	}
+	if req.TableSessionID != nil {
+		session, err := s.sessionRepo.GetTableSessionByID(*req.TableSessionID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: table session ID %d not found", ErrLockerValidation, *req.TableSessionID)
+			}
+			return nil, fmt.Errorf("failed to get table session: %w", err)
+		}
+		// The fee is billed when the session stops
+		if !session.Running() {
+			return nil, fmt.Errorf("%w: table session ID %d is already stopped", ErrLockerValidation, session.ID)
+		}
+		if rental.BookingID == nil {
+			rental.BookingID = session.BookingID
+		}
+		if rental.ClientID == nil {
+			rental.ClientID = session.ClientID
+		}
+	}
+	if rental.BookingID != nil {
+		booking, err := s.bookingRepo.GetBookingByID(*rental.BookingID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: booking ID %d not found", ErrLockerValidation, *rental.BookingID)
+			}
+			return nil, fmt.Errorf("failed to get booking: %w", err)
+		}
+		if rental.ClientID == nil {
+			rental.ClientID = booking.ClientID
+		}
+	}
+
+	if err := s.lockerRepo.CreateRental(s.db, rental); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrLockerRented
+		}
+		return nil, fmt.Errorf("failed to rent locker: %w", err)
+	}
+	return rental, nil
+}
+
+func (s *lockerService) GetRentals(filters models.LockerRentalFilters) ([]models.LockerRental, error) {
+	rentals, err := s.lockerRepo.GetRentals(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locker rentals: %w", err)
+	}
+	return rentals, nil
+}
+
+func (s *lockerService) GetRental(id int64) (*models.LockerRental, error) {
+	rental, err := s.lockerRepo.GetRentalByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrLockerRentalNotFound
+		}
+		return nil, fmt.Errorf("failed to get locker rental: %w", err)
+	}
+	return rental, nil
+}
+
+func (s *lockerService) ReleaseRental(id int64, req ReleaseLockerRequest, releasedBy int64) (*models.LockerRental, error) {
+	rental, err := s.GetRental(id)
+	if err != nil {
+		return nil, err
+	}
+	if !rental.Active() {
+		return nil, ErrLockerReleased
+	}
+	now := utils.NowUTC()
+	depositReturned := req.DepositReturned == nil || *req.DepositReturned
+	rental.ReleasedAt = &now
+	rental.ReleasedBy = &releasedBy
+	rental.DepositReturned = &depositReturned
+	if err := s.lockerRepo.ReleaseRental(s.db, rental); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrLockerReleased
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrLockerRentalNotFound
+		}
+		return nil, fmt.Errorf("failed to release locker: %w", err)
+	}
+	return rental, nil
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrLostFoundNotFound      = apperrors.New(utils.ErrCodeNotFound, "lost and found item not found")
+	ErrLostFoundPhotoNotFound = apperrors.New(utils.ErrCodeNotFound, "the lost and found item has no photo")
+	ErrLostFoundReturned      = apperrors.New(utils.ErrCodeConflict, "the item was already returned")
+	ErrLostFoundValidation    = apperrors.New(utils.ErrCodeValidationFailed, "lost and found validation error")
+)
+
+var (
+	lostFoundSettings   = models.DefaultLostFoundSettings()
+	lostFoundSettingsMu sync.RWMutex
+)
+
+// SetLostFoundSettings sets the lost and found rules (the lost_and_found setting).
+func SetLostFoundSettings(settings models.LostFoundSettings) {
+	lostFoundSettingsMu.Lock()
+	defer lostFoundSettingsMu.Unlock()
+	lostFoundSettings = settings
+}
+
+// CurrentLostFoundSettings returns the configured lost and found rules.
+func CurrentLostFoundSettings() models.LostFoundSettings {
+	lostFoundSettingsMu.RLock()
+	defer lostFoundSettingsMu.RUnlock()
+	return lostFoundSettings
+}
+
+// LostFoundPurgeInterval is how often RunPurge deletes the entries past their retention.
+var LostFoundPurgeInterval = time.Hour
+
+// LostFoundItemRequest is the body of POST and PUT /lost-and-found.
+type LostFoundItemRequest struct {
+	Description string     `json:"description" binding:"required"`
+	TableID     *int64     `json:"table_id"`
+	FoundAt     *time.Time `json:"found_at"` // Defaults to now on create
+	Notes       *string    `json:"notes"`
+	Version     *int       `json:"version"` // Checked on update if set
+}
+
+// ReturnLostFoundItemRequest is the body of POST /lost-and-found/:id/return. The item is
+// returned to a known client or to someone named.
+type ReturnLostFoundItemRequest struct {
+	ClientID *int64  `json:"client_id"`
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+}
+
+// --- LostFoundService Interface ---
+type LostFoundService interface {
+	// LogItem logs an item found at this branch.
+	LogItem(req LostFoundItemRequest, foundBy int64) (*models.LostFoundItem, error)
+	// GetItems returns the items of this branch matching filters, most recently found first.
+	GetItems(filters models.LostFoundFilters) ([]models.LostFoundItem, error)
+	GetItem(id int64) (*models.LostFoundItem, error)
+	UpdateItem(id int64, req LostFoundItemRequest) (*models.LostFoundItem, error)
+	// MarkReturned records that an item was handed back to its owner.
+	MarkReturned(id int64, req ReturnLostFoundItemRequest, returnedBy int64) (*models.LostFoundItem, error)
+	// SetPhoto replaces the photo of an item with a JPEG, PNG or WebP image.
+	SetPhoto(id int64, data []byte) error
+	GetPhoto(id int64) (*models.Photo, error)
+	DeletePhoto(id int64) error
+	DeleteItem(id int64) error
+	// RunPurge deletes the entries found longer ago than the retention of the lost_and_found
+	// setting, every LostFoundPurgeInterval until ctx is done. Every instance may run it.
+	RunPurge(ctx context.Context)
+}
+
+type lostFoundService struct {
+	lostFoundRepo repositories.LostFoundRepository
+	bookingRepo   repositories.BookingRepository
+	clientRepo    repositories.ClientRepository
+}
+
+// NewLostFoundService creates a new LostFoundService.
+func NewLostFoundService(lostFoundRepo repositories.LostFoundRepository, bookingRepo repositories.BookingRepository,
+	clientRepo repositories.ClientRepository) LostFoundService {
+	return &lostFoundService{lostFoundRepo: lostFoundRepo, bookingRepo: bookingRepo, clientRepo: clientRepo}
+}
+
+// withPurgeAt sets when item is purged under the current retention.
+func withPurgeAt(item *models.LostFoundItem) {
+	if retention := CurrentLostFoundSettings().Retention(); retention > 0 {
+		purgeAt := item.FoundAt.Add(retention)
+		item.PurgeAt = &purgeAt
+	}
+}
+
+// applyLostFoundRequest sets the fields of req on item.
+func (s *lostFoundService) applyLostFoundRequest(item *models.LostFoundItem, req LostFoundItemRequest) error {
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		return fmt.Errorf("%w: description is required", ErrLostFoundValidation)
+	}
+	if req.TableID != nil {
+		if _, err := s.bookingRepo.GetGameTableByID(*req.TableID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return fmt.Errorf("%w: game table ID %d not found", ErrLostFoundValidation, *req.TableID)
+			}
+			return fmt.Errorf("failed to get game table: %w", err)
+		}
+	}
+	if req.FoundAt != nil {
+		if req.FoundAt.After(utils.NowUTC()) {
+			return fmt.Errorf("%w: found_at cannot be in the future", ErrLostFoundValidation)
+		}
+		item.FoundAt = req.FoundAt.UTC()
+	}
+	item.Description = description
+	item.TableID = req.TableID
+	item.Notes = req.Notes
+	return nil
+}
+
+func (s *lostFoundService) LogItem(req LostFoundItemRequest, foundBy int64) (*models.LostFoundItem, error) {
+	item := &models.LostFoundItem{
+		BranchCode: utils.BranchCode(),
+		FoundAt:    utils.NowUTC(),
+		FoundBy:    &foundBy,
+		Status:     models.LostFoundStatusStored,
+	}
+	if err := s.applyLostFoundRequest(item, req); err != nil {
+		return nil, err
+	}
+	if err := s.lostFoundRepo.CreateItem(item); err != nil {
+		return nil, fmt.Errorf("failed to log lost and found item: %w", err)
+	}
+	withPurgeAt(item)
+	return item, nil
+}
+
+func (s *lostFoundService) GetItems(filters models.LostFoundFilters) ([]models.LostFoundItem, error) {
+	if filters.Status != "" && filters.Status != models.LostFoundStatusStored && filters.Status != models.LostFoundStatusReturned {
+		return nil, fmt.Errorf("%w: invalid status '%s'", ErrLostFoundValidation, filters.Status)
+	}
+	items, err := s.lostFoundRepo.GetItems(utils.BranchCode(), filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lost and found items: %w", err)
+	}
+	for i := range items {
+		withPurgeAt(&items[i])
+	}
+	return items, nil
+}
+
+// GetItem returns an item of this branch; the items of other branches are not found.
+func (s *lostFoundService) GetItem(id int64) (*models.LostFoundItem, error) {
+	item, err := s.lostFoundRepo.GetItemByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrLostFoundNotFound
+		}
+		return nil, fmt.Errorf("failed to get lost and found item: %w", err)
+	}
+	if item.BranchCode != utils.BranchCode() {
+		return nil, ErrLostFoundNotFound
+	}
+	withPurgeAt(item)
+	return item, nil
+}
+
+func (s *lostFoundService) UpdateItem(id int64, req LostFoundItemRequest) (*models.LostFoundItem, error) {
+	item, err := s.GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyLostFoundRequest(item, req); err != nil {
+		return nil, err
+	}
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	if err := s.lostFoundRepo.UpdateItem(item, version); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrLostFoundNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update lost and found item: %w", err)
+	}
+	withPurgeAt(item)
+	return item, nil
+}
+
+func (s *lostFoundService) MarkReturned(id int64, req ReturnLostFoundItemRequest, returnedBy int64) (*models.LostFoundItem, error) {
+	var name *string
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		trimmed := strings.TrimSpace(*req.Name)
+		name = &trimmed
+	}
+	if req.ClientID == nil && name == nil {
+		return nil, fmt.Errorf("%w: give the client_id or name of who collected the item", ErrLostFoundValidation)
+	}
+	item, err := s.GetItem(id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == models.LostFoundStatusReturned {
+		return nil, ErrLostFoundReturned
+	}
+	if req.ClientID != nil {
+		if _, err := s.clientRepo.GetClientByID(*req.ClientID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: client ID %d not found", ErrLostFoundValidation, *req.ClientID)
+			}
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+	}
+
+	now := utils.NowUTC()
+	item.ReturnedToClientID = req.ClientID
+	item.ReturnedToName = name
+	item.ReturnedAt = &now
+	item.ReturnedBy = &returnedBy
+	if err := s.lostFoundRepo.MarkItemReturned(item); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrLostFoundNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrLostFoundReturned
+		}
+		return nil, fmt.Errorf("failed to return lost and found item: %w", err)
+	}
+	return item, nil
+}
+
+func (s *lostFoundService) SetPhoto(id int64, data []byte) error {
+	contentType, err := checkPhoto(data, ErrLostFoundValidation)
+	if err != nil {
+		return err
+	}
+	if _, err := s.GetItem(id); err != nil {
+		return err
+	}
+	if err := s.lostFoundRepo.SetItemPhoto(id, &models.Photo{ContentType: contentType, Data: data}); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrLostFoundNotFound
+		}
+		return fmt.Errorf("failed to save lost and found photo: %w", err)
+	}
+	return nil
+}
+
+func (s *lostFoundService) GetPhoto(id int64) (*models.Photo, error) {
+	if _, err := s.GetItem(id); err != nil {
+		return nil, err
+	}
+	photo, err := s.lostFoundRepo.GetItemPhoto(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrLostFoundPhotoNotFound
+		}
+		return nil, fmt.Errorf("failed to get lost and found photo: %w", err)
+	}
+	return photo, nil
+}
+
+func (s *lostFoundService) DeletePhoto(id int64) error {
+	if _, err := s.GetItem(id); err != nil {
+		return err
+	}
+	if err := s.lostFoundRepo.SetItemPhoto(id, nil); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrLostFoundNotFound
+		}
+		return fmt.Errorf("failed to delete lost and found photo: %w", err)
+	}
+	return nil
+}
+
+func (s *lostFoundService) DeleteItem(id int64) error {
+	if _, err := s.GetItem(id); err != nil {
+		return err
+	}
+	if err := s.lostFoundRepo.DeleteItem(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrLostFoundNotFound
+		}
+		return fmt.Errorf("failed to delete lost and found item: %w", err)
+	}
+	return nil
+}
+
+func (s *lostFoundService) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(LostFoundPurgeInterval)
+	defer ticker.Stop()
+	for {
+		s.purge(utils.NowUTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes the entries found before the retention, unless they are kept forever.
+func (s *lostFoundService) purge(now time.Time) {
+	retention := CurrentLostFoundSettings().Retention()
+	if retention <= 0 {
+		return
+	}
+	deleted, err := s.lostFoundRepo.DeleteItemsFoundBefore(now.Add(-retention))
+	if err != nil {
+		utils.LogError(err, "Failed to purge lost and found items")
+		return
+	}
+	if deleted > 0 {
+		utils.LogInfo("Purged lost and found items", map[string]interface{}{"deleted": deleted})
+	}
+}
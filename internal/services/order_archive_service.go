@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrArchivedOrderNotFound = apperrors.New(utils.ErrCodeNotFound, "archived order not found")
+
+var (
+	orderArchiveSettings   models.OrderArchiveSettings
+	orderArchiveSettingsMu sync.RWMutex
+)
+
+// SetOrderArchiveSettings sets the retention of the orders (the order_archive setting).
+func SetOrderArchiveSettings(settings models.OrderArchiveSettings) {
+	orderArchiveSettingsMu.Lock()
+	defer orderArchiveSettingsMu.Unlock()
+	orderArchiveSettings = settings
+}
+
+// CurrentOrderArchiveSettings returns the configured retention of the orders.
+func CurrentOrderArchiveSettings() models.OrderArchiveSettings {
+	orderArchiveSettingsMu.RLock()
+	defer orderArchiveSettingsMu.RUnlock()
+	return orderArchiveSettings
+}
+
+// OrderArchiveInterval is how often RunArchival moves the orders past their retention.
+var OrderArchiveInterval = 24 * time.Hour
+
+// OrderArchiveBatchSize is how many orders are moved per transaction, so the archival never
+// holds the locks of many orders at once.
+const OrderArchiveBatchSize = 1000
+
+// --- OrderArchiveService Interface ---
+type OrderArchiveService interface {
+	// ArchiveOrders moves the completed orders whose business date is more than the retention
+	// of the order_archive setting ago to the archive, and returns how many were moved.
+	ArchiveOrders() (int, error)
+	// RunArchival archives the orders at start and every OrderArchiveInterval until ctx is
+	// done. Every instance may run it.
+	RunArchival(ctx context.Context)
+	// GetArchivedOrders returns a page of the archived orders matching filters, newest first.
+	GetArchivedOrders(filters models.OrderFilters) ([]models.ArchivedOrder, int, error)
+	// GetArchivedOrder returns an archived order with its items.
+	GetArchivedOrder(orderID int64) (*models.ArchivedOrder, error)
+}
+
+type orderArchiveService struct {
+	archiveRepo repositories.OrderArchiveRepository
+	db          *sql.DB
+}
+
+// NewOrderArchiveService creates a new OrderArchiveService.
+func NewOrderArchiveService(archiveRepo repositories.OrderArchiveRepository, db *sql.DB) OrderArchiveService {
+	return &orderArchiveService{archiveRepo: archiveRepo, db: db}
+}
+
+func (s *orderArchiveService) ArchiveOrders() (int, error) {
+	settings := CurrentOrderArchiveSettings()
+	if !settings.Enabled() {
+		return 0, nil
+	}
+	before := utils.NowInClub().AddDate(0, -settings.RetentionMonths, 0).Format(utils.DateLayout)
+	archived := 0
+	for {
+		count, err := s.archiveBatch(before)
+		archived += count
+		if err != nil {
+			return archived, err
+		}
+		if count < OrderArchiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// archiveBatch moves up to OrderArchiveBatchSize completed orders from before the business
+// date in one transaction.
+func (s *orderArchiveService) archiveBatch(before string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count, err := s.archiveRepo.ArchiveOrders(tx, StatusCompleted, before, OrderArchiveBatchSize, utils.NowUTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive orders: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archived orders: %w", err)
+	}
+	return count, nil
+}
+
+func (s *orderArchiveService) RunArchival(ctx context.Context) {
+	ticker := time.NewTicker(OrderArchiveInterval)
+	defer ticker.Stop()
+	for {
+		archived, err := s.ArchiveOrders()
+		if err != nil {
+			utils.LogError(err, "Failed to archive orders")
+		}
+		if archived > 0 {
+			utils.LogInfo("Archived orders", map[string]interface{}{"archived": archived})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *orderArchiveService) GetArchivedOrders(filters models.OrderFilters) ([]models.ArchivedOrder, int, error) {
+	orders, total, err := s.archiveRepo.GetArchivedOrders(filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get archived orders: %w", err)
+	}
+	return orders, total, nil
+}
+
+func (s *orderArchiveService) GetArchivedOrder(orderID int64) (*models.ArchivedOrder, error) {
+	order, err := s.archiveRepo.GetArchivedOrderByID(orderID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrArchivedOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get archived order: %w", err)
+	}
+	items, err := s.archiveRepo.GetArchivedOrderItems(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived order items: %w", err)
+	}
+	order.OrderItems = items
+	return order, nil
+}
@@ -1,23 +1,32 @@
 package services
 
 import (
-	"database/sql"
 	"database/sql"
 	"errors"
 	"fmt"
+	"ps_club_backend/internal/events"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
 	"ps_club_backend/pkg/utils" // Added for utils.NewNullString
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
 )
 
 // Custom Errors - some might be redefined or become more specific
 var (
-	ErrPricelistItemNotFound = errors.New("pricelist item not found or not available")
-	ErrInsufficientStock     = errors.New("insufficient stock for item")
-	ErrOrderNotFound         = errors.New("order not found")
-	ErrInvalidOrderStatus    = errors.New("invalid order status")
+	ErrPricelistItemNotFound = apperrors.New(utils.ErrCodeNotFound, "pricelist item not found or not available")
+	ErrInsufficientStock     = apperrors.New(utils.ErrCodeConflict, "insufficient stock for item")
+	ErrOrderNotFound         = apperrors.New(utils.ErrCodeNotFound, "order not found")
+	ErrInvalidOrderStatus    = apperrors.New(utils.ErrCodeValidationFailed, "invalid order status")
+	ErrInvalidOrderNumber    = apperrors.New(utils.ErrCodeValidationFailed, "invalid order number, use YYYY-MM-DD/#N or N for today")
+	ErrOrderItemUUIDTaken    = apperrors.New(utils.ErrCodeConflict, "another order already has an item with the UUID")
+	ErrOrderItemNotFound     = apperrors.New(utils.ErrCodeNotFound, "order item not found")
 	// TODO: Consider adding more specific errors for different failure scenarios
 	// e.g., ErrOrderCreationConflict if some underlying data changed during creation
 )
@@ -34,6 +43,9 @@ const (
 	StatusRefunded   = "refunded"
 )
 
+// OrderStatuses lists the valid order statuses.
+var OrderStatuses = []string{StatusPending, StatusPreparing, StatusReady, StatusServed, StatusCompleted, StatusPaid, StatusCancelled, StatusRefunded}
+
 // --- Data Transfer Objects (DTOs) --- (These remain the same as they are for service input/output)
 
 // CreateOrderItemRequest is used for creating individual order items.
@@ -41,6 +53,12 @@ type CreateOrderItemRequest struct {
 	PricelistItemID int64  `json:"pricelist_item_id" binding:"required"`
 	Quantity        int    `json:"quantity" binding:"required,gt=0"`
 	Notes           string `json:"notes"`
+	// UUID is given by the client to the item; unique across all order items
+	UUID *string `json:"uuid" binding:"omitempty,uuid"`
+
+	// Set by the caller, not the client: the price charged for an order taken offline, kept
+	// instead of the current price
+	UnitPrice *models.Money `json:"-"`
 }
 
 // CreateOrderRequest is used for creating a new order.
@@ -49,11 +67,43 @@ type CreateOrderRequest struct {
 	BookingID      *int64                   `json:"booking_id"`
 	StaffID        int64                    `json:"staff_id" binding:"required"`
 	TableID        *int64                   `json:"table_id"`
-	Status         string                   `json:"status" binding:"required"`
+	Status         string                   `json:"status" binding:"required,order_status"`
 	PaymentMethod  *string                  `json:"payment_method"`
 	Notes          *string                  `json:"notes"`
 	OrderItems     []CreateOrderItemRequest `json:"order_items" binding:"required,dive"`
-	DiscountAmount *float64                 `json:"discount_amount"`
+	DiscountAmount *models.Money            `json:"discount_amount" binding:"omitempty,money"`
+	GuestCount     *int                     `json:"guest_count" binding:"omitempty,min=1"`
+	// PromoCode redeems an active promo code, whose percentage of the total is the discount;
+	// discount_amount must then be left out
+	PromoCode *string `json:"promo_code"`
+	// ServiceCharge adds the service charge of the service_charge setting (true) or waives it
+	// (false); unset, it is added to large groups and VIP tables as the setting says
+	ServiceCharge *bool `json:"service_charge"`
+	// UUID is given by the client to the order so that a retried request returns the order
+	// created the first time instead of creating it again
+	UUID *string `json:"uuid" binding:"omitempty,uuid"`
+
+	// Set by the caller, not the client: the discount must be within the limit of
+	// CallerRole unless a manager approved it.
+	CallerRole       string `json:"-"`
+	DiscountApproved bool   `json:"-"`
+	// An order a POS took offline keeps the time it was taken, and sells items already
+	// handed over even if the stock recorded is short
+	OrderTime           *time.Time `json:"-"`
+	AllowStockShortfall bool       `json:"-"`
+	// Source is the channel the order is placed through, one of models.OrderSources, from the
+	// authenticated principal; empty for the POS. Orders of self-service channels have no
+	// StaffID.
+	Source string `json:"-"`
+}
+
+// orderStaffID returns the staff member who took the order of req, nil for an order placed
+// through a self-service channel.
+func orderStaffID(req CreateOrderRequest) *int64 {
+	if req.StaffID == 0 {
+		return nil
+	}
+	return &req.StaffID
 }
 
 // OrderItemResponse represents an item within an order for API responses.
@@ -63,8 +113,8 @@ type OrderItemResponse struct {
 	PricelistItemID int64    `json:"pricelist_item_id"`
 	ItemName        string   `json:"item_name"`
 	Quantity        int      `json:"quantity"`
-	UnitPrice       float64  `json:"unit_price"`
-	TotalPrice      float64  `json:"total_price"`
+	UnitPrice       models.Money `json:"unit_price"`
+	TotalPrice      models.Money `json:"total_price"`
 	Notes           *string  `json:"notes"`
 }
 
@@ -77,9 +127,9 @@ type OrderResponse struct {
 	StaffName      *string             `json:"staff_name,omitempty"` // Changed to pointer
 	TableName      *string             `json:"table_name,omitempty"`
 	Status         string              `json:"status"`
-	TotalAmount    float64             `json:"total_amount"`
-	DiscountAmount float64             `json:"discount_amount"`
-	FinalAmount    float64             `json:"final_amount"`
+	TotalAmount    models.Money        `json:"total_amount"`
+	DiscountAmount models.Money        `json:"discount_amount"`
+	FinalAmount    models.Money        `json:"final_amount"`
 	PaymentMethod  *string             `json:"payment_method,omitempty"`
 	Notes          *string             `json:"notes,omitempty"`
 	OrderItems     []OrderItemResponse `json:"order_items"`
@@ -89,7 +139,36 @@ type OrderResponse struct {
 
 // UpdateOrderStatusRequest is used for updating the status of an order.
 type UpdateOrderStatusRequest struct {
-	Status string `json:"status" binding:"required"`
+	Status  string `json:"status" binding:"required,order_status"`
+	Version *int   `json:"version"` // Version the client last read; a stale value is rejected with ErrVersionConflict
+	// VoidReason is required to cancel the order, one of models.VoidReasons; "other" needs a VoidNote
+	VoidReason *string `json:"void_reason" binding:"omitempty,void_reason"`
+	VoidNote   *string `json:"void_note"`
+
+	// Set by the caller, not the client: only an Admin may change orders of a closed day.
+	// Empty for internal calls, e.g. an approved refund or the end-of-day close.
+	CallerRole string `json:"-"`
+	CallerID   int64  `json:"-"` // UserID recorded as having cancelled the order; 0 for internal calls
+}
+// BulkUpdateOrderStatusRequest sets the status of several orders, selected by ID or by
+// their current status, e.g. {"from_status": "served", "status": "completed"} at the end of the night.
+type BulkUpdateOrderStatusRequest struct {
+	OrderIDs   []int64 `json:"order_ids"`
+	FromStatus string  `json:"from_status" binding:"omitempty,order_status"`
+	Status     string  `json:"status" binding:"required,order_status"`
+	VoidReason *string `json:"void_reason" binding:"omitempty,void_reason"` // Required to cancel, see UpdateOrderStatusRequest
+	VoidNote   *string `json:"void_note"`
+	CallerRole string  `json:"-"` // Set by the caller, not the client; see UpdateOrderStatusRequest
+	CallerID   int64   `json:"-"`
+}
+
+// VoidOrderItemRequest voids an item of an open order, e.g. {"void_reason": "wrong_item"}.
+type VoidOrderItemRequest struct {
+	VoidReason string  `json:"void_reason" binding:"required,void_reason"` // One of models.VoidReasons
+	VoidNote   *string `json:"void_note"`                                  // Required for "other"
+	Version    *int    `json:"version"`                                    // Of the order; see UpdateOrderStatusRequest
+	CallerRole string  `json:"-"`                                          // Set by the caller, not the client; see UpdateOrderStatusRequest
+	CallerID   int64   `json:"-"`
 }
 // --- End of DTOs ---
 
@@ -98,9 +177,24 @@ type UpdateOrderStatusRequest struct {
 type OrderService interface {
 	CreateOrder(req CreateOrderRequest) (*models.Order, error) // Returning models.Order for now
 	GetOrders(filters models.OrderFilters) ([]models.Order, int, error) // Added totalCount
+	// GetOrdersByCursor returns a page of orders, newest first, of filters.PageSize orders.
+	// Pass the NextCursor of a page as cursor to get the following page ("" for the first).
+	GetOrdersByCursor(filters models.OrderFilters, cursor string) (*models.CursorPage[models.Order], error)
+	// ExportOrders calls fn with every order matching filters (paging is ignored), newest
+	// first, as it is read, so exports of long periods are never held in memory.
+	ExportOrders(filters models.OrderFilters, fn func(*models.Order) error) error
 	GetOrderByID(orderID int64) (*models.Order, error) // Returning models.Order with items
+	GetOrderByNumber(number string) (*models.Order, error) // Display number of this branch, e.g. "2024-06-01/#37"
+	GetOrderItems(orderID int64) ([]models.OrderItem, error)
 	UpdateOrderStatus(orderID int64, req UpdateOrderStatusRequest) (*models.Order, error)
+	// BulkUpdateOrderStatus sets the status of the selected orders in one transaction and
+	// reports the outcome per order; orders that fail are left unchanged.
+	BulkUpdateOrderStatus(req BulkUpdateOrderStatusRequest) (*BulkResult, error)
+	// VoidOrderItem removes an item from an open order, returning its stock and taking its
+	// price, discount share and tax off the order, and records the void with its reason.
+	VoidOrderItem(orderID, itemID int64, req VoidOrderItemRequest) (*models.Order, error)
 	DeleteOrder(orderID int64) error
+	RenderReceipt(orderID int64) (string, error)
 }
 
 // --- orderService Implementation ---
@@ -108,6 +202,11 @@ type orderService struct {
 	orderRepo        repositories.OrderRepository
 	pricelistRepo    repositories.PricelistRepository
 	inventoryMvRepo  repositories.InventoryMovementRepository
+	batchRepo        repositories.StockBatchRepository // Sales take stock first expiry first out
+	accountRepo      repositories.ClientAccountRepository // Charges orders paid with the house_account method
+	publisher        events.Publisher // Records order events in the transaction of the change
+	dayCloseRepo     repositories.DayCloseRepository // Locks the orders of closed business days
+	promoRepo        repositories.PromoCodeRepository // Promo codes redeemed with orders
 	db               *sql.DB // For managing transactions
 }
 
@@ -116,12 +215,22 @@ func NewOrderService(
 	or repositories.OrderRepository,
 	pr repositories.PricelistRepository,
 	imr repositories.InventoryMovementRepository,
+	br repositories.StockBatchRepository,
+	ar repositories.ClientAccountRepository,
+	publisher events.Publisher,
+	dcr repositories.DayCloseRepository,
+	pcr repositories.PromoCodeRepository,
 	db *sql.DB,
 ) OrderService {
 	return &orderService{
 		orderRepo:        or,
 		pricelistRepo:    pr,
 		inventoryMvRepo:  imr,
+		batchRepo:        br,
+		accountRepo:      ar,
+		publisher:        publisher,
+		dayCloseRepo:     dcr,
+		promoRepo:        pcr,
 		db:               db,
 	}
 }
@@ -129,19 +238,31 @@ func NewOrderService(
 // --- Method Implementations ---
 
 func (s *orderService) CreateOrder(req CreateOrderRequest) (*models.Order, error) {
+	if existing, err := s.orderWithUUID(req.UUID); existing != nil || err != nil {
+		return existing, err
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, fmt.Errorf("failed to start database transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	var totalAmount float64
+	var totalAmount models.Money
 	orderItemsToCreate := make([]models.OrderItem, 0, len(req.OrderItems))
+	var sales []models.InventoryMovement // Taken from the batches once the order has an ID
 
+	itemUUIDs := make(map[string]bool)
 	for _, itemReq := range req.OrderItems {
 		if itemReq.Quantity <= 0 {
 			return nil, fmt.Errorf("%w: quantity for item ID %d must be positive", ErrValidation, itemReq.PricelistItemID)
 		}
+		if itemReq.UUID != nil {
+			if itemUUIDs[strings.ToLower(*itemReq.UUID)] {
+				return nil, fmt.Errorf("%w: order item UUID %s is given twice", ErrValidation, *itemReq.UUID)
+			}
+			itemUUIDs[strings.ToLower(*itemReq.UUID)] = true
+		}
 		price, stock, itemName, tracksStock, repoErr := s.pricelistRepo.GetItemPriceAndStock(itemReq.PricelistItemID)
 		if repoErr != nil {
 			if errors.Is(repoErr, repositories.ErrNotFound) {
@@ -150,82 +271,220 @@ func (s *orderService) CreateOrder(req CreateOrderRequest) (*models.Order, error
 			return nil, fmt.Errorf("failed to fetch pricelist item %d details: %w", itemReq.PricelistItemID, repoErr)
 		}
 
-		itemTotalPrice := price * float64(itemReq.Quantity)
-		totalAmount += itemTotalPrice
+		units, repoErr := s.pricelistRepo.GetItemUnits(itemReq.PricelistItemID)
+		if repoErr != nil {
+			return nil, fmt.Errorf("failed to fetch units of pricelist item %d: %w", itemReq.PricelistItemID, repoErr)
+		}
+
+		if itemReq.UnitPrice != nil {
+			price = *itemReq.UnitPrice
+		}
+		itemTotalPrice := price.MulInt(itemReq.Quantity) // Exact decimal arithmetic, no rounding
+		totalAmount = totalAmount.Add(itemTotalPrice)
+		stockQuantity := units.ForPortions(itemReq.Quantity) // Each sold unit takes a portion of the stock
 
 		if tracksStock {
-			if !stock.Valid || stock.Int64 < int64(itemReq.Quantity) {
-				return nil, fmt.Errorf("%w %s (ID: %d). Requested: %d, Available: %d",
-					ErrInsufficientStock, itemName, itemReq.PricelistItemID, itemReq.Quantity, stock.Int64)
+			if !req.AllowStockShortfall && (stock == nil || stock.Cmp(stockQuantity) < 0) {
+				available := models.ZeroQuantity
+				if stock != nil {
+					available = *stock
+				}
+				return nil, fmt.Errorf("%w %s (ID: %d). Requested: %s %s, Available: %s %s",
+					ErrInsufficientStock, itemName, itemReq.PricelistItemID, stockQuantity, units.StockUnit, available, units.StockUnit)
 			}
-			_, repoErr = s.pricelistRepo.UpdateStock(tx, itemReq.PricelistItemID, -itemReq.Quantity)
+			_, repoErr = s.pricelistRepo.UpdateStock(tx, itemReq.PricelistItemID, stockQuantity.Neg())
 			if repoErr != nil {
 				return nil, fmt.Errorf("failed to update stock for item %s (ID: %d): %w", itemName, itemReq.PricelistItemID, repoErr)
 			}
 			movement := models.InventoryMovement{
 				PricelistItemID: itemReq.PricelistItemID,
-				StaffID:         &req.StaffID,
-				MovementType:    MovementTypeSale,
-				QuantityChanged: -itemReq.Quantity,
+				StaffID:         orderStaffID(req),
+				MovementType:    models.MovementTypeSale,
+				QuantityChanged: stockQuantity.Neg(),
 				Reason:          utils.NewNullString("Order creation"), // Changed to utils
-				MovementDate:    time.Now(),
+				MovementDate:    time.Now().UTC(),
 			}
 			_, repoErr = s.inventoryMvRepo.CreateMovement(tx, &movement)
 			if repoErr != nil {
 				return nil, fmt.Errorf("failed to record inventory movement for sale of item %s (ID: %d): %w", itemName, itemReq.PricelistItemID, repoErr)
 			}
+			sales = append(sales, movement)
 		}
 		orderItemsToCreate = append(orderItemsToCreate, models.OrderItem{
 			PricelistItemID: itemReq.PricelistItemID,
 			Quantity:        itemReq.Quantity,
+			StockQuantity:   stockQuantity,
 			UnitPrice:       price,
 			TotalPrice:      itemTotalPrice,
 			Notes:           utils.NewNullString(itemReq.Notes), // Changed to utils
+			UUID:            itemReq.UUID,
 		})
 	}
 
+	var promo *models.PromoCode
+	if req.PromoCode != nil {
+		if req.DiscountAmount != nil {
+			return nil, fmt.Errorf("%w: give either a promo code or a discount amount", ErrPromoCodeValidation)
+		}
+		promo, err = s.promoRepo.GetPromoCodeByCode(tx, normalizePromoCode(*req.PromoCode))
+		if errors.Is(err, repositories.ErrNotFound) || (err == nil && !promo.Active) {
+			return nil, fmt.Errorf("%w: promo code %s is unknown or no longer active", ErrPromoCodeValidation, *req.PromoCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up promo code: %w", err)
+		}
+		// The discount of a promo code an Admin set up is not limited by the caller's role
+		discount := promo.DiscountOn(totalAmount)
+		req.DiscountAmount = &discount
+		req.DiscountApproved = true
+	}
+
 	finalAmount := totalAmount
 	if req.DiscountAmount != nil {
-		finalAmount = totalAmount - *req.DiscountAmount
-		if finalAmount < 0 {
-			finalAmount = 0
+		if req.DiscountAmount.IsNegative() {
+			return nil, fmt.Errorf("%w: discount amount cannot be negative", ErrValidation)
 		}
+		if !req.DiscountApproved {
+			if err := checkDiscountLimit(req.CallerRole, *req.DiscountAmount, totalAmount); err != nil {
+				return nil, err
+			}
+		}
+		finalAmount = totalAmount.Sub(*req.DiscountAmount)
+		if finalAmount.IsNegative() {
+			finalAmount = models.ZeroMoney
+		}
+		// The discount given is split over the items by line total, so the shares stored
+		// for the reports add up to it exactly
+		lineTotals := make([]models.Money, len(orderItemsToCreate))
+		for i, item := range orderItemsToCreate {
+			lineTotals[i] = item.TotalPrice
+		}
+		for i, share := range totalAmount.Sub(finalAmount).Allocate(lineTotals) {
+			orderItemsToCreate[i].DiscountAmount = share
+		}
+	}
+
+	itemIDs := make([]int64, len(orderItemsToCreate))
+	for i, item := range orderItemsToCreate {
+		itemIDs[i] = item.PricelistItemID
+	}
+	taxClasses, err := s.pricelistRepo.GetItemTaxClasses(itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tax classes of items: %w", err)
+	}
+	// The service charge is on the items after discount, before any tax added
+	serviceChargeRate, serviceCharge := orderServiceCharge(req, finalAmount)
+	finalAmount = finalAmount.Add(serviceCharge)
+	taxes := CurrentTaxSettings()
+	taxAmount := applyTax(taxes, orderItemsToCreate, taxClasses)
+	if !taxes.PricesIncludeTax() {
+		finalAmount = finalAmount.Add(taxAmount)
 	}
 
 	if !isValidOrderStatus(req.Status) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidOrderStatus, req.Status)
 	}
 
+	orderTime := time.Now().UTC()
+	if req.OrderTime != nil {
+		orderTime = req.OrderTime.UTC()
+	}
 	order := models.Order{
-		ClientID:       req.ClientID,
-		BookingID:      req.BookingID,
-		StaffID:        &req.StaffID,
-		TableID:        req.TableID,
-		Status:         req.Status,
-		TotalAmount:    totalAmount,
-		DiscountAmount: req.DiscountAmount, // This is already a *float64
-		FinalAmount:    finalAmount,
-		PaymentMethod:  req.PaymentMethod,
-		Notes:          req.Notes,
-		OrderTime:      time.Now(),
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ClientID:         req.ClientID,
+		BookingID:        req.BookingID,
+		StaffID:          orderStaffID(req),
+		TableID:          req.TableID,
+		Status:           req.Status,
+		TotalAmount:      totalAmount,
+		DiscountAmount:   req.DiscountAmount,
+		FinalAmount:      finalAmount,
+		TaxAmount:        taxAmount,
+		PricesIncludeTax: taxes.PricesIncludeTax(),
+		PaymentMethod:    req.PaymentMethod,
+		Notes:            req.Notes,
+		GuestCount:       req.GuestCount,
+		UUID:             req.UUID,
+		Source:           req.Source,
+		OrderTime:        orderTime,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+
+		ServiceChargeRate:   serviceChargeRate,
+		ServiceChargeAmount: serviceCharge,
+	}
+
+	order.BranchCode = utils.BranchCode()
+	order.BusinessDate = utils.FormatClubTime(order.OrderTime, utils.DateLayout)
+	order.DailyNumber, err = s.orderRepo.NextDailyOrderNumber(tx, order.BranchCode, order.BusinessDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate order number: %w", err)
 	}
 
 	createdOrderID, repoErr := s.orderRepo.CreateOrder(tx, &order)
 	if repoErr != nil {
+		// Retried at the same time: the other request created it
+		if errors.Is(repoErr, repositories.ErrDuplicateKey) && req.UUID != nil {
+			tx.Rollback()
+			if existing, err := s.orderWithUUID(req.UUID); existing != nil || err != nil {
+				return existing, err
+			}
+		}
 		return nil, fmt.Errorf("failed to create order record: %w", repoErr)
 	}
 	order.ID = createdOrderID
 
+	for _, sale := range sales {
+		if err := s.batchRepo.DeductFEFO(tx, sale.PricelistItemID, sale.QuantityChanged.Neg(), sale.ID, &order.ID); err != nil {
+			return nil, fmt.Errorf("failed to take item ID %d from its stock batches: %w", sale.PricelistItemID, err)
+		}
+	}
+
 	for _, itemModel := range orderItemsToCreate {
 		itemModel.OrderID = createdOrderID // Link item to the created order
 		_, repoErr = s.orderRepo.CreateOrderItem(tx, &itemModel)
+		if errors.Is(repoErr, repositories.ErrDuplicateKey) {
+			return nil, fmt.Errorf("%w: order item UUID %s", ErrOrderItemUUIDTaken, *itemModel.UUID)
+		}
 		if repoErr != nil {
 			return nil, fmt.Errorf("failed to create order item (pricelist_item_id: %d): %w", itemModel.PricelistItemID, repoErr)
 		}
 	}
 
+	if promo != nil {
+		redemption := &models.PromoCodeRedemption{
+			OrderID:        order.ID,
+			BranchCode:     order.BranchCode,
+			PromoCodeID:    promo.ID,
+			DiscountAmount: *order.DiscountAmount,
+			RedeemedAt:     order.CreatedAt,
+		}
+		if err := s.promoRepo.CreateRedemption(tx, redemption); err != nil {
+			return nil, err
+		}
+	}
+
+	if order.PaymentMethod != nil && *order.PaymentMethod == models.PaymentMethodHouseAccount {
+		if err := chargeClientAccount(tx, s.accountRepo, &order); err != nil {
+			return nil, err
+		}
+	}
+
+	payload := events.NewOrderPayload(&order, "")
+	if err := s.publisher.Publish(tx, events.OrderCreated, events.AggregateOrder, order.ID, payload); err != nil {
+		return nil, err
+	}
+	if order.DiscountAmount != nil && order.DiscountAmount.IsPositive() {
+		if err := s.publisher.Publish(tx, events.OrderDiscounted, events.AggregateOrder, order.ID, payload); err != nil {
+			return nil, err
+		}
+	}
+	// Orders rung up as e.g. "paid" at the counter never change status, so announce their status too
+	if order.Status != StatusPending {
+		if err := s.publisher.Publish(tx, events.OrderStatusEvent(order.Status), events.AggregateOrder, order.ID, payload); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit order transaction: %w", err)
 	}
@@ -234,16 +493,99 @@ func (s *orderService) CreateOrder(req CreateOrderRequest) (*models.Order, error
 	return s.GetOrderByID(createdOrderID)
 }
 
+// orderWithUUID returns the order created before with uuid, nil if uuid is nil or no order has it.
+func (s *orderService) orderWithUUID(uuid *string) (*models.Order, error) {
+	if uuid == nil {
+		return nil, nil
+	}
+	existing, err := s.orderRepo.GetOrderByUUID(*uuid)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up order by UUID: %w", err)
+	}
+	return s.GetOrderByID(existing.ID)
+}
+
 func (s *orderService) GetOrders(filters models.OrderFilters) ([]models.Order, int, error) {
 	orders, totalCount, err := s.orderRepo.GetOrders(filters)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get orders: %w", err)
 	}
 	// The repository GetOrders now includes joins for client, staff, table names.
-	// No need to fetch order items for the list view usually.
+	// Order items are only loaded when asked for.
+	if filters.IncludeItems {
+		if err := s.attachOrderItems(orders); err != nil {
+			return nil, 0, err
+		}
+	}
 	return orders, totalCount, nil
 }
 
+// attachOrderItems loads the items of orders in one query and sets each order's OrderItems.
+func (s *orderService) attachOrderItems(orders []models.Order) error {
+	orderIDs := make([]int64, len(orders))
+	for i, order := range orders {
+		orderIDs[i] = order.ID
+	}
+	items, err := s.orderRepo.GetOrderItemsByOrderIDs(orderIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get order items: %w", err)
+	}
+	itemsByOrder := make(map[int64][]models.OrderItem, len(orders))
+	for _, item := range items {
+		itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], item)
+	}
+	for i := range orders {
+		orders[i].OrderItems = itemsByOrder[orders[i].ID]
+	}
+	return nil
+}
+
+func (s *orderService) ExportOrders(filters models.OrderFilters, fn func(*models.Order) error) error {
+	if err := s.orderRepo.StreamOrders(filters, fn); err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+	return nil
+}
+
+func (s *orderService) GetOrdersByCursor(filters models.OrderFilters, cursor string) (*models.CursorPage[models.Order], error) {
+	after, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := filters.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultCursorPageSize
+	}
+	filters.Cursor = after
+	filters.PageSize = pageSize + 1 // One extra row tells whether there is a next page
+
+	orders, _, err := s.orderRepo.GetOrders(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	page := cursorPage(orders, pageSize, func(o models.Order) models.Cursor {
+		return models.Cursor{Time: o.OrderTime, ID: o.ID}
+	})
+	if filters.IncludeItems {
+		if err := s.attachOrderItems(page.Data); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// GetOrderItems returns the items of an order, e.g. for orders listed without their items.
+func (s *orderService) GetOrderItems(orderID int64) ([]models.OrderItem, error) {
+	items, err := s.orderRepo.GetOrderItemsByOrderID(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	return items, nil
+}
+
 func (s *orderService) GetOrderByID(orderID int64) (*models.Order, error) {
 	order, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil {
@@ -256,7 +598,7 @@ func (s *orderService) GetOrderByID(orderID int64) (*models.Order, error) {
 	items, err := s.orderRepo.GetOrderItemsByOrderID(orderID)
 	if err != nil {
 		// Log this error but don't necessarily fail the whole request if order header is found
-		fmt.Printf("Warning: failed to get order items for order ID %d: %v\n", orderID, err)
+		apperrors.Warn(err, fmt.Sprintf("failed to get order items for order ID %d", orderID))
 		// Depending on requirements, might return order without items or a specific error
 	}
 	order.OrderItems = items
@@ -276,10 +618,51 @@ func (s *orderService) GetOrderByID(orderID int64) (*models.Order, error) {
 	return order, nil
 }
 
+// GetOrderByNumber looks up an order of this branch by its display number. The
+// date part may be omitted ("37" or "#37") to search today's orders.
+func (s *orderService) GetOrderByNumber(number string) (*models.Order, error) {
+	businessDate, dailyNumber, err := parseOrderNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.GetOrderByNumber(utils.BranchCode(), businessDate, dailyNumber)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order by number from repository: %w", err)
+	}
+	return s.GetOrderByID(order.ID)
+}
+
+// parseOrderNumber parses "YYYY-MM-DD/#N", "YYYY-MM-DD/N", "#N" or "N" into the
+// business date (today in the club timezone if omitted) and the daily number.
+func parseOrderNumber(number string) (string, int, error) {
+	number = strings.TrimSpace(number)
+	businessDate := utils.FormatClubTime(time.Now(), utils.DateLayout)
+	if i := strings.LastIndex(number, "/"); i >= 0 {
+		date, err := utils.ParseClubDate(number[:i])
+		if err != nil {
+			return "", 0, ErrInvalidOrderNumber
+		}
+		businessDate = utils.FormatClubTime(date, utils.DateLayout)
+		number = number[i+1:]
+	}
+	dailyNumber, err := strconv.Atoi(strings.TrimPrefix(number, "#"))
+	if err != nil || dailyNumber < 1 {
+		return "", 0, ErrInvalidOrderNumber
+	}
+	return businessDate, dailyNumber, nil
+}
+
 func (s *orderService) UpdateOrderStatus(orderID int64, req UpdateOrderStatusRequest) (*models.Order, error) {
 	if !isValidOrderStatus(req.Status) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidOrderStatus, req.Status)
 	}
+	void, err := cancellationVoid(req.Status, req.VoidReason, req.VoidNote, req.CallerRole, req.CallerID)
+	if err != nil {
+		return nil, err
+	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -294,54 +677,350 @@ func (s *orderService) UpdateOrderStatus(orderID int64, req UpdateOrderStatusReq
 		}
 		return nil, fmt.Errorf("failed to fetch order for status update: %w", err)
 	}
+	if req.Version != nil && *req.Version != currentOrder.Version {
+		return nil, ErrVersionConflict
+	}
+	if err := s.checkDayOpen(req.CallerRole, currentOrder); err != nil {
+		return nil, err
+	}
 
-	if req.Status == StatusCancelled && currentOrder.Status != StatusCancelled && currentOrder.Status != StatusRefunded {
-		orderItems, repoErr := s.orderRepo.GetOrderItemsByOrderID(orderID)
+	if err := s.applyOrderStatus(tx, currentOrder, req.Status, void); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction for order status update: %w", err)
+	}
+	return s.GetOrderByID(orderID)
+}
+
+// orderVoid is who cancelled an order or voided an item, and why.
+type orderVoid struct {
+	reason   string
+	note     *string
+	voidedBy *int64
+}
+
+// cancellationVoid validates the void reason of a change to status, required to cancel an
+// order, and returns the void to record; nil for other statuses.
+func cancellationVoid(status string, reason, note *string, callerRole string, callerID int64) (*orderVoid, error) {
+	if status != StatusCancelled {
+		return nil, nil
+	}
+	if reason == nil {
+		return nil, fmt.Errorf("%w: void_reason is required to cancel an order, one of %v", ErrValidation, models.VoidReasons)
+	}
+	return newOrderVoid(*reason, note, callerRole, callerID)
+}
+
+// newOrderVoid validates a void reason given by staff, or VoidReasonDayClose for an internal
+// call (empty callerRole), and its note.
+func newOrderVoid(reason string, note *string, callerRole string, callerID int64) (*orderVoid, error) {
+	if !models.IsValidVoidReason(reason) && !(reason == models.VoidReasonDayClose && callerRole == "") {
+		return nil, fmt.Errorf("%w: void_reason must be one of %v", ErrValidation, models.VoidReasons)
+	}
+	if note != nil && strings.TrimSpace(*note) == "" {
+		note = nil
+	}
+	if reason == models.VoidReasonOther && note == nil {
+		return nil, fmt.Errorf("%w: void_note is required with void_reason \"other\"", ErrValidation)
+	}
+	void := &orderVoid{reason: reason, note: note}
+	if callerID != 0 {
+		void.voidedBy = &callerID
+	}
+	return void, nil
+}
+
+// recordVoid records the void of order, or of one of its items, taking amount off it.
+func (s *orderService) recordVoid(tx *sql.Tx, order *models.Order, item *models.OrderItem, amount models.Money, void *orderVoid) error {
+	record := &models.OrderVoid{
+		BranchCode:   order.BranchCode,
+		OrderID:      order.ID,
+		OrderNumber:  order.OrderNumber,
+		Amount:       amount,
+		Reason:       void.reason,
+		Note:         void.note,
+		OrderStaffID: order.StaffID,
+		VoidedBy:     void.voidedBy,
+		VoidedAt:     utils.NowUTC(),
+	}
+	if item != nil {
+		record.OrderItemID = &item.ID
+		record.PricelistItemID = &item.PricelistItemID
+		record.Quantity = &item.Quantity
+	}
+	return s.orderRepo.CreateOrderVoid(tx, record)
+}
+
+// returnItemStock returns the stock an item of order took, if its pricelist item tracks
+// stock, recording the return as reason. restoreBatches also puts the deductions of the
+// pricelist item in order back in its batches.
+func (s *orderService) returnItemStock(tx *sql.Tx, order *models.Order, item models.OrderItem, reason string, restoreBatches bool) error {
+	// Need to check PricelistItem's TracksStock status
+	_, _, _, tracksStock, itemDetailErr := s.pricelistRepo.GetItemPriceAndStock(item.PricelistItemID)
+	if itemDetailErr != nil {
+		return fmt.Errorf("failed to get item details for stock return (item ID %d): %w", item.PricelistItemID, itemDetailErr)
+	}
+	if !tracksStock {
+		return nil
+	}
+	_, repoErr := s.pricelistRepo.UpdateStock(tx, item.PricelistItemID, item.StockQuantity) // Return the stock it took
+	if repoErr != nil {
+		return fmt.Errorf("failed to return stock for item ID %d: %w", item.PricelistItemID, repoErr)
+	}
+	if restoreBatches {
+		if repoErr = s.batchRepo.RestoreOrderDeductions(tx, order.ID, item.PricelistItemID); repoErr != nil {
+			return fmt.Errorf("failed to return stock batches for item ID %d: %w", item.PricelistItemID, repoErr)
+		}
+	}
+	movement := models.InventoryMovement{
+		PricelistItemID: item.PricelistItemID,
+		StaffID:         order.StaffID, // Use staff ID from the order
+		MovementType:    models.MovementTypeReturnCancellation,
+		QuantityChanged: item.StockQuantity, // Positive quantity for return
+		Reason:          utils.NewNullString(reason),
+		MovementDate:    time.Now().UTC(),
+	}
+	_, repoErr = s.inventoryMvRepo.CreateMovement(tx, &movement)
+	if repoErr != nil {
+		return fmt.Errorf("failed to record inventory movement for stock return (item ID %d): %w", item.PricelistItemID, repoErr)
+	}
+	return nil
+}
+
+// applyOrderStatus changes the status of order within tx: a cancellation returns the
+// stock of its items and records void, and a change of status publishes the status event.
+func (s *orderService) applyOrderStatus(tx *sql.Tx, order *models.Order, status string, void *orderVoid) error {
+	if status == StatusCancelled && order.Status != StatusCancelled && order.Status != StatusRefunded {
+		orderItems, repoErr := s.orderRepo.GetOrderItemsByOrderID(order.ID)
 		if repoErr != nil {
-			return nil, fmt.Errorf("failed to fetch order items for stock return: %w", repoErr)
+			return fmt.Errorf("failed to fetch order items for stock return: %w", repoErr)
 		}
 		for _, item := range orderItems {
-			// Need to check PricelistItem's TracksStock status
-			_, _, _, tracksStock, itemDetailErr := s.pricelistRepo.GetItemPriceAndStock(item.PricelistItemID)
-			if itemDetailErr != nil {
-				return nil, fmt.Errorf("failed to get item details for stock return (item ID %d): %w", item.PricelistItemID, itemDetailErr)
+			if err := s.returnItemStock(tx, order, item, fmt.Sprintf("Order %d cancelled", order.ID), true); err != nil {
+				return err
+			}
+		}
+		if void != nil {
+			if err := s.recordVoid(tx, order, nil, order.FinalAmount, void); err != nil {
+				return err
 			}
+		}
+	}
+	if (status == StatusCancelled || status == StatusRefunded) && order.Status != StatusCancelled && order.Status != StatusRefunded {
+		if err := reverseClientAccountCharge(tx, s.accountRepo, order.ID, fmt.Sprintf("Order %d %s", order.ID, status)); err != nil {
+			return err
+		}
+	}
 
-			if tracksStock {
-				_, repoErr = s.pricelistRepo.UpdateStock(tx, item.PricelistItemID, item.Quantity) // Return positive quantity
-				if repoErr != nil {
-					return nil, fmt.Errorf("failed to return stock for item ID %d: %w", item.PricelistItemID, repoErr)
-				}
-				movement := models.InventoryMovement{
-					PricelistItemID: item.PricelistItemID,
-					StaffID:         currentOrder.StaffID, // Use staff ID from the order
-					MovementType:    MovementTypeReturnCancellation,
-					QuantityChanged: item.Quantity, // Positive quantity for return
-					Reason:          utils.NewNullString(fmt.Sprintf("Order %d cancelled", orderID)), // Changed to utils
-					MovementDate:    time.Now(),
-				}
-				_, repoErr = s.inventoryMvRepo.CreateMovement(tx, &movement)
-				if repoErr != nil {
-					return nil, fmt.Errorf("failed to record inventory movement for stock return (item ID %d): %w", item.PricelistItemID, repoErr)
-				}
+	// The version precondition also serializes concurrent cancellations, so stock is returned only once.
+	err := s.orderRepo.UpdateOrderStatus(tx, order.ID, status, order.Version, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("failed to update order status in repository: %w", err)
+	}
+
+	if status != order.Status {
+		previousStatus := order.Status
+		order.Status = status
+		payload := events.NewOrderPayload(order, previousStatus)
+		if err := s.publisher.Publish(tx, events.OrderStatusEvent(status), events.AggregateOrder, order.ID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *orderService) BulkUpdateOrderStatus(req BulkUpdateOrderStatusRequest) (*BulkResult, error) {
+	if !isValidOrderStatus(req.Status) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOrderStatus, req.Status)
+	}
+	if req.Status == StatusRefunded {
+		return nil, fmt.Errorf("%w: refunds need a manager approval per order and cannot be made in bulk", ErrValidation)
+	}
+	if (len(req.OrderIDs) == 0) == (req.FromStatus == "") {
+		return nil, fmt.Errorf("%w: select the orders with either order_ids or from_status", ErrValidation)
+	}
+	void, err := cancellationVoid(req.Status, req.VoidReason, req.VoidNote, req.CallerRole, req.CallerID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIDs := req.OrderIDs
+	if req.FromStatus != "" {
+		var err error
+		if orderIDs, err = s.orderIDsWithStatus(req.FromStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	return runBulk(s.db, orderIDs, func(tx *sql.Tx, orderID int64) error {
+		order, err := s.orderRepo.GetOrderByID(orderID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrOrderNotFound
 			}
+			return fmt.Errorf("failed to fetch order for status update: %w", err)
 		}
+		// The order may have moved on since it was selected by its status
+		if req.FromStatus != "" && order.Status != req.FromStatus {
+			return fmt.Errorf("%w: order is now '%s'", ErrInvalidOrderStatus, order.Status)
+		}
+		if err := s.checkDayOpen(req.CallerRole, order); err != nil {
+			return err
+		}
+		return s.applyOrderStatus(tx, order, req.Status, void)
+	})
+}
+
+func (s *orderService) VoidOrderItem(orderID, itemID int64, req VoidOrderItemRequest) (*models.Order, error) {
+	void, err := newOrderVoid(req.VoidReason, req.VoidNote, req.CallerRole, req.CallerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	err = s.orderRepo.UpdateOrderStatus(tx, orderID, req.Status, time.Now())
+	order, err := s.orderRepo.GetOrderByID(orderID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return nil, ErrOrderNotFound
 		}
-		return nil, fmt.Errorf("failed to update order status in repository: %w", err)
+		return nil, fmt.Errorf("failed to fetch order for item void: %w", err)
+	}
+	if req.Version != nil && *req.Version != order.Version {
+		return nil, ErrVersionConflict
+	}
+	if err := s.checkDayOpen(req.CallerRole, order); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(OpenOrderStatuses, order.Status) {
+		return nil, fmt.Errorf("%w: only items of open orders can be voided; the order is '%s'", ErrValidation, order.Status)
+	}
+	if order.PaymentMethod != nil && *order.PaymentMethod == models.PaymentMethodHouseAccount {
+		return nil, fmt.Errorf("%w: the order is charged to a house account; cancel it instead", ErrValidation)
+	}
+	items, err := s.orderRepo.GetOrderItemsByOrderID(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order items for item void: %w", err)
+	}
+	index := slices.IndexFunc(items, func(item models.OrderItem) bool { return item.ID == itemID })
+	if index < 0 {
+		return nil, ErrOrderItemNotFound
+	}
+	if len(items) == 1 {
+		return nil, fmt.Errorf("%w: the last item of an order cannot be voided; cancel the order instead", ErrValidation)
+	}
+	item := items[index]
+
+	// The batch deductions are kept per order and pricelist item, so those of an item on
+	// several lines stay with the order
+	sharedItem := slices.ContainsFunc(items, func(other models.OrderItem) bool {
+		return other.ID != item.ID && other.PricelistItemID == item.PricelistItemID
+	})
+	if err := s.returnItemStock(tx, order, item, fmt.Sprintf("Item voided from order %d", order.ID), !sharedItem); err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.DeleteOrderItem(tx, orderID, itemID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrOrderItemNotFound
+		}
+		return nil, fmt.Errorf("failed to delete order item: %w", err)
+	}
+
+	previousFinal := order.FinalAmount
+	takeOffItem(order, item)
+	if err := s.orderRepo.UpdateOrderAmounts(tx, order, order.Version, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to update order amounts: %w", err)
+	}
+	if err := s.recordVoid(tx, order, &item, previousFinal.Sub(order.FinalAmount), void); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction for order status update: %w", err)
+		return nil, fmt.Errorf("failed to commit item void: %w", err)
 	}
 	return s.GetOrderByID(orderID)
 }
 
+// takeOffItem takes a voided item off the amounts of order: its price, its share of the
+// discount and its tax. The service charge is charged again on the rest.
+func takeOffItem(order *models.Order, item models.OrderItem) {
+	net := order.FinalAmount.Sub(order.ServiceChargeAmount) // Items after discount
+	if !order.PricesIncludeTax {
+		net = net.Sub(order.TaxAmount)
+	}
+	net = net.Sub(item.TotalPrice.Sub(item.DiscountAmount))
+	if net.IsNegative() {
+		net = models.ZeroMoney
+	}
+
+	order.TotalAmount = order.TotalAmount.Sub(item.TotalPrice)
+	if order.DiscountAmount != nil {
+		discount := order.DiscountAmount.Sub(item.DiscountAmount)
+		order.DiscountAmount = &discount
+	}
+	order.TaxAmount = order.TaxAmount.Sub(item.TaxAmount)
+	if order.ServiceChargeRate != nil {
+		order.ServiceChargeAmount = models.ChargeOn(net, *order.ServiceChargeRate)
+	}
+	order.FinalAmount = net.Add(order.ServiceChargeAmount)
+	if !order.PricesIncludeTax {
+		order.FinalAmount = order.FinalAmount.Add(order.TaxAmount)
+	}
+}
+
+// checkDayOpen returns ErrDayClosed if the business day of order was closed and callerRole
+// is neither empty (an internal call) nor Admin.
+func (s *orderService) checkDayOpen(callerRole string, order *models.Order) error {
+	if callerRole == "" || strings.EqualFold(callerRole, approverRole) {
+		return nil
+	}
+	closed, err := s.dayCloseRepo.IsClosedThrough(order.BranchCode, order.BusinessDate)
+	if err != nil {
+		return fmt.Errorf("failed to check whether the business day is closed: %w", err)
+	}
+	if closed {
+		return ErrDayClosed
+	}
+	return nil
+}
+
+// orderIDsWithStatus returns the IDs of all orders with the given status, or
+// ErrBulkTooLarge if there are more than MaxBulkSize.
+func (s *orderService) orderIDsWithStatus(status string) ([]int64, error) {
+	var ids []int64
+	for page := 1; ; page++ {
+		orders, total, err := s.orderRepo.GetOrders(models.OrderFilters{Status: &status, Page: page, PageSize: bulkPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get orders with status %s: %w", status, err)
+		}
+		if total > MaxBulkSize {
+			return nil, ErrBulkTooLarge
+		}
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+		if len(orders) == 0 || len(ids) >= total {
+			break
+		}
+	}
+	return ids, nil
+}
+
 func (s *orderService) DeleteOrder(orderID int64) error {
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -368,17 +1047,20 @@ func (s *orderService) DeleteOrder(orderID int64) error {
 				return fmt.Errorf("failed to get item details for stock return (item ID %d): %w", item.PricelistItemID, itemDetailErr)
 			}
 			if tracksStock {
-				_, repoErr = s.pricelistRepo.UpdateStock(tx, item.PricelistItemID, item.Quantity) // Return positive quantity
+				_, repoErr = s.pricelistRepo.UpdateStock(tx, item.PricelistItemID, item.StockQuantity) // Return the stock it took
 				if repoErr != nil {
 					return fmt.Errorf("failed to return stock for item ID %d on delete: %w", item.PricelistItemID, repoErr)
 				}
+				if repoErr = s.batchRepo.RestoreOrderDeductions(tx, orderID, item.PricelistItemID); repoErr != nil {
+					return fmt.Errorf("failed to return stock batches for item ID %d on delete: %w", item.PricelistItemID, repoErr)
+				}
 				movement := models.InventoryMovement{
 					PricelistItemID: item.PricelistItemID,
 					StaffID:         order.StaffID, // Use staff ID from the order
-					MovementType:    MovementTypeReturnDeletion,
-					QuantityChanged: item.Quantity, // Positive quantity for return
+					MovementType:    models.MovementTypeReturnDeletion,
+					QuantityChanged: item.StockQuantity, // Positive quantity for return
 					Reason:          utils.NewNullString(fmt.Sprintf("Order %d deleted", orderID)), // Changed to utils
-					MovementDate:    time.Now(),
+					MovementDate:    time.Now().UTC(),
 				}
 				_, repoErr = s.inventoryMvRepo.CreateMovement(tx, &movement)
 				if repoErr != nil {
@@ -388,6 +1070,12 @@ func (s *orderService) DeleteOrder(orderID int64) error {
 		}
 	}
 
+	if order.Status != StatusCancelled && order.Status != StatusRefunded {
+		if err := reverseClientAccountCharge(tx, s.accountRepo, orderID, fmt.Sprintf("Order %d deleted", orderID)); err != nil {
+			return err
+		}
+	}
+
 	_, err = s.orderRepo.DeleteOrderItemsByOrderID(tx, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to delete order items: %w", err)
@@ -404,12 +1092,98 @@ func (s *orderService) DeleteOrder(orderID int64) error {
 	return tx.Commit()
 }
 
-// Helper function to validate order status (can be expanded)
+// receiptWidth is the line width of plain-text receipts (fits 58mm thermal printers).
+const receiptWidth = 32
+
+// RenderReceipt renders a plain-text receipt for an order. Amounts are formatted
+// with the configured currency and the order time is shown in the club timezone.
+func (s *orderService) RenderReceipt(orderID int64) (string, error) {
+	order, err := s.GetOrderByID(orderID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	separator := strings.Repeat("-", receiptWidth) + "\n"
+	b.WriteString(fmt.Sprintf("Order %s\n", order.OrderNumber))
+	b.WriteString(utils.FormatClubTime(order.OrderTime, "2006-01-02 15:04") + "\n")
+	b.WriteString(separator)
+	for _, item := range order.OrderItems {
+		name := fmt.Sprintf("Item #%d", item.PricelistItemID)
+		if item.PricelistItem != nil && item.PricelistItem.Name != "" {
+			name = item.PricelistItem.Name
+		}
+		b.WriteString(name + "\n")
+		b.WriteString(receiptLine(fmt.Sprintf("  %d x %s", item.Quantity, item.UnitPrice), item.TotalPrice.String()))
+	}
+	b.WriteString(separator)
+	b.WriteString(receiptLine("Subtotal", order.TotalAmount.String()))
+	if order.DiscountAmount != nil && !order.DiscountAmount.IsZero() {
+		b.WriteString(receiptLine("Discount", order.DiscountAmount.Neg().String()))
+	}
+	if order.ServiceChargeAmount.IsPositive() {
+		b.WriteString(receiptLine(serviceChargeLabel(order), order.ServiceChargeAmount.String()))
+	}
+	// Tax added to the prices comes before the total; tax included in them is shown after it
+	taxLines := receiptTaxLines(order.OrderItems)
+	if !order.PricesIncludeTax {
+		for _, line := range taxLines {
+			b.WriteString(receiptLine(line.label, line.amount.String()))
+		}
+	}
+	b.WriteString(receiptLine("Total", order.FinalAmount.String()))
+	if order.PricesIncludeTax {
+		for _, line := range taxLines {
+			b.WriteString(receiptLine("incl. "+line.label, line.amount.String()))
+		}
+	}
+	if order.PaymentMethod != nil && *order.PaymentMethod != "" {
+		b.WriteString(receiptLine("Payment", *order.PaymentMethod))
+	}
+	return b.String(), nil
+}
+
+// receiptTaxLine is the tax of the items of a receipt at one rate.
+type receiptTaxLine struct {
+	label  string // e.g. "VAT 12%"
+	rate   decimal.Decimal
+	amount models.Money
+}
+
+// receiptTaxLines totals the tax of the taxed items per rate, highest rate first.
+func receiptTaxLines(items []models.OrderItem) []receiptTaxLine {
+	name := CurrentTaxSettings().DisplayName()
+	var lines []receiptTaxLine
+	for _, item := range items {
+		if item.TaxRate == nil {
+			continue
+		}
+		i := slices.IndexFunc(lines, func(l receiptTaxLine) bool { return l.rate.Equal(*item.TaxRate) })
+		if i < 0 {
+			lines = append(lines, receiptTaxLine{label: name + " " + item.TaxRate.String() + "%", rate: *item.TaxRate})
+			i = len(lines) - 1
+		}
+		lines[i].amount = lines[i].amount.Add(item.TaxAmount)
+	}
+	slices.SortStableFunc(lines, func(a, b receiptTaxLine) int { return b.rate.Cmp(a.rate) })
+	return lines
+}
+
+// receiptLine left-aligns label and right-aligns value within receiptWidth.
+func receiptLine(label, value string) string {
+	padding := receiptWidth - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+	if padding < 1 {
+		padding = 1
+	}
+	return label + strings.Repeat(" ", padding) + value + "\n"
+}
+
+// Helper function to validate order status against OrderStatuses
 func isValidOrderStatus(status string) bool {
-	switch status {
-	case StatusPending, StatusCompleted, StatusCancelled, StatusPreparing, StatusReady, StatusServed, StatusPaid, StatusRefunded:
-		return true
-	default:
-		return false
+	for _, valid := range OrderStatuses {
+		if status == valid {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/repositories/mocks"
+
+	"github.com/shopspring/decimal"
+)
+
+// testPricelistItem is an item of the pricelist the order service sells from in the tests.
+type testPricelistItem struct {
+	name   string
+	price  int64
+	tracks bool
+	stock  *models.Quantity
+}
+
+// orderTestRecorder records what CreateOrder wrote through the mocked repositories.
+type orderTestRecorder struct {
+	order        *models.Order
+	items        []models.OrderItem
+	stockChanges map[int64]models.Quantity
+	movements    []models.InventoryMovement
+	fefo         map[int64]models.Quantity
+	events       []string
+}
+
+func newTestOrderService(t *testing.T, pricelist map[int64]testPricelistItem) (OrderService, *orderTestRecorder) {
+	t.Helper()
+	rec := &orderTestRecorder{stockChanges: map[int64]models.Quantity{}, fefo: map[int64]models.Quantity{}}
+
+	orderRepo := &mocks.MockOrderRepository{
+		NextDailyOrderNumberFunc: func(repositories.SQLExecutor, string, string) (int, error) { return 1, nil },
+		CreateOrderFunc: func(_ repositories.SQLExecutor, order *models.Order) (int64, error) {
+			created := *order
+			created.ID = 42
+			rec.order = &created
+			return created.ID, nil
+		},
+		CreateOrderItemFunc: func(_ repositories.SQLExecutor, item *models.OrderItem) (int64, error) {
+			rec.items = append(rec.items, *item)
+			return int64(len(rec.items)), nil
+		},
+		GetOrderByIDFunc: func(id int64) (*models.Order, error) {
+			if rec.order == nil || rec.order.ID != id {
+				return nil, repositories.ErrNotFound
+			}
+			order := *rec.order
+			return &order, nil
+		},
+		GetOrderItemsByOrderIDFunc: func(int64) ([]models.OrderItem, error) { return rec.items, nil },
+	}
+	pricelistRepo := &mocks.MockPricelistRepository{
+		GetItemPriceAndStockFunc: func(id int64) (models.Money, *models.Quantity, string, bool, error) {
+			item, ok := pricelist[id]
+			if !ok {
+				return models.ZeroMoney, nil, "", false, repositories.ErrNotFound
+			}
+			return models.NewMoneyFromInt(item.price), item.stock, item.name, item.tracks, nil
+		},
+		GetItemUnitsFunc: func(int64) (models.ItemUnits, error) { return models.DefaultItemUnits(), nil },
+		UpdateStockFunc: func(_ repositories.SQLExecutor, id int64, change models.Quantity) (models.Quantity, error) {
+			rec.stockChanges[id] = rec.stockChanges[id].Add(change)
+			return *pricelist[id].stock, nil
+		},
+		GetItemTaxClassesFunc: func([]int64) (map[int64]string, error) { return map[int64]string{}, nil },
+	}
+	movementRepo := &mocks.MockInventoryMovementRepository{
+		CreateMovementFunc: func(_ repositories.SQLExecutor, movement *models.InventoryMovement) (int64, error) {
+			rec.movements = append(rec.movements, *movement)
+			return int64(len(rec.movements)), nil
+		},
+	}
+	batchRepo := &mocks.MockStockBatchRepository{
+		DeductFEFOFunc: func(_ repositories.SQLExecutor, itemID int64, quantity models.Quantity, _ int64, _ *int64) error {
+			rec.fefo[itemID] = rec.fefo[itemID].Add(quantity)
+			return nil
+		},
+	}
+	outboxRepo := &mocks.MockOutboxRepository{
+		CreateEventFunc: func(_ repositories.SQLExecutor, event *models.DomainEvent) (int64, error) {
+			rec.events = append(rec.events, event.EventType)
+			return int64(len(rec.events)), nil
+		},
+	}
+	promoRepo := &mocks.MockPromoCodeRepository{}
+
+	service := NewOrderService(orderRepo, pricelistRepo, movementRepo, batchRepo, &mocks.MockClientAccountRepository{},
+		events.NewPublisher(outboxRepo), &mocks.MockDayCloseRepository{}, promoRepo, newTxDB(t))
+	return service, rec
+}
+
+func quantityPtr(value int) *models.Quantity {
+	q := models.NewQuantityFromInt(value)
+	return &q
+}
+
+func moneyPtr(value int64) *models.Money {
+	m := models.NewMoneyFromInt(value)
+	return &m
+}
+
+func TestOrderServiceCreateOrder(t *testing.T) {
+	SetDiscountLimits(models.DiscountLimits{"Staff": {MaxPercent: decimalPtr(10)}})
+	t.Cleanup(func() { SetDiscountLimits(models.DiscountLimits{}) })
+
+	pricelist := map[int64]testPricelistItem{
+		1: {name: "Cola", price: 500, tracks: true, stock: quantityPtr(10)},
+		2: {name: "Hookah", price: 3000},
+		3: {name: "Chips", price: 300, tracks: true, stock: quantityPtr(1)},
+	}
+	itemUUID := "5f0c6f7e-8d1b-4c8e-9b5a-2f6f4c1d9e10"
+
+	tests := []struct {
+		name       string
+		req        CreateOrderRequest
+		wantErr    error
+		wantFinal  int64
+		wantStock  map[int64]int // Stock taken per item; items not listed must be left alone
+		wantEvents []string
+	}{
+		{
+			name:       "deducts the stock of tracked items",
+			req:        orderRequest(CreateOrderItemRequest{PricelistItemID: 1, Quantity: 3}, CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}),
+			wantFinal:  4500,
+			wantStock:  map[int64]int{1: 3},
+			wantEvents: []string{events.OrderCreated},
+		},
+		{
+			name:    "rejects an order for more than the stock",
+			req:     orderRequest(CreateOrderItemRequest{PricelistItemID: 3, Quantity: 2}),
+			wantErr: ErrInsufficientStock,
+		},
+		{
+			name: "sells an offline order short of stock",
+			req: func() CreateOrderRequest {
+				req := orderRequest(CreateOrderItemRequest{PricelistItemID: 3, Quantity: 2})
+				req.AllowStockShortfall = true
+				return req
+			}(),
+			wantFinal:  600,
+			wantStock:  map[int64]int{3: 2},
+			wantEvents: []string{events.OrderCreated},
+		},
+		{
+			name:    "rejects an unknown item",
+			req:     orderRequest(CreateOrderItemRequest{PricelistItemID: 99, Quantity: 1}),
+			wantErr: ErrPricelistItemNotFound,
+		},
+		{
+			name:    "rejects a quantity that is not positive",
+			req:     orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 0}),
+			wantErr: ErrValidation,
+		},
+		{
+			name: "rejects an item UUID given twice",
+			req: orderRequest(
+				CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1, UUID: &itemUUID},
+				CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1, UUID: &itemUUID},
+			),
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects an unknown status",
+			req:     withStatus(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), "lost"),
+			wantErr: ErrInvalidOrderStatus,
+		},
+		{
+			name:       "takes a discount within the limit of the role",
+			req:        withDiscount(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), 300, false),
+			wantFinal:  2700,
+			wantEvents: []string{events.OrderCreated, events.OrderDiscounted},
+		},
+		{
+			name:    "rejects a discount over the limit of the role",
+			req:     withDiscount(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), 301, false),
+			wantErr: ErrDiscountLimitExceeded,
+		},
+		{
+			name:       "takes a discount over the limit a manager approved",
+			req:        withDiscount(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), 1500, true),
+			wantFinal:  1500,
+			wantEvents: []string{events.OrderCreated, events.OrderDiscounted},
+		},
+		{
+			name:    "rejects a negative discount",
+			req:     withDiscount(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), -1, true),
+			wantErr: ErrValidation,
+		},
+		{
+			name: "rejects a promo code together with a discount amount",
+			req: func() CreateOrderRequest {
+				req := withDiscount(orderRequest(CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1}), 100, false)
+				code := "SUMMER"
+				req.PromoCode = &code
+				return req
+			}(),
+			wantErr: ErrPromoCodeValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, rec := newTestOrderService(t, pricelist)
+
+			order, err := service.CreateOrder(tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateOrder error = %v, want %v", err, tt.wantErr)
+				}
+				if rec.order != nil {
+					t.Errorf("CreateOrder wrote order %+v despite failing", rec.order)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+			if want := models.NewMoneyFromInt(tt.wantFinal); order.FinalAmount.Cmp(want) != 0 {
+				t.Errorf("FinalAmount = %s, want %s", order.FinalAmount, want)
+			}
+			if len(rec.items) != len(tt.req.OrderItems) {
+				t.Errorf("created %d order items, want %d", len(rec.items), len(tt.req.OrderItems))
+			}
+			if len(rec.stockChanges) != len(tt.wantStock) {
+				t.Errorf("stock changed for %v, want %v", rec.stockChanges, tt.wantStock)
+			}
+			for itemID, taken := range tt.wantStock {
+				want := models.NewQuantityFromInt(-taken)
+				if got := rec.stockChanges[itemID]; got.Cmp(want) != 0 {
+					t.Errorf("stock of item %d changed by %s, want %s", itemID, got, want)
+				}
+				if got := rec.fefo[itemID]; got.Cmp(want.Neg()) != 0 {
+					t.Errorf("%s of item %d taken from its batches, want %s", got, itemID, want.Neg())
+				}
+			}
+			if len(rec.movements) != len(tt.wantStock) {
+				t.Errorf("recorded %d inventory movements, want %d", len(rec.movements), len(tt.wantStock))
+			}
+			for _, movement := range rec.movements {
+				if movement.MovementType != models.MovementTypeSale {
+					t.Errorf("movement type = %s, want %s", movement.MovementType, models.MovementTypeSale)
+				}
+			}
+			if !slices.Equal(rec.events, tt.wantEvents) {
+				t.Errorf("published %v, want %v", rec.events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestOrderServiceSplitsDiscountOverItems(t *testing.T) {
+	service, rec := newTestOrderService(t, map[int64]testPricelistItem{
+		1: {name: "Cola", price: 100},
+		2: {name: "Hookah", price: 200},
+	})
+	req := withDiscount(orderRequest(
+		CreateOrderItemRequest{PricelistItemID: 1, Quantity: 1},
+		CreateOrderItemRequest{PricelistItemID: 2, Quantity: 1},
+	), 100, true)
+	if _, err := service.CreateOrder(req); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	total := models.ZeroMoney
+	for _, item := range rec.items {
+		total = total.Add(item.DiscountAmount)
+	}
+	if total.Cmp(models.NewMoneyFromInt(100)) != 0 {
+		t.Errorf("item discount shares add up to %s, want 100", total)
+	}
+	if share := rec.items[1].DiscountAmount; share.Cmp(rec.items[0].DiscountAmount) <= 0 {
+		t.Errorf("the dearer item's share %s is not larger than %s", share, rec.items[0].DiscountAmount)
+	}
+}
+
+func TestParseOrderNumber(t *testing.T) {
+	tests := []struct {
+		number     string
+		wantDate   string // empty for today
+		wantNumber int
+		wantErr    bool
+	}{
+		{number: "2024-06-01/#37", wantDate: "2024-06-01", wantNumber: 37},
+		{number: "#5", wantNumber: 5},
+		{number: "12", wantNumber: 12},
+		{number: "2024-06-01/#0", wantErr: true},
+		{number: "2024-13-01/#3", wantErr: true},
+		{number: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		date, number, err := parseOrderNumber(tt.number)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidOrderNumber) {
+				t.Errorf("parseOrderNumber(%q) error = %v, want %v", tt.number, err, ErrInvalidOrderNumber)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseOrderNumber(%q): %v", tt.number, err)
+			continue
+		}
+		if tt.wantDate != "" && date != tt.wantDate {
+			t.Errorf("parseOrderNumber(%q) date = %s, want %s", tt.number, date, tt.wantDate)
+		}
+		if number != tt.wantNumber {
+			t.Errorf("parseOrderNumber(%q) number = %d, want %d", tt.number, number, tt.wantNumber)
+		}
+	}
+}
+
+func orderRequest(items ...CreateOrderItemRequest) CreateOrderRequest {
+	return CreateOrderRequest{StaffID: 7, Status: StatusPending, CallerRole: "Staff", OrderItems: items}
+}
+
+func withStatus(req CreateOrderRequest, status string) CreateOrderRequest {
+	req.Status = status
+	return req
+}
+
+func withDiscount(req CreateOrderRequest, amount int64, approved bool) CreateOrderRequest {
+	req.DiscountAmount = moneyPtr(amount)
+	req.DiscountApproved = approved
+	return req
+}
+
+func decimalPtr(value int64) *decimal.Decimal {
+	d := decimal.NewFromInt(value)
+	return &d
+}
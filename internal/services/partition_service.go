@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// PartitionMaintenanceInterval is how often RunMaintenance creates the partitions ahead.
+var PartitionMaintenanceInterval = 24 * time.Hour
+
+// PartitionMonthsAhead is how many months after the current one have their partitions created,
+// so an outage of the server at the turn of a month never leaves orders without a partition.
+const PartitionMonthsAhead = 3
+
+// --- PartitionService Interface ---
+type PartitionService interface {
+	// CreatePartitions creates the monthly partitions of repositories.PartitionedTables still
+	// missing for the current month and the PartitionMonthsAhead after it, and returns their names.
+	CreatePartitions() ([]string, error)
+	// RunMaintenance creates the partitions ahead at start and every PartitionMaintenanceInterval
+	// until ctx is done. Every instance may run it.
+	RunMaintenance(ctx context.Context)
+}
+
+type partitionService struct {
+	partitionRepo repositories.PartitionRepository
+}
+
+// NewPartitionService creates a new PartitionService.
+func NewPartitionService(partitionRepo repositories.PartitionRepository) PartitionService {
+	return &partitionService{partitionRepo: partitionRepo}
+}
+
+func (s *partitionService) CreatePartitions() ([]string, error) {
+	now := utils.NowUTC()
+	through := now.AddDate(0, PartitionMonthsAhead, 0)
+	created := []string{}
+	for _, table := range repositories.PartitionedTables {
+		partitions, err := s.partitionRepo.CreateMonthlyPartitions(table, now, through)
+		if err != nil {
+			return created, fmt.Errorf("failed to create the partitions of %s: %w", table, err)
+		}
+		created = append(created, partitions...)
+	}
+	return created, nil
+}
+
+func (s *partitionService) RunMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(PartitionMaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		created, err := s.CreatePartitions()
+		if err != nil {
+			utils.LogError(err, "Failed to create the monthly partitions")
+		}
+		if len(created) > 0 {
+			utils.LogInfo("Created monthly partitions", map[string]interface{}{"partitions": created})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
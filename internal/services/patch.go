@@ -0,0 +1,26 @@
+package services
+
+import "fmt"
+
+// Update requests carry the fields a JSON Merge Patch (RFC 7386) sets to null in
+// Clear, by JSON name, since a nil pointer field means "leave unchanged".
+
+// clears reports whether field is among the cleared fields of an update request.
+func clears(cleared []string, field string) bool {
+	for _, name := range cleared {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// checkClearable returns errValidation if a cleared field is not in clearable.
+func checkClearable(cleared, clearable []string, errValidation error) error {
+	for _, name := range cleared {
+		if !clears(clearable, name) {
+			return fmt.Errorf("%w: %s cannot be cleared", errValidation, name)
+		}
+	}
+	return nil
+}
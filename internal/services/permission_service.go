@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// PermissionCacheTTL is how long the permissions of a role are cached, so changes to
+// role_permissions apply after at most that long.
+var PermissionCacheTTL = time.Minute
+
+// --- PermissionService Interface ---
+type PermissionService interface {
+	// RolePermissions returns the set of permissions granted to a role (by name, any case);
+	// an unknown role has none.
+	RolePermissions(role string) (map[string]bool, error)
+}
+
+type cachedPermissions struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+type permissionService struct {
+	authRepo repositories.AuthRepository
+	mu       sync.RWMutex
+	cache    map[string]cachedPermissions // By lower-cased role name
+}
+
+// NewPermissionService creates a new PermissionService.
+func NewPermissionService(authRepo repositories.AuthRepository) PermissionService {
+	return &permissionService{authRepo: authRepo, cache: map[string]cachedPermissions{}}
+}
+
+func (s *permissionService) RolePermissions(role string) (map[string]bool, error) {
+	key := strings.ToLower(role)
+	now := utils.NowUTC()
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok && now.Sub(cached.loadedAt) < PermissionCacheTTL {
+		return cached.permissions, nil
+	}
+
+	names, err := s.authRepo.GetRolePermissions(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions of role %s: %w", role, err)
+	}
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedPermissions{permissions: permissions, loadedAt: now}
+	s.mu.Unlock()
+	return permissions, nil
+}
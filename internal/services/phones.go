@@ -0,0 +1,21 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"ps_club_backend/pkg/utils"
+)
+
+// normalizePhone returns a phone number as it is stored, in E.164 form (see utils.NormalizePhone),
+// or validationErr if it is not a phone number. Nil and blank numbers are returned as they are.
+func normalizePhone(phone *string, validationErr error) (*string, error) {
+	if phone == nil || strings.TrimSpace(*phone) == "" {
+		return phone, nil
+	}
+	normalized, err := utils.NormalizePhone(*phone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a valid phone number, e.g. +7 701 123 45 67", validationErr, *phone)
+	}
+	return &normalized, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// MaxPhotoBytes is the largest photo accepted for a record.
+const MaxPhotoBytes = 5 << 20
+
+// PhotoContentTypes are the content types accepted for photos.
+var PhotoContentTypes = []string{"image/jpeg", "image/png", "image/webp"}
+
+// checkPhoto returns the content type of a photo, or validationErr if it is empty, too large
+// or not a JPEG, PNG or WebP image. The type is sniffed from the data, not taken from the upload.
+func checkPhoto(data []byte, validationErr error) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: the photo is empty", validationErr)
+	}
+	if len(data) > MaxPhotoBytes {
+		return "", fmt.Errorf("%w: the photo exceeds %d MB", validationErr, MaxPhotoBytes>>20)
+	}
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(PhotoContentTypes, contentType) {
+		return "", fmt.Errorf("%w: the photo must be one of %v, got %s", validationErr, PhotoContentTypes, contentType)
+	}
+	return contentType, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrPowerControlDisabled = apperrors.New(utils.ErrCodeBadRequest, "power control is not configured")
+	ErrNoPowerDevice        = apperrors.New(utils.ErrCodeBadRequest, "the table has no power device")
+	ErrPowerDeviceFailed    = apperrors.New(utils.ErrCodeDeviceFailed, "the power device could not be switched")
+)
+
+// Power states of a table's devices.
+const (
+	PowerStateOn  = "on"
+	PowerStateOff = "off"
+)
+
+// PowerController switches a power device; power.HTTPController implements it.
+type PowerController interface {
+	SetPower(ctx context.Context, deviceID string, on bool) error
+}
+
+// SetTablePowerRequest is the body of POST /tables/:id/power.
+type SetTablePowerRequest struct {
+	State string `json:"state" binding:"required,oneof=on off"`
+}
+
+// --- PowerService Interface ---
+type PowerService interface {
+	// SetTablePower switches the devices of a table on or off by hand, whatever its session.
+	SetTablePower(ctx context.Context, tableID int64, on bool) error
+	// HandleSessionEvent switches the devices of the event's table on when a session starts or
+	// the client checks in, and off when the session stops. Subscribe it to those events;
+	// a failure is returned so the relay retries the event.
+	HandleSessionEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type powerService struct {
+	bookingRepo repositories.BookingRepository
+	controller  PowerController // nil if power control is not configured
+}
+
+// NewPowerService creates a new PowerService. controller may be nil, which disables power control.
+func NewPowerService(bookingRepo repositories.BookingRepository, controller PowerController) PowerService {
+	return &powerService{bookingRepo: bookingRepo, controller: controller}
+}
+
+// PowerEvents lists the events HandleSessionEvent acts on, with the power state each one sets.
+var PowerEvents = map[string]bool{
+	events.TableSessionStarted: true,
+	events.BookingCheckedIn:    true,
+	events.TableSessionStopped: false,
+}
+
+func (s *powerService) SetTablePower(ctx context.Context, tableID int64, on bool) error {
+	if s.controller == nil {
+		return ErrPowerControlDisabled
+	}
+	table, err := s.bookingRepo.GetGameTableByID(tableID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrGameTableNotFound
+		}
+		return fmt.Errorf("failed to get game table: %w", err)
+	}
+	if table.PowerDeviceID == nil || *table.PowerDeviceID == "" {
+		return ErrNoPowerDevice
+	}
+	if err := s.controller.SetPower(ctx, *table.PowerDeviceID, on); err != nil {
+		return fmt.Errorf("%w: %v", ErrPowerDeviceFailed, err)
+	}
+	utils.LogInfo("Table power switched", map[string]interface{}{"table_id": tableID, "device_id": *table.PowerDeviceID, "on": on})
+	return nil
+}
+
+func (s *powerService) HandleSessionEvent(ctx context.Context, event models.DomainEvent) error {
+	on, ok := PowerEvents[event.EventType]
+	if !ok || s.controller == nil {
+		return nil
+	}
+	var payload struct {
+		TableID int64 `json:"table_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+	}
+	err := s.SetTablePower(ctx, payload.TableID, on)
+	if errors.Is(err, ErrNoPowerDevice) || errors.Is(err, ErrGameTableNotFound) {
+		return nil
+	}
+	return err
+}
@@ -6,18 +6,22 @@ import (
 	"fmt"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 	"strings"
-	// "time" // Not directly used in DTOs, but will be in method implementations
+	"time"
 )
 
 // --- Custom Service Errors for Pricelist ---
 var (
-	ErrCategoryNotFound    = errors.New("category not found")
-	ErrCategoryNameExists  = errors.New("category name already exists")
-	ErrItemNotFound        = errors.New("pricelist item not found")
-	ErrItemNameConflict    = errors.New("item name/SKU conflict") // More generic for SKU or name within category
-	ErrValidation          = errors.New("validation error")      // Generic validation error
-	ErrPricelistForeignKey = errors.New("operation failed due to existing references (e.g., category in use by items, or item in use by orders)")
+	ErrCategoryNotFound    = apperrors.New(utils.ErrCodeNotFound, "category not found")
+	ErrCategoryNameExists  = apperrors.New(utils.ErrCodeConflict, "category name already exists")
+	ErrItemNotFound        = apperrors.New(utils.ErrCodeNotFound, "pricelist item not found")
+	ErrItemNotDeleted      = apperrors.New(utils.ErrCodeNotFound, "pricelist item is not deleted")
+	ErrItemNameConflict    = apperrors.New(utils.ErrCodeConflict, "item name/SKU conflict")                                       // More generic for SKU or name within category
+	ErrValidation          = apperrors.New(utils.ErrCodeValidationFailed, "validation error")                                     // Generic validation error
+	ErrVersionConflict     = apperrors.New(utils.ErrCodeVersionConflict, "record was modified by another user, reload and retry") // Generic optimistic lock failure
+	ErrPricelistForeignKey = apperrors.New(utils.ErrCodeConflict, "operation failed due to existing references (e.g., category in use by items, or item in use by orders)")
 )
 
 // --- Category DTOs ---
@@ -35,27 +39,50 @@ type CreatePricelistItemRequest struct {
 	CategoryID        int64    `json:"category_id" binding:"required"`
 	Name              string   `json:"name" binding:"required"`
 	Description       *string  `json:"description"`
-	Price             float64  `json:"price" binding:"required,gt=0"`
+	Price             models.Money `json:"price" binding:"required,gt=0"`
 	SKU               *string  `json:"sku"`
 	IsAvailable       bool     `json:"is_available"` // Defaults to false (Go default) if not in JSON
-	ItemType          string   `json:"item_type" binding:"required"`
+	ItemType          string   `json:"item_type" binding:"required,item_type"`
 	TracksStock       bool     `json:"tracks_stock"` // Defaults to false (Go default) if not in JSON
-	CurrentStock      *int     `json:"current_stock"`
-	LowStockThreshold *int     `json:"low_stock_threshold"`
+	CurrentStock      *models.Quantity `json:"current_stock" binding:"omitempty,gte=0"`     // In the stock unit
+	LowStockThreshold *models.Quantity `json:"low_stock_threshold" binding:"omitempty,gte=0"`
+	StockUnit         *string  `json:"stock_unit" binding:"omitempty,stock_unit"`             // Omitted for pcs
+	PortionSize       *models.Quantity `json:"portion_size" binding:"omitempty,gt=0"`       // Stock a sold unit takes; omitted for 1
+	PurchaseUnit      *string  `json:"purchase_unit"`                                         // e.g. "bottle"
+	PurchaseUnitSize  *models.Quantity `json:"purchase_unit_size" binding:"omitempty,gt=0"` // Stock a purchase unit holds; omitted for 1
+	TaxClass          *string  `json:"tax_class"` // One of the classes of the tax setting; omitted for its default class
 }
 type UpdatePricelistItemRequest struct {
 	CategoryID        *int64   `json:"category_id"`
 	Name              *string  `json:"name"`
 	Description       *string  `json:"description"`
-	Price             *float64 `json:"price,omitempty,gt=0"`
+	Price             *models.Money `json:"price,omitempty" binding:"omitempty,gt=0"`
 	SKU               *string  `json:"sku"`
 	IsAvailable       *bool    `json:"is_available"`
-	ItemType          *string  `json:"item_type"`
+	ItemType          *string  `json:"item_type" binding:"omitempty,item_type"`
 	TracksStock       *bool    `json:"tracks_stock"`
-	CurrentStock      *int     `json:"current_stock"`
-	LowStockThreshold *int     `json:"low_stock_threshold"`
+	CurrentStock      *models.Quantity `json:"current_stock" binding:"omitempty,gte=0"`
+	LowStockThreshold *models.Quantity `json:"low_stock_threshold" binding:"omitempty,gte=0"`
+	StockUnit         *string  `json:"stock_unit" binding:"omitempty,stock_unit"`
+	PortionSize       *models.Quantity `json:"portion_size" binding:"omitempty,gt=0"`
+	PurchaseUnit      *string  `json:"purchase_unit"`
+	PurchaseUnitSize  *models.Quantity `json:"purchase_unit_size" binding:"omitempty,gt=0"`
+	TaxClass          *string  `json:"tax_class"`
+	Version           *int     `json:"version"` // Version the client last read; a stale value is rejected with ErrVersionConflict
+	CallerRole        string   `json:"-"`       // Role of the authenticated user; limits the fields it may change (pricelistItemFieldRules)
+	Clear             []string `json:"-"`       // Fields to set to null, from a merge patch; see PricelistItemClearableFields
 }
 
+// BulkSetItemAvailabilityRequest makes several items available or unavailable, e.g.
+// everything of a supplier that failed to deliver.
+type BulkSetItemAvailabilityRequest struct {
+	ItemIDs     []int64 `json:"item_ids" binding:"required,min=1"`
+	IsAvailable *bool   `json:"is_available" binding:"required"`
+}
+
+// PricelistItemClearableFields are the fields of UpdatePricelistItemRequest a merge patch may set to null.
+var PricelistItemClearableFields = []string{"description", "sku", "low_stock_threshold", "purchase_unit", "tax_class"}
+
 // --- PricelistService Interface ---
 type PricelistService interface {
 	CreateCategory(req CreatePricelistCategoryRequest) (*models.PricelistCategory, error)
@@ -68,18 +95,28 @@ type PricelistService interface {
 	GetItemByID(itemID int64) (*models.PricelistItem, error)
 	GetItems(categoryID *int64, itemType *string, page, pageSize int) ([]models.PricelistItem, int, error)
 	UpdateItem(itemID int64, req UpdatePricelistItemRequest) (*models.PricelistItem, error)
-	DeleteItem(itemID int64) error
+	// DeleteItem soft deletes an item: it is left out of the pricelist, staying on past orders,
+	// until an Admin restores it. The delete is recorded in the audit log.
+	DeleteItem(itemID int64, deletedBy int64) error
+	// RestoreItem restores a soft deleted item, recording it in the audit log. ErrItemNotDeleted
+	// if the item is not deleted; ErrItemNameConflict if another item has its SKU since.
+	RestoreItem(itemID int64, restoredBy int64) (*models.PricelistItem, error)
+	// BulkSetItemAvailability sets the availability of the items in one transaction and
+	// reports the outcome per item.
+	BulkSetItemAvailability(req BulkSetItemAvailabilityRequest) (*BulkResult, error)
 }
 
 // --- pricelistService Implementation ---
 type pricelistService struct {
 	pricelistRepo repositories.PricelistRepository
+	auditLogRepo  repositories.AuditLogRepository
 	db            *sql.DB
 }
 
-func NewPricelistService(repo repositories.PricelistRepository, db *sql.DB) PricelistService {
+func NewPricelistService(repo repositories.PricelistRepository, auditLogRepo repositories.AuditLogRepository, db *sql.DB) PricelistService {
 	return &pricelistService{
 		pricelistRepo: repo,
+		auditLogRepo:  auditLogRepo,
 		db:            db,
 	}
 }
@@ -173,7 +210,7 @@ func (s *pricelistService) DeleteCategory(categoryID int64) error {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return ErrCategoryNotFound
 		}
-		if strings.Contains(err.Error(), "is currently in use by") || strings.Contains(err.Error(), "violates foreign key constraint"){
+		if errors.Is(err, repositories.ErrReferenced) {
 			return fmt.Errorf("%w: category cannot be deleted as it's referenced by other records", ErrPricelistForeignKey)
 		}
 		return fmt.Errorf("failed to delete category: %w", err)
@@ -188,19 +225,30 @@ func (s *pricelistService) CreateItem(req CreatePricelistItemRequest) (*models.P
 		return nil, fmt.Errorf("%w: item name cannot be empty", ErrValidation)
 	}
 	if req.TracksStock && req.CurrentStock == nil {
-		zeroStock := 0
+		zeroStock := models.ZeroQuantity
 		req.CurrentStock = &zeroStock // Default to 0 if tracking stock and not provided
 	}
 	if !req.TracksStock { // If not tracking, ensure stock fields are nil to avoid confusion/errors
 		req.CurrentStock = nil
 		req.LowStockThreshold = nil
 	}
-	if req.TracksStock && req.CurrentStock != nil && *req.CurrentStock < 0 {
+	if req.TracksStock && req.CurrentStock != nil && req.CurrentStock.IsNegative() {
 		return nil, fmt.Errorf("%w: current stock cannot be negative", ErrValidation)
 	}
-	if req.LowStockThreshold != nil && *req.LowStockThreshold < 0 {
+	if req.LowStockThreshold != nil && req.LowStockThreshold.IsNegative() {
 		return nil, fmt.Errorf("%w: low stock threshold cannot be negative", ErrValidation)
 	}
+	req.ItemType = strings.ToUpper(strings.TrimSpace(req.ItemType))
+	if !models.IsValidItemType(req.ItemType) {
+		return nil, fmt.Errorf("%w: item type must be one of %s", ErrValidation, strings.Join(models.ItemTypes, ", "))
+	}
+	if err := validateTaxClass(req.TaxClass); err != nil {
+		return nil, err
+	}
+	units := models.DefaultItemUnits()
+	if err := applyItemUnits(&units, req.StockUnit, req.PortionSize, req.PurchaseUnit, req.PurchaseUnitSize); err != nil {
+		return nil, err
+	}
 
 
 	// Check if category exists
@@ -224,6 +272,8 @@ func (s *pricelistService) CreateItem(req CreatePricelistItemRequest) (*models.P
 		TracksStock:       req.TracksStock,
 		CurrentStock:      req.CurrentStock,
 		LowStockThreshold: req.LowStockThreshold,
+		ItemUnits:         units,
+		TaxClass:          req.TaxClass,
 	}
 
 	id, err := s.pricelistRepo.CreateItem(s.db, item)
@@ -231,7 +281,7 @@ func (s *pricelistService) CreateItem(req CreatePricelistItemRequest) (*models.P
 		if errors.Is(err, repositories.ErrDuplicateKey) {
 			return nil, fmt.Errorf("%w: %s", ErrItemNameConflict, err.Error())
 		}
-		if strings.Contains(err.Error(), "pricelist_items_category_id_fkey") {
+		if repositories.ViolatedConstraint(err) == "pricelist_items_category_id_fkey" {
 			return nil, fmt.Errorf("%w: category with ID %d not found for item", ErrCategoryNotFound, req.CategoryID)
 		}
 		return nil, fmt.Errorf("failed to create item: %w", err)
@@ -269,6 +319,20 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 		}
 		return nil, fmt.Errorf("failed to find item for update: %w", err)
 	}
+	if req.Version != nil && *req.Version != item.Version {
+		return nil, ErrVersionConflict
+	}
+	if err := checkClearable(req.Clear, PricelistItemClearableFields, ErrValidation); err != nil {
+		return nil, err
+	}
+	var changed []string
+	if moneyChanged(&item.Price, req.Price) { changed = append(changed, "price") }
+	if stringChanged(item.TaxClass, req.TaxClass) || (clears(req.Clear, "tax_class") && item.TaxClass != nil) {
+		changed = append(changed, "tax_class")
+	}
+	if err := pricelistItemFieldRules.check(req.CallerRole, changed); err != nil {
+		return nil, err
+	}
 
 	if req.CategoryID != nil {
 		// Validate new category if provided
@@ -292,7 +356,22 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 	if req.Price != nil { item.Price = *req.Price }
 	if req.SKU != nil { item.SKU = req.SKU } // SKU can be set to empty string
 	if req.IsAvailable != nil { item.IsAvailable = *req.IsAvailable }
-	if req.ItemType != nil { item.ItemType = *req.ItemType }
+	if req.ItemType != nil {
+		itemType := strings.ToUpper(strings.TrimSpace(*req.ItemType))
+		if !models.IsValidItemType(itemType) {
+			return nil, fmt.Errorf("%w: item type must be one of %s", ErrValidation, strings.Join(models.ItemTypes, ", "))
+		}
+		item.ItemType = itemType
+	}
+	if req.TaxClass != nil {
+		if err := validateTaxClass(req.TaxClass); err != nil {
+			return nil, err
+		}
+		item.TaxClass = req.TaxClass
+	}
+	if err := applyItemUnits(&item.ItemUnits, req.StockUnit, req.PortionSize, req.PurchaseUnit, req.PurchaseUnitSize); err != nil {
+		return nil, err
+	}
 
 	// Handle TracksStock logic
 	if req.TracksStock != nil {
@@ -302,10 +381,10 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 			item.LowStockThreshold = nil
 		} else { // If changing to track stock (or already tracking)
 			if req.CurrentStock != nil { // If new stock value is provided
-				if *req.CurrentStock < 0 { return nil, fmt.Errorf("%w: current stock cannot be negative", ErrValidation) }
+				if req.CurrentStock.IsNegative() { return nil, fmt.Errorf("%w: current stock cannot be negative", ErrValidation) }
 				item.CurrentStock = req.CurrentStock
 			} else if item.CurrentStock == nil { // If now tracking and stock was nil, default to 0
-				zeroStock := 0
+				zeroStock := models.ZeroQuantity
 				item.CurrentStock = &zeroStock
 			}
 		}
@@ -313,7 +392,7 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 		if !item.TracksStock {
 			return nil, fmt.Errorf("%w: cannot update CurrentStock for an item that does not track stock, unless TracksStock is also updated to true", ErrValidation)
 		}
-		if *req.CurrentStock < 0 { return nil, fmt.Errorf("%w: current stock cannot be negative", ErrValidation) }
+		if req.CurrentStock.IsNegative() { return nil, fmt.Errorf("%w: current stock cannot be negative", ErrValidation) }
 		item.CurrentStock = req.CurrentStock
 	}
 
@@ -321,11 +400,16 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 		if !item.TracksStock {
 			return nil, fmt.Errorf("%w: cannot set LowStockThreshold for an item that does not track stock", ErrValidation)
 		}
-		if *req.LowStockThreshold < 0 { return nil, fmt.Errorf("%w: low stock threshold cannot be negative", ErrValidation) }
+		if req.LowStockThreshold.IsNegative() { return nil, fmt.Errorf("%w: low stock threshold cannot be negative", ErrValidation) }
 		item.LowStockThreshold = req.LowStockThreshold
 	} else if req.TracksStock != nil && !*req.TracksStock { // If TracksStock is being set to false
 		item.LowStockThreshold = nil // Ensure it's cleared
 	}
+	if clears(req.Clear, "description") { item.Description = nil }
+	if clears(req.Clear, "sku") { item.SKU = nil }
+	if clears(req.Clear, "low_stock_threshold") { item.LowStockThreshold = nil }
+	if clears(req.Clear, "purchase_unit") { item.PurchaseUnit = nil }
+	if clears(req.Clear, "tax_class") { item.TaxClass = nil }
 
 
 	err = s.pricelistRepo.UpdateItem(s.db, item)
@@ -336,7 +420,10 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 		if errors.Is(err, repositories.ErrNotFound) {
 			return nil, ErrItemNotFound // Should have been caught by GetItemByID
 		}
-		if strings.Contains(err.Error(), "pricelist_items_category_id_fkey") {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
+		if repositories.ViolatedConstraint(err) == "pricelist_items_category_id_fkey" {
 			return nil, fmt.Errorf("%w: category with ID %d not found for item", ErrCategoryNotFound, item.CategoryID)
 		}
 		return nil, fmt.Errorf("failed to update item: %w", err)
@@ -344,7 +431,36 @@ func (s *pricelistService) UpdateItem(itemID int64, req UpdatePricelistItemReque
 	return s.pricelistRepo.GetItemByID(itemID)
 }
 
-func (s *pricelistService) DeleteItem(itemID int64) error {
+func (s *pricelistService) BulkSetItemAvailability(req BulkSetItemAvailabilityRequest) (*BulkResult, error) {
+	if req.IsAvailable == nil {
+		return nil, fmt.Errorf("%w: is_available is required", ErrValidation)
+	}
+	return runBulk(s.db, req.ItemIDs, func(tx *sql.Tx, itemID int64) error {
+		item, err := s.pricelistRepo.GetItemByID(itemID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrItemNotFound
+			}
+			return fmt.Errorf("failed to find item: %w", err)
+		}
+		if item.IsAvailable == *req.IsAvailable {
+			return nil
+		}
+		item.IsAvailable = *req.IsAvailable
+		if err := s.pricelistRepo.UpdateItem(tx, item); err != nil {
+			if errors.Is(err, repositories.ErrVersionConflict) {
+				return ErrVersionConflict
+			}
+			if errors.Is(err, repositories.ErrNotFound) {
+				return ErrItemNotFound
+			}
+			return fmt.Errorf("failed to update item: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *pricelistService) DeleteItem(itemID int64, deletedBy int64) error {
 	_, err := s.pricelistRepo.GetItemByID(itemID)
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
@@ -353,15 +469,67 @@ func (s *pricelistService) DeleteItem(itemID int64) error {
 		return fmt.Errorf("failed to find item for deletion: %w", err)
 	}
 
-	err = s.pricelistRepo.DeleteItem(s.db, itemID)
+	err = softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordDeleted, ReferenceEntityPricelistItems, itemID, deletedBy, func(tx *sql.Tx, now time.Time) error {
+		return s.pricelistRepo.DeleteItem(tx, itemID, deletedBy, now)
+	})
 	if err != nil {
 		if errors.Is(err, repositories.ErrNotFound) {
 			return ErrItemNotFound
 		}
-		if strings.Contains(err.Error(), "is referenced by other records") || strings.Contains(err.Error(), "violates foreign key constraint") {
-			return fmt.Errorf("%w: item cannot be deleted as it's referenced by other records", ErrPricelistForeignKey)
-		}
 		return fmt.Errorf("failed to delete item: %w", err)
 	}
 	return nil
 }
+
+func (s *pricelistService) RestoreItem(itemID int64, restoredBy int64) (*models.PricelistItem, error) {
+	err := softDeleteChange(s.db, s.auditLogRepo, models.AuditActionRecordRestored, ReferenceEntityPricelistItems, itemID, restoredBy, func(tx *sql.Tx, now time.Time) error {
+		return s.pricelistRepo.RestoreItem(tx, itemID, now)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrItemNotDeleted
+		}
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, fmt.Errorf("%w: its SKU is used by another item since it was deleted", ErrItemNameConflict)
+		}
+		return nil, fmt.Errorf("failed to restore item: %w", err)
+	}
+	return s.pricelistRepo.GetItemByID(itemID)
+}
+
+// validateTaxClass checks that a tax class given for an item is one of the classes of the tax setting.
+func validateTaxClass(class *string) error {
+	if class == nil {
+		return nil
+	}
+	if _, ok := CurrentTaxSettings().Classes[*class]; !ok {
+		return fmt.Errorf("%w: tax class %q is not one of the classes of the tax setting", ErrValidation, *class)
+	}
+	return nil
+}
+
+// applyItemUnits sets the units of measure given for an item on units.
+func applyItemUnits(units *models.ItemUnits, stockUnit *string, portionSize *models.Quantity, purchaseUnit *string, purchaseUnitSize *models.Quantity) error {
+	if stockUnit != nil {
+		if !models.IsValidStockUnit(*stockUnit) {
+			return fmt.Errorf("%w: stock unit must be one of %s", ErrValidation, strings.Join(models.StockUnits, ", "))
+		}
+		units.StockUnit = *stockUnit
+	}
+	if portionSize != nil {
+		if !portionSize.IsPositive() {
+			return fmt.Errorf("%w: portion size must be positive", ErrValidation)
+		}
+		units.PortionSize = *portionSize
+	}
+	if purchaseUnit != nil {
+		units.PurchaseUnit = purchaseUnit
+	}
+	if purchaseUnitSize != nil {
+		if !purchaseUnitSize.IsPositive() {
+			return fmt.Errorf("%w: purchase unit size must be positive", ErrValidation)
+		}
+		units.PurchaseUnitSize = *purchaseUnitSize
+	}
+	return nil
+}
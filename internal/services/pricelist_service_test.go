@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/internal/repositories/mocks"
+)
+
+// newTestPricelistService returns a pricelist service over category 1 and item 10 (Cola at
+// 500, tracking 4 in stock, version 2), recording the items it creates and updates.
+func newTestPricelistService(t *testing.T) (PricelistService, *mocks.MockPricelistRepository, *[]models.PricelistItem) {
+	t.Helper()
+	var written []models.PricelistItem
+	repo := &mocks.MockPricelistRepository{
+		GetCategoryByIDFunc: func(id int64) (*models.PricelistCategory, error) {
+			if id != 1 {
+				return nil, repositories.ErrNotFound
+			}
+			return &models.PricelistCategory{ID: 1, Name: "Drinks"}, nil
+		},
+		GetItemByIDFunc: func(id int64) (*models.PricelistItem, error) {
+			if id != 10 {
+				return nil, repositories.ErrNotFound
+			}
+			stock := models.NewQuantityFromInt(4)
+			return &models.PricelistItem{
+				ID: 10, CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR",
+				TracksStock: true, CurrentStock: &stock, ItemUnits: models.DefaultItemUnits(), Version: 2,
+			}, nil
+		},
+		CreateItemFunc: func(_ repositories.SQLExecutor, item *models.PricelistItem) (int64, error) {
+			written = append(written, *item)
+			return 10, nil
+		},
+		UpdateItemFunc: func(_ repositories.SQLExecutor, item *models.PricelistItem) error {
+			written = append(written, *item)
+			return nil
+		},
+	}
+	return NewPricelistService(repo, &mocks.MockAuditLogRepository{}, newTxDB(t)), repo, &written
+}
+
+func TestPricelistServiceCreateItem(t *testing.T) {
+	negative := models.NewQuantityFromInt(-1)
+	stock := models.NewQuantityFromInt(5)
+	zeroPortion := models.ZeroQuantity
+	unknownClass := "luxury"
+
+	tests := []struct {
+		name      string
+		req       CreatePricelistItemRequest
+		repoErr   error // Returned by CreateItem
+		wantErr   error
+		wantStock *models.Quantity // Stock written; nil when the item must not track stock
+	}{
+		{
+			name:      "starts a tracked item at zero stock",
+			req:       CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "bar", TracksStock: true},
+			wantStock: &models.ZeroQuantity,
+		},
+		{
+			name:      "keeps the stock given",
+			req:       CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR", TracksStock: true, CurrentStock: &stock},
+			wantStock: &stock,
+		},
+		{
+			name: "drops the stock of an item that does not track it",
+			req:  CreatePricelistItemRequest{CategoryID: 1, Name: "Hookah", Price: models.NewMoneyFromInt(3000), ItemType: "HOOKAH", CurrentStock: &stock},
+		},
+		{
+			name:    "rejects an empty name",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "  ", Price: models.NewMoneyFromInt(500), ItemType: "BAR"},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects an unknown item type",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "GADGET"},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects negative stock",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR", TracksStock: true, CurrentStock: &negative},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects a portion size that is not positive",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR", PortionSize: &zeroPortion},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects a tax class the tax setting does not have",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR", TaxClass: &unknownClass},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects an unknown category",
+			req:     CreatePricelistItemRequest{CategoryID: 2, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR"},
+			wantErr: ErrCategoryNotFound,
+		},
+		{
+			name:    "reports a SKU taken by another item",
+			req:     CreatePricelistItemRequest{CategoryID: 1, Name: "Cola", Price: models.NewMoneyFromInt(500), ItemType: "BAR"},
+			repoErr: fmt.Errorf("%w: sku", repositories.ErrDuplicateKey),
+			wantErr: ErrItemNameConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, written := newTestPricelistService(t)
+			if tt.repoErr != nil {
+				repo.CreateItemFunc = func(repositories.SQLExecutor, *models.PricelistItem) (int64, error) { return 0, tt.repoErr }
+			}
+
+			_, err := service.CreateItem(tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateItem error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateItem: %v", err)
+			}
+			item := (*written)[0]
+			if item.ItemType != strings.ToUpper(tt.req.ItemType) {
+				t.Errorf("ItemType = %s, want it upper-cased", item.ItemType)
+			}
+			switch {
+			case tt.wantStock == nil && (item.CurrentStock != nil || item.LowStockThreshold != nil):
+				t.Errorf("an item without stock tracking was written with stock %v", item.CurrentStock)
+			case tt.wantStock != nil && (item.CurrentStock == nil || item.CurrentStock.Cmp(*tt.wantStock) != 0):
+				t.Errorf("CurrentStock = %v, want %s", item.CurrentStock, tt.wantStock)
+			}
+		})
+	}
+}
+
+func TestPricelistServiceUpdateItem(t *testing.T) {
+	staleVersion, currentVersion := 1, 2
+	price := models.NewMoneyFromInt(600)
+	stock := models.NewQuantityFromInt(8)
+	negative := models.NewQuantityFromInt(-2)
+	tracks, untracks := true, false
+	empty := ""
+
+	tests := []struct {
+		name    string
+		req     UpdatePricelistItemRequest
+		itemID  int64
+		repoErr error // Returned by UpdateItem
+		wantErr error
+		check   func(t *testing.T, item models.PricelistItem)
+	}{
+		{
+			name: "changes the price as an Admin",
+			req:  UpdatePricelistItemRequest{Price: &price, Version: &currentVersion, CallerRole: "Admin"},
+			check: func(t *testing.T, item models.PricelistItem) {
+				if item.Price.Cmp(price) != 0 {
+					t.Errorf("Price = %s, want %s", item.Price, price)
+				}
+			},
+		},
+		{
+			name:    "rejects a price change by Staff",
+			req:     UpdatePricelistItemRequest{Price: &price, CallerRole: "Staff"},
+			wantErr: &FieldPermissionError{},
+		},
+		{
+			name: "lets Staff set the stock",
+			req:  UpdatePricelistItemRequest{CurrentStock: &stock, CallerRole: "Staff"},
+			check: func(t *testing.T, item models.PricelistItem) {
+				if item.CurrentStock == nil || item.CurrentStock.Cmp(stock) != 0 {
+					t.Errorf("CurrentStock = %v, want %s", item.CurrentStock, stock)
+				}
+			},
+		},
+		{
+			name: "clears the stock when tracking stops",
+			req:  UpdatePricelistItemRequest{TracksStock: &untracks},
+			check: func(t *testing.T, item models.PricelistItem) {
+				if item.TracksStock || item.CurrentStock != nil || item.LowStockThreshold != nil {
+					t.Errorf("item still tracks stock: %+v", item)
+				}
+			},
+		},
+		{
+			name:    "rejects negative stock",
+			req:     UpdatePricelistItemRequest{TracksStock: &tracks, CurrentStock: &negative},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects an empty name",
+			req:     UpdatePricelistItemRequest{Name: &empty},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects clearing a field that cannot be cleared",
+			req:     UpdatePricelistItemRequest{Clear: []string{"price"}},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "rejects a stale version",
+			req:     UpdatePricelistItemRequest{Price: &price, Version: &staleVersion},
+			wantErr: ErrVersionConflict,
+		},
+		{
+			name:    "reports a change made between reading and writing",
+			req:     UpdatePricelistItemRequest{Price: &price},
+			repoErr: repositories.ErrVersionConflict,
+			wantErr: ErrVersionConflict,
+		},
+		{
+			name:    "rejects an unknown item",
+			itemID:  11,
+			req:     UpdatePricelistItemRequest{Price: &price},
+			wantErr: ErrItemNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, written := newTestPricelistService(t)
+			if tt.repoErr != nil {
+				repo.UpdateItemFunc = func(repositories.SQLExecutor, *models.PricelistItem) error { return tt.repoErr }
+			}
+			itemID := tt.itemID
+			if itemID == 0 {
+				itemID = 10
+			}
+
+			_, err := service.UpdateItem(itemID, tt.req)
+			if tt.wantErr != nil {
+				var permissionErr *FieldPermissionError
+				if _, ok := tt.wantErr.(*FieldPermissionError); ok {
+					if !errors.As(err, &permissionErr) {
+						t.Fatalf("UpdateItem error = %v, want a *FieldPermissionError", err)
+					}
+				} else if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateItem error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.repoErr == nil && len(*written) > 0 {
+					t.Errorf("UpdateItem wrote %+v despite failing", (*written)[0])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateItem: %v", err)
+			}
+			tt.check(t, (*written)[0])
+		})
+	}
+}
+
+func TestPricelistServiceDeleteCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		categoryID int64
+		repoErr    error
+		wantErr    error
+	}{
+		{name: "deletes an unused category", categoryID: 1},
+		{name: "rejects a category items still use", categoryID: 1, repoErr: repositories.ErrReferenced, wantErr: ErrPricelistForeignKey},
+		{name: "rejects an unknown category", categoryID: 2, wantErr: ErrCategoryNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newTestPricelistService(t)
+			repo.DeleteCategoryFunc = func(repositories.SQLExecutor, int64) error { return tt.repoErr }
+
+			err := service.DeleteCategory(tt.categoryID)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("DeleteCategory: %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteCategory error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPricelistServiceRestoreItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{name: "rejects an item that is not deleted", repoErr: repositories.ErrNotFound, wantErr: ErrItemNotDeleted},
+		{name: "rejects an item whose SKU was taken since", repoErr: repositories.ErrDuplicateKey, wantErr: ErrItemNameConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newTestPricelistService(t)
+			repo.RestoreItemFunc = func(repositories.SQLExecutor, int64, time.Time) error { return tt.repoErr }
+
+			if _, err := service.RestoreItem(10, 1); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RestoreItem error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
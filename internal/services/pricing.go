@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"ps_club_backend/internal/models"
+)
+
+var (
+	pricingRules   = models.PricingRules{}
+	pricingRulesMu sync.RWMutex
+)
+
+// SetPricingRules sets the pricing rules (the pricing_rules setting).
+func SetPricingRules(rules models.PricingRules) {
+	pricingRulesMu.Lock()
+	defer pricingRulesMu.Unlock()
+	pricingRules = rules
+}
+
+// CurrentPricingRules returns the configured pricing rules.
+func CurrentPricingRules() models.PricingRules {
+	pricingRulesMu.RLock()
+	defer pricingRulesMu.RUnlock()
+	return pricingRules
+}
+
+// quoteTable prices minutes of play at table with controllers (nil for the table's base
+// controllers) under the current pricing rules: the table's hourly rate plus the surcharge
+// of its console type for each extra controller. A per-minute table charges its minute rate
+// plus the surcharge / 60 for each minute rounded up to its billing increment, and at least
+// its minimum charge. An invalid number of controllers is reported as errValidation.
+func quoteTable(table *models.GameTable, controllers *int, minutes int, errValidation error) (*models.PriceQuote, error) {
+	rules := CurrentPricingRules()
+	base := table.BaseControllers
+	if base <= 0 {
+		base = models.DefaultBaseControllers
+	}
+	count := base
+	if controllers != nil {
+		count = *controllers
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("%w: controllers must be at least 1", errValidation)
+	}
+	if rules.MaxControllers > 0 && count > rules.MaxControllers {
+		return nil, fmt.Errorf("%w: at most %d controllers can be used at a table", errValidation, rules.MaxControllers)
+	}
+
+	quote := &models.PriceQuote{
+		TableID:              table.ID,
+		ConsoleType:          table.ConsoleType,
+		Minutes:              minutes,
+		Controllers:          count,
+		BaseControllers:      base,
+		ExtraControllers:     max(count-base, 0),
+		ControllerHourlyRate: rules.ControllerSurcharge(table.ConsoleType),
+	}
+	if table.HourlyRate != nil {
+		quote.TableHourlyRate = *table.HourlyRate
+	}
+	controllerHourly := quote.ControllerHourlyRate.MulInt(quote.ExtraControllers)
+	quote.HourlyRate = quote.TableHourlyRate.Add(controllerHourly)
+	quote.BillingMode = table.BillingMode
+	if quote.BillingMode != models.BillingModePerMinute {
+		quote.BillingMode = models.BillingModeHourly
+		quote.BilledMinutes = minutes
+		quote.TableAmount = quote.TableHourlyRate.ForMinutes(minutes)
+		quote.ControllerAmount = controllerHourly.ForMinutes(minutes)
+		quote.TotalAmount = quote.TableAmount.Add(quote.ControllerAmount)
+		return quote, nil
+	}
+
+	tableMinute := models.ZeroMoney
+	if table.MinuteRate != nil {
+		tableMinute = *table.MinuteRate
+	}
+	controllerMinute := controllerHourly.ForMinutes(1)
+	minuteRate := tableMinute.Add(controllerMinute)
+	quote.TableMinuteRate = &tableMinute
+	quote.MinuteRate = &minuteRate
+	quote.BillingIncrementMinutes = billingIncrement(table.BillingIncrementMinutes)
+	quote.MinimumCharge = table.MinimumCharge
+	var amount models.Money
+	quote.BilledMinutes, amount, quote.MinimumChargeAmount = models.ChargePerMinute(minuteRate, minutes, quote.BillingIncrementMinutes, table.MinimumCharge)
+	quote.TableAmount = tableMinute.MulInt(quote.BilledMinutes).Round()
+	quote.ControllerAmount = amount.Sub(quote.TableAmount)
+	quote.TotalAmount = amount.Add(quote.MinimumChargeAmount)
+	return quote, nil
+}
+
+// billingIncrement returns the billing increment of a per-minute table, the default if it is not set.
+func billingIncrement(minutes int) int {
+	if minutes <= 0 {
+		return models.DefaultBillingIncrementMinutes
+	}
+	return minutes
+}
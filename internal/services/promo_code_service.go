@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPromoCodeNotFound   = apperrors.New(utils.ErrCodeNotFound, "promo code not found")
+	ErrPromoCodeExists     = apperrors.New(utils.ErrCodeConflict, "the promo code already exists")
+	ErrPromoCodeValidation = apperrors.New(utils.ErrCodeValidationFailed, "promo code validation error")
+)
+
+// promoCodePattern is the form of a promo code, after it is upper-cased.
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,30}$`)
+
+// PromoCodeRequest is the body of POST /promo-codes.
+type PromoCodeRequest struct {
+	Code            string          `json:"code" binding:"required"` // 3 to 30 letters, digits, - or _; stored upper case
+	Description     *string         `json:"description"`
+	DiscountPercent decimal.Decimal `json:"discount_percent"` // Above 0, at most 100
+	IssuedCount     int             `json:"issued_count" binding:"min=0"`
+	Active          *bool           `json:"active"` // Defaults to true
+}
+
+// UpdatePromoCodeRequest is the body of PUT /promo-codes/:id; fields left out are kept. The
+// code itself cannot be changed, so the redemptions stay with it.
+type UpdatePromoCodeRequest struct {
+	Description     *string          `json:"description"`
+	DiscountPercent *decimal.Decimal `json:"discount_percent"`
+	IssuedCount     *int             `json:"issued_count" binding:"omitempty,min=0"`
+	Active          *bool            `json:"active"`
+}
+
+// --- PromoCodeService Interface ---
+type PromoCodeService interface {
+	CreatePromoCode(req PromoCodeRequest, createdBy int64) (*models.PromoCode, error)
+	// GetPromoCodes lists the promo codes by code; with activeOnly only the active ones.
+	GetPromoCodes(activeOnly bool) ([]models.PromoCode, error)
+	// UpdatePromoCode changes a promo code; deactivating it stops its redemption.
+	UpdatePromoCode(id int64, req UpdatePromoCodeRequest) (*models.PromoCode, error)
+}
+
+type promoCodeService struct {
+	promoRepo repositories.PromoCodeRepository
+}
+
+// NewPromoCodeService creates a new PromoCodeService.
+func NewPromoCodeService(promoRepo repositories.PromoCodeRepository) PromoCodeService {
+	return &promoCodeService{promoRepo: promoRepo}
+}
+
+// normalizePromoCode upper-cases a promo code as given by a client or staff member.
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validatePromoDiscount checks the discount percent of a promo code.
+func validatePromoDiscount(percent decimal.Decimal) error {
+	if !percent.IsPositive() || percent.GreaterThan(decimal.NewFromInt(100)) {
+		return fmt.Errorf("%w: discount_percent must be above 0 and at most 100", ErrPromoCodeValidation)
+	}
+	return nil
+}
+
+func (s *promoCodeService) CreatePromoCode(req PromoCodeRequest, createdBy int64) (*models.PromoCode, error) {
+	code := normalizePromoCode(req.Code)
+	if !promoCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must be 3 to 30 letters, digits, - or _", ErrPromoCodeValidation)
+	}
+	if err := validatePromoDiscount(req.DiscountPercent); err != nil {
+		return nil, err
+	}
+	if req.IssuedCount < 0 {
+		return nil, fmt.Errorf("%w: issued_count cannot be negative", ErrPromoCodeValidation)
+	}
+	promo := &models.PromoCode{
+		Code:            code,
+		Description:     req.Description,
+		DiscountPercent: req.DiscountPercent,
+		IssuedCount:     req.IssuedCount,
+		Active:          req.Active == nil || *req.Active,
+		CreatedBy:       &createdBy,
+	}
+	if err := s.promoRepo.CreatePromoCode(promo); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrPromoCodeExists
+		}
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return promo, nil
+}
+
+func (s *promoCodeService) GetPromoCodes(activeOnly bool) ([]models.PromoCode, error) {
+	promos, err := s.promoRepo.GetPromoCodes(activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo codes: %w", err)
+	}
+	return promos, nil
+}
+
+func (s *promoCodeService) UpdatePromoCode(id int64, req UpdatePromoCodeRequest) (*models.PromoCode, error) {
+	promo, err := s.promoRepo.GetPromoCodeByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	if req.Description != nil {
+		promo.Description = req.Description
+	}
+	if req.DiscountPercent != nil {
+		if err := validatePromoDiscount(*req.DiscountPercent); err != nil {
+			return nil, err
+		}
+		promo.DiscountPercent = *req.DiscountPercent
+	}
+	if req.IssuedCount != nil {
+		if *req.IssuedCount < 0 {
+			return nil, fmt.Errorf("%w: issued_count cannot be negative", ErrPromoCodeValidation)
+		}
+		promo.IssuedCount = *req.IssuedCount
+	}
+	if req.Active != nil {
+		promo.Active = *req.Active
+	}
+	if err := s.promoRepo.UpdatePromoCode(promo); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to update promo code: %w", err)
+	}
+	return promo, nil
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrPushDeviceNotFound = apperrors.New(utils.ErrCodeNotFound, "push device not found")
+	ErrPushValidation     = apperrors.New(utils.ErrCodeValidationFailed, "push notification validation error")
+	// ErrPushTokenUnregistered is returned by a PushSender for a token that is no longer valid,
+	// e.g. because the app was uninstalled; the device is then removed.
+	ErrPushTokenUnregistered = errors.New("the push token is no longer registered")
+)
+
+var (
+	pushSettings   = models.DefaultPushSettings()
+	pushSettingsMu sync.RWMutex
+)
+
+// SetPushSettings sets the loyalty milestones clients are notified of (the push_notifications setting).
+func SetPushSettings(settings models.PushSettings) {
+	pushSettingsMu.Lock()
+	defer pushSettingsMu.Unlock()
+	pushSettings = settings
+}
+
+// CurrentPushSettings returns the configured push notifications.
+func CurrentPushSettings() models.PushSettings {
+	pushSettingsMu.RLock()
+	defer pushSettingsMu.RUnlock()
+	return pushSettings
+}
+
+// PushSender sends push notifications to devices; push.FCMSender implements it.
+type PushSender interface {
+	// Send pushes message to the device with token. It returns an error matching
+	// ErrPushTokenUnregistered if the token is no longer valid.
+	Send(ctx context.Context, token string, message models.PushMessage) error
+}
+
+// PushEvents are the events HandleEvent pushes notifications for.
+var PushEvents = []string{
+	events.BookingCreated,
+	events.BookingStatusEvent(string(models.BookingStatusConfirmed)),
+	events.TableSessionStarted,
+	events.ClientLoyaltyChanged,
+}
+
+// RegisterPushDeviceRequest is the body of POST /channels/client_app/push-devices.
+type RegisterPushDeviceRequest struct {
+	ClientID int64  `json:"client_id" binding:"required"`
+	Token    string `json:"token" binding:"required"`    // The FCM registration token of the device
+	Platform string `json:"platform" binding:"required"` // One of models.PushPlatforms
+}
+
+// UpdatePushPreferencesRequest is the body of PUT .../push-preferences; fields left out are kept.
+type UpdatePushPreferencesRequest struct {
+	BookingConfirmations *bool `json:"booking_confirmations"`
+	TableReady           *bool `json:"table_ready"`
+	LoyaltyMilestones    *bool `json:"loyalty_milestones"`
+}
+
+// --- PushNotificationService Interface ---
+type PushNotificationService interface {
+	// RegisterDevice stores the token of a device of the client app for req.ClientID. A token
+	// registered before, by the same or another client, moves to req.ClientID.
+	RegisterDevice(req RegisterPushDeviceRequest) (*models.PushDevice, error)
+	// UnregisterDevice forgets a token, e.g. when the client signs out of the app;
+	// ErrPushDeviceNotFound if it is not registered.
+	UnregisterDevice(token string) error
+	GetDevices(clientID int64) ([]models.PushDevice, error)
+	// DeleteDevice removes a device of a client; ErrPushDeviceNotFound if the client has no such device.
+	DeleteDevice(clientID, deviceID int64) error
+
+	// GetPreferences returns the push preferences of a client, the defaults if it saved none.
+	GetPreferences(clientID int64) (*models.PushPreferences, error)
+	UpdatePreferences(clientID int64, req UpdatePushPreferencesRequest) (*models.PushPreferences, error)
+
+	// GetDeliveries lists the pushes sent and attempted, newest first, with their total.
+	GetDeliveries(filters models.PushDeliveryFilters) ([]models.PushDelivery, int, error)
+
+	// HandleEvent pushes the notification of one of PushEvents to the devices of its client,
+	// if the client wants it, and records a delivery per device. Devices the event already
+	// reached are skipped; failed sends are recorded but not retried, as a late "your table is
+	// ready" is worse than none. Without a sender nothing is pushed.
+	HandleEvent(ctx context.Context, event models.DomainEvent) error
+	// PushToClient pushes message to every device of a client regardless of its push
+	// preferences, for notifications the client chose elsewhere such as booking reminders, and
+	// records a delivery per device. It returns how many devices it reached.
+	PushToClient(ctx context.Context, clientID int64, kind string, message models.PushMessage) (int, error)
+}
+
+type pushNotificationService struct {
+	pushRepo   repositories.PushRepository
+	clientRepo repositories.ClientRepository
+	sender     PushSender
+}
+
+// NewPushNotificationService creates a new PushNotificationService. sender may be nil when
+// push notifications are not configured; devices and preferences are still kept.
+func NewPushNotificationService(pushRepo repositories.PushRepository, clientRepo repositories.ClientRepository, sender PushSender) PushNotificationService {
+	return &pushNotificationService{pushRepo: pushRepo, clientRepo: clientRepo, sender: sender}
+}
+
+// checkClient returns ErrClientNotFound for an unknown client and ErrClientAnonymized for an
+// anonymized one.
+func (s *pushNotificationService) checkClient(clientID int64) error {
+	client, err := s.clientRepo.GetClientByID(clientID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrClientNotFound
+		}
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.AnonymizedAt != nil {
+		return ErrClientAnonymized
+	}
+	return nil
+}
+
+func (s *pushNotificationService) RegisterDevice(req RegisterPushDeviceRequest) (*models.PushDevice, error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, fmt.Errorf("%w: token is required", ErrPushValidation)
+	}
+	if !models.IsValidPushPlatform(req.Platform) {
+		return nil, fmt.Errorf("%w: platform must be one of %s", ErrPushValidation, strings.Join(models.PushPlatforms, ", "))
+	}
+	if err := s.checkClient(req.ClientID); err != nil {
+		return nil, err
+	}
+	device := &models.PushDevice{ClientID: req.ClientID, Token: token, Platform: req.Platform}
+	if err := s.pushRepo.SaveDevice(device); err != nil {
+		return nil, fmt.Errorf("failed to register push device: %w", err)
+	}
+	return device, nil
+}
+
+func (s *pushNotificationService) UnregisterDevice(token string) error {
+	if err := s.pushRepo.DeleteDeviceByToken(strings.TrimSpace(token)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrPushDeviceNotFound
+		}
+		return fmt.Errorf("failed to unregister push device: %w", err)
+	}
+	return nil
+}
+
+func (s *pushNotificationService) GetDevices(clientID int64) ([]models.PushDevice, error) {
+	if err := s.checkClient(clientID); err != nil && !errors.Is(err, ErrClientAnonymized) {
+		return nil, err
+	}
+	devices, err := s.pushRepo.GetDevices(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push devices: %w", err)
+	}
+	return devices, nil
+}
+
+func (s *pushNotificationService) DeleteDevice(clientID, deviceID int64) error {
+	if err := s.pushRepo.DeleteDevice(clientID, deviceID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrPushDeviceNotFound
+		}
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+	return nil
+}
+
+// preferences returns the saved preferences of a client or the defaults.
+func (s *pushNotificationService) preferences(clientID int64) (*models.PushPreferences, error) {
+	preferences, err := s.pushRepo.GetPreferences(clientID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		defaults := models.DefaultPushPreferences(clientID)
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+func (s *pushNotificationService) GetPreferences(clientID int64) (*models.PushPreferences, error) {
+	if err := s.checkClient(clientID); err != nil {
+		return nil, err
+	}
+	return s.preferences(clientID)
+}
+
+func (s *pushNotificationService) UpdatePreferences(clientID int64, req UpdatePushPreferencesRequest) (*models.PushPreferences, error) {
+	if err := s.checkClient(clientID); err != nil {
+		return nil, err
+	}
+	preferences, err := s.preferences(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if req.BookingConfirmations != nil {
+		preferences.BookingConfirmations = *req.BookingConfirmations
+	}
+	if req.TableReady != nil {
+		preferences.TableReady = *req.TableReady
+	}
+	if req.LoyaltyMilestones != nil {
+		preferences.LoyaltyMilestones = *req.LoyaltyMilestones
+	}
+	if err := s.pushRepo.SavePreferences(preferences); err != nil {
+		return nil, fmt.Errorf("failed to save push preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+func (s *pushNotificationService) GetDeliveries(filters models.PushDeliveryFilters) ([]models.PushDelivery, int, error) {
+	deliveries, total, err := s.pushRepo.GetDeliveries(filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get push deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+func (s *pushNotificationService) HandleEvent(ctx context.Context, event models.DomainEvent) error {
+	if s.sender == nil {
+		return nil
+	}
+	clientID, kind, message, err := pushNotificationFor(event)
+	if err != nil || clientID == 0 {
+		return err
+	}
+	preferences, err := s.preferences(clientID)
+	if err != nil {
+		return err
+	}
+	if !preferences.Allows(kind) {
+		return nil
+	}
+	devices, err := s.pushRepo.GetDevices(clientID)
+	if err != nil {
+		return fmt.Errorf("failed to get push devices: %w", err)
+	}
+
+	for _, device := range devices {
+		sent, err := s.pushRepo.WasSent(event.ID, device.ID)
+		if err != nil {
+			return err
+		}
+		if sent {
+			continue
+		}
+		eventID := event.ID
+		s.send(ctx, device, &eventID, kind, message)
+	}
+	return nil
+}
+
+func (s *pushNotificationService) PushToClient(ctx context.Context, clientID int64, kind string, message models.PushMessage) (int, error) {
+	if s.sender == nil {
+		return 0, errors.New("push notifications are not configured")
+	}
+	devices, err := s.pushRepo.GetDevices(clientID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get push devices: %w", err)
+	}
+	reached := 0
+	for _, device := range devices {
+		if s.send(ctx, device, nil, kind, message) {
+			reached++
+		}
+	}
+	return reached, nil
+}
+
+// send pushes message to device and records the delivery, removing the device if its token
+// is no longer registered. It reports whether the push was sent.
+func (s *pushNotificationService) send(ctx context.Context, device models.PushDevice, eventID *int64, kind string, message models.PushMessage) bool {
+	deviceID := device.ID
+	delivery := &models.PushDelivery{
+		ClientID: device.ClientID, DeviceID: &deviceID, EventID: eventID, Kind: kind,
+		Title: message.Title, Body: message.Body, Status: models.PushDeliverySent,
+	}
+	if sendErr := s.sender.Send(ctx, device.Token, message); sendErr != nil {
+		errMsg := sendErr.Error()
+		delivery.Error = &errMsg
+		delivery.Status = models.PushDeliveryFailed
+		if errors.Is(sendErr, ErrPushTokenUnregistered) {
+			delivery.Status = models.PushDeliveryUnregistered
+		} else {
+			utils.LogError(sendErr, fmt.Sprintf("Failed to push %s to device ID %d of client ID %d", kind, device.ID, device.ClientID))
+		}
+	}
+	if err := s.pushRepo.CreateDelivery(delivery); err != nil {
+		utils.LogError(err, "Failed to record push delivery")
+	}
+	if delivery.Status == models.PushDeliveryUnregistered {
+		if err := s.pushRepo.DeleteDevice(device.ClientID, device.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			utils.LogError(err, fmt.Sprintf("Failed to remove unregistered push device ID %d", device.ID))
+		}
+	}
+	return delivery.Status == models.PushDeliverySent
+}
+
+// pushNotificationFor returns the client, kind and message of the push notification of event,
+// and a clientID of 0 if it calls for none.
+func pushNotificationFor(event models.DomainEvent) (clientID int64, kind string, message models.PushMessage, err error) {
+	switch event.EventType {
+	case events.BookingCreated, events.BookingStatusEvent(string(models.BookingStatusConfirmed)):
+		var payload events.BookingPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return 0, "", message, fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+		}
+		if payload.ClientID == nil || payload.Status != string(models.BookingStatusConfirmed) {
+			return 0, "", message, nil
+		}
+		return *payload.ClientID, models.PushKindBookingConfirmed, models.PushMessage{
+			Title: "Booking confirmed",
+			Body: fmt.Sprintf("Your table is booked for %s to %s.",
+				utils.FormatClubTime(payload.StartTime, "Mon 2 Jan, 15:04"), utils.FormatClubTime(payload.EndTime, "15:04")),
+			Data: map[string]string{"kind": models.PushKindBookingConfirmed, "booking_id": strconv.FormatInt(payload.BookingID, 10)},
+		}, nil
+
+	case events.TableSessionStarted:
+		var payload events.TableSessionPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return 0, "", message, fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+		}
+		if payload.ClientID == nil {
+			return 0, "", message, nil
+		}
+		return *payload.ClientID, models.PushKindTableReady, models.PushMessage{
+			Title: "Your table is ready",
+			Body:  "Your table is waiting for you. Have a good game!",
+			Data: map[string]string{"kind": models.PushKindTableReady, "session_id": strconv.FormatInt(payload.SessionID, 10),
+				"table_id": strconv.FormatInt(payload.TableID, 10)},
+		}, nil
+
+	case events.ClientLoyaltyChanged:
+		var payload events.LoyaltyPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return 0, "", message, fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+		}
+		milestone, reached := CurrentPushSettings().ReachedMilestone(payload.PreviousPoints, payload.Points)
+		if !reached {
+			return 0, "", message, nil
+		}
+		return payload.ClientID, models.PushKindLoyaltyMilestone, models.PushMessage{
+			Title: "Loyalty milestone reached",
+			Body:  fmt.Sprintf("You have collected %d loyalty points. Thank you for playing with us!", milestone),
+			Data: map[string]string{"kind": models.PushKindLoyaltyMilestone, "milestone": strconv.Itoa(milestone),
+				"points": strconv.Itoa(payload.Points)},
+		}, nil
+	}
+	return 0, "", message, nil
+}
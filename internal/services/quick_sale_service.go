@@ -0,0 +1,237 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrQuickSalePresetNotFound = apperrors.New(utils.ErrCodeNotFound, "quick-sale preset not found")
+	ErrQuickSalePresetExists   = apperrors.New(utils.ErrCodeConflict, "a quick-sale preset with this name already exists")
+	ErrQuickSalePresetInactive = apperrors.New(utils.ErrCodeConflict, "the quick-sale preset is inactive")
+	ErrQuickSaleValidation     = apperrors.New(utils.ErrCodeValidationFailed, "quick-sale preset validation error")
+)
+
+// QuickSalePresetItemRequest is an item of a quick-sale preset.
+type QuickSalePresetItemRequest struct {
+	PricelistItemID int64 `json:"pricelist_item_id" binding:"required"`
+	Quantity        int   `json:"quantity" binding:"required,gt=0"`
+}
+
+// QuickSalePresetRequest is the body of POST and PUT /quick-sale-presets.
+type QuickSalePresetRequest struct {
+	Name      string                       `json:"name" binding:"required,max=100"`
+	SortOrder int                          `json:"sort_order"`
+	IsActive  *bool                        `json:"is_active"` // Defaults to true
+	Items     []QuickSalePresetItemRequest `json:"items" binding:"required,min=1,dive"`
+	Version   *int                         `json:"version"` // Checked on update if set
+}
+
+// QuickOrderRequest is the body of POST /orders/quick/:preset_id.
+type QuickOrderRequest struct {
+	StaffID       int64   `json:"staff_id" binding:"required"`
+	ClientID      *int64  `json:"client_id"`
+	BookingID     *int64  `json:"booking_id"`
+	TableID       *int64  `json:"table_id"`
+	Status        string  `json:"status" binding:"omitempty,order_status"` // Defaults to pending
+	PaymentMethod *string `json:"payment_method"`
+	Notes         *string `json:"notes"`                           // Defaults to the name of the preset
+	Times         int     `json:"times" binding:"omitempty,min=1"` // How many times the preset is sold, default 1
+}
+
+// --- QuickSaleService Interface ---
+type QuickSaleService interface {
+	CreatePreset(req QuickSalePresetRequest) (*models.QuickSalePreset, error)
+	// GetPresets returns the presets of this branch, only the active ones unless includeInactive.
+	GetPresets(includeInactive bool) ([]models.QuickSalePreset, error)
+	GetPreset(id int64) (*models.QuickSalePreset, error)
+	UpdatePreset(id int64, req QuickSalePresetRequest) (*models.QuickSalePreset, error)
+	DeletePreset(id int64) error
+	// BuildOrderRequest returns the order request of selling an active preset req.Times times;
+	// create it like any other order.
+	BuildOrderRequest(presetID int64, req QuickOrderRequest) (*CreateOrderRequest, error)
+}
+
+type quickSaleService struct {
+	quickSaleRepo repositories.QuickSaleRepository
+	pricelistRepo repositories.PricelistRepository
+	db            *sql.DB
+}
+
+// NewQuickSaleService creates a new QuickSaleService.
+func NewQuickSaleService(quickSaleRepo repositories.QuickSaleRepository, pricelistRepo repositories.PricelistRepository, db *sql.DB) QuickSaleService {
+	return &quickSaleService{quickSaleRepo: quickSaleRepo, pricelistRepo: pricelistRepo, db: db}
+}
+
+// presetItems checks the items of req and converts them.
+func (s *quickSaleService) presetItems(req QuickSalePresetRequest) ([]models.QuickSalePresetItem, error) {
+	items := make([]models.QuickSalePresetItem, 0, len(req.Items))
+	seen := make(map[int64]bool, len(req.Items))
+	for _, itemReq := range req.Items {
+		if itemReq.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of item %d must be positive", ErrQuickSaleValidation, itemReq.PricelistItemID)
+		}
+		if seen[itemReq.PricelistItemID] {
+			return nil, fmt.Errorf("%w: item %d is listed more than once", ErrQuickSaleValidation, itemReq.PricelistItemID)
+		}
+		seen[itemReq.PricelistItemID] = true
+		if _, _, _, _, err := s.pricelistRepo.GetItemPriceAndStock(itemReq.PricelistItemID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("%w: pricelist item ID %d not found", ErrQuickSaleValidation, itemReq.PricelistItemID)
+			}
+			return nil, fmt.Errorf("failed to get pricelist item: %w", err)
+		}
+		items = append(items, models.QuickSalePresetItem{PricelistItemID: itemReq.PricelistItemID, Quantity: itemReq.Quantity})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: a preset needs at least one item", ErrQuickSaleValidation)
+	}
+	return items, nil
+}
+
+// savePreset writes preset and its items in a transaction: an insert if it has no ID yet,
+// otherwise an update checked against version.
+func (s *quickSaleService) savePreset(preset *models.QuickSalePreset, items []models.QuickSalePresetItem, version int) (*models.QuickSalePreset, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if preset.ID == 0 {
+		err = s.quickSaleRepo.CreatePreset(tx, preset)
+	} else {
+		err = s.quickSaleRepo.UpdatePreset(tx, preset, version)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrDuplicateKey):
+			return nil, ErrQuickSalePresetExists
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, ErrQuickSalePresetNotFound
+		case errors.Is(err, repositories.ErrVersionConflict):
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to save quick-sale preset: %w", err)
+	}
+	if err := s.quickSaleRepo.ReplacePresetItems(tx, preset.ID, items); err != nil {
+		return nil, fmt.Errorf("failed to save quick-sale preset items: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetPreset(preset.ID)
+}
+
+func (s *quickSaleService) CreatePreset(req QuickSalePresetRequest) (*models.QuickSalePreset, error) {
+	items, err := s.presetItems(req)
+	if err != nil {
+		return nil, err
+	}
+	preset := &models.QuickSalePreset{
+		BranchCode: utils.BranchCode(),
+		Name:       req.Name,
+		SortOrder:  req.SortOrder,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+	return s.savePreset(preset, items, 0)
+}
+
+func (s *quickSaleService) GetPresets(includeInactive bool) ([]models.QuickSalePreset, error) {
+	presets, err := s.quickSaleRepo.GetPresets(utils.BranchCode(), !includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quick-sale presets: %w", err)
+	}
+	return presets, nil
+}
+
+// GetPreset returns a preset of this branch; the presets of other branches are not found.
+func (s *quickSaleService) GetPreset(id int64) (*models.QuickSalePreset, error) {
+	preset, err := s.quickSaleRepo.GetPresetByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrQuickSalePresetNotFound
+		}
+		return nil, fmt.Errorf("failed to get quick-sale preset: %w", err)
+	}
+	if preset.BranchCode != utils.BranchCode() {
+		return nil, ErrQuickSalePresetNotFound
+	}
+	return preset, nil
+}
+
+func (s *quickSaleService) UpdatePreset(id int64, req QuickSalePresetRequest) (*models.QuickSalePreset, error) {
+	preset, err := s.GetPreset(id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.presetItems(req)
+	if err != nil {
+		return nil, err
+	}
+	preset.Name = req.Name
+	preset.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		preset.IsActive = *req.IsActive
+	}
+	version := 0
+	if req.Version != nil {
+		version = *req.Version
+	}
+	return s.savePreset(preset, items, version)
+}
+
+func (s *quickSaleService) DeletePreset(id int64) error {
+	if _, err := s.GetPreset(id); err != nil {
+		return err
+	}
+	if err := s.quickSaleRepo.DeletePreset(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrQuickSalePresetNotFound
+		}
+		return fmt.Errorf("failed to delete quick-sale preset: %w", err)
+	}
+	return nil
+}
+
+func (s *quickSaleService) BuildOrderRequest(presetID int64, req QuickOrderRequest) (*CreateOrderRequest, error) {
+	preset, err := s.GetPreset(presetID)
+	if err != nil {
+		return nil, err
+	}
+	if !preset.IsActive {
+		return nil, ErrQuickSalePresetInactive
+	}
+	times := req.Times
+	if times < 1 {
+		times = 1
+	}
+	status := req.Status
+	if status == "" {
+		status = StatusPending
+	}
+	notes := req.Notes
+	if notes == nil {
+		notes = &preset.Name
+	}
+	orderItems := make([]CreateOrderItemRequest, len(preset.Items))
+	for i, item := range preset.Items {
+		orderItems[i] = CreateOrderItemRequest{PricelistItemID: item.PricelistItemID, Quantity: item.Quantity * times}
+	}
+	return &CreateOrderRequest{
+		ClientID:      req.ClientID,
+		BookingID:     req.BookingID,
+		StaffID:       req.StaffID,
+		TableID:       req.TableID,
+		Status:        status,
+		PaymentMethod: req.PaymentMethod,
+		Notes:         notes,
+		OrderItems:    orderItems,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrReferencedRecordNotFound = apperrors.New(utils.ErrCodeNotFound, "record not found")
+
+// Entities whose references can be checked before they are deleted, named like their routes.
+const (
+	ReferenceEntityClients             = "clients"
+	ReferenceEntityPricelistItems      = "pricelist-items"
+	ReferenceEntityPricelistCategories = "pricelist-categories"
+	ReferenceEntityStaff               = "staff"
+	ReferenceEntityTables              = "tables"
+	ReferenceEntityBookings            = "bookings"
+)
+
+// referenceEntity is the table of an entity, what to do instead of deleting a record of it
+// that cannot be deleted, if anything, and whether its records are soft deleted.
+type referenceEntity struct {
+	table      string
+	suggestion string
+	softDelete bool
+}
+
+var referenceEntities = map[string]referenceEntity{
+	ReferenceEntityClients:             {"clients", "Anonymize the client instead (POST /clients/:id/anonymize), which keeps their orders and bookings.", true},
+	ReferenceEntityPricelistItems:      {"pricelist_items", "Mark the item unavailable instead (is_available: false), which keeps it on past orders.", true},
+	ReferenceEntityPricelistCategories: {"pricelist_categories", "Move its items to another category first.", false},
+	ReferenceEntityStaff:               {"staff_members", "", false},
+	ReferenceEntityTables:              {"game_tables", "Put the table under maintenance instead (PUT /tables/:id/status).", false},
+	ReferenceEntityBookings:            {"bookings", "Cancel the booking instead (PATCH /bookings/:id/cancel).", true},
+}
+
+// --- ReferenceService Interface ---
+type ReferenceService interface {
+	// GetReferences returns the records referencing a record of entity (one of the
+	// ReferenceEntity constants), and whether they keep it from being deleted.
+	GetReferences(entity string, id int64) (*models.RecordReferences, error)
+}
+
+type referenceService struct {
+	referenceRepo repositories.ReferenceRepository
+}
+
+// NewReferenceService creates a new ReferenceService.
+func NewReferenceService(referenceRepo repositories.ReferenceRepository) ReferenceService {
+	return &referenceService{referenceRepo: referenceRepo}
+}
+
+func (s *referenceService) GetReferences(entity string, id int64) (*models.RecordReferences, error) {
+	definition, ok := referenceEntities[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	exists, err := s.referenceRepo.RecordExists(definition.table, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", entity, err)
+	}
+	if !exists {
+		return nil, ErrReferencedRecordNotFound
+	}
+	references, err := s.referenceRepo.CountReferences(definition.table, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count references of %s: %w", entity, err)
+	}
+
+	result := &models.RecordReferences{
+		Entity:     entity,
+		ID:         id,
+		SoftDelete: definition.softDelete,
+		Blocking:   []models.RecordReference{},
+		Affected:   []models.RecordReference{},
+	}
+	for _, reference := range references {
+		if reference.OnDelete == models.ReferenceOnDeleteRestrict {
+			result.Blocking = append(result.Blocking, reference)
+		} else {
+			result.Affected = append(result.Affected, reference)
+		}
+	}
+	result.CanDelete = len(result.Blocking) == 0 || definition.softDelete
+	if !result.CanDelete {
+		result.Suggestion = definition.suggestion
+	}
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrReorderPointsInProgress = apperrors.New(utils.ErrCodeConflict, "the reorder points are already being calculated")
+
+var (
+	reorderPointSettings   = models.DefaultReorderPointSettings()
+	reorderPointSettingsMu sync.RWMutex
+)
+
+// SetReorderPointSettings sets how reorder points are calculated (the reorder_points setting).
+func SetReorderPointSettings(settings models.ReorderPointSettings) {
+	reorderPointSettingsMu.Lock()
+	defer reorderPointSettingsMu.Unlock()
+	reorderPointSettings = settings
+}
+
+// CurrentReorderPointSettings returns how reorder points are calculated.
+func CurrentReorderPointSettings() models.ReorderPointSettings {
+	reorderPointSettingsMu.RLock()
+	defer reorderPointSettingsMu.RUnlock()
+	return reorderPointSettings
+}
+
+// ReorderPointInterval is how often RunCalculation recalculates the reorder points.
+var ReorderPointInterval = 6 * time.Hour
+
+const (
+	reorderPointLockKey = "reorder_points:calculate"
+	// reorderPointTimeout bounds how long a crashed instance keeps the calculation lock.
+	reorderPointTimeout = 10 * time.Minute
+)
+
+// --- ReorderPointService Interface ---
+type ReorderPointService interface {
+	// GetReorderPoints returns the last calculated reorder point of each stock-tracked item.
+	GetReorderPoints() ([]models.ReorderPoint, error)
+	// Calculate recalculates the reorder points now from the consumption of the items, setting
+	// their low stock thresholds if the reorder_points setting auto-updates them. Only one
+	// calculation runs at a time across instances; ErrReorderPointsInProgress otherwise.
+	Calculate(ctx context.Context) ([]models.ReorderPoint, error)
+	// RunCalculation recalculates the reorder points at start and every ReorderPointInterval
+	// until ctx is done. Every instance may run it; a calculation running elsewhere is skipped.
+	RunCalculation(ctx context.Context)
+}
+
+type reorderPointService struct {
+	reorderPointRepo repositories.ReorderPointRepository
+	store            kvstore.Store
+	db               *sql.DB
+}
+
+// NewReorderPointService creates a new ReorderPointService.
+func NewReorderPointService(reorderPointRepo repositories.ReorderPointRepository, store kvstore.Store, db *sql.DB) ReorderPointService {
+	return &reorderPointService{reorderPointRepo: reorderPointRepo, store: store, db: db}
+}
+
+func (s *reorderPointService) GetReorderPoints() ([]models.ReorderPoint, error) {
+	points, err := s.reorderPointRepo.GetReorderPoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reorder points: %w", err)
+	}
+	return points, nil
+}
+
+// consumptionOver returns how much of consumed over lookbackDays falls on days, rounded up to
+// whole stock units.
+func consumptionOver(consumed models.Quantity, lookbackDays, days int) models.Quantity {
+	return models.NewQuantity(consumed.MulInt(days).Decimal().Div(decimal.NewFromInt(int64(lookbackDays)))).Ceil()
+}
+
+// calculateReorderPoint sets the suggestions of point, whose consumption is set, under settings.
+func calculateReorderPoint(point *models.ReorderPoint, settings models.ReorderPointSettings, now time.Time) {
+	if point.Consumed.IsNegative() {
+		point.Consumed = models.ZeroQuantity // More returned than sold
+	}
+	point.LookbackDays = settings.LookbackDays
+	point.LeadTimeDays = settings.LeadTimeDays
+	point.SafetyDays = settings.SafetyDays
+	point.CoverDays = settings.CoverDays
+	point.AverageDailyConsumption = point.Consumed.Decimal().Div(decimal.NewFromInt(int64(settings.LookbackDays))).Round(4)
+	point.SuggestedThreshold = consumptionOver(point.Consumed, settings.LookbackDays, settings.LeadTimeDays+settings.SafetyDays)
+	point.SuggestedReorderQuantity = consumptionOver(point.Consumed, settings.LookbackDays, settings.CoverDays)
+	point.CalculatedAt = now
+}
+
+func (s *reorderPointService) Calculate(ctx context.Context) ([]models.ReorderPoint, error) {
+	locked, err := s.store.SetNX(ctx, reorderPointLockKey, "1", reorderPointTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the reorder point calculation: %w", err)
+	}
+	if !locked {
+		return nil, ErrReorderPointsInProgress
+	}
+	defer func() {
+		if err := s.store.Delete(context.Background(), reorderPointLockKey); err != nil {
+			utils.LogError(err, "Failed to release the reorder point calculation lock")
+		}
+	}()
+
+	settings := CurrentReorderPointSettings()
+	now := utils.NowUTC()
+	points, err := s.reorderPointRepo.GetItemConsumption(now.AddDate(0, 0, -settings.LookbackDays), ConsumptionMovementTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item consumption: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	applied := 0
+	for i := range points {
+		point := &points[i]
+		calculateReorderPoint(point, settings, now)
+		// Items without consumption keep the threshold they were given, e.g. new ones
+		if settings.AutoUpdate && point.Consumed.IsPositive() &&
+			(point.LowStockThreshold == nil || point.LowStockThreshold.Cmp(point.SuggestedThreshold) != 0) {
+			err := s.reorderPointRepo.SetLowStockThreshold(tx, point.PricelistItemID, point.SuggestedThreshold)
+			if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+				return nil, fmt.Errorf("failed to set low stock threshold: %w", err)
+			}
+			if err == nil {
+				threshold := point.SuggestedThreshold
+				point.LowStockThreshold = &threshold
+				point.AppliedAt = &now
+				applied++
+			}
+		}
+		if err := s.reorderPointRepo.SaveReorderPoint(tx, point); err != nil {
+			return nil, fmt.Errorf("failed to save reorder point: %w", err)
+		}
+	}
+	if err := s.reorderPointRepo.DeleteReorderPointsCalculatedBefore(tx, now); err != nil {
+		return nil, fmt.Errorf("failed to delete stale reorder points: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reorder points: %w", err)
+	}
+	if applied > 0 {
+		utils.LogInfo("Updated low stock thresholds to the reorder points", map[string]interface{}{"items": applied})
+	}
+
+	// Listed like GetReorderPoints, with when each was last applied
+	return s.GetReorderPoints()
+}
+
+func (s *reorderPointService) RunCalculation(ctx context.Context) {
+	ticker := time.NewTicker(ReorderPointInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Calculate(ctx); err != nil && !errors.Is(err, ErrReorderPointsInProgress) {
+			utils.LogError(err, "Failed to calculate the reorder points")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
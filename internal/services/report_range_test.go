@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories/mocks"
+	"ps_club_backend/pkg/utils"
+)
+
+func TestParseReportRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   string
+		period     string
+		wantPeriod string
+		wantErr    bool
+	}{
+		{name: "daily by default", from: "2024-06-01", to: "2024-06-30", wantPeriod: PeriodDaily},
+		{name: "a single day", from: "2024-06-01", to: "2024-06-01", period: PeriodWeekly, wantPeriod: PeriodWeekly},
+		{name: "the longest range", from: "2024-01-01", to: "2024-12-31", period: PeriodMonthly, wantPeriod: PeriodMonthly},
+		{name: "one day too long", from: "2024-01-01", to: "2025-01-01", wantErr: true},
+		{name: "reversed", from: "2024-06-30", to: "2024-06-01", wantErr: true},
+		{name: "no from", to: "2024-06-30", wantErr: true},
+		{name: "no to", from: "2024-06-01", wantErr: true},
+		{name: "from not a date", from: "01.06.2024", to: "2024-06-30", wantErr: true},
+		{name: "to a date-time", from: "2024-06-01", to: "2024-06-30T00:00:00Z", wantErr: true},
+		{name: "unknown period", from: "2024-06-01", to: "2024-06-30", period: "yearly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, period, err := parseReportRange(tt.from, tt.to, tt.period)
+			if tt.wantErr {
+				if !errors.Is(err, ErrReportValidation) {
+					t.Errorf("parseReportRange(%q, %q, %q) error = %v, want %v", tt.from, tt.to, tt.period, err, ErrReportValidation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReportRange(%q, %q, %q): %v", tt.from, tt.to, tt.period, err)
+			}
+			if period != tt.wantPeriod {
+				t.Errorf("period = %q, want %q", period, tt.wantPeriod)
+			}
+			if got := utils.FormatClubTime(start, utils.DateLayout); got != tt.from {
+				t.Errorf("start = %s, want the start of %s", start, tt.from)
+			}
+			if got := utils.FormatClubTime(end, utils.DateLayout); got != tt.to {
+				t.Errorf("end = %s, want the start of %s", end, tt.to)
+			}
+		})
+	}
+}
+
+func TestGetVoidReportRange(t *testing.T) {
+	var gotFrom, gotTo time.Time
+	reportRepo := &mocks.MockReportRepository{
+		GetVoidsFunc: func(_ string, from, to time.Time) ([]models.OrderVoid, error) {
+			gotFrom, gotTo = from, to
+			return nil, nil
+		},
+	}
+	svc := NewReportService(reportRepo, nil, nil, nil, nil)
+	day := func(date string) *time.Time {
+		t.Helper()
+		parsed, err := utils.ParseClubDate(date)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", date, err)
+		}
+		return &parsed
+	}
+
+	// By default the DefaultVoidReportDays before now
+	before := utils.NowUTC()
+	if _, err := svc.GetVoidReport(utils.TimeRange{}); err != nil {
+		t.Fatalf("GetVoidReport without a range: %v", err)
+	}
+	if gotTo.Before(before) || gotTo.After(utils.NowUTC()) {
+		t.Errorf("default range ends at %s, want now", gotTo)
+	}
+	if want := utils.AddClubDays(gotTo, -DefaultVoidReportDays); !gotFrom.Equal(want) {
+		t.Errorf("default range starts at %s, want %s", gotFrom, want)
+	}
+
+	// A range given is used as it is
+	from, to := day("2024-06-01"), day("2024-06-08")
+	if _, err := svc.GetVoidReport(utils.TimeRange{From: from, To: to}); err != nil {
+		t.Fatalf("GetVoidReport for a week: %v", err)
+	}
+	if !gotFrom.Equal(*from) || !gotTo.Equal(*to) {
+		t.Errorf("range = [%s, %s), want [%s, %s)", gotFrom, gotTo, from, to)
+	}
+
+	// The longest range is accepted, a longer or reversed one refused before querying
+	longest := from.Add(MaxVoidReportDays * 24 * time.Hour)
+	if _, err := svc.GetVoidReport(utils.TimeRange{From: from, To: &longest}); err != nil {
+		t.Errorf("GetVoidReport for %d days: %v", MaxVoidReportDays, err)
+	}
+	reportRepo.GetVoidsFunc = nil // Panics if called
+	tooLong := longest.Add(time.Hour)
+	for name, timeRange := range map[string]utils.TimeRange{
+		"too long": {From: from, To: &tooLong},
+		"reversed": {From: to, To: from},
+		"empty":    {From: from, To: from},
+	} {
+		if _, err := svc.GetVoidReport(timeRange); !errors.Is(err, ErrReportValidation) {
+			t.Errorf("GetVoidReport %s range error = %v, want %v", name, err, ErrReportValidation)
+		}
+	}
+}
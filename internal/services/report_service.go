@@ -0,0 +1,934 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+// Activity feed settings.
+const (
+	DefaultActivityLimit = 20
+	MaxActivityLimit     = 100
+)
+
+// Period report settings.
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+
+	MaxPeriodReportDays = 366 // Bounds the days a report may backfill
+)
+
+// Metrics of the period comparison.
+const (
+	MetricRevenue      = "revenue"       // Sales and stopped table sessions, like the daily summaries
+	MetricSales        = "sales"         // Final amounts of the orders
+	MetricSessions     = "sessions"      // Totals of the stopped table sessions
+	MetricOrders       = "orders"        // Number of orders
+	MetricAverageCheck = "average_check" // Sales per order
+)
+
+// CompareMetrics lists the metrics the periods can be compared by.
+var CompareMetrics = []string{MetricRevenue, MetricSales, MetricSessions, MetricOrders, MetricAverageCheck}
+
+// Granularities of the period comparison.
+const (
+	GranularityDay  = "day"
+	GranularityHour = "hour"
+
+	MaxHourlyCompareDays = 14 // Bounds the points of an hourly comparison
+)
+
+// Staffing report settings.
+const (
+	DefaultTablesPerStaff = 4.0 // Occupied tables a staff member on shift looks after
+	DefaultStaffingDays   = 30  // Days before today the staffing report covers by default
+	MaxStaffingReportDays = 92
+)
+
+// Void report settings.
+const (
+	DefaultVoidReportDays = 30 // Days before today the void report covers by default
+	MaxVoidReportDays     = 92
+)
+
+var ErrReportValidation = apperrors.New(utils.ErrCodeValidationFailed, "report validation error")
+
+// activityEventTypes are the significant events shown in the activity feed.
+var activityEventTypes = []string{
+	events.BookingCreated,
+	events.OrderCompleted,
+	events.OrderDiscounted,
+	events.InventoryWrittenOff,
+	events.StaffClockedIn,
+}
+
+// --- ReportService Interface ---
+type ReportService interface {
+	GetDashboardSummary() (*models.DashboardSummary, error)
+	// GetActivityFeed returns recent significant events, newest first. Pass the
+	// NextCursor of a page as cursor to get the following page ("" for the first).
+	GetActivityFeed(cursor string, limit int) (*models.ActivityFeed, error)
+	// GetPeriodReport totals the business days from startDate to endDate (YYYY-MM-DD, inclusive)
+	// per day, ISO week or month, latest first. Past days are read from their daily summaries;
+	// the summaries of days that were never closed are backfilled, and today is computed live.
+	GetPeriodReport(startDate, endDate, period string) ([]models.PeriodReportItem, error)
+	// GetTaxReport totals the sales from startDate to endDate (YYYY-MM-DD, inclusive) per day,
+	// ISO week or month and tax class and rate, with the tax computed when each order was created.
+	GetTaxReport(startDate, endDate, period string) ([]models.TaxReportItem, error)
+	// ComparePeriods aligns the series of a metric (one of CompareMetrics, default revenue) per
+	// day or hour over periods A and B, e.g. this week and last week, with the change from B to
+	// A at each point and in total. A zero periodB is the period of the same length right
+	// before periodA.
+	ComparePeriods(metric, granularity string, periodA, periodB utils.TimeRange) (*models.PeriodComparison, error)
+	// GetStaffingReport joins the hourly table occupancy with the staff on shift over a time
+	// range (default the DefaultStaffingDays before today), reporting the hours in which the
+	// occupied tables per staff member exceeded threshold (default DefaultTablesPerStaff) and
+	// the hours of the week that were over it at least every other week, where shifts should be
+	// added.
+	GetStaffingReport(timeRange utils.TimeRange, threshold float64) (*models.StaffingReport, error)
+	// GetVoidReport totals the orders cancelled and items voided over a time range (default the
+	// DefaultVoidReportDays before today up to now) by the staff member who voided, by reason
+	// and by the shift they were clocked in for.
+	GetVoidReport(timeRange utils.TimeRange) (*models.VoidReport, error)
+	// GetDiscountReport totals the sales from startDate to endDate (YYYY-MM-DD, inclusive) and
+	// their discounts by promo code, by the staff member who took the orders and per day, ISO
+	// week or month, with the share of the orders discounted, the revenue forgone and the
+	// redemptions of each code against the number issued.
+	GetDiscountReport(startDate, endDate, period string) (*models.DiscountReport, error)
+	// GetSourceReport totals the sales and the bookings from startDate to endDate (YYYY-MM-DD,
+	// inclusive) by the channel they were placed through: the POS, the kiosk, the client app
+	// and the Telegram bot, with each source's share of the revenue.
+	GetSourceReport(startDate, endDate string) (*models.SourceReport, error)
+}
+
+// --- reportService Implementation ---
+type reportService struct {
+	reportRepo      repositories.ReportRepository
+	dayCloseRepo    repositories.DayCloseRepository
+	shiftReportRepo repositories.ShiftReportRepository
+	noteRepo        repositories.CalendarNoteRepository // Overlays the calendar notes on the reports
+	db              *sql.DB
+}
+
+// NewReportService creates a new instance of ReportService.
+func NewReportService(reportRepo repositories.ReportRepository, dayCloseRepo repositories.DayCloseRepository,
+	shiftReportRepo repositories.ShiftReportRepository, noteRepo repositories.CalendarNoteRepository, db *sql.DB) ReportService {
+	return &reportService{
+		reportRepo:      reportRepo,
+		dayCloseRepo:    dayCloseRepo,
+		shiftReportRepo: shiftReportRepo,
+		noteRepo:        noteRepo,
+		db:              db,
+	}
+}
+
+// GetDashboardSummary returns the key metrics for the dashboard as of now.
+func (s *reportService) GetDashboardSummary() (*models.DashboardSummary, error) {
+	return s.reportRepo.GetDashboardSummary(utils.NowUTC())
+}
+
+func (s *reportService) GetActivityFeed(cursor string, limit int) (*models.ActivityFeed, error) {
+	if limit <= 0 {
+		limit = DefaultActivityLimit
+	}
+	if limit > MaxActivityLimit {
+		limit = MaxActivityLimit
+	}
+	filter := models.ActivityFilter{
+		EventTypes:           activityEventTypes,
+		DiscountEventType:    events.OrderDiscounted,
+		LargeDiscountPercent: LargeDiscountPercent,
+		Limit:                limit + 1, // One extra row tells whether there is a next page
+	}
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	domainEvents, err := s.reportRepo.GetActivityEvents(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity events: %w", err)
+	}
+
+	feed := &models.ActivityFeed{Data: []models.ActivityItem{}}
+	if len(domainEvents) > limit {
+		domainEvents = domainEvents[:limit]
+		last := domainEvents[limit-1]
+		next := encodeCursor(models.Cursor{Time: last.CreatedAt, ID: last.ID})
+		feed.NextCursor = &next
+	}
+	for _, event := range domainEvents {
+		feed.Data = append(feed.Data, models.ActivityItem{
+			ID:            event.ID,
+			Type:          event.EventType,
+			OccurredAt:    event.CreatedAt,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Summary:       activitySummary(event),
+			Data:          event.Payload,
+		})
+	}
+	return feed, nil
+}
+
+// parseReportRange validates the dates (YYYY-MM-DD, inclusive) and period of a report, and
+// returns the starts of the first and last business days and the period, daily by default.
+func parseReportRange(startDate, endDate, period string) (time.Time, time.Time, string, error) {
+	if period == "" {
+		period = PeriodDaily
+	}
+	if period != PeriodDaily && period != PeriodWeekly && period != PeriodMonthly {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: period must be daily, weekly or monthly", ErrReportValidation)
+	}
+	if startDate == "" || endDate == "" {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: the report needs from and to, or a range", ErrReportValidation)
+	}
+	start, err := utils.ParseClubDate(startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: from must be YYYY-MM-DD", ErrReportValidation)
+	}
+	end, err := utils.ParseClubDate(endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: to must be YYYY-MM-DD", ErrReportValidation)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: to is before from", ErrReportValidation)
+	}
+	if end.Sub(start) >= MaxPeriodReportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: the report covers at most %d days", ErrReportValidation, MaxPeriodReportDays)
+	}
+	return start, end, period, nil
+}
+
+func (s *reportService) GetPeriodReport(startDate, endDate, period string) ([]models.PeriodReportItem, error) {
+	start, end, period, err := parseReportRange(startDate, endDate, period)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.dayCloseRepo.GetSummaries(utils.BranchCode(), startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+	byDate := make(map[string]*models.DailySummary, len(stored))
+	for i := range stored {
+		byDate[stored[i].BusinessDate] = &stored[i]
+	}
+	notes, err := s.noteRepo.GetNotes(utils.BranchCode(), startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar notes: %w", err)
+	}
+
+	today := utils.FormatClubTime(utils.NowUTC(), utils.DateLayout)
+	var items []models.PeriodReportItem
+	for day := start; !day.After(end); _, day = utils.DayBounds(day) {
+		date := utils.FormatClubTime(day, utils.DateLayout)
+		if date > today {
+			break
+		}
+		summary := byDate[date]
+		if summary == nil {
+			if summary, err = s.summarizeDay(date, day, date == today); err != nil {
+				return nil, err
+			}
+		}
+		key := periodKey(day, period)
+		if len(items) == 0 || items[len(items)-1].Period != key {
+			items = append(items, models.PeriodReportItem{Period: key, CategorySales: []models.CategorySales{}})
+		}
+		addToPeriod(&items[len(items)-1], summary)
+		items[len(items)-1].Notes = appendNotes(items[len(items)-1].Notes, notesCovering(notes, date))
+	}
+	slices.Reverse(items)
+	if items == nil {
+		items = []models.PeriodReportItem{}
+	}
+	return items, nil
+}
+
+func (s *reportService) GetTaxReport(startDate, endDate, period string) ([]models.TaxReportItem, error) {
+	start, end, period, err := parseReportRange(startDate, endDate, period)
+	if err != nil {
+		return nil, err
+	}
+	_, endOfRange := utils.DayBounds(end)
+	items, err := s.reportRepo.GetTaxSummary(models.TaxReportFilter{
+		BranchCode: utils.BranchCode(),
+		Start:      start,
+		End:        endOfRange,
+		Period:     period,
+		Statuses:   ShiftSalesOrderStatuses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax summary: %w", err)
+	}
+	return items, nil
+}
+
+// summarizeDay computes the summary of a business day that has none. A past day's summary is
+// saved as backfilled, so the next report reads it; today's is not, as the day goes on.
+func (s *reportService) summarizeDay(date string, start time.Time, isToday bool) (*models.DailySummary, error) {
+	_, end := utils.DayBounds(start)
+	summary, err := buildDailySummary(s.db, s.dayCloseRepo, s.shiftReportRepo, date, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize %s: %w", date, err)
+	}
+	if isToday {
+		return summary, nil
+	}
+	summary.Backfilled = true
+	if err := s.dayCloseRepo.CreateSummary(s.db, summary); err != nil {
+		if !errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, fmt.Errorf("failed to save backfilled summary of %s: %w", date, err)
+		}
+		// Closed or backfilled concurrently; report the saved summary
+		if summary, err = s.dayCloseRepo.GetSummary(summary.BranchCode, date); err != nil {
+			return nil, fmt.Errorf("failed to get daily summary of %s: %w", date, err)
+		}
+	}
+	return summary, nil
+}
+
+// periodKey returns the period of the business day starting at day, formatted like the sales report.
+func periodKey(day time.Time, period string) string {
+	local := day.In(utils.ClubLocation())
+	switch period {
+	case PeriodWeekly:
+		year, week := local.ISOWeek()
+		return fmt.Sprintf("%04d-%02d", year, week)
+	case PeriodMonthly:
+		return local.Format(utils.MonthLayout)
+	}
+	return local.Format(utils.DateLayout)
+}
+
+// addToPeriod adds the totals of a daily summary to item.
+func addToPeriod(item *models.PeriodReportItem, summary *models.DailySummary) {
+	item.Days++
+	item.Revenue = item.Revenue.Add(summary.Revenue)
+	item.OrderCount += summary.OrderCount
+	item.SalesTotal = item.SalesTotal.Add(summary.SalesTotal)
+	item.SessionTotal = item.SessionTotal.Add(summary.SessionTotal)
+	item.HoursBooked += summary.HoursBooked
+	for _, sales := range summary.CategorySales {
+		i := slices.IndexFunc(item.CategorySales, func(c models.CategorySales) bool {
+			return (c.CategoryID == nil && sales.CategoryID == nil) ||
+				(c.CategoryID != nil && sales.CategoryID != nil && *c.CategoryID == *sales.CategoryID)
+		})
+		if i < 0 {
+			item.CategorySales = append(item.CategorySales, sales)
+			continue
+		}
+		item.CategorySales[i].Quantity += sales.Quantity
+		item.CategorySales[i].Amount = item.CategorySales[i].Amount.Add(sales.Amount)
+	}
+	slices.SortStableFunc(item.CategorySales, func(a, b models.CategorySales) int { return b.Amount.Cmp(a.Amount) })
+}
+
+// activitySummary renders a one-line description of an activity feed event.
+func activitySummary(event models.DomainEvent) string {
+	switch event.EventType {
+	case events.BookingCreated:
+		var p events.BookingPayload
+		if json.Unmarshal(event.Payload, &p) == nil {
+			return fmt.Sprintf("New booking #%d for table %d, %s–%s", p.BookingID, p.TableID,
+				utils.FormatClubTime(p.StartTime, "2006-01-02 15:04"), utils.FormatClubTime(p.EndTime, "15:04"))
+		}
+	case events.OrderCompleted:
+		var p events.OrderPayload
+		if json.Unmarshal(event.Payload, &p) == nil {
+			return fmt.Sprintf("Order %s completed: %s", p.OrderNumber, p.FinalAmount)
+		}
+	case events.OrderDiscounted:
+		var p events.OrderPayload
+		if json.Unmarshal(event.Payload, &p) == nil && p.DiscountAmount != nil && p.TotalAmount.IsPositive() {
+			percent := p.DiscountAmount.Decimal().Mul(decimal.NewFromInt(100)).Div(p.TotalAmount.Decimal()).Round(0)
+			return fmt.Sprintf("Discount of %s (%s%%) on order %s", *p.DiscountAmount, percent, p.OrderNumber)
+		}
+	case events.InventoryWrittenOff:
+		var p events.InventoryPayload
+		if json.Unmarshal(event.Payload, &p) == nil {
+			movementType := strings.ReplaceAll(p.MovementType, "_", " ")
+			if p.StockUnit != "" && p.StockUnit != models.UnitPieces {
+				return fmt.Sprintf("Written off %s %s of %s (%s)", p.Quantity, p.StockUnit, p.ItemName, movementType)
+			}
+			return fmt.Sprintf("Written off %s × %s (%s)", p.Quantity, p.ItemName, movementType)
+		}
+	case events.StaffClockedIn:
+		var p events.TimeClockPayload
+		if json.Unmarshal(event.Payload, &p) == nil {
+			return fmt.Sprintf("%s clocked in", p.StaffName)
+		}
+	}
+	return event.EventType
+}
+
+// periodNotes returns the calendar notes of the days a compared period touches.
+func (s *reportService) periodNotes(period utils.TimeRange) ([]models.CalendarNote, error) {
+	fromDate, toDate := period.ClubDates()
+	notes, err := s.noteRepo.GetNotes(utils.BranchCode(), fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar notes: %w", err)
+	}
+	return notes, nil
+}
+
+// comparedBucket totals the orders or table sessions of a day or hour of a compared period.
+type comparedBucket struct {
+	key    string // Like models.SeriesBucket
+	future bool   // Starts after now, so it has no value yet
+	count  int
+	amount models.Money
+}
+
+func (s *reportService) ComparePeriods(metric, granularity string, periodA, periodB utils.TimeRange) (*models.PeriodComparison, error) {
+	if metric == "" {
+		metric = MetricRevenue
+	} else if !slices.Contains(CompareMetrics, metric) {
+		return nil, fmt.Errorf("%w: metric must be one of %s", ErrReportValidation, strings.Join(CompareMetrics, ", "))
+	}
+	if granularity == "" {
+		granularity = GranularityDay
+	} else if granularity != GranularityDay && granularity != GranularityHour {
+		return nil, fmt.Errorf("%w: granularity must be day or hour", ErrReportValidation)
+	}
+	if periodA.From == nil || periodA.To == nil {
+		return nil, fmt.Errorf("%w: period_a needs a start and an end", ErrReportValidation)
+	}
+	if periodB.IsZero() {
+		length := periodA.To.Sub(*periodA.From)
+		from := periodA.From.Add(-length)
+		periodB = utils.TimeRange{From: &from, To: periodA.From}
+	} else if periodB.From == nil || periodB.To == nil {
+		return nil, fmt.Errorf("%w: period_b needs a start and an end", ErrReportValidation)
+	}
+	maxDays := MaxPeriodReportDays
+	if granularity == GranularityHour {
+		maxDays = MaxHourlyCompareDays
+	}
+	for _, period := range []utils.TimeRange{periodA, periodB} {
+		if period.To.Sub(*period.From) > time.Duration(maxDays)*24*time.Hour {
+			return nil, fmt.Errorf("%w: a period covers at most %d days per %s", ErrReportValidation, maxDays, granularity)
+		}
+	}
+
+	now := utils.NowUTC()
+	bucketsA, err := s.comparedBuckets(metric, granularity == GranularityHour, periodA, now)
+	if err != nil {
+		return nil, err
+	}
+	bucketsB, err := s.comparedBuckets(metric, granularity == GranularityHour, periodB, now)
+	if err != nil {
+		return nil, err
+	}
+
+	notesA, err := s.periodNotes(periodA)
+	if err != nil {
+		return nil, err
+	}
+	notesB, err := s.periodNotes(periodB)
+	if err != nil {
+		return nil, err
+	}
+
+	totalA, totalB := bucketsTotal(metric, bucketsA), bucketsTotal(metric, bucketsB)
+	comparison := &models.PeriodComparison{
+		Metric:      metric,
+		Granularity: granularity,
+		PeriodA:     models.ComparedPeriod{From: *periodA.From, To: *periodA.To, Total: metricFloat(metric, totalA), Notes: notesA},
+		PeriodB:     models.ComparedPeriod{From: *periodB.From, To: *periodB.To, Total: metricFloat(metric, totalB), Notes: notesB},
+		Delta:       metricFloat(metric, totalA.Sub(totalB)),
+		DeltaPct:    deltaPercent(totalA, totalB),
+		Points:      make([]models.ComparisonPoint, max(len(bucketsA), len(bucketsB))),
+	}
+	for i := range comparison.Points {
+		point := &comparison.Points[i]
+		point.Offset = i
+		var valueA, valueB *decimal.Decimal
+		if i < len(bucketsA) {
+			point.BucketA = &bucketsA[i].key
+			if !bucketsA[i].future {
+				value := metricValue(metric, bucketsA[i].count, bucketsA[i].amount)
+				valueA, point.ValueA = &value, floatPtr(metricFloat(metric, value))
+			}
+		}
+		if i < len(bucketsB) {
+			point.BucketB = &bucketsB[i].key
+			if !bucketsB[i].future {
+				value := metricValue(metric, bucketsB[i].count, bucketsB[i].amount)
+				valueB, point.ValueB = &value, floatPtr(metricFloat(metric, value))
+			}
+		}
+		if valueA != nil && valueB != nil {
+			point.Delta = floatPtr(metricFloat(metric, valueA.Sub(*valueB)))
+			point.DeltaPct = deltaPercent(*valueA, *valueB)
+		}
+	}
+	return comparison, nil
+}
+
+// comparedBuckets returns the days or hours of period in order, totalling the orders and
+// table sessions of the metric in each.
+func (s *reportService) comparedBuckets(metric string, hourly bool, period utils.TimeRange, now time.Time) ([]comparedBucket, error) {
+	filter := models.SeriesFilter{
+		BranchCode: utils.BranchCode(),
+		Start:      *period.From,
+		End:        *period.To,
+		Hourly:     hourly,
+		Statuses:   ShiftSalesOrderStatuses,
+	}
+	totals := map[string]models.SeriesBucket{}
+	if metric != MetricSessions {
+		sales, err := s.reportRepo.GetSalesSeries(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sales series: %w", err)
+		}
+		for _, bucket := range sales {
+			totals[bucket.Bucket] = bucket
+		}
+	}
+	if metric == MetricRevenue || metric == MetricSessions {
+		sessions, err := s.reportRepo.GetSessionSeries(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get table session series: %w", err)
+		}
+		for _, bucket := range sessions {
+			total := totals[bucket.Bucket]
+			total.Amount = total.Amount.Add(bucket.Amount)
+			totals[bucket.Bucket] = total
+		}
+	}
+
+	start, layout := utils.StartOfDay(*period.From), utils.DateLayout
+	if hourly {
+		start, layout = period.From.Truncate(time.Hour), "2006-01-02T15:00"
+	}
+	var buckets []comparedBucket
+	for t := start; t.Before(*period.To); {
+		key := utils.FormatClubTime(t, layout)
+		// The hour repeated when DST ends is one bucket
+		if len(buckets) == 0 || buckets[len(buckets)-1].key != key {
+			total := totals[key]
+			buckets = append(buckets, comparedBucket{key: key, future: t.After(now), count: total.Count, amount: total.Amount})
+		}
+		if hourly {
+			t = t.Add(time.Hour)
+		} else {
+			t = utils.AddClubDays(t, 1)
+		}
+	}
+	return buckets, nil
+}
+
+// bucketsTotal returns the value of metric over the buckets that are not in the future.
+func bucketsTotal(metric string, buckets []comparedBucket) decimal.Decimal {
+	count, amount := 0, models.ZeroMoney
+	for _, bucket := range buckets {
+		if !bucket.future {
+			count += bucket.count
+			amount = amount.Add(bucket.amount)
+		}
+	}
+	return metricValue(metric, count, amount)
+}
+
+// metricValue returns the value of metric for count orders totalling amount.
+func metricValue(metric string, count int, amount models.Money) decimal.Decimal {
+	switch metric {
+	case MetricOrders:
+		return decimal.NewFromInt(int64(count))
+	case MetricAverageCheck:
+		if count == 0 {
+			return decimal.Zero
+		}
+		return amount.Decimal().Div(decimal.NewFromInt(int64(count)))
+	}
+	return amount.Decimal()
+}
+
+// metricFloat renders a value of metric as a number for charting, rounded to the currency
+// decimals unless it is a count.
+func metricFloat(metric string, value decimal.Decimal) float64 {
+	if metric == MetricOrders {
+		return value.InexactFloat64()
+	}
+	return value.Round(int32(utils.CurrentCurrency().Decimals)).InexactFloat64()
+}
+
+// deltaPercent returns the change from b to a as a percentage of b, to one decimal; nil if b is 0.
+func deltaPercent(a, b decimal.Decimal) *float64 {
+	if b.IsZero() {
+		return nil
+	}
+	return floatPtr(a.Sub(b).Mul(decimal.NewFromInt(100)).Div(b.Abs()).Round(1).InexactFloat64())
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func (s *reportService) GetStaffingReport(timeRange utils.TimeRange, threshold float64) (*models.StaffingReport, error) {
+	if threshold == 0 {
+		threshold = DefaultTablesPerStaff
+	} else if threshold < 0 {
+		return nil, fmt.Errorf("%w: threshold must be positive", ErrReportValidation)
+	}
+	now := utils.NowUTC()
+	today := utils.StartOfDay(now)
+	from, to := utils.AddClubDays(today, -DefaultStaffingDays), today
+	if timeRange.From != nil {
+		from = *timeRange.From
+	}
+	if timeRange.To != nil {
+		to = *timeRange.To
+	} else if timeRange.From != nil {
+		to = now
+	}
+	// Whole hours, up to the current one
+	from = from.Truncate(time.Hour)
+	if to.After(now) {
+		to = now
+	}
+	if !to.Truncate(time.Hour).Equal(to) {
+		to = to.Truncate(time.Hour).Add(time.Hour)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: the range must end after it starts and start before now", ErrReportValidation)
+	}
+	if to.Sub(from) > MaxStaffingReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: the report covers at most %d days", ErrReportValidation, MaxStaffingReportDays)
+	}
+
+	hours, err := s.reportRepo.GetHourlyUtilization(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly utilization: %w", err)
+	}
+
+	type slot struct{ weekday, hour int }
+	type slotTotals struct {
+		over, total     int
+		occupied, staff float64
+	}
+	slots := map[slot]*slotTotals{}
+	report := &models.StaffingReport{
+		From:            from,
+		To:              to,
+		Threshold:       threshold,
+		Hours:           []models.StaffingHour{},
+		Recommendations: []models.StaffingRecommendation{},
+	}
+	for _, hour := range hours {
+		local := hour.Hour.In(utils.ClubLocation())
+		key := slot{weekday: int(local.Weekday()), hour: local.Hour()}
+		totals := slots[key]
+		if totals == nil {
+			totals = &slotTotals{}
+			slots[key] = totals
+		}
+		totals.total++
+		if hour.OccupiedTables <= threshold*hour.StaffOnShift || hour.OccupiedTables < 0.5 {
+			continue // Within the threshold, or about no table was in use
+		}
+		item := models.StaffingHour{
+			Hour:            hour.Hour,
+			OccupiedTables:  roundTo(hour.OccupiedTables, 2),
+			StaffOnShift:    roundTo(hour.StaffOnShift, 2),
+			AdditionalStaff: int(math.Ceil(hour.OccupiedTables/threshold - hour.StaffOnShift - 1e-9)),
+		}
+		if hour.StaffOnShift > 0 {
+			item.TablesPerStaff = floatPtr(roundTo(hour.OccupiedTables/hour.StaffOnShift, 2))
+		}
+		report.Hours = append(report.Hours, item)
+		totals.over++
+		totals.occupied += hour.OccupiedTables
+		totals.staff += hour.StaffOnShift
+	}
+
+	for key, totals := range slots {
+		if totals.over == 0 || totals.over*2 < totals.total {
+			continue // Not over the threshold at least every other week
+		}
+		avgOccupied, avgStaff := totals.occupied/float64(totals.over), totals.staff/float64(totals.over)
+		report.Recommendations = append(report.Recommendations, models.StaffingRecommendation{
+			Weekday:         key.weekday,
+			WeekdayName:     time.Weekday(key.weekday).String(),
+			Hour:            key.hour,
+			HoursOver:       totals.over,
+			HoursTotal:      totals.total,
+			AvgOccupied:     roundTo(avgOccupied, 2),
+			AvgStaff:        roundTo(avgStaff, 2),
+			AdditionalStaff: int(math.Ceil(avgOccupied/threshold - avgStaff - 1e-9)),
+		})
+	}
+	// Most often over first, then in the order of the week from Monday
+	slices.SortFunc(report.Recommendations, func(a, b models.StaffingRecommendation) int {
+		if a.HoursOver != b.HoursOver {
+			return b.HoursOver - a.HoursOver
+		}
+		if a.Weekday != b.Weekday {
+			return (a.Weekday+6)%7 - (b.Weekday+6)%7
+		}
+		return a.Hour - b.Hour
+	})
+	return report, nil
+}
+
+func (s *reportService) GetVoidReport(timeRange utils.TimeRange) (*models.VoidReport, error) {
+	now := utils.NowUTC()
+	from, to := utils.AddClubDays(now, -DefaultVoidReportDays), now
+	if timeRange.From != nil {
+		from = *timeRange.From
+	}
+	if timeRange.To != nil {
+		to = *timeRange.To
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: the range must end after it starts", ErrReportValidation)
+	}
+	if to.Sub(from) > MaxVoidReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: the report covers at most %d days", ErrReportValidation, MaxVoidReportDays)
+	}
+
+	voids, err := s.reportRepo.GetVoids(utils.BranchCode(), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voids: %w", err)
+	}
+
+	report := &models.VoidReport{From: from, To: to, Voids: voids}
+	type shiftKey struct {
+		entryID int64 // 0 for the voids made off the clock
+		staffID int64
+	}
+	byStaff := map[int64]*models.VoidTotal{}
+	byReason := map[string]*models.VoidTotal{}
+	byShift := map[shiftKey]*models.VoidTotal{}
+	var staffOrder, reasonOrder, shiftOrder []*models.VoidTotal
+	add := func(total *models.VoidTotal, void models.OrderVoid) {
+		if void.OrderItemID == nil {
+			total.OrderCount++
+		} else {
+			total.ItemCount++
+		}
+		total.Amount = total.Amount.Add(void.Amount)
+	}
+	for _, void := range voids {
+		if void.OrderItemID == nil {
+			report.OrderCount++
+		} else {
+			report.ItemCount++
+		}
+		report.Amount = report.Amount.Add(void.Amount)
+
+		var staffID int64
+		if void.VoidedBy != nil {
+			staffID = *void.VoidedBy
+		}
+		staff := byStaff[staffID]
+		if staff == nil {
+			staff = &models.VoidTotal{StaffID: void.VoidedBy, StaffName: void.VoidedByName}
+			byStaff[staffID] = staff
+			staffOrder = append(staffOrder, staff)
+		}
+		add(staff, void)
+
+		reason := byReason[void.Reason]
+		if reason == nil {
+			reason = &models.VoidTotal{Reason: void.Reason}
+			byReason[void.Reason] = reason
+			reasonOrder = append(reasonOrder, reason)
+		}
+		add(reason, void)
+
+		key := shiftKey{staffID: staffID}
+		if void.TimeClockEntryID != nil {
+			key.entryID = *void.TimeClockEntryID
+		}
+		shift := byShift[key]
+		if shift == nil {
+			shift = &models.VoidTotal{StaffID: void.VoidedBy, StaffName: void.VoidedByName, TimeClockEntryID: void.TimeClockEntryID,
+				ClockInAt: void.ClockInAt, ClockOutAt: void.ClockOutAt}
+			byShift[key] = shift
+			shiftOrder = append(shiftOrder, shift)
+		}
+		add(shift, void)
+	}
+
+	byAmount := func(a, b *models.VoidTotal) int { return b.Amount.Cmp(a.Amount) }
+	slices.SortStableFunc(staffOrder, byAmount)
+	slices.SortStableFunc(reasonOrder, byAmount)
+	// Latest shift first; the voids are latest first, so the off-clock totals keep that order
+	slices.SortStableFunc(shiftOrder, func(a, b *models.VoidTotal) int {
+		switch {
+		case a.ClockInAt == nil && b.ClockInAt == nil:
+			return 0
+		case a.ClockInAt == nil:
+			return 1
+		case b.ClockInAt == nil:
+			return -1
+		}
+		return b.ClockInAt.Compare(*a.ClockInAt)
+	})
+	report.ByStaff = derefVoidTotals(staffOrder)
+	report.ByReason = derefVoidTotals(reasonOrder)
+	report.ByShift = derefVoidTotals(shiftOrder)
+	return report, nil
+}
+
+func (s *reportService) GetDiscountReport(startDate, endDate, period string) (*models.DiscountReport, error) {
+	start, end, period, err := parseReportRange(startDate, endDate, period)
+	if err != nil {
+		return nil, err
+	}
+	_, endOfRange := utils.DayBounds(end)
+	filter := models.DiscountReportFilter{
+		BranchCode: utils.BranchCode(),
+		Start:      start,
+		End:        endOfRange,
+		Period:     period,
+		Statuses:   ShiftSalesOrderStatuses,
+	}
+	byPeriod, err := s.reportRepo.GetDiscountsByPeriod(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discounts by period: %w", err)
+	}
+	byStaff, err := s.reportRepo.GetDiscountsByStaff(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discounts by staff: %w", err)
+	}
+	byCode, err := s.reportRepo.GetPromoCodeUsage(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code usage: %w", err)
+	}
+
+	report := &models.DiscountReport{
+		From:     startDate,
+		To:       endDate,
+		Period:   period,
+		ByCode:   byCode,
+		ByStaff:  byStaff,
+		ByPeriod: byPeriod,
+	}
+	for i := range byPeriod {
+		finishDiscountTotal(&byPeriod[i])
+		report.OrderCount += byPeriod[i].OrderCount
+		report.DiscountedOrders += byPeriod[i].DiscountedOrders
+		report.GrossSales = report.GrossSales.Add(byPeriod[i].GrossSales)
+		report.Discounts = report.Discounts.Add(byPeriod[i].Discounts)
+	}
+	for i := range byStaff {
+		finishDiscountTotal(&byStaff[i])
+	}
+	for i, code := range byCode {
+		if code.IssuedCount > 0 {
+			byCode[i].RedemptionRate = floatPtr(roundTo(float64(code.TotalRedemptions)*100/float64(code.IssuedCount), 2))
+		}
+	}
+	report.NetSales = report.GrossSales.Sub(report.Discounts)
+	report.AttachRate = attachRate(report.DiscountedOrders, report.OrderCount)
+	return report, nil
+}
+
+// finishDiscountTotal sets the fields of a discount total derived from its sums.
+func finishDiscountTotal(total *models.DiscountTotal) {
+	total.NetSales = total.GrossSales.Sub(total.Discounts)
+	total.AttachRate = attachRate(total.DiscountedOrders, total.OrderCount)
+}
+
+// attachRate is the percentage of orders that were discounted.
+func attachRate(discounted, orders int) float64 {
+	if orders == 0 {
+		return 0
+	}
+	return roundTo(float64(discounted)*100/float64(orders), 2)
+}
+
+// derefVoidTotals copies the totals of a void report grouping into a slice.
+func derefVoidTotals(totals []*models.VoidTotal) []models.VoidTotal {
+	result := make([]models.VoidTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	return result
+}
+
+// roundTo rounds value to the given decimals.
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+func (s *reportService) GetSourceReport(startDate, endDate string) (*models.SourceReport, error) {
+	start, end, _, err := parseReportRange(startDate, endDate, "")
+	if err != nil {
+		return nil, err
+	}
+	_, endOfRange := utils.DayBounds(end)
+	filter := models.SourceReportFilter{
+		BranchCode: utils.BranchCode(),
+		Start:      start,
+		End:        endOfRange,
+		Statuses:   ShiftSalesOrderStatuses,
+	}
+	sales, err := s.reportRepo.GetSalesBySource(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales by source: %w", err)
+	}
+	bookings, err := s.reportRepo.GetBookingsBySource(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings by source: %w", err)
+	}
+
+	report := &models.SourceReport{From: startDate, To: endDate, BySource: make([]models.SourceTotal, len(models.OrderSources))}
+	index := make(map[string]int, len(models.OrderSources))
+	for i, source := range models.OrderSources {
+		report.BySource[i].Source = source
+		index[source] = i
+	}
+	for _, total := range sales {
+		i, ok := index[total.Source]
+		if !ok {
+			continue
+		}
+		report.BySource[i].OrderCount, report.BySource[i].Revenue = total.OrderCount, total.Revenue
+		report.OrderCount += total.OrderCount
+		report.Revenue = report.Revenue.Add(total.Revenue)
+	}
+	for _, total := range bookings {
+		i, ok := index[total.Source]
+		if !ok {
+			continue
+		}
+		report.BySource[i].BookingCount, report.BySource[i].BookingRevenue = total.BookingCount, total.BookingRevenue
+		report.BookingCount += total.BookingCount
+		report.BookingRevenue = report.BookingRevenue.Add(total.BookingRevenue)
+	}
+	for i := range report.BySource {
+		total := &report.BySource[i]
+		if total.OrderCount > 0 {
+			total.AverageOrder = models.NewMoney(total.Revenue.Decimal().Div(decimal.NewFromInt(int64(total.OrderCount)))).Round()
+		}
+		if report.Revenue.IsPositive() {
+			total.RevenueShare = total.Revenue.Decimal().Mul(decimal.NewFromInt(100)).Div(report.Revenue.Decimal()).Round(2).InexactFloat64()
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/kvstore"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrReportRefreshInProgress = apperrors.New(utils.ErrCodeConflict, "the report views are already being refreshed")
+
+// ReportViewRefreshInterval is how often RunRefresh refreshes the report views.
+var ReportViewRefreshInterval = 15 * time.Minute
+
+const (
+	reportViewLockKey = "report_views:refresh"
+	// reportViewRefreshTimeout bounds how long a crashed instance keeps the refresh lock.
+	reportViewRefreshTimeout = 30 * time.Minute
+)
+
+// --- ReportViewService Interface ---
+type ReportViewService interface {
+	// RefreshViews refreshes all report views now and returns their refreshes. Only one
+	// refresh runs at a time across instances; ErrReportRefreshInProgress otherwise.
+	RefreshViews(ctx context.Context) ([]models.ReportViewRefresh, error)
+	// GetRefreshes returns the last refresh of each report view.
+	GetRefreshes() ([]models.ReportViewRefresh, error)
+	// RunRefresh refreshes the report views every ReportViewRefreshInterval until ctx is
+	// done. Every instance may run it; a refresh already running elsewhere is skipped.
+	RunRefresh(ctx context.Context)
+}
+
+type reportViewService struct {
+	reportViewRepo repositories.ReportViewRepository
+	store          kvstore.Store
+}
+
+// NewReportViewService creates a new ReportViewService.
+func NewReportViewService(reportViewRepo repositories.ReportViewRepository, store kvstore.Store) ReportViewService {
+	return &reportViewService{reportViewRepo: reportViewRepo, store: store}
+}
+
+func (s *reportViewService) RefreshViews(ctx context.Context) ([]models.ReportViewRefresh, error) {
+	locked, err := s.store.SetNX(ctx, reportViewLockKey, "1", reportViewRefreshTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the report view refresh: %w", err)
+	}
+	if !locked {
+		return nil, ErrReportRefreshInProgress
+	}
+	defer func() {
+		if err := s.store.Delete(context.Background(), reportViewLockKey); err != nil {
+			utils.LogError(err, "Failed to release the report view refresh lock")
+		}
+	}()
+
+	refreshes := make([]models.ReportViewRefresh, 0, len(models.ReportViews))
+	for _, view := range models.ReportViews {
+		refresh, err := s.reportViewRepo.RefreshView(view)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh report view %s: %w", view, err)
+		}
+		refreshes = append(refreshes, *refresh)
+	}
+	return refreshes, nil
+}
+
+func (s *reportViewService) GetRefreshes() ([]models.ReportViewRefresh, error) {
+	refreshes, err := s.reportViewRepo.GetRefreshes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report view refreshes: %w", err)
+	}
+	return refreshes, nil
+}
+
+func (s *reportViewService) RunRefresh(ctx context.Context) {
+	ticker := time.NewTicker(ReportViewRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.RefreshViews(ctx); err != nil && !errors.Is(err, ErrReportRefreshInProgress) {
+			utils.LogError(err, "Failed to refresh the report views")
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrSalesTargetNotFound   = apperrors.New(utils.ErrCodeNotFound, "sales target not found")
+	ErrSalesTargetExists     = apperrors.New(utils.ErrCodeConflict, "the branch already has a target for this category and month")
+	ErrSalesTargetValidation = apperrors.New(utils.ErrCodeValidationFailed, "sales target validation error")
+)
+
+// SalesTargetNoticeDay is the day of the month from which the Admins are notified of the sales
+// targets falling far behind; earlier in the month the pace says little.
+var SalesTargetNoticeDay = 15
+
+// SalesTargetBehindPercent is the percentage of its target below which the projected sales of
+// a category are far behind.
+var SalesTargetBehindPercent = 80
+
+// SalesTargetCheckInterval is how often RunBehindCheck looks for sales targets to notify of.
+var SalesTargetCheckInterval = time.Hour
+
+// SalesTargetRequest is the body of POST /sales-targets.
+type SalesTargetRequest struct {
+	BranchCode string       `json:"branch_code"` // Defaults to the branch of this instance
+	CategoryID int64        `json:"category_id" binding:"required"`
+	Month      string       `json:"month" binding:"required"` // YYYY-MM
+	Amount     models.Money `json:"amount" binding:"required,gt=0"`
+}
+
+// SalesTargetAmountRequest is the body of PUT /sales-targets/:id.
+type SalesTargetAmountRequest struct {
+	Amount models.Money `json:"amount" binding:"required,gt=0"`
+}
+
+// --- SalesTargetService Interface ---
+type SalesTargetService interface {
+	CreateTarget(req SalesTargetRequest, createdBy int64) (*models.SalesTarget, error)
+	// GetTargets lists the targets of a branch in a month (YYYY-MM); empty arguments mean the
+	// branch of this instance and the current month.
+	GetTargets(branchCode, month string) ([]models.SalesTarget, error)
+	// UpdateTarget changes the amount of a target; a changed target may be notified of again.
+	UpdateTarget(id int64, amount models.Money) (*models.SalesTarget, error)
+	DeleteTarget(id int64) error
+	// GetProgress compares the targets of a branch in a month with the sales so far and the
+	// sales projected at their pace; empty arguments as for GetTargets.
+	GetProgress(branchCode, month string) (*models.SalesTargetProgress, error)
+	// NotifyBehind publishes a sales_target.behind event for each target of the current month
+	// of this branch that is far behind and was not notified of yet, and returns their progress.
+	// Before SalesTargetNoticeDay it notifies of none.
+	NotifyBehind(now time.Time) ([]models.CategoryTargetProgress, error)
+	// RunBehindCheck runs NotifyBehind at start and every SalesTargetCheckInterval until ctx is
+	// done. Every instance may run it; a target is notified of only once.
+	RunBehindCheck(ctx context.Context)
+	// HandleBehindEvent emails the target of a sales_target.behind event to the active Admins
+	// with an email address. A failure is returned so the relay retries the event.
+	HandleBehindEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type salesTargetService struct {
+	targetRepo   repositories.SalesTargetRepository
+	dayCloseRepo repositories.DayCloseRepository // Totals the sales per category
+	authRepo     repositories.AuthRepository
+	publisher    events.Publisher // Records the notices in their transaction
+	sender       MailSender       // nil if email is not configured
+	db           *sql.DB
+}
+
+// NewSalesTargetService creates a new SalesTargetService. sender may be nil, which disables
+// emailing the behind notices.
+func NewSalesTargetService(targetRepo repositories.SalesTargetRepository, dayCloseRepo repositories.DayCloseRepository,
+	authRepo repositories.AuthRepository, publisher events.Publisher, sender MailSender, db *sql.DB) SalesTargetService {
+	return &salesTargetService{
+		targetRepo:   targetRepo,
+		dayCloseRepo: dayCloseRepo,
+		authRepo:     authRepo,
+		publisher:    publisher,
+		sender:       sender,
+		db:           db,
+	}
+}
+
+// salesTargetError converts the errors of the sales target repository.
+func salesTargetError(err error, action string) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrSalesTargetNotFound
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		return ErrSalesTargetExists
+	case errors.Is(err, repositories.ErrInvalidReference):
+		return fmt.Errorf("%w: %v", ErrSalesTargetValidation, err)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// targetBranchAndMonth validates the branch code and month of the sales targets, defaulting
+// to the branch of this instance and the current month.
+func targetBranchAndMonth(branchCode, month string) (string, string, error) {
+	if branchCode == "" {
+		branchCode = utils.BranchCode()
+	} else if !utils.IsValidBranchCode(branchCode) {
+		return "", "", fmt.Errorf("%w: invalid branch_code %q", ErrSalesTargetValidation, branchCode)
+	}
+	if month == "" {
+		month = utils.NowInClub().Format(utils.MonthLayout)
+	} else if _, _, err := utils.ParseClubMonth(month); err != nil {
+		return "", "", fmt.Errorf("%w: month must be YYYY-MM", ErrSalesTargetValidation)
+	}
+	return branchCode, month, nil
+}
+
+func (s *salesTargetService) CreateTarget(req SalesTargetRequest, createdBy int64) (*models.SalesTarget, error) {
+	if !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrSalesTargetValidation)
+	}
+	if req.Month == "" {
+		return nil, fmt.Errorf("%w: month is required", ErrSalesTargetValidation)
+	}
+	branchCode, month, err := targetBranchAndMonth(req.BranchCode, req.Month)
+	if err != nil {
+		return nil, err
+	}
+	target := &models.SalesTarget{
+		BranchCode: branchCode,
+		CategoryID: req.CategoryID,
+		Month:      month,
+		Amount:     req.Amount,
+		CreatedBy:  &createdBy,
+	}
+	if err := s.targetRepo.CreateTarget(target); err != nil {
+		return nil, salesTargetError(err, "create sales target")
+	}
+	return target, nil
+}
+
+func (s *salesTargetService) GetTargets(branchCode, month string) ([]models.SalesTarget, error) {
+	branchCode, month, err := targetBranchAndMonth(branchCode, month)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.targetRepo.GetTargets(branchCode, month)
+	if err != nil {
+		return nil, salesTargetError(err, "get sales targets")
+	}
+	return targets, nil
+}
+
+func (s *salesTargetService) UpdateTarget(id int64, amount models.Money) (*models.SalesTarget, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrSalesTargetValidation)
+	}
+	target, err := s.targetRepo.UpdateTargetAmount(id, amount)
+	if err != nil {
+		return nil, salesTargetError(err, "update sales target")
+	}
+	return target, nil
+}
+
+func (s *salesTargetService) DeleteTarget(id int64) error {
+	if err := s.targetRepo.DeleteTarget(id); err != nil {
+		return salesTargetError(err, "delete sales target")
+	}
+	return nil
+}
+
+func (s *salesTargetService) GetProgress(branchCode, month string) (*models.SalesTargetProgress, error) {
+	branchCode, month, err := targetBranchAndMonth(branchCode, month)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.targetRepo.GetTargets(branchCode, month)
+	if err != nil {
+		return nil, salesTargetError(err, "get sales targets")
+	}
+	return s.progress(s.db, targets, branchCode, month, utils.NowUTC())
+}
+
+// progress compares targets of a branch in a month with the sales of their categories up to now.
+func (s *salesTargetService) progress(executor repositories.SQLExecutor, targets []models.SalesTarget, branchCode, month string,
+	now time.Time) (*models.SalesTargetProgress, error) {
+	start, end, err := utils.ParseClubMonth(month)
+	if err != nil {
+		return nil, fmt.Errorf("%w: month must be YYYY-MM", ErrSalesTargetValidation)
+	}
+	daysInMonth := start.In(utils.ClubLocation()).AddDate(0, 1, -1).Day()
+	// The share of the month passed, by the clock; DST days count as they are long
+	elapsed := decimal.NewFromFloat(float64(now.Sub(start)) / float64(end.Sub(start)))
+	if elapsed.IsNegative() {
+		elapsed = decimal.Zero
+	} else if elapsed.GreaterThan(decimal.NewFromInt(1)) {
+		elapsed = decimal.NewFromInt(1)
+	}
+
+	actuals := map[int64]models.Money{}
+	if len(targets) > 0 && now.After(start) {
+		to := end
+		if now.Before(end) {
+			to = now
+		}
+		sales, err := s.dayCloseRepo.GetCategorySales(executor, branchCode, ShiftSalesOrderStatuses, start, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to total sales per category: %w", err)
+		}
+		for _, category := range sales {
+			if category.CategoryID != nil {
+				actuals[*category.CategoryID] = category.Amount
+			}
+		}
+	}
+
+	result := &models.SalesTargetProgress{
+		BranchCode:  branchCode,
+		Month:       month,
+		DaysInMonth: daysInMonth,
+		DaysElapsed: elapsed.Mul(decimal.NewFromInt(int64(daysInMonth))).Round(2).InexactFloat64(),
+		Categories:  make([]models.CategoryTargetProgress, 0, len(targets)),
+		Target:      models.ZeroMoney,
+		Actual:      models.ZeroMoney,
+		Projected:   models.ZeroMoney,
+	}
+	for _, target := range targets {
+		item := targetProgress(target, actuals[target.CategoryID], elapsed)
+		result.Categories = append(result.Categories, item)
+		result.Target = result.Target.Add(item.Target)
+		result.Actual = result.Actual.Add(item.Actual)
+		result.Projected = result.Projected.Add(item.Projected)
+	}
+	return result, nil
+}
+
+// targetProgress is the progress of a target with actual sales when the elapsed share of its
+// month has passed.
+func targetProgress(target models.SalesTarget, actual models.Money, elapsed decimal.Decimal) models.CategoryTargetProgress {
+	projected := actual
+	if elapsed.IsPositive() {
+		projected = models.NewMoney(actual.Decimal().Div(elapsed)).Round()
+	}
+	item := models.CategoryTargetProgress{
+		TargetID:         target.ID,
+		CategoryID:       target.CategoryID,
+		CategoryName:     target.CategoryName,
+		Target:           target.Amount,
+		Actual:           actual,
+		Percent:          percentOf(actual, target.Amount),
+		Expected:         models.NewMoney(target.Amount.Decimal().Mul(elapsed)).Round(),
+		Projected:        projected,
+		ProjectedPercent: percentOf(projected, target.Amount),
+	}
+	switch {
+	case actual.Cmp(target.Amount) >= 0:
+		item.Status = models.SalesTargetAchieved
+	case projected.Cmp(target.Amount) >= 0:
+		item.Status = models.SalesTargetOnTrack
+	case item.ProjectedPercent < float64(SalesTargetBehindPercent):
+		item.Status = models.SalesTargetFarBehind
+	default:
+		item.Status = models.SalesTargetBehind
+	}
+	return item
+}
+
+// percentOf returns amount as a percentage of total, to one decimal.
+func percentOf(amount, total models.Money) float64 {
+	if !total.IsPositive() {
+		return 0
+	}
+	return amount.Decimal().Mul(decimal.NewFromInt(100)).Div(total.Decimal()).Round(1).InexactFloat64()
+}
+
+func (s *salesTargetService) NotifyBehind(now time.Time) ([]models.CategoryTargetProgress, error) {
+	local := now.In(utils.ClubLocation())
+	if local.Day() < SalesTargetNoticeDay {
+		return nil, nil
+	}
+	branchCode, month := utils.BranchCode(), local.Format(utils.MonthLayout)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locks the targets, so a check running elsewhere skips them or finds them notified
+	targets, err := s.targetRepo.GetUnnotifiedTargets(tx, branchCode, month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales targets to check: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	progress, err := s.progress(tx, targets, branchCode, month, now)
+	if err != nil {
+		return nil, err
+	}
+	var notified []models.CategoryTargetProgress
+	for _, item := range progress.Categories {
+		if item.Status != models.SalesTargetFarBehind {
+			continue
+		}
+		if err := s.targetRepo.MarkBehindNotified(tx, item.TargetID, now); err != nil {
+			return nil, err
+		}
+		payload := events.SalesTargetPayload{
+			TargetID:     item.TargetID,
+			BranchCode:   branchCode,
+			CategoryID:   item.CategoryID,
+			CategoryName: item.CategoryName,
+			Month:        month,
+			Target:       item.Target,
+			Actual:       item.Actual,
+			Projected:    item.Projected,
+		}
+		if err := s.publisher.Publish(tx, events.SalesTargetBehind, events.AggregateSalesTarget, item.TargetID, payload); err != nil {
+			return nil, err
+		}
+		notified = append(notified, item)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sales target notices: %w", err)
+	}
+	return notified, nil
+}
+
+func (s *salesTargetService) RunBehindCheck(ctx context.Context) {
+	ticker := time.NewTicker(SalesTargetCheckInterval)
+	defer ticker.Stop()
+	for {
+		notified, err := s.NotifyBehind(utils.NowUTC())
+		if err != nil {
+			utils.LogError(err, "Failed to notify of sales targets falling behind")
+		} else if len(notified) > 0 {
+			utils.LogInfo("Notified of sales targets falling behind", map[string]interface{}{"targets": len(notified)})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *salesTargetService) HandleBehindEvent(ctx context.Context, event models.DomainEvent) error {
+	if s.sender == nil {
+		return nil
+	}
+	var payload events.SalesTargetPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+	}
+	recipients, err := s.authRepo.GetAdminEmails()
+	if err != nil {
+		return fmt.Errorf("failed to get admin emails: %w", err)
+	}
+	if len(recipients) == 0 {
+		utils.LogWarn("No Admin has an email address; sales target falling behind not emailed", map[string]interface{}{"target_id": payload.TargetID})
+		return nil
+	}
+
+	subject := fmt.Sprintf("Sales target behind: %s, %s", payload.CategoryName, payload.Month)
+	body := fmt.Sprintf("The %s sales of branch %s are %s so far against a target of %s for %s.\n"+
+		"At the current pace they reach %s (%.1f%% of the target) by the end of the month.\n"+
+		"See the progress of all categories under /dashboard/targets?month=%s.\n",
+		payload.CategoryName, payload.BranchCode, payload.Actual, payload.Target, payload.Month,
+		payload.Projected, percentOf(payload.Projected, payload.Target), payload.Month)
+	if err := s.sender.Send(ctx, recipients, subject, body); err != nil {
+		return fmt.Errorf("failed to email sales target ID %d falling behind: %w", payload.TargetID, err)
+	}
+	return nil
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrSavedReportNotFound   = apperrors.New(utils.ErrCodeNotFound, "saved report not found")
+	ErrSavedReportNameTaken  = apperrors.New(utils.ErrCodeConflict, "a saved report with this name already exists")
+	ErrSavedReportValidation = apperrors.New(utils.ErrCodeValidationFailed, "saved report validation error")
+)
+
+// SavedReportScheduleInterval is how often RunSchedule looks for saved reports due to be emailed.
+var SavedReportScheduleInterval = time.Minute
+
+// reportTimeRangeParams are the time range parameters of the reports, see utils.ParseTimeRange.
+var reportTimeRangeParams = []string{"from", "to", "range"}
+
+// savedReportParams are the query parameters each report type takes besides its time range,
+// and the values they may have; nil for an ID.
+var savedReportParams = map[string]map[string][]string{
+	models.SavedReportTypeSummary:   {"period": {PeriodDaily, PeriodWeekly, PeriodMonthly}},
+	models.SavedReportTypeTax:       {"period": {PeriodDaily, PeriodWeekly, PeriodMonthly}},
+	models.SavedReportTypeSales:     {"period": {PeriodDaily, PeriodWeekly, PeriodMonthly}, "item_id": nil, "category_id": nil},
+	models.SavedReportTypeBookings:  {"granularity": {"daily", "hourly"}, "table_id": nil},
+	models.SavedReportTypeInventory: {},
+}
+
+// ReportRunner runs the report of a type with the query parameters of its route;
+// handlers.ReportRunner implements it. Invalid parameters are returned wrapping
+// ErrSavedReportValidation.
+type ReportRunner interface {
+	RunReport(reportType string, params url.Values) (interface{}, error)
+}
+
+// SavedReportRequest is the body of POST and PUT /reports/saved.
+type SavedReportRequest struct {
+	Name       string                 `json:"name" binding:"required,max=100"`
+	ReportType string                 `json:"report_type" binding:"required"`
+	Params     map[string]string      `json:"params"`
+	Schedule   *ReportScheduleRequest `json:"schedule"` // null to not email the report
+}
+
+// ReportScheduleRequest is the schedule of a SavedReportRequest, see models.ReportSchedule.
+type ReportScheduleRequest struct {
+	Frequency  string   `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Weekday    *int     `json:"weekday" binding:"omitempty,min=0,max=6"`
+	DayOfMonth *int     `json:"day_of_month" binding:"omitempty,min=1,max=28"`
+	Time       string   `json:"time" binding:"required"`
+	Recipients []string `json:"recipients" binding:"required,min=1,max=20,dive,email"`
+}
+
+// --- SavedReportService Interface ---
+type SavedReportService interface {
+	CreateReport(req SavedReportRequest, createdBy int64) (*models.SavedReport, error)
+	// GetReports lists the saved reports by name.
+	GetReports() ([]models.SavedReport, error)
+	GetReport(id int64) (*models.SavedReport, error)
+	UpdateReport(id int64, req SavedReportRequest) (*models.SavedReport, error)
+	DeleteReport(id int64) error
+	// RunReport runs a saved report; overrides replace its saved parameters for this run, e.g.
+	// range=yesterday.
+	RunReport(id int64, overrides url.Values) (*models.SavedReportRun, error)
+	// EmailDueReports emails the scheduled reports due at now and sets their next run; it
+	// returns how many were due. Every instance may run it; a run is emailed only once.
+	EmailDueReports(ctx context.Context, now time.Time) (int, error)
+	// RunSchedule runs EmailDueReports every SavedReportScheduleInterval until ctx is done.
+	RunSchedule(ctx context.Context)
+}
+
+type savedReportService struct {
+	savedReportRepo repositories.SavedReportRepository
+	runner          ReportRunner
+	sender          MailSender // nil if email is not configured
+	db              *sql.DB
+}
+
+// NewSavedReportService creates a new SavedReportService. sender may be nil, which disables
+// emailing the scheduled reports.
+func NewSavedReportService(savedReportRepo repositories.SavedReportRepository, runner ReportRunner, sender MailSender,
+	db *sql.DB) SavedReportService {
+	return &savedReportService{savedReportRepo: savedReportRepo, runner: runner, sender: sender, db: db}
+}
+
+// savedReportError converts the errors of the saved report repository.
+func savedReportError(err error, action string) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrSavedReportNotFound
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		return ErrSavedReportNameTaken
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// validateReportParams checks that params are parameters of the report type with valid values.
+func validateReportParams(reportType string, params map[string]string) error {
+	allowed, ok := savedReportParams[reportType]
+	if !ok {
+		return fmt.Errorf("%w: report_type must be one of %s", ErrSavedReportValidation, strings.Join(models.SavedReportTypes, ", "))
+	}
+	for name, value := range params {
+		if reportType != models.SavedReportTypeInventory && slices.Contains(reportTimeRangeParams, name) {
+			continue
+		}
+		values, ok := allowed[name]
+		if !ok {
+			return fmt.Errorf("%w: the %s report has no %s parameter", ErrSavedReportValidation, reportType, name)
+		}
+		if values == nil {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return fmt.Errorf("%w: %s must be an ID", ErrSavedReportValidation, name)
+			}
+		} else if !slices.Contains(values, value) {
+			return fmt.Errorf("%w: %s must be one of %s", ErrSavedReportValidation, name, strings.Join(values, ", "))
+		}
+	}
+	if _, err := utils.ParseTimeRange(params["from"], params["to"], params["range"]); err != nil {
+		return fmt.Errorf("%w: %v", ErrSavedReportValidation, err)
+	}
+	return nil
+}
+
+// applySavedReportRequest sets the fields of req on report, and its next run as of now.
+func applySavedReportRequest(report *models.SavedReport, req SavedReportRequest, now time.Time) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrSavedReportValidation)
+	}
+	params := map[string]string{}
+	for key, value := range req.Params {
+		if value = strings.TrimSpace(value); value != "" {
+			params[key] = value
+		}
+	}
+	if err := validateReportParams(req.ReportType, params); err != nil {
+		return err
+	}
+	report.Name = name
+	report.ReportType = req.ReportType
+	report.Params = params
+	report.Schedule = nil
+	report.NextRunAt = nil
+	if req.Schedule == nil {
+		return nil
+	}
+
+	schedule := &models.ReportSchedule{
+		Frequency:  req.Schedule.Frequency,
+		Time:       req.Schedule.Time,
+		Recipients: req.Schedule.Recipients,
+	}
+	if _, err := time.Parse("15:04", schedule.Time); err != nil {
+		return fmt.Errorf("%w: schedule time must be HH:MM", ErrSavedReportValidation)
+	}
+	switch schedule.Frequency {
+	case models.ReportFrequencyWeekly:
+		if req.Schedule.Weekday == nil {
+			return fmt.Errorf("%w: a weekly schedule needs a weekday", ErrSavedReportValidation)
+		}
+		schedule.Weekday = req.Schedule.Weekday
+	case models.ReportFrequencyMonthly:
+		if req.Schedule.DayOfMonth == nil {
+			return fmt.Errorf("%w: a monthly schedule needs a day_of_month", ErrSavedReportValidation)
+		}
+		schedule.DayOfMonth = req.Schedule.DayOfMonth
+	}
+	nextRunAt := nextReportRun(schedule, now)
+	report.Schedule = schedule
+	report.NextRunAt = &nextRunAt
+	return nil
+}
+
+// nextReportRun returns the first time after after at which a report with the schedule is
+// emailed, in UTC. The time of day is club time, so it stays the same across DST changes.
+func nextReportRun(schedule *models.ReportSchedule, after time.Time) time.Time {
+	clock, _ := time.Parse("15:04", schedule.Time)
+	local := after.In(utils.ClubLocation())
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, utils.ClubLocation())
+	}
+
+	var next time.Time
+	switch schedule.Frequency {
+	case models.ReportFrequencyWeekly:
+		days := (*schedule.Weekday - int(local.Weekday()) + 7) % 7
+		next = at(local.Year(), local.Month(), local.Day()+days)
+		if !next.After(after) {
+			next = at(local.Year(), local.Month(), local.Day()+days+7)
+		}
+	case models.ReportFrequencyMonthly:
+		next = at(local.Year(), local.Month(), *schedule.DayOfMonth)
+		if !next.After(after) {
+			next = at(local.Year(), local.Month()+1, *schedule.DayOfMonth)
+		}
+	default: // Daily
+		next = at(local.Year(), local.Month(), local.Day())
+		if !next.After(after) {
+			next = at(local.Year(), local.Month(), local.Day()+1)
+		}
+	}
+	return next.UTC()
+}
+
+func (s *savedReportService) CreateReport(req SavedReportRequest, createdBy int64) (*models.SavedReport, error) {
+	report := &models.SavedReport{CreatedBy: &createdBy}
+	if err := applySavedReportRequest(report, req, utils.NowUTC()); err != nil {
+		return nil, err
+	}
+	if err := s.savedReportRepo.CreateReport(report); err != nil {
+		return nil, savedReportError(err, "create saved report")
+	}
+	return report, nil
+}
+
+func (s *savedReportService) GetReports() ([]models.SavedReport, error) {
+	reports, err := s.savedReportRepo.GetReports()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (s *savedReportService) GetReport(id int64) (*models.SavedReport, error) {
+	report, err := s.savedReportRepo.GetReportByID(id)
+	if err != nil {
+		return nil, savedReportError(err, "get saved report")
+	}
+	return report, nil
+}
+
+func (s *savedReportService) UpdateReport(id int64, req SavedReportRequest) (*models.SavedReport, error) {
+	report, err := s.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySavedReportRequest(report, req, utils.NowUTC()); err != nil {
+		return nil, err
+	}
+	if err := s.savedReportRepo.UpdateReport(report); err != nil {
+		return nil, savedReportError(err, "update saved report")
+	}
+	return report, nil
+}
+
+func (s *savedReportService) DeleteReport(id int64) error {
+	if err := s.savedReportRepo.DeleteReport(id); err != nil {
+		return savedReportError(err, "delete saved report")
+	}
+	return nil
+}
+
+func (s *savedReportService) RunReport(id int64, overrides url.Values) (*models.SavedReportRun, error) {
+	report, err := s.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	for key, value := range report.Params {
+		params[key] = value
+	}
+	// A time range given for the run replaces the saved one as a whole
+	if slices.ContainsFunc(reportTimeRangeParams, overrides.Has) {
+		for _, key := range reportTimeRangeParams {
+			delete(params, key)
+		}
+	}
+	for key := range overrides {
+		if value := strings.TrimSpace(overrides.Get(key)); value != "" {
+			params[key] = value
+		}
+	}
+	if err := validateReportParams(report.ReportType, params); err != nil {
+		return nil, err
+	}
+
+	data, err := s.runner.RunReport(report.ReportType, reportQuery(params))
+	if err != nil {
+		if errors.Is(err, ErrSavedReportValidation) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to run saved report ID %d: %w", id, err)
+	}
+	return &models.SavedReportRun{Report: *report, Params: params, Data: data}, nil
+}
+
+// reportQuery returns params as query parameters.
+func reportQuery(params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	return query
+}
+
+func (s *savedReportService) EmailDueReports(ctx context.Context, now time.Time) (int, error) {
+	if s.sender == nil {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locks the due reports, so an instance running the schedule as well skips them
+	due, err := s.savedReportRepo.GetDueReports(tx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due saved reports: %w", err)
+	}
+	for _, report := range due {
+		if err := s.savedReportRepo.ScheduleNextRun(tx, report.ID, now, nextReportRun(report.Schedule, now)); err != nil {
+			return 0, fmt.Errorf("failed to schedule saved report ID %d: %w", report.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit saved report runs: %w", err)
+	}
+
+	// A run that fails is not retried; its error is kept on the report until the next run
+	for _, report := range due {
+		var lastError *string
+		if err := s.emailReport(ctx, &report, now); err != nil {
+			utils.LogError(err, fmt.Sprintf("Failed to email saved report ID %d", report.ID))
+			message := err.Error()
+			lastError = &message
+		}
+		if err := s.savedReportRepo.SetLastError(report.ID, lastError); err != nil {
+			utils.LogError(err, fmt.Sprintf("Failed to record run of saved report ID %d", report.ID))
+		}
+	}
+	return len(due), nil
+}
+
+// emailReport runs a scheduled report and emails it to its recipients.
+func (s *savedReportService) emailReport(ctx context.Context, report *models.SavedReport, now time.Time) error {
+	data, err := s.runner.RunReport(report.ReportType, reportQuery(report.Params))
+	if err != nil {
+		return fmt.Errorf("running report: %w", err)
+	}
+	body, err := renderSavedReport(report, data, now)
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Report: %s, %s", report.Name, utils.FormatClubTime(now, utils.DateLayout))
+	if err := s.sender.Send(ctx, report.Schedule.Recipients, subject, body); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	return nil
+}
+
+// renderSavedReport formats the result of a saved report as the plain text of its email: its
+// parameters, then the rows as JSON, as the report route returns them.
+func renderSavedReport(report *models.SavedReport, data interface{}, now time.Time) (string, error) {
+	rows, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding report: %w", err)
+	}
+	keys := make([]string, 0, len(report.Params))
+	for key := range report.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(report.Name + "\n")
+	b.WriteString(fmt.Sprintf("%s report, run %s\n", report.ReportType, utils.FormatClubTime(now, "2006-01-02 15:04")))
+	for _, key := range keys {
+		b.WriteString(fmt.Sprintf("  %s: %s\n", key, report.Params[key]))
+	}
+	b.WriteString("\n")
+	b.Write(rows)
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+func (s *savedReportService) RunSchedule(ctx context.Context) {
+	ticker := time.NewTicker(SavedReportScheduleInterval)
+	defer ticker.Stop()
+	for {
+		if emailed, err := s.EmailDueReports(ctx, utils.NowUTC()); err != nil {
+			utils.LogError(err, "Failed to email scheduled reports")
+		} else if emailed > 0 {
+			utils.LogInfo("Ran scheduled reports", map[string]interface{}{"reports": emailed})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrSearchQueryTooShort = apperrors.New(utils.ErrCodeValidationFailed, "search query must be at least 2 characters")
+
+// Search limits per result group.
+const (
+	DefaultSearchLimit = 5
+	MaxSearchLimit     = 20
+)
+
+// --- SearchService Interface ---
+type SearchService interface {
+	// Search looks up orders, bookings and clients matching q and returns up to limit
+	// matches per entity type. A query that is an order number (e.g. "2024-06-01/#37"
+	// or "#37") also finds that order of this branch.
+	Search(q string, limit int) (*models.SearchResults, error)
+}
+
+// --- searchService Implementation ---
+type searchService struct {
+	searchRepo repositories.SearchRepository
+}
+
+// NewSearchService creates a new instance of SearchService.
+func NewSearchService(searchRepo repositories.SearchRepository) SearchService {
+	return &searchService{searchRepo: searchRepo}
+}
+
+func (s *searchService) Search(q string, limit int) (*models.SearchResults, error) {
+	q = strings.TrimSpace(q)
+	var number *models.OrderNumberRef
+	if businessDate, dailyNumber, err := parseOrderNumber(q); err == nil {
+		number = &models.OrderNumberRef{BranchCode: utils.BranchCode(), BusinessDate: businessDate, DailyNumber: dailyNumber}
+	}
+	// Order numbers such as "7" are shorter than useful text queries
+	if number == nil && utf8.RuneCountInString(q) < 2 {
+		return nil, ErrSearchQueryTooShort
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	results := &models.SearchResults{Query: q, Groups: []models.SearchGroup{}}
+
+	orders, err := s.searchRepo.SearchOrders(q, number, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+	if len(orders) > 0 {
+		results.Groups = append(results.Groups, models.SearchGroup{Type: models.SearchTypeOrders, TopScore: orders[0].Score, Items: orders})
+	}
+
+	bookings, err := s.searchRepo.SearchBookings(q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search bookings: %w", err)
+	}
+	if len(bookings) > 0 {
+		results.Groups = append(results.Groups, models.SearchGroup{Type: models.SearchTypeBookings, TopScore: bookings[0].Score, Items: bookings})
+	}
+
+	clients, err := s.searchRepo.SearchClients(q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search clients: %w", err)
+	}
+	if len(clients) > 0 {
+		results.Groups = append(results.Groups, models.SearchGroup{Type: models.SearchTypeClients, TopScore: clients[0].Score, Items: clients})
+	}
+
+	// Best group first; ties keep the orders, bookings, clients order
+	sort.SliceStable(results.Groups, func(i, j int) bool {
+		return results.Groups[i].TopScore > results.Groups[j].TopScore
+	})
+	return results, nil
+}
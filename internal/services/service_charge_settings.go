@@ -0,0 +1,53 @@
+package services
+
+import (
+	"sync"
+
+	"ps_club_backend/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	serviceChargeSettings   models.ServiceChargeSettings
+	serviceChargeSettingsMu sync.RWMutex
+)
+
+// SetServiceChargeSettings sets which orders carry a service charge and its percentage (the
+// service_charge setting).
+func SetServiceChargeSettings(settings models.ServiceChargeSettings) {
+	serviceChargeSettingsMu.Lock()
+	defer serviceChargeSettingsMu.Unlock()
+	serviceChargeSettings = settings
+}
+
+// CurrentServiceChargeSettings returns which orders carry a service charge and its percentage.
+func CurrentServiceChargeSettings() models.ServiceChargeSettings {
+	serviceChargeSettingsMu.RLock()
+	defer serviceChargeSettingsMu.RUnlock()
+	return serviceChargeSettings
+}
+
+// orderServiceCharge returns the service charge rate (nil if none) and amount of the order
+// req, whose items total amount after discount.
+func orderServiceCharge(req CreateOrderRequest, amount models.Money) (*decimal.Decimal, models.Money) {
+	settings := CurrentServiceChargeSettings()
+	charged := settings.AppliesTo(req.TableID, req.GuestCount)
+	if req.ServiceCharge != nil {
+		charged = *req.ServiceCharge && settings.Enabled()
+	}
+	if !charged {
+		return nil, models.ZeroMoney
+	}
+	rate := settings.Percent
+	return &rate, models.ChargeOn(amount, rate)
+}
+
+// serviceChargeLabel is the receipt and bill line of the service charge of order, e.g. "Service charge 10%".
+func serviceChargeLabel(order *models.Order) string {
+	label := CurrentServiceChargeSettings().DisplayName()
+	if order.ServiceChargeRate != nil {
+		label += " " + order.ServiceChargeRate.String() + "%"
+	}
+	return label
+}
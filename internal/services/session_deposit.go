@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrPaymentProviderDisabled = apperrors.New(utils.ErrCodeBadRequest, "card pre-authorization is not configured")
+	ErrDepositPaymentFailed    = apperrors.New(utils.ErrCodePaymentFailed, "the payment provider refused the deposit")
+	ErrDepositNotFound         = apperrors.New(utils.ErrCodeNotFound, "the table session has no deposit")
+	ErrDepositNotFailed        = apperrors.New(utils.ErrCodeConflict, "only a deposit whose capture failed can be captured again")
+)
+
+// depositCaptureTimeout bounds a call to the payment provider. A deposit left capturing for
+// longer, e.g. by a crash, may be captured again.
+const depositCaptureTimeout = 2 * time.Minute
+
+// PaymentProvider places and settles card pre-authorizations; payments.HTTPProvider implements it.
+type PaymentProvider interface {
+	// PreAuthorize places a hold of amount on the card and returns the ID of the pre-authorization.
+	PreAuthorize(ctx context.Context, amount models.Money, cardToken, reference string) (string, error)
+	// Capture charges amount of a pre-authorization and releases the rest of the hold.
+	Capture(ctx context.Context, authorizationID string, amount models.Money) error
+	// Void releases the whole hold of a pre-authorization.
+	Void(ctx context.Context, authorizationID string) error
+}
+
+// SessionDepositRequest is the deposit taken when a table session starts.
+type SessionDepositRequest struct {
+	Amount models.Money `json:"amount" binding:"required,money"`
+	Method string       `json:"method" binding:"required"` // One of models.DepositMethods
+	// CardToken is the card as tokenized by the terminal; required for card_preauth
+	CardToken string `json:"card_token"`
+}
+
+// validateDepositRequest checks the deposit of a session start.
+func (s *tableSessionService) validateDepositRequest(req *SessionDepositRequest) error {
+	if !req.Amount.IsPositive() {
+		return fmt.Errorf("%w: the deposit amount must be positive", ErrTableSessionValidation)
+	}
+	if !slices.Contains(models.DepositMethods, req.Method) {
+		return fmt.Errorf("%w: invalid deposit method '%s', must be one of %v", ErrTableSessionValidation, req.Method, models.DepositMethods)
+	}
+	if req.Method == models.DepositMethodCardPreAuth {
+		if s.payments == nil {
+			return ErrPaymentProviderDisabled
+		}
+		if strings.TrimSpace(req.CardToken) == "" {
+			return fmt.Errorf("%w: card_token is required for a card pre-authorization", ErrTableSessionValidation)
+		}
+	}
+	return nil
+}
+
+// preAuthorizeDeposit places the hold of a card deposit for a session at table tableID.
+func (s *tableSessionService) preAuthorizeDeposit(req *SessionDepositRequest, tableID int64) (*string, error) {
+	if req.Method != models.DepositMethodCardPreAuth {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), depositCaptureTimeout)
+	defer cancel()
+	reference := "table-" + strconv.FormatInt(tableID, 10) + "-" + strconv.FormatInt(utils.NowUTC().Unix(), 10)
+	authorizationID, err := s.payments.PreAuthorize(ctx, req.Amount, strings.TrimSpace(req.CardToken), reference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDepositPaymentFailed, err)
+	}
+	return &authorizationID, nil
+}
+
+// voidDeposit releases the hold of a card deposit whose session could not be started.
+func (s *tableSessionService) voidDeposit(authorizationID *string) {
+	if authorizationID == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), depositCaptureTimeout)
+	defer cancel()
+	if err := s.payments.Void(ctx, *authorizationID); err != nil {
+		utils.LogError(err, "StartSession: failed to void the pre-authorization "+*authorizationID+" of a session that did not start")
+	}
+}
+
+// attachDeposit sets the deposit of session, if it has one.
+func (s *tableSessionService) attachDeposit(session *models.TableSession) error {
+	deposit, err := s.depositRepo.GetDepositBySession(session.ID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deposit of table session: %w", err)
+	}
+	session.Deposit = deposit
+	return nil
+}
+
+// settleDeposit applies the held deposit of session, billed until stoppedAt, within the stop
+// transaction: cash is settled at once and the remainder handed back, a pre-authorization is
+// left capturing for captureDeposit.
+func (s *tableSessionService) settleDeposit(executor repositories.SQLExecutor, session *models.TableSession, stoppedAt time.Time) error {
+	deposit := session.Deposit
+	if deposit == nil || deposit.Status != models.DepositStatusHeld {
+		return nil
+	}
+	deposit.Settle(session.TotalAmount)
+	deposit.SettledAt = &stoppedAt
+	deposit.Status = models.DepositStatusSettled
+	if deposit.Method == models.DepositMethodCardPreAuth {
+		deposit.Status = models.DepositStatusCapturing
+	}
+	if err := s.depositRepo.UpdateDeposit(executor, deposit, models.DepositStatusHeld); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("failed to settle deposit: %w", err)
+	}
+	return nil
+}
+
+// captureDeposit asks the payment provider to capture the applied amount of a capturing
+// deposit and release the rest, or to void it if nothing was applied. A refusal marks the
+// deposit failed with the reason, to be captured again with RetryDepositCapture.
+func (s *tableSessionService) captureDeposit(deposit *models.SessionDeposit) error {
+	var err error
+	switch {
+	case s.payments == nil:
+		err = ErrPaymentProviderDisabled
+	case deposit.AuthorizationID == nil:
+		err = errors.New("the deposit has no pre-authorization")
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), depositCaptureTimeout)
+		if deposit.AppliedAmount.IsPositive() {
+			err = s.payments.Capture(ctx, *deposit.AuthorizationID, deposit.AppliedAmount)
+		} else {
+			err = s.payments.Void(ctx, *deposit.AuthorizationID)
+		}
+		cancel()
+	}
+
+	deposit.Status = models.DepositStatusSettled
+	deposit.FailureReason = nil
+	if err != nil {
+		reason := err.Error()
+		deposit.Status = models.DepositStatusFailed
+		deposit.FailureReason = &reason
+	}
+	if updateErr := s.depositRepo.UpdateDeposit(s.db, deposit, models.DepositStatusCapturing); updateErr != nil {
+		return fmt.Errorf("failed to record the capture of deposit: %w", updateErr)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDepositPaymentFailed, err)
+	}
+	return nil
+}
+
+func (s *tableSessionService) RetryDepositCapture(sessionID int64) (*models.SessionDeposit, error) {
+	deposit, err := s.depositRepo.GetDepositBySession(sessionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrDepositNotFound
+		}
+		return nil, fmt.Errorf("failed to get deposit of table session: %w", err)
+	}
+	stuck := deposit.Status == models.DepositStatusCapturing && utils.NowUTC().Sub(deposit.UpdatedAt) > depositCaptureTimeout
+	if deposit.Status != models.DepositStatusFailed && !stuck {
+		return nil, ErrDepositNotFailed
+	}
+	// Claiming the deposit as capturing keeps two retries from capturing it twice
+	fromStatus := deposit.Status
+	deposit.Status = models.DepositStatusCapturing
+	if err := s.depositRepo.UpdateDeposit(s.db, deposit, fromStatus); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
+		return nil, fmt.Errorf("failed to claim deposit: %w", err)
+	}
+	if err := s.captureDeposit(deposit); err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrSetupCompleted  = apperrors.New(utils.ErrCodeConflict, "the initial setup has been completed")
+	ErrSetupValidation = apperrors.New(utils.ErrCodeValidationFailed, "setup validation error")
+)
+
+// CompleteSetupRequest is the body of POST /setup: the first Admin and the club settings.
+type CompleteSetupRequest struct {
+	Username      string               `json:"username" binding:"required,max=100"`
+	Password      string               `json:"password" binding:"required,min=8,max=72"`
+	Email         *string              `json:"email" binding:"omitempty,email,max=255"`
+	FullName      *string              `json:"full_name" binding:"omitempty,max=255"`
+	ClubName      string               `json:"club_name" binding:"required,max=255"`
+	Timezone      string               `json:"timezone" binding:"required"` // IANA name, e.g. "Asia/Almaty"
+	Currency      string               `json:"currency" binding:"required"` // Code, e.g. "KZT"
+	BusinessHours models.BusinessHours `json:"business_hours"`
+}
+
+// --- SetupService Interface ---
+type SetupService interface {
+	GetSetupStatus() (*models.SetupStatus, error)
+	// CompleteSetup creates the first Admin and saves the club settings, all or nothing. It
+	// fails with ErrSetupCompleted once it has been completed or there are users.
+	CompleteSetup(req CompleteSetupRequest) (*models.User, error)
+}
+
+type setupService struct {
+	setupRepo repositories.SetupRepository
+	authRepo  repositories.AuthRepository
+	db        *sql.DB
+}
+
+// NewSetupService creates a new SetupService.
+func NewSetupService(setupRepo repositories.SetupRepository, authRepo repositories.AuthRepository, db *sql.DB) SetupService {
+	return &setupService{setupRepo: setupRepo, authRepo: authRepo, db: db}
+}
+
+func (s *setupService) GetSetupStatus() (*models.SetupStatus, error) {
+	status, err := s.setupRepo.GetSetupStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setup status: %w", err)
+	}
+	return status, nil
+}
+
+func (s *setupService) CompleteSetup(req CompleteSetupRequest) (*models.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrSetupValidation)
+	}
+	clubName := strings.TrimSpace(req.ClubName)
+	if clubName == "" {
+		return nil, fmt.Errorf("%w: club_name is required", ErrSetupValidation)
+	}
+	timezone := strings.TrimSpace(req.Timezone)
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: invalid timezone: %v", ErrSetupValidation, err)
+	}
+	currency, err := utils.ParseCurrencySetting(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid currency: %v", ErrSetupValidation, err)
+	}
+	settings := map[string]string{
+		models.SettingKeyClubName:     clubName,
+		models.SettingKeyClubTimezone: timezone,
+		models.SettingKeyCurrency:     strings.TrimSpace(req.Currency),
+	}
+	if len(req.BusinessHours) > 0 {
+		if err := req.BusinessHours.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSetupValidation, err)
+		}
+		hours, err := json.Marshal(req.BusinessHours)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode business hours: %w", err)
+		}
+		settings[models.SettingKeyBusinessHours] = string(hours)
+	}
+	adminRoleID := roleIDs["admin"]
+	user := models.User{Username: username, RoleID: &adminRoleID}
+	if req.Email != nil && strings.TrimSpace(*req.Email) != "" {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		user.Email = &email
+	}
+	if req.FullName != nil && strings.TrimSpace(*req.FullName) != "" {
+		fullName := strings.TrimSpace(*req.FullName)
+		user.FullName = &fullName
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := utils.NowUTC()
+	if err := s.setupRepo.ClaimSetup(tx, now); err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrSetupCompleted
+		}
+		return nil, fmt.Errorf("failed to claim setup: %w", err)
+	}
+	userID, err := s.authRepo.CreateUser(tx, &user, string(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create first admin: %w", err)
+	}
+	if err := s.setupRepo.SaveSettings(tx, settings, now); err != nil {
+		return nil, fmt.Errorf("failed to save club settings: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// The settings are in effect right away, as if saved through /settings
+	if err := utils.SetClubTimezone(timezone); err != nil {
+		utils.LogError(err, "CompleteSetup: failed to apply club timezone")
+	}
+	if err := utils.SetCurrency(currency); err != nil {
+		utils.LogError(err, "CompleteSetup: failed to apply currency")
+	}
+
+	created, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("first admin created but failed to retrieve it: %w", err)
+	}
+	created.PasswordHash = ""
+	return created, nil
+}
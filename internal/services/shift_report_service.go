@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrShiftReportNotFound = apperrors.New(utils.ErrCodeNotFound, "shift report not found")
+
+// ShiftSalesOrderStatuses are the statuses of the orders a shift report counts as sales.
+var ShiftSalesOrderStatuses = []string{StatusCompleted, StatusPaid}
+
+// OpenOrderStatuses are the statuses of the orders a shift hands over to the next one.
+var OpenOrderStatuses = []string{StatusPending, StatusPreparing, StatusReady, StatusServed}
+
+// MailSender sends a plain-text email; mail.SMTPSender implements it.
+type MailSender interface {
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+// --- ShiftReportService Interface ---
+type ShiftReportService interface {
+	GetReport(id int64) (*models.ShiftReport, error)
+	// GetReportForShift returns the most recent report of a scheduled shift, i.e. of the last
+	// clock-out during it.
+	GetReportForShift(shiftID int64) (*models.ShiftReport, error)
+	// GetReports returns the reports of this branch matching filters, most recent first.
+	GetReports(filters models.ShiftReportFilters) ([]models.ShiftReport, error)
+	// HandleReportEvent emails the report of a staff.shift_reported event to the active Admins
+	// with an email address. Subscribe it to that event; a report is emailed once, and a
+	// failure is returned so the relay retries the event.
+	HandleReportEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type shiftReportService struct {
+	shiftReportRepo repositories.ShiftReportRepository
+	staffRepo       repositories.StaffRepository
+	authRepo        repositories.AuthRepository
+	sender          MailSender // nil if email is not configured
+}
+
+// NewShiftReportService creates a new ShiftReportService. sender may be nil, which disables
+// emailing the reports.
+func NewShiftReportService(shiftReportRepo repositories.ShiftReportRepository, staffRepo repositories.StaffRepository,
+	authRepo repositories.AuthRepository, sender MailSender) ShiftReportService {
+	return &shiftReportService{
+		shiftReportRepo: shiftReportRepo,
+		staffRepo:       staffRepo,
+		authRepo:        authRepo,
+		sender:          sender,
+	}
+}
+
+// buildShiftReport computes the end-of-shift report of entry, which was just closed, within
+// the clock-out transaction so it sees the same orders as the clock-out.
+func buildShiftReport(executor repositories.SQLExecutor, repo repositories.ShiftReportRepository, staff *models.StaffMember,
+	entry *models.TimeClockEntry, req ClockOutRequest) (*models.ShiftReport, error) {
+	report := &models.ShiftReport{
+		BranchCode:       utils.BranchCode(),
+		TimeClockEntryID: entry.ID,
+		StaffID:          staff.ID,
+		StaffName:        staffDisplayName(staff),
+		StartedAt:        entry.ClockInAt,
+		EndedAt:          *entry.ClockOutAt,
+		HandoverNotes:    req.HandoverNotes,
+	}
+	if report.HandoverNotes != nil && strings.TrimSpace(*report.HandoverNotes) == "" {
+		report.HandoverNotes = nil
+	}
+
+	shiftID, err := repo.FindOverlappingShift(executor, staff.ID, report.StartedAt, report.EndedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find scheduled shift: %w", err)
+	}
+	report.ShiftID = shiftID
+
+	sales, err := repo.GetSalesByPayment(executor, report.BranchCode, ShiftSalesOrderStatuses, report.StartedAt, report.EndedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total shift sales: %w", err)
+	}
+	report.SalesByPayment = sales
+	for _, total := range sales {
+		report.OrderCount += total.OrderCount
+		report.SalesTotal = report.SalesTotal.Add(total.Amount)
+		if total.PaymentMethod == models.PaymentMethodCash {
+			report.Cash.CashSales = total.Amount
+		}
+	}
+
+	report.SessionCount, report.SessionTotal, err = repo.GetStoppedSessionTotals(executor, report.StartedAt, report.EndedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total shift table sessions: %w", err)
+	}
+
+	report.Cash.CashAccountPayments, err = repo.GetAccountPaymentsTotal(executor, models.PaymentMethodCash, report.StartedAt, report.EndedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total house account payments: %w", err)
+	}
+	report.Cash.CashDeposits, err = repo.GetCashDepositsTotal(executor, report.StartedAt, report.EndedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total cash deposits: %w", err)
+	}
+	if req.OpeningCash != nil {
+		report.Cash.OpeningCash = *req.OpeningCash
+	}
+	report.Cash.ExpectedCash = report.Cash.OpeningCash.Add(report.Cash.CashSales).Add(report.Cash.CashAccountPayments).
+		Add(report.Cash.CashDeposits)
+	if req.CountedCash != nil {
+		counted := *req.CountedCash
+		difference := counted.Sub(report.Cash.ExpectedCash)
+		report.Cash.CountedCash = &counted
+		report.Cash.Difference = &difference
+	}
+
+	report.OpenOrders, err = repo.GetOpenOrders(executor, report.BranchCode, OpenOrderStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open orders: %w", err)
+	}
+
+	if err := repo.CreateReport(executor, report); err != nil {
+		return nil, fmt.Errorf("failed to save shift report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *shiftReportService) GetReport(id int64) (*models.ShiftReport, error) {
+	report, err := s.shiftReportRepo.GetReportByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrShiftReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get shift report: %w", err)
+	}
+	if report.BranchCode != utils.BranchCode() {
+		return nil, ErrShiftReportNotFound
+	}
+	return report, nil
+}
+
+func (s *shiftReportService) GetReportForShift(shiftID int64) (*models.ShiftReport, error) {
+	if _, err := s.staffRepo.GetShiftByID(shiftID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrShiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+	report, err := s.shiftReportRepo.GetLatestReportForShift(shiftID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrShiftReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get shift report: %w", err)
+	}
+	if report.BranchCode != utils.BranchCode() {
+		return nil, ErrShiftReportNotFound
+	}
+	return report, nil
+}
+
+func (s *shiftReportService) GetReports(filters models.ShiftReportFilters) ([]models.ShiftReport, error) {
+	reports, err := s.shiftReportRepo.GetReports(utils.BranchCode(), filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (s *shiftReportService) HandleReportEvent(ctx context.Context, event models.DomainEvent) error {
+	if s.sender == nil {
+		return nil
+	}
+	var payload struct {
+		ReportID int64 `json:"report_id"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+	}
+	report, err := s.shiftReportRepo.GetReportByID(payload.ReportID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) { // Deleted with its staff member
+			return nil
+		}
+		return fmt.Errorf("failed to get shift report: %w", err)
+	}
+	if report.EmailedAt != nil {
+		return nil
+	}
+	recipients, err := s.authRepo.GetAdminEmails()
+	if err != nil {
+		return fmt.Errorf("failed to get admin emails: %w", err)
+	}
+	if len(recipients) == 0 {
+		utils.LogWarn("No Admin has an email address; shift report not emailed", map[string]interface{}{"report_id": report.ID})
+		return nil
+	}
+
+	subject := fmt.Sprintf("Shift report: %s, %s", report.StaffName, utils.FormatClubTime(report.EndedAt, "2006-01-02 15:04"))
+	if err := s.sender.Send(ctx, recipients, subject, renderShiftReport(report)); err != nil {
+		return fmt.Errorf("failed to email shift report ID %d: %w", report.ID, err)
+	}
+	if err := s.shiftReportRepo.MarkReportEmailed(report.ID, utils.NowUTC()); err != nil {
+		return fmt.Errorf("failed to mark shift report ID %d emailed: %w", report.ID, err)
+	}
+	return nil
+}
+
+// renderShiftReport formats a report as the plain text of its email.
+func renderShiftReport(report *models.ShiftReport) string {
+	var b strings.Builder
+	separator := strings.Repeat("-", receiptWidth) + "\n"
+	b.WriteString(fmt.Sprintf("Shift report #%d\n", report.ID))
+	b.WriteString(report.StaffName + "\n")
+	b.WriteString(utils.FormatClubTime(report.StartedAt, "2006-01-02 15:04") + " - " + utils.FormatClubTime(report.EndedAt, "2006-01-02 15:04") + "\n")
+
+	b.WriteString(separator)
+	b.WriteString(receiptLine(fmt.Sprintf("Orders (%d)", report.OrderCount), report.SalesTotal.String()))
+	for _, total := range report.SalesByPayment {
+		b.WriteString(receiptLine(fmt.Sprintf("  %s (%d)", total.PaymentMethod, total.OrderCount), total.Amount.String()))
+	}
+	b.WriteString(receiptLine(fmt.Sprintf("Table sessions (%d)", report.SessionCount), report.SessionTotal.String()))
+
+	b.WriteString(separator)
+	b.WriteString(receiptLine("Opening cash", report.Cash.OpeningCash.String()))
+	b.WriteString(receiptLine("Cash sales", report.Cash.CashSales.String()))
+	b.WriteString(receiptLine("Account payments", report.Cash.CashAccountPayments.String()))
+	if !report.Cash.CashDeposits.IsZero() {
+		b.WriteString(receiptLine("Session deposits", report.Cash.CashDeposits.String()))
+	}
+	b.WriteString(receiptLine("Expected cash", report.Cash.ExpectedCash.String()))
+	if report.Cash.CountedCash != nil {
+		b.WriteString(receiptLine("Counted cash", report.Cash.CountedCash.String()))
+		b.WriteString(receiptLine("Difference", report.Cash.Difference.String()))
+	} else {
+		b.WriteString(receiptLine("Counted cash", "not counted"))
+	}
+
+	b.WriteString(separator)
+	b.WriteString(fmt.Sprintf("Open orders: %d\n", len(report.OpenOrders)))
+	for _, order := range report.OpenOrders {
+		b.WriteString(receiptLine(fmt.Sprintf("  %s %s", order.OrderNumber, order.Status), order.FinalAmount.String()))
+	}
+
+	if report.HandoverNotes != nil {
+		b.WriteString(separator)
+		b.WriteString("Handover notes:\n")
+		b.WriteString(*report.HandoverNotes + "\n")
+	}
+	return b.String()
+}
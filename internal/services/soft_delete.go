@@ -0,0 +1,42 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	"ps_club_backend/pkg/utils"
+)
+
+// softDeleteChange runs change, the soft delete or restore of the record of entity (one of the
+// ReferenceEntity constants) with the ID, and records it in the audit log as action
+// (AuditActionRecordDeleted or AuditActionRecordRestored) of userID, in one transaction.
+// Errors of change are returned as is.
+func softDeleteChange(db *sql.DB, auditLogRepo repositories.AuditLogRepository, action, entity string, id, userID int64, change func(tx *sql.Tx, now time.Time) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := utils.NowUTC()
+	if err := change(tx, now); err != nil {
+		return err
+	}
+	details := fmt.Sprintf("%s %d", entity, id)
+	entry := &models.AuditLogEntry{
+		UserID:    &userID,
+		Action:    action,
+		Details:   &details,
+		CreatedAt: now,
+	}
+	if err := auditLogRepo.CreateEntry(tx, entry); err != nil {
+		return fmt.Errorf("failed to record %s of %s: %w", action, details, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s of %s: %w", action, details, err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrStaffDocumentValidation = apperrors.New(utils.ErrCodeValidationFailed, "staff document validation error")
+	ErrStaffDocumentNotFound   = apperrors.New(utils.ErrCodeNotFound, "staff document not found")
+)
+
+// DefaultStaffDocumentDays is how far ahead the expiring staff documents report looks by default.
+const DefaultStaffDocumentDays = 30
+
+// StaffDocumentNoticeDays is how many days before a staff document expires the Admins are
+// notified of it.
+var StaffDocumentNoticeDays = 30
+
+// StaffDocumentCheckInterval is how often RunExpiryCheck looks for staff documents to notify of.
+var StaffDocumentCheckInterval = 6 * time.Hour
+
+// --- StaffDocumentService Interface ---
+type StaffDocumentService interface {
+	// GetDocuments lists the documents of a staff member, newest first.
+	GetDocuments(staffID int64) ([]models.StaffDocument, error)
+	// AddDocument stores a JPEG, PNG or WebP photo or scan of a document of a staff member,
+	// valid through expiresOn (YYYY-MM-DD) if set. A certificate needs a title.
+	AddDocument(staffID int64, documentType string, title, expiresOn *string, data []byte, uploadedBy int64) (*models.StaffDocument, error)
+	GetDocumentPhoto(staffID, documentID int64) (*models.Photo, error)
+	DeleteDocument(staffID, documentID int64) error
+	// GetExpiringDocuments reports the documents that expire within the next days, including
+	// those already expired, unless a newer document of the same type and title replaced them.
+	GetExpiringDocuments(days int) ([]models.ExpiringStaffDocument, error)
+	// NotifyExpiring publishes a staff.document_expiry event for each document that expires
+	// within StaffDocumentNoticeDays and was not notified of yet, and returns them.
+	NotifyExpiring() ([]models.StaffDocument, error)
+	// RunExpiryCheck runs NotifyExpiring at start and every StaffDocumentCheckInterval until ctx
+	// is done. Every instance may run it; a document is notified of only once.
+	RunExpiryCheck(ctx context.Context)
+	// HandleExpiryEvent emails the document of a staff.document_expiry event to the active
+	// Admins with an email address. A failure is returned so the relay retries the event.
+	HandleExpiryEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type staffDocumentService struct {
+	documentRepo repositories.StaffDocumentRepository
+	staffRepo    repositories.StaffRepository
+	authRepo     repositories.AuthRepository
+	publisher    events.Publisher // Records the notices in their transaction
+	sender       MailSender       // nil if email is not configured
+	db           *sql.DB
+}
+
+// NewStaffDocumentService creates a new StaffDocumentService. sender may be nil, which disables
+// emailing the expiry notices.
+func NewStaffDocumentService(documentRepo repositories.StaffDocumentRepository, staffRepo repositories.StaffRepository,
+	authRepo repositories.AuthRepository, publisher events.Publisher, sender MailSender, db *sql.DB) StaffDocumentService {
+	return &staffDocumentService{
+		documentRepo: documentRepo,
+		staffRepo:    staffRepo,
+		authRepo:     authRepo,
+		publisher:    publisher,
+		sender:       sender,
+		db:           db,
+	}
+}
+
+// daysUntil returns the days from today (club time) to a date (YYYY-MM-DD), negative if it passed.
+func daysUntil(date string, today time.Time) (int, error) {
+	day, err := time.Parse(utils.DateLayout, date)
+	if err != nil {
+		return 0, err
+	}
+	todayDate, _ := time.Parse(utils.DateLayout, today.Format(utils.DateLayout))
+	return int(day.Sub(todayDate).Hours() / 24), nil
+}
+
+// checkStaffExists returns ErrStaffNotFound if there is no such staff member.
+func (s *staffDocumentService) checkStaffExists(staffID int64) error {
+	if _, err := s.staffRepo.GetStaffMemberByID(staffID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrStaffNotFound
+		}
+		return fmt.Errorf("failed to get staff member: %w", err)
+	}
+	return nil
+}
+
+func (s *staffDocumentService) GetDocuments(staffID int64) ([]models.StaffDocument, error) {
+	if err := s.checkStaffExists(staffID); err != nil {
+		return nil, err
+	}
+	documents, err := s.documentRepo.GetDocuments(staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff documents: %w", err)
+	}
+	today := utils.NowInClub().Format(utils.DateLayout)
+	for i := range documents {
+		documents[i].Expired = documents[i].ExpiresOn != nil && *documents[i].ExpiresOn < today
+	}
+	return documents, nil
+}
+
+func (s *staffDocumentService) AddDocument(staffID int64, documentType string, title, expiresOn *string, data []byte, uploadedBy int64) (*models.StaffDocument, error) {
+	if !slices.Contains(models.StaffDocumentTypes, documentType) {
+		return nil, fmt.Errorf("%w: document_type must be one of %v", ErrStaffDocumentValidation, models.StaffDocumentTypes)
+	}
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		title = &trimmed
+		if trimmed == "" {
+			title = nil
+		} else if len(trimmed) > 255 {
+			return nil, fmt.Errorf("%w: title must be at most 255 characters", ErrStaffDocumentValidation)
+		}
+	}
+	if documentType == models.StaffDocumentCertificate && title == nil {
+		return nil, fmt.Errorf("%w: a certificate needs a title", ErrStaffDocumentValidation)
+	}
+	if expiresOn != nil {
+		trimmed := strings.TrimSpace(*expiresOn)
+		expiresOn = &trimmed
+		if trimmed == "" {
+			expiresOn = nil
+		} else if !utils.IsValidDate(trimmed) {
+			return nil, fmt.Errorf("%w: invalid expiry date, use YYYY-MM-DD", ErrStaffDocumentValidation)
+		}
+	}
+	contentType, err := checkPhoto(data, ErrStaffDocumentValidation)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkStaffExists(staffID); err != nil {
+		return nil, err
+	}
+
+	document := &models.StaffDocument{StaffID: staffID, DocumentType: documentType, Title: title, ExpiresOn: expiresOn, UploadedBy: &uploadedBy}
+	if err := s.documentRepo.AddDocument(document, &models.Photo{ContentType: contentType, Data: data}); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to add staff document: %w", err)
+	}
+	document.Expired = expiresOn != nil && *expiresOn < utils.NowInClub().Format(utils.DateLayout)
+	return document, nil
+}
+
+func (s *staffDocumentService) GetDocumentPhoto(staffID, documentID int64) (*models.Photo, error) {
+	photo, err := s.documentRepo.GetDocumentPhoto(staffID, documentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get staff document: %w", err)
+	}
+	return photo, nil
+}
+
+func (s *staffDocumentService) DeleteDocument(staffID, documentID int64) error {
+	if err := s.documentRepo.DeleteDocument(staffID, documentID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return ErrStaffDocumentNotFound
+		}
+		return fmt.Errorf("failed to delete staff document: %w", err)
+	}
+	return nil
+}
+
+func (s *staffDocumentService) GetExpiringDocuments(days int) ([]models.ExpiringStaffDocument, error) {
+	if days < 0 || days > 365 {
+		return nil, fmt.Errorf("%w: days must be between 0 and 365", ErrStaffDocumentValidation)
+	}
+	today := utils.NowInClub()
+	documents, err := s.documentRepo.GetExpiringDocuments(today.AddDate(0, 0, days).Format(utils.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring staff documents: %w", err)
+	}
+
+	expiring := make([]models.ExpiringStaffDocument, 0, len(documents))
+	for _, document := range documents {
+		daysLeft, err := daysUntil(*document.ExpiresOn, today)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry date of staff document ID %d: %w", document.ID, err)
+		}
+		document.Expired = daysLeft < 0
+		expiring = append(expiring, models.ExpiringStaffDocument{StaffDocument: document, DaysUntilExpiry: daysLeft})
+	}
+	return expiring, nil
+}
+
+func (s *staffDocumentService) NotifyExpiring() ([]models.StaffDocument, error) {
+	until := utils.NowInClub().AddDate(0, 0, StaffDocumentNoticeDays).Format(utils.DateLayout)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locks the documents, so a check running elsewhere finds them notified
+	documents, err := s.documentRepo.MarkExpiryNotified(tx, until, utils.NowUTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark expiring staff documents notified: %w", err)
+	}
+	for _, document := range documents {
+		payload := events.StaffDocumentPayload{
+			DocumentID:   document.ID,
+			StaffID:      document.StaffID,
+			StaffName:    document.StaffName,
+			DocumentType: document.DocumentType,
+			Title:        document.Title,
+			ExpiresOn:    *document.ExpiresOn,
+		}
+		if err := s.publisher.Publish(tx, events.StaffDocumentExpiry, events.AggregateStaff, document.StaffID, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit staff document notices: %w", err)
+	}
+	return documents, nil
+}
+
+func (s *staffDocumentService) RunExpiryCheck(ctx context.Context) {
+	ticker := time.NewTicker(StaffDocumentCheckInterval)
+	defer ticker.Stop()
+	for {
+		notified, err := s.NotifyExpiring()
+		if err != nil {
+			utils.LogError(err, "Failed to notify of expiring staff documents")
+		} else if len(notified) > 0 {
+			utils.LogInfo("Notified of expiring staff documents", map[string]interface{}{"documents": len(notified)})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *staffDocumentService) HandleExpiryEvent(ctx context.Context, event models.DomainEvent) error {
+	if s.sender == nil {
+		return nil
+	}
+	var payload events.StaffDocumentPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", event.EventType, err)
+	}
+	recipients, err := s.authRepo.GetAdminEmails()
+	if err != nil {
+		return fmt.Errorf("failed to get admin emails: %w", err)
+	}
+	if len(recipients) == 0 {
+		utils.LogWarn("No Admin has an email address; staff document expiry not emailed", map[string]interface{}{"document_id": payload.DocumentID})
+		return nil
+	}
+
+	document := payload.DocumentType
+	if payload.Title != nil {
+		document += " (" + *payload.Title + ")"
+	}
+	verb := "expires"
+	if payload.ExpiresOn < utils.NowInClub().Format(utils.DateLayout) {
+		verb = "expired"
+	}
+	subject := fmt.Sprintf("Staff document %s: %s, %s", verb, payload.StaffName, payload.DocumentType)
+	body := fmt.Sprintf("The %s of %s %s on %s.\nUpload the renewed document under /staff/%d/documents.\n",
+		document, payload.StaffName, verb, payload.ExpiresOn, payload.StaffID)
+	if err := s.sender.Send(ctx, recipients, subject, body); err != nil {
+		return fmt.Errorf("failed to email expiry of staff document ID %d: %w", payload.DocumentID, err)
+	}
+	return nil
+}
@@ -4,44 +4,63 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"ps_club_backend/internal/events"
 	"ps_club_backend/internal/models"
 	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
 	"strings"
 	"time"
 )
 
 // --- Custom Service Errors for Staff ---
 var (
-	ErrStaffNotFound       = errors.New("staff member not found")
-	ErrUserForStaffNotFound= errors.New("user account for staff member not found")
-	ErrStaffUserConflict   = errors.New("user ID is already associated with another staff member")
-	ErrShiftNotFound       = errors.New("shift not found")
-	ErrShiftValidation     = errors.New("shift validation error (e.g., end time before start time)")
-	ErrShiftOverlap        = errors.New("shift overlaps with an existing shift for the staff member") 
-	ErrStaffDataValidation = errors.New("staff data validation error")
-	ErrHireDateFormat      = errors.New("invalid hire date format, please use YYYY-MM-DD")
-	ErrShiftTimeFormat     = errors.New("invalid time format for shift, please use YYYY-MM-DDTHH:MM:SSZ or RFC3339 like format")
-	ErrStaffInUse          = errors.New("staff member cannot be deleted as they are referenced in other records")
+	ErrStaffNotFound        = apperrors.New(utils.ErrCodeNotFound, "staff member not found")
+	ErrUserForStaffNotFound = apperrors.New(utils.ErrCodeBadRequest, "user account for staff member not found")
+	ErrStaffUserConflict    = apperrors.New(utils.ErrCodeConflict, "user ID is already associated with another staff member")
+	ErrShiftNotFound        = apperrors.New(utils.ErrCodeNotFound, "shift not found")
+	ErrShiftValidation      = apperrors.New(utils.ErrCodeValidationFailed, "shift validation error (e.g., end time before start time)")
+	ErrShiftOverlap         = apperrors.New(utils.ErrCodeConflict, "shift overlaps with an existing shift for the staff member")
+	ErrShiftOnTimeOff       = apperrors.New(utils.ErrCodeConflict, "shift falls on approved time off of the staff member")
+	ErrStaffDataValidation  = apperrors.New(utils.ErrCodeValidationFailed, "staff data validation error")
+	ErrHireDateFormat       = apperrors.New(utils.ErrCodeValidationFailed, "invalid hire date format, please use YYYY-MM-DD")
+	ErrShiftTimeFormat      = apperrors.New(utils.ErrCodeValidationFailed, "invalid time format for shift, please use YYYY-MM-DDTHH:MM:SSZ or RFC3339 like format")
+	ErrStaffInUse           = apperrors.New(utils.ErrCodeConflict, "staff member cannot be deleted as they are referenced in other records")
+	ErrNoStaffProfile       = apperrors.New(utils.ErrCodeForbidden, "no staff member is linked to the current user")
+	ErrAlreadyClockedIn     = apperrors.New(utils.ErrCodeConflict, "staff member is already clocked in")
+	ErrNotClockedIn         = apperrors.New(utils.ErrCodeConflict, "staff member is not clocked in")
+	ErrClockInRestricted    = apperrors.New(utils.ErrCodeClockInRestricted, "clock-in is only allowed from the club's network or premises")
+)
+
+var (
+	ErrClockInLocation            = apperrors.New(utils.ErrCodeValidationFailed, "invalid device coordinates")
+	ErrClockInViolationNotFound   = apperrors.New(utils.ErrCodeNotFound, "clock-in violation not found")
+	ErrClockInViolationOverridden = apperrors.New(utils.ErrCodeConflict, "the clock-in violation was already overridden")
 )
 
 // --- StaffMember DTOs ---
 type CreateStaffMemberRequest struct { 
 	UserID      int64    `json:"user_id" binding:"required"`
-	PhoneNumber *string  `json:"phone_number"`
+	PhoneNumber *string  `json:"phone_number" binding:"omitempty,phone"`
 	Address     *string  `json:"address"`
-	HireDate    *string  `json:"hire_date"` 
+	HireDate    *string  `json:"hire_date" binding:"omitempty,date"`
 	Position    *string  `json:"position" binding:"required"`
-	Salary      *float64 `json:"salary"`
+	Salary      *models.Money `json:"salary" binding:"omitempty,money"`
 }
 
 type UpdateStaffMemberRequest struct {
-	PhoneNumber *string  `json:"phone_number"`
+	PhoneNumber *string  `json:"phone_number" binding:"omitempty,phone"`
 	Address     *string  `json:"address"`
-	HireDate    *string  `json:"hire_date"`
+	HireDate    *string  `json:"hire_date" binding:"omitempty,date"`
 	Position    *string  `json:"position"`
-	Salary      *float64 `json:"salary"`
+	Salary      *models.Money `json:"salary" binding:"omitempty,money"`
+	CallerRole  string   `json:"-"` // Role of the authenticated user; limits the fields it may change (staffFieldRules)
+	Clear       []string `json:"-"` // Fields to set to null, from a merge patch; see StaffClearableFields
 }
 
+// StaffClearableFields are the fields of UpdateStaffMemberRequest a merge patch may set to null.
+var StaffClearableFields = []string{"phone_number", "address", "hire_date", "salary"}
+
 // --- Shift DTOs ---
 type CreateShiftRequest struct {
 	StaffID   int64   `json:"staff_id" binding:"required"`
@@ -56,6 +75,21 @@ type UpdateShiftRequest struct {
 	Notes     *string `json:"notes"`
 }
 
+// ClockInRequest is the optional body of POST /shifts/clock-in, with the device coordinates
+// checked against the clock_in setting.
+type ClockInRequest struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	IPAddress string   `json:"-"` // Of the request, set by the handler
+}
+
+// ClockOutRequest is the optional body of POST /shifts/clock-out, for the end-of-shift report.
+type ClockOutRequest struct {
+	OpeningCash   *models.Money `json:"opening_cash" binding:"omitempty,money"` // Cash in the drawer at clock-in; defaults to 0
+	CountedCash   *models.Money `json:"counted_cash" binding:"omitempty,money"` // Cash counted in the drawer at clock-out
+	HandoverNotes *string       `json:"handover_notes" binding:"omitempty,max=4000"`
+}
+
 // --- StaffService Interface ---
 type StaffService interface {
 	// StaffMember methods
@@ -69,24 +103,44 @@ type StaffService interface {
 	// Shift methods
 	CreateShift(req CreateShiftRequest) (*models.Shift, error)
 	GetShiftByID(shiftID int64) (*models.Shift, error)
-	GetShifts(staffID *int64, startTimeFromStr *string, startTimeToStr *string, page, pageSize int) ([]models.Shift, int, error)
+	// GetShifts lists the shifts of staffID, if set, starting in [startTimeFrom, startTimeTo); nil
+	// bounds are open.
+	GetShifts(staffID *int64, startTimeFrom, startTimeTo *time.Time, page, pageSize int) ([]models.Shift, int, error)
 	UpdateShift(shiftID int64, req UpdateShiftRequest) (*models.Shift, error)
 	DeleteShift(shiftID int64) error
+
+	// Time clock methods, for the staff member linked to the user
+	// ClockIn opens an entry if the request comes from where the clock_in setting allows; otherwise
+	// it records a violation and returns ErrClockInRestricted.
+	ClockIn(userID int64, req ClockInRequest) (*models.TimeClockEntry, error)
+	// ClockOut closes the open entry and saves its end-of-shift report, returned in the entry.
+	ClockOut(userID int64, req ClockOutRequest) (*models.TimeClockEntry, error)
+	// ClockOutStaff clocks out a staff member on their behalf, e.g. at the day close.
+	ClockOutStaff(staffID int64, req ClockOutRequest) (*models.TimeClockEntry, error)
+	// GetClockInViolations lists the refused clock-ins matching filters, latest first.
+	GetClockInViolations(filters models.ClockInViolationFilters) ([]models.ClockInViolation, error)
+	// OverrideClockInViolation clocks the staff member of a refused clock-in in as of the attempt.
+	OverrideClockInViolation(id int64, adminUserID int64) (*models.ClockInViolation, error)
 }
 
 // --- staffService Implementation ---
 type staffService struct {
-	staffRepo repositories.StaffRepository
-	userRepo  repositories.AuthRepository 
-	db        *sql.DB
+	staffRepo       repositories.StaffRepository
+	userRepo        repositories.AuthRepository 
+	shiftReportRepo repositories.ShiftReportRepository
+	publisher       events.Publisher // Records clock-ins and clock-outs in the transaction of the entry
+	db              *sql.DB
 }
 
 // NewStaffService creates a new instance of StaffService.
-func NewStaffService(sr repositories.StaffRepository, ur repositories.AuthRepository, db *sql.DB) StaffService {
+func NewStaffService(sr repositories.StaffRepository, ur repositories.AuthRepository, shiftReportRepo repositories.ShiftReportRepository,
+	publisher events.Publisher, db *sql.DB) StaffService {
 	return &staffService{
-		staffRepo: sr,
-		userRepo:  ur,
-		db:        db,
+		staffRepo:       sr,
+		userRepo:        ur,
+		shiftReportRepo: shiftReportRepo,
+		publisher:       publisher,
+		db:              db,
 	}
 }
 
@@ -102,14 +156,12 @@ func parseDate(dateStrPointer *string, format string, errorToReturn error) (*str
     return &dateStr, nil 
 }
 
+// parseDateTime accepts RFC3339 or a naive local time string. Naive values are
+// interpreted in the club timezone; the result is always normalized to UTC.
 func parseDateTime(dateTimeStr string, errorToReturn error) (time.Time, error) {
-    parsedTime, err := time.Parse(time.RFC3339, dateTimeStr)
+    parsedTime, err := utils.ParseClubDateTime(dateTimeStr)
     if err != nil {
-		// Try parsing without timezone if RFC3339 fails (common if client sends local time string)
-		parsedTime, err = time.Parse("2006-01-02T15:04:05", dateTimeStr)
-		if err != nil {
-			return time.Time{}, errorToReturn
-		}
+		return time.Time{}, errorToReturn
     }
     return parsedTime, nil
 }
@@ -140,13 +192,17 @@ func (s *staffService) CreateStaffMember(req CreateStaffMemberRequest) (*models.
 	if req.Position == nil || strings.TrimSpace(*req.Position) == "" {
 		return nil, fmt.Errorf("%w: position cannot be empty", ErrStaffDataValidation)
 	}
-	if req.Salary != nil && *req.Salary < 0 {
+	if req.Salary != nil && req.Salary.IsNegative() {
 		return nil, fmt.Errorf("%w: salary cannot be negative", ErrStaffDataValidation)
 	}
+	phoneNumber, err := normalizePhone(req.PhoneNumber, ErrStaffDataValidation)
+	if err != nil {
+		return nil, err
+	}
 
 	staff := &models.StaffMember{
 		UserID:      &req.UserID,
-		PhoneNumber: req.PhoneNumber,
+		PhoneNumber: phoneNumber, // In E.164 form
 		Address:     req.Address,
 		HireDate:    hireDateStrPtr,
 		Position:    req.Position,
@@ -202,7 +258,25 @@ func (s *staffService) UpdateStaffMember(staffID int64, req UpdateStaffMemberReq
 		return nil, fmt.Errorf("failed to find staff member for update: %w", err)
 	}
 
-	if req.PhoneNumber != nil { staff.PhoneNumber = req.PhoneNumber }
+	if err := checkClearable(req.Clear, StaffClearableFields, ErrStaffDataValidation); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	if stringChanged(staff.HireDate, req.HireDate) || (clears(req.Clear, "hire_date") && staff.HireDate != nil) { changed = append(changed, "hire_date") }
+	if stringChanged(staff.Position, req.Position) { changed = append(changed, "position") }
+	if moneyChanged(staff.Salary, req.Salary) || (clears(req.Clear, "salary") && staff.Salary != nil) { changed = append(changed, "salary") }
+	if err := staffFieldRules.check(req.CallerRole, changed); err != nil {
+		return nil, err
+	}
+
+	if req.PhoneNumber != nil {
+		phoneNumber, err := normalizePhone(req.PhoneNumber, ErrStaffDataValidation)
+		if err != nil {
+			return nil, err
+		}
+		staff.PhoneNumber = phoneNumber // In E.164 form
+	}
 	if req.Address != nil { staff.Address = req.Address }
 	if req.HireDate != nil {
 		hd, parseErr := parseDate(req.HireDate, "2006-01-02", ErrHireDateFormat)
@@ -216,11 +290,15 @@ func (s *staffService) UpdateStaffMember(staffID int64, req UpdateStaffMemberReq
 		staff.Position = req.Position 
 	}
 	if req.Salary != nil { 
-		if *req.Salary < 0 {
+		if req.Salary.IsNegative() {
 			return nil, fmt.Errorf("%w: salary cannot be negative", ErrStaffDataValidation)
 		}
 		staff.Salary = req.Salary 
 	}
+	if clears(req.Clear, "phone_number") { staff.PhoneNumber = nil }
+	if clears(req.Clear, "address") { staff.Address = nil }
+	if clears(req.Clear, "hire_date") { staff.HireDate = nil }
+	if clears(req.Clear, "salary") { staff.Salary = nil }
 	
 	updatedStaff, err := s.staffRepo.UpdateStaffMember(s.db, staff)
 	if err != nil {
@@ -245,9 +323,9 @@ func (s *staffService) DeleteStaffMember(staffID int64) error {
 		if errors.Is(err, repositories.ErrNotFound) { 
 			return ErrStaffNotFound
 		}
-		if strings.Contains(err.Error(), "violates foreign key constraint") {
-             return ErrStaffInUse
-        }
+		if errors.Is(err, repositories.ErrReferenced) {
+			return ErrStaffInUse
+		}
 		return fmt.Errorf("failed to delete staff member: %w", err)
 	}
 	return nil
@@ -277,6 +355,10 @@ func (s *staffService) CreateShift(req CreateShiftRequest) (*models.Shift, error
 		return nil, fmt.Errorf("failed to validate staff member for shift: %w", err)
 	}
 	
+	if err := s.checkTimeOff(req.StaffID, startTime, endTime); err != nil {
+		return nil, err
+	}
+
 	// TODO: Shift overlap validation
 	// existingShifts, _, _ := s.staffRepo.GetShifts(&req.StaffID, &startTime, &endTime, 1, 1)
 	// if len(existingShifts) > 0 { return nil, ErrShiftOverlap }
@@ -295,6 +377,21 @@ func (s *staffService) CreateShift(req CreateShiftRequest) (*models.Shift, error
 	return s.staffRepo.GetShiftByID(createdShift.ID)
 }
 
+// checkTimeOff returns ErrShiftOnTimeOff if approved time off of a staff member covers a day
+// (club time) of the shift from start to end.
+func (s *staffService) checkTimeOff(staffID int64, start, end time.Time) error {
+	firstDay := utils.FormatClubTime(start, utils.DateLayout)
+	lastDay := utils.FormatClubTime(end.Add(-time.Nanosecond), utils.DateLayout)
+	onTimeOff, err := s.staffRepo.HasApprovedTimeOff(staffID, firstDay, lastDay)
+	if err != nil {
+		return fmt.Errorf("failed to check time off of staff member: %w", err)
+	}
+	if onTimeOff {
+		return ErrShiftOnTimeOff
+	}
+	return nil
+}
+
 func (s *staffService) GetShiftByID(shiftID int64) (*models.Shift, error) {
 	shift, err := s.staffRepo.GetShiftByID(shiftID)
 	if err != nil {
@@ -306,24 +403,10 @@ func (s *staffService) GetShiftByID(shiftID int64) (*models.Shift, error) {
 	return shift, nil
 }
 
-func (s *staffService) GetShifts(staffID *int64, startTimeFromStr *string, startTimeToStr *string, page, pageSize int) ([]models.Shift, int, error) {
+func (s *staffService) GetShifts(staffID *int64, startTimeFrom, startTimeTo *time.Time, page, pageSize int) ([]models.Shift, int, error) {
 	if page <= 0 { page = 1 }
 	if pageSize <= 0 { pageSize = 10 }
 
-	var startTimeFrom, startTimeTo *time.Time
-	var err error
-
-	if startTimeFromStr != nil && strings.TrimSpace(*startTimeFromStr) != "" {
-		t, parseErr := parseDateTime(*startTimeFromStr, ErrShiftTimeFormat)
-		if parseErr != nil { return nil, 0, fmt.Errorf("start_time_from: %w", parseErr) }
-		startTimeFrom = &t
-	}
-	if startTimeToStr != nil && strings.TrimSpace(*startTimeToStr) != "" {
-		t, parseErr := parseDateTime(*startTimeToStr, ErrShiftTimeFormat)
-		if parseErr != nil { return nil, 0, fmt.Errorf("start_time_to: %w", parseErr) }
-		startTimeTo = &t
-	}
-	
 	if startTimeFrom != nil && startTimeTo != nil && !startTimeTo.After(*startTimeFrom) {
         return nil, 0, fmt.Errorf("%w: end time filter must be after start time filter", ErrShiftValidation)
     }
@@ -372,6 +455,10 @@ func (s *staffService) UpdateShift(shiftID int64, req UpdateShiftRequest) (*mode
 		shift.Notes = req.Notes
 	}
 	
+	if err := s.checkTimeOff(shift.StaffID, shift.StartTime, shift.EndTime); err != nil {
+		return nil, err
+	}
+
 	// TODO: Implement shift overlap validation for update if required
 	
 	updatedShift, err := s.staffRepo.UpdateShift(s.db, shift)
@@ -401,3 +488,208 @@ func (s *staffService) DeleteShift(shiftID int64) error {
 	}
 	return nil
 }
+
+// --- Time Clock Method Implementations ---
+
+// staffForUser returns the staff member linked to a user account.
+func (s *staffService) staffForUser(userID int64) (*models.StaffMember, error) {
+	staff, err := s.staffRepo.GetStaffMemberByUserID(userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrNoStaffProfile
+		}
+		return nil, fmt.Errorf("failed to find staff member for user: %w", err)
+	}
+	return staff, nil
+}
+
+// staffDisplayName returns the full name of the staff member's user, or the username.
+func staffDisplayName(staff *models.StaffMember) string {
+	if staff.User == nil {
+		return fmt.Sprintf("Staff #%d", staff.ID)
+	}
+	if staff.User.FullName != nil && *staff.User.FullName != "" {
+		return *staff.User.FullName
+	}
+	return staff.User.Username
+}
+
+func (s *staffService) ClockIn(userID int64, req ClockInRequest) (*models.TimeClockEntry, error) {
+	staff, err := s.staffForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude must be sent together", ErrClockInLocation)
+	}
+	if req.Latitude != nil && !models.ValidCoordinates(*req.Latitude, *req.Longitude) {
+		return nil, fmt.Errorf("%w: latitude must be between -90 and 90 and longitude between -180 and 180", ErrClockInLocation)
+	}
+
+	now := utils.NowUTC()
+	if reason, distance := CurrentClockInSettings().Check(req.IPAddress, req.Latitude, req.Longitude); reason != "" {
+		violation := &models.ClockInViolation{StaffID: staff.ID, Reason: reason, Latitude: req.Latitude, Longitude: req.Longitude,
+			DistanceMeters: distance, AttemptedAt: now}
+		if req.IPAddress != "" {
+			violation.IPAddress = &req.IPAddress
+		}
+		if err := s.staffRepo.CreateClockInViolation(violation); err != nil {
+			return nil, fmt.Errorf("failed to record clock-in violation: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %s (violation ID %d)", ErrClockInRestricted, reason, violation.ID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	entry, err := s.clockIn(tx, staff, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit clock-in: %w", err)
+	}
+	return entry, nil
+}
+
+// clockIn opens a time clock entry of staff at clockInAt in tx and publishes it.
+func (s *staffService) clockIn(tx *sql.Tx, staff *models.StaffMember, clockInAt time.Time) (*models.TimeClockEntry, error) {
+	entry, err := s.staffRepo.CreateTimeClockEntry(tx, &models.TimeClockEntry{StaffID: staff.ID, ClockInAt: clockInAt})
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrAlreadyClockedIn
+		}
+		return nil, fmt.Errorf("failed to clock in: %w", err)
+	}
+	payload := events.TimeClockPayload{EntryID: entry.ID, StaffID: staff.ID, StaffName: staffDisplayName(staff), ClockInAt: entry.ClockInAt}
+	if err := s.publisher.Publish(tx, events.StaffClockedIn, events.AggregateStaff, staff.ID, payload); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *staffService) GetClockInViolations(filters models.ClockInViolationFilters) ([]models.ClockInViolation, error) {
+	violations, err := s.staffRepo.GetClockInViolations(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clock-in violations: %w", err)
+	}
+	return violations, nil
+}
+
+func (s *staffService) OverrideClockInViolation(id int64, adminUserID int64) (*models.ClockInViolation, error) {
+	violation, err := s.staffRepo.GetClockInViolationByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClockInViolationNotFound
+		}
+		return nil, fmt.Errorf("failed to get clock-in violation: %w", err)
+	}
+	if violation.OverriddenAt != nil {
+		return nil, ErrClockInViolationOverridden
+	}
+	staff, err := s.staffRepo.GetStaffMemberByID(violation.StaffID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to get staff member: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	entry, err := s.clockIn(tx, staff, violation.AttemptedAt)
+	if err != nil {
+		return nil, err
+	}
+	now := utils.NowUTC()
+	if err := s.staffRepo.OverrideClockInViolation(tx, id, adminUserID, now, entry.ID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrClockInViolationOverridden // Overridden concurrently
+		}
+		return nil, fmt.Errorf("failed to override clock-in violation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit clock-in override: %w", err)
+	}
+	violation.OverriddenBy = &adminUserID
+	violation.OverriddenAt = &now
+	violation.TimeClockEntryID = &entry.ID
+	return violation, nil
+}
+
+func (s *staffService) ClockOut(userID int64, req ClockOutRequest) (*models.TimeClockEntry, error) {
+	staff, err := s.staffForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.clockOut(staff, req)
+}
+
+func (s *staffService) ClockOutStaff(staffID int64, req ClockOutRequest) (*models.TimeClockEntry, error) {
+	staff, err := s.staffRepo.GetStaffMemberByID(staffID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to get staff member: %w", err)
+	}
+	return s.clockOut(staff, req)
+}
+
+// clockOut closes the open time clock entry of staff and saves its end-of-shift report.
+func (s *staffService) clockOut(staff *models.StaffMember, req ClockOutRequest) (*models.TimeClockEntry, error) {
+	open, err := s.staffRepo.GetOpenTimeClockEntry(staff.ID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrNotClockedIn
+		}
+		return nil, fmt.Errorf("failed to find open time clock entry: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	entry, err := s.staffRepo.CloseTimeClockEntry(tx, open.ID, utils.NowUTC())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) { // Clocked out concurrently
+			return nil, ErrNotClockedIn
+		}
+		return nil, fmt.Errorf("failed to clock out: %w", err)
+	}
+	payload := events.TimeClockPayload{EntryID: entry.ID, StaffID: staff.ID, StaffName: staffDisplayName(staff), ClockInAt: entry.ClockInAt, ClockOutAt: entry.ClockOutAt}
+	if err := s.publisher.Publish(tx, events.StaffClockedOut, events.AggregateStaff, staff.ID, payload); err != nil {
+		return nil, err
+	}
+	report, err := buildShiftReport(tx, s.shiftReportRepo, staff, entry, req)
+	if err != nil {
+		return nil, err
+	}
+	reportPayload := events.ShiftReportPayload{
+		ReportID:         report.ID,
+		TimeClockEntryID: entry.ID,
+		StaffID:          staff.ID,
+		StaffName:        report.StaffName,
+		ShiftID:          report.ShiftID,
+		SalesTotal:       report.SalesTotal,
+		CashDifference:   report.Cash.Difference,
+		OpenOrderCount:   len(report.OpenOrders),
+	}
+	if err := s.publisher.Publish(tx, events.StaffShiftReported, events.AggregateStaff, staff.ID, reportPayload); err != nil {
+		return nil, err
+	}
+	entry.Report = report
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit clock-out: %w", err)
+	}
+	return entry, nil
+}
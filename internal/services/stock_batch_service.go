@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrStockBatchValidation = apperrors.New(utils.ErrCodeValidationFailed, "stock batch validation error")
+
+// DefaultExpiringDays is how far ahead the expiring stock report looks by default.
+const DefaultExpiringDays = 7
+
+// StockBatchWriteOffInterval is how often RunWriteOff writes off the expired batches.
+var StockBatchWriteOffInterval = time.Hour
+
+// --- StockBatchService Interface ---
+type StockBatchService interface {
+	// GetBatches returns the batches matching filters, first expiry first.
+	GetBatches(filters models.StockBatchFilters) ([]models.StockBatch, error)
+	// GetExpiringBatches returns the batches in stock that expire within days (club time),
+	// including those already expired, first expiry first.
+	GetExpiringBatches(days int) ([]models.ExpiringStockBatch, error)
+	// WriteOffExpired writes off the stock left in the batches past their expiry date as
+	// spoilage, recorded as by staffID if set, and returns the batches written off.
+	WriteOffExpired(staffID *int64) ([]models.StockBatch, error)
+	// RunWriteOff writes off the expired batches every StockBatchWriteOffInterval until ctx is
+	// done. Every instance may run it; a batch is written off only once.
+	RunWriteOff(ctx context.Context)
+}
+
+type stockBatchService struct {
+	batchRepo       repositories.StockBatchRepository
+	pricelistRepo   repositories.PricelistRepository
+	inventoryMvRepo repositories.InventoryMovementRepository
+	publisher       events.Publisher // Records the write-offs in their transaction
+	db              *sql.DB
+}
+
+// NewStockBatchService creates a new StockBatchService.
+func NewStockBatchService(batchRepo repositories.StockBatchRepository, pricelistRepo repositories.PricelistRepository,
+	inventoryMvRepo repositories.InventoryMovementRepository, publisher events.Publisher, db *sql.DB) StockBatchService {
+	return &stockBatchService{batchRepo: batchRepo, pricelistRepo: pricelistRepo, inventoryMvRepo: inventoryMvRepo, publisher: publisher, db: db}
+}
+
+func (s *stockBatchService) GetBatches(filters models.StockBatchFilters) ([]models.StockBatch, error) {
+	batches, err := s.batchRepo.GetBatches(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock batches: %w", err)
+	}
+	return batches, nil
+}
+
+func (s *stockBatchService) GetExpiringBatches(days int) ([]models.ExpiringStockBatch, error) {
+	if days < 0 || days > 365 {
+		return nil, fmt.Errorf("%w: days must be between 0 and 365", ErrStockBatchValidation)
+	}
+	today := utils.NowInClub()
+	until := today.AddDate(0, 0, days).Format(utils.DateLayout)
+	batches, err := s.batchRepo.GetBatches(models.StockBatchFilters{InStock: true, ExpiresOnOrBefore: &until})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring stock batches: %w", err)
+	}
+
+	todayDate, _ := time.Parse(utils.DateLayout, today.Format(utils.DateLayout))
+	expiring := make([]models.ExpiringStockBatch, 0, len(batches))
+	for _, batch := range batches {
+		expiry, err := time.Parse(utils.DateLayout, *batch.ExpiryDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry date of stock batch ID %d: %w", batch.ID, err)
+		}
+		daysLeft := int(expiry.Sub(todayDate).Hours() / 24)
+		expiring = append(expiring, models.ExpiringStockBatch{StockBatch: batch, DaysUntilExpiry: daysLeft, Expired: daysLeft < 0})
+	}
+	return expiring, nil
+}
+
+func (s *stockBatchService) WriteOffExpired(staffID *int64) ([]models.StockBatch, error) {
+	// A batch may be used through its expiry date
+	yesterday := utils.NowInClub().AddDate(0, 0, -1).Format(utils.DateLayout)
+	expired, err := s.batchRepo.GetBatches(models.StockBatchFilters{InStock: true, ExpiresOnOrBefore: &yesterday})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired stock batches: %w", err)
+	}
+	if len(expired) == 0 {
+		return []models.StockBatch{}, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	writtenOff := make([]models.StockBatch, 0, len(expired))
+	for _, batch := range expired {
+		// Locks the batch, so a write-off running elsewhere finds it empty
+		quantity, err := s.batchRepo.WriteOffBatch(tx, batch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write off stock batch: %w", err)
+		}
+		if quantity.IsZero() {
+			continue
+		}
+		if _, err := s.pricelistRepo.UpdateStock(tx, batch.PricelistItemID, quantity.Neg()); err != nil {
+			return nil, fmt.Errorf("failed to update stock for item ID %d: %w", batch.PricelistItemID, err)
+		}
+		reason := fmt.Sprintf("Batch expired on %s", *batch.ExpiryDate)
+		if batch.BatchNumber != nil {
+			reason = fmt.Sprintf("Batch %s expired on %s", *batch.BatchNumber, *batch.ExpiryDate)
+		}
+		movement := models.InventoryMovement{
+			PricelistItemID: batch.PricelistItemID,
+			StaffID:         staffID,
+			MovementType:    models.MovementTypeSpoilage,
+			QuantityChanged: quantity.Neg(),
+			Reason:          &reason,
+			MovementDate:    time.Now().UTC(),
+		}
+		if _, err := s.inventoryMvRepo.CreateMovement(tx, &movement); err != nil {
+			return nil, fmt.Errorf("failed to record write-off of stock batch ID %d: %w", batch.ID, err)
+		}
+		if err := s.batchRepo.SetWriteOffMovement(tx, batch.ID, movement.ID); err != nil {
+			return nil, fmt.Errorf("failed to link write-off of stock batch ID %d: %w", batch.ID, err)
+		}
+		payload := events.InventoryPayload{
+			MovementID:      movement.ID,
+			PricelistItemID: batch.PricelistItemID,
+			ItemName:        batch.ItemName,
+			MovementType:    movement.MovementType,
+			Quantity:        quantity,
+			StockUnit:       batch.StockUnit,
+			StaffID:         staffID,
+			Reason:          &reason,
+		}
+		if err := s.publisher.Publish(tx, events.InventoryWrittenOff, events.AggregatePricelistItem, batch.PricelistItemID, payload); err != nil {
+			return nil, err
+		}
+
+		now := movement.MovementDate
+		batch.QuantityRemaining = models.ZeroQuantity
+		batch.WrittenOffAt = &now
+		batch.WriteOffMovementID = &movement.ID
+		writtenOff = append(writtenOff, batch)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit stock batch write-offs: %w", err)
+	}
+	return writtenOff, nil
+}
+
+func (s *stockBatchService) RunWriteOff(ctx context.Context) {
+	ticker := time.NewTicker(StockBatchWriteOffInterval)
+	defer ticker.Stop()
+	for {
+		writtenOff, err := s.WriteOffExpired(nil)
+		if err != nil {
+			utils.LogError(err, "Failed to write off expired stock batches")
+		} else if len(writtenOff) > 0 {
+			utils.LogInfo("Wrote off expired stock batches", map[string]interface{}{"batches": len(writtenOff)})
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrSupplierNotFound   = apperrors.New(utils.ErrCodeNotFound, "supplier not found")
+	ErrSupplierNameTaken  = apperrors.New(utils.ErrCodeConflict, "a supplier with this name already exists")
+	ErrSupplierValidation = apperrors.New(utils.ErrCodeValidationFailed, "supplier validation error")
+)
+
+// MaxSupplierPriceRows is the most prices one CSV import may contain.
+const MaxSupplierPriceRows = 5000
+
+// MaxSupplierPriceFileBytes is the largest price list CSV accepted.
+const MaxSupplierPriceFileBytes = 2 << 20
+
+// Defaults and limits of the purchase suggestions.
+const (
+	DefaultConsumptionDays = 30 // Days of consumption the daily rate is taken from
+	DefaultCoverDays       = 14 // Days of consumption the suggested quantity covers
+	MaxConsumptionDays     = 365
+)
+
+// ConsumptionMovementTypes are the inventory movements counted as consumption of an item:
+// sales and components used, net of the returns of cancelled and deleted orders.
+var ConsumptionMovementTypes = []string{models.MovementTypeSale, models.MovementTypeReturnCancellation, models.MovementTypeReturnDeletion, models.MovementTypeComponentUsage}
+
+// SupplierRequest is the body of POST and PUT /suppliers.
+type SupplierRequest struct {
+	Name        string  `json:"name" binding:"required,max=255"`
+	ContactName *string `json:"contact_name" binding:"omitempty,max=255"`
+	PhoneNumber *string `json:"phone_number" binding:"omitempty,phone"`
+	Email       *string `json:"email" binding:"omitempty,email"`
+	Notes       *string `json:"notes"`
+}
+
+// SupplierPriceImportError is a line of a price list CSV that was not imported.
+type SupplierPriceImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// SupplierPriceImportResult reports the outcome of a price list import.
+type SupplierPriceImportResult struct {
+	Imported int                        `json:"imported"`
+	Failed   int                        `json:"failed"`
+	Errors   []SupplierPriceImportError `json:"errors"`
+}
+
+// --- SupplierService Interface ---
+type SupplierService interface {
+	CreateSupplier(req SupplierRequest) (*models.Supplier, error)
+	GetSuppliers() ([]models.Supplier, error)
+	GetSupplier(id int64) (*models.Supplier, error)
+	UpdateSupplier(id int64, req SupplierRequest) (*models.Supplier, error)
+	// DeleteSupplier deletes a supplier with its price list.
+	DeleteSupplier(id int64) error
+	GetPrices(supplierID int64) ([]models.SupplierPrice, error)
+	// ImportPrices adds or replaces prices of a supplier from a CSV file with a header line
+	// naming its columns: item_id or sku (the pricelist item's), price, and optionally
+	// min_order_quantity and supplier_sku. Invalid lines are reported and skipped.
+	ImportPrices(supplierID int64, file io.Reader) (*SupplierPriceImportResult, error)
+	// GetPurchaseSuggestions proposes, for each low-stock item, a quantity covering coverDays of
+	// its consumption over the last consumptionDays, and the suppliers selling it, cheapest first.
+	GetPurchaseSuggestions(consumptionDays, coverDays int) ([]models.PurchaseSuggestion, error)
+}
+
+type supplierService struct {
+	supplierRepo repositories.SupplierRepository
+	db           *sql.DB
+}
+
+// NewSupplierService creates a new SupplierService.
+func NewSupplierService(supplierRepo repositories.SupplierRepository, db *sql.DB) SupplierService {
+	return &supplierService{supplierRepo: supplierRepo, db: db}
+}
+
+// supplierError converts the errors of the supplier repository.
+func supplierError(err error, action string) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return ErrSupplierNotFound
+	case errors.Is(err, repositories.ErrDuplicateKey):
+		return ErrSupplierNameTaken
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// applySupplierRequest sets the fields of req on supplier.
+func applySupplierRequest(supplier *models.Supplier, req SupplierRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrSupplierValidation)
+	}
+	supplier.Name = name
+	supplier.ContactName = req.ContactName
+	supplier.PhoneNumber = req.PhoneNumber
+	supplier.Email = req.Email
+	supplier.Notes = req.Notes
+	return nil
+}
+
+func (s *supplierService) CreateSupplier(req SupplierRequest) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	if err := applySupplierRequest(supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.supplierRepo.CreateSupplier(supplier); err != nil {
+		return nil, supplierError(err, "create supplier")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) GetSuppliers() ([]models.Supplier, error) {
+	suppliers, err := s.supplierRepo.GetSuppliers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+func (s *supplierService) GetSupplier(id int64) (*models.Supplier, error) {
+	supplier, err := s.supplierRepo.GetSupplierByID(id)
+	if err != nil {
+		return nil, supplierError(err, "get supplier")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) UpdateSupplier(id int64, req SupplierRequest) (*models.Supplier, error) {
+	supplier, err := s.GetSupplier(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySupplierRequest(supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.supplierRepo.UpdateSupplier(supplier); err != nil {
+		return nil, supplierError(err, "update supplier")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) DeleteSupplier(id int64) error {
+	if err := s.supplierRepo.DeleteSupplier(id); err != nil {
+		return supplierError(err, "delete supplier")
+	}
+	return nil
+}
+
+func (s *supplierService) GetPrices(supplierID int64) ([]models.SupplierPrice, error) {
+	if _, err := s.GetSupplier(supplierID); err != nil {
+		return nil, err
+	}
+	prices, err := s.supplierRepo.GetSupplierPrices(supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier prices: %w", err)
+	}
+	return prices, nil
+}
+
+// importedPrice is a line of a price list CSV, whose item is named by ID or by SKU.
+type importedPrice struct {
+	line  int
+	sku   string
+	price models.SupplierPrice
+}
+
+// parsePriceList reads the lines of a price list CSV. Lines that cannot be parsed are
+// reported in result; an unreadable file or header is a validation error.
+func parsePriceList(supplierID int64, file io.Reader, result *SupplierPriceImportResult) ([]importedPrice, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Short lines are reported line by line
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: the file has no readable header line: %v", ErrSupplierValidation, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	_, hasItemID := columns["item_id"]
+	_, hasSKU := columns["sku"]
+	if _, ok := columns["price"]; !ok || (!hasItemID && !hasSKU) {
+		return nil, fmt.Errorf("%w: the header must name a price column and an item_id or sku column", ErrSupplierValidation)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var lines []importedPrice
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSupplierValidation, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(lines)+result.Failed >= MaxSupplierPriceRows {
+			return nil, fmt.Errorf("%w: a price list may have at most %d lines", ErrSupplierValidation, MaxSupplierPriceRows)
+		}
+		fail := func(format string, args ...interface{}) {
+			result.Failed++
+			result.Errors = append(result.Errors, SupplierPriceImportError{Line: line, Error: fmt.Sprintf(format, args...)})
+		}
+
+		entry := importedPrice{line: line, price: models.SupplierPrice{SupplierID: supplierID, MinOrderQuantity: 1}}
+		if value := field(record, "item_id"); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				fail("invalid item_id %q", value)
+				continue
+			}
+			entry.price.PricelistItemID = id
+		} else if entry.sku = field(record, "sku"); entry.sku == "" {
+			fail("item_id or sku is required")
+			continue
+		}
+		price, err := models.ParseMoney(field(record, "price"))
+		if err != nil || price.IsNegative() {
+			fail("invalid price %q", field(record, "price"))
+			continue
+		}
+		entry.price.Price = price
+		if value := field(record, "min_order_quantity"); value != "" {
+			quantity, err := strconv.Atoi(value)
+			if err != nil || quantity < 1 {
+				fail("invalid min_order_quantity %q", value)
+				continue
+			}
+			entry.price.MinOrderQuantity = quantity
+		}
+		if value := field(record, "supplier_sku"); value != "" {
+			if len(value) > 100 {
+				fail("supplier_sku is longer than 100 characters")
+				continue
+			}
+			entry.price.SupplierSKU = &value
+		}
+		lines = append(lines, entry)
+	}
+	return lines, nil
+}
+
+func (s *supplierService) ImportPrices(supplierID int64, file io.Reader) (*SupplierPriceImportResult, error) {
+	if _, err := s.GetSupplier(supplierID); err != nil {
+		return nil, err
+	}
+	result := &SupplierPriceImportResult{Errors: []SupplierPriceImportError{}}
+	lines, err := parsePriceList(supplierID, file, result)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemIDs []int64
+	var skus []string
+	for _, entry := range lines {
+		if entry.sku != "" {
+			skus = append(skus, entry.sku)
+		} else {
+			itemIDs = append(itemIDs, entry.price.PricelistItemID)
+		}
+	}
+	knownIDs, err := s.supplierRepo.GetItemIDs(itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pricelist items: %w", err)
+	}
+	idsBySKU, err := s.supplierRepo.GetItemIDsBySKU(skus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pricelist items: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lineByItem := make(map[int64]int, len(lines))
+	for _, entry := range lines {
+		fail := func(format string, args ...interface{}) {
+			result.Failed++
+			result.Errors = append(result.Errors, SupplierPriceImportError{Line: entry.line, Error: fmt.Sprintf(format, args...)})
+		}
+		if entry.sku != "" {
+			id, ok := idsBySKU[entry.sku]
+			if !ok {
+				fail("no pricelist item has SKU %q", entry.sku)
+				continue
+			}
+			entry.price.PricelistItemID = id
+		} else if !knownIDs[entry.price.PricelistItemID] {
+			fail("pricelist item ID %d not found", entry.price.PricelistItemID)
+			continue
+		}
+		if previous, ok := lineByItem[entry.price.PricelistItemID]; ok {
+			fail("the item is already priced on line %d", previous)
+			continue
+		}
+		lineByItem[entry.price.PricelistItemID] = entry.line
+
+		if err := s.supplierRepo.UpsertSupplierPrice(tx, &entry.price); err != nil {
+			return nil, fmt.Errorf("failed to save supplier price: %w", err)
+		}
+		result.Imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit price list import: %w", err)
+	}
+	return result, nil
+}
+
+// suggestedQuantity returns how many purchase units of item to buy to cover coverDays of its
+// consumption over consumptionDays on top of its low stock threshold, and at least enough to get
+// above the threshold.
+func suggestedQuantity(item models.StockConsumption, consumptionDays, coverDays int) int {
+	shortfall := item.LowStockThreshold.Sub(item.CurrentStock)
+	quantity := consumptionOver(item.Consumed, consumptionDays, coverDays).Add(shortfall).DivCeil(item.PurchaseUnitSize)
+	minimum := int(shortfall.Decimal().Div(item.PurchaseUnitSize.Decimal()).Floor().IntPart()) + 1
+	if quantity < minimum {
+		quantity = minimum
+	}
+	return quantity
+}
+
+func (s *supplierService) GetPurchaseSuggestions(consumptionDays, coverDays int) ([]models.PurchaseSuggestion, error) {
+	if consumptionDays < 1 || consumptionDays > MaxConsumptionDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrSupplierValidation, MaxConsumptionDays)
+	}
+	if coverDays < 1 || coverDays > MaxConsumptionDays {
+		return nil, fmt.Errorf("%w: cover_days must be between 1 and %d", ErrSupplierValidation, MaxConsumptionDays)
+	}
+
+	since := utils.NowUTC().AddDate(0, 0, -consumptionDays)
+	items, err := s.supplierRepo.GetLowStockConsumption(since, ConsumptionMovementTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get low stock items: %w", err)
+	}
+	itemIDs := make([]int64, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.PricelistItemID
+	}
+	offers, err := s.supplierRepo.GetOffers(itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier offers: %w", err)
+	}
+
+	suggestions := make([]models.PurchaseSuggestion, 0, len(items))
+	for _, item := range items {
+		if item.Consumed.IsNegative() {
+			item.Consumed = models.ZeroQuantity // More returned than sold
+		}
+		suggestion := models.PurchaseSuggestion{
+			StockConsumption:  item,
+			DailyConsumption:  item.Consumed.Decimal().Div(decimal.NewFromInt(int64(consumptionDays))).Round(2),
+			SuggestedQuantity: suggestedQuantity(item, consumptionDays, coverDays),
+			Offers:            offers[item.PricelistItemID],
+		}
+		if suggestion.Offers == nil {
+			suggestion.Offers = []models.SupplierOffer{}
+		}
+		if len(suggestion.Offers) > 0 {
+			cheapest := suggestion.Offers[0]
+			if suggestion.SuggestedQuantity < cheapest.MinOrderQuantity {
+				suggestion.SuggestedQuantity = cheapest.MinOrderQuantity
+			}
+			cost := cheapest.Price.MulInt(suggestion.SuggestedQuantity).Round()
+			suggestion.Cheapest = &cheapest
+			suggestion.EstimatedCost = &cost
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var ErrSyncValidation = apperrors.New(utils.ErrCodeValidationFailed, "sync validation error")
+
+// Bounds of offline sync.
+const (
+	DefaultSyncPullLimit = 500
+	maxSyncPullLimit     = 2000
+	maxSyncPushOrders    = 100
+	// maxOfflineClockSkew is how far in the future the clock of a POS may put an order
+	maxOfflineClockSkew = 5 * time.Minute
+)
+
+// Results of syncing an order taken offline.
+const (
+	SyncOrderCreated   = "created"
+	SyncOrderDuplicate = "duplicate" // Synced before; the order is returned as it is
+	SyncOrderRejected  = "rejected"
+)
+
+// offlineOrderRejections are the errors of CreateOrder that reject an order taken offline
+// rather than fail the whole push; domain errors reject it too.
+var offlineOrderRejections = []error{
+	ErrPricelistItemNotFound, ErrInvalidOrderStatus, ErrDiscountLimitExceeded, ErrClientAccountNotFound,
+	ErrClientAccountInactive, ErrClientAccountOverdue, ErrCreditLimitExceeded, ErrClientAccountValidation,
+}
+
+// OfflineOrderItemRequest is an item of an order taken offline, at the price the POS charged.
+type OfflineOrderItemRequest struct {
+	PricelistItemID int64        `json:"pricelist_item_id" binding:"required"`
+	Quantity        int          `json:"quantity" binding:"required,gt=0"`
+	UnitPrice       models.Money `json:"unit_price" binding:"money"`
+	Notes           string       `json:"notes"`
+	UUID            *string      `json:"uuid" binding:"omitempty,uuid"`
+}
+
+// OfflineOrderRequest is an order a POS took while offline, identified by the UUID it gave it.
+type OfflineOrderRequest struct {
+	UUID           string                    `json:"uuid" binding:"required,uuid"`
+	OrderTime      time.Time                 `json:"order_time" binding:"required"` // When the POS took the order
+	StaffID        int64                     `json:"staff_id" binding:"required"`
+	ClientID       *int64                    `json:"client_id"`
+	TableID        *int64                    `json:"table_id"`
+	Status         string                    `json:"status" binding:"required,order_status"`
+	PaymentMethod  *string                   `json:"payment_method"`
+	Notes          *string                   `json:"notes"`
+	DiscountAmount *models.Money             `json:"discount_amount" binding:"omitempty,money"`
+	GuestCount     *int                      `json:"guest_count" binding:"omitempty,min=1"`
+	ServiceCharge  *bool                     `json:"service_charge"`
+	Items          []OfflineOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// PushOrdersRequest is the body of POST /sync/orders.
+type PushOrdersRequest struct {
+	Orders []OfflineOrderRequest `json:"orders" binding:"required,min=1,dive"`
+}
+
+// SyncOrderResult is what became of an order taken offline.
+type SyncOrderResult struct {
+	UUID      string                `json:"uuid"`
+	Result    string                `json:"result"` // created, duplicate or rejected
+	Order     *models.Order         `json:"order,omitempty"`
+	Conflicts []models.SyncConflict `json:"conflicts,omitempty"` // How the order differed from the server, and was resolved
+	Error     string                `json:"error,omitempty"`     // Why it was rejected
+}
+
+// --- SyncService Interface ---
+type SyncService interface {
+	// GetChanges returns up to limit changes of the pricelist, game tables and clients after
+	// cursor (0 for everything), in version order.
+	GetChanges(cursor int64, limit int) (*models.SyncChanges, error)
+	// PushOrders creates the orders a POS took offline, in the order given, each on its own. An
+	// order synced before is returned as a duplicate, so a push may be retried.
+	PushOrders(req PushOrdersRequest, callerRole string) ([]SyncOrderResult, error)
+}
+
+type syncService struct {
+	syncRepo      repositories.SyncRepository
+	orderService  OrderService
+	orderRepo     repositories.OrderRepository
+	pricelistRepo repositories.PricelistRepository
+	clientRepo    repositories.ClientRepository
+	tableRepo     repositories.GameTableRepository
+	dayCloseRepo  repositories.DayCloseRepository
+}
+
+// NewSyncService creates a new SyncService.
+func NewSyncService(
+	syncRepo repositories.SyncRepository,
+	orderService OrderService,
+	orderRepo repositories.OrderRepository,
+	pricelistRepo repositories.PricelistRepository,
+	clientRepo repositories.ClientRepository,
+	tableRepo repositories.GameTableRepository,
+	dayCloseRepo repositories.DayCloseRepository,
+) SyncService {
+	return &syncService{
+		syncRepo:      syncRepo,
+		orderService:  orderService,
+		orderRepo:     orderRepo,
+		pricelistRepo: pricelistRepo,
+		clientRepo:    clientRepo,
+		tableRepo:     tableRepo,
+		dayCloseRepo:  dayCloseRepo,
+	}
+}
+
+func (s *syncService) GetChanges(cursor int64, limit int) (*models.SyncChanges, error) {
+	if cursor < 0 {
+		return nil, fmt.Errorf("%w: cursor cannot be negative", ErrSyncValidation)
+	}
+	if limit < 1 || limit > maxSyncPullLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrSyncValidation, maxSyncPullLimit)
+	}
+	serverTime := utils.NowUTC()
+	// One more than limit of each source: whatever is left out of the page after sorting has a
+	// higher version than all of the page
+	changes, err := s.syncRepo.GetDeletions(cursor, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletions: %w", err)
+	}
+	for _, entity := range models.SyncEntities {
+		entityChanges, err := s.syncRepo.GetChanges(entity, cursor, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s changes: %w", entity, err)
+		}
+		changes = append(changes, entityChanges...)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Version < changes[j].Version })
+
+	page := &models.SyncChanges{Changes: changes, Cursor: cursor, ServerTime: serverTime}
+	if len(changes) > limit {
+		page.Changes, page.HasMore = changes[:limit], true
+	}
+	if len(page.Changes) > 0 {
+		page.Cursor = page.Changes[len(page.Changes)-1].Version
+	}
+	return page, nil
+}
+
+func (s *syncService) PushOrders(req PushOrdersRequest, callerRole string) ([]SyncOrderResult, error) {
+	if len(req.Orders) > maxSyncPushOrders {
+		return nil, fmt.Errorf("%w: at most %d orders can be pushed at once", ErrSyncValidation, maxSyncPushOrders)
+	}
+	results := make([]SyncOrderResult, 0, len(req.Orders))
+	for _, orderReq := range req.Orders {
+		result, err := s.pushOrder(orderReq, callerRole)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync order %s: %w", orderReq.UUID, err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// pushOrder creates an order taken offline, resolving its conflicts with the server: the
+// prices the POS charged are kept, items are sold even if the stock recorded is short and a
+// client or table deleted in the meantime is left out. It is rejected if an item no longer
+// exists, its business day was closed or it breaks the rules of orders.
+func (s *syncService) pushOrder(req OfflineOrderRequest, callerRole string) (*SyncOrderResult, error) {
+	uuid := strings.ToLower(req.UUID)
+	result := &SyncOrderResult{UUID: uuid}
+	existing, err := s.syncedOrder(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		result.Result, result.Order = SyncOrderDuplicate, existing
+		return result, nil
+	}
+	reject := func(reason string) (*SyncOrderResult, error) {
+		result.Result, result.Error = SyncOrderRejected, reason
+		return result, nil
+	}
+
+	orderTime := req.OrderTime.UTC()
+	if orderTime.After(utils.NowUTC().Add(maxOfflineClockSkew)) {
+		return reject("order_time is in the future")
+	}
+	closed, err := s.dayCloseRepo.IsClosedThrough(utils.BranchCode(), utils.FormatClubTime(orderTime, utils.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check whether the business day is closed: %w", err)
+	}
+	if closed {
+		return reject(ErrDayClosed.Error())
+	}
+
+	createReq := CreateOrderRequest{
+		ClientID:            req.ClientID,
+		StaffID:             req.StaffID,
+		TableID:             req.TableID,
+		Status:              req.Status,
+		PaymentMethod:       req.PaymentMethod,
+		Notes:               req.Notes,
+		DiscountAmount:      req.DiscountAmount,
+		GuestCount:          req.GuestCount,
+		ServiceCharge:       req.ServiceCharge,
+		CallerRole:          callerRole,
+		UUID:                &uuid,
+		OrderTime:           &orderTime,
+		AllowStockShortfall: true,
+	}
+	if req.ClientID != nil {
+		if _, err := s.clientRepo.GetClientByID(*req.ClientID); errors.Is(err, repositories.ErrNotFound) {
+			createReq.ClientID = nil
+			result.Conflicts = append(result.Conflicts, models.SyncConflict{
+				Type: models.SyncConflictClientMissing, Detail: fmt.Sprintf("client ID %d no longer exists", *req.ClientID),
+			})
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+	}
+	if req.TableID != nil {
+		if _, err := s.tableRepo.GetGameTableByID(*req.TableID); errors.Is(err, repositories.ErrNotFound) {
+			createReq.TableID = nil
+			result.Conflicts = append(result.Conflicts, models.SyncConflict{
+				Type: models.SyncConflictTableMissing, Detail: fmt.Sprintf("game table ID %d no longer exists", *req.TableID),
+			})
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get game table: %w", err)
+		}
+	}
+
+	for _, item := range req.Items {
+		price, stock, name, tracksStock, err := s.pricelistRepo.GetItemPriceAndStock(item.PricelistItemID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return reject(fmt.Sprintf("pricelist item ID %d no longer exists", item.PricelistItemID))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pricelist item %d: %w", item.PricelistItemID, err)
+		}
+		units, err := s.pricelistRepo.GetItemUnits(item.PricelistItemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get units of pricelist item %d: %w", item.PricelistItemID, err)
+		}
+		itemID := item.PricelistItemID
+		if price.Cmp(item.UnitPrice) != 0 {
+			result.Conflicts = append(result.Conflicts, models.SyncConflict{
+				Type: models.SyncConflictPriceChanged, PricelistItemID: &itemID,
+				Detail: fmt.Sprintf("%s was sold at %s, the price is now %s", name, item.UnitPrice, price),
+			})
+		}
+		if needed := units.ForPortions(item.Quantity); tracksStock && (stock == nil || stock.Cmp(needed) < 0) {
+			available := models.ZeroQuantity
+			if stock != nil {
+				available = *stock
+			}
+			result.Conflicts = append(result.Conflicts, models.SyncConflict{
+				Type: models.SyncConflictStockShortfall, PricelistItemID: &itemID,
+				Detail: fmt.Sprintf("%s: sold %s %s, %s %s in stock", name, needed, units.StockUnit, available, units.StockUnit),
+			})
+		}
+		unitPrice := item.UnitPrice
+		createReq.OrderItems = append(createReq.OrderItems, CreateOrderItemRequest{
+			PricelistItemID: item.PricelistItemID,
+			Quantity:        item.Quantity,
+			Notes:           item.Notes,
+			UUID:            item.UUID,
+			UnitPrice:       &unitPrice,
+		})
+	}
+
+	order, err := s.orderService.CreateOrder(createReq)
+	if err != nil {
+		// Pushed twice at once: the other push created it
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			if existing, getErr := s.syncedOrder(uuid); getErr == nil && existing != nil {
+				return &SyncOrderResult{UUID: uuid, Result: SyncOrderDuplicate, Order: existing}, nil
+			}
+		}
+		if _, isDomainErr := apperrors.AsDomainError(err); isDomainErr || slices.ContainsFunc(offlineOrderRejections, func(target error) bool {
+			return errors.Is(err, target)
+		}) {
+			return reject(err.Error())
+		}
+		return nil, err
+	}
+	result.Result, result.Order = SyncOrderCreated, order
+	return result, nil
+}
+
+// syncedOrder returns the order synced before with uuid, nil if there is none.
+func (s *syncService) syncedOrder(uuid string) (*models.Order, error) {
+	existing, err := s.orderRepo.GetOrderByUUID(uuid)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up synced order: %w", err)
+	}
+	order, err := s.orderService.GetOrderByID(existing.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get synced order: %w", err)
+	}
+	return order, nil
+}
@@ -0,0 +1,546 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/events"
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrTableSessionNotFound   = apperrors.New(utils.ErrCodeNotFound, "table session not found")
+	ErrTableSessionRunning    = apperrors.New(utils.ErrCodeConflict, "the table already runs a session")
+	ErrTableSessionStopped    = apperrors.New(utils.ErrCodeConflict, "table session is already stopped")
+	ErrTableSessionValidation = apperrors.New(utils.ErrCodeValidationFailed, "table session validation error")
+)
+
+// SessionWarningMinutes are the remaining minutes of a prepaid session at which a
+// table_session.warning event is published.
+var SessionWarningMinutes = []int{10, 5}
+
+// SessionTimerInterval is how often RunTimers checks the time limits of the running sessions.
+var SessionTimerInterval = 15 * time.Second
+
+// StartTableSessionRequest is the body of POST /table-sessions.
+type StartTableSessionRequest struct {
+	TableID   int64  `json:"table_id" binding:"required"`
+	BookingID *int64 `json:"booking_id"`
+	ClientID  *int64 `json:"client_id"`
+	// LimitMinutes is the prepaid time; omit it for an open session that runs until stopped
+	LimitMinutes *int   `json:"limit_minutes" binding:"omitempty,min=1"`
+	OnExpiry     string `json:"on_expiry" binding:"omitempty,oneof=stop overtime"` // Defaults to stop
+	Controllers  *int   `json:"controllers" binding:"omitempty,min=1"`             // Defaults to the table's base controllers
+	// OvertimeRate is charged per started minute of overtime; defaults to the session's hourly rate / 60,
+	// or its minute rate on a per-minute table
+	OvertimeRate *models.Money `json:"overtime_rate" binding:"omitempty,money"`
+	// Deposit is held until the session stops, then applied to its bill and the rest refunded
+	Deposit *SessionDepositRequest `json:"deposit"`
+}
+
+// --- TableSessionService Interface ---
+type TableSessionService interface {
+	// StartSession starts a session at a table and marks the table occupied. The session is
+	// billed at the table's hourly rate plus the surcharge of its extra controllers.
+	StartSession(req StartTableSessionRequest, startedBy int64) (*models.TableSession, error)
+	// GetRunningSessions returns the sessions that have not been stopped, billed up to now.
+	GetRunningSessions() ([]models.TableSession, error)
+	GetSession(id int64) (*models.TableSession, error)
+	// StopSession stops a running session, bills its time and overtime and marks the table available.
+	// Its deposit pays the bill up to its amount and the rest is refunded: cash is handed back and a
+	// card pre-authorization captured for the amount applied.
+	StopSession(id int64, stoppedBy int64) (*models.TableSession, error)
+	// RetryDepositCapture captures the card deposit of a stopped session whose capture failed.
+	RetryDepositCapture(sessionID int64) (*models.SessionDeposit, error)
+	// RenderReceipt renders a plain-text receipt for the bill of a session, so far if it is
+	// still running, with the rounding, minimum charge and overtime broken out.
+	RenderReceipt(id int64) (string, error)
+	// RunTimers warns of and applies the time limits of prepaid sessions until ctx is
+	// done. Every instance may run it; each warning and expiry is applied once.
+	RunTimers(ctx context.Context)
+}
+
+type tableSessionService struct {
+	sessionRepo repositories.TableSessionRepository
+	bookingRepo repositories.BookingRepository
+	tableRepo   repositories.GameTableRepository
+	lockerRepo  repositories.LockerRepository
+	depositRepo repositories.SessionDepositRepository
+	payments    PaymentProvider // nil if card pre-authorization is not configured
+	publisher   events.Publisher
+	db          *sql.DB
+}
+
+// NewTableSessionService creates a new TableSessionService. payments may be nil, which disables
+// card deposits.
+func NewTableSessionService(sessionRepo repositories.TableSessionRepository, bookingRepo repositories.BookingRepository,
+	tableRepo repositories.GameTableRepository, lockerRepo repositories.LockerRepository, depositRepo repositories.SessionDepositRepository,
+	payments PaymentProvider, publisher events.Publisher, db *sql.DB) TableSessionService {
+	return &tableSessionService{
+		sessionRepo: sessionRepo,
+		bookingRepo: bookingRepo,
+		tableRepo:   tableRepo,
+		lockerRepo:  lockerRepo,
+		depositRepo: depositRepo,
+		payments:    payments,
+		publisher:   publisher,
+		db:          db,
+	}
+}
+
+func (s *tableSessionService) StartSession(req StartTableSessionRequest, startedBy int64) (*models.TableSession, error) {
+	onExpiry := req.OnExpiry
+	if onExpiry == "" {
+		onExpiry = models.SessionExpiryStop
+	}
+	if !models.IsValidSessionExpiryAction(onExpiry) {
+		return nil, fmt.Errorf("%w: invalid on_expiry '%s', must be one of %v", ErrTableSessionValidation, onExpiry, models.SessionExpiryActions)
+	}
+	if req.LimitMinutes != nil && *req.LimitMinutes <= 0 {
+		return nil, fmt.Errorf("%w: limit_minutes must be positive", ErrTableSessionValidation)
+	}
+	if req.OvertimeRate != nil && req.OvertimeRate.IsNegative() {
+		return nil, fmt.Errorf("%w: overtime_rate must not be negative", ErrTableSessionValidation)
+	}
+	if req.Deposit != nil {
+		if err := s.validateDepositRequest(req.Deposit); err != nil {
+			return nil, err
+		}
+	}
+
+	table, err := s.bookingRepo.GetGameTableByID(req.TableID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, fmt.Errorf("%w: game table ID %d not found", ErrTableSessionValidation, req.TableID)
+		}
+		return nil, fmt.Errorf("failed to get game table: %w", err)
+	}
+	if table.Status == models.TableStatusMaintenance {
+		return nil, fmt.Errorf("%w: table '%s' is under maintenance", ErrTableSessionValidation, table.Name)
+	}
+	// Only the hourly rate is taken from the quote; the time is billed as the session runs
+	quote, err := quoteTable(table, req.Controllers, 0, ErrTableSessionValidation)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.NowUTC()
+	session := &models.TableSession{
+		TableID:      req.TableID,
+		BookingID:    req.BookingID,
+		ClientID:     req.ClientID,
+		StartedBy:    &startedBy,
+		StartedAt:    now,
+		LimitMinutes: req.LimitMinutes,
+		OnExpiry:     onExpiry,
+		Controllers:  req.Controllers,
+		HourlyRate:   &quote.HourlyRate,
+		Status:       models.TableSessionStatusActive,
+		BillingMode:  quote.BillingMode,
+	}
+	if quote.BillingMode == models.BillingModePerMinute {
+		increment := quote.BillingIncrementMinutes
+		session.MinuteRate = quote.MinuteRate
+		session.BillingIncrementMinutes = &increment
+		session.MinimumCharge = quote.MinimumCharge
+	}
+	if req.LimitMinutes != nil {
+		endsAt := now.Add(time.Duration(*req.LimitMinutes) * time.Minute)
+		session.EndsAt = &endsAt
+		if onExpiry == models.SessionExpiryOvertime {
+			session.OvertimeRate = req.OvertimeRate
+			if session.OvertimeRate == nil {
+				perMinute := quote.HourlyRate.ForMinutes(1)
+				if session.MinuteRate != nil {
+					perMinute = *session.MinuteRate
+				}
+				session.OvertimeRate = &perMinute
+			}
+		}
+	}
+
+	var authorizationID *string
+	if req.Deposit != nil {
+		if authorizationID, err = s.preAuthorizeDeposit(req.Deposit, req.TableID); err != nil {
+			return nil, err
+		}
+	}
+	created, err := s.createSession(session, table, req.Deposit, authorizationID, startedBy)
+	if err != nil {
+		// The hold is released if the session did not start
+		s.voidDeposit(authorizationID)
+		return nil, err
+	}
+	return created, nil
+}
+
+// createSession records session at table with its deposit, if any, and marks the table occupied.
+func (s *tableSessionService) createSession(session *models.TableSession, table *models.GameTable, depositReq *SessionDepositRequest,
+	authorizationID *string, startedBy int64) (*models.TableSession, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created, err := s.sessionRepo.CreateTableSession(tx, session)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicateKey) {
+			return nil, ErrTableSessionRunning
+		}
+		return nil, fmt.Errorf("failed to create table session: %w", err)
+	}
+	if depositReq != nil {
+		deposit := &models.SessionDeposit{
+			TableSessionID:  created.ID,
+			ClientID:        created.ClientID,
+			Method:          depositReq.Method,
+			Amount:          depositReq.Amount,
+			AuthorizationID: authorizationID,
+			TakenBy:         &startedBy,
+			TakenAt:         created.StartedAt,
+		}
+		if err := s.depositRepo.CreateDeposit(tx, deposit); err != nil {
+			return nil, fmt.Errorf("failed to record deposit: %w", err)
+		}
+		created.Deposit = deposit
+	}
+	if err := changeTableStatus(tx, s.tableRepo, s.publisher, table, models.TableStatusOccupied, nil); err != nil {
+		return nil, err
+	}
+	if err := s.publisher.Publish(tx, events.TableSessionStarted, events.AggregateTableSession, created.ID, events.NewTableSessionPayload(created)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit table session: %w", err)
+	}
+	return created, nil
+}
+
+func (s *tableSessionService) GetRunningSessions() ([]models.TableSession, error) {
+	sessions, err := s.sessionRepo.GetRunningTableSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running table sessions: %w", err)
+	}
+	now := utils.NowUTC()
+	for i := range sessions {
+		if err := s.attachLockerRentals(&sessions[i]); err != nil {
+			return nil, err
+		}
+		if err := s.attachDeposit(&sessions[i]); err != nil {
+			return nil, err
+		}
+		billSession(&sessions[i], now)
+	}
+	return sessions, nil
+}
+
+func (s *tableSessionService) GetSession(id int64) (*models.TableSession, error) {
+	session, err := s.sessionRepo.GetTableSessionByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTableSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get table session: %w", err)
+	}
+	if err := s.attachLockerRentals(session); err != nil {
+		return nil, err
+	}
+	if err := s.attachDeposit(session); err != nil {
+		return nil, err
+	}
+	if session.Running() {
+		billSession(session, utils.NowUTC())
+	}
+	return session, nil
+}
+
+// attachLockerRentals sets the lockers rented for the visit of session, with the session or
+// its booking. A running session bills their fees; a stopped one keeps what it billed.
+func (s *tableSessionService) attachLockerRentals(session *models.TableSession) error {
+	rentals, err := s.lockerRepo.GetSessionRentals(session.ID, session.BookingID)
+	if err != nil {
+		return fmt.Errorf("failed to get locker rentals of table session: %w", err)
+	}
+	session.LockerRentals = rentals
+	if session.Running() {
+		session.LockerAmount = models.ZeroMoney
+		for _, rental := range rentals {
+			session.LockerAmount = session.LockerAmount.Add(rental.Fee)
+		}
+	}
+	return nil
+}
+
+func (s *tableSessionService) StopSession(id int64, stoppedBy int64) (*models.TableSession, error) {
+	session, err := s.sessionRepo.GetTableSessionByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTableSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get table session: %w", err)
+	}
+	if !session.Running() {
+		return nil, ErrTableSessionStopped
+	}
+	session.StoppedBy = &stoppedBy
+	if err := s.stop(session, utils.NowUTC()); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// stop records the stop of session at stoppedAt, with its bill until then, settles its
+// deposit and publishes table_session.stopped. It returns ErrVersionConflict if the session
+// changed since it was read. A card deposit is captured after the stop is committed; a
+// failed capture is logged and left to RetryDepositCapture.
+func (s *tableSessionService) stop(session *models.TableSession, stoppedAt time.Time) error {
+	fromStatus := session.Status
+	session.StoppedAt = &stoppedAt
+	if err := s.attachLockerRentals(session); err != nil {
+		return err
+	}
+	if err := s.attachDeposit(session); err != nil {
+		return err
+	}
+	billSession(session, stoppedAt)
+	table, err := s.tableRepo.GetGameTableByID(session.TableID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("failed to get game table: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.sessionRepo.StopTableSession(tx, session, fromStatus); err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionConflict
+		}
+		return fmt.Errorf("failed to stop table session: %w", err)
+	}
+	if err := s.settleDeposit(tx, session, stoppedAt); err != nil {
+		return err
+	}
+	// The table is freed if it is still occupied; a status changed meanwhile is left to the status sync
+	if table != nil && table.Status == models.TableStatusOccupied {
+		if err := changeTableStatus(tx, s.tableRepo, s.publisher, table, models.TableStatusAvailable, nil); err != nil && !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+	if err := s.publisher.Publish(tx, events.TableSessionStopped, events.AggregateTableSession, session.ID, events.NewTableSessionPayload(session)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit table session stop: %w", err)
+	}
+	if session.Deposit != nil && session.Deposit.Status == models.DepositStatusCapturing {
+		if err := s.captureDeposit(session.Deposit); err != nil {
+			utils.LogError(err, "stop: failed to capture the deposit of table session "+strconv.FormatInt(session.ID, 10))
+		}
+	}
+	return nil
+}
+
+func (s *tableSessionService) RenderReceipt(id int64) (string, error) {
+	session, err := s.GetSession(id)
+	if err != nil {
+		return "", err
+	}
+	tableName := fmt.Sprintf("Table #%d", session.TableID)
+	if table, err := s.bookingRepo.GetGameTableByID(session.TableID); err == nil {
+		tableName = table.Name
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return "", fmt.Errorf("failed to get game table: %w", err)
+	}
+	end := utils.NowUTC()
+	if session.StoppedAt != nil {
+		end = *session.StoppedAt
+	}
+	played := 0
+	if end.After(session.StartedAt) {
+		played = int(math.Ceil(end.Sub(session.StartedAt).Minutes()))
+	}
+
+	var b strings.Builder
+	separator := strings.Repeat("-", receiptWidth) + "\n"
+	b.WriteString(fmt.Sprintf("%s, session %d\n", tableName, session.ID))
+	b.WriteString(utils.FormatClubTime(session.StartedAt, "2006-01-02 15:04") + " - " + utils.FormatClubTime(end, "15:04") + "\n")
+	b.WriteString(separator)
+	b.WriteString(receiptLine("Played", fmt.Sprintf("%d min", played)))
+	if session.LimitMinutes != nil {
+		b.WriteString(receiptLine("Prepaid", fmt.Sprintf("%d min", *session.LimitMinutes)))
+	}
+	if session.BillingMode == models.BillingModePerMinute && session.MinuteRate != nil {
+		increment := models.DefaultBillingIncrementMinutes
+		if session.BillingIncrementMinutes != nil {
+			increment = *session.BillingIncrementMinutes
+		}
+		b.WriteString(receiptLine("Billed", fmt.Sprintf("%d min", session.BilledMinutes)))
+		b.WriteString(fmt.Sprintf("  rounded up to %d min\n", increment))
+		b.WriteString(receiptLine(fmt.Sprintf("  %d x %s/min", session.BilledMinutes, session.MinuteRate),
+			session.TimeAmount.Sub(session.MinimumChargeAmount).String()))
+		if session.MinimumChargeAmount.IsPositive() {
+			b.WriteString(receiptLine(fmt.Sprintf("  Minimum charge %s", session.MinimumCharge), session.MinimumChargeAmount.String()))
+		}
+	} else if session.HourlyRate != nil {
+		b.WriteString(receiptLine(fmt.Sprintf("  %d min x %s/h", session.BilledMinutes, session.HourlyRate), session.TimeAmount.String()))
+	}
+	if session.OvertimeMinutes > 0 && session.OvertimeRate != nil {
+		b.WriteString(receiptLine(fmt.Sprintf("Overtime %d x %s/min", session.OvertimeMinutes, session.OvertimeRate), session.OvertimeAmount.String()))
+	}
+	for _, rental := range session.LockerRentals {
+		if rental.Fee.IsPositive() {
+			b.WriteString(receiptLine("Locker "+rental.LockerNumber, rental.Fee.String()))
+		}
+	}
+	b.WriteString(separator)
+	b.WriteString(receiptLine("Total", session.TotalAmount.String()))
+	return b.String(), nil
+}
+
+// billSession sets the bill of session run up until at. The time is the prepaid limit, or
+// every started minute of an open session, at the session's hourly rate; a per-minute
+// session rounds it up to the billing increment at its minute rate and charges at least its
+// minimum charge. The overtime is every started minute past the time limit of a session
+// that continues as overtime, at the overtime rate. The locker fees set by
+// attachLockerRentals are added to the total.
+func billSession(session *models.TableSession, at time.Time) {
+	minutes := 0
+	if session.LimitMinutes != nil {
+		minutes = *session.LimitMinutes
+	} else if at.After(session.StartedAt) {
+		minutes = int(math.Ceil(at.Sub(session.StartedAt).Minutes()))
+	}
+	if session.BillingMode == models.BillingModePerMinute && session.MinuteRate != nil {
+		increment := 0
+		if session.BillingIncrementMinutes != nil {
+			increment = *session.BillingIncrementMinutes
+		}
+		var amount models.Money
+		session.BilledMinutes, amount, session.MinimumChargeAmount = models.ChargePerMinute(*session.MinuteRate, minutes, increment, session.MinimumCharge)
+		session.TimeAmount = amount.Add(session.MinimumChargeAmount)
+	} else if session.HourlyRate != nil {
+		session.BilledMinutes = minutes
+		session.TimeAmount = session.HourlyRate.ForMinutes(minutes)
+	}
+	if session.EndsAt != nil && session.OnExpiry == models.SessionExpiryOvertime && at.After(*session.EndsAt) {
+		session.OvertimeMinutes = int(math.Ceil(at.Sub(*session.EndsAt).Minutes()))
+		if session.OvertimeRate != nil {
+			session.OvertimeAmount = session.OvertimeRate.MulInt(session.OvertimeMinutes).Round()
+		}
+	}
+	session.TotalAmount = session.TimeAmount.Add(session.OvertimeAmount).Add(session.LockerAmount)
+}
+
+func (s *tableSessionService) RunTimers(ctx context.Context) {
+	ticker := time.NewTicker(SessionTimerInterval)
+	defer ticker.Stop()
+	for {
+		s.checkTimers(utils.NowUTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkTimers sends the warnings that are due and expires the sessions whose time is up.
+func (s *tableSessionService) checkTimers(now time.Time) {
+	horizon := now
+	for _, minutes := range SessionWarningMinutes {
+		if until := now.Add(time.Duration(minutes) * time.Minute); until.After(horizon) {
+			horizon = until
+		}
+	}
+	sessions, err := s.sessionRepo.GetTimedTableSessions(horizon)
+	if err != nil {
+		utils.LogError(err, "Failed to check table session timers")
+		return
+	}
+	for i := range sessions {
+		session := &sessions[i]
+		var err error
+		if now.Before(*session.EndsAt) {
+			err = s.warn(session, now)
+		} else {
+			err = s.expire(session, now)
+		}
+		if err != nil {
+			utils.LogError(err, "Failed to apply the time limit of table session "+strconv.FormatInt(session.ID, 10))
+		}
+	}
+}
+
+// warn publishes table_session.warning for the lowest of SessionWarningMinutes the remaining
+// time of session has reached, unless it was already published.
+func (s *tableSessionService) warn(session *models.TableSession, now time.Time) error {
+	remaining := session.EndsAt.Sub(now)
+	due := 0
+	for _, minutes := range SessionWarningMinutes {
+		if remaining <= time.Duration(minutes)*time.Minute && (due == 0 || minutes < due) {
+			due = minutes
+		}
+	}
+	if due == 0 || (session.WarnedMinutes != nil && *session.WarnedMinutes <= due) {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	marked, err := s.sessionRepo.MarkTableSessionWarned(tx, session.ID, due, now)
+	if err != nil || !marked {
+		return err
+	}
+	payload := events.NewTableSessionPayload(session)
+	remainingMinutes := int(math.Ceil(remaining.Minutes()))
+	payload.RemainingMinutes = &remainingMinutes
+	if err := s.publisher.Publish(tx, events.TableSessionWarning, events.AggregateTableSession, session.ID, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// expire applies the expiry action of session, whose time is up: it is stopped at its
+// time limit or continues as overtime.
+func (s *tableSessionService) expire(session *models.TableSession, now time.Time) error {
+	if session.OnExpiry != models.SessionExpiryOvertime {
+		// Another instance may have stopped it first
+		if err := s.stop(session, *session.EndsAt); err != nil && !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	started, err := s.sessionRepo.SetTableSessionOvertime(tx, session.ID, now)
+	if err != nil || !started {
+		return err
+	}
+	session.Status = models.TableSessionStatusOvertime
+	if err := s.publisher.Publish(tx, events.TableSessionOvertime, events.AggregateTableSession, session.ID, events.NewTableSessionPayload(session)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"sync"
+
+	"ps_club_backend/internal/models"
+)
+
+var (
+	taxSettings   models.TaxSettings
+	taxSettingsMu sync.RWMutex
+)
+
+// SetTaxSettings sets the tax classes and mode (the tax setting).
+func SetTaxSettings(settings models.TaxSettings) {
+	taxSettingsMu.Lock()
+	defer taxSettingsMu.Unlock()
+	taxSettings = settings
+}
+
+// CurrentTaxSettings returns the configured tax classes and mode.
+func CurrentTaxSettings() models.TaxSettings {
+	taxSettingsMu.RLock()
+	defer taxSettingsMu.RUnlock()
+	return taxSettings
+}
+
+// applyTax sets the tax class, rate and amount of items, whose line totals after their
+// discount share are taxed, and returns the order's tax. itemClasses holds the tax class
+// of the pricelist items that have one, by pricelist item ID.
+func applyTax(settings models.TaxSettings, items []models.OrderItem, itemClasses map[int64]string) models.Money {
+	var total models.Money
+	for i := range items {
+		class, rate, ok := settings.ClassFor(itemClasses[items[i].PricelistItemID])
+		if !ok {
+			continue // Untaxed
+		}
+		items[i].TaxClass = &class
+		items[i].TaxRate = &rate
+		items[i].TaxAmount = settings.TaxOn(items[i].TotalPrice.Sub(items[i].DiscountAmount), rate)
+		total = total.Add(items[i].TaxAmount)
+	}
+	return total
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/repositories"
+	apperrors "ps_club_backend/pkg/errors"
+	"ps_club_backend/pkg/utils"
+)
+
+var (
+	ErrTimeOffValidation = apperrors.New(utils.ErrCodeValidationFailed, "time off validation error")
+	ErrTimeOffNotFound   = apperrors.New(utils.ErrCodeNotFound, "time off request not found")
+	ErrTimeOffNotPending = apperrors.New(utils.ErrCodeConflict, "the time off request is not pending")
+	ErrTimeOffForbidden  = apperrors.New(utils.ErrCodeForbidden, "staff may only manage their own time off and availability")
+)
+
+// MaxTimeOffDays is the longest time off a single request may cover.
+const MaxTimeOffDays = 90
+
+// maxAvailabilityWindows bounds the availability windows of a staff member.
+const maxAvailabilityWindows = 50
+
+// TimeOffCaller is the authenticated user managing time off or availability.
+type TimeOffCaller struct {
+	UserID int64
+	Role   string
+}
+
+func (c TimeOffCaller) isAdmin() bool { return c.Role == "Admin" }
+
+// CreateTimeOffRequest is the body of POST /time-off.
+type CreateTimeOffRequest struct {
+	StaffID   *int64  `json:"staff_id"` // Admins may request for anyone; defaults to the caller's staff profile
+	StartDate string  `json:"start_date" binding:"required,date"`
+	EndDate   string  `json:"end_date" binding:"required,date"` // Inclusive
+	Reason    *string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+// DecideTimeOffRequest is the optional body of POST /time-off/:id/approve and /reject.
+type DecideTimeOffRequest struct {
+	Note *string `json:"note" binding:"omitempty,max=1000"`
+}
+
+// SetAvailabilityRequest is the body of PUT /staff/:id/availability.
+type SetAvailabilityRequest struct {
+	Windows []models.AvailabilityWindow `json:"windows" binding:"required"`
+}
+
+// --- TimeOffService Interface ---
+type TimeOffService interface {
+	// RequestTimeOff records a pending time off request. Staff request for themselves and not
+	// for past days; Admins may request for anyone, e.g. to record sick leave.
+	RequestTimeOff(req CreateTimeOffRequest, caller TimeOffCaller) (*models.TimeOffRequest, error)
+	// GetRequests lists the time off requests matching filters; Staff only see their own.
+	GetRequests(filters models.TimeOffFilters, caller TimeOffCaller) ([]models.TimeOffRequest, error)
+	// GetRequest returns a time off request with the shifts scheduled during it, if it is
+	// pending or approved.
+	GetRequest(id int64, caller TimeOffCaller) (*models.TimeOffRequest, error)
+	// Approve approves a pending request. It returns the shifts scheduled during the time off,
+	// which are left to the Admin to reassign; no new shift can be scheduled on those days.
+	Approve(id int64, adminUserID int64, note *string) (*models.TimeOffRequest, error)
+	Reject(id int64, adminUserID int64, note *string) (*models.TimeOffRequest, error)
+	// Cancel withdraws a request: Staff their own pending requests, Admins any pending or
+	// approved request.
+	Cancel(id int64, caller TimeOffCaller) (*models.TimeOffRequest, error)
+
+	// GetAvailability returns the weekly availability windows of a staff member.
+	GetAvailability(staffID int64) ([]models.AvailabilityWindow, error)
+	// SetAvailability replaces the weekly availability windows of a staff member; Staff only
+	// set their own.
+	SetAvailability(staffID int64, req SetAvailabilityRequest, caller TimeOffCaller) ([]models.AvailabilityWindow, error)
+	// GetDayAvailability returns, for scheduling a date (YYYY-MM-DD), each staff member with
+	// whether they are off that day and their availability windows of its weekday.
+	GetDayAvailability(date string) ([]models.StaffDayAvailability, error)
+}
+
+type timeOffService struct {
+	timeOffRepo repositories.TimeOffRepository
+	staffRepo   repositories.StaffRepository
+	db          *sql.DB
+}
+
+// NewTimeOffService creates a new TimeOffService.
+func NewTimeOffService(timeOffRepo repositories.TimeOffRepository, staffRepo repositories.StaffRepository, db *sql.DB) TimeOffService {
+	return &timeOffService{timeOffRepo: timeOffRepo, staffRepo: staffRepo, db: db}
+}
+
+// callerStaffID returns the staff member linked to the caller; ErrNoStaffProfile if there is none.
+func (s *timeOffService) callerStaffID(caller TimeOffCaller) (int64, error) {
+	staff, err := s.staffRepo.GetStaffMemberByUserID(caller.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return 0, ErrNoStaffProfile
+		}
+		return 0, fmt.Errorf("failed to get staff profile: %w", err)
+	}
+	return staff.ID, nil
+}
+
+// checkOwnStaff returns ErrTimeOffForbidden unless the caller is an Admin or is staffID.
+func (s *timeOffService) checkOwnStaff(staffID int64, caller TimeOffCaller) error {
+	if caller.isAdmin() {
+		return nil
+	}
+	ownID, err := s.callerStaffID(caller)
+	if err != nil {
+		return err
+	}
+	if ownID != staffID {
+		return ErrTimeOffForbidden
+	}
+	return nil
+}
+
+func (s *timeOffService) RequestTimeOff(req CreateTimeOffRequest, caller TimeOffCaller) (*models.TimeOffRequest, error) {
+	start, err := time.Parse(utils.DateLayout, req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_date, use YYYY-MM-DD", ErrTimeOffValidation)
+	}
+	end, err := time.Parse(utils.DateLayout, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end_date, use YYYY-MM-DD", ErrTimeOffValidation)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end_date must not be before start_date", ErrTimeOffValidation)
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > MaxTimeOffDays {
+		return nil, fmt.Errorf("%w: a request may cover at most %d days", ErrTimeOffValidation, MaxTimeOffDays)
+	}
+	if req.Reason != nil && strings.TrimSpace(*req.Reason) == "" {
+		req.Reason = nil
+	}
+
+	var staffID int64
+	switch {
+	case req.StaffID != nil && caller.isAdmin():
+		staffID = *req.StaffID
+		if _, err := s.staffRepo.GetStaffMemberByID(staffID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return nil, ErrStaffNotFound
+			}
+			return nil, fmt.Errorf("failed to get staff member: %w", err)
+		}
+	default:
+		if staffID, err = s.callerStaffID(caller); err != nil {
+			return nil, err
+		}
+		if req.StaffID != nil && *req.StaffID != staffID {
+			return nil, ErrTimeOffForbidden
+		}
+	}
+	if !caller.isAdmin() && req.StartDate < utils.NowInClub().Format(utils.DateLayout) {
+		return nil, fmt.Errorf("%w: time off cannot start in the past", ErrTimeOffValidation)
+	}
+
+	request := &models.TimeOffRequest{
+		StaffID:     staffID,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Reason:      req.Reason,
+		Status:      models.TimeOffPending,
+		RequestedBy: &caller.UserID,
+	}
+	if err := s.timeOffRepo.CreateRequest(request); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to create time off request: %w", err)
+	}
+	return s.getRequest(request.ID)
+}
+
+func (s *timeOffService) GetRequests(filters models.TimeOffFilters, caller TimeOffCaller) ([]models.TimeOffRequest, error) {
+	if filters.Status != nil && !slices.Contains(models.TimeOffStatuses, *filters.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %v", ErrTimeOffValidation, models.TimeOffStatuses)
+	}
+	for _, date := range []*string{filters.From, filters.To} {
+		if date != nil && !utils.IsValidDate(*date) {
+			return nil, fmt.Errorf("%w: invalid date %q, use YYYY-MM-DD", ErrTimeOffValidation, *date)
+		}
+	}
+	if !caller.isAdmin() {
+		staffID, err := s.callerStaffID(caller)
+		if err != nil {
+			return nil, err
+		}
+		if filters.StaffID != nil && *filters.StaffID != staffID {
+			return nil, ErrTimeOffForbidden
+		}
+		filters.StaffID = &staffID
+	}
+	requests, err := s.timeOffRepo.GetRequests(filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time off requests: %w", err)
+	}
+	return requests, nil
+}
+
+// getRequest returns a request with its conflicting shifts; ErrTimeOffNotFound if there is none.
+func (s *timeOffService) getRequest(id int64) (*models.TimeOffRequest, error) {
+	request, err := s.timeOffRepo.GetRequestByID(id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTimeOffNotFound
+		}
+		return nil, fmt.Errorf("failed to get time off request: %w", err)
+	}
+	if request.Status != models.TimeOffPending && request.Status != models.TimeOffApproved {
+		return request, nil
+	}
+	from, err := utils.ParseClubDate(request.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date of time off request ID %d: %w", request.ID, err)
+	}
+	lastDay, err := utils.ParseClubDate(request.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date of time off request ID %d: %w", request.ID, err)
+	}
+	_, to := utils.DayBounds(lastDay)
+	request.ConflictingShifts, err = s.timeOffRepo.GetShiftsBetween(request.StaffID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shifts during time off: %w", err)
+	}
+	return request, nil
+}
+
+func (s *timeOffService) GetRequest(id int64, caller TimeOffCaller) (*models.TimeOffRequest, error) {
+	request, err := s.getRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnStaff(request.StaffID, caller); err != nil {
+		if errors.Is(err, ErrTimeOffForbidden) {
+			return nil, ErrTimeOffNotFound // Others' requests are not disclosed
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// decide moves a pending request to status as decided by an Admin.
+func (s *timeOffService) decide(id int64, status string, adminUserID int64, note *string) (*models.TimeOffRequest, error) {
+	if note != nil && strings.TrimSpace(*note) == "" {
+		note = nil
+	}
+	err := s.timeOffRepo.UpdateStatus(id, []string{models.TimeOffPending}, status, &adminUserID, note, utils.NowUTC())
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			if _, getErr := s.getRequest(id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrTimeOffNotPending
+		}
+		return nil, fmt.Errorf("failed to decide time off request: %w", err)
+	}
+	return s.getRequest(id)
+}
+
+func (s *timeOffService) Approve(id int64, adminUserID int64, note *string) (*models.TimeOffRequest, error) {
+	return s.decide(id, models.TimeOffApproved, adminUserID, note)
+}
+
+func (s *timeOffService) Reject(id int64, adminUserID int64, note *string) (*models.TimeOffRequest, error) {
+	return s.decide(id, models.TimeOffRejected, adminUserID, note)
+}
+
+func (s *timeOffService) Cancel(id int64, caller TimeOffCaller) (*models.TimeOffRequest, error) {
+	request, err := s.GetRequest(id, caller)
+	if err != nil {
+		return nil, err
+	}
+	cancellable := []string{models.TimeOffPending}
+	if caller.isAdmin() {
+		cancellable = append(cancellable, models.TimeOffApproved)
+	}
+	if !slices.Contains(cancellable, request.Status) {
+		return nil, fmt.Errorf("%w: a %s request cannot be cancelled", ErrTimeOffNotPending, request.Status)
+	}
+	if err := s.timeOffRepo.UpdateStatus(id, cancellable, models.TimeOffCancelled, nil, nil, utils.NowUTC()); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrTimeOffNotPending // Decided in the meantime
+		}
+		return nil, fmt.Errorf("failed to cancel time off request: %w", err)
+	}
+	return s.getRequest(id)
+}
+
+func (s *timeOffService) GetAvailability(staffID int64) ([]models.AvailabilityWindow, error) {
+	if _, err := s.staffRepo.GetStaffMemberByID(staffID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to get staff member: %w", err)
+	}
+	windows, err := s.timeOffRepo.GetAvailability(staffID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff availability: %w", err)
+	}
+	return windows, nil
+}
+
+// parseWindowTime parses the HH:MM of an availability window; "24:00" ends a day.
+func parseWindowTime(value string, allowEndOfDay bool) (int, bool) {
+	if allowEndOfDay && value == "24:00" {
+		return 24 * 60, true
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// validateAvailability checks windows, writing their times as HH:MM, and sorts them by weekday
+// and start time.
+func validateAvailability(windows []models.AvailabilityWindow) error {
+	if len(windows) > maxAvailabilityWindows {
+		return fmt.Errorf("%w: at most %d availability windows", ErrTimeOffValidation, maxAvailabilityWindows)
+	}
+	for i := range windows {
+		window := &windows[i]
+		if window.Weekday < 1 || window.Weekday > 7 {
+			return fmt.Errorf("%w: weekday must be 1 (Monday) to 7 (Sunday)", ErrTimeOffValidation)
+		}
+		if !slices.Contains(models.AvailabilityPreferences, window.Preference) {
+			return fmt.Errorf("%w: preference must be one of %v", ErrTimeOffValidation, models.AvailabilityPreferences)
+		}
+		start, okStart := parseWindowTime(window.StartTime, false)
+		end, okEnd := parseWindowTime(window.EndTime, true)
+		if !okStart || !okEnd {
+			return fmt.Errorf("%w: start_time and end_time must be HH:MM", ErrTimeOffValidation)
+		}
+		if end <= start {
+			return fmt.Errorf("%w: end_time must be after start_time; split a window across midnight in two", ErrTimeOffValidation)
+		}
+		window.StartTime = fmt.Sprintf("%02d:%02d", start/60, start%60)
+		window.EndTime = fmt.Sprintf("%02d:%02d", end/60, end%60)
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].Weekday != windows[j].Weekday {
+			return windows[i].Weekday < windows[j].Weekday
+		}
+		return windows[i].StartTime < windows[j].StartTime
+	})
+	for i := 1; i < len(windows); i++ {
+		if windows[i].Weekday == windows[i-1].Weekday && windows[i].StartTime < windows[i-1].EndTime {
+			return fmt.Errorf("%w: availability windows of weekday %d overlap", ErrTimeOffValidation, windows[i].Weekday)
+		}
+	}
+	return nil
+}
+
+func (s *timeOffService) SetAvailability(staffID int64, req SetAvailabilityRequest, caller TimeOffCaller) ([]models.AvailabilityWindow, error) {
+	if err := validateAvailability(req.Windows); err != nil {
+		return nil, err
+	}
+	if _, err := s.staffRepo.GetStaffMemberByID(staffID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to get staff member: %w", err)
+	}
+	if err := s.checkOwnStaff(staffID, caller); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := s.timeOffRepo.ReplaceAvailability(tx, staffID, req.Windows); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, fmt.Errorf("failed to save staff availability: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit staff availability: %w", err)
+	}
+	return s.timeOffRepo.GetAvailability(staffID)
+}
+
+func (s *timeOffService) GetDayAvailability(date string) ([]models.StaffDayAvailability, error) {
+	day, err := time.Parse(utils.DateLayout, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid date, use YYYY-MM-DD", ErrTimeOffValidation)
+	}
+	weekday := int(day.Weekday())
+	if weekday == 0 {
+		weekday = 7 // Sunday
+	}
+	days, err := s.timeOffRepo.GetDayAvailability(date, weekday)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff availability: %w", err)
+	}
+	return days, nil
+}
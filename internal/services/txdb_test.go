@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+// newTxDB returns a *sql.DB whose transactions begin, commit and roll back but run no
+// statements, for services that open a transaction around calls to mocked repositories.
+func newTxDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(txOnlyConnector{})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type txOnlyConnector struct{}
+
+func (txOnlyConnector) Connect(context.Context) (driver.Conn, error) { return txOnlyConn{}, nil }
+func (txOnlyConnector) Driver() driver.Driver                        { return txOnlyDriver{} }
+
+type txOnlyDriver struct{}
+
+func (txOnlyDriver) Open(string) (driver.Conn, error) { return txOnlyConn{}, nil }
+
+type txOnlyConn struct{}
+
+func (txOnlyConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("txdb: unexpected statement %q, mock the repository instead", query)
+}
+func (txOnlyConn) Close() error              { return nil }
+func (txOnlyConn) Begin() (driver.Tx, error) { return txOnlyTx{}, nil }
+
+type txOnlyTx struct{}
+
+func (txOnlyTx) Commit() error   { return nil }
+func (txOnlyTx) Rollback() error { return nil }
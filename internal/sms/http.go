@@ -0,0 +1,69 @@
+// Package sms sends text messages through an SMS gateway's HTTP API.
+// services.BookingReminderService calls it to remind clients of their bookings.
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ps_club_backend/pkg/utils"
+)
+
+// Message is the JSON body posted to send a text message.
+type Message struct {
+	To   string `json:"to"` // The phone number as stored on the client
+	Text string `json:"text"`
+	From string `json:"from,omitempty"` // The sender name or number, if the gateway needs one
+}
+
+// HTTPGateway posts each message to URL:
+//
+//	POST {URL}   Message
+//
+// A 2xx response means the gateway accepted the message. Sends are not retried.
+type HTTPGateway struct {
+	URL    string
+	Token  string // Sent as a bearer token if set
+	From   string
+	Client *http.Client
+}
+
+// NewHTTPGateway creates an HTTPGateway for url.
+func NewHTTPGateway(url, token, from string) *HTTPGateway {
+	return &HTTPGateway{
+		URL:    strings.TrimRight(url, "/"),
+		Token:  token,
+		From:   from,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SendSMS sends text to phoneNumber.
+func (g *HTTPGateway) SendSMS(ctx context.Context, phoneNumber, text string) error {
+	payload, err := json.Marshal(Message{To: phoneNumber, Text: text, From: g.From})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending SMS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return utils.ResponseError("SMS gateway", resp)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package telegram sends messages to clients with the club's Telegram bot through the Bot API.
+// services.BookingReminderService calls it to remind clients of their bookings in the chat
+// they linked.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ps_club_backend/pkg/utils"
+)
+
+// DefaultAPIURL is the Telegram Bot API; the bot token completes it.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// BotSender sends messages with the bot of Token:
+//
+//	POST {APIURL}/bot{Token}/sendMessage   {"chat_id": ..., "text": "..."}
+//
+// Sends are not retried.
+type BotSender struct {
+	APIURL string
+	Token  string
+	Client *http.Client
+}
+
+// NewBotSender creates a BotSender for the bot with token.
+func NewBotSender(token string) *BotSender {
+	return &BotSender{APIURL: DefaultAPIURL, Token: token, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// apiResponse is the envelope of every Bot API response.
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// SendMessage sends text to the chat chatID.
+func (s *BotSender) SendMessage(ctx context.Context, chatID int64, text string) error {
+	payload, err := json.Marshal(map[string]interface{}{"chat_id": chatID, "text": text})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(s.APIURL, "/") + "/bot" + s.Token + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		// The URL holds the bot token, so the error is not wrapped as is
+		return fmt.Errorf("sending Telegram message: %s", errorWithoutURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var result apiResponse
+	if json.Unmarshal(utils.ReadErrorBody(resp), &result) == nil && result.Description != "" {
+		return fmt.Errorf("Telegram responded %s: %s", resp.Status, result.Description)
+	}
+	return fmt.Errorf("Telegram responded %s", resp.Status)
+}
+
+// errorWithoutURL returns the message of an error of http.Client.Do without the request URL.
+func errorWithoutURL(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
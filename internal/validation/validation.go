@@ -0,0 +1,168 @@
+// Package validation holds the rules request DTOs are validated with. Register adds
+// them to gin's binding validator, so they are used through `binding` struct tags:
+//
+//	PhoneNumber *string      `json:"phone_number" binding:"omitempty,phone"`
+//	DateOfBirth *string      `json:"date_of_birth" binding:"omitempty,date"`
+//	Salary      *models.Money `json:"salary" binding:"omitempty,money"`
+//	Status      string       `json:"status" binding:"required,order_status"`
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ps_club_backend/internal/models"
+	"ps_club_backend/internal/services"
+	"ps_club_backend/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom rules, by tag.
+var rules = map[string]validator.Func{
+	// phone accepts digits with an optional leading + and spaces, dashes or parentheses
+	"phone": func(fl validator.FieldLevel) bool { return utils.IsValidPhoneNumber(fl.Field().String()) },
+	// date accepts a date in YYYY-MM-DD format
+	"date": func(fl validator.FieldLevel) bool { return utils.IsValidDate(fl.Field().String()) },
+	// money accepts a non-negative amount; Money fields are validated as float64, see Register
+	"money":               func(fl validator.FieldLevel) bool { return fl.Field().Float() >= 0 },
+	"order_status":        oneOf(services.OrderStatuses),
+	"booking_status":      oneOf(bookingStatuses()),
+	"item_type":           oneOf(models.ItemTypes),
+	"stock_unit":          oneOf(models.StockUnits),
+	"movement_type":       oneOf(models.ManualMovementTypes),
+	"cancellation_reason": oneOf(models.CancellationReasons),
+	"void_reason":         oneOf(models.VoidReasons),
+	"note_category":       oneOf(models.CalendarNoteCategories),
+	"console_type":        oneOf(models.ConsoleTypes),
+	"billing_mode":        oneOf(models.BillingModes),
+}
+
+// allowedValues holds the values of the enum rules, for error messages.
+var allowedValues = map[string][]string{
+	"order_status":        services.OrderStatuses,
+	"booking_status":      bookingStatuses(),
+	"item_type":           models.ItemTypes,
+	"stock_unit":          models.StockUnits,
+	"movement_type":       models.ManualMovementTypes,
+	"cancellation_reason": models.CancellationReasons,
+	"void_reason":         models.VoidReasons,
+	"note_category":       models.CalendarNoteCategories,
+	"console_type":        models.ConsoleTypes,
+	"billing_mode":        models.BillingModes,
+}
+
+func bookingStatuses() []string {
+	statuses := make([]string, len(models.BookingStatuses))
+	for i, status := range models.BookingStatuses {
+		statuses[i] = string(status)
+	}
+	return statuses
+}
+
+// oneOf returns a rule accepting the given values. Item types are compared case-insensitively
+// like the services do, everything else exactly.
+func oneOf(values []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		for _, valid := range values {
+			if value == valid || (fl.GetTag() == "item_type" && strings.EqualFold(value, valid)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Register adds the custom rules and field types to v and makes errors name fields by their JSON name.
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// Validates Money as a float64, so tags like `binding:"required,gt=0"` work on Money fields
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if m, ok := field.Interface().(models.Money); ok {
+			return m.Decimal().InexactFloat64()
+		}
+		return nil
+	}, models.Money{})
+	// Likewise Quantity, e.g. `binding:"omitempty,gte=0"`
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if q, ok := field.Interface().(models.Quantity); ok {
+			return q.Decimal().InexactFloat64()
+		}
+		return nil
+	}, models.Quantity{})
+
+	for tag, rule := range rules {
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			return fmt.Errorf("failed to register validation rule %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// FieldErrors converts the validation errors in err to messages by field, e.g.
+// {"phone_number": "must be a valid phone number"}. Nested fields are named by path,
+// e.g. "order_items[0].quantity". It returns false if err is not a validation error.
+func FieldErrors(err error) (map[string]string, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+	fields := make(map[string]string, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// The namespace starts with the name of the request struct, which means nothing to the client
+		_, field, found := strings.Cut(fieldErr.Namespace(), ".")
+		if !found {
+			field = fieldErr.Field()
+		}
+		fields[field] = message(fieldErr)
+	}
+	return fields, true
+}
+
+// message describes the rule a field failed.
+func message(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a valid phone number"
+	case "date":
+		return "must be a date in YYYY-MM-DD format"
+	case "money":
+		return "must not be negative"
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at least " + fieldErr.Param() + " characters long"
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at most " + fieldErr.Param() + " characters long"
+		}
+		return "must be at most " + fieldErr.Param()
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "gte":
+		return "must be at least " + fieldErr.Param()
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	}
+	if values, ok := allowedValues[fieldErr.Tag()]; ok {
+		return "must be one of: " + strings.Join(values, ", ")
+	}
+	return "failed the " + fieldErr.Tag() + " check"
+}
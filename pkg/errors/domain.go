@@ -0,0 +1,48 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+)
+
+// DomainError is an error of the business rules, such as a missing record, invalid input or
+// a conflict, carrying the API error code (utils.ErrCode*) it is answered with. Services
+// declare them as sentinels, matched with errors.Is, and handlers map the code to an HTTP
+// status instead of classifying each error themselves.
+type DomainError struct {
+	Code    string
+	Message string
+	Details string
+	Err     error // The sentinel of an error made WithDetails
+}
+
+// New declares a domain error with an API error code and a message.
+func New(code, message string) *DomainError {
+	return &DomainError{Code: code, Message: message}
+}
+
+func (e *DomainError) Error() string {
+	if e.Details != "" {
+		return e.Message + ": " + e.Details
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails returns the error with details, such as the offending value; it still
+// matches e with errors.Is.
+func (e *DomainError) WithDetails(format string, args ...interface{}) *DomainError {
+	return &DomainError{Code: e.Code, Message: e.Message, Details: fmt.Sprintf(format, args...), Err: e}
+}
+
+// AsDomainError returns the first domain error in the chain of err.
+func AsDomainError(err error) (*DomainError, bool) {
+	var domainErr *DomainError
+	if stderrors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	return nil, false
+}
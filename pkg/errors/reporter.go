@@ -0,0 +1,117 @@
+// Package errors holds the domain errors services return and reports panics and server
+// errors to Sentry, or any service accepting Sentry DSNs, in addition to the log.
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ps_club_backend/pkg/utils"
+
+	"github.com/getsentry/sentry-go"
+)
+
+var (
+	enabled   bool
+	enabledMu sync.RWMutex
+)
+
+// Request describes the HTTP request an error or panic occurred in.
+type Request struct {
+	ID         string // X-Request-ID
+	Method     string
+	Route      string // As registered, e.g. "/api/v1/orders/:id"
+	Path       string
+	StatusCode int
+	UserID     *int64
+	Username   string
+}
+
+// Configure reports to the given DSN from now on; an empty DSN stops reporting. The
+// environment is tagged from SENTRY_ENVIRONMENT.
+func Configure(dsn string) error {
+	enabledMu.Lock()
+	defer enabledMu.Unlock()
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		enabled = false
+		return nil
+	}
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      utils.Getenv("SENTRY_ENVIRONMENT", "production"),
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid error reporting DSN: %w", err)
+	}
+	enabled = true
+	return nil
+}
+
+// ValidateDSN checks that dsn is a valid Sentry DSN; empty is valid and disables reporting.
+func ValidateDSN(dsn string) error {
+	if strings.TrimSpace(dsn) == "" {
+		return nil
+	}
+	if _, err := sentry.NewDsn(strings.TrimSpace(dsn)); err != nil {
+		return fmt.Errorf("invalid error reporting DSN: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether errors are reported.
+func Enabled() bool {
+	enabledMu.RLock()
+	defer enabledMu.RUnlock()
+	return enabled
+}
+
+// ReportPanic reports a value recovered from a panic while handling req.
+func ReportPanic(recovered interface{}, req Request) {
+	if !Enabled() {
+		return
+	}
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) { applyRequest(scope, req) })
+	hub.Recover(recovered)
+}
+
+// ReportError reports an error that failed req, such as one answered with a 5xx status.
+func ReportError(err error, req Request) {
+	if !Enabled() || err == nil {
+		return
+	}
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) { applyRequest(scope, req) })
+	hub.CaptureException(err)
+}
+
+// Warn logs and reports an error that is worked around rather than returned, such as
+// a detail that could not be loaded for a response.
+func Warn(err error, message string) {
+	if err == nil {
+		return
+	}
+	utils.LogWarn(message, map[string]interface{}{"error": err.Error()})
+	if !Enabled() {
+		return
+	}
+	hub := sentry.CurrentHub().Clone()
+	hub.ConfigureScope(func(scope *sentry.Scope) { scope.SetLevel(sentry.LevelWarning) })
+	hub.CaptureException(fmt.Errorf("%s: %w", message, err))
+}
+
+func applyRequest(scope *sentry.Scope, req Request) {
+	scope.SetTags(map[string]string{
+		"request_id":  req.ID,
+		"route":       req.Method + " " + req.Route,
+		"status_code": strconv.Itoa(req.StatusCode),
+	})
+	scope.SetContext("request", sentry.Context{"method": req.Method, "path": req.Path})
+	if req.UserID != nil {
+		scope.SetUser(sentry.User{ID: strconv.FormatInt(*req.UserID, 10), Username: req.Username})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultBranchCode is used when the instance is not configured for a branch.
+const DefaultBranchCode = "main"
+
+var branchCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+
+var (
+	branchCode   = DefaultBranchCode
+	branchCodeMu sync.RWMutex
+)
+
+// SetBranchCode sets the code of the branch this instance serves (e.g. "center").
+// Per-branch data such as daily order numbers is kept apart by this code. An empty
+// code resets to DefaultBranchCode.
+func SetBranchCode(code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		code = DefaultBranchCode
+	}
+	if !branchCodePattern.MatchString(code) {
+		return fmt.Errorf("invalid branch code %q: use up to 20 letters, digits, '-' or '_'", code)
+	}
+	branchCodeMu.Lock()
+	branchCode = code
+	branchCodeMu.Unlock()
+	return nil
+}
+
+// IsValidBranchCode reports whether code is a valid branch code.
+func IsValidBranchCode(code string) bool {
+	return branchCodePattern.MatchString(code)
+}
+
+// BranchCode returns the configured branch code.
+func BranchCode() string {
+	branchCodeMu.RLock()
+	defer branchCodeMu.RUnlock()
+	return branchCode
+}
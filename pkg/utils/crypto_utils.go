@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptedValuePrefix marks values encrypted by EncryptString. The ID of the key and the
+// base64 nonce and ciphertext follow: "enc:v1:<key id>:<data>".
+const encryptedValuePrefix = "enc:v1:"
+
+// ErrUnknownEncryptionKey is returned when a value is encrypted with a key that is not configured.
+var ErrUnknownEncryptionKey = errors.New("value is encrypted with an unknown key")
+
+// encryptionKey is an AES-256-GCM key and its ID, the start of the SHA-256 of the key.
+type encryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var (
+	currentEncryptionKey *encryptionKey
+	encryptionKeysByID   = map[string]*encryptionKey{}
+	encryptionKeysMu     sync.RWMutex
+)
+
+// SetEncryptionKeys sets the key new values are encrypted with and the previous keys, which
+// values encrypted before a rotation may still use. Keys are 32 random bytes, base64 encoded.
+// Without a current key values are not encrypted, but previous keys still decrypt.
+func SetEncryptionKeys(current string, previous []string) error {
+	var currentKey *encryptionKey
+	keysByID := map[string]*encryptionKey{}
+	for i, encoded := range append([]string{current}, previous...) {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+		key, err := parseEncryptionKey(encoded)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			currentKey = key
+		}
+		keysByID[key.id] = key
+	}
+	encryptionKeysMu.Lock()
+	currentEncryptionKey = currentKey
+	encryptionKeysByID = keysByID
+	encryptionKeysMu.Unlock()
+	return nil
+}
+
+func parseEncryptionKey(encoded string) (*encryptionKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: not base64: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("invalid encryption key: %d bytes, expected 32", len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return &encryptionKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// EncryptionEnabled reports whether a current encryption key is set.
+func EncryptionEnabled() bool {
+	encryptionKeysMu.RLock()
+	defer encryptionKeysMu.RUnlock()
+	return currentEncryptionKey != nil
+}
+
+// EncryptString encrypts plain with the current key (AES-256-GCM). Without a current key
+// it returns plain unchanged.
+func EncryptString(plain string) (string, error) {
+	encryptionKeysMu.RLock()
+	key := currentEncryptionKey
+	encryptionKeysMu.RUnlock()
+	if key == nil {
+		return plain, nil
+	}
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := key.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedValuePrefix + key.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptString decrypts a value encrypted by EncryptString with the current or a previous
+// key. Values that are not encrypted, such as those stored before encryption was enabled,
+// are returned unchanged.
+func DecryptString(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	keyID, data, ok := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	encryptionKeysMu.RLock()
+	key := encryptionKeysByID[keyID]
+	encryptionKeysMu.RUnlock()
+	if key == nil {
+		return "", fmt.Errorf("%w %s", ErrUnknownEncryptionKey, keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+	plain, err := key.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypting value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// IsEncrypted reports whether value was encrypted by EncryptString.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix)
+}
+
+// NeedsReencryption reports whether value is not encrypted with the current key, so a key
+// rotation must encrypt it again. Without a current key nothing needs it.
+func NeedsReencryption(value string) bool {
+	encryptionKeysMu.RLock()
+	key := currentEncryptionKey
+	encryptionKeysMu.RUnlock()
+	return key != nil && !strings.HasPrefix(value, encryptedValuePrefix+key.id+":")
+}
+
+// LoadEncryptionKeys sets the encryption keys from the environment: the current key from
+// DATA_ENCRYPTION_KEY, or from the file named by DATA_ENCRYPTION_KEY_FILE (e.g. written by a
+// KMS or secret manager agent), and the comma-separated DATA_ENCRYPTION_PREVIOUS_KEYS.
+func LoadEncryptionKeys() error {
+	current := os.Getenv("DATA_ENCRYPTION_KEY")
+	if path := os.Getenv("DATA_ENCRYPTION_KEY_FILE"); current == "" && path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading DATA_ENCRYPTION_KEY_FILE: %w", err)
+		}
+		current = string(content)
+	}
+	var previous []string
+	if keys := os.Getenv("DATA_ENCRYPTION_PREVIOUS_KEYS"); keys != "" {
+		previous = strings.Split(keys, ",")
+	}
+	return SetEncryptionKeys(current, previous)
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Currency describes how monetary amounts are rounded and rendered.
+// Decimals is the number of minor unit digits amounts are rounded to
+// (e.g. 2 for KZT tiyn, 0 for currencies without minor units).
+type Currency struct {
+	Code               string `json:"code"`
+	Symbol             string `json:"symbol"`
+	Decimals           int    `json:"decimals"`
+	SymbolFirst        bool   `json:"symbol_first"`
+	ThousandsSeparator string `json:"thousands_separator"`
+	DecimalSeparator   string `json:"decimal_separator"`
+}
+
+// DefaultCurrencyCode is used when no currency is configured.
+const DefaultCurrencyCode = "KZT"
+
+// knownCurrencies holds defaults for the currencies the club is expected to use.
+var knownCurrencies = map[string]Currency{
+	"KZT": {Code: "KZT", Symbol: "₸", Decimals: 2, SymbolFirst: false, ThousandsSeparator: " ", DecimalSeparator: ","},
+	"RUB": {Code: "RUB", Symbol: "₽", Decimals: 2, SymbolFirst: false, ThousandsSeparator: " ", DecimalSeparator: ","},
+	"USD": {Code: "USD", Symbol: "$", Decimals: 2, SymbolFirst: true, ThousandsSeparator: ",", DecimalSeparator: "."},
+	"EUR": {Code: "EUR", Symbol: "€", Decimals: 2, SymbolFirst: false, ThousandsSeparator: " ", DecimalSeparator: ","},
+}
+
+var (
+	currentCurrency   = knownCurrencies[DefaultCurrencyCode]
+	currentCurrencyMu sync.RWMutex
+)
+
+// ParseCurrencySetting parses a currency setting value. The value is either a
+// currency code ("USD") or a JSON object; JSON fields override the defaults of
+// a known code.
+func ParseCurrencySetting(value string) (Currency, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return knownCurrencies[DefaultCurrencyCode], nil
+	}
+	if !strings.HasPrefix(value, "{") {
+		c, ok := knownCurrencies[strings.ToUpper(value)]
+		if !ok {
+			return Currency{}, fmt.Errorf("unknown currency code %q, provide a JSON definition instead", value)
+		}
+		return c, nil
+	}
+
+	var probe struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(value), &probe); err != nil {
+		return Currency{}, fmt.Errorf("invalid currency definition: %w", err)
+	}
+	c := knownCurrencies[strings.ToUpper(probe.Code)] // Zero value for unknown codes
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		return Currency{}, fmt.Errorf("invalid currency definition: %w", err)
+	}
+	c.Code = strings.ToUpper(c.Code)
+	if err := validateCurrency(c); err != nil {
+		return Currency{}, err
+	}
+	return c, nil
+}
+
+func validateCurrency(c Currency) error {
+	if len(c.Code) != 3 {
+		return fmt.Errorf("currency code must be a 3-letter ISO 4217 code, got %q", c.Code)
+	}
+	if c.Decimals < 0 || c.Decimals > 4 {
+		return fmt.Errorf("currency decimals must be between 0 and 4, got %d", c.Decimals)
+	}
+	if c.Decimals > 0 && c.DecimalSeparator == "" {
+		return fmt.Errorf("currency decimal_separator is required when decimals > 0")
+	}
+	return nil
+}
+
+// SetCurrency sets the currency used for parsing and rendering monetary amounts.
+func SetCurrency(c Currency) error {
+	if err := validateCurrency(c); err != nil {
+		return err
+	}
+	currentCurrencyMu.Lock()
+	currentCurrency = c
+	currentCurrencyMu.Unlock()
+	return nil
+}
+
+// CurrentCurrency returns the configured currency.
+func CurrentCurrency() Currency {
+	currentCurrencyMu.RLock()
+	defer currentCurrencyMu.RUnlock()
+	return currentCurrency
+}
+
+// FormatAmount renders a plain decimal string that is already rounded to the
+// currency decimals ("-1250.50") for display ("-1 250,50 ₸") using the
+// configured currency's symbol and separators.
+func FormatAmount(fixed string) string {
+	c := CurrentCurrency()
+	sign := ""
+	if strings.HasPrefix(fixed, "-") {
+		sign = "-"
+		fixed = fixed[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(fixed, ".")
+
+	var grouped strings.Builder
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteString(c.ThousandsSeparator)
+		}
+		grouped.WriteRune(digit)
+	}
+	number := grouped.String()
+	if fracPart != "" {
+		number += c.DecimalSeparator + fracPart
+	}
+	if c.Symbol == "" {
+		return sign + number + " " + c.Code
+	}
+	if c.SymbolFirst {
+		return sign + c.Symbol + number
+	}
+	return sign + number + " " + c.Symbol
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -26,12 +28,54 @@ func NewAPIError(statusCode int, code string, message string, details string) *A
 	}
 }
 
-// RespondWithError sends a standardized JSON error response
+// RespondWithError sends a standardized JSON error response. The message is
+// translated by error code into the request's language (see RequestLanguage).
 func RespondWithError(c *gin.Context, err *APIError) {
+	if err.StatusCode >= http.StatusInternalServerError {
+		// Attached for the error report of middleware.Recovery
+		_ = c.Error(fmt.Errorf("%s: %s", err.Message, err.Details))
+	}
+	err = localizeAPIError(c, err)
 	c.JSON(err.StatusCode, gin.H{"error": err})
 	c.Abort() // Abort further processing if it's a middleware or critical error
 }
 
+// RespondWithVersionConflict sends a 409 response for an update that carried a stale
+// version, including the current state of the record so the client can merge and retry.
+func RespondWithVersionConflict(c *gin.Context, current interface{}) {
+	apiErr := NewAPIError(http.StatusConflict, ErrCodeVersionConflict, "The record was modified by another user. Reload it and retry.", "")
+	apiErr = localizeAPIError(c, apiErr)
+	c.JSON(apiErr.StatusCode, gin.H{"error": apiErr, "current": current})
+	c.Abort()
+}
+
+// RespondWithForbiddenFields sends a 403 response for an update that changed fields the
+// caller's role may not change, listing them so the client can drop them and retry.
+func RespondWithForbiddenFields(c *gin.Context, fields []string) {
+	apiErr := NewAPIError(http.StatusForbidden, ErrCodeFieldNotPermitted, "Your role may not change some of the submitted fields.", strings.Join(fields, ", "))
+	apiErr = localizeAPIError(c, apiErr)
+	c.JSON(apiErr.StatusCode, gin.H{"error": apiErr, "fields": fields})
+	c.Abort()
+}
+
+// RespondWithDayCloseBlocked sends a 409 response for an end-of-day close that found records
+// still open, listing them so they can be closed or the day closed with force.
+func RespondWithDayCloseBlocked(c *gin.Context, open interface{}) {
+	apiErr := NewAPIError(http.StatusConflict, ErrCodeDayCloseBlocked, "Orders, table sessions or shifts of the day are still open.", "")
+	apiErr = localizeAPIError(c, apiErr)
+	c.JSON(apiErr.StatusCode, gin.H{"error": apiErr, "open": open})
+	c.Abort()
+}
+
+// RespondWithClientDuplicates sends a 409 response for a new client that probably exists
+// already, listing the candidates so one can be used instead or the client created with force.
+func RespondWithClientDuplicates(c *gin.Context, candidates interface{}) {
+	apiErr := NewAPIError(http.StatusConflict, ErrCodeClientDuplicate, "The client probably exists already.", "")
+	apiErr = localizeAPIError(c, apiErr)
+	c.JSON(apiErr.StatusCode, gin.H{"error": apiErr, "candidates": candidates})
+	c.Abort()
+}
+
 // Common Error Constants (examples)
 const (
 	ErrCodeBadRequest          = "BAD_REQUEST"
@@ -39,6 +83,20 @@ const (
 	ErrCodeForbidden           = "FORBIDDEN"
 	ErrCodeNotFound            = "NOT_FOUND"
 	ErrCodeConflict            = "CONFLICT"
+	ErrCodeVersionConflict     = "VERSION_CONFLICT"
+	ErrCodeTooManyRequests     = "TOO_MANY_REQUESTS"
+	ErrCodeIdempotencyKeyReuse = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeDiscountLimitExceeded = "DISCOUNT_LIMIT_EXCEEDED" // Retry with a manager override (X-Approval-PIN)
+	ErrCodeFieldNotPermitted   = "FIELD_NOT_PERMITTED" // The response lists the offending fields
+	ErrCodeBookingNoticeTooShort = "BOOKING_NOTICE_TOO_SHORT" // The booking starts sooner than the booking policy allows
+	ErrCodeLateCancellationFee   = "LATE_CANCELLATION_FEE"    // Retry with accept_late_fee to cancel for the fee
+	ErrCodeDayCloseBlocked       = "DAY_CLOSE_BLOCKED"        // The response lists the open records; retry with force to close them
+	ErrCodeDayClosed             = "DAY_CLOSED"               // The business day was closed; only an Admin may change its records
+	ErrCodeClientBlacklisted     = "CLIENT_BLACKLISTED"       // Retry with override_blacklist for a manager override, if the booking policy allows it
+	ErrCodeClientDuplicate       = "CLIENT_DUPLICATE"         // The response lists the probable duplicates; retry with force to create the client anyway
+	ErrCodeClockInRestricted     = "CLOCK_IN_RESTRICTED"      // The clock-in was recorded as a violation of the clock_in setting; an Admin may override it
+	ErrCodePaymentFailed         = "PAYMENT_FAILED"           // The payment provider declined or could not be reached
+	ErrCodeDeviceFailed          = "DEVICE_FAILED"            // A device of the club, e.g. a table's power relay, did not respond
 	ErrCodeInternalServerError = "INTERNAL_SERVER_ERROR"
 	ErrCodeValidationFailed    = "VALIDATION_FAILED"
 	ErrCodeNotImplemented    = "NOT_IMPLEMENTED" // New code
@@ -63,18 +121,12 @@ func IsValidPasswordLength(password string, minLength int) bool {
 	return len(password) >= minLength
 }
 
-// ValidatePayloadWithRules is a generic helper to validate a payload based on a map of rules.
-// Rules map: fieldName -> validationFunction (func(value interface{}) (bool, string))
-// This is a conceptual example; a more robust solution would use struct tags and a validation library like validator/v10.
-func ValidatePayloadWithRules(payload interface{}, rules map[string]func(interface{}) (bool, string)) (bool, map[string]string) {
-	// This function would require reflection to iterate over payload fields and apply rules.
-	// For simplicity in this context, direct validation in handlers is often clearer without a complex generic validator.
-	// Or, use a library like "github.com/go-playground/validator/v10"
-	// Example usage with validator/v10:
-	// validate := validator.New()
-	// err := validate.Struct(payload)
-	// if err != nil { ... handle validation errors ... }
-	panic("ValidatePayloadWithRules is conceptual and not fully implemented here. Use a library like go-playground/validator.")
+// IsValidPhoneNumber checks if a string looks like a phone number: 7 to 20 digits,
+// spaces, dashes or parentheses with an optional leading +.
+var phoneRegex = regexp.MustCompile(`^\+?[0-9\s\-()]{7,20}$`)
+
+func IsValidPhoneNumber(phone string) bool {
+	return phoneRegex.MatchString(strings.TrimSpace(phone))
 }
 
 // Helper to return a standard validation error
@@ -82,3 +134,17 @@ func RespondValidationFailed(c *gin.Context, details string) {
 	RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeValidationFailed, "Input validation failed", details))
 }
 
+// RespondWithValidationErrors sends a 400 response for a request that failed validation,
+// with the problem of each invalid field (`{"error": {...}, "fields": {"email": "..."}}`).
+func RespondWithValidationErrors(c *gin.Context, fields map[string]string) {
+	details := make([]string, 0, len(fields))
+	for field, problem := range fields {
+		details = append(details, field+" "+problem)
+	}
+	sort.Strings(details)
+	apiErr := NewAPIError(http.StatusBadRequest, ErrCodeValidationFailed, "Input validation failed", strings.Join(details, "; "))
+	apiErr = localizeAPIError(c, apiErr)
+	c.JSON(apiErr.StatusCode, gin.H{"error": apiErr, "fields": fields})
+	c.Abort()
+}
+
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxErrorBody is how much of an error response of another service is kept in the error.
+const MaxErrorBody = 500
+
+// ReadErrorBody reads the start of the body of an error response, at most MaxErrorBody
+// bytes, without surrounding spaces.
+func ReadErrorBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBody))
+	return bytes.TrimSpace(body)
+}
+
+// ResponseError returns the error of a response of service with an unexpected status, e.g.
+// "SMS gateway responded 502 Bad Gateway: upstream timeout", with the start of its body.
+func ResponseError(service string, resp *http.Response) error {
+	if msg := ReadErrorBody(resp); len(msg) > 0 {
+		return fmt.Errorf("%s responded %s: %s", service, resp.Status, msg)
+	}
+	return fmt.Errorf("%s responded %s", service, resp.Status)
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Languages of API messages. English is the language of the source strings.
+const (
+	LanguageEnglish = "en"
+	LanguageRussian = "ru"
+	LanguageKazakh  = "kk"
+)
+
+// SupportedLanguages lists the languages API messages are available in.
+var SupportedLanguages = []string{LanguageEnglish, LanguageRussian, LanguageKazakh}
+
+// LanguageContextKey is the gin context key of the authenticated user's preferred
+// language, set by AuthMiddleware from the token.
+const LanguageContextKey = "userLanguage"
+
+// errorMessages translates the message of error responses by error code. English
+// responses keep the specific message written by the handler.
+var errorMessages = map[string]map[string]string{
+	LanguageRussian: {
+		ErrCodeBadRequest:            "Некорректный запрос.",
+		ErrCodeUnauthorized:          "Требуется авторизация.",
+		ErrCodeForbidden:             "Недостаточно прав для выполнения действия.",
+		ErrCodeNotFound:              "Запись не найдена.",
+		ErrCodeConflict:              "Конфликт с текущим состоянием данных.",
+		ErrCodeVersionConflict:       "Запись была изменена другим пользователем. Обновите её и повторите попытку.",
+		ErrCodeTooManyRequests:       "Слишком много запросов. Повторите попытку позже.",
+		ErrCodeIdempotencyKeyReuse:   "Ключ идемпотентности уже использован для другого запроса.",
+		ErrCodeDiscountLimitExceeded: "Скидка превышает лимит вашей роли. Требуется подтверждение менеджера.",
+		ErrCodeFieldNotPermitted:     "Ваша роль не может изменять некоторые из переданных полей.",
+		ErrCodeBookingNoticeTooShort: "Бронирование начинается слишком скоро по правилам клуба.",
+		ErrCodeLateCancellationFee:   "Поздняя отмена платная. Подтвердите оплату, чтобы отменить бронирование.",
+		ErrCodeDayCloseBlocked:       "Заказы, игровые сессии или смены этого дня ещё не закрыты.",
+		ErrCodeDayClosed:             "Рабочий день закрыт. Изменять его записи может только администратор.",
+		ErrCodeClientDuplicate:       "Похоже, такой клиент уже существует.",
+		ErrCodeInternalServerError:   "Внутренняя ошибка сервера.",
+		ErrCodeValidationFailed:      "Ошибка проверки данных.",
+		ErrCodeNotImplemented:        "Функция ещё не реализована.",
+	},
+	LanguageKazakh: {
+		ErrCodeBadRequest:            "Сұраныс дұрыс емес.",
+		ErrCodeUnauthorized:          "Авторизация қажет.",
+		ErrCodeForbidden:             "Бұл әрекетті орындауға құқығыңыз жеткіліксіз.",
+		ErrCodeNotFound:              "Жазба табылмады.",
+		ErrCodeConflict:              "Деректердің ағымдағы күйімен қайшылық бар.",
+		ErrCodeVersionConflict:       "Жазбаны басқа пайдаланушы өзгертті. Оны жаңартып, қайталап көріңіз.",
+		ErrCodeTooManyRequests:       "Сұраныстар тым көп. Кейінірек қайталап көріңіз.",
+		ErrCodeIdempotencyKeyReuse:   "Идемпотенттілік кілті басқа сұраныс үшін қолданылған.",
+		ErrCodeDiscountLimitExceeded: "Жеңілдік рөліңіздің шегінен асады. Менеджердің растауы қажет.",
+		ErrCodeFieldNotPermitted:     "Рөліңіз жіберілген кейбір өрістерді өзгерте алмайды.",
+		ErrCodeBookingNoticeTooShort: "Клуб ережелері бойынша брондау тым ерте басталады.",
+		ErrCodeLateCancellationFee:   "Кеш бас тарту ақылы. Брондаудан бас тарту үшін төлемді растаңыз.",
+		ErrCodeDayCloseBlocked:       "Осы күннің тапсырыстары, ойын сессиялары немесе ауысымдары әлі жабылмаған.",
+		ErrCodeDayClosed:             "Жұмыс күні жабылды. Оның жазбаларын тек әкімші өзгерте алады.",
+		ErrCodeClientDuplicate:       "Мұндай клиент бұрыннан бар сияқты.",
+		ErrCodeInternalServerError:   "Сервердің ішкі қатесі.",
+		ErrCodeValidationFailed:      "Деректерді тексеру сәтсіз аяқталды.",
+		ErrCodeNotImplemented:        "Бұл функция әлі іске асырылмаған.",
+	},
+}
+
+// IsSupportedLanguage reports whether lang is one of SupportedLanguages.
+func IsSupportedLanguage(lang string) bool {
+	for _, supported := range SupportedLanguages {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAcceptLanguage returns the supported language the Accept-Language header
+// prefers most, e.g. "ru" for "ru-RU,ru;q=0.9,en;q=0.8", or "" if none is supported.
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !IsSupportedLanguage(lang) {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// RequestLanguage returns the language to answer c in: the authenticated user's
+// preferred language, else the best match of the Accept-Language header, else English.
+func RequestLanguage(c *gin.Context) string {
+	if lang := c.GetString(LanguageContextKey); IsSupportedLanguage(lang) {
+		return lang
+	}
+	if lang := ParseAcceptLanguage(c.GetHeader("Accept-Language")); lang != "" {
+		return lang
+	}
+	return LanguageEnglish
+}
+
+// TranslateErrorCode returns the message for code in lang, if there is a translation.
+func TranslateErrorCode(lang, code string) (string, bool) {
+	message, ok := errorMessages[lang][code]
+	return message, ok
+}
+
+// localizeAPIError returns err with its message in the request's language and sets Content-Language.
+func localizeAPIError(c *gin.Context, err *APIError) *APIError {
+	lang := RequestLanguage(c)
+	message, ok := TranslateErrorCode(lang, err.Code)
+	if !ok {
+		c.Header("Content-Language", LanguageEnglish)
+		return err
+	}
+	c.Header("Content-Language", lang)
+	localized := *err
+	localized.Message = message
+	return &localized
+}
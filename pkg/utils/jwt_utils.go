@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -9,18 +11,37 @@ import (
 
 // jwtSecretKey is used to sign and verify JWT tokens. 
 // IMPORTANT: In a production environment, this key should be strong and come from a secure configuration (e.g., environment variable).
-var jwtSecretKey = []byte("your-super-secret-and-long-jwt-key-ps-club-crm") // TODO: Move to config/env
+var jwtSecretKey = []byte(DefaultJWTSecret)
+
+// DefaultJWTSecret is only suitable for development; set JWT_SECRET in any shared environment.
+const DefaultJWTSecret = "your-super-secret-and-long-jwt-key-ps-club-crm"
+
+// SetJWTSecret sets the key used to sign and verify tokens. It must be called
+// before serving requests, so that tokens issued at login validate in AuthMiddleware.
+func SetJWTSecret(secret string) {
+	jwtSecretKey = []byte(secret)
+}
 
 const (
 	AccessTokenTTL  = 15 * time.Minute    // Access token lives for 15 minutes
 	RefreshTokenTTL = 7 * 24 * time.Hour // Refresh token lives for 7 days
 )
 
+// RefreshTokenIssuer marks refresh tokens, so they cannot be used as access tokens.
+const RefreshTokenIssuer = "ps-club-crm-backend-refresh"
+
 // Claims defines the JWT claims structure
 type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"` // User role for authorization
+	// SessionID is the login session (device) the token belongs to; 0 for tokens issued before sessions existed
+	SessionID int64 `json:"sid,omitempty"`
+	// Language is the user's preferred language of API messages; empty follows Accept-Language
+	Language string `json:"lang,omitempty"`
+	// ImpersonatorID is the Admin acting as this user with an impersonation token; 0 otherwise
+	ImpersonatorID   int64  `json:"imp,omitempty"`
+	ImpersonatorName string `json:"imp_name,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -46,16 +67,34 @@ func GenerateAccessToken(userID int64, username string, role string) (string, er
 	return tokenString, nil
 }
 
-// GenerateRefreshToken creates a new JWT refresh token for a given user ID.
-// Refresh tokens typically have fewer claims and a longer expiry.
-func GenerateRefreshToken(userID int64) (string, error) {
+// NewRefreshTokenID returns a random refresh token ID (jti).
+func NewRefreshTokenID() (string, error) {
+	tokenID := make([]byte, 16)
+	if _, err := rand.Read(tokenID); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token ID: %w", err)
+	}
+	return hex.EncodeToString(tokenID), nil
+}
+
+// RevokedSessionKey is the shared store key marking a session as revoked. While it
+// exists, access tokens of the session are rejected.
+func RevokedSessionKey(sessionID int64) string {
+	return fmt.Sprintf("auth:revoked_session:%d", sessionID)
+}
+
+// GenerateRefreshToken creates a new JWT refresh token for a given user ID and session.
+// Refresh tokens typically have fewer claims and a longer expiry. Each carries a
+// unique ID (jti, see NewRefreshTokenID) so it can be revoked individually.
+func GenerateRefreshToken(userID, sessionID int64, tokenID string) (string, error) {
 	expirationTime := time.Now().Add(RefreshTokenTTL)
 	claims := &Claims{
-		UserID: userID, // Only UserID needed for refresh token to identify user
+		UserID:    userID, // Only UserID needed for refresh token to identify user
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "ps-club-crm-backend-refresh",
+			Issuer:    RefreshTokenIssuer,
 		},
 	}
 
@@ -67,9 +106,33 @@ func GenerateRefreshToken(userID int64) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken parses and validates a JWT token string.
+// ValidateToken parses and validates an access token string.
 // It returns the claims if the token is valid, otherwise an error.
 func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer == RefreshTokenIssuer {
+		return nil, fmt.Errorf("refresh tokens cannot be used for authentication")
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken parses and validates a refresh token string. It does not
+// check revocation, which is kept by the auth service.
+func ValidateRefreshToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != RefreshTokenIssuer || claims.ID == "" {
+		return nil, fmt.Errorf("not a refresh token")
+	}
+	return claims, nil
+}
+
+func parseToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		// Don't forget to validate the alg is what you expect:
@@ -47,7 +47,8 @@ func GinLogger() gin.HandlerFunc {
 		}
 
 		// Log request details
-		event.Str("method", c.Request.Method).
+		event.Str("request_id", c.GetString("requestID")).
+			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
 			Int("status_code", statusCode).
 			Str("client_ip", c.ClientIP()).
@@ -75,6 +76,17 @@ func LogInfo(message string, fields ...map[string]interface{}) {
 	event.Msg(message)
 }
 
+// LogWarn is a helper to log a warning.
+func LogWarn(message string, fields ...map[string]interface{}) {
+	event := log.Warn()
+	if len(fields) > 0 {
+		for _, f := range fields {
+			event = event.Fields(f)
+		}
+	}
+	event.Msg(message)
+}
+
 // LogDebug is a helper to log a debug message.
 func LogDebug(message string, fields ...map[string]interface{}) {
 	event := log.Debug()
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultPhoneCountryCode is the country calling code of phone numbers written without one:
+// Kazakhstan, +7.
+const DefaultPhoneCountryCode = "7"
+
+// ErrInvalidPhoneNumber is returned by NormalizePhone for strings that are not phone numbers.
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// phoneSeparators are dropped from phone numbers before they are normalized.
+var phoneSeparators = strings.NewReplacer(" ", "", "\t", "", "-", "", "(", "", ")", "", ".", "")
+
+// NormalizePhone returns a phone number in E.164 form, e.g. "+77011234567" for
+// "8 (701) 123-45-67". Spaces, dashes, dots and parentheses are dropped. A national number,
+// with the trunk prefix 8 or without it ("701 123 45 67"), gets DefaultPhoneCountryCode, and
+// "00" starts an international number like "+". The normalize_phone SQL function of migration
+// 0051 mirrors it for the stored numbers.
+func NormalizePhone(phone string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(phone))
+	international := false
+	switch {
+	case strings.HasPrefix(number, "+"):
+		international, number = true, number[1:]
+	case strings.HasPrefix(number, "00"):
+		international, number = true, number[2:]
+	}
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return "", ErrInvalidPhoneNumber
+	}
+
+	if !international {
+		switch {
+		case len(number) == 11 && number[0] == '8':
+			number = DefaultPhoneCountryCode + number[1:]
+		case len(number) == 11 && number[0] == '7':
+		case len(number) == 10:
+			number = DefaultPhoneCountryCode + number
+		default:
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+	// E.164 allows up to 15 digits; numbers of +7 (Kazakhstan, Russia) have 10 after the code
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' || (number[0] == '7' && len(number) != 11) {
+		return "", ErrInvalidPhoneNumber
+	}
+	return "+" + number, nil
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"encoding/base64"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize is the width and height of generated QR codes, in pixels.
+const qrCodeSize = 256
+
+// QRCodeDataURI encodes content as a PNG QR code, returned as a data URI that clients
+// can use directly as an image source.
+func QRCodeDataURI(content string) (string, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
@@ -1,5 +1,10 @@
 package utils
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 // NewNullString is a helper for string pointers, returning nil if string is empty.
 // Useful for fields that are optional and should be NULL in DB if not provided.
 func NewNullString(s string) *string {
@@ -8,3 +13,32 @@ func NewNullString(s string) *string {
 	}
 	return &s
 }
+
+// MaskPhone hides all digits of a phone number but the last four, keeping its format,
+// e.g. "+7 701 123 45 67" becomes "+* *** *** 45 67".
+func MaskPhone(phone string) string {
+	masked := []rune(phone)
+	keep := 4
+	for i := len(masked) - 1; i >= 0; i-- {
+		if masked[i] < '0' || masked[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		masked[i] = '*'
+	}
+	return string(masked)
+}
+
+// MaskEmail hides the local part of an email address but its first character,
+// e.g. "john.doe@example.com" becomes "j***@example.com".
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first) + "***" + email[at:]
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time range presets, relative to now in the club timezone. The weeks start on Monday.
+const (
+	RangeToday      = "today"
+	RangeYesterday  = "yesterday"
+	RangeThisWeek   = "this_week"
+	RangeLastWeek   = "last_week"
+	RangeThisMonth  = "this_month"
+	RangeLastMonth  = "last_month"
+	RangeLast7Days  = "last_7_days" // Today and the 6 days before
+	RangeLast30Days = "last_30_days"
+)
+
+// RangePresets lists the accepted time range presets.
+var RangePresets = []string{RangeToday, RangeYesterday, RangeThisWeek, RangeLastWeek, RangeThisMonth, RangeLastMonth, RangeLast7Days, RangeLast30Days}
+
+// ErrInvalidTimeRange is wrapped by the errors of ParseTimeRange.
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// TimeRange is the half-open UTC interval [From, To). A nil bound is open.
+type TimeRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// IsZero reports whether the range has no bounds.
+func (r TimeRange) IsZero() bool {
+	return r.From == nil && r.To == nil
+}
+
+// ClubDates returns the inclusive club dates (YYYY-MM-DD) of the days the range touches, for
+// queries by business date; an open bound is empty.
+func (r TimeRange) ClubDates() (from, to string) {
+	if r.From != nil {
+		from = FormatClubTime(*r.From, DateLayout)
+	}
+	if r.To != nil {
+		to = FormatClubTime(r.To.Add(-time.Nanosecond), DateLayout)
+	}
+	return from, to
+}
+
+// ParseTimeRange parses the bounds of a time range, or a preset (one of RangePresets) instead
+// of both. A date (YYYY-MM-DD) is a whole club day, so to=2024-06-30 includes June 30; a
+// date-time (RFC3339, or naive in the club timezone) is an instant, from inclusive and to
+// exclusive. Empty bounds are open. to must be after from.
+func ParseTimeRange(fromStr, toStr, preset string) (TimeRange, error) {
+	fromStr, toStr, preset = strings.TrimSpace(fromStr), strings.TrimSpace(toStr), strings.TrimSpace(preset)
+	if preset != "" {
+		if fromStr != "" || toStr != "" {
+			return TimeRange{}, fmt.Errorf("%w: give either range or from and to", ErrInvalidTimeRange)
+		}
+		return presetTimeRange(preset, NowInClub())
+	}
+
+	var r TimeRange
+	if fromStr != "" {
+		from, err := parseRangeBound(fromStr, false)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("%w: from must be YYYY-MM-DD or a date-time: %v", ErrInvalidTimeRange, err)
+		}
+		r.From = &from
+	}
+	if toStr != "" {
+		to, err := parseRangeBound(toStr, true)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("%w: to must be YYYY-MM-DD or a date-time: %v", ErrInvalidTimeRange, err)
+		}
+		r.To = &to
+	}
+	if r.From != nil && r.To != nil && !r.To.After(*r.From) {
+		return TimeRange{}, fmt.Errorf("%w: to must be after from", ErrInvalidTimeRange)
+	}
+	return r, nil
+}
+
+// parseRangeBound parses a bound of a time range; a date as the end of a range is the next
+// club midnight, so the day is included.
+func parseRangeBound(value string, isEnd bool) (time.Time, error) {
+	if IsValidDate(value) {
+		day, err := ParseClubDate(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if isEnd {
+			_, end := DayBounds(day)
+			return end, nil
+		}
+		return day, nil
+	}
+	return ParseClubDateTime(value)
+}
+
+// presetTimeRange returns the range of a preset as of now.
+func presetTimeRange(preset string, now time.Time) (TimeRange, error) {
+	var from, to time.Time
+	switch preset {
+	case RangeToday:
+		from, to = StartOfDay(now), AddClubDays(now, 1)
+	case RangeYesterday:
+		from, to = AddClubDays(now, -1), StartOfDay(now)
+	case RangeThisWeek:
+		from = StartOfWeek(now)
+		to = AddClubDays(from, 7)
+	case RangeLastWeek:
+		to = StartOfWeek(now)
+		from = AddClubDays(to, -7)
+	case RangeThisMonth:
+		from = StartOfMonth(now)
+		to = StartOfMonth(from.AddDate(0, 1, 0))
+	case RangeLastMonth:
+		to = StartOfMonth(now)
+		from = StartOfMonth(to.AddDate(0, -1, 0))
+	case RangeLast7Days:
+		from, to = AddClubDays(now, -6), AddClubDays(now, 1)
+	case RangeLast30Days:
+		from, to = AddClubDays(now, -29), AddClubDays(now, 1)
+	default:
+		return TimeRange{}, fmt.Errorf("%w: range must be one of %s", ErrInvalidTimeRange, strings.Join(RangePresets, ", "))
+	}
+	from, to = from.UTC(), to.UTC()
+	return TimeRange{From: &from, To: &to}, nil
+}
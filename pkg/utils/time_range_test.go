@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// formatRange returns the bounds of r in RFC3339, UTC, with "" for an open bound.
+func formatRange(r TimeRange) (from, to string) {
+	if r.From != nil {
+		from = r.From.UTC().Format(time.RFC3339)
+	}
+	if r.To != nil {
+		to = r.To.UTC().Format(time.RFC3339)
+	}
+	return from, to
+}
+
+func TestParseTimeRange(t *testing.T) {
+	useClubTimezone(t, "Asia/Almaty") // UTC+5
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom string // RFC3339, UTC; "" if open
+		wantTo   string
+	}{
+		{name: "no bounds"},
+		{name: "dates include the last day", from: "2024-06-01", to: "2024-06-30", wantFrom: "2024-05-31T19:00:00Z", wantTo: "2024-06-30T19:00:00Z"},
+		{name: "a single day", from: "2024-06-01", to: "2024-06-01", wantFrom: "2024-05-31T19:00:00Z", wantTo: "2024-06-01T19:00:00Z"},
+		{name: "open start", to: "2024-06-30", wantTo: "2024-06-30T19:00:00Z"},
+		{name: "open end", from: "2024-06-01", wantFrom: "2024-05-31T19:00:00Z"},
+		{name: "date-times are instants", from: "2024-06-01T10:00:00Z", to: "2024-06-01T17:30:00+05:00", wantFrom: "2024-06-01T10:00:00Z", wantTo: "2024-06-01T12:30:00Z"},
+		{name: "naive date-times are club time", from: "2024-06-01 18:00", to: "2024-06-01T23:00", wantFrom: "2024-06-01T13:00:00Z", wantTo: "2024-06-01T18:00:00Z"},
+		{name: "surrounding spaces", from: " 2024-06-01 ", to: "2024-06-02 ", wantFrom: "2024-05-31T19:00:00Z", wantTo: "2024-06-02T19:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseTimeRange(tt.from, tt.to, "")
+			if err != nil {
+				t.Fatalf("ParseTimeRange(%q, %q): %v", tt.from, tt.to, err)
+			}
+			if from, to := formatRange(r); from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("ParseTimeRange(%q, %q) = [%s, %s), want [%s, %s)", tt.from, tt.to, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestParseTimeRangeRejects(t *testing.T) {
+	tests := []struct {
+		name             string
+		from, to, preset string
+	}{
+		{name: "to before from", from: "2024-06-30", to: "2024-06-01"},
+		{name: "to equal to from", from: "2024-06-01T10:00:00Z", to: "2024-06-01T10:00:00Z"},
+		{name: "date-time to before from", from: "2024-06-01T10:00:00Z", to: "2024-06-01T09:59:59Z"},
+		{name: "day first date", from: "01.06.2024"},
+		{name: "impossible date", to: "2024-02-30"},
+		{name: "not a date", from: "yesterday"},
+		{name: "unknown preset", preset: "this_year"},
+		{name: "preset with from", from: "2024-06-01", preset: RangeToday},
+		{name: "preset with to", to: "2024-06-30", preset: RangeThisWeek},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseTimeRange(tt.from, tt.to, tt.preset)
+			if !errors.Is(err, ErrInvalidTimeRange) {
+				from, to := formatRange(r)
+				t.Errorf("ParseTimeRange(%q, %q, %q) = [%s, %s), %v; want %v", tt.from, tt.to, tt.preset, from, to, err, ErrInvalidTimeRange)
+			}
+		})
+	}
+}
+
+func TestPresetTimeRange(t *testing.T) {
+	loc := useClubTimezone(t, "Asia/Almaty")
+	// A Wednesday night; the club days (UTC+5) start at 19:00 UTC the day before
+	now := time.Date(2024, time.June, 12, 23, 30, 0, 0, loc)
+
+	tests := []struct {
+		preset   string
+		wantFrom string // RFC3339, UTC
+		wantTo   string
+	}{
+		{RangeToday, "2024-06-11T19:00:00Z", "2024-06-12T19:00:00Z"},
+		{RangeYesterday, "2024-06-10T19:00:00Z", "2024-06-11T19:00:00Z"},
+		{RangeThisWeek, "2024-06-09T19:00:00Z", "2024-06-16T19:00:00Z"},
+		{RangeLastWeek, "2024-06-02T19:00:00Z", "2024-06-09T19:00:00Z"},
+		{RangeThisMonth, "2024-05-31T19:00:00Z", "2024-06-30T19:00:00Z"},
+		{RangeLastMonth, "2024-04-30T19:00:00Z", "2024-05-31T19:00:00Z"},
+		{RangeLast7Days, "2024-06-05T19:00:00Z", "2024-06-12T19:00:00Z"},
+		{RangeLast30Days, "2024-05-13T19:00:00Z", "2024-06-12T19:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			r, err := presetTimeRange(tt.preset, now)
+			if err != nil {
+				t.Fatalf("presetTimeRange(%q): %v", tt.preset, err)
+			}
+			if from, to := formatRange(r); from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("presetTimeRange(%q) = [%s, %s), want [%s, %s)", tt.preset, from, to, tt.wantFrom, tt.wantTo)
+			}
+			if r.From.Location() != time.UTC || r.To.Location() != time.UTC {
+				t.Errorf("presetTimeRange(%q) = %v, %v, want UTC", tt.preset, r.From.Location(), r.To.Location())
+			}
+		})
+	}
+
+	// Every preset is accepted, as of the real now
+	for _, preset := range RangePresets {
+		if r, err := ParseTimeRange("", "", preset); err != nil || r.From == nil || r.To == nil || !r.To.After(*r.From) {
+			t.Errorf("ParseTimeRange(%q) = %+v, %v; want a closed range", preset, r, err)
+		}
+	}
+}
+
+func TestTimeRangeClubDates(t *testing.T) {
+	useClubTimezone(t, "Asia/Almaty")
+	tests := []struct {
+		name             string
+		from, to         string
+		wantFrom, wantTo string
+	}{
+		{name: "whole days", from: "2024-06-01", to: "2024-06-30", wantFrom: "2024-06-01", wantTo: "2024-06-30"},
+		{name: "instants touch their days", from: "2024-06-01T18:30:00Z", to: "2024-06-02T10:00:00Z", wantFrom: "2024-06-01", wantTo: "2024-06-02"},
+		{name: "open bounds", to: "2024-06-30", wantTo: "2024-06-30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseTimeRange(tt.from, tt.to, "")
+			if err != nil {
+				t.Fatalf("ParseTimeRange(%q, %q): %v", tt.from, tt.to, err)
+			}
+			if from, to := r.ClubDates(); from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("ClubDates = %q, %q, want %q, %q", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
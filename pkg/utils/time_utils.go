@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DateLayout is the layout used for date-only inputs (YYYY-MM-DD).
+const DateLayout = "2006-01-02"
+
+// MonthLayout is the layout used for month inputs (YYYY-MM).
+const MonthLayout = "2006-01"
+
+// naiveDateTimeLayouts are accepted for date-time inputs that carry no offset.
+// Such inputs are interpreted as wall-clock time in the club timezone.
+var naiveDateTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+var (
+	clubLocation   = time.UTC
+	clubLocationMu sync.RWMutex
+)
+
+// SetClubTimezone sets the IANA timezone (e.g. "Asia/Almaty") used to interpret
+// naive inputs and to compute day/week/month boundaries. An empty name resets to UTC.
+func SetClubTimezone(name string) error {
+	name = strings.TrimSpace(name)
+	loc := time.UTC
+	if name != "" {
+		var err error
+		loc, err = time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("invalid club timezone %q: %w", name, err)
+		}
+	}
+	clubLocationMu.Lock()
+	clubLocation = loc
+	clubLocationMu.Unlock()
+	return nil
+}
+
+// ClubLocation returns the configured club timezone.
+func ClubLocation() *time.Location {
+	clubLocationMu.RLock()
+	defer clubLocationMu.RUnlock()
+	return clubLocation
+}
+
+// NowInClub returns the current time in the club timezone.
+func NowInClub() time.Time {
+	return time.Now().In(ClubLocation())
+}
+
+// NowUTC returns the current time normalized to UTC. Use it for stored timestamps.
+func NowUTC() time.Time {
+	return time.Now().UTC()
+}
+
+// StartOfDay returns the start of t's calendar day in the club timezone: midnight, or the end
+// of the DST gap on days whose midnight the clocks skip.
+func StartOfDay(t time.Time) time.Time {
+	local := t.In(ClubLocation())
+	return clubMidnight(local.Year(), local.Month(), local.Day(), local.Location())
+}
+
+// AddClubDays returns the start of the club day days after t's day (before it if negative).
+// Unlike StartOfDay(t).AddDate it lands on the start of the day when a DST gap moved either.
+func AddClubDays(t time.Time, days int) time.Time {
+	local := t.In(ClubLocation())
+	return clubMidnight(local.Year(), local.Month(), local.Day()+days, local.Location())
+}
+
+// StartOfWeek returns the start of the Monday of t's week in the club timezone.
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+	return AddClubDays(day, -offset)
+}
+
+// StartOfMonth returns the start of the first day of t's month in the club timezone.
+func StartOfMonth(t time.Time) time.Time {
+	local := t.In(ClubLocation())
+	return clubMidnight(local.Year(), local.Month(), 1, local.Location())
+}
+
+// clubMidnight returns the first instant of a calendar day in loc; day may overflow the month
+// as with time.Date. Where the clocks skip midnight (e.g. America/Santiago in September),
+// time.Date moves it back into the previous day, so the day starts when the clocks jump.
+func clubMidnight(year int, month time.Month, day int, loc *time.Location) time.Time {
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if noon := time.Date(year, month, day, 12, 0, 0, 0, loc); midnight.Day() != noon.Day() {
+		_, midnight = midnight.ZoneBounds()
+	}
+	return midnight
+}
+
+// DayBounds returns the half-open UTC interval [start, end) covering t's day in the club timezone.
+// On DST transition days the interval is 23 or 25 hours long.
+func DayBounds(t time.Time) (time.Time, time.Time) {
+	return StartOfDay(t).UTC(), AddClubDays(t, 1).UTC()
+}
+
+// ParseClubDate parses a YYYY-MM-DD string as the start of that day in the club timezone (see
+// StartOfDay) and returns it in UTC.
+func ParseClubDate(value string) (time.Time, error) {
+	t, err := time.Parse(DateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return clubMidnight(t.Year(), t.Month(), t.Day(), ClubLocation()).UTC(), nil
+}
+
+// ParseClubMonth parses a YYYY-MM string and returns the half-open UTC interval [start, end)
+// covering the month in the club timezone.
+func ParseClubMonth(value string) (time.Time, time.Time, error) {
+	t, err := time.Parse(MonthLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	loc := ClubLocation()
+	return clubMidnight(t.Year(), t.Month(), 1, loc).UTC(), clubMidnight(t.Year(), t.Month()+1, 1, loc).UTC(), nil
+}
+
+// IsValidDate checks if value is a date in YYYY-MM-DD format.
+func IsValidDate(value string) bool {
+	_, err := time.Parse(DateLayout, value)
+	return err == nil
+}
+
+// ParseClubDateRange parses an inclusive YYYY-MM-DD date range and returns the
+// half-open UTC interval [from, to) covering both days in the club timezone.
+// Either side may be empty, in which case the corresponding result is nil.
+func ParseClubDateRange(fromStr, toStr string) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if strings.TrimSpace(fromStr) != "" {
+		t, err := ParseClubDate(fromStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &t
+	}
+	if strings.TrimSpace(toStr) != "" {
+		t, err := ParseClubDate(toStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, end := DayBounds(t)
+		to = &end
+	}
+	return from, to, nil
+}
+
+// ParseClubDateTime parses an RFC3339 timestamp, or a naive date-time interpreted in the
+// club timezone, and returns it normalized to UTC.
+func ParseClubDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	var lastErr error
+	for _, layout := range naiveDateTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, ClubLocation())
+		if err == nil {
+			return t.UTC(), nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+// FormatClubTime formats t in the club timezone using the given layout.
+func FormatClubTime(t time.Time, layout string) string {
+	return t.In(ClubLocation()).Format(layout)
+}
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata" // The DST zones below must not depend on the host's zoneinfo
+)
+
+// useClubTimezone sets the club timezone for the test and restores UTC after it.
+func useClubTimezone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	if err := SetClubTimezone(name); err != nil {
+		t.Fatalf("SetClubTimezone(%q): %v", name, err)
+	}
+	t.Cleanup(func() { SetClubTimezone("") })
+	return ClubLocation()
+}
+
+func TestDayBoundsAroundDST(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		at        string // RFC3339
+		wantStart string // RFC3339, UTC
+		wantHours float64
+	}{
+		{"Berlin day before spring forward", "Europe/Berlin", "2024-03-30T12:00:00+01:00", "2024-03-29T23:00:00Z", 24},
+		{"Berlin spring forward", "Europe/Berlin", "2024-03-31T12:00:00+02:00", "2024-03-30T23:00:00Z", 23},
+		{"Berlin spring forward before the gap", "Europe/Berlin", "2024-03-31T01:30:00+01:00", "2024-03-30T23:00:00Z", 23},
+		{"Berlin day after spring forward", "Europe/Berlin", "2024-04-01T00:00:00+02:00", "2024-03-31T22:00:00Z", 24},
+		{"Berlin fall back", "Europe/Berlin", "2024-10-27T12:00:00+01:00", "2024-10-26T22:00:00Z", 25},
+		{"Berlin fall back, first 02:30", "Europe/Berlin", "2024-10-27T02:30:00+02:00", "2024-10-26T22:00:00Z", 25},
+		{"Berlin fall back, second 02:30", "Europe/Berlin", "2024-10-27T02:30:00+01:00", "2024-10-26T22:00:00Z", 25},
+		{"Berlin last minute of fall back", "Europe/Berlin", "2024-10-27T23:59:00+01:00", "2024-10-26T22:00:00Z", 25},
+		{"New York spring forward", "America/New_York", "2024-03-10T20:00:00-04:00", "2024-03-10T05:00:00Z", 23},
+		{"New York fall back", "America/New_York", "2024-11-03T01:30:00-05:00", "2024-11-03T04:00:00Z", 25},
+		// Midnight does not exist on the spring forward day in Santiago, so the day starts at 01:00
+		{"Santiago spring forward at midnight", "America/Santiago", "2024-09-08T12:00:00-03:00", "2024-09-08T04:00:00Z", 23},
+		{"Santiago fall back at midnight", "America/Santiago", "2024-04-06T12:00:00-03:00", "2024-04-06T03:00:00Z", 25},
+		{"Almaty has no DST", "Asia/Almaty", "2024-03-31T12:00:00+05:00", "2024-03-30T19:00:00Z", 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useClubTimezone(t, tt.zone)
+			at := mustParseRFC3339(t, tt.at)
+
+			start, end := DayBounds(at)
+			if got := start.Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("DayBounds start = %s, want %s", got, tt.wantStart)
+			}
+			if start.Location() != time.UTC || end.Location() != time.UTC {
+				t.Errorf("DayBounds = %v, %v, want UTC", start.Location(), end.Location())
+			}
+			if hours := end.Sub(start).Hours(); hours != tt.wantHours {
+				t.Errorf("DayBounds spans %v hours, want %v", hours, tt.wantHours)
+			}
+			if at.Before(start) || !at.Before(end) {
+				t.Errorf("DayBounds [%s, %s) does not contain %s", start, end, at)
+			}
+			if !StartOfDay(at).Equal(start) {
+				t.Errorf("StartOfDay = %s, want %s", StartOfDay(at), start)
+			}
+		})
+	}
+}
+
+func TestStartOfWeekAroundDST(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		at        string
+		wantStart string // RFC3339 in the club timezone
+		wantHours float64
+	}{
+		{"Berlin week of spring forward, Sunday", "Europe/Berlin", "2024-03-31T23:00:00+02:00", "2024-03-25T00:00:00+01:00", 167},
+		{"Berlin week after spring forward, Monday", "Europe/Berlin", "2024-04-01T00:00:00+02:00", "2024-04-01T00:00:00+02:00", 168},
+		{"Berlin week of fall back, Sunday", "Europe/Berlin", "2024-10-27T12:00:00+01:00", "2024-10-21T00:00:00+02:00", 169},
+		{"Berlin week of fall back, Monday", "Europe/Berlin", "2024-10-21T00:00:00+02:00", "2024-10-21T00:00:00+02:00", 169},
+		{"New York week of spring forward", "America/New_York", "2024-03-10T12:00:00-04:00", "2024-03-04T00:00:00-05:00", 167},
+		{"New York week of fall back, Tuesday", "America/New_York", "2024-11-05T09:00:00-05:00", "2024-11-04T00:00:00-05:00", 168},
+		{"Santiago week of spring forward", "America/Santiago", "2024-09-08T12:00:00-03:00", "2024-09-02T00:00:00-04:00", 167},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useClubTimezone(t, tt.zone)
+			at := mustParseRFC3339(t, tt.at)
+
+			start := StartOfWeek(at)
+			if got := start.Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("StartOfWeek = %s, want %s", got, tt.wantStart)
+			}
+			if start.Weekday() != time.Monday {
+				t.Errorf("StartOfWeek is a %s, want Monday", start.Weekday())
+			}
+			if hours := AddClubDays(start, 7).Sub(start).Hours(); hours != tt.wantHours {
+				t.Errorf("the week spans %v hours, want %v", hours, tt.wantHours)
+			}
+		})
+	}
+}
+
+func TestStartOfMonthAroundDST(t *testing.T) {
+	loc := useClubTimezone(t, "Europe/Berlin")
+	tests := []struct {
+		at        string
+		wantStart string
+		wantHours float64
+	}{
+		{"2024-03-31T12:00:00+02:00", "2024-03-01T00:00:00+01:00", 31*24 - 1},
+		{"2024-10-31T23:59:59+01:00", "2024-10-01T00:00:00+02:00", 31*24 + 1},
+		{"2024-11-01T00:00:00+01:00", "2024-11-01T00:00:00+01:00", 30 * 24},
+	}
+	for _, tt := range tests {
+		start := StartOfMonth(mustParseRFC3339(t, tt.at))
+		if got := start.Format(time.RFC3339); got != tt.wantStart {
+			t.Errorf("StartOfMonth(%s) = %s, want %s", tt.at, got, tt.wantStart)
+		}
+		if start.Location() != loc {
+			t.Errorf("StartOfMonth(%s) is in %v, want %v", tt.at, start.Location(), loc)
+		}
+		if hours := start.AddDate(0, 1, 0).Sub(start).Hours(); hours != tt.wantHours {
+			t.Errorf("the month of %s spans %v hours, want %v", tt.at, hours, tt.wantHours)
+		}
+	}
+}
+
+func TestParseClubDateRangeAroundDST(t *testing.T) {
+	useClubTimezone(t, "Europe/Berlin")
+	tests := []struct {
+		from, to         string
+		wantFrom, wantTo string // RFC3339, UTC; empty for nil
+	}{
+		{"2024-03-31", "2024-03-31", "2024-03-30T23:00:00Z", "2024-03-31T22:00:00Z"},
+		{"2024-10-27", "2024-10-27", "2024-10-26T22:00:00Z", "2024-10-27T23:00:00Z"},
+		{"2024-03-25", "2024-03-31", "2024-03-24T23:00:00Z", "2024-03-31T22:00:00Z"},
+		{"", "2024-10-27", "", "2024-10-27T23:00:00Z"},
+		{"2024-10-27", "", "2024-10-26T22:00:00Z", ""},
+	}
+	for _, tt := range tests {
+		from, to, err := ParseClubDateRange(tt.from, tt.to)
+		if err != nil {
+			t.Errorf("ParseClubDateRange(%q, %q): %v", tt.from, tt.to, err)
+			continue
+		}
+		if got := formatOptional(from); got != tt.wantFrom {
+			t.Errorf("ParseClubDateRange(%q, %q) from = %s, want %s", tt.from, tt.to, got, tt.wantFrom)
+		}
+		if got := formatOptional(to); got != tt.wantTo {
+			t.Errorf("ParseClubDateRange(%q, %q) to = %s, want %s", tt.from, tt.to, got, tt.wantTo)
+		}
+	}
+
+	if _, _, err := ParseClubDateRange("2024-02-30", ""); err == nil {
+		t.Error("ParseClubDateRange accepted 2024-02-30")
+	}
+}
+
+func TestParseClubDateTimeAroundDST(t *testing.T) {
+	useClubTimezone(t, "Europe/Berlin")
+	tests := []struct {
+		value string
+		want  []string // RFC3339, UTC; any of them
+	}{
+		{"2024-03-31T01:59:00", []string{"2024-03-31T00:59:00Z"}},
+		// 02:30 does not exist on the spring forward day; Go does not promise which side it lands on
+		{"2024-03-31T02:30:00", []string{"2024-03-31T00:30:00Z", "2024-03-31T01:30:00Z"}},
+		{"2024-03-31T03:00", []string{"2024-03-31T01:00:00Z"}},
+		// 02:30 happens twice on the fall back day; either is a valid reading
+		{"2024-10-27 02:30:00", []string{"2024-10-27T00:30:00Z", "2024-10-27T01:30:00Z"}},
+		{"2024-10-27 03:00", []string{"2024-10-27T02:00:00Z"}},
+		// An explicit offset is taken as given
+		{"2024-10-27T02:30:00+01:00", []string{"2024-10-27T01:30:00Z"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseClubDateTime(tt.value)
+		if err != nil {
+			t.Errorf("ParseClubDateTime(%q): %v", tt.value, err)
+			continue
+		}
+		if formatted := got.UTC().Format(time.RFC3339); !slices.Contains(tt.want, formatted) {
+			t.Errorf("ParseClubDateTime(%q) = %s, want one of %v", tt.value, formatted, tt.want)
+		}
+	}
+}
+
+func TestSetClubTimezoneRejectsUnknownZone(t *testing.T) {
+	useClubTimezone(t, "Europe/Berlin")
+	if err := SetClubTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Fatal("SetClubTimezone accepted an unknown zone")
+	}
+	if got := ClubLocation().String(); got != "Europe/Berlin" {
+		t.Errorf("ClubLocation() = %s after a rejected zone, want Europe/Berlin", got)
+	}
+	if err := SetClubTimezone(""); err != nil || ClubLocation() != time.UTC {
+		t.Errorf("SetClubTimezone(\"\") = %v, location %v, want UTC", err, ClubLocation())
+	}
+}
+
+func mustParseRFC3339(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", value, err)
+	}
+	return parsed
+}
+
+func formatOptional(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
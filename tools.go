@@ -0,0 +1,8 @@
+//go:build tools
+
+// Package tools pins the code generators used by go:generate in go.mod.
+package tools
+
+import (
+	_ "github.com/99designs/gqlgen"
+)